@@ -0,0 +1,115 @@
+// Command agui-deadletter inspects and replays the dead letters a FileSink
+// stored (see package deadletter). By default it decodes every letter again
+// and writes the events recovered to stdout as JSON Lines, e.g. to feed them
+// back once a decoder is fixed; letters failing again are appended to the
+// -retry file. With -summary it counts the letters by source and error
+// instead.
+//
+//	agui-deadletter -file dead.jsonl -retry dead-again.jsonl > recovered.jsonl
+//	agui-deadletter -file dead.jsonl -summary
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/deadletter"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "agui-deadletter:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	file := flag.String("file", "", "file of the dead letters")
+	retry := flag.String("retry", "", "file the letters failing again are appended to")
+	summary := flag.Bool("summary", false, "count the letters by source and error instead of replaying them")
+	flag.Parse()
+	if *file == "" {
+		return errors.New("-file is required")
+	}
+
+	in, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if *summary {
+		return summarize(in, os.Stdout)
+	}
+
+	var config deadletter.ReplayConfig
+	if *retry != "" {
+		sink, err := deadletter.NewFileSink(*retry)
+		if err != nil {
+			return err
+		}
+		defer sink.Close()
+		config.Retry = sink
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	out := bufio.NewWriter(os.Stdout)
+	result, err := deadletter.Replay(ctx, in, func(_ context.Context, _ deadletter.Letter, event events.Event) error {
+		data, err := event.ToJSON()
+		if err != nil {
+			return err
+		}
+		if _, err := out.Write(append(data, '\n')); err != nil {
+			return err
+		}
+		return nil
+	}, config)
+	if flushErr := out.Flush(); err == nil {
+		err = flushErr
+	}
+	fmt.Fprintf(os.Stderr, "recovered %d letters, %d failed again\n", result.Recovered, result.Failed)
+	return err
+}
+
+// summarize writes the number of letters of r by source and error to w
+func summarize(r io.Reader, w io.Writer) error {
+	type cause struct{ source, error string }
+	counts := make(map[cause]int)
+	reader := deadletter.NewReader(r)
+	for {
+		letter, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		counts[cause{letter.Source, letter.Error}]++
+	}
+
+	causes := make([]cause, 0, len(counts))
+	for c := range counts {
+		causes = append(causes, c)
+	}
+	sort.Slice(causes, func(i, j int) bool {
+		if counts[causes[i]] != counts[causes[j]] {
+			return counts[causes[i]] > counts[causes[j]]
+		}
+		return causes[i].source+causes[i].error < causes[j].source+causes[j].error
+	})
+	for _, c := range causes {
+		if _, err := fmt.Fprintf(w, "%6d  %-8s  %s\n", counts[c], c.source, c.error); err != nil {
+			return err
+		}
+	}
+	return nil
+}
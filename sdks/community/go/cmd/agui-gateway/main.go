@@ -0,0 +1,245 @@
+// Command agui-gateway bridges AG-UI transports: it serves runs over HTTP and
+// Server-Sent Events, and optionally gRPC, forwarding each to an upstream
+// agent over gRPC or SSE. See package gateway.
+//
+//	agui-gateway -listen :8080 -upstream grpc://agent:50051
+//	agui-gateway -grpc-listen :50051 -upstream https://agent.example.com/agentic
+//
+// Callers are authenticated with the API keys of $AG_UI_GATEWAY_KEYS, a
+// comma-separated list of key=principal pairs sent in X-API-Key; without
+// keys the HTTP front is open. The gRPC front does not authenticate.
+//
+// -diagnostics serves a diagnostic endpoint at the given path of the HTTP
+// front, behind the same authentication, for client doctor --connectivity.
+//
+// -expect-schema refuses to start a gateway built from other .proto files
+// than the deployment, as identified by the hash -print-schema prints.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/proto/pb"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server/gateway"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server/grpcserver"
+)
+
+const (
+	// keysEnv holds the API keys of callers, as key=principal pairs
+	keysEnv = "AG_UI_GATEWAY_KEYS"
+
+	// upstreamKeyEnv holds the API key sent upstream
+	upstreamKeyEnv = "AG_UI_UPSTREAM_API_KEY"
+
+	// shutdownTimeout bounds how long runs may drain on shutdown
+	shutdownTimeout = 30 * time.Second
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "agui-gateway:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	listen := flag.String("listen", ":8080", "address of the HTTP front, empty to disable it")
+	grpcListen := flag.String("grpc-listen", "", "address of the gRPC front, empty to disable it")
+	upstream := flag.String("upstream", "", "upstream agent: grpc://host:port, grpcs://host:port or an http(s) URL")
+	forwardPrincipal := flag.String("forward-principal", "", "header sending the authenticated caller upstream, empty for none")
+	maxRuns := flag.Int("max-runs", 0, "maximum number of runs in flight, 0 for no limit")
+	runTimeout := flag.Duration("run-timeout", 0, "timeout of each run, 0 for none")
+	rate := flag.Float64("rate", 0, "runs per second allowed per caller, 0 for no limit")
+	heartbeat := flag.Duration("heartbeat", 0, "emit a heartbeat when a run is silent for this long, 0 unless requested by the client")
+	maxEventSize := flag.Int("max-event-size", 0, "largest event in bytes, larger messages snapshots are sent in chunks, 0 for no limit")
+	expectSchema := flag.String("expect-schema", "", "protobuf schema hash the deployment expects, refusing to start with another, empty to skip the check")
+	printSchema := flag.Bool("print-schema", false, "print the protobuf schema hash of the build and exit")
+	diagnostics := flag.String("diagnostics", "", "path of the HTTP front serving a diagnostic endpoint, e.g. /diagnostics, empty for none")
+	flag.Parse()
+
+	if *printSchema {
+		fmt.Println(pb.SchemaHash())
+		return nil
+	}
+	if err := pb.CheckSchema(*expectSchema, "deployment (-expect-schema)"); err != nil {
+		return err
+	}
+
+	if *diagnostics != "" && (!strings.HasPrefix(*diagnostics, "/") || *diagnostics == "/") {
+		return fmt.Errorf("invalid -diagnostics %q: expected a path below /", *diagnostics)
+	}
+
+	logger := slog.Default()
+	keys, err := parseKeys(os.Getenv(keysEnv))
+	if err != nil {
+		return err
+	}
+	config := gateway.Config{}
+	if *forwardPrincipal != "" {
+		header := *forwardPrincipal
+		config.Headers = func(ctx context.Context, _ *types.RunAgentInput) map[string]string {
+			if principal, ok := server.PrincipalFromContext(ctx); ok {
+				return map[string]string{header: principal.ID}
+			}
+			return nil
+		}
+	}
+	agent, closeUpstream, err := newUpstream(*upstream, os.Getenv(upstreamKeyEnv), config)
+	if err != nil {
+		return err
+	}
+	defer closeUpstream()
+	runConfig := server.RunManagerConfig{MaxConcurrentRuns: *maxRuns, RunTimeout: *runTimeout, Heartbeat: *heartbeat, MaxEventSize: *maxEventSize, Logger: logger}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	failed := make(chan error, 2)
+
+	var httpServer *http.Server
+	var front, diagnosticFront *server.Server
+	if *listen != "" {
+		front = server.NewServer(agent, server.Config{RunManagerConfig: runConfig})
+		var handler http.Handler = front
+		if *diagnostics != "" {
+			diagnosticServer := server.NewDiagnosticServer(server.DiagnosticConfig{Config: server.Config{RunManagerConfig: server.RunManagerConfig{Logger: logger}}})
+			diagnosticFront = diagnosticServer.Server
+			mux := http.NewServeMux()
+			mux.Handle(*diagnostics, diagnosticServer)
+			mux.Handle(strings.TrimSuffix(*diagnostics, "/")+"/runs/", diagnosticServer)
+			mux.Handle("/", front)
+			handler = mux
+		}
+		middleware := server.MiddlewareConfig{Logger: logger, RateLimit: server.RateLimitConfig{RequestsPerSecond: *rate}}
+		if len(keys) > 0 {
+			middleware.Authenticator = server.NewAPIKeyAuthenticator("X-API-Key", keys)
+		}
+		httpServer = &http.Server{Addr: *listen, Handler: server.Chain(handler, server.NewMiddleware(middleware)...)}
+		go func() {
+			logger.Info("Serving HTTP", "addr", *listen, "upstream", *upstream)
+			if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				failed <- err
+			}
+		}()
+	}
+
+	var grpcServer *grpc.Server
+	var service *grpcserver.Service
+	if *grpcListen != "" {
+		listener, err := net.Listen("tcp", *grpcListen)
+		if err != nil {
+			return err
+		}
+		service = grpcserver.NewService(agent, runConfig)
+		grpcServer = grpc.NewServer()
+		pb.RegisterAgentServiceServer(grpcServer, service)
+		go func() {
+			logger.Info("Serving gRPC", "addr", *grpcListen, "upstream", *upstream)
+			if err := grpcServer.Serve(listener); err != nil {
+				failed <- err
+			}
+		}()
+	}
+	if httpServer == nil && grpcServer == nil {
+		return errors.New("nothing to serve: set -listen or -grpc-listen")
+	}
+
+	select {
+	case err := <-failed:
+		return err
+	case <-ctx.Done():
+	}
+	logger.Info("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if front != nil {
+		_ = front.Shutdown(shutdownCtx)
+		if diagnosticFront != nil {
+			_ = diagnosticFront.Shutdown(shutdownCtx)
+		}
+		_ = httpServer.Shutdown(shutdownCtx)
+	}
+	if service != nil {
+		_ = service.RunManager().Shutdown(shutdownCtx)
+		grpcServer.GracefulStop()
+	}
+	return nil
+}
+
+// newUpstream creates the agent forwarding runs to the upstream at target
+func newUpstream(target, apiKey string, config gateway.Config) (server.Agent, func(), error) {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return nil, nil, fmt.Errorf("invalid -upstream %q", target)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		client := sse.NewClient(sse.Config{Endpoint: target, APIKey: apiKey})
+		return gateway.NewSSEAgent(client, config), func() { _ = client.Close() }, nil
+	case "grpc", "grpcs":
+		creds := insecure.NewCredentials()
+		if u.Scheme == "grpcs" {
+			creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+		}
+		conn, err := grpc.NewClient(u.Host, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return nil, nil, err
+		}
+		if apiKey != "" {
+			config.Headers = withHeader(config.Headers, "authorization", "Bearer "+apiKey)
+		}
+		return gateway.NewGRPCAgent(pb.NewAgentServiceClient(conn), config), func() { _ = conn.Close() }, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported upstream scheme %q", u.Scheme)
+	}
+}
+
+// withHeader adds a constant header to those of headers
+func withHeader(headers func(context.Context, *types.RunAgentInput) map[string]string, key, value string) func(context.Context, *types.RunAgentInput) map[string]string {
+	return func(ctx context.Context, input *types.RunAgentInput) map[string]string {
+		result := map[string]string{key: value}
+		if headers != nil {
+			for k, v := range headers(ctx, input) {
+				result[k] = v
+			}
+		}
+		return result
+	}
+}
+
+// parseKeys parses comma-separated key=principal pairs
+func parseKeys(value string) (map[string]string, error) {
+	keys := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, principal, ok := strings.Cut(pair, "=")
+		if !ok || key == "" || principal == "" {
+			return nil, fmt.Errorf("invalid $%s entry %q, want key=principal", keysEnv, pair)
+		}
+		keys[key] = principal
+	}
+	return keys, nil
+}
@@ -0,0 +1,61 @@
+// Command agui-load generates load against an AG-UI server and reports the
+// latency, throughput and errors it sees. See package loadtest.
+//
+//	agui-load -endpoint http://localhost:8080/agentic -streams 50 -rate 20 -duration 1m
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/loadtest"
+)
+
+// apiKeyEnv holds the API key when -api-key is not given, to keep it off
+// the command line
+const apiKeyEnv = "AG_UI_API_KEY"
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "agui-load:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var config loadtest.Config
+	flag.StringVar(&config.Client.Endpoint, "endpoint", "http://localhost:8080/agentic", "agent endpoint")
+	flag.StringVar(&config.Client.APIKey, "api-key", os.Getenv(apiKeyEnv), "API key, defaults to $"+apiKeyEnv)
+	flag.StringVar(&config.Client.AuthHeader, "auth-header", "", "header carrying the API key (defaults to Authorization)")
+	flag.IntVar(&config.Streams, "streams", 10, "number of streams open at once")
+	flag.Float64Var(&config.Rate, "rate", 0, "runs started per second, 0 to start one whenever a stream is free")
+	flag.IntVar(&config.Runs, "runs", 0, "number of runs to start, 0 for no limit")
+	flag.DurationVar(&config.Duration, "duration", 0, "how long to start runs, 0 for no limit")
+	flag.DurationVar(&config.RunTimeout, "timeout", 0, "timeout of each run, 0 for none")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+	if config.Runs == 0 && config.Duration == 0 {
+		config.Runs = 100
+	}
+
+	// Interrupting cancels the runs in flight and reports
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	report, err := loadtest.Run(ctx, config)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	fmt.Print(report)
+	return nil
+}
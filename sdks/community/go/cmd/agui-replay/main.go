@@ -0,0 +1,130 @@
+// Command agui-replay serves archived runs (see package archive) over HTTP and
+// Server-Sent Events as if they were live. A run request replays the archive
+// of its thread and run IDs.
+//
+//	agui-replay -dir /var/lib/agui/archive
+//	agui-replay -s3-bucket audit -prefix runs -speed 4 -max-delay 2s
+//
+// Callers are authenticated with the API keys of $AG_UI_REPLAY_KEYS, a
+// comma-separated list of key=principal pairs sent in X-API-Key; without
+// keys the server is open.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/archive"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server"
+)
+
+const (
+	// keysEnv holds the API keys of callers, as key=principal pairs
+	keysEnv = "AG_UI_REPLAY_KEYS"
+
+	// shutdownTimeout bounds how long replays may drain on shutdown
+	shutdownTimeout = 30 * time.Second
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "agui-replay:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	listen := flag.String("listen", ":8080", "address to serve on")
+	dir := flag.String("dir", "", "directory of the archives")
+	s3Bucket := flag.String("s3-bucket", "", "S3 bucket of the archives")
+	s3Region := flag.String("s3-region", "", "region of the S3 bucket (defaults to $AWS_REGION)")
+	s3Endpoint := flag.String("s3-endpoint", "", "endpoint of an S3 compatible service")
+	gcsBucket := flag.String("gcs-bucket", "", "Cloud Storage bucket of the archives")
+	prefix := flag.String("prefix", "", "prefix of the keys of archives")
+	speed := flag.Float64("speed", 1, "replay speed relative to the original timing")
+	instant := flag.Bool("instant", false, "replay without delays")
+	maxDelay := flag.Duration("max-delay", 0, "maximum wait between two events, 0 for none")
+	snapshots := flag.Bool("snapshots", false, "start replays with snapshots of the archived messages and state")
+	flag.Parse()
+
+	var source archive.Source
+	switch {
+	case *dir != "" && *s3Bucket == "" && *gcsBucket == "":
+		source = archive.NewFileSink(*dir)
+	case *s3Bucket != "" && *dir == "" && *gcsBucket == "":
+		source = archive.NewS3Sink(archive.S3Config{Bucket: *s3Bucket, Region: *s3Region, Endpoint: *s3Endpoint})
+	case *gcsBucket != "" && *dir == "" && *s3Bucket == "":
+		source = archive.NewGCSSink(archive.GCSConfig{Bucket: *gcsBucket})
+	default:
+		return errors.New("set exactly one of -dir, -s3-bucket and -gcs-bucket")
+	}
+	if *speed <= 0 {
+		return fmt.Errorf("invalid -speed %v", *speed)
+	}
+	keys, err := parseKeys(os.Getenv(keysEnv))
+	if err != nil {
+		return err
+	}
+
+	logger := slog.Default()
+	agent := archive.NewReplayAgent(source, archive.ReplayConfig{
+		Prefix:    *prefix,
+		Speed:     *speed,
+		Instant:   *instant,
+		MaxDelay:  *maxDelay,
+		Snapshots: *snapshots,
+	})
+	replay := server.NewServer(agent, server.Config{RunManagerConfig: server.RunManagerConfig{Logger: logger}})
+	middleware := server.MiddlewareConfig{Logger: logger}
+	if len(keys) > 0 {
+		middleware.Authenticator = server.NewAPIKeyAuthenticator("X-API-Key", keys)
+	}
+	httpServer := &http.Server{Addr: *listen, Handler: server.Chain(replay, server.NewMiddleware(middleware)...)}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	failed := make(chan error, 1)
+	go func() {
+		logger.Info("Serving archived runs", "addr", *listen)
+		if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			failed <- err
+		}
+	}()
+
+	select {
+	case err := <-failed:
+		return err
+	case <-ctx.Done():
+	}
+	logger.Info("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	_ = replay.Shutdown(shutdownCtx)
+	return httpServer.Shutdown(shutdownCtx)
+}
+
+// parseKeys parses comma-separated key=principal pairs
+func parseKeys(value string) (map[string]string, error) {
+	keys := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, principal, ok := strings.Cut(pair, "=")
+		if !ok || key == "" || principal == "" {
+			return nil, fmt.Errorf("invalid $%s entry %q, want key=principal", keysEnv, pair)
+		}
+		keys[key] = principal
+	}
+	return keys, nil
+}
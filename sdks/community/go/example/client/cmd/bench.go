@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/agent"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/bench"
+)
+
+// runBench benchmarks an endpoint and prints the report
+func runBench(ctx context.Context, args []string) error {
+	cfg, err := loadProfile()
+	if err != nil {
+		return err
+	}
+
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	endpoint := flags.String("endpoint", defaultEndpoint(cfg), "agent endpoint")
+	runs := flags.Int("runs", 20, "total number of runs")
+	concurrency := flags.Int("concurrency", 4, "number of runs in flight at once")
+	message := flags.String("message", "Hello! Tell me about yourself.", "user message sent by each run")
+	timeout := flags.Duration("timeout", 2*time.Minute, "timeout of each run, 0 for none")
+	format := flags.String("output", "markdown", "report format: markdown or json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *format != "markdown" && *format != formatJSON {
+		return fmt.Errorf("invalid --output %q", *format)
+	}
+
+	apiKey, _, err := cfg.LookupAPIKey()
+	if err != nil {
+		return err
+	}
+	report, err := bench.Run(ctx, bench.Config{
+		Endpoint:    agent.Endpoint{URL: *endpoint, APIKey: apiKey},
+		Runs:        *runs,
+		Concurrency: *concurrency,
+		Message:     *message,
+		Timeout:     *timeout,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *format == formatJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	fmt.Print(report.Markdown())
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/agent"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/notify"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/google/uuid"
+)
+
+type chatOptions struct {
+	endpoint  agent.Endpoint
+	approvals agent.Endpoint
+	notify    *notify.Notifier
+}
+
+func runChat(ctx context.Context, args []string) error {
+	cfg, err := loadProfile()
+	if err != nil {
+		return err
+	}
+
+	flags := flag.NewFlagSet("chat", flag.ContinueOnError)
+	tui := flags.Bool("tui", false, "full-screen chat UI")
+	parseTransport := transportFlags(flags, cfg)
+	approvals := flags.String("approvals", cfg.Approvals, "approvals API of the server, enables approving tool calls")
+	notifyEnd := flags.Bool("notify", false, "notify when a run ends, with a desktop notification or the notify-hook command")
+	parseOutput := outputFlags(flags, cfg.Output)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	out, err := parseOutput()
+	if err != nil {
+		return err
+	}
+	transport, err := parseTransport()
+	if err != nil {
+		return err
+	}
+
+	opts := chatOptions{
+		endpoint:  transport.endpoint,
+		approvals: agent.Endpoint{URL: *approvals, APIKey: transport.endpoint.APIKey},
+	}
+	if *notifyEnd {
+		hook, err := cfg.Get("notify-hook")
+		if err != nil {
+			return err
+		}
+		opts.notify = &notify.Notifier{Hook: hook, Endpoint: transport.endpoint.URL}
+	}
+	if *tui {
+		if !out.isDefault() {
+			return errors.New("--filter, --select and --output only apply without --tui")
+		}
+		if transport.debug {
+			return errors.New("--debug only applies without --tui")
+		}
+		return runTUI(ctx, opts)
+	}
+	return runPlain(ctx, opts, out)
+}
+
+// runPlain reads messages from stdin and prints each event of the response on its own line
+func runPlain(ctx context.Context, opts chatOptions, out *output) error {
+	threadID := "thread-" + uuid.NewString()
+	var history []types.Message
+
+	scanner := bufio.NewScanner(os.Stdin)
+	fmt.Print("> ")
+	for scanner.Scan() {
+		text := scanner.Text()
+		if text == "" {
+			fmt.Print("> ")
+			continue
+		}
+		history = append(history, types.Message{ID: "msg-" + uuid.NewString(), Role: types.RoleUser, Content: text})
+
+		err := agent.Chat(ctx, agent.NewInput(threadID, history, nil), opts.endpoint, func(event events.Event) {
+			if snapshot, ok := event.(*events.MessagesSnapshotEvent); ok {
+				history = snapshot.Messages
+			}
+			out.print(event, nil)
+			if err := opts.notify.Observe(ctx, event); err != nil {
+				fmt.Fprintln(os.Stderr, "warning:", err)
+			}
+		})
+		out.flush()
+		if err != nil {
+			return err
+		}
+		fmt.Print("> ")
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/agent"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/diff"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/ui"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/uuid"
+	"golang.org/x/term"
+)
+
+const compareUsage = `usage: client compare (--endpoint URL ... | --profiles NAME,NAME...) [--diff] [MESSAGE]`
+
+// compareTarget is one of the servers a prompt is sent to
+type compareTarget struct {
+	name     string
+	endpoint agent.Endpoint
+}
+
+// runCompare sends one prompt to several servers at once and shows their
+// responses side by side
+func runCompare(ctx context.Context, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	flags := flag.NewFlagSet("compare", flag.ContinueOnError)
+	var endpoints []string
+	flags.Func("endpoint", "agent endpoint to compare, repeat for each server", func(url string) error {
+		endpoints = append(endpoints, url)
+		return nil
+	})
+	profiles := flags.String("profiles", "", "comma separated config profiles whose endpoints to compare")
+	showDiff := flags.Bool("diff", false, "show the differences from the first response instead of the responses")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var targets []compareTarget
+	for _, name := range splitList(*profiles) {
+		p, err := cfg.Profile(name)
+		if err != nil {
+			return err
+		}
+		apiKey, _, err := p.LookupAPIKey()
+		if err != nil {
+			return err
+		}
+		targets = append(targets, compareTarget{name: p.Name, endpoint: agent.Endpoint{URL: defaultEndpoint(p), APIKey: apiKey}})
+	}
+	if len(endpoints) > 0 {
+		p, err := cfg.Profile(profile)
+		if err != nil {
+			return err
+		}
+		apiKey, _, err := p.LookupAPIKey()
+		if err != nil {
+			return err
+		}
+		for _, url := range endpoints {
+			targets = append(targets, compareTarget{name: url, endpoint: agent.Endpoint{URL: url, APIKey: apiKey}})
+		}
+	}
+	if len(targets) < 2 {
+		return errors.New(compareUsage)
+	}
+
+	prompt := strings.Join(flags.Args(), " ")
+	if prompt == "" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		prompt = strings.TrimSpace(string(data))
+	}
+	if prompt == "" {
+		return errors.New("no message to send")
+	}
+
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		return comparePlain(ctx, targets, prompt, *showDiff)
+	}
+
+	names := make([]string, len(targets))
+	for i, t := range targets {
+		names[i] = t.name
+	}
+	p := tea.NewProgram(ui.NewCompareModel(prompt, names, *showDiff), tea.WithAltScreen())
+	fanOut(ctx, targets, prompt, func(i int, event events.Event) {
+		p.Send(ui.CompareEventMsg{Index: i, Event: event})
+	}, func(i int, err error) {
+		p.Send(ui.CompareDoneMsg{Index: i, Err: err})
+	})
+	_, err = p.Run()
+	return err
+}
+
+// fanOut runs the prompt against every target concurrently. It returns
+// at once, send and done are called from the goroutines of the runs.
+func fanOut(ctx context.Context, targets []compareTarget, prompt string, send func(int, events.Event), done func(int, error)) {
+	threadID := "compare-" + uuid.NewString()
+	messages := []types.Message{{ID: "msg-" + uuid.NewString(), Role: types.RoleUser, Content: prompt}}
+	for i, t := range targets {
+		go func() {
+			err := agent.Chat(ctx, agent.NewInput(threadID, messages, nil), t.endpoint, func(event events.Event) {
+				send(i, event)
+			})
+			done(i, err)
+		}()
+	}
+}
+
+// comparePlain waits for all responses and prints them one after the other,
+// for when the output is not a terminal
+func comparePlain(ctx context.Context, targets []compareTarget, prompt string, showDiff bool) error {
+	type response struct {
+		result     runResult
+		firstEvent time.Duration
+		duration   time.Duration
+		err        error
+	}
+	responses := make([]response, len(targets))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(len(targets))
+	start := time.Now()
+	fanOut(ctx, targets, prompt, func(i int, event events.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		if responses[i].result.Events == 0 {
+			responses[i].firstEvent = time.Since(start)
+		}
+		responses[i].result.apply(event)
+	}, func(i int, err error) {
+		mu.Lock()
+		responses[i].duration, responses[i].err = time.Since(start), err
+		mu.Unlock()
+		wg.Done()
+	})
+	wg.Wait()
+
+	for i, r := range responses {
+		status := fmt.Sprintf("%s, first event %s", r.duration.Round(time.Millisecond), r.firstEvent.Round(time.Millisecond))
+		if r.err != nil {
+			status = "error: " + r.err.Error()
+		}
+		fmt.Printf("== %s (%s) ==\n%s\n\n", targets[i].name, status, r.result.Text)
+	}
+	if showDiff {
+		for i := 1; i < len(responses); i++ {
+			fmt.Printf("== diff %s -> %s ==\n", targets[0].name, targets[i].name)
+			for _, op := range diff.Words(responses[0].result.Text, responses[i].result.Text) {
+				switch op.Kind {
+				case diff.Insert:
+					fmt.Printf("{+%s+}", op.Text)
+				case diff.Delete:
+					fmt.Printf("[-%s-]", op.Text)
+				default:
+					fmt.Print(op.Text)
+				}
+			}
+			fmt.Print("\n\n")
+		}
+	}
+	return nil
+}
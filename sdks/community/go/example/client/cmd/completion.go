@@ -0,0 +1,320 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/config"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/plugin"
+)
+
+const completionUsage = `usage: client completion bash|zsh|fish|powershell`
+
+// completeCommand is the hidden command the completion scripts call. Its
+// arguments are the words typed after the program name up to the cursor,
+// the last one being the word under completion. It prints the candidates
+// one per line; printing none lets the shell complete file names.
+const completeCommand = "__complete"
+
+// The completion scripts are formatted with the program name and a version
+// of it usable as a shell identifier
+var completionScripts = map[string]string{
+	"bash": `_%[2]s_complete() {
+	local IFS=$'\n'
+	COMPREPLY=($(%[1]s __complete "${COMP_WORDS[@]:1:$COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F _%[2]s_complete %[1]s
+`,
+	"zsh": `#compdef %[1]s
+_%[2]s_complete() {
+	local -a candidates
+	candidates=(${(f)"$(%[1]s __complete "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+	if (( ${#candidates} )); then
+		compadd -Q -- "${candidates[@]}"
+	else
+		_files
+	fi
+}
+compdef _%[2]s_complete %[1]s
+`,
+	"fish": `function __%[2]s_complete
+	%[1]s __complete (commandline -opc)[2..-1] (commandline -ct) 2>/dev/null
+end
+complete -c %[1]s -n 'test -n "$(__%[2]s_complete)"' -f -a '(__%[2]s_complete)'
+`,
+	"powershell": `Register-ArgumentCompleter -Native -CommandName '%[1]s' -ScriptBlock {
+	param($wordToComplete, $commandAst, $cursorPosition)
+	$words = @($commandAst.CommandElements | Select-Object -Skip 1 |
+		Where-Object { $_.Extent.EndOffset -le $cursorPosition } |
+		ForEach-Object { $_.ToString() })
+	if ($wordToComplete -eq '') {
+		# Empty arguments are dropped when calling native commands
+		$words += '""'
+	}
+	& '%[1]s' __complete @words 2>$null | ForEach-Object {
+		[System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+	}
+}
+`,
+}
+
+// commandFlags are the flags of each command and subcommand
+var commandFlags = map[string][]string{
+	"chat":           append([]string{"--tui", "--approvals", "--notify"}, append(transportFlagNames, outputFlagNames...)...),
+	"stream":         append([]string{"--record", "--notify"}, append(transportFlagNames, outputFlagNames...)...),
+	"replay":         append([]string{"--speed"}, outputFlagNames...),
+	"compare":        {"--endpoint", "--profiles", "--diff"},
+	"bench":          {"--endpoint", "--runs", "--concurrency", "--message", "--timeout", "--output"},
+	"init":           {"--no-check"},
+	"doctor":         {"--endpoint", "--run", "--connectivity", "--timeout"},
+	"run cancel":     {"--endpoint"},
+	"session list":   {"--dir", "--search", "--label", "--tool", "--since", "--until", "--limit", "--output", "--template"},
+	"session export": {"--dir", "--format", "--out"},
+	"session share":  {"--dir", "--encrypt", "--out"},
+	"session import": {"--dir", "--verify", "--signer", "--id", "--force"},
+}
+
+// boolFlags are the flags that take no value
+var boolFlags = map[string]bool{"--tui": true, "--diff": true, "--notify": true, "--debug": true,
+	"--encrypt": true, "--verify": true, "--force": true, "--no-check": true, "--run": true}
+
+var (
+	transportFlagNames = []string{"--endpoint", "--wire-format", "--grpc", "--debug"}
+	outputFlagNames    = []string{"--filter", "--select", "--output", "--template", "--per"}
+)
+
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// runCompletion prints the completion script for a shell
+func runCompletion(args []string) error {
+	if len(args) != 1 {
+		return errors.New(completionUsage)
+	}
+	script, ok := completionScripts[args[0]]
+	if !ok {
+		return fmt.Errorf("unsupported shell %q\n%s", args[0], completionUsage)
+	}
+	name := filepath.Base(os.Args[0])
+	fmt.Printf(script, name, nonIdentifier.ReplaceAllString(name, "_"))
+	return nil
+}
+
+func runComplete(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	current := args[len(args)-1]
+	if current == `""` {
+		current = ""
+	}
+	for _, candidate := range complete(ctx, args[:len(args)-1], current) {
+		fmt.Println(candidate)
+	}
+	return nil
+}
+
+// complete returns the candidates for the word current that follows words
+func complete(ctx context.Context, words []string, current string) []string {
+	// Skip the global flags, completing the value of --profile
+	for len(words) > 0 && (words[0] == "--profile" || words[0] == "-profile" || strings.HasPrefix(words[0], "--profile=")) {
+		if strings.Contains(words[0], "=") {
+			words = words[1:]
+			continue
+		}
+		if len(words) == 1 {
+			return matching(profileNames(), current)
+		}
+		profile, words = words[1], words[2:]
+	}
+	if len(words) == 0 {
+		if strings.HasPrefix(current, "-") {
+			return matching([]string{"--profile"}, current)
+		}
+		return matching(commandNames(), current)
+	}
+
+	command, args := words[0], words[1:]
+	if (command == "session" || command == "run") && len(args) > 0 {
+		command, args = command+" "+args[0], args[1:]
+	}
+	if n := len(args); n > 0 && strings.HasPrefix(args[n-1], "-") && !strings.Contains(args[n-1], "=") && !boolFlags[args[n-1]] {
+		values, list := flagValues(ctx, command, args, args[n-1])
+		if list {
+			return matchingList(values, current)
+		}
+		return matching(values, current)
+	}
+	if strings.HasPrefix(current, "-") {
+		return matching(commandFlags[command], current)
+	}
+
+	switch command {
+	case "config":
+		return matching(configArgs(args), current)
+	case "session":
+		return matching([]string{"list", "export", "share", "import", "key"}, current)
+	case "run":
+		return matching([]string{"cancel"}, current)
+	case "session export", "session share":
+		return matching(sessionIDs(ctx, flagValue(args, "dir")), current)
+	case "completion":
+		if len(args) == 0 {
+			return matching(sortedKeys(completionScripts), current)
+		}
+	}
+	return nil
+}
+
+// flagValues returns the values of flag, or nil to complete file names.
+// list reports whether the flag takes a comma separated list.
+func flagValues(ctx context.Context, command string, args []string, flag string) (values []string, list bool) {
+	switch strings.TrimLeft(flag, "-") {
+	case "profiles":
+		return profileNames(), true
+	case "tool":
+		return sessionTools(ctx, flagValue(args, "dir")), true
+	case "output":
+		if command == "bench" {
+			return []string{"markdown", "json"}, false
+		}
+		return []string{formatText, formatJSON, formatTemplate}, false
+	case "wire-format":
+		return []string{"json", "protobuf"}, false
+	case "per":
+		return []string{"event", "result"}, false
+	case "format":
+		return []string{"markdown", "html"}, false
+	}
+	return nil, false
+}
+
+// configArgs completes the arguments of the config command
+func configArgs(args []string) []string {
+	switch {
+	case len(args) == 0:
+		return []string{"show", "get", "set", "unset", "set-secret", "unset-secret", "encrypt", "decrypt", "profiles"}
+	case len(args) == 1 && (args[0] == "get" || args[0] == "set" || args[0] == "unset"):
+		return config.Keys()
+	case len(args) == 1 && (args[0] == "set-secret" || args[0] == "unset-secret"):
+		return []string{config.APIKeyName}
+	case len(args) == 1 && args[0] == "profiles":
+		return []string{"diff"}
+	case len(args) >= 2 && len(args) <= 3 && args[0] == "profiles" && args[1] == "diff":
+		return profileNames()
+	}
+	return nil
+}
+
+func commandNames() []string {
+	names := []string{"chat", "stream", "replay", "compare", "bench", "init", "doctor", "run", "session", "config", "plugins", "completion"}
+	if plugins, err := plugin.List(pluginDir()); err == nil {
+		for _, p := range plugins {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+func profileNames() []string {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil
+	}
+	return cfg.ProfileNames()
+}
+
+func sessionIDs(ctx context.Context, dir string) []string {
+	store, err := openSessionStore(dir)
+	if err != nil {
+		return nil
+	}
+	ids, _ := store.List(ctx)
+	return ids
+}
+
+// sessionTools returns the names of the tools called in the stored sessions
+func sessionTools(ctx context.Context, dir string) []string {
+	store, err := openSessionStore(dir)
+	if err != nil {
+		return nil
+	}
+	ids, _ := store.List(ctx)
+	seen := make(map[string]bool)
+	var tools []string
+	for _, id := range ids {
+		s, err := store.Get(ctx, id)
+		if err != nil {
+			continue
+		}
+		for _, message := range s.Messages {
+			for _, call := range message.ToolCalls {
+				if name := call.Function.Name; name != "" && !seen[name] {
+					seen[name] = true
+					tools = append(tools, name)
+				}
+			}
+		}
+	}
+	sort.Strings(tools)
+	return tools
+}
+
+// flagValue returns the value given to a flag in args, or the default of
+// the flags that have one
+func flagValue(args []string, name string) string {
+	value := ""
+	for i, arg := range args {
+		flag, v, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || flag != name {
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			v = args[i+1]
+		}
+		value = v
+	}
+	if value == "" && name == "dir" {
+		return defaultSessionDir()
+	}
+	return value
+}
+
+func matching(candidates []string, prefix string) []string {
+	var matches []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			matches = append(matches, c)
+		}
+	}
+	return matches
+}
+
+// matchingList completes the last item of a comma separated list, leaving
+// out the items already in it
+func matchingList(candidates []string, current string) []string {
+	i := strings.LastIndex(current, ",") + 1
+	done := strings.Split(current[:i], ",")
+	var matches []string
+	for _, c := range matching(candidates, current[i:]) {
+		if !slices.Contains(done, c) {
+			matches = append(matches, current[:i]+c)
+		}
+	}
+	return matches
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/config"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/plugin"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/session"
+)
+
+// setupCompletion creates a config with a prod profile, a plugin named
+// deploy and a session store holding two sessions
+func setupCompletion(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv(configEnv, filepath.Join(dir, "config.json"))
+	t.Setenv(sessionDirEnv, filepath.Join(dir, "sessions"))
+	t.Setenv(sessionKeysEnv, "")
+	t.Setenv("PATH", t.TempDir())
+	selected := profile
+	t.Cleanup(func() { profile = selected })
+
+	cfg := &config.Config{}
+	_, err := cfg.Own("prod", true)
+	require.NoError(t, err)
+	require.NoError(t, cfg.Save(configPath()))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(pluginDir(), "deploy"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(pluginDir(), "deploy", plugin.ManifestFile), []byte(`{"exec":"run"}`), 0o644))
+
+	store, err := openSessionStore(defaultSessionDir())
+	require.NoError(t, err)
+	defer store.Close()
+	ctx := context.Background()
+	for id, tools := range map[string][]string{"thread-1": {"get_weather", "search"}, "thread-2": {"search"}} {
+		message := types.Message{ID: "1", Role: types.RoleAssistant}
+		for _, tool := range tools {
+			message.ToolCalls = append(message.ToolCalls, types.ToolCall{ID: tool, Type: "function", Function: types.FunctionCall{Name: tool}})
+		}
+		require.NoError(t, store.Save(ctx, &session.Session{ID: id, Messages: []types.Message{message}}))
+	}
+}
+
+func TestComplete(t *testing.T) {
+	setupCompletion(t)
+
+	tests := []struct {
+		name    string
+		words   []string
+		current string
+		want    []string
+	}{
+		{name: "commands", current: "c", want: []string{"chat", "compare", "config", "completion"}},
+		{name: "plugins", current: "dep", want: []string{"deploy"}},
+		{name: "global flags", current: "--p", want: []string{"--profile"}},
+		{name: "profiles", words: []string{"--profile"}, current: "", want: []string{config.DefaultProfile, "prod"}},
+		{name: "after --profile", words: []string{"--profile", "prod"}, current: "st", want: []string{"stream"}},
+		{name: "after --profile=", words: []string{"--profile=prod"}, current: "rep", want: []string{"replay"}},
+		{name: "command flags", words: []string{"chat"}, current: "--t", want: []string{"--tui", "--template"}},
+		{name: "subcommand flags", words: []string{"session", "export"}, current: "--f", want: []string{"--format"}},
+		{name: "flag values", words: []string{"session", "export", "--format"}, current: "", want: []string{"markdown", "html"}},
+		{name: "output of bench", words: []string{"bench", "--output"}, current: "", want: []string{"markdown", "json"}},
+		{name: "output", words: []string{"stream", "--output"}, current: "t", want: []string{formatText, formatTemplate}},
+		{name: "wire format", words: []string{"chat", "--wire-format"}, current: "p", want: []string{"protobuf"}},
+		{name: "file names", words: []string{"stream", "--record"}, current: "", want: nil},
+		{name: "after a bool flag", words: []string{"chat", "--tui"}, current: "--a", want: []string{"--approvals"}},
+		{name: "after a flag with =", words: []string{"session", "export", "--format=html"}, current: "thread-", want: []string{"thread-1", "thread-2"}},
+		{name: "profile list", words: []string{"compare", "--profiles"}, current: "", want: []string{config.DefaultProfile, "prod"}},
+		{name: "profile list item", words: []string{"compare", "--profiles"}, current: "prod,", want: []string{"prod," + config.DefaultProfile}},
+		{name: "tools", words: []string{"session", "list", "--tool"}, current: "", want: []string{"get_weather", "search"}},
+		{name: "tool list item", words: []string{"session", "list", "--tool"}, current: "search,g", want: []string{"search,get_weather"}},
+		{name: "session subcommands", words: []string{"session"}, current: "", want: []string{"list", "export", "share", "import", "key"}},
+		{name: "session IDs", words: []string{"session", "share"}, current: "thread-2", want: []string{"thread-2"}},
+		{name: "session IDs in another directory", words: []string{"session", "export", "--dir", t.TempDir()}, current: "", want: nil},
+		{name: "run subcommands", words: []string{"run"}, current: "", want: []string{"cancel"}},
+		{name: "config subcommands", words: []string{"config"}, current: "un", want: []string{"unset", "unset-secret"}},
+		{name: "config keys", words: []string{"config", "get"}, current: "e", want: []string{"endpoint"}},
+		{name: "secret names", words: []string{"config", "set-secret"}, current: "", want: []string{config.APIKeyName}},
+		{name: "profiles to diff", words: []string{"config", "profiles", "diff"}, current: "p", want: []string{"prod"}},
+		{name: "second profile to diff", words: []string{"config", "profiles", "diff", "prod"}, current: "", want: []string{config.DefaultProfile, "prod"}},
+		{name: "no third profile to diff", words: []string{"config", "profiles", "diff", "prod", "default"}, current: "", want: nil},
+		{name: "shells", words: []string{"completion"}, current: "", want: []string{"bash", "fish", "powershell", "zsh"}},
+		{name: "one shell", words: []string{"completion", "bash"}, current: "", want: nil},
+		{name: "free arguments", words: []string{"stream"}, current: "", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, complete(context.Background(), tt.words, tt.current))
+		})
+	}
+}
+
+func TestCompleteWithoutConfig(t *testing.T) {
+	t.Setenv(configEnv, filepath.Join(t.TempDir(), "config.json"))
+	t.Setenv(sessionDirEnv, filepath.Join(t.TempDir(), "missing"))
+	t.Setenv("PATH", t.TempDir())
+
+	assert.Equal(t, []string{config.DefaultProfile}, complete(context.Background(), []string{"--profile"}, ""))
+	assert.Nil(t, complete(context.Background(), []string{"session", "list", "--tool"}, ""))
+	assert.Equal(t, []string{"plugins"}, complete(context.Background(), nil, "pl"), "only the built in commands")
+}
+
+func TestRunComplete(t *testing.T) {
+	setupCompletion(t)
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "no arguments", want: "chat\nstream\nreplay\ncompare\nbench\ninit\ndoctor\nrun\nsession\nconfig\nplugins\ncompletion\ndeploy\n"},
+		{name: "empty word from PowerShell", args: []string{"run", `""`}, want: "cancel\n"},
+		{name: "no candidates", args: []string{"stream", ""}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := captureStdout(t, func() {
+				require.NoError(t, runComplete(context.Background(), tt.args))
+			})
+			assert.Equal(t, tt.want, out)
+		})
+	}
+}
+
+func TestRunCompletion(t *testing.T) {
+	args := os.Args
+	os.Args = []string{"/usr/local/bin/ag-ui-client"}
+	defer func() { os.Args = args }()
+
+	tests := map[string]string{
+		"bash":       "complete -o default -F _ag_ui_client_complete ag-ui-client\n",
+		"zsh":        "compdef _ag_ui_client_complete ag-ui-client\n",
+		"fish":       "complete -c ag-ui-client -n 'test -n \"$(__ag_ui_client_complete)\"'",
+		"powershell": "& 'ag-ui-client' __complete @words",
+	}
+	require.Len(t, tests, len(completionScripts))
+	for shell, want := range tests {
+		t.Run(shell, func(t *testing.T) {
+			script := captureStdout(t, func() {
+				require.NoError(t, runCompletion([]string{shell}))
+			})
+			assert.Contains(t, script, want, "functions are named with a shell identifier")
+			assert.NotContains(t, script, "%!", "the script is fully formatted")
+		})
+	}
+
+	assert.EqualError(t, runCompletion(nil), completionUsage)
+	assert.EqualError(t, runCompletion([]string{"tcsh"}), "unsupported shell \"tcsh\"\n"+completionUsage)
+}
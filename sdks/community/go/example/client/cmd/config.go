@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/agent"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/config"
+	"golang.org/x/term"
+)
+
+const configUsage = `usage:
+  client [--profile NAME] config show
+  client [--profile NAME] config get KEY
+  client [--profile NAME] config set KEY VALUE
+  client [--profile NAME] config unset KEY
+  client [--profile NAME] config set-secret [api-key]     reads the secret from stdin
+  client [--profile NAME] config unset-secret [api-key]
+  client config encrypt     encrypts the api-key and notify-hook values in the file
+  client config decrypt
+  client config profiles
+  client config profiles diff PROFILE [PROFILE]
+
+keys: endpoint, approvals, api-key, output, notify-hook`
+
+const (
+	// configEnv overrides the path of the config file
+	configEnv = "AG_UI_CONFIG"
+
+	// profileEnv selects the config profile when --profile is not given
+	profileEnv = "AGUI_PROFILE"
+)
+
+// profile is the config profile selected with --profile or AGUI_PROFILE
+var profile = os.Getenv(profileEnv)
+
+func configPath() string {
+	if path := os.Getenv(configEnv); path != "" {
+		return path
+	}
+	return config.DefaultPath()
+}
+
+func loadConfig() (*config.Config, error) {
+	return config.Load(configPath())
+}
+
+// loadProfile returns the settings of the selected profile
+func loadProfile() (*config.Profile, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Profile(profile)
+}
+
+func defaultEndpoint(p *config.Profile) string {
+	if p.Endpoint != "" {
+		return p.Endpoint
+	}
+	return agent.DefaultEndpoint()
+}
+
+func runConfig(args []string) error {
+	if len(args) == 0 {
+		return errors.New(configUsage)
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	switch cmd, args := args[0], args[1:]; {
+	case cmd == "show" && len(args) == 0:
+		p, err := cfg.Profile(profile)
+		if err != nil {
+			return err
+		}
+		fmt.Println("profile:", p.Name)
+		for _, key := range config.Keys() {
+			value, err := p.Get(key)
+			switch {
+			case err != nil:
+				value = fmt.Sprintf("<%v>", err)
+			case key == config.APIKeyName && value != "":
+				value = mask(value)
+			}
+			if p.Inherited(key) {
+				value += " (from " + config.DefaultProfile + ")"
+			}
+			fmt.Printf("%s = %s\n", key, value)
+		}
+		if _, source, err := p.LookupAPIKey(); err != nil {
+			fmt.Printf("API key in use from the %s: %v\n", source, err)
+		} else if source != config.SourceNone {
+			fmt.Printf("API key in use from the %s\n", source)
+		} else {
+			fmt.Println("no API key")
+		}
+		if cfg.Encrypted() {
+			fmt.Println("sensitive values are encrypted in", configPath())
+		}
+		return nil
+
+	case cmd == "get" && len(args) == 1:
+		p, err := cfg.Profile(profile)
+		if err != nil {
+			return err
+		}
+		value, err := p.Get(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+
+	case cmd == "set" && len(args) == 2:
+		own, err := cfg.Own(profile, true)
+		if err != nil {
+			return err
+		}
+		if err := own.Set(args[0], args[1]); err != nil {
+			return err
+		}
+		if args[0] == config.APIKeyName && !cfg.Encrypted() {
+			fmt.Fprintln(os.Stderr, "warning: the API key is stored in plaintext, prefer client config set-secret")
+		}
+		return cfg.Save(configPath())
+
+	case cmd == "unset" && len(args) == 1:
+		own, err := cfg.Own(profile, false)
+		if err != nil {
+			return err
+		}
+		if err := own.Set(args[0], ""); err != nil {
+			return err
+		}
+		return cfg.Save(configPath())
+
+	case cmd == "set-secret" && len(args) <= 1:
+		name := secretName(args)
+		value, err := readSecret(name)
+		if err != nil {
+			return err
+		}
+		if err := config.SetSecret(profile, name, value); err != nil {
+			if errors.Is(err, config.ErrKeychainUnavailable) {
+				return fmt.Errorf("%w\nset %s in the environment instead, or store the key in plaintext with client config set %s", err, config.APIKeyEnv, name)
+			}
+			return err
+		}
+		// Drop the plaintext copy now that the keychain has it
+		if own, err := cfg.Own(profile, false); err == nil && own.APIKey != "" {
+			own.APIKey = ""
+			if err := cfg.Save(configPath()); err != nil {
+				return err
+			}
+			fmt.Fprintln(os.Stderr, "removed the plaintext API key from", configPath())
+		}
+		return nil
+
+	case cmd == "unset-secret" && len(args) <= 1:
+		return config.UnsetSecret(profile, secretName(args))
+
+	case cmd == "encrypt" && len(args) == 0:
+		if err := ensureMasterKey(); err != nil {
+			return err
+		}
+		cfg.SetEncrypted(true)
+		return cfg.Save(configPath())
+
+	case cmd == "decrypt" && len(args) == 0:
+		cfg.SetEncrypted(false)
+		if err := cfg.Save(configPath()); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, "warning: the sensitive values are stored in plaintext in", configPath())
+		return nil
+
+	case cmd == "profiles" && len(args) == 0:
+		active := profile
+		if active == "" {
+			active = config.DefaultProfile
+		}
+		for _, name := range cfg.ProfileNames() {
+			marker := " "
+			if name == active {
+				marker = "*"
+			}
+			p, _ := cfg.Profile(name)
+			fmt.Printf("%s %s\t%s\n", marker, name, p.Endpoint)
+		}
+		return nil
+
+	case cmd == "profiles" && len(args) >= 2 && len(args) <= 3 && args[0] == "diff":
+		other := config.DefaultProfile
+		if len(args) == 3 {
+			other = args[2]
+		}
+		return diffProfiles(cfg, args[1], other)
+
+	default:
+		return errors.New(configUsage)
+	}
+}
+
+// diffProfiles prints the effective settings that differ between two
+// profiles
+func diffProfiles(cfg *config.Config, a, b string) error {
+	pa, err := cfg.Profile(a)
+	if err != nil {
+		return err
+	}
+	pb, err := cfg.Profile(b)
+	if err != nil {
+		return err
+	}
+
+	same := true
+	for _, key := range config.Keys() {
+		va, _ := pa.Get(key)
+		vb, _ := pb.Get(key)
+		if va == vb {
+			continue
+		}
+		if key == config.APIKeyName {
+			va, vb = maskOrEmpty(va), maskOrEmpty(vb)
+		}
+		same = false
+		fmt.Printf("%s\n  - %s: %s\n  + %s: %s\n", key, pa.Name, va, pb.Name, vb)
+	}
+	if same {
+		fmt.Printf("%s and %s have the same settings\n", pa.Name, pb.Name)
+	}
+	return nil
+}
+
+// ensureMasterKey generates the master key of encrypted config files when
+// neither the environment nor the keychain has one. Without a keychain the
+// key is only set for this run, and the user has to export it.
+func ensureMasterKey() error {
+	_, source, err := config.MasterKey()
+	if err == nil {
+		fmt.Fprintln(os.Stderr, "encrypting with the master key from the", source)
+		return nil
+	}
+	if !errors.Is(err, config.ErrNoMasterKey) {
+		return err
+	}
+	key, err := config.NewMasterKey()
+	switch {
+	case errors.Is(err, config.ErrKeychainUnavailable):
+		fmt.Fprintf(os.Stderr, "%v\ngenerated a master key, export it to read the config file:\n  export %s=%s\n", err, config.MasterKeyEnv, key)
+		return os.Setenv(config.MasterKeyEnv, key)
+	case err != nil:
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "generated a master key and stored it in the keychain, set it on other machines with\n  export %s=%s\n", config.MasterKeyEnv, key)
+	return nil
+}
+
+func secretName(args []string) string {
+	if len(args) == 1 {
+		return args[0]
+	}
+	return config.APIKeyName
+}
+
+// readSecret reads a secret from the terminal without echoing it, or from
+// stdin when it is not a terminal
+func readSecret(name string) (string, error) {
+	var value string
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		fmt.Fprintf(os.Stderr, "%s: ", name)
+		data, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+		value = string(data)
+	} else {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		value = line
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("empty %s", name)
+	}
+	return value, nil
+}
+
+func maskOrEmpty(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return mask(secret)
+}
+
+// mask hides all but the last characters of a secret
+func mask(secret string) string {
+	if len(secret) <= 8 {
+		return "********"
+	}
+	return "********" + secret[len(secret)-4:]
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/agent"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/config"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/doctor"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+)
+
+// errDoctorFailed is returned once the failed checks have been printed
+var errDoctorFailed = errors.New("some checks failed, see the hints above")
+
+// runDoctor diagnoses the connection to the agent of the selected profile
+func runDoctor(ctx context.Context, args []string) error {
+	cfg, err := loadProfile()
+	if err != nil {
+		return err
+	}
+
+	flags := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	url := flags.String("endpoint", defaultEndpoint(cfg), "agent endpoint")
+	run := flags.Bool("run", false, "send a test message to check that events are streamed")
+	timeout := flags.Duration("timeout", 5*time.Second, "timeout of each check")
+	connectivity := flags.Bool("connectivity", false, "self-test the full path against a diagnostic endpoint and print its capability matrix")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errors.New("usage: client doctor [--endpoint URL] [--run] [--connectivity] [--timeout D]")
+	}
+
+	apiKey, source, err := cfg.LookupAPIKey()
+	fmt.Printf("config   %s, profile %s\n", configPath(), cfg.Name)
+	switch {
+	case err != nil:
+		fmt.Printf("api key  %v\n", err)
+	case source == config.SourceNone:
+		fmt.Println("api key  none")
+	default:
+		fmt.Printf("api key  %s from the %s\n", mask(apiKey), source)
+	}
+
+	endpoint := agent.Endpoint{URL: *url, APIKey: apiKey}
+	if !diagnose(ctx, os.Stdout, doctor.Options{Endpoint: endpoint, Run: *run, Timeout: *timeout}) {
+		return errDoctorFailed
+	}
+	if *connectivity {
+		fmt.Println()
+		report := doctor.SelfTest(ctx, endpoint, *timeout)
+		printMatrix(os.Stdout, report)
+		if !report.Passed() {
+			return errDoctorFailed
+		}
+	}
+	return nil
+}
+
+// printMatrix prints the capability matrix of a self-test
+func printMatrix(w io.Writer, report sse.SelfTestReport) {
+	fmt.Fprintf(w, "%-13s %-5s %s\n", "connectivity", "sse", "detail")
+	for _, result := range report.Results {
+		fmt.Fprintf(w, "%-13s %-5s %s\n", result.Check, result.Status, result.Detail)
+	}
+}
+
+// diagnose prints the result of each check with its hint, and reports
+// whether all checks passed
+func diagnose(ctx context.Context, w io.Writer, opts doctor.Options) bool {
+	return doctor.Run(ctx, opts, func(r doctor.Result) {
+		fmt.Fprintf(w, "%-4s %-9s %s\n", r.Status, r.Name, r.Detail)
+		if r.Hint != "" && r.Status != doctor.OK {
+			fmt.Fprintf(w, "     %-9s → %s\n", "", r.Hint)
+		}
+	})
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/agent"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/config"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/doctor"
+	"golang.org/x/term"
+)
+
+// runInit asks for the settings of the selected profile, checking that the
+// agent can be reached with them before saving
+func runInit(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("init", flag.ContinueOnError)
+	noCheck := flags.Bool("no-check", false, "save without checking the connection to the agent")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errors.New("usage: client [--profile NAME] init [--no-check]")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	own, err := cfg.Own(profile, true)
+	if err != nil {
+		return err
+	}
+	current, err := cfg.Profile(profile)
+	if err != nil {
+		return err
+	}
+
+	p := &prompter{in: bufio.NewReader(os.Stdin)}
+	fmt.Printf("Configuring profile %s in %s, press Enter to keep the value in brackets.\n\n", current.Name, configPath())
+
+	endpoint := defaultEndpoint(current)
+	apiKey, source, err := current.LookupAPIKey()
+	if err != nil {
+		return err
+	}
+	newKey := ""
+	for {
+		if endpoint, err = p.ask("Agent endpoint", endpoint); err != nil {
+			return err
+		}
+		keyPrompt := "API key (Enter for none)"
+		if source != config.SourceNone {
+			keyPrompt = fmt.Sprintf("API key (Enter keeps %s from the %s)", mask(apiKey), source)
+		}
+		if newKey, err = p.secret(keyPrompt); err != nil {
+			return err
+		}
+		if newKey != "" {
+			apiKey = newKey
+		}
+		if *noCheck {
+			break
+		}
+
+		fmt.Println()
+		ok := diagnose(ctx, os.Stdout, doctor.Options{Endpoint: agent.Endpoint{URL: endpoint, APIKey: apiKey}})
+		fmt.Println()
+		if ok {
+			break
+		}
+		retry, err := p.confirm("Change the endpoint or API key?", true)
+		if err != nil {
+			return err
+		}
+		if !retry {
+			break
+		}
+	}
+
+	approvals, err := p.ask("Approvals API URL (Enter for none)", current.Approvals)
+	if err != nil {
+		return err
+	}
+	currentFormat := current.Output
+	if currentFormat == "" {
+		currentFormat = formatText
+	}
+	format, err := p.choose("Default output format", []string{formatText, formatJSON}, currentFormat)
+	if err != nil {
+		return err
+	}
+
+	update(&own.Endpoint, endpoint, defaultEndpoint(current))
+	update(&own.Approvals, approvals, current.Approvals)
+	update(&own.Output, format, currentFormat)
+	if newKey != "" {
+		if err := storeAPIKey(p, own, newKey); err != nil {
+			return err
+		}
+	}
+	if err := cfg.Save(configPath()); err != nil {
+		return err
+	}
+	fmt.Printf("\nSaved profile %s. Run client doctor --run to check that events stream.\n", current.Name)
+	return nil
+}
+
+// update sets a setting of the profile when the value differs from the
+// effective one, so settings inherited from the default profile stay so
+func update(setting *string, value, effective string) {
+	if value != effective {
+		*setting = value
+	}
+}
+
+// storeAPIKey keeps the key in the keychain, or in the config file when the
+// user agrees to it on machines without a keychain
+func storeAPIKey(p *prompter, own *config.Settings, key string) error {
+	err := config.SetSecret(profile, config.APIKeyName, key)
+	if err == nil {
+		own.APIKey = ""
+		return nil
+	}
+	if !errors.Is(err, config.ErrKeychainUnavailable) {
+		return err
+	}
+
+	fmt.Println(err)
+	plaintext, err := p.confirm("Store the API key in plaintext in "+configPath()+"?", false)
+	if err != nil {
+		return err
+	}
+	if !plaintext {
+		fmt.Printf("The API key was not saved, set %s in the environment instead.\n", config.APIKeyEnv)
+		return nil
+	}
+	own.APIKey = key
+	return nil
+}
+
+// prompter asks questions on stdout and reads the answers from stdin
+type prompter struct {
+	in *bufio.Reader
+}
+
+// ask returns the answer to a question, or def when it is left empty
+func (p *prompter) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Printf("%s [%s]: ", question, def)
+	} else {
+		fmt.Printf("%s: ", question)
+	}
+	line, err := p.in.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		fmt.Println()
+		return "", fmt.Errorf("init aborted: %w", err)
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// secret reads an answer without echoing it when stdin is a terminal
+func (p *prompter) secret(question string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return p.ask(question, "")
+	}
+	fmt.Printf("%s: ", question)
+	data, err := term.ReadPassword(fd)
+	fmt.Println()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func (p *prompter) confirm(question string, def bool) (bool, error) {
+	choices := "y/N"
+	if def {
+		choices = "Y/n"
+	}
+	for {
+		answer, err := p.ask(question+" ["+choices+"]", "")
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+// choose asks until the answer is one of choices
+func (p *prompter) choose(question string, choices []string, def string) (string, error) {
+	question += " (" + strings.Join(choices, ", ") + ")"
+	for {
+		answer, err := p.ask(question, def)
+		if err != nil {
+			return "", err
+		}
+		if slices.Contains(choices, answer) {
+			return answer, nil
+		}
+		fmt.Printf("%q is not one of %s\n", answer, strings.Join(choices, ", "))
+	}
+}
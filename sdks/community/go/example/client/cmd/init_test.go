@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/config"
+)
+
+// runInitWith runs init with the lines of answers on stdin and returns what
+// it printed
+func runInitWith(t *testing.T, answers []string, args ...string) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = stdin }()
+	for _, answer := range answers {
+		_, err = w.WriteString(answer + "\n")
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	var initErr error
+	out := captureStdout(t, func() {
+		initErr = runInit(context.Background(), args)
+	})
+	return out, initErr
+}
+
+// setupInit selects a profile in an empty config whose default profile
+// has an endpoint
+func setupInit(t *testing.T, name string) {
+	t.Helper()
+	t.Setenv(configEnv, filepath.Join(t.TempDir(), "config.json"))
+	t.Setenv(config.APIKeyEnv, "")
+	selected := profile
+	profile = name
+	t.Cleanup(func() { profile = selected })
+
+	cfg := &config.Config{}
+	require.NoError(t, cfg.Set("endpoint", "https://agents.example.com/agentic"))
+	require.NoError(t, cfg.Save(configPath()))
+}
+
+// savedProfile returns the settings stored for the profile and its
+// effective ones
+func savedProfile(t *testing.T, name string) (*config.Settings, *config.Profile) {
+	t.Helper()
+	cfg, err := loadConfig()
+	require.NoError(t, err)
+	own, err := cfg.Own(name, false)
+	require.NoError(t, err)
+	p, err := cfg.Profile(name)
+	require.NoError(t, err)
+	return own, p
+}
+
+func TestRunInit(t *testing.T) {
+	keyring.MockInit()
+	setupInit(t, "prod")
+
+	out, err := runInitWith(t, []string{"https://prod.example.com/agentic", "sk-prod", "https://prod.example.com/approvals", "yaml", "json"}, "--no-check")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Agent endpoint [https://agents.example.com/agentic]: ")
+	assert.Contains(t, out, "API key (Enter for none): ")
+	assert.Contains(t, out, `"yaml" is not one of text, json`)
+	assert.Contains(t, out, "Saved profile prod.")
+
+	own, p := savedProfile(t, "prod")
+	assert.Equal(t, config.Settings{Endpoint: "https://prod.example.com/agentic", Approvals: "https://prod.example.com/approvals", Output: formatJSON}, *own,
+		"the API key is not stored in the config file")
+	key, source, err := p.LookupAPIKey()
+	require.NoError(t, err)
+	assert.Equal(t, "sk-prod", key)
+	assert.Equal(t, config.SourceKeychain, source)
+
+	// Running it again keeps the answers left empty
+	out, err = runInitWith(t, []string{"", "", "", ""}, "--no-check")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Agent endpoint [https://prod.example.com/agentic]: ")
+	assert.Contains(t, out, "API key (Enter keeps ******** from the keychain): ")
+	saved, _ := savedProfile(t, "prod")
+	assert.Equal(t, own, saved)
+}
+
+func TestRunInitInherits(t *testing.T) {
+	keyring.MockInit()
+	setupInit(t, "staging")
+
+	_, err := runInitWith(t, []string{"", "", "", ""}, "--no-check")
+	require.NoError(t, err)
+	own, p := savedProfile(t, "staging")
+	assert.Equal(t, config.Settings{}, *own, "settings equal to the default profile stay inherited")
+	assert.Equal(t, "https://agents.example.com/agentic", p.Endpoint)
+}
+
+func TestRunInitWithoutKeychain(t *testing.T) {
+	tests := []struct {
+		answer string
+		want   string
+		out    string
+	}{
+		{answer: "y", want: "sk-prod"},
+		{answer: "", out: "The API key was not saved, set " + config.APIKeyEnv + " in the environment instead."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.answer, func(t *testing.T) {
+			keyring.MockInitWithError(errors.New("no secret service"))
+			setupInit(t, "prod")
+
+			out, err := runInitWith(t, []string{"", "sk-prod", "", "", tt.answer}, "--no-check")
+			require.NoError(t, err)
+			assert.Contains(t, out, "Store the API key in plaintext in "+configPath()+"? [y/N]")
+			assert.Contains(t, out, tt.out)
+			own, _ := savedProfile(t, "prod")
+			assert.Equal(t, tt.want, own.APIKey)
+		})
+	}
+}
+
+func TestRunInitCheck(t *testing.T) {
+	keyring.MockInit()
+	setupInit(t, config.DefaultProfile)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closed := "http://" + listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	// The check fails, the endpoint is changed and fails again, and the
+	// user goes on without fixing it
+	out, err := runInitWith(t, []string{closed, "", "", "http://" + listener.Addr().String() + "/v2", "", "n", "", ""})
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(out, "Change the endpoint or API key? [Y/n]"))
+	assert.Contains(t, out, "fail tcp")
+	own, _ := savedProfile(t, config.DefaultProfile)
+	assert.Equal(t, "http://"+listener.Addr().String()+"/v2", own.Endpoint)
+}
+
+func TestRunInitAborted(t *testing.T) {
+	keyring.MockInit()
+	setupInit(t, "prod")
+
+	_, err := runInitWith(t, []string{"https://prod.example.com/agentic"}, "--no-check")
+	assert.EqualError(t, err, "init aborted: EOF")
+	cfg, err := loadConfig()
+	require.NoError(t, err)
+	assert.NotContains(t, cfg.ProfileNames(), "prod", "nothing is saved")
+
+	_, err = runInitWith(t, nil, "extra")
+	assert.EqualError(t, err, "usage: client [--profile NAME] init [--no-check]")
+}
@@ -2,39 +2,169 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/agent"
-	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/message"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/outbox"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/plugin"
 	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/ui"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-func runTea(p *tea.Program, userInputCh chan string) error {
-	defer close(userInputCh)
+const usage = `usage:
+  client [--profile NAME] COMMAND ...
+
+commands:
+  client [chat] [--tui] [--approvals URL] [--notify] [transport flags] [output flags]
+  client stream [--record FILE] [--notify] [transport flags] [output flags] [MESSAGE]
+  client replay [--speed N] [output flags] FILE
+  client compare (--endpoint URL ... | --profiles NAME,NAME...) [--diff] [MESSAGE]
+  client bench [--endpoint URL] [--runs N] [--concurrency N] [--output markdown|json]
+  client run cancel [--endpoint URL] RUN_ID
+  client session list [flags]
+  client session export [--format markdown|html] [--out FILE] ID
+  client session share|import|key ...
+  client init [--no-check]
+  client doctor [--endpoint URL] [--run] [--connectivity] [--timeout D]
+  client config show|get|set|unset|set-secret|unset-secret|encrypt|decrypt|profiles
+  client plugins
+  client completion bash|zsh|fish|powershell
+  client NAME ...   runs the plugin NAME, see client plugins
+
+transport flags:
+  --endpoint URL          agent endpoint
+  --wire-format FORMAT    json, or protobuf over gRPC falling back to json
+  --grpc ADDR             gRPC AgentService address, the host of --endpoint by default
+  --debug                 log the negotiated wire format to stderr
+
+output flags:
+  --filter TYPES    only print these event types
+  --select PATH     print a gjson path of each event
+  --output FORMAT   text, json or template
+  --template TEXT   Go template with sprig functions, @FILE reads a file
+  --per event|result  print each event or a summary of each run`
+
+// globalFlags takes the flags that come before the command off args. Only
+// --profile is global, the default chat command keeps its own flags.
+func globalFlags(args []string) ([]string, error) {
+	for len(args) > 0 {
+		switch {
+		case args[0] == "--profile" || args[0] == "-profile":
+			if len(args) < 2 {
+				return nil, errors.New("--profile requires a profile name")
+			}
+			profile, args = args[1], args[2:]
+		case strings.HasPrefix(args[0], "--profile="):
+			profile, args = strings.TrimPrefix(args[0], "--profile="), args[1:]
+		default:
+			return args, nil
+		}
+	}
+	return args, nil
+}
+
+func runTea(p *tea.Program, runs chan *types.RunAgentInput) error {
+	defer close(runs)
 	_, err := p.Run()
 	return err
 }
 
-func main() {
-	userInputCh := make(chan string)
-	p := tea.NewProgram(ui.InitialModel(userInputCh), tea.WithAltScreen())
+// outboxPath is where the chat UI keeps the messages not yet delivered to
+// endpoint, so that they are only ever sent to the server they were typed for
+func outboxPath(endpoint string) string {
+	sum := sha256.Sum256([]byte(endpoint))
+	return filepath.Join(filepath.Dir(configPath()), "outbox", hex.EncodeToString(sum[:8])+".json")
+}
+
+func runTUI(ctx context.Context, opts chatOptions) error {
+	runs := make(chan *types.RunAgentInput)
 
-	sendUserInput := func(msg *message.Message) {
-		p.Send(msg)
+	var decide ui.DecideFunc
+	if opts.approvals.URL != "" {
+		decide = func(ctx context.Context, approvalID string, approved bool) error {
+			return agent.Decide(ctx, opts.approvals, approvalID, approved)
+		}
 	}
-	go func() {
+	queue, err := outbox.Open(outboxPath(opts.endpoint.URL))
+	if err != nil {
+		return fmt.Errorf("failed to open outbox: %w", err)
+	}
+	p := tea.NewProgram(ui.InitialModel(runs, decide, queue), tea.WithAltScreen())
 
-		for msg := range userInputCh {
-			err := agent.Chat(context.Background(), msg, agent.DefaultEndpoint(), sendUserInput)
-			if err != nil {
-				log.Fatal(err)
-			}
+	go func() {
+		for input := range runs {
+			err := agent.Chat(ctx, input, opts.endpoint, func(event events.Event) {
+				p.Send(ui.EventMsg{Event: event})
+				// Failed notifications are dropped, stderr would garble the UI
+				_ = opts.notify.Observe(ctx, event)
+			})
+			p.Send(ui.RunDoneMsg{Err: err})
 		}
 	}()
 
-	teaErr := runTea(p, userInputCh)
-	if teaErr != nil {
-		log.Fatal(teaErr)
+	return runTea(p, runs)
+}
+
+func main() {
+	ctx := context.Background()
+	args, err := globalFlags(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch {
+	case len(args) > 0 && args[0] == "config":
+		err = runConfig(args[1:])
+	case len(args) > 0 && args[0] == "init":
+		err = runInit(ctx, args[1:])
+	case len(args) > 0 && args[0] == "doctor":
+		err = runDoctor(ctx, args[1:])
+	case len(args) > 0 && args[0] == "session":
+		err = runSession(ctx, args[1:])
+	case len(args) > 0 && args[0] == "run":
+		err = runRun(ctx, args[1:])
+	case len(args) > 0 && args[0] == "chat":
+		err = runChat(ctx, args[1:])
+	case len(args) > 0 && args[0] == "stream":
+		err = runStream(ctx, args[1:])
+	case len(args) > 0 && args[0] == "replay":
+		err = runReplay(ctx, args[1:])
+	case len(args) > 0 && args[0] == "compare":
+		err = runCompare(ctx, args[1:])
+	case len(args) > 0 && args[0] == "bench":
+		err = runBench(ctx, args[1:])
+	case len(args) > 0 && args[0] == "plugins":
+		err = runPlugins(args[1:])
+	case len(args) > 0 && args[0] == "completion":
+		err = runCompletion(args[1:])
+	case len(args) > 0 && args[0] == completeCommand:
+		err = runComplete(ctx, args[1:])
+	case len(args) > 0 && args[0] != "" && args[0][0] != '-':
+		// Other commands are provided by plugins
+		err = runPlugin(ctx, args[0], args[1:])
+		if errors.Is(err, plugin.ErrNotFound) {
+			err = fmt.Errorf("unknown command %q\n%s", args[0], usage)
+		}
+	default:
+		// Without a command the client starts the full-screen chat
+		err = runChat(ctx, append([]string{"--tui"}, args...))
+	}
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		// The plugin has reported its error already
+		os.Exit(exit.ExitCode())
+	}
+	if err != nil {
+		log.Fatal(err)
 	}
 }
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/filter"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/message"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/jsonpatch"
+)
+
+// Output formats of the --output flag
+const (
+	formatText     = "text"
+	formatJSON     = "json"
+	formatTemplate = "template"
+)
+
+// output prints events in line mode
+type output struct {
+	types    filter.Types
+	selector string
+	format   string
+	tmpl     *template.Template
+
+	// result collects the events of a run when printing per result
+	result *runResult
+
+	// explicit is set when an output flag was given on the command line
+	explicit bool
+}
+
+var outputFlagSet = map[string]bool{"filter": true, "select": true, "output": true, "template": true, "per": true}
+
+// outputFlags adds the flags controlling the output of events to flags.
+// The output format defaults to defaultFormat, or text when it is empty.
+func outputFlags(flags *flag.FlagSet, defaultFormat string) func() (*output, error) {
+	if defaultFormat == "" {
+		defaultFormat = formatText
+	}
+	types := flags.String("filter", "", "comma separated event types to print, prefix with - to hide a type, * matches any suffix")
+	selector := flags.String("select", "", "print the result of this gjson path on each event instead of the rendered event")
+	format := flags.String("output", defaultFormat, "output format: text, json or template")
+	text := flags.String("template", "", "Go template for --output template, @FILE reads it from a file")
+	per := flags.String("per", "event", "with --output json or template, print each event or one summary per run: event or result")
+	return func() (*output, error) {
+		t, err := filter.ParseTypes(*types)
+		if err != nil {
+			return nil, err
+		}
+		out := &output{types: t, selector: *selector, format: *format}
+		flags.Visit(func(f *flag.Flag) {
+			out.explicit = out.explicit || outputFlagSet[f.Name]
+		})
+
+		switch *format {
+		case formatText, formatJSON:
+		case formatTemplate:
+			if out.tmpl, err = parseTemplate(*text); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("invalid --output %q", *format)
+		}
+		if *format != formatTemplate && *text != "" {
+			return nil, errors.New("--template requires --output template")
+		}
+		if *format != formatText && *selector != "" {
+			return nil, errors.New("--select only applies to --output text")
+		}
+
+		switch *per {
+		case "event":
+		case "result":
+			if *format == formatText {
+				return nil, errors.New("--per result requires --output json or template")
+			}
+			out.result = &runResult{}
+		default:
+			return nil, fmt.Errorf("invalid --per %q", *per)
+		}
+		return out, nil
+	}
+}
+
+// parseTemplate parses the template of --template. Templates get the sprig
+// functions and a newline is added after each execution unless the template
+// ends with one.
+func parseTemplate(text string) (*template.Template, error) {
+	if name, ok := strings.CutPrefix(text, "@"); ok {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		text = string(data)
+	}
+	if text == "" {
+		return nil, errors.New("--output template requires --template")
+	}
+	tmpl, err := template.New("output").Funcs(sprig.TxtFuncMap()).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// isDefault reports whether no output flag was given
+func (o *output) isDefault() bool {
+	return !o.explicit
+}
+
+// print renders event, or the selected value of it. data is the raw JSON
+// of the event if it is at hand.
+func (o *output) print(event events.Event, data []byte) {
+	if o.result != nil {
+		o.result.apply(event)
+		if event.Type() == events.EventTypeRunFinished || event.Type() == events.EventTypeRunError {
+			o.flush()
+		}
+		return
+	}
+	if !o.types.Match(event.Type()) {
+		return
+	}
+
+	if o.format == formatText && o.selector == "" {
+		if msg := message.NewMessage(event); msg != nil {
+			for _, line := range msg.Strings() {
+				fmt.Println(line)
+			}
+		}
+		return
+	}
+
+	if data == nil {
+		var err error
+		if data, err = event.ToJSON(); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to encode event:", err)
+			return
+		}
+	}
+	if o.selector != "" {
+		if value, ok := filter.Select(data, o.selector); ok {
+			fmt.Println(value)
+		}
+		return
+	}
+	o.printJSON(data)
+}
+
+// flush prints the summary of the current run when printing per result
+func (o *output) flush() {
+	if o.result == nil || o.result.Events == 0 {
+		return
+	}
+	data, err := json.Marshal(o.result)
+	o.result = &runResult{}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to encode result:", err)
+		return
+	}
+	o.printJSON(data)
+}
+
+// printJSON prints a JSON value as is or through the template
+func (o *output) printJSON(data []byte) {
+	if o.format == formatJSON {
+		var line bytes.Buffer
+		if err := json.Compact(&line, data); err != nil {
+			line.Reset()
+			line.Write(data)
+		}
+		fmt.Println(line.String())
+		return
+	}
+	if err := executeTemplate(o.tmpl, data); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// executeTemplate runs tmpl on a JSON value. Templates see the value
+// decoded, so fields are addressed by their JSON names, e.g. {{.delta}}.
+func executeTemplate(tmpl *template.Template, data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, value); err != nil {
+		return err
+	}
+	text := b.String()
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	fmt.Print(text)
+	return nil
+}
+
+// runResult summarizes a run for --per result
+type runResult struct {
+	ThreadID  string          `json:"threadId,omitempty"`
+	RunID     string          `json:"runId,omitempty"`
+	Text      string          `json:"text"`
+	ToolCalls []*toolCall     `json:"toolCalls,omitempty"`
+	Messages  []types.Message `json:"messages,omitempty"`
+	State     any             `json:"state,omitempty"`
+	Result    any             `json:"result,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Events    int             `json:"events"`
+
+	// dispatcher applies run events, created on first use
+	dispatcher *events.Dispatcher
+}
+
+type toolCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+	Result    string `json:"result,omitempty"`
+}
+
+func (r *runResult) apply(event events.Event) {
+	r.Events++
+	if r.dispatcher == nil {
+		r.dispatcher = r.newDispatcher()
+	}
+	if err := r.dispatcher.Dispatch(event); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to apply event:", err)
+	}
+}
+
+// newDispatcher registers the handlers summarizing the run
+func (r *runResult) newDispatcher() *events.Dispatcher {
+	d := events.NewDispatcher()
+	d.OnRunStarted(func(e *events.RunStartedEvent) {
+		r.ThreadID, r.RunID = e.ThreadIDValue, e.RunIDValue
+	})
+	d.OnRunFinished(func(e *events.RunFinishedEvent) {
+		r.Result = e.Result
+	})
+	d.OnRunError(func(e *events.RunErrorEvent) {
+		r.Error = e.Message
+	})
+	d.OnTextMessageContent(func(e *events.TextMessageContentEvent) {
+		r.Text += e.Delta
+	})
+	d.OnTextMessageChunk(func(e *events.TextMessageChunkEvent) {
+		if e.Delta != nil {
+			r.Text += *e.Delta
+		}
+	})
+	d.OnToolCallStart(func(e *events.ToolCallStartEvent) {
+		r.ToolCalls = append(r.ToolCalls, &toolCall{ID: e.ToolCallID, Name: e.ToolCallName})
+	})
+	d.OnToolCallArgs(func(e *events.ToolCallArgsEvent) {
+		if call := r.toolCall(e.ToolCallID); call != nil {
+			call.Arguments += e.Delta
+		}
+	})
+	d.OnToolCallResult(func(e *events.ToolCallResultEvent) {
+		if call := r.toolCall(e.ToolCallID); call != nil {
+			call.Result = e.Content
+		}
+	})
+	d.OnMessagesSnapshot(func(e *events.MessagesSnapshotEvent) {
+		r.Messages = e.Messages
+	})
+	d.OnStateSnapshot(func(e *events.StateSnapshotEvent) {
+		r.State = e.Snapshot
+	})
+	d.OnStateDelta(func(e *events.StateDeltaEvent) {
+		if state, err := jsonpatch.Apply(r.State, e.Delta); err == nil {
+			r.State = state
+		}
+	})
+	return d
+}
+
+func (r *runResult) toolCall(id string) *toolCall {
+	for _, call := range r.ToolCalls {
+		if call.ID == id {
+			return call
+		}
+	}
+	return nil
+}
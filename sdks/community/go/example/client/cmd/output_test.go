@@ -0,0 +1,220 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// parseOutput parses the output flags of a command line
+func parseOutput(t *testing.T, defaultFormat string, args ...string) (*output, error) {
+	t.Helper()
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	newOutput := outputFlags(flags, defaultFormat)
+	require.NoError(t, flags.Parse(args))
+	return newOutput()
+}
+
+// captureStdout returns what fn prints to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	fn()
+	require.NoError(t, w.Close())
+	return <-done
+}
+
+func TestOutputFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		explicit bool
+		wantErr  string
+	}{
+		{name: "defaults"},
+		{name: "filter", args: []string{"--filter", "TOOL_CALL_*,-TOOL_CALL_ARGS"}, explicit: true},
+		{name: "select", args: []string{"--select", "delta"}, explicit: true},
+		{name: "invalid filter", args: []string{"--filter", "TEXT_*_END"}, wantErr: "invalid event type pattern"},
+		{name: "select with json", args: []string{"--output", "json", "--select", "delta"}, wantErr: "--select only applies to --output text"},
+		{name: "invalid output", args: []string{"--output", "yaml"}, wantErr: `invalid --output "yaml"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := parseOutput(t, "", tt.args...)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, formatText, out.format)
+			assert.Equal(t, tt.explicit, !out.isDefault())
+		})
+	}
+}
+
+func TestOutputSelect(t *testing.T) {
+	run := []events.Event{
+		events.NewRunStartedEvent("thread-1", "run-1"),
+		events.NewTextMessageStartEvent("msg-1"),
+		events.NewTextMessageContentEvent("msg-1", "Hello"),
+		events.NewToolCallStartEvent("call-1", "search"),
+		events.NewToolCallArgsEvent("call-1", `{"q":"go"}`),
+		events.NewToolCallEndEvent("call-1"),
+		events.NewRunFinishedEvent("thread-1", "run-1"),
+	}
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "select",
+			args: []string{"--select", "type"},
+			want: "RUN_STARTED\nTEXT_MESSAGE_START\nTEXT_MESSAGE_CONTENT\nTOOL_CALL_START\nTOOL_CALL_ARGS\nTOOL_CALL_END\nRUN_FINISHED\n",
+		},
+		{
+			name: "filter and select",
+			args: []string{"--filter", "TOOL_CALL_*,-TOOL_CALL_END", "--select", "type"},
+			want: "TOOL_CALL_START\nTOOL_CALL_ARGS\n",
+		},
+		{
+			name: "events without the path are skipped",
+			args: []string{"--select", "delta"},
+			want: "Hello\n{\"q\":\"go\"}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := parseOutput(t, "", tt.args...)
+			require.NoError(t, err)
+			got := captureStdout(t, func() {
+				for _, event := range run {
+					out.print(event, nil)
+				}
+			})
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseTemplate(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "event.tmpl")
+	require.NoError(t, os.WriteFile(file, []byte("{{.type}} from file"), 0o600))
+
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr string
+	}{
+		{name: "inline", text: "{{.type}}", want: "RUN_STARTED\n"},
+		{name: "keeps a trailing newline", text: "{{.type}}\n", want: "RUN_STARTED\n"},
+		{name: "sprig functions", text: `{{.runId | upper}} {{.missing | default "none"}}`, want: "RUN-1 none\n"},
+		{name: "from file", text: "@" + file, want: "RUN_STARTED from file\n"},
+		{name: "missing", text: "", wantErr: "--output template requires --template"},
+		{name: "missing file", text: "@" + file + ".missing", wantErr: "no such file"},
+		{name: "invalid", text: "{{.type", wantErr: "invalid --template"},
+	}
+	data := []byte(`{"type":"RUN_STARTED","threadId":"thread-1","runId":"run-1"}`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := parseTemplate(tt.text)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			got := captureStdout(t, func() {
+				require.NoError(t, executeTemplate(tmpl, data))
+			})
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestOutputFormats(t *testing.T) {
+	run := []events.Event{
+		events.NewRunStartedEvent("thread-1", "run-1"),
+		events.NewTextMessageContentEvent("msg-1", "Hel"),
+		events.NewTextMessageContentEvent("msg-1", "lo"),
+		events.NewToolCallStartEvent("call-1", "search"),
+		events.NewToolCallArgsEvent("call-1", `{"q":"go"}`),
+		events.NewToolCallResultEvent("msg-2", "call-1", "found"),
+		events.NewRunFinishedEvent("thread-1", "run-1"),
+	}
+	for _, event := range run {
+		event.SetTimestamp(1735830245000)
+	}
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "json",
+			args: []string{"--output", "json", "--filter", "RUN_*"},
+			want: `{"type":"RUN_STARTED","timestamp":1735830245000,"threadId":"thread-1","runId":"run-1"}` + "\n" +
+				`{"type":"RUN_FINISHED","timestamp":1735830245000,"threadId":"thread-1","runId":"run-1"}` + "\n",
+		},
+		{
+			name: "template",
+			args: []string{"--output", "template", "--template", "{{.type}} {{.delta}}", "--filter", "TEXT_MESSAGE_CONTENT"},
+			want: "TEXT_MESSAGE_CONTENT Hel\nTEXT_MESSAGE_CONTENT lo\n",
+		},
+		{
+			name: "json per result",
+			args: []string{"--output", "json", "--per", "result"},
+			want: `{"threadId":"thread-1","runId":"run-1","text":"Hello","toolCalls":[{"id":"call-1","name":"search","arguments":"{\"q\":\"go\"}","result":"found"}],"events":7}` + "\n",
+		},
+		{
+			name: "template per result",
+			args: []string{"--output", "template", "--template", "{{.runId}}: {{.text}} ({{.events}} events)", "--per", "result"},
+			want: "run-1: Hello (7 events)\n",
+		},
+		{name: "template without output template", args: []string{"--template", "{{.type}}"}, wantErr: "--template requires --output template"},
+		{name: "per result with text", args: []string{"--per", "result"}, wantErr: "--per result requires --output json or template"},
+		{name: "invalid per", args: []string{"--output", "json", "--per", "run"}, wantErr: `invalid --per "run"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := parseOutput(t, "", tt.args...)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			got := captureStdout(t, func() {
+				for _, event := range run {
+					out.print(event, nil)
+				}
+			})
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestOutputDefaultFormat(t *testing.T) {
+	out, err := parseOutput(t, formatJSON)
+	require.NoError(t, err)
+	assert.Equal(t, formatJSON, out.format)
+	assert.True(t, out.isDefault(), "a configured default is not an explicit flag")
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/config"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/plugin"
+)
+
+// Environment passed to plugins on top of the client's own, so they can use
+// the same config and session stores
+const (
+	pluginClientEnv    = "AG_UI_CLIENT"
+	pluginEndpointEnv  = "AG_UI_ENDPOINT"
+	pluginApprovalsEnv = "AG_UI_APPROVALS"
+)
+
+// pluginDir is the plugins directory, next to the config file
+func pluginDir() string {
+	return filepath.Join(filepath.Dir(configPath()), "plugins")
+}
+
+// runPlugins lists the installed plugins
+func runPlugins(args []string) error {
+	if len(args) > 0 {
+		return errors.New("usage: client plugins")
+	}
+	plugins, err := plugin.List(pluginDir())
+	if err != nil {
+		return err
+	}
+	if len(plugins) == 0 {
+		fmt.Printf("no plugins, add %s* executables to PATH or plugin directories to %s\n", plugin.Prefix, pluginDir())
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tDESCRIPTION\tPATH")
+	for _, p := range plugins {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", p.Name, p.Description, p.Path)
+	}
+	return w.Flush()
+}
+
+// runPlugin runs the plugin providing the command name, with the endpoint
+// and profile of the client but not its API key unless the plugin opts in.
+// Errors wrapping plugin.ErrNotFound mean there is no such command.
+func runPlugin(ctx context.Context, name string, args []string) error {
+	p, err := plugin.Find(pluginDir(), name)
+	if err != nil {
+		return err
+	}
+	prof, err := loadProfile()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, p.Path, append(p.Args, args...)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = append(pluginEnviron(os.Environ()),
+		configEnv+"="+configPath(),
+		profileEnv+"="+prof.Name,
+		pluginEndpointEnv+"="+defaultEndpoint(prof),
+		pluginApprovalsEnv+"="+prof.Approvals,
+		sessionDirEnv+"="+defaultSessionDir(),
+	)
+	if self, err := os.Executable(); err == nil {
+		cmd.Env = append(cmd.Env, pluginClientEnv+"="+self)
+	}
+	if p.APIKey {
+		key, _, err := prof.LookupAPIKey()
+		if err != nil {
+			return err
+		}
+		if key != "" {
+			cmd.Env = append(cmd.Env, config.APIKeyEnv+"="+key)
+		}
+	}
+	return cmd.Run()
+}
+
+// pluginEnviron returns the environment of the client without the API key,
+// which plugins only get when their manifest opts in
+func pluginEnviron(environ []string) []string {
+	return slices.DeleteFunc(slices.Clone(environ), func(kv string) bool {
+		return strings.HasPrefix(kv, config.APIKeyEnv+"=")
+	})
+}
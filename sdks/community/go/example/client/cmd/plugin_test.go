@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/config"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/plugin"
+)
+
+// pluginEnv runs the plugin name, which writes its environment to a file,
+// and returns that environment
+func pluginEnv(t *testing.T, name string) map[string]string {
+	t.Helper()
+	out := filepath.Join(t.TempDir(), "env")
+	require.NoError(t, runPlugin(context.Background(), name, []string{out}))
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	env := map[string]string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if key, value, ok := strings.Cut(line, "="); ok {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+func TestRunPluginEnv(t *testing.T) {
+	keyring.MockInit()
+	dir := t.TempDir()
+	t.Setenv(configEnv, filepath.Join(dir, "config.json"))
+	t.Setenv(config.APIKeyEnv, "")
+	cfg := &config.Config{}
+	require.NoError(t, cfg.Set("endpoint", "https://agents.example.com/agentic"))
+	require.NoError(t, cfg.Save(configPath()))
+	require.NoError(t, config.SetSecret(config.DefaultProfile, config.APIKeyName, "sk-secret"))
+
+	script := "#!/bin/sh\nenv > \"$1\"\n"
+	bin := t.TempDir()
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	require.NoError(t, os.WriteFile(filepath.Join(bin, plugin.Prefix+"onpath"), []byte(script), 0o755))
+	for name, manifest := range map[string]string{
+		"plain":  `{"exec":"run"}`,
+		"optsin": `{"exec":"run","apiKey":true}`,
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(pluginDir(), name), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(pluginDir(), name, plugin.ManifestFile), []byte(manifest), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(pluginDir(), name, "run"), []byte(script), 0o755))
+	}
+
+	tests := []struct {
+		plugin string
+		apiKey string
+	}{
+		{"onpath", ""},
+		{"plain", ""},
+		{"optsin", "sk-secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.plugin, func(t *testing.T) {
+			env := pluginEnv(t, tt.plugin)
+			assert.Equal(t, "https://agents.example.com/agentic", env[pluginEndpointEnv])
+			assert.Equal(t, config.DefaultProfile, env[profileEnv])
+			assert.Equal(t, configPath(), env[configEnv])
+			key, ok := env[config.APIKeyEnv]
+			assert.Equal(t, tt.apiKey, key)
+			assert.Equal(t, tt.apiKey != "", ok)
+		})
+	}
+
+	// A key in the environment of the client is not passed on either
+	t.Setenv(config.APIKeyEnv, "sk-env")
+	_, ok := pluginEnv(t, "onpath")[config.APIKeyEnv]
+	assert.False(t, ok)
+	assert.Equal(t, "sk-env", pluginEnv(t, "optsin")[config.APIKeyEnv])
+}
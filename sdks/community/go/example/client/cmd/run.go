@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/agent"
+)
+
+const runUsage = `usage:
+  client run cancel [--endpoint URL] RUN_ID   cancels a run in progress`
+
+func runRun(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New(runUsage)
+	}
+	switch args[0] {
+	case "cancel":
+		return runRunCancel(ctx, args[1:])
+	default:
+		return errors.New(runUsage)
+	}
+}
+
+// runRunCancel cancels a run of the agent of the selected profile, which
+// then ends with a RUN_ERROR event with code CANCELLED
+func runRunCancel(ctx context.Context, args []string) error {
+	cfg, err := loadProfile()
+	if err != nil {
+		return err
+	}
+
+	flags := flag.NewFlagSet("run cancel", flag.ContinueOnError)
+	url := flags.String("endpoint", defaultEndpoint(cfg), "agent endpoint")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New(runUsage)
+	}
+
+	apiKey, _, err := cfg.LookupAPIKey()
+	if err != nil {
+		return err
+	}
+	runID := flags.Arg(0)
+	if err := agent.Cancel(ctx, agent.Endpoint{URL: *url, APIKey: apiKey}, runID); err != nil {
+		return err
+	}
+	fmt.Printf("run %s cancelled\n", runID)
+	return nil
+}
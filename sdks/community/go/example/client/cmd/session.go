@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/export"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/session"
+)
+
+const sessionUsage = `usage:
+  client session list [flags]
+  client session export [--format markdown|html] [--out FILE] ID
+  client session share [--encrypt] [--out FILE] ID
+  client session import [--verify] [--signer KEY] [--id ID] [--force] FILE
+  client session key    prints the public key shared sessions are signed with`
+
+// sessionKeysEnv holds the keys of encrypted session stores, see session.KeyringFromEnv
+const sessionKeysEnv = "AG_UI_SESSION_KEYS"
+
+// sessionDirEnv overrides the default session directory
+const sessionDirEnv = "AG_UI_SESSION_DIR"
+
+func defaultSessionDir() string {
+	if dir := os.Getenv(sessionDirEnv); dir != "" {
+		return dir
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "ag-ui", "sessions")
+}
+
+// openSessionStore opens the session files in dir, decrypting them with the
+// keys in AG_UI_SESSION_KEYS if it is set. The store locks dir, so that
+// instances of the client running at once do not overwrite each other.
+func openSessionStore(dir string) (*session.FileStore, error) {
+	var config session.Config
+	if os.Getenv(sessionKeysEnv) != "" {
+		keyring, err := session.KeyringFromEnv(sessionKeysEnv)
+		if err != nil {
+			return nil, err
+		}
+		config.Cipher = session.NewAESGCMCipher(keyring)
+	}
+	return session.NewFileStore(dir, config)
+}
+
+func runSession(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New(sessionUsage)
+	}
+	switch args[0] {
+	case "list":
+		return runSessionList(ctx, args[1:])
+	case "export":
+		return runSessionExport(ctx, args[1:])
+	case "share":
+		return runSessionShare(ctx, args[1:])
+	case "import":
+		return runSessionImport(ctx, args[1:])
+	case "key":
+		return runSessionKey(args[1:])
+	default:
+		return errors.New(sessionUsage)
+	}
+}
+
+func runSessionList(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("session list", flag.ContinueOnError)
+	dir := flags.String("dir", defaultSessionDir(), "session directory")
+	search := flags.String("search", "", "only sessions whose messages contain every word")
+	labels := flags.String("label", "", "only sessions with all of these comma separated labels")
+	tools := flags.String("tool", "", "only sessions that called any of these comma separated tools")
+	since := flags.String("since", "", "only sessions updated on or after this date (YYYY-MM-DD or RFC 3339)")
+	until := flags.String("until", "", "only sessions updated before this date (YYYY-MM-DD or RFC 3339)")
+	limit := flags.Int("limit", 0, "maximum number of sessions to list")
+	format := flags.String("output", formatText, "output format: text, json or template")
+	text := flags.String("template", "", "Go template applied to each session for --output template, @FILE reads it from a file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var tmpl *template.Template
+	switch *format {
+	case formatText, formatJSON:
+		if *text != "" {
+			return errors.New("--template requires --output template")
+		}
+	case formatTemplate:
+		var err error
+		if tmpl, err = parseTemplate(*text); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid --output %q", *format)
+	}
+
+	query := session.Query{
+		Text:   *search,
+		Labels: splitList(*labels),
+		Tools:  splitList(*tools),
+		Limit:  *limit,
+	}
+	var err error
+	if query.Since, err = parseDate(*since); err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	if query.Until, err = parseDate(*until); err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+
+	files, err := openSessionStore(*dir)
+	if err != nil {
+		return err
+	}
+	store, err := session.NewIndexedStore(ctx, files)
+	if err != nil {
+		return err
+	}
+	sessions, err := store.Search(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	if *format != formatText {
+		for _, s := range sessions {
+			data, err := json.Marshal(s)
+			if err != nil {
+				return err
+			}
+			if tmpl == nil {
+				fmt.Println(string(data))
+			} else if err := executeTemplate(tmpl, data); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tUPDATED\tMESSAGES\tLABELS")
+	for _, s := range sessions {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", s.ID, s.UpdatedAt.Local().Format(time.DateTime), len(s.Messages), strings.Join(s.Labels, ","))
+	}
+	return w.Flush()
+}
+
+// runSessionExport renders a session as a document to share
+func runSessionExport(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("session export", flag.ContinueOnError)
+	dir := flags.String("dir", defaultSessionDir(), "session directory")
+	format := flags.String("format", export.Markdown, "document format: markdown or html")
+	out := flags.String("out", "", "write the document to this file instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New(sessionUsage)
+	}
+	if *format != export.Markdown && *format != export.HTML {
+		return fmt.Errorf("invalid --format %q", *format)
+	}
+
+	store, err := openSessionStore(*dir)
+	if err != nil {
+		return err
+	}
+	s, err := store.Get(ctx, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return export.Write(os.Stdout, s, *format)
+	}
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := export.Write(file, s, *format); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/bundle"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/session"
+)
+
+// passphraseEnv supplies the bundle passphrase without a prompt
+const passphraseEnv = "AG_UI_BUNDLE_PASSPHRASE"
+
+// signingKeyPath is where the key signing shared sessions is kept
+func signingKeyPath() string {
+	return filepath.Join(filepath.Dir(configPath()), "signing.key")
+}
+
+func readPassphrase() (string, error) {
+	if passphrase := os.Getenv(passphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	return readSecret("passphrase")
+}
+
+// runSessionShare writes a session as a signed bundle
+func runSessionShare(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("session share", flag.ContinueOnError)
+	dir := flags.String("dir", defaultSessionDir(), "session directory")
+	encrypt := flags.Bool("encrypt", false, "encrypt the bundle with a passphrase, read from "+passphraseEnv+" or prompted for")
+	out := flags.String("out", "", "write the bundle to this file instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New(sessionUsage)
+	}
+
+	store, err := openSessionStore(*dir)
+	if err != nil {
+		return err
+	}
+	s, err := store.Get(ctx, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	key, err := bundle.LoadKey(signingKeyPath())
+	if err != nil {
+		return err
+	}
+	var passphrase string
+	if *encrypt {
+		if passphrase, err = readPassphrase(); err != nil {
+			return err
+		}
+	}
+	b, err := bundle.Create(s, key, passphrase)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if *out == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*out, data, 0o600)
+}
+
+// runSessionImport adds the session of a bundle to the store
+func runSessionImport(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("session import", flag.ContinueOnError)
+	dir := flags.String("dir", defaultSessionDir(), "session directory")
+	verify := flags.Bool("verify", false, "refuse bundles that are unsigned or whose signature does not match")
+	signer := flags.String("signer", "", "only accept bundles signed by this public key, implies --verify")
+	id := flags.String("id", "", "import the session under this ID instead of its own")
+	force := flags.Bool("force", false, "replace an existing session with the same ID")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New(sessionUsage)
+	}
+
+	var data []byte
+	var err error
+	if path := flags.Arg(0); path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+	b, err := bundle.Read(data)
+	if err != nil {
+		return err
+	}
+
+	if *verify || *signer != "" {
+		key, err := b.Verify()
+		if err != nil {
+			return err
+		}
+		if *signer != "" {
+			want, err := bundle.ParsePublicKey(*signer)
+			if err != nil {
+				return err
+			}
+			if !key.Equal(want) {
+				return fmt.Errorf("%w: signed by %s", bundle.ErrInvalidSignature, bundle.FormatPublicKey(key))
+			}
+		}
+		fmt.Fprintln(os.Stderr, "signature verified, signed by", bundle.FormatPublicKey(key))
+	} else {
+		fmt.Fprintln(os.Stderr, "warning: the signature was not checked, use --verify")
+	}
+
+	var passphrase string
+	if b.Encryption != nil {
+		if passphrase, err = readPassphrase(); err != nil {
+			return err
+		}
+	}
+	s, err := b.Open(passphrase)
+	if err != nil {
+		return err
+	}
+	if *id != "" {
+		s.ID = *id
+	}
+
+	store, err := openSessionStore(*dir)
+	if err != nil {
+		return err
+	}
+	if _, err := store.Get(ctx, s.ID); err == nil && !*force {
+		return fmt.Errorf("session %s already exists, use --force to replace it or --id to import it under another ID", s.ID)
+	} else if err != nil && !errors.Is(err, session.ErrNotFound) {
+		return err
+	}
+	if err := store.Save(ctx, s); err != nil {
+		return err
+	}
+	fmt.Println(s.ID)
+	return nil
+}
+
+// runSessionKey prints the public key of the signing key, for teammates to
+// pass to session import --signer
+func runSessionKey(args []string) error {
+	if len(args) != 0 {
+		return errors.New(sessionUsage)
+	}
+	key, err := bundle.LoadKey(signingKeyPath())
+	if err != nil {
+		return err
+	}
+	fmt.Println(bundle.FormatPublicKey(key.Public().(ed25519.PublicKey)))
+	return nil
+}
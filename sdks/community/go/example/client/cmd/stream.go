@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/agent"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/event"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/notify"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/record"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/google/uuid"
+)
+
+const replayUsage = `usage: client replay [--speed N] [output flags] FILE`
+
+// runStream sends a single message, taken from the arguments or stdin, and
+// prints the events of the response, optionally recording the raw frames
+func runStream(ctx context.Context, args []string) error {
+	cfg, err := loadProfile()
+	if err != nil {
+		return err
+	}
+
+	flags := flag.NewFlagSet("stream", flag.ContinueOnError)
+	parseTransport := transportFlags(flags, cfg)
+	recordPath := flags.String("record", "", "write the raw frames with their timestamps to this file")
+	notifyEnd := flags.Bool("notify", false, "notify when the run ends, with a desktop notification or the notify-hook command")
+	parseOutput := outputFlags(flags, cfg.Output)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	out, err := parseOutput()
+	if err != nil {
+		return err
+	}
+	transport, err := parseTransport()
+	if err != nil {
+		return err
+	}
+
+	text := strings.Join(flags.Args(), " ")
+	if text == "" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		text = strings.TrimSpace(string(data))
+	}
+	if text == "" {
+		return errors.New("no message to send")
+	}
+
+	var recorder *record.Writer
+	if *recordPath != "" {
+		file, err := os.Create(*recordPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		recorder = record.NewWriter(file)
+	}
+
+	var notifier *notify.Notifier
+	if *notifyEnd {
+		hook, err := cfg.Get("notify-hook")
+		if err != nil {
+			return err
+		}
+		notifier = &notify.Notifier{Hook: hook, Endpoint: transport.endpoint.URL}
+	}
+
+	messages := []types.Message{{ID: "msg-" + uuid.NewString(), Role: types.RoleUser, Content: text}}
+	input := agent.NewInput("thread-"+uuid.NewString(), messages, nil)
+	defer out.flush()
+	return agent.Stream(ctx, input, transport.endpoint, func(frame sse.Frame) error {
+		if recorder != nil {
+			if err := recorder.Write(frame); err != nil {
+				return fmt.Errorf("failed to record frame: %w", err)
+			}
+		}
+		e, err := event.Parse(frame.Data)
+		if err != nil {
+			return fmt.Errorf("failed to process SSE event %w", err)
+		}
+		out.print(e, frame.Data)
+		if err := notifier.Observe(ctx, e); err != nil {
+			fmt.Fprintln(os.Stderr, "warning:", err)
+		}
+		return nil
+	})
+}
+
+// runReplay prints the events of a recording made with stream --record
+func runReplay(ctx context.Context, args []string) error {
+	cfg, err := loadProfile()
+	if err != nil {
+		return err
+	}
+
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+	speed := flags.Float64("speed", 1, "playback speed, 2 plays twice as fast, 0 without pauses")
+	parseOutput := outputFlags(flags, cfg.Output)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	out, err := parseOutput()
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New(replayUsage)
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	defer out.flush()
+	return record.Replay(ctx, record.NewReader(file), *speed, func(frame record.Frame) error {
+		e, err := event.Parse([]byte(frame.Data))
+		if err != nil {
+			// Keep going, broken frames are often why the recording was made
+			fmt.Println("invalid frame:", err)
+			return nil
+		}
+		out.print(e, []byte(frame.Data))
+		return nil
+	})
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/agent"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+// transport is how chat and stream reach the agent
+type transport struct {
+	endpoint agent.Endpoint
+	debug    bool
+}
+
+// transportFlags adds the flags choosing the endpoint and the wire format of
+// the events. The returned function builds the transport once the flags are
+// parsed.
+func transportFlags(flags *flag.FlagSet, cfg *config.Profile) func() (*transport, error) {
+	url := flags.String("endpoint", defaultEndpoint(cfg), "agent endpoint")
+	wireFormat := flags.String("wire-format", "json", "wire format of the events: json, or protobuf over the gRPC AgentService falling back to json")
+	grpcAddr := flags.String("grpc", "", "address of the gRPC AgentService for --wire-format protobuf, the host of --endpoint by default")
+	debug := flags.Bool("debug", false, "log the negotiated wire format to stderr")
+
+	return func() (*transport, error) {
+		contentType, err := agent.NegotiateWireFormat(*wireFormat)
+		if err != nil {
+			return nil, err
+		}
+		apiKey, _, err := cfg.LookupAPIKey()
+		if err != nil {
+			return nil, err
+		}
+		t := &transport{
+			endpoint: agent.Endpoint{URL: *url, APIKey: apiKey, WireFormat: contentType, GRPCAddr: *grpcAddr},
+			debug:    *debug,
+		}
+		if *debug {
+			logger := logrus.New()
+			logger.SetOutput(os.Stderr)
+			logger.SetLevel(logrus.DebugLevel)
+			t.endpoint.Logger = logger
+		}
+		return t, nil
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/agent"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/config"
+)
+
+func TestTransportFlags(t *testing.T) {
+	keyring.MockInit()
+	t.Setenv(config.APIKeyEnv, "sk-env")
+	cfg := &config.Config{}
+	require.NoError(t, cfg.Set("endpoint", "https://agents.example.com/agentic"))
+	p, err := cfg.Profile(config.DefaultProfile)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    agent.Endpoint
+		debug   bool
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			want: agent.Endpoint{URL: "https://agents.example.com/agentic", APIKey: "sk-env", WireFormat: agent.ContentTypeJSON},
+		},
+		{
+			name:  "protobuf",
+			args:  []string{"--wire-format", "protobuf", "--grpc", "localhost:50051", "--debug"},
+			want:  agent.Endpoint{URL: "https://agents.example.com/agentic", APIKey: "sk-env", WireFormat: agent.ContentTypeProtobuf, GRPCAddr: "localhost:50051"},
+			debug: true,
+		},
+		{
+			name:    "unsupported wire format",
+			args:    []string{"--wire-format", "yaml"},
+			wantErr: `unsupported wire format "yaml"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := flag.NewFlagSet("test", flag.ContinueOnError)
+			flags.SetOutput(io.Discard)
+			newTransport := transportFlags(flags, p)
+			require.NoError(t, flags.Parse(tt.args))
+
+			tr, err := newTransport()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.debug, tr.debug)
+			assert.Equal(t, tt.debug, tr.endpoint.Logger != nil, "debug logs go to stderr")
+			tr.endpoint.Logger = nil
+			assert.Equal(t, tt.want, tr.endpoint)
+		})
+	}
+}
@@ -1,15 +1,21 @@
 package agent
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/event"
-	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/message"
 	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
 	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
@@ -17,11 +23,90 @@ func DefaultEndpoint() string {
 	return "http://localhost:8000/agentic"
 }
 
-func Chat(ctx context.Context, inputMsg string, endpoint string, send func(msg *message.Message)) error {
+// Endpoint is a server URL with the API key to authenticate with, if any
+type Endpoint struct {
+	URL    string
+	APIKey string
+
+	// WireFormat is the content type to receive events in, JSON when empty.
+	// Protobuf travels over the gRPC AgentService at GRPCAddr, the host of
+	// URL by default, and falls back to JSON over SSE when the service is
+	// unavailable.
+	WireFormat string
+	GRPCAddr   string
+
+	// Logger receives debug logs, nil discards them
+	Logger *logrus.Logger
+}
+
+func (e Endpoint) logger() *logrus.Logger {
+	if e.Logger != nil {
+		return e.Logger
+	}
 	logger := logrus.New()
 	logger.SetLevel(logrus.FatalLevel)
+	return logger
+}
+
+// NewInput creates the input for a run continuing the conversation in messages
+func NewInput(threadID string, messages []types.Message, state any) *types.RunAgentInput {
+	if state == nil {
+		state = map[string]any{}
+	}
+	return &types.RunAgentInput{
+		ThreadID:       threadID,
+		RunID:          "run-" + uuid.NewString(),
+		State:          state,
+		Messages:       messages,
+		Tools:          []types.Tool{},
+		Context:        []types.Context{},
+		ForwardedProps: map[string]any{},
+	}
+}
+
+// Chat runs the agent at endpoint with input and passes each event it
+// streams back to send, rebuilding messages snapshots sent in chunks and
+// upgrading the events of servers speaking an older protocol version. It
+// returns once the stream ends.
+func Chat(ctx context.Context, input *types.RunAgentInput, endpoint Endpoint, send func(events.Event)) error {
+	assembler := events.NewSnapshotAssembler(0)
+	shim, err := events.NewProtocolShim(input.ProtocolVersion)
+	if err != nil {
+		return err
+	}
+	return Stream(ctx, input, endpoint, func(frame sse.Frame) error {
+		rawEvent, err := event.Parse(frame.Data)
+		if err != nil {
+			return fmt.Errorf("failed to process SSE event %w", err)
+		}
+		rawEvent, err = assembler.Assemble(shim.Upgrade(rawEvent))
+		if err != nil {
+			return err
+		}
+		if rawEvent != nil {
+			send(rawEvent)
+		}
+		return nil
+	})
+}
+
+// Stream runs the agent at endpoint with input and passes each raw SSE frame
+// to handle. It returns once the stream ends or handle returns an error.
+func Stream(ctx context.Context, input *types.RunAgentInput, endpoint Endpoint, handle func(sse.Frame) error) error {
+	logger := endpoint.logger()
+	if endpoint.WireFormat == ContentTypeProtobuf {
+		logger.Debugf("wire format %s over gRPC at %s", ContentTypeProtobuf, endpoint.grpcAddr())
+		err := streamGRPC(ctx, input, endpoint, handle)
+		if !errors.Is(err, errGRPCUnavailable) {
+			return err
+		}
+		logger.Debugf("falling back to %s: %v", ContentTypeJSON, err)
+	}
+	logger.Debugf("wire format %s over SSE from %s", ContentTypeJSON, endpoint.URL)
+
 	sseConfig := sse.Config{
-		Endpoint:       endpoint,
+		Endpoint:       endpoint.URL,
+		APIKey:         endpoint.APIKey,
 		ConnectTimeout: 30 * time.Second,
 		ReadTimeout:    5 * time.Minute,
 		BufferSize:     100,
@@ -35,64 +120,85 @@ func Chat(ctx context.Context, inputMsg string, endpoint string, send func(msg *
 		client.Close()
 	}()
 
-	sessionID := "test-session-1755371887"
-	runID := "run-1755744865857245000"
-
-	payload := types.RunAgentInput{
-		ThreadID: sessionID,
-		RunID:    runID,
-		State:    map[string]any{},
-		Messages: []types.Message{
-			{
-				ID:      "msg-1",
-				Role:    types.RoleUser,
-				Content: inputMsg,
-			},
-		},
-		Tools:          []types.Tool{},
-		Context:        []types.Context{},
-		ForwardedProps: map[string]any{},
-	}
-
 	// Start the SSE stream
-	var err error
 	frames, errorCh, err := client.Stream(sse.StreamOptions{
 		Context: ctx,
-		Payload: payload,
+		Payload: *input,
 	})
-
 	if err != nil {
-		return errors.New("Failed to establish SSE connection")
+		return fmt.Errorf("failed to establish SSE connection: %w", err)
 	}
 
-	// Parse SSE events
 	for {
 		select {
 		case frame, ok := <-frames:
 			if !ok {
 				return nil
 			}
-
-			rawEvent, err := event.Parse(frame.Data)
-			if err != nil {
-				return fmt.Errorf("failed to process SSE event %w", err)
-			}
-			currMsg := message.NewMessage(rawEvent)
-			if currMsg == nil {
-				return fmt.Errorf("failed to parse message %w", err)
+			if err := handle(frame); err != nil {
+				return err
 			}
-			send(currMsg)
 
 		case err, ok := <-errorCh:
 			if !ok {
-				break
+				errorCh = nil
+				continue
 			}
 			if err != nil {
-				break
+				return err
 			}
 
 		case <-ctx.Done():
-			break
+			return ctx.Err()
 		}
 	}
 }
+
+// Unreachable reports whether err means the server could not be reached,
+// as opposed to the server failing the run
+func Unreachable(err error) bool {
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	return errors.As(err, &opErr) || errors.As(err, &dnsErr)
+}
+
+// Cancel cancels the run runID in progress at endpoint
+func Cancel(ctx context.Context, endpoint Endpoint, runID string) error {
+	client := sse.NewClient(sse.Config{
+		Endpoint:   endpoint.URL,
+		APIKey:     endpoint.APIKey,
+		Logger:     endpoint.logger(),
+		AuthHeader: "Authorization",
+		AuthScheme: "Bearer",
+	})
+	defer client.Close()
+	return client.Cancel(ctx, runID)
+}
+
+// Decide approves or rejects a pending tool call approval through the
+// server's approvals API
+func Decide(ctx context.Context, approvals Endpoint, approvalID string, approved bool) error {
+	body, err := json.Marshal(map[string]any{"approved": approved})
+	if err != nil {
+		return err
+	}
+	url := strings.TrimSuffix(approvals.URL, "/") + "/" + approvalID
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if approvals.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+approvals.APIKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("approval %s: %s", approvalID, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package agent
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding/negotiation"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/proto/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Wire formats of the events
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// errGRPCUnavailable means the gRPC AgentService could not be used and the
+// run should fall back to JSON over SSE
+var errGRPCUnavailable = errors.New("gRPC AgentService unavailable")
+
+// NegotiateWireFormat resolves a --wire-format value, "json", "protobuf" or
+// a content type, through the encoding registry. The format asked for is
+// preferred with JSON as the fallback.
+func NegotiateWireFormat(name string) (string, error) {
+	preferred := name
+	switch name {
+	case "", "json":
+		preferred = ContentTypeJSON
+	case "protobuf", "proto":
+		preferred = ContentTypeProtobuf
+	}
+
+	negotiator := negotiation.NewContentNegotiator(ContentTypeJSON)
+	capabilities, ok := negotiator.GetCapabilities(preferred)
+	if !ok || !capabilities.CanStream {
+		return "", fmt.Errorf("unsupported wire format %q", name)
+	}
+	contentType, err := negotiator.Negotiate(capabilities.ContentType + ", " + ContentTypeJSON + ";q=0.5")
+	if err != nil {
+		return "", err
+	}
+	if contentType != ContentTypeProtobuf {
+		// JSON variants all travel as plain JSON over SSE
+		contentType = ContentTypeJSON
+	}
+	return contentType, nil
+}
+
+// grpcAddr returns the address of the gRPC AgentService, by default the
+// host of the endpoint URL
+func (e Endpoint) grpcAddr() string {
+	if e.GRPCAddr != "" {
+		return e.GRPCAddr
+	}
+	if u, err := url.Parse(e.URL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return e.URL
+}
+
+// streamGRPC runs the agent over the gRPC AgentService, passing each event
+// to handle as the JSON frame SSE would have carried. It returns an error
+// wrapping errGRPCUnavailable when the service fails before the first event.
+func streamGRPC(ctx context.Context, input *types.RunAgentInput, endpoint Endpoint, handle func(sse.Frame) error) error {
+	creds := insecure.NewCredentials()
+	if strings.HasPrefix(endpoint.URL, "https://") {
+		creds = credentials.NewTLS(&tls.Config{})
+	}
+	conn, err := grpc.NewClient(endpoint.grpcAddr(), grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errGRPCUnavailable, err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if endpoint.APIKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+endpoint.APIKey)
+	}
+
+	req, err := pb.NewRunAgentRequest(input)
+	if err != nil {
+		return err
+	}
+	stream, err := pb.NewAgentServiceClient(conn).RunAgent(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errGRPCUnavailable, err)
+	}
+	// A failed send shows up as the error of the next receive
+	_ = stream.Send(req)
+
+	received := false
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if code := status.Code(err); !received && (code == codes.Unavailable || code == codes.Unimplemented) {
+				return fmt.Errorf("%w: %v", errGRPCUnavailable, err)
+			}
+			return fmt.Errorf("gRPC stream failed: %w", err)
+		}
+		received = true
+
+		e, err := pb.ResponseEvent(resp)
+		if err != nil {
+			return fmt.Errorf("failed to decode protobuf event: %w", err)
+		}
+		frame, err := e.ToJSON()
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", e.Type(), err)
+		}
+		if err := handle(sse.Frame{Data: frame, Timestamp: time.Now()}); err != nil {
+			return err
+		}
+	}
+}
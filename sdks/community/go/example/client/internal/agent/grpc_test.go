@@ -0,0 +1,243 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/event"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/proto/pb"
+)
+
+func TestNegotiateWireFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr string
+	}{
+		{name: "", want: ContentTypeJSON},
+		{name: "json", want: ContentTypeJSON},
+		{name: "protobuf", want: ContentTypeProtobuf},
+		{name: "proto", want: ContentTypeProtobuf},
+		{name: ContentTypeProtobuf, want: ContentTypeProtobuf},
+		{name: "application/vnd.ag-ui+json", want: ContentTypeJSON},
+		{name: "yaml", wantErr: `unsupported wire format "yaml"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contentType, err := NegotiateWireFormat(tt.name)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, contentType)
+		})
+	}
+}
+
+func TestGRPCAddr(t *testing.T) {
+	tests := []struct {
+		endpoint Endpoint
+		want     string
+	}{
+		{Endpoint{URL: "https://agents.example.com:8443/agentic"}, "agents.example.com:8443"},
+		{Endpoint{URL: "https://agents.example.com/agentic", GRPCAddr: "grpc.example.com:50051"}, "grpc.example.com:50051"},
+		{Endpoint{URL: "localhost:50051"}, "localhost:50051"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.endpoint.grpcAddr())
+	}
+}
+
+// agentService serves the events of run to each RunAgent call, recording
+// the authorization metadata and the input received
+type agentService struct {
+	pb.UnimplementedAgentServiceServer
+	run    func(stream grpc.BidiStreamingServer[pb.RunAgentRequest, pb.RunAgentResponse]) error
+	auth   chan string
+	inputs chan *types.RunAgentInput
+}
+
+func (s *agentService) RunAgent(stream grpc.BidiStreamingServer[pb.RunAgentRequest, pb.RunAgentResponse]) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	s.auth <- fmt.Sprint(md.Get("authorization"))
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	input, err := pb.RequestInput(req)
+	if err != nil {
+		return err
+	}
+	s.inputs <- input
+	return s.run(stream)
+}
+
+// sendEvents returns a run sending events, then ending with err
+func sendEvents(err error, evts ...events.Event) func(grpc.BidiStreamingServer[pb.RunAgentRequest, pb.RunAgentResponse]) error {
+	return func(stream grpc.BidiStreamingServer[pb.RunAgentRequest, pb.RunAgentResponse]) error {
+		for _, e := range evts {
+			resp, err := pb.NewRunAgentResponse(e)
+			if err != nil {
+				return err
+			}
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+		}
+		return err
+	}
+}
+
+// serveGRPC starts a gRPC server on a local port, serving svc when it is
+// not nil, and returns its address
+func serveGRPC(t *testing.T, svc *agentService) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	if svc != nil {
+		pb.RegisterAgentServiceServer(srv, svc)
+	}
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(srv.Stop)
+	return listener.Addr().String()
+}
+
+// closedAddr returns a local address nothing listens on
+func closedAddr(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+	return addr
+}
+
+func TestStreamProtobuf(t *testing.T) {
+	var sseRequests atomic.Int32
+	sseServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sseRequests.Add(1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"type\":\"RUN_STARTED\",\"threadId\":\"thread-1\",\"runId\":\"over-sse\"}\n\n")
+	}))
+	defer sseServer.Close()
+
+	overGRPC := []events.Event{
+		events.NewRunStartedEvent("thread-1", "run-1"),
+		events.NewTextMessageContentEvent("msg-1", "Hello"),
+		events.NewRunFinishedEvent("thread-1", "run-1"),
+	}
+	tests := []struct {
+		name     string
+		svc      *agentService
+		addr     string
+		wantRuns []string
+		wantErr  string
+		wantSSE  bool // falling back to JSON over SSE
+	}{
+		{
+			name:     "events over gRPC",
+			svc:      &agentService{run: sendEvents(nil, overGRPC...)},
+			wantRuns: []string{"run-1", "", "run-1"},
+		},
+		{
+			name:     "service unimplemented",
+			addr:     "unregistered",
+			wantRuns: []string{"over-sse"},
+			wantSSE:  true,
+		},
+		{
+			name:     "server unreachable",
+			addr:     closedAddr(t),
+			wantRuns: []string{"over-sse"},
+			wantSSE:  true,
+		},
+		{
+			name:     "unavailable before the first event",
+			svc:      &agentService{run: sendEvents(status.Error(codes.Unavailable, "draining"))},
+			wantRuns: []string{"over-sse"},
+			wantSSE:  true,
+		},
+		{
+			name:     "failure after the first event",
+			svc:      &agentService{run: sendEvents(status.Error(codes.Unavailable, "draining"), overGRPC[0])},
+			wantRuns: []string{"run-1"},
+			wantErr:  "gRPC stream failed",
+		},
+		{
+			name:    "other failure before the first event",
+			svc:     &agentService{run: sendEvents(status.Error(codes.PermissionDenied, "forbidden"))},
+			wantErr: "forbidden",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sseRequests.Store(0)
+			addr := tt.addr
+			switch {
+			case tt.svc != nil:
+				tt.svc.auth = make(chan string, 1)
+				tt.svc.inputs = make(chan *types.RunAgentInput, 1)
+				addr = serveGRPC(t, tt.svc)
+			case addr == "unregistered":
+				addr = serveGRPC(t, nil)
+			}
+			var logs bytes.Buffer
+			logger := logrus.New()
+			logger.SetOutput(&logs)
+			logger.SetLevel(logrus.DebugLevel)
+			endpoint := Endpoint{URL: sseServer.URL, APIKey: "sk-test", WireFormat: ContentTypeProtobuf, GRPCAddr: addr, Logger: logger}
+			input := NewInput("thread-1", []types.Message{{ID: "1", Role: types.RoleUser, Content: "Hi"}}, nil)
+
+			var runs []string
+			err := Stream(context.Background(), input, endpoint, func(frame sse.Frame) error {
+				e, err := event.Parse(frame.Data)
+				require.NoError(t, err)
+				var runID string
+				switch e := e.(type) {
+				case *events.RunStartedEvent:
+					runID = e.RunID()
+				case *events.RunFinishedEvent:
+					runID = e.RunID()
+				}
+				runs = append(runs, runID)
+				return nil
+			})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantRuns, runs)
+			assert.Equal(t, tt.wantSSE, sseRequests.Load() > 0)
+			assert.Contains(t, logs.String(), "wire format application/x-protobuf over gRPC at "+addr)
+			assert.Equal(t, tt.wantSSE, strings.Contains(logs.String(), "falling back to application/json"))
+
+			if tt.svc != nil {
+				assert.Equal(t, "[Bearer sk-test]", <-tt.svc.auth)
+				received := <-tt.svc.inputs
+				assert.Equal(t, input.RunID, received.RunID)
+				assert.Equal(t, input.Messages, received.Messages)
+			}
+		})
+	}
+}
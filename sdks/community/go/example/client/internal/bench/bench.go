@@ -0,0 +1,294 @@
+// Package bench drives concurrent runs against an AG-UI server and reports
+// how fast it streams
+package bench
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/agent"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/event"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/google/uuid"
+)
+
+// maxErrorSamples bounds the distinct error messages kept in a report
+const maxErrorSamples = 5
+
+type Config struct {
+	Endpoint agent.Endpoint
+
+	// Runs is the total number of runs, Concurrency how many are in flight
+	// at once
+	Runs        int
+	Concurrency int
+
+	// Message is the user message each run sends
+	Message string
+
+	// Timeout bounds each run, 0 means no limit
+	Timeout time.Duration
+}
+
+// run is the measurements of one run
+type run struct {
+	duration   time.Duration
+	firstEvent time.Duration
+	gaps       []time.Duration
+	events     int
+	tokens     int
+	// streaming is the time from the first text to the end of the run
+	streaming time.Duration
+	err       error
+}
+
+// Stats summarizes a series of samples
+type Stats struct {
+	Count int     `json:"count"`
+	Min   float64 `json:"min"`
+	Mean  float64 `json:"mean"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P95   float64 `json:"p95"`
+	P99   float64 `json:"p99"`
+	Max   float64 `json:"max"`
+}
+
+// Report is the result of a benchmark. Durations are in milliseconds.
+type Report struct {
+	Endpoint    string  `json:"endpoint"`
+	Runs        int     `json:"runs"`
+	Concurrency int     `json:"concurrency"`
+	WallTimeMs  float64 `json:"wallTimeMs"`
+	RunsPerSec  float64 `json:"runsPerSec"`
+
+	Errors       int      `json:"errors"`
+	ErrorRate    float64  `json:"errorRate"`
+	ErrorSamples []string `json:"errorSamples,omitempty"`
+
+	TimeToFirstEventMs Stats `json:"timeToFirstEventMs"`
+	RunDurationMs      Stats `json:"runDurationMs"`
+	EventLatencyMs     Stats `json:"eventLatencyMs"`
+	TokensPerSec       Stats `json:"tokensPerSec"`
+	EventsPerRun       Stats `json:"eventsPerRun"`
+}
+
+// Run benchmarks the endpoint. It returns an error only for an invalid
+// config, failed runs are counted in the report.
+func Run(ctx context.Context, config Config) (*Report, error) {
+	if config.Runs <= 0 {
+		return nil, fmt.Errorf("runs must be positive, got %d", config.Runs)
+	}
+	if config.Concurrency <= 0 {
+		return nil, fmt.Errorf("concurrency must be positive, got %d", config.Concurrency)
+	}
+
+	jobs := make(chan struct{})
+	results := make(chan run)
+	var wg sync.WaitGroup
+	for range min(config.Concurrency, config.Runs) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range jobs {
+				results <- measure(ctx, config)
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for range config.Runs {
+			select {
+			case jobs <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	start := time.Now()
+	var runs []run
+	for r := range results {
+		runs = append(runs, r)
+	}
+	return report(config, runs, time.Since(start)), nil
+}
+
+// measure runs the agent once
+func measure(ctx context.Context, config Config) run {
+	if config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.Timeout)
+		defer cancel()
+	}
+
+	messages := []types.Message{{ID: "msg-" + uuid.NewString(), Role: types.RoleUser, Content: config.Message}}
+	input := agent.NewInput("bench-"+uuid.NewString(), messages, nil)
+
+	var r run
+	var last, firstText time.Time
+	start := time.Now()
+	r.err = agent.Stream(ctx, input, config.Endpoint, func(frame sse.Frame) error {
+		now := time.Now()
+		if r.events == 0 {
+			r.firstEvent = now.Sub(start)
+		} else {
+			r.gaps = append(r.gaps, now.Sub(last))
+		}
+		last = now
+		r.events++
+
+		e, err := event.Parse(frame.Data)
+		if err != nil {
+			return err
+		}
+		var text string
+		switch e := e.(type) {
+		case *events.TextMessageContentEvent:
+			text = e.Delta
+		case *events.TextMessageChunkEvent:
+			if e.Delta != nil {
+				text = *e.Delta
+			}
+		case *events.RunErrorEvent:
+			return fmt.Errorf("run error: %s", e.Message)
+		}
+		if text != "" {
+			if firstText.IsZero() {
+				firstText = now
+			}
+			r.tokens += estimateTokens(text)
+		}
+		return nil
+	})
+	r.duration = time.Since(start)
+	if r.err == nil && r.events == 0 {
+		r.err = fmt.Errorf("no events")
+	}
+	if !firstText.IsZero() {
+		r.streaming = last.Sub(firstText)
+	}
+	return r
+}
+
+// estimateTokens approximates the tokens of streamed text as a quarter of
+// its length, like session.EstimateTokens does for messages
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+func report(config Config, runs []run, wall time.Duration) *Report {
+	rep := &Report{
+		Endpoint:    config.Endpoint.URL,
+		Runs:        len(runs),
+		Concurrency: config.Concurrency,
+		WallTimeMs:  ms(wall),
+	}
+	if wall > 0 {
+		rep.RunsPerSec = float64(len(runs)) / wall.Seconds()
+	}
+
+	var ttfe, durations, gaps, tps, counts []float64
+	seen := map[string]bool{}
+	for _, r := range runs {
+		if r.err != nil {
+			rep.Errors++
+			if msg := r.err.Error(); !seen[msg] && len(rep.ErrorSamples) < maxErrorSamples {
+				seen[msg] = true
+				rep.ErrorSamples = append(rep.ErrorSamples, msg)
+			}
+			continue
+		}
+		ttfe = append(ttfe, ms(r.firstEvent))
+		durations = append(durations, ms(r.duration))
+		for _, gap := range r.gaps {
+			gaps = append(gaps, ms(gap))
+		}
+		if r.streaming > 0 {
+			tps = append(tps, float64(r.tokens)/r.streaming.Seconds())
+		}
+		counts = append(counts, float64(r.events))
+	}
+	if len(runs) > 0 {
+		rep.ErrorRate = float64(rep.Errors) / float64(len(runs))
+	}
+
+	rep.TimeToFirstEventMs = stats(ttfe)
+	rep.RunDurationMs = stats(durations)
+	rep.EventLatencyMs = stats(gaps)
+	rep.TokensPerSec = stats(tps)
+	rep.EventsPerRun = stats(counts)
+	return rep
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func stats(samples []float64) Stats {
+	if len(samples) == 0 {
+		return Stats{}
+	}
+	sort.Float64s(samples)
+	var sum float64
+	for _, s := range samples {
+		sum += s
+	}
+	return Stats{
+		Count: len(samples),
+		Min:   samples[0],
+		Mean:  sum / float64(len(samples)),
+		P50:   percentile(samples, 50),
+		P90:   percentile(samples, 90),
+		P95:   percentile(samples, 95),
+		P99:   percentile(samples, 99),
+		Max:   samples[len(samples)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile p of sorted samples
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank-1, 0)]
+}
+
+// Markdown renders the report as a Markdown document
+func (r *Report) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Benchmark of %s\n\n", r.Endpoint)
+	fmt.Fprintf(&b, "- Runs: %d (concurrency %d)\n", r.Runs, r.Concurrency)
+	fmt.Fprintf(&b, "- Wall time: %.0f ms (%.2f runs/s)\n", r.WallTimeMs, r.RunsPerSec)
+	fmt.Fprintf(&b, "- Errors: %d (%.1f%%)\n", r.Errors, r.ErrorRate*100)
+	for _, sample := range r.ErrorSamples {
+		fmt.Fprintf(&b, "  - `%s`\n", sample)
+	}
+
+	b.WriteString("\n| Metric | Count | Min | Mean | p50 | p90 | p95 | p99 | Max |\n")
+	b.WriteString("|---|---:|---:|---:|---:|---:|---:|---:|---:|\n")
+	for _, row := range []struct {
+		name  string
+		stats Stats
+	}{
+		{"Time to first event (ms)", r.TimeToFirstEventMs},
+		{"Run duration (ms)", r.RunDurationMs},
+		{"Event latency (ms)", r.EventLatencyMs},
+		{"Tokens/s", r.TokensPerSec},
+		{"Events per run", r.EventsPerRun},
+	} {
+		s := row.stats
+		fmt.Fprintf(&b, "| %s | %d | %.1f | %.1f | %.1f | %.1f | %.1f | %.1f | %.1f |\n",
+			row.name, s.Count, s.Min, s.Mean, s.P50, s.P90, s.P95, s.P99, s.Max)
+	}
+	return b.String()
+}
@@ -0,0 +1,124 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/agent"
+)
+
+func TestPercentile(t *testing.T) {
+	ten := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	tests := []struct {
+		name   string
+		sorted []float64
+		p      float64
+		want   float64
+	}{
+		{"single sample", []float64{42}, 50, 42},
+		{"single sample p99", []float64{42}, 99, 42},
+		{"p0 is the minimum", ten, 0, 1},
+		{"p50", ten, 50, 5},
+		{"p90", ten, 90, 9},
+		{"p95 rounds up", ten, 95, 10},
+		{"p99", ten, 99, 10},
+		{"p100 is the maximum", ten, 100, 10},
+		{"two samples p50", []float64{1, 2}, 50, 1},
+		{"two samples p51", []float64{1, 2}, 51, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, percentile(tt.sorted, tt.p))
+		})
+	}
+}
+
+func TestStats(t *testing.T) {
+	assert.Equal(t, Stats{}, stats(nil))
+
+	samples := make([]float64, 0, 100)
+	for i := 100; i > 0; i-- {
+		samples = append(samples, float64(i))
+	}
+	assert.Equal(t, Stats{Count: 100, Min: 1, Mean: 50.5, P50: 50, P90: 90, P95: 95, P99: 99, Max: 100}, stats(samples))
+}
+
+func TestReport(t *testing.T) {
+	runs := []run{
+		{duration: 100 * time.Millisecond, firstEvent: 10 * time.Millisecond, gaps: []time.Duration{time.Millisecond, 3 * time.Millisecond}, events: 3, tokens: 10, streaming: time.Second},
+		{duration: 300 * time.Millisecond, firstEvent: 30 * time.Millisecond, events: 1},
+	}
+	for i := range maxErrorSamples + 2 {
+		runs = append(runs, run{err: fmt.Errorf("error %d", i)}, run{err: fmt.Errorf("error %d", i)})
+	}
+
+	rep := report(Config{Endpoint: agent.Endpoint{URL: "http://agent"}, Concurrency: 4}, runs, 2*time.Second)
+	assert.Equal(t, "http://agent", rep.Endpoint)
+	assert.Equal(t, 16, rep.Runs)
+	assert.Equal(t, 8.0, rep.RunsPerSec)
+	assert.Equal(t, 14, rep.Errors)
+	assert.Equal(t, 14.0/16, rep.ErrorRate)
+	assert.Equal(t, []string{"error 0", "error 1", "error 2", "error 3", "error 4"}, rep.ErrorSamples, "distinct errors, at most maxErrorSamples")
+
+	assert.Equal(t, Stats{Count: 2, Min: 10, Mean: 20, P50: 10, P90: 30, P95: 30, P99: 30, Max: 30}, rep.TimeToFirstEventMs)
+	assert.Equal(t, Stats{Count: 2, Min: 100, Mean: 200, P50: 100, P90: 300, P95: 300, P99: 300, Max: 300}, rep.RunDurationMs)
+	assert.Equal(t, Stats{Count: 2, Min: 1, Mean: 2, P50: 1, P90: 3, P95: 3, P99: 3, Max: 3}, rep.EventLatencyMs)
+	assert.Equal(t, Stats{Count: 1, Min: 10, Mean: 10, P50: 10, P90: 10, P95: 10, P99: 10, Max: 10}, rep.TokensPerSec, "runs without text have no rate")
+	assert.Equal(t, 2, rep.EventsPerRun.Count)
+
+	assert.Contains(t, rep.Markdown(), "| Run duration (ms) | 2 | 100.0 | 200.0 | 100.0 | 300.0 | 300.0 | 300.0 | 300.0 |")
+	assert.Contains(t, rep.Markdown(), "- Errors: 14 (87.5%)")
+}
+
+func TestRun(t *testing.T) {
+	frames := []string{
+		`{"type":"RUN_STARTED","threadId":"thread-1","runId":"run-1"}`,
+		`{"type":"TEXT_MESSAGE_START","messageId":"msg-1","role":"assistant"}`,
+		`{"type":"TEXT_MESSAGE_CONTENT","messageId":"msg-1","delta":"Hello there"}`,
+		`{"type":"TEXT_MESSAGE_END","messageId":"msg-1"}`,
+		`{"type":"RUN_FINISHED","threadId":"thread-1","runId":"run-1"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, frame := range frames {
+			fmt.Fprintf(w, "data: %s\n\n", frame)
+		}
+	}))
+	defer server.Close()
+
+	rep, err := Run(context.Background(), Config{Endpoint: agent.Endpoint{URL: server.URL}, Runs: 5, Concurrency: 2, Message: "Hi"})
+	require.NoError(t, err)
+	assert.Equal(t, 5, rep.Runs)
+	assert.Zero(t, rep.Errors, rep.ErrorSamples)
+	assert.Equal(t, Stats{Count: 5, Min: 5, Mean: 5, P50: 5, P90: 5, P95: 5, P99: 5, Max: 5}, rep.EventsPerRun)
+	assert.Equal(t, 5*4, rep.EventLatencyMs.Count)
+}
+
+func TestRunErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"type\":\"RUN_ERROR\",\"message\":\"overloaded\"}\n\n")
+	}))
+	defer server.Close()
+
+	rep, err := Run(context.Background(), Config{Endpoint: agent.Endpoint{URL: server.URL}, Runs: 3, Concurrency: 3})
+	require.NoError(t, err)
+	assert.Equal(t, 3, rep.Errors)
+	assert.Equal(t, 1.0, rep.ErrorRate)
+	require.Len(t, rep.ErrorSamples, 1)
+	assert.Contains(t, rep.ErrorSamples[0], "run error: overloaded")
+}
+
+func TestRunInvalidConfig(t *testing.T) {
+	for _, config := range []Config{{Runs: 0, Concurrency: 1}, {Runs: 1, Concurrency: 0}} {
+		_, err := Run(context.Background(), config)
+		assert.ErrorContains(t, err, "must be positive")
+	}
+}
@@ -0,0 +1,216 @@
+// Package bundle packs a session into a signed file that can be handed to a
+// teammate or another machine. The bundle is signed with the sender's
+// ed25519 key, so the receiver can check who produced it and that it was
+// not altered, and it can be encrypted with a passphrase.
+package bundle
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/session"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Version is the bundle format written by Create
+const Version = 1
+
+// schemeScrypt derives an AES-256-GCM key from the passphrase with scrypt
+const schemeScrypt = "scrypt-aes-256-gcm"
+
+// signingContext separates bundle signatures from anything else signed
+// with the same key
+const signingContext = "ag-ui-session-bundle\x00"
+
+var (
+	// ErrUnsigned is returned when verifying a bundle without a signature
+	ErrUnsigned = errors.New("bundle is not signed")
+
+	// ErrInvalidSignature is returned when a bundle was altered or its
+	// signature does not match its signer
+	ErrInvalidSignature = errors.New("invalid bundle signature")
+
+	// ErrPassphraseRequired is returned when opening an encrypted bundle
+	// without a passphrase
+	ErrPassphraseRequired = errors.New("bundle is encrypted, a passphrase is required")
+)
+
+// Bundle is a session as written to the bundle file. Payload holds the
+// session JSON, encrypted when Encryption is set.
+type Bundle struct {
+	Version    int         `json:"version"`
+	Encryption *Encryption `json:"encryption,omitempty"`
+	Payload    []byte      `json:"payload"`
+
+	// Signer is the public key the bundle was signed with
+	Signer    string `json:"signer,omitempty"`
+	Signature []byte `json:"signature,omitempty"`
+}
+
+// Encryption describes how the payload was encrypted
+type Encryption struct {
+	Scheme string `json:"scheme"`
+	Salt   []byte `json:"salt"`
+}
+
+// Create bundles s signed with key. A non-empty passphrase encrypts it.
+func Create(s *session.Session, key ed25519.PrivateKey, passphrase string) (*Bundle, error) {
+	payload, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode session %s: %w", s.ID, err)
+	}
+
+	b := &Bundle{Version: Version, Payload: payload}
+	if passphrase != "" {
+		b.Encryption = &Encryption{Scheme: schemeScrypt, Salt: make([]byte, 16)}
+		if _, err := rand.Read(b.Encryption.Salt); err != nil {
+			return nil, err
+		}
+		cipher, err := b.Encryption.cipher(passphrase)
+		if err != nil {
+			return nil, err
+		}
+		if b.Payload, err = cipher.Encrypt(payload, b.Encryption.Salt); err != nil {
+			return nil, err
+		}
+	}
+
+	b.Signer = FormatPublicKey(key.Public().(ed25519.PublicKey))
+	b.Signature = ed25519.Sign(key, b.signedData())
+	return b, nil
+}
+
+// Read decodes a bundle file
+func Read(data []byte) (*Bundle, error) {
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	if b.Version != Version {
+		return nil, fmt.Errorf("unsupported bundle version %d", b.Version)
+	}
+	return &b, nil
+}
+
+// Verify checks the signature and returns the signer's public key
+func (b *Bundle) Verify() (ed25519.PublicKey, error) {
+	if b.Signer == "" || len(b.Signature) == 0 {
+		return nil, ErrUnsigned
+	}
+	signer, err := ParsePublicKey(b.Signer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	if !ed25519.Verify(signer, b.signedData(), b.Signature) {
+		return nil, ErrInvalidSignature
+	}
+	return signer, nil
+}
+
+// Open returns the bundled session, decrypting it with passphrase
+func (b *Bundle) Open(passphrase string) (*session.Session, error) {
+	payload := b.Payload
+	if b.Encryption != nil {
+		if passphrase == "" {
+			return nil, ErrPassphraseRequired
+		}
+		cipher, err := b.Encryption.cipher(passphrase)
+		if err != nil {
+			return nil, err
+		}
+		if payload, err = cipher.Decrypt(b.Payload, b.Encryption.Salt); err != nil {
+			return nil, fmt.Errorf("failed to decrypt bundle (wrong passphrase?): %w", err)
+		}
+	}
+
+	var s session.Session
+	if err := json.Unmarshal(payload, &s); err != nil {
+		return nil, fmt.Errorf("invalid bundled session: %w", err)
+	}
+	if s.ID == "" {
+		return nil, session.ErrInvalidID
+	}
+	return &s, nil
+}
+
+// signedData is what the signature covers: everything in the bundle but
+// the signature itself
+func (b *Bundle) signedData() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(signingContext)
+	buf.WriteByte(byte(b.Version))
+	if b.Encryption != nil {
+		buf.WriteString(b.Encryption.Scheme)
+		buf.WriteByte(0)
+		buf.Write(b.Encryption.Salt)
+	}
+	buf.WriteByte(0)
+	buf.WriteString(b.Signer)
+	buf.WriteByte(0)
+	buf.Write(b.Payload)
+	return buf.Bytes()
+}
+
+func (e *Encryption) cipher(passphrase string) (*session.AESGCMCipher, error) {
+	if e.Scheme != schemeScrypt {
+		return nil, fmt.Errorf("unsupported bundle encryption %q", e.Scheme)
+	}
+	material, err := scrypt.Key([]byte(passphrase), e.Salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	keyring, err := session.NewKeyring(session.Key{ID: e.Scheme, Material: material})
+	if err != nil {
+		return nil, err
+	}
+	return session.NewAESGCMCipher(keyring), nil
+}
+
+// FormatPublicKey encodes a public key as shown to users
+func FormatPublicKey(key ed25519.PublicKey) string {
+	return base64.RawURLEncoding.EncodeToString(key)
+}
+
+// ParsePublicKey decodes a key encoded with FormatPublicKey
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	key, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key %q", s)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// LoadKey reads the signing key stored at path, generating it on first use
+func LoadKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		seed, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("invalid signing key in %s", path)
+		}
+		return ed25519.NewKeyFromSeed(seed), nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	encoded := base64.StdEncoding.EncodeToString(key.Seed()) + "\n"
+	if err := os.WriteFile(path, []byte(encoded), 0o600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
@@ -0,0 +1,141 @@
+package bundle
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/session"
+)
+
+func newSession() *session.Session {
+	s := session.New("thread-1")
+	s.AppendMessages(types.Message{ID: "msg-1", Role: types.RoleUser, Content: "Hello"})
+	return s
+}
+
+func newKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func TestRoundTrip(t *testing.T) {
+	key := newKey(t)
+	for _, passphrase := range []string{"", "correct horse"} {
+		t.Run("passphrase="+passphrase, func(t *testing.T) {
+			b, err := Create(newSession(), key, passphrase)
+			require.NoError(t, err)
+			assert.Equal(t, passphrase != "", b.Encryption != nil)
+			if passphrase != "" {
+				assert.NotContains(t, string(b.Payload), "Hello", "the payload is encrypted")
+			}
+
+			data, err := json.Marshal(b)
+			require.NoError(t, err)
+			read, err := Read(data)
+			require.NoError(t, err)
+			signer, err := read.Verify()
+			require.NoError(t, err)
+			assert.Equal(t, key.Public(), signer)
+
+			s, err := read.Open(passphrase)
+			require.NoError(t, err)
+			assert.Equal(t, "thread-1", s.ID)
+			require.Len(t, s.Messages, 1)
+			assert.Equal(t, "Hello", s.Messages[0].Content)
+		})
+	}
+}
+
+func TestVerifyTampered(t *testing.T) {
+	other := FormatPublicKey(newKey(t).Public().(ed25519.PublicKey))
+	tests := []struct {
+		name   string
+		tamper func(b *Bundle)
+	}{
+		{"payload", func(b *Bundle) { b.Payload[0] ^= 1 }},
+		{"salt", func(b *Bundle) { b.Encryption.Salt[0] ^= 1 }},
+		{"scheme", func(b *Bundle) { b.Encryption.Scheme = "none" }},
+		{"encryption removed", func(b *Bundle) { b.Encryption = nil }},
+		{"signer", func(b *Bundle) { b.Signer = other }},
+		{"malformed signer", func(b *Bundle) { b.Signer = "not a key" }},
+		{"version", func(b *Bundle) { b.Version++ }},
+		{"signature", func(b *Bundle) { b.Signature[0] ^= 1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := Create(newSession(), newKey(t), "secret")
+			require.NoError(t, err)
+			tt.tamper(b)
+			_, err = b.Verify()
+			assert.ErrorIs(t, err, ErrInvalidSignature)
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	encrypted, err := Create(newSession(), newKey(t), "secret")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		err  func() error
+		want error
+	}{
+		{"unsigned", func() error {
+			b := *encrypted
+			b.Signature = nil
+			_, err := b.Verify()
+			return err
+		}, ErrUnsigned},
+		{"no signer", func() error {
+			b := *encrypted
+			b.Signer = ""
+			_, err := b.Verify()
+			return err
+		}, ErrUnsigned},
+		{"passphrase required", func() error {
+			_, err := encrypted.Open("")
+			return err
+		}, ErrPassphraseRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.err(), tt.want)
+		})
+	}
+
+	_, err = encrypted.Open("wrong")
+	assert.ErrorContains(t, err, "wrong passphrase")
+
+	data, err := json.Marshal(&Bundle{Version: Version + 1})
+	require.NoError(t, err)
+	_, err = Read(data)
+	assert.ErrorContains(t, err, "unsupported bundle version")
+}
+
+func TestLoadKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "signing.key")
+	key, err := LoadKey(path)
+	require.NoError(t, err)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	reloaded, err := LoadKey(path)
+	require.NoError(t, err)
+	assert.Equal(t, key, reloaded)
+
+	require.NoError(t, os.WriteFile(path, []byte("garbage"), 0o600))
+	_, err = LoadKey(path)
+	assert.ErrorContains(t, err, "invalid signing key")
+}
@@ -0,0 +1,242 @@
+// Package config holds the settings of the client and the credentials it
+// sends to agents. Settings live in a JSON file in the user's config
+// directory. Secrets live in the OS keychain, with the environment and the
+// config file as fallbacks for machines without one. The sensitive values of
+// the file can be encrypted with a master key, so the file can be checked in.
+//
+// The file holds a default profile at the top level and named profiles,
+// e.g. for dev, staging and prod, under "profiles". Named profiles inherit
+// every setting they leave empty from the default profile:
+//
+//	{
+//	  "endpoint": "http://localhost:8000/agentic",
+//	  "profiles": {
+//	    "prod": {"endpoint": "https://agents.example.com/agentic"}
+//	  }
+//	}
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+)
+
+// DefaultProfile is the name of the top level profile
+const DefaultProfile = "default"
+
+// Settings are the settings of one profile
+type Settings struct {
+	Endpoint  string `json:"endpoint,omitempty"`
+	Approvals string `json:"approvals,omitempty"`
+
+	// APIKey is only used when the key is neither in the environment nor in
+	// the keychain. Prefer set-secret, which keeps it out of this file.
+	APIKey string `json:"apiKey,omitempty"`
+
+	// Output is the default --output format of chat, stream and replay
+	Output string `json:"output,omitempty"`
+
+	// NotifyHook is a shell command run instead of the desktop notification
+	// when a run started with --notify ends
+	NotifyHook string `json:"notifyHook,omitempty"`
+}
+
+// Config is the content of the config file
+type Config struct {
+	Settings
+	Profiles map[string]*Settings `json:"profiles,omitempty"`
+
+	// encrypted is set when Save encrypts the sensitive values
+	encrypted bool
+}
+
+// fields maps the keys of the config commands to the fields of Settings
+func (s *Settings) fields() map[string]*string {
+	return map[string]*string{
+		"endpoint":    &s.Endpoint,
+		"approvals":   &s.Approvals,
+		APIKeyName:    &s.APIKey,
+		"notify-hook": &s.NotifyHook,
+		"output":      &s.Output,
+	}
+}
+
+// Keys returns the keys accepted by Get and Set
+func Keys() []string {
+	var keys []string
+	for key := range (&Settings{}).fields() {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *Settings) Get(key string) (string, error) {
+	field, ok := s.fields()[key]
+	if !ok {
+		return "", fmt.Errorf("unknown config key %q", key)
+	}
+	return *field, nil
+}
+
+// Set changes a setting, an empty value unsets it
+func (s *Settings) Set(key, value string) error {
+	field, ok := s.fields()[key]
+	if !ok {
+		return fmt.Errorf("unknown config key %q", key)
+	}
+	*field = value
+	return nil
+}
+
+// ProfileNames returns the names of all profiles, the default one first
+func (c *Config) ProfileNames() []string {
+	var names []string
+	for name := range c.Profiles {
+		if name != DefaultProfile {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...)
+}
+
+// Own returns the settings stored for a profile, without inherited ones.
+// With create, a missing profile is added to the config.
+func (c *Config) Own(name string, create bool) (*Settings, error) {
+	if name == "" || name == DefaultProfile {
+		return &c.Settings, nil
+	}
+	settings, ok := c.Profiles[name]
+	if !ok {
+		if !create {
+			return nil, fmt.Errorf("unknown profile %q", name)
+		}
+		if c.Profiles == nil {
+			c.Profiles = map[string]*Settings{}
+		}
+		settings = &Settings{}
+		c.Profiles[name] = settings
+	}
+	return settings, nil
+}
+
+// Profile returns the effective settings of a profile, those it leaves
+// empty taken from the default profile
+func (c *Config) Profile(name string) (*Profile, error) {
+	own, err := c.Own(name, false)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = DefaultProfile
+	}
+
+	p := &Profile{Name: name, Settings: *own, config: c}
+	inherited := p.Settings.fields()
+	for key, value := range c.Settings.fields() {
+		if *inherited[key] == "" {
+			*inherited[key] = *value
+		}
+	}
+	return p, nil
+}
+
+// Profile is the effective settings of a profile. The sensitive settings
+// may be encrypted in Settings; Get and LookupAPIKey decrypt them.
+type Profile struct {
+	Name string
+	Settings
+
+	config *Config
+}
+
+// Get returns a setting of the profile, decrypted if it is encrypted in
+// the config file
+func (p *Profile) Get(key string) (string, error) {
+	value, err := p.Settings.Get(key)
+	if err != nil || !slices.Contains(sensitiveKeys, key) {
+		return value, err
+	}
+	source := p.Name
+	if p.Inherited(key) {
+		source = DefaultProfile
+	}
+	return p.config.reveal(source, key)
+}
+
+// Inherited reports whether the setting key of the profile comes from the
+// default profile
+func (p *Profile) Inherited(key string) bool {
+	if p.Name == DefaultProfile {
+		return false
+	}
+	own, err := p.config.Own(p.Name, false)
+	if err != nil {
+		return false
+	}
+	value, _ := own.Get(key)
+	inherited, _ := p.Settings.Get(key)
+	return value == "" && inherited != ""
+}
+
+// DefaultPath returns the path of the config file
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "ag-ui", "config.json")
+}
+
+// Load reads the config file at path. A missing file is an empty config.
+func Load(path string) (*Config, error) {
+	c := &Config{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	c.encrypted = c.sealed()
+	return c, nil
+}
+
+// Save writes the config to path, readable only by the user, encrypting
+// the sensitive values when the config is encrypted and decrypting them
+// otherwise
+func (c *Config) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if c.encrypted || c.sealed() {
+		out := &Config{}
+		if err := json.Unmarshal(data, out); err != nil {
+			return err
+		}
+		transform := out.decrypt
+		if c.encrypted {
+			transform = out.encrypt
+		}
+		if err := transform(); err != nil {
+			return err
+		}
+		if data, err = json.MarshalIndent(out, "", "  "); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
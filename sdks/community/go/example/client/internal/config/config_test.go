@@ -0,0 +1,144 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newProfilesConfig returns a config whose prod profile overrides the
+// endpoint of the default profile
+func newProfilesConfig(t *testing.T) *Config {
+	t.Helper()
+	c := &Config{}
+	require.NoError(t, c.Set("endpoint", "http://localhost:8000/agentic"))
+	require.NoError(t, c.Set("approvals", "ask"))
+	prod, err := c.Own("prod", true)
+	require.NoError(t, err)
+	require.NoError(t, prod.Set("endpoint", "https://agents.example.com/agentic"))
+	_, err = c.Own("dev", true)
+	require.NoError(t, err)
+	return c
+}
+
+func TestProfile(t *testing.T) {
+	c := newProfilesConfig(t)
+	tests := []struct {
+		profile   string
+		endpoint  string
+		name      string
+		inherited map[string]bool
+	}{
+		{
+			profile:   "",
+			name:      DefaultProfile,
+			endpoint:  "http://localhost:8000/agentic",
+			inherited: map[string]bool{"endpoint": false, "approvals": false},
+		},
+		{
+			profile:   DefaultProfile,
+			name:      DefaultProfile,
+			endpoint:  "http://localhost:8000/agentic",
+			inherited: map[string]bool{"endpoint": false, "approvals": false},
+		},
+		{
+			profile:   "prod",
+			name:      "prod",
+			endpoint:  "https://agents.example.com/agentic",
+			inherited: map[string]bool{"endpoint": false, "approvals": true, "output": false},
+		},
+		{
+			profile:   "dev",
+			name:      "dev",
+			endpoint:  "http://localhost:8000/agentic",
+			inherited: map[string]bool{"endpoint": true, "approvals": true, "output": false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"/"+tt.profile, func(t *testing.T) {
+			p, err := c.Profile(tt.profile)
+			require.NoError(t, err)
+			assert.Equal(t, tt.name, p.Name)
+			assert.Equal(t, tt.endpoint, p.Endpoint)
+			assert.Equal(t, "ask", p.Approvals)
+			for key, want := range tt.inherited {
+				assert.Equal(t, want, p.Inherited(key), key)
+			}
+		})
+	}
+
+	_, err := c.Profile("staging")
+	assert.ErrorContains(t, err, `unknown profile "staging"`)
+}
+
+func TestProfileOwnSettings(t *testing.T) {
+	c := newProfilesConfig(t)
+	p, err := c.Profile("dev")
+	require.NoError(t, err)
+	p.Endpoint = "changed"
+
+	dev, err := c.Own("dev", false)
+	require.NoError(t, err)
+	assert.Empty(t, dev.Endpoint, "the effective settings are a copy")
+	assert.Equal(t, "http://localhost:8000/agentic", c.Endpoint)
+
+	// Changing the default profile changes what the others inherit
+	require.NoError(t, c.Set("endpoint", "http://localhost:9000/agentic"))
+	p, err = c.Profile("dev")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:9000/agentic", p.Endpoint)
+	p, err = c.Profile("prod")
+	require.NoError(t, err)
+	assert.Equal(t, "https://agents.example.com/agentic", p.Endpoint)
+}
+
+func TestProfileNames(t *testing.T) {
+	assert.Equal(t, []string{DefaultProfile}, (&Config{}).ProfileNames())
+	assert.Equal(t, []string{DefaultProfile, "dev", "prod"}, newProfilesConfig(t).ProfileNames())
+
+	_, err := (&Config{}).Own("prod", false)
+	assert.ErrorContains(t, err, `unknown profile "prod"`)
+}
+
+func TestSettingsKeys(t *testing.T) {
+	assert.Equal(t, []string{APIKeyName, "approvals", "endpoint", "notify-hook", "output"}, Keys())
+
+	var s Settings
+	for _, key := range Keys() {
+		require.NoError(t, s.Set(key, key+"-value"))
+		value, err := s.Get(key)
+		require.NoError(t, err)
+		assert.Equal(t, key+"-value", value)
+	}
+	assert.ErrorContains(t, s.Set("colour", "red"), `unknown config key "colour"`)
+	_, err := s.Get("colour")
+	assert.ErrorContains(t, err, `unknown config key "colour"`)
+}
+
+func TestLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ag-ui", "config.json")
+	c, err := Load(path)
+	require.NoError(t, err, "a missing file is an empty config")
+	assert.Equal(t, &Config{}, c)
+
+	c = newProfilesConfig(t)
+	require.NoError(t, c.Save(path))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.False(t, loaded.Encrypted())
+	p, err := loaded.Profile("prod")
+	require.NoError(t, err)
+	assert.Equal(t, "https://agents.example.com/agentic", p.Endpoint)
+	assert.True(t, p.Inherited("approvals"))
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+	_, err = Load(path)
+	assert.ErrorContains(t, err, "invalid config file")
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	// MasterKeyEnv holds the base64 key that encrypts the sensitive values
+	// of the config file, overriding the key in the keychain
+	MasterKeyEnv = "AG_UI_CONFIG_KEY"
+
+	// masterKeyAccount is the keychain account of the master key
+	masterKeyAccount = "config-key"
+
+	// encPrefix and encSuffix wrap encrypted values, sops style:
+	// ENC[AES256_GCM,data:...,iv:...]
+	encPrefix = "ENC[AES256_GCM,"
+	encSuffix = "]"
+)
+
+// ErrNoMasterKey is returned when an encrypted config file is read without
+// a master key in the environment or the keychain
+var ErrNoMasterKey = fmt.Errorf("no config master key, set %s to the key the file was encrypted with", MasterKeyEnv)
+
+// sensitiveKeys are the settings encrypted in an encrypted config file;
+// the notify hook may carry webhook tokens
+var sensitiveKeys = []string{APIKeyName, "notify-hook"}
+
+// Encrypted reports whether the sensitive values are encrypted in the file
+func (c *Config) Encrypted() bool {
+	return c.encrypted
+}
+
+// SetEncrypted changes whether Save encrypts the sensitive values
+func (c *Config) SetEncrypted(encrypted bool) {
+	c.encrypted = encrypted
+}
+
+// MasterKey returns the key of encrypted config files from the environment,
+// then from the OS keychain
+func MasterKey() ([]byte, Source, error) {
+	encoded, source := os.Getenv(MasterKeyEnv), SourceEnv
+	if encoded == "" {
+		var err error
+		if encoded, err = keyring.Get(keyringService, masterKeyAccount); err != nil {
+			return nil, SourceNone, ErrNoMasterKey
+		}
+		source = SourceKeychain
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != 32 {
+		return nil, source, fmt.Errorf("invalid config master key in the %s: want 32 bytes in base64", source)
+	}
+	return key, source, nil
+}
+
+// NewMasterKey generates a master key and stores it in the OS keychain. It
+// returns the key in base64, to set MasterKeyEnv on other machines.
+func NewMasterKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	encoded := base64.StdEncoding.EncodeToString(key)
+	if err := keyring.Set(keyringService, masterKeyAccount, encoded); err != nil {
+		return encoded, fmt.Errorf("%w: %v", ErrKeychainUnavailable, err)
+	}
+	return encoded, nil
+}
+
+// isEncrypted reports whether a value of the file is encrypted
+func isEncrypted(value string) bool {
+	return strings.HasPrefix(value, encPrefix) && strings.HasSuffix(value, encSuffix)
+}
+
+// additionalData binds an encrypted value to its setting: the profile and
+// key it was encrypted for, so that it cannot be moved to another setting
+// or profile
+func additionalData(profile, key string) []byte {
+	return []byte(profile + "\x00" + key)
+}
+
+// encryptValue seals the value of key in profile with AES-GCM
+func encryptValue(aead cipher.AEAD, profile, key, value string) (string, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nil, nonce, []byte(value), additionalData(profile, key))
+	return encPrefix + "data:" + base64.StdEncoding.EncodeToString(sealed) +
+		",iv:" + base64.StdEncoding.EncodeToString(nonce) + encSuffix, nil
+}
+
+func decryptValue(aead cipher.AEAD, profile, key, value string) (string, error) {
+	fields := make(map[string][]byte)
+	for _, field := range strings.Split(strings.TrimSuffix(strings.TrimPrefix(value, encPrefix), encSuffix), ",") {
+		name, encoded, _ := strings.Cut(field, ":")
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return "", fmt.Errorf("invalid encrypted %s", key)
+		}
+		fields[name] = data
+	}
+	if len(fields["iv"]) != aead.NonceSize() {
+		return "", fmt.Errorf("invalid encrypted %s", key)
+	}
+	plain, err := aead.Open(nil, fields["iv"], fields["data"], additionalData(profile, key))
+	if err != nil {
+		return "", fmt.Errorf("cannot decrypt %s, wrong config master key?", key)
+	}
+	return string(plain), nil
+}
+
+// crypt applies fn to the sensitive values of every profile, naming them
+// profile/key in errors
+func (c *Config) crypt(fn func(profile, key, value string) (string, error)) error {
+	for _, name := range c.ProfileNames() {
+		own, err := c.Own(name, false)
+		if err != nil {
+			continue
+		}
+		fields := own.fields()
+		for _, key := range sensitiveKeys {
+			if *fields[key] == "" {
+				continue
+			}
+			value, err := fn(name, key, *fields[key])
+			if err != nil {
+				return fmt.Errorf("%s/%s: %w", name, key, err)
+			}
+			*fields[key] = value
+		}
+	}
+	return nil
+}
+
+// sealed reports whether values are encrypted in the config. Values read
+// from the file are only decrypted when read, see reveal, so that a missing
+// master key only fails the commands needing them.
+func (c *Config) sealed() bool {
+	sealed := false
+	_ = c.crypt(func(_, _, value string) (string, error) {
+		sealed = sealed || isEncrypted(value)
+		return value, nil
+	})
+	return sealed
+}
+
+// reveal returns the value of the sensitive setting key of profile,
+// decrypting it if needed
+func (c *Config) reveal(profile, key string) (string, error) {
+	own, err := c.Own(profile, false)
+	if err != nil {
+		return "", err
+	}
+	value := *own.fields()[key]
+	if !isEncrypted(value) {
+		return value, nil
+	}
+	aead, err := masterAEAD()
+	if err == nil {
+		value, err = decryptValue(aead, profile, key, value)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s/%s: %w", profile, key, err)
+	}
+	return value, nil
+}
+
+// decrypt decrypts the values still encrypted, before they are written in
+// plaintext
+func (c *Config) decrypt() error {
+	var aead cipher.AEAD
+	return c.crypt(func(profile, key, value string) (string, error) {
+		if !isEncrypted(value) {
+			return value, nil
+		}
+		if aead == nil {
+			var err error
+			if aead, err = masterAEAD(); err != nil {
+				return "", err
+			}
+		}
+		return decryptValue(aead, profile, key, value)
+	})
+}
+
+// encrypt encrypts the sensitive values before they are written
+func (c *Config) encrypt() error {
+	aead, err := masterAEAD()
+	if err != nil {
+		return err
+	}
+	return c.crypt(func(profile, key, value string) (string, error) {
+		if isEncrypted(value) {
+			// Values still encrypted are written as read, once checked to
+			// be encrypted with the same master key
+			if _, err := decryptValue(aead, profile, key, value); err != nil {
+				return "", err
+			}
+			return value, nil
+		}
+		return encryptValue(aead, profile, key, value)
+	})
+}
+
+func masterAEAD() (cipher.AEAD, error) {
+	key, _, err := MasterKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
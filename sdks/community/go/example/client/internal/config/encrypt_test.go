@@ -0,0 +1,193 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+)
+
+// setMasterKey sets a random master key in the environment, with an empty
+// keychain
+func setMasterKey(t *testing.T) {
+	t.Helper()
+	keyring.MockInit()
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	t.Setenv(MasterKeyEnv, base64.StdEncoding.EncodeToString(key))
+}
+
+func newEncryptedConfig() *Config {
+	c := &Config{}
+	c.APIKey = "sk-default"
+	c.NotifyHook = "curl https://hooks.example.com/token"
+	c.Endpoint = "http://localhost:8000/agentic"
+	prod, _ := c.Own("prod", true)
+	prod.APIKey = "sk-prod"
+	c.SetEncrypted(true)
+	return c
+}
+
+// readFile returns the raw settings of the config file at path
+func readFile(t *testing.T, path string) *Config {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	raw := &Config{}
+	require.NoError(t, json.Unmarshal(data, raw))
+	return raw
+}
+
+// get returns the setting key of a profile of c
+func get(t *testing.T, c *Config, profile, key string) (string, error) {
+	t.Helper()
+	p, err := c.Profile(profile)
+	require.NoError(t, err)
+	return p.Get(key)
+}
+
+func TestEncryptedRoundTrip(t *testing.T) {
+	setMasterKey(t)
+	path := filepath.Join(t.TempDir(), "config.json")
+	c := newEncryptedConfig()
+	require.NoError(t, c.Save(path))
+
+	assert.Equal(t, "sk-default", c.APIKey, "Save leaves the config in memory in plaintext")
+	assert.Equal(t, "sk-prod", c.Profiles["prod"].APIKey)
+
+	raw := readFile(t, path)
+	assert.True(t, isEncrypted(raw.APIKey))
+	assert.True(t, isEncrypted(raw.NotifyHook))
+	assert.True(t, isEncrypted(raw.Profiles["prod"].APIKey))
+	assert.Equal(t, "http://localhost:8000/agentic", raw.Endpoint, "only sensitive values are encrypted")
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.True(t, loaded.Encrypted())
+	for _, tt := range []struct{ profile, key, want string }{
+		{DefaultProfile, APIKeyName, "sk-default"},
+		{DefaultProfile, "notify-hook", "curl https://hooks.example.com/token"},
+		{DefaultProfile, "endpoint", "http://localhost:8000/agentic"},
+		{"prod", APIKeyName, "sk-prod"},
+		{"prod", "notify-hook", "curl https://hooks.example.com/token"},
+	} {
+		value, err := get(t, loaded, tt.profile, tt.key)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, value, "%s/%s", tt.profile, tt.key)
+	}
+
+	// Values left encrypted are written back as read
+	require.NoError(t, loaded.Settings.Set("output", "json"))
+	require.NoError(t, loaded.Save(path))
+	rewritten := readFile(t, path)
+	assert.Equal(t, raw.APIKey, rewritten.APIKey)
+	assert.Equal(t, raw.Profiles["prod"].APIKey, rewritten.Profiles["prod"].APIKey)
+	assert.Equal(t, "json", rewritten.Output)
+
+	// Decrypting writes them in plaintext
+	loaded.SetEncrypted(false)
+	require.NoError(t, loaded.Save(path))
+	rewritten = readFile(t, path)
+	assert.Equal(t, "sk-default", rewritten.APIKey)
+	assert.Equal(t, "sk-prod", rewritten.Profiles["prod"].APIKey)
+	assert.Equal(t, "curl https://hooks.example.com/token", rewritten.NotifyHook)
+}
+
+func TestEncryptedErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		tamper  func(raw *Config)
+		profile string
+		key     string
+		wantErr string
+	}{
+		{
+			name:    "api key swapped into notify hook",
+			tamper:  func(raw *Config) { raw.NotifyHook = raw.APIKey },
+			profile: DefaultProfile,
+			key:     "notify-hook",
+			wantErr: "default/notify-hook: cannot decrypt notify-hook",
+		},
+		{
+			name:    "api key copied to another profile",
+			tamper:  func(raw *Config) { raw.Profiles["prod"].APIKey = raw.APIKey },
+			profile: "prod",
+			key:     APIKeyName,
+			wantErr: "prod/" + APIKeyName + ": cannot decrypt " + APIKeyName,
+		},
+		{
+			name:    "invalid base64",
+			tamper:  func(raw *Config) { raw.APIKey = encPrefix + "data:!!!,iv:AAAA" + encSuffix },
+			profile: DefaultProfile,
+			key:     APIKeyName,
+			wantErr: "default/" + APIKeyName + ": invalid encrypted " + APIKeyName,
+		},
+		{
+			name:    "missing iv",
+			tamper:  func(raw *Config) { raw.APIKey = strings.Split(raw.APIKey, ",iv:")[0] + encSuffix },
+			profile: DefaultProfile,
+			key:     APIKeyName,
+			wantErr: "invalid encrypted " + APIKeyName,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setMasterKey(t)
+			path := filepath.Join(t.TempDir(), "config.json")
+			require.NoError(t, newEncryptedConfig().Save(path))
+
+			raw := readFile(t, path)
+			tt.tamper(raw)
+			data, err := json.Marshal(raw)
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(path, data, 0o600))
+
+			// Values are only decrypted when read
+			c, err := Load(path)
+			require.NoError(t, err)
+			_, err = get(t, c, tt.profile, tt.key)
+			assert.ErrorContains(t, err, tt.wantErr)
+			assert.ErrorContains(t, c.Save(path), tt.wantErr, "values that cannot be decrypted are not written back")
+		})
+	}
+}
+
+func TestEncryptedWithoutMasterKey(t *testing.T) {
+	setMasterKey(t)
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, newEncryptedConfig().Save(path))
+
+	t.Setenv(MasterKeyEnv, "")
+	c, err := Load(path)
+	require.NoError(t, err, "loading needs no master key")
+	endpoint, err := get(t, c, DefaultProfile, "endpoint")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8000/agentic", endpoint)
+	_, err = get(t, c, DefaultProfile, "notify-hook")
+	assert.ErrorIs(t, err, ErrNoMasterKey)
+
+	prod, err := c.Profile("prod")
+	require.NoError(t, err)
+	_, _, err = prod.LookupAPIKey()
+	assert.ErrorIs(t, err, ErrNoMasterKey)
+	t.Setenv(APIKeyEnv, "sk-env")
+	key, source, err := prod.LookupAPIKey()
+	require.NoError(t, err, "a key in the environment needs no master key")
+	assert.Equal(t, "sk-env", key)
+	assert.Equal(t, SourceEnv, source)
+
+	assert.ErrorIs(t, c.Save(path), ErrNoMasterKey)
+	assert.ErrorIs(t, newEncryptedConfig().Save(filepath.Join(t.TempDir(), "config.json")), ErrNoMasterKey)
+
+	t.Setenv(MasterKeyEnv, "c2hvcnQ=")
+	_, err = get(t, c, DefaultProfile, APIKeyName)
+	assert.ErrorContains(t, err, "invalid config master key")
+}
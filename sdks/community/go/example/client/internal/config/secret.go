@@ -0,0 +1,101 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	// APIKeyName is the name of the API key secret
+	APIKeyName = "api-key"
+
+	// APIKeyEnv overrides the API key from the keychain and the config file
+	APIKeyEnv = "AG_UI_API_KEY"
+
+	keyringService = "ag-ui-client"
+)
+
+// ErrKeychainUnavailable is returned when the OS keychain cannot be used,
+// e.g. on a headless machine without a secret service
+var ErrKeychainUnavailable = errors.New("OS keychain unavailable")
+
+// Source tells where a secret was found
+type Source string
+
+const (
+	SourceNone     Source = ""
+	SourceEnv      Source = "environment"
+	SourceKeychain Source = "keychain"
+	SourceConfig   Source = "config file"
+)
+
+// SetSecret stores a secret of a profile in the OS keychain
+func SetSecret(profile, name, value string) error {
+	if err := checkSecret(name); err != nil {
+		return err
+	}
+	if err := keyring.Set(keyringService, account(profile, name), value); err != nil {
+		return fmt.Errorf("%w: %v", ErrKeychainUnavailable, err)
+	}
+	return nil
+}
+
+// UnsetSecret removes a secret of a profile from the OS keychain. Removing
+// a secret that is not stored is not an error.
+func UnsetSecret(profile, name string) error {
+	if err := checkSecret(name); err != nil {
+		return err
+	}
+	err := keyring.Delete(keyringService, account(profile, name))
+	if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("%w: %v", ErrKeychainUnavailable, err)
+	}
+	return nil
+}
+
+// LookupAPIKey returns the API key to send to agents, looking in the
+// environment, then the OS keychain and the config file for the profile,
+// then both for the default profile. A keychain that cannot be used is
+// skipped, so headless machines work with the environment and the file.
+// A key encrypted in the file is only decrypted when it is the one used.
+func (p *Profile) LookupAPIKey() (string, Source, error) {
+	if key := os.Getenv(APIKeyEnv); key != "" {
+		return key, SourceEnv, nil
+	}
+	profiles := []string{p.Name}
+	if p.Name != DefaultProfile {
+		profiles = append(profiles, DefaultProfile)
+	}
+	for _, name := range profiles {
+		if key, err := keyring.Get(keyringService, account(name, APIKeyName)); err == nil && key != "" {
+			return key, SourceKeychain, nil
+		}
+		if own, err := p.config.Own(name, false); err == nil && own.APIKey != "" {
+			key, err := p.config.reveal(name, APIKeyName)
+			if err != nil {
+				return "", SourceConfig, err
+			}
+			return key, SourceConfig, nil
+		}
+	}
+	return "", SourceNone, nil
+}
+
+// account is the keychain account of a secret. Secrets of the default
+// profile keep the plain name.
+func account(profile, name string) string {
+	if profile == "" || profile == DefaultProfile {
+		return name
+	}
+	return profile + "/" + name
+}
+
+func checkSecret(name string) error {
+	if name != APIKeyName {
+		return fmt.Errorf("unknown secret %q", name)
+	}
+	return nil
+}
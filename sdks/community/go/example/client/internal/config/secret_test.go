@@ -0,0 +1,137 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+)
+
+func TestLookupAPIKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        string
+		keychain   map[string]string
+		config     map[string]string
+		profile    string
+		wantKey    string
+		wantSource Source
+	}{
+		{
+			name:       "environment first",
+			env:        "sk-env",
+			keychain:   map[string]string{"prod": "sk-keychain"},
+			config:     map[string]string{"prod": "sk-config"},
+			profile:    "prod",
+			wantKey:    "sk-env",
+			wantSource: SourceEnv,
+		},
+		{
+			name:       "keychain before the config file",
+			keychain:   map[string]string{"prod": "sk-keychain"},
+			config:     map[string]string{"prod": "sk-config"},
+			profile:    "prod",
+			wantKey:    "sk-keychain",
+			wantSource: SourceKeychain,
+		},
+		{
+			name:       "profile before the default profile",
+			keychain:   map[string]string{DefaultProfile: "sk-default"},
+			config:     map[string]string{"prod": "sk-config"},
+			profile:    "prod",
+			wantKey:    "sk-config",
+			wantSource: SourceConfig,
+		},
+		{
+			name:       "keychain of the default profile",
+			keychain:   map[string]string{DefaultProfile: "sk-default"},
+			config:     map[string]string{DefaultProfile: "sk-config"},
+			profile:    "prod",
+			wantKey:    "sk-default",
+			wantSource: SourceKeychain,
+		},
+		{
+			name:       "config file of the default profile",
+			config:     map[string]string{DefaultProfile: "sk-config"},
+			profile:    "prod",
+			wantKey:    "sk-config",
+			wantSource: SourceConfig,
+		},
+		{
+			name:     "the default profile does not use named ones",
+			keychain: map[string]string{"prod": "sk-keychain"},
+			config:   map[string]string{"prod": "sk-config"},
+			profile:  DefaultProfile,
+		},
+		{
+			name:    "none",
+			profile: "prod",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyring.MockInit()
+			t.Setenv(APIKeyEnv, tt.env)
+			for profile, key := range tt.keychain {
+				require.NoError(t, SetSecret(profile, APIKeyName, key))
+			}
+			c := &Config{}
+			_, err := c.Own("prod", true)
+			require.NoError(t, err)
+			for profile, key := range tt.config {
+				own, err := c.Own(profile, false)
+				require.NoError(t, err)
+				own.APIKey = key
+			}
+
+			p, err := c.Profile(tt.profile)
+			require.NoError(t, err)
+			key, source, err := p.LookupAPIKey()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantKey, key)
+			assert.Equal(t, tt.wantSource, source)
+		})
+	}
+}
+
+func TestLookupAPIKeyWithoutKeychain(t *testing.T) {
+	keyring.MockInitWithError(errors.New("no secret service"))
+	t.Setenv(APIKeyEnv, "")
+	c := &Config{}
+	c.APIKey = "sk-config"
+
+	p, err := c.Profile(DefaultProfile)
+	require.NoError(t, err)
+	key, source, err := p.LookupAPIKey()
+	require.NoError(t, err)
+	assert.Equal(t, "sk-config", key, "headless machines fall back to the config file")
+	assert.Equal(t, SourceConfig, source)
+}
+
+func TestSecrets(t *testing.T) {
+	keyring.MockInit()
+	require.NoError(t, SetSecret(DefaultProfile, APIKeyName, "sk-default"))
+	require.NoError(t, SetSecret("prod", APIKeyName, "sk-prod"))
+
+	// Secrets of the default profile keep the plain account name
+	stored, err := keyring.Get(keyringService, APIKeyName)
+	require.NoError(t, err)
+	assert.Equal(t, "sk-default", stored)
+	stored, err = keyring.Get(keyringService, "prod/"+APIKeyName)
+	require.NoError(t, err)
+	assert.Equal(t, "sk-prod", stored)
+
+	require.NoError(t, UnsetSecret("prod", APIKeyName))
+	_, err = keyring.Get(keyringService, "prod/"+APIKeyName)
+	assert.ErrorIs(t, err, keyring.ErrNotFound)
+	assert.NoError(t, UnsetSecret("prod", APIKeyName), "removing a missing secret is not an error")
+
+	assert.EqualError(t, SetSecret(DefaultProfile, "password", "hunter2"), `unknown secret "password"`)
+	assert.EqualError(t, UnsetSecret(DefaultProfile, "password"), `unknown secret "password"`)
+
+	keyring.MockInitWithError(errors.New("no secret service"))
+	assert.ErrorIs(t, SetSecret(DefaultProfile, APIKeyName, "sk-default"), ErrKeychainUnavailable)
+	assert.ErrorIs(t, UnsetSecret(DefaultProfile, APIKeyName), ErrKeychainUnavailable)
+}
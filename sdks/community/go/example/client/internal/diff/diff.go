@@ -0,0 +1,110 @@
+// Package diff compares two texts word by word
+package diff
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Kind tells whether a piece of text is in both texts or only one
+type Kind int
+
+const (
+	Equal Kind = iota
+	Insert
+	Delete
+)
+
+// Op is a piece of the diff. Text includes the whitespace that followed
+// the words in their text.
+type Op struct {
+	Kind Kind
+	Text string
+}
+
+// maxCells bounds the comparison table. Longer texts are compared line by
+// line instead of word by word.
+const maxCells = 1 << 22
+
+// Words returns the edits turning a into b, comparing whole words
+func Words(a, b string) []Op {
+	wa, wb := split(a, splitWords), split(b, splitWords)
+	if len(wa)*len(wb) > maxCells {
+		wa, wb = split(a, splitLines), split(b, splitLines)
+	}
+	return merge(lcs(wa, wb))
+}
+
+// split cuts text into tokens that keep their trailing separator, so
+// joining them gives back text
+func split(text string, isSeparator func(rune) bool) []string {
+	var tokens []string
+	start, inSep := 0, false
+	for i, r := range text {
+		sep := isSeparator(r)
+		if inSep && !sep {
+			tokens = append(tokens, text[start:i])
+			start = i
+		}
+		inSep = sep
+	}
+	if start < len(text) {
+		tokens = append(tokens, text[start:])
+	}
+	return tokens
+}
+
+func splitWords(r rune) bool { return unicode.IsSpace(r) }
+func splitLines(r rune) bool { return r == '\n' }
+
+// lcs diffs two token lists through their longest common subsequence
+func lcs(a, b []string) []Op {
+	n, m := len(a), len(b)
+	// lengths[i*(m+1)+j] is the LCS length of a[i:] and b[j:]
+	lengths := make([]int32, (n+1)*(m+1))
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if strings.TrimSpace(a[i]) == strings.TrimSpace(b[j]) {
+				lengths[i*(m+1)+j] = lengths[(i+1)*(m+1)+j+1] + 1
+			} else {
+				lengths[i*(m+1)+j] = max(lengths[(i+1)*(m+1)+j], lengths[i*(m+1)+j+1])
+			}
+		}
+	}
+
+	var ops []Op
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case strings.TrimSpace(a[i]) == strings.TrimSpace(b[j]):
+			ops = append(ops, Op{Equal, b[j]})
+			i, j = i+1, j+1
+		case lengths[(i+1)*(m+1)+j] >= lengths[i*(m+1)+j+1]:
+			ops = append(ops, Op{Delete, a[i]})
+			i++
+		default:
+			ops = append(ops, Op{Insert, b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, Op{Delete, a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, Op{Insert, b[j]})
+	}
+	return ops
+}
+
+// merge joins consecutive ops of the same kind
+func merge(ops []Op) []Op {
+	var merged []Op
+	for _, op := range ops {
+		if len(merged) > 0 && merged[len(merged)-1].Kind == op.Kind {
+			merged[len(merged)-1].Text += op.Text
+			continue
+		}
+		merged = append(merged, op)
+	}
+	return merged
+}
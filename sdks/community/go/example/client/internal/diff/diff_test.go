@@ -0,0 +1,120 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// apply rebuilds the new text from the ops
+func apply(ops []Op) string {
+	var b strings.Builder
+	for _, op := range ops {
+		if op.Kind != Delete {
+			b.WriteString(op.Text)
+		}
+	}
+	return b.String()
+}
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Op
+	}{
+		{
+			name: "both empty",
+		},
+		{
+			name: "equal",
+			a:    "the quick fox",
+			b:    "the quick fox",
+			want: []Op{{Equal, "the quick fox"}},
+		},
+		{
+			name: "from empty",
+			b:    "hello world",
+			want: []Op{{Insert, "hello world"}},
+		},
+		{
+			name: "to empty",
+			a:    "hello world",
+			want: []Op{{Delete, "hello world"}},
+		},
+		{
+			name: "word replaced",
+			a:    "the quick brown fox",
+			b:    "the quick red fox",
+			want: []Op{{Equal, "the quick "}, {Delete, "brown "}, {Insert, "red "}, {Equal, "fox"}},
+		},
+		{
+			name: "words inserted",
+			a:    "the fox jumps",
+			b:    "the quick brown fox jumps",
+			want: []Op{{Equal, "the "}, {Insert, "quick brown "}, {Equal, "fox jumps"}},
+		},
+		{
+			name: "words deleted at the end",
+			a:    "the fox jumps high",
+			b:    "the fox",
+			want: []Op{{Equal, "the fox"}, {Delete, "jumps high"}},
+		},
+		{
+			name: "whitespace changes are equal, with the new whitespace",
+			a:    "hello  world\n",
+			b:    "hello world",
+			want: []Op{{Equal, "hello world"}},
+		},
+		{
+			name: "leading whitespace",
+			a:    " a b",
+			b:    " a c",
+			want: []Op{{Equal, " a "}, {Delete, "b"}, {Insert, "c"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := Words(tt.a, tt.b)
+			assert.Equal(t, tt.want, ops)
+			assert.Equal(t, tt.b, apply(ops))
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		text  string
+		words []string
+		lines []string
+	}{
+		{"", nil, nil},
+		{"one", []string{"one"}, []string{"one"}},
+		{"one two\nthree", []string{"one ", "two\n", "three"}, []string{"one two\n", "three"}},
+		{"  indented\n\n", []string{"  ", "indented\n\n"}, []string{"  indented\n\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.words, split(tt.text, splitWords))
+			assert.Equal(t, tt.lines, split(tt.text, splitLines))
+			assert.Equal(t, tt.text, strings.Join(split(tt.text, splitWords), ""))
+		})
+	}
+}
+
+func TestWordsLongTexts(t *testing.T) {
+	// Past maxCells, texts are compared line by line
+	line := strings.Repeat("word ", 100) + "\n"
+	a := strings.Repeat(line, 30) + "the old line\n" + strings.Repeat(line, 30)
+	b := strings.Repeat(line, 30) + "the new line\n" + strings.Repeat(line, 30)
+
+	ops := Words(a, b)
+	assert.Equal(t, []Op{
+		{Equal, strings.Repeat(line, 30)},
+		{Delete, "the old line\n"},
+		{Insert, "the new line\n"},
+		{Equal, strings.Repeat(line, 30)},
+	}, ops)
+	assert.Equal(t, b, apply(ops))
+}
@@ -0,0 +1,388 @@
+// Package doctor diagnoses why the client cannot talk to an agent. It walks
+// the path of a request, from resolving the host to receiving streamed
+// events, and tells what to fix at the first step that fails.
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/agent"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Status is the outcome of a check
+type Status int
+
+const (
+	OK Status = iota
+	Warn
+	Fail
+	// Skip means the check did not run, because an earlier one failed or
+	// it does not apply
+	Skip
+)
+
+func (s Status) String() string {
+	switch s {
+	case OK:
+		return "ok"
+	case Warn:
+		return "warn"
+	case Fail:
+		return "fail"
+	default:
+		return "skip"
+	}
+}
+
+// Result is the outcome of one check, with a hint telling how to fix it
+// when it did not pass
+type Result struct {
+	Name   string
+	Status Status
+	Detail string
+	Hint   string
+}
+
+// Options tell what to check
+type Options struct {
+	Endpoint agent.Endpoint
+
+	// Run sends a test message to check that events arrive as they are
+	// produced rather than all at once
+	Run bool
+
+	// Timeout bounds each check, 5 seconds when zero
+	Timeout time.Duration
+}
+
+// The checks, in the order they run
+const (
+	CheckURL       = "url"
+	CheckProxy     = "proxy"
+	CheckDNS       = "dns"
+	CheckTCP       = "tcp"
+	CheckTLS       = "tls"
+	CheckAuth      = "auth"
+	CheckStreaming = "streaming"
+)
+
+// bufferedAfter is how long the first event may take before events that
+// then arrive all at once are blamed on a buffering proxy
+const bufferedAfter = time.Second
+
+// Run runs the checks against opts.Endpoint, passing each result to report
+// as soon as it is known. It returns false when a check failed.
+func Run(ctx context.Context, opts Options, report func(Result)) bool {
+	if opts.Timeout == 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	d := &doctor{opts: opts}
+	checks := []struct {
+		name  string
+		check func(context.Context) Result
+	}{
+		{CheckURL, d.checkURL},
+		{CheckProxy, d.checkProxy},
+		{CheckDNS, d.checkDNS},
+		{CheckTCP, d.checkTCP},
+		{CheckTLS, d.checkTLS},
+		{CheckAuth, d.checkAuth},
+		{CheckStreaming, d.checkStreaming},
+	}
+
+	ok := true
+	for _, c := range checks {
+		result := Result{Name: c.name, Status: Skip, Detail: "an earlier check failed"}
+		if ok {
+			result = c.check(ctx)
+			result.Name = c.name
+		}
+		ok = ok && result.Status != Fail
+		report(result)
+	}
+	return ok
+}
+
+type doctor struct {
+	opts Options
+
+	url   *url.URL
+	addr  string
+	proxy *url.URL
+}
+
+func (d *doctor) checkURL(context.Context) Result {
+	u, err := url.Parse(d.opts.Endpoint.URL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return Result{Status: Fail, Detail: fmt.Sprintf("%q is not an http or https URL", d.opts.Endpoint.URL),
+			Hint: "set the endpoint with client config set endpoint URL, e.g. " + agent.DefaultEndpoint()}
+	}
+	d.url = u
+	port := u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+	d.addr = net.JoinHostPort(u.Hostname(), port)
+	return Result{Status: OK, Detail: u.String()}
+}
+
+func (d *doctor) checkProxy(context.Context) Result {
+	proxy, err := http.ProxyFromEnvironment(&http.Request{URL: d.url})
+	if err != nil {
+		return Result{Status: Fail, Detail: "invalid proxy setting: " + err.Error(),
+			Hint: "fix HTTP_PROXY / HTTPS_PROXY in the environment"}
+	}
+	if proxy == nil {
+		return Result{Status: OK, Detail: "direct connection, no proxy"}
+	}
+	d.proxy = proxy
+	hint := "proxies that buffer responses hold streamed events back until the run ends"
+	if ip := net.ParseIP(d.url.Hostname()); d.url.Hostname() == "localhost" || (ip != nil && ip.IsLoopback()) {
+		hint = "add " + d.url.Hostname() + " to NO_PROXY, a local server needs no proxy"
+	}
+	return Result{Status: Warn, Detail: "requests go through the proxy " + proxy.Redacted(), Hint: hint}
+}
+
+func (d *doctor) checkDNS(ctx context.Context) Result {
+	host := d.url.Hostname()
+	if net.ParseIP(host) != nil {
+		return Result{Status: OK, Detail: host + " is an IP address"}
+	}
+	if d.proxy != nil {
+		return Result{Status: Skip, Detail: "the proxy resolves " + host}
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.opts.Timeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		hint := "check the host name for typos, and your network, VPN or /etc/hosts"
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsTimeout {
+			hint = "the DNS server did not answer, check your network connection"
+		}
+		return Result{Status: Fail, Detail: fmt.Sprintf("cannot resolve %s: %v", host, err), Hint: hint}
+	}
+	return Result{Status: OK, Detail: fmt.Sprintf("%s resolves to %s", host, strings.Join(addrs, ", "))}
+}
+
+func (d *doctor) checkTCP(ctx context.Context) Result {
+	addr := d.addr
+	if d.proxy != nil {
+		addr = d.proxy.Host
+		if d.proxy.Port() == "" {
+			addr = net.JoinHostPort(d.proxy.Hostname(), "80")
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.opts.Timeout)
+	defer cancel()
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		hint := "check the port and any firewall between you and the server"
+		switch {
+		case errors.Is(err, syscall.ECONNREFUSED):
+			hint = "nothing listens on " + addr + ", is the server running?"
+		case errors.Is(err, context.DeadlineExceeded):
+			hint = "no answer from " + addr + ", a firewall may be dropping the connection"
+		}
+		return Result{Status: Fail, Detail: fmt.Sprintf("cannot connect to %s: %v", addr, err), Hint: hint}
+	}
+	conn.Close()
+	return Result{Status: OK, Detail: "connected to " + addr}
+}
+
+func (d *doctor) checkTLS(ctx context.Context) Result {
+	if d.url.Scheme != "https" {
+		return Result{Status: Skip, Detail: "plain HTTP"}
+	}
+	if d.proxy != nil {
+		return Result{Status: Skip, Detail: "the connection is tunnelled through the proxy"}
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.opts.Timeout)
+	defer cancel()
+	dialer := &tls.Dialer{Config: &tls.Config{ServerName: d.url.Hostname()}}
+	conn, err := dialer.DialContext(ctx, "tcp", d.addr)
+	if err != nil {
+		return Result{Status: Fail, Detail: "TLS handshake failed: " + err.Error(), Hint: tlsHint(err)}
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	cert := state.PeerCertificates[0]
+	detail := fmt.Sprintf("%s, certificate issued by %s, valid until %s",
+		tls.VersionName(state.Version), cert.Issuer.CommonName, cert.NotAfter.Format(time.DateOnly))
+	if left := time.Until(cert.NotAfter); left < 14*24*time.Hour {
+		return Result{Status: Warn, Detail: detail,
+			Hint: fmt.Sprintf("the certificate expires in %d days, renew it", int(left.Hours()/24))}
+	}
+	return Result{Status: OK, Detail: detail}
+}
+
+func tlsHint(err error) string {
+	var unknown x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	var record tls.RecordHeaderError
+	switch {
+	case errors.As(err, &unknown):
+		return "the certificate is signed by an unknown authority, install its CA or point SSL_CERT_FILE at it"
+	case errors.As(err, &hostname):
+		return fmt.Sprintf("the certificate is not valid for %s, check the host name of the endpoint", hostname.Host)
+	case errors.As(err, &invalid) && invalid.Reason == x509.Expired:
+		return "the certificate has expired, renew it on the server"
+	case errors.As(err, &record):
+		return "the server does not speak TLS, try an http:// endpoint"
+	}
+	return "check the TLS setup of the server"
+}
+
+// checkAuth posts a body the server must reject. The status tells whether
+// the credentials got past authentication without starting a run.
+func (d *doctor) checkAuth(ctx context.Context) Result {
+	ctx, cancel := context.WithTimeout(ctx, d.opts.Timeout)
+	defer cancel()
+	resp, err := d.post(ctx, []byte("[]"))
+	if err != nil {
+		return Result{Status: Fail, Detail: "request failed: " + err.Error(), Hint: "check the endpoint URL and the proxy settings"}
+	}
+	resp.Body.Close()
+
+	withKey := d.opts.Endpoint.APIKey != ""
+	switch code := resp.StatusCode; {
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		hint := "the server requires an API key, store one with client config set-secret"
+		if withKey {
+			hint = "the server rejected the API key, check it and the profile it belongs to with client config show"
+		}
+		return Result{Status: Fail, Detail: "server answered " + resp.Status, Hint: hint}
+	case code == http.StatusNotFound:
+		return Result{Status: Fail, Detail: "server answered " + resp.Status,
+			Hint: "no agent at " + d.url.Path + ", check the path of the endpoint"}
+	case code == http.StatusMethodNotAllowed:
+		return Result{Status: Fail, Detail: "server answered " + resp.Status,
+			Hint: "the endpoint does not accept runs, check its path"}
+	case code == http.StatusBadGateway || code == http.StatusGatewayTimeout:
+		return Result{Status: Fail, Detail: "server answered " + resp.Status,
+			Hint: "a proxy or load balancer in front of the agent cannot reach it"}
+	case code >= 500:
+		return Result{Status: Warn, Detail: "server answered " + resp.Status, Hint: "check the server logs"}
+	case withKey:
+		return Result{Status: OK, Detail: "the server accepted the API key"}
+	default:
+		return Result{Status: OK, Detail: "the server requires no API key"}
+	}
+}
+
+// checkStreaming runs the agent with a test message and looks at how the
+// events arrive
+func (d *doctor) checkStreaming(ctx context.Context) Result {
+	if !d.opts.Run {
+		return Result{Status: Skip, Detail: "use --run to send a test message"}
+	}
+	input := agent.NewInput("thread-"+uuid.NewString(), []types.Message{
+		{ID: "msg-" + uuid.NewString(), Role: types.RoleUser, Content: "Reply with OK."},
+	}, nil)
+	body, err := json.Marshal(input)
+	if err != nil {
+		return Result{Status: Fail, Detail: err.Error()}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*d.opts.Timeout)
+	defer cancel()
+	start := time.Now()
+	resp, err := d.post(ctx, body)
+	if err != nil {
+		return Result{Status: Fail, Detail: "request failed: " + err.Error()}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Result{Status: Fail, Detail: "server answered " + resp.Status, Hint: "check the server logs"}
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		return Result{Status: Fail, Detail: fmt.Sprintf("response is %q, not text/event-stream", ct),
+			Hint: "a proxy may be rewriting the response, or the endpoint is not an AG-UI agent"}
+	}
+
+	var arrivals []time.Duration
+	scanner := sse.NewFrameScanner(resp.Body, 1024*1024)
+	for {
+		if _, err = scanner.Next(); err != nil {
+			break
+		}
+		arrivals = append(arrivals, time.Since(start))
+	}
+	if err != io.EOF {
+		return Result{Status: Fail, Detail: fmt.Sprintf("stream broke after %d events: %v", len(arrivals), err),
+			Hint: "a proxy may be closing idle or long connections, raise its read timeout"}
+	}
+	if len(arrivals) == 0 {
+		return Result{Status: Fail, Detail: "the stream carried no events", Hint: "check the server logs"}
+	}
+
+	first, last := arrivals[0], arrivals[len(arrivals)-1]
+	detail := fmt.Sprintf("%d events, the first after %s, the last after %s",
+		len(arrivals), first.Round(time.Millisecond), last.Round(time.Millisecond))
+	if enc := resp.Header.Get("Content-Encoding"); enc != "" && enc != "identity" {
+		return Result{Status: Warn, Detail: detail + ", compressed with " + enc,
+			Hint: "compressing the stream makes proxies and clients buffer it, exclude text/event-stream from compression"}
+	}
+	if len(arrivals) > 2 && first > bufferedAfter && last-first < 50*time.Millisecond {
+		return Result{Status: Warn, Detail: detail + ", all at once",
+			Hint: "a proxy is buffering the stream, disable response buffering for the endpoint (nginx: proxy_buffering off, or send X-Accel-Buffering: no)"}
+	}
+	return Result{Status: OK, Detail: detail}
+}
+
+func (d *doctor) post(ctx context.Context, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	if d.opts.Endpoint.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+d.opts.Endpoint.APIKey)
+	}
+	return http.DefaultClient.Do(req)
+}
+
+// SelfTest runs the self-test of the SSE client against endpoint, which
+// must be a diagnostic endpoint (see server.DiagnosticServer), with the
+// settings of the real client
+func SelfTest(ctx context.Context, endpoint agent.Endpoint, timeout time.Duration) sse.SelfTestReport {
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+	logger := endpoint.Logger
+	if logger == nil {
+		logger = logrus.New()
+		logger.SetOutput(io.Discard)
+	}
+	client := sse.NewClient(sse.Config{
+		Endpoint:       endpoint.URL,
+		APIKey:         endpoint.APIKey,
+		ConnectTimeout: timeout,
+		Logger:         logger,
+	})
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*timeout)
+	defer cancel()
+	return client.SelfTest(ctx)
+}
@@ -0,0 +1,264 @@
+package doctor
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/agent"
+)
+
+// run runs the checks and returns their results by name
+func run(t *testing.T, opts Options) (map[string]Result, bool) {
+	t.Helper()
+	results := make(map[string]Result)
+	var order []string
+	ok := Run(context.Background(), opts, func(r Result) {
+		results[r.Name] = r
+		order = append(order, r.Name)
+	})
+	require.Equal(t, []string{CheckURL, CheckProxy, CheckDNS, CheckTCP, CheckTLS, CheckAuth, CheckStreaming}, order)
+	return results, ok
+}
+
+// agentServer serves runs with stream, answering the body of the auth check
+// with status and requests without the API key with 401
+func agentServer(t *testing.T, status int, apiKey string, stream http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if apiKey != "" && r.Header.Get("Authorization") != "Bearer "+apiKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if string(body) == "[]" {
+			w.WriteHeader(status)
+			return
+		}
+		stream(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// events streams n events, flushing each
+func events(n int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(w, "data: {\"type\":\"CUSTOM\",\"name\":\"event-%d\"}\n\n", i)
+			w.(http.Flusher).Flush()
+		}
+	}
+}
+
+// closedAddr returns a local address nothing listens on
+func closedAddr(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+	return addr
+}
+
+func TestRun(t *testing.T) {
+	server := agentServer(t, http.StatusUnprocessableEntity, "", events(3))
+	results, ok := run(t, Options{Endpoint: agent.Endpoint{URL: server.URL + "/agentic"}, Run: true})
+	assert.True(t, ok)
+
+	assert.Equal(t, Result{Name: CheckURL, Status: OK, Detail: server.URL + "/agentic"}, results[CheckURL])
+	assert.Equal(t, OK, results[CheckProxy].Status, "loopback addresses are not proxied")
+	assert.Equal(t, OK, results[CheckDNS].Status)
+	assert.Equal(t, OK, results[CheckTCP].Status)
+	assert.Equal(t, Result{Name: CheckTLS, Status: Skip, Detail: "plain HTTP"}, results[CheckTLS])
+	assert.Equal(t, Result{Name: CheckAuth, Status: OK, Detail: "the server requires no API key"}, results[CheckAuth])
+	assert.Equal(t, OK, results[CheckStreaming].Status)
+	assert.Contains(t, results[CheckStreaming].Detail, "3 events")
+
+	results, ok = run(t, Options{Endpoint: agent.Endpoint{URL: server.URL}})
+	assert.True(t, ok)
+	assert.Equal(t, Result{Name: CheckStreaming, Status: Skip, Detail: "use --run to send a test message"}, results[CheckStreaming])
+}
+
+func TestInvalidURL(t *testing.T) {
+	for _, url := range []string{"", "localhost:8000", "ftp://example.com/agent", "http://"} {
+		t.Run(url, func(t *testing.T) {
+			results, ok := run(t, Options{Endpoint: agent.Endpoint{URL: url}, Run: true})
+			assert.False(t, ok)
+			assert.Equal(t, Fail, results[CheckURL].Status)
+			assert.Contains(t, results[CheckURL].Hint, "client config set endpoint")
+			for _, name := range []string{CheckProxy, CheckDNS, CheckTCP, CheckTLS, CheckAuth, CheckStreaming} {
+				assert.Equal(t, Result{Name: name, Status: Skip, Detail: "an earlier check failed"}, results[name])
+			}
+		})
+	}
+}
+
+func TestConnectionRefused(t *testing.T) {
+	addr := closedAddr(t)
+	results, ok := run(t, Options{Endpoint: agent.Endpoint{URL: "http://" + addr}})
+	assert.False(t, ok)
+	assert.Equal(t, Result{Name: CheckDNS, Status: OK, Detail: "127.0.0.1 is an IP address"}, results[CheckDNS])
+	assert.Equal(t, Fail, results[CheckTCP].Status)
+	assert.Equal(t, "nothing listens on "+addr+", is the server running?", results[CheckTCP].Hint)
+	assert.Equal(t, Skip, results[CheckAuth].Status)
+}
+
+func TestUnknownHost(t *testing.T) {
+	results, ok := run(t, Options{Endpoint: agent.Endpoint{URL: "http://agent.invalid"}, Timeout: time.Second})
+	assert.False(t, ok)
+	assert.Equal(t, Fail, results[CheckDNS].Status)
+	assert.Contains(t, results[CheckDNS].Detail, "cannot resolve agent.invalid")
+	assert.Equal(t, Skip, results[CheckTCP].Status)
+}
+
+func TestTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	results, ok := run(t, Options{Endpoint: agent.Endpoint{URL: server.URL}})
+	assert.False(t, ok)
+	assert.Equal(t, Fail, results[CheckTLS].Status)
+	assert.Contains(t, results[CheckTLS].Hint, "unknown authority")
+
+	plain := httptest.NewServer(http.NotFoundHandler())
+	defer plain.Close()
+	results, ok = run(t, Options{Endpoint: agent.Endpoint{URL: "https://" + plain.Listener.Addr().String()}})
+	assert.False(t, ok)
+	assert.Equal(t, "the server does not speak TLS, try an http:// endpoint", results[CheckTLS].Hint)
+}
+
+func TestTLSHint(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{x509.UnknownAuthorityError{}, "the certificate is signed by an unknown authority, install its CA or point SSL_CERT_FILE at it"},
+		{x509.HostnameError{Certificate: &x509.Certificate{}, Host: "agents.example.com"}, "the certificate is not valid for agents.example.com, check the host name of the endpoint"},
+		{x509.CertificateInvalidError{Reason: x509.Expired}, "the certificate has expired, renew it on the server"},
+		{x509.CertificateInvalidError{Reason: x509.NotAuthorizedToSign}, "check the TLS setup of the server"},
+		{fmt.Errorf("handshake: %w", tls.RecordHeaderError{}), "the server does not speak TLS, try an http:// endpoint"},
+		{errors.New("EOF"), "check the TLS setup of the server"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tlsHint(tt.err))
+	}
+}
+
+func TestAuth(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		apiKey string
+		sent   string
+		want   Status
+		hint   string
+	}{
+		{name: "no key needed", status: http.StatusBadRequest, want: OK},
+		{name: "key accepted", status: http.StatusBadRequest, apiKey: "sk-test", sent: "sk-test", want: OK},
+		{name: "key missing", apiKey: "sk-test", want: Fail, hint: "the server requires an API key, store one with client config set-secret"},
+		{name: "key rejected", apiKey: "sk-test", sent: "sk-other", want: Fail, hint: "the server rejected the API key, check it and the profile it belongs to with client config show"},
+		{name: "forbidden", status: http.StatusForbidden, want: Fail, hint: "the server requires an API key, store one with client config set-secret"},
+		{name: "wrong path", status: http.StatusNotFound, want: Fail, hint: "no agent at /agentic, check the path of the endpoint"},
+		{name: "wrong method", status: http.StatusMethodNotAllowed, want: Fail, hint: "the endpoint does not accept runs, check its path"},
+		{name: "bad gateway", status: http.StatusBadGateway, want: Fail, hint: "a proxy or load balancer in front of the agent cannot reach it"},
+		{name: "server error", status: http.StatusInternalServerError, want: Warn, hint: "check the server logs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := agentServer(t, tt.status, tt.apiKey, events(1))
+			results, ok := run(t, Options{Endpoint: agent.Endpoint{URL: server.URL + "/agentic", APIKey: tt.sent}})
+			assert.Equal(t, tt.want != Fail, ok)
+			assert.Equal(t, tt.want, results[CheckAuth].Status, results[CheckAuth].Detail)
+			assert.Equal(t, tt.hint, results[CheckAuth].Hint)
+		})
+	}
+}
+
+func TestStreaming(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream http.HandlerFunc
+		want   Status
+		detail string
+		hint   string
+	}{
+		{
+			name:   "streamed",
+			stream: events(3),
+			want:   OK,
+			detail: "3 events",
+		},
+		{
+			name: "run refused",
+			stream: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			want:   Fail,
+			detail: "server answered 500 Internal Server Error",
+		},
+		{
+			name: "not an event stream",
+			stream: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				fmt.Fprint(w, "<html></html>")
+			},
+			want: Fail,
+			hint: "a proxy may be rewriting the response, or the endpoint is not an AG-UI agent",
+		},
+		{
+			name:   "no events",
+			stream: events(0),
+			want:   Fail,
+			detail: "the stream carried no events",
+		},
+		{
+			name: "compressed",
+			stream: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Encoding", "br")
+				events(2)(w, r)
+			},
+			want:   Warn,
+			detail: "compressed with br",
+		},
+		{
+			name: "buffered",
+			stream: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(bufferedAfter + 100*time.Millisecond)
+				events(3)(w, r)
+			},
+			want:   Warn,
+			detail: "all at once",
+			hint:   "a proxy is buffering the stream",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := agentServer(t, http.StatusBadRequest, "", tt.stream)
+			results, ok := run(t, Options{Endpoint: agent.Endpoint{URL: server.URL}, Run: true})
+			result := results[CheckStreaming]
+			assert.Equal(t, tt.want != Fail, ok)
+			assert.Equal(t, tt.want, result.Status, result.Detail)
+			assert.Contains(t, result.Detail, tt.detail)
+			assert.Contains(t, result.Hint, tt.hint)
+		})
+	}
+}
+
+func TestStatusString(t *testing.T) {
+	assert.Equal(t, "ok", OK.String())
+	assert.Equal(t, "warn", Warn.String())
+	assert.Equal(t, "fail", Fail.String())
+	assert.Equal(t, "skip", Skip.String())
+}
@@ -1,27 +1,22 @@
 package event
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
 )
 
 func Parse(data []byte) (events.Event, error) {
-	// Parse the SSE event
-	var eventData map[string]interface{}
-
-	err := json.Unmarshal(data, &eventData)
+	// Peek the event type - the server sends it as "type" field directly -
+	// so that the event is only unmarshaled once
+	eventType, err := events.PeekEventType(data)
 	if err != nil {
 		return nil, fmt.Errorf("received non-JSON frame event data %w", err)
 	}
 
 	decoder := events.NewEventDecoder(nil)
 
-	// Extract event type - the server sends it as "type" field directly
-	eventType, _ := eventData["type"].(string)
-
-	event, err := decoder.DecodeEvent(eventType, data)
+	event, err := decoder.DecodeEvent(string(eventType), data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode event %w", err)
 	}
@@ -0,0 +1,174 @@
+// Package export renders a stored conversation as a document to share,
+// in Markdown or as a standalone HTML page
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/session"
+)
+
+// Formats
+const (
+	Markdown = "markdown"
+	HTML     = "html"
+)
+
+// Write renders s to w in format
+func Write(w io.Writer, s *session.Session, format string) error {
+	doc := newDocument(s)
+	switch format {
+	case Markdown:
+		return writeMarkdown(w, doc)
+	case HTML:
+		return writeHTML(w, doc)
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// document is a session prepared for rendering: tool results are attached
+// to their calls and structured values are indented JSON
+type document struct {
+	ID        string
+	Labels    []string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Messages  []message
+	State     string
+}
+
+type message struct {
+	Role    string
+	Label   string
+	Content string
+	// Prose is set for user and assistant text, which is rendered as is.
+	// Content of other roles is shown preformatted.
+	Prose     bool
+	ToolCalls []toolCall
+}
+
+type toolCall struct {
+	ID        string
+	Name      string
+	Arguments string
+	Result    string
+	HasResult bool
+}
+
+func newDocument(s *session.Session) *document {
+	doc := &document{ID: s.ID, Labels: s.Labels, CreatedAt: s.CreatedAt, UpdatedAt: s.UpdatedAt}
+	if s.State != nil {
+		doc.State = indentJSON(s.State)
+	}
+
+	results := map[string]string{}
+	for _, m := range s.Messages {
+		if m.Role == types.RoleTool && m.ToolCallID != "" {
+			results[m.ToolCallID] = content(m)
+		}
+	}
+
+	for _, m := range s.Messages {
+		if m.Role == types.RoleTool && m.ToolCallID != "" && calledIn(s.Messages, m.ToolCallID) {
+			// Rendered with its call
+			continue
+		}
+		msg := message{
+			Role:    string(m.Role),
+			Label:   label(m),
+			Content: content(m),
+			Prose:   m.Role == types.RoleUser || m.Role == types.RoleAssistant,
+		}
+		for _, call := range m.ToolCalls {
+			result, ok := results[call.ID]
+			msg.ToolCalls = append(msg.ToolCalls, toolCall{
+				ID:        call.ID,
+				Name:      call.Function.Name,
+				Arguments: indentJSONString(call.Function.Arguments),
+				Result:    result,
+				HasResult: ok,
+			})
+		}
+		doc.Messages = append(doc.Messages, msg)
+	}
+	return doc
+}
+
+func calledIn(messages []types.Message, toolCallID string) bool {
+	for _, m := range messages {
+		for _, call := range m.ToolCalls {
+			if call.ID == toolCallID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// label names the author of a message like the chat view does
+func label(m types.Message) string {
+	var l string
+	switch m.Role {
+	case types.RoleUser:
+		l = "You"
+	case types.RoleAssistant:
+		l = "Assistant"
+	case "":
+		l = "Unknown"
+	default:
+		l = strings.ToUpper(string(m.Role[:1])) + string(m.Role[1:])
+	}
+	if m.Name != "" {
+		l += " (" + m.Name + ")"
+	}
+	return l
+}
+
+// content returns the text of a message. Attachments are listed by type,
+// activity content is shown as JSON.
+func content(m types.Message) string {
+	if text, ok := m.ContentString(); ok {
+		return text
+	}
+	if parts, ok := m.ContentInputContents(); ok {
+		var text []string
+		for _, part := range parts {
+			switch {
+			case part.Text != "":
+				text = append(text, part.Text)
+			case part.URL != "":
+				text = append(text, fmt.Sprintf("[%s attachment: %s]", part.MimeType, part.URL))
+			default:
+				text = append(text, fmt.Sprintf("[%s attachment]", part.MimeType))
+			}
+		}
+		return strings.Join(text, "\n\n")
+	}
+	if m.Content == nil {
+		return ""
+	}
+	return indentJSON(m.Content)
+}
+
+func indentJSON(value any) string {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// indentJSONString indents a JSON document, leaving other text as is
+func indentJSONString(text string) string {
+	var value any
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return text
+	}
+	return indentJSON(value)
+}
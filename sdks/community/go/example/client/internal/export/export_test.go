@@ -0,0 +1,139 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/session"
+)
+
+// newSession returns a conversation with a tool call answered by a tool
+// message, one left unanswered, an orphan tool message and attachments
+func newSession() *session.Session {
+	created := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	return &session.Session{
+		ID:        "thread-1",
+		Labels:    []string{"weather", "demo"},
+		CreatedAt: created,
+		UpdatedAt: created.Add(time.Hour),
+		State:     map[string]any{"city": "Paris"},
+		Messages: []types.Message{
+			{ID: "1", Role: types.RoleUser, Content: "What is the weather in <Paris>?"},
+			{ID: "2", Role: types.RoleAssistant, Content: "Let me check.", ToolCalls: []types.ToolCall{
+				{ID: "call-1", Type: "function", Function: types.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+				{ID: "call-2", Type: "function", Function: types.FunctionCall{Name: "get_time", Arguments: "not json"}},
+			}},
+			{ID: "3", Role: types.RoleTool, ToolCallID: "call-1", Content: "sunny"},
+			{ID: "4", Role: types.RoleTool, ToolCallID: "call-9", Content: "from an earlier thread"},
+			{ID: "5", Role: types.RoleUser, Name: "alice", Content: []types.InputContent{
+				{Type: "text", Text: "And here?"},
+				{Type: "binary", MimeType: "image/png", URL: "https://example.com/map.png"},
+				{Type: "binary", MimeType: "application/pdf", ID: "file-1"},
+			}},
+			{ID: "6", Role: types.RoleActivity, Content: map[string]any{"progress": 50}},
+		},
+	}
+}
+
+func TestNewDocument(t *testing.T) {
+	doc := newDocument(newSession())
+	assert.Equal(t, "{\n  \"city\": \"Paris\"\n}", doc.State)
+
+	var labels []string
+	for _, m := range doc.Messages {
+		labels = append(labels, m.Label)
+	}
+	assert.Equal(t, []string{"You", "Assistant", "Tool", "You (alice)", "Activity"}, labels,
+		"tool messages answering a call are rendered with it")
+
+	assistant := doc.Messages[1]
+	assert.True(t, assistant.Prose)
+	assert.Equal(t, []toolCall{
+		{ID: "call-1", Name: "get_weather", Arguments: "{\n  \"city\": \"Paris\"\n}", Result: "sunny", HasResult: true},
+		{ID: "call-2", Name: "get_time", Arguments: "not json"},
+	}, assistant.ToolCalls)
+
+	orphan := doc.Messages[2]
+	assert.False(t, orphan.Prose)
+	assert.Equal(t, "from an earlier thread", orphan.Content)
+
+	assert.Equal(t, "And here?\n\n[image/png attachment: https://example.com/map.png]\n\n[application/pdf attachment]", doc.Messages[3].Content)
+	assert.Equal(t, "{\n  \"progress\": 50\n}", doc.Messages[4].Content)
+}
+
+func TestLabel(t *testing.T) {
+	tests := []struct {
+		message types.Message
+		want    string
+	}{
+		{types.Message{Role: types.RoleUser}, "You"},
+		{types.Message{Role: types.RoleAssistant, Name: "planner"}, "Assistant (planner)"},
+		{types.Message{Role: types.RoleSystem}, "System"},
+		{types.Message{}, "Unknown"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, label(tt.message))
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, Write(&out, newSession(), Markdown))
+	md := out.String()
+
+	for _, want := range []string{
+		"# Conversation thread-1\n\n- Started: Thu, 02 Jan 2025 15:04:05 UTC\n- Last updated: Thu, 02 Jan 2025 16:04:05 UTC\n- Labels: weather, demo\n",
+		"\n## You\n\nWhat is the weather in <Paris>?\n",
+		"<summary>Tool call: <code>get_weather</code></summary>\n\n**Arguments**\n\n```json\n{\n  \"city\": \"Paris\"\n}\n```\n\n**Result**\n\n```\nsunny\n```\n\n</details>",
+		"<summary>Tool call: <code>get_time</code></summary>\n\n**Arguments**\n\n```json\nnot json\n```\n\n</details>",
+		"\n## Tool\n\n```\nfrom an earlier thread\n```\n",
+		"\n## State\n\n```json\n{\n  \"city\": \"Paris\"\n}\n```\n",
+	} {
+		assert.Contains(t, md, want)
+	}
+}
+
+func TestCodeBlock(t *testing.T) {
+	assert.Equal(t, "```go\nx := 1\n```\n", codeBlock("go", "x := 1\n\n"))
+	assert.Equal(t, "````\nuse ``` fences\n````\n", codeBlock("", "use ``` fences"), "fences are longer than the backticks inside")
+}
+
+func TestWriteHTML(t *testing.T) {
+	s := newSession()
+	s.Messages = append(s.Messages, types.Message{ID: "7", Role: types.RoleAssistant, Content: "<script>alert(1)</script>"})
+	var out bytes.Buffer
+	require.NoError(t, Write(&out, s, HTML))
+	page := out.String()
+
+	assert.Contains(t, page, "<title>Conversation thread-1</title>")
+	assert.Contains(t, page, "Started Thu, 02 Jan 2025 15:04:05 UTC · last updated Thu, 02 Jan 2025 16:04:05 UTC · labels: weather, demo")
+	assert.Contains(t, page, `<div class="message user">`)
+	assert.Contains(t, page, `<div class="content">What is the weather in &lt;Paris&gt;?</div>`)
+	assert.Contains(t, page, "<summary>Tool call: <code>get_weather</code></summary>")
+	assert.Contains(t, page, "<p>Result</p>\n<pre>sunny</pre>")
+	assert.Contains(t, page, "<pre>from an earlier thread</pre>")
+	assert.Contains(t, page, "<h2>State</h2>")
+	assert.NotContains(t, page, "<script>", "content is escaped")
+}
+
+func TestWriteMinimal(t *testing.T) {
+	s := &session.Session{ID: "thread-1"}
+	var out bytes.Buffer
+	require.NoError(t, Write(&out, s, Markdown))
+	assert.Equal(t, "# Conversation thread-1\n\n", out.String())
+
+	out.Reset()
+	require.NoError(t, Write(&out, s, HTML))
+	assert.NotContains(t, out.String(), "State")
+	assert.NotContains(t, out.String(), "Started")
+}
+
+func TestWriteUnsupported(t *testing.T) {
+	err := Write(&bytes.Buffer{}, newSession(), "pdf")
+	assert.EqualError(t, err, `unsupported export format "pdf"`)
+}
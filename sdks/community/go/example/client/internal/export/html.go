@@ -0,0 +1,65 @@
+package export
+
+import (
+	"html/template"
+	"io"
+	"time"
+)
+
+var htmlTemplate = template.Must(template.New("conversation").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return t.Format(time.RFC1123) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Conversation {{.ID}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 50rem; margin: 2rem auto; padding: 0 1rem; color: #222; }
+.meta { color: #666; font-size: 0.9rem; }
+.message { border-left: 3px solid #ccc; margin: 1.5rem 0; padding: 0.25rem 1rem; }
+.message.user { border-color: #7d56f4; }
+.message.assistant { border-color: #04b575; }
+.label { font-weight: bold; }
+.content { white-space: pre-wrap; }
+details { background: #f6f6f6; border-radius: 4px; margin: 0.5rem 0; padding: 0.5rem 0.75rem; }
+summary { cursor: pointer; }
+pre { background: #fff; border: 1px solid #ddd; border-radius: 4px; overflow-x: auto; padding: 0.5rem; }
+</style>
+</head>
+<body>
+<h1>Conversation {{.ID}}</h1>
+<p class="meta">
+{{- if not .CreatedAt.IsZero}}Started {{date .CreatedAt}}{{end}}
+{{- if not .UpdatedAt.IsZero}} · last updated {{date .UpdatedAt}}{{end}}
+{{- range $i, $l := .Labels}}{{if eq $i 0}} · labels: {{else}}, {{end}}{{$l}}{{end -}}
+</p>
+{{range .Messages}}
+<div class="message {{.Role}}">
+<p class="label">{{.Label}}</p>
+{{- if .Content}}
+{{if .Prose}}<div class="content">{{.Content}}</div>{{else}}<pre>{{.Content}}</pre>{{end}}
+{{- end}}
+{{- range .ToolCalls}}
+<details>
+<summary>Tool call: <code>{{.Name}}</code></summary>
+<p>Arguments</p>
+<pre>{{.Arguments}}</pre>
+{{- if .HasResult}}
+<p>Result</p>
+<pre>{{.Result}}</pre>
+{{- end}}
+</details>
+{{- end}}
+</div>
+{{end}}
+{{- if .State}}
+<h2>State</h2>
+<pre>{{.State}}</pre>
+{{end -}}
+</body>
+</html>
+`))
+
+func writeHTML(w io.Writer, doc *document) error {
+	return htmlTemplate.Execute(w, doc)
+}
@@ -0,0 +1,59 @@
+package export
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+)
+
+func writeMarkdown(w io.Writer, doc *document) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Conversation %s\n\n", doc.ID)
+	if !doc.CreatedAt.IsZero() {
+		fmt.Fprintf(&b, "- Started: %s\n", doc.CreatedAt.Format(time.RFC1123))
+	}
+	if !doc.UpdatedAt.IsZero() {
+		fmt.Fprintf(&b, "- Last updated: %s\n", doc.UpdatedAt.Format(time.RFC1123))
+	}
+	if len(doc.Labels) > 0 {
+		fmt.Fprintf(&b, "- Labels: %s\n", strings.Join(doc.Labels, ", "))
+	}
+
+	for _, m := range doc.Messages {
+		fmt.Fprintf(&b, "\n## %s\n\n", m.Label)
+		if m.Content != "" {
+			if m.Prose {
+				b.WriteString(m.Content + "\n")
+			} else {
+				b.WriteString(codeBlock("", m.Content))
+			}
+		}
+		for _, call := range m.ToolCalls {
+			// GitHub and most renderers show details elements collapsed
+			fmt.Fprintf(&b, "\n<details>\n<summary>Tool call: <code>%s</code></summary>\n\n", html.EscapeString(call.Name))
+			b.WriteString("**Arguments**\n\n" + codeBlock("json", call.Arguments))
+			if call.HasResult {
+				b.WriteString("\n**Result**\n\n" + codeBlock("", call.Result))
+			}
+			b.WriteString("\n</details>\n")
+		}
+	}
+
+	if doc.State != "" {
+		b.WriteString("\n## State\n\n" + codeBlock("json", doc.State))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// codeBlock fences text, with a fence longer than any backtick run in it
+func codeBlock(lang, text string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + strings.TrimRight(text, "\n") + "\n" + fence + "\n"
+}
@@ -0,0 +1,82 @@
+// Package filter narrows down the events printed by the line mode commands
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/tidwall/gjson"
+)
+
+// Types is an allow/deny list of event types, parsed from a comma separated
+// list such as "TOOL_CALL_RESULT,STATE_DELTA" or "-TEXT_MESSAGE_*". Names
+// prefixed with - are denied, a trailing * matches any suffix. With no
+// allowed names every type that is not denied matches.
+type Types struct {
+	allow []string
+	deny  []string
+}
+
+func ParseTypes(spec string) (Types, error) {
+	var t Types
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		deny := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+		if name == "" || strings.Contains(strings.TrimSuffix(name, "*"), "*") {
+			return Types{}, fmt.Errorf("invalid event type pattern %q", name)
+		}
+		if deny {
+			t.deny = append(t.deny, name)
+		} else {
+			t.allow = append(t.allow, name)
+		}
+	}
+	return t, nil
+}
+
+// IsZero reports whether the filter matches every event type
+func (t Types) IsZero() bool {
+	return len(t.allow) == 0 && len(t.deny) == 0
+}
+
+// Match reports whether events of type eventType pass the filter
+func (t Types) Match(eventType events.EventType) bool {
+	for _, pattern := range t.deny {
+		if match(pattern, string(eventType)) {
+			return false
+		}
+	}
+	if len(t.allow) == 0 {
+		return true
+	}
+	for _, pattern := range t.allow {
+		if match(pattern, string(eventType)) {
+			return true
+		}
+	}
+	return false
+}
+
+func match(pattern, name string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(name, prefix)
+	}
+	return pattern == name
+}
+
+// Select evaluates the gjson path expression path against the JSON of an
+// event, see https://github.com/tidwall/gjson/blob/master/SYNTAX.md. Strings
+// are returned without quotes, other values as JSON. ok is false if the path
+// does not match anything.
+func Select(data []byte, path string) (value string, ok bool) {
+	result := gjson.GetBytes(data, path)
+	if !result.Exists() {
+		return "", false
+	}
+	return result.String(), true
+}
@@ -0,0 +1,97 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+func TestTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		zero    bool
+		match   []events.EventType
+		noMatch []events.EventType
+	}{
+		{
+			name:  "empty",
+			spec:  "",
+			zero:  true,
+			match: []events.EventType{events.EventTypeRunStarted, events.EventTypeTextMessageContent},
+		},
+		{
+			name:    "allow list",
+			spec:    "TOOL_CALL_RESULT, state_delta",
+			match:   []events.EventType{events.EventTypeToolCallResult, events.EventTypeStateDelta},
+			noMatch: []events.EventType{events.EventTypeRunStarted, events.EventTypeStateSnapshot},
+		},
+		{
+			name:    "deny wildcard",
+			spec:    "-TEXT_MESSAGE_*",
+			match:   []events.EventType{events.EventTypeRunStarted, events.EventTypeToolCallStart},
+			noMatch: []events.EventType{events.EventTypeTextMessageStart, events.EventTypeTextMessageContent},
+		},
+		{
+			name:    "deny wins over allow",
+			spec:    "TOOL_CALL_*,-TOOL_CALL_ARGS",
+			match:   []events.EventType{events.EventTypeToolCallStart, events.EventTypeToolCallEnd},
+			noMatch: []events.EventType{events.EventTypeToolCallArgs, events.EventTypeRunFinished},
+		},
+		{
+			name:  "blank entries are skipped",
+			spec:  " , ,",
+			zero:  true,
+			match: []events.EventType{events.EventTypeRunStarted},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			types, err := ParseTypes(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.zero, types.IsZero())
+			for _, eventType := range tt.match {
+				assert.True(t, types.Match(eventType), eventType)
+			}
+			for _, eventType := range tt.noMatch {
+				assert.False(t, types.Match(eventType), eventType)
+			}
+		})
+	}
+}
+
+func TestParseTypesInvalid(t *testing.T) {
+	for _, spec := range []string{"-", "TEXT_*_END", "*_END", "RUN_STARTED,-"} {
+		t.Run(spec, func(t *testing.T) {
+			_, err := ParseTypes(spec)
+			assert.ErrorContains(t, err, "invalid event type pattern")
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	data := []byte(`{"type":"TOOL_CALL_RESULT","toolCallId":"call-1","content":"42","delta":[{"op":"add","path":"/a"}],"count":3}`)
+	tests := []struct {
+		path  string
+		want  string
+		found bool
+	}{
+		{"type", "TOOL_CALL_RESULT", true},
+		{"content", "42", true},
+		{"count", "3", true},
+		{"delta.0.op", "add", true},
+		{"delta.#", "1", true},
+		{"delta.0", `{"op":"add","path":"/a"}`, true},
+		{"missing", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			value, ok := Select(data, tt.path)
+			assert.Equal(t, tt.found, ok)
+			assert.Equal(t, tt.want, value)
+		})
+	}
+}
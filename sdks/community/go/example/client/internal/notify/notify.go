@@ -0,0 +1,175 @@
+// Package notify tells the user that a run has ended, so that long agent
+// tasks can be left running in the background. It shows a desktop
+// notification, or runs a hook command given the metadata of the run.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// Statuses of an ended run
+const (
+	StatusFinished = "finished"
+	StatusError    = "error"
+)
+
+// timeout bounds the time a notification or hook may take
+const timeout = 10 * time.Second
+
+// runCommand runs a notification or hook command, returning its output
+var runCommand = (*exec.Cmd).CombinedOutput
+
+// Run is the metadata of an ended run. Hooks get it as JSON on stdin.
+type Run struct {
+	ThreadID  string    `json:"threadId"`
+	RunID     string    `json:"runId"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Endpoint  string    `json:"endpoint"`
+	StartedAt time.Time `json:"startedAt"`
+	EndedAt   time.Time `json:"endedAt"`
+	Events    int       `json:"events"`
+}
+
+// Notifier watches the events of runs and notifies when one ends. It must
+// be given the events of one run at a time; a nil Notifier does nothing.
+type Notifier struct {
+	// Hook is a shell command run instead of the desktop notification
+	Hook     string
+	Endpoint string
+
+	run Run
+	// ended is the ID of the run notified last
+	ended string
+}
+
+// Observe records an event, notifying when it ends the run
+func (n *Notifier) Observe(ctx context.Context, event events.Event) error {
+	if n == nil {
+		return nil
+	}
+	if n.run.Events == 0 && n.ended != "" && event.RunID() == n.ended {
+		// Trailing events of the run notified last, e.g. a RUN_ERROR after
+		// its RUN_FINISHED, do not notify it again
+		return nil
+	}
+	now := time.Now()
+	if n.run.Events == 0 {
+		n.run.StartedAt = now
+	}
+	n.run.Events++
+
+	switch e := event.(type) {
+	case *events.RunStartedEvent:
+		n.run.ThreadID, n.run.RunID = e.ThreadIDValue, e.RunIDValue
+		return nil
+	case *events.RunFinishedEvent:
+		n.run.Status = StatusFinished
+		if n.run.RunID == "" {
+			n.run.ThreadID, n.run.RunID = e.ThreadIDValue, e.RunIDValue
+		}
+	case *events.RunErrorEvent:
+		n.run.Status, n.run.Error = StatusError, e.Message
+		if n.run.RunID == "" {
+			n.run.RunID = e.RunIDValue
+		}
+	default:
+		return nil
+	}
+
+	run := n.run
+	run.Endpoint, run.EndedAt = n.Endpoint, now
+	n.run, n.ended = Run{}, run.RunID
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if n.Hook != "" {
+		return Hook(ctx, n.Hook, run)
+	}
+	title, body := summary(run)
+	return Desktop(ctx, title, body)
+}
+
+func summary(run Run) (title, body string) {
+	took := run.EndedAt.Sub(run.StartedAt).Round(time.Second)
+	if run.Status == StatusError {
+		return "Agent run failed", fmt.Sprintf("%s (after %s)", run.Error, took)
+	}
+	return "Agent run finished", fmt.Sprintf("Run %s finished in %s", run.RunID, took)
+}
+
+// Desktop shows a desktop notification with notify-send, or the native
+// mechanism on macOS and Windows. The text is passed through the
+// environment so that it needs no quoting.
+func Desktop(ctx context.Context, title, body string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.CommandContext(ctx, "osascript", "-e",
+			`display notification (system attribute "AG_UI_NOTIFY_BODY") with title (system attribute "AG_UI_NOTIFY_TITLE")`)
+	case "windows":
+		cmd = exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", `
+Add-Type -AssemblyName System.Windows.Forms
+$icon = New-Object System.Windows.Forms.NotifyIcon
+$icon.Icon = [System.Drawing.SystemIcons]::Information
+$icon.Visible = $true
+$icon.ShowBalloonTip(10000, $env:AG_UI_NOTIFY_TITLE, $env:AG_UI_NOTIFY_BODY, 'Info')
+Start-Sleep -Seconds 1
+$icon.Dispose()`)
+	default:
+		cmd = exec.CommandContext(ctx, "notify-send", "--app-name=ag-ui-client", "--", title, body)
+	}
+	cmd.Env = append(os.Environ(), "AG_UI_NOTIFY_TITLE="+title, "AG_UI_NOTIFY_BODY="+body)
+	if out, err := runCommand(cmd); err != nil {
+		return fmt.Errorf("failed to show notification: %w%s", err, detail(out))
+	}
+	return nil
+}
+
+// Hook runs command with the shell. The run is described by the
+// AG_UI_RUN_* environment variables and as JSON on stdin.
+func Hook(ctx context.Context, command string, run Run) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Env = append(os.Environ(),
+		"AG_UI_RUN_STATUS="+run.Status,
+		"AG_UI_RUN_ERROR="+run.Error,
+		"AG_UI_THREAD_ID="+run.ThreadID,
+		"AG_UI_RUN_ID="+run.RunID,
+		"AG_UI_RUN_DURATION="+strconv.FormatFloat(run.EndedAt.Sub(run.StartedAt).Seconds(), 'f', 3, 64),
+		"AG_UI_RUN_EVENTS="+strconv.Itoa(run.Events),
+		"AG_UI_ENDPOINT="+run.Endpoint,
+	)
+	if out, err := runCommand(cmd); err != nil {
+		return fmt.Errorf("notify hook failed: %w%s", err, detail(out))
+	}
+	return nil
+}
+
+// detail formats the output of a failed command for its error
+func detail(out []byte) string {
+	if out = bytes.TrimSpace(out); len(out) == 0 {
+		return ""
+	}
+	return ": " + string(out)
+}
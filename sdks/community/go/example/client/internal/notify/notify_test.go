@@ -0,0 +1,211 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// fakeRunner replaces the command runner, recording the commands run
+func fakeRunner(t *testing.T, out string, err error) *[]*exec.Cmd {
+	t.Helper()
+	var cmds []*exec.Cmd
+	original := runCommand
+	runCommand = func(cmd *exec.Cmd) ([]byte, error) {
+		cmds = append(cmds, cmd)
+		return []byte(out), err
+	}
+	t.Cleanup(func() { runCommand = original })
+	return &cmds
+}
+
+// env returns the value of the last definition of key in cmd's environment
+func env(cmd *exec.Cmd, key string) string {
+	var value string
+	for _, kv := range cmd.Env {
+		if v, ok := strings.CutPrefix(kv, key+"="); ok {
+			value = v
+		}
+	}
+	return value
+}
+
+func TestObserve(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []events.Event
+		want   []Run
+	}{
+		{
+			name: "finished",
+			events: []events.Event{
+				events.NewRunStartedEvent("thread-1", "run-1"),
+				events.NewTextMessageStartEvent("msg-1"),
+				events.NewTextMessageEndEvent("msg-1"),
+				events.NewRunFinishedEvent("thread-1", "run-1"),
+			},
+			want: []Run{{ThreadID: "thread-1", RunID: "run-1", Status: StatusFinished, Events: 4}},
+		},
+		{
+			name: "error",
+			events: []events.Event{
+				events.NewRunStartedEvent("thread-1", "run-1"),
+				events.NewRunErrorEvent("model unavailable"),
+			},
+			want: []Run{{ThreadID: "thread-1", RunID: "run-1", Status: StatusError, Error: "model unavailable", Events: 2}},
+		},
+		{
+			name: "without RUN_STARTED",
+			events: []events.Event{
+				events.NewStepStartedEvent("work"),
+				events.NewRunFinishedEvent("thread-1", "run-1"),
+			},
+			want: []Run{{ThreadID: "thread-1", RunID: "run-1", Status: StatusFinished, Events: 2}},
+		},
+		{
+			name: "run in progress",
+			events: []events.Event{
+				events.NewRunStartedEvent("thread-1", "run-1"),
+				events.NewStepStartedEvent("work"),
+			},
+		},
+		{
+			name: "trailing events of an ended run",
+			events: []events.Event{
+				events.NewRunStartedEvent("thread-1", "run-1"),
+				events.NewRunFinishedEvent("thread-1", "run-1"),
+				events.NewRunErrorEvent("stream closed", events.WithRunID("run-1")),
+			},
+			want: []Run{{ThreadID: "thread-1", RunID: "run-1", Status: StatusFinished, Events: 2}},
+		},
+		{
+			name: "consecutive runs",
+			events: []events.Event{
+				events.NewRunStartedEvent("thread-1", "run-1"),
+				events.NewRunFinishedEvent("thread-1", "run-1"),
+				events.NewRunStartedEvent("thread-1", "run-2"),
+				events.NewStepStartedEvent("work"),
+				events.NewRunErrorEvent("cancelled", events.WithRunID("run-2")),
+			},
+			want: []Run{
+				{ThreadID: "thread-1", RunID: "run-1", Status: StatusFinished, Events: 2},
+				{ThreadID: "thread-1", RunID: "run-2", Status: StatusError, Error: "cancelled", Events: 3},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmds := fakeRunner(t, "", nil)
+			n := &Notifier{Hook: "notify-me", Endpoint: "https://agents.example.com"}
+			for _, event := range tt.events {
+				require.NoError(t, n.Observe(context.Background(), event))
+			}
+
+			require.Len(t, *cmds, len(tt.want))
+			for i, cmd := range *cmds {
+				data, err := io.ReadAll(cmd.Stdin)
+				require.NoError(t, err)
+				var run Run
+				require.NoError(t, json.Unmarshal(data, &run))
+				assert.False(t, run.EndedAt.Before(run.StartedAt))
+				run.StartedAt, run.EndedAt = time.Time{}, time.Time{}
+				want := tt.want[i]
+				want.Endpoint = "https://agents.example.com"
+				assert.Equal(t, want, run)
+			}
+		})
+	}
+
+	t.Run("nil notifier", func(t *testing.T) {
+		cmds := fakeRunner(t, "", nil)
+		var n *Notifier
+		require.NoError(t, n.Observe(context.Background(), events.NewRunFinishedEvent("thread-1", "run-1")))
+		assert.Empty(t, *cmds)
+	})
+
+	t.Run("desktop notification without a hook", func(t *testing.T) {
+		cmds := fakeRunner(t, "", nil)
+		n := &Notifier{}
+		require.NoError(t, n.Observe(context.Background(), events.NewRunErrorEvent("model unavailable")))
+		require.Len(t, *cmds, 1)
+		assert.Equal(t, "Agent run failed", env((*cmds)[0], "AG_UI_NOTIFY_TITLE"))
+		assert.Contains(t, env((*cmds)[0], "AG_UI_NOTIFY_BODY"), "model unavailable")
+	})
+}
+
+func TestDesktop(t *testing.T) {
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		t.Skip("notify-send is used on other systems only")
+	}
+	cmds := fakeRunner(t, "", nil)
+	require.NoError(t, Desktop(context.Background(), "--help", "-u critical"))
+
+	require.Len(t, *cmds, 1)
+	cmd := (*cmds)[0]
+	assert.Equal(t, []string{"notify-send", "--app-name=ag-ui-client", "--", "--help", "-u critical"}, cmd.Args,
+		"text starting with a dash is not taken for options")
+	assert.Equal(t, "--help", env(cmd, "AG_UI_NOTIFY_TITLE"))
+	assert.Equal(t, "-u critical", env(cmd, "AG_UI_NOTIFY_BODY"))
+}
+
+func TestHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks run with cmd on Windows")
+	}
+	cmds := fakeRunner(t, "", nil)
+	started := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	run := Run{
+		ThreadID:  "thread-1",
+		RunID:     "run-1",
+		Status:    StatusError,
+		Error:     "model unavailable",
+		Endpoint:  "https://agents.example.com",
+		StartedAt: started,
+		EndedAt:   started.Add(1500 * time.Millisecond),
+		Events:    7,
+	}
+	t.Setenv("AG_UI_RUN_ID", "stale")
+	require.NoError(t, Hook(context.Background(), "notify-me --now", run))
+
+	require.Len(t, *cmds, 1)
+	cmd := (*cmds)[0]
+	assert.Equal(t, []string{"sh", "-c", "notify-me --now"}, cmd.Args)
+	for key, want := range map[string]string{
+		"AG_UI_RUN_STATUS":   StatusError,
+		"AG_UI_RUN_ERROR":    "model unavailable",
+		"AG_UI_THREAD_ID":    "thread-1",
+		"AG_UI_RUN_ID":       "run-1",
+		"AG_UI_RUN_DURATION": "1.500",
+		"AG_UI_RUN_EVENTS":   "7",
+		"AG_UI_ENDPOINT":     "https://agents.example.com",
+	} {
+		assert.Equal(t, want, env(cmd, key), key)
+	}
+
+	data, err := io.ReadAll(cmd.Stdin)
+	require.NoError(t, err)
+	var got Run
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, run, got)
+}
+
+func TestCommandFailure(t *testing.T) {
+	fakeRunner(t, "  no such command\n", errors.New("exit status 127"))
+	err := Hook(context.Background(), "notify-me", Run{})
+	assert.EqualError(t, err, "notify hook failed: exit status 127: no such command")
+
+	fakeRunner(t, "", errors.New("exit status 1"))
+	err = Desktop(context.Background(), "title", "body")
+	assert.EqualError(t, err, "failed to show notification: exit status 1")
+}
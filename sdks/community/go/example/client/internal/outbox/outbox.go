@@ -0,0 +1,132 @@
+// Package outbox queues chat messages until the server has accepted them,
+// so input typed while the server is unreachable is not lost. The queue is
+// kept in a JSON file and survives restarts of the client.
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Item is a queued message
+type Item struct {
+	ID       string    `json:"id"`
+	Content  string    `json:"content"`
+	QueuedAt time.Time `json:"queuedAt"`
+}
+
+// Outbox is a FIFO queue of messages. It is safe for concurrent use.
+type Outbox struct {
+	path string
+
+	mu    sync.Mutex
+	items []Item
+}
+
+// Open loads the outbox stored at path. A missing file is an empty outbox,
+// an empty path keeps the outbox in memory only.
+func Open(path string) (*Outbox, error) {
+	o := &Outbox{path: path}
+	if path == "" {
+		return o, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return o, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &o.items); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Push queues content behind the queued messages
+func (o *Outbox) Push(content string) (Item, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	item := Item{ID: uuid.NewString(), Content: content, QueuedAt: time.Now()}
+	o.items = append(o.items, item)
+	return item, o.save()
+}
+
+// Peek returns the oldest queued message
+func (o *Outbox) Peek() (Item, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.items) == 0 {
+		return Item{}, false
+	}
+	return o.items[0], true
+}
+
+// Remove drops a message once it has been delivered
+func (o *Outbox) Remove(id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i, item := range o.items {
+		if item.ID == id {
+			o.items = append(o.items[:i], o.items[i+1:]...)
+			return o.save()
+		}
+	}
+	return nil
+}
+
+// Items returns the queued messages, oldest first
+func (o *Outbox) Items() []Item {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]Item(nil), o.items...)
+}
+
+func (o *Outbox) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.items)
+}
+
+// save writes the queue through a temporary file so a crash never leaves a
+// truncated outbox behind
+func (o *Outbox) save() error {
+	if o.path == "" {
+		return nil
+	}
+	if len(o.items) == 0 {
+		err := os.Remove(o.path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	data, err := json.Marshal(o.items)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(o.path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(o.path), ".outbox-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), o.path)
+}
@@ -0,0 +1,115 @@
+package outbox
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// contents returns the contents of the queued messages
+func contents(o *Outbox) []string {
+	var contents []string
+	for _, item := range o.Items() {
+		contents = append(contents, item.Content)
+	}
+	return contents
+}
+
+func TestQueue(t *testing.T) {
+	for _, path := range []string{"", filepath.Join(t.TempDir(), "outbox", "queue.json")} {
+		t.Run(fmt.Sprintf("path=%q", path), func(t *testing.T) {
+			o, err := Open(path)
+			require.NoError(t, err)
+			_, ok := o.Peek()
+			assert.False(t, ok)
+
+			first, err := o.Push("first")
+			require.NoError(t, err)
+			second, err := o.Push("second")
+			require.NoError(t, err)
+			assert.NotEqual(t, first.ID, second.ID)
+			assert.False(t, first.QueuedAt.IsZero())
+			assert.Equal(t, 2, o.Len())
+
+			item, ok := o.Peek()
+			require.True(t, ok)
+			assert.Equal(t, first, item, "oldest first")
+			assert.Equal(t, 2, o.Len(), "Peek leaves the message queued")
+
+			require.NoError(t, o.Remove(first.ID))
+			require.NoError(t, o.Remove("unknown"))
+			assert.Equal(t, []string{"second"}, contents(o))
+
+			require.NoError(t, o.Remove(second.ID))
+			assert.Zero(t, o.Len())
+		})
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox", "queue.json")
+	o, err := Open(path)
+	require.NoError(t, err)
+	first, err := o.Push("first")
+	require.NoError(t, err)
+	_, err = o.Push("second")
+	require.NoError(t, err)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, contents(reopened))
+	item, _ := reopened.Peek()
+	assert.Equal(t, first.ID, item.ID)
+	assert.True(t, first.QueuedAt.Equal(item.QueuedAt))
+
+	// Draining the queue removes the file
+	for _, item := range reopened.Items() {
+		require.NoError(t, reopened.Remove(item.ID))
+	}
+	_, err = os.Stat(path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Empty(t, entries, "no temporary files are left behind")
+
+	o, err = Open(path)
+	require.NoError(t, err)
+	assert.Zero(t, o.Len())
+}
+
+func TestOpenInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+	_, err := Open(path)
+	assert.Error(t, err)
+}
+
+func TestConcurrentPush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	o, err := Open(path)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := o.Push(fmt.Sprintf("message %d", i))
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 20, o.Len())
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, contents(o), contents(reopened))
+}
@@ -0,0 +1,149 @@
+// Package plugin finds the executables that add custom subcommands to the
+// client. A plugin is either an executable named ag-ui-client-<name> on
+// PATH, or a directory in the plugins directory with a plugin.json manifest:
+//
+//	{"name": "deploy", "description": "Deploy the agent", "exec": "bin/deploy"}
+//
+// exec is relative to the plugin's directory, name defaults to the
+// directory name. Plugins in the plugins directory take precedence over
+// those on PATH. Plugins only get the API key of the profile when their
+// manifest asks for it with "apiKey": true; executables on PATH never do.
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Prefix is the prefix of plugin executables on PATH
+const Prefix = "ag-ui-client-"
+
+// ManifestFile is the name of the manifest in a plugin directory
+const ManifestFile = "plugin.json"
+
+var ErrNotFound = errors.New("plugin not found")
+
+// Manifest describes a plugin in the plugins directory
+type Manifest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Exec        string   `json:"exec"`
+	Args        []string `json:"args,omitempty"`
+
+	// APIKey opts the plugin in to receiving the API key of the profile
+	APIKey bool `json:"apiKey,omitempty"`
+}
+
+// Plugin is an installed plugin
+type Plugin struct {
+	Name        string
+	Description string
+	Path        string
+
+	// Args are passed to the plugin before the arguments of the command
+	Args []string
+
+	// APIKey is set when the plugin is given the API key of the profile
+	APIKey bool
+}
+
+// Find returns the plugin called name from dir or PATH
+func Find(dir, name string) (*Plugin, error) {
+	plugins, err := fromDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range plugins {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+
+	path, err := exec.LookPath(Prefix + name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return &Plugin{Name: name, Path: path}, nil
+}
+
+// List returns the plugins in dir and on PATH, sorted by name
+func List(dir string) ([]*Plugin, error) {
+	plugins, err := fromDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for _, p := range plugins {
+		seen[p.Name] = true
+	}
+
+	for _, pathDir := range filepath.SplitList(os.Getenv("PATH")) {
+		matches, _ := filepath.Glob(filepath.Join(pathDir, Prefix+"*"))
+		for _, path := range matches {
+			name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), Prefix), filepath.Ext(path))
+			if name == "" || seen[name] || !executable(path) {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, &Plugin{Name: name, Path: path})
+		}
+	}
+
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins, nil
+}
+
+// fromDir reads the manifests of the plugins in dir. A missing directory
+// has no plugins.
+func fromDir(dir string) ([]*Plugin, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var plugins []*Plugin
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		pluginDir := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(filepath.Join(pluginDir, ManifestFile))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var m Manifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("invalid manifest %s: %w", filepath.Join(pluginDir, ManifestFile), err)
+		}
+		if m.Name == "" {
+			m.Name = entry.Name()
+		}
+		if m.Exec == "" {
+			return nil, fmt.Errorf("manifest of plugin %s has no exec", m.Name)
+		}
+		path := m.Exec
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(pluginDir, path)
+		}
+		plugins = append(plugins, &Plugin{Name: m.Name, Description: m.Description, Path: path, Args: m.Args, APIKey: m.APIKey})
+	}
+	return plugins, nil
+}
+
+func executable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir() && info.Mode()&0o111 != 0
+}
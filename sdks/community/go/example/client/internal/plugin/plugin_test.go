@@ -0,0 +1,112 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFile creates a file and its directory
+func writeFile(t *testing.T, path, content string, mode os.FileMode) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), mode))
+}
+
+// setup creates a plugins directory and a PATH with these plugins:
+//   - deploy, both in the plugins directory and on PATH
+//   - lint, on PATH only
+//   - notes, in the plugins directory with a default name and an absolute exec
+//   - secret, in the plugins directory opting in to the API key
+//
+// and a non executable ag-ui-client-readme on PATH
+func setup(t *testing.T) (dir, bin string) {
+	dir, bin = filepath.Join(t.TempDir(), "plugins"), t.TempDir()
+	t.Setenv("PATH", bin)
+
+	writeFile(t, filepath.Join(bin, Prefix+"deploy"), "#!/bin/sh\n", 0o755)
+	writeFile(t, filepath.Join(bin, Prefix+"lint"), "#!/bin/sh\n", 0o755)
+	writeFile(t, filepath.Join(bin, Prefix+"readme"), "not a plugin", 0o644)
+
+	writeFile(t, filepath.Join(dir, "deploy", ManifestFile), `{"name":"deploy","description":"Deploy the agent","exec":"bin/deploy","args":["--quiet"]}`, 0o644)
+	writeFile(t, filepath.Join(dir, "notes", ManifestFile), `{"exec":"/usr/local/bin/notes"}`, 0o644)
+	writeFile(t, filepath.Join(dir, "secret", ManifestFile), `{"exec":"run","apiKey":true}`, 0o644)
+	writeFile(t, filepath.Join(dir, "empty", "README"), "no manifest", 0o644)
+	writeFile(t, filepath.Join(dir, "stray.json"), "{}", 0o644)
+	return dir, bin
+}
+
+func TestFind(t *testing.T) {
+	dir, bin := setup(t)
+	tests := []struct {
+		name string
+		want *Plugin
+	}{
+		{"deploy", &Plugin{Name: "deploy", Description: "Deploy the agent", Path: filepath.Join(dir, "deploy", "bin", "deploy"), Args: []string{"--quiet"}}},
+		{"lint", &Plugin{Name: "lint", Path: filepath.Join(bin, Prefix+"lint")}},
+		{"notes", &Plugin{Name: "notes", Path: "/usr/local/bin/notes"}},
+		{"secret", &Plugin{Name: "secret", Path: filepath.Join(dir, "secret", "run"), APIKey: true}},
+		{"readme", nil},
+		{"missing", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Find(dir, tt.name)
+			if tt.want == nil {
+				assert.ErrorIs(t, err, ErrNotFound)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, p)
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	dir, bin := setup(t)
+	plugins, err := List(dir)
+	require.NoError(t, err)
+
+	var names, paths []string
+	for _, p := range plugins {
+		names = append(names, p.Name)
+		paths = append(paths, p.Path)
+	}
+	assert.Equal(t, []string{"deploy", "lint", "notes", "secret"}, names, "sorted, without duplicates or non executables")
+	assert.Equal(t, filepath.Join(dir, "deploy", "bin", "deploy"), paths[0], "the plugins directory takes precedence")
+	assert.Equal(t, filepath.Join(bin, Prefix+"lint"), paths[1])
+
+	// Without a plugins directory only PATH is searched
+	plugins, err = List(filepath.Join(t.TempDir(), "missing"))
+	require.NoError(t, err)
+	require.Len(t, plugins, 2)
+	assert.Equal(t, filepath.Join(bin, Prefix+"deploy"), plugins[0].Path)
+}
+
+func TestInvalidManifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		wantErr  string
+	}{
+		{"malformed", `{"exec":`, "invalid manifest"},
+		{"wrong type", `{"exec":"run","args":"--quiet"}`, "invalid manifest"},
+		{"no exec", `{"name":"deploy"}`, "manifest of plugin deploy has no exec"},
+		{"no exec nor name", `{}`, "manifest of plugin broken has no exec"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("PATH", t.TempDir())
+			writeFile(t, filepath.Join(dir, "broken", ManifestFile), tt.manifest, 0o644)
+
+			_, err := Find(dir, "lint")
+			assert.ErrorContains(t, err, tt.wantErr)
+			_, err = List(dir)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
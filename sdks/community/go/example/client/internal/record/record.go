@@ -0,0 +1,97 @@
+// Package record stores the raw SSE frames of a run so it can be replayed
+// later. A recording is a file of JSON lines, one frame per line:
+//
+//	{"time":"2025-01-02T15:04:05.123456Z","data":"{\"type\":\"RUN_STARTED\",...}"}
+//
+// Frames are kept verbatim, including ones that fail to parse, so recordings
+// can be attached to bug reports as they are.
+package record
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+)
+
+// Frame is one recorded SSE frame
+type Frame struct {
+	Time time.Time `json:"time"`
+	Data string    `json:"data"`
+}
+
+// Writer appends frames to a recording
+type Writer struct {
+	enc *json.Encoder
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{enc: json.NewEncoder(w)}
+}
+
+// Write records frame, stamping it with the current time if it has none
+func (w *Writer) Write(frame sse.Frame) error {
+	t := frame.Timestamp
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return w.enc.Encode(Frame{Time: t, Data: string(frame.Data)})
+}
+
+// Reader reads the frames of a recording
+type Reader struct {
+	dec *json.Decoder
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{dec: json.NewDecoder(r)}
+}
+
+// Next returns the next frame, or io.EOF at the end of the recording
+func (r *Reader) Next() (Frame, error) {
+	var frame Frame
+	if err := r.dec.Decode(&frame); err != nil {
+		if errors.Is(err, io.EOF) {
+			return Frame{}, io.EOF
+		}
+		return Frame{}, fmt.Errorf("invalid recording: %w", err)
+	}
+	return frame, nil
+}
+
+// Replay passes the frames of r to handle, waiting between frames as long as
+// they were apart when recorded divided by speed. A speed of 0 or less
+// replays without waiting.
+func Replay(ctx context.Context, r *Reader, speed float64, handle func(Frame) error) error {
+	var last time.Time
+	for {
+		frame, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if speed > 0 && !last.IsZero() {
+			if delay := time.Duration(float64(frame.Time.Sub(last)) / speed); delay > 0 {
+				timer := time.NewTimer(delay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-timer.C:
+				}
+			}
+		}
+		last = frame.Time
+
+		if err := handle(frame); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package record
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+)
+
+// record writes frames recorded at the given offsets from a fixed start
+func record(t *testing.T, offsets []time.Duration, data ...string) *bytes.Buffer {
+	t.Helper()
+	start := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for i, d := range data {
+		require.NoError(t, w.Write(sse.Frame{Data: []byte(d), Timestamp: start.Add(offsets[i])}))
+	}
+	return &buf
+}
+
+func TestRoundTrip(t *testing.T) {
+	data := []string{
+		`{"type":"RUN_STARTED","threadId":"thread-1","runId":"run-1"}`,
+		`not json, kept verbatim`,
+		`{"type":"RUN_FINISHED","threadId":"thread-1","runId":"run-1"}`,
+	}
+	buf := record(t, []time.Duration{0, time.Second, 2 * time.Second}, data...)
+	assert.Equal(t, 3, strings.Count(buf.String(), "\n"), "one frame per line")
+
+	r := NewReader(buf)
+	for i, want := range data {
+		frame, err := r.Next()
+		require.NoError(t, err)
+		assert.Equal(t, want, frame.Data)
+		assert.Equal(t, time.Date(2025, 1, 2, 15, 4, 5+i, 0, time.UTC), frame.Time)
+	}
+	_, err := r.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestWriteStampsFrames(t *testing.T) {
+	var buf bytes.Buffer
+	before := time.Now()
+	require.NoError(t, NewWriter(&buf).Write(sse.Frame{Data: []byte("{}")}))
+
+	frame, err := NewReader(&buf).Next()
+	require.NoError(t, err)
+	assert.False(t, frame.Time.Before(before.Truncate(time.Microsecond)))
+}
+
+func TestReaderInvalid(t *testing.T) {
+	r := NewReader(strings.NewReader("{\"time\":\"2025-01-02T15:04:05Z\",\"data\":\"{}\"}\nnot a frame\n"))
+	_, err := r.Next()
+	require.NoError(t, err)
+	_, err = r.Next()
+	assert.ErrorContains(t, err, "invalid recording")
+	assert.NotErrorIs(t, err, io.EOF)
+}
+
+func TestReplay(t *testing.T) {
+	offsets := []time.Duration{0, 200 * time.Millisecond, 400 * time.Millisecond}
+	tests := []struct {
+		name    string
+		speed   float64
+		atLeast time.Duration
+		atMost  time.Duration
+	}{
+		{name: "without waiting", speed: 0, atMost: 100 * time.Millisecond},
+		{name: "real time", speed: 1, atLeast: 400 * time.Millisecond},
+		{name: "faster", speed: 10, atLeast: 40 * time.Millisecond, atMost: 300 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := record(t, offsets, "a", "b", "c")
+			var got []string
+			start := time.Now()
+			err := Replay(context.Background(), NewReader(buf), tt.speed, func(frame Frame) error {
+				got = append(got, frame.Data)
+				return nil
+			})
+			elapsed := time.Since(start)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"a", "b", "c"}, got)
+			assert.GreaterOrEqual(t, elapsed, tt.atLeast)
+			if tt.atMost > 0 {
+				assert.Less(t, elapsed, tt.atMost)
+			}
+		})
+	}
+}
+
+func TestReplayStops(t *testing.T) {
+	t.Run("handler error", func(t *testing.T) {
+		buf := record(t, []time.Duration{0, 0, 0}, "a", "b", "c")
+		stop := errors.New("stop")
+		var got []string
+		err := Replay(context.Background(), NewReader(buf), 0, func(frame Frame) error {
+			got = append(got, frame.Data)
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, []string{"a"}, got)
+	})
+
+	t.Run("cancelled while waiting", func(t *testing.T) {
+		buf := record(t, []time.Duration{0, time.Hour}, "a", "b")
+		ctx, cancel := context.WithCancel(context.Background())
+		var got []string
+		err := Replay(ctx, NewReader(buf), 1, func(frame Frame) error {
+			got = append(got, frame.Data)
+			cancel()
+			return nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, []string{"a"}, got)
+	})
+
+	t.Run("invalid frame", func(t *testing.T) {
+		buf := record(t, []time.Duration{0}, "a")
+		buf.WriteString("garbage\n")
+		err := Replay(context.Background(), NewReader(buf), 0, func(Frame) error { return nil })
+		assert.ErrorContains(t, err, "invalid recording")
+	})
+}
@@ -0,0 +1,223 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/diff"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// columnGap separates the columns of the comparison
+const columnGap = 3
+
+// CompareEventMsg delivers an event streamed by one of the compared runs
+type CompareEventMsg struct {
+	Index int
+	Event events.Event
+}
+
+// CompareDoneMsg reports that one of the compared runs has ended
+type CompareDoneMsg struct {
+	Index int
+	Err   error
+}
+
+// compareColumn is the response of one server
+type compareColumn struct {
+	name       string
+	transcript transcript
+	firstEvent time.Duration
+	duration   time.Duration
+	events     int
+	done       bool
+	err        error
+}
+
+// CompareModel shows the responses of several servers to one prompt side
+// by side, or their differences from the first one
+type CompareModel struct {
+	prompt   string
+	columns  []*compareColumn
+	start    time.Time
+	viewport viewport.Model
+
+	width, height int
+	ready         bool
+	showDiff      bool
+	typingDots    int
+}
+
+// NewCompareModel creates the comparison of the servers called names. The
+// events of server i must be delivered as CompareEventMsg with Index i,
+// followed by a CompareDoneMsg.
+func NewCompareModel(prompt string, names []string, showDiff bool) *CompareModel {
+	m := &CompareModel{prompt: prompt, start: time.Now(), showDiff: showDiff, viewport: getViewport(80, 20)}
+	for _, name := range names {
+		m.columns = append(m.columns, &compareColumn{name: name})
+	}
+	return m
+}
+
+func (m *CompareModel) Init() tea.Cmd {
+	return tea.Batch(tea.EnterAltScreen, tickCmd())
+}
+
+func (m *CompareModel) running() bool {
+	for _, c := range m.columns {
+		if !c.done {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *CompareModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.viewport.Width = m.width - 4
+		m.viewport.Height = m.height - 8
+		m.ready = true
+		m.updateContent()
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q":
+			return m, tea.Quit
+		case "d":
+			m.showDiff = !m.showDiff
+			m.updateContent()
+		case "g":
+			m.viewport.GotoTop()
+		case "G":
+			m.viewport.GotoBottom()
+		}
+
+	case CompareEventMsg:
+		c := m.columns[msg.Index]
+		if c.events == 0 {
+			c.firstEvent = time.Since(m.start)
+		}
+		c.events++
+		c.transcript.apply(msg.Event)
+		m.updateContent()
+
+	case CompareDoneMsg:
+		c := m.columns[msg.Index]
+		c.done, c.err, c.duration = true, msg.Err, time.Since(m.start)
+		for _, e := range c.transcript.entries {
+			e.streaming = false
+		}
+		m.updateContent()
+
+	case tickMsg:
+		m.typingDots++
+		if m.running() {
+			m.updateContent()
+		}
+		return m, tickCmd()
+	}
+	return m, cmd
+}
+
+func (m *CompareModel) columnWidth() int {
+	n := len(m.columns)
+	return max((m.viewport.Width-columnGap*(n-1))/n, 10)
+}
+
+func (m *CompareModel) updateContent() {
+	if !m.ready {
+		return
+	}
+	follow := m.viewport.AtBottom()
+	width := m.columnWidth()
+
+	var views []string
+	for i, c := range m.columns {
+		if i > 0 {
+			views = append(views, strings.Repeat(" ", columnGap))
+		}
+		views = append(views, lipgloss.NewStyle().Width(width).Render(m.renderColumn(i, c, width)))
+	}
+	m.viewport.SetContent(lipgloss.JoinHorizontal(lipgloss.Top, views...))
+	if follow {
+		m.viewport.GotoBottom()
+	}
+}
+
+func (m *CompareModel) renderColumn(i int, c *compareColumn, width int) string {
+	title := ColumnTitleStyle.Render(c.name)
+
+	var status string
+	switch {
+	case c.err != nil:
+		status = ErrorStyle.Render("✗ " + c.err.Error())
+	case c.done:
+		status = SuccessStyle.Render(fmt.Sprintf("✓ %s", c.duration.Round(time.Millisecond)))
+	case c.events == 0:
+		status = getTypingIndicator(m.typingDots)
+	default:
+		status = WarningStyle.Render("● streaming")
+	}
+	if c.events > 0 {
+		status += TimestampStyle.Render(fmt.Sprintf(" first event %s", c.firstEvent.Round(time.Millisecond)))
+	}
+
+	var body string
+	if m.showDiff && i > 0 {
+		ops := diff.Words(m.columns[0].transcript.text(), c.transcript.text())
+		body = MessageContentStyle.Width(width - 2).Render(renderDiff(ops))
+	} else {
+		body = c.transcript.render(width)
+	}
+	return title + "\n" + status + "\n\n" + body
+}
+
+// renderDiff marks text missing from the first response and text only in
+// this one
+func renderDiff(ops []diff.Op) string {
+	var b strings.Builder
+	for _, op := range ops {
+		switch op.Kind {
+		case diff.Insert:
+			b.WriteString(DiffInsertStyle.Render(op.Text))
+		case diff.Delete:
+			b.WriteString(DiffDeleteStyle.Render(op.Text))
+		default:
+			b.WriteString(op.Text)
+		}
+	}
+	return b.String()
+}
+
+func (m *CompareModel) View() string {
+	if !m.ready {
+		return lipgloss.NewStyle().Foreground(primaryColor).Bold(true).Render("\n  ✨ Initializing...")
+	}
+
+	header := HeaderStyle.Render(fmt.Sprintf("⚖ Comparing %d servers", len(m.columns)))
+	if m.running() {
+		header += SuccessStyle.Render(" ● running")
+	}
+	prompt := TimestampStyle.Render("  prompt: " + m.prompt)
+
+	mode := "diff"
+	if m.showDiff {
+		mode = "side by side"
+	}
+	help := HelpStyle.Render(strings.Join([]string{
+		HelpKeyStyle.Render("d") + " " + HelpDescStyle.Render(mode),
+		HelpKeyStyle.Render("j/k g/G") + " " + HelpDescStyle.Render("scroll"),
+		HelpKeyStyle.Render("q") + " " + HelpDescStyle.Render("quit"),
+	}, " • "))
+
+	return fmt.Sprintf("%s\n%s\n\n%s\n%s", header, prompt, m.viewport.View(), help)
+}
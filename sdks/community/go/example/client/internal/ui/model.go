@@ -1,84 +1,114 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
-	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/message"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/agent"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/outbox"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/google/uuid"
 )
 
-type UIMessage struct {
-	Role      string
-	Content   string
-	Timestamp time.Time
+const (
+	sidebarWidth    = 34
+	minSidebarWidth = 90
+
+	// Delivery of queued messages is retried with a doubling delay between
+	// these bounds while the server is unreachable
+	minRetryDelay = 2 * time.Second
+	maxRetryDelay = 30 * time.Second
+)
+
+// EventMsg delivers an event streamed by the active run
+type EventMsg struct {
+	Event events.Event
 }
 
-func NewUIMessage(role, content string) UIMessage {
-	return UIMessage{
-		Role:      role,
-		Content:   content,
-		Timestamp: time.Now(),
-	}
+// RunDoneMsg reports that the active run has ended
+type RunDoneMsg struct {
+	Err error
 }
 
-func (m UIMessage) String() string {
-	var roleStyle lipgloss.Style
-	var rolePrefix string
+// DecideFunc approves or rejects a pending tool call approval
+type DecideFunc func(ctx context.Context, approvalID string, approved bool) error
 
-	if m.Role == "user" {
-		roleStyle = UserLabelStyle
-		rolePrefix = "You"
-	} else {
-		roleStyle = AssistantLabelStyle
-		rolePrefix = "Assistant"
-	}
-
-	timestamp := m.Timestamp.Format("15:04")
-	header := fmt.Sprintf("%s %s", roleStyle.Render(rolePrefix), TimestampStyle.Render(timestamp))
-	content := MessageContentStyle.Render(m.Content)
+type decisionMsg struct {
+	err error
+}
 
-	return fmt.Sprintf("%s\n%s", header, content)
+// retryMsg starts delivering the queued messages. Only the message of the
+// latest scheduled attempt is acted on.
+type retryMsg struct {
+	attempt int
 }
 
 type Model struct {
-	messages       []UIMessage
-	viewport       viewport.Model
-	textarea       textarea.Model
-	userInput      chan string
-	ready          bool
-	waitingForResp bool
-	typingDots     int
+	transcript transcript
+	state      agentState
+	history    []types.Message
+	threadID   string
+
+	// runStart is the index of the first transcript entry of the active run
+	runStart int
+
+	// queue holds the messages the server has not accepted yet, sending is
+	// the one the active run delivers
+	queue      *outbox.Outbox
+	sending    string
+	offline    bool
+	attempt    int
+	retryDelay time.Duration
+	retryAt    time.Time
+
+	viewport    viewport.Model
+	textarea    textarea.Model
+	runs        chan<- *types.RunAgentInput
+	decide      DecideFunc
+	width       int
+	height      int
+	ready       bool
+	running     bool
+	showSidebar bool
+	typingDots  int
+	status      string
 }
 
 func (m *Model) updateViewportContent() {
-	if len(m.messages) == 0 {
+	if len(m.transcript.entries) == 0 && m.queue.Len() == 0 {
 		// Show splash screen when no messages
 		m.viewport.SetContent(getSplashScreen(m.viewport.Width, m.viewport.Height))
 		return
 	}
 
-	var content strings.Builder
-	for i, msg := range m.messages {
-		if i > 0 {
-			content.WriteString("\n\n")
+	// Keep the user's scroll position unless they are following the stream
+	follow := m.viewport.AtBottom()
+
+	content := m.transcript.render(m.viewport.Width)
+
+	// Add typing indicator if waiting for the first response
+	if m.running && len(m.transcript.entries) == m.runStart {
+		content += "\n\n" + getTypingIndicator(m.typingDots)
+	}
+	if queued := m.renderQueue(m.viewport.Width); queued != "" {
+		if content != "" {
+			content += "\n\n"
 		}
-		content.WriteString(msg.String())
+		content += queued
 	}
 
-	// Add typing indicator if waiting for response
-	if m.waitingForResp {
-		content.WriteString("\n\n")
-		content.WriteString(getTypingIndicator(m.typingDots))
+	m.viewport.SetContent(content)
+	if follow {
+		m.viewport.GotoBottom()
 	}
-
-	m.viewport.SetContent(content.String())
-	m.viewport.GotoBottom()
 }
 
 func getTextarea() textarea.Model {
@@ -104,25 +134,182 @@ func getTextarea() textarea.Model {
 	return ta
 }
 
-func InitialModel(userInput chan string) *Model {
-	vp := viewport.New(80, 20)
+func getViewport(width, height int) viewport.Model {
+	vp := viewport.New(width, height)
 	vp.KeyMap = viewport.KeyMap{
 		Up:       key.NewBinding(key.WithKeys("up", "k")),
 		Down:     key.NewBinding(key.WithKeys("down", "j")),
 		PageDown: key.NewBinding(key.WithKeys("pgdown")),
 		PageUp:   key.NewBinding(key.WithKeys("pgup")),
 	}
+	return vp
+}
 
+// InitialModel creates the chat UI. Runs requested by the user are sent on
+// runs; their events must be delivered back as EventMsg followed by a
+// RunDoneMsg. decide resolves tool call approvals and may be nil. Messages
+// wait in queue until the server accepts them, messages left in it by an
+// earlier session are sent first; a nil queue keeps them in memory.
+func InitialModel(runs chan<- *types.RunAgentInput, decide DecideFunc, queue *outbox.Outbox) *Model {
+	if queue == nil {
+		queue, _ = outbox.Open("")
+	}
 	return &Model{
-		viewport:  vp,
-		textarea:  getTextarea(),
-		userInput: userInput,
-		messages:  []UIMessage{},
+		viewport:    getViewport(80, 20),
+		textarea:    getTextarea(),
+		runs:        runs,
+		decide:      decide,
+		queue:       queue,
+		threadID:    "thread-" + uuid.NewString(),
+		showSidebar: true,
 	}
 }
 
 func (m *Model) Init() tea.Cmd {
-	return tea.Batch(textarea.Blink, tea.EnterAltScreen, tickCmd())
+	cmds := []tea.Cmd{textarea.Blink, tea.EnterAltScreen, tickCmd()}
+	if m.queue.Len() > 0 {
+		attempt := m.attempt
+		cmds = append(cmds, func() tea.Msg { return retryMsg{attempt: attempt} })
+	}
+	return tea.Batch(cmds...)
+}
+
+// sidebarVisible reports whether the state sidebar fits on screen
+func (m *Model) sidebarVisible() bool {
+	return m.showSidebar && m.width >= minSidebarWidth
+}
+
+func (m *Model) resize() {
+	headerHeight := 2
+	footHeight := lipgloss.Height(m.textareaView()) + 1
+	verticalMarginHeight := headerHeight + footHeight
+
+	width := m.width - 4
+	if m.sidebarVisible() {
+		width -= sidebarWidth + 4
+	}
+	if !m.ready {
+		// Since this program is using the full size of the viewport we
+		// need to wait until we've received the window dimensions before
+		// we can initialize the viewport. The initial dimensions come in
+		// quickly, though asynchronously, which is why we wait for them
+		// here.
+		m.viewport = getViewport(width, m.height-verticalMarginHeight-2)
+		m.ready = true
+	} else {
+		m.viewport.Width = width
+		m.viewport.Height = m.height - verticalMarginHeight - 2
+	}
+	m.textarea.SetWidth(m.width - 4)
+	m.updateViewportContent()
+}
+
+// send starts a run continuing the conversation
+func (m *Model) send() {
+	m.transcript.snapshot = nil
+	m.runStart = len(m.transcript.entries)
+	m.running = true
+	m.status = ""
+	m.runs <- agent.NewInput(m.threadID, append([]types.Message(nil), m.history...), m.state.value)
+}
+
+// flush sends the oldest queued message, unless a run is active or the
+// server is known to be unreachable
+func (m *Model) flush() {
+	if m.running || m.offline {
+		return
+	}
+	item, ok := m.queue.Peek()
+	if !ok {
+		return
+	}
+	m.history = append(m.history, types.Message{ID: "msg-" + item.ID, Role: types.RoleUser, Content: item.Content})
+	m.transcript.add(&entry{kind: entryUser, content: item.Content, historyLen: len(m.history)})
+	m.sending = item.ID
+	m.send()
+}
+
+// runDone ends the active run. A queued message that never reached the
+// server goes back to the queue and its delivery is retried later.
+func (m *Model) runDone(err error) tea.Cmd {
+	sending := m.sending
+	m.sending = ""
+	if sending != "" && agent.Unreachable(err) && len(m.transcript.entries) == m.runStart {
+		m.running = false
+		m.transcript.entries = m.transcript.entries[:m.runStart-1]
+		m.history = m.history[:len(m.history)-1]
+		m.offline = true
+		m.retryDelay = min(max(2*m.retryDelay, minRetryDelay), maxRetryDelay)
+		m.retryAt = time.Now().Add(m.retryDelay)
+		m.attempt++
+		attempt := m.attempt
+		return tea.Tick(m.retryDelay, func(time.Time) tea.Msg { return retryMsg{attempt: attempt} })
+	}
+
+	m.finishRun(err)
+	if sending != "" {
+		if err := m.queue.Remove(sending); err != nil {
+			m.status = "outbox: " + err.Error()
+		}
+	}
+	m.retryDelay = 0
+	m.flush()
+	return nil
+}
+
+// retry delivers the queued messages now
+func (m *Model) retry() {
+	m.attempt++
+	m.offline = false
+	m.flush()
+	m.updateViewportContent()
+}
+
+// regenerate discards the last response and runs the last user message again
+func (m *Model) regenerate() {
+	last := m.transcript.lastUser()
+	if last < 0 || m.running {
+		return
+	}
+	m.history = m.history[:m.transcript.entries[last].historyLen]
+	m.transcript.entries = m.transcript.entries[:last+1]
+	m.send()
+	m.updateViewportContent()
+}
+
+// finishRun folds the messages of the finished run into the history
+func (m *Model) finishRun(err error) {
+	m.running = false
+	if err != nil {
+		m.transcript.add(&entry{kind: entryError, content: err.Error()})
+	}
+	for _, e := range m.transcript.entries[m.runStart:] {
+		e.streaming = false
+	}
+	if m.transcript.snapshot != nil {
+		m.history = m.transcript.snapshot
+	} else {
+		m.history = append(m.history, m.transcript.history(m.runStart)...)
+	}
+}
+
+// decideApproval resolves the oldest pending approval
+func (m *Model) decideApproval(approved bool) tea.Cmd {
+	pending := m.transcript.pendingApproval()
+	if pending == nil {
+		return nil
+	}
+	if m.decide == nil {
+		m.status = "approvals are not configured, start with --approvals"
+		return nil
+	}
+	id, decide := pending.approvalID, m.decide
+	pending.approvalStatus = "sending"
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return decisionMsg{err: decide(ctx, id, approved)}
+	}
 }
 
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -131,36 +318,19 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		vpCmd tea.Cmd
 	)
 
-	m.textarea, tiCmd = m.textarea.Update(msg)
-	m.viewport, vpCmd = m.viewport.Update(msg)
+	// Keys go to the input in input mode and scroll the transcript otherwise
+	keyMsg, isKey := msg.(tea.KeyMsg)
+	if !isKey || m.textarea.Focused() {
+		m.textarea, tiCmd = m.textarea.Update(msg)
+	}
+	if !isKey || !m.textarea.Focused() || keyMsg.Type == tea.KeyPgUp || keyMsg.Type == tea.KeyPgDown {
+		m.viewport, vpCmd = m.viewport.Update(msg)
+	}
 
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		headerHeight := 2
-		footHeight := lipgloss.Height(m.textareaView()) + 1
-		verticalMarginHeight := headerHeight + footHeight
-
-		if !m.ready {
-			// Since this program is using the full size of the viewport we
-			// need to wait until we've received the window dimensions before
-			// we can initialize the viewport. The initial dimensions come in
-			// quickly, though asynchronously, which is why we wait for them
-			// here.
-			m.viewport = viewport.New(msg.Width-4, msg.Height-verticalMarginHeight-2)
-			m.viewport.KeyMap = viewport.KeyMap{
-				Up:       key.NewBinding(key.WithKeys("up", "k")),
-				Down:     key.NewBinding(key.WithKeys("down", "j")),
-				PageDown: key.NewBinding(key.WithKeys("pgdown")),
-				PageUp:   key.NewBinding(key.WithKeys("pgup")),
-			}
-			m.updateViewportContent()
-			m.textarea.SetWidth(msg.Width - 4)
-			m.ready = true
-		} else {
-			m.viewport.Width = msg.Width - 4
-			m.viewport.Height = msg.Height - verticalMarginHeight - 2
-			m.textarea.SetWidth(msg.Width - 4)
-		}
+		m.width, m.height = msg.Width, msg.Height
+		m.resize()
 
 	case tea.KeyMsg:
 		switch msg.Type {
@@ -169,19 +339,19 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case tea.KeyEsc:
 			if m.textarea.Focused() {
 				m.textarea.Blur()
-				m.viewport.YOffset = 0
 			}
 		case tea.KeyEnter:
-			if m.textarea.Focused() && m.textarea.Value() != "" && !m.waitingForResp {
-				// Send the message
-				m.userInput <- m.textarea.Value()
-
-				// Add to messages
-				uiMsg := NewUIMessage("user", m.textarea.Value())
-				m.messages = append(m.messages, uiMsg)
-				m.updateViewportContent()
+			if m.textarea.Focused() && strings.TrimSpace(m.textarea.Value()) != "" {
+				// Messages always pass through the queue so that they are
+				// delivered in order, even when typed during a run or while
+				// the server is unreachable
+				if _, err := m.queue.Push(m.textarea.Value()); err != nil {
+					m.status = "outbox: " + err.Error()
+				}
 				m.textarea.Reset()
-				m.waitingForResp = true
+				m.viewport.GotoBottom()
+				m.flush()
+				m.updateViewportContent()
 			}
 		default:
 			if !m.textarea.Focused() {
@@ -190,21 +360,60 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					// Enter input mode
 					m.textarea.Focus()
 					return m, textarea.Blink
+				case "y":
+					return m, m.decideApproval(true)
+				case "n":
+					return m, m.decideApproval(false)
+				case "r":
+					m.regenerate()
+				case "R":
+					if m.offline {
+						m.retry()
+					}
+				case "s":
+					m.showSidebar = !m.showSidebar
+					m.resize()
+				case "g":
+					m.viewport.GotoTop()
+				case "G":
+					m.viewport.GotoBottom()
 				}
 			}
 		}
 
-	case *message.Message:
-		m.waitingForResp = false
-		for _, currMsg := range msg.Strings() {
-			uiMsg := NewUIMessage("assistant", currMsg)
-			m.messages = append(m.messages, uiMsg)
+	case EventMsg:
+		m.transcript.apply(msg.Event)
+		m.state.apply(msg.Event)
+		if m.transcript.pendingApproval() != nil && m.textarea.Focused() {
+			// Leave input mode so that y/n decide the approval
+			m.textarea.Blur()
+		}
+		m.updateViewportContent()
+
+	case RunDoneMsg:
+		cmd := m.runDone(msg.Err)
+		m.updateViewportContent()
+		return m, tea.Batch(tiCmd, vpCmd, cmd)
+
+	case retryMsg:
+		if msg.attempt == m.attempt && !m.running {
+			m.retry()
+		}
+
+	case decisionMsg:
+		if msg.err != nil {
+			m.status = "approval failed: " + msg.err.Error()
+			for _, e := range m.transcript.entries {
+				if e.approvalStatus == "sending" {
+					e.approvalStatus = "pending"
+				}
+			}
 		}
 		m.updateViewportContent()
 
 	case tickMsg:
 		m.typingDots++
-		if m.waitingForResp {
+		if m.running {
 			m.updateViewportContent()
 		}
 		return m, tickCmd()
@@ -227,12 +436,22 @@ func (m *Model) View() string {
 		return initMsg
 	}
 
-	// Header with message count
+	// Header with message count and run status
 	msgCount := ""
-	if len(m.messages) > 0 {
-		msgCount = TimestampStyle.Render(fmt.Sprintf(" (%d messages)", len(m.messages)))
+	if len(m.history) > 0 {
+		msgCount = TimestampStyle.Render(fmt.Sprintf(" (%d messages)", len(m.history)))
 	}
 	header := HeaderStyle.Render("💬 Chat" + msgCount)
+	if m.running {
+		header += SuccessStyle.Render(" ● running")
+	}
+	if m.offline {
+		wait := max(time.Until(m.retryAt).Round(time.Second), 0)
+		header += WarningStyle.Render(fmt.Sprintf(" ⚠ server unreachable, retrying in %s", wait))
+	}
+	if n := m.queue.Len(); n > 0 {
+		header += TimestampStyle.Render(fmt.Sprintf(" (%d queued)", n))
+	}
 
 	// Viewport with scroll indicator
 	viewportView := ViewportStyle.
@@ -240,18 +459,15 @@ func (m *Model) View() string {
 		Height(m.viewport.Height + 2).
 		Render(m.viewport.View())
 
-	// Add scroll percentage if there are messages
-	if len(m.messages) > 0 && m.viewport.TotalLineCount() > m.viewport.Height {
-		percent := float64(m.viewport.YOffset) / float64(m.viewport.TotalLineCount()-m.viewport.Height) * 100
-		if percent < 0 {
-			percent = 0
-		}
-		if percent > 100 {
-			percent = 100
-		}
-		scrollInfo := TimestampStyle.Render(fmt.Sprintf(" %.0f%% ", percent))
+	// Add scroll percentage if there is more than a screen of content
+	if m.viewport.TotalLineCount() > m.viewport.Height {
+		scrollInfo := TimestampStyle.Render(fmt.Sprintf(" %.0f%% ", m.viewport.ScrollPercent()*100))
 		viewportView = lipgloss.JoinHorizontal(lipgloss.Top, viewportView, scrollInfo)
 	}
+	if m.sidebarVisible() {
+		sidebar := m.state.render(sidebarWidth, m.viewport.Height+2)
+		viewportView = lipgloss.JoinHorizontal(lipgloss.Top, viewportView, " ", sidebar)
+	}
 
 	inputView := m.textareaView()
 
@@ -260,9 +476,25 @@ func (m *Model) View() string {
 		HelpKeyStyle.Render("i/a") + " " + HelpDescStyle.Render("input mode"),
 		HelpKeyStyle.Render("Esc") + " " + HelpDescStyle.Render("normal mode"),
 		HelpKeyStyle.Render("Enter") + " " + HelpDescStyle.Render("send"),
-		HelpKeyStyle.Render("Ctrl+C") + " " + HelpDescStyle.Render("quit"),
 	}
+	if !m.textarea.Focused() {
+		helpItems = append(helpItems,
+			HelpKeyStyle.Render("y/n")+" "+HelpDescStyle.Render("approve/reject"),
+			HelpKeyStyle.Render("r")+" "+HelpDescStyle.Render("regenerate"),
+		)
+		if m.offline {
+			helpItems = append(helpItems, HelpKeyStyle.Render("R")+" "+HelpDescStyle.Render("retry now"))
+		}
+		helpItems = append(helpItems,
+			HelpKeyStyle.Render("s")+" "+HelpDescStyle.Render("state"),
+			HelpKeyStyle.Render("j/k g/G")+" "+HelpDescStyle.Render("scroll"),
+		)
+	}
+	helpItems = append(helpItems, HelpKeyStyle.Render("Ctrl+C")+" "+HelpDescStyle.Render("quit"))
 	help := HelpStyle.Render(strings.Join(helpItems, " • "))
+	if m.status != "" {
+		help = WarningStyle.Render("  "+m.status) + "\n" + help
+	}
 
 	// Add input mode indicator
 	if m.textarea.Focused() {
@@ -275,3 +507,16 @@ func (m *Model) View() string {
 
 	return fmt.Sprintf("%s\n\n%s\n\n%s\n%s", header, viewportView, inputView, help)
 }
+
+// renderQueue shows the messages waiting for delivery below the transcript
+func (m *Model) renderQueue(width int) string {
+	var blocks []string
+	for _, item := range m.queue.Items() {
+		if item.ID == m.sending {
+			continue
+		}
+		label := fmt.Sprintf("%s %s %s", UserLabelStyle.Render("You"), TimestampStyle.Render(item.QueuedAt.Format("15:04")), WarningStyle.Render("⏳ queued"))
+		blocks = append(blocks, label+"\n"+MessageContentStyle.Faint(true).Width(width-2).Render(item.Content))
+	}
+	return strings.Join(blocks, "\n\n")
+}
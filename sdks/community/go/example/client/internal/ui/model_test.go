@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/example/client/internal/outbox"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+// unreachable is the error of a run whose server cannot be reached
+var unreachable = &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+// lastMessage returns the content of the last message of a run input,
+// without the newline the textarea adds on enter
+func lastMessage(t *testing.T, runs <-chan *types.RunAgentInput) string {
+	t.Helper()
+	select {
+	case input := <-runs:
+		require.NotEmpty(t, input.Messages)
+		content, _ := input.Messages[len(input.Messages)-1].ContentString()
+		return strings.TrimSpace(content)
+	default:
+		require.Fail(t, "no run started")
+		return ""
+	}
+}
+
+func TestDrainOutbox(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	queue, err := outbox.Open(path)
+	require.NoError(t, err)
+	_, err = queue.Push("left by an earlier session")
+	require.NoError(t, err)
+	_, err = queue.Push("second")
+	require.NoError(t, err)
+
+	runs := make(chan *types.RunAgentInput, 1)
+	m := InitialModel(runs, nil, queue)
+	m.Update(retryMsg{attempt: m.attempt})
+	assert.Equal(t, "left by an earlier session", lastMessage(t, runs), "queued messages are sent first")
+	assert.True(t, m.running)
+
+	// The server cannot be reached: the message stays queued
+	_, cmd := m.Update(RunDoneMsg{Err: unreachable})
+	assert.NotNil(t, cmd, "delivery is retried later")
+	assert.True(t, m.offline)
+	assert.False(t, m.running)
+	assert.Empty(t, m.history)
+	assert.Equal(t, 2, queue.Len())
+
+	// A stale retry is ignored
+	m.Update(retryMsg{attempt: m.attempt - 1})
+	assert.Empty(t, runs)
+
+	m.Update(retryMsg{attempt: m.attempt})
+	assert.Equal(t, "left by an earlier session", lastMessage(t, runs))
+	assert.False(t, m.offline)
+
+	// Once accepted, the message leaves the queue and the next one is sent
+	m.Update(EventMsg{Event: events.NewRunStartedEvent(m.threadID, "run-1")})
+	m.Update(RunDoneMsg{})
+	assert.Equal(t, "second", lastMessage(t, runs))
+	assert.Equal(t, 1, queue.Len())
+
+	m.Update(EventMsg{Event: events.NewRunStartedEvent(m.threadID, "run-2")})
+	m.Update(RunDoneMsg{})
+	assert.Empty(t, runs)
+	assert.False(t, m.running)
+	assert.Zero(t, queue.Len())
+
+	reopened, err := outbox.Open(path)
+	require.NoError(t, err)
+	assert.Zero(t, reopened.Len(), "delivered messages are removed from the file")
+}
+
+func TestEnterQueuesDuringRun(t *testing.T) {
+	runs := make(chan *types.RunAgentInput, 1)
+	m := InitialModel(runs, nil, nil)
+	m.textarea.Focus()
+
+	m.textarea.SetValue("first")
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, "first", lastMessage(t, runs))
+
+	// Messages typed during a run wait for it to end
+	m.textarea.SetValue("second")
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Empty(t, runs)
+	assert.Equal(t, 2, m.queue.Len())
+
+	m.Update(EventMsg{Event: events.NewRunStartedEvent(m.threadID, "run-1")})
+	m.Update(RunDoneMsg{})
+	assert.Equal(t, "second", lastMessage(t, runs))
+	assert.Equal(t, 1, m.queue.Len())
+}
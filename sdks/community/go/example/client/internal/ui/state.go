@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/jsonpatch"
+)
+
+// agentState tracks the shared state of the conversation shown in the sidebar
+type agentState struct {
+	value any
+	steps []string
+	err   error
+
+	// dispatcher applies run events, created on first use
+	dispatcher *events.Dispatcher
+}
+
+// apply updates the state with a run event
+func (s *agentState) apply(event events.Event) {
+	if s.dispatcher == nil {
+		s.dispatcher = s.newDispatcher()
+	}
+	if err := s.dispatcher.Dispatch(event); err != nil {
+		s.err = err
+	}
+}
+
+// newDispatcher registers the handlers updating the state
+func (s *agentState) newDispatcher() *events.Dispatcher {
+	d := events.NewDispatcher()
+	d.OnStateSnapshot(func(e *events.StateSnapshotEvent) {
+		s.value = e.Snapshot
+		s.err = nil
+	})
+	d.OnStateDelta(func(e *events.StateDeltaEvent) {
+		if s.err != nil {
+			return
+		}
+		s.value, s.err = jsonpatch.Apply(s.value, e.Delta)
+	})
+	d.OnStepStarted(func(e *events.StepStartedEvent) {
+		s.steps = append(s.steps, e.StepName)
+	})
+	d.OnStepFinished(func(e *events.StepFinishedEvent) {
+		for i, step := range s.steps {
+			if step == e.StepName {
+				s.steps = append(s.steps[:i], s.steps[i+1:]...)
+				break
+			}
+		}
+	})
+	return d
+}
+
+func (s *agentState) render(width, height int) string {
+	var b strings.Builder
+	b.WriteString(SidebarTitleStyle.Render("State"))
+	b.WriteString("\n")
+	switch {
+	case s.err != nil:
+		b.WriteString(ErrorStyle.Render("out of sync: " + s.err.Error()))
+	case s.value == nil:
+		b.WriteString(TimestampStyle.Render("no state"))
+	default:
+		data, err := json.MarshalIndent(s.value, "", "  ")
+		if err != nil {
+			data = []byte(err.Error())
+		}
+		b.WriteString(string(data))
+	}
+	if len(s.steps) > 0 {
+		b.WriteString("\n\n")
+		b.WriteString(SidebarTitleStyle.Render("Steps"))
+		for _, step := range s.steps {
+			b.WriteString("\n• " + step)
+		}
+	}
+	return SidebarStyle.Width(width).Height(height).MaxHeight(height + 2).Render(b.String())
+}
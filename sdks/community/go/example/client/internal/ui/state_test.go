@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+func TestAgentState(t *testing.T) {
+	var s agentState
+	assert.Contains(t, s.render(40, 20), "no state")
+
+	s.apply(events.NewStateSnapshotEvent(map[string]any{"city": "Paris", "count": 1}))
+	s.apply(events.NewStateDeltaEvent([]events.JSONPatchOperation{{Op: "replace", Path: "/count", Value: 2}}))
+	require.NoError(t, s.err)
+	assert.Equal(t, map[string]any{"city": "Paris", "count": 2}, s.value)
+	assert.Contains(t, s.render(40, 20), `"count": 2`)
+
+	// A delta that does not apply puts the state out of sync, and later
+	// deltas are ignored until the next snapshot
+	s.apply(events.NewStateDeltaEvent([]events.JSONPatchOperation{{Op: "add", Path: "/city/name", Value: "Rome"}}))
+	require.Error(t, s.err)
+	assert.Contains(t, s.render(40, 20), "out of sync")
+	s.apply(events.NewStateDeltaEvent([]events.JSONPatchOperation{{Op: "replace", Path: "/count", Value: 3}}))
+	assert.Equal(t, map[string]any{"city": "Paris", "count": 2}, s.value)
+
+	s.apply(events.NewStateSnapshotEvent(map[string]any{"count": 4}))
+	assert.NoError(t, s.err)
+	assert.Equal(t, map[string]any{"count": 4}, s.value)
+}
+
+func TestAgentStateSteps(t *testing.T) {
+	var s agentState
+	s.apply(events.NewStepStartedEvent("search"))
+	s.apply(events.NewStepStartedEvent("summarize"))
+	assert.Equal(t, []string{"search", "summarize"}, s.steps)
+	out := s.render(40, 20)
+	assert.Contains(t, out, "Steps")
+	assert.Contains(t, out, "• search")
+
+	s.apply(events.NewStepFinishedEvent("search"))
+	s.apply(events.NewStepFinishedEvent("unknown"))
+	assert.Equal(t, []string{"summarize"}, s.steps)
+
+	s.apply(events.NewStepFinishedEvent("summarize"))
+	assert.NotContains(t, s.render(40, 20), "Steps")
+}
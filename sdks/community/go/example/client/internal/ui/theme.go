@@ -86,4 +86,56 @@ var (
 
 	HelpDescStyle = lipgloss.NewStyle().
 		Foreground(mutedTextColor)
-)
\ No newline at end of file
+
+	// Transcript styles
+	StreamingCursorStyle = lipgloss.NewStyle().
+		Foreground(accentColor)
+
+	ThinkingStyle = lipgloss.NewStyle().
+		Foreground(mutedTextColor).
+		Italic(true).
+		PaddingLeft(2)
+
+	ToolTitleStyle = lipgloss.NewStyle().
+		Foreground(warningColor).
+		Bold(true)
+
+	ToolPanelStyle = lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(bgLightColor).
+		Padding(0, 1).
+		MarginLeft(2)
+
+	ErrorStyle = lipgloss.NewStyle().
+		Foreground(errorColor).
+		Bold(true)
+
+	WarningStyle = lipgloss.NewStyle().
+		Foreground(warningColor)
+
+	SuccessStyle = lipgloss.NewStyle().
+		Foreground(successColor)
+
+	// Sidebar styles
+	SidebarStyle = lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(bgLightColor).
+		Padding(0, 1)
+
+	SidebarTitleStyle = lipgloss.NewStyle().
+		Foreground(infoColor).
+		Bold(true)
+
+	// Comparison styles
+	ColumnTitleStyle = lipgloss.NewStyle().
+		Foreground(secondaryColor).
+		Bold(true)
+
+	DiffInsertStyle = lipgloss.NewStyle().
+		Foreground(successColor).
+		Underline(true)
+
+	DiffDeleteStyle = lipgloss.NewStyle().
+		Foreground(errorColor).
+		Strikethrough(true)
+)
@@ -0,0 +1,315 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+type entryKind int
+
+const (
+	entryUser entryKind = iota
+	entryAssistant
+	entryThinking
+	entryTool
+	entryNotice
+	entryError
+)
+
+// entry is one block of the transcript: a message, a tool call or a notice
+type entry struct {
+	kind      entryKind
+	id        string
+	content   string
+	timestamp time.Time
+	streaming bool
+
+	// Tool calls
+	toolName        string
+	parentMessageID string
+	result          string
+	approvalID      string
+	approvalStatus  string
+
+	// historyLen is the length of the history once a user entry was sent
+	historyLen int
+}
+
+// transcript is the conversation shown in the chat view, built from run events
+type transcript struct {
+	entries []*entry
+
+	// snapshot holds the history from a MESSAGES_SNAPSHOT of the active run
+	snapshot []types.Message
+
+	// dispatcher applies run events, created on first use
+	dispatcher *events.Dispatcher
+}
+
+func (t *transcript) add(e *entry) *entry {
+	if e.timestamp.IsZero() {
+		e.timestamp = time.Now()
+	}
+	t.entries = append(t.entries, e)
+	return e
+}
+
+func (t *transcript) find(kind entryKind, id string) *entry {
+	for i := len(t.entries) - 1; i >= 0; i-- {
+		if t.entries[i].kind == kind && t.entries[i].id == id {
+			return t.entries[i]
+		}
+	}
+	return nil
+}
+
+// pendingApproval returns the oldest tool call awaiting approval
+func (t *transcript) pendingApproval() *entry {
+	for _, e := range t.entries {
+		if e.kind == entryTool && e.approvalStatus == "pending" {
+			return e
+		}
+	}
+	return nil
+}
+
+// lastUser returns the index of the last user entry, or -1
+func (t *transcript) lastUser() int {
+	for i := len(t.entries) - 1; i >= 0; i-- {
+		if t.entries[i].kind == entryUser {
+			return i
+		}
+	}
+	return -1
+}
+
+// apply updates the transcript with a run event. Handler panics are shown
+// as errors rather than crashing the chat.
+func (t *transcript) apply(event events.Event) {
+	if t.dispatcher == nil {
+		t.dispatcher = t.newDispatcher()
+	}
+	if err := t.dispatcher.Dispatch(event); err != nil {
+		t.add(&entry{kind: entryError, content: err.Error()})
+	}
+}
+
+// newDispatcher registers the handlers updating the transcript
+func (t *transcript) newDispatcher() *events.Dispatcher {
+	d := events.NewDispatcher()
+	d.OnTextMessageStart(func(e *events.TextMessageStartEvent) {
+		t.add(&entry{kind: entryAssistant, id: e.MessageID, streaming: true})
+	})
+	d.OnTextMessageContent(func(e *events.TextMessageContentEvent) {
+		t.appendText(entryAssistant, e.MessageID, e.Delta)
+	})
+	d.OnTextMessageChunk(func(e *events.TextMessageChunkEvent) {
+		if e.MessageID != nil && e.Delta != nil {
+			t.appendText(entryAssistant, *e.MessageID, *e.Delta)
+		}
+	})
+	d.OnTextMessageEnd(func(e *events.TextMessageEndEvent) {
+		t.finish(entryAssistant, e.MessageID)
+	})
+
+	events.On(d, func(*events.ThinkingTextMessageStartEvent) {
+		t.add(&entry{kind: entryThinking, id: "thinking", streaming: true})
+	})
+	events.On(d, func(e *events.ThinkingTextMessageContentEvent) {
+		t.appendText(entryThinking, "thinking", e.Delta)
+	})
+	events.On(d, func(*events.ThinkingTextMessageEndEvent) {
+		t.finish(entryThinking, "thinking")
+	})
+	d.OnReasoningMessageStart(func(e *events.ReasoningMessageStartEvent) {
+		t.add(&entry{kind: entryThinking, id: e.MessageID, streaming: true})
+	})
+	d.OnReasoningMessageContent(func(e *events.ReasoningMessageContentEvent) {
+		t.appendText(entryThinking, e.MessageID, e.Delta)
+	})
+	d.OnReasoningMessageEnd(func(e *events.ReasoningMessageEndEvent) {
+		t.finish(entryThinking, e.MessageID)
+	})
+
+	d.OnToolCallStart(func(e *events.ToolCallStartEvent) {
+		parent := ""
+		if e.ParentMessageID != nil {
+			parent = *e.ParentMessageID
+		}
+		t.add(&entry{kind: entryTool, id: e.ToolCallID, toolName: e.ToolCallName, parentMessageID: parent, streaming: true})
+	})
+	d.OnToolCallArgs(func(e *events.ToolCallArgsEvent) {
+		t.appendText(entryTool, e.ToolCallID, e.Delta)
+	})
+	d.OnToolCallEnd(func(e *events.ToolCallEndEvent) {
+		t.finish(entryTool, e.ToolCallID)
+	})
+	d.OnToolCallResult(func(e *events.ToolCallResultEvent) {
+		if tool := t.find(entryTool, e.ToolCallID); tool != nil {
+			tool.result = e.Content
+		}
+	})
+
+	// Approvals, see server.ApprovalManager
+	d.OnApprovalRequested(func(e *events.ApprovalRequestedEvent) {
+		if e.ToolCallID == "" {
+			return
+		}
+		tool := t.find(entryTool, e.ToolCallID)
+		if tool == nil {
+			tool = t.add(&entry{kind: entryTool, id: e.ToolCallID, toolName: e.ToolName, content: e.Arguments})
+		}
+		tool.approvalID, tool.approvalStatus = e.ApprovalID, "pending"
+	})
+	d.OnApprovalGranted(func(e *events.ApprovalGrantedEvent) {
+		if tool := t.find(entryTool, e.ToolCallID); tool != nil {
+			tool.approvalID, tool.approvalStatus = e.ApprovalID, "approved"
+			if e.Arguments != nil {
+				tool.content = *e.Arguments
+			}
+		}
+	})
+	d.OnApprovalDenied(func(e *events.ApprovalDeniedEvent) {
+		if tool := t.find(entryTool, e.ToolCallID); tool != nil {
+			tool.approvalID, tool.approvalStatus = e.ApprovalID, "rejected"
+		}
+	})
+	d.OnMessagesSnapshot(func(e *events.MessagesSnapshotEvent) {
+		t.snapshot = e.Messages
+	})
+	d.OnMessageUpdated(func(e *events.MessageUpdatedEvent) {
+		if message := t.find(entryAssistant, e.MessageID); message != nil {
+			message.content = e.Content
+		}
+	})
+	d.OnMessageDeleted(func(e *events.MessageDeletedEvent) {
+		if message := t.find(entryAssistant, e.MessageID); message != nil {
+			message.kind = entryNotice
+			message.content = "message retracted"
+			if e.Reason != nil {
+				message.content += ": " + *e.Reason
+			}
+		}
+	})
+	d.OnStepStarted(func(e *events.StepStartedEvent) {
+		t.add(&entry{kind: entryNotice, content: "step started: " + e.StepName})
+	})
+	d.OnRunError(func(e *events.RunErrorEvent) {
+		content := e.Message
+		if e.Code != nil {
+			content = fmt.Sprintf("[%s] %s", *e.Code, e.Message)
+		}
+		t.add(&entry{kind: entryError, content: content})
+	})
+	return d
+}
+
+func (t *transcript) appendText(kind entryKind, id, delta string) {
+	e := t.find(kind, id)
+	if e == nil {
+		e = t.add(&entry{kind: kind, id: id, streaming: true})
+	}
+	e.content += delta
+}
+
+func (t *transcript) finish(kind entryKind, id string) {
+	if e := t.find(kind, id); e != nil {
+		e.streaming = false
+	}
+}
+
+// history converts the entries of a run, starting at index from, into the
+// messages to send with the next run
+func (t *transcript) history(from int) []types.Message {
+	var messages []types.Message
+	var results []types.Message
+	assistant := map[string]int{}
+	for _, e := range t.entries[from:] {
+		switch e.kind {
+		case entryAssistant:
+			assistant[e.id] = len(messages)
+			messages = append(messages, types.Message{ID: e.id, Role: types.RoleAssistant, Content: e.content})
+		case entryTool:
+			call := types.ToolCall{ID: e.id, Type: "function", Function: types.FunctionCall{Name: e.toolName, Arguments: e.content}}
+			i, ok := assistant[e.parentMessageID]
+			if !ok {
+				i = len(messages)
+				messages = append(messages, types.Message{ID: "msg-" + e.id, Role: types.RoleAssistant})
+				assistant[e.parentMessageID] = i
+			}
+			messages[i].ToolCalls = append(messages[i].ToolCalls, call)
+			if e.result != "" {
+				results = append(results, types.Message{ID: "result-" + e.id, Role: types.RoleTool, ToolCallID: e.id, Content: e.result})
+			}
+		}
+	}
+	return append(messages, results...)
+}
+
+// text returns the assistant text of the transcript
+func (t *transcript) text() string {
+	var parts []string
+	for _, e := range t.entries {
+		if e.kind == entryAssistant && e.content != "" {
+			parts = append(parts, e.content)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// render draws the transcript for a view of the given width
+func (t *transcript) render(width int) string {
+	var blocks []string
+	for _, e := range t.entries {
+		blocks = append(blocks, e.render(width))
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+func (e *entry) render(width int) string {
+	timestamp := TimestampStyle.Render(e.timestamp.Format("15:04"))
+	cursor := ""
+	if e.streaming {
+		cursor = StreamingCursorStyle.Render("▌")
+	}
+
+	switch e.kind {
+	case entryUser:
+		return fmt.Sprintf("%s %s\n%s", UserLabelStyle.Render("You"), timestamp, MessageContentStyle.Width(width-2).Render(e.content))
+	case entryAssistant:
+		return fmt.Sprintf("%s %s\n%s", AssistantLabelStyle.Render("Assistant"), timestamp, MessageContentStyle.Width(width-2).Render(e.content+cursor))
+	case entryThinking:
+		return ThinkingStyle.Width(width - 2).Render("thinking: " + e.content + cursor)
+	case entryTool:
+		return e.renderTool(width)
+	case entryError:
+		return ErrorStyle.Render("✗ " + e.content)
+	default:
+		return TimestampStyle.Render("· " + e.content)
+	}
+}
+
+func (e *entry) renderTool(width int) string {
+	title := ToolTitleStyle.Render("⚙ " + e.toolName)
+	switch e.approvalStatus {
+	case "pending":
+		title += " " + WarningStyle.Render("awaiting approval (y/n)")
+	case "approved":
+		title += " " + SuccessStyle.Render("approved")
+	case "rejected":
+		title += " " + ErrorStyle.Render("rejected")
+	}
+
+	lines := []string{title, TimestampStyle.Render("args: ") + e.content}
+	if e.streaming {
+		lines[1] += StreamingCursorStyle.Render("▌")
+	}
+	if e.result != "" {
+		lines = append(lines, TimestampStyle.Render("result: ")+e.result)
+	}
+	return ToolPanelStyle.Width(width - 4).Render(strings.Join(lines, "\n"))
+}
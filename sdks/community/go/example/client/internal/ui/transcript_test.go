@@ -0,0 +1,223 @@
+package ui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+func ptr(s string) *string { return &s }
+
+// applied returns the entries of a transcript the events were applied to,
+// without their timestamps
+func applied(evts ...events.Event) []*entry {
+	var tr transcript
+	for _, e := range evts {
+		tr.apply(e)
+	}
+	for _, e := range tr.entries {
+		e.timestamp = time.Time{}
+	}
+	return tr.entries
+}
+
+func TestTranscriptApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []events.Event
+		want   []*entry
+	}{
+		{
+			name: "streaming text",
+			events: []events.Event{
+				events.NewTextMessageStartEvent("msg-1"),
+				events.NewTextMessageContentEvent("msg-1", "Hel"),
+				events.NewTextMessageContentEvent("msg-1", "lo"),
+			},
+			want: []*entry{{kind: entryAssistant, id: "msg-1", content: "Hello", streaming: true}},
+		},
+		{
+			name: "ended text",
+			events: []events.Event{
+				events.NewTextMessageStartEvent("msg-1"),
+				events.NewTextMessageContentEvent("msg-1", "Hello"),
+				events.NewTextMessageEndEvent("msg-1"),
+			},
+			want: []*entry{{kind: entryAssistant, id: "msg-1", content: "Hello"}},
+		},
+		{
+			name: "chunks",
+			events: []events.Event{
+				events.NewTextMessageChunkEvent(ptr("msg-1"), ptr("assistant"), ptr("Hi")),
+				events.NewTextMessageChunkEvent(ptr("msg-1"), nil, nil),
+				events.NewTextMessageChunkEvent(ptr("msg-1"), nil, ptr(" there")),
+			},
+			want: []*entry{{kind: entryAssistant, id: "msg-1", content: "Hi there", streaming: true}},
+		},
+		{
+			name: "thinking and reasoning",
+			events: []events.Event{
+				events.NewThinkingTextMessageStartEvent(),
+				events.NewThinkingTextMessageContentEvent("hmm"),
+				events.NewThinkingTextMessageEndEvent(),
+				events.NewReasoningMessageStartEvent("r-1", "assistant"),
+				events.NewReasoningMessageContentEvent("r-1", "because"),
+			},
+			want: []*entry{
+				{kind: entryThinking, id: "thinking", content: "hmm"},
+				{kind: entryThinking, id: "r-1", content: "because", streaming: true},
+			},
+		},
+		{
+			name: "tool call",
+			events: []events.Event{
+				events.NewToolCallStartEvent("call-1", "get_weather", events.WithParentMessageID("msg-1")),
+				events.NewToolCallArgsEvent("call-1", `{"city":`),
+				events.NewToolCallArgsEvent("call-1", `"Paris"}`),
+				events.NewToolCallEndEvent("call-1"),
+				events.NewToolCallResultEvent("result-1", "call-1", "sunny"),
+				events.NewToolCallResultEvent("result-2", "call-9", "unknown call"),
+			},
+			want: []*entry{{kind: entryTool, id: "call-1", toolName: "get_weather", parentMessageID: "msg-1", content: `{"city":"Paris"}`, result: "sunny"}},
+		},
+		{
+			name: "approvals",
+			events: []events.Event{
+				events.NewToolCallStartEvent("call-1", "delete_file"),
+				events.NewToolCallEndEvent("call-1"),
+				events.NewApprovalRequestedEvent("approval-1").WithToolCall("call-1", "delete_file", "{}"),
+				events.NewApprovalRequestedEvent("approval-2").WithToolCall("call-2", "send_mail", `{"to":"bob"}`),
+				events.NewApprovalRequestedEvent("approval-3"),
+				events.NewApprovalGrantedEvent("approval-2").WithToolCallID("call-2").WithArguments(`{"to":"alice"}`),
+				events.NewApprovalDeniedEvent("approval-1").WithToolCallID("call-1"),
+			},
+			want: []*entry{
+				{kind: entryTool, id: "call-1", toolName: "delete_file", approvalID: "approval-1", approvalStatus: "rejected"},
+				{kind: entryTool, id: "call-2", toolName: "send_mail", content: `{"to":"alice"}`, approvalID: "approval-2", approvalStatus: "approved"},
+			},
+		},
+		{
+			name: "edited and retracted messages",
+			events: []events.Event{
+				events.NewTextMessageStartEvent("msg-1"),
+				events.NewTextMessageEndEvent("msg-1"),
+				events.NewTextMessageStartEvent("msg-2"),
+				events.NewTextMessageEndEvent("msg-2"),
+				events.NewTextMessageStartEvent("msg-3"),
+				events.NewTextMessageEndEvent("msg-3"),
+				events.NewMessageUpdatedEvent("msg-1", "edited"),
+				events.NewMessageDeletedEvent("msg-2"),
+				&events.MessageDeletedEvent{BaseEvent: events.NewBaseEvent(events.EventTypeMessageDeleted), MessageID: "msg-3", Reason: ptr("policy")},
+				events.NewMessageUpdatedEvent("msg-9", "unknown message"),
+			},
+			want: []*entry{
+				{kind: entryAssistant, id: "msg-1", content: "edited"},
+				{kind: entryNotice, id: "msg-2", content: "message retracted"},
+				{kind: entryNotice, id: "msg-3", content: "message retracted: policy"},
+			},
+		},
+		{
+			name: "notices and errors",
+			events: []events.Event{
+				events.NewStepStartedEvent("search"),
+				events.NewRunErrorEvent("model unavailable"),
+				events.NewRunErrorEvent("too many requests", events.WithErrorCode("rate_limited")),
+			},
+			want: []*entry{
+				{kind: entryNotice, content: "step started: search"},
+				{kind: entryError, content: "model unavailable"},
+				{kind: entryError, content: "[rate_limited] too many requests"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, applied(tt.events...))
+		})
+	}
+}
+
+func TestTranscriptSnapshot(t *testing.T) {
+	var tr transcript
+	messages := []types.Message{{ID: "1", Role: types.RoleUser, Content: "Hi"}}
+	tr.apply(events.NewMessagesSnapshotEvent(messages))
+	assert.Equal(t, messages, tr.snapshot)
+	assert.Empty(t, tr.entries)
+}
+
+func TestPendingApproval(t *testing.T) {
+	var tr transcript
+	assert.Nil(t, tr.pendingApproval())
+	tr.apply(events.NewApprovalRequestedEvent("approval-1").WithToolCall("call-1", "delete_file", "{}"))
+	tr.apply(events.NewApprovalRequestedEvent("approval-2").WithToolCall("call-2", "send_mail", "{}"))
+	assert.Equal(t, "approval-1", tr.pendingApproval().approvalID, "the oldest first")
+
+	tr.apply(events.NewApprovalGrantedEvent("approval-1").WithToolCallID("call-1"))
+	assert.Equal(t, "approval-2", tr.pendingApproval().approvalID)
+}
+
+func TestHistory(t *testing.T) {
+	var tr transcript
+	assert.Equal(t, -1, tr.lastUser())
+	tr.add(&entry{kind: entryUser, content: "earlier"})
+	tr.add(&entry{kind: entryAssistant, id: "msg-0", content: "earlier answer"})
+	tr.add(&entry{kind: entryUser, content: "What is the weather?"})
+	from := tr.lastUser() + 1
+	assert.Equal(t, 3, from)
+	for _, e := range []events.Event{
+		events.NewTextMessageStartEvent("msg-1"),
+		events.NewTextMessageContentEvent("msg-1", "Let me check."),
+		events.NewToolCallStartEvent("call-1", "get_weather", events.WithParentMessageID("msg-1")),
+		events.NewToolCallArgsEvent("call-1", `{"city":"Paris"}`),
+		events.NewToolCallResultEvent("result-1", "call-1", "sunny"),
+		events.NewToolCallStartEvent("call-2", "get_time"),
+		events.NewStepStartedEvent("search"),
+		events.NewTextMessageStartEvent("msg-2"),
+		events.NewTextMessageContentEvent("msg-2", "Sunny."),
+	} {
+		tr.apply(e)
+	}
+
+	assert.Equal(t, []types.Message{
+		{ID: "msg-1", Role: types.RoleAssistant, Content: "Let me check.", ToolCalls: []types.ToolCall{
+			{ID: "call-1", Type: "function", Function: types.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+		}},
+		{ID: "msg-call-2", Role: types.RoleAssistant, ToolCalls: []types.ToolCall{
+			{ID: "call-2", Type: "function", Function: types.FunctionCall{Name: "get_time"}},
+		}},
+		{ID: "msg-2", Role: types.RoleAssistant, Content: "Sunny."},
+		{ID: "result-call-1", Role: types.RoleTool, ToolCallID: "call-1", Content: "sunny"},
+	}, tr.history(from), "tool results follow the calls, notices are left out")
+
+	assert.Equal(t, "earlier answer\n\nLet me check.\n\nSunny.", tr.text())
+}
+
+func TestEntryRender(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry entry
+		want  []string
+	}{
+		{"user", entry{kind: entryUser, content: "Hi"}, []string{"You", "Hi"}},
+		{"streaming", entry{kind: entryAssistant, content: "Hel", streaming: true}, []string{"Assistant", "Hel▌"}},
+		{"thinking", entry{kind: entryThinking, content: "hmm"}, []string{"thinking: hmm"}},
+		{"error", entry{kind: entryError, content: "model unavailable"}, []string{"✗ model unavailable"}},
+		{"notice", entry{kind: entryNotice, content: "step started: search"}, []string{"· step started: search"}},
+		{"tool", entry{kind: entryTool, toolName: "get_weather", content: "{}", result: "sunny"}, []string{"⚙ get_weather", "args: {}", "result: sunny"}},
+		{"pending", entry{kind: entryTool, toolName: "delete_file", approvalStatus: "pending"}, []string{"awaiting approval (y/n)"}},
+		{"approved", entry{kind: entryTool, toolName: "delete_file", approvalStatus: "approved"}, []string{"approved"}},
+		{"rejected", entry{kind: entryTool, toolName: "delete_file", approvalStatus: "rejected"}, []string{"rejected"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := tt.entry.render(80)
+			for _, want := range tt.want {
+				assert.Contains(t, out, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server"
+)
+
+// defaultMaxRuns is the default number of recent runs kept
+const defaultMaxRuns = 1000
+
+// Config configures an Aggregator
+type Config struct {
+	// MaxRuns is the number of recent runs whose statistics are kept for
+	// Runs (defaults to 1000). Summaries cover every run.
+	MaxRuns int
+
+	// Tokenizer estimates the number of tokens of a text (defaults to
+	// EstimateTokens)
+	Tokenizer func(text string) int
+
+	// OnRun, when set, is called with the statistics of each completed run,
+	// e.g. to export them
+	OnRun func(RunStats)
+
+	// Clock times the runs (defaults to the real clock)
+	Clock clock.Clock
+}
+
+// Summary aggregates the statistics of runs
+type Summary struct {
+	Runs     int            `json:"runs"`
+	Outcomes map[string]int `json:"outcomes"`
+
+	// ErrorCodes counts failed runs by the code of their RUN_ERROR
+	ErrorCodes map[string]int `json:"errorCodes"`
+
+	Duration     Timing `json:"duration"`
+	FirstContent Timing `json:"firstContent"`
+
+	Events   map[events.EventType]int `json:"events"`
+	Messages int                      `json:"messages"`
+	Tokens   Tokens                   `json:"tokens"`
+	Tools    map[string]*ToolStats    `json:"tools"`
+	Steps    map[string]*Timing       `json:"steps"`
+}
+
+func newSummary() *Summary {
+	return &Summary{
+		Outcomes:   make(map[string]int),
+		ErrorCodes: make(map[string]int),
+		Events:     make(map[events.EventType]int),
+		Tools:      make(map[string]*ToolStats),
+		Steps:      make(map[string]*Timing),
+	}
+}
+
+func (s *Summary) add(stats RunStats) {
+	s.Runs++
+	s.Outcomes[stats.Outcome]++
+	if stats.ErrorCode != "" {
+		s.ErrorCodes[stats.ErrorCode]++
+	}
+	s.Duration.add(Timing{Count: 1, TotalMs: stats.DurationMs, MaxMs: stats.DurationMs})
+	if stats.FirstContentMs > 0 {
+		s.FirstContent.add(Timing{Count: 1, TotalMs: stats.FirstContentMs, MaxMs: stats.FirstContentMs})
+	}
+	for eventType, n := range stats.Events {
+		s.Events[eventType] += n
+	}
+	s.Messages += stats.Messages
+	s.Tokens.add(stats.Tokens)
+	for name, tool := range stats.Tools {
+		if _, ok := s.Tools[name]; !ok {
+			s.Tools[name] = &ToolStats{}
+		}
+		s.Tools[name].add(*tool)
+	}
+	for name, timing := range stats.Steps {
+		if _, ok := s.Steps[name]; !ok {
+			s.Steps[name] = &Timing{}
+		}
+		s.Steps[name].add(*timing)
+	}
+}
+
+// clone returns a deep copy of s
+func (s *Summary) clone() Summary {
+	c := *newSummary()
+	c.Runs = s.Runs
+	c.Duration = s.Duration
+	c.FirstContent = s.FirstContent
+	c.Messages = s.Messages
+	c.Tokens = s.Tokens
+	for k, v := range s.Outcomes {
+		c.Outcomes[k] = v
+	}
+	for k, v := range s.ErrorCodes {
+		c.ErrorCodes[k] = v
+	}
+	for k, v := range s.Events {
+		c.Events[k] = v
+	}
+	for k, v := range s.Tools {
+		tool := *v
+		c.Tools[k] = &tool
+	}
+	for k, v := range s.Steps {
+		timing := *v
+		c.Steps[k] = &timing
+	}
+	return c
+}
+
+// Aggregator collects the statistics of runs. It is safe for concurrent
+// use.
+type Aggregator struct {
+	config Config
+
+	mu      sync.Mutex
+	recent  []RunStats
+	next    int
+	summary *Summary
+	tenants map[string]*Summary
+	metrics *metrics
+}
+
+// NewAggregator creates an aggregator
+func NewAggregator(config Config) *Aggregator {
+	if config.MaxRuns <= 0 {
+		config.MaxRuns = defaultMaxRuns
+	}
+	if config.Tokenizer == nil {
+		config.Tokenizer = EstimateTokens
+	}
+	config.Clock = clock.Or(config.Clock)
+	return &Aggregator{
+		config:  config,
+		summary: newSummary(),
+		tenants: make(map[string]*Summary),
+		metrics: newMetrics(),
+	}
+}
+
+// Tap collects the statistics of the runs passing to emitter. It is meant
+// for server.RunManagerConfig.Tap.
+func (a *Aggregator) Tap(ctx context.Context, input *types.RunAgentInput, emitter server.Emitter) server.Emitter {
+	run := a.Start(ctx, input)
+	return server.EmitterFunc(func(ctx context.Context, event events.Event) error {
+		run.Observe(event)
+		return emitter.Emit(ctx, event)
+	})
+}
+
+// add records the statistics of a completed run
+func (a *Aggregator) add(stats RunStats) {
+	a.mu.Lock()
+	if len(a.recent) < a.config.MaxRuns {
+		a.recent = append(a.recent, stats)
+	} else {
+		a.recent[a.next] = stats
+	}
+	a.next = (a.next + 1) % a.config.MaxRuns
+	a.summary.add(stats)
+	if tenant := stats.Labels[server.TenantLabel]; tenant != "" {
+		if _, ok := a.tenants[tenant]; !ok {
+			a.tenants[tenant] = newSummary()
+		}
+		a.tenants[tenant].add(stats)
+	}
+	a.metrics.add(stats)
+	a.mu.Unlock()
+
+	if a.config.OnRun != nil {
+		a.config.OnRun(stats)
+	}
+}
+
+// Runs returns the statistics of the most recent runs, newest first
+func (a *Aggregator) Runs() []RunStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	runs := make([]RunStats, 0, len(a.recent))
+	for i := 1; i <= len(a.recent); i++ {
+		runs = append(runs, a.recent[(a.next-i+len(a.recent))%len(a.recent)])
+	}
+	return runs
+}
+
+// Summary returns the statistics of all runs, or of the runs of a tenant
+// when tenant is not empty
+func (a *Aggregator) Summary(tenant string) Summary {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	summary := a.summary
+	if tenant != "" {
+		if summary = a.tenants[tenant]; summary == nil {
+			return newSummary().clone()
+		}
+	}
+	return summary.clone()
+}
+
+// Handler serves the statistics as JSON:
+//
+//	GET /summary[?tenant=ID]           Summary
+//	GET /runs[?tenant=ID][&limit=N]    recent RunStats, newest first
+//
+// Mount it behind authentication, e.g. with http.StripPrefix.
+func (a *Aggregator) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /summary", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, a.Summary(r.URL.Query().Get("tenant")))
+	})
+	mux.HandleFunc("GET /runs", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		limit := a.config.MaxRuns
+		if value := query.Get("limit"); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				http.Error(w, "invalid limit", http.StatusBadRequest)
+				return
+			}
+			limit = n
+		}
+		tenant := query.Get("tenant")
+		runs := make([]RunStats, 0)
+		for _, run := range a.Runs() {
+			if len(runs) == limit {
+				break
+			}
+			if tenant == "" || run.Labels[server.TenantLabel] == tenant {
+				runs = append(runs, run)
+			}
+		}
+		writeJSON(w, runs)
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,373 @@
+// Package analytics computes statistics of agent runs from their event
+// streams: durations, token counts, tool usage, time spent per step and
+// error codes. An Aggregator taps the run manager
+// (server.RunManagerConfig.Tap), or consumes any stream of events through
+// Start, and serves the statistics as JSON and, optionally, as Prometheus
+// metrics, so that agent behavior is measured without a separate pipeline.
+//
+// AG-UI events carry no token usage, so tokens are estimated from the text
+// streamed by the agent and the messages of the run input, unless the agent
+// reports them in a CUSTOM event named UsageEvent. A UsageMeter tallies the
+// reported usage and cost hints per tenant, session and model for billing.
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server"
+)
+
+// UsageEvent is the name of the CUSTOM events reporting the token usage of
+// a run, see events.NewUsageEvent. Reported usage adds up over the events of
+// a run and replaces estimates.
+const UsageEvent = events.UsageEventName
+
+// Outcomes of runs
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+
+	// OutcomeIncomplete is a run whose stream ended without a terminal event
+	OutcomeIncomplete = "incomplete"
+)
+
+// EstimateTokens approximates the number of tokens of text as a quarter of
+// its length in bytes, the usual rule of thumb for English text
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// Tokens counts the tokens of a run
+type Tokens struct {
+	Input     int `json:"input"`
+	Output    int `json:"output"`
+	Reasoning int `json:"reasoning"`
+
+	// Estimated is set when the counts were estimated rather than reported
+	Estimated bool `json:"estimated"`
+}
+
+func (t *Tokens) add(other Tokens) {
+	t.Input += other.Input
+	t.Output += other.Output
+	t.Reasoning += other.Reasoning
+	t.Estimated = t.Estimated || other.Estimated
+}
+
+// Timing accumulates durations
+type Timing struct {
+	Count   int     `json:"count"`
+	TotalMs float64 `json:"totalMs"`
+	MaxMs   float64 `json:"maxMs"`
+}
+
+// MeanMs returns the mean duration
+func (t Timing) MeanMs() float64 {
+	if t.Count == 0 {
+		return 0
+	}
+	return t.TotalMs / float64(t.Count)
+}
+
+func (t *Timing) observe(d time.Duration) {
+	ms := float64(d) / float64(time.Millisecond)
+	t.Count++
+	t.TotalMs += ms
+	if ms > t.MaxMs {
+		t.MaxMs = ms
+	}
+}
+
+func (t *Timing) add(other Timing) {
+	t.Count += other.Count
+	t.TotalMs += other.TotalMs
+	if other.MaxMs > t.MaxMs {
+		t.MaxMs = other.MaxMs
+	}
+}
+
+// MarshalJSON adds the mean to the encoded timing
+func (t Timing) MarshalJSON() ([]byte, error) {
+	type timing Timing
+	return json.Marshal(struct {
+		timing
+		MeanMs float64 `json:"meanMs"`
+	}{timing(t), t.MeanMs()})
+}
+
+// ToolStats describes the use of a tool
+type ToolStats struct {
+	// Calls counts the calls of the tool
+	Calls int `json:"calls"`
+
+	// Results counts the results of calls returned in the same run
+	Results int `json:"results"`
+
+	// ArgsBytes is the size of the arguments streamed for the calls
+	ArgsBytes int `json:"argsBytes"`
+
+	// Streaming times the calls, from TOOL_CALL_START to TOOL_CALL_END
+	Streaming Timing `json:"streaming"`
+}
+
+func (s *ToolStats) add(other ToolStats) {
+	s.Calls += other.Calls
+	s.Results += other.Results
+	s.ArgsBytes += other.ArgsBytes
+	s.Streaming.add(other.Streaming)
+}
+
+// RunStats are the statistics of a run
+type RunStats struct {
+	ThreadID string `json:"threadId"`
+	RunID    string `json:"runId"`
+
+	// Labels are the metric labels of the run, see server.MetricLabels
+	Labels map[string]string `json:"labels,omitempty"`
+
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	DurationMs float64   `json:"durationMs"`
+
+	// FirstContentMs is the time to the first text streamed by the agent
+	// (0 = none)
+	FirstContentMs float64 `json:"firstContentMs,omitempty"`
+
+	// Outcome is OutcomeSuccess, OutcomeError or OutcomeIncomplete, and
+	// ErrorCode the code of the RUN_ERROR of failed runs
+	Outcome   string `json:"outcome"`
+	ErrorCode string `json:"errorCode,omitempty"`
+
+	// Events counts the events of the run by type
+	Events map[events.EventType]int `json:"events"`
+
+	// Messages counts the text messages of the agent
+	Messages int `json:"messages"`
+
+	Tokens Tokens                `json:"tokens"`
+	Tools  map[string]*ToolStats `json:"tools,omitempty"`
+	Steps  map[string]*Timing    `json:"steps,omitempty"`
+}
+
+// Run collects the statistics of a run from its events. It is safe for
+// concurrent use.
+type Run struct {
+	aggregator *Aggregator
+
+	mu        sync.Mutex
+	stats     RunStats
+	input     *types.RunAgentInput
+	output    strings.Builder
+	reasoning strings.Builder
+	reported  *Tokens
+	tools     map[string]*toolCall
+	steps     map[string]time.Time
+	done      bool
+}
+
+// toolCall is a tool call being tracked
+type toolCall struct {
+	name    string
+	started time.Time
+	ended   bool
+}
+
+// Start starts collecting the statistics of a run. Feed it the events of
+// the run with Observe; the run completes with its terminal event, or when
+// Finish is called.
+func (a *Aggregator) Start(ctx context.Context, input *types.RunAgentInput) *Run {
+	r := &Run{
+		aggregator: a,
+		input:      input,
+		tools:      make(map[string]*toolCall),
+		steps:      make(map[string]time.Time),
+		stats: RunStats{
+			ThreadID:  input.ThreadID,
+			RunID:     input.RunID,
+			StartedAt: a.config.Clock.Now(),
+			Events:    make(map[events.EventType]int),
+			Tools:     make(map[string]*ToolStats),
+			Steps:     make(map[string]*Timing),
+		},
+	}
+	if labels := server.MetricLabels(ctx); len(labels) > 0 {
+		r.stats.Labels = labels
+	}
+	return r
+}
+
+// Observe adds event to the statistics of the run
+func (r *Run) Observe(event events.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.done {
+		return
+	}
+	now := r.aggregator.config.Clock.Now()
+	r.stats.Events[event.Type()]++
+
+	switch e := event.(type) {
+	case *events.TextMessageStartEvent:
+		r.stats.Messages++
+	case *events.TextMessageContentEvent:
+		r.content(now, e.Delta)
+	case *events.TextMessageChunkEvent:
+		if e.Delta != nil {
+			r.content(now, *e.Delta)
+		}
+	case *events.ReasoningMessageContentEvent:
+		r.reasoning.WriteString(e.Delta)
+	case *events.ThinkingTextMessageContentEvent:
+		r.reasoning.WriteString(e.Delta)
+	case *events.ToolCallStartEvent:
+		r.startTool(e.ToolCallID, e.ToolCallName, now)
+	case *events.ToolCallArgsEvent:
+		r.toolArgs(e.ToolCallID, e.Delta)
+	case *events.ToolCallEndEvent:
+		if call, ok := r.tools[e.ToolCallID]; ok && !call.ended {
+			call.ended = true
+			r.tool(call.name).Streaming.observe(now.Sub(call.started))
+		}
+	case *events.ToolCallChunkEvent:
+		if e.ToolCallID == nil {
+			break
+		}
+		if _, ok := r.tools[*e.ToolCallID]; !ok && e.ToolCallName != nil {
+			r.startTool(*e.ToolCallID, *e.ToolCallName, now)
+		}
+		if e.Delta != nil {
+			r.toolArgs(*e.ToolCallID, *e.Delta)
+		}
+	case *events.ToolCallResultEvent:
+		if call, ok := r.tools[e.ToolCallID]; ok {
+			r.tool(call.name).Results++
+		}
+	case *events.StepStartedEvent:
+		r.steps[e.StepName] = now
+	case *events.StepFinishedEvent:
+		if started, ok := r.steps[e.StepName]; ok {
+			delete(r.steps, e.StepName)
+			r.step(e.StepName).observe(now.Sub(started))
+		}
+	case *events.CustomEvent:
+		if usage, ok, err := events.UsageOf(e); ok && err == nil {
+			r.usage(*usage)
+		}
+	case *events.RunFinishedEvent:
+		r.finish(now, OutcomeSuccess, "")
+	case *events.RunErrorEvent:
+		code := ""
+		if e.Code != nil {
+			code = *e.Code
+		}
+		r.finish(now, OutcomeError, code)
+	}
+}
+
+// Finish completes the run if its terminal event was not observed, as
+// OutcomeIncomplete, and returns its statistics
+func (r *Run) Finish() RunStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.done {
+		r.finish(r.aggregator.config.Clock.Now(), OutcomeIncomplete, "")
+	}
+	return r.stats
+}
+
+func (r *Run) content(now time.Time, delta string) {
+	if r.stats.FirstContentMs == 0 && delta != "" {
+		r.stats.FirstContentMs = milliseconds(now.Sub(r.stats.StartedAt))
+	}
+	r.output.WriteString(delta)
+}
+
+func (r *Run) startTool(id, name string, now time.Time) {
+	r.tools[id] = &toolCall{name: name, started: now}
+	r.tool(name).Calls++
+}
+
+func (r *Run) toolArgs(id, delta string) {
+	if call, ok := r.tools[id]; ok {
+		r.tool(call.name).ArgsBytes += len(delta)
+	}
+	r.output.WriteString(delta)
+}
+
+func (r *Run) tool(name string) *ToolStats {
+	stats, ok := r.stats.Tools[name]
+	if !ok {
+		stats = &ToolStats{}
+		r.stats.Tools[name] = stats
+	}
+	return stats
+}
+
+func (r *Run) step(name string) *Timing {
+	timing, ok := r.stats.Steps[name]
+	if !ok {
+		timing = &Timing{}
+		r.stats.Steps[name] = timing
+	}
+	return timing
+}
+
+// usage adds the token usage reported by a UsageEvent
+func (r *Run) usage(usage events.Usage) {
+	if r.reported == nil {
+		r.reported = &Tokens{}
+	}
+	r.reported.add(Tokens{Input: usage.InputTokens, Output: usage.OutputTokens, Reasoning: usage.ReasoningTokens})
+}
+
+// finish completes the statistics and hands them to the aggregator
+func (r *Run) finish(now time.Time, outcome, code string) {
+	r.done = true
+	r.stats.FinishedAt = now
+	r.stats.DurationMs = milliseconds(now.Sub(r.stats.StartedAt))
+	r.stats.Outcome = outcome
+	r.stats.ErrorCode = code
+
+	if r.reported != nil {
+		r.stats.Tokens = *r.reported
+	} else {
+		tokenize := r.aggregator.config.Tokenizer
+		r.stats.Tokens = Tokens{
+			Input:     tokenize(inputText(r.input)),
+			Output:    tokenize(r.output.String()),
+			Reasoning: tokenize(r.reasoning.String()),
+			Estimated: true,
+		}
+	}
+	r.output.Reset()
+	r.reasoning.Reset()
+	r.aggregator.add(r.stats)
+}
+
+// inputText returns the text of the messages of input
+func inputText(input *types.RunAgentInput) string {
+	var b strings.Builder
+	for _, message := range input.Messages {
+		switch content := message.Content.(type) {
+		case nil:
+		case string:
+			b.WriteString(content)
+		default:
+			if data, err := json.Marshal(content); err == nil {
+				b.Write(data)
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
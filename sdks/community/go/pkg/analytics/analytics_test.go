@@ -0,0 +1,177 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/testhelper"
+)
+
+var discard = server.EmitterFunc(func(context.Context, events.Event) error { return nil })
+
+func newInput(runID string) *types.RunAgentInput {
+	return &types.RunAgentInput{
+		ThreadID: "thread-1",
+		RunID:    runID,
+		Messages: []types.Message{{ID: "msg-1", Role: types.RoleUser, Content: "What is the weather?"}},
+	}
+}
+
+// weatherAgent plans, calls a tool and answers, advancing clk as it goes
+func weatherAgent(clk *testhelper.FakeClock) server.Agent {
+	return server.AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, emitter *server.EventEmitter) error {
+		if err := emitter.StartStep(ctx, "plan"); err != nil {
+			return err
+		}
+		clk.Advance(200 * time.Millisecond)
+		if err := emitter.FinishStep(ctx, "plan"); err != nil {
+			return err
+		}
+		id, err := emitter.StartToolCall(ctx, "get_weather", "")
+		if err != nil {
+			return err
+		}
+		if err := emitter.EmitToolCallArgs(ctx, id, `{"city":"Paris"}`); err != nil {
+			return err
+		}
+		clk.Advance(50 * time.Millisecond)
+		if err := emitter.EndToolCall(ctx, id); err != nil {
+			return err
+		}
+		if _, err := emitter.EmitToolCallResult(ctx, id, "sunny"); err != nil {
+			return err
+		}
+		msg, err := emitter.StartTextMessage(ctx, "assistant")
+		if err != nil {
+			return err
+		}
+		clk.Advance(100 * time.Millisecond)
+		if err := emitter.EmitContent(ctx, msg, "It is sunny in Paris."); err != nil {
+			return err
+		}
+		return emitter.EndTextMessage(ctx, msg)
+	})
+}
+
+func TestRunStatistics(t *testing.T) {
+	clk := testhelper.NewFakeClock(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+	var completed []RunStats
+	aggregator := NewAggregator(Config{Clock: clk, OnRun: func(stats RunStats) { completed = append(completed, stats) }})
+
+	ctx := server.WithTenant(context.Background(), "acme")
+	require.NoError(t, server.NewRunManager(weatherAgent(clk), server.RunManagerConfig{Tap: aggregator.Tap}).Run(ctx, newInput("run-1"), discard))
+
+	require.Len(t, completed, 1)
+	stats := completed[0]
+	assert.Equal(t, "run-1", stats.RunID)
+	assert.Equal(t, map[string]string{server.TenantLabel: "acme"}, stats.Labels)
+	assert.Equal(t, OutcomeSuccess, stats.Outcome)
+	assert.Equal(t, 350.0, stats.DurationMs)
+	assert.Equal(t, 350.0, stats.FirstContentMs)
+	assert.Equal(t, 1, stats.Messages)
+	assert.Equal(t, 1, stats.Events[events.EventTypeToolCallStart])
+	total := 0
+	for _, n := range stats.Events {
+		total += n
+	}
+	assert.Equal(t, 11, total)
+	assert.Equal(t, &ToolStats{Calls: 1, Results: 1, ArgsBytes: 16, Streaming: Timing{Count: 1, TotalMs: 50, MaxMs: 50}}, stats.Tools["get_weather"])
+	assert.Equal(t, &Timing{Count: 1, TotalMs: 200, MaxMs: 200}, stats.Steps["plan"])
+	assert.Equal(t, Tokens{
+		Input:     EstimateTokens("What is the weather?\n"),
+		Output:    EstimateTokens(`{"city":"Paris"}It is sunny in Paris.`),
+		Estimated: true,
+	}, stats.Tokens)
+
+	assert.Equal(t, 1, aggregator.Summary("acme").Runs)
+	assert.Zero(t, aggregator.Summary("other").Runs)
+}
+
+func TestReportedUsage(t *testing.T) {
+	aggregator := NewAggregator(Config{})
+	run := aggregator.Start(context.Background(), newInput("run-1"))
+	run.Observe(events.NewRunStartedEvent("thread-1", "run-1"))
+	run.Observe(events.NewCustomEvent(UsageEvent, events.WithValue(map[string]any{"inputTokens": 100, "outputTokens": 20})))
+	run.Observe(events.NewTextMessageContentEvent("msg-1", "a long answer that is not counted"))
+	run.Observe(events.NewCustomEvent(UsageEvent, events.WithValue(map[string]any{"inputTokens": 150, "outputTokens": 30, "reasoningTokens": 5})))
+	run.Observe(events.NewRunErrorEvent("out of tokens", events.WithErrorCode("QUOTA")))
+	run.Observe(events.NewStepStartedEvent("late"))
+
+	stats := run.Finish()
+	assert.Equal(t, Tokens{Input: 250, Output: 50, Reasoning: 5}, stats.Tokens)
+	assert.Equal(t, OutcomeError, stats.Outcome)
+	assert.Equal(t, "QUOTA", stats.ErrorCode)
+	assert.Zero(t, stats.Events[events.EventTypeStepStarted], "events after the end are ignored")
+}
+
+func TestIncompleteRun(t *testing.T) {
+	aggregator := NewAggregator(Config{})
+	run := aggregator.Start(context.Background(), newInput("run-1"))
+	run.Observe(events.NewRunStartedEvent("thread-1", "run-1"))
+	run.Observe(events.NewToolCallChunkEvent().WithToolCallChunkID("call-1").WithToolCallChunkName("search").WithToolCallChunkDelta(`{"q":"go"}`))
+
+	stats := run.Finish()
+	assert.Equal(t, OutcomeIncomplete, stats.Outcome)
+	assert.Equal(t, &ToolStats{Calls: 1, ArgsBytes: 10}, stats.Tools["search"])
+	assert.Equal(t, stats, run.Finish(), "Finish is idempotent")
+	assert.Equal(t, map[string]int{OutcomeIncomplete: 1}, aggregator.Summary("").Outcomes)
+}
+
+func TestHandler(t *testing.T) {
+	aggregator := NewAggregator(Config{MaxRuns: 2})
+	manager := server.NewRunManager(server.AgentFunc(func(context.Context, *types.RunAgentInput, *server.EventEmitter) error {
+		return &server.RunError{Code: "QUOTA", Message: "out of tokens"}
+	}), server.RunManagerConfig{Tap: aggregator.Tap})
+	for i, tenant := range []string{"acme", "globex", "acme"} {
+		ctx := server.WithTenant(context.Background(), tenant)
+		require.Error(t, manager.Run(ctx, newInput("run-"+string(rune('1'+i))), discard))
+	}
+	srv := httptest.NewServer(aggregator.Handler())
+	defer srv.Close()
+
+	get := func(path string, v any) {
+		t.Helper()
+		resp, err := http.Get(srv.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	}
+
+	var summary Summary
+	get("/summary", &summary)
+	assert.Equal(t, 3, summary.Runs)
+	assert.Equal(t, map[string]int{"QUOTA": 3}, summary.ErrorCodes)
+	get("/summary?tenant=acme", &summary)
+	assert.Equal(t, 2, summary.Runs)
+
+	var runs []RunStats
+	get("/runs", &runs)
+	require.Len(t, runs, 2, "only MaxRuns runs are kept")
+	assert.Equal(t, "run-3", runs[0].RunID)
+	assert.Equal(t, "run-2", runs[1].RunID)
+	get("/runs?tenant=acme&limit=5", &runs)
+	require.Len(t, runs, 1)
+	assert.Equal(t, "run-3", runs[0].RunID)
+
+	resp, err := http.Get(srv.URL + "/runs?limit=x")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestTimingJSON(t *testing.T) {
+	data, err := json.Marshal(Timing{Count: 2, TotalMs: 30, MaxMs: 20})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"count":2,"totalMs":30,"maxMs":20,"meanMs":15}`, string(data))
+}
@@ -0,0 +1,178 @@
+package analytics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DurationBuckets are the upper bounds, in seconds, of the buckets of the
+// run duration histogram
+var DurationBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+// metrics holds the series exposed to Prometheus, keyed by their rendered
+// labels
+type metrics struct {
+	runs      map[string]float64
+	events    map[string]float64
+	tokens    map[string]float64
+	toolCalls map[string]float64
+	steps     map[string]*Timing
+	durations map[string]*histogram
+}
+
+// histogram counts observations in DurationBuckets
+type histogram struct {
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		runs:      make(map[string]float64),
+		events:    make(map[string]float64),
+		tokens:    make(map[string]float64),
+		toolCalls: make(map[string]float64),
+		steps:     make(map[string]*Timing),
+		durations: make(map[string]*histogram),
+	}
+}
+
+func (m *metrics) add(stats RunStats) {
+	base := stats.Labels
+	m.runs[labels(base, "outcome", stats.Outcome, "error_code", stats.ErrorCode)]++
+	for eventType, n := range stats.Events {
+		m.events[labels(base, "type", string(eventType))] += float64(n)
+	}
+	m.tokens[labels(base, "kind", "input")] += float64(stats.Tokens.Input)
+	m.tokens[labels(base, "kind", "output")] += float64(stats.Tokens.Output)
+	m.tokens[labels(base, "kind", "reasoning")] += float64(stats.Tokens.Reasoning)
+	for name, tool := range stats.Tools {
+		m.toolCalls[labels(base, "tool", name)] += float64(tool.Calls)
+	}
+	for name, timing := range stats.Steps {
+		key := labels(base, "step", name)
+		if _, ok := m.steps[key]; !ok {
+			m.steps[key] = &Timing{}
+		}
+		m.steps[key].add(*timing)
+	}
+
+	key := labels(base)
+	h, ok := m.durations[key]
+	if !ok {
+		h = &histogram{buckets: make([]uint64, len(DurationBuckets))}
+		m.durations[key] = h
+	}
+	seconds := stats.DurationMs / 1000
+	for i, bound := range DurationBuckets {
+		if seconds <= bound {
+			h.buckets[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// write writes the metrics in the Prometheus text exposition format
+func (m *metrics) write(w io.Writer) {
+	counter := func(name, help string, series map[string]float64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+		for _, key := range sortedKeys(series) {
+			fmt.Fprintf(w, "%s%s %s\n", name, key, formatFloat(series[key]))
+		}
+	}
+	counter("agui_runs_total", "Completed runs by outcome and error code.", m.runs)
+	counter("agui_events_total", "Events of completed runs by type.", m.events)
+	counter("agui_tokens_total", "Tokens of completed runs, estimated unless reported by the agent.", m.tokens)
+	counter("agui_tool_calls_total", "Tool calls of completed runs by tool.", m.toolCalls)
+
+	const steps = "agui_step_duration_seconds"
+	fmt.Fprintf(w, "# HELP %s Duration of the steps of completed runs.\n# TYPE %s summary\n", steps, steps)
+	for _, key := range sortedKeys(m.steps) {
+		fmt.Fprintf(w, "%s_sum%s %s\n", steps, key, formatFloat(m.steps[key].TotalMs/1000))
+		fmt.Fprintf(w, "%s_count%s %d\n", steps, key, m.steps[key].Count)
+	}
+
+	const durations = "agui_run_duration_seconds"
+	fmt.Fprintf(w, "# HELP %s Duration of completed runs.\n# TYPE %s histogram\n", durations, durations)
+	for _, key := range sortedKeys(m.durations) {
+		h := m.durations[key]
+		for i, bound := range DurationBuckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", durations, withLabel(key, "le", formatFloat(bound)), h.buckets[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", durations, withLabel(key, "le", "+Inf"), h.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", durations, key, formatFloat(h.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", durations, key, h.count)
+	}
+}
+
+// MetricsHandler serves the statistics of completed runs as Prometheus
+// metrics. Series carry the labels of server.MetricLabels, e.g. the tenant.
+func (a *Aggregator) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		a.metrics.write(w)
+	})
+}
+
+// labels renders base and the extra name, value pairs as a Prometheus label
+// set, sorted by name. Empty values are left out.
+func labels(base map[string]string, extra ...string) string {
+	all := make(map[string]string, len(base)+len(extra)/2)
+	for name, value := range base {
+		all[name] = value
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		all[extra[i]] = extra[i+1]
+	}
+	names := make([]string, 0, len(all))
+	for name, value := range all {
+		if value != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + escapeLabel(all[name]) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// withLabel adds a label to a rendered label set
+func withLabel(set, name, value string) string {
+	pair := name + `="` + escapeLabel(value) + `"`
+	if set == "" {
+		return "{" + pair + "}"
+	}
+	return strings.TrimSuffix(set, "}") + "," + pair + "}"
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
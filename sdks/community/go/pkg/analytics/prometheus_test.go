@@ -0,0 +1,55 @@
+package analytics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/testhelper"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	clk := testhelper.NewFakeClock(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+	aggregator := NewAggregator(Config{Clock: clk})
+	manager := server.NewRunManager(weatherAgent(clk), server.RunManagerConfig{Tap: aggregator.Tap})
+	require.NoError(t, manager.Run(server.WithTenant(context.Background(), `acme "eu"`), newInput("run-1"), discard))
+
+	srv := httptest.NewServer(aggregator.MetricsHandler())
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/plain")
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	for _, line := range []string{
+		"# TYPE agui_runs_total counter",
+		`agui_runs_total{outcome="success",tenant="acme \"eu\""} 1`,
+		`agui_events_total{tenant="acme \"eu\"",type="TOOL_CALL_START"} 1`,
+		`agui_tool_calls_total{tenant="acme \"eu\"",tool="get_weather"} 1`,
+		`agui_step_duration_seconds_sum{step="plan",tenant="acme \"eu\""} 0.2`,
+		`agui_step_duration_seconds_count{step="plan",tenant="acme \"eu\""} 1`,
+		"# TYPE agui_run_duration_seconds histogram",
+		`agui_run_duration_seconds_bucket{tenant="acme \"eu\"",le="0.25"} 0`,
+		`agui_run_duration_seconds_bucket{tenant="acme \"eu\"",le="0.5"} 1`,
+		`agui_run_duration_seconds_bucket{tenant="acme \"eu\"",le="+Inf"} 1`,
+		`agui_run_duration_seconds_sum{tenant="acme \"eu\""} 0.35`,
+	} {
+		assert.Contains(t, string(body), line+"\n")
+	}
+}
+
+func TestLabels(t *testing.T) {
+	assert.Equal(t, "", labels(nil, "error_code", ""))
+	assert.Equal(t, `{a="1",b="x\\y\n"}`, labels(map[string]string{"b": "x\\y\n"}, "a", "1"))
+	assert.Equal(t, `{le="+Inf"}`, withLabel("", "le", "+Inf"))
+	assert.Equal(t, `{a="1",le="0.5"}`, withLabel(`{a="1"}`, "le", "0.5"))
+}
@@ -0,0 +1,280 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server"
+)
+
+// defaultMaxSessions is the default number of sessions whose usage is kept
+const defaultMaxSessions = 10000
+
+// UsageConfig configures a UsageMeter
+type UsageConfig struct {
+	// MaxSessions is the number of sessions whose totals are kept, the
+	// oldest being forgotten first (defaults to 10000). Tenant totals cover
+	// every session.
+	MaxSessions int
+
+	// OnUsage, when set, is called with each usage report, e.g. to export
+	// it to a billing system
+	OnUsage func(UsageRecord)
+
+	// Clock timestamps the reports (defaults to the real clock)
+	Clock clock.Clock
+}
+
+// UsageRecord is a usage report of a run
+type UsageRecord struct {
+	Tenant   string       `json:"tenant,omitempty"`
+	ThreadID string       `json:"threadId"`
+	RunID    string       `json:"runId"`
+	At       time.Time    `json:"at"`
+	Usage    events.Usage `json:"usage"`
+}
+
+// UsageTotals tallies the token usage reported by agents
+type UsageTotals struct {
+	Reports int    `json:"reports"`
+	Tokens  Tokens `json:"tokens"`
+
+	// Cost adds up the cost hints by currency
+	Cost map[string]float64 `json:"cost,omitempty"`
+
+	// Models breaks the totals down by model, "" for usage reported without
+	// one
+	Models map[string]*UsageTotals `json:"models,omitempty"`
+}
+
+func (t *UsageTotals) add(usage events.Usage, byModel bool) {
+	t.Reports++
+	t.Tokens.add(Tokens{Input: usage.InputTokens, Output: usage.OutputTokens, Reasoning: usage.ReasoningTokens})
+	if usage.Cost > 0 {
+		if t.Cost == nil {
+			t.Cost = make(map[string]float64)
+		}
+		t.Cost[usage.CostCurrency()] += usage.Cost
+	}
+	if !byModel {
+		return
+	}
+	if t.Models == nil {
+		t.Models = make(map[string]*UsageTotals)
+	}
+	model, ok := t.Models[usage.Model]
+	if !ok {
+		model = &UsageTotals{}
+		t.Models[usage.Model] = model
+	}
+	model.add(usage, false)
+}
+
+// clone returns a deep copy of t
+func (t *UsageTotals) clone() UsageTotals {
+	c := UsageTotals{Reports: t.Reports, Tokens: t.Tokens}
+	if t.Cost != nil {
+		c.Cost = make(map[string]float64, len(t.Cost))
+		for currency, cost := range t.Cost {
+			c.Cost[currency] = cost
+		}
+	}
+	if t.Models != nil {
+		c.Models = make(map[string]*UsageTotals, len(t.Models))
+		for name, model := range t.Models {
+			model := model.clone()
+			c.Models[name] = &model
+		}
+	}
+	return c
+}
+
+// sessionKey identifies a session within its tenant
+type sessionKey struct {
+	tenant, threadID string
+}
+
+// UsageMeter tallies the token usage reported by agents with usage events
+// (see events.NewUsageEvent) per tenant, per session and per model, for
+// billing dashboards. It is safe for concurrent use.
+type UsageMeter struct {
+	config UsageConfig
+
+	mu       sync.Mutex
+	total    *UsageTotals
+	tenants  map[string]*UsageTotals
+	sessions map[sessionKey]*UsageTotals
+	order    []sessionKey
+}
+
+// NewUsageMeter creates a usage meter
+func NewUsageMeter(config UsageConfig) *UsageMeter {
+	if config.MaxSessions <= 0 {
+		config.MaxSessions = defaultMaxSessions
+	}
+	config.Clock = clock.Or(config.Clock)
+	return &UsageMeter{
+		config:   config,
+		total:    &UsageTotals{},
+		tenants:  make(map[string]*UsageTotals),
+		sessions: make(map[sessionKey]*UsageTotals),
+	}
+}
+
+// Tap meters the usage reported by the runs passing to emitter, attributed
+// to the tenant of ctx (see server.TenantFromContext) and to the thread of
+// the run. It is meant for server.RunManagerConfig.Tap.
+func (m *UsageMeter) Tap(ctx context.Context, input *types.RunAgentInput, emitter server.Emitter) server.Emitter {
+	tenant, _ := server.TenantFromContext(ctx)
+	return server.EmitterFunc(func(ctx context.Context, event events.Event) error {
+		if usage, ok, err := events.UsageOf(event); ok && err == nil {
+			m.Record(UsageRecord{Tenant: tenant, ThreadID: input.ThreadID, RunID: input.RunID, Usage: *usage})
+		}
+		return emitter.Emit(ctx, event)
+	})
+}
+
+// Record adds a usage report, e.g. one received by a client. A zero At is
+// set to the current time.
+func (m *UsageMeter) Record(record UsageRecord) {
+	if record.At.IsZero() {
+		record.At = m.config.Clock.Now()
+	}
+	m.mu.Lock()
+	m.total.add(record.Usage, true)
+	// Usage without a tenant is kept under "" for the metrics
+	tenant, ok := m.tenants[record.Tenant]
+	if !ok {
+		tenant = &UsageTotals{}
+		m.tenants[record.Tenant] = tenant
+	}
+	tenant.add(record.Usage, true)
+	if record.ThreadID != "" {
+		key := sessionKey{record.Tenant, record.ThreadID}
+		session, ok := m.sessions[key]
+		if !ok {
+			if len(m.order) == m.config.MaxSessions {
+				delete(m.sessions, m.order[0])
+				m.order = m.order[1:]
+			}
+			session = &UsageTotals{}
+			m.sessions[key] = session
+			m.order = append(m.order, key)
+		}
+		session.add(record.Usage, true)
+	}
+	m.mu.Unlock()
+
+	if m.config.OnUsage != nil {
+		m.config.OnUsage(record)
+	}
+}
+
+// Total returns the usage of all runs, or of the runs of a tenant when
+// tenant is not empty
+func (m *UsageMeter) Total(tenant string) UsageTotals {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if tenant == "" {
+		return m.total.clone()
+	}
+	if totals, ok := m.tenants[tenant]; ok {
+		return totals.clone()
+	}
+	return UsageTotals{}
+}
+
+// Tenants returns the usage of each tenant
+func (m *UsageMeter) Tenants() map[string]UsageTotals {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tenants := make(map[string]UsageTotals, len(m.tenants))
+	for tenant, totals := range m.tenants {
+		if tenant != "" {
+			tenants[tenant] = totals.clone()
+		}
+	}
+	return tenants
+}
+
+// Session returns the usage of the runs of a thread of a tenant ("" for
+// runs without one), reporting false for an unknown or forgotten session
+func (m *UsageMeter) Session(tenant, threadID string) (UsageTotals, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	totals, ok := m.sessions[sessionKey{tenant, threadID}]
+	if !ok {
+		return UsageTotals{}, false
+	}
+	return totals.clone(), true
+}
+
+// Handler serves the usage as JSON:
+//
+//	GET /usage[?tenant=ID]             UsageTotals
+//	GET /tenants                       UsageTotals by tenant
+//	GET /sessions/{id}[?tenant=ID]     UsageTotals of a session
+//
+// Mount it behind authentication, e.g. with http.StripPrefix.
+func (m *UsageMeter) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /usage", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, m.Total(r.URL.Query().Get("tenant")))
+	})
+	mux.HandleFunc("GET /tenants", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, m.Tenants())
+	})
+	mux.HandleFunc("GET /sessions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		totals, ok := m.Session(r.URL.Query().Get("tenant"), r.PathValue("id"))
+		if !ok {
+			http.Error(w, "session not found", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, totals)
+	})
+	return mux
+}
+
+// MetricsHandler serves the usage as Prometheus counters by tenant and
+// model. Usage without a tenant has no tenant label.
+func (m *UsageMeter) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.writeMetrics(w)
+	})
+}
+
+func (m *UsageMeter) writeMetrics(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tokens := make(map[string]float64)
+	cost := make(map[string]float64)
+	for tenant, totals := range m.tenants {
+		for model, usage := range totals.Models {
+			base := map[string]string{server.TenantLabel: tenant, "model": model}
+			tokens[labels(base, "kind", "input")] += float64(usage.Tokens.Input)
+			tokens[labels(base, "kind", "output")] += float64(usage.Tokens.Output)
+			tokens[labels(base, "kind", "reasoning")] += float64(usage.Tokens.Reasoning)
+			for currency, amount := range usage.Cost {
+				cost[labels(base, "currency", currency)] += amount
+			}
+		}
+	}
+
+	const tokensName, costName = "agui_usage_tokens_total", "agui_usage_cost_total"
+	fmt.Fprintf(w, "# HELP %s Tokens reported by agents by tenant, model and kind.\n# TYPE %s counter\n", tokensName, tokensName)
+	for _, key := range sortedKeys(tokens) {
+		fmt.Fprintf(w, "%s%s %s\n", tokensName, key, formatFloat(tokens[key]))
+	}
+	fmt.Fprintf(w, "# HELP %s Cost hints reported by agents by tenant, model and currency.\n# TYPE %s counter\n", costName, costName)
+	for _, key := range sortedKeys(cost) {
+		fmt.Fprintf(w, "%s%s %s\n", costName, key, formatFloat(cost[key]))
+	}
+}
@@ -0,0 +1,112 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server"
+)
+
+// usageAgent answers with a message, reporting its usage, then reports the
+// usage of the run
+var usageAgent = server.AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, emitter *server.EventEmitter) error {
+	id, err := emitter.StartTextMessage(ctx, "assistant")
+	if err != nil {
+		return err
+	}
+	if err := emitter.EndTextMessage(ctx, id); err != nil {
+		return err
+	}
+	if err := emitter.EmitUsage(ctx, events.Usage{Model: "large", InputTokens: 100, OutputTokens: 20, Cost: 0.5, MessageID: id}); err != nil {
+		return err
+	}
+	return emitter.EmitUsage(ctx, events.Usage{Model: "small", InputTokens: 10, OutputTokens: 2, Cost: 0.25})
+})
+
+func TestUsageMeter(t *testing.T) {
+	var records []UsageRecord
+	meter := NewUsageMeter(UsageConfig{OnUsage: func(record UsageRecord) { records = append(records, record) }})
+	manager := server.NewRunManager(usageAgent, server.RunManagerConfig{Tap: meter.Tap})
+
+	acme := server.WithTenant(context.Background(), "acme")
+	require.NoError(t, manager.Run(acme, newInput("run-1"), discard))
+	require.NoError(t, manager.Run(acme, newInput("run-2"), discard))
+	require.NoError(t, manager.Run(context.Background(), newInput("run-3"), discard))
+
+	require.Len(t, records, 6)
+	assert.Equal(t, "acme", records[0].Tenant)
+	assert.Equal(t, "run-1", records[0].RunID)
+	assert.False(t, records[0].At.IsZero())
+
+	total := meter.Total("")
+	assert.Equal(t, 6, total.Reports)
+	assert.Equal(t, Tokens{Input: 330, Output: 66}, total.Tokens)
+	assert.Equal(t, map[string]float64{"USD": 2.25}, total.Cost)
+
+	tenant := meter.Total("acme")
+	assert.Equal(t, Tokens{Input: 220, Output: 44}, tenant.Tokens)
+	assert.Equal(t, Tokens{Input: 200, Output: 40}, tenant.Models["large"].Tokens)
+	assert.Equal(t, map[string]float64{"USD": 0.5}, tenant.Models["small"].Cost)
+	assert.Equal(t, []string{"acme"}, sortedKeys(meter.Tenants()))
+
+	session, ok := meter.Session("acme", "thread-1")
+	require.True(t, ok)
+	assert.Equal(t, tenant, session)
+	session, ok = meter.Session("", "thread-1")
+	require.True(t, ok)
+	assert.Equal(t, 2, session.Reports, "sessions are scoped to their tenant")
+	_, ok = meter.Session("other", "thread-1")
+	assert.False(t, ok)
+}
+
+func TestUsageMeterForgetsSessions(t *testing.T) {
+	meter := NewUsageMeter(UsageConfig{MaxSessions: 2})
+	for _, thread := range []string{"thread-1", "thread-2", "thread-3"} {
+		meter.Record(UsageRecord{ThreadID: thread, Usage: events.Usage{InputTokens: 1}})
+	}
+	_, ok := meter.Session("", "thread-1")
+	assert.False(t, ok)
+	_, ok = meter.Session("", "thread-3")
+	assert.True(t, ok)
+	assert.Equal(t, 3, meter.Total("").Tokens.Input)
+}
+
+func TestUsageHandlers(t *testing.T) {
+	meter := NewUsageMeter(UsageConfig{})
+	meter.Record(UsageRecord{Tenant: "acme", ThreadID: "thread-1", Usage: events.Usage{Model: "large", InputTokens: 100, OutputTokens: 20, Cost: 0.5}})
+	meter.Record(UsageRecord{ThreadID: "thread-2", Usage: events.Usage{Model: "large", InputTokens: 10, Cost: 0.1, Currency: "EUR"}})
+
+	get := func(handler http.Handler, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+	rec := get(meter.Handler(), "/sessions/thread-1?tenant=acme")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var totals UsageTotals
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &totals))
+	assert.Equal(t, 100, totals.Tokens.Input)
+	assert.Equal(t, http.StatusNotFound, get(meter.Handler(), "/sessions/thread-1").Code)
+
+	var tenants map[string]UsageTotals
+	require.NoError(t, json.Unmarshal(get(meter.Handler(), "/tenants").Body.Bytes(), &tenants))
+	assert.Equal(t, []string{"acme"}, sortedKeys(tenants))
+
+	body := get(meter.MetricsHandler(), "/metrics").Body.String()
+	for _, line := range []string{
+		`agui_usage_tokens_total{kind="input",model="large",tenant="acme"} 100`,
+		`agui_usage_tokens_total{kind="input",model="large"} 10`,
+		`agui_usage_cost_total{currency="USD",model="large",tenant="acme"} 0.5`,
+		`agui_usage_cost_total{currency="EUR",model="large"} 0.1`,
+	} {
+		assert.Contains(t, body, line+"\n")
+	}
+}
@@ -0,0 +1,334 @@
+// Package archive records completed runs for audit and analytics. An
+// Archiver taps the run manager (server.RunManagerConfig.Tap): it records the
+// validated event stream of each run, and when the run finishes packages the
+// events, final state and metadata and ships them to one or more sinks, a
+// directory, S3 or GCS.
+//
+// An archive is two objects under PREFIX/THREAD/RUN/: the events, as JSON
+// lines (events.jsonl) or length-prefixed protobuf (events.pb), and
+// manifest.json describing them. The manifest is written last, so an archive
+// without one is incomplete.
+//
+// NewReplayAgent serves archived runs back as if they were live, so that
+// UIs review past runs through the usual client code path. cmd/agui-replay
+// serves it.
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/bufpool"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/jsonpatch"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/proto/pb"
+)
+
+// ErrNotFound is returned by sources for missing objects
+var ErrNotFound = errors.New("archive not found")
+
+// ManifestVersion is the version of the manifest written by this package
+const ManifestVersion = 1
+
+// ManifestFile is the name of the manifest of an archive
+const ManifestFile = "manifest.json"
+
+// Format is the encoding of the events of an archive
+type Format string
+
+const (
+	// FormatJSONL stores one JSON event per line, as sent over SSE
+	FormatJSONL Format = "jsonl"
+
+	// FormatProtobuf stores pb.Event messages, each preceded by its length
+	// as a uvarint
+	FormatProtobuf Format = "protobuf"
+)
+
+// File returns the name of the events file of an archive in format f
+func (f Format) File() string {
+	if f == FormatProtobuf {
+		return "events.pb"
+	}
+	return "events.jsonl"
+}
+
+// ContentType returns the media type of the events file
+func (f Format) ContentType() string {
+	if f == FormatProtobuf {
+		return "application/octet-stream"
+	}
+	return "application/x-ndjson"
+}
+
+func (f Format) valid() bool {
+	return f == FormatJSONL || f == FormatProtobuf
+}
+
+// Outcomes of an archived run
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// Manifest describes an archived run
+type Manifest struct {
+	Version  int    `json:"version"`
+	ThreadID string `json:"threadId"`
+	RunID    string `json:"runId"`
+
+	// Tenant and Principal identify who the run was served for, when known
+	Tenant    string `json:"tenant,omitempty"`
+	Principal string `json:"principal,omitempty"`
+
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+
+	// Outcome is OutcomeSuccess for runs ending with RUN_FINISHED and
+	// OutcomeError for those ending with RUN_ERROR, whose code and message
+	// are ErrorCode and ErrorMessage
+	Outcome      string `json:"outcome"`
+	ErrorCode    string `json:"errorCode,omitempty"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+
+	// Format, Events and EventCount describe the events file, named Events
+	// in the directory of the manifest; SHA256 is its hex digest
+	Format     Format `json:"format"`
+	Events     string `json:"events"`
+	EventCount int    `json:"eventCount"`
+	SHA256     string `json:"sha256"`
+
+	// Input is the input of the run
+	Input *types.RunAgentInput `json:"input,omitempty"`
+
+	// FinalState is the state of the run input updated by the STATE_SNAPSHOT
+	// and STATE_DELTA events of the run. StateError reports a delta that
+	// could not be applied, after which FinalState is the last good state.
+	FinalState any    `json:"finalState,omitempty"`
+	StateError string `json:"stateError,omitempty"`
+
+	// Metadata holds application defined attributes (see Config.Metadata)
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// Archive is a packaged run
+type Archive struct {
+	Manifest Manifest
+	Events   []byte
+}
+
+// recorder accumulates the events of a run and tracks its state
+type recorder struct {
+	format   Format
+	manifest Manifest
+	buf      bytes.Buffer
+	state    any
+}
+
+func newRecorder(format Format, input *types.RunAgentInput, started time.Time) *recorder {
+	r := &recorder{
+		format: format,
+		manifest: Manifest{
+			Version:   ManifestVersion,
+			ThreadID:  input.ThreadID,
+			RunID:     input.RunID,
+			StartedAt: started,
+			Format:    format,
+			Events:    format.File(),
+			Input:     input,
+		},
+	}
+	state, err := clone(input.State)
+	if err != nil {
+		r.manifest.StateError = err.Error()
+	}
+	r.state = state
+	return r
+}
+
+// record appends event to the archive and applies it to the state
+func (r *recorder) record(event events.Event) error {
+	if err := r.encode(event); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", event.Type(), err)
+	}
+	r.manifest.EventCount++
+
+	switch e := event.(type) {
+	case *events.StateSnapshotEvent:
+		if state, err := clone(e.Snapshot); err == nil {
+			r.state = state
+			r.manifest.StateError = ""
+		} else {
+			r.manifest.StateError = err.Error()
+		}
+	case *events.StateDeltaEvent:
+		if r.manifest.StateError != "" {
+			break
+		}
+		// Apply may leave a partly patched document behind on failure
+		working, err := clone(r.state)
+		if err == nil {
+			working, err = jsonpatch.Apply(working, e.Delta)
+		}
+		if err != nil {
+			r.manifest.StateError = fmt.Sprintf("STATE_DELTA %d: %v", r.manifest.EventCount, err)
+			break
+		}
+		r.state = working
+	case *events.RunErrorEvent:
+		r.manifest.Outcome = OutcomeError
+		r.manifest.ErrorMessage = e.Message
+		if e.Code != nil {
+			r.manifest.ErrorCode = *e.Code
+		}
+	case *events.RunFinishedEvent:
+		r.manifest.Outcome = OutcomeSuccess
+	}
+	return nil
+}
+
+func (r *recorder) encode(event events.Event) error {
+	if r.format == FormatProtobuf {
+		pe, err := pb.EventToProto(event)
+		if err != nil {
+			return err
+		}
+		size := proto.Size(pe)
+		pool := bufpool.Default()
+		data := pool.GetSlice(binary.MaxVarintLen64 + size)
+		defer func() { pool.PutSlice(data) }()
+		data = binary.AppendUvarint(data, uint64(size))
+		data, err = proto.MarshalOptions{}.MarshalAppend(data, pe)
+		if err != nil {
+			return err
+		}
+		r.buf.Write(data)
+		return nil
+	}
+	data, err := event.ToJSON()
+	if err != nil {
+		return err
+	}
+	r.buf.Write(data)
+	r.buf.WriteByte('\n')
+	return nil
+}
+
+// archive packages the recorded run
+func (r *recorder) archive(finished time.Time) *Archive {
+	manifest := r.manifest
+	manifest.FinishedAt = finished
+	manifest.FinalState = r.state
+	manifest.SHA256 = hexSHA256(r.buf.Bytes())
+	return &Archive{Manifest: manifest, Events: r.buf.Bytes()}
+}
+
+// Load reads the archive in directory dir of source, verifying its events
+// against the manifest
+func Load(ctx context.Context, source Source, dir string) (*Archive, error) {
+	data, err := source.Get(ctx, path.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if manifest.Version > ManifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d", manifest.Version)
+	}
+	if !manifest.Format.valid() || !filepath.IsLocal(manifest.Events) {
+		return nil, fmt.Errorf("invalid manifest: events %q in format %q", manifest.Events, manifest.Format)
+	}
+	data, err = source.Get(ctx, path.Join(dir, manifest.Events))
+	if err != nil {
+		return nil, err
+	}
+	if sum := hexSHA256(data); sum != manifest.SHA256 {
+		return nil, fmt.Errorf("events of %s do not match the manifest: sha256 %s, want %s", dir, sum, manifest.SHA256)
+	}
+	return &Archive{Manifest: manifest, Events: data}, nil
+}
+
+// clone deep-copies a decoded JSON value, so that patches do not modify the
+// run input or the events
+func clone(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReadEvents decodes the events file of an archive in format
+func ReadEvents(format Format, data []byte) ([]events.Event, error) {
+	switch format {
+	case FormatJSONL:
+		return readJSONL(data)
+	case FormatProtobuf:
+		return readProtobuf(data)
+	default:
+		return nil, fmt.Errorf("unsupported archive format %q", format)
+	}
+}
+
+func readJSONL(data []byte) ([]events.Event, error) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	decoder := events.NewEventDecoder(logger)
+
+	var result []events.Event
+	for line := 1; len(data) > 0; line++ {
+		var raw []byte
+		raw, data, _ = bytes.Cut(data, []byte("\n"))
+		if len(bytes.TrimSpace(raw)) == 0 {
+			continue
+		}
+		event, err := decoder.Decode(raw)
+		if err != nil {
+			return result, fmt.Errorf("line %d: %w", line, err)
+		}
+		result = append(result, event)
+	}
+	return result, nil
+}
+
+func readProtobuf(data []byte) ([]events.Event, error) {
+	var result []events.Event
+	for len(data) > 0 {
+		size, n := binary.Uvarint(data)
+		if n <= 0 || uint64(len(data)-n) < size {
+			return result, errors.New("truncated protobuf archive")
+		}
+		var pe pb.Event
+		if err := proto.Unmarshal(data[n:n+int(size)], &pe); err != nil {
+			return result, fmt.Errorf("event %d: %w", len(result)+1, err)
+		}
+		event, err := pb.EventFromProto(&pe)
+		if err != nil {
+			return result, fmt.Errorf("event %d: %w", len(result)+1, err)
+		}
+		result = append(result, event)
+		data = data[n+int(size):]
+	}
+	return result, nil
+}
@@ -0,0 +1,194 @@
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/testhelper"
+)
+
+// statefulAgent replies and updates the counter of its state
+var statefulAgent = server.AgentFunc(func(ctx context.Context, input *types.RunAgentInput, emitter *server.EventEmitter) error {
+	id, err := emitter.StartTextMessage(ctx, "assistant")
+	if err != nil {
+		return err
+	}
+	if err := emitter.EmitContent(ctx, id, "counting"); err != nil {
+		return err
+	}
+	if err := emitter.EndTextMessage(ctx, id); err != nil {
+		return err
+	}
+	return emitter.EmitStateDelta(ctx, []events.JSONPatchOperation{
+		{Op: "replace", Path: "/count", Value: 2},
+		{Op: "add", Path: "/seen/-", Value: "run-1"},
+	})
+})
+
+func newInput() *types.RunAgentInput {
+	return &types.RunAgentInput{
+		ThreadID: "thread-1",
+		RunID:    "run-1",
+		State:    map[string]any{"count": 1, "seen": []any{}},
+	}
+}
+
+// readArchive reads the manifest and events written by a FileSink to dir
+func readArchive(t *testing.T, dir string) (Manifest, []events.Event) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	require.NoError(t, err)
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+
+	data, err = os.ReadFile(filepath.Join(dir, manifest.Events))
+	require.NoError(t, err)
+	assert.Equal(t, manifest.SHA256, hexSHA256(data))
+	seq, err := ReadEvents(manifest.Format, data)
+	require.NoError(t, err)
+	return manifest, seq
+}
+
+func eventTypes(seq []events.Event) []events.EventType {
+	got := make([]events.EventType, len(seq))
+	for i, event := range seq {
+		got[i] = event.Type()
+	}
+	return got
+}
+
+func TestArchiveRun(t *testing.T) {
+	for _, format := range []Format{FormatJSONL, FormatProtobuf} {
+		t.Run(string(format), func(t *testing.T) {
+			dir := t.TempDir()
+			clk := testhelper.NewFakeClock(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+			archiver, err := NewArchiver(Config{
+				Sinks:  []Sink{NewFileSink(dir)},
+				Format: format,
+				Prefix: "runs",
+				Clock:  clk,
+				Metadata: func(context.Context, *types.RunAgentInput) map[string]any {
+					return map[string]any{"region": "eu"}
+				},
+			})
+			require.NoError(t, err)
+
+			input := newInput()
+			ctx := server.WithPrincipal(server.WithTenant(context.Background(), "acme"), &server.Principal{ID: "alice"})
+			require.NoError(t, server.NewRunManager(statefulAgent, server.RunManagerConfig{Tap: archiver.Tap}).
+				Run(ctx, input, server.EmitterFunc(func(context.Context, events.Event) error { return nil })))
+			require.NoError(t, archiver.Close(context.Background()))
+
+			manifest, seq := readArchive(t, filepath.Join(dir, "runs", "thread-1", "run-1"))
+			assert.Equal(t, []events.EventType{
+				events.EventTypeRunStarted,
+				events.EventTypeTextMessageStart,
+				events.EventTypeTextMessageContent,
+				events.EventTypeTextMessageEnd,
+				events.EventTypeStateDelta,
+				events.EventTypeRunFinished,
+			}, eventTypes(seq))
+			assert.Equal(t, "counting", seq[2].(*events.TextMessageContentEvent).Delta)
+
+			assert.Equal(t, ManifestVersion, manifest.Version)
+			assert.Equal(t, "thread-1", manifest.ThreadID)
+			assert.Equal(t, "run-1", manifest.RunID)
+			assert.Equal(t, "acme", manifest.Tenant)
+			assert.Equal(t, "alice", manifest.Principal)
+			assert.Equal(t, OutcomeSuccess, manifest.Outcome)
+			assert.Equal(t, format, manifest.Format)
+			assert.Equal(t, 6, manifest.EventCount)
+			assert.Equal(t, clk.Now(), manifest.StartedAt)
+			assert.Equal(t, map[string]any{"count": 2.0, "seen": []any{"run-1"}}, manifest.FinalState)
+			assert.Empty(t, manifest.StateError)
+			assert.Equal(t, map[string]any{"region": "eu"}, manifest.Metadata)
+			assert.Equal(t, map[string]any{"count": 1, "seen": []any{}}, input.State, "the run input is not modified")
+		})
+	}
+}
+
+func TestArchiveFailedRuns(t *testing.T) {
+	failing := server.AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, emitter *server.EventEmitter) error {
+		if err := emitter.EmitStateSnapshot(ctx, map[string]any{"step": "one"}); err != nil {
+			return err
+		}
+		if err := emitter.EmitStateDelta(ctx, []events.JSONPatchOperation{{Op: "add", Path: "/step/0", Value: "x"}}); err != nil {
+			return err
+		}
+		return &server.RunError{Code: "QUOTA", Message: "out of tokens"}
+	})
+	discard := server.EmitterFunc(func(context.Context, events.Event) error { return nil })
+
+	t.Run("skipped by default", func(t *testing.T) {
+		dir := t.TempDir()
+		archiver, err := NewArchiver(Config{Sinks: []Sink{NewFileSink(dir)}})
+		require.NoError(t, err)
+		require.Error(t, server.NewRunManager(failing, server.RunManagerConfig{Tap: archiver.Tap}).Run(context.Background(), newInput(), discard))
+		require.NoError(t, archiver.Close(context.Background()))
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("archived with Errors", func(t *testing.T) {
+		dir := t.TempDir()
+		archiver, err := NewArchiver(Config{Sinks: []Sink{NewFileSink(dir)}, Errors: true})
+		require.NoError(t, err)
+		require.Error(t, server.NewRunManager(failing, server.RunManagerConfig{Tap: archiver.Tap}).Run(context.Background(), newInput(), discard))
+		require.NoError(t, archiver.Close(context.Background()))
+
+		manifest, seq := readArchive(t, filepath.Join(dir, "thread-1", "run-1"))
+		assert.Len(t, seq, 4)
+		assert.Equal(t, OutcomeError, manifest.Outcome)
+		assert.Equal(t, "QUOTA", manifest.ErrorCode)
+		assert.Equal(t, "out of tokens", manifest.ErrorMessage)
+		assert.Equal(t, map[string]any{"step": "one"}, manifest.FinalState, "the last good state is kept")
+		assert.Contains(t, manifest.StateError, "STATE_DELTA 3")
+	})
+}
+
+func TestArchiveIsBestEffort(t *testing.T) {
+	var delivered []events.EventType
+	archiver, err := NewArchiver(Config{
+		Sinks:  []Sink{SinkFunc(func(context.Context, string, []byte, string) error { return errors.New("bucket gone") })},
+		Logger: quiet,
+	})
+	require.NoError(t, err)
+
+	err = server.NewRunManager(statefulAgent, server.RunManagerConfig{Tap: archiver.Tap}).Run(context.Background(), newInput(),
+		server.EmitterFunc(func(_ context.Context, event events.Event) error {
+			delivered = append(delivered, event.Type())
+			return nil
+		}))
+	require.NoError(t, err, "archival failures do not fail runs")
+	require.NoError(t, archiver.Close(context.Background()))
+	assert.Len(t, delivered, 6)
+}
+
+func TestReadEventsRejectsCorruptArchives(t *testing.T) {
+	_, err := ReadEvents(FormatProtobuf, []byte{0x05, 0x01})
+	assert.Error(t, err)
+	_, err = ReadEvents(FormatJSONL, []byte("{not json}\n"))
+	assert.Error(t, err)
+	_, err = ReadEvents("xml", nil)
+	assert.Error(t, err)
+}
+
+func TestNewArchiverValidatesConfig(t *testing.T) {
+	_, err := NewArchiver(Config{})
+	assert.Error(t, err)
+	_, err = NewArchiver(Config{Sinks: []Sink{NewFileSink(t.TempDir())}, Format: "xml"})
+	assert.Error(t, err)
+}
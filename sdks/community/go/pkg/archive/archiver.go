@@ -0,0 +1,183 @@
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/logging"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/runctx"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server"
+)
+
+// defaultTimeout bounds the shipping of an archive to a sink
+const defaultTimeout = time.Minute
+
+// Config configures an Archiver
+type Config struct {
+	// Sinks receive every archive
+	Sinks []Sink
+
+	// Format of the events (defaults to FormatJSONL)
+	Format Format
+
+	// Prefix is prepended to the keys of archives
+	Prefix string
+
+	// Errors also archives runs ending with RUN_ERROR
+	Errors bool
+
+	// Metadata returns application defined attributes stored in the
+	// manifest of a run, from the context of the request serving it
+	Metadata func(ctx context.Context, input *types.RunAgentInput) map[string]any
+
+	// Timeout bounds the shipping of an archive to each sink (defaults to a
+	// minute)
+	Timeout time.Duration
+
+	// Logger receives the errors of archival (defaults to slog.Default())
+	Logger *slog.Logger
+
+	// Clock timestamps the runs (defaults to the real clock)
+	Clock clock.Clock
+}
+
+// Archiver archives runs as they finish. Archives are shipped in the
+// background, so archival never delays or fails a run; its errors are
+// logged. Call Close before exiting to wait for pending archives.
+type Archiver struct {
+	config Config
+	logger *slog.Logger
+	wg     sync.WaitGroup
+}
+
+// NewArchiver creates an archiver shipping to the sinks of config
+func NewArchiver(config Config) (*Archiver, error) {
+	if len(config.Sinks) == 0 {
+		return nil, errors.New("archive: no sinks configured")
+	}
+	if config.Format == "" {
+		config.Format = FormatJSONL
+	}
+	if !config.Format.valid() {
+		return nil, fmt.Errorf("archive: unsupported format %q", config.Format)
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultTimeout
+	}
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+	config.Clock = clock.Or(config.Clock)
+	return &Archiver{config: config, logger: logging.ContextLogger(logging.ForComponent(config.Logger, "archiver"))}, nil
+}
+
+// Tap records the events of a run passing to emitter and archives the run
+// when it ends. It is meant for server.RunManagerConfig.Tap.
+func (a *Archiver) Tap(ctx context.Context, input *types.RunAgentInput, emitter server.Emitter) server.Emitter {
+	rec := newRecorder(a.config.Format, input, a.config.Clock.Now())
+	if tenant, ok := server.TenantFromContext(ctx); ok {
+		rec.manifest.Tenant = tenant
+	}
+	if principal, ok := server.PrincipalFromContext(ctx); ok {
+		rec.manifest.Principal = principal.ID
+	}
+	if a.config.Metadata != nil {
+		rec.manifest.Metadata = a.config.Metadata(ctx, input)
+	}
+
+	var mu sync.Mutex
+	failed := false
+	return server.EmitterFunc(func(ctx context.Context, event events.Event) error {
+		mu.Lock()
+		if !failed {
+			if err := rec.record(event); err != nil {
+				a.logger.ErrorContext(ctx, "Run not archived", "error", err)
+				failed = true
+			}
+		}
+		mu.Unlock()
+
+		err := emitter.Emit(ctx, event)
+
+		switch event.Type() {
+		case events.EventTypeRunFinished, events.EventTypeRunError:
+			mu.Lock()
+			defer mu.Unlock()
+			if !failed && (event.Type() == events.EventTypeRunFinished || a.config.Errors) {
+				a.ship(rec.archive(a.config.Clock.Now()))
+			}
+			failed = true // a run has a single terminal event
+		}
+		return err
+	})
+}
+
+// Store writes archive to every sink, the events then the manifest
+func (a *Archiver) Store(ctx context.Context, archive *Archive) error {
+	manifest, err := json.MarshalIndent(archive.Manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	dir := a.Key(archive.Manifest.ThreadID, archive.Manifest.RunID)
+
+	var errs []error
+	for _, sink := range a.config.Sinks {
+		sinkCtx, cancel := context.WithTimeout(ctx, a.config.Timeout)
+		err := sink.Put(sinkCtx, path.Join(dir, archive.Manifest.Events), archive.Events, archive.Manifest.Format.ContentType())
+		if err == nil {
+			err = sink.Put(sinkCtx, path.Join(dir, ManifestFile), manifest, "application/json")
+		}
+		cancel()
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Key returns the key of the directory holding the archive of a run
+func (a *Archiver) Key(threadID, runID string) string {
+	return path.Join(a.config.Prefix, threadID, runID)
+}
+
+// Close waits for pending archives to be shipped, or for ctx to expire
+func (a *Archiver) Close(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		a.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ship stores archive in the background
+func (a *Archiver) ship(archive *Archive) {
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		ctx := runctx.With(context.Background(), runctx.Metadata{
+			ThreadID: archive.Manifest.ThreadID,
+			RunID:    archive.Manifest.RunID,
+			Tenant:   archive.Manifest.Tenant,
+		})
+		if err := a.Store(ctx, archive); err != nil {
+			a.logger.ErrorContext(ctx, "Failed to archive run", "error", err)
+			return
+		}
+		a.logger.DebugContext(ctx, "Run archived")
+	}()
+}
@@ -0,0 +1,144 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server"
+)
+
+// RunErrorCodeNotFound ends the replay of a run without an archive
+const RunErrorCodeNotFound = "NOT_FOUND"
+
+// ReplayConfig configures a replay agent
+type ReplayConfig struct {
+	// Prefix of the keys of archives, as in Config.Prefix
+	Prefix string
+
+	// Speed scales the original timing of the events, e.g. 2 replays a run
+	// twice as fast (defaults to 1)
+	Speed float64
+
+	// Instant replays the events without delays
+	Instant bool
+
+	// MaxDelay caps the wait between two events, e.g. to skip the idle
+	// periods of a run (0 = no cap)
+	MaxDelay time.Duration
+
+	// Snapshots precedes the events with MESSAGES_SNAPSHOT and
+	// STATE_SNAPSHOT events carrying the messages and state of the archived
+	// run input, so that clients display the run in context
+	Snapshots bool
+
+	// Clock times the replay (defaults to the real clock)
+	Clock clock.Clock
+}
+
+// NewReplayAgent creates an agent replaying archived runs as if they were
+// live. The thread and run IDs of the run input select the archive; the
+// rest of the input is ignored. Served by a server.Server, archived runs
+// reach clients over SSE through the usual client code path.
+//
+// Events are spaced as they were originally, from their timestamps, and
+// restamped with the time of the replay. A run that ended with RUN_ERROR
+// ends with the same code and message.
+func NewReplayAgent(source Source, config ReplayConfig) server.Agent {
+	if config.Speed <= 0 {
+		config.Speed = 1
+	}
+	config.Clock = clock.Or(config.Clock)
+
+	return server.AgentFunc(func(ctx context.Context, input *types.RunAgentInput, emitter *server.EventEmitter) error {
+		archive, err := Load(ctx, source, path.Join(config.Prefix, input.ThreadID, input.RunID))
+		if errors.Is(err, ErrNotFound) {
+			return &server.RunError{
+				Code:    RunErrorCodeNotFound,
+				Message: fmt.Sprintf("no archive of run %s of thread %s", input.RunID, input.ThreadID),
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load archive: %w", err)
+		}
+		seq, err := ReadEvents(archive.Manifest.Format, archive.Events)
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+		return config.replay(ctx, archive.Manifest, seq, emitter)
+	})
+}
+
+// replay emits the events of an archived run
+func (c ReplayConfig) replay(ctx context.Context, manifest Manifest, seq []events.Event, emitter *server.EventEmitter) error {
+	if c.Snapshots && manifest.Input != nil {
+		if len(manifest.Input.Messages) > 0 {
+			if err := emitter.EmitMessagesSnapshot(ctx, manifest.Input.Messages); err != nil {
+				return err
+			}
+		}
+		if manifest.Input.State != nil {
+			if err := emitter.EmitStateSnapshot(ctx, manifest.Input.State); err != nil {
+				return err
+			}
+		}
+	}
+
+	var last *int64
+	for _, event := range seq {
+		if timestamp := event.Timestamp(); timestamp != nil {
+			if last != nil {
+				if err := c.wait(ctx, time.Duration(*timestamp-*last)*time.Millisecond); err != nil {
+					return err
+				}
+			}
+			last = timestamp
+		}
+
+		// The run manager emits the lifecycle events of the replay
+		switch e := event.(type) {
+		case *events.RunStartedEvent:
+			continue
+		case *events.RunFinishedEvent:
+			return nil
+		case *events.RunErrorEvent:
+			runErr := &server.RunError{Message: e.Message}
+			if e.Code != nil {
+				runErr.Code = *e.Code
+			}
+			return runErr
+		}
+		event.SetTimestamp(c.Clock.Now().UnixMilli())
+		if err := emitter.Emit(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wait waits for the replay of a gap of d between two events
+func (c ReplayConfig) wait(ctx context.Context, d time.Duration) error {
+	if c.Instant {
+		return nil
+	}
+	d = time.Duration(float64(d) / c.Speed)
+	if c.MaxDelay > 0 && d > c.MaxDelay {
+		d = c.MaxDelay
+	}
+	if d <= 0 {
+		return nil
+	}
+	timer := c.Clock.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C():
+		return nil
+	}
+}
@@ -0,0 +1,155 @@
+package archive
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/testhelper"
+)
+
+// store archives the events of a run in a new directory, returning its sink
+func store(t *testing.T, input *types.RunAgentInput, seq ...events.Event) *FileSink {
+	t.Helper()
+	sink := NewFileSink(t.TempDir())
+	archiver, err := NewArchiver(Config{Sinks: []Sink{sink}})
+	require.NoError(t, err)
+	rec := newRecorder(FormatJSONL, input, time.Now())
+	for _, event := range seq {
+		require.NoError(t, rec.record(event))
+	}
+	require.NoError(t, archiver.Store(context.Background(), rec.archive(time.Now())))
+	return sink
+}
+
+// at sets the timestamp of event
+func at[E events.Event](event E, ms int64) E {
+	event.SetTimestamp(ms)
+	return event
+}
+
+func TestReplayOverSSE(t *testing.T) {
+	dir := t.TempDir()
+	archiver, err := NewArchiver(Config{Sinks: []Sink{NewFileSink(dir)}, Format: FormatProtobuf})
+	require.NoError(t, err)
+	input := newInput()
+	input.Messages = []types.Message{{ID: "msg-1", Role: types.RoleUser, Content: "count"}}
+	require.NoError(t, server.NewRunManager(statefulAgent, server.RunManagerConfig{Tap: archiver.Tap}).
+		Run(context.Background(), input, server.EmitterFunc(func(context.Context, events.Event) error { return nil })))
+	require.NoError(t, archiver.Close(context.Background()))
+
+	replay := httptest.NewServer(server.NewServer(NewReplayAgent(NewFileSink(dir), ReplayConfig{Instant: true, Snapshots: true}), server.Config{}))
+	defer replay.Close()
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	client := sse.NewClient(sse.Config{Endpoint: replay.URL, Logger: logger})
+	defer client.Close()
+
+	frames, errs, err := client.Stream(sse.StreamOptions{Context: context.Background(), Payload: types.RunAgentInput{ThreadID: "thread-1", RunID: "run-1"}})
+	require.NoError(t, err)
+	seq, err := testhelper.Capture(t, frames, errs)
+	require.NoError(t, err)
+	assert.Equal(t, []events.EventType{
+		events.EventTypeRunStarted,
+		events.EventTypeMessagesSnapshot,
+		events.EventTypeStateSnapshot,
+		events.EventTypeTextMessageStart,
+		events.EventTypeTextMessageContent,
+		events.EventTypeTextMessageEnd,
+		events.EventTypeStateDelta,
+		events.EventTypeRunFinished,
+	}, eventTypes(seq))
+	assert.Equal(t, "count", seq[1].(*events.MessagesSnapshotEvent).Messages[0].Content)
+	assert.Equal(t, "counting", seq[4].(*events.TextMessageContentEvent).Delta)
+	assert.Equal(t, "run-1", seq[7].(*events.RunFinishedEvent).RunID())
+
+	frames, errs, err = client.Stream(sse.StreamOptions{Context: context.Background(), Payload: types.RunAgentInput{ThreadID: "thread-1", RunID: "run-2"}})
+	require.NoError(t, err)
+	seq, err = testhelper.Capture(t, frames, errs)
+	require.NoError(t, err)
+	require.Len(t, seq, 2)
+	assert.Equal(t, RunErrorCodeNotFound, *seq[1].(*events.RunErrorEvent).Code)
+}
+
+func TestReplayTiming(t *testing.T) {
+	sink := store(t, newInput(),
+		at(events.NewRunStartedEvent("thread-1", "run-1"), 10_000),
+		at(events.NewStepStartedEvent("plan"), 11_000),
+		at(events.NewStepFinishedEvent("plan"), 15_000),
+		at(events.NewRunErrorEvent("out of tokens", events.WithErrorCode("QUOTA")), 15_500),
+	)
+	clk := testhelper.NewFakeClock(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+	agent := NewReplayAgent(sink, ReplayConfig{Speed: 2, MaxDelay: time.Second, Clock: clk})
+
+	emitted := make(chan events.Event, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- server.NewRunManager(agent, server.RunManagerConfig{}).Run(context.Background(), newInput(),
+			server.EmitterFunc(func(_ context.Context, event events.Event) error {
+				emitted <- event
+				return nil
+			}))
+	}()
+	next := func() events.Event {
+		t.Helper()
+		select {
+		case event := <-emitted:
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatal("no event replayed")
+			return nil
+		}
+	}
+	// step waits for the replay to wait for d, then lets it go on
+	step := func(d time.Duration) {
+		t.Helper()
+		clk.BlockUntil(1)
+		clk.Advance(d - time.Millisecond)
+		assert.Empty(t, emitted, "replayed before %s", d)
+		clk.Advance(time.Millisecond)
+	}
+
+	assert.Equal(t, events.EventTypeRunStarted, next().Type())
+	step(500 * time.Millisecond)
+	started := next()
+	assert.Equal(t, events.EventTypeStepStarted, started.Type())
+	assert.Equal(t, clk.Now().UnixMilli(), *started.Timestamp(), "events are restamped")
+	step(time.Second) // 2s at double speed, capped
+	assert.Equal(t, events.EventTypeStepFinished, next().Type())
+	step(250 * time.Millisecond)
+
+	runErr, ok := next().(*events.RunErrorEvent)
+	require.True(t, ok)
+	assert.Equal(t, "QUOTA", *runErr.Code)
+	assert.Equal(t, "out of tokens", runErr.Message)
+	require.Error(t, <-done)
+}
+
+func TestReplayRejectsTamperedArchives(t *testing.T) {
+	sink := store(t, newInput(), events.NewRunStartedEvent("thread-1", "run-1"), events.NewRunFinishedEvent("thread-1", "run-1"))
+	require.NoError(t, os.WriteFile(filepath.Join(sink.dir, "thread-1", "run-1", "events.jsonl"), []byte("{}\n"), 0o644))
+
+	_, err := Load(context.Background(), sink, "thread-1/run-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "do not match the manifest")
+
+	var last events.Event
+	err = server.NewRunManager(NewReplayAgent(sink, ReplayConfig{}), server.RunManagerConfig{}).Run(context.Background(), newInput(),
+		server.EmitterFunc(func(_ context.Context, event events.Event) error {
+			last = event
+			return nil
+		}))
+	require.Error(t, err)
+	assert.Equal(t, server.RunErrorCodeAgentError, *last.(*events.RunErrorEvent).Code)
+}
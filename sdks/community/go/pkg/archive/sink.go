@@ -0,0 +1,287 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/internal/cloud"
+)
+
+// Sink stores the objects of archives. Keys are slash-separated paths.
+type Sink interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+}
+
+// Source reads the objects of archives back, returning ErrNotFound for
+// missing ones. The sinks of this package are sources too.
+type Source interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+// SinkFunc adapts an ordinary function to the Sink interface
+type SinkFunc func(ctx context.Context, key string, data []byte, contentType string) error
+
+// Put calls f(ctx, key, data, contentType)
+func (f SinkFunc) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	return f(ctx, key, data, contentType)
+}
+
+// FileSink stores archives in a directory
+type FileSink struct {
+	dir string
+}
+
+// NewFileSink creates a sink writing below dir
+func NewFileSink(dir string) *FileSink {
+	return &FileSink{dir: dir}
+}
+
+// Put writes data to the file named key below the directory of the sink,
+// replacing it atomically
+func (s *FileSink) Put(_ context.Context, key string, data []byte, _ string) error {
+	if !filepath.IsLocal(filepath.FromSlash(key)) {
+		return fmt.Errorf("invalid archive key %q", key)
+	}
+	name := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// Get reads the file named key below the directory of the sink
+func (s *FileSink) Get(_ context.Context, key string) ([]byte, error) {
+	if !filepath.IsLocal(filepath.FromSlash(key)) {
+		return nil, fmt.Errorf("invalid archive key %q", key)
+	}
+	data, err := os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return data, err
+}
+
+// S3Config configures an S3Sink. Empty fields default to the environment
+// variables of the AWS CLI.
+type S3Config struct {
+	// Bucket receives the archives
+	Bucket string `json:"bucket"`
+
+	// Region of the bucket (defaults to AWS_REGION, then AWS_DEFAULT_REGION)
+	Region string `json:"region"`
+
+	// AccessKeyID, SecretAccessKey and SessionToken sign the requests
+	// (default to AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN)
+	AccessKeyID     string `json:"-"`
+	SecretAccessKey string `json:"-"`
+	SessionToken    string `json:"-"`
+
+	// Endpoint of an S3 compatible service, addressed path-style (defaults
+	// to the virtual-hosted endpoint of the bucket on AWS)
+	Endpoint string `json:"endpoint"`
+
+	// Client sends the requests (defaults to http.DefaultClient)
+	Client *http.Client `json:"-"`
+}
+
+// S3Sink stores archives in an S3 bucket
+type S3Sink struct {
+	config S3Config
+	base   string
+	now    func() time.Time
+}
+
+// NewS3Sink creates a sink writing to an S3 bucket
+func NewS3Sink(config S3Config) *S3Sink {
+	if config.Region == "" {
+		config.Region = os.Getenv("AWS_REGION")
+	}
+	if config.Region == "" {
+		config.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if config.AccessKeyID == "" {
+		config.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		config.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		config.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	base := "https://" + config.Bucket + ".s3." + config.Region + ".amazonaws.com"
+	if config.Endpoint != "" {
+		base = strings.TrimSuffix(config.Endpoint, "/") + "/" + config.Bucket
+	}
+	return &S3Sink{config: config, base: base, now: time.Now}
+}
+
+func (s *S3Sink) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	req, err := s.request(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	s.sign(req, data)
+	_, err = send(s.config.Client, req, "s3 put "+key)
+	return err
+}
+
+func (s *S3Sink) Get(ctx context.Context, key string) ([]byte, error) {
+	req, err := s.request(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.sign(req, nil)
+	return send(s.config.Client, req, "s3 get "+key)
+}
+
+// request creates a request for the object key
+func (s *S3Sink) request(ctx context.Context, method, key string, data []byte) (*http.Request, error) {
+	if s.config.Bucket == "" || s.config.Region == "" || s.config.AccessKeyID == "" {
+		return nil, errors.New("s3: no bucket, region or credentials configured")
+	}
+	u, err := url.Parse(s.base)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, "/", key)
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+	return http.NewRequestWithContext(ctx, method, u.String(), body)
+}
+
+// sign signs req, whose body is data
+func (s *S3Sink) sign(req *http.Request, data []byte) {
+	req.Header.Set("X-Amz-Content-Sha256", hexSHA256(data))
+	if s.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.config.SessionToken)
+	}
+	cloud.SignV4(req, data, s.config.AccessKeyID, s.config.SecretAccessKey, s.config.Region, "s3", s.now())
+}
+
+// GCSConfig configures a GCSSink
+type GCSConfig struct {
+	// Bucket receives the archives
+	Bucket string `json:"bucket"`
+
+	// Token returns an OAuth 2.0 access token for the requests. It defaults
+	// to GOOGLE_OAUTH_ACCESS_TOKEN, then to the token of the service account
+	// from the metadata server on Google Cloud.
+	Token func(ctx context.Context) (string, error) `json:"-"`
+
+	// Endpoint of Cloud Storage (defaults to https://storage.googleapis.com)
+	Endpoint string `json:"endpoint"`
+
+	// Client sends the requests (defaults to http.DefaultClient)
+	Client *http.Client `json:"-"`
+}
+
+// GCSSink stores archives in a Google Cloud Storage bucket
+type GCSSink struct {
+	config GCSConfig
+}
+
+// NewGCSSink creates a sink writing to a Cloud Storage bucket
+func NewGCSSink(config GCSConfig) *GCSSink {
+	if config.Endpoint == "" {
+		config.Endpoint = "https://storage.googleapis.com"
+	}
+	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	if config.Token == nil {
+		config.Token = cloud.GoogleToken(config.Client)
+	}
+	return &GCSSink{config: config}
+}
+
+func (s *GCSSink) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if s.config.Bucket == "" {
+		return errors.New("gcs: no bucket configured")
+	}
+	token, err := s.config.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("gcs: failed to get an access token: %w", err)
+	}
+	endpoint := s.config.Endpoint + "/upload/storage/v1/b/" + url.PathEscape(s.config.Bucket) +
+		"/o?" + url.Values{"uploadType": {"media"}, "name": {key}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", contentType)
+	_, err = send(s.config.Client, req, "gcs upload "+key)
+	return err
+}
+
+func (s *GCSSink) Get(ctx context.Context, key string) ([]byte, error) {
+	if s.config.Bucket == "" {
+		return nil, errors.New("gcs: no bucket configured")
+	}
+	token, err := s.config.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gcs: failed to get an access token: %w", err)
+	}
+	endpoint := s.config.Endpoint + "/storage/v1/b/" + url.PathEscape(s.config.Bucket) +
+		"/o/" + url.PathEscape(key) + "?alt=media"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return send(s.config.Client, req, "gcs download "+key)
+}
+
+// send sends req, failing unless it succeeds, and returns the response body
+func send(client *http.Client, req *http.Request, what string) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w", what, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, what)
+	}
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%s: %s %s", what, resp.Status, strings.TrimSpace(string(body)))
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w", what, err)
+	}
+	return data, nil
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,167 @@
+package archive
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var quiet = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// objectStore records the objects uploaded to a fake S3 or GCS
+type objectStore struct {
+	mu      sync.Mutex
+	objects map[string]string
+	types   map[string]string
+}
+
+// get serves the object key
+func (s *objectStore) get(w http.ResponseWriter, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[key]
+	if !ok {
+		http.NotFound(w, nil)
+		return
+	}
+	_, _ = io.WriteString(w, data)
+}
+
+func (s *objectStore) put(r *http.Request, key string) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.objects == nil {
+		s.objects = make(map[string]string)
+		s.types = make(map[string]string)
+	}
+	s.objects[key] = string(body)
+	s.types[key] = r.Header.Get("Content-Type")
+}
+
+func TestFileSink(t *testing.T) {
+	dir := t.TempDir()
+	sink := NewFileSink(dir)
+
+	require.NoError(t, sink.Put(context.Background(), "a/b/events.jsonl", []byte("first"), "application/x-ndjson"))
+	require.NoError(t, sink.Put(context.Background(), "a/b/events.jsonl", []byte("second"), "application/x-ndjson"))
+	data, err := os.ReadFile(filepath.Join(dir, "a", "b", "events.jsonl"))
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+
+	entries, err := os.ReadDir(filepath.Join(dir, "a", "b"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+
+	assert.Error(t, sink.Put(context.Background(), "../escape", nil, ""))
+	assert.Error(t, sink.Put(context.Background(), "/etc/passwd", nil, ""))
+}
+
+func TestS3Sink(t *testing.T) {
+	store := &objectStore{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "session", r.Header.Get("X-Amz-Security-Token"))
+		assert.NotEmpty(t, r.Header.Get("X-Amz-Content-Sha256"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+		assert.Contains(t, r.Header.Get("Authorization"), "/eu-west-1/s3/aws4_request")
+		if r.URL.Path == "/audit/denied/manifest.json" {
+			http.Error(w, "<Error><Code>AccessDenied</Code></Error>", http.StatusForbidden)
+			return
+		}
+		if r.Method == http.MethodGet {
+			store.get(w, r.URL.Path)
+			return
+		}
+		assert.Equal(t, http.MethodPut, r.Method)
+		store.put(r, r.URL.Path)
+	}))
+	defer srv.Close()
+
+	sink := NewS3Sink(S3Config{
+		Bucket:          "audit",
+		Region:          "eu-west-1",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		SessionToken:    "session",
+		Endpoint:        srv.URL,
+	})
+	require.NoError(t, sink.Put(context.Background(), "runs/thread-1/run-1/events.jsonl", []byte("{}\n"), "application/x-ndjson"))
+	assert.Equal(t, map[string]string{"/audit/runs/thread-1/run-1/events.jsonl": "{}\n"}, store.objects)
+	assert.Equal(t, "application/x-ndjson", store.types["/audit/runs/thread-1/run-1/events.jsonl"])
+
+	data, err := sink.Get(context.Background(), "runs/thread-1/run-1/events.jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, "{}\n", string(data))
+	_, err = sink.Get(context.Background(), "runs/thread-1/run-2/events.jsonl")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	err = sink.Put(context.Background(), "denied/manifest.json", []byte("{}"), "application/json")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "AccessDenied")
+}
+
+func TestGCSSink(t *testing.T) {
+	store := &objectStore{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token-1", r.Header.Get("Authorization"))
+		if name, ok := strings.CutPrefix(r.URL.EscapedPath(), "/storage/v1/b/audit/o/"); ok {
+			assert.Equal(t, "media", r.URL.Query().Get("alt"))
+			key, err := url.PathUnescape(name)
+			require.NoError(t, err)
+			store.get(w, key)
+			return
+		}
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/upload/storage/v1/b/audit/o", r.URL.Path)
+		assert.Equal(t, "media", r.URL.Query().Get("uploadType"))
+		store.put(r, r.URL.Query().Get("name"))
+		_, _ = io.WriteString(w, `{"kind":"storage#object"}`)
+	}))
+	defer srv.Close()
+
+	sink := NewGCSSink(GCSConfig{
+		Bucket:   "audit",
+		Endpoint: srv.URL,
+		Token:    func(context.Context) (string, error) { return "token-1", nil },
+	})
+	require.NoError(t, sink.Put(context.Background(), "runs/thread 1/run-1/manifest.json", []byte(`{"version":1}`), "application/json"))
+	assert.Equal(t, map[string]string{"runs/thread 1/run-1/manifest.json": `{"version":1}`}, store.objects)
+	assert.Equal(t, "application/json", store.types["runs/thread 1/run-1/manifest.json"])
+
+	data, err := sink.Get(context.Background(), "runs/thread 1/run-1/manifest.json")
+	require.NoError(t, err)
+	assert.Equal(t, `{"version":1}`, string(data))
+	_, err = sink.Get(context.Background(), "runs/thread 1/run-2/manifest.json")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestStoreWritesManifestLast(t *testing.T) {
+	var mu sync.Mutex
+	var keys []string
+	recording := SinkFunc(func(_ context.Context, key string, _ []byte, _ string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		keys = append(keys, key)
+		return nil
+	})
+	archiver, err := NewArchiver(Config{Sinks: []Sink{recording, recording}, Prefix: "audit", Format: FormatProtobuf})
+	require.NoError(t, err)
+
+	rec := newRecorder(FormatProtobuf, newInput(), archiver.config.Clock.Now())
+	require.NoError(t, archiver.Store(context.Background(), rec.archive(archiver.config.Clock.Now())))
+	assert.Equal(t, []string{
+		"audit/thread-1/run-1/events.pb", "audit/thread-1/run-1/manifest.json",
+		"audit/thread-1/run-1/events.pb", "audit/thread-1/run-1/manifest.json",
+	}, keys)
+}
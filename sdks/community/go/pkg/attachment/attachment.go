@@ -0,0 +1,96 @@
+// Package attachment attaches binary content, such as images or files
+// produced by tools, to the messages and tool calls of a run. An Attacher
+// inlines small content in the attachment event as base64 and uploads larger
+// content to a pluggable BlobStore, referencing it by URL; consumers read
+// either back with Open, which checks the content against its hash.
+package attachment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// defaultInlineLimit is the default size up to which content is inlined
+const defaultInlineLimit = 64 << 10
+
+// ErrNoStore is returned for content over the inline limit, or referenced
+// by URL, when no BlobStore is configured
+var ErrNoStore = errors.New("no blob store configured")
+
+// Config configures an Attacher
+type Config struct {
+	// Store keeps the content over InlineLimit; without one such content is
+	// rejected with ErrNoStore
+	Store BlobStore
+
+	// InlineLimit is the size in bytes up to which content is inlined
+	// (defaults to 64 KiB). Inline content grows by a third once encoded as
+	// base64, which counts towards event size limits.
+	InlineLimit int
+}
+
+// Attacher creates attachments and reads their content back
+type Attacher struct {
+	config Config
+}
+
+// NewAttacher creates an attacher
+func NewAttacher(config Config) *Attacher {
+	if config.InlineLimit <= 0 {
+		config.InlineLimit = defaultInlineLimit
+	}
+	return &Attacher{config: config}
+}
+
+// Attach creates an attachment for data, inlining it or uploading it to
+// the store depending on its size. The caller links the attachment to a
+// message or tool call and sends it with events.NewAttachmentEvent.
+func (a *Attacher) Attach(ctx context.Context, name, mediaType string, data []byte) (events.Attachment, error) {
+	attachment := events.Attachment{
+		ID:        "att-" + uuid.NewString(),
+		Name:      name,
+		MediaType: mediaType,
+		Size:      int64(len(data)),
+		SHA256:    events.ContentHash(data),
+	}
+	if len(data) <= a.config.InlineLimit {
+		attachment.Data = append([]byte{}, data...)
+		return attachment, nil
+	}
+	if a.config.Store == nil {
+		return events.Attachment{}, fmt.Errorf("%w: %d bytes exceed the inline limit of %d", ErrNoStore, len(data), a.config.InlineLimit)
+	}
+	url, err := a.config.Store.Upload(ctx, attachment.SHA256, data, mediaType)
+	if err != nil {
+		return events.Attachment{}, fmt.Errorf("failed to upload attachment %s: %w", attachment.ID, err)
+	}
+	attachment.URL = url
+	return attachment, nil
+}
+
+// Open returns the content of an attachment, downloading it from the store
+// when it is not inline, once checked against its size and hash
+func (a *Attacher) Open(ctx context.Context, attachment events.Attachment) ([]byte, error) {
+	if err := attachment.Validate(); err != nil {
+		return nil, err
+	}
+	if attachment.URL == "" {
+		return attachment.Data, nil
+	}
+	if a.config.Store == nil {
+		return nil, fmt.Errorf("%w: cannot download %s", ErrNoStore, attachment.URL)
+	}
+	data, err := a.config.Store.Download(ctx, attachment.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download attachment %s: %w", attachment.ID, err)
+	}
+	if err := attachment.Verify(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
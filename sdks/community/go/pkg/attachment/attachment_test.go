@@ -0,0 +1,96 @@
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/archive"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+func TestAttachInline(t *testing.T) {
+	ctx := context.Background()
+	attacher := NewAttacher(Config{InlineLimit: 16})
+
+	attachment, err := attacher.Attach(ctx, "dot.png", "image/png", []byte("small"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("small"), attachment.Data)
+	assert.Empty(t, attachment.URL)
+	data, err := attacher.Open(ctx, attachment)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("small"), data)
+
+	_, err = attacher.Attach(ctx, "large.bin", "application/octet-stream", bytes.Repeat([]byte("x"), 17))
+	assert.True(t, errors.Is(err, ErrNoStore), "got %v", err)
+}
+
+func TestAttachOutOfBand(t *testing.T) {
+	stores := map[string]func(t *testing.T) BlobStore{
+		"memory": func(t *testing.T) BlobStore { return NewMemoryStore() },
+		"sink": func(t *testing.T) BlobStore {
+			return NewSinkStore(archive.NewFileSink(t.TempDir()), SinkConfig{})
+		},
+		"sink with base url": func(t *testing.T) BlobStore {
+			return NewSinkStore(archive.NewFileSink(t.TempDir()), SinkConfig{BaseURL: "https://cdn.example.com/"})
+		},
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newStore(t)
+			attacher := NewAttacher(Config{Store: store, InlineLimit: 16})
+			content := bytes.Repeat([]byte("x"), 100)
+
+			attachment, err := attacher.Attach(ctx, "large.bin", "application/octet-stream", content)
+			require.NoError(t, err)
+			assert.Nil(t, attachment.Data)
+			assert.NotEmpty(t, attachment.URL)
+			assert.Equal(t, events.ContentHash(content), attachment.SHA256)
+
+			// Attachments survive the wire
+			encoded, err := events.NewAttachmentEvent(attachment).ToJSON()
+			require.NoError(t, err)
+			decoded, err := events.EventFromJSON(encoded)
+			require.NoError(t, err)
+			received, ok, err := events.AttachmentOf(decoded)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			data, err := attacher.Open(ctx, *received)
+			require.NoError(t, err)
+			assert.Equal(t, content, data)
+
+			_, err = store.Upload(ctx, attachment.SHA256, []byte("tampered"), "application/octet-stream")
+			require.NoError(t, err)
+			_, err = attacher.Open(ctx, *received)
+			assert.True(t, errors.Is(err, events.ErrInvalidAttachment), "got %v", err)
+
+			missing := *received
+			missing.URL = attachment.URL[:len(attachment.URL)-1]
+			_, err = attacher.Open(ctx, missing)
+			assert.True(t, errors.Is(err, ErrNotFound), "got %v", err)
+		})
+	}
+}
+
+func TestSinkStoreURLs(t *testing.T) {
+	ctx := context.Background()
+	store := NewSinkStore(archive.NewFileSink(t.TempDir()), SinkConfig{Prefix: "blobs/", BaseURL: "https://cdn.example.com"})
+
+	url, err := store.Upload(ctx, "abc", []byte("data"), "text/plain")
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn.example.com/blobs/abc", url)
+	data, err := store.Download(ctx, "blob:blobs/abc")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+
+	for _, url := range []string{"https://elsewhere.example.com/blobs/abc", "blob:archives/abc", "memory:abc"} {
+		_, err := store.Download(ctx, url)
+		assert.Error(t, err, url)
+	}
+}
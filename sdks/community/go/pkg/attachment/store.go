@@ -0,0 +1,131 @@
+package attachment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/archive"
+)
+
+// ErrNotFound is returned by stores for missing blobs
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore keeps the content of attachments sent out of band.
+// Implementations are safe for concurrent use.
+type BlobStore interface {
+	// Upload stores data under key, the hex encoded SHA-256 of data, and
+	// returns the URL referencing it
+	Upload(ctx context.Context, key string, data []byte, mediaType string) (string, error)
+
+	// Download returns the data referenced by a URL returned by Upload
+	Download(ctx context.Context, url string) ([]byte, error)
+}
+
+// memoryScheme prefixes the URLs of a MemoryStore
+const memoryScheme = "memory:"
+
+// MemoryStore keeps blobs in memory, e.g. for tests or a single process
+// serving both ends of a run
+type MemoryStore struct {
+	mu    sync.RWMutex
+	blobs map[string][]byte
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{blobs: make(map[string][]byte)}
+}
+
+// Upload stores data under key
+func (s *MemoryStore) Upload(_ context.Context, key string, data []byte, _ string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs[key] = append([]byte{}, data...)
+	return memoryScheme + key, nil
+}
+
+// Download returns the blob referenced by url
+func (s *MemoryStore) Download(_ context.Context, url string) ([]byte, error) {
+	key, ok := strings.CutPrefix(url, memoryScheme)
+	if !ok {
+		return nil, fmt.Errorf("unsupported blob url %q", url)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.blobs[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, url)
+	}
+	return data, nil
+}
+
+// blobScheme prefixes the URLs of a SinkStore without a BaseURL
+const blobScheme = "blob:"
+
+// Sink is where a SinkStore keeps blobs, such as the directory, S3 and GCS
+// sinks of package archive
+type Sink interface {
+	archive.Sink
+	archive.Source
+}
+
+// SinkConfig configures a SinkStore
+type SinkConfig struct {
+	// Prefix is prepended to the keys of the blobs (defaults to
+	// "attachments/")
+	Prefix string
+
+	// BaseURL is the URL the objects of the sink are publicly served from,
+	// e.g. a CDN in front of a bucket. URLs are BaseURL/PREFIX/KEY when it is
+	// set, so that consumers can download blobs with any HTTP client, and
+	// blob:PREFIX/KEY otherwise.
+	BaseURL string
+}
+
+// SinkStore keeps blobs in an archive sink
+type SinkStore struct {
+	sink   Sink
+	config SinkConfig
+}
+
+// NewSinkStore creates a store writing to sink
+func NewSinkStore(sink Sink, config SinkConfig) *SinkStore {
+	if config.Prefix == "" {
+		config.Prefix = "attachments/"
+	}
+	if config.BaseURL != "" {
+		config.BaseURL = strings.TrimSuffix(config.BaseURL, "/") + "/"
+	}
+	return &SinkStore{sink: sink, config: config}
+}
+
+// Upload stores data in the sink
+func (s *SinkStore) Upload(ctx context.Context, key string, data []byte, mediaType string) (string, error) {
+	name := s.config.Prefix + key
+	if err := s.sink.Put(ctx, name, data, mediaType); err != nil {
+		return "", err
+	}
+	if s.config.BaseURL != "" {
+		return s.config.BaseURL + name, nil
+	}
+	return blobScheme + name, nil
+}
+
+// Download reads the blob referenced by url from the sink
+func (s *SinkStore) Download(ctx context.Context, url string) ([]byte, error) {
+	name, ok := strings.CutPrefix(url, blobScheme)
+	if !ok && s.config.BaseURL != "" {
+		name, ok = strings.CutPrefix(url, s.config.BaseURL)
+	}
+	if !ok || !strings.HasPrefix(name, s.config.Prefix) {
+		return nil, fmt.Errorf("unsupported blob url %q", url)
+	}
+	data, err := s.sink.Get(ctx, name)
+	if errors.Is(err, archive.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	return data, err
+}
@@ -0,0 +1,281 @@
+// Package bufpool is the pool of byte buffers shared by the encoders, the
+// SSE writer, the snapshot chunker and the archiver, so that high-throughput
+// services reuse the memory of the events they serialize instead of leaving
+// it to the garbage collector.
+//
+// Buffers are pooled in tiers of capacity. The memory a pool retains is
+// bounded, buffers returned beyond the bound are left to the garbage
+// collector, and Stats reports the hit rate and footprint of the pool.
+// Unlike a sync.Pool, retained buffers are not dropped by garbage
+// collections, so the footprint reported is exact.
+package bufpool
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultTiers are the capacities of the buffers pooled by default
+var DefaultTiers = []int{4 << 10, 64 << 10, 1 << 20}
+
+// defaultMaxRetainedBytes bounds the memory retained by default
+const defaultMaxRetainedBytes = 32 << 20
+
+// Config configures a Pool
+type Config struct {
+	// Tiers are the capacities of the buffers pooled (defaults to
+	// DefaultTiers). Requests are served from the smallest tier fitting
+	// them; buffers larger than the largest tier are not retained.
+	Tiers []int
+
+	// MaxRetainedBytes bounds the total capacity of the buffers the pool
+	// holds (defaults to 32MB)
+	MaxRetainedBytes int64
+
+	// SecureZero zeroes the contents of buffers when they are returned, for
+	// pools handling sensitive data
+	SecureZero bool
+}
+
+// Stats describe the activity of a pool since it was created
+type Stats struct {
+	// Gets counts the buffers requested and Hits those served from the pool
+	Gets uint64
+	Hits uint64
+
+	// Puts counts the buffers returned and Dropped those not retained,
+	// being too small, too large or over MaxRetainedBytes
+	Puts    uint64
+	Dropped uint64
+
+	// RetainedBuffers and RetainedBytes are the number and total capacity
+	// of the buffers the pool holds
+	RetainedBuffers int
+	RetainedBytes   int64
+}
+
+// HitRate returns the fraction of requests served from the pool
+func (s Stats) HitRate() float64 {
+	if s.Gets == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Gets)
+}
+
+// tier holds the buffers of one capacity tier
+type tier struct {
+	size    int
+	buffers []*bytes.Buffer
+	slices  [][]byte
+}
+
+// Pool is a size-tiered, memory-bounded pool of byte buffers and slices.
+// It is safe for concurrent use.
+type Pool struct {
+	config Config
+
+	mu       sync.Mutex
+	tiers    []tier
+	retained int64
+	count    int
+
+	gets    atomic.Uint64
+	hits    atomic.Uint64
+	puts    atomic.Uint64
+	dropped atomic.Uint64
+}
+
+// New creates a pool
+func New(config Config) *Pool {
+	if len(config.Tiers) == 0 {
+		config.Tiers = DefaultTiers
+	}
+	if config.MaxRetainedBytes <= 0 {
+		config.MaxRetainedBytes = defaultMaxRetainedBytes
+	}
+	sizes := append([]int{}, config.Tiers...)
+	sort.Ints(sizes)
+	p := &Pool{config: config}
+	for _, size := range sizes {
+		p.tiers = append(p.tiers, tier{size: size})
+	}
+	return p
+}
+
+var defaultPool = New(Config{SecureZero: true})
+
+// Default returns the pool shared by the packages of the SDK. It zeroes
+// the buffers returned to it, as events may carry sensitive data.
+func Default() *Pool {
+	return defaultPool
+}
+
+// GetBuffer returns an empty buffer with a capacity of at least size.
+// Return it with PutBuffer once its contents are no longer referenced.
+func (p *Pool) GetBuffer(size int) *bytes.Buffer {
+	p.gets.Add(1)
+	i := p.tierFor(size)
+	if i < 0 {
+		return bytes.NewBuffer(make([]byte, 0, size))
+	}
+
+	p.mu.Lock()
+	t := &p.tiers[i]
+	if n := len(t.buffers); n > 0 {
+		buf := t.buffers[n-1]
+		t.buffers[n-1] = nil
+		t.buffers = t.buffers[:n-1]
+		p.release(buf.Cap())
+		p.mu.Unlock()
+		p.hits.Add(1)
+		return buf
+	}
+	p.mu.Unlock()
+	return bytes.NewBuffer(make([]byte, 0, t.size))
+}
+
+// PutBuffer returns buf to the pool
+func (p *Pool) PutBuffer(buf *bytes.Buffer) {
+	if buf == nil {
+		return
+	}
+	p.puts.Add(1)
+	i := p.tierOf(buf.Cap())
+	if i < 0 {
+		p.dropped.Add(1)
+		return
+	}
+	// Zero the whole backing array: the bytes already read and those past
+	// the length, left by larger writes, may be sensitive too
+	buf.Reset()
+	if p.config.SecureZero {
+		clear(buf.AvailableBuffer()[:buf.Cap()])
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.retain(buf.Cap()) {
+		p.dropped.Add(1)
+		return
+	}
+	p.tiers[i].buffers = append(p.tiers[i].buffers, buf)
+}
+
+// GetSlice returns an empty slice with a capacity of at least size. Return
+// it with PutSlice once its contents are no longer referenced.
+func (p *Pool) GetSlice(size int) []byte {
+	p.gets.Add(1)
+	i := p.tierFor(size)
+	if i < 0 {
+		return make([]byte, 0, size)
+	}
+
+	p.mu.Lock()
+	t := &p.tiers[i]
+	if n := len(t.slices); n > 0 {
+		slice := t.slices[n-1]
+		t.slices[n-1] = nil
+		t.slices = t.slices[:n-1]
+		p.release(cap(slice))
+		p.mu.Unlock()
+		p.hits.Add(1)
+		return slice
+	}
+	p.mu.Unlock()
+	return make([]byte, 0, t.size)
+}
+
+// PutSlice returns slice to the pool
+func (p *Pool) PutSlice(slice []byte) {
+	if slice == nil {
+		return
+	}
+	p.puts.Add(1)
+	i := p.tierOf(cap(slice))
+	if i < 0 {
+		p.dropped.Add(1)
+		return
+	}
+	if p.config.SecureZero {
+		clear(slice[:cap(slice)])
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.retain(cap(slice)) {
+		p.dropped.Add(1)
+		return
+	}
+	p.tiers[i].slices = append(p.tiers[i].slices, slice[:0])
+}
+
+// Stats returns the activity of the pool
+func (p *Pool) Stats() Stats {
+	p.mu.Lock()
+	retained, count := p.retained, p.count
+	p.mu.Unlock()
+	return Stats{
+		Gets:            p.gets.Load(),
+		Hits:            p.hits.Load(),
+		Puts:            p.puts.Load(),
+		Dropped:         p.dropped.Load(),
+		RetainedBuffers: count,
+		RetainedBytes:   retained,
+	}
+}
+
+// Reset releases the buffers the pool holds
+func (p *Pool) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i := range p.tiers {
+		p.tiers[i].buffers, p.tiers[i].slices = nil, nil
+	}
+	p.retained, p.count = 0, 0
+}
+
+// tierFor returns the index of the smallest tier serving requests for
+// size bytes, or -1 when size exceeds the largest tier
+func (p *Pool) tierFor(size int) int {
+	for i := range p.tiers {
+		if size <= p.tiers[i].size {
+			return i
+		}
+	}
+	return -1
+}
+
+// tierOf returns the index of the largest tier a buffer of capacity can
+// serve, or -1 when it is too small for any tier or larger than the
+// largest
+func (p *Pool) tierOf(capacity int) int {
+	if capacity > p.tiers[len(p.tiers)-1].size {
+		return -1
+	}
+	for i := len(p.tiers) - 1; i >= 0; i-- {
+		if capacity >= p.tiers[i].size {
+			return i
+		}
+	}
+	return -1
+}
+
+// retain accounts for a buffer of capacity joining the pool, reporting
+// whether it fits within MaxRetainedBytes. p.mu must be held.
+func (p *Pool) retain(capacity int) bool {
+	if p.retained+int64(capacity) > p.config.MaxRetainedBytes {
+		return false
+	}
+	p.retained += int64(capacity)
+	p.count++
+	return true
+}
+
+// release accounts for a buffer of capacity leaving the pool. p.mu must be
+// held.
+func (p *Pool) release(capacity int) {
+	p.retained -= int64(capacity)
+	p.count--
+}
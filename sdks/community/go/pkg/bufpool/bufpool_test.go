@@ -0,0 +1,116 @@
+package bufpool
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolTiers(t *testing.T) {
+	pool := New(Config{Tiers: []int{1024, 64}})
+
+	buf := pool.GetBuffer(100)
+	assert.Equal(t, 1024, buf.Cap(), "requests are served from the smallest tier fitting them")
+	buf.WriteString("hello")
+	pool.PutBuffer(buf)
+
+	reused := pool.GetBuffer(512)
+	assert.Same(t, buf, reused)
+	assert.Zero(t, reused.Len(), "buffers are reset")
+	assert.Equal(t, 64, pool.GetBuffer(10).Cap(), "the buffer was taken from the pool")
+
+	large := pool.GetBuffer(4096)
+	assert.GreaterOrEqual(t, large.Cap(), 4096)
+	pool.PutBuffer(large)
+	pool.PutSlice(make([]byte, 10))
+
+	stats := pool.Stats()
+	assert.Equal(t, uint64(4), stats.Gets)
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(3), stats.Puts)
+	assert.Equal(t, uint64(2), stats.Dropped, "buffers too large or too small for a tier are dropped")
+	assert.Equal(t, 0.25, stats.HitRate())
+	assert.Zero(t, stats.RetainedBytes)
+}
+
+func TestPoolGrownBuffers(t *testing.T) {
+	pool := New(Config{Tiers: []int{64, 1024}})
+
+	buf := pool.GetBuffer(10)
+	buf.Write(make([]byte, 500))
+	pool.PutBuffer(buf)
+	assert.Equal(t, int64(buf.Cap()), pool.Stats().RetainedBytes)
+
+	assert.Same(t, buf, pool.GetBuffer(64), "grown buffers serve the largest tier they fit")
+	assert.NotSame(t, buf, pool.GetBuffer(1024))
+}
+
+func TestPoolMaxRetainedBytes(t *testing.T) {
+	pool := New(Config{Tiers: []int{1024}, MaxRetainedBytes: 2048})
+	for range 3 {
+		pool.PutSlice(make([]byte, 0, 1024))
+	}
+
+	stats := pool.Stats()
+	assert.Equal(t, 2, stats.RetainedBuffers)
+	assert.Equal(t, int64(2048), stats.RetainedBytes)
+	assert.Equal(t, uint64(1), stats.Dropped)
+
+	pool.GetSlice(100)
+	assert.Equal(t, int64(1024), pool.Stats().RetainedBytes)
+
+	pool.Reset()
+	assert.Zero(t, pool.Stats().RetainedBuffers)
+	assert.Equal(t, uint64(1), pool.Stats().Hits, "counters survive Reset")
+}
+
+func TestPoolSecureZero(t *testing.T) {
+	pool := New(Config{Tiers: []int{16}, SecureZero: true})
+	slice := append(pool.GetSlice(16), "secret"...)
+	pool.PutSlice(slice)
+	assert.Equal(t, make([]byte, 6), slice[:6])
+
+	buf := pool.GetBuffer(16)
+	buf.WriteString("secret")
+	contents := buf.Bytes()
+	pool.PutBuffer(buf)
+	assert.Equal(t, make([]byte, 6), contents)
+
+	// Bytes already read, and those past the length of a truncated slice,
+	// are zeroed as well
+	buf = pool.GetBuffer(16)
+	buf.WriteString("secret password")
+	buf.Next(7)
+	pool.PutBuffer(buf)
+	buf = pool.GetBuffer(16)
+	assert.Equal(t, make([]byte, buf.Cap()), buf.AvailableBuffer()[:buf.Cap()])
+	slice = append(pool.GetSlice(16), "secret password"...)
+	pool.PutSlice(slice[:6])
+	slice = pool.GetSlice(16)
+	assert.Equal(t, make([]byte, cap(slice)), slice[:cap(slice)])
+}
+
+func TestPoolConcurrency(t *testing.T) {
+	pool := New(Config{})
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 100 {
+				buf := pool.GetBuffer(i * 100)
+				buf.WriteString("event")
+				pool.PutBuffer(buf)
+				pool.PutSlice(pool.GetSlice(i * 1000))
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := pool.Stats()
+	require.Equal(t, uint64(1600), stats.Gets)
+	assert.Greater(t, stats.HitRate(), 0.0)
+	assert.LessOrEqual(t, stats.RetainedBytes, int64(defaultMaxRetainedBytes))
+}
@@ -0,0 +1,486 @@
+// Package client is a high-level client for AG-UI agents. It composes the
+// SSE transport of package sse, an event decoder, protocol validation and
+// session persistence behind a single Client, so that applications start
+// runs and read their events without wiring those packages together:
+//
+//	c := client.New(client.Config{
+//		Transport: sse.Config{Endpoint: "http://localhost:8080/agent"},
+//		Sessions:  store,
+//		Validate:  true,
+//	})
+//	defer c.Close()
+//	run, err := c.StartRun(ctx, &types.RunAgentInput{ThreadID: "thread-1", Messages: messages})
+//	for event := range run.Events() {
+//		...
+//	}
+//	if err := run.Err(); err != nil {
+//		...
+//	}
+//	next, err := run.SendMessage(ctx, "And then?")
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/deadletter"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding/json"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/logging"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/session"
+)
+
+const defaultBufferSize = 100
+
+var (
+	// ErrClosed is returned when starting a run on a closed Client
+	ErrClosed = errors.New("client closed")
+
+	// ErrInvalidEvent ends the runs streaming an event that fails
+	// validation, see Config.Validate
+	ErrInvalidEvent = errors.New("invalid event")
+)
+
+// RunError is the error of a run ended by a RUN_ERROR event
+type RunError struct {
+	Code    string
+	Message string
+}
+
+func (e *RunError) Error() string {
+	if e.Code == "" {
+		return e.Message
+	}
+	return e.Code + ": " + e.Message
+}
+
+// Is reports cancelled runs as sse.ErrRunCancelled
+func (e *RunError) Is(target error) bool {
+	return target == sse.ErrRunCancelled && e.Code == events.RunErrorCodeCancelled
+}
+
+// Config configures a Client
+type Config struct {
+	// Transport configures the SSE connections to the agent
+	Transport sse.Config
+
+	// Decoder decodes the events of the streams (defaults to JSON)
+	Decoder encoding.Decoder
+
+	// Validate checks the events of each run against the protocol, ending
+	// the run with ErrInvalidEvent on the first invalid one
+	Validate bool
+
+	// ValidatorOptions configure the validators of Validate
+	ValidatorOptions []events.ValidatorOption
+
+	// Sessions, when set, persists the thread of each run: runs carry the
+	// history of their thread, followed by the messages of their input, and
+	// the messages and state they produce are saved once they finish
+	Sessions session.Store
+
+	// DeadLetters captures the frames that fail decoding, which are
+	// otherwise dropped (nil = dropped)
+	DeadLetters *deadletter.Queue
+
+	// BufferSize is the number of events buffered for each run (defaults
+	// to 100). A run whose events are not consumed holds up its stream once
+	// the buffer is full.
+	BufferSize int
+
+	// StreamOptions are the options of the streams of every run, e.g.
+	// Heartbeat or Extensions; their Context and Payload are ignored
+	StreamOptions sse.StreamOptions
+
+	// Resume resumes the runs whose stream fails before they end, giving
+	// each run its own StreamOptions.IdempotencyKey unless one is set
+	Resume ResumeConfig
+}
+
+// Client starts runs of an agent. It is safe for concurrent use.
+type Client struct {
+	config    Config
+	transport *sse.Client
+	logger    *logrus.Logger
+
+	mu     sync.Mutex
+	runs   map[*RunHandle]struct{}
+	closed bool
+	wg     sync.WaitGroup
+
+	resumes resumeCounters
+}
+
+// New creates a Client
+func New(config Config) *Client {
+	if config.Decoder == nil {
+		config.Decoder = json.NewDecoder()
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = defaultBufferSize
+	}
+	if config.Resume.InitialDelay <= 0 {
+		config.Resume.InitialDelay = defaultResumeInitialDelay
+	}
+	if config.Resume.MaxDelay <= 0 {
+		config.Resume.MaxDelay = defaultResumeMaxDelay
+	}
+	return &Client{
+		config:    config,
+		transport: sse.NewClient(config.Transport),
+		logger:    config.Transport.Logger,
+		runs:      make(map[*RunHandle]struct{}),
+	}
+}
+
+// Transport returns the SSE client the runs are streamed with, e.g. to
+// query the capabilities of the agent
+func (c *Client) Transport() *sse.Client {
+	return c.transport
+}
+
+// StartRun starts a run with input, generating its thread and run IDs when
+// it has none. With Config.Sessions the messages of input are first added
+// to the history of the thread, replacing those with the same ID, and the
+// run carries the whole history. The run goes on until it ends, ctx is done
+// or it is cancelled.
+func (c *Client) StartRun(ctx context.Context, input *types.RunAgentInput) (*RunHandle, error) {
+	payload := *input
+	if payload.ThreadID == "" {
+		payload.ThreadID = events.GenerateThreadID()
+	}
+	if payload.RunID == "" {
+		payload.RunID = events.GenerateRunID()
+	}
+	if c.config.Sessions != nil {
+		thread, err := c.config.Sessions.Update(ctx, payload.ThreadID, func(s *session.Session) error {
+			s.AppendMessages(payload.Messages...)
+			if payload.State != nil {
+				s.State = payload.State
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load session %s: %w", payload.ThreadID, err)
+		}
+		payload.Messages, payload.State = thread.RunInput(payload.RunID).Messages, thread.State
+	}
+	shim, err := events.NewProtocolShim(payload.ProtocolVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, ErrClosed
+	}
+	streamCtx, cancel := context.WithCancelCause(ctx)
+	opts := c.config.StreamOptions
+	opts.Context, opts.Payload = streamCtx, payload
+	if c.config.Resume.MaxAttempts > 0 && opts.IdempotencyKey == "" {
+		opts.IdempotencyKey = sse.NewIdempotencyKey()
+	}
+	frames, errs, err := c.transport.Stream(opts)
+	if err != nil {
+		cancel(nil)
+		return nil, err
+	}
+
+	run := &RunHandle{
+		client:     c,
+		input:      payload,
+		cancel:     cancel,
+		events:     make(chan events.Event, c.config.BufferSize),
+		done:       make(chan struct{}),
+		transcript: newTranscript(payload.Messages, payload.State),
+		replay:     replay{runID: payload.RunID},
+	}
+	if c.config.Validate {
+		run.validator = events.NewEventValidator(c.config.ValidatorOptions...)
+	}
+	c.runs[run] = struct{}{}
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer cancel(nil)
+		run.drive(streamCtx, shim, opts, frames, errs)
+		c.mu.Lock()
+		delete(c.runs, run)
+		c.mu.Unlock()
+	}()
+	return run, nil
+}
+
+// Close cancels the runs in progress by dropping their streams, waits for
+// them to end and closes the transport
+func (c *Client) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	for run := range c.runs {
+		run.cancel(sse.ErrRunCancelled)
+	}
+	c.mu.Unlock()
+	c.wg.Wait()
+	return c.transport.Close()
+}
+
+// RunHandle is a run started by a Client
+type RunHandle struct {
+	client    *Client
+	input     types.RunAgentInput
+	cancel    context.CancelCauseFunc
+	events    chan events.Event
+	done      chan struct{}
+	validator *events.EventValidator
+
+	// transcript and the state of the stream are only accessed by drive
+	// until done is closed
+	transcript *transcript
+	err        error
+	finished   bool
+	last       sse.Frame
+	replay     replay
+
+	resumes resumeCounters
+}
+
+// ID returns the ID of the run
+func (r *RunHandle) ID() string {
+	return r.input.RunID
+}
+
+// ThreadID returns the ID of the thread of the run
+func (r *RunHandle) ThreadID() string {
+	return r.input.ThreadID
+}
+
+// Input returns the input the run was started with
+func (r *RunHandle) Input() types.RunAgentInput {
+	return r.input
+}
+
+// Events returns the events of the run, upgraded to events.ProtocolVersion.
+// The events of resumed runs are delivered once (see ResumeConfig). The
+// channel is closed once the run has ended.
+func (r *RunHandle) Events() <-chan events.Event {
+	return r.events
+}
+
+// Done is closed once the run has ended and its session, if any, is saved
+func (r *RunHandle) Done() <-chan struct{} {
+	return r.done
+}
+
+// Err returns why the run failed once it has ended: a *RunError for runs
+// ended by RUN_ERROR, sse.ErrRunCancelled for cancelled runs,
+// ErrInvalidEvent, sse.ErrRunIncomplete or the error of the stream. It is
+// nil for runs that finished, and while the run is in progress.
+func (r *RunHandle) Err() error {
+	select {
+	case <-r.done:
+		return r.err
+	default:
+		return nil
+	}
+}
+
+// Wait blocks until the run has ended, or ctx is done, and returns Err
+func (r *RunHandle) Wait(ctx context.Context) error {
+	select {
+	case <-r.done:
+		return r.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Messages returns the messages of the thread once the run has ended: those
+// of its input followed by those it produced. It is nil while the run is in
+// progress.
+func (r *RunHandle) Messages() []types.Message {
+	select {
+	case <-r.done:
+		return slices.Clone(r.transcript.messages())
+	default:
+		return nil
+	}
+}
+
+// State returns the agent state once the run has ended, see Messages
+func (r *RunHandle) State() any {
+	select {
+	case <-r.done:
+		return r.transcript.state
+	default:
+		return nil
+	}
+}
+
+// Cancel cancels the run on its server (see sse.Client.Cancel). When the
+// server cannot cancel it the stream of the run is dropped instead. Runs
+// that have ended are left as they are.
+func (r *RunHandle) Cancel(ctx context.Context) error {
+	select {
+	case <-r.done:
+		return nil
+	default:
+	}
+	if err := r.client.transport.Cancel(ctx, r.ID()); err != nil {
+		if r.client.logger != nil {
+			r.client.logger.WithError(err).WithField(logging.KeyRunID, r.ID()).Warn("Server did not cancel the run, dropping its stream")
+		}
+		r.cancel(sse.ErrRunCancelled)
+	}
+	return nil
+}
+
+// SendMessage continues the thread of the run with a user message, in a new
+// run started once this one has ended: AG-UI runs take their messages up
+// front. The new run carries the messages and state of this run when it
+// finished, or those it was started with otherwise, and the tools and
+// context of its input.
+func (r *RunHandle) SendMessage(ctx context.Context, content string) (*RunHandle, error) {
+	if err := r.Wait(ctx); err != nil && ctx.Err() != nil {
+		return nil, err
+	}
+	input := r.input
+	input.RunID = ""
+	if r.err == nil {
+		input.Messages, input.State = r.transcript.messages(), r.transcript.state
+	}
+	input.Messages = append(input.Messages[:len(input.Messages):len(input.Messages)], types.Message{
+		ID:      events.GenerateMessageID(),
+		Role:    types.RoleUser,
+		Content: content,
+	})
+	return r.client.StartRun(ctx, &input)
+}
+
+// drive reads the stream of the run, resuming it as configured, until the
+// run ends, then saves the session of the run if it finished
+func (r *RunHandle) drive(ctx context.Context, shim *events.ProtocolShim, opts sse.StreamOptions, frames <-chan sse.Frame, errs <-chan error) {
+	defer close(r.done)
+	defer close(r.events)
+
+	streamErr := r.read(ctx, shim, frames, errs)
+	if r.client.config.Resume.MaxAttempts > 0 && r.interruption(ctx, streamErr) != nil {
+		streamErr = r.resume(ctx, shim, opts, streamErr)
+	}
+
+	switch cause := context.Cause(ctx); {
+	case r.err != nil:
+	case r.finished:
+	case errors.Is(cause, sse.ErrRunCancelled), errors.Is(cause, context.Canceled):
+		r.err = cause
+	case streamErr != nil:
+		r.err = streamErr
+	default:
+		r.err = sse.ErrRunIncomplete
+	}
+	if r.err != nil || r.client.config.Sessions == nil {
+		return
+	}
+	// The stream context may be done once the run finished; saving the
+	// session is not part of the run
+	ctx = context.WithoutCancel(ctx)
+	_, err := r.client.config.Sessions.Update(ctx, r.ThreadID(), func(s *session.Session) error {
+		for _, id := range r.transcript.deleted {
+			s.DeleteMessage(id)
+		}
+		s.AppendMessages(r.transcript.messages()...)
+		s.State = r.transcript.state
+		return nil
+	})
+	if err != nil {
+		r.err = fmt.Errorf("failed to save session %s: %w", r.ThreadID(), err)
+	}
+}
+
+// read delivers the events of a stream of the run until it closes and
+// returns the error it failed with
+func (r *RunHandle) read(ctx context.Context, shim *events.ProtocolShim, frames <-chan sse.Frame, errs <-chan error) error {
+	var streamErr error
+	for frames != nil {
+		select {
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+			} else if err != nil && streamErr == nil {
+				streamErr = err
+			}
+			continue
+		case frame, ok := <-frames:
+			if !ok {
+				frames = nil
+				continue
+			}
+			if r.err != nil {
+				continue
+			}
+			r.last = frame
+			event, err := r.client.config.Decoder.Decode(ctx, frame.Data)
+			if err == nil {
+				if _, ok := events.HeartbeatInterval(event); ok {
+					continue
+				}
+			}
+			if r.replay.duplicate(frame) {
+				r.count(func(c *resumeCounters) *atomic.Int64 { return &c.duplicates })
+				continue
+			}
+			if err != nil {
+				if r.client.logger != nil {
+					r.client.logger.WithError(err).WithField(logging.KeyRunID, r.ID()).Warn("Dropping undecodable event")
+				}
+				letter := deadletter.NewLetter("sse", frame.Data, err)
+				letter.ThreadID, letter.RunID = r.ThreadID(), r.ID()
+				r.client.config.DeadLetters.Capture(ctx, letter)
+				continue
+			}
+			event = shim.Upgrade(event)
+			if r.validator != nil {
+				if err := r.validator.ValidateEvent(event); err != nil {
+					r.err = fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+					r.cancel(r.err)
+					continue
+				}
+			}
+			if err := r.transcript.apply(event); err != nil && r.client.logger != nil {
+				r.client.logger.WithError(err).WithField(logging.KeyRunID, r.ID()).Warn("Failed to apply event to the transcript")
+			}
+			switch e := event.(type) {
+			case *events.RunFinishedEvent:
+				r.finished = r.finished || e.RunID() == "" || e.RunID() == r.ID()
+			case *events.RunErrorEvent:
+				if e.RunID() == "" || e.RunID() == r.ID() {
+					r.err = &RunError{Code: deref(e.Code), Message: e.Message}
+				}
+			}
+			select {
+			case r.events <- event:
+			case <-ctx.Done():
+			}
+		}
+	}
+	if streamErr == nil && errs != nil {
+		streamErr = <-errs
+	}
+	return streamErr
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
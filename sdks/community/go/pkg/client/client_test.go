@@ -0,0 +1,237 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/session"
+)
+
+// newAgentServer serves agent over SSE
+func newAgentServer(t *testing.T, agent server.Agent) *httptest.Server {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	srv := httptest.NewServer(server.NewServer(agent, server.Config{RunManagerConfig: server.RunManagerConfig{Logger: logger}}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestClient(t *testing.T, endpoint string, config Config) *Client {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	config.Transport.Endpoint, config.Transport.Logger = endpoint, logger
+	c := New(config)
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+// drain returns the types of the events of run once it has ended
+func drain(t *testing.T, run *RunHandle) []events.EventType {
+	t.Helper()
+	var types []events.EventType
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-run.Events():
+			if !ok {
+				<-run.Done()
+				return types
+			}
+			types = append(types, event.Type())
+		case <-timeout:
+			t.Fatalf("run %s did not end", run.ID())
+		}
+	}
+}
+
+// echo answers the last message of its input, counting the turns in its
+// state
+func echo(ctx context.Context, input *types.RunAgentInput, emitter *server.EventEmitter) error {
+	last := input.Messages[len(input.Messages)-1]
+	messageID, err := emitter.StartTextMessage(ctx, "assistant")
+	if err != nil {
+		return err
+	}
+	if err := emitter.EmitContent(ctx, messageID, "You said: "); err != nil {
+		return err
+	}
+	if err := emitter.EmitContent(ctx, messageID, fmt.Sprint(last.Content)); err != nil {
+		return err
+	}
+	if err := emitter.EndTextMessage(ctx, messageID); err != nil {
+		return err
+	}
+	if input.State == nil {
+		return emitter.EmitStateSnapshot(ctx, map[string]any{"turns": len(input.Messages)})
+	}
+	return emitter.EmitStateDelta(ctx, []events.JSONPatchOperation{{Op: "replace", Path: "/turns", Value: len(input.Messages)}})
+}
+
+func TestClientRuns(t *testing.T) {
+	srv := newAgentServer(t, server.AgentFunc(echo))
+	store := session.NewMemoryStore(session.MemoryConfig{})
+	c := newTestClient(t, srv.URL, Config{Sessions: store, Validate: true})
+	ctx := context.Background()
+
+	run, err := c.StartRun(ctx, &types.RunAgentInput{
+		ThreadID: "thread-1",
+		Messages: []types.Message{{ID: "user-1", Role: types.RoleUser, Content: "hello"}},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, run.ID())
+	assert.Equal(t, []events.EventType{
+		events.EventTypeRunStarted,
+		events.EventTypeTextMessageStart,
+		events.EventTypeTextMessageContent,
+		events.EventTypeTextMessageContent,
+		events.EventTypeTextMessageEnd,
+		events.EventTypeStateSnapshot,
+		events.EventTypeRunFinished,
+	}, drain(t, run))
+	require.NoError(t, run.Err())
+	messages := run.Messages()
+	require.Len(t, messages, 2)
+	assert.Equal(t, types.RoleAssistant, messages[1].Role)
+	assert.Equal(t, "You said: hello", messages[1].Content)
+
+	next, err := run.SendMessage(ctx, "again")
+	require.NoError(t, err)
+	assert.Equal(t, "thread-1", next.ThreadID())
+	assert.NotEqual(t, run.ID(), next.ID())
+	drain(t, next)
+	require.NoError(t, next.Err())
+	require.Len(t, next.Messages(), 4)
+	assert.Equal(t, "You said: again", next.Messages()[3].Content)
+	assert.Equal(t, map[string]any{"turns": float64(3)}, next.State())
+
+	thread, err := store.Get(ctx, "thread-1")
+	require.NoError(t, err)
+	assert.Equal(t, next.Messages(), thread.Messages)
+	assert.Equal(t, next.State(), thread.State)
+
+	// A new run of the thread carries its history
+	run, err = c.StartRun(ctx, &types.RunAgentInput{
+		ThreadID: "thread-1",
+		Messages: []types.Message{{ID: "user-3", Role: types.RoleUser, Content: "once more"}},
+	})
+	require.NoError(t, err)
+	assert.Len(t, run.Input().Messages, 5)
+	drain(t, run)
+	require.NoError(t, run.Err())
+	assert.Equal(t, map[string]any{"turns": float64(5)}, run.State())
+}
+
+func TestClientRunErrors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("RunError", func(t *testing.T) {
+		srv := newAgentServer(t, server.AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, emitter *server.EventEmitter) error {
+			if _, err := emitter.StartTextMessage(ctx, "assistant"); err != nil {
+				return err
+			}
+			return &server.RunError{Code: "QUOTA", Message: "out of quota"}
+		}))
+		store := session.NewMemoryStore(session.MemoryConfig{})
+		c := newTestClient(t, srv.URL, Config{Sessions: store})
+
+		run, err := c.StartRun(ctx, &types.RunAgentInput{
+			ThreadID: "thread-1",
+			Messages: []types.Message{{ID: "user-1", Role: types.RoleUser, Content: "hello"}},
+		})
+		require.NoError(t, err)
+		drain(t, run)
+		var runErr *RunError
+		require.ErrorAs(t, run.Err(), &runErr)
+		assert.Equal(t, "QUOTA", runErr.Code)
+
+		thread, err := store.Get(ctx, "thread-1")
+		require.NoError(t, err)
+		assert.Len(t, thread.Messages, 1, "the output of failed runs is not saved")
+	})
+
+	t.Run("InvalidEvent", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			for _, event := range []events.Event{
+				events.NewRunStartedEvent("thread-1", "run-1"),
+				events.NewTextMessageContentEvent("msg-1", "orphan"),
+				events.NewRunFinishedEvent("thread-1", "run-1"),
+			} {
+				data, err := event.ToJSON()
+				require.NoError(t, err)
+				fmt.Fprintf(w, "data: %s\n\n", data)
+			}
+		}))
+		defer srv.Close()
+		input := &types.RunAgentInput{ThreadID: "thread-1", RunID: "run-1"}
+
+		run, err := newTestClient(t, srv.URL, Config{Validate: true}).StartRun(ctx, input)
+		require.NoError(t, err)
+		assert.Equal(t, []events.EventType{events.EventTypeRunStarted}, drain(t, run))
+		assert.ErrorIs(t, run.Err(), ErrInvalidEvent)
+
+		run, err = newTestClient(t, srv.URL, Config{}).StartRun(ctx, input)
+		require.NoError(t, err)
+		assert.Len(t, drain(t, run), 3)
+		assert.NoError(t, run.Err())
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		started := make(chan struct{})
+		srv := newAgentServer(t, server.AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, _ *server.EventEmitter) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		}))
+		run, err := newTestClient(t, srv.URL, Config{}).StartRun(ctx, &types.RunAgentInput{})
+		require.NoError(t, err)
+		<-started
+		require.NoError(t, run.Cancel(ctx))
+		drain(t, run)
+		assert.ErrorIs(t, run.Err(), sse.ErrRunCancelled)
+	})
+
+	t.Run("Closed", func(t *testing.T) {
+		c := newTestClient(t, "http://127.0.0.1:0", Config{})
+		require.NoError(t, c.Close())
+		_, err := c.StartRun(ctx, &types.RunAgentInput{})
+		assert.ErrorIs(t, err, ErrClosed)
+	})
+}
+
+func TestClientConcurrentRuns(t *testing.T) {
+	srv := newAgentServer(t, server.AgentFunc(echo))
+	c := newTestClient(t, srv.URL, Config{Validate: true})
+
+	var wg sync.WaitGroup
+	for i := range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			run, err := c.StartRun(context.Background(), &types.RunAgentInput{
+				Messages: []types.Message{{ID: fmt.Sprint("user-", i), Role: types.RoleUser, Content: fmt.Sprint(i)}},
+			})
+			if !assert.NoError(t, err) {
+				return
+			}
+			drain(t, run)
+			assert.NoError(t, run.Err())
+			assert.Equal(t, fmt.Sprint("You said: ", i), run.Messages()[1].Content)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,226 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	agerrors "github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/errors"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/logging"
+)
+
+const (
+	defaultResumeInitialDelay = 100 * time.Millisecond
+	defaultResumeMaxDelay     = 5 * time.Second
+)
+
+// ResumeConfig configures the resumption of the runs whose stream fails
+// before they end. The stream is opened again with the idempotency key of
+// the run, from the continuity token of the last event received (see
+// sse.StreamOptions.Resume), and the events replayed that were already
+// delivered are dropped, so that the events of the run read as a single
+// stream. Resuming requires servers supporting idempotency keys, see
+// server.IdempotencyConfig.
+//
+// Dropped connections, stalled streams and 408, 429 and 5xx statuses are
+// resumed; cancelled runs, runs the server no longer has (410 Gone) and
+// other statuses are not.
+type ResumeConfig struct {
+	// MaxAttempts is the number of times a run is resumed before it fails
+	// with the error of its stream (0 = runs are not resumed)
+	MaxAttempts int
+
+	// InitialDelay is the delay before the first attempt to resume a run,
+	// doubled for each following one (defaults to 100ms)
+	InitialDelay time.Duration
+
+	// MaxDelay caps the delay between attempts (defaults to 5s)
+	MaxDelay time.Duration
+
+	// Jitter randomizes the delays by up to this fraction of them (0.0 to
+	// 1.0), so that the runs dropped together are not resumed together
+	Jitter float64
+
+	// Clock times the delays (defaults to the real clock)
+	Clock clock.Clock
+
+	// OnResume, when set, is called before each attempt to resume a run,
+	// with the number of the attempt and the error of the stream
+	OnResume func(runID string, attempt int, err error)
+}
+
+// ResumeStats counts the resumptions of runs, see ResumeConfig
+type ResumeStats struct {
+	// Attempts counts the attempts to resume a run
+	Attempts int64
+
+	// Reconnects counts the attempts that opened a stream again
+	Reconnects int64
+
+	// Recovered counts the resumed runs that ended with RUN_FINISHED or
+	// RUN_ERROR
+	Recovered int64
+
+	// Failed counts the resumed runs that failed with the error of their
+	// stream once resuming them gave up
+	Failed int64
+
+	// Duplicates counts the replayed events dropped as already delivered
+	Duplicates int64
+}
+
+// resumeCounters backs ResumeStats
+type resumeCounters struct {
+	attempts   atomic.Int64
+	reconnects atomic.Int64
+	recovered  atomic.Int64
+	failed     atomic.Int64
+	duplicates atomic.Int64
+}
+
+func (c *resumeCounters) stats() ResumeStats {
+	return ResumeStats{
+		Attempts:   c.attempts.Load(),
+		Reconnects: c.reconnects.Load(),
+		Recovered:  c.recovered.Load(),
+		Failed:     c.failed.Load(),
+		Duplicates: c.duplicates.Load(),
+	}
+}
+
+// ResumeStats returns the resumptions of the runs of the client
+func (c *Client) ResumeStats() ResumeStats {
+	return c.resumes.stats()
+}
+
+// ResumeStats returns the resumptions of the run
+func (r *RunHandle) ResumeStats() ResumeStats {
+	return r.resumes.stats()
+}
+
+// count increments a counter of the run and of its client
+func (r *RunHandle) count(counter func(*resumeCounters) *atomic.Int64) {
+	counter(&r.resumes).Add(1)
+	counter(&r.client.resumes).Add(1)
+}
+
+// replay tells apart the events a resumed stream replays that were
+// delivered before it was resumed
+type replay struct {
+	runID string
+
+	// seq is the sequence number of the last event received with a
+	// continuity token, resumedAt its value when the stream was resumed
+	seq, resumedAt int
+
+	// received counts the events of the stream, skip those at its start
+	// that were delivered by the previous streams of the run
+	received, skip int
+}
+
+// resume starts a stream replaying the run after the last continuity
+// token received, or from the start when there is none
+func (p *replay) resume(fromToken bool) {
+	p.resumedAt = p.seq
+	if fromToken {
+		p.skip = 0
+	} else {
+		p.skip = max(p.skip, p.received)
+	}
+	p.received = 0
+}
+
+// duplicate reports whether the next event of the stream, received in
+// frame, was already delivered. Events stamped with a continuity token are
+// told by their sequence number, others by their position.
+func (p *replay) duplicate(frame sse.Frame) bool {
+	p.received++
+	if token, err := events.ParseContinuityToken(frame.ID); err == nil && token.RunID == p.runID {
+		p.seq = max(p.seq, token.Seq)
+		return token.Seq <= p.resumedAt
+	}
+	return p.received <= p.skip
+}
+
+// interruption returns the error of a stream that ended before the run
+// did, nil when the run ended or was cancelled
+func (r *RunHandle) interruption(ctx context.Context, streamErr error) error {
+	switch {
+	case r.err != nil, r.finished, ctx.Err() != nil:
+		return nil
+	case streamErr == nil:
+		return sse.ErrRunIncomplete
+	}
+	return streamErr
+}
+
+// resumable reports whether resuming a run may get past the error of its
+// stream
+func resumable(err error) bool {
+	var statusErr *sse.StatusError
+	switch {
+	case errors.As(err, &statusErr):
+		return statusErr.Code == http.StatusRequestTimeout ||
+			statusErr.Code == http.StatusTooManyRequests ||
+			statusErr.Code >= http.StatusInternalServerError
+	case errors.Is(err, sse.ErrRunCancelled), errors.Is(err, context.Canceled), errors.Is(err, sse.ErrFrameTooLarge):
+		return false
+	}
+	return true
+}
+
+// resume resumes the run, whose stream opened with opts failed with
+// streamErr, until it ends or ResumeConfig gives up, and returns the error
+// of its last stream
+func (r *RunHandle) resume(ctx context.Context, shim *events.ProtocolShim, opts sse.StreamOptions, streamErr error) error {
+	config := r.client.config.Resume
+	calls := 0
+	_ = agerrors.Retry(ctx, &agerrors.RetryConfig{
+		// The stream the run started with counts as the first attempt
+		MaxAttempts:  config.MaxAttempts + 1,
+		InitialDelay: config.InitialDelay,
+		MaxDelay:     config.MaxDelay,
+		Multiplier:   2,
+		Jitter:       config.Jitter,
+		RetryIf:      resumable,
+		OnRetry: func(attempt int, err error, delay time.Duration) {
+			if r.client.logger != nil {
+				r.client.logger.WithError(err).WithField(logging.KeyRunID, r.ID()).
+					Warnf("Stream of the run failed, resuming it in %s (attempt %d)", delay, attempt)
+			}
+			if config.OnResume != nil {
+				config.OnResume(r.ID(), attempt, err)
+			}
+		},
+		Clock: config.Clock,
+	}, func() error {
+		if calls++; calls > 1 {
+			next := opts.Resume(r.last)
+			r.replay.resume(next.ContinuityToken != "")
+			r.count(func(c *resumeCounters) *atomic.Int64 { return &c.attempts })
+			frames, errs, err := r.client.transport.Stream(next)
+			if err != nil {
+				streamErr = err
+				return err
+			}
+			r.count(func(c *resumeCounters) *atomic.Int64 { return &c.reconnects })
+			streamErr = r.read(ctx, shim, frames, errs)
+		}
+		return r.interruption(ctx, streamErr)
+	})
+
+	var runErr *RunError
+	switch {
+	case calls < 2:
+	case r.finished, errors.As(r.err, &runErr):
+		r.count(func(c *resumeCounters) *atomic.Int64 { return &c.recovered })
+	case r.interruption(ctx, streamErr) != nil:
+		r.count(func(c *resumeCounters) *atomic.Int64 { return &c.failed })
+	}
+	return streamErr
+}
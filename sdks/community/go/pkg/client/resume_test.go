@@ -0,0 +1,212 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server"
+)
+
+// cutStreams drops the connections of the first streams after the given
+// numbers of frames
+func cutStreams(cuts ...int) func(http.RoundTripper) http.RoundTripper {
+	var mu sync.Mutex
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(req)
+			if err != nil || resp.StatusCode != http.StatusOK {
+				return resp, err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if len(cuts) > 0 {
+				resp.Body = &cutBody{ReadCloser: resp.Body, frames: cuts[0]}
+				cuts = cuts[1:]
+			}
+			return resp, nil
+		})
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// cutBody fails once frames SSE frames were read
+type cutBody struct {
+	io.ReadCloser
+	frames int
+	prev   byte
+}
+
+func (b *cutBody) Read(p []byte) (int, error) {
+	if b.frames == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	n, err := b.ReadCloser.Read(p)
+	for i := range n {
+		if p[i] == '\n' && b.prev == '\n' {
+			b.prev = 0
+			if b.frames--; b.frames == 0 {
+				return i + 1, nil
+			}
+			continue
+		}
+		b.prev = p[i]
+	}
+	return n, err
+}
+
+// count streams the digits as the deltas of a message
+func count(ctx context.Context, _ *types.RunAgentInput, emitter *server.EventEmitter) error {
+	messageID, err := emitter.StartTextMessage(ctx, "assistant")
+	if err != nil {
+		return err
+	}
+	for i := range 10 {
+		if err := emitter.EmitContent(ctx, messageID, fmt.Sprint(i)); err != nil {
+			return err
+		}
+	}
+	return emitter.EndTextMessage(ctx, messageID)
+}
+
+func TestClientResume(t *testing.T) {
+	ctx := context.Background()
+	resume := ResumeConfig{MaxAttempts: 3, InitialDelay: time.Millisecond}
+
+	t.Run("ContinuityToken", func(t *testing.T) {
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		srv := httptest.NewServer(server.NewServer(server.AgentFunc(count), server.Config{
+			RunManagerConfig: server.RunManagerConfig{Logger: logger},
+			Idempotency:      server.IdempotencyConfig{Grace: time.Minute},
+		}))
+		defer srv.Close()
+		c := newTestClient(t, srv.URL, Config{
+			Transport: sse.Config{WrapTransport: cutStreams(4, 5)},
+			Validate:  true,
+			Resume:    resume,
+		})
+
+		run, err := c.StartRun(ctx, &types.RunAgentInput{
+			Messages: []types.Message{{ID: "user-1", Role: types.RoleUser, Content: "count"}},
+		})
+		require.NoError(t, err)
+		want := []events.EventType{events.EventTypeRunStarted, events.EventTypeTextMessageStart}
+		for range 10 {
+			want = append(want, events.EventTypeTextMessageContent)
+		}
+		want = append(want, events.EventTypeTextMessageEnd, events.EventTypeRunFinished)
+		assert.Equal(t, want, drain(t, run))
+		require.NoError(t, run.Err())
+		assert.Equal(t, "0123456789", run.Messages()[1].Content)
+
+		stats := ResumeStats{Attempts: 2, Reconnects: 2, Recovered: 1}
+		assert.Equal(t, stats, run.ResumeStats())
+		assert.Equal(t, stats, c.ResumeStats())
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		// A server replaying the run from the start, without continuity
+		// tokens
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			for _, event := range []events.Event{
+				events.NewRunStartedEvent("thread-1", "run-1"),
+				events.NewTextMessageStartEvent("msg-1", events.WithRole("assistant")),
+				events.NewTextMessageContentEvent("msg-1", "a"),
+				events.NewTextMessageContentEvent("msg-1", "b"),
+				events.NewTextMessageEndEvent("msg-1"),
+				events.NewRunFinishedEvent("thread-1", "run-1"),
+			} {
+				data, err := event.ToJSON()
+				require.NoError(t, err)
+				fmt.Fprintf(w, "data: %s\n\n", data)
+			}
+		}))
+		defer srv.Close()
+		c := newTestClient(t, srv.URL, Config{
+			Transport: sse.Config{WrapTransport: cutStreams(3, 2)},
+			Validate:  true,
+			Resume:    resume,
+		})
+
+		run, err := c.StartRun(ctx, &types.RunAgentInput{ThreadID: "thread-1", RunID: "run-1"})
+		require.NoError(t, err)
+		assert.Len(t, drain(t, run), 6)
+		require.NoError(t, run.Err())
+		assert.Equal(t, "ab", run.Messages()[0].Content)
+		assert.Equal(t, ResumeStats{Attempts: 2, Reconnects: 2, Recovered: 1, Duplicates: 5}, run.ResumeStats())
+	})
+
+	t.Run("Gone", func(t *testing.T) {
+		var mu sync.Mutex
+		var keys []string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			keys = append(keys, r.Header.Get(events.IdempotencyKeyHeader))
+			attempt := len(keys)
+			mu.Unlock()
+			if attempt > 1 {
+				http.Error(w, "run expired", http.StatusGone)
+				return
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			data, err := events.NewRunStartedEvent("thread-1", "run-1").ToJSON()
+			require.NoError(t, err)
+			fmt.Fprintf(w, "data: %s\n\n", data)
+		}))
+		defer srv.Close()
+		var resumed []error
+		config := resume
+		config.OnResume = func(runID string, attempt int, err error) {
+			assert.Equal(t, "run-1", runID)
+			assert.Equal(t, len(resumed)+1, attempt)
+			resumed = append(resumed, err)
+		}
+		c := newTestClient(t, srv.URL, Config{Resume: config})
+
+		run, err := c.StartRun(ctx, &types.RunAgentInput{ThreadID: "thread-1", RunID: "run-1"})
+		require.NoError(t, err)
+		drain(t, run)
+		var statusErr *sse.StatusError
+		require.ErrorAs(t, run.Err(), &statusErr)
+		assert.Equal(t, http.StatusGone, statusErr.Code)
+		require.Len(t, resumed, 1)
+		assert.ErrorIs(t, resumed[0], sse.ErrRunIncomplete)
+		assert.Equal(t, ResumeStats{Attempts: 1, Failed: 1}, run.ResumeStats())
+
+		// Every attempt carries the idempotency key of the run
+		require.Len(t, keys, 2)
+		assert.NotEmpty(t, keys[0])
+		assert.Equal(t, keys[0], keys[1])
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		srv := newAgentServer(t, server.AgentFunc(count))
+		c := newTestClient(t, srv.URL, Config{Transport: sse.Config{WrapTransport: cutStreams(4)}})
+
+		run, err := c.StartRun(ctx, &types.RunAgentInput{
+			Messages: []types.Message{{ID: "user-1", Role: types.RoleUser, Content: "count"}},
+		})
+		require.NoError(t, err)
+		assert.Len(t, drain(t, run), 4)
+		assert.Error(t, run.Err())
+		assert.Equal(t, ResumeStats{}, c.ResumeStats())
+	})
+}
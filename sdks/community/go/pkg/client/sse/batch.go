@@ -0,0 +1,239 @@
+package sse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+)
+
+const (
+	defaultBatchMaxEvents  = 100
+	defaultBatchMaxBytes   = 1 << 20
+	defaultBatchMaxLatency = 100 * time.Millisecond
+)
+
+var (
+	// ErrBatchSenderClosed is returned when sending to a closed BatchSender
+	ErrBatchSenderClosed = errors.New("batch sender closed")
+
+	// ErrItemTooLarge is returned for an item that does not fit in a batch
+	// of BatchConfig.MaxBytes on its own
+	ErrItemTooLarge = errors.New("item too large for a batch")
+)
+
+// SendBatch posts a batch, a slice of items such as ingest envelopes, to
+// the endpoint as a JSON array, as expected by the handler of an
+// ingest.Ingester. A failed batch of envelopes may be sent again as a
+// whole: the ingester drops the envelopes it already delivered.
+func (c *Client) SendBatch(ctx context.Context, batch any) error {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("failed to marshal batch: %w", err)
+	}
+	return c.postBatch(ctx, body)
+}
+
+// postBatch posts an encoded batch to the endpoint
+func (c *Client) postBatch(ctx context.Context, body []byte) error {
+	c.mu.RLock()
+	config, httpClient := c.config, c.httpClient
+	c.mu.RUnlock()
+	if err := discoverEndpoint(&config); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setAuth(req, config)
+	if err := sign(req, config, body); err != nil {
+		return err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(resp.Body)
+		return &StatusError{Code: resp.StatusCode, Body: string(body)}
+	}
+	return nil
+}
+
+// BatchConfig configures a BatchSender of items of type T. A batch is sent
+// as soon as it reaches MaxEvents items, once adding an item would take it
+// over MaxBytes, or MaxLatency after its first item was added, whichever
+// comes first.
+type BatchConfig[T any] struct {
+	// MaxEvents is the largest number of items in a batch (defaults to 100)
+	MaxEvents int
+
+	// MaxBytes is the largest size of a batch in its JSON encoding
+	// (defaults to 1MB)
+	MaxBytes int
+
+	// MaxLatency is the longest an item waits for its batch to fill up
+	// (defaults to 100ms)
+	MaxLatency time.Duration
+
+	// Clock times MaxLatency (defaults to the real clock)
+	Clock clock.Clock
+
+	// OnBatch, when set, is called with the outcome of each batch sent,
+	// from the goroutine sending it
+	OnBatch func(BatchResult[T])
+}
+
+// BatchResult is the outcome of sending a batch
+type BatchResult[T any] struct {
+	Items []T
+
+	// Err is the reason the batch could not be sent, nil once the server
+	// acknowledged it
+	Err error
+}
+
+// BatchSender groups the items of chatty producers, typically
+// ingest.Envelope values, into batches sent with SendBatch, saving an HTTP
+// request per event. Batches are sent one at a time and in order. It is
+// safe for concurrent use.
+type BatchSender[T any] struct {
+	client *Client
+	config BatchConfig[T]
+	clock  clock.Clock
+
+	// sending serializes the batches sent, so that they arrive in order;
+	// it is taken before mu
+	sending sync.Mutex
+
+	mu      sync.Mutex
+	pending []T
+	encoded [][]byte
+	size    int
+	// batch counts the batches started, so that a latency timer only
+	// flushes the batch it was started for
+	batch  uint64
+	closed bool
+}
+
+// NewBatchSender creates a BatchSender posting to the endpoint of client
+func NewBatchSender[T any](client *Client, config BatchConfig[T]) *BatchSender[T] {
+	if config.MaxEvents <= 0 {
+		config.MaxEvents = defaultBatchMaxEvents
+	}
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = defaultBatchMaxBytes
+	}
+	if config.MaxLatency <= 0 {
+		config.MaxLatency = defaultBatchMaxLatency
+	}
+	return &BatchSender[T]{client: client, config: config, clock: clock.Or(config.Clock)}
+}
+
+// Send adds an item to the current batch, sending the batch first when the
+// item would take it over MaxBytes and afterwards when it reaches
+// MaxEvents. Send only fails for items it cannot add; the outcome of the
+// batches it sends is reported to OnBatch.
+func (b *BatchSender[T]) Send(ctx context.Context, item T) error {
+	encoded, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal batch item: %w", err)
+	}
+	// A batch of a single item is wrapped in brackets
+	if len(encoded)+2 > b.config.MaxBytes {
+		return fmt.Errorf("%w: %d bytes exceeds the limit of %d", ErrItemTooLarge, len(encoded)+2, b.config.MaxBytes)
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrBatchSenderClosed
+	}
+	if len(b.pending) >= b.config.MaxEvents || len(b.pending) > 0 && b.size+len(encoded)+1 > b.config.MaxBytes {
+		// The item starts the next batch
+		b.mu.Unlock()
+		_ = b.Flush(ctx)
+		return b.Send(ctx, item)
+	}
+	if len(b.pending) == 0 {
+		b.batch++
+		b.size = 2
+		go b.flushAfter(b.batch)
+	} else {
+		b.size++
+	}
+	b.pending = append(b.pending, item)
+	b.encoded = append(b.encoded, encoded)
+	b.size += len(encoded)
+	full := len(b.pending) >= b.config.MaxEvents
+	b.mu.Unlock()
+
+	if full {
+		_ = b.Flush(ctx)
+	}
+	return nil
+}
+
+// flushAfter sends batch once it has waited MaxLatency, unless it was sent
+// already
+func (b *BatchSender[T]) flushAfter(batch uint64) {
+	<-b.clock.After(b.config.MaxLatency)
+	b.sending.Lock()
+	defer b.sending.Unlock()
+	b.mu.Lock()
+	if b.batch != batch {
+		b.mu.Unlock()
+		return
+	}
+	b.mu.Unlock()
+	_ = b.send(context.Background())
+}
+
+// Flush sends the current batch, if any, and returns its error
+func (b *BatchSender[T]) Flush(ctx context.Context) error {
+	b.sending.Lock()
+	defer b.sending.Unlock()
+	return b.send(ctx)
+}
+
+// send sends the current batch; callers must hold sending
+func (b *BatchSender[T]) send(ctx context.Context) error {
+	b.mu.Lock()
+	items, encoded := b.pending, b.encoded
+	b.pending, b.encoded, b.size = nil, nil, 0
+	// The latency timer of the batch must not flush the next one
+	b.batch++
+	b.mu.Unlock()
+	if len(items) == 0 {
+		return nil
+	}
+
+	body := append([]byte{'['}, bytes.Join(encoded, []byte{','})...)
+	body = append(body, ']')
+	err := b.client.postBatch(ctx, body)
+	if b.config.OnBatch != nil {
+		b.config.OnBatch(BatchResult[T]{Items: items, Err: err})
+	}
+	return err
+}
+
+// Close sends the current batch and rejects further items with
+// ErrBatchSenderClosed. It returns the error of the last batch.
+func (b *BatchSender[T]) Close(ctx context.Context) error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	return b.Flush(ctx)
+}
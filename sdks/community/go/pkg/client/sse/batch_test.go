@@ -0,0 +1,158 @@
+package sse
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/ingest"
+)
+
+// ingestServer serves an ingest.Ingester recording the deltas it receives
+// and counting the batches posted
+type ingestServer struct {
+	*httptest.Server
+	batches atomic.Int32
+
+	mu     sync.Mutex
+	deltas []string
+}
+
+func newIngestServer(t *testing.T) *ingestServer {
+	s := &ingestServer{}
+	ingester := ingest.NewIngester(func(_ context.Context, _ string, event events.Event) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.deltas = append(s.deltas, event.(*events.TextMessageContentEvent).Delta)
+		return nil
+	}, ingest.Config{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	handler := ingester.Handler()
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.batches.Add(1)
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *ingestServer) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.deltas...)
+}
+
+func batchEnvelope(n int) ingest.Envelope {
+	return ingest.Envelope{
+		RunID:    "run-1",
+		EventID:  fmt.Sprintf("evt-%d", n),
+		Sequence: uint64(n),
+		Event:    events.NewTextMessageContentEvent("msg-1", fmt.Sprint(n)),
+	}
+}
+
+func TestSendBatch(t *testing.T) {
+	server := newIngestServer(t)
+	client := quietClient(server.URL)
+
+	batch := []ingest.Envelope{batchEnvelope(1), batchEnvelope(2)}
+	require.NoError(t, client.SendBatch(context.Background(), batch))
+	require.NoError(t, client.SendBatch(context.Background(), batch), "resent batches are deduplicated")
+	assert.Equal(t, []string{"1", "2"}, server.received())
+}
+
+func TestBatchSender(t *testing.T) {
+	t.Run("max events", func(t *testing.T) {
+		server := newIngestServer(t)
+		var results []BatchResult[ingest.Envelope]
+		sender := NewBatchSender(quietClient(server.URL), BatchConfig[ingest.Envelope]{MaxEvents: 2, MaxLatency: time.Hour, OnBatch: func(result BatchResult[ingest.Envelope]) {
+			results = append(results, result)
+		}})
+
+		for n := 1; n <= 5; n++ {
+			require.NoError(t, sender.Send(context.Background(), batchEnvelope(n)))
+		}
+		assert.Equal(t, int32(2), server.batches.Load())
+		require.NoError(t, sender.Close(context.Background()))
+		assert.Equal(t, int32(3), server.batches.Load())
+		assert.Equal(t, []string{"1", "2", "3", "4", "5"}, server.received())
+
+		require.Len(t, results, 3)
+		for i, size := range []int{2, 2, 1} {
+			assert.Len(t, results[i].Items, size)
+			assert.NoError(t, results[i].Err)
+		}
+		assert.ErrorIs(t, sender.Send(context.Background(), batchEnvelope(6)), ErrBatchSenderClosed)
+	})
+
+	t.Run("max bytes", func(t *testing.T) {
+		server := newIngestServer(t)
+		encoded, err := batchEnvelope(1).MarshalJSON()
+		require.NoError(t, err)
+		// Room for two envelopes but not three
+		sender := NewBatchSender(quietClient(server.URL), BatchConfig[ingest.Envelope]{MaxBytes: 3*len(encoded) + 2, MaxLatency: time.Hour})
+
+		for n := 1; n <= 3; n++ {
+			require.NoError(t, sender.Send(context.Background(), batchEnvelope(n)))
+		}
+		assert.Equal(t, int32(1), server.batches.Load())
+		require.NoError(t, sender.Flush(context.Background()))
+		assert.Equal(t, []string{"1", "2", "3"}, server.received())
+
+		tooLarge := batchEnvelope(4)
+		tooLarge.Event = events.NewTextMessageContentEvent("msg-1", string(make([]byte, 4*len(encoded))))
+		assert.ErrorIs(t, sender.Send(context.Background(), tooLarge), ErrItemTooLarge)
+	})
+
+	t.Run("max latency", func(t *testing.T) {
+		server := newIngestServer(t)
+		sent := make(chan BatchResult[ingest.Envelope], 1)
+		sender := NewBatchSender(quietClient(server.URL), BatchConfig[ingest.Envelope]{MaxLatency: 20 * time.Millisecond, OnBatch: func(result BatchResult[ingest.Envelope]) {
+			sent <- result
+		}})
+
+		start := time.Now()
+		require.NoError(t, sender.Send(context.Background(), batchEnvelope(1)))
+		require.NoError(t, sender.Send(context.Background(), batchEnvelope(2)))
+
+		var result BatchResult[ingest.Envelope]
+		select {
+		case result = <-sent:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the batch was not sent")
+		}
+		assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+		assert.Len(t, result.Items, 2)
+		assert.NoError(t, result.Err)
+		assert.Equal(t, []string{"1", "2"}, server.received())
+	})
+
+	t.Run("failed batches", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "failed to ingest event", http.StatusInternalServerError)
+		}))
+		defer server.Close()
+		var failed []BatchResult[ingest.Envelope]
+		sender := NewBatchSender(quietClient(server.URL), BatchConfig[ingest.Envelope]{MaxEvents: 1, OnBatch: func(result BatchResult[ingest.Envelope]) {
+			failed = append(failed, result)
+		}})
+
+		require.NoError(t, sender.Send(context.Background(), batchEnvelope(1)), "failures are reported per batch")
+		require.Len(t, failed, 1)
+		assert.Equal(t, "evt-1", failed[0].Items[0].EventID)
+		assert.Error(t, failed[0].Err)
+
+		require.NoError(t, sender.Flush(context.Background()), "nothing left to send")
+		assert.Len(t, failed, 1)
+	})
+}
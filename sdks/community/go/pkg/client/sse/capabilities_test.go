@@ -0,0 +1,112 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+func TestClientCapabilities(t *testing.T) {
+	t.Run("advertised", func(t *testing.T) {
+		advertised := events.DefaultCapabilities()
+		advertised.MaxEventSize = 1 << 20
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodGet, r.Method)
+			assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+			w.Header().Set("Content-Type", events.ContentTypeJSON)
+			_ = json.NewEncoder(w).Encode(advertised)
+		}))
+		defer server.Close()
+
+		client := quietClient(server.URL)
+		require.NoError(t, client.Reconfigure(Config{Endpoint: server.URL, APIKey: "secret"}, false))
+		capabilities, err := client.Capabilities(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, advertised, capabilities)
+		assert.False(t, capabilities.Legacy)
+	})
+
+	t.Run("legacy server", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}))
+		defer server.Close()
+
+		capabilities, err := quietClient(server.URL).Capabilities(context.Background())
+		require.NoError(t, err)
+		assert.True(t, capabilities.Legacy)
+		assert.True(t, capabilities.SupportsEvent(events.EventTypeTextMessageStart))
+		assert.False(t, capabilities.SupportsExtension(events.ExtensionHeartbeat))
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		_, err := quietClient(server.URL).Capabilities(context.Background())
+		assert.Error(t, err)
+	})
+}
+
+func TestStreamExtensions(t *testing.T) {
+	started := `{"type":"RUN_STARTED","threadId":"thread-1","runId":"run-1"}`
+
+	t.Run("advertised to the server", func(t *testing.T) {
+		header := make(chan string, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header <- r.Header.Get(events.ExtensionsHeader)
+			w.Header().Set("Content-Type", "text/event-stream")
+		}))
+		defer server.Close()
+
+		frames, errs, err := quietClient(server.URL).Stream(StreamOptions{
+			Payload:    newTestRunAgentInput(),
+			Heartbeat:  time.Second,
+			Extensions: []string{events.ExtensionAttachments},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "attachment, heartbeat", <-header)
+		_, err = drain(t, frames, errs)
+		assert.NoError(t, err)
+	})
+
+	t.Run("heartbeats not advertised", func(t *testing.T) {
+		server := newStallingServer(t, nil, started)
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		frames, errs, err := quietClient(server.URL).Stream(StreamOptions{Context: ctx, Payload: newTestRunAgentInput(), Heartbeat: 20 * time.Millisecond})
+		require.NoError(t, err)
+		count, err := drain(t, frames, errs)
+		assert.Equal(t, 1, count)
+		assert.False(t, errors.Is(err, ErrRunStalled), "an older server is not stalled for lacking heartbeats, got %v", err)
+	})
+
+	t.Run("heartbeats advertised", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(events.ExtensionsHeader, events.ExtensionHeartbeat)
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte("data: " + started + "\n\n"))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		frames, errs, err := quietClient(server.URL).Stream(StreamOptions{Payload: newTestRunAgentInput(), Heartbeat: 20 * time.Millisecond})
+		require.NoError(t, err)
+		_, err = drain(t, frames, errs)
+		assert.True(t, errors.Is(err, ErrRunStalled), "got %v", err)
+	})
+}
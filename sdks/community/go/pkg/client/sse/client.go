@@ -1,17 +1,26 @@
 package sse
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
 	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/logging"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/runctx"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
@@ -24,16 +33,118 @@ type Config struct {
 	ReadTimeout    time.Duration
 	BufferSize     int
 	Logger         *logrus.Logger
+
+	// WrapTransport decorates the HTTP transport of the client, e.g. to
+	// inject faults in tests
+	WrapTransport func(http.RoundTripper) http.RoundTripper
+
+	// Signer signs every request of the client, for gateways requiring
+	// signed requests (see HMACSigner and SigV4Signer)
+	Signer Signer
+
+	// TLS configures the TLS connections of the client: root CAs, client
+	// certificates, versions, cipher suites and pins (nil = defaults)
+	TLS *TLSConfig
+
+	// Discovery, when set, provides the endpoint of each new connection in
+	// place of Endpoint, rotating across the endpoints it discovered. The
+	// requests controlling a run go to the endpoint of its stream.
+	Discovery *Discovery
+}
+
+var (
+	// ErrReconnectRequired is returned by Reconfigure when a change only
+	// takes effect on new connections while streams are open
+	ErrReconnectRequired = errors.New("config change requires reconnecting the open streams")
+
+	// ErrReconfigured ends the streams that Reconfigure closed to apply a
+	// change, so the caller can start them again
+	ErrReconfigured = errors.New("stream closed to apply a new configuration")
+
+	// ErrRunNotFound is returned by Cancel when the server has no such run
+	// in progress
+	ErrRunNotFound = errors.New("run not found")
+)
+
+// StatusError is returned for requests the server answered with an
+// unexpected status
+type StatusError struct {
+	Code int
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d: %s", e.Code, e.Body)
+}
+
+// Validate checks the settings of a client
+func (c Config) Validate() error {
+	if c.Endpoint != "" {
+		u, err := url.Parse(c.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid endpoint %q", c.Endpoint)
+		}
+	}
+	if c.ConnectTimeout < 0 || c.ReadTimeout < 0 {
+		return errors.New("timeouts must not be negative")
+	}
+	if c.BufferSize < 0 {
+		return errors.New("buffer size must not be negative")
+	}
+	if c.TLS != nil {
+		return c.TLS.Validate()
+	}
+	return nil
+}
+
+// needsReconnect reports whether changing from c to next only takes effect
+// on new connections
+func (c Config) needsReconnect(next Config) bool {
+	return c.Endpoint != next.Endpoint || c.APIKey != next.APIKey ||
+		c.AuthHeader != next.AuthHeader || c.AuthScheme != next.AuthScheme ||
+		!reflect.DeepEqual(c.TLS, next.TLS) || !sameSigner(c.Signer, next.Signer) ||
+		c.Discovery != next.Discovery
+}
+
+// sameSigner reports whether a and b sign requests alike. Signers that are
+// functions, e.g. SignerFuncs, cannot be compared and are the same when
+// they run the same code.
+func sameSigner(a, b Signer) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Type() != vb.Type() {
+		return false
+	}
+	if va.Kind() == reflect.Func {
+		return va.Pointer() == vb.Pointer()
+	}
+	return reflect.DeepEqual(a, b)
 }
 
 type Client struct {
+	mu         sync.RWMutex
 	config     Config
 	httpClient *http.Client
 	logger     *logrus.Logger
+
+	// streams cancels the open streams
+	streams map[int]context.CancelCauseFunc
+	nextID  int
+
+	// runEndpoints holds the discovered endpoints of the open streams by
+	// run ID
+	runEndpoints map[string]string
 }
 
 type Frame struct {
-	Data      []byte
+	Data []byte
+
+	// ID is the SSE event ID of the frame, or of the last frame setting
+	// one. Servers stamp the frames of runs streamed with an idempotency
+	// key with continuity tokens, see StreamOptions.Resume.
+	ID        string
 	Timestamp time.Time
 }
 
@@ -41,13 +152,93 @@ type StreamOptions struct {
 	Context context.Context
 	Payload types.RunAgentInput
 	Headers map[string]string
+
+	// Heartbeat requests heartbeat events from the server at this interval
+	// while the run is silent (0 = none)
+	Heartbeat time.Duration
+
+	// StallTimeout ends the stream with ErrRunStalled when no event arrives
+	// for this long. It defaults to three heartbeat intervals once heartbeats
+	// are requested from a server advertising them, or announced by the
+	// server, and is otherwise disabled.
+	StallTimeout time.Duration
+
+	// Extensions lists the protocol extensions the caller handles, sent in
+	// events.ExtensionsHeader so that the agent can adapt the events of the
+	// run. The heartbeat extension is added when Heartbeat is set.
+	Extensions []string
+
+	// IdempotencyKey, when set, is sent in events.IdempotencyKeyHeader.
+	// Retrying a stream with the same key and payload, e.g. after a dropped
+	// connection, re-attaches to the run the first attempt started on
+	// servers supporting it, replaying its events from the start, or after
+	// ContinuityToken, instead of starting a duplicate run. See
+	// NewIdempotencyKey.
+	IdempotencyKey string
+
+	// FlowWindow, when set, opens a flow control window of this many events
+	// carrying streamed content (see events.FlowWindowHeader): the server
+	// holds the run back once they are sent until more credit is granted
+	// with GrantCredit. The flow control extension is added to Extensions.
+	FlowWindow int
+
+	// ContinuityToken, when set along with IdempotencyKey, is sent in
+	// events.LastEventIDHeader so that the server resumes the run after the
+	// event it was issued for instead of replaying it from the start.
+	// Servers no longer having the run fail the stream with a *StatusError
+	// of code 410 Gone. See Resume.
+	ContinuityToken string
+}
+
+// Resume returns a copy of the options resuming the run after frame, the
+// last one received before the stream dropped, when the server stamped it
+// with a continuity token (see events.ContinuityToken). Otherwise, e.g. for
+// servers not supporting continuity tokens, the copy replays the run from
+// the start like any retry with the same IdempotencyKey.
+func (o StreamOptions) Resume(frame Frame) StreamOptions {
+	o.ContinuityToken = ""
+	if token, err := events.ParseContinuityToken(frame.ID); err == nil && token.RunID == o.Payload.RunID {
+		o.ContinuityToken = frame.ID
+	}
+	return o
+}
+
+// NewIdempotencyKey returns a random key for StreamOptions.IdempotencyKey,
+// to be reused by every retry of a run
+func NewIdempotencyKey() string {
+	return uuid.NewString()
+}
+
+// extensions returns the extensions advertised for the stream
+func (o StreamOptions) extensions() []string {
+	extensions := o.Extensions
+	if o.Heartbeat > 0 && !slices.Contains(extensions, events.ExtensionHeartbeat) {
+		extensions = append(slices.Clone(extensions), events.ExtensionHeartbeat)
+	}
+	if o.FlowWindow > 0 && !slices.Contains(extensions, events.ExtensionFlowControl) {
+		extensions = append(slices.Clone(extensions), events.ExtensionFlowControl)
+	}
+	return extensions
 }
 
 func NewClient(config Config) *Client {
 	if config.Logger == nil {
 		config.Logger = logrus.New()
+		config.Logger.AddHook(logging.ContextHook{})
+	}
+	applyDefaults(&config)
+
+	return &Client{
+		config:     config,
+		httpClient: newHTTPClient(config),
+		logger:     config.Logger,
+		streams:    make(map[int]context.CancelCauseFunc),
+
+		runEndpoints: make(map[string]string),
 	}
+}
 
+func applyDefaults(config *Config) {
 	if config.ConnectTimeout == 0 {
 		config.ConnectTimeout = 30 * time.Second
 	}
@@ -59,7 +250,9 @@ func NewClient(config Config) *Client {
 	if config.BufferSize == 0 {
 		config.BufferSize = 100
 	}
+}
 
+func newHTTPClient(config Config) *http.Client {
 	transport := &http.Transport{
 		DisableCompression:    true,
 		ExpectContinueTimeout: 0,
@@ -71,19 +264,233 @@ func NewClient(config Config) *Client {
 		TLSHandshakeTimeout:   10 * time.Second,
 	}
 
-	httpClient := &http.Client{
-		Transport: transport,
+	var roundTripper http.RoundTripper = transport
+	if config.TLS != nil {
+		tlsConfig, err := config.TLS.ClientConfig()
+		if err != nil {
+			// Never fall back to the default TLS settings, which could skip
+			// the pins or the client certificate
+			return &http.Client{Transport: failingTransport{err}}
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	if config.WrapTransport != nil {
+		roundTripper = config.WrapTransport(transport)
+	}
+
+	return &http.Client{
+		Transport: roundTripper,
 		Timeout:   0,
 	}
+}
 
-	return &Client{
-		config:     config,
-		httpClient: httpClient,
-		logger:     config.Logger,
+// Config returns the current settings of the client
+func (c *Client) Config() Config {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.config
+}
+
+// Reconfigure changes the settings of the client at runtime. Timeouts and
+// the read timeout of open streams change at once, the buffer size, the
+// connect timeout and WrapTransport apply to new streams. Changing the
+// endpoint, the discovery, the credentials, the signer or the TLS settings
+// while streams are open fails with ErrReconnectRequired, unless
+// allowReconnect, in which case the open streams end with ErrReconfigured.
+// The logger cannot be replaced; change its level instead.
+func (c *Client) Reconfigure(config Config, allowReconnect bool) error {
+	applyDefaults(&config)
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	config.Logger = c.config.Logger
+	if c.config.needsReconnect(config) && len(c.streams) > 0 {
+		if !allowReconnect {
+			return ErrReconnectRequired
+		}
+		for _, cancel := range c.streams {
+			cancel(ErrReconfigured)
+		}
+	}
+	// Transport wrappers cannot be compared, so clients with one are
+	// rebuilt on every change
+	if config.ConnectTimeout != c.config.ConnectTimeout || !reflect.DeepEqual(config.TLS, c.config.TLS) ||
+		config.WrapTransport != nil || c.config.WrapTransport != nil {
+		// Open streams keep their connection on the old transport
+		c.httpClient.CloseIdleConnections()
+		c.httpClient = newHTTPClient(config)
+	}
+	c.config = config
+	return nil
+}
+
+// discoverEndpoint sets the endpoint of config to the next one of its
+// discovery, if any
+func discoverEndpoint(config *Config) error {
+	if config.Discovery == nil {
+		return nil
+	}
+	endpoint, err := config.Discovery.Next()
+	if err != nil {
+		return err
+	}
+	config.Endpoint = endpoint
+	return nil
+}
+
+// pinRunEndpoint sends the requests controlling the run runID to the
+// discovered endpoint of its stream, returning the function to forget it
+func (c *Client) pinRunEndpoint(runID string, config Config) func() {
+	if config.Discovery == nil || runID == "" {
+		return func() {}
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.runEndpoints[runID] = config.Endpoint
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.runEndpoints[runID] == config.Endpoint {
+			delete(c.runEndpoints, runID)
+		}
+	}
+}
+
+// track registers an open stream, returning the function to unregister it
+func (c *Client) track(cancel context.CancelCauseFunc) func() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := c.nextID
+	c.nextID++
+	c.streams[id] = cancel
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.streams, id)
+	}
+}
+
+// Capabilities asks the server for the event types, formats, extensions and
+// sizes it supports. Servers predating the handshake, which reject the
+// request with 404, 405 or 501, are reported with events.LegacyCapabilities
+// so that callers can fall back to the base protocol.
+func (c *Client) Capabilities(ctx context.Context) (events.Capabilities, error) {
+	c.mu.RLock()
+	config, httpClient := c.config, c.httpClient
+	c.mu.RUnlock()
+	if err := discoverEndpoint(&config); err != nil {
+		return events.Capabilities{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, config.Endpoint, nil)
+	if err != nil {
+		return events.Capabilities{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", events.ContentTypeJSON)
+	setAuth(req, config)
+	if err := sign(req, config, nil); err != nil {
+		return events.Capabilities{}, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return events.Capabilities{}, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return events.LegacyCapabilities(), nil
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return events.Capabilities{}, &StatusError{Code: resp.StatusCode, Body: string(body)}
+	}
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, events.ContentTypeJSON) {
+		return events.LegacyCapabilities(), nil
+	}
+
+	var capabilities events.Capabilities
+	if err := json.NewDecoder(resp.Body).Decode(&capabilities); err != nil {
+		return events.Capabilities{}, fmt.Errorf("failed to decode capabilities: %w", err)
+	}
+	return capabilities, nil
 }
 
-// Stream creates a basic SSE stream without reconnection
+// Cancel asks the server to cancel a run in progress, with a DELETE request
+// for runs/{id} below the endpoint. The stream of the run then ends with a
+// RUN_ERROR event with code events.RunErrorCodeCancelled. Runs the server
+// does not know of, e.g. completed ones, fail with ErrRunNotFound.
+func (c *Client) Cancel(ctx context.Context, runID string) error {
+	return c.controlRun(ctx, http.MethodDelete, runID, "", nil)
+}
+
+// GrantCredit grants a run streamed with StreamOptions.FlowWindow credit
+// for that many more events, with a flow control event posted to
+// runs/{id}/flow below the endpoint. Runs the server does not know of or
+// does not meter fail with ErrRunNotFound.
+func (c *Client) GrantCredit(ctx context.Context, runID string, credit int) error {
+	grant := events.NewFlowControlEvent(credit)
+	if _, _, err := events.FlowControlOf(grant); err != nil {
+		return err
+	}
+	body, err := grant.ToJSON()
+	if err != nil {
+		return fmt.Errorf("failed to marshal flow control event: %w", err)
+	}
+	return c.controlRun(ctx, http.MethodPost, runID, "/flow", body)
+}
+
+// controlRun sends a request for runs/{id}, followed by suffix, below the
+// endpoint
+func (c *Client) controlRun(ctx context.Context, method, runID, suffix string, body []byte) error {
+	c.mu.RLock()
+	config, httpClient := c.config, c.httpClient
+	runEndpoint, ok := c.runEndpoints[runID]
+	c.mu.RUnlock()
+	if ok {
+		config.Endpoint = runEndpoint
+	} else if err := discoverEndpoint(&config); err != nil {
+		return err
+	}
+
+	endpoint := strings.TrimSuffix(config.Endpoint, "/") + "/runs/" + url.PathEscape(runID) + suffix
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	setAuth(req, config)
+	if err := sign(req, config, body); err != nil {
+		return err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted, http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrRunNotFound, runID)
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return &StatusError{Code: resp.StatusCode, Body: string(body)}
+	}
+}
+
+// Stream creates a basic SSE stream without reconnection. Entries about the
+// stream are logged with a context carrying the thread and run IDs of the
+// payload, see logging.ContextHook. Payloads failing
+// types.RunAgentInput.Validate are not sent.
 func (c *Client) Stream(opts StreamOptions) (<-chan Frame, <-chan error, error) {
 	return c.stream(opts)
 }
@@ -94,18 +501,45 @@ func (c *Client) stream(opts StreamOptions) (<-chan Frame, <-chan error, error)
 		opts.Context = context.Background()
 	}
 
+	if opts.Payload.ProtocolVersion == "" {
+		opts.Payload.ProtocolVersion = events.ProtocolVersion
+	}
+	if err := opts.Payload.Validate(); err != nil {
+		return nil, nil, err
+	}
 	payloadBytes, err := json.Marshal(opts.Payload)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
+	ctx, cancel := context.WithCancelCause(runctx.With(opts.Context, runctx.Metadata{
+		ThreadID: opts.Payload.ThreadID,
+		RunID:    opts.Payload.RunID,
+	}))
+	untrack := c.track(cancel)
+	c.mu.RLock()
+	config, httpClient := c.config, c.httpClient
+	c.mu.RUnlock()
+	if err := discoverEndpoint(&config); err != nil {
+		untrack()
+		cancel(nil)
+		return nil, nil, err
+	}
+	forget := c.pinRunEndpoint(opts.Payload.RunID, config)
+	failed := func() {
+		forget()
+		untrack()
+		cancel(nil)
+	}
+
 	req, err := http.NewRequestWithContext(
-		opts.Context,
+		ctx,
 		http.MethodPost,
-		c.config.Endpoint,
+		config.Endpoint,
 		bytes.NewReader(payloadBytes),
 	)
 	if err != nil {
+		failed()
 		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
@@ -114,141 +548,232 @@ func (c *Client) stream(opts StreamOptions) (<-chan Frame, <-chan error, error)
 	req.Header.Set("Cache-Control", "no-cache")
 	req.Header.Set("Connection", "keep-alive")
 
-	if c.config.APIKey != "" {
-		authHeader := c.config.AuthHeader
-		if authHeader == "" {
-			authHeader = "Authorization"
-		}
+	setAuth(req, config)
 
-		// Build the header value based on header type
-		if authHeader == "Authorization" {
-			// Use scheme (Bearer by default) for Authorization header
-			scheme := "Bearer"
-			if c.config.AuthScheme != "" {
-				scheme = c.config.AuthScheme
-			}
-			req.Header.Set(authHeader, scheme+" "+c.config.APIKey)
-		} else {
-			// For custom headers like X-API-Key, use the key directly
-			req.Header.Set(authHeader, c.config.APIKey)
+	if opts.Heartbeat > 0 {
+		req.Header.Set(events.HeartbeatHeader, heartbeatHeader(opts.Heartbeat))
+	}
+	if opts.IdempotencyKey != "" {
+		req.Header.Set(events.IdempotencyKeyHeader, opts.IdempotencyKey)
+		if opts.ContinuityToken != "" {
+			req.Header.Set(events.LastEventIDHeader, opts.ContinuityToken)
 		}
 	}
-
+	if opts.FlowWindow > 0 {
+		req.Header.Set(events.FlowWindowHeader, strconv.Itoa(opts.FlowWindow))
+	}
+	if extensions := opts.extensions(); len(extensions) > 0 {
+		req.Header.Set(events.ExtensionsHeader, events.FormatExtensions(extensions))
+	}
 	for key, value := range opts.Headers {
 		req.Header.Set(key, value)
 	}
+	if err := sign(req, config, payloadBytes); err != nil {
+		failed()
+		return nil, nil, err
+	}
 
 	if c.logger != nil {
-		c.logger.WithFields(logrus.Fields{
-			"endpoint": c.config.Endpoint,
+		c.logger.WithContext(ctx).WithFields(logrus.Fields{
+			"endpoint": config.Endpoint,
 			"method":   req.Method,
 			"headers":  req.Header,
 		}).Debug("Initiating SSE connection")
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
+		failed()
 		return nil, nil, fmt.Errorf("failed to execute request: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		_ = resp.Body.Close()
-		return nil, nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+		failed()
+		return nil, nil, &StatusError{Code: resp.StatusCode, Body: string(body)}
 	}
 
 	contentType := resp.Header.Get("Content-Type")
 	if !strings.HasPrefix(contentType, "text/event-stream") {
 		_ = resp.Body.Close()
+		failed()
 		return nil, nil, fmt.Errorf("unexpected content-type: %s", contentType)
 	}
 
 	if c.logger != nil {
-		c.logger.WithFields(logrus.Fields{
+		c.logger.WithContext(ctx).WithFields(logrus.Fields{
 			"status":       resp.StatusCode,
 			"content_type": contentType,
 		}).Info("SSE connection established")
 	}
 
-	frames := make(chan Frame, c.config.BufferSize)
+	watchdog := newWatchdog(opts, time.Now())
+	if opts.Heartbeat > 0 && !slices.Contains(events.ParseExtensions(resp.Header.Get(events.ExtensionsHeader)), events.ExtensionHeartbeat) {
+		// Older servers ignore the request; heartbeats they happen to send
+		// still arm the watchdog
+		watchdog.interval = 0
+		if c.logger != nil {
+			c.logger.WithContext(ctx).WithField("endpoint", config.Endpoint).Warn("Server does not advertise heartbeats, stall detection relies on StallTimeout")
+		}
+	}
+
+	frames := make(chan Frame, config.BufferSize)
 	errors := make(chan error, 1)
 
-	go c.readStream(opts.Context, resp, frames, errors)
+	go func() {
+		defer failed()
+		c.readStream(ctx, resp, watchdog, frames, errors)
+	}()
 
 	return frames, errors, nil
 }
 
-func (c *Client) readStream(ctx context.Context, resp *http.Response, frames chan<- Frame, errors chan<- error) {
+// failingTransport fails every request with err
+type failingTransport struct {
+	err error
+}
+
+func (t failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("invalid TLS settings: %w", t.err)
+}
+
+// sign signs req, whose body is body, with the signer of config, if any. It
+// must be called once every header of req is set.
+func sign(req *http.Request, config Config, body []byte) error {
+	if config.Signer == nil {
+		return nil
+	}
+	if err := config.Signer.Sign(req, body); err != nil {
+		return fmt.Errorf("failed to sign request: %w", err)
+	}
+	return nil
+}
+
+// setAuth sets the credentials of config on req
+func setAuth(req *http.Request, config Config) {
+	if config.APIKey == "" {
+		return
+	}
+	authHeader := config.AuthHeader
+	if authHeader == "" {
+		authHeader = "Authorization"
+	}
+
+	// Build the header value based on header type
+	if authHeader == "Authorization" {
+		// Use scheme (Bearer by default) for Authorization header
+		scheme := "Bearer"
+		if config.AuthScheme != "" {
+			scheme = config.AuthScheme
+		}
+		req.Header.Set(authHeader, scheme+" "+config.APIKey)
+	} else {
+		// For custom headers like X-API-Key, use the key directly
+		req.Header.Set(authHeader, config.APIKey)
+	}
+}
+
+func (c *Client) readStream(ctx context.Context, resp *http.Response, watchdog *watchdog, frames chan<- Frame, errors chan<- error) {
 	defer func() {
 		_ = resp.Body.Close()
+		if context.Cause(ctx) == ErrReconfigured {
+			select {
+			case errors <- ErrReconfigured:
+			default:
+			}
+		}
 		close(frames)
 		close(errors)
 		if c.logger != nil {
-			c.logger.Info("SSE connection closed")
+			c.logger.WithContext(ctx).Info("SSE connection closed")
+		}
+	}()
+
+	// A single goroutine scans the stream so that reads can be abandoned on
+	// timeouts; closing the body on return ends it
+	type scanResult struct {
+		frame Frame
+		bytes int64
+		err   error
+	}
+	results := make(chan scanResult)
+	scanner := NewFrameScanner(resp.Body, 0)
+	go func() {
+		for {
+			data, err := scanner.Next()
+			result := scanResult{bytes: scanner.BytesRead(), err: err}
+			if err == nil {
+				result.frame = Frame{Data: bytes.Clone(data), ID: scanner.LastEventID(), Timestamp: time.Now()}
+			}
+			select {
+			case results <- result:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
 		}
 	}()
 
-	reader := bufio.NewReader(resp.Body)
-	var buffer bytes.Buffer
 	var frameCount int64
 	var byteCount int64
 	startTime := time.Now()
-
-	// Create a channel for read results
-	type readResult struct {
-		line []byte
-		err  error
-	}
-	readCh := make(chan readResult)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			if c.logger != nil {
-				c.logger.WithField("reason", "context cancelled").Debug("Stopping SSE stream")
+				c.logger.WithContext(ctx).WithField("reason", "context cancelled").Debug("Stopping SSE stream")
 			}
 			return
 		default:
 		}
 
-		// Start async read
-		go func() {
-			line, err := reader.ReadBytes('\n')
-			select {
-			case readCh <- readResult{line: line, err: err}:
-			case <-ctx.Done():
-			}
-		}()
-
-		// Wait for read result with timeout
-		var result readResult
-		if c.config.ReadTimeout > 0 {
-			select {
-			case result = <-readCh:
-				// Got result
-			case <-time.After(c.config.ReadTimeout):
-				// Timeout occurred
+		// Wait for the next frame with timeout
+		var timeout <-chan time.Time
+		readTimeout := c.Config().ReadTimeout
+		if readTimeout > 0 {
+			if !timer.Stop() {
 				select {
-				case errors <- fmt.Errorf("read timeout after %v", c.config.ReadTimeout):
-				case <-ctx.Done():
+				case <-timer.C:
+				default:
 				}
-				return
+			}
+			timer.Reset(readTimeout)
+			timeout = timer.C
+		}
+		var result scanResult
+		select {
+		case result = <-results:
+			// Got result
+		case <-timeout:
+			select {
+			case errors <- fmt.Errorf("read timeout after %v", readTimeout):
 			case <-ctx.Done():
-				return
 			}
-		} else {
+			return
+		case <-watchdog.expired():
+			if c.logger != nil {
+				c.logger.WithContext(ctx).WithField("stall_timeout", watchdog.stallTimeout()).Warn("SSE run stalled")
+			}
 			select {
-			case result = <-readCh:
-				// Got result
+			case errors <- fmt.Errorf("%w: no events for %v", ErrRunStalled, watchdog.stallTimeout()):
 			case <-ctx.Done():
-				return
 			}
+			return
+		case <-ctx.Done():
+			return
 		}
 
+		byteCount = result.bytes
 		if result.err != nil {
 			if result.err == io.EOF {
 				if c.logger != nil {
-					c.logger.WithFields(logrus.Fields{
+					c.logger.WithContext(ctx).WithFields(logrus.Fields{
 						"frames":   frameCount,
 						"bytes":    byteCount,
 						"duration": time.Since(startTime),
@@ -263,48 +788,25 @@ func (c *Client) readStream(ctx context.Context, resp *http.Response, frames cha
 			return
 		}
 
-		line := result.line
-
-		byteCount += int64(len(line))
-		line = bytes.TrimSuffix(line, []byte("\n"))
-		line = bytes.TrimSuffix(line, []byte("\r"))
-
-		if len(line) == 0 {
-			if buffer.Len() > 0 {
-				frame := Frame{
-					Data:      make([]byte, buffer.Len()),
-					Timestamp: time.Now(),
-				}
-				copy(frame.Data, buffer.Bytes())
-				buffer.Reset()
-
-				select {
-				case frames <- frame:
-					frameCount++
-					if frameCount%100 == 0 && c.logger != nil {
-						c.logger.WithFields(logrus.Fields{
-							"frames": frameCount,
-							"bytes":  byteCount,
-						}).Debug("SSE stream progress")
-					}
-				case <-ctx.Done():
-					return
-				}
-			}
-			continue
-		}
-
-		if bytes.HasPrefix(line, []byte("data: ")) {
-			data := bytes.TrimPrefix(line, []byte("data: "))
-			if buffer.Len() > 0 {
-				buffer.WriteByte('\n')
+		watchdog.observe(result.frame)
+		select {
+		case frames <- result.frame:
+			frameCount++
+			if frameCount%100 == 0 && c.logger != nil {
+				c.logger.WithContext(ctx).WithFields(logrus.Fields{
+					"frames": frameCount,
+					"bytes":  byteCount,
+				}).Debug("SSE stream progress")
 			}
-			buffer.Write(data)
+		case <-ctx.Done():
+			return
 		}
 	}
 }
 
 func (c *Client) Close() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	c.httpClient.CloseIdleConnections()
 	return nil
 }
@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
 	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -512,7 +513,7 @@ func TestReadStream(t *testing.T) {
 		frames := make(chan Frame, 10)
 		errors := make(chan error, 1)
 
-		go client.readStream(context.Background(), resp, frames, errors)
+		go client.readStream(context.Background(), resp, &watchdog{}, frames, errors)
 
 		// Write some data then close
 		go func() {
@@ -551,7 +552,7 @@ func TestReadStream(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
 		defer cancel()
 
-		go client.readStream(ctx, resp, frames, errors)
+		go client.readStream(ctx, resp, &watchdog{}, frames, errors)
 
 		// Write data with carriage returns
 		go func() {
@@ -581,7 +582,7 @@ func TestReadStream(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
 		defer cancel()
 
-		go client.readStream(ctx, resp, frames, errors)
+		go client.readStream(ctx, resp, &watchdog{}, frames, errors)
 
 		go func() {
 			// Multiple empty lines should be ignored
@@ -611,7 +612,7 @@ func TestReadStream(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
 		defer cancel()
 
-		go client.readStream(ctx, resp, frames, errors)
+		go client.readStream(ctx, resp, &watchdog{}, frames, errors)
 
 		go func() {
 			// Lines without "data: " prefix should be ignored
@@ -663,7 +664,7 @@ func TestReadStreamWithErrors(t *testing.T) {
 		frames := make(chan Frame, 10)
 		errors := make(chan error, 1)
 
-		go client.readStream(context.Background(), resp, frames, errors)
+		go client.readStream(context.Background(), resp, &watchdog{}, frames, errors)
 
 		select {
 		case err := <-errors:
@@ -738,7 +739,7 @@ func BenchmarkReadStream(b *testing.B) {
 		client := NewClient(Config{})
 
 		ctx, cancel := context.WithCancel(context.Background())
-		go client.readStream(ctx, resp, frames, errors)
+		go client.readStream(ctx, resp, &watchdog{}, frames, errors)
 
 		count := 0
 		for range frames {
@@ -750,3 +751,119 @@ func BenchmarkReadStream(b *testing.B) {
 		}
 	}
 }
+
+func TestClientCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/agent/runs/run-1":
+			w.WriteHeader(http.StatusAccepted)
+		case "/agent/runs/run-2":
+			http.Error(w, "run not found", http.StatusNotFound)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	client := quietClient(server.URL)
+	require.NoError(t, client.Reconfigure(Config{Endpoint: server.URL + "/agent/", APIKey: "secret"}, false))
+	assert.NoError(t, client.Cancel(context.Background(), "run-1"))
+	assert.ErrorIs(t, client.Cancel(context.Background(), "run-2"), ErrRunNotFound)
+	assert.Error(t, client.Cancel(context.Background(), "run/3"))
+}
+
+func TestStreamIdempotencyKey(t *testing.T) {
+	keys := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys <- r.Header.Get(events.IdempotencyKeyHeader)
+		w.Header().Set("Content-Type", "text/event-stream")
+	}))
+	defer server.Close()
+
+	key := NewIdempotencyKey()
+	assert.NotEqual(t, key, NewIdempotencyKey())
+	for _, opts := range []StreamOptions{
+		{Payload: newTestRunAgentInput(), IdempotencyKey: key},
+		{Payload: newTestRunAgentInput()},
+	} {
+		frames, errs, err := quietClient(server.URL).Stream(opts)
+		require.NoError(t, err)
+		_, err = drain(t, frames, errs)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, key, <-keys)
+	assert.Empty(t, <-keys)
+}
+
+func TestStreamResume(t *testing.T) {
+	tokens := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens <- r.Header.Get(events.LastEventIDHeader)
+		w.Header().Set("Content-Type", "text/event-stream")
+		for seq := 1; seq <= 2; seq++ {
+			fmt.Fprintf(w, "id: %s\ndata: {\"type\":\"STEP_STARTED\",\"stepName\":\"step\"}\n\n", events.ContinuityToken{RunID: "run-1", Seq: seq})
+		}
+	}))
+	defer server.Close()
+
+	opts := StreamOptions{Payload: newTestRunAgentInput(), IdempotencyKey: NewIdempotencyKey()}
+	frames, _, err := quietClient(server.URL).Stream(opts)
+	require.NoError(t, err)
+	var last Frame
+	for frame := range frames {
+		last = frame
+	}
+	assert.Empty(t, <-tokens)
+	assert.Equal(t, events.ContinuityToken{RunID: "run-1", Seq: 2}.String(), last.ID)
+
+	resumed := opts.Resume(last)
+	assert.Equal(t, last.ID, resumed.ContinuityToken)
+	frames, errs, err := quietClient(server.URL).Stream(resumed)
+	require.NoError(t, err)
+	_, err = drain(t, frames, errs)
+	assert.NoError(t, err)
+	assert.Equal(t, last.ID, <-tokens)
+
+	assert.Empty(t, opts.Resume(Frame{ID: "STEP_STARTED_1700000000"}).ContinuityToken, "other IDs are not continuity tokens")
+	other := opts
+	other.Payload.RunID = "run-2"
+	assert.Empty(t, other.Resume(last).ContinuityToken, "tokens of other runs are not presented")
+}
+
+func TestClientFlowControl(t *testing.T) {
+	grants := make(chan *events.FlowControl, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/runs/run-1/flow":
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			event, err := events.EventFromJSON(body)
+			require.NoError(t, err)
+			grant, ok, err := events.FlowControlOf(event)
+			require.NoError(t, err)
+			require.True(t, ok)
+			grants <- grant
+			w.WriteHeader(http.StatusAccepted)
+		case "/runs/run-2/flow":
+			http.Error(w, "run not found", http.StatusNotFound)
+		default:
+			assert.Equal(t, "8", r.Header.Get(events.FlowWindowHeader))
+			assert.Contains(t, events.ParseExtensions(r.Header.Get(events.ExtensionsHeader)), events.ExtensionFlowControl)
+			w.Header().Set("Content-Type", "text/event-stream")
+		}
+	}))
+	defer server.Close()
+
+	client := quietClient(server.URL)
+	frames, errs, err := client.Stream(StreamOptions{Payload: newTestRunAgentInput(), FlowWindow: 8})
+	require.NoError(t, err)
+	_, err = drain(t, frames, errs)
+	assert.NoError(t, err)
+
+	require.NoError(t, client.GrantCredit(context.Background(), "run-1", 4))
+	assert.Equal(t, 4, (<-grants).Credit)
+	assert.ErrorIs(t, client.GrantCredit(context.Background(), "run-2", 4), ErrRunNotFound)
+	assert.ErrorIs(t, client.GrantCredit(context.Background(), "run-1", 0), events.ErrInvalidFlowControl)
+}
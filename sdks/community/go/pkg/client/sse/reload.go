@@ -0,0 +1,100 @@
+package sse
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/config"
+)
+
+// Settings read by ConfigFrom and WatchConfig, relative to their prefix
+const (
+	SettingEndpoint       = "endpoint"
+	SettingAPIKey         = "api.key"
+	SettingAuthHeader     = "auth.header"
+	SettingAuthScheme     = "auth.scheme"
+	SettingConnectTimeout = "connect.timeout"
+	SettingReadTimeout    = "read.timeout"
+	SettingBufferSize     = "buffer.size"
+	SettingLogLevel       = "log.level"
+)
+
+// ConfigFrom returns base with the fields set under prefix in cfg, e.g.
+// "sse.read.timeout" for the prefix "sse.". Unlike the typed getters of
+// config.Config, an invalid value is an error rather than ignored.
+func ConfigFrom(cfg *config.Config, prefix string, base Config) (Config, error) {
+	for key, field := range map[string]*string{
+		SettingEndpoint:   &base.Endpoint,
+		SettingAPIKey:     &base.APIKey,
+		SettingAuthHeader: &base.AuthHeader,
+		SettingAuthScheme: &base.AuthScheme,
+	} {
+		if value, ok := cfg.Lookup(prefix + key); ok {
+			*field = value
+		}
+	}
+	for key, field := range map[string]*time.Duration{
+		SettingConnectTimeout: &base.ConnectTimeout,
+		SettingReadTimeout:    &base.ReadTimeout,
+	} {
+		if value, ok := cfg.Lookup(prefix + key); ok {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return Config{}, fmt.Errorf("invalid %s%s: %w", prefix, key, err)
+			}
+			*field = d
+		}
+	}
+	if value, ok := cfg.Lookup(prefix + SettingBufferSize); ok {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s%s: %w", prefix, SettingBufferSize, err)
+		}
+		base.BufferSize = n
+	}
+	return base, base.Validate()
+}
+
+// WatchConfig applies the settings under prefix in cfg now and whenever they
+// change, see ConfigFrom for their names. A setting removed from cfg goes
+// back to its value when WatchConfig was called. An invalid change, or one
+// that needs reconnecting without allowReconnect, is logged and rejected as
+// a whole, keeping the settings in use. It returns a function to stop
+// watching, and fails when the current settings are invalid.
+func (c *Client) WatchConfig(cfg *config.Config, prefix string, allowReconnect bool) (func(), error) {
+	base := c.Config()
+	baseLevel := c.logger.GetLevel()
+
+	apply := func() error {
+		level := baseLevel
+		if value, ok := cfg.Lookup(prefix + SettingLogLevel); ok {
+			var err error
+			if level, err = logrus.ParseLevel(value); err != nil {
+				return fmt.Errorf("invalid %s%s: %w", prefix, SettingLogLevel, err)
+			}
+		}
+		next, err := ConfigFrom(cfg, prefix, base)
+		if err != nil {
+			return err
+		}
+		if err := c.Reconfigure(next, allowReconnect); err != nil {
+			return err
+		}
+		c.logger.SetLevel(level)
+		return nil
+	}
+
+	if err := apply(); err != nil {
+		return nil, err
+	}
+	return cfg.OnChange(prefix, func(changes []config.Change) {
+		if err := apply(); err != nil {
+			c.logger.WithError(err).Warn("Rejected SSE client config change")
+			return
+		}
+		c.logger.WithField("changes", len(changes)).Debug("Applied SSE client config change")
+	}), nil
+}
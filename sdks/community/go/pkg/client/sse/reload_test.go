@@ -0,0 +1,222 @@
+package sse
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/config"
+)
+
+// settings is a config source whose values the test changes
+type settings struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func (s *settings) Name() string {
+	return "test"
+}
+
+func (s *settings) Load(context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	values := make(map[string]string, len(s.values))
+	for k, v := range s.values {
+		values[k] = v
+	}
+	return values, nil
+}
+
+func (s *settings) set(t *testing.T, cfg *config.Config, key, value string) {
+	s.mu.Lock()
+	if value == "" {
+		delete(s.values, key)
+	} else {
+		s.values[key] = value
+	}
+	s.mu.Unlock()
+	require.NoError(t, cfg.Reload(context.Background()))
+}
+
+// openStream starts a stream on a server that never ends it, until the
+// test is done
+func openStream(t *testing.T, client *Client) (<-chan Frame, <-chan error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	frames, errs, err := client.Stream(StreamOptions{Context: ctx, Payload: newTestRunAgentInput()})
+	require.NoError(t, err)
+	return frames, errs
+}
+
+func newHangingServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, Config{Endpoint: "https://agent.example.com/run"}.Validate())
+	assert.Error(t, Config{Endpoint: "agent.example.com"}.Validate())
+	assert.Error(t, Config{ReadTimeout: -time.Second}.Validate())
+	assert.Error(t, Config{BufferSize: -1}.Validate())
+}
+
+func TestReconfigure(t *testing.T) {
+	server := newHangingServer(t)
+	client := NewClient(Config{Endpoint: server.URL, Logger: logrus.New()})
+	defer client.Close()
+
+	t.Run("applies at once without open streams", func(t *testing.T) {
+		require.NoError(t, client.Reconfigure(Config{Endpoint: server.URL + "/v2", ConnectTimeout: time.Second}, false))
+		cfg := client.Config()
+		assert.Equal(t, server.URL+"/v2", cfg.Endpoint)
+		assert.Equal(t, time.Second, cfg.ConnectTimeout)
+		assert.Equal(t, 5*time.Minute, cfg.ReadTimeout)
+		assert.Equal(t, 100, cfg.BufferSize)
+	})
+
+	t.Run("rejects invalid settings", func(t *testing.T) {
+		err := client.Reconfigure(Config{Endpoint: server.URL, BufferSize: -5}, true)
+		require.Error(t, err)
+		assert.Equal(t, server.URL+"/v2", client.Config().Endpoint)
+	})
+
+	t.Run("rejects reconnecting unless allowed", func(t *testing.T) {
+		_, errs := openStream(t, client)
+
+		// Tuning an open stream is safe
+		require.NoError(t, client.Reconfigure(Config{Endpoint: server.URL + "/v2", ReadTimeout: time.Hour}, false))
+		assert.Equal(t, time.Hour, client.Config().ReadTimeout)
+
+		err := client.Reconfigure(Config{Endpoint: server.URL, APIKey: "new"}, false)
+		assert.ErrorIs(t, err, ErrReconnectRequired)
+		assert.Empty(t, client.Config().APIKey)
+
+		require.NoError(t, client.Reconfigure(Config{Endpoint: server.URL, APIKey: "new"}, true))
+		select {
+		case err := <-errs:
+			assert.ErrorIs(t, err, ErrReconfigured)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the open stream was not closed")
+		}
+		assert.Equal(t, "new", client.Config().APIKey)
+	})
+}
+
+func TestReconfigureSigner(t *testing.T) {
+	signatures := make(chan string, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signatures <- r.Header.Get(SignatureHeader)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+	client := NewClient(Config{Endpoint: server.URL, Logger: logrus.New(), Signer: &HMACSigner{KeyID: "key-1", Secret: []byte("secret")}})
+	defer client.Close()
+
+	_, errs := openStream(t, client)
+	assert.Contains(t, <-signatures, "keyId=key-1,")
+
+	// Keeping the signer is safe
+	cfg := client.Config()
+	cfg.ReadTimeout = time.Hour
+	require.NoError(t, client.Reconfigure(cfg, false))
+
+	cfg.Signer = &HMACSigner{KeyID: "key-2", Secret: []byte("rotated")}
+	assert.ErrorIs(t, client.Reconfigure(cfg, false), ErrReconnectRequired)
+	require.NoError(t, client.Reconfigure(cfg, true))
+	select {
+	case err := <-errs:
+		assert.ErrorIs(t, err, ErrReconfigured)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the open stream was not closed")
+	}
+
+	openStream(t, client)
+	assert.Contains(t, <-signatures, "keyId=key-2,", "new streams are signed by the new signer")
+
+	assert.True(t, Config{}.needsReconnect(Config{Signer: SignerFunc(func(*http.Request, []byte) error { return nil })}))
+	assert.True(t, Config{}.needsReconnect(Config{Discovery: &Discovery{}}))
+}
+
+// roundTripperFunc adapts a function to http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestReconfigureWrapTransport(t *testing.T) {
+	server := newHangingServer(t)
+	client := NewClient(Config{Endpoint: server.URL, Logger: logrus.New()})
+	defer client.Close()
+	openStream(t, client)
+
+	var wrapped atomic.Int32
+	cfg := client.Config()
+	cfg.WrapTransport = func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			wrapped.Add(1)
+			return next.RoundTrip(r)
+		})
+	}
+	require.NoError(t, client.Reconfigure(cfg, false), "open streams keep their transport")
+	openStream(t, client)
+	assert.Equal(t, int32(1), wrapped.Load(), "new streams use the new transport")
+}
+
+func TestWatchConfig(t *testing.T) {
+	server := newHangingServer(t)
+	logger := logrus.New()
+	logger.SetLevel(logrus.InfoLevel)
+	client := NewClient(Config{Endpoint: server.URL, Logger: logger})
+	defer client.Close()
+
+	source := &settings{values: map[string]string{"sse.read.timeout": "30s"}}
+	cfg, err := config.New(context.Background(), config.Options{}, source)
+	require.NoError(t, err)
+
+	stop, err := client.WatchConfig(cfg, "sse.", false)
+	require.NoError(t, err)
+	defer stop()
+	assert.Equal(t, 30*time.Second, client.Config().ReadTimeout)
+
+	source.set(t, cfg, "sse.buffer.size", "10")
+	source.set(t, cfg, "sse.log.level", "debug")
+	assert.Equal(t, 10, client.Config().BufferSize)
+	assert.Equal(t, logrus.DebugLevel, logger.GetLevel())
+
+	// Invalid values are rejected as a whole
+	source.set(t, cfg, "sse.read.timeout", "soon")
+	assert.Equal(t, 30*time.Second, client.Config().ReadTimeout)
+
+	source.set(t, cfg, "sse.read.timeout", "")
+	source.set(t, cfg, "sse.log.level", "")
+	assert.Equal(t, 5*time.Minute, client.Config().ReadTimeout)
+	assert.Equal(t, logrus.InfoLevel, logger.GetLevel())
+
+	// Changing the endpoint of an open stream is not allowed
+	_, _ = openStream(t, client)
+	source.set(t, cfg, "sse.endpoint", server.URL+"/other")
+	assert.Equal(t, server.URL, client.Config().Endpoint)
+
+	stop()
+	source.set(t, cfg, "sse.buffer.size", "20")
+	assert.Equal(t, 10, client.Config().BufferSize)
+}
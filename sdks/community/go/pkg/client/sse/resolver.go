@@ -0,0 +1,249 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+)
+
+// ErrNoEndpoints is returned by Discovery.Next before any endpoint was
+// resolved
+var ErrNoEndpoints = errors.New("no endpoint discovered")
+
+// Resolver discovers the endpoints serving an agent
+type Resolver interface {
+	Resolve(ctx context.Context) ([]string, error)
+}
+
+// ResolverFunc adapts an ordinary function to the Resolver interface
+type ResolverFunc func(ctx context.Context) ([]string, error)
+
+// Resolve calls f(ctx)
+func (f ResolverFunc) Resolve(ctx context.Context) ([]string, error) {
+	return f(ctx)
+}
+
+// StaticResolver returns a resolver of fixed endpoints
+func StaticResolver(endpoints ...string) Resolver {
+	return ResolverFunc(func(context.Context) ([]string, error) {
+		return slices.Clone(endpoints), nil
+	})
+}
+
+// TXTEndpointPrefix prefixes the TXT records listing endpoints, e.g.
+// "agui-endpoint=https://agent-1.example.com/run"
+const TXTEndpointPrefix = "agui-endpoint="
+
+// DNSResolver discovers endpoints in DNS. With Service set, it looks up the
+// SRV records of _service._proto.name and returns an endpoint per target,
+// by ascending priority then descending weight. Otherwise it returns the
+// URLs of the TXT records of Name starting with TXTEndpointPrefix, in the
+// order received.
+type DNSResolver struct {
+	Name string
+
+	// Service and Proto name the SRV records looked up, e.g. "agui" and
+	// "tcp" (the default)
+	Service string
+	Proto   string
+
+	// Scheme and Path complete the endpoints of SRV targets (default to
+	// "https" and "/")
+	Scheme string
+	Path   string
+
+	// LookupSRV and LookupTXT resolve the records (default to the methods
+	// of net.DefaultResolver), e.g. to use another DNS server
+	LookupSRV func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupTXT func(ctx context.Context, name string) ([]string, error)
+}
+
+// Resolve looks up the endpoints of r
+func (r *DNSResolver) Resolve(ctx context.Context) ([]string, error) {
+	if r.Service == "" {
+		return r.resolveTXT(ctx)
+	}
+	return r.resolveSRV(ctx)
+}
+
+func (r *DNSResolver) resolveSRV(ctx context.Context) ([]string, error) {
+	lookup := r.LookupSRV
+	if lookup == nil {
+		lookup = net.DefaultResolver.LookupSRV
+	}
+	proto := r.Proto
+	if proto == "" {
+		proto = "tcp"
+	}
+	_, records, err := lookup(ctx, r.Service, proto, r.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up SRV records of %s: %w", r.Name, err)
+	}
+	records = slices.Clone(records)
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].Priority != records[j].Priority {
+			return records[i].Priority < records[j].Priority
+		}
+		return records[i].Weight > records[j].Weight
+	})
+
+	scheme, path := r.Scheme, r.Path
+	if scheme == "" {
+		scheme = "https"
+	}
+	if path == "" {
+		path = "/"
+	}
+	endpoints := make([]string, 0, len(records))
+	for _, record := range records {
+		host := strings.TrimSuffix(record.Target, ".")
+		if host == "" {
+			// A target of "." means the service is unavailable
+			continue
+		}
+		endpoint := url.URL{Scheme: scheme, Host: net.JoinHostPort(host, strconv.Itoa(int(record.Port))), Path: path}
+		endpoints = append(endpoints, endpoint.String())
+	}
+	return endpoints, nil
+}
+
+func (r *DNSResolver) resolveTXT(ctx context.Context) ([]string, error) {
+	lookup := r.LookupTXT
+	if lookup == nil {
+		lookup = net.DefaultResolver.LookupTXT
+	}
+	records, err := lookup(ctx, r.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up TXT records of %s: %w", r.Name, err)
+	}
+	var endpoints []string
+	for _, record := range records {
+		if endpoint, ok := strings.CutPrefix(strings.TrimSpace(record), TXTEndpointPrefix); ok {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints, nil
+}
+
+// DiscoveryConfig configures a Discovery
+type DiscoveryConfig struct {
+	Resolver Resolver
+
+	// Interval is the time between resolutions (defaults to 30s)
+	Interval time.Duration
+
+	// Clock schedules the resolutions (defaults to the real clock)
+	Clock clock.Clock
+
+	// Logger receives failed resolutions and endpoint changes, nil
+	// discards them
+	Logger *logrus.Logger
+}
+
+// Discovery keeps the endpoints of an agent up to date by resolving them
+// periodically, and rotates the connections of a client across them: set
+// it as Config.Discovery and each new stream, batch or capability request
+// goes to the next endpoint in turn. A resolution failing or finding no
+// endpoint keeps the endpoints known, so that a DNS outage does not take
+// the client down. It is safe for concurrent use.
+type Discovery struct {
+	config DiscoveryConfig
+
+	mu        sync.Mutex
+	endpoints []string
+	next      int
+}
+
+// NewDiscovery creates a discovery of the endpoints of config.Resolver;
+// call Run to resolve them
+func NewDiscovery(config DiscoveryConfig) *Discovery {
+	if config.Interval <= 0 {
+		config.Interval = 30 * time.Second
+	}
+	config.Clock = clock.Or(config.Clock)
+	return &Discovery{config: config}
+}
+
+// Run resolves the endpoints now and then every interval until ctx is done.
+// It returns at once, failing when the first resolution fails or finds no
+// endpoint; later failures are logged.
+func (d *Discovery) Run(ctx context.Context) error {
+	if err := d.Refresh(ctx); err != nil {
+		return err
+	}
+	go func() {
+		ticker := d.config.Clock.NewTicker(d.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+				if err := d.Refresh(ctx); err != nil && d.config.Logger != nil && ctx.Err() == nil {
+					d.config.Logger.WithError(err).Warn("Endpoint discovery failed, keeping the known endpoints")
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Refresh resolves the endpoints once
+func (d *Discovery) Refresh(ctx context.Context) error {
+	endpoints, err := d.config.Resolver.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+	if len(endpoints) == 0 {
+		return ErrNoEndpoints
+	}
+	for _, endpoint := range endpoints {
+		if err := (Config{Endpoint: endpoint}).Validate(); err != nil {
+			return fmt.Errorf("discovered %w", err)
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if slices.Equal(d.endpoints, endpoints) {
+		return nil
+	}
+	if d.config.Logger != nil {
+		d.config.Logger.WithFields(logrus.Fields{"endpoints": endpoints, "previous": d.endpoints}).Info("Discovered endpoints changed")
+	}
+	d.endpoints = endpoints
+	d.next = 0
+	return nil
+}
+
+// Endpoints returns the endpoints known
+func (d *Discovery) Endpoints() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return slices.Clone(d.endpoints)
+}
+
+// Next returns the endpoint of the next connection, rotating across the
+// endpoints known
+func (d *Discovery) Next() (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.endpoints) == 0 {
+		return "", ErrNoEndpoints
+	}
+	endpoint := d.endpoints[d.next%len(d.endpoints)]
+	d.next = (d.next + 1) % len(d.endpoints)
+	return endpoint, nil
+}
@@ -0,0 +1,151 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDNSResolverSRV(t *testing.T) {
+	resolver := &DNSResolver{
+		Name:    "agents.example.com",
+		Service: "agui",
+		Path:    "/run",
+		LookupSRV: func(_ context.Context, service, proto, name string) (string, []*net.SRV, error) {
+			assert.Equal(t, "agui", service)
+			assert.Equal(t, "tcp", proto)
+			assert.Equal(t, "agents.example.com", name)
+			return "", []*net.SRV{
+				{Target: "backup.example.com.", Port: 8443, Priority: 20, Weight: 10},
+				{Target: "agent-2.example.com.", Port: 443, Priority: 10, Weight: 5},
+				{Target: "agent-1.example.com.", Port: 443, Priority: 10, Weight: 50},
+				{Target: ".", Port: 0, Priority: 30},
+			}, nil
+		},
+	}
+	endpoints, err := resolver.Resolve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"https://agent-1.example.com:443/run",
+		"https://agent-2.example.com:443/run",
+		"https://backup.example.com:8443/run",
+	}, endpoints)
+
+	resolver.LookupSRV = func(context.Context, string, string, string) (string, []*net.SRV, error) {
+		return "", nil, errors.New("no such host")
+	}
+	_, err = resolver.Resolve(context.Background())
+	assert.EqualError(t, err, "failed to look up SRV records of agents.example.com: no such host")
+}
+
+func TestDNSResolverTXT(t *testing.T) {
+	resolver := &DNSResolver{
+		Name: "agents.example.com",
+		LookupTXT: func(_ context.Context, name string) ([]string, error) {
+			assert.Equal(t, "agents.example.com", name)
+			return []string{
+				"v=spf1 -all",
+				TXTEndpointPrefix + "https://agent-1.example.com/run",
+				" " + TXTEndpointPrefix + "https://agent-2.example.com/run ",
+			}, nil
+		},
+	}
+	endpoints, err := resolver.Resolve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://agent-1.example.com/run", "https://agent-2.example.com/run"}, endpoints)
+}
+
+func TestDiscovery(t *testing.T) {
+	var mu sync.Mutex
+	endpoints := []string{"http://agent-1", "http://agent-2"}
+	var resolveErr error
+	discovery := NewDiscovery(DiscoveryConfig{
+		Interval: 5 * time.Millisecond,
+		Resolver: ResolverFunc(func(context.Context) ([]string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return endpoints, resolveErr
+		}),
+	})
+	_, err := discovery.Next()
+	assert.ErrorIs(t, err, ErrNoEndpoints)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, discovery.Run(ctx))
+
+	var rotated []string
+	for range 3 {
+		endpoint, err := discovery.Next()
+		require.NoError(t, err)
+		rotated = append(rotated, endpoint)
+	}
+	assert.Equal(t, []string{"http://agent-1", "http://agent-2", "http://agent-1"}, rotated)
+
+	mu.Lock()
+	endpoints = []string{"http://agent-1", "http://agent-2", "http://agent-3"}
+	mu.Unlock()
+	require.Eventually(t, func() bool { return len(discovery.Endpoints()) == 3 }, time.Second, time.Millisecond,
+		"scaling out is followed")
+
+	mu.Lock()
+	endpoints, resolveErr = nil, errors.New("timeout")
+	mu.Unlock()
+	assert.Error(t, discovery.Refresh(ctx))
+	mu.Lock()
+	resolveErr = nil
+	mu.Unlock()
+	assert.ErrorIs(t, discovery.Refresh(ctx), ErrNoEndpoints)
+	assert.Len(t, discovery.Endpoints(), 3, "failed resolutions keep the known endpoints")
+
+	t.Run("invalid endpoint", func(t *testing.T) {
+		discovery := NewDiscovery(DiscoveryConfig{Resolver: StaticResolver("agent-1:443")})
+		assert.Error(t, discovery.Run(context.Background()))
+	})
+}
+
+func TestClientDiscovery(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	newServer := func(name string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			paths = append(paths, name+" "+r.URL.Path)
+			mu.Unlock()
+			if r.Header.Get("Accept") == "text/event-stream" {
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+			}
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	first, second := newServer("first"), newServer("second")
+	discovery := NewDiscovery(DiscoveryConfig{Resolver: StaticResolver(first.URL, second.URL)})
+	require.NoError(t, discovery.Refresh(context.Background()))
+	client := NewClient(Config{Logger: quietClient("").logger, Discovery: discovery})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	payload := newTestRunAgentInput()
+	_, errs, err := client.Stream(StreamOptions{Context: ctx, Payload: payload})
+	require.NoError(t, err)
+	require.NoError(t, client.Cancel(context.Background(), payload.RunID))
+	_, err = client.Capabilities(context.Background())
+	require.NoError(t, err)
+	cancel()
+	<-errs
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"first /", "first /runs/" + payload.RunID, "second /"}, paths,
+		"streams rotate across the endpoints and run control follows the stream")
+}
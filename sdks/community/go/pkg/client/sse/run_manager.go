@@ -0,0 +1,500 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/deadletter"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/logging"
+)
+
+// RunState is the state of a run driven by a RunManager. Runs start
+// pending, turn running on RUN_STARTED and end in one of the terminal
+// states.
+type RunState string
+
+const (
+	// RunStatePending is the state of a run whose RUN_STARTED event has not
+	// arrived yet
+	RunStatePending RunState = "pending"
+	// RunStateRunning is the state of a started run
+	RunStateRunning RunState = "running"
+	// RunStateFinished is the state of a run ended by RUN_FINISHED
+	RunStateFinished RunState = "finished"
+	// RunStateFailed is the state of a run ended by RUN_ERROR, or by its
+	// stream failing or ending early
+	RunStateFailed RunState = "failed"
+	// RunStateCancelled is the state of a run cancelled with
+	// RunManager.Cancel, by the server or by the context of its stream
+	RunStateCancelled RunState = "cancelled"
+)
+
+// Terminal reports whether a run in state s has ended
+func (s RunState) Terminal() bool {
+	return s == RunStateFinished || s == RunStateFailed || s == RunStateCancelled
+}
+
+// runTransitions lists the states each state may turn into
+var runTransitions = map[RunState][]RunState{
+	RunStatePending: {RunStateRunning, RunStateFailed, RunStateCancelled},
+	RunStateRunning: {RunStateFinished, RunStateFailed, RunStateCancelled},
+}
+
+const defaultRunBufferSize = 100
+
+var (
+	// ErrRunExists is returned when starting a run whose ID the RunManager
+	// already tracks
+	ErrRunExists = errors.New("run already exists")
+
+	// ErrRunCancelled ends the runs cancelled with RunManager.Cancel
+	ErrRunCancelled = errors.New("run cancelled")
+
+	// ErrRunIncomplete ends the runs whose stream closed before their
+	// RUN_FINISHED or RUN_ERROR event
+	ErrRunIncomplete = errors.New("stream ended before the run completed")
+
+	// ErrInvalidTransition ends the runs receiving a lifecycle event their
+	// state does not allow, e.g. RUN_FINISHED before RUN_STARTED
+	ErrInvalidTransition = errors.New("invalid run state transition")
+)
+
+// RunStatus is a snapshot of a run driven by a RunManager
+type RunStatus struct {
+	RunID    string
+	ThreadID string
+
+	// ParentRunID is the run whose stream carried this one, for runs the
+	// server nested in another (empty for runs started with Start)
+	ParentRunID string
+
+	State RunState
+
+	// Err is the reason a failed or cancelled run ended
+	Err error
+
+	// Events counts the events received for the run
+	Events int
+
+	// StartedAt is when the run was started, or first seen for nested
+	// runs, and EndedAt when it reached a terminal state
+	StartedAt time.Time
+	EndedAt   time.Time
+}
+
+// RunManagerConfig configures a RunManager
+type RunManagerConfig struct {
+	// BufferSize is the number of events buffered for each run (defaults
+	// to 100). A run whose events are not consumed holds up its stream
+	// once the buffer is full, leaving the other runs unaffected.
+	BufferSize int
+
+	// Clock timestamps state changes (defaults to the real clock)
+	Clock clock.Clock
+
+	// Logger receives diagnostics, nil discards them
+	Logger *logrus.Logger
+
+	// DeadLetters captures the events of the streams that fail decoding,
+	// which are otherwise dropped (nil = dropped)
+	DeadLetters *deadletter.Queue
+
+	// OnStateChange, when set, is called with the status of a run each time
+	// its state changes, e.g. to refresh a dashboard. It is called from the
+	// goroutine reading the stream of the run and must not block.
+	OnStateChange func(RunStatus)
+}
+
+// RunManager drives several runs at once, each over its own stream from a
+// Client that runs may share. It demultiplexes the events of each stream by
+// run ID, so that runs the server nests in another get their own channel,
+// and tracks the state of every run. Runs stay listed once ended until
+// Forget is called. It is safe for concurrent use.
+type RunManager struct {
+	config RunManagerConfig
+	clock  clock.Clock
+
+	mu   sync.Mutex
+	runs map[string]*Run
+	wg   sync.WaitGroup
+}
+
+// NewRunManager creates a RunManager
+func NewRunManager(config RunManagerConfig) *RunManager {
+	if config.BufferSize <= 0 {
+		config.BufferSize = defaultRunBufferSize
+	}
+	return &RunManager{config: config, clock: clock.Or(config.Clock), runs: make(map[string]*Run)}
+}
+
+// Run is a run driven by a RunManager
+type Run struct {
+	manager *RunManager
+	client  *Client
+	cancel  context.CancelCauseFunc
+	events  chan events.Event
+	done    chan struct{}
+
+	mu     sync.Mutex
+	status RunStatus
+}
+
+// ID returns the ID of the run
+func (r *Run) ID() string {
+	return r.status.RunID
+}
+
+// Events returns the events of the run, upgraded to events.ProtocolVersion.
+// The channel is closed once the run has ended, after its RUN_FINISHED or
+// RUN_ERROR event if any.
+func (r *Run) Events() <-chan events.Event {
+	return r.events
+}
+
+// Done is closed once the run has ended
+func (r *Run) Done() <-chan struct{} {
+	return r.done
+}
+
+// Status returns the current status of the run
+func (r *Run) Status() RunStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status
+}
+
+// Err returns the reason the run failed or was cancelled, nil while it is
+// in progress or once it finished
+func (r *Run) Err() error {
+	return r.Status().Err
+}
+
+// GrantCredit grants a run streamed with StreamOptions.FlowWindow credit for
+// that many more events, see Client.GrantCredit. Consumers call it as they
+// catch up with the events of the run, so that the server streams it no
+// faster than they keep up.
+func (r *Run) GrantCredit(ctx context.Context, credit int) error {
+	return r.client.GrantCredit(ctx, r.ID(), credit)
+}
+
+// transition moves the run to state, failing it with ErrInvalidTransition
+// when its current state does not allow it. It reports whether the run
+// ended.
+func (r *Run) transition(state RunState, err error) bool {
+	r.mu.Lock()
+	from := r.status.State
+	if from.Terminal() {
+		r.mu.Unlock()
+		return false
+	}
+	valid := false
+	for _, to := range runTransitions[from] {
+		valid = valid || to == state
+	}
+	if !valid {
+		state, err = RunStateFailed, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, state)
+	}
+	r.status.State, r.status.Err = state, err
+	if state.Terminal() {
+		r.status.EndedAt = r.manager.clock.Now()
+	}
+	status := r.status
+	r.mu.Unlock()
+
+	if logger := r.manager.config.Logger; logger != nil {
+		logger.WithFields(logrus.Fields{logging.KeyRunID: status.RunID, "from": from, "to": state}).Debug("Run state changed")
+	}
+	if r.manager.config.OnStateChange != nil {
+		r.manager.config.OnStateChange(status)
+	}
+	return state.Terminal()
+}
+
+// end closes the channels of an ended run
+func (r *Run) end() {
+	close(r.events)
+	close(r.done)
+}
+
+// deliver passes an event to the consumer of the run, unless ctx is done
+func (r *Run) deliver(ctx context.Context, event events.Event) {
+	r.mu.Lock()
+	r.status.Events++
+	r.mu.Unlock()
+	select {
+	case r.events <- event:
+	case <-ctx.Done():
+	}
+}
+
+// newRun registers a run, failing with ErrRunExists for known IDs
+func (m *RunManager) newRun(status RunStatus, client *Client, cancel context.CancelCauseFunc) (*Run, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.runs[status.RunID]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrRunExists, status.RunID)
+	}
+	status.State, status.StartedAt = RunStatePending, m.clock.Now()
+	run := &Run{
+		manager: m,
+		client:  client,
+		cancel:  cancel,
+		events:  make(chan events.Event, m.config.BufferSize),
+		done:    make(chan struct{}),
+		status:  status,
+	}
+	m.runs[status.RunID] = run
+	return run, nil
+}
+
+// Start streams a run from client with opts, generating its run ID when
+// the payload has none. The run goes on until it ends, opts.Context is
+// done or Cancel or Close is called.
+func (m *RunManager) Start(client *Client, opts StreamOptions) (*Run, error) {
+	if opts.Payload.RunID == "" {
+		opts.Payload.RunID = events.GenerateRunID()
+	}
+	shim, err := events.NewProtocolShim(opts.Payload.ProtocolVersion)
+	if err != nil {
+		return nil, err
+	}
+	parent := opts.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancelCause(parent)
+	opts.Context = ctx
+
+	run, err := m.newRun(RunStatus{RunID: opts.Payload.RunID, ThreadID: opts.Payload.ThreadID}, client, cancel)
+	if err != nil {
+		cancel(nil)
+		return nil, err
+	}
+	frames, errs, err := client.Stream(opts)
+	if err != nil {
+		cancel(nil)
+		m.mu.Lock()
+		delete(m.runs, run.ID())
+		m.mu.Unlock()
+		return nil, err
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer cancel(nil)
+		m.drive(ctx, run, shim, frames, errs)
+	}()
+	return run, nil
+}
+
+// drive reads the stream of run until it closes. Events go to the
+// innermost run in progress on the stream: RUN_STARTED events for another
+// run ID open a nested run, which its terminal event closes.
+func (m *RunManager) drive(ctx context.Context, run *Run, shim *events.ProtocolShim, frames <-chan Frame, errs <-chan error) {
+	open := []*Run{run}
+	var streamErr error
+	for frames != nil {
+		select {
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+			} else if err != nil {
+				streamErr = err
+			}
+			continue
+		case frame, ok := <-frames:
+			if !ok {
+				frames = nil
+				continue
+			}
+			event, err := events.EventFromJSON(frame.Data)
+			if err != nil {
+				if m.config.Logger != nil {
+					m.config.Logger.WithError(err).WithField(logging.KeyRunID, run.ID()).Warn("Dropping undecodable event")
+				}
+				current := run
+				if len(open) > 0 {
+					current = open[len(open)-1]
+				}
+				status := current.Status()
+				letter := deadletter.NewLetter("sse", frame.Data, err)
+				letter.ThreadID, letter.RunID = status.ThreadID, status.RunID
+				m.config.DeadLetters.Capture(ctx, letter)
+				continue
+			}
+			if _, ok := events.HeartbeatInterval(event); ok {
+				continue
+			}
+			open = m.route(ctx, open, shim.Upgrade(event))
+		}
+	}
+	if streamErr == nil && errs != nil {
+		streamErr = <-errs
+	}
+
+	state, err := RunStateFailed, streamErr
+	switch cause := context.Cause(ctx); {
+	case errors.Is(cause, ErrRunCancelled), errors.Is(cause, context.Canceled):
+		state, err = RunStateCancelled, cause
+	case err == nil:
+		err = ErrRunIncomplete
+	}
+	for i := len(open) - 1; i >= 0; i-- {
+		if open[i].transition(state, err) {
+			open[i].end()
+		}
+	}
+}
+
+// route delivers an event to the run it belongs to among the runs open on
+// a stream, innermost last, and returns the runs left open
+func (m *RunManager) route(ctx context.Context, open []*Run, event events.Event) []*Run {
+	if len(open) == 0 {
+		if m.config.Logger != nil {
+			m.config.Logger.WithField("type", event.Type()).Warn("Dropping event received after the run ended")
+		}
+		return open
+	}
+	target := len(open) - 1
+	var state RunState
+	var err error
+
+	switch e := event.(type) {
+	case *events.RunStartedEvent:
+		state = RunStateRunning
+		if top := open[target]; e.RunID() != "" && e.RunID() != top.ID() {
+			nested, newErr := m.newRun(RunStatus{RunID: e.RunID(), ThreadID: e.ThreadID(), ParentRunID: top.ID()}, top.client, top.cancel)
+			if newErr != nil {
+				if m.config.Logger != nil {
+					m.config.Logger.WithError(newErr).Warn("Dropping nested run")
+				}
+				return open
+			}
+			open = append(open, nested)
+			target++
+		}
+	case *events.RunFinishedEvent:
+		state, target = RunStateFinished, openRun(open, e.RunID())
+	case *events.RunErrorEvent:
+		state, err, target = RunStateFailed, errors.New(e.Message), openRun(open, e.RunID())
+		if e.Code != nil {
+			err = fmt.Errorf("%s: %s", *e.Code, e.Message)
+		}
+		if e.Cancelled() {
+			state = RunStateCancelled
+		}
+	}
+	if target < 0 {
+		if m.config.Logger != nil {
+			m.config.Logger.WithField("type", event.Type()).Warn("Dropping lifecycle event of an unknown run")
+		}
+		return open
+	}
+
+	run := open[target]
+	run.deliver(ctx, event)
+	if state == "" || !run.transition(state, err) {
+		return open
+	}
+	run.end()
+	return append(open[:target], open[target+1:]...)
+}
+
+// openRun returns the index of the open run with runID, the innermost one
+// when runID is empty, or -1
+func openRun(open []*Run, runID string) int {
+	for i := len(open) - 1; i >= 0; i-- {
+		if runID == "" || open[i].ID() == runID {
+			return i
+		}
+	}
+	return -1
+}
+
+// Run returns the run with runID
+func (m *RunManager) Run(runID string) (*Run, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	run, ok := m.runs[runID]
+	return run, ok
+}
+
+// Runs returns the status of every run, in the order they were started
+func (m *RunManager) Runs() []RunStatus {
+	m.mu.Lock()
+	runs := make([]*Run, 0, len(m.runs))
+	for _, run := range m.runs {
+		runs = append(runs, run)
+	}
+	m.mu.Unlock()
+
+	statuses := make([]RunStatus, len(runs))
+	for i, run := range runs {
+		statuses[i] = run.Status()
+	}
+	sort.SliceStable(statuses, func(i, j int) bool {
+		if !statuses[i].StartedAt.Equal(statuses[j].StartedAt) {
+			return statuses[i].StartedAt.Before(statuses[j].StartedAt)
+		}
+		return statuses[i].RunID < statuses[j].RunID
+	})
+	return statuses
+}
+
+// Cancel cancels a run in progress on its server (see Client.Cancel). When
+// the server cannot cancel it, e.g. because it predates run cancellation,
+// the stream of the run is dropped instead, ending the runs nested in it
+// too. Runs not in progress fail with ErrRunNotFound.
+func (m *RunManager) Cancel(ctx context.Context, runID string) error {
+	run, ok := m.Run(runID)
+	if !ok || run.Status().State.Terminal() {
+		return fmt.Errorf("%w: %s", ErrRunNotFound, runID)
+	}
+	if err := run.client.Cancel(ctx, runID); err != nil {
+		if m.config.Logger != nil {
+			m.config.Logger.WithError(err).WithField(logging.KeyRunID, runID).Warn("Server did not cancel the run, dropping its stream")
+		}
+		run.cancel(ErrRunCancelled)
+	}
+	return nil
+}
+
+// Forget stops tracking an ended run and reports whether it did
+func (m *RunManager) Forget(runID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	run, ok := m.runs[runID]
+	if !ok {
+		return false
+	}
+	if !run.Status().State.Terminal() {
+		return false
+	}
+	delete(m.runs, runID)
+	return true
+}
+
+// Wait blocks until every run started so far has ended
+func (m *RunManager) Wait() {
+	m.wg.Wait()
+}
+
+// Close cancels the runs in progress by dropping their streams, and waits
+// for them to end
+func (m *RunManager) Close() {
+	m.mu.Lock()
+	for _, run := range m.runs {
+		run.cancel(ErrRunCancelled)
+	}
+	m.mu.Unlock()
+	m.wg.Wait()
+}
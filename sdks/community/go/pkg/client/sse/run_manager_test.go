@@ -0,0 +1,301 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/deadletter"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/logging"
+)
+
+// scriptedServer streams the events scripted for the run ID of each request
+func scriptedServer(t *testing.T, scripts map[string][]events.Event) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var input types.RunAgentInput
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&input))
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range scripts[input.RunID] {
+			data, err := event.ToJSON()
+			require.NoError(t, err)
+			fmt.Fprintf(w, "data: %s\n\n", data)
+		}
+	}))
+}
+
+func runInput(runID string) StreamOptions {
+	input := newTestRunAgentInput()
+	input.RunID = runID
+	return StreamOptions{Payload: input}
+}
+
+// collect returns the types of the events of run once it has ended
+func collect(t *testing.T, run *Run) []events.EventType {
+	var types []events.EventType
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-run.Events():
+			if !ok {
+				return types
+			}
+			types = append(types, event.Type())
+		case <-timeout:
+			t.Fatalf("run %s did not end", run.ID())
+		}
+	}
+}
+
+func TestRunManagerConcurrentRuns(t *testing.T) {
+	text := func(runID, content string) []events.Event {
+		return []events.Event{
+			events.NewRunStartedEvent("thread-1", runID),
+			events.NewTextMessageStartEvent("msg-"+runID, events.WithRole("assistant")),
+			events.NewTextMessageContentEvent("msg-"+runID, content),
+			events.NewTextMessageEndEvent("msg-" + runID),
+			events.NewRunFinishedEvent("thread-1", runID),
+		}
+	}
+	server := scriptedServer(t, map[string][]events.Event{"run-1": text("run-1", "one"), "run-2": text("run-2", "two")})
+	defer server.Close()
+
+	var mu sync.Mutex
+	changes := map[string][]RunState{}
+	manager := NewRunManager(RunManagerConfig{OnStateChange: func(status RunStatus) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes[status.RunID] = append(changes[status.RunID], status.State)
+	}})
+	client := quietClient(server.URL)
+
+	runs := make([]*Run, 2)
+	for i := range runs {
+		run, err := manager.Start(client, runInput(fmt.Sprintf("run-%d", i+1)))
+		require.NoError(t, err)
+		runs[i] = run
+	}
+	for i, run := range runs {
+		var content []string
+		for event := range run.Events() {
+			if e, ok := event.(*events.TextMessageContentEvent); ok {
+				content = append(content, e.Delta)
+			}
+		}
+		assert.Equal(t, []string{[]string{"one", "two"}[i]}, content, "events are not mixed up between runs")
+		assert.Equal(t, RunStateFinished, run.Status().State)
+		assert.Equal(t, 5, run.Status().Events)
+		assert.NoError(t, run.Err())
+	}
+	manager.Wait()
+
+	statuses := manager.Runs()
+	require.Len(t, statuses, 2)
+	assert.ElementsMatch(t, []string{"run-1", "run-2"}, []string{statuses[0].RunID, statuses[1].RunID})
+	assert.Equal(t, map[string][]RunState{
+		"run-1": {RunStateRunning, RunStateFinished},
+		"run-2": {RunStateRunning, RunStateFinished},
+	}, changes)
+
+	_, err := manager.Start(client, runInput("run-1"))
+	assert.ErrorIs(t, err, ErrRunExists)
+	assert.True(t, manager.Forget("run-1"))
+	_, ok := manager.Run("run-1")
+	assert.False(t, ok)
+}
+
+func TestRunManagerDemultiplexesNestedRuns(t *testing.T) {
+	server := scriptedServer(t, map[string][]events.Event{"run-1": {
+		events.NewRunStartedEvent("thread-1", "run-1"),
+		events.NewStepStartedEvent("delegate"),
+		events.NewRunStartedEvent("thread-2", "sub-run"),
+		events.NewTextMessageStartEvent("msg-1", events.WithRole("assistant")),
+		events.NewTextMessageEndEvent("msg-1"),
+		events.NewRunFinishedEvent("thread-2", "sub-run"),
+		events.NewStepFinishedEvent("delegate"),
+		events.NewRunFinishedEvent("thread-1", "run-1"),
+	}})
+	defer server.Close()
+
+	manager := NewRunManager(RunManagerConfig{})
+	run, err := manager.Start(quietClient(server.URL), runInput("run-1"))
+	require.NoError(t, err)
+
+	var sub *Run
+	require.Eventually(t, func() bool {
+		sub, _ = manager.Run("sub-run")
+		return sub != nil
+	}, 5*time.Second, time.Millisecond)
+	assert.Equal(t, []events.EventType{
+		events.EventTypeRunStarted, events.EventTypeTextMessageStart, events.EventTypeTextMessageEnd, events.EventTypeRunFinished,
+	}, collect(t, sub))
+	assert.Equal(t, []events.EventType{
+		events.EventTypeRunStarted, events.EventTypeStepStarted, events.EventTypeStepFinished, events.EventTypeRunFinished,
+	}, collect(t, run))
+
+	status := sub.Status()
+	assert.Equal(t, "run-1", status.ParentRunID)
+	assert.Equal(t, "thread-2", status.ThreadID)
+	assert.Equal(t, RunStateFinished, status.State)
+}
+
+func TestRunManagerFailures(t *testing.T) {
+	server := scriptedServer(t, map[string][]events.Event{
+		"errored": {
+			events.NewRunStartedEvent("thread-1", "errored"),
+			events.NewRunErrorEvent("model unavailable", events.WithErrorCode("AGENT_ERROR"), events.WithRunID("errored")),
+		},
+		"truncated": {
+			events.NewRunStartedEvent("thread-1", "truncated"),
+			events.NewStepStartedEvent("think"),
+		},
+		"unstarted": {
+			events.NewRunFinishedEvent("thread-1", "unstarted"),
+		},
+		"cancelled": {
+			events.NewRunStartedEvent("thread-1", "cancelled"),
+			events.NewRunErrorEvent("run cancelled by client", events.WithErrorCode(events.RunErrorCodeCancelled)),
+		},
+	})
+	defer server.Close()
+
+	manager := NewRunManager(RunManagerConfig{})
+	client := quietClient(server.URL)
+	for _, tc := range []struct {
+		runID string
+		state RunState
+		err   error
+	}{
+		{"errored", RunStateFailed, nil},
+		{"truncated", RunStateFailed, ErrRunIncomplete},
+		{"unstarted", RunStateFailed, ErrInvalidTransition},
+		{"cancelled", RunStateCancelled, nil},
+	} {
+		t.Run(tc.runID, func(t *testing.T) {
+			run, err := manager.Start(client, runInput(tc.runID))
+			require.NoError(t, err)
+			collect(t, run)
+			<-run.Done()
+			assert.Equal(t, tc.state, run.Status().State)
+			require.Error(t, run.Err())
+			if tc.err != nil {
+				assert.ErrorIs(t, run.Err(), tc.err)
+			}
+			assert.False(t, run.Status().EndedAt.IsZero())
+		})
+	}
+	assert.Equal(t, "AGENT_ERROR: model unavailable", mustRun(t, manager, "errored").Err().Error())
+}
+
+func TestRunManagerDeadLetters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"type\":\"RUN_STARTED\",\"threadId\":\"thread-1\",\"runId\":\"run-1\"}\n\n")
+		fmt.Fprint(w, "data: {\"type\":\"TEXT_MESSAGE_CONTENT\",\n\n")
+		fmt.Fprint(w, "data: {\"type\":\"RUN_FINISHED\",\"threadId\":\"thread-1\",\"runId\":\"run-1\"}\n\n")
+	}))
+	defer server.Close()
+
+	var letters []deadletter.Letter
+	queue := deadletter.NewQueue(deadletter.SinkFunc(func(_ context.Context, letter deadletter.Letter) error {
+		letters = append(letters, letter)
+		return nil
+	}), deadletter.Config{Logger: logging.Discard()})
+	manager := NewRunManager(RunManagerConfig{DeadLetters: queue})
+	run, err := manager.Start(quietClient(server.URL), runInput("run-1"))
+	require.NoError(t, err)
+	assert.Equal(t, []events.EventType{events.EventTypeRunStarted, events.EventTypeRunFinished}, collect(t, run))
+	manager.Wait()
+
+	require.Len(t, letters, 1)
+	assert.Equal(t, `{"type":"TEXT_MESSAGE_CONTENT",`, string(letters[0].Data))
+	assert.Equal(t, "sse", letters[0].Source)
+	assert.Equal(t, "run-1", letters[0].RunID)
+	assert.NotEmpty(t, letters[0].Error)
+}
+
+func mustRun(t *testing.T, manager *RunManager, runID string) *Run {
+	run, ok := manager.Run(runID)
+	require.True(t, ok)
+	return run
+}
+
+func TestRunManagerCancel(t *testing.T) {
+	// cancellable serves runs until they are cancelled, over DELETE unless
+	// legacy is set
+	cancellable := func(legacy bool) *httptest.Server {
+		var mu sync.Mutex
+		cancels := map[string]chan struct{}{}
+		cancelled := func(runID string) chan struct{} {
+			mu.Lock()
+			defer mu.Unlock()
+			if cancels[runID] == nil {
+				cancels[runID] = make(chan struct{})
+			}
+			return cancels[runID]
+		}
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodDelete {
+				if legacy {
+					http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+					return
+				}
+				close(cancelled(strings.TrimPrefix(r.URL.Path, "/runs/")))
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			var input types.RunAgentInput
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&input))
+			w.Header().Set("Content-Type", "text/event-stream")
+			started, _ := events.NewRunStartedEvent(input.ThreadID, input.RunID).ToJSON()
+			fmt.Fprintf(w, "data: %s\n\n", started)
+			w.(http.Flusher).Flush()
+			select {
+			case <-cancelled(input.RunID):
+				cancelledEvent, _ := events.NewRunErrorEvent("run cancelled by client", events.WithErrorCode(events.RunErrorCodeCancelled), events.WithRunID(input.RunID)).ToJSON()
+				fmt.Fprintf(w, "data: %s\n\n", cancelledEvent)
+			case <-r.Context().Done():
+			}
+		}))
+	}
+
+	for _, legacy := range []bool{false, true} {
+		t.Run(fmt.Sprintf("legacy=%v", legacy), func(t *testing.T) {
+			server := cancellable(legacy)
+			defer server.Close()
+			manager := NewRunManager(RunManagerConfig{})
+			client := quietClient(server.URL)
+
+			run, err := manager.Start(client, runInput("run-1"))
+			require.NoError(t, err)
+			other, err := manager.Start(client, runInput("run-2"))
+			require.NoError(t, err)
+			require.Equal(t, events.EventTypeRunStarted, (<-run.Events()).Type())
+
+			require.NoError(t, manager.Cancel(context.Background(), "run-1"))
+			collect(t, run)
+			assert.Equal(t, RunStateCancelled, run.Status().State)
+			if legacy {
+				assert.ErrorIs(t, run.Err(), ErrRunCancelled)
+			}
+			assert.ErrorIs(t, manager.Cancel(context.Background(), "run-1"), ErrRunNotFound)
+			assert.ErrorIs(t, manager.Cancel(context.Background(), "run-3"), ErrRunNotFound)
+
+			require.Equal(t, events.EventTypeRunStarted, (<-other.Events()).Type())
+			assert.Equal(t, RunStateRunning, other.Status().State, "other runs go on")
+			manager.Close()
+			assert.Equal(t, RunStateCancelled, other.Status().State)
+			assert.ErrorIs(t, other.Err(), ErrRunCancelled)
+		})
+	}
+}
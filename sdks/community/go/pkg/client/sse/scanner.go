@@ -0,0 +1,188 @@
+package sse
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// DefaultMaxFrameSize bounds the frames a FrameScanner reads unless
+	// told otherwise
+	DefaultMaxFrameSize = 16 << 20
+
+	// scannerBufferSize is the initial size of the buffer of a FrameScanner
+	scannerBufferSize = 64 << 10
+)
+
+// ErrFrameTooLarge is returned by FrameScanner.Next for frames exceeding
+// the maximum frame size
+var ErrFrameTooLarge = errors.New("SSE frame too large")
+
+// FrameScanner reads the frames of an SSE stream. It reads into a single
+// buffer, grown only for frames larger than it, looks for line ends with
+// bytes.IndexByte, which is vectorized on common platforms, and returns
+// the data of frames carried by a single data line without copying it.
+//
+// Only data fields are kept, joined with newlines when a frame has
+// several, along with the last event ID set by id fields (see
+// LastEventID); comments and the other fields are skipped. A FrameScanner
+// is not safe for concurrent use.
+type FrameScanner struct {
+	r       io.Reader
+	maxSize int
+	buf     []byte
+	frame   int // offset of the frame being scanned in buf
+	pos     int // offset of the next line in buf
+	scan    int // offset up to which the next line has no line end
+	end     int // end of the data read into buf
+	err     error
+	read    int64
+
+	// fields are the data fields of the frame being scanned, as offsets
+	// from frame
+	fields []field
+	joined []byte
+
+	// lastID is the last event ID, kept across frames as SSE requires
+	lastID []byte
+}
+
+// field locates a data field in the buffer of a FrameScanner
+type field struct {
+	start, end int
+}
+
+// NewFrameScanner creates a scanner reading frames of at most maxSize
+// bytes from r (0 = DefaultMaxFrameSize)
+func NewFrameScanner(r io.Reader, maxSize int) *FrameScanner {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxFrameSize
+	}
+	return &FrameScanner{
+		r:       r,
+		maxSize: maxSize,
+		buf:     make([]byte, min(scannerBufferSize, maxSize)),
+	}
+}
+
+// Next returns the data of the next frame with data. The returned slice
+// is only valid until the next call; copy it to keep it. Next returns
+// io.EOF at the end of the stream, dropping an unterminated last frame,
+// ErrFrameTooLarge for frames exceeding the maximum size, and the errors
+// of the underlying reader.
+func (s *FrameScanner) Next() ([]byte, error) {
+	for {
+		i := bytes.IndexByte(s.buf[s.scan:s.end], '\n')
+		if i < 0 {
+			s.scan = s.end
+			if err := s.fill(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		line := s.buf[s.pos : s.scan+i]
+		lineStart := s.pos
+		s.pos = s.scan + i + 1
+		s.scan = s.pos
+		if n := len(line); n > 0 && line[n-1] == '\r' {
+			line = line[:n-1]
+		}
+
+		if len(line) == 0 {
+			data, ok := s.data()
+			s.frame, s.fields = s.pos, s.fields[:0]
+			if ok {
+				return data, nil
+			}
+			continue
+		}
+		if bytes.HasPrefix(line, dataField) {
+			start := lineStart + len(dataField)
+			if start < lineStart+len(line) && s.buf[start] == ' ' {
+				start++
+			}
+			s.fields = append(s.fields, field{start: start - s.frame, end: lineStart + len(line) - s.frame})
+		} else if id, ok := bytes.CutPrefix(line, idField); ok {
+			id, _ = bytes.CutPrefix(id, []byte{' '})
+			// IDs containing NULL are ignored as SSE requires
+			if bytes.IndexByte(id, 0) < 0 {
+				s.lastID = append(s.lastID[:0], id...)
+			}
+		}
+	}
+}
+
+var (
+	// dataField prefixes the data lines of a frame
+	dataField = []byte("data:")
+	// idField prefixes the lines setting the last event ID
+	idField = []byte("id:")
+)
+
+// LastEventID returns the last event ID set by the frames returned so far,
+// empty when none set it
+func (s *FrameScanner) LastEventID() string {
+	return string(s.lastID)
+}
+
+// BytesRead returns the number of bytes read from the stream so far
+func (s *FrameScanner) BytesRead() int64 {
+	return s.read
+}
+
+// data returns the data of the frame ending at s.pos, reporting whether it
+// had any
+func (s *FrameScanner) data() ([]byte, bool) {
+	switch len(s.fields) {
+	case 0:
+		return nil, false
+	case 1:
+		return s.buf[s.frame+s.fields[0].start : s.frame+s.fields[0].end], true
+	}
+	s.joined = s.joined[:0]
+	for i, f := range s.fields {
+		if i > 0 {
+			s.joined = append(s.joined, '\n')
+		}
+		s.joined = append(s.joined, s.buf[s.frame+f.start:s.frame+f.end]...)
+	}
+	return s.joined, true
+}
+
+// fill reads more of the stream, moving the frame being scanned to the
+// start of the buffer or growing it when the frame fills it
+func (s *FrameScanner) fill() error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.frame > 0 {
+		n := copy(s.buf, s.buf[s.frame:s.end])
+		s.pos -= s.frame
+		s.scan -= s.frame
+		s.end, s.frame = n, 0
+	}
+	if s.end == len(s.buf) {
+		if len(s.buf) >= s.maxSize {
+			s.err = fmt.Errorf("%w: exceeds %d bytes", ErrFrameTooLarge, s.maxSize)
+			return s.err
+		}
+		grown := make([]byte, min(2*len(s.buf), s.maxSize))
+		copy(grown, s.buf[:s.end])
+		s.buf = grown
+	}
+
+	n, err := s.r.Read(s.buf[s.end:])
+	s.end += n
+	s.read += int64(n)
+	if err != nil {
+		// Lines read along with the error are scanned first
+		s.err = err
+		if n == 0 {
+			return err
+		}
+	}
+	return nil
+}
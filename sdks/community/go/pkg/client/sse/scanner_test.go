@@ -0,0 +1,154 @@
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scanAll returns the data of every frame of stream, copied, and the error
+// ending the scan
+func scanAll(r io.Reader, maxSize int) ([]string, error) {
+	scanner := NewFrameScanner(r, maxSize)
+	var frames []string
+	for {
+		data, err := scanner.Next()
+		if err != nil {
+			return frames, err
+		}
+		frames = append(frames, string(data))
+	}
+}
+
+func TestFrameScanner(t *testing.T) {
+	stream := strings.Join([]string{
+		"data: {\"type\":\"RUN_STARTED\"}\n\n",
+		": comment\nevent: message\nid: 1\ndata: first\ndata: second\n\n",
+		"data:no space\r\n\r\n",
+		"data:  two spaces\n\n",
+		"event: ping\n\n",
+		"data:\n\n",
+		"data: unterminated",
+	}, "")
+	expected := []string{`{"type":"RUN_STARTED"}`, "first\nsecond", "no space", " two spaces", ""}
+
+	for name, r := range map[string]io.Reader{
+		"whole":     strings.NewReader(stream),
+		"one byte":  iotest.OneByteReader(strings.NewReader(stream)),
+		"data eof":  iotest.DataErrReader(strings.NewReader(stream)),
+		"half read": iotest.HalfReader(strings.NewReader(stream)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			frames, err := scanAll(r, 0)
+			assert.ErrorIs(t, err, io.EOF)
+			assert.Equal(t, expected, frames)
+		})
+	}
+}
+
+func TestFrameScannerLargeFrames(t *testing.T) {
+	large := strings.Repeat("x", 3*scannerBufferSize)
+	stream := "data: small\n\ndata: " + large + "\n\ndata: small\n\n"
+
+	frames, err := scanAll(strings.NewReader(stream), 0)
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, []string{"small", large, "small"}, frames, "the buffer grows for large frames")
+
+	frames, err = scanAll(strings.NewReader(stream), 2*scannerBufferSize)
+	assert.ErrorIs(t, err, ErrFrameTooLarge)
+	assert.Equal(t, []string{"small"}, frames)
+}
+
+func TestFrameScannerErrors(t *testing.T) {
+	failure := errors.New("connection reset")
+	frames, err := scanAll(io.MultiReader(strings.NewReader("data: 1\n\ndata: 2"), iotest.ErrReader(failure)), 0)
+	assert.ErrorIs(t, err, failure)
+	assert.Equal(t, []string{"1"}, frames)
+}
+
+func TestFrameScannerLastEventID(t *testing.T) {
+	stream := "id: 1\ndata: first\n\ndata: second\n\nid:2\n\ndata: third\n\nid: bad\x00\ndata: fourth\n\nid:\ndata: fifth\n\n"
+	scanner := NewFrameScanner(strings.NewReader(stream), 0)
+
+	var ids []string
+	for {
+		if _, err := scanner.Next(); err != nil {
+			break
+		}
+		ids = append(ids, scanner.LastEventID())
+	}
+	assert.Equal(t, []string{"1", "1", "2", "2", ""}, ids, "IDs carry over frames without one, frames without data set them too")
+}
+
+func TestFrameScannerZeroCopy(t *testing.T) {
+	stream := "data: first\n\ndata: second\n\n"
+	scanner := NewFrameScanner(strings.NewReader(stream), 0)
+
+	data, err := scanner.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(data))
+	assert.Equal(t, &scanner.buf[len("data: ")], &data[0], "the data is a slice of the read buffer")
+	assert.Equal(t, int64(len(stream)), scanner.BytesRead())
+}
+
+// benchmarkStream is a stream of events of typical size
+func benchmarkStream(events int) []byte {
+	var stream bytes.Buffer
+	for i := 0; i < events; i++ {
+		fmt.Fprintf(&stream, "data: {\"type\":\"TEXT_MESSAGE_CONTENT\",\"messageId\":\"msg-1\",\"delta\":\"token %d of the streamed answer\"}\n\n", i)
+	}
+	return stream.Bytes()
+}
+
+// BenchmarkFrameScanner scans 50k events per op, a second of a busy stream
+func BenchmarkFrameScanner(b *testing.B) {
+	stream := benchmarkStream(50_000)
+	b.SetBytes(int64(len(stream)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		scanner := NewFrameScanner(bytes.NewReader(stream), 0)
+		for {
+			if _, err := scanner.Next(); err != nil {
+				break
+			}
+		}
+	}
+}
+
+// BenchmarkLineReader scans the same events line by line with a
+// bufio.Reader, as the client did before FrameScanner
+func BenchmarkLineReader(b *testing.B) {
+	stream := benchmarkStream(50_000)
+	b.SetBytes(int64(len(stream)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		reader := bufio.NewReader(bytes.NewReader(stream))
+		var buffer bytes.Buffer
+		for {
+			line, err := reader.ReadBytes('\n')
+			if err != nil {
+				break
+			}
+			line = bytes.TrimSuffix(bytes.TrimSuffix(line, []byte("\n")), []byte("\r"))
+			if len(line) == 0 {
+				frame := make([]byte, buffer.Len())
+				copy(frame, buffer.Bytes())
+				buffer.Reset()
+				continue
+			}
+			if bytes.HasPrefix(line, []byte("data: ")) {
+				buffer.Write(bytes.TrimPrefix(line, []byte("data: ")))
+			}
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+// SelfTestStatus is the outcome of a self-test check
+type SelfTestStatus string
+
+const (
+	SelfTestPass SelfTestStatus = "pass"
+	SelfTestWarn SelfTestStatus = "warn"
+	SelfTestFail SelfTestStatus = "fail"
+	// SelfTestSkip means the check did not run, because an earlier one
+	// failed or the client does not support it
+	SelfTestSkip SelfTestStatus = "skip"
+)
+
+// The checks of a self-test, in the order they run
+const (
+	CheckNegotiation  = "negotiation"
+	CheckAuth         = "auth"
+	CheckEncoding     = "encoding"
+	CheckStreaming    = "streaming"
+	CheckReconnection = "reconnection"
+	CheckWebSocket    = "websocket"
+)
+
+// SelfTestResult is the outcome of one self-test check
+type SelfTestResult struct {
+	Check  string
+	Status SelfTestStatus
+	Detail string
+}
+
+// SelfTestReport is the capability matrix of the path from a client to a
+// diagnostic endpoint
+type SelfTestReport struct {
+	Results []SelfTestResult
+}
+
+// Passed reports whether no check failed
+func (r SelfTestReport) Passed() bool {
+	for _, result := range r.Results {
+		if result.Status == SelfTestFail {
+			return false
+		}
+	}
+	return true
+}
+
+// Result returns the result of check
+func (r SelfTestReport) Result(check string) (SelfTestResult, bool) {
+	for _, result := range r.Results {
+		if result.Check == check {
+			return result, true
+		}
+	}
+	return SelfTestResult{}, false
+}
+
+// SelfTest tests the full path from the client to the endpoint, which must
+// be a diagnostic endpoint (see server.DiagnosticServer) reached with the
+// same settings as the agent, e.g. through the same proxies and with the
+// same credentials. It negotiates capabilities, runs a diagnostic run,
+// checking its authentication, encoding and streaming, then drops a second
+// run midway and re-attaches to it with its idempotency key.
+func (c *Client) SelfTest(ctx context.Context) SelfTestReport {
+	var report SelfTestReport
+	add := func(check string, status SelfTestStatus, format string, args ...any) {
+		report.Results = append(report.Results, SelfTestResult{Check: check, Status: status, Detail: fmt.Sprintf(format, args...)})
+	}
+	skipRest := func(checks ...string) SelfTestReport {
+		for _, check := range checks {
+			add(check, SelfTestSkip, "an earlier check failed")
+		}
+		add(CheckWebSocket, SelfTestSkip, "this client only speaks SSE")
+		return report
+	}
+
+	capabilities, err := c.Capabilities(ctx)
+	switch {
+	case err != nil:
+		add(CheckNegotiation, SelfTestFail, "capabilities request failed: %v", err)
+		if isAuthError(err) {
+			add(CheckAuth, SelfTestFail, "the endpoint rejected the credentials")
+			return skipRest(CheckEncoding, CheckStreaming, CheckReconnection)
+		}
+		return skipRest(CheckAuth, CheckEncoding, CheckStreaming, CheckReconnection)
+	case !capabilities.SupportsExtension(events.ExtensionDiagnostics):
+		add(CheckNegotiation, SelfTestFail, "the endpoint is not a diagnostic endpoint (extensions: %s)", events.FormatExtensions(capabilities.Extensions))
+		return skipRest(CheckAuth, CheckEncoding, CheckStreaming, CheckReconnection)
+	}
+
+	run := c.startDiagnosticRun(ctx, NewIdempotencyKey(), 0)
+	if run.err != nil && isAuthError(run.err) {
+		add(CheckNegotiation, SelfTestPass, "%s", describeCapabilities(capabilities))
+		add(CheckAuth, SelfTestFail, "the endpoint rejected the credentials of the run: %v", run.err)
+		return skipRest(CheckEncoding, CheckStreaming, CheckReconnection)
+	}
+	if run.echo == nil {
+		add(CheckNegotiation, SelfTestFail, "the run carried no diagnostic echo: %v", run.err)
+		return skipRest(CheckAuth, CheckEncoding, CheckStreaming, CheckReconnection)
+	}
+	if run.echo.ProtocolVersion != events.ProtocolVersion {
+		add(CheckNegotiation, SelfTestWarn, "%s; the run was downgraded to protocol %s", describeCapabilities(capabilities), run.echo.ProtocolVersion)
+	} else {
+		add(CheckNegotiation, SelfTestPass, "%s", describeCapabilities(capabilities))
+	}
+
+	c.mu.RLock()
+	authenticating := c.config.APIKey != "" || c.config.Signer != nil
+	c.mu.RUnlock()
+	switch {
+	case run.echo.Principal != "":
+		add(CheckAuth, SelfTestPass, "authenticated as %s", run.echo.Principal)
+	case authenticating:
+		add(CheckAuth, SelfTestWarn, "credentials sent, but the endpoint authenticated nobody")
+	default:
+		add(CheckAuth, SelfTestPass, "anonymous, the endpoint requires no credentials")
+	}
+
+	switch {
+	case run.err != nil:
+		add(CheckEncoding, SelfTestFail, "%v", run.err)
+		return skipRest(CheckStreaming, CheckReconnection)
+	case run.text.String() != events.DiagnosticProbe:
+		add(CheckEncoding, SelfTestFail, "the probe text arrived altered: %q", run.text.String())
+	case run.echo.Headers["Accept"] != "text/event-stream":
+		add(CheckEncoding, SelfTestWarn, "%d events decoded, but the endpoint received Accept %q", run.events, run.echo.Headers["Accept"])
+	default:
+		add(CheckEncoding, SelfTestPass, "%d events decoded and validated, UTF-8 text intact", run.events)
+	}
+
+	interval := time.Duration(run.echo.IntervalMs) * time.Millisecond
+	switch n := len(run.contentAt); {
+	case n < 2:
+		add(CheckStreaming, SelfTestSkip, "too few chunks to tell")
+	case run.contentAt[n-1]-run.contentAt[0] < time.Duration(n-1)*interval/2:
+		add(CheckStreaming, SelfTestFail, "%d chunks sent %s apart arrived within %s, a proxy is buffering the stream",
+			n, interval, (run.contentAt[n-1] - run.contentAt[0]).Round(time.Millisecond))
+	default:
+		add(CheckStreaming, SelfTestPass, "%d chunks arrived as they were sent, the first after %s",
+			n, run.contentAt[0].Round(time.Millisecond))
+	}
+
+	key := NewIdempotencyKey()
+	if dropped := c.startDiagnosticRun(ctx, key, 1); dropped.echo == nil {
+		add(CheckReconnection, SelfTestFail, "the run to drop failed: %v", dropped.err)
+	} else if resumed := c.readDiagnosticRun(ctx, key, dropped.input, 0); resumed.err != nil {
+		add(CheckReconnection, SelfTestFail, "re-attaching to the dropped run failed: %v", resumed.err)
+	} else if resumed.text.String() != events.DiagnosticProbe {
+		add(CheckReconnection, SelfTestFail, "the re-attached run did not replay the whole run")
+	} else {
+		add(CheckReconnection, SelfTestPass, "a dropped run was re-attached to and replayed %d events", resumed.events)
+	}
+	add(CheckWebSocket, SelfTestSkip, "this client only speaks SSE")
+	return report
+}
+
+// diagnosticRun is a run of a diagnostic endpoint, as received
+type diagnosticRun struct {
+	input  types.RunAgentInput
+	echo   *events.DiagnosticEcho
+	text   strings.Builder
+	events int
+
+	// contentAt are the arrival times of the chunks of the probe, since the
+	// request
+	contentAt []time.Duration
+
+	// err is the error that ended the run early, if any
+	err error
+}
+
+// startDiagnosticRun starts a diagnostic run with the idempotency key key and
+// reads it, dropping the connection after dropAfter chunks of the probe
+// (0 = never)
+func (c *Client) startDiagnosticRun(ctx context.Context, key string, dropAfter int) *diagnosticRun {
+	input := types.RunAgentInput{
+		ThreadID: "self-test-" + uuid.NewString(),
+		RunID:    "self-test-" + uuid.NewString(),
+		Messages: []types.Message{{ID: uuid.NewString(), Role: types.RoleUser, Content: "self-test"}},
+	}
+	return c.readDiagnosticRun(ctx, key, input, dropAfter)
+}
+
+// readDiagnosticRun starts or re-attaches to the diagnostic run of key and
+// reads it
+func (c *Client) readDiagnosticRun(ctx context.Context, key string, input types.RunAgentInput, dropAfter int) *diagnosticRun {
+	run := &diagnosticRun{input: input}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	start := time.Now()
+	frames, errs, err := c.Stream(StreamOptions{
+		Context:        ctx,
+		Payload:        input,
+		IdempotencyKey: key,
+		Extensions:     []string{events.ExtensionDiagnostics},
+	})
+	if err != nil {
+		run.err = err
+		return run
+	}
+
+	validator := events.NewEventValidator()
+	finished := false
+	for frame := range frames {
+		event, err := events.EventFromJSON(frame.Data)
+		if err == nil {
+			err = validator.ValidateEvent(event)
+		}
+		if err != nil {
+			run.err = fmt.Errorf("event %d: %w", run.events+1, err)
+			return run
+		}
+		run.events++
+
+		switch e := event.(type) {
+		case *events.CustomEvent:
+			if echo, ok, err := events.DiagnosticEchoOf(e); ok {
+				if err != nil {
+					run.err = err
+					return run
+				}
+				run.echo = echo
+			}
+		case *events.TextMessageContentEvent:
+			run.text.WriteString(e.Delta)
+			run.contentAt = append(run.contentAt, time.Since(start))
+			if dropAfter > 0 && len(run.contentAt) == dropAfter {
+				cancel()
+				return run
+			}
+		case *events.RunFinishedEvent:
+			finished = true
+		case *events.RunErrorEvent:
+			run.err = fmt.Errorf("run failed: %s", e.Message)
+			return run
+		}
+	}
+	if err := <-errs; err != nil && !errors.Is(err, context.Canceled) {
+		run.err = err
+	} else if !finished {
+		run.err = ErrRunIncomplete
+	}
+	return run
+}
+
+// isAuthError reports whether err is the rejection of the credentials of a
+// request
+func isAuthError(err error) bool {
+	var status *StatusError
+	return errors.As(err, &status) && (status.Code == http.StatusUnauthorized || status.Code == http.StatusForbidden)
+}
+
+func describeCapabilities(capabilities events.Capabilities) string {
+	return fmt.Sprintf("protocols %s, formats %s, extensions %s",
+		strings.Join(capabilities.ProtocolVersions, ", "),
+		strings.Join(capabilities.Formats, ", "),
+		events.FormatExtensions(capabilities.Extensions))
+}
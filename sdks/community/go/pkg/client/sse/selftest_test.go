@@ -0,0 +1,114 @@
+package sse
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server"
+)
+
+// newDiagnosticServer serves a diagnostic endpoint accepting the API key
+// "secret" for alice, through wrap
+func newDiagnosticServer(t *testing.T, wrap func(http.Handler) http.Handler) *httptest.Server {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	diagnostics := server.NewDiagnosticServer(server.DiagnosticConfig{
+		Config: server.Config{RunManagerConfig: server.RunManagerConfig{Logger: logger}},
+		Chunks: 4,
+		Delay:  20 * time.Millisecond,
+	})
+	handler := server.Chain(diagnostics, server.NewMiddleware(server.MiddlewareConfig{
+		Logger:        logger,
+		Authenticator: server.NewAPIKeyAuthenticator("X-API-Key", map[string]string{"secret": "alice"}),
+	})...)
+	if wrap != nil {
+		handler = wrap(handler)
+	}
+	httpServer := httptest.NewServer(handler)
+	t.Cleanup(httpServer.Close)
+	return httpServer
+}
+
+func selfTestClient(endpoint, apiKey string) *Client {
+	return NewClient(Config{Endpoint: endpoint, APIKey: apiKey, AuthHeader: "X-API-Key", Logger: quietClient("").logger})
+}
+
+func statuses(report SelfTestReport) map[string]SelfTestStatus {
+	result := make(map[string]SelfTestStatus)
+	for _, r := range report.Results {
+		result[r.Check] = r.Status
+	}
+	return result
+}
+
+func TestSelfTest(t *testing.T) {
+	httpServer := newDiagnosticServer(t, nil)
+	report := selfTestClient(httpServer.URL, "secret").SelfTest(context.Background())
+	require.True(t, report.Passed(), "%+v", report.Results)
+	assert.Equal(t, map[string]SelfTestStatus{
+		CheckNegotiation:  SelfTestPass,
+		CheckAuth:         SelfTestPass,
+		CheckEncoding:     SelfTestPass,
+		CheckStreaming:    SelfTestPass,
+		CheckReconnection: SelfTestPass,
+		CheckWebSocket:    SelfTestSkip,
+	}, statuses(report))
+	auth, _ := report.Result(CheckAuth)
+	assert.Equal(t, "authenticated as alice", auth.Detail)
+
+	t.Run("rejected credentials", func(t *testing.T) {
+		report := selfTestClient(httpServer.URL, "wrong").SelfTest(context.Background())
+		assert.False(t, report.Passed())
+		assert.Equal(t, SelfTestFail, statuses(report)[CheckAuth])
+		assert.Equal(t, SelfTestSkip, statuses(report)[CheckStreaming])
+	})
+
+	t.Run("not a diagnostic endpoint", func(t *testing.T) {
+		agent := server.NewServer(server.AgentFunc(func(context.Context, *types.RunAgentInput, *server.EventEmitter) error { return nil }), server.Config{})
+		httpServer := httptest.NewServer(agent)
+		defer httpServer.Close()
+		report := selfTestClient(httpServer.URL, "").SelfTest(context.Background())
+		result, _ := report.Result(CheckNegotiation)
+		assert.Equal(t, SelfTestFail, result.Status)
+		assert.Contains(t, result.Detail, "not a diagnostic endpoint")
+	})
+
+	t.Run("buffering proxy", func(t *testing.T) {
+		httpServer := newDiagnosticServer(t, func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				recorder := httptest.NewRecorder()
+				next.ServeHTTP(recorder, r)
+				for key, values := range recorder.Header() {
+					w.Header()[key] = values
+				}
+				w.WriteHeader(recorder.Code)
+				_, _ = w.Write(recorder.Body.Bytes())
+			})
+		})
+		report := selfTestClient(httpServer.URL, "secret").SelfTest(context.Background())
+		result, _ := report.Result(CheckStreaming)
+		assert.Equal(t, SelfTestFail, result.Status, result.Detail)
+		assert.Contains(t, result.Detail, "buffering")
+		assert.Equal(t, SelfTestPass, statuses(report)[CheckEncoding])
+	})
+}
+
+func TestDiagnosticEchoOfStream(t *testing.T) {
+	httpServer := newDiagnosticServer(t, nil)
+	client := selfTestClient(httpServer.URL, "secret")
+	run := client.startDiagnosticRun(context.Background(), "", 0)
+	require.NoError(t, run.err)
+	assert.Equal(t, events.DiagnosticProbe, run.text.String())
+	assert.Equal(t, []string{events.ExtensionDiagnostics}, run.echo.Extensions)
+	assert.Equal(t, "alice", run.echo.Principal)
+	assert.Len(t, run.contentAt, 4)
+}
@@ -0,0 +1,325 @@
+package sse
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+)
+
+// Signer signs the requests of a client, for gateways authenticating
+// requests by their signature. Sign is called once every other header of a
+// request is set, with the body of the request (nil for none), right before
+// the request is sent.
+type Signer interface {
+	Sign(req *http.Request, body []byte) error
+}
+
+// SignerFunc adapts an ordinary function to the Signer interface
+type SignerFunc func(req *http.Request, body []byte) error
+
+// Sign calls f(req, body)
+func (f SignerFunc) Sign(req *http.Request, body []byte) error {
+	return f(req, body)
+}
+
+// ErrInvalidSignature is returned by HMACSigner.Verify for requests whose
+// signature is missing, malformed, stale or wrong
+var ErrInvalidSignature = errors.New("invalid request signature")
+
+// Headers of the requests signed by an HMACSigner
+const (
+	// SignatureHeader carries the signature, as
+	// "HMAC-SHA256 keyId=...,headers=...,signature=..."
+	SignatureHeader = "X-AG-UI-Signature"
+
+	// SignatureDateHeader carries the time of the signature, in Unix seconds
+	SignatureDateHeader = "X-AG-UI-Signature-Date"
+
+	// ContentSHA256Header carries the hex encoded SHA-256 of the body
+	ContentSHA256Header = "X-AG-UI-Content-SHA256"
+
+	hmacAlgorithm = "HMAC-SHA256"
+)
+
+// HMACSigner signs requests with HMAC-SHA256 over their canonical form: the
+// method, the escaped path, the query sorted by key and value, the signed
+// headers, lower-cased, with their values trimmed, the time of the signature
+// and the SHA-256 of the body, each on its own line. The signature, the time
+// and the hash of the body are sent in SignatureHeader, SignatureDateHeader
+// and ContentSHA256Header.
+type HMACSigner struct {
+	// KeyID identifies Secret to the verifier
+	KeyID  string
+	Secret []byte
+
+	// Headers are the headers signed besides the host (defaults to
+	// Content-Type)
+	Headers []string
+
+	// Clock dates the signatures (defaults to the real clock)
+	Clock clock.Clock
+}
+
+// Sign signs req
+func (s *HMACSigner) Sign(req *http.Request, body []byte) error {
+	if len(s.Secret) == 0 {
+		return errors.New("hmac signer has no secret")
+	}
+	date := strconv.FormatInt(clock.Or(s.Clock).Now().Unix(), 10)
+	req.Header.Set(SignatureDateHeader, date)
+	req.Header.Set(ContentSHA256Header, hashHex(body))
+	headers := s.signedHeaders()
+	signature := hmacHex(s.Secret, hmacCanonicalRequest(req, headers))
+	req.Header.Set(SignatureHeader, fmt.Sprintf("%s keyId=%s,headers=%s,signature=%s",
+		hmacAlgorithm, s.KeyID, strings.Join(headers, ";"), signature))
+	return nil
+}
+
+// Verify checks the signature of req, whose body is body, e.g. in a gateway
+// or a test server. Signatures dated more than maxSkew away from now are
+// rejected (0 = any date).
+func (s *HMACSigner) Verify(req *http.Request, body []byte, maxSkew time.Duration) error {
+	params, ok := strings.CutPrefix(req.Header.Get(SignatureHeader), hmacAlgorithm+" ")
+	if !ok {
+		return fmt.Errorf("%w: missing %s", ErrInvalidSignature, SignatureHeader)
+	}
+	fields := make(map[string]string)
+	for _, param := range strings.Split(params, ",") {
+		key, value, _ := strings.Cut(param, "=")
+		fields[key] = value
+	}
+	if fields["keyId"] != s.KeyID {
+		return fmt.Errorf("%w: unknown key %q", ErrInvalidSignature, fields["keyId"])
+	}
+	if maxSkew > 0 {
+		seconds, err := strconv.ParseInt(req.Header.Get(SignatureDateHeader), 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: malformed %s", ErrInvalidSignature, SignatureDateHeader)
+		}
+		skew := clock.Or(s.Clock).Now().Sub(time.Unix(seconds, 0))
+		if skew > maxSkew || skew < -maxSkew {
+			return fmt.Errorf("%w: signature dated %s away", ErrInvalidSignature, skew)
+		}
+	}
+	if req.Header.Get(ContentSHA256Header) != hashHex(body) {
+		return fmt.Errorf("%w: body does not match %s", ErrInvalidSignature, ContentSHA256Header)
+	}
+	expected := hmacHex(s.Secret, hmacCanonicalRequest(req, strings.Split(fields["headers"], ";")))
+	if !hmac.Equal([]byte(expected), []byte(fields["signature"])) {
+		return fmt.Errorf("%w: signature mismatch", ErrInvalidSignature)
+	}
+	return nil
+}
+
+// signedHeaders returns the names of the headers signed, lower-cased and
+// sorted
+func (s *HMACSigner) signedHeaders() []string {
+	names := s.Headers
+	if names == nil {
+		names = []string{"Content-Type"}
+	}
+	headers := []string{"host"}
+	for _, name := range names {
+		headers = append(headers, strings.ToLower(name))
+	}
+	sort.Strings(headers)
+	return headers
+}
+
+// hmacCanonicalRequest returns the canonical form of req signed by an
+// HMACSigner
+func hmacCanonicalRequest(req *http.Request, headers []string) string {
+	var b strings.Builder
+	b.WriteString(req.Method + "\n")
+	b.WriteString(canonicalPath(req.URL, false) + "\n")
+	b.WriteString(canonicalQuery(req.URL.Query()) + "\n")
+	for _, name := range headers {
+		b.WriteString(name + ":" + canonicalHeaderValue(req, name) + "\n")
+	}
+	b.WriteString(req.Header.Get(SignatureDateHeader) + "\n")
+	b.WriteString(req.Header.Get(ContentSHA256Header))
+	return b.String()
+}
+
+// AWSCredentials are the credentials of a SigV4Signer
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// SessionToken is the token of temporary credentials, if any
+	SessionToken string
+}
+
+// StaticAWSCredentials returns a provider of fixed credentials for
+// SigV4Signer.Credentials
+func StaticAWSCredentials(credentials AWSCredentials) func(context.Context) (AWSCredentials, error) {
+	return func(context.Context) (AWSCredentials, error) {
+		return credentials, nil
+	}
+}
+
+const (
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat = "20060102T150405Z"
+	sigV4DateFormat = "20060102"
+)
+
+// SigV4Signer signs requests with AWS Signature Version 4, e.g. for agents
+// behind Amazon API Gateway with IAM authorization or Lambda function URLs
+type SigV4Signer struct {
+	// Credentials provides the credentials of each signature, so that
+	// temporary credentials can be renewed
+	Credentials func(ctx context.Context) (AWSCredentials, error)
+
+	// Region and Service scope the signature, e.g. "us-east-1" and
+	// "execute-api" (the default) or "lambda"
+	Region  string
+	Service string
+
+	// Clock dates the signatures (defaults to the real clock)
+	Clock clock.Clock
+}
+
+// Sign signs req
+func (s *SigV4Signer) Sign(req *http.Request, body []byte) error {
+	if s.Credentials == nil {
+		return errors.New("sigv4 signer has no credentials")
+	}
+	if s.Region == "" {
+		return errors.New("sigv4 signer has no region")
+	}
+	credentials, err := s.Credentials(req.Context())
+	if err != nil {
+		return fmt.Errorf("failed to get AWS credentials: %w", err)
+	}
+	service := s.Service
+	if service == "" {
+		service = "execute-api"
+	}
+
+	now := clock.Or(s.Clock).Now().UTC()
+	req.Header.Set("X-Amz-Date", now.Format(sigV4TimeFormat))
+	if credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+	payloadHash := hashHex(body)
+	if service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	headers := []string{"host"}
+	for name := range req.Header {
+		name = strings.ToLower(name)
+		if name == "content-type" || strings.HasPrefix(name, "x-amz-") {
+			headers = append(headers, name)
+		}
+	}
+	sort.Strings(headers)
+	signedHeaders := strings.Join(headers, ";")
+
+	var canonical strings.Builder
+	canonical.WriteString(req.Method + "\n")
+	canonical.WriteString(canonicalPath(req.URL, service != "s3") + "\n")
+	canonical.WriteString(canonicalQuery(req.URL.Query()) + "\n")
+	for _, name := range headers {
+		canonical.WriteString(name + ":" + canonicalHeaderValue(req, name) + "\n")
+	}
+	canonical.WriteString("\n" + signedHeaders + "\n" + payloadHash)
+
+	scope := strings.Join([]string{now.Format(sigV4DateFormat), s.Region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{sigV4Algorithm, now.Format(sigV4TimeFormat), scope, hashHex([]byte(canonical.String()))}, "\n")
+	key := []byte("AWS4" + credentials.SecretAccessKey)
+	for _, part := range []string{now.Format(sigV4DateFormat), s.Region, service, "aws4_request"} {
+		key = hmacSum(key, part)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, credentials.AccessKeyID, scope, signedHeaders, hex.EncodeToString(hmacSum(key, stringToSign))))
+	return nil
+}
+
+// canonicalPath returns the escaped path of u, "/" when empty, escaped again
+// segment by segment if doubleEscape, as SigV4 requires for every service
+// but S3
+func canonicalPath(u *url.URL, doubleEscape bool) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	if !doubleEscape {
+		return path
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = awsEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery returns the parameters of a query sorted by key and value,
+// escaped as RFC 3986 requires
+func canonicalQuery(query url.Values) string {
+	params := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			params = append(params, awsEscape(key)+"="+awsEscape(value))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// canonicalHeaderValue returns the values of a header of req, trimmed, with
+// runs of spaces collapsed, and comma separated
+func canonicalHeaderValue(req *http.Request, name string) string {
+	if name == "host" {
+		if req.Host != "" {
+			return req.Host
+		}
+		return req.URL.Host
+	}
+	values := req.Header.Values(name)
+	for i, value := range values {
+		values[i] = strings.Join(strings.Fields(value), " ")
+	}
+	return strings.Join(values, ",")
+}
+
+// awsEscape escapes every byte of s but the unreserved characters of RFC 3986
+func awsEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSum(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hmacHex(key []byte, data string) string {
+	return hex.EncodeToString(hmacSum(key, data))
+}
@@ -0,0 +1,156 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+)
+
+// fixedClock is a clock stopped at now
+type fixedClock struct {
+	clock.Clock
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
+
+func TestSigV4Signer(t *testing.T) {
+	// The get-vanilla case of the AWS SigV4 test suite
+	signer := &SigV4Signer{
+		Credentials: StaticAWSCredentials(AWSCredentials{
+			AccessKeyID:     "AKIDEXAMPLE",
+			SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		}),
+		Region:  "us-east-1",
+		Service: "service",
+		Clock:   fixedClock{now: time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)},
+	}
+	req := httptest.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	req.Host = "example.amazonaws.com"
+	req.Header = http.Header{}
+	require.NoError(t, signer.Sign(req, nil))
+
+	assert.Equal(t, "20150830T123600Z", req.Header.Get("X-Amz-Date"))
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+		"SignedHeaders=host;x-amz-date, "+
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		req.Header.Get("Authorization"))
+
+	t.Run("session token", func(t *testing.T) {
+		signer := *signer
+		signer.Credentials = StaticAWSCredentials(AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "token"})
+		req := httptest.NewRequest(http.MethodPost, "https://example.amazonaws.com/agent", nil)
+		req.Header = http.Header{"Content-Type": {"application/json"}}
+		require.NoError(t, signer.Sign(req, []byte(`{}`)))
+		assert.Equal(t, "token", req.Header.Get("X-Amz-Security-Token"))
+		assert.Contains(t, req.Header.Get("Authorization"), "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token,")
+	})
+
+	t.Run("credentials failure", func(t *testing.T) {
+		signer := *signer
+		signer.Credentials = func(context.Context) (AWSCredentials, error) {
+			return AWSCredentials{}, errors.New("expired")
+		}
+		err := signer.Sign(httptest.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil), nil)
+		assert.EqualError(t, err, "failed to get AWS credentials: expired")
+	})
+}
+
+func TestHMACSigner(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	signer := &HMACSigner{KeyID: "key-1", Secret: []byte("secret"), Clock: fixedClock{now: now}}
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "http://agent.example/run?b=2&a=1", nil)
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	req := newRequest()
+	require.NoError(t, signer.Sign(req, []byte(`{"a":1}`)))
+	assert.Equal(t, "1700000000", req.Header.Get(SignatureDateHeader))
+	assert.Contains(t, req.Header.Get(SignatureHeader), "HMAC-SHA256 keyId=key-1,headers=content-type;host,signature=")
+	assert.NoError(t, signer.Verify(req, []byte(`{"a":1}`), time.Minute))
+
+	t.Run("tampered body", func(t *testing.T) {
+		assert.True(t, errors.Is(signer.Verify(req, []byte(`{"a":2}`), 0), ErrInvalidSignature))
+	})
+
+	t.Run("tampered header", func(t *testing.T) {
+		tampered := req.Clone(context.Background())
+		tampered.Header.Set("Content-Type", "text/plain")
+		assert.True(t, errors.Is(signer.Verify(tampered, []byte(`{"a":1}`), 0), ErrInvalidSignature))
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		other := &HMACSigner{KeyID: "key-1", Secret: []byte("other")}
+		assert.True(t, errors.Is(other.Verify(req, []byte(`{"a":1}`), 0), ErrInvalidSignature))
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		later := &HMACSigner{KeyID: "key-1", Secret: []byte("secret"), Clock: fixedClock{now: now.Add(time.Hour)}}
+		assert.True(t, errors.Is(later.Verify(req, []byte(`{"a":1}`), time.Minute), ErrInvalidSignature))
+		assert.NoError(t, later.Verify(req, []byte(`{"a":1}`), 0))
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		assert.True(t, errors.Is(signer.Verify(newRequest(), nil, 0), ErrInvalidSignature))
+	})
+}
+
+func TestClientSignsRequests(t *testing.T) {
+	signer := &HMACSigner{KeyID: "key-1", Secret: []byte("secret")}
+	verified := make(chan error, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		verified <- signer.Verify(r, body, time.Minute)
+		if r.Header.Get("Accept") == "text/event-stream" {
+			w.Header().Set("Content-Type", "text/event-stream")
+		}
+	}))
+	defer server.Close()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	client := NewClient(Config{Endpoint: server.URL, Logger: logger, Signer: signer})
+
+	frames, errs, err := client.Stream(StreamOptions{
+		Payload: newTestRunAgentInput(),
+		Headers: map[string]string{"Content-Type": "application/json; charset=utf-8"},
+	})
+	require.NoError(t, err)
+	_, err = drain(t, frames, errs)
+	require.NoError(t, err)
+	assert.NoError(t, <-verified, "stream requests are signed after their headers are set")
+
+	require.NoError(t, client.SendBatch(context.Background(), []any{}))
+	assert.NoError(t, <-verified, "batches are signed")
+
+	t.Run("signing failure", func(t *testing.T) {
+		client := NewClient(Config{Endpoint: server.URL, Logger: logger, Signer: SignerFunc(func(*http.Request, []byte) error {
+			return errors.New("no key")
+		})})
+		_, _, err := client.Stream(StreamOptions{Payload: newTestRunAgentInput()})
+		assert.EqualError(t, err, "failed to sign request: no key")
+		assert.Empty(t, verified, "unsigned requests are not sent")
+	})
+}
+
+func TestCanonicalPath(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "https://example.amazonaws.com/runs/a%20b", nil)
+	assert.Equal(t, "/runs/a%2520b", canonicalPath(req.URL, true))
+	assert.Equal(t, "/runs/a%20b", canonicalPath(req.URL, false))
+	req = httptest.NewRequest(http.MethodGet, "https://example.amazonaws.com", nil)
+	assert.Equal(t, "/", canonicalPath(req.URL, false))
+}
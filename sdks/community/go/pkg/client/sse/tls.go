@@ -0,0 +1,163 @@
+package sse
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// TLSConfig configures the TLS connections of a client. The zero value
+// verifies servers against the system roots with TLS 1.2 or later.
+type TLSConfig struct {
+	// RootCAFile and RootCAPEM hold PEM encoded certificates verifying the
+	// server in place of the system roots, e.g. a private CA
+	RootCAFile string
+	RootCAPEM  []byte
+
+	// CertFile and KeyFile hold the PEM encoded certificate and key the
+	// client authenticates with (mutual TLS). Certificates can hold them
+	// instead.
+	CertFile     string
+	KeyFile      string
+	Certificates []tls.Certificate
+
+	// ServerName overrides the host name verified, e.g. when connecting
+	// through an IP address
+	ServerName string
+
+	// MinVersion is the lowest TLS version accepted (defaults to TLS 1.2)
+	MinVersion uint16
+
+	// CipherSuites restricts the cipher suites of TLS 1.2 connections (TLS
+	// 1.3 suites are not configurable)
+	CipherSuites []uint16
+
+	// PinnedSPKI lists the base64 encoded SHA-256 hashes of the public keys
+	// (SubjectPublicKeyInfo) accepted in the verified chain of the server,
+	// or its certificate when InsecureSkipVerify is set, see SPKIPin.
+	// Connections whose chain has none of them are refused.
+	PinnedSPKI []string
+
+	// InsecureSkipVerify disables the verification of the server, pins
+	// excepted. It is rejected by Validate unless DangerouslyAllowInsecure
+	// is set too, which also allows versions before TLS 1.2 and insecure
+	// cipher suites.
+	InsecureSkipVerify       bool
+	DangerouslyAllowInsecure bool
+}
+
+// SPKIPin returns the pin of cert for TLSConfig.PinnedSPKI
+func SPKIPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Validate checks the TLS settings, loading the files they name
+func (t *TLSConfig) Validate() error {
+	_, err := t.ClientConfig()
+	return err
+}
+
+// ClientConfig returns the crypto/tls configuration of the settings
+func (t *TLSConfig) ClientConfig() (*tls.Config, error) {
+	if t.InsecureSkipVerify && !t.DangerouslyAllowInsecure {
+		return nil, errors.New("tls: InsecureSkipVerify requires DangerouslyAllowInsecure")
+	}
+	config := &tls.Config{
+		ServerName:         t.ServerName,
+		MinVersion:         t.MinVersion,
+		CipherSuites:       t.CipherSuites,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+		Certificates:       t.Certificates,
+	}
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+	if config.MinVersion < tls.VersionTLS12 && !t.DangerouslyAllowInsecure {
+		return nil, fmt.Errorf("tls: %s requires DangerouslyAllowInsecure", tls.VersionName(config.MinVersion))
+	}
+	for _, id := range t.CipherSuites {
+		if err := checkCipherSuite(id, t.DangerouslyAllowInsecure); err != nil {
+			return nil, err
+		}
+	}
+
+	if t.RootCAFile != "" || len(t.RootCAPEM) > 0 {
+		pem := slices.Clone(t.RootCAPEM)
+		if t.RootCAFile != "" {
+			data, err := os.ReadFile(t.RootCAFile)
+			if err != nil {
+				return nil, fmt.Errorf("tls: failed to read root CAs: %w", err)
+			}
+			pem = append(pem, '\n')
+			pem = append(pem, data...)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New("tls: no root CA certificate found")
+		}
+	}
+
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return nil, errors.New("tls: CertFile and KeyFile must be set together")
+	}
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: failed to load client certificate: %w", err)
+		}
+		config.Certificates = append(slices.Clone(config.Certificates), cert)
+	}
+
+	if len(t.PinnedSPKI) > 0 {
+		pins := make(map[string]bool, len(t.PinnedSPKI))
+		for _, pin := range t.PinnedSPKI {
+			sum, err := base64.StdEncoding.DecodeString(pin)
+			if err != nil || len(sum) != sha256.Size {
+				return nil, fmt.Errorf("tls: invalid SPKI pin %q", pin)
+			}
+			pins[pin] = true
+		}
+		verified := !t.InsecureSkipVerify
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			// Match the verified chains only: the certificates sent by the
+			// server may carry any pinned certificate besides its chain.
+			// Without verification, only the leaf is the server's.
+			certs := state.PeerCertificates[:min(1, len(state.PeerCertificates))]
+			if verified {
+				certs = slices.Concat(state.VerifiedChains...)
+			}
+			for _, cert := range certs {
+				if pins[SPKIPin(cert)] {
+					return nil
+				}
+			}
+			return errors.New("tls: no pinned public key in the server certificate chain")
+		}
+	}
+	return config, nil
+}
+
+// checkCipherSuite checks that the cipher suite id is known and, unless
+// allowInsecure, secure
+func checkCipherSuite(id uint16, allowInsecure bool) error {
+	for _, suite := range tls.CipherSuites() {
+		if suite.ID == id {
+			return nil
+		}
+	}
+	for _, suite := range tls.InsecureCipherSuites() {
+		if suite.ID == id {
+			if allowInsecure {
+				return nil
+			}
+			return fmt.Errorf("tls: insecure cipher suite %s requires DangerouslyAllowInsecure", suite.Name)
+		}
+	}
+	return fmt.Errorf("tls: unknown cipher suite %#04x", id)
+}
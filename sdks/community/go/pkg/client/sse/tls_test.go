@@ -0,0 +1,222 @@
+package sse
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTLSServer starts a TLS server answering capability requests with 404,
+// returning the PEM encoded certificate it presents
+func newTLSServer(t *testing.T, clientCAs *x509.CertPool) (*httptest.Server, []byte) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	if clientCAs != nil {
+		server.TLS = &tls.Config{ClientCAs: clientCAs, ClientAuth: tls.RequireAndVerifyClientCert}
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+}
+
+// newClientCertificate writes a self-signed client certificate and its key
+// to dir, returning the certificate
+func newClientCertificate(t *testing.T, dir string) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "client.crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "client.key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+// newCertificate issues a certificate for the test servers, a CA when
+// parent is nil, returning it with its key
+func newCertificate(t *testing.T, name string, parent *tls.Certificate) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	issuer, signer := template, any(key)
+	if parent == nil {
+		template.KeyUsage |= x509.KeyUsageCertSign
+		template.BasicConstraintsValid, template.IsCA = true, true
+	} else {
+		issuer, signer = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, signer)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: cert}
+}
+
+func TestClientTLS(t *testing.T) {
+	server, serverPEM := newTLSServer(t, nil)
+	capabilities := func(config *TLSConfig) error {
+		_, err := NewClient(Config{Endpoint: server.URL, Logger: quietClient("").logger, TLS: config}).Capabilities(context.Background())
+		return err
+	}
+
+	assert.Error(t, capabilities(nil), "unknown roots are refused")
+	assert.NoError(t, capabilities(&TLSConfig{RootCAPEM: serverPEM}))
+	assert.NoError(t, capabilities(&TLSConfig{InsecureSkipVerify: true, DangerouslyAllowInsecure: true}))
+
+	t.Run("pins", func(t *testing.T) {
+		pin := SPKIPin(server.Certificate())
+		assert.NoError(t, capabilities(&TLSConfig{RootCAPEM: serverPEM, PinnedSPKI: []string{pin}}))
+		other := SPKIPin(&x509.Certificate{RawSubjectPublicKeyInfo: []byte("other")})
+		err := capabilities(&TLSConfig{RootCAPEM: serverPEM, PinnedSPKI: []string{other}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no pinned public key")
+		err = capabilities(&TLSConfig{InsecureSkipVerify: true, DangerouslyAllowInsecure: true, PinnedSPKI: []string{other}})
+		assert.Error(t, err, "pins are checked even when skipping verification")
+	})
+
+	t.Run("pins match the verified chain", func(t *testing.T) {
+		pinned := newCertificate(t, "pinned CA", nil)
+		other := newCertificate(t, "other CA", nil)
+		roots := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: pinned.Leaf.Raw})
+		roots = append(roots, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: other.Leaf.Raw})...)
+		serve := func(cert tls.Certificate) *httptest.Server {
+			server := httptest.NewUnstartedServer(http.NotFoundHandler())
+			server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+			server.StartTLS()
+			t.Cleanup(server.Close)
+			return server
+		}
+		capabilities := func(server *httptest.Server) error {
+			config := &TLSConfig{RootCAPEM: roots, PinnedSPKI: []string{SPKIPin(pinned.Leaf)}}
+			_, err := NewClient(Config{Endpoint: server.URL, Logger: quietClient("").logger, TLS: config}).Capabilities(context.Background())
+			return err
+		}
+
+		assert.NoError(t, capabilities(serve(newCertificate(t, "server", &pinned))))
+
+		// A server of another CA sending the pinned certificate along with
+		// its chain is refused
+		forged := newCertificate(t, "server", &other)
+		forged.Certificate = append(forged.Certificate, pinned.Leaf.Raw)
+		err := capabilities(serve(forged))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no pinned public key")
+	})
+
+	t.Run("invalid settings fail requests", func(t *testing.T) {
+		err := capabilities(&TLSConfig{InsecureSkipVerify: true})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid TLS settings")
+	})
+}
+
+func TestClientMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	clientCert := newClientCertificate(t, dir)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server, serverPEM := newTLSServer(t, clientCAs)
+
+	capabilities := func(config *TLSConfig) error {
+		_, err := NewClient(Config{Endpoint: server.URL, Logger: quietClient("").logger, TLS: config}).Capabilities(context.Background())
+		return err
+	}
+	assert.Error(t, capabilities(&TLSConfig{RootCAPEM: serverPEM}), "clients without certificate are refused")
+	assert.NoError(t, capabilities(&TLSConfig{
+		RootCAPEM: serverPEM,
+		CertFile:  filepath.Join(dir, "client.crt"),
+		KeyFile:   filepath.Join(dir, "client.key"),
+	}))
+}
+
+func TestTLSConfigValidate(t *testing.T) {
+	dir := t.TempDir()
+	newClientCertificate(t, dir)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "empty.pem"), nil, 0o600))
+
+	for name, config := range map[string]TLSConfig{
+		"insecure skip verify": {InsecureSkipVerify: true},
+		"old version":          {MinVersion: tls.VersionTLS10},
+		"insecure cipher":      {CipherSuites: []uint16{tls.TLS_RSA_WITH_RC4_128_SHA}},
+		"unknown cipher":       {CipherSuites: []uint16{0xffff}},
+		"missing root file":    {RootCAFile: filepath.Join(dir, "missing.pem")},
+		"no root certificate":  {RootCAFile: filepath.Join(dir, "empty.pem")},
+		"certificate only":     {CertFile: filepath.Join(dir, "client.crt")},
+		"key mismatch":         {CertFile: filepath.Join(dir, "client.crt"), KeyFile: filepath.Join(dir, "client.crt")},
+		"malformed pin":        {PinnedSPKI: []string{"not a pin"}},
+		"short pin":            {PinnedSPKI: []string{"c2hvcnQ="}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, config.Validate())
+			assert.Error(t, Config{TLS: &config}.Validate())
+		})
+	}
+
+	for name, config := range map[string]TLSConfig{
+		"defaults":           {},
+		"dangerous insecure": {InsecureSkipVerify: true, DangerouslyAllowInsecure: true},
+		"dangerous version":  {MinVersion: tls.VersionTLS10, DangerouslyAllowInsecure: true},
+		"secure cipher":      {CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}},
+		"client certificate": {CertFile: filepath.Join(dir, "client.crt"), KeyFile: filepath.Join(dir, "client.key")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.NoError(t, config.Validate())
+		})
+	}
+
+	tlsConfig, err := (&TLSConfig{}).ClientConfig()
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+}
+
+func TestReconfigureTLS(t *testing.T) {
+	server, serverPEM := newTLSServer(t, nil)
+	client := NewClient(Config{Endpoint: server.URL, Logger: quietClient("").logger})
+	_, err := client.Capabilities(context.Background())
+	require.Error(t, err)
+
+	require.Error(t, client.Reconfigure(Config{Endpoint: server.URL, TLS: &TLSConfig{InsecureSkipVerify: true}}, false))
+	require.NoError(t, client.Reconfigure(Config{Endpoint: server.URL, TLS: &TLSConfig{RootCAPEM: serverPEM}}, false))
+	_, err = client.Capabilities(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, Config{}.needsReconnect(Config{TLS: &TLSConfig{}}))
+	assert.False(t, Config{TLS: &TLSConfig{RootCAPEM: serverPEM}}.needsReconnect(Config{TLS: &TLSConfig{RootCAPEM: serverPEM}}))
+}
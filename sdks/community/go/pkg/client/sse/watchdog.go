@@ -0,0 +1,75 @@
+package sse
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// missedHeartbeats is the number of heartbeat intervals without events after
+// which a run is considered stalled, unless StallTimeout is set
+const missedHeartbeats = 3
+
+// ErrRunStalled ends a stream whose run sent no event, heartbeats included,
+// for longer than its stall timeout
+var ErrRunStalled = errors.New("run stalled")
+
+// watchdog tracks the liveness of a run from the frames of its stream
+type watchdog struct {
+	// timeout is the configured stall timeout; interval is the heartbeat
+	// interval requested or last announced by the server
+	timeout  time.Duration
+	interval time.Duration
+	last     time.Time
+}
+
+func newWatchdog(opts StreamOptions, now time.Time) *watchdog {
+	return &watchdog{timeout: opts.StallTimeout, interval: opts.Heartbeat, last: now}
+}
+
+// stallTimeout returns the time after which a silent run is stalled (0 =
+// never)
+func (w *watchdog) stallTimeout() time.Duration {
+	if w.timeout > 0 {
+		return w.timeout
+	}
+	return missedHeartbeats * w.interval
+}
+
+// expired returns a channel firing when the run stalls, or nil when the
+// watchdog is disabled
+func (w *watchdog) expired() <-chan time.Time {
+	timeout := w.stallTimeout()
+	if timeout <= 0 {
+		return nil
+	}
+	return time.After(time.Until(w.last.Add(timeout)))
+}
+
+// observe records a frame, adopting the interval announced by heartbeats
+func (w *watchdog) observe(frame Frame) {
+	w.last = frame.Timestamp
+	if !bytes.Contains(frame.Data, []byte(events.HeartbeatEventName)) {
+		return
+	}
+	var event struct {
+		Type  events.EventType `json:"type"`
+		Name  string           `json:"name"`
+		Value events.Heartbeat `json:"value"`
+	}
+	if json.Unmarshal(frame.Data, &event) != nil || event.Type != events.EventTypeCustom || event.Name != events.HeartbeatEventName {
+		return
+	}
+	if event.Value.IntervalMs > 0 {
+		w.interval = time.Duration(event.Value.IntervalMs) * time.Millisecond
+	}
+}
+
+// heartbeatHeader formats an interval for events.HeartbeatHeader
+func heartbeatHeader(interval time.Duration) string {
+	return strconv.FormatFloat(interval.Seconds(), 'f', -1, 64)
+}
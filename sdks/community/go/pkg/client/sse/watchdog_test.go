@@ -0,0 +1,118 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// newStallingServer streams the given frames, then keeps the stream open
+// without sending anything
+func newStallingServer(t *testing.T, header chan<- string, frames ...string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if header != nil {
+			header <- r.Header.Get(events.HeartbeatHeader)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		for _, frame := range frames {
+			fmt.Fprintf(w, "data: %s\n\n", frame)
+			w.(http.Flusher).Flush()
+		}
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// drain reads a stream until it ends, returning the number of frames and
+// the first error
+func drain(t *testing.T, frames <-chan Frame, errs <-chan error) (int, error) {
+	t.Helper()
+	count := 0
+	for frames != nil || errs != nil {
+		select {
+		case _, ok := <-frames:
+			if !ok {
+				frames = nil
+				continue
+			}
+			count++
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			return count, err
+		case <-time.After(5 * time.Second):
+			t.Fatal("stream did not end")
+		}
+	}
+	return count, nil
+}
+
+func quietClient(endpoint string) *Client {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewClient(Config{Endpoint: endpoint, Logger: logger})
+}
+
+func TestStreamWatchdog(t *testing.T) {
+	started := `{"type":"RUN_STARTED","threadId":"thread-1","runId":"run-1"}`
+	heartbeat := `{"type":"CUSTOM","name":"heartbeat","value":{"intervalMs":40}}`
+
+	t.Run("requested heartbeats", func(t *testing.T) {
+		header := make(chan string, 1)
+		server := newStallingServer(t, header, started, heartbeat)
+		frames, errs, err := quietClient(server.URL).Stream(StreamOptions{Payload: newTestRunAgentInput(), Heartbeat: 40 * time.Millisecond})
+		require.NoError(t, err)
+		assert.Equal(t, "0.04", <-header)
+
+		start := time.Now()
+		count, err := drain(t, frames, errs)
+		assert.Equal(t, 2, count)
+		assert.True(t, errors.Is(err, ErrRunStalled), "got %v", err)
+		assert.GreaterOrEqual(t, time.Since(start), 3*40*time.Millisecond-10*time.Millisecond)
+	})
+
+	t.Run("announced heartbeats", func(t *testing.T) {
+		server := newStallingServer(t, nil, started, heartbeat)
+		frames, errs, err := quietClient(server.URL).Stream(StreamOptions{Payload: newTestRunAgentInput()})
+		require.NoError(t, err)
+		_, err = drain(t, frames, errs)
+		assert.True(t, errors.Is(err, ErrRunStalled), "got %v", err)
+	})
+
+	t.Run("stall timeout", func(t *testing.T) {
+		server := newStallingServer(t, nil, started)
+		frames, errs, err := quietClient(server.URL).Stream(StreamOptions{Payload: newTestRunAgentInput(), StallTimeout: 50 * time.Millisecond})
+		require.NoError(t, err)
+		count, err := drain(t, frames, errs)
+		assert.Equal(t, 1, count)
+		assert.True(t, errors.Is(err, ErrRunStalled), "got %v", err)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		header := make(chan string, 1)
+		server := newStallingServer(t, header, started)
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		frames, errs, err := quietClient(server.URL).Stream(StreamOptions{Context: ctx, Payload: newTestRunAgentInput()})
+		require.NoError(t, err)
+		assert.Empty(t, <-header)
+		count, err := drain(t, frames, errs)
+		assert.Equal(t, 1, count)
+		assert.NoError(t, err, "without heartbeats the stream waits for the run")
+	})
+}
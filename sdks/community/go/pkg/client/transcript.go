@@ -0,0 +1,140 @@
+package client
+
+import (
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/jsonpatch"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/session"
+)
+
+// transcript rebuilds the messages and state of a thread from the events of
+// a run
+type transcript struct {
+	thread *session.Session
+	state  any
+
+	// deleted holds the IDs of the messages MESSAGE_DELETED removed
+	deleted []string
+
+	// lastMessageID and lastToolCallID are continued by the chunk events
+	// leaving out their ID
+	lastMessageID  string
+	lastToolCallID string
+}
+
+func newTranscript(messages []types.Message, state any) *transcript {
+	thread := session.New("")
+	thread.AppendMessages(messages...)
+	return &transcript{thread: thread, state: state}
+}
+
+// messages returns the messages of the thread
+func (t *transcript) messages() []types.Message {
+	return t.thread.Messages
+}
+
+// apply updates the transcript with an event
+func (t *transcript) apply(event events.Event) error {
+	switch e := event.(type) {
+	case *events.TextMessageStartEvent:
+		t.message(e.MessageID, deref(e.Role))
+	case *events.TextMessageContentEvent:
+		t.appendContent(e.MessageID, e.Delta)
+	case *events.TextMessageChunkEvent:
+		id := deref(e.MessageID)
+		if id == "" {
+			id = t.lastMessageID
+		}
+		t.message(id, deref(e.Role))
+		t.appendContent(id, deref(e.Delta))
+	case *events.ToolCallStartEvent:
+		t.toolCall(e.ToolCallID, e.ToolCallName, deref(e.ParentMessageID))
+	case *events.ToolCallArgsEvent:
+		t.appendArgs(e.ToolCallID, e.Delta)
+	case *events.ToolCallChunkEvent:
+		id := deref(e.ToolCallID)
+		if id == "" {
+			id = t.lastToolCallID
+		}
+		t.toolCall(id, deref(e.ToolCallName), deref(e.ParentMessageID))
+		t.appendArgs(id, deref(e.Delta))
+	case *events.ToolCallResultEvent:
+		t.thread.AppendMessages(types.Message{ID: e.MessageID, Role: types.RoleTool, Content: e.Content, ToolCallID: e.ToolCallID})
+	case *events.MessageDeletedEvent:
+		if t.thread.DeleteMessage(e.MessageID) {
+			t.deleted = append(t.deleted, e.MessageID)
+		}
+	case *events.StateSnapshotEvent:
+		t.state = e.Snapshot
+	case *events.StateDeltaEvent:
+		state, err := jsonpatch.Apply(t.state, e.Delta)
+		if err != nil {
+			return err
+		}
+		t.state = state
+	default:
+		t.thread.ApplyEvent(event)
+	}
+	return nil
+}
+
+// message returns the message with the given ID, adding it with role
+// (defaulting to assistant) if it is new
+func (t *transcript) message(id, role string) *types.Message {
+	if id == "" {
+		return nil
+	}
+	t.lastMessageID = id
+	for i := range t.thread.Messages {
+		if t.thread.Messages[i].ID == id {
+			return &t.thread.Messages[i]
+		}
+	}
+	if role == "" {
+		role = string(types.RoleAssistant)
+	}
+	t.thread.Messages = append(t.thread.Messages, types.Message{ID: id, Role: types.Role(role)})
+	return &t.thread.Messages[len(t.thread.Messages)-1]
+}
+
+func (t *transcript) appendContent(id, delta string) {
+	if message := t.message(id, ""); message != nil && delta != "" {
+		content, _ := message.Content.(string)
+		message.Content = content + delta
+	}
+}
+
+// toolCall adds a tool call to its parent message, or to a message of its
+// own when it has none, unless it is known already
+func (t *transcript) toolCall(id, name, parentMessageID string) {
+	if id == "" || t.findToolCall(id) != nil {
+		return
+	}
+	t.lastToolCallID = id
+	if parentMessageID == "" {
+		parentMessageID = id
+	}
+	message := t.message(parentMessageID, "")
+	message.ToolCalls = append(message.ToolCalls, types.ToolCall{
+		ID:       id,
+		Type:     types.ToolCallTypeFunction,
+		Function: types.FunctionCall{Name: name},
+	})
+}
+
+func (t *transcript) appendArgs(id, delta string) {
+	if call := t.findToolCall(id); call != nil {
+		call.Function.Arguments += delta
+	}
+}
+
+func (t *transcript) findToolCall(id string) *types.ToolCall {
+	for i := range t.thread.Messages {
+		for j := range t.thread.Messages[i].ToolCalls {
+			if t.thread.Messages[i].ToolCalls[j].ID == id {
+				return &t.thread.Messages[i].ToolCalls[j]
+			}
+		}
+	}
+	return nil
+}
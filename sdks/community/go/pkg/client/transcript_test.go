@@ -0,0 +1,58 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+func TestTranscript(t *testing.T) {
+	s := func(v string) *string { return &v }
+	transcript := newTranscript([]types.Message{
+		{ID: "user-1", Role: types.RoleUser, Content: "What's the weather?"},
+		{ID: "note-1", Role: types.RoleAssistant, Content: "stale"},
+	}, nil)
+
+	textChunk := events.NewTextMessageChunkEvent(s("msg-1"), s("assistant"), s("Let me "))
+	textChunk2 := events.NewTextMessageChunkEvent(nil, nil, s("check."))
+	argsChunk := events.NewToolCallChunkEvent()
+	argsChunk.ToolCallID, argsChunk.ToolCallName, argsChunk.Delta = s("call-2"), s("forecast"), s(`{"days":`)
+	argsChunk2 := events.NewToolCallChunkEvent()
+	argsChunk2.Delta = s("3}")
+	for _, event := range []events.Event{
+		textChunk,
+		textChunk2,
+		events.NewToolCallStartEvent("call-1", "weather", events.WithParentMessageID("msg-1")),
+		events.NewToolCallArgsEvent("call-1", `{"city":`),
+		events.NewToolCallArgsEvent("call-1", `"Paris"}`),
+		events.NewToolCallEndEvent("call-1"),
+		events.NewToolCallResultEvent("result-1", "call-1", "sunny"),
+		argsChunk,
+		argsChunk2,
+		events.NewMessageDeletedEvent("note-1"),
+		events.NewStateSnapshotEvent(map[string]any{"city": "Paris"}),
+		events.NewStateDeltaEvent([]events.JSONPatchOperation{{Op: "add", Path: "/units", Value: "C"}}),
+	} {
+		require.NoError(t, transcript.apply(event))
+	}
+
+	assert.Equal(t, []types.Message{
+		{ID: "user-1", Role: types.RoleUser, Content: "What's the weather?"},
+		{ID: "msg-1", Role: types.RoleAssistant, Content: "Let me check.", ToolCalls: []types.ToolCall{
+			{ID: "call-1", Type: types.ToolCallTypeFunction, Function: types.FunctionCall{Name: "weather", Arguments: `{"city":"Paris"}`}},
+		}},
+		{ID: "result-1", Role: types.RoleTool, Content: "sunny", ToolCallID: "call-1"},
+		{ID: "call-2", Role: types.RoleAssistant, ToolCalls: []types.ToolCall{
+			{ID: "call-2", Type: types.ToolCallTypeFunction, Function: types.FunctionCall{Name: "forecast", Arguments: `{"days":3}`}},
+		}},
+	}, transcript.messages())
+	assert.Equal(t, []string{"note-1"}, transcript.deleted)
+	assert.Equal(t, map[string]any{"city": "Paris", "units": "C"}, transcript.state)
+
+	require.NoError(t, transcript.apply(events.NewMessageUpdatedEvent("msg-1", "Checked.")))
+	assert.Equal(t, "Checked.", transcript.messages()[1].Content)
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/internal/cloud"
+)
+
+// AWSConfig configures an AWSResolver. Empty fields default to the
+// environment variables of the AWS CLI.
+type AWSConfig struct {
+	// Region of the secrets (defaults to AWS_REGION, then AWS_DEFAULT_REGION)
+	Region string `json:"region"`
+
+	// AccessKeyID, SecretAccessKey and SessionToken sign the requests
+	// (default to AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN)
+	AccessKeyID     string `json:"-"`
+	SecretAccessKey string `json:"-"`
+	SessionToken    string `json:"-"`
+
+	// Endpoint of Secrets Manager (defaults to the regional endpoint)
+	Endpoint string `json:"endpoint"`
+
+	// Client sends the requests (defaults to http.DefaultClient)
+	Client *http.Client `json:"-"`
+}
+
+// AWSResolver resolves awssm://SECRET#FIELD references with AWS Secrets
+// Manager. SECRET is the name or ARN of the secret, and FIELD selects a
+// field of a secret stored as a JSON object. Secrets Manager grants no
+// leases, set Options.SecretRefresh to pick up rotations.
+type AWSResolver struct {
+	config AWSConfig
+	now    func() time.Time
+}
+
+// NewAWSResolver creates a resolver reading from AWS Secrets Manager
+func NewAWSResolver(config AWSConfig) *AWSResolver {
+	if config.Region == "" {
+		config.Region = os.Getenv("AWS_REGION")
+	}
+	if config.Region == "" {
+		config.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if config.AccessKeyID == "" {
+		config.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		config.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		config.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "https://secretsmanager." + config.Region + ".amazonaws.com"
+	}
+	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	return &AWSResolver{config: config, now: time.Now}
+}
+
+func (r *AWSResolver) Scheme() string {
+	return "awssm"
+}
+
+func (r *AWSResolver) Resolve(ctx context.Context, ref Ref) (Secret, error) {
+	if r.config.Region == "" || r.config.AccessKeyID == "" {
+		return Secret{}, errors.New("aws secrets manager: no region or credentials configured")
+	}
+	body, err := json.Marshal(map[string]string{"SecretId": ref.Path})
+	if err != nil {
+		return Secret{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.config.Endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return Secret{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	if r.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", r.config.SessionToken)
+	}
+	cloud.SignV4(req, body, r.config.AccessKeyID, r.config.SecretAccessKey, r.config.Region, "secretsmanager", r.now())
+
+	resp, err := r.config.Client.Do(req)
+	if err != nil {
+		return Secret{}, fmt.Errorf("aws secrets manager request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&failure)
+		return Secret{}, fmt.Errorf("aws secrets manager %s: %s %s %s", ref.Path, resp.Status, failure.Type, failure.Message)
+	}
+
+	var out struct {
+		SecretString *string `json:"SecretString"`
+		SecretBinary []byte  `json:"SecretBinary"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Secret{}, fmt.Errorf("invalid aws secrets manager response: %w", err)
+	}
+	value := string(out.SecretBinary)
+	if out.SecretString != nil {
+		value = *out.SecretString
+	}
+	value, err = selectKey(value, ref.Key)
+	if err != nil {
+		return Secret{}, fmt.Errorf("aws secrets manager %s: %w", ref.Path, err)
+	}
+	return Secret{Value: value}, nil
+}
@@ -0,0 +1,352 @@
+// Package config merges settings from layered sources, such as defaults, a
+// file, a remote key-value store, the environment and command line flags,
+// and keeps them up to date while the process runs. Fleets of agents can
+// then change log levels, thresholds or endpoints in etcd or Consul without
+// a restart.
+//
+// Settings are flat string values under dotted keys, e.g. "log.level".
+// Values that reference a secret, such as "vault://secret/data/agent#api_key",
+// are replaced by the secret when a Resolver for the scheme is configured,
+// so API keys and TLS material never live in files or the environment.
+// Sources are given in increasing precedence, a key set by a later source
+// overriding the same key of earlier ones. The usual order is:
+//
+//	cfg, err := config.New(ctx, config.Options{},
+//		config.NewMapSource("defaults", defaults),
+//		config.NewFileSource("agent.json", 0),
+//		config.NewConsulSource(config.ConsulConfig{Prefix: "agents/prod/"}),
+//		config.NewEnvSource("AGUI_"),
+//		config.NewFlagSource(flag.CommandLine),
+//	)
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+)
+
+// Source provides settings
+type Source interface {
+	// Name identifies the source in logs and changes
+	Name() string
+
+	// Load returns the current settings of the source
+	Load(ctx context.Context) (map[string]string, error)
+}
+
+// Watcher is a source that can tell when its settings change
+type Watcher interface {
+	Source
+
+	// Watch calls changed whenever the settings may have changed, until ctx
+	// is done or watching fails. Spurious calls are allowed.
+	Watch(ctx context.Context, changed func()) error
+}
+
+// Change is a setting that changed value. Old or New is empty when the
+// setting was added or removed.
+type Change struct {
+	Key    string
+	Old    string
+	New    string
+	Source string
+}
+
+// Options configure a Config
+type Options struct {
+	// Logger receives watch failures and invalid values (defaults to slog.Default())
+	Logger *slog.Logger
+
+	// RetryDelay is the first delay before watching a source again after it
+	// failed, doubled on each failure up to a minute (defaults to one second)
+	RetryDelay time.Duration
+
+	// Resolvers replace the values referencing a secret of their scheme
+	Resolvers []Resolver
+
+	// SecretRefresh is how often Watch fetches again the secrets that carry
+	// no lease, to pick up rotations (defaults to never)
+	SecretRefresh time.Duration
+
+	// Clock times the watch retries and the secret refreshes (defaults to
+	// the real clock)
+	Clock clock.Clock
+}
+
+// Config is the merged settings of its sources. It is safe for concurrent use.
+type Config struct {
+	sources   []Source
+	opts      Options
+	resolvers map[string]Resolver
+
+	// update serializes changes to the settings, which may wait on secret
+	// resolvers, and guards secrets
+	update  sync.Mutex
+	secrets map[string]*cachedSecret
+	wake    chan struct{}
+
+	mu        sync.RWMutex
+	layers    []map[string]string
+	values    map[string]string
+	listeners map[int]listener
+	nextID    int
+}
+
+type listener struct {
+	prefix string
+	fn     func([]Change)
+}
+
+// New loads every source and merges their settings
+func New(ctx context.Context, opts Options, sources ...Source) (*Config, error) {
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = time.Second
+	}
+	opts.Clock = clock.Or(opts.Clock)
+	c := &Config{
+		sources:   sources,
+		opts:      opts,
+		resolvers: make(map[string]Resolver),
+		secrets:   make(map[string]*cachedSecret),
+		wake:      make(chan struct{}, 1),
+		layers:    make([]map[string]string, len(sources)),
+		listeners: make(map[int]listener),
+	}
+	for _, r := range opts.Resolvers {
+		c.resolvers[r.Scheme()] = r
+	}
+	for i, source := range sources {
+		layer, err := source.Load(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config from %s: %w", source.Name(), err)
+		}
+		c.layers[i] = layer
+	}
+	values, err := c.resolve(ctx, c.merge(), true)
+	if err != nil {
+		return nil, err
+	}
+	c.values = values
+	return c, nil
+}
+
+// Lookup returns the value of a setting and whether any source sets it
+func (c *Config) Lookup(key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, ok := c.values[key]
+	return value, ok
+}
+
+// String returns the value of a setting, or def when it is not set
+func (c *Config) String(key, def string) string {
+	if value, ok := c.Lookup(key); ok {
+		return value
+	}
+	return def
+}
+
+// Int returns a setting as an int, or def when it is not set or invalid
+func (c *Config) Int(key string, def int) int {
+	return parse(c, key, def, strconv.Atoi)
+}
+
+// Float returns a setting as a float64, or def when it is not set or invalid
+func (c *Config) Float(key string, def float64) float64 {
+	return parse(c, key, def, func(s string) (float64, error) { return strconv.ParseFloat(s, 64) })
+}
+
+// Bool returns a setting as a bool, or def when it is not set or invalid
+func (c *Config) Bool(key string, def bool) bool {
+	return parse(c, key, def, strconv.ParseBool)
+}
+
+// Duration returns a setting as a duration such as "1m30s", or def when it
+// is not set or invalid
+func (c *Config) Duration(key string, def time.Duration) time.Duration {
+	return parse(c, key, def, time.ParseDuration)
+}
+
+func parse[T any](c *Config, key string, def T, conv func(string) (T, error)) T {
+	value, ok := c.Lookup(key)
+	if !ok {
+		return def
+	}
+	v, err := conv(strings.TrimSpace(value))
+	if err != nil {
+		c.opts.Logger.Warn("invalid config value, using the default", "key", key, "value", value, "default", def)
+		return def
+	}
+	return v
+}
+
+// All returns a copy of the merged settings
+func (c *Config) All() map[string]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return copyMap(c.values)
+}
+
+// OnChange calls fn with the changes to the settings whose key starts with
+// prefix, every time a watched source changes them. An empty prefix matches
+// every setting. fn runs on the watching goroutine and should not block.
+// The returned function removes the callback.
+func (c *Config) OnChange(prefix string, fn func([]Change)) func() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := c.nextID
+	c.nextID++
+	c.listeners[id] = listener{prefix: prefix, fn: fn}
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.listeners, id)
+	}
+}
+
+// Watch watches the sources that implement Watcher until ctx is done,
+// reloading a source whenever it changes. A source whose watch fails keeps
+// its last settings and is watched again after a delay. Secrets are renewed
+// or fetched again before they expire. Watch returns immediately.
+func (c *Config) Watch(ctx context.Context) {
+	for i, source := range c.sources {
+		if w, ok := source.(Watcher); ok {
+			go c.watch(ctx, i, w)
+		}
+	}
+	if len(c.resolvers) > 0 {
+		go c.refreshSecrets(ctx)
+	}
+}
+
+func (c *Config) watch(ctx context.Context, i int, w Watcher) {
+	delay := c.opts.RetryDelay
+	for {
+		err := w.Watch(ctx, func() { c.reload(ctx, i) })
+		if ctx.Err() != nil {
+			return
+		}
+		c.opts.Logger.Warn("config watch failed", "source", w.Name(), "error", err, "retry", delay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.opts.Clock.After(delay):
+		}
+		delay = min(2*delay, time.Minute)
+		// Changes made while the watch was down are picked up on reload
+		c.reload(ctx, i)
+	}
+}
+
+// Reload loads every source again, e.g. on SIGHUP for sources that cannot
+// be watched, and notifies the callbacks of the changes
+func (c *Config) Reload(ctx context.Context) error {
+	for i, source := range c.sources {
+		layer, err := source.Load(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load config from %s: %w", source.Name(), err)
+		}
+		c.apply(ctx, i, layer)
+	}
+	return nil
+}
+
+// reload loads one source again and notifies the callbacks of the changes
+func (c *Config) reload(ctx context.Context, i int) {
+	layer, err := c.sources[i].Load(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			c.opts.Logger.Warn("config reload failed", "source", c.sources[i].Name(), "error", err)
+		}
+		return
+	}
+	c.apply(ctx, i, layer)
+}
+
+// apply replaces the settings of source i, or only resolves the secrets
+// again when i is negative, and notifies the callbacks of the changes
+func (c *Config) apply(ctx context.Context, i int, layer map[string]string) {
+	c.update.Lock()
+	c.mu.Lock()
+	if i >= 0 {
+		c.layers[i] = layer
+	}
+	merged := c.merge()
+	c.mu.Unlock()
+
+	// Secrets that cannot be resolved keep their last value
+	values, _ := c.resolve(ctx, merged, false)
+
+	c.mu.Lock()
+	old := c.values
+	c.values = values
+	changes := c.diff(old, c.values)
+	var notify []func()
+	for _, l := range c.listeners {
+		var matched []Change
+		for _, change := range changes {
+			if strings.HasPrefix(change.Key, l.prefix) {
+				matched = append(matched, change)
+			}
+		}
+		if len(matched) > 0 {
+			fn := l.fn
+			notify = append(notify, func() { fn(matched) })
+		}
+	}
+	c.mu.Unlock()
+	c.update.Unlock()
+
+	for _, fn := range notify {
+		fn()
+	}
+}
+
+// merge layers the sources, later ones taking precedence. Callers hold mu.
+func (c *Config) merge() map[string]string {
+	values := make(map[string]string)
+	for _, layer := range c.layers {
+		for key, value := range layer {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// diff returns the changes from old to values, sorted by key. Callers hold mu.
+func (c *Config) diff(old, values map[string]string) []Change {
+	var changes []Change
+	for key, value := range values {
+		if prev, ok := old[key]; !ok || prev != value {
+			changes = append(changes, Change{Key: key, Old: prev, New: value, Source: c.sourceOf(key)})
+		}
+	}
+	for key, prev := range old {
+		if _, ok := values[key]; !ok {
+			changes = append(changes, Change{Key: key, Old: prev})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// sourceOf returns the name of the source a setting comes from. Callers hold mu.
+func (c *Config) sourceOf(key string) string {
+	for i := len(c.layers) - 1; i >= 0; i-- {
+		if _, ok := c.layers[i][key]; ok {
+			return c.sources[i].Name()
+		}
+	}
+	return ""
+}
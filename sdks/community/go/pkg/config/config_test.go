@@ -0,0 +1,393 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+)
+
+// kvServer is an in-memory key-value store whose changes can be awaited,
+// behind the fake etcd and Consul APIs
+type kvServer struct {
+	mu      sync.Mutex
+	values  map[string]string
+	index   uint64
+	changed chan struct{}
+}
+
+func newKVServer(values map[string]string) *kvServer {
+	return &kvServer{values: values, index: 1, changed: make(chan struct{})}
+}
+
+func (s *kvServer) Put(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+	s.index++
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *kvServer) snapshot(prefix string) (map[string]string, uint64, chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	values := make(map[string]string)
+	for key, value := range s.values {
+		if strings.HasPrefix(key, prefix) {
+			values[key] = value
+		}
+	}
+	return values, s.index, s.changed
+}
+
+// consulHandler serves the KV API of Consul with blocking queries
+func (s *kvServer) consulHandler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+		assert.Equal(t, "true", r.URL.Query().Get("recurse"))
+		values, index, changed := s.snapshot(prefix)
+		if wait := r.URL.Query().Get("index"); wait == strconv.FormatUint(index, 10) {
+			select {
+			case <-changed:
+			case <-r.Context().Done():
+				return
+			}
+			values, index, _ = s.snapshot(prefix)
+		}
+
+		w.Header().Set("X-Consul-Index", strconv.FormatUint(index, 10))
+		if len(values) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		type kv struct {
+			Key   string
+			Value []byte
+		}
+		kvs := []kv{{Key: prefix}} // the folder itself
+		for key, value := range values {
+			kvs = append(kvs, kv{Key: key, Value: []byte(value)})
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(kvs))
+	})
+}
+
+// etcdHandler serves the range and watch calls of the etcd JSON gateway
+func (s *kvServer) etcdHandler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v3/kv/range", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Key []byte `json:"key"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		values, index, _ := s.snapshot(string(req.Key))
+		var kvs []etcdKV
+		for key, value := range values {
+			kvs = append(kvs, etcdKV{Key: []byte(key), Value: []byte(value)})
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]any{
+			"header": map[string]string{"revision": strconv.FormatUint(index, 10)},
+			"kvs":    kvs,
+		}))
+	})
+	mux.HandleFunc("/v3/watch", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Create struct {
+				Key           []byte `json:"key"`
+				StartRevision int64  `json:"start_revision,string"`
+			} `json:"create_request"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		fmt.Fprintln(w, `{"result":{"header":{},"created":true}}`)
+		w.(http.Flusher).Flush()
+
+		seen := uint64(req.Create.StartRevision - 1)
+		for {
+			_, index, changed := s.snapshot(string(req.Create.Key))
+			if index > seen {
+				seen = index
+				fmt.Fprintf(w, `{"result":{"header":{"revision":"%d"},"events":[{"kv":{}}]}}`+"\n", index)
+				w.(http.Flusher).Flush()
+			}
+			select {
+			case <-changed:
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+	return mux
+}
+
+// changes collects the changes passed to an OnChange callback
+type changes struct {
+	ch chan []Change
+}
+
+func newChanges(c *Config, prefix string) *changes {
+	cs := &changes{ch: make(chan []Change, 10)}
+	c.OnChange(prefix, func(changes []Change) { cs.ch <- changes })
+	return cs
+}
+
+func (cs *changes) next(t *testing.T) []Change {
+	t.Helper()
+	select {
+	case changes := <-cs.ch:
+		return changes
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change notified")
+		return nil
+	}
+}
+
+func TestPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"log": {"level": "info", "json": true}, "threshold": 0.5, "endpoint": "file"}`), 0o600))
+	t.Setenv("TESTCFG_ENDPOINT", "env")
+	t.Setenv("TESTCFG_LOG_LEVEL", "warn")
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.String("log.level", "error", "")
+	flags.String("endpoint", "flag-default", "")
+	require.NoError(t, flags.Parse([]string{"-log.level", "debug"}))
+
+	cfg, err := New(context.Background(), Options{},
+		NewMapSource("defaults", map[string]string{"log.level": "error", "retries": "3", "endpoint": "default"}),
+		NewFileSource(path, 0),
+		NewEnvSource("TESTCFG_"),
+		NewFlagSource(flags),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "debug", cfg.String("log.level", ""), "flags override the environment")
+	assert.Equal(t, "env", cfg.String("endpoint", ""), "flags left at their default do not override")
+	assert.Equal(t, 3, cfg.Int("retries", 0))
+	assert.Equal(t, 0.5, cfg.Float("threshold", 0))
+	assert.True(t, cfg.Bool("log.json", false))
+	assert.Equal(t, "fallback", cfg.String("missing", "fallback"))
+}
+
+func TestTypedDefaults(t *testing.T) {
+	cfg, err := New(context.Background(), Options{},
+		NewMapSource("defaults", map[string]string{"timeout": "1m30s", "retries": "many"}))
+	require.NoError(t, err)
+
+	assert.Equal(t, 90*time.Second, cfg.Duration("timeout", 0))
+	assert.Equal(t, 5, cfg.Int("retries", 5), "invalid values fall back to the default")
+	assert.Equal(t, time.Second, cfg.Duration("missing", time.Second))
+}
+
+func TestNewFailsOnSourceError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{not json`), 0o600))
+
+	_, err := New(context.Background(), Options{}, NewFileSource(path, 0))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), path)
+
+	_, err = New(context.Background(), Options{}, NewFlagSource(flag.NewFlagSet("test", flag.ContinueOnError)))
+	assert.Error(t, err, "flags must be parsed")
+}
+
+func TestFileSourceWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"log": {"level": "info"}, "retries": 3}`), 0o600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cfg, err := New(ctx, Options{}, NewFileSource(path, 10*time.Millisecond))
+	require.NoError(t, err)
+	logChanges := newChanges(cfg, "log.")
+	all := newChanges(cfg, "")
+	cfg.Watch(ctx)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"log": {"level": "debug", "format": "json"}}`), 0o600))
+
+	assert.Equal(t, []Change{
+		{Key: "log.format", New: "json", Source: "file " + path},
+		{Key: "log.level", Old: "info", New: "debug", Source: "file " + path},
+	}, logChanges.next(t))
+	assert.Len(t, all.next(t), 3, "retries was removed")
+	assert.Equal(t, "debug", cfg.String("log.level", ""))
+	_, ok := cfg.Lookup("retries")
+	assert.False(t, ok)
+}
+
+func TestOverriddenChangesAreNotNotified(t *testing.T) {
+	kv := newKVServer(map[string]string{"app/log/level": "info"})
+	srv := httptest.NewServer(kv.consulHandler(t))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cfg, err := New(ctx, Options{},
+		NewConsulSource(ConsulConfig{Address: srv.URL, Prefix: "app/"}),
+		NewMapSource("overrides", map[string]string{"log.level": "debug"}),
+	)
+	require.NoError(t, err)
+	all := newChanges(cfg, "")
+	cfg.Watch(ctx)
+
+	kv.Put("app/log/level", "warn")
+	kv.Put("app/retries", "5")
+
+	assert.Equal(t, []Change{{Key: "retries", New: "5", Source: "consul " + srv.URL + "/app/"}}, all.next(t))
+	assert.Equal(t, "debug", cfg.String("log.level", ""))
+}
+
+func TestConsulSource(t *testing.T) {
+	kv := newKVServer(map[string]string{"agents/prod/log/level": "info", "agents/prod/limits/tokens": "1000", "agents/dev/log/level": "debug"})
+	srv := httptest.NewServer(kv.consulHandler(t))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cfg, err := New(ctx, Options{}, NewConsulSource(ConsulConfig{Address: srv.URL, Prefix: "agents/prod/"}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"log.level": "info", "limits.tokens": "1000"}, cfg.All())
+
+	changes := newChanges(cfg, "limits.")
+	cfg.Watch(ctx)
+	kv.Put("agents/prod/limits/tokens", "2000")
+
+	assert.Equal(t, []Change{{Key: "limits.tokens", Old: "1000", New: "2000", Source: "consul " + srv.URL + "/agents/prod/"}}, changes.next(t))
+	assert.Equal(t, 2000, cfg.Int("limits.tokens", 0))
+}
+
+func TestConsulSourceMissingPrefix(t *testing.T) {
+	srv := httptest.NewServer(newKVServer(map[string]string{}).consulHandler(t))
+	defer srv.Close()
+
+	values, err := NewConsulSource(ConsulConfig{Address: srv.URL, Prefix: "none/"}).Load(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, values)
+}
+
+func TestEtcdSource(t *testing.T) {
+	kv := newKVServer(map[string]string{"/agents/prod/log/level": "info", "/agents/dev/log/level": "debug"})
+	srv := httptest.NewServer(kv.etcdHandler(t))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cfg, err := New(ctx, Options{}, NewEtcdSource(EtcdConfig{Endpoint: srv.URL, Prefix: "/agents/prod/"}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"log.level": "info"}, cfg.All())
+
+	changes := newChanges(cfg, "")
+	cfg.Watch(ctx)
+	kv.Put("/agents/prod/log/level", "warn")
+
+	got := changes.next(t)
+	assert.Equal(t, []Change{{Key: "log.level", Old: "info", New: "warn", Source: "etcd " + srv.URL + "/agents/prod/"}}, got)
+}
+
+func TestWatchRetriesFailedSource(t *testing.T) {
+	kv := newKVServer(map[string]string{"app/mode": "a"})
+	var mu sync.Mutex
+	failing := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fail := failing
+		mu.Unlock()
+		if fail {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		kv.consulHandler(t).ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	clk := &retryClock{Clock: clock.Real, waits: make(chan time.Duration, 1), fire: make(chan time.Time)}
+	cfg, err := New(ctx, Options{RetryDelay: 10 * time.Millisecond, Clock: clk, Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}, NewConsulSource(ConsulConfig{Address: srv.URL, Prefix: "app/"}))
+	require.NoError(t, err)
+	changes := newChanges(cfg, "")
+
+	mu.Lock()
+	failing = true
+	mu.Unlock()
+	cfg.Watch(ctx)
+	select {
+	case delay := <-clk.waits:
+		assert.Equal(t, 10*time.Millisecond, delay)
+	case <-time.After(time.Second):
+		t.Fatal("the failed watch was not retried")
+	}
+	assert.Equal(t, "a", cfg.String("mode", ""), "a failing source keeps its settings")
+
+	kv.Put("app/mode", "b")
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+	clk.fire <- time.Now()
+
+	assert.Equal(t, "b", changes.next(t)[0].New)
+}
+
+// retryClock hands the delays of watch retries to the test, which decides
+// when they elapse
+type retryClock struct {
+	clock.Clock
+	waits chan time.Duration
+	fire  chan time.Time
+}
+
+func (c *retryClock) After(d time.Duration) <-chan time.Time {
+	select {
+	case c.waits <- d:
+	default:
+	}
+	return c.fire
+}
+
+func TestOnChangeCancel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"a": "1"}`), 0o600))
+	cfg, err := New(context.Background(), Options{}, NewFileSource(path, 0))
+	require.NoError(t, err)
+
+	var got []string
+	cancel := cfg.OnChange("", func(changes []Change) {
+		for _, c := range changes {
+			got = append(got, c.Key+"="+c.New)
+		}
+	})
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"a": "2", "b": "1"}`), 0o600))
+	require.NoError(t, cfg.Reload(context.Background()))
+	cancel()
+	require.NoError(t, os.WriteFile(path, []byte(`{"a": "3"}`), 0o600))
+	require.NoError(t, cfg.Reload(context.Background()))
+
+	sort.Strings(got)
+	assert.Equal(t, []string{"a=2", "b=1"}, got)
+	assert.Equal(t, "3", cfg.String("a", ""))
+}
+
+func TestPrefixEnd(t *testing.T) {
+	assert.Equal(t, []byte("/agents/prod0"), prefixEnd([]byte("/agents/prod/")))
+	assert.Equal(t, []byte("b"), prefixEnd([]byte("a\xff")))
+	assert.Equal(t, []byte{0}, prefixEnd(nil))
+}
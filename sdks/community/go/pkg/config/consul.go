@@ -0,0 +1,163 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultConsulAddress is the HTTP API of a local Consul agent
+const defaultConsulAddress = "http://127.0.0.1:8500"
+
+// defaultConsulWait is how long a blocking query waits for a change
+const defaultConsulWait = 5 * time.Minute
+
+// ConsulConfig configures a ConsulSource
+type ConsulConfig struct {
+	// Address is the HTTP API of a Consul agent (defaults to http://127.0.0.1:8500)
+	Address string `json:"address"`
+
+	// Prefix selects the keys of the settings, e.g. "agents/prod/". The
+	// rest of a key with slashes turned into dots is the setting, so
+	// "agents/prod/log/level" sets "log.level".
+	Prefix string `json:"prefix"`
+
+	// Token is the ACL token, if the agent requires one
+	Token string `json:"-"`
+
+	// Datacenter to read from (defaults to the agent's)
+	Datacenter string `json:"datacenter"`
+
+	// Wait bounds each blocking query of Watch (defaults to 5 minutes)
+	Wait time.Duration `json:"wait"`
+
+	// Client sends the requests (defaults to http.DefaultClient). Its
+	// timeout must exceed Wait.
+	Client *http.Client `json:"-"`
+}
+
+// ConsulSource reads settings from the Consul KV store and watches them
+// with blocking queries
+type ConsulSource struct {
+	config ConsulConfig
+
+	mu    sync.Mutex
+	index uint64
+}
+
+// NewConsulSource creates a source of the keys under config.Prefix
+func NewConsulSource(config ConsulConfig) *ConsulSource {
+	if config.Address == "" {
+		config.Address = defaultConsulAddress
+	}
+	config.Address = strings.TrimSuffix(config.Address, "/")
+	if config.Wait <= 0 {
+		config.Wait = defaultConsulWait
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	return &ConsulSource{config: config}
+}
+
+func (s *ConsulSource) Name() string {
+	return "consul " + s.config.Address + "/" + s.config.Prefix
+}
+
+func (s *ConsulSource) Load(ctx context.Context) (map[string]string, error) {
+	values, index, err := s.get(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.index = index
+	s.mu.Unlock()
+	return values, nil
+}
+
+// Watch runs blocking queries from the index of the last Load, calling
+// changed whenever the index of the keys moves
+func (s *ConsulSource) Watch(ctx context.Context, changed func()) error {
+	s.mu.Lock()
+	// An index of 0 would make every query return at once
+	index := max(s.index, 1)
+	s.mu.Unlock()
+	for {
+		_, next, err := s.get(ctx, index)
+		if err != nil {
+			return err
+		}
+		if next != index {
+			changed()
+		}
+		if next < index {
+			// The index went backwards, e.g. after a snapshot restore
+			next = 0
+		}
+		index = max(next, 1)
+	}
+}
+
+// get reads the keys under the prefix. A non-zero index makes it a blocking
+// query returning once the keys change or the wait elapses.
+func (s *ConsulSource) get(ctx context.Context, index uint64) (map[string]string, uint64, error) {
+	query := url.Values{"recurse": {"true"}}
+	if s.config.Datacenter != "" {
+		query.Set("dc", s.config.Datacenter)
+	}
+	if index > 0 {
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", fmt.Sprintf("%ds", int(s.config.Wait.Seconds())))
+	}
+	u := s.config.Address + "/v1/kv/" + (&url.URL{Path: s.config.Prefix}).EscapedPath() + "?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if s.config.Token != "" {
+		req.Header.Set("X-Consul-Token", s.config.Token)
+	}
+
+	resp, err := s.config.Client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("consul request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	next, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	values := make(map[string]string)
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		// No key under the prefix yet
+		return values, next, nil
+	default:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, 0, fmt.Errorf("consul kv %s: %s: %s", s.config.Prefix, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var kvs []struct {
+		Key   string `json:"Key"`
+		Value []byte `json:"Value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&kvs); err != nil {
+		return nil, 0, fmt.Errorf("invalid consul response: %w", err)
+	}
+	for _, kv := range kvs {
+		// Keys ending in a slash are folders
+		if strings.HasSuffix(kv.Key, "/") {
+			continue
+		}
+		if key := settingKey(kv.Key, s.config.Prefix); key != "" {
+			values[key] = string(kv.Value)
+		}
+	}
+	return values, next, nil
+}
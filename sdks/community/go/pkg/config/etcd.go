@@ -0,0 +1,237 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultEtcdEndpoint is the client URL of a local etcd
+const defaultEtcdEndpoint = "http://127.0.0.1:2379"
+
+// EtcdConfig configures an EtcdSource
+type EtcdConfig struct {
+	// Endpoint is the client URL of an etcd member (defaults to http://127.0.0.1:2379)
+	Endpoint string `json:"endpoint"`
+
+	// Prefix selects the keys of the settings, e.g. "/agents/prod/". The
+	// rest of a key with slashes turned into dots is the setting, so
+	// "/agents/prod/log/level" sets "log.level".
+	Prefix string `json:"prefix"`
+
+	// Username and Password authenticate when etcd has auth enabled
+	Username string `json:"username"`
+	Password string `json:"-"`
+
+	// Client sends the requests (defaults to http.DefaultClient)
+	Client *http.Client `json:"-"`
+}
+
+// EtcdSource reads settings from etcd through its JSON gateway and watches
+// them for changes, so no etcd client library is needed
+type EtcdSource struct {
+	config EtcdConfig
+
+	mu       sync.Mutex
+	revision int64
+}
+
+// NewEtcdSource creates a source of the keys under config.Prefix
+func NewEtcdSource(config EtcdConfig) *EtcdSource {
+	if config.Endpoint == "" {
+		config.Endpoint = defaultEtcdEndpoint
+	}
+	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	return &EtcdSource{config: config}
+}
+
+func (s *EtcdSource) Name() string {
+	return "etcd " + s.config.Endpoint + s.config.Prefix
+}
+
+type etcdKV struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+type etcdHeader struct {
+	Revision int64 `json:"revision,string"`
+}
+
+func (s *EtcdSource) Load(ctx context.Context) (map[string]string, error) {
+	var resp struct {
+		Header etcdHeader `json:"header"`
+		Kvs    []etcdKV   `json:"kvs"`
+	}
+	body, err := s.post(ctx, "/v3/kv/range", s.keyRange(nil))
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("invalid etcd response: %w", err)
+	}
+
+	values := make(map[string]string, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		if key := settingKey(string(kv.Key), s.config.Prefix); key != "" {
+			values[key] = string(kv.Value)
+		}
+	}
+	s.mu.Lock()
+	s.revision = resp.Header.Revision
+	s.mu.Unlock()
+	return values, nil
+}
+
+// Watch streams the changes after the revision of the last Load
+func (s *EtcdSource) Watch(ctx context.Context, changed func()) error {
+	s.mu.Lock()
+	start := s.revision + 1
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	body, err := s.post(ctx, "/v3/watch", map[string]any{"create_request": s.keyRange(&start)})
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	decoder := json.NewDecoder(body)
+	for {
+		var msg struct {
+			Result *struct {
+				Canceled        bool            `json:"canceled"`
+				CancelReason    string          `json:"cancel_reason"`
+				CompactRevision int64           `json:"compact_revision,string"`
+				Events          json.RawMessage `json:"events"`
+			} `json:"result"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := decoder.Decode(&msg); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return fmt.Errorf("etcd watch stream broke: %w", err)
+		}
+		switch {
+		case msg.Error != nil:
+			return fmt.Errorf("etcd watch failed: %s", msg.Error.Message)
+		case msg.Result == nil:
+		case msg.Result.CompactRevision != 0:
+			return fmt.Errorf("etcd watch revision %d was compacted", start)
+		case msg.Result.Canceled:
+			return fmt.Errorf("etcd watch canceled: %s", msg.Result.CancelReason)
+		case len(msg.Result.Events) > 0 && string(msg.Result.Events) != "null":
+			changed()
+		}
+	}
+}
+
+// keyRange selects the keys under the prefix, from revision start if set
+func (s *EtcdSource) keyRange(start *int64) map[string]any {
+	r := map[string]any{
+		"key":       []byte(s.config.Prefix),
+		"range_end": prefixEnd([]byte(s.config.Prefix)),
+	}
+	if start != nil {
+		r["start_revision"] = strconv.FormatInt(*start, 10)
+	}
+	return r
+}
+
+func (s *EtcdSource) post(ctx context.Context, path string, payload any) (io.ReadCloser, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.Endpoint+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.config.Username != "" {
+		token, err := s.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", token)
+	}
+
+	resp, err := s.config.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("etcd request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("etcd %s: %s: %s", path, resp.Status, bytes.TrimSpace(msg))
+	}
+	return resp.Body, nil
+}
+
+// authenticate exchanges the username and password for a token
+func (s *EtcdSource) authenticate(ctx context.Context) (string, error) {
+	data, err := json.Marshal(map[string]string{"name": s.config.Username, "password": s.config.Password})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.Endpoint+"/v3/auth/authenticate", bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.config.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("etcd request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("etcd authentication failed: %s", resp.Status)
+	}
+	var auth struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&auth); err != nil {
+		return "", fmt.Errorf("invalid etcd response: %w", err)
+	}
+	return auth.Token, nil
+}
+
+// prefixEnd is the end of the key range holding every key with prefix
+func prefixEnd(prefix []byte) []byte {
+	end := bytes.Clone(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	// Every byte is 0xff: the range runs to the end of the keyspace
+	return []byte{0}
+}
+
+// settingKey turns a key of a remote store into a setting key, or "" for
+// keys outside prefix
+func settingKey(key, prefix string) string {
+	rest, ok := strings.CutPrefix(key, prefix)
+	if !ok {
+		return ""
+	}
+	return strings.ReplaceAll(strings.TrimPrefix(rest, "/"), "/", ".")
+}
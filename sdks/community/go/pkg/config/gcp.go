@@ -0,0 +1,102 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/internal/cloud"
+)
+
+// defaultGCPEndpoint is the REST API of Secret Manager
+const defaultGCPEndpoint = "https://secretmanager.googleapis.com"
+
+// GCPConfig configures a GCPResolver
+type GCPConfig struct {
+	// Token returns an OAuth 2.0 access token for the requests. It defaults
+	// to GOOGLE_OAUTH_ACCESS_TOKEN, then to the token of the service account
+	// from the metadata server on Google Cloud.
+	Token func(ctx context.Context) (string, error) `json:"-"`
+
+	// Endpoint of Secret Manager (defaults to https://secretmanager.googleapis.com)
+	Endpoint string `json:"endpoint"`
+
+	// Client sends the requests (defaults to http.DefaultClient)
+	Client *http.Client `json:"-"`
+}
+
+// GCPResolver resolves gcpsm://projects/PROJECT/secrets/SECRET#FIELD
+// references with GCP Secret Manager. The latest version is read unless
+// the path ends with /versions/VERSION, and FIELD selects a field of a
+// secret stored as a JSON object. Set Options.SecretRefresh to pick up new
+// versions.
+type GCPResolver struct {
+	config GCPConfig
+}
+
+// NewGCPResolver creates a resolver reading from GCP Secret Manager
+func NewGCPResolver(config GCPConfig) *GCPResolver {
+	if config.Endpoint == "" {
+		config.Endpoint = defaultGCPEndpoint
+	}
+	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	if config.Token == nil {
+		config.Token = cloud.GoogleToken(config.Client)
+	}
+	return &GCPResolver{config: config}
+}
+
+func (r *GCPResolver) Scheme() string {
+	return "gcpsm"
+}
+
+func (r *GCPResolver) Resolve(ctx context.Context, ref Ref) (Secret, error) {
+	name := strings.Trim(ref.Path, "/")
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+	token, err := r.config.Token(ctx)
+	if err != nil {
+		return Secret{}, fmt.Errorf("gcp secret manager: no access token: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.config.Endpoint+"/v1/"+name+":access", nil)
+	if err != nil {
+		return Secret{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := r.config.Client.Do(req)
+	if err != nil {
+		return Secret{}, fmt.Errorf("gcp secret manager request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&failure)
+		return Secret{}, fmt.Errorf("gcp secret manager %s: %s %s", name, resp.Status, failure.Error.Message)
+	}
+
+	var out struct {
+		Payload struct {
+			Data []byte `json:"data"`
+		} `json:"payload"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Secret{}, fmt.Errorf("invalid gcp secret manager response: %w", err)
+	}
+	value, err := selectKey(string(out.Payload.Data), ref.Key)
+	if err != nil {
+		return Secret{}, fmt.Errorf("gcp secret manager %s: %w", name, err)
+	}
+	return Secret{Value: value}, nil
+}
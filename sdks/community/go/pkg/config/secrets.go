@@ -0,0 +1,251 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+)
+
+// Ref is a reference to a secret, written scheme://path#key. Key selects a
+// field of a secret holding several, e.g. a JSON object.
+type Ref struct {
+	Scheme string
+	Path   string
+	Key    string
+}
+
+// ParseRef parses a reference to a secret
+func ParseRef(s string) (Ref, bool) {
+	scheme, rest, ok := strings.Cut(s, "://")
+	if !ok || scheme == "" || rest == "" {
+		return Ref{}, false
+	}
+	path, key, _ := strings.Cut(rest, "#")
+	return Ref{Scheme: scheme, Path: path, Key: key}, path != ""
+}
+
+func (r Ref) String() string {
+	s := r.Scheme + "://" + r.Path
+	if r.Key != "" {
+		s += "#" + r.Key
+	}
+	return s
+}
+
+// Secret is a resolved secret
+type Secret struct {
+	Value string
+
+	// TTL is how long the value may be used, zero when it does not expire
+	TTL time.Duration
+
+	// Lease identifies the lease of the secret, for resolvers implementing
+	// Renewer
+	Lease string
+}
+
+// Resolver fetches the secrets referenced with its scheme
+type Resolver interface {
+	// Scheme is the scheme of the references, e.g. "vault"
+	Scheme() string
+
+	Resolve(ctx context.Context, ref Ref) (Secret, error)
+}
+
+// Renewer is a resolver whose secrets are leases that can be extended
+// rather than fetched again
+type Renewer interface {
+	Renew(ctx context.Context, secret Secret) (Secret, error)
+}
+
+type cachedSecret struct {
+	ref     Ref
+	secret  Secret
+	fetched time.Time
+
+	// refreshAt is when Watch renews or fetches the secret again, zero for never
+	refreshAt time.Time
+}
+
+// expired reports whether the secret can no longer be used
+func (s *cachedSecret) expired(now time.Time) bool {
+	return s.secret.TTL > 0 && now.After(s.fetched.Add(s.secret.TTL))
+}
+
+// resolve replaces the references in values with their secrets. A secret
+// that cannot be resolved fails with strict, and otherwise keeps the value
+// it had before or is left out. Callers hold update.
+func (c *Config) resolve(ctx context.Context, values map[string]string, strict bool) (map[string]string, error) {
+	if len(c.resolvers) == 0 {
+		return values, nil
+	}
+	used := make(map[string]bool)
+	for key, value := range values {
+		ref, ok := ParseRef(value)
+		if !ok || c.resolvers[ref.Scheme] == nil {
+			continue
+		}
+		used[value] = true
+		secret, err := c.secret(ctx, ref)
+		if err == nil {
+			values[key] = secret
+			continue
+		}
+		if strict {
+			return nil, fmt.Errorf("failed to resolve %s: %w", key, err)
+		}
+		c.opts.Logger.Warn("failed to resolve secret", "key", key, "ref", value, "error", err)
+		c.mu.RLock()
+		prev, ok := c.values[key]
+		c.mu.RUnlock()
+		if ok {
+			values[key] = prev
+		} else {
+			delete(values, key)
+		}
+	}
+	for ref := range c.secrets {
+		if !used[ref] {
+			delete(c.secrets, ref)
+		}
+	}
+	return values, nil
+}
+
+// secret returns a cached secret, fetching it when missing or expired.
+// Callers hold update.
+func (c *Config) secret(ctx context.Context, ref Ref) (string, error) {
+	cached, ok := c.secrets[ref.String()]
+	if ok && !cached.expired(c.opts.Clock.Now()) {
+		return cached.secret.Value, nil
+	}
+	secret, err := c.resolvers[ref.Scheme].Resolve(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	c.cache(ref, secret)
+	return secret.Value, nil
+}
+
+// cache stores a fetched secret and schedules its refresh. Callers hold update.
+func (c *Config) cache(ref Ref, secret Secret) {
+	now := c.opts.Clock.Now()
+	s := &cachedSecret{ref: ref, secret: secret, fetched: now}
+	switch {
+	case secret.TTL > 0:
+		// Renew with a third of the lease left
+		s.refreshAt = now.Add(secret.TTL * 2 / 3)
+	case c.opts.SecretRefresh > 0:
+		s.refreshAt = now.Add(c.opts.SecretRefresh)
+	}
+	c.secrets[ref.String()] = s
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// refreshSecrets renews or fetches again each secret when its refresh is
+// due, until ctx is done
+func (c *Config) refreshSecrets(ctx context.Context) {
+	timer := c.opts.Clock.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.wake:
+		case <-timer.C():
+		}
+
+		if c.refreshDue(ctx) {
+			c.apply(ctx, -1, nil)
+		}
+
+		c.update.Lock()
+		next := time.Hour
+		for _, s := range c.secrets {
+			if !s.refreshAt.IsZero() {
+				next = min(next, clock.Until(c.opts.Clock, s.refreshAt))
+			}
+		}
+		c.update.Unlock()
+		timer.Reset(max(next, 0))
+	}
+}
+
+// refreshDue refreshes the secrets that are due and reports whether any of
+// them changed value
+func (c *Config) refreshDue(ctx context.Context) bool {
+	c.update.Lock()
+	defer c.update.Unlock()
+	changed := false
+	now := c.opts.Clock.Now()
+	for _, s := range c.secrets {
+		if s.refreshAt.IsZero() || now.Before(s.refreshAt) {
+			continue
+		}
+		secret, err := c.refresh(ctx, s)
+		if err != nil {
+			c.opts.Logger.Warn("failed to refresh secret", "ref", s.ref.String(), "error", err, "retry", c.opts.RetryDelay)
+			s.refreshAt = now.Add(c.opts.RetryDelay)
+			continue
+		}
+		changed = changed || secret.Value != s.secret.Value
+		c.cache(s.ref, secret)
+	}
+	return changed
+}
+
+// refresh renews the lease of a secret, fetching the secret again when it
+// has no lease or the renewal fails. Callers hold update.
+func (c *Config) refresh(ctx context.Context, s *cachedSecret) (Secret, error) {
+	resolver := c.resolvers[s.ref.Scheme]
+	if renewer, ok := resolver.(Renewer); ok && s.secret.Lease != "" {
+		secret, err := renewer.Renew(ctx, s.secret)
+		if err == nil {
+			return secret, nil
+		}
+		c.opts.Logger.Debug("failed to renew lease, fetching the secret again", "ref", s.ref.String(), "error", err)
+	}
+	return resolver.Resolve(ctx, s.ref)
+}
+
+// field returns the value of a secret, or one field of it when key is set
+// and the secret is a JSON object
+func field(fields map[string]any, key string) (string, error) {
+	if key == "" {
+		if len(fields) != 1 {
+			return "", fmt.Errorf("the secret has %d fields, select one with #key", len(fields))
+		}
+		for k := range fields {
+			key = k
+		}
+	}
+	value, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("the secret has no field %q", key)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(value)
+	return string(data), err
+}
+
+// selectKey returns a field of a secret stored as a JSON object, or the
+// whole secret when key is empty
+func selectKey(value, key string) (string, error) {
+	if key == "" {
+		return value, nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("cannot select %q, the secret is not a JSON object", key)
+	}
+	return field(fields, key)
+}
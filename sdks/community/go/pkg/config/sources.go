@@ -0,0 +1,210 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+)
+
+// MapSource is a fixed set of settings, e.g. the defaults of a program
+type MapSource struct {
+	name   string
+	values map[string]string
+}
+
+// NewMapSource creates a source of values
+func NewMapSource(name string, values map[string]string) *MapSource {
+	return &MapSource{name: name, values: values}
+}
+
+func (s *MapSource) Name() string {
+	return s.name
+}
+
+func (s *MapSource) Load(context.Context) (map[string]string, error) {
+	return copyMap(s.values), nil
+}
+
+// FileSource reads settings from a JSON file. Nested objects are flattened
+// into dotted keys, so {"log": {"level": "debug"}} sets "log.level".
+type FileSource struct {
+	path     string
+	interval time.Duration
+	clock    clock.Clock
+
+	mu     sync.Mutex
+	loaded string
+}
+
+// defaultPollInterval is how often a FileSource checks its file for changes
+const defaultPollInterval = 5 * time.Second
+
+// NewFileSource creates a source reading the file at path, checking it for
+// changes every interval (defaults to 5 seconds). A missing file has no
+// settings.
+func NewFileSource(path string, interval time.Duration) *FileSource {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	return &FileSource{path: path, interval: interval, clock: clock.Real}
+}
+
+// WithClock sets the clock timing the polls of the file
+func (s *FileSource) WithClock(c clock.Clock) *FileSource {
+	s.clock = clock.Or(c)
+	return s
+}
+
+func (s *FileSource) Name() string {
+	return "file " + s.path
+}
+
+func (s *FileSource) Load(context.Context) (map[string]string, error) {
+	// A write racing the read is caught by the next poll
+	stat := s.stat()
+	data, err := os.ReadFile(s.path)
+	if err == nil || errors.Is(err, os.ErrNotExist) {
+		s.mu.Lock()
+		s.loaded = stat
+		s.mu.Unlock()
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return flattenJSON(data)
+}
+
+// Watch polls the modification time and size of the file, reporting
+// changes since the last Load
+func (s *FileSource) Watch(ctx context.Context, changed func()) error {
+	s.mu.Lock()
+	last := s.loaded
+	s.mu.Unlock()
+	ticker := s.clock.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C():
+			if current := s.stat(); current != last {
+				last = current
+				changed()
+			}
+		}
+	}
+}
+
+func (s *FileSource) stat() string {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprint(info.ModTime().UnixNano(), info.Size())
+}
+
+// EnvSource reads settings from environment variables with a prefix. The
+// rest of the name is lowercased with underscores turned into dots, so
+// AGUI_LOG_LEVEL sets "log.level" for the prefix "AGUI_".
+type EnvSource struct {
+	prefix string
+}
+
+// NewEnvSource creates a source of the environment variables starting with prefix
+func NewEnvSource(prefix string) *EnvSource {
+	return &EnvSource{prefix: prefix}
+}
+
+func (s *EnvSource) Name() string {
+	return "env " + s.prefix + "*"
+}
+
+func (s *EnvSource) Load(context.Context) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		if rest, ok := strings.CutPrefix(name, s.prefix); ok && rest != "" {
+			values[strings.ReplaceAll(strings.ToLower(rest), "_", ".")] = value
+		}
+	}
+	return values, nil
+}
+
+// FlagSource reads the flags set on the command line, keyed by flag name.
+// Flags left at their default are not settings, so they do not override
+// other sources; give defaults through a MapSource instead.
+type FlagSource struct {
+	flags *flag.FlagSet
+}
+
+// NewFlagSource creates a source of the flags of flags, which must be parsed
+func NewFlagSource(flags *flag.FlagSet) *FlagSource {
+	return &FlagSource{flags: flags}
+}
+
+func (s *FlagSource) Name() string {
+	return "flags"
+}
+
+func (s *FlagSource) Load(context.Context) (map[string]string, error) {
+	if !s.flags.Parsed() {
+		return nil, errors.New("flags not parsed")
+	}
+	values := make(map[string]string)
+	s.flags.Visit(func(f *flag.Flag) {
+		values[f.Name] = f.Value.String()
+	})
+	return values, nil
+}
+
+// flattenJSON turns a JSON object into dotted keys. Values that are not
+// strings keep their JSON text, e.g. 3 or true.
+func flattenJSON(data []byte) (map[string]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc map[string]any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	values := make(map[string]string)
+	flatten("", doc, values)
+	return values, nil
+}
+
+func flatten(prefix string, doc map[string]any, values map[string]string) {
+	for key, value := range doc {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch v := value.(type) {
+		case map[string]any:
+			flatten(key, v, values)
+		case string:
+			values[key] = v
+		case nil:
+		default:
+			data, _ := json.Marshal(v)
+			values[key] = string(data)
+		}
+	}
+}
+
+func copyMap(m map[string]string) map[string]string {
+	c := make(map[string]string, len(m))
+	for key, value := range m {
+		c[key] = value
+	}
+	return c
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultVaultAddress is the API of a local Vault server
+const defaultVaultAddress = "http://127.0.0.1:8200"
+
+// VaultConfig configures a VaultResolver. Empty fields default to the
+// environment variables of the Vault CLI.
+type VaultConfig struct {
+	// Address of the Vault API (defaults to VAULT_ADDR, then http://127.0.0.1:8200)
+	Address string `json:"address"`
+
+	// Token authenticates the requests (defaults to VAULT_TOKEN)
+	Token string `json:"-"`
+
+	// Namespace of Vault Enterprise (defaults to VAULT_NAMESPACE)
+	Namespace string `json:"namespace"`
+
+	// Client sends the requests (defaults to http.DefaultClient)
+	Client *http.Client `json:"-"`
+}
+
+// VaultResolver resolves vault://PATH#FIELD references by reading PATH from
+// the Vault API, e.g. vault://secret/data/agent#api_key for a KV version 2
+// secret. Leased secrets, such as dynamic database credentials, are renewed
+// before they expire.
+type VaultResolver struct {
+	config VaultConfig
+}
+
+// NewVaultResolver creates a resolver reading from the Vault server of config
+func NewVaultResolver(config VaultConfig) *VaultResolver {
+	if config.Address == "" {
+		config.Address = os.Getenv("VAULT_ADDR")
+	}
+	if config.Address == "" {
+		config.Address = defaultVaultAddress
+	}
+	config.Address = strings.TrimSuffix(config.Address, "/")
+	if config.Token == "" {
+		config.Token = os.Getenv("VAULT_TOKEN")
+	}
+	if config.Namespace == "" {
+		config.Namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	return &VaultResolver{config: config}
+}
+
+func (r *VaultResolver) Scheme() string {
+	return "vault"
+}
+
+// vaultResponse is the envelope of the Vault API responses
+type vaultResponse struct {
+	LeaseID       string         `json:"lease_id"`
+	LeaseDuration int            `json:"lease_duration"`
+	Renewable     bool           `json:"renewable"`
+	Data          map[string]any `json:"data"`
+}
+
+func (r *VaultResolver) Resolve(ctx context.Context, ref Ref) (Secret, error) {
+	var resp vaultResponse
+	if err := r.do(ctx, http.MethodGet, "/v1/"+strings.TrimPrefix(ref.Path, "/"), nil, &resp); err != nil {
+		return Secret{}, err
+	}
+
+	fields := resp.Data
+	// KV version 2 nests the secret under data next to its metadata
+	if nested, ok := fields["data"].(map[string]any); ok && fields["metadata"] != nil {
+		fields = nested
+	}
+	value, err := field(fields, ref.Key)
+	if err != nil {
+		return Secret{}, fmt.Errorf("vault %s: %w", ref.Path, err)
+	}
+
+	secret := Secret{Value: value, TTL: time.Duration(resp.LeaseDuration) * time.Second}
+	if resp.Renewable {
+		secret.Lease = resp.LeaseID
+	}
+	return secret, nil
+}
+
+// Renew extends the lease of a secret by its last duration
+func (r *VaultResolver) Renew(ctx context.Context, secret Secret) (Secret, error) {
+	body := map[string]any{"lease_id": secret.Lease, "increment": int(secret.TTL.Seconds())}
+	var resp vaultResponse
+	if err := r.do(ctx, http.MethodPut, "/v1/sys/leases/renew", body, &resp); err != nil {
+		return Secret{}, err
+	}
+	if resp.LeaseDuration <= 0 {
+		return Secret{}, fmt.Errorf("vault did not renew lease %s", secret.Lease)
+	}
+	secret.TTL = time.Duration(resp.LeaseDuration) * time.Second
+	if !resp.Renewable {
+		secret.Lease = ""
+	}
+	return secret, nil
+}
+
+func (r *VaultResolver) do(ctx context.Context, method, path string, payload, out any) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, r.config.Address+path, body)
+	if err != nil {
+		return err
+	}
+	if r.config.Token != "" {
+		req.Header.Set("X-Vault-Token", r.config.Token)
+	}
+	if r.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", r.config.Namespace)
+	}
+
+	resp, err := r.config.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&failure)
+		return fmt.Errorf("vault %s %s: %s %s", method, path, resp.Status, strings.Join(failure.Errors, "; "))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid vault response: %w", err)
+	}
+	return nil
+}
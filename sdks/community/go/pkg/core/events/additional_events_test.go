@@ -2,8 +2,10 @@ package events
 
 import (
 	"encoding/json"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -559,3 +561,95 @@ func TestToolCallEndEvent_ToJSON(t *testing.T) {
 	assert.Equal(t, string(EventTypeToolCallEnd), decoded["type"])
 	assert.Equal(t, "tool-123", decoded["toolCallId"])
 }
+
+func TestHeartbeatEvent(t *testing.T) {
+	event := NewHeartbeatEvent(15 * time.Second)
+	require.NoError(t, event.Validate())
+	interval, ok := HeartbeatInterval(event)
+	assert.True(t, ok)
+	assert.Equal(t, 15*time.Second, interval)
+
+	data, err := event.ToJSON()
+	require.NoError(t, err)
+	decoded, err := EventFromJSON(data)
+	require.NoError(t, err)
+	interval, ok = HeartbeatInterval(decoded)
+	assert.True(t, ok, "decoded heartbeats are recognized")
+	assert.Equal(t, 15*time.Second, interval)
+
+	_, ok = HeartbeatInterval(NewCustomEvent("usage"))
+	assert.False(t, ok)
+	_, ok = HeartbeatInterval(NewStepStartedEvent("heartbeat"))
+	assert.False(t, ok)
+}
+
+func TestAttachmentEvent(t *testing.T) {
+	content := []byte("\x89PNG")
+	attachment := Attachment{ID: "att-1", MediaType: "image/png", Size: int64(len(content)), SHA256: ContentHash(content), Data: content, ToolCallID: "tool-1"}
+	require.NoError(t, attachment.Validate())
+
+	data, err := NewAttachmentEvent(attachment).ToJSON()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"data":"iVBORw=="`, "inline content is base64")
+	decoded, err := EventFromJSON(data)
+	require.NoError(t, err)
+	got, ok, err := AttachmentOf(decoded)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, attachment, *got)
+
+	tampered := attachment
+	tampered.Data = []byte("\x89PNH")
+	_, _, err = AttachmentOf(NewAttachmentEvent(tampered))
+	assert.True(t, errors.Is(err, ErrInvalidAttachment), "got %v", err)
+
+	both := attachment
+	both.URL = "blob:attachments/" + attachment.SHA256
+	assert.True(t, errors.Is(both.Validate(), ErrInvalidAttachment))
+	both.Data = nil
+	assert.NoError(t, both.Validate(), "out of band content is checked on download")
+
+	_, ok, _ = AttachmentOf(NewCustomEvent("usage"))
+	assert.False(t, ok)
+}
+
+func TestUsageEvent(t *testing.T) {
+	usage := Usage{Model: "gpt-4o", InputTokens: 120, OutputTokens: 48, ReasoningTokens: 8, Cost: 0.0021, MessageID: "msg-1"}
+	assert.Equal(t, 176, usage.TotalTokens())
+	assert.Equal(t, DefaultCurrency, usage.CostCurrency())
+
+	data, err := NewUsageEvent(usage).ToJSON()
+	require.NoError(t, err)
+	decoded, err := EventFromJSON(data)
+	require.NoError(t, err)
+	got, ok, err := UsageOf(decoded)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, usage, *got)
+
+	_, ok, err = UsageOf(NewCustomEvent(UsageEventName, WithValue(map[string]any{"inputTokens": -1})))
+	assert.True(t, ok)
+	assert.True(t, errors.Is(err, ErrInvalidUsage), "got %v", err)
+	_, ok, _ = UsageOf(NewCustomEvent("progress"))
+	assert.False(t, ok)
+}
+
+func TestModerationEvent(t *testing.T) {
+	moderation := Moderation{MessageID: "msg-1", Action: ModerationRedacted, Labels: []string{"pii"}, Reason: "email address"}
+	data, err := NewModerationEvent(moderation).ToJSON()
+	require.NoError(t, err)
+	decoded, err := EventFromJSON(data)
+	require.NoError(t, err)
+	got, ok, err := ModerationOf(decoded)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, moderation, *got)
+
+	_, ok, err = ModerationOf(NewModerationEvent(Moderation{MessageID: "msg-1", ToolCallID: "call-1", Action: ModerationBlocked}))
+	assert.True(t, ok)
+	assert.True(t, errors.Is(err, ErrInvalidModeration), "got %v", err)
+	_, _, err = ModerationOf(NewModerationEvent(Moderation{ToolCallID: "call-1", Action: "hidden"}))
+	assert.True(t, errors.Is(err, ErrInvalidModeration), "got %v", err)
+	_, ok, _ = ModerationOf(NewUsageEvent(Usage{}))
+	assert.False(t, ok)
+}
@@ -0,0 +1,187 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Approvals express human in the loop flows: an agent requests approval
+// before taking an action, usually running a tool call, and the run goes on
+// once the request is granted or denied. A request is answered at most once;
+// its tool call has no result while the request is pending. The answer may
+// come in a later run, e.g. after the run paused for the decision, so
+// answers to requests not seen in a run are accepted.
+
+// ApprovalRequestedEvent asks a human to approve an action before the run
+// takes it
+type ApprovalRequestedEvent struct {
+	*BaseEvent
+
+	// ApprovalID identifies the request; answers refer to it
+	ApprovalID string `json:"approvalId"`
+
+	// ToolCallID, ToolName and Arguments describe the tool call awaiting
+	// approval, if the request is for one
+	ToolCallID string `json:"toolCallId,omitempty"`
+	ToolName   string `json:"toolName,omitempty"`
+	Arguments  string `json:"arguments,omitempty"`
+
+	// Prompt is shown to the human deciding, if any
+	Prompt *string `json:"prompt,omitempty"`
+}
+
+// NewApprovalRequestedEvent creates a new approval requested event
+func NewApprovalRequestedEvent(approvalID string) *ApprovalRequestedEvent {
+	return &ApprovalRequestedEvent{
+		BaseEvent:  NewBaseEvent(EventTypeApprovalRequested),
+		ApprovalID: approvalID,
+	}
+}
+
+// WithToolCall sets the tool call awaiting approval
+func (e *ApprovalRequestedEvent) WithToolCall(toolCallID, toolName, arguments string) *ApprovalRequestedEvent {
+	e.ToolCallID, e.ToolName, e.Arguments = toolCallID, toolName, arguments
+	return e
+}
+
+// WithPrompt sets the prompt shown to the human deciding
+func (e *ApprovalRequestedEvent) WithPrompt(prompt string) *ApprovalRequestedEvent {
+	e.Prompt = &prompt
+	return e
+}
+
+// Validate validates the approval requested event
+func (e *ApprovalRequestedEvent) Validate() error {
+	if err := e.BaseEvent.Validate(); err != nil {
+		return err
+	}
+
+	if e.ApprovalID == "" {
+		return fmt.Errorf("ApprovalRequestedEvent validation failed: approvalId field is required")
+	}
+
+	if e.ToolCallID == "" && (e.ToolName != "" || e.Arguments != "") {
+		return fmt.Errorf("ApprovalRequestedEvent validation failed: toolCallId field is required with toolName or arguments")
+	}
+
+	return nil
+}
+
+// ToJSON serializes the event to JSON
+func (e *ApprovalRequestedEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// ApprovalGrantedEvent grants an approval request; the run takes the action
+type ApprovalGrantedEvent struct {
+	*BaseEvent
+
+	// ApprovalID is the ID of the request granted
+	ApprovalID string `json:"approvalId"`
+
+	// ToolCallID is the tool call of the request, if any
+	ToolCallID string `json:"toolCallId,omitempty"`
+
+	// Arguments replaces the arguments of the tool call, if set
+	Arguments *string `json:"arguments,omitempty"`
+
+	// Reason optionally explains the decision
+	Reason *string `json:"reason,omitempty"`
+}
+
+// NewApprovalGrantedEvent creates a new approval granted event
+func NewApprovalGrantedEvent(approvalID string) *ApprovalGrantedEvent {
+	return &ApprovalGrantedEvent{
+		BaseEvent:  NewBaseEvent(EventTypeApprovalGranted),
+		ApprovalID: approvalID,
+	}
+}
+
+// WithToolCallID sets the tool call of the request granted
+func (e *ApprovalGrantedEvent) WithToolCallID(toolCallID string) *ApprovalGrantedEvent {
+	e.ToolCallID = toolCallID
+	return e
+}
+
+// WithArguments replaces the arguments of the tool call
+func (e *ApprovalGrantedEvent) WithArguments(arguments string) *ApprovalGrantedEvent {
+	e.Arguments = &arguments
+	return e
+}
+
+// WithReason sets the reason of the decision
+func (e *ApprovalGrantedEvent) WithReason(reason string) *ApprovalGrantedEvent {
+	e.Reason = &reason
+	return e
+}
+
+// Validate validates the approval granted event
+func (e *ApprovalGrantedEvent) Validate() error {
+	if err := e.BaseEvent.Validate(); err != nil {
+		return err
+	}
+
+	if e.ApprovalID == "" {
+		return fmt.Errorf("ApprovalGrantedEvent validation failed: approvalId field is required")
+	}
+
+	return nil
+}
+
+// ToJSON serializes the event to JSON
+func (e *ApprovalGrantedEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// ApprovalDeniedEvent denies an approval request; the run does not take the
+// action
+type ApprovalDeniedEvent struct {
+	*BaseEvent
+
+	// ApprovalID is the ID of the request denied
+	ApprovalID string `json:"approvalId"`
+
+	// ToolCallID is the tool call of the request, if any
+	ToolCallID string `json:"toolCallId,omitempty"`
+
+	// Reason optionally explains the decision
+	Reason *string `json:"reason,omitempty"`
+}
+
+// NewApprovalDeniedEvent creates a new approval denied event
+func NewApprovalDeniedEvent(approvalID string) *ApprovalDeniedEvent {
+	return &ApprovalDeniedEvent{
+		BaseEvent:  NewBaseEvent(EventTypeApprovalDenied),
+		ApprovalID: approvalID,
+	}
+}
+
+// WithToolCallID sets the tool call of the request denied
+func (e *ApprovalDeniedEvent) WithToolCallID(toolCallID string) *ApprovalDeniedEvent {
+	e.ToolCallID = toolCallID
+	return e
+}
+
+// WithReason sets the reason of the decision
+func (e *ApprovalDeniedEvent) WithReason(reason string) *ApprovalDeniedEvent {
+	e.Reason = &reason
+	return e
+}
+
+// Validate validates the approval denied event
+func (e *ApprovalDeniedEvent) Validate() error {
+	if err := e.BaseEvent.Validate(); err != nil {
+		return err
+	}
+
+	if e.ApprovalID == "" {
+		return fmt.Errorf("ApprovalDeniedEvent validation failed: approvalId field is required")
+	}
+
+	return nil
+}
+
+// ToJSON serializes the event to JSON
+func (e *ApprovalDeniedEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
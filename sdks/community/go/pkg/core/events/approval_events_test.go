@@ -0,0 +1,74 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApprovalEvents(t *testing.T) {
+	requested := NewApprovalRequestedEvent("approval-1").WithToolCall("tool-1", "delete_file", `{"path":"/tmp/x"}`).WithPrompt("Delete /tmp/x?")
+	granted := NewApprovalGrantedEvent("approval-1").WithToolCallID("tool-1").WithArguments(`{"path":"/tmp/y"}`)
+	denied := NewApprovalDeniedEvent("approval-1").WithReason("too risky")
+
+	for _, event := range []Event{requested, granted, denied} {
+		t.Run(string(event.Type()), func(t *testing.T) {
+			require.NoError(t, event.Validate())
+			data, err := event.ToJSON()
+			require.NoError(t, err)
+			assert.Contains(t, string(data), `"approvalId":"approval-1"`)
+
+			decoded, err := EventFromJSON(data)
+			require.NoError(t, err)
+			assert.Equal(t, event, decoded)
+			decoded, err = NewEventDecoder(nil).DecodeEvent(string(event.Type()), data)
+			require.NoError(t, err)
+			assert.Equal(t, event, decoded)
+		})
+	}
+
+	assert.Error(t, NewApprovalRequestedEvent("").Validate())
+	assert.Error(t, NewApprovalRequestedEvent("approval-1").WithToolCall("", "delete_file", "").Validate())
+	assert.Error(t, NewApprovalGrantedEvent("").Validate())
+	assert.Error(t, NewApprovalDeniedEvent("").Validate())
+}
+
+func TestEventValidatorApprovals(t *testing.T) {
+	t.Run("ApprovalFlow", func(t *testing.T) {
+		validator := NewEventValidator()
+		for _, event := range []Event{
+			NewToolCallStartEvent("tool-1", "delete_file"),
+			NewToolCallEndEvent("tool-1"),
+			NewApprovalRequestedEvent("approval-1").WithToolCall("tool-1", "delete_file", "{}"),
+		} {
+			require.NoError(t, validator.ValidateEvent(event))
+		}
+
+		err := validator.ValidateEvent(NewToolCallResultEvent("msg-1", "tool-1", "deleted"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "awaiting approval approval-1")
+		assert.Error(t, validator.ValidateEvent(NewApprovalRequestedEvent("approval-1")), "requests are unique")
+
+		require.NoError(t, validator.ValidateEvent(NewApprovalGrantedEvent("approval-1")))
+		require.NoError(t, validator.ValidateEvent(NewToolCallResultEvent("msg-1", "tool-1", "deleted")))
+		assert.Error(t, validator.ValidateEvent(NewApprovalDeniedEvent("approval-1")), "requests are answered once")
+		assert.Error(t, validator.ValidateEvent(NewApprovalRequestedEvent("approval-1")))
+	})
+
+	t.Run("AnswerFromEarlierRun", func(t *testing.T) {
+		validator := NewEventValidator()
+		assert.NoError(t, validator.ValidateEvent(NewApprovalDeniedEvent("approval-1").WithReason("no")))
+	})
+
+	t.Run("MalformedApproval", func(t *testing.T) {
+		validator := NewEventValidator()
+		assert.Error(t, validator.ValidateEvent(NewApprovalRequestedEvent("")))
+	})
+
+	t.Run("CustomEventsAreNotApprovals", func(t *testing.T) {
+		validator := NewEventValidator()
+		require.NoError(t, validator.ValidateEvent(NewCustomEvent("approval_requested")))
+		require.NoError(t, validator.ValidateEvent(NewCustomEvent("approval_requested")))
+	})
+}
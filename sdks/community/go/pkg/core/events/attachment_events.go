@@ -0,0 +1,121 @@
+package events
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Attachments extend the protocol with CUSTOM events carrying binary
+// content, such as images or files produced by tools, for a message or a
+// tool call. Small content is inlined as base64; larger content is stored
+// out of band and referenced by URL (see package attachment). Either way the
+// SHA-256 of the content lets consumers verify what they received.
+const (
+	// AttachmentEventName is the name of attachment CUSTOM events
+	AttachmentEventName = "attachment"
+)
+
+// ErrInvalidAttachment is returned for malformed attachments and for
+// content that does not match its hash
+var ErrInvalidAttachment = errors.New("invalid attachment")
+
+// Attachment describes binary content attached to a message or tool call
+type Attachment struct {
+	// ID identifies the attachment
+	ID string `json:"id"`
+
+	// Name is the file name of the content, if any
+	Name string `json:"name,omitempty"`
+
+	// MediaType is the MIME type of the content
+	MediaType string `json:"mediaType"`
+
+	// Size is the length of the content in bytes, and SHA256 its hex
+	// encoded hash
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+
+	// Data is the inline content, unless URL references content stored out
+	// of band
+	Data []byte `json:"data,omitempty"`
+	URL  string `json:"url,omitempty"`
+
+	// MessageID and ToolCallID link the attachment to a message or a tool
+	// call of the run
+	MessageID  string `json:"messageId,omitempty"`
+	ToolCallID string `json:"toolCallId,omitempty"`
+}
+
+// ContentHash returns the hex encoded SHA-256 of data, as in Attachment.SHA256
+func ContentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Validate validates the attachment, and its inline content against its
+// size and hash
+func (a Attachment) Validate() error {
+	if a.ID == "" {
+		return fmt.Errorf("%w: id field is required", ErrInvalidAttachment)
+	}
+	if a.MediaType == "" {
+		return fmt.Errorf("%w: mediaType field is required", ErrInvalidAttachment)
+	}
+	if a.SHA256 == "" {
+		return fmt.Errorf("%w: sha256 field is required", ErrInvalidAttachment)
+	}
+	if a.URL == "" {
+		return a.Verify(a.Data)
+	}
+	if a.Data != nil {
+		return fmt.Errorf("%w: data and url are exclusive", ErrInvalidAttachment)
+	}
+	return nil
+}
+
+// Verify checks that data is the content of the attachment
+func (a Attachment) Verify(data []byte) error {
+	if int64(len(data)) != a.Size {
+		return fmt.Errorf("%w: %s has %d bytes, expected %d", ErrInvalidAttachment, a.ID, len(data), a.Size)
+	}
+	if ContentHash(data) != a.SHA256 {
+		return fmt.Errorf("%w: %s does not match its hash", ErrInvalidAttachment, a.ID)
+	}
+	return nil
+}
+
+// NewAttachmentEvent creates an attachment event
+func NewAttachmentEvent(attachment Attachment) *CustomEvent {
+	return NewCustomEvent(AttachmentEventName, WithValue(attachment))
+}
+
+// AttachmentOf reports whether event is an attachment event and returns its
+// attachment once validated
+func AttachmentOf(event Event) (*Attachment, bool, error) {
+	custom, ok := event.(*CustomEvent)
+	if !ok || custom.Name != AttachmentEventName {
+		return nil, false, nil
+	}
+	var attachment Attachment
+	switch value := custom.Value.(type) {
+	case Attachment:
+		attachment = value
+	case *Attachment:
+		attachment = *value
+	default:
+		data, err := json.Marshal(value)
+		if err == nil {
+			err = json.Unmarshal(data, &attachment)
+		}
+		if err != nil {
+			return nil, true, fmt.Errorf("%w: %v", ErrInvalidAttachment, err)
+		}
+	}
+	if err := attachment.Validate(); err != nil {
+		return nil, true, err
+	}
+	return &attachment, true, nil
+}
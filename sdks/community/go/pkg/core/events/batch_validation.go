@@ -0,0 +1,220 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrEventSkipped is the error of the events of a batch left unchecked, in
+// fail-fast mode after its first invalid event or once its context is done
+var ErrEventSkipped = errors.New("event skipped")
+
+// BatchOptions configures EventValidator.ValidateBatch
+type BatchOptions struct {
+	// FailFast stops the validation at the first invalid event, leaving the
+	// following events unchecked. Otherwise every event is checked, the
+	// invalid ones leaving the sequence state untouched.
+	FailFast bool
+
+	// Parallelism bounds the goroutines checking events on their own
+	// (defaults to GOMAXPROCS)
+	Parallelism int
+}
+
+// EventResult is the result of the validation of an event of a batch
+type EventResult struct {
+	// Index is the position of the event in the batch
+	Index int
+	Type  EventType
+
+	// Err is the reason the event is invalid, ErrEventSkipped for events
+	// left unchecked, nil for valid events
+	Err error
+}
+
+// FindingKind classifies the findings of a batch
+type FindingKind string
+
+const (
+	FindingRunNotFinished   FindingKind = "run_not_finished"
+	FindingMessageNotEnded  FindingKind = "message_not_ended"
+	FindingToolCallNotEnded FindingKind = "tool_call_not_ended"
+	FindingStepNotFinished  FindingKind = "step_not_finished"
+	FindingApprovalPending  FindingKind = "approval_pending"
+)
+
+// Finding is an issue of a batch as a whole rather than of one of its
+// events: something it left in progress. Batches cut from a longer stream
+// expectedly leave some, complete ones should not.
+type Finding struct {
+	Kind FindingKind
+
+	// ID is the ID of the run, message, tool call or approval request, or
+	// the name of the step
+	ID string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s", f.Kind, f.ID)
+}
+
+// BatchResult is the result of EventValidator.ValidateBatch
+type BatchResult struct {
+	// Results holds the result of each event of the batch, in order
+	Results []EventResult
+
+	// Findings lists what the validator has in progress once the batch is
+	// validated, sorted by kind and ID
+	Findings []Finding
+
+	// Valid, Invalid and Skipped count the events by result
+	Valid   int
+	Invalid int
+	Skipped int
+}
+
+// Err joins the errors of the invalid events, nil when there are none
+func (r *BatchResult) Err() error {
+	var errs []error
+	for _, result := range r.Results {
+		if result.Err != nil && !errors.Is(result.Err, ErrEventSkipped) {
+			errs = append(errs, fmt.Errorf("event %d: %w", result.Index, result.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateBatch validates a batch of events as successive calls to
+// ValidateEvent would, e.g. for bulk imports, and returns the result of
+// each event along with the findings of the batch. The checks of events on
+// their own, the costliest ones, run in parallel; the sequence rules are
+// then applied in order, the batch holding the validator meanwhile.
+//
+// An error is only returned when ctx is done before the validation
+// completes, along with the partial result: the events not checked by then
+// are skipped.
+func (v *EventValidator) ValidateBatch(ctx context.Context, batch []Event, opts BatchOptions) (*BatchResult, error) {
+	result := &BatchResult{Results: make([]EventResult, len(batch))}
+	for i, event := range batch {
+		result.Results[i] = EventResult{Index: i, Err: ErrEventSkipped}
+		if event != nil {
+			result.Results[i].Type = event.Type()
+		}
+	}
+
+	checked := v.checkBatch(ctx, batch, result.Results, opts)
+
+	v.mu.Lock()
+	failed := false
+	for i := 0; i < checked; i++ {
+		r := &result.Results[i]
+		if failed || ctx.Err() != nil {
+			r.Err = ErrEventSkipped
+			continue
+		}
+		if r.Err == nil {
+			r.Err = v.applySequenceRules(batch[i])
+		}
+		failed = r.Err != nil && opts.FailFast
+	}
+	result.Findings = v.findings()
+	v.mu.Unlock()
+
+	for i := range result.Results {
+		switch err := result.Results[i].Err; {
+		case err == nil:
+			result.Valid++
+		case errors.Is(err, ErrEventSkipped):
+			result.Skipped++
+		default:
+			result.Invalid++
+		}
+	}
+	if result.Skipped > 0 && ctx.Err() != nil {
+		return result, context.Cause(ctx)
+	}
+	return result, nil
+}
+
+// checkBatch checks the events of batch on their own in parallel, setting
+// the error of their results. It returns the number of leading events
+// checked: all of them, but in fail-fast mode those up to the first
+// invalid event, and those checked before ctx was done.
+func (v *EventValidator) checkBatch(ctx context.Context, batch []Event, results []EventResult, opts BatchOptions) int {
+	workers := opts.Parallelism
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(batch))
+
+	// next is the next event to check, and end bounds the events worth
+	// checking, lowered to the first invalid event in fail-fast mode
+	var next, end atomic.Int64
+	end.Store(int64(len(batch)))
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				i := next.Add(1) - 1
+				if i >= end.Load() {
+					return
+				}
+				err := v.checkEvent(batch[i])
+				results[i].Err = err
+				for err != nil && opts.FailFast {
+					current := end.Load()
+					if i+1 >= current || end.CompareAndSwap(current, i+1) {
+						break
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Events are handed out in order, so those below the first left
+	// unchecked were all checked; those checked past the first invalid one
+	// before workers noticed it are skipped nonetheless
+	checked := int(end.Load())
+	for i := 0; i < checked; i++ {
+		if errors.Is(results[i].Err, ErrEventSkipped) {
+			checked = i
+		}
+	}
+	for i := checked; i < len(results); i++ {
+		results[i].Err = ErrEventSkipped
+	}
+	return checked
+}
+
+// findings lists what the validator has in progress; callers must hold mu
+func (v *EventValidator) findings() []Finding {
+	var findings []Finding
+	add := func(kind FindingKind, ids map[string]bool) {
+		for id := range ids {
+			findings = append(findings, Finding{Kind: kind, ID: id})
+		}
+	}
+	add(FindingRunNotFinished, v.activeRuns)
+	add(FindingMessageNotEnded, v.activeMessages)
+	add(FindingMessageNotEnded, v.activeReasoningMessages)
+	add(FindingToolCallNotEnded, v.activeToolCalls)
+	add(FindingStepNotFinished, v.activeSteps)
+	for id := range v.pendingApprovals {
+		findings = append(findings, Finding{Kind: FindingApprovalPending, ID: id})
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Kind != findings[j].Kind {
+			return findings[i].Kind < findings[j].Kind
+		}
+		return findings[i].ID < findings[j].ID
+	})
+	return findings
+}
@@ -0,0 +1,125 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchWithErrors returns a batch of a run streaming a message, with an
+// event invalid on its own at index 3 and one breaking the sequence at 5
+func batchWithErrors() []Event {
+	return []Event{
+		NewRunStartedEvent("thread-1", "run-1"),
+		NewTextMessageStartEvent("msg-1", WithRole("assistant")),
+		NewTextMessageContentEvent("msg-1", "Hello"),
+		NewTextMessageContentEvent("msg-1", ""),
+		NewTextMessageEndEvent("msg-1"),
+		NewTextMessageContentEvent("msg-1", "late"),
+		NewToolCallStartEvent("call-1", "search"),
+		NewStepStartedEvent("plan"),
+	}
+}
+
+func TestValidateBatch(t *testing.T) {
+	t.Run("collect all", func(t *testing.T) {
+		v := NewEventValidator()
+		result, err := v.ValidateBatch(context.Background(), batchWithErrors(), BatchOptions{Parallelism: 4})
+		require.NoError(t, err)
+
+		assert.Equal(t, 6, result.Valid)
+		assert.Equal(t, 2, result.Invalid)
+		assert.Zero(t, result.Skipped)
+		assert.Contains(t, result.Results[3].Err.Error(), "TEXT_MESSAGE_CONTENT validation failed")
+		assert.Error(t, result.Results[5].Err)
+		assert.Equal(t, EventTypeTextMessageContent, result.Results[5].Type)
+		assert.Equal(t, []Finding{
+			{Kind: FindingRunNotFinished, ID: "run-1"},
+			{Kind: FindingStepNotFinished, ID: "plan"},
+			{Kind: FindingToolCallNotEnded, ID: "call-1"},
+		}, result.Findings)
+
+		err = result.Err()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "event 3: ")
+		assert.Contains(t, err.Error(), "event 5: ")
+
+		assert.NoError(t, v.ValidateEvent(NewToolCallEndEvent("call-1")), "the batch updates the sequence state")
+	})
+
+	t.Run("fail fast", func(t *testing.T) {
+		v := NewEventValidator()
+		result, err := v.ValidateBatch(context.Background(), batchWithErrors(), BatchOptions{FailFast: true, Parallelism: 4})
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, result.Valid)
+		assert.Equal(t, 1, result.Invalid)
+		assert.Equal(t, 4, result.Skipped)
+		for _, r := range result.Results[4:] {
+			assert.ErrorIs(t, r.Err, ErrEventSkipped)
+		}
+		assert.Equal(t, []Finding{
+			{Kind: FindingMessageNotEnded, ID: "msg-1"},
+			{Kind: FindingRunNotFinished, ID: "run-1"},
+		}, result.Findings)
+	})
+
+	t.Run("fail fast on sequence", func(t *testing.T) {
+		batch := []Event{
+			NewTextMessageContentEvent("msg-1", "orphan"),
+			NewRunStartedEvent("thread-1", "run-1"),
+		}
+		result, err := NewEventValidator().ValidateBatch(context.Background(), batch, BatchOptions{FailFast: true})
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Invalid)
+		assert.Equal(t, 1, result.Skipped)
+		assert.Empty(t, result.Findings, "skipped events are not applied")
+	})
+
+	t.Run("matches incremental validation", func(t *testing.T) {
+		var batch []Event
+		batch = append(batch, NewRunStartedEvent("thread-1", "run-1"))
+		for i := 0; i < 500; i++ {
+			id := fmt.Sprintf("msg-%d", i)
+			batch = append(batch, NewTextMessageStartEvent(id, WithRole("assistant")))
+			if i%7 == 0 {
+				batch = append(batch, NewTextMessageContentEvent(id, ""))
+			}
+			batch = append(batch, NewTextMessageContentEvent(id, "token"))
+			if i%11 != 0 {
+				batch = append(batch, NewTextMessageEndEvent(id))
+			}
+		}
+
+		incremental := NewEventValidator(WithMaxEventSize(1 << 10))
+		var expected []bool
+		for _, event := range batch {
+			expected = append(expected, incremental.ValidateEvent(event) == nil)
+		}
+
+		result, err := NewEventValidator(WithMaxEventSize(1<<10)).ValidateBatch(context.Background(), batch, BatchOptions{Parallelism: 8})
+		require.NoError(t, err)
+		for i, r := range result.Results {
+			assert.Equal(t, expected[i], r.Err == nil, "event %d", i)
+		}
+		assert.Len(t, result.Findings, 1+46, "the run and the messages never ended")
+	})
+
+	t.Run("nil events", func(t *testing.T) {
+		result, err := NewEventValidator().ValidateBatch(context.Background(), []Event{nil}, BatchOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Invalid)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		result, err := NewEventValidator().ValidateBatch(ctx, batchWithErrors(), BatchOptions{})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, len(batchWithErrors()), result.Skipped)
+		assert.NoError(t, result.Err())
+	})
+}
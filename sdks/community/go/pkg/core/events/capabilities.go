@@ -0,0 +1,113 @@
+package events
+
+import (
+	"slices"
+	"strings"
+)
+
+// Capabilities are advertised so that clients and servers of different
+// versions degrade gracefully. A server describes what it supports in
+// response to a GET on its agent endpoint, the connection handshake, and
+// lists its extensions in ExtensionsHeader on each run response; clients
+// list theirs in ExtensionsHeader on each run request.
+const (
+	// ExtensionsHeader is the HTTP header listing the extensions supported
+	// by the sender, comma separated
+	ExtensionsHeader = "X-AG-UI-Extensions"
+
+	// ContentTypeJSON is the format of events sent as JSON
+	ContentTypeJSON = "application/json"
+
+	// IdempotencyKeyHeader is the HTTP header identifying a run request
+	// across retries, so that servers re-attach retried requests to the run
+	// the first one started
+	IdempotencyKeyHeader = "Idempotency-Key"
+)
+
+// Extensions are the protocol extensions defined by this package, named
+// after their CUSTOM events
+const (
+	ExtensionHeartbeat      = HeartbeatEventName
+	ExtensionSnapshotChunks = MessagesSnapshotChunkEventName
+	ExtensionAttachments    = AttachmentEventName
+	ExtensionUsage          = UsageEventName
+	ExtensionFlowControl    = FlowControlEventName
+
+	// ExtensionDiagnostics is advertised by diagnostic endpoints only
+	ExtensionDiagnostics = DiagnosticEchoEventName
+)
+
+// Capabilities describes what a peer supports
+type Capabilities struct {
+	// EventTypes are the event types the peer understands, nil when unknown
+	EventTypes []EventType `json:"eventTypes,omitempty"`
+
+	// Formats are the content types events can be encoded in
+	Formats []string `json:"formats"`
+
+	// Extensions are the protocol extensions supported
+	Extensions []string `json:"extensions"`
+
+	// ProtocolVersions are the protocol versions the peer can speak, oldest
+	// first
+	ProtocolVersions []string `json:"protocolVersions,omitempty"`
+
+	// MaxEventSize and MaxBodyBytes are the largest event, in its JSON
+	// encoding, and the largest run request accepted (0 = unlimited)
+	MaxEventSize int   `json:"maxEventSize,omitempty"`
+	MaxBodyBytes int64 `json:"maxBodyBytes,omitempty"`
+
+	// Legacy is set for peers that do not advertise their capabilities,
+	// which are assumed to support the base protocol only
+	Legacy bool `json:"-"`
+}
+
+// DefaultCapabilities returns the capabilities of this package: every
+// event type and extension it defines, in JSON
+func DefaultCapabilities() Capabilities {
+	eventTypes := make([]EventType, 0, len(validEventTypes))
+	for eventType := range validEventTypes {
+		eventTypes = append(eventTypes, eventType)
+	}
+	slices.Sort(eventTypes)
+	return Capabilities{
+		EventTypes: eventTypes,
+		Formats:    []string{ContentTypeJSON},
+		Extensions: []string{ExtensionAttachments, ExtensionFlowControl, ExtensionHeartbeat, ExtensionSnapshotChunks, ExtensionUsage},
+
+		ProtocolVersions: SupportedProtocolVersions(),
+	}
+}
+
+// LegacyCapabilities returns the capabilities assumed for peers that do not
+// advertise theirs
+func LegacyCapabilities() Capabilities {
+	return Capabilities{Formats: []string{ContentTypeJSON}, Extensions: []string{}, Legacy: true}
+}
+
+// SupportsEvent reports whether the peer understands an event type,
+// assuming it does when its event types are unknown
+func (c Capabilities) SupportsEvent(eventType EventType) bool {
+	return c.EventTypes == nil || slices.Contains(c.EventTypes, eventType)
+}
+
+// SupportsExtension reports whether the peer supports an extension
+func (c Capabilities) SupportsExtension(name string) bool {
+	return slices.Contains(c.Extensions, name)
+}
+
+// ParseExtensions parses the value of ExtensionsHeader
+func ParseExtensions(header string) []string {
+	extensions := []string{}
+	for _, name := range strings.Split(header, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			extensions = append(extensions, name)
+		}
+	}
+	return extensions
+}
+
+// FormatExtensions formats extensions as the value of ExtensionsHeader
+func FormatExtensions(extensions []string) string {
+	return strings.Join(extensions, ", ")
+}
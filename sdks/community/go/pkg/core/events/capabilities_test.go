@@ -0,0 +1,44 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilities(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		capabilities := DefaultCapabilities()
+		assert.Len(t, capabilities.EventTypes, len(validEventTypes))
+		assert.True(t, capabilities.SupportsEvent(EventTypeActivitySnapshot))
+		assert.False(t, capabilities.SupportsEvent(EventType("UNKNOWN")))
+		for _, extension := range []string{ExtensionHeartbeat, ExtensionSnapshotChunks, ExtensionAttachments, ExtensionUsage, ExtensionFlowControl} {
+			assert.True(t, capabilities.SupportsExtension(extension), extension)
+		}
+	})
+
+	t.Run("legacy", func(t *testing.T) {
+		capabilities := LegacyCapabilities()
+		assert.True(t, capabilities.Legacy)
+		assert.True(t, capabilities.SupportsEvent(EventType("UNKNOWN")), "event types of legacy peers are unknown")
+		assert.False(t, capabilities.SupportsExtension(ExtensionHeartbeat))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		capabilities := DefaultCapabilities()
+		capabilities.MaxEventSize = 1024
+		data, err := json.Marshal(capabilities)
+		require.NoError(t, err)
+		var decoded Capabilities
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, capabilities, decoded)
+	})
+
+	t.Run("extensions header", func(t *testing.T) {
+		assert.Equal(t, []string{"heartbeat", "usage"}, ParseExtensions(" heartbeat,, usage "))
+		assert.Empty(t, ParseExtensions(""))
+		assert.Equal(t, "heartbeat, usage", FormatExtensions([]string{"heartbeat", "usage"}))
+	})
+}
@@ -0,0 +1,65 @@
+package events
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Continuity tokens let clients resume the stream of a run where a dropped
+// connection left it. Servers stamp each frame of a resumable run, one
+// requested with IdempotencyKeyHeader, with a token as its SSE event ID; a
+// client reconnecting presents the token of the last frame it received in
+// LastEventIDHeader, along with the idempotency key, and the server resumes
+// with the event following it instead of replaying the run from the start.
+const (
+	// LastEventIDHeader is the HTTP header carrying the continuity token of
+	// the last event received, as defined for SSE
+	LastEventIDHeader = "Last-Event-ID"
+
+	// continuityTokenPrefix versions the format of continuity tokens
+	continuityTokenPrefix = "ct1."
+)
+
+// ErrInvalidContinuityToken is returned for malformed continuity tokens, and
+// for tokens that do not designate an event of the run they are presented
+// for
+var ErrInvalidContinuityToken = errors.New("invalid continuity token")
+
+// ContinuityToken designates an event delivered for a run
+type ContinuityToken struct {
+	RunID string
+
+	// Seq is the sequence number of the event among those streamed for the
+	// run, starting at 1
+	Seq int
+}
+
+// String encodes the token, as stamped on SSE frames. Tokens are opaque to
+// clients, which present them as received.
+func (t ContinuityToken) String() string {
+	return continuityTokenPrefix + base64.RawURLEncoding.EncodeToString([]byte(t.RunID)) + "." + strconv.Itoa(t.Seq)
+}
+
+// ParseContinuityToken parses a token encoded with ContinuityToken.String
+func ParseContinuityToken(s string) (ContinuityToken, error) {
+	encoded, ok := strings.CutPrefix(s, continuityTokenPrefix)
+	if !ok {
+		return ContinuityToken{}, fmt.Errorf("%w: %q", ErrInvalidContinuityToken, s)
+	}
+	runID, seq, ok := strings.Cut(encoded, ".")
+	if !ok {
+		return ContinuityToken{}, fmt.Errorf("%w: %q", ErrInvalidContinuityToken, s)
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(runID)
+	if err != nil || len(decoded) == 0 {
+		return ContinuityToken{}, fmt.Errorf("%w: %q has no run ID", ErrInvalidContinuityToken, s)
+	}
+	n, err := strconv.Atoi(seq)
+	if err != nil || n <= 0 {
+		return ContinuityToken{}, fmt.Errorf("%w: %q has no sequence number", ErrInvalidContinuityToken, s)
+	}
+	return ContinuityToken{RunID: string(decoded), Seq: n}, nil
+}
@@ -0,0 +1,29 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContinuityToken(t *testing.T) {
+	token := ContinuityToken{RunID: "run-1.retry:2", Seq: 42}
+	parsed, err := ParseContinuityToken(token.String())
+	require.NoError(t, err)
+	assert.Equal(t, token, parsed)
+
+	for _, s := range []string{
+		"",
+		"RUN_STARTED_1700000000",
+		"ct1.",
+		"ct1.cnVuLTE",
+		"ct1..3",
+		"ct1.cnVuLTE.0",
+		"ct1.cnVuLTE.many",
+		"ct1.!!!.3",
+	} {
+		_, err := ParseContinuityToken(s)
+		assert.ErrorIs(t, err, ErrInvalidContinuityToken, s)
+	}
+}
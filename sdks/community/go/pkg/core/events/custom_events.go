@@ -3,6 +3,7 @@ package events
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 // RawEvent contains raw event data that should be passed through without processing
@@ -102,3 +103,44 @@ func (e *CustomEvent) Validate() error {
 func (e *CustomEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
+
+// Run heartbeats extend the protocol with CUSTOM events that a server emits
+// while a run is silent, so that a client can tell a long-running run from a
+// stalled one. A client requests them by sending HeartbeatHeader with the
+// interval in seconds; each heartbeat carries the interval in effect.
+const (
+	// HeartbeatEventName is the name of heartbeat CUSTOM events
+	HeartbeatEventName = "heartbeat"
+
+	// HeartbeatHeader is the HTTP header requesting heartbeats for a run
+	HeartbeatHeader = "X-AG-UI-Heartbeat"
+)
+
+// Heartbeat is the value of a heartbeat event
+type Heartbeat struct {
+	// IntervalMs is the time after which the next heartbeat is due, unless
+	// other events are emitted in between
+	IntervalMs int64 `json:"intervalMs"`
+}
+
+// NewHeartbeatEvent creates a heartbeat announcing the given interval
+func NewHeartbeatEvent(interval time.Duration) *CustomEvent {
+	return NewCustomEvent(HeartbeatEventName, WithValue(Heartbeat{IntervalMs: interval.Milliseconds()}))
+}
+
+// HeartbeatInterval reports whether event is a heartbeat and returns the
+// interval it announces (0 when missing)
+func HeartbeatInterval(event Event) (time.Duration, bool) {
+	custom, ok := event.(*CustomEvent)
+	if !ok || custom.Name != HeartbeatEventName {
+		return 0, false
+	}
+	switch value := custom.Value.(type) {
+	case Heartbeat:
+		return time.Duration(value.IntervalMs) * time.Millisecond, true
+	case map[string]any:
+		ms, _ := value["intervalMs"].(float64)
+		return time.Duration(ms * float64(time.Millisecond)), true
+	}
+	return 0, true
+}
@@ -20,6 +20,16 @@ func NewEventDecoder(logger *logrus.Logger) *EventDecoder {
 	return &EventDecoder{logger: logger}
 }
 
+// Decode decodes an event from its JSON encoding in a single pass, reading
+// its type with PeekEventType
+func (ed *EventDecoder) Decode(data []byte) (Event, error) {
+	eventType, err := PeekEventType(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse event type: %w", err)
+	}
+	return ed.DecodeEvent(string(eventType), data)
+}
+
 // DecodeEvent decodes a raw SSE event into the appropriate Go SDK event type
 func (ed *EventDecoder) DecodeEvent(eventName string, data []byte) (Event, error) {
 	eventType := EventType(eventName)
@@ -130,6 +140,41 @@ func (ed *EventDecoder) DecodeEvent(eventName string, data []byte) (Event, error
 		}
 		return &evt, nil
 
+	case EventTypeMessageUpdated:
+		var evt MessageUpdatedEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, fmt.Errorf("failed to decode MESSAGE_UPDATED: %w", err)
+		}
+		return &evt, nil
+
+	case EventTypeMessageDeleted:
+		var evt MessageDeletedEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, fmt.Errorf("failed to decode MESSAGE_DELETED: %w", err)
+		}
+		return &evt, nil
+
+	case EventTypeApprovalRequested:
+		var evt ApprovalRequestedEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, fmt.Errorf("failed to decode APPROVAL_REQUESTED: %w", err)
+		}
+		return &evt, nil
+
+	case EventTypeApprovalGranted:
+		var evt ApprovalGrantedEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, fmt.Errorf("failed to decode APPROVAL_GRANTED: %w", err)
+		}
+		return &evt, nil
+
+	case EventTypeApprovalDenied:
+		var evt ApprovalDeniedEvent
+		if err := json.Unmarshal(data, &evt); err != nil {
+			return nil, fmt.Errorf("failed to decode APPROVAL_DENIED: %w", err)
+		}
+		return &evt, nil
+
 	case EventTypeActivitySnapshot:
 		var evt ActivitySnapshotEvent
 		if err := json.Unmarshal(data, &evt); err != nil {
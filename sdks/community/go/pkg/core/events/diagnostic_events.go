@@ -0,0 +1,81 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Diagnostic endpoints serve runs for clients testing the path to an agent,
+// advertising ExtensionDiagnostics. A diagnostic run starts with a CUSTOM
+// event echoing what the endpoint received, then streams DiagnosticProbe in
+// a text message, chunk by chunk, so that clients can check that events are
+// negotiated, authenticated, encoded and streamed end to end.
+const (
+	// DiagnosticEchoEventName is the name of the CUSTOM event starting a
+	// diagnostic run
+	DiagnosticEchoEventName = "diagnostic_echo"
+
+	// DiagnosticProbe is the text streamed by diagnostic runs, mixing
+	// characters that break lossy encodings
+	DiagnosticProbe = "ASCII, Latin-1 é, CJK 世界, emoji 🚀, \"quotes\", \\backslash\\, tab\t and\nnewline"
+)
+
+// ErrInvalidDiagnosticEcho is returned for malformed diagnostic echoes
+var ErrInvalidDiagnosticEcho = errors.New("invalid diagnostic echo")
+
+// DiagnosticEcho is the value of a diagnostic echo event: the request of the
+// run as the endpoint received it
+type DiagnosticEcho struct {
+	// ProtocolVersion is the version negotiated for the run
+	ProtocolVersion string `json:"protocolVersion"`
+
+	// Extensions are the extensions the client listed in ExtensionsHeader
+	Extensions []string `json:"extensions,omitempty"`
+
+	// Principal is the ID of the authenticated caller, empty for anonymous
+	// ones
+	Principal string `json:"principal,omitempty"`
+
+	// Headers are the request headers relevant to streaming, e.g. Accept
+	// and IdempotencyKeyHeader, credentials excluded
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Chunks is the number of TEXT_MESSAGE_CONTENT events DiagnosticProbe
+	// is streamed in, IntervalMs the time between two of them
+	Chunks     int   `json:"chunks"`
+	IntervalMs int64 `json:"intervalMs"`
+}
+
+// NewDiagnosticEchoEvent creates a diagnostic echo event
+func NewDiagnosticEchoEvent(echo DiagnosticEcho) *CustomEvent {
+	return NewCustomEvent(DiagnosticEchoEventName, WithValue(echo))
+}
+
+// DiagnosticEchoOf reports whether event is a diagnostic echo event and
+// returns its echo
+func DiagnosticEchoOf(event Event) (*DiagnosticEcho, bool, error) {
+	custom, ok := event.(*CustomEvent)
+	if !ok || custom.Name != DiagnosticEchoEventName {
+		return nil, false, nil
+	}
+	var echo DiagnosticEcho
+	switch value := custom.Value.(type) {
+	case DiagnosticEcho:
+		echo = value
+	case *DiagnosticEcho:
+		echo = *value
+	default:
+		data, err := json.Marshal(value)
+		if err == nil {
+			err = json.Unmarshal(data, &echo)
+		}
+		if err != nil {
+			return nil, true, fmt.Errorf("%w: %v", ErrInvalidDiagnosticEcho, err)
+		}
+	}
+	if echo.Chunks < 0 || echo.IntervalMs < 0 {
+		return nil, true, fmt.Errorf("%w: chunks and intervalMs must not be negative", ErrInvalidDiagnosticEcho)
+	}
+	return &echo, true, nil
+}
@@ -0,0 +1,300 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sync"
+
+	agerrors "github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/errors"
+)
+
+// HandlerPanicError reports that a handler of a Dispatcher panicked, as a
+// critical error
+type HandlerPanicError struct {
+	// EventType is the type of the event the handler panicked on
+	EventType EventType
+
+	// PanicError holds the value the handler panicked with and the stack
+	// trace of the panic
+	*agerrors.PanicError
+}
+
+func (e *HandlerPanicError) Error() string {
+	return fmt.Sprintf("handler of %s panicked: %v", e.EventType, e.Value)
+}
+
+func (e *HandlerPanicError) Unwrap() error {
+	return e.PanicError
+}
+
+// Dispatcher hands decoded events to the handlers registered for their type,
+// so that consumers handle the events they are interested in rather than
+// switching over every event type. Handlers are registered with the On
+// methods, or On for any event type, and with OnEvent for every event.
+//
+// Handlers run synchronously, in the order they were registered, and events
+// are handled one at a time in the order they are dispatched, even when
+// Dispatch is called concurrently. The handlers registered with OnUnhandled
+// run last, for the events no typed handler handled. A panicking handler
+// does not keep the other handlers from running: Dispatch recovers the panic
+// and returns it as a *HandlerPanicError. Handlers may register handlers,
+// which take effect from the next event.
+type Dispatcher struct {
+	// mu serializes dispatches
+	mu sync.Mutex
+
+	handlersMu sync.RWMutex
+	handlers   []dispatchHandler
+	unhandled  []func(Event)
+}
+
+// dispatchHandler is a handler of the events of a type, or of every event
+// when eventType is nil
+type dispatchHandler struct {
+	eventType reflect.Type
+	handle    func(Event)
+}
+
+// NewDispatcher creates a dispatcher without handlers
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{}
+}
+
+// On registers handler for the events of type T, e.g.
+// *TextMessageContentEvent
+func On[T Event](d *Dispatcher, handler func(T)) {
+	d.register(reflect.TypeFor[T](), func(event Event) { handler(event.(T)) })
+}
+
+// OnEvent registers handler for every event
+func (d *Dispatcher) OnEvent(handler func(Event)) {
+	d.register(nil, handler)
+}
+
+// OnUnhandled registers handler for the events no typed handler handles
+func (d *Dispatcher) OnUnhandled(handler func(Event)) {
+	d.handlersMu.Lock()
+	defer d.handlersMu.Unlock()
+	d.unhandled = append(d.unhandled, handler)
+}
+
+func (d *Dispatcher) register(eventType reflect.Type, handle func(Event)) {
+	d.handlersMu.Lock()
+	defer d.handlersMu.Unlock()
+	d.handlers = append(d.handlers, dispatchHandler{eventType: eventType, handle: handle})
+}
+
+// Dispatch hands event to its handlers and returns the panics of those
+// that panicked
+func (d *Dispatcher) Dispatch(event Event) error {
+	if event == nil {
+		return fmt.Errorf("event cannot be nil")
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.handlersMu.RLock()
+	handlers, unhandled := d.handlers, d.unhandled
+	d.handlersMu.RUnlock()
+
+	eventType := reflect.TypeOf(event)
+	handled := false
+	var errs []error
+	for _, h := range handlers {
+		if h.eventType != nil {
+			if h.eventType != eventType {
+				continue
+			}
+			handled = true
+		}
+		if err := dispatchTo(h.handle, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if !handled {
+		for _, handle := range unhandled {
+			if err := dispatchTo(handle, event); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// dispatchTo runs a handler, recovering its panic
+func dispatchTo(handle func(Event), event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &HandlerPanicError{EventType: event.Type(), PanicError: agerrors.NewPanicError(r, debug.Stack())}
+		}
+	}()
+	handle(event)
+	return nil
+}
+
+// OnRunStarted registers handler for RUN_STARTED events
+func (d *Dispatcher) OnRunStarted(handler func(*RunStartedEvent)) {
+	On(d, handler)
+}
+
+// OnRunFinished registers handler for RUN_FINISHED events
+func (d *Dispatcher) OnRunFinished(handler func(*RunFinishedEvent)) {
+	On(d, handler)
+}
+
+// OnRunError registers handler for RUN_ERROR events
+func (d *Dispatcher) OnRunError(handler func(*RunErrorEvent)) {
+	On(d, handler)
+}
+
+// OnStepStarted registers handler for STEP_STARTED events
+func (d *Dispatcher) OnStepStarted(handler func(*StepStartedEvent)) {
+	On(d, handler)
+}
+
+// OnStepFinished registers handler for STEP_FINISHED events
+func (d *Dispatcher) OnStepFinished(handler func(*StepFinishedEvent)) {
+	On(d, handler)
+}
+
+// OnTextMessageStart registers handler for TEXT_MESSAGE_START events
+func (d *Dispatcher) OnTextMessageStart(handler func(*TextMessageStartEvent)) {
+	On(d, handler)
+}
+
+// OnTextMessageContent registers handler for TEXT_MESSAGE_CONTENT events
+func (d *Dispatcher) OnTextMessageContent(handler func(*TextMessageContentEvent)) {
+	On(d, handler)
+}
+
+// OnTextMessageEnd registers handler for TEXT_MESSAGE_END events
+func (d *Dispatcher) OnTextMessageEnd(handler func(*TextMessageEndEvent)) {
+	On(d, handler)
+}
+
+// OnTextMessageChunk registers handler for TEXT_MESSAGE_CHUNK events
+func (d *Dispatcher) OnTextMessageChunk(handler func(*TextMessageChunkEvent)) {
+	On(d, handler)
+}
+
+// OnToolCallStart registers handler for TOOL_CALL_START events
+func (d *Dispatcher) OnToolCallStart(handler func(*ToolCallStartEvent)) {
+	On(d, handler)
+}
+
+// OnToolCallArgs registers handler for TOOL_CALL_ARGS events
+func (d *Dispatcher) OnToolCallArgs(handler func(*ToolCallArgsEvent)) {
+	On(d, handler)
+}
+
+// OnToolCallEnd registers handler for TOOL_CALL_END events
+func (d *Dispatcher) OnToolCallEnd(handler func(*ToolCallEndEvent)) {
+	On(d, handler)
+}
+
+// OnToolCallChunk registers handler for TOOL_CALL_CHUNK events
+func (d *Dispatcher) OnToolCallChunk(handler func(*ToolCallChunkEvent)) {
+	On(d, handler)
+}
+
+// OnToolCallResult registers handler for TOOL_CALL_RESULT events
+func (d *Dispatcher) OnToolCallResult(handler func(*ToolCallResultEvent)) {
+	On(d, handler)
+}
+
+// OnStateSnapshot registers handler for STATE_SNAPSHOT events
+func (d *Dispatcher) OnStateSnapshot(handler func(*StateSnapshotEvent)) {
+	On(d, handler)
+}
+
+// OnStateDelta registers handler for STATE_DELTA events
+func (d *Dispatcher) OnStateDelta(handler func(*StateDeltaEvent)) {
+	On(d, handler)
+}
+
+// OnMessagesSnapshot registers handler for MESSAGES_SNAPSHOT events
+func (d *Dispatcher) OnMessagesSnapshot(handler func(*MessagesSnapshotEvent)) {
+	On(d, handler)
+}
+
+// OnMessageUpdated registers handler for MESSAGE_UPDATED events
+func (d *Dispatcher) OnMessageUpdated(handler func(*MessageUpdatedEvent)) {
+	On(d, handler)
+}
+
+// OnMessageDeleted registers handler for MESSAGE_DELETED events
+func (d *Dispatcher) OnMessageDeleted(handler func(*MessageDeletedEvent)) {
+	On(d, handler)
+}
+
+// OnApprovalRequested registers handler for APPROVAL_REQUESTED events
+func (d *Dispatcher) OnApprovalRequested(handler func(*ApprovalRequestedEvent)) {
+	On(d, handler)
+}
+
+// OnApprovalGranted registers handler for APPROVAL_GRANTED events
+func (d *Dispatcher) OnApprovalGranted(handler func(*ApprovalGrantedEvent)) {
+	On(d, handler)
+}
+
+// OnApprovalDenied registers handler for APPROVAL_DENIED events
+func (d *Dispatcher) OnApprovalDenied(handler func(*ApprovalDeniedEvent)) {
+	On(d, handler)
+}
+
+// OnActivitySnapshot registers handler for ACTIVITY_SNAPSHOT events
+func (d *Dispatcher) OnActivitySnapshot(handler func(*ActivitySnapshotEvent)) {
+	On(d, handler)
+}
+
+// OnActivityDelta registers handler for ACTIVITY_DELTA events
+func (d *Dispatcher) OnActivityDelta(handler func(*ActivityDeltaEvent)) {
+	On(d, handler)
+}
+
+// OnReasoningStart registers handler for REASONING_START events
+func (d *Dispatcher) OnReasoningStart(handler func(*ReasoningStartEvent)) {
+	On(d, handler)
+}
+
+// OnReasoningMessageStart registers handler for REASONING_MESSAGE_START events
+func (d *Dispatcher) OnReasoningMessageStart(handler func(*ReasoningMessageStartEvent)) {
+	On(d, handler)
+}
+
+// OnReasoningMessageContent registers handler for REASONING_MESSAGE_CONTENT events
+func (d *Dispatcher) OnReasoningMessageContent(handler func(*ReasoningMessageContentEvent)) {
+	On(d, handler)
+}
+
+// OnReasoningMessageEnd registers handler for REASONING_MESSAGE_END events
+func (d *Dispatcher) OnReasoningMessageEnd(handler func(*ReasoningMessageEndEvent)) {
+	On(d, handler)
+}
+
+// OnReasoningMessageChunk registers handler for REASONING_MESSAGE_CHUNK events
+func (d *Dispatcher) OnReasoningMessageChunk(handler func(*ReasoningMessageChunkEvent)) {
+	On(d, handler)
+}
+
+// OnReasoningEnd registers handler for REASONING_END events
+func (d *Dispatcher) OnReasoningEnd(handler func(*ReasoningEndEvent)) {
+	On(d, handler)
+}
+
+// OnReasoningEncryptedValue registers handler for REASONING_ENCRYPTED_VALUE events
+func (d *Dispatcher) OnReasoningEncryptedValue(handler func(*ReasoningEncryptedValueEvent)) {
+	On(d, handler)
+}
+
+// OnRaw registers handler for RAW events
+func (d *Dispatcher) OnRaw(handler func(*RawEvent)) {
+	On(d, handler)
+}
+
+// OnCustom registers handler for CUSTOM events
+func (d *Dispatcher) OnCustom(handler func(*CustomEvent)) {
+	On(d, handler)
+}
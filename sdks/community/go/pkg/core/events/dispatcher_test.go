@@ -0,0 +1,101 @@
+package events
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	agerrors "github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/errors"
+)
+
+func TestDispatcher(t *testing.T) {
+	d := NewDispatcher()
+	var calls []string
+	d.OnTextMessageContent(func(e *TextMessageContentEvent) {
+		calls = append(calls, "content:"+e.Delta)
+	})
+	d.OnEvent(func(e Event) {
+		calls = append(calls, "any:"+string(e.Type()))
+	})
+	On(d, func(e *TextMessageContentEvent) {
+		calls = append(calls, "generic:"+e.Delta)
+	})
+	d.OnToolCallEnd(func(e *ToolCallEndEvent) {
+		calls = append(calls, "end:"+e.ToolCallID)
+	})
+	d.OnUnhandled(func(e Event) {
+		calls = append(calls, "unhandled:"+string(e.Type()))
+	})
+
+	for _, event := range []Event{
+		NewTextMessageContentEvent("msg-1", "Hello"),
+		NewToolCallEndEvent("tool-1"),
+		NewStepStartedEvent("plan"),
+	} {
+		require.NoError(t, d.Dispatch(event))
+	}
+	assert.Equal(t, []string{
+		"content:Hello", "any:TEXT_MESSAGE_CONTENT", "generic:Hello",
+		"any:TOOL_CALL_END", "end:tool-1",
+		"any:STEP_STARTED", "unhandled:STEP_STARTED",
+	}, calls, "handlers run in registration order, unhandled ones last")
+
+	assert.Error(t, d.Dispatch(nil))
+}
+
+func TestDispatcherIsolatesPanics(t *testing.T) {
+	d := NewDispatcher()
+	var handled []string
+	d.OnStepStarted(func(*StepStartedEvent) {
+		panic("boom")
+	})
+	d.OnStepStarted(func(e *StepStartedEvent) {
+		handled = append(handled, e.StepName)
+	})
+
+	err := d.Dispatch(NewStepStartedEvent("plan"))
+	var panicErr *HandlerPanicError
+	require.True(t, errors.As(err, &panicErr), "got %v", err)
+	assert.Equal(t, EventTypeStepStarted, panicErr.EventType)
+	assert.Equal(t, "boom", panicErr.Value)
+	assert.NotEmpty(t, panicErr.Stack)
+	assert.Equal(t, agerrors.SeverityCritical, agerrors.GetSeverity(err))
+	assert.Equal(t, []string{"plan"}, handled, "the other handlers still run")
+
+	require.Error(t, d.Dispatch(NewStepStartedEvent("search")))
+	assert.Equal(t, []string{"plan", "search"}, handled)
+}
+
+func TestDispatcherSerializesEvents(t *testing.T) {
+	d := NewDispatcher()
+	var active, overlaps, count int
+	d.OnEvent(func(Event) {
+		active++
+		if active > 1 {
+			overlaps++
+		}
+		count++
+		active--
+	})
+	// Handlers registered while dispatching apply from the next event
+	d.OnStepStarted(func(*StepStartedEvent) {
+		d.OnStepFinished(func(*StepFinishedEvent) {})
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				assert.NoError(t, d.Dispatch(NewStepStartedEvent("step")))
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 800, count)
+	assert.Zero(t, overlaps)
+}
@@ -23,6 +23,11 @@ const (
 	EventTypeStateSnapshot      EventType = "STATE_SNAPSHOT"
 	EventTypeStateDelta         EventType = "STATE_DELTA"
 	EventTypeMessagesSnapshot   EventType = "MESSAGES_SNAPSHOT"
+	EventTypeMessageUpdated     EventType = "MESSAGE_UPDATED"
+	EventTypeMessageDeleted     EventType = "MESSAGE_DELETED"
+	EventTypeApprovalRequested  EventType = "APPROVAL_REQUESTED"
+	EventTypeApprovalGranted    EventType = "APPROVAL_GRANTED"
+	EventTypeApprovalDenied     EventType = "APPROVAL_DENIED"
 	EventTypeActivitySnapshot   EventType = "ACTIVITY_SNAPSHOT"
 	EventTypeActivityDelta      EventType = "ACTIVITY_DELTA"
 	EventTypeRaw                EventType = "RAW"
@@ -85,6 +90,11 @@ var validEventTypes = map[EventType]bool{
 	EventTypeStateSnapshot:              true,
 	EventTypeStateDelta:                 true,
 	EventTypeMessagesSnapshot:           true,
+	EventTypeMessageUpdated:             true,
+	EventTypeMessageDeleted:             true,
+	EventTypeApprovalRequested:          true,
+	EventTypeApprovalGranted:            true,
+	EventTypeApprovalDenied:             true,
 	EventTypeActivitySnapshot:           true,
 	EventTypeActivityDelta:              true,
 	EventTypeRaw:                        true,
@@ -140,6 +150,14 @@ type BaseEvent struct {
 	EventType   EventType `json:"type"`
 	TimestampMs *int64    `json:"timestamp,omitempty"`
 	RawEvent    any       `json:"rawEvent,omitempty"`
+
+	// ProtocolVersion is the protocol version of the run, set on RUN_STARTED
+	// events by servers negotiating it (see NegotiateProtocolVersion)
+	ProtocolVersion string `json:"protocolVersion,omitempty"`
+
+	// Metadata is attached to the event by the server emitting it, e.g. the
+	// tenant of the run, leaving RawEvent to the producer of the event
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 // Type returns the event type
@@ -180,6 +198,14 @@ func (b *BaseEvent) ToJSON() ([]byte, error) {
 		eventData["data"] = b.RawEvent
 	}
 
+	if b.ProtocolVersion != "" {
+		eventData["protocolVersion"] = b.ProtocolVersion
+	}
+
+	if len(b.Metadata) > 0 {
+		eventData["metadata"] = b.Metadata
+	}
+
 	return json.Marshal(eventData)
 }
 
@@ -231,219 +257,32 @@ func ValidateSequence(events []Event) error {
 		return nil
 	}
 
-	// Track active runs, messages, tool calls, and steps
-	activeRuns := make(map[string]bool)
-	activeMessages := make(map[string]bool)
-	activeReasoningMessages := make(map[string]bool)
-	activeToolCalls := make(map[string]bool)
-	activeSteps := make(map[string]bool)
-	finishedRuns := make(map[string]bool)
-
+	validator := NewEventValidator()
 	for i, event := range events {
 		if err := event.Validate(); err != nil {
 			return fmt.Errorf("event %d validation failed: %w", i, err)
 		}
 
-		// Check sequence-specific validation rules
-		switch event.Type() {
-		case EventTypeRunStarted:
-			if runEvent, ok := event.(*RunStartedEvent); ok {
-				if activeRuns[runEvent.RunID()] {
-					return fmt.Errorf("run %s already started", runEvent.RunID())
-				}
-				if finishedRuns[runEvent.RunID()] {
-					return fmt.Errorf("cannot restart finished run %s", runEvent.RunID())
-				}
-				activeRuns[runEvent.RunID()] = true
-			}
-
-		case EventTypeRunFinished:
-			if runEvent, ok := event.(*RunFinishedEvent); ok {
-				if !activeRuns[runEvent.RunID()] {
-					return fmt.Errorf("cannot finish run %s that was not started", runEvent.RunID())
-				}
-				delete(activeRuns, runEvent.RunID())
-				finishedRuns[runEvent.RunID()] = true
-			}
-
-		case EventTypeRunError:
-			if runEvent, ok := event.(*RunErrorEvent); ok {
-				if runEvent.RunID() != "" && !activeRuns[runEvent.RunID()] {
-					return fmt.Errorf("cannot error run %s that was not started", runEvent.RunID())
-				}
-				if runEvent.RunID() != "" {
-					delete(activeRuns, runEvent.RunID())
-					finishedRuns[runEvent.RunID()] = true
-				}
-			}
-
-		case EventTypeStepStarted:
-			if stepEvent, ok := event.(*StepStartedEvent); ok {
-				if activeSteps[stepEvent.StepName] {
-					return fmt.Errorf("step %s already started", stepEvent.StepName)
-				}
-				activeSteps[stepEvent.StepName] = true
-			}
-
-		case EventTypeStepFinished:
-			if stepEvent, ok := event.(*StepFinishedEvent); ok {
-				if !activeSteps[stepEvent.StepName] {
-					return fmt.Errorf("cannot finish step %s that was not started", stepEvent.StepName)
-				}
-				delete(activeSteps, stepEvent.StepName)
-			}
-
-		case EventTypeTextMessageStart:
-			if msgEvent, ok := event.(*TextMessageStartEvent); ok {
-				if activeMessages[msgEvent.MessageID] {
-					return fmt.Errorf("message %s already started", msgEvent.MessageID)
-				}
-				activeMessages[msgEvent.MessageID] = true
-			}
-
-		case EventTypeTextMessageContent:
-			if msgEvent, ok := event.(*TextMessageContentEvent); ok {
-				if !activeMessages[msgEvent.MessageID] {
-					return fmt.Errorf("cannot add content to message %s that was not started", msgEvent.MessageID)
-				}
-				// Content events are valid between start and end
-			}
-
-		case EventTypeTextMessageEnd:
-			if msgEvent, ok := event.(*TextMessageEndEvent); ok {
-				if !activeMessages[msgEvent.MessageID] {
-					return fmt.Errorf("cannot end message %s that was not started", msgEvent.MessageID)
-				}
-				delete(activeMessages, msgEvent.MessageID)
-			}
-
-		case EventTypeTextMessageChunk:
-			// Chunk events are always valid in sequence context.
-
-		case EventTypeToolCallStart:
-			if toolEvent, ok := event.(*ToolCallStartEvent); ok {
-				if activeToolCalls[toolEvent.ToolCallID] {
-					return fmt.Errorf("tool call %s already started", toolEvent.ToolCallID)
-				}
-				activeToolCalls[toolEvent.ToolCallID] = true
-			}
-
-		case EventTypeToolCallArgs:
-			if toolEvent, ok := event.(*ToolCallArgsEvent); ok {
-				if !activeToolCalls[toolEvent.ToolCallID] {
-					return fmt.Errorf("cannot add args to tool call %s that was not started", toolEvent.ToolCallID)
-				}
-				// Args events are valid between start and end
-			}
-
-		case EventTypeToolCallEnd:
-			if toolEvent, ok := event.(*ToolCallEndEvent); ok {
-				if !activeToolCalls[toolEvent.ToolCallID] {
-					return fmt.Errorf("cannot end tool call %s that was not started", toolEvent.ToolCallID)
-				}
-				delete(activeToolCalls, toolEvent.ToolCallID)
-			}
-
-		case EventTypeToolCallChunk:
-			// Chunk events are always valid in sequence context.
-
-		case EventTypeToolCallResult:
-			// Tool call result events are always valid in sequence context.
-
-		case EventTypeThinkingStart, EventTypeThinkingEnd, EventTypeThinkingTextMessageStart, EventTypeThinkingTextMessageContent, EventTypeThinkingTextMessageEnd:
-			// Thinking events are always valid in sequence context.
-
-		case EventTypeReasoningStart:
-			// Reasoning events are always valid in sequence context.
-
-		case EventTypeReasoningMessageStart:
-			if msgEvent, ok := event.(*ReasoningMessageStartEvent); ok {
-				if activeReasoningMessages[msgEvent.MessageID] {
-					return fmt.Errorf("reasoning message %s already started", msgEvent.MessageID)
-				}
-				activeReasoningMessages[msgEvent.MessageID] = true
-			}
-
-		case EventTypeReasoningMessageContent:
-			if msgEvent, ok := event.(*ReasoningMessageContentEvent); ok {
-				if !activeReasoningMessages[msgEvent.MessageID] {
-					return fmt.Errorf("cannot add content to reasoning message %s that was not started", msgEvent.MessageID)
-				}
-			}
-
-		case EventTypeReasoningMessageEnd:
-			if msgEvent, ok := event.(*ReasoningMessageEndEvent); ok {
-				if !activeReasoningMessages[msgEvent.MessageID] {
-					return fmt.Errorf("cannot end reasoning message %s that was not started", msgEvent.MessageID)
-				}
-				delete(activeReasoningMessages, msgEvent.MessageID)
-			}
-
-		case EventTypeReasoningMessageChunk:
-			// Chunk events are always valid in sequence context.
-
-		case EventTypeReasoningEncryptedValue:
-			// Encrypted value events are always valid in sequence context.
-
-		case EventTypeReasoningEnd:
-			// Reasoning events are always valid in sequence context.
-
-		case EventTypeStateSnapshot:
-			// State snapshot events are always valid in sequence context
-			// They represent complete state at any point in time
-			// Additional validation could be added if needed (e.g., frequency limits)
-
-		case EventTypeStateDelta:
-			// State delta events are always valid in sequence context
-			// They represent incremental changes at any point in time
-			// Additional validation could be added if needed (e.g., conflict detection)
-
-		case EventTypeMessagesSnapshot:
-			// Message snapshot events are always valid in sequence context
-			// They represent complete message state at any point in time
-			// Additional validation could be added if needed (e.g., consistency checks)
-
-		case EventTypeActivitySnapshot:
-			// Activity snapshot events are always valid in sequence context
-			// They represent complete activity state at any point in time
-
-		case EventTypeActivityDelta:
-			// Activity delta events are always valid in sequence context
-			// They represent incremental activity changes at any point in time
-
-		case EventTypeRaw:
-			// Raw events are always valid in sequence context
-			// They contain external data that should be passed through
-			// Additional validation could be added via custom validators
-
-		case EventTypeCustom:
-			// Custom events are always valid in sequence context
-			// They contain application-specific data
-			// Additional validation could be added via custom validators
-
-		default:
-			// This should not happen due to prior validation, but add safety check
-			return fmt.Errorf("unknown event type in sequence: %s", event.Type())
+		if err := validator.applySequenceRules(event); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
-// EventFromJSON parses an event from JSON data
+// EventFromJSON parses an event from JSON data. The type is peeked without
+// unmarshaling (see PeekEventType), so data is only unmarshaled once, into
+// the concrete event.
 func EventFromJSON(data []byte) (Event, error) {
-	// First, parse the base event to determine the type
-	var base struct {
-		Type EventType `json:"type"`
-	}
-
-	if err := json.Unmarshal(data, &base); err != nil {
+	eventType, err := PeekEventType(data)
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse event type: %w", err)
 	}
 
 	// Create the appropriate event type based on the type field
 	var event Event
-	switch base.Type {
+	switch eventType {
 	case EventTypeRunStarted:
 		event = &RunStartedEvent{}
 	case EventTypeRunFinished:
@@ -476,6 +315,16 @@ func EventFromJSON(data []byte) (Event, error) {
 		event = &StateDeltaEvent{}
 	case EventTypeMessagesSnapshot:
 		event = &MessagesSnapshotEvent{}
+	case EventTypeMessageUpdated:
+		event = &MessageUpdatedEvent{}
+	case EventTypeMessageDeleted:
+		event = &MessageDeletedEvent{}
+	case EventTypeApprovalRequested:
+		event = &ApprovalRequestedEvent{}
+	case EventTypeApprovalGranted:
+		event = &ApprovalGrantedEvent{}
+	case EventTypeApprovalDenied:
+		event = &ApprovalDeniedEvent{}
 	case EventTypeActivitySnapshot:
 		event = &ActivitySnapshotEvent{}
 	case EventTypeActivityDelta:
@@ -499,7 +348,7 @@ func EventFromJSON(data []byte) (Event, error) {
 	case EventTypeReasoningEncryptedValue:
 		event = &ReasoningEncryptedValueEvent{}
 	default:
-		return nil, fmt.Errorf("unknown event type: %s", base.Type)
+		return nil, fmt.Errorf("unknown event type: %s", eventType)
 	}
 
 	// Unmarshal into the specific event type
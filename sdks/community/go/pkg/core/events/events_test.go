@@ -284,6 +284,42 @@ func TestMessageEvents(t *testing.T) {
 		event.MessageID = ""
 		assert.Error(t, event.Validate())
 	})
+
+	t.Run("MessageUpdatedEvent", func(t *testing.T) {
+		event := NewMessageUpdatedEvent("msg-123", "Paris is the capital").WithReason("fact check")
+
+		assert.Equal(t, EventTypeMessageUpdated, event.Type())
+		assert.NoError(t, event.Validate())
+
+		data, err := event.ToJSON()
+		require.NoError(t, err)
+		decoded, err := EventFromJSON(data)
+		require.NoError(t, err)
+		assert.Equal(t, event, decoded)
+
+		event.Content = ""
+		assert.Error(t, event.Validate())
+		event.Content = "Paris"
+		event.MessageID = ""
+		assert.Error(t, event.Validate())
+	})
+
+	t.Run("MessageDeletedEvent", func(t *testing.T) {
+		event := NewMessageDeletedEvent("msg-123")
+
+		assert.Equal(t, EventTypeMessageDeleted, event.Type())
+		assert.NoError(t, event.Validate())
+		assert.Nil(t, event.Reason)
+
+		data, err := event.WithReason("leaked a secret").ToJSON()
+		require.NoError(t, err)
+		decoded, err := NewEventDecoder(nil).DecodeEvent(string(EventTypeMessageDeleted), data)
+		require.NoError(t, err)
+		assert.Equal(t, "leaked a secret", *decoded.(*MessageDeletedEvent).Reason)
+
+		event.MessageID = ""
+		assert.Error(t, event.Validate())
+	})
 }
 
 func TestToolEvents(t *testing.T) {
@@ -0,0 +1,98 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Flow control extends the protocol so that clients which cannot keep up
+// with a run slow it down. A client opens a window with FlowWindowHeader
+// when it requests a run, or re-attaches to one, and grants more credit as
+// it consumes events by posting flow control CUSTOM events to runs/{id}/flow
+// below the agent endpoint. The server holds back the events carrying
+// streamed content (see ConsumesCredit) once the credit is spent, each
+// costing one credit, while lifecycle events and heartbeats go through.
+const (
+	// FlowControlEventName is the name of flow control CUSTOM events
+	FlowControlEventName = "flow_control"
+
+	// FlowWindowHeader is the HTTP header opening a flow control window of
+	// the given number of events for a run
+	FlowWindowHeader = "X-AG-UI-Flow-Window"
+)
+
+// ErrInvalidFlowControl is returned for malformed flow control windows and
+// credit grants
+var ErrInvalidFlowControl = errors.New("invalid flow control")
+
+// FlowControl is the value of a flow control event
+type FlowControl struct {
+	// Credit is the number of events granted to the server on top of its
+	// remaining credit
+	Credit int `json:"credit"`
+}
+
+// Validate validates the credit grant
+func (f FlowControl) Validate() error {
+	if f.Credit <= 0 {
+		return fmt.Errorf("%w: credit must be positive", ErrInvalidFlowControl)
+	}
+	return nil
+}
+
+// NewFlowControlEvent creates a flow control event granting credit
+func NewFlowControlEvent(credit int) *CustomEvent {
+	return NewCustomEvent(FlowControlEventName, WithValue(FlowControl{Credit: credit}))
+}
+
+// FlowControlOf reports whether event is a flow control event and returns
+// its credit grant once validated
+func FlowControlOf(event Event) (*FlowControl, bool, error) {
+	custom, ok := event.(*CustomEvent)
+	if !ok || custom.Name != FlowControlEventName {
+		return nil, false, nil
+	}
+	var flow FlowControl
+	switch value := custom.Value.(type) {
+	case FlowControl:
+		flow = value
+	case *FlowControl:
+		flow = *value
+	default:
+		data, err := json.Marshal(value)
+		if err == nil {
+			err = json.Unmarshal(data, &flow)
+		}
+		if err != nil {
+			return nil, true, fmt.Errorf("%w: %v", ErrInvalidFlowControl, err)
+		}
+	}
+	if err := flow.Validate(); err != nil {
+		return nil, true, err
+	}
+	return &flow, true, nil
+}
+
+// ParseFlowWindow parses the value of FlowWindowHeader
+func ParseFlowWindow(header string) (int, error) {
+	window, err := strconv.Atoi(header)
+	if err != nil || window <= 0 {
+		return 0, fmt.Errorf("%w: window %q must be a positive number of events", ErrInvalidFlowControl, header)
+	}
+	return window, nil
+}
+
+// ConsumesCredit reports whether event carries streamed content, which
+// flow control holds back when the client runs out of credit
+func ConsumesCredit(event Event) bool {
+	switch event.Type() {
+	case EventTypeTextMessageContent, EventTypeTextMessageChunk,
+		EventTypeToolCallArgs, EventTypeToolCallChunk,
+		EventTypeReasoningMessageContent, EventTypeReasoningMessageChunk,
+		EventTypeThinkingTextMessageContent:
+		return true
+	}
+	return false
+}
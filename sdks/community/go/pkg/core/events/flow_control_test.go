@@ -0,0 +1,46 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlowControlOf(t *testing.T) {
+	data, err := NewFlowControlEvent(16).ToJSON()
+	require.NoError(t, err)
+	decoded, err := EventFromJSON(data)
+	require.NoError(t, err)
+
+	flow, ok, err := FlowControlOf(decoded)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 16, flow.Credit)
+
+	_, ok, err = FlowControlOf(NewFlowControlEvent(0))
+	assert.True(t, ok)
+	assert.ErrorIs(t, err, ErrInvalidFlowControl)
+
+	_, ok, _ = FlowControlOf(NewHeartbeatEvent(0))
+	assert.False(t, ok)
+}
+
+func TestParseFlowWindow(t *testing.T) {
+	window, err := ParseFlowWindow("64")
+	require.NoError(t, err)
+	assert.Equal(t, 64, window)
+
+	for _, header := range []string{"", "0", "-1", "many"} {
+		_, err := ParseFlowWindow(header)
+		assert.ErrorIs(t, err, ErrInvalidFlowControl, header)
+	}
+}
+
+func TestConsumesCredit(t *testing.T) {
+	assert.True(t, ConsumesCredit(NewTextMessageContentEvent("msg-1", "Hello")))
+	assert.True(t, ConsumesCredit(NewToolCallArgsEvent("call-1", "{}")))
+	assert.False(t, ConsumesCredit(NewTextMessageStartEvent("msg-1")))
+	assert.False(t, ConsumesCredit(NewRunFinishedEvent("thread-1", "run-1")))
+	assert.False(t, ConsumesCredit(NewHeartbeatEvent(0)))
+}
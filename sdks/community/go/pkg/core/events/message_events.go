@@ -240,3 +240,97 @@ func (e *TextMessageChunkEvent) Validate() error {
 func (e *TextMessageChunkEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
+
+// MessageUpdatedEvent replaces the content of a message sent earlier, e.g.
+// to correct it, instead of resending a messages snapshot. The message must
+// have ended.
+type MessageUpdatedEvent struct {
+	*BaseEvent
+	MessageID string `json:"messageId"`
+
+	// Content is the new content of the message, replacing it as a whole
+	Content string `json:"content"`
+
+	// Reason optionally tells users why the message changed
+	Reason *string `json:"reason,omitempty"`
+}
+
+// NewMessageUpdatedEvent creates a new message updated event
+func NewMessageUpdatedEvent(messageID, content string) *MessageUpdatedEvent {
+	return &MessageUpdatedEvent{
+		BaseEvent: NewBaseEvent(EventTypeMessageUpdated),
+		MessageID: messageID,
+		Content:   content,
+	}
+}
+
+// WithReason sets the reason of the update
+func (e *MessageUpdatedEvent) WithReason(reason string) *MessageUpdatedEvent {
+	e.Reason = &reason
+	return e
+}
+
+// Validate validates the message updated event
+func (e *MessageUpdatedEvent) Validate() error {
+	if err := e.BaseEvent.Validate(); err != nil {
+		return err
+	}
+
+	if e.MessageID == "" {
+		return fmt.Errorf("MessageUpdatedEvent validation failed: messageId field is required")
+	}
+
+	if e.Content == "" {
+		return fmt.Errorf("MessageUpdatedEvent validation failed: content must not be empty, delete the message instead")
+	}
+
+	return nil
+}
+
+// ToJSON serializes the event to JSON
+func (e *MessageUpdatedEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// MessageDeletedEvent retracts a message sent earlier, which clients remove
+// from the conversation or render as retracted. The message must have
+// ended, and cannot be updated afterwards.
+type MessageDeletedEvent struct {
+	*BaseEvent
+	MessageID string `json:"messageId"`
+
+	// Reason optionally tells users why the message was retracted
+	Reason *string `json:"reason,omitempty"`
+}
+
+// NewMessageDeletedEvent creates a new message deleted event
+func NewMessageDeletedEvent(messageID string) *MessageDeletedEvent {
+	return &MessageDeletedEvent{
+		BaseEvent: NewBaseEvent(EventTypeMessageDeleted),
+		MessageID: messageID,
+	}
+}
+
+// WithReason sets the reason of the retraction
+func (e *MessageDeletedEvent) WithReason(reason string) *MessageDeletedEvent {
+	e.Reason = &reason
+	return e
+}
+
+// Validate validates the message deleted event
+func (e *MessageDeletedEvent) Validate() error {
+	if err := e.BaseEvent.Validate(); err != nil {
+		return err
+	}
+
+	if e.MessageID == "" {
+		return fmt.Errorf("MessageDeletedEvent validation failed: messageId field is required")
+	}
+
+	return nil
+}
+
+// ToJSON serializes the event to JSON
+func (e *MessageDeletedEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
@@ -0,0 +1,222 @@
+// Package metrics derives Prometheus metrics from AG-UI event streams:
+// events by type, run durations and outcomes, and tool call latencies. It
+// only depends on the events it observes, so that any consumer of events,
+// a client reading a stream, a server tap or a replay of recorded runs,
+// can export them alongside its own metrics.
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// Outcomes of the runs observed, the values of the outcome label
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+	// OutcomeIncomplete is the outcome of the runs still in progress when
+	// their stream was closed
+	OutcomeIncomplete = "incomplete"
+)
+
+var (
+	// DurationBuckets are the default upper bounds, in seconds, of the
+	// buckets of the run duration histogram
+	DurationBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+	// LatencyBuckets are the default upper bounds, in seconds, of the
+	// buckets of the tool call latency histogram
+	LatencyBuckets = []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+	// SizeBuckets are the default upper bounds, in bytes, of the buckets of
+	// the event size histogram
+	SizeBuckets = prometheus.ExponentialBuckets(64, 4, 8)
+)
+
+// Config configures a Collector
+type Config struct {
+	// Namespace prefixes the metric names (defaults to "agui")
+	Namespace string
+
+	// ConstLabels are added to every series, e.g. the name of the agent
+	ConstLabels prometheus.Labels
+
+	// DurationBuckets, LatencyBuckets and SizeBuckets bound the buckets of
+	// the histograms (default to the package variables of the same name)
+	DurationBuckets []float64
+	LatencyBuckets  []float64
+	SizeBuckets     []float64
+
+	// Clock times runs and tool calls (defaults to the real clock)
+	Clock clock.Clock
+}
+
+// Collector holds the metrics derived from event streams, each observed
+// with its own Stream. It is safe for concurrent use.
+type Collector struct {
+	clock clock.Clock
+
+	events    *prometheus.CounterVec
+	sizes     *prometheus.HistogramVec
+	runs      *prometheus.HistogramVec
+	active    prometheus.Gauge
+	toolCalls *prometheus.HistogramVec
+}
+
+// New creates a collector and registers its metrics with registerer:
+//
+//   - agui_stream_events_total{type}, a counter of events by type
+//   - agui_stream_event_size_bytes{type}, a histogram of the size of the
+//     events observed with Stream.ObserveFrame
+//   - agui_stream_run_duration_seconds{outcome}, a histogram of the
+//     duration of runs, from RUN_STARTED to RUN_FINISHED or RUN_ERROR
+//   - agui_stream_runs_active, a gauge of the runs in progress
+//   - agui_stream_tool_call_duration_seconds{tool}, a histogram of the
+//     latency of tool calls, from TOOL_CALL_START to TOOL_CALL_RESULT
+//
+// It fails when registerer already has metrics of the same names.
+func New(registerer prometheus.Registerer, config Config) (*Collector, error) {
+	if config.Namespace == "" {
+		config.Namespace = "agui"
+	}
+	if len(config.DurationBuckets) == 0 {
+		config.DurationBuckets = DurationBuckets
+	}
+	if len(config.LatencyBuckets) == 0 {
+		config.LatencyBuckets = LatencyBuckets
+	}
+	if len(config.SizeBuckets) == 0 {
+		config.SizeBuckets = SizeBuckets
+	}
+	opts := func(name, help string) prometheus.Opts {
+		return prometheus.Opts{Namespace: config.Namespace, Subsystem: "stream", Name: name, Help: help, ConstLabels: config.ConstLabels}
+	}
+	histogram := func(name, help string, buckets []float64) prometheus.HistogramOpts {
+		o := opts(name, help)
+		return prometheus.HistogramOpts{Namespace: o.Namespace, Subsystem: o.Subsystem, Name: o.Name, Help: o.Help, ConstLabels: o.ConstLabels, Buckets: buckets}
+	}
+
+	c := &Collector{
+		clock:  clock.Or(config.Clock),
+		events: prometheus.NewCounterVec(prometheus.CounterOpts(opts("events_total", "Events observed by type.")), []string{"type"}),
+		sizes: prometheus.NewHistogramVec(histogram("event_size_bytes",
+			"Size of the events observed by type, in bytes.", config.SizeBuckets), []string{"type"}),
+		runs: prometheus.NewHistogramVec(histogram("run_duration_seconds",
+			"Duration of the runs observed by outcome.", config.DurationBuckets), []string{"outcome"}),
+		active: prometheus.NewGauge(prometheus.GaugeOpts(opts("runs_active", "Runs in progress."))),
+		toolCalls: prometheus.NewHistogramVec(histogram("tool_call_duration_seconds",
+			"Latency of the tool calls observed by tool, from their start to their result.", config.LatencyBuckets), []string{"tool"}),
+	}
+	for _, collector := range []prometheus.Collector{c.events, c.sizes, c.runs, c.active, c.toolCalls} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Stream observes the events of a single stream
+func (c *Collector) Stream() *Stream {
+	return &Stream{
+		collector: c,
+		runs:      make(map[string]time.Time),
+		toolCalls: make(map[string]toolCall),
+	}
+}
+
+// toolCall is a tool call waiting for its result
+type toolCall struct {
+	name    string
+	started time.Time
+}
+
+// Stream derives metrics from the events of a stream, including the runs
+// it nests, for its Collector. It is safe for concurrent use, although the
+// events of a stream are usually observed from a single goroutine.
+type Stream struct {
+	collector *Collector
+
+	mu        sync.Mutex
+	runs      map[string]time.Time
+	toolCalls map[string]toolCall
+	closed    bool
+}
+
+// Observe accounts for the next event of the stream
+func (s *Stream) Observe(event events.Event) {
+	s.collector.events.WithLabelValues(string(event.Type())).Inc()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	now := s.collector.clock.Now()
+	switch e := event.(type) {
+	case *events.RunStartedEvent:
+		if _, ok := s.runs[e.RunID()]; !ok {
+			s.collector.active.Inc()
+		}
+		s.runs[e.RunID()] = now
+	case *events.RunFinishedEvent:
+		s.endRun(e.RunID(), OutcomeSuccess, now)
+	case *events.RunErrorEvent:
+		s.endRun(e.RunID(), OutcomeError, now)
+	case *events.ToolCallStartEvent:
+		s.toolCalls[e.ToolCallID] = toolCall{name: e.ToolCallName, started: now}
+	case *events.ToolCallResultEvent:
+		if call, ok := s.toolCalls[e.ToolCallID]; ok {
+			delete(s.toolCalls, e.ToolCallID)
+			s.collector.toolCalls.WithLabelValues(call.name).Observe(now.Sub(call.started).Seconds())
+		}
+	}
+}
+
+// ObserveFrame accounts for the next event of the stream like Observe, and
+// for its size, the bytes it was received or sent as
+func (s *Stream) ObserveFrame(event events.Event, size int) {
+	s.collector.sizes.WithLabelValues(string(event.Type())).Observe(float64(size))
+	s.Observe(event)
+}
+
+// endRun observes the duration of a run; callers must hold mu. RUN_ERROR
+// events without a run ID end the only run in progress, if any.
+func (s *Stream) endRun(runID, outcome string, now time.Time) {
+	if runID == "" && len(s.runs) == 1 {
+		for id := range s.runs {
+			runID = id
+		}
+	}
+	started, ok := s.runs[runID]
+	if !ok {
+		return
+	}
+	delete(s.runs, runID)
+	s.collector.active.Dec()
+	s.collector.runs.WithLabelValues(outcome).Observe(now.Sub(started).Seconds())
+	if len(s.runs) == 0 {
+		// Calls left without results, e.g. executed by the client, are not
+		// answered on this stream
+		clear(s.toolCalls)
+	}
+}
+
+// Close ends the observation of the stream, observing the runs still in
+// progress with OutcomeIncomplete
+func (s *Stream) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	now := s.collector.clock.Now()
+	for runID := range s.runs {
+		s.endRun(runID, OutcomeIncomplete, now)
+	}
+}
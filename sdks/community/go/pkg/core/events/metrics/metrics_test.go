@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/testhelper"
+)
+
+// histogram returns the histogram of a series of the metrics of registry
+func histogram(t *testing.T, registry *prometheus.Registry, name, label, value string) *dto.Histogram {
+	t.Helper()
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, pair := range metric.GetLabel() {
+				if pair.GetName() == label && pair.GetValue() == value {
+					return metric.GetHistogram()
+				}
+			}
+		}
+	}
+	t.Fatalf("no %s series with %s=%q", name, label, value)
+	return nil
+}
+
+func TestCollector(t *testing.T) {
+	clk := testhelper.NewFakeClock(time.Unix(0, 0))
+	registry := prometheus.NewRegistry()
+	collector, err := New(registry, Config{Clock: clk, ConstLabels: prometheus.Labels{"agent": "weather"}})
+	require.NoError(t, err)
+
+	stream := collector.Stream()
+	stream.Observe(events.NewRunStartedEvent("thread-1", "run-1"))
+	assert.Equal(t, 1.0, testutil.ToFloat64(collector.active))
+	stream.Observe(events.NewToolCallStartEvent("call-1", "get_weather"))
+	stream.Observe(events.NewToolCallStartEvent("call-2", "get_time"))
+	stream.ObserveFrame(events.NewToolCallArgsEvent("call-1", `{"city":"Paris"}`), 100)
+	stream.Observe(events.NewToolCallEndEvent("call-1"))
+	clk.Advance(250 * time.Millisecond)
+	stream.Observe(events.NewToolCallResultEvent("msg-1", "call-1", "sunny"))
+	clk.Advance(time.Second)
+	stream.Observe(events.NewRunFinishedEvent("thread-1", "run-1"))
+	stream.Observe(events.NewToolCallResultEvent("msg-2", "call-2", "noon"))
+
+	stream = collector.Stream()
+	stream.Observe(events.NewRunStartedEvent("thread-1", "run-2"))
+	stream.Observe(events.NewRunErrorEvent("boom"))
+	stream.Observe(events.NewRunStartedEvent("thread-1", "run-3"))
+	stream.Close()
+	stream.Close()
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(collector.events.WithLabelValues("TOOL_CALL_START")))
+	assert.Equal(t, 3.0, testutil.ToFloat64(collector.events.WithLabelValues("RUN_STARTED")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(collector.active))
+
+	tool := histogram(t, registry, "agui_stream_tool_call_duration_seconds", "tool", "get_weather")
+	assert.Equal(t, uint64(1), tool.GetSampleCount())
+	assert.Equal(t, 0.25, tool.GetSampleSum())
+	assert.Equal(t, 1, testutil.CollectAndCount(collector.toolCalls), "the result of a call of an ended run is not observed")
+
+	assert.Equal(t, 1.25, histogram(t, registry, "agui_stream_run_duration_seconds", "outcome", OutcomeSuccess).GetSampleSum())
+	assert.Equal(t, uint64(1), histogram(t, registry, "agui_stream_run_duration_seconds", "outcome", OutcomeError).GetSampleCount())
+	assert.Equal(t, uint64(1), histogram(t, registry, "agui_stream_run_duration_seconds", "outcome", OutcomeIncomplete).GetSampleCount())
+	assert.Equal(t, 100.0, histogram(t, registry, "agui_stream_event_size_bytes", "type", "TOOL_CALL_ARGS").GetSampleSum())
+	assert.NotNil(t, histogram(t, registry, "agui_stream_event_size_bytes", "agent", "weather"), "series carry the constant labels")
+}
+
+func TestCollectorRegistration(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	_, err := New(registry, Config{})
+	require.NoError(t, err)
+	_, err = New(registry, Config{})
+	assert.Error(t, err, "metrics are registered once")
+
+	_, err = New(registry, Config{Namespace: "other"})
+	assert.NoError(t, err)
+}
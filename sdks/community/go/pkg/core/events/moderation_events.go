@@ -0,0 +1,86 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Moderation extends the protocol with CUSTOM events annotating the content
+// of a message or the arguments of a tool call once moderated, so that
+// clients can flag content that was redacted, or explain why a run stopped
+// on blocked content. The annotation follows the content it applies to.
+const (
+	// ModerationEventName is the name of moderation CUSTOM events
+	ModerationEventName = "moderation"
+
+	// Actions taken by moderation, the values of Moderation.Action
+	ModerationAllowed  = "allowed"
+	ModerationRedacted = "redacted"
+	ModerationBlocked  = "blocked"
+)
+
+// ErrInvalidModeration is returned for malformed moderation annotations
+var ErrInvalidModeration = errors.New("invalid moderation")
+
+// Moderation is the value of a moderation event
+type Moderation struct {
+	// MessageID or ToolCallID is the message or tool call whose content
+	// was moderated
+	MessageID  string `json:"messageId,omitempty"`
+	ToolCallID string `json:"toolCallId,omitempty"`
+
+	// Action is what moderation did with the content
+	Action string `json:"action"`
+
+	// Labels classify the content, e.g. "pii" or "violence"
+	Labels []string `json:"labels,omitempty"`
+
+	// Reason explains the action, for display
+	Reason string `json:"reason,omitempty"`
+}
+
+// Validate validates the moderation annotation
+func (m Moderation) Validate() error {
+	if (m.MessageID == "") == (m.ToolCallID == "") {
+		return fmt.Errorf("%w: exactly one of messageId and toolCallId is required", ErrInvalidModeration)
+	}
+	switch m.Action {
+	case ModerationAllowed, ModerationRedacted, ModerationBlocked:
+		return nil
+	}
+	return fmt.Errorf("%w: unknown action %q", ErrInvalidModeration, m.Action)
+}
+
+// NewModerationEvent creates a moderation event
+func NewModerationEvent(moderation Moderation) *CustomEvent {
+	return NewCustomEvent(ModerationEventName, WithValue(moderation))
+}
+
+// ModerationOf reports whether event is a moderation event and returns its
+// annotation once validated
+func ModerationOf(event Event) (*Moderation, bool, error) {
+	custom, ok := event.(*CustomEvent)
+	if !ok || custom.Name != ModerationEventName {
+		return nil, false, nil
+	}
+	var moderation Moderation
+	switch value := custom.Value.(type) {
+	case Moderation:
+		moderation = value
+	case *Moderation:
+		moderation = *value
+	default:
+		data, err := json.Marshal(value)
+		if err == nil {
+			err = json.Unmarshal(data, &moderation)
+		}
+		if err != nil {
+			return nil, true, fmt.Errorf("%w: %v", ErrInvalidModeration, err)
+		}
+	}
+	if err := moderation.Validate(); err != nil {
+		return nil, true, err
+	}
+	return &moderation, true, nil
+}
@@ -0,0 +1,165 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// errMalformedEvent is returned by PeekEventType for data that is not a
+// JSON object
+var errMalformedEvent = errors.New("malformed event JSON")
+
+// PeekEventType returns the "type" field of the JSON object in data without
+// unmarshaling it, so that events can be decoded in a single pass into
+// their concrete type. Only the members preceding the field are scanned,
+// and their values are skipped without being decoded; the type comes first
+// in the events encoded by the SDKs. It returns an empty type when data has
+// no "type" field, and fails for data that is not an object or whose type
+// is not a string. The rest of data is not validated.
+func PeekEventType(data []byte) (EventType, error) {
+	i := skipSpace(data, 0)
+	if i >= len(data) || data[i] != '{' {
+		return "", fmt.Errorf("%w: expected an object", errMalformedEvent)
+	}
+	i = skipSpace(data, i+1)
+	if i < len(data) && data[i] == '}' {
+		return "", nil
+	}
+
+	for i < len(data) {
+		key, next, err := scanString(data, i)
+		if err != nil {
+			return "", err
+		}
+		i = skipSpace(data, next)
+		if i >= len(data) || data[i] != ':' {
+			return "", fmt.Errorf("%w: expected a colon at offset %d", errMalformedEvent, i)
+		}
+		i = skipSpace(data, i+1)
+
+		if isKey(key, "type") {
+			value, _, err := scanString(data, i)
+			if err != nil {
+				return "", fmt.Errorf("event type is not a string: %w", err)
+			}
+			eventType, err := unquote(value)
+			return EventType(eventType), err
+		}
+
+		if i, err = skipValue(data, i); err != nil {
+			return "", err
+		}
+		i = skipSpace(data, i)
+		if i >= len(data) {
+			break
+		}
+		switch data[i] {
+		case ',':
+			i = skipSpace(data, i+1)
+		case '}':
+			return "", nil
+		default:
+			return "", fmt.Errorf("%w: unexpected %q at offset %d", errMalformedEvent, data[i], i)
+		}
+	}
+	return "", fmt.Errorf("%w: unexpected end of data", errMalformedEvent)
+}
+
+// skipSpace returns the offset of the first non-space byte of data from i
+func skipSpace(data []byte, i int) int {
+	for i < len(data) {
+		switch data[i] {
+		case ' ', '\t', '\n', '\r':
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// scanString returns the JSON string starting at offset i of data, quotes
+// included, and the offset following it
+func scanString(data []byte, i int) ([]byte, int, error) {
+	if i >= len(data) || data[i] != '"' {
+		return nil, i, fmt.Errorf("%w: expected a string at offset %d", errMalformedEvent, i)
+	}
+	for j := i + 1; j < len(data); j++ {
+		switch data[j] {
+		case '\\':
+			j++
+		case '"':
+			return data[i : j+1], j + 1, nil
+		}
+	}
+	return nil, i, fmt.Errorf("%w: unterminated string at offset %d", errMalformedEvent, i)
+}
+
+// isKey reports whether the quoted JSON string key is name
+func isKey(key []byte, name string) bool {
+	if bytes.IndexByte(key, '\\') < 0 {
+		return string(key[1:len(key)-1]) == name
+	}
+	unquoted, err := unquote(key)
+	return err == nil && unquoted == name
+}
+
+// unquote decodes the quoted JSON string s
+func unquote(s []byte) (string, error) {
+	if bytes.IndexByte(s, '\\') < 0 {
+		return string(s[1 : len(s)-1]), nil
+	}
+	var unquoted string
+	if err := json.Unmarshal(s, &unquoted); err != nil {
+		return "", fmt.Errorf("%w: %v", errMalformedEvent, err)
+	}
+	return unquoted, nil
+}
+
+// skipValue returns the offset following the JSON value starting at offset
+// i of data. Nested objects and arrays are matched by their brackets only.
+func skipValue(data []byte, i int) (int, error) {
+	if i >= len(data) {
+		return i, fmt.Errorf("%w: unexpected end of data", errMalformedEvent)
+	}
+	switch data[i] {
+	case '"':
+		_, next, err := scanString(data, i)
+		return next, err
+	case '{', '[':
+		depth := 0
+		for j := i; j < len(data); j++ {
+			switch data[j] {
+			case '"':
+				_, next, err := scanString(data, j)
+				if err != nil {
+					return j, err
+				}
+				j = next - 1
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return j + 1, nil
+				}
+			}
+		}
+		return i, fmt.Errorf("%w: unterminated value at offset %d", errMalformedEvent, i)
+	}
+	// Numbers, booleans and null run until the next delimiter
+	j := i
+	for j < len(data) {
+		switch data[j] {
+		case ',', '}', ']', ' ', '\t', '\n', '\r':
+			if j == i {
+				return i, fmt.Errorf("%w: expected a value at offset %d", errMalformedEvent, i)
+			}
+			return j, nil
+		}
+		j++
+	}
+	return j, nil
+}
@@ -0,0 +1,105 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeekEventType(t *testing.T) {
+	for data, expected := range map[string]EventType{
+		`{"type":"RUN_STARTED","threadId":"t","runId":"r"}`:                                EventTypeRunStarted,
+		` { "type" : "RUN_STARTED" } `:                                                     EventTypeRunStarted,
+		`{"threadId":"t","nested":{"type":"x","list":[1,{"a":"}"}]},"type":"RAW"}`:         EventTypeRaw,
+		`{"delta":"a \"quoted\" }{ string","n":-1.5e3,"ok":true,"v":null,"type":"CUSTOM"}`: EventTypeCustom,
+		`{"type":"RUN_ERROR"}`:            EventTypeRunError,
+		`{"type":"TEXT_MESSAGE_CONTENT"}`: EventTypeTextMessageContent,
+		`{"threadId":"t"}`:                "",
+		`{}`:                              "",
+		`{"type":"RUN_STARTED", this is not validated`: EventTypeRunStarted,
+	} {
+		eventType, err := PeekEventType([]byte(data))
+		require.NoError(t, err, data)
+		assert.Equal(t, expected, eventType, data)
+	}
+
+	for _, data := range []string{
+		``,
+		`[]`,
+		`"RUN_STARTED"`,
+		`{"type":1}`,
+		`{"type" "RUN_STARTED"}`,
+		`{"a":"unterminated`,
+		`{"a":{"b":1}`,
+		`{"a":1 "type":"RAW"}`,
+		`{"a":,"type":"RAW"}`,
+	} {
+		_, err := PeekEventType([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestDecoderDecode(t *testing.T) {
+	decoder := NewEventDecoder(nil)
+	data, err := NewTextMessageContentEvent("msg-1", "Hello").ToJSON()
+	require.NoError(t, err)
+
+	event, err := decoder.Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", event.(*TextMessageContentEvent).Delta)
+
+	_, err = decoder.Decode([]byte(`{"type":"NOT_A_TYPE"}`))
+	assert.Error(t, err)
+	_, err = decoder.Decode([]byte(`not json`))
+	assert.Error(t, err)
+	_, err = EventFromJSON([]byte(`{"type":"RUN_STARTED","threadId":`))
+	assert.Error(t, err, "malformed data after the type still fails")
+}
+
+// largeSnapshot returns the encoding of a state snapshot of about size
+// bytes, with its type last as other producers may send it
+func largeSnapshot(b *testing.B, size int) []byte {
+	var records []map[string]any
+	for i := 0; i*200 < size; i++ {
+		records = append(records, map[string]any{"id": fmt.Sprintf("record-%d", i), "score": i, "tags": []string{"a", "b"}, "text": strings.Repeat("token ", 20)})
+	}
+	encoded, err := json.Marshal(map[string]any{"snapshot": map[string]any{"records": records}, "type": EventTypeStateSnapshot})
+	require.NoError(b, err)
+	return encoded
+}
+
+// BenchmarkEventFromJSON decodes a 1MB snapshot in a single pass
+func BenchmarkEventFromJSON(b *testing.B) {
+	data := largeSnapshot(b, 1<<20)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := EventFromJSON(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkEventFromJSONGenericType decodes the same snapshot by reading
+// its type from a generic map first
+func BenchmarkEventFromJSONGenericType(b *testing.B) {
+	data := largeSnapshot(b, 1<<20)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var generic map[string]any
+		if err := json.Unmarshal(data, &generic); err != nil {
+			b.Fatal(err)
+		}
+		var event StateSnapshotEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
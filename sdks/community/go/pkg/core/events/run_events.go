@@ -236,6 +236,15 @@ type RunErrorEvent struct {
 	RunIDValue string  `json:"runId,omitempty"`
 }
 
+// RunErrorCodeCancelled is the standard code of the RUN_ERROR events ending
+// cancelled runs, whether the client or the server cancelled them
+const RunErrorCodeCancelled = "CANCELLED"
+
+// Cancelled reports whether the run ended because it was cancelled
+func (e *RunErrorEvent) Cancelled() bool {
+	return e.Code != nil && *e.Code == RunErrorCodeCancelled
+}
+
 // NewRunErrorEvent creates a new run error event
 func NewRunErrorEvent(message string, options ...RunErrorOption) *RunErrorEvent {
 	event := &RunErrorEvent{
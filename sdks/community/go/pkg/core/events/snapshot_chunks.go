@@ -0,0 +1,185 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/bufpool"
+)
+
+// Messages snapshots larger than the event size limit of a transport are
+// sent as a series of CUSTOM events, each carrying a page of the messages
+// and a sequence number, followed by a terminator with no messages. A
+// consumer rebuilds the snapshot with a SnapshotAssembler.
+const (
+	// MessagesSnapshotChunkEventName is the name of snapshot chunk CUSTOM
+	// events
+	MessagesSnapshotChunkEventName = "messages_snapshot_chunk"
+)
+
+// ErrInvalidSnapshotChunk is returned for snapshot chunks that are
+// malformed or out of sequence
+var ErrInvalidSnapshotChunk = errors.New("invalid messages snapshot chunk")
+
+// MessagesSnapshotChunk is the value of a snapshot chunk event
+type MessagesSnapshotChunk struct {
+	// SnapshotID identifies the snapshot the chunk belongs to
+	SnapshotID string `json:"snapshotId"`
+
+	// Sequence numbers the chunks of a snapshot from 0
+	Sequence int `json:"sequence"`
+
+	// Messages is the page of messages carried by the chunk
+	Messages []Message `json:"messages,omitempty"`
+
+	// Final marks the terminator, sent after the last page
+	Final bool `json:"final,omitempty"`
+}
+
+// NewMessagesSnapshotChunkEvent creates a snapshot chunk event
+func NewMessagesSnapshotChunkEvent(chunk MessagesSnapshotChunk) *CustomEvent {
+	return NewCustomEvent(MessagesSnapshotChunkEventName, WithValue(chunk))
+}
+
+// SplitMessagesSnapshot returns the events sending snapshot within maxSize
+// bytes each: the snapshot itself when it fits or maxSize is 0, and
+// otherwise its chunks and terminator. It fails with ErrEventTooLarge when a
+// single message does not fit in a chunk.
+func SplitMessagesSnapshot(snapshot *MessagesSnapshotEvent, maxSize int) ([]Event, error) {
+	if maxSize <= 0 {
+		return []Event{snapshot}, nil
+	}
+	data, err := snapshot.ToJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode messages snapshot: %w", err)
+	}
+	if len(data) <= maxSize {
+		return []Event{snapshot}, nil
+	}
+
+	snapshotID := fmt.Sprintf("snapshot-%s", uuid.New().String())
+	// The largest chunk without messages bounds the size of the others
+	// once their messages are added
+	empty, err := NewMessagesSnapshotChunkEvent(MessagesSnapshotChunk{SnapshotID: snapshotID, Sequence: len(snapshot.Messages)}).ToJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode messages snapshot chunk: %w", err)
+	}
+	overhead := len(empty) + len(`,"messages":[]`)
+
+	// Messages are encoded into a pooled buffer only to be measured
+	buf := bufpool.Default().GetBuffer(0)
+	defer bufpool.Default().PutBuffer(buf)
+	encoder := json.NewEncoder(buf)
+
+	var parts []Event
+	var page []Message
+	size := overhead
+	flush := func() {
+		parts = append(parts, NewMessagesSnapshotChunkEvent(MessagesSnapshotChunk{SnapshotID: snapshotID, Sequence: len(parts), Messages: page}))
+		page, size = nil, overhead
+	}
+	for i, msg := range snapshot.Messages {
+		buf.Reset()
+		if err := encoder.Encode(msg); err != nil {
+			return nil, fmt.Errorf("failed to encode message at index %d: %w", i, err)
+		}
+		// Encode terminates the message with a newline
+		length := buf.Len() - 1
+		if overhead+length > maxSize {
+			return nil, fmt.Errorf("%w: message at index %d takes %d bytes in a chunk of at most %d", ErrEventTooLarge, i, overhead+length, maxSize)
+		}
+		if size+length+1 > maxSize {
+			flush()
+		}
+		page = append(page, msg)
+		size += length + 1
+	}
+	if len(page) > 0 {
+		flush()
+	}
+	return append(parts, NewMessagesSnapshotChunkEvent(MessagesSnapshotChunk{SnapshotID: snapshotID, Sequence: len(parts), Final: true})), nil
+}
+
+// SnapshotAssembler rebuilds the messages snapshots sent in chunks. It is
+// not safe for concurrent use; use one per stream.
+type SnapshotAssembler struct {
+	maxMessages int
+	pending     map[string]*MessagesSnapshotChunk
+}
+
+// NewSnapshotAssembler creates an assembler buffering at most maxMessages
+// messages per snapshot (0 = unlimited)
+func NewSnapshotAssembler(maxMessages int) *SnapshotAssembler {
+	return &SnapshotAssembler{
+		maxMessages: maxMessages,
+		pending:     make(map[string]*MessagesSnapshotChunk),
+	}
+}
+
+// Assemble passes a stream event through the assembler. It returns events
+// other than snapshot chunks unchanged, nil for the chunks of a snapshot in
+// progress, and the rebuilt MESSAGES_SNAPSHOT on its terminator. A chunk out
+// of sequence fails with ErrInvalidSnapshotChunk and drops its snapshot.
+func (a *SnapshotAssembler) Assemble(event Event) (Event, error) {
+	chunk, ok, err := snapshotChunk(event)
+	if !ok {
+		return event, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := a.pending[chunk.SnapshotID]
+	if snapshot == nil {
+		snapshot = &MessagesSnapshotChunk{SnapshotID: chunk.SnapshotID}
+	}
+	if chunk.Sequence != snapshot.Sequence {
+		delete(a.pending, chunk.SnapshotID)
+		return nil, fmt.Errorf("%w: snapshot %s expected chunk %d, got %d", ErrInvalidSnapshotChunk, chunk.SnapshotID, snapshot.Sequence, chunk.Sequence)
+	}
+	snapshot.Messages = append(snapshot.Messages, chunk.Messages...)
+	snapshot.Sequence++
+	if a.maxMessages > 0 && len(snapshot.Messages) > a.maxMessages {
+		delete(a.pending, chunk.SnapshotID)
+		return nil, fmt.Errorf("%w: snapshot %s exceeds %d messages", ErrInvalidSnapshotChunk, chunk.SnapshotID, a.maxMessages)
+	}
+	if !chunk.Final {
+		a.pending[chunk.SnapshotID] = snapshot
+		return nil, nil
+	}
+
+	delete(a.pending, chunk.SnapshotID)
+	rebuilt := NewMessagesSnapshotEvent(snapshot.Messages)
+	rebuilt.TimestampMs = event.Timestamp()
+	return rebuilt, nil
+}
+
+// snapshotChunk reports whether event is a snapshot chunk and decodes it
+func snapshotChunk(event Event) (*MessagesSnapshotChunk, bool, error) {
+	custom, ok := event.(*CustomEvent)
+	if !ok || custom.Name != MessagesSnapshotChunkEventName {
+		return nil, false, nil
+	}
+	var chunk MessagesSnapshotChunk
+	switch value := custom.Value.(type) {
+	case MessagesSnapshotChunk:
+		chunk = value
+	case *MessagesSnapshotChunk:
+		chunk = *value
+	default:
+		data, err := json.Marshal(value)
+		if err == nil {
+			err = json.Unmarshal(data, &chunk)
+		}
+		if err != nil {
+			return nil, true, fmt.Errorf("%w: %v", ErrInvalidSnapshotChunk, err)
+		}
+	}
+	if chunk.SnapshotID == "" {
+		return nil, true, fmt.Errorf("%w: snapshotId field is required", ErrInvalidSnapshotChunk)
+	}
+	return &chunk, true, nil
+}
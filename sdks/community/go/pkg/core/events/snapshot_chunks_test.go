@@ -0,0 +1,100 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func snapshotMessages(n int) []Message {
+	messages := make([]Message, n)
+	for i := range messages {
+		messages[i] = Message{ID: fmt.Sprintf("msg-%d", i), Role: "user", Content: strings.Repeat("x", 100)}
+	}
+	return messages
+}
+
+func TestSplitMessagesSnapshot(t *testing.T) {
+	snapshot := NewMessagesSnapshotEvent(snapshotMessages(50))
+
+	t.Run("fits", func(t *testing.T) {
+		parts, err := SplitMessagesSnapshot(snapshot, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []Event{snapshot}, parts)
+		parts, err = SplitMessagesSnapshot(snapshot, 1<<20)
+		require.NoError(t, err)
+		assert.Equal(t, []Event{snapshot}, parts)
+	})
+
+	t.Run("chunks", func(t *testing.T) {
+		const maxSize = 1024
+		parts, err := SplitMessagesSnapshot(snapshot, maxSize)
+		require.NoError(t, err)
+		require.Greater(t, len(parts), 2)
+
+		validator := NewEventValidator(WithMaxEventSize(maxSize))
+		assembler := NewSnapshotAssembler(0)
+		for i, part := range parts {
+			require.NoError(t, validator.ValidateEvent(part), "part %d", i)
+			// Chunks are rebuilt from the wire as well as in process
+			data, err := part.ToJSON()
+			require.NoError(t, err)
+			decoded, err := EventFromJSON(data)
+			require.NoError(t, err)
+
+			rebuilt, err := assembler.Assemble(decoded)
+			require.NoError(t, err)
+			if i < len(parts)-1 {
+				assert.Nil(t, rebuilt)
+				continue
+			}
+			require.IsType(t, &MessagesSnapshotEvent{}, rebuilt)
+			assert.Len(t, rebuilt.(*MessagesSnapshotEvent).Messages, 50)
+			assert.Equal(t, snapshot.Messages[49].ID, rebuilt.(*MessagesSnapshotEvent).Messages[49].ID)
+		}
+		assert.Empty(t, assembler.pending)
+	})
+
+	t.Run("message too large", func(t *testing.T) {
+		_, err := SplitMessagesSnapshot(snapshot, 200)
+		assert.True(t, errors.Is(err, ErrEventTooLarge), "got %v", err)
+	})
+}
+
+func TestSnapshotAssembler(t *testing.T) {
+	chunk := func(sequence int, final bool, messages ...Message) Event {
+		return NewMessagesSnapshotChunkEvent(MessagesSnapshotChunk{SnapshotID: "snapshot-1", Sequence: sequence, Messages: messages, Final: final})
+	}
+	messages := snapshotMessages(3)
+
+	t.Run("passes other events", func(t *testing.T) {
+		event := NewCustomEvent("progress")
+		rebuilt, err := NewSnapshotAssembler(0).Assemble(event)
+		require.NoError(t, err)
+		assert.Equal(t, event, rebuilt)
+	})
+
+	t.Run("out of sequence", func(t *testing.T) {
+		assembler := NewSnapshotAssembler(0)
+		_, err := assembler.Assemble(chunk(0, false, messages[0]))
+		require.NoError(t, err)
+		_, err = assembler.Assemble(chunk(2, true))
+		assert.True(t, errors.Is(err, ErrInvalidSnapshotChunk), "got %v", err)
+		assert.Empty(t, assembler.pending)
+	})
+
+	t.Run("too many messages", func(t *testing.T) {
+		assembler := NewSnapshotAssembler(2)
+		_, err := assembler.Assemble(chunk(0, false, messages...))
+		assert.True(t, errors.Is(err, ErrInvalidSnapshotChunk), "got %v", err)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := NewSnapshotAssembler(0).Assemble(NewCustomEvent(MessagesSnapshotChunkEventName, WithValue("nope")))
+		assert.True(t, errors.Is(err, ErrInvalidSnapshotChunk), "got %v", err)
+	})
+}
@@ -0,0 +1,223 @@
+package events
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+)
+
+// Step is a step of a run, as tracked by a StepTracker
+type Step struct {
+	Name string
+
+	// Parent is the name of the step the step is nested in, empty for top
+	// level steps, and Depth its nesting level, 0 for top level steps
+	Parent string
+	Depth  int
+
+	StartedAt time.Time
+
+	// FinishedAt is zero while the step is open
+	FinishedAt time.Time
+
+	// Orphaned reports that the step was closed by the tracker, because its
+	// parent finished or the run ended while it was open
+	Orphaned bool
+}
+
+// Duration returns how long the step ran, 0 while it is open
+func (s Step) Duration() time.Duration {
+	if s.FinishedAt.IsZero() {
+		return 0
+	}
+	return s.FinishedAt.Sub(s.StartedAt)
+}
+
+// StepTrackerOption configures a StepTracker
+type StepTrackerOption func(*StepTracker)
+
+// WithStepClock times the steps with c rather than the real clock
+func WithStepClock(c clock.Clock) StepTrackerOption {
+	return func(t *StepTracker) {
+		t.clock = c
+	}
+}
+
+// StepTracker keeps the hierarchy of the steps of a run: a step started
+// while another is open is nested in it. Producers start and finish steps
+// with Start and Finish, which return the events to emit; finishing a step
+// closes the steps still open in it, and Close the steps left open when the
+// run ends. Consumers track the steps of a stream with Observe. It is safe
+// for concurrent use.
+type StepTracker struct {
+	clock clock.Clock
+
+	mu    sync.Mutex
+	steps []*Step
+	open  []*Step
+}
+
+// NewStepTracker creates a step tracker without steps
+func NewStepTracker(options ...StepTrackerOption) *StepTracker {
+	t := &StepTracker{}
+	for _, opt := range options {
+		opt(t)
+	}
+	t.clock = clock.Or(t.clock)
+	return t
+}
+
+// Start opens the step name, nested in the innermost open step, and
+// returns its STEP_STARTED event. Step names must be unique among the open
+// steps.
+func (t *StepTracker) Start(name string) (*StepStartedEvent, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.start(name); err != nil {
+		return nil, err
+	}
+	return NewStepStartedEvent(name), nil
+}
+
+// Finish closes the step name and returns the STEP_FINISHED events to emit:
+// those of the steps still open in it, innermost first, then its own
+func (t *StepTracker) Finish(name string) ([]Event, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	closed, err := t.finish(name)
+	if err != nil {
+		return nil, err
+	}
+	return finishedEvents(closed), nil
+}
+
+// Close closes every open step, innermost first, and returns their
+// STEP_FINISHED events, e.g. once the run ended
+func (t *StepTracker) Close() []Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.open) == 0 {
+		return nil
+	}
+	closed := t.close(0)
+	return finishedEvents(closed)
+}
+
+// Observe tracks the steps of a stream from its STEP_STARTED and
+// STEP_FINISHED events, ignoring the others. A STEP_FINISHED event closes
+// the steps still open in its step as orphans.
+func (t *StepTracker) Observe(event Event) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch e := event.(type) {
+	case *StepStartedEvent:
+		return t.start(e.StepName)
+	case *StepFinishedEvent:
+		_, err := t.finish(e.StepName)
+		return err
+	}
+	return nil
+}
+
+// Nested returns the names of the open steps nested in the step name,
+// innermost first, which finishing it closes
+func (t *StepTracker) Nested(name string) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	i := t.index(name)
+	if i < 0 {
+		return nil
+	}
+	var names []string
+	for j := len(t.open) - 1; j > i; j-- {
+		names = append(names, t.open[j].Name)
+	}
+	return names
+}
+
+// Current returns the innermost open step
+func (t *StepTracker) Current() (Step, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.open) == 0 {
+		return Step{}, false
+	}
+	return *t.open[len(t.open)-1], true
+}
+
+// Steps returns the steps tracked, open or not, in the order they started
+func (t *StepTracker) Steps() []Step {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	steps := make([]Step, len(t.steps))
+	for i, step := range t.steps {
+		steps[i] = *step
+	}
+	return steps
+}
+
+// start opens a step; callers must hold mu
+func (t *StepTracker) start(name string) error {
+	if name == "" {
+		return fmt.Errorf("step name cannot be empty")
+	}
+	if t.index(name) >= 0 {
+		return fmt.Errorf("step %s already started", name)
+	}
+	step := &Step{Name: name, Depth: len(t.open), StartedAt: t.clock.Now()}
+	if len(t.open) > 0 {
+		step.Parent = t.open[len(t.open)-1].Name
+	}
+	t.steps = append(t.steps, step)
+	t.open = append(t.open, step)
+	return nil
+}
+
+// finish closes a step and the steps nested in it, returning them innermost
+// first; callers must hold mu
+func (t *StepTracker) finish(name string) ([]*Step, error) {
+	i := t.index(name)
+	if i < 0 {
+		return nil, fmt.Errorf("cannot finish step %s that was not started", name)
+	}
+	closed := t.close(i)
+	// The step itself was finished, not orphaned
+	closed[len(closed)-1].Orphaned = false
+	return closed, nil
+}
+
+// close closes the open steps from the ith, innermost first, as orphans;
+// callers must hold mu
+func (t *StepTracker) close(i int) []*Step {
+	now := t.clock.Now()
+	closed := make([]*Step, 0, len(t.open)-i)
+	for j := len(t.open) - 1; j >= i; j-- {
+		t.open[j].FinishedAt = now
+		t.open[j].Orphaned = true
+		closed = append(closed, t.open[j])
+	}
+	clear(t.open[i:])
+	t.open = t.open[:i]
+	return closed
+}
+
+// index returns the position of the open step name, -1 when it is not
+// open; callers must hold mu
+func (t *StepTracker) index(name string) int {
+	for i, step := range t.open {
+		if step.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func finishedEvents(steps []*Step) []Event {
+	evts := make([]Event, len(steps))
+	for i, step := range steps {
+		evts[i] = NewStepFinishedEvent(step.Name)
+	}
+	return evts
+}
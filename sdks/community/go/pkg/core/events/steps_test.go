@@ -0,0 +1,112 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+)
+
+// tickingClock advances by a second each time it is read
+type tickingClock struct {
+	clock.Clock
+	now time.Time
+}
+
+func (c *tickingClock) Now() time.Time {
+	c.now = c.now.Add(time.Second)
+	return c.now
+}
+
+func stepNames(evts []Event) []string {
+	names := make([]string, len(evts))
+	for i, event := range evts {
+		names[i] = event.(*StepFinishedEvent).StepName
+	}
+	return names
+}
+
+func TestStepTracker(t *testing.T) {
+	tracker := NewStepTracker(WithStepClock(&tickingClock{now: time.Unix(0, 0)}))
+
+	started, err := tracker.Start("plan")
+	require.NoError(t, err)
+	assert.Equal(t, "plan", started.StepName)
+	_, err = tracker.Start("search")
+	require.NoError(t, err)
+	_, err = tracker.Start("rank")
+	require.NoError(t, err)
+	_, err = tracker.Start("search")
+	assert.Error(t, err, "open step names are unique")
+
+	current, ok := tracker.Current()
+	require.True(t, ok)
+	assert.Equal(t, Step{Name: "rank", Parent: "search", Depth: 2, StartedAt: time.Unix(3, 0)}, current)
+	assert.Equal(t, []string{"rank", "search"}, tracker.Nested("plan"))
+
+	finished, err := tracker.Finish("search")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rank", "search"}, stepNames(finished), "nested steps finish first")
+	_, err = tracker.Finish("rank")
+	assert.Error(t, err)
+
+	_, err = tracker.Start("answer")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"answer", "plan"}, stepNames(tracker.Close()))
+	assert.Empty(t, tracker.Close())
+
+	steps := tracker.Steps()
+	require.Len(t, steps, 4)
+	assert.Equal(t, []Step{
+		{Name: "plan", StartedAt: time.Unix(1, 0), FinishedAt: time.Unix(6, 0), Orphaned: true},
+		{Name: "search", Parent: "plan", Depth: 1, StartedAt: time.Unix(2, 0), FinishedAt: time.Unix(4, 0)},
+		{Name: "rank", Parent: "search", Depth: 2, StartedAt: time.Unix(3, 0), FinishedAt: time.Unix(4, 0), Orphaned: true},
+		{Name: "answer", Parent: "plan", Depth: 1, StartedAt: time.Unix(5, 0), FinishedAt: time.Unix(6, 0), Orphaned: true},
+	}, steps)
+	assert.Equal(t, 2*time.Second, steps[1].Duration())
+	assert.Zero(t, Step{StartedAt: time.Unix(1, 0)}.Duration(), "open steps have no duration")
+}
+
+func TestStepTrackerObserve(t *testing.T) {
+	tracker := NewStepTracker()
+	for _, event := range []Event{
+		NewRunStartedEvent("thread-1", "run-1"),
+		NewStepStartedEvent("outer"),
+		NewStepStartedEvent("inner"),
+		NewStepFinishedEvent("outer"),
+	} {
+		require.NoError(t, tracker.Observe(event))
+	}
+	assert.Error(t, tracker.Observe(NewStepFinishedEvent("inner")), "inner was closed with outer")
+
+	_, ok := tracker.Current()
+	assert.False(t, ok)
+	steps := tracker.Steps()
+	require.Len(t, steps, 2)
+	assert.False(t, steps[0].Orphaned)
+	assert.True(t, steps[1].Orphaned)
+}
+
+func TestEventValidatorNestedSteps(t *testing.T) {
+	flat := NewEventValidator()
+	nested := NewEventValidator(WithNestedSteps())
+	for _, event := range []Event{NewStepStartedEvent("outer"), NewStepStartedEvent("inner")} {
+		require.NoError(t, flat.ValidateEvent(event))
+		require.NoError(t, nested.ValidateEvent(event))
+	}
+
+	assert.NoError(t, flat.ValidateEvent(NewStepFinishedEvent("outer")), "flat steps finish in any order")
+	err := nested.ValidateEvent(NewStepFinishedEvent("outer"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nested step inner is open")
+
+	require.NoError(t, nested.ValidateEvent(NewStepFinishedEvent("inner")))
+	require.NoError(t, nested.ValidateEvent(NewStepFinishedEvent("outer")))
+
+	require.NoError(t, nested.ValidateEvent(NewStepStartedEvent("again")))
+	nested.Reset()
+	assert.Error(t, nested.ValidateEvent(NewStepFinishedEvent("again")))
+}
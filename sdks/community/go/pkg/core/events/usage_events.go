@@ -0,0 +1,102 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Token usage extends the protocol with CUSTOM events reporting the model
+// tokens consumed by a run, for billing and cost tracking. Agents report the
+// usage of each message when it ends and, when the run completes, the usage
+// not reported yet, e.g. that of tool calls; the reports of a run add up.
+const (
+	// UsageEventName is the name of usage CUSTOM events
+	UsageEventName = "usage"
+
+	// DefaultCurrency is the currency of costs reported without one
+	DefaultCurrency = "USD"
+)
+
+// ErrInvalidUsage is returned for malformed usage reports
+var ErrInvalidUsage = errors.New("invalid usage")
+
+// Usage is the value of a usage event
+type Usage struct {
+	// Model is the model that consumed the tokens
+	Model string `json:"model,omitempty"`
+
+	// InputTokens are the prompt tokens and OutputTokens the completion
+	// tokens. ReasoningTokens are reported apart from OutputTokens by
+	// models that think before answering.
+	InputTokens     int `json:"inputTokens"`
+	OutputTokens    int `json:"outputTokens"`
+	ReasoningTokens int `json:"reasoningTokens,omitempty"`
+
+	// Cost is the cost of the tokens as estimated by the agent, in Currency
+	// (defaults to DefaultCurrency). It is a hint for dashboards, not an
+	// invoice.
+	Cost     float64 `json:"cost,omitempty"`
+	Currency string  `json:"currency,omitempty"`
+
+	// MessageID is the message the tokens were consumed for, empty for
+	// usage reported for the run as a whole
+	MessageID string `json:"messageId,omitempty"`
+}
+
+// TotalTokens returns the number of tokens of all kinds
+func (u Usage) TotalTokens() int {
+	return u.InputTokens + u.OutputTokens + u.ReasoningTokens
+}
+
+// CostCurrency returns the currency of the cost
+func (u Usage) CostCurrency() string {
+	if u.Currency == "" {
+		return DefaultCurrency
+	}
+	return u.Currency
+}
+
+// Validate validates the usage report
+func (u Usage) Validate() error {
+	if u.InputTokens < 0 || u.OutputTokens < 0 || u.ReasoningTokens < 0 {
+		return fmt.Errorf("%w: token counts cannot be negative", ErrInvalidUsage)
+	}
+	if u.Cost < 0 {
+		return fmt.Errorf("%w: cost cannot be negative", ErrInvalidUsage)
+	}
+	return nil
+}
+
+// NewUsageEvent creates a usage event
+func NewUsageEvent(usage Usage) *CustomEvent {
+	return NewCustomEvent(UsageEventName, WithValue(usage))
+}
+
+// UsageOf reports whether event is a usage event and returns its usage once
+// validated
+func UsageOf(event Event) (*Usage, bool, error) {
+	custom, ok := event.(*CustomEvent)
+	if !ok || custom.Name != UsageEventName {
+		return nil, false, nil
+	}
+	var usage Usage
+	switch value := custom.Value.(type) {
+	case Usage:
+		usage = value
+	case *Usage:
+		usage = *value
+	default:
+		data, err := json.Marshal(value)
+		if err == nil {
+			err = json.Unmarshal(data, &usage)
+		}
+		if err != nil {
+			return nil, true, fmt.Errorf("%w: %v", ErrInvalidUsage, err)
+		}
+	}
+	if err := usage.Validate(); err != nil {
+		return nil, true, err
+	}
+	return &usage, true, nil
+}
@@ -0,0 +1,414 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrEventTooLarge is returned for events whose JSON encoding exceeds the
+// size limit of a validator
+var ErrEventTooLarge = errors.New("event too large")
+
+// EventValidator incrementally validates events against AG-UI protocol rules.
+// Unlike ValidateSequence, it keeps track of active runs, messages, tool calls
+// and steps between calls so events can be checked one at a time as they are
+// produced or received. It is safe for concurrent use.
+type EventValidator struct {
+	mu sync.Mutex
+
+	activeRuns              map[string]bool
+	activeMessages          map[string]bool
+	activeReasoningMessages map[string]bool
+	activeToolCalls         map[string]bool
+	activeSteps             map[string]bool
+	finishedRuns            map[string]bool
+
+	// deletedMessages holds the messages retracted by MESSAGE_DELETED
+	deletedMessages map[string]bool
+
+	// pendingApprovals maps the approval requests awaiting an answer to
+	// their tool call, and answeredApprovals holds those answered
+	pendingApprovals  map[string]string
+	answeredApprovals map[string]bool
+
+	// stepStack holds the open steps, innermost last, when nestedSteps
+	stepStack []string
+
+	maxEventSize int
+	nestedSteps  bool
+}
+
+// ValidatorOption defines options for creating event validators
+type ValidatorOption func(*EventValidator)
+
+// WithMaxEventSize rejects events whose JSON encoding is larger than size
+// bytes (0 = unlimited). Messages snapshots over the limit can be sent in
+// chunks with SplitMessagesSnapshot.
+func WithMaxEventSize(size int) ValidatorOption {
+	return func(v *EventValidator) {
+		v.maxEventSize = size
+	}
+}
+
+// WithNestedSteps requires steps to be properly nested: a STEP_FINISHED
+// event must finish the innermost open step. Without it steps may finish in
+// any order.
+func WithNestedSteps() ValidatorOption {
+	return func(v *EventValidator) {
+		v.nestedSteps = true
+	}
+}
+
+// NewEventValidator creates a new event validator with empty sequence state
+func NewEventValidator(options ...ValidatorOption) *EventValidator {
+	v := &EventValidator{}
+	for _, opt := range options {
+		opt(v)
+	}
+	v.reset()
+	return v
+}
+
+// MaxEventSize returns the size limit of the validator (0 = unlimited)
+func (v *EventValidator) MaxEventSize() int {
+	return v.maxEventSize
+}
+
+// ValidateEvent validates a single event and, if it is valid, records its
+// effect on the sequence state. Invalid events leave the state untouched.
+func (v *EventValidator) ValidateEvent(event Event) error {
+	if err := v.checkEvent(event); err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.applySequenceRules(event)
+}
+
+// checkEvent checks an event on its own, regardless of the sequence state
+func (v *EventValidator) checkEvent(event Event) error {
+	if event == nil {
+		return fmt.Errorf("event cannot be nil")
+	}
+
+	if err := event.Validate(); err != nil {
+		return fmt.Errorf("%s validation failed: %w", event.Type(), err)
+	}
+	if v.maxEventSize > 0 {
+		data, err := event.ToJSON()
+		if err != nil {
+			return fmt.Errorf("%s encoding failed: %w", event.Type(), err)
+		}
+		if len(data) > v.maxEventSize {
+			return fmt.Errorf("%s %w: %d bytes exceeds the limit of %d", event.Type(), ErrEventTooLarge, len(data), v.maxEventSize)
+		}
+	}
+	return nil
+}
+
+// Reset clears all sequence state
+func (v *EventValidator) Reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.reset()
+}
+
+// Compact forgets the runs finished, the messages deleted and the approval
+// requests answered, kept to reject their reuse, e.g. to bound the memory
+// of a long-lived validator under memory pressure. It returns the number of
+// entries forgotten; the runs and approvals in progress are left as they
+// are.
+func (v *EventValidator) Compact() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	forgotten := len(v.finishedRuns) + len(v.deletedMessages) + len(v.answeredApprovals)
+	v.finishedRuns = make(map[string]bool)
+	v.deletedMessages = make(map[string]bool)
+	v.answeredApprovals = make(map[string]bool)
+	return forgotten
+}
+
+// reset reinitializes the sequence state; callers must hold mu or own v exclusively
+func (v *EventValidator) reset() {
+	v.activeRuns = make(map[string]bool)
+	v.activeMessages = make(map[string]bool)
+	v.activeReasoningMessages = make(map[string]bool)
+	v.activeToolCalls = make(map[string]bool)
+	v.activeSteps = make(map[string]bool)
+	v.finishedRuns = make(map[string]bool)
+	v.deletedMessages = make(map[string]bool)
+	v.pendingApprovals = make(map[string]string)
+	v.answeredApprovals = make(map[string]bool)
+	v.stepStack = nil
+}
+
+// applySequenceRules checks the sequence-specific rules for an event that has
+// already passed its own validation and updates the tracked state
+func (v *EventValidator) applySequenceRules(event Event) error {
+	switch event.Type() {
+	case EventTypeRunStarted:
+		if runEvent, ok := event.(*RunStartedEvent); ok {
+			if v.activeRuns[runEvent.RunID()] {
+				return fmt.Errorf("run %s already started", runEvent.RunID())
+			}
+			if v.finishedRuns[runEvent.RunID()] {
+				return fmt.Errorf("cannot restart finished run %s", runEvent.RunID())
+			}
+			v.activeRuns[runEvent.RunID()] = true
+		}
+
+	case EventTypeRunFinished:
+		if runEvent, ok := event.(*RunFinishedEvent); ok {
+			if !v.activeRuns[runEvent.RunID()] {
+				return fmt.Errorf("cannot finish run %s that was not started", runEvent.RunID())
+			}
+			delete(v.activeRuns, runEvent.RunID())
+			v.finishedRuns[runEvent.RunID()] = true
+		}
+
+	case EventTypeRunError:
+		if runEvent, ok := event.(*RunErrorEvent); ok {
+			if runEvent.RunID() != "" && !v.activeRuns[runEvent.RunID()] {
+				return fmt.Errorf("cannot error run %s that was not started", runEvent.RunID())
+			}
+			if runEvent.RunID() != "" {
+				delete(v.activeRuns, runEvent.RunID())
+				v.finishedRuns[runEvent.RunID()] = true
+			}
+		}
+
+	case EventTypeStepStarted:
+		if stepEvent, ok := event.(*StepStartedEvent); ok {
+			if v.activeSteps[stepEvent.StepName] {
+				return fmt.Errorf("step %s already started", stepEvent.StepName)
+			}
+			v.activeSteps[stepEvent.StepName] = true
+			if v.nestedSteps {
+				v.stepStack = append(v.stepStack, stepEvent.StepName)
+			}
+		}
+
+	case EventTypeStepFinished:
+		if stepEvent, ok := event.(*StepFinishedEvent); ok {
+			if !v.activeSteps[stepEvent.StepName] {
+				return fmt.Errorf("cannot finish step %s that was not started", stepEvent.StepName)
+			}
+			if v.nestedSteps {
+				innermost := v.stepStack[len(v.stepStack)-1]
+				if innermost != stepEvent.StepName {
+					return fmt.Errorf("cannot finish step %s while its nested step %s is open", stepEvent.StepName, innermost)
+				}
+				v.stepStack = v.stepStack[:len(v.stepStack)-1]
+			}
+			delete(v.activeSteps, stepEvent.StepName)
+		}
+
+	case EventTypeTextMessageStart:
+		if msgEvent, ok := event.(*TextMessageStartEvent); ok {
+			if v.activeMessages[msgEvent.MessageID] {
+				return fmt.Errorf("message %s already started", msgEvent.MessageID)
+			}
+			if v.deletedMessages[msgEvent.MessageID] {
+				return fmt.Errorf("cannot restart deleted message %s", msgEvent.MessageID)
+			}
+			v.activeMessages[msgEvent.MessageID] = true
+		}
+
+	case EventTypeTextMessageContent:
+		if msgEvent, ok := event.(*TextMessageContentEvent); ok {
+			if !v.activeMessages[msgEvent.MessageID] {
+				return fmt.Errorf("cannot add content to message %s that was not started", msgEvent.MessageID)
+			}
+			// Content events are valid between start and end
+		}
+
+	case EventTypeTextMessageEnd:
+		if msgEvent, ok := event.(*TextMessageEndEvent); ok {
+			if !v.activeMessages[msgEvent.MessageID] {
+				return fmt.Errorf("cannot end message %s that was not started", msgEvent.MessageID)
+			}
+			delete(v.activeMessages, msgEvent.MessageID)
+		}
+
+	case EventTypeTextMessageChunk:
+		// Chunk events are always valid in sequence context.
+
+	case EventTypeToolCallStart:
+		if toolEvent, ok := event.(*ToolCallStartEvent); ok {
+			if v.activeToolCalls[toolEvent.ToolCallID] {
+				return fmt.Errorf("tool call %s already started", toolEvent.ToolCallID)
+			}
+			v.activeToolCalls[toolEvent.ToolCallID] = true
+		}
+
+	case EventTypeToolCallArgs:
+		if toolEvent, ok := event.(*ToolCallArgsEvent); ok {
+			if !v.activeToolCalls[toolEvent.ToolCallID] {
+				return fmt.Errorf("cannot add args to tool call %s that was not started", toolEvent.ToolCallID)
+			}
+			// Args events are valid between start and end
+		}
+
+	case EventTypeToolCallEnd:
+		if toolEvent, ok := event.(*ToolCallEndEvent); ok {
+			if !v.activeToolCalls[toolEvent.ToolCallID] {
+				return fmt.Errorf("cannot end tool call %s that was not started", toolEvent.ToolCallID)
+			}
+			delete(v.activeToolCalls, toolEvent.ToolCallID)
+		}
+
+	case EventTypeToolCallChunk:
+		// Chunk events are always valid in sequence context.
+
+	case EventTypeToolCallResult:
+		if toolEvent, ok := event.(*ToolCallResultEvent); ok {
+			for id, toolCallID := range v.pendingApprovals {
+				if toolCallID == toolEvent.ToolCallID {
+					return fmt.Errorf("tool call %s is awaiting approval %s", toolEvent.ToolCallID, id)
+				}
+			}
+		}
+
+	case EventTypeThinkingStart, EventTypeThinkingEnd, EventTypeThinkingTextMessageStart, EventTypeThinkingTextMessageContent, EventTypeThinkingTextMessageEnd:
+		// Thinking events are always valid in sequence context.
+
+	case EventTypeReasoningStart:
+		// Reasoning events are always valid in sequence context.
+
+	case EventTypeReasoningMessageStart:
+		if msgEvent, ok := event.(*ReasoningMessageStartEvent); ok {
+			if v.activeReasoningMessages[msgEvent.MessageID] {
+				return fmt.Errorf("reasoning message %s already started", msgEvent.MessageID)
+			}
+			v.activeReasoningMessages[msgEvent.MessageID] = true
+		}
+
+	case EventTypeReasoningMessageContent:
+		if msgEvent, ok := event.(*ReasoningMessageContentEvent); ok {
+			if !v.activeReasoningMessages[msgEvent.MessageID] {
+				return fmt.Errorf("cannot add content to reasoning message %s that was not started", msgEvent.MessageID)
+			}
+		}
+
+	case EventTypeReasoningMessageEnd:
+		if msgEvent, ok := event.(*ReasoningMessageEndEvent); ok {
+			if !v.activeReasoningMessages[msgEvent.MessageID] {
+				return fmt.Errorf("cannot end reasoning message %s that was not started", msgEvent.MessageID)
+			}
+			delete(v.activeReasoningMessages, msgEvent.MessageID)
+		}
+
+	case EventTypeReasoningMessageChunk:
+		// Chunk events are always valid in sequence context.
+
+	case EventTypeReasoningEncryptedValue:
+		// Encrypted value events are always valid in sequence context.
+
+	case EventTypeReasoningEnd:
+		// Reasoning events are always valid in sequence context.
+
+	case EventTypeStateSnapshot:
+		// State snapshot events are always valid in sequence context
+		// They represent complete state at any point in time
+		// Additional validation could be added if needed (e.g., frequency limits)
+
+	case EventTypeStateDelta:
+		// State delta events are always valid in sequence context
+		// They represent incremental changes at any point in time
+		// Additional validation could be added if needed (e.g., conflict detection)
+
+	case EventTypeMessagesSnapshot:
+		// Message snapshot events are always valid in sequence context
+		// They represent complete message state at any point in time
+		// Additional validation could be added if needed (e.g., consistency checks)
+
+	case EventTypeMessageUpdated:
+		if msgEvent, ok := event.(*MessageUpdatedEvent); ok {
+			if err := v.checkMessageEdit("update", msgEvent.MessageID); err != nil {
+				return err
+			}
+		}
+
+	case EventTypeMessageDeleted:
+		if msgEvent, ok := event.(*MessageDeletedEvent); ok {
+			if err := v.checkMessageEdit("delete", msgEvent.MessageID); err != nil {
+				return err
+			}
+			v.deletedMessages[msgEvent.MessageID] = true
+		}
+
+	case EventTypeActivitySnapshot:
+		// Activity snapshot events are always valid in sequence context
+		// They represent complete activity state at any point in time
+
+	case EventTypeActivityDelta:
+		// Activity delta events are always valid in sequence context
+		// They represent incremental activity changes at any point in time
+
+	case EventTypeRaw:
+		// Raw events are always valid in sequence context
+		// They contain external data that should be passed through
+		// Additional validation could be added via custom validators
+
+	case EventTypeApprovalRequested:
+		if approvalEvent, ok := event.(*ApprovalRequestedEvent); ok {
+			id := approvalEvent.ApprovalID
+			if _, pending := v.pendingApprovals[id]; pending || v.answeredApprovals[id] {
+				return fmt.Errorf("approval %s already requested", id)
+			}
+			v.pendingApprovals[id] = approvalEvent.ToolCallID
+		}
+
+	case EventTypeApprovalGranted:
+		if approvalEvent, ok := event.(*ApprovalGrantedEvent); ok {
+			if err := v.answerApproval(approvalEvent.ApprovalID); err != nil {
+				return err
+			}
+		}
+
+	case EventTypeApprovalDenied:
+		if approvalEvent, ok := event.(*ApprovalDeniedEvent); ok {
+			if err := v.answerApproval(approvalEvent.ApprovalID); err != nil {
+				return err
+			}
+		}
+
+	case EventTypeCustom:
+		// Custom events are always valid in sequence context
+		// They contain application-specific data
+		// Additional validation could be added via custom validators
+
+	default:
+		// This should not happen due to prior validation, but add safety check
+		return fmt.Errorf("unknown event type in sequence: %s", event.Type())
+	}
+
+	return nil
+}
+
+// checkMessageEdit checks that the message with ID messageID can be edited:
+// messages being streamed must end first and deleted ones stay deleted.
+// Messages the validator has not seen, e.g. from the history of the
+// thread, can be edited.
+func (v *EventValidator) checkMessageEdit(edit, messageID string) error {
+	if v.activeMessages[messageID] || v.activeReasoningMessages[messageID] {
+		return fmt.Errorf("cannot %s message %s before it ended", edit, messageID)
+	}
+	if v.deletedMessages[messageID] {
+		return fmt.Errorf("cannot %s deleted message %s", edit, messageID)
+	}
+	return nil
+}
+
+// answerApproval records the answer to an approval request, which is
+// answered at most once; callers must hold mu
+func (v *EventValidator) answerApproval(id string) error {
+	if v.answeredApprovals[id] {
+		return fmt.Errorf("approval %s already answered", id)
+	}
+	delete(v.pendingApprovals, id)
+	v.answeredApprovals[id] = true
+	return nil
+}
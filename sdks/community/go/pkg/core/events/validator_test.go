@@ -0,0 +1,105 @@
+package events
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventValidator(t *testing.T) {
+	t.Run("ValidIncrementalSequence", func(t *testing.T) {
+		validator := NewEventValidator()
+
+		for _, event := range []Event{
+			NewRunStartedEvent("thread-1", "run-1"),
+			NewTextMessageStartEvent("msg-1", WithRole("assistant")),
+			NewTextMessageContentEvent("msg-1", "Hello"),
+			NewTextMessageEndEvent("msg-1"),
+			NewToolCallStartEvent("tool-1", "search"),
+			NewToolCallArgsEvent("tool-1", "{}"),
+			NewToolCallEndEvent("tool-1"),
+			NewRunFinishedEvent("thread-1", "run-1"),
+		} {
+			require.NoError(t, validator.ValidateEvent(event), "event %s", event.Type())
+		}
+	})
+
+	t.Run("RejectsNilEvent", func(t *testing.T) {
+		assert.Error(t, NewEventValidator().ValidateEvent(nil))
+	})
+
+	t.Run("RejectsInvalidEvent", func(t *testing.T) {
+		err := NewEventValidator().ValidateEvent(NewTextMessageStartEvent(""))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "TEXT_MESSAGE_START validation failed")
+	})
+
+	t.Run("RejectsContentBeforeStart", func(t *testing.T) {
+		err := NewEventValidator().ValidateEvent(NewTextMessageContentEvent("msg-1", "Hello"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "was not started")
+	})
+
+	t.Run("RejectedEventLeavesStateUnchanged", func(t *testing.T) {
+		validator := NewEventValidator()
+		require.NoError(t, validator.ValidateEvent(NewStepStartedEvent("plan")))
+		require.Error(t, validator.ValidateEvent(NewStepStartedEvent("plan")))
+		require.NoError(t, validator.ValidateEvent(NewStepFinishedEvent("plan")))
+		assert.Error(t, validator.ValidateEvent(NewStepFinishedEvent("plan")))
+	})
+
+	t.Run("RejectsRestartOfFinishedRun", func(t *testing.T) {
+		validator := NewEventValidator()
+		require.NoError(t, validator.ValidateEvent(NewRunStartedEvent("thread-1", "run-1")))
+		require.NoError(t, validator.ValidateEvent(NewRunFinishedEvent("thread-1", "run-1")))
+		assert.Error(t, validator.ValidateEvent(NewRunStartedEvent("thread-1", "run-1")))
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		validator := NewEventValidator()
+		require.NoError(t, validator.ValidateEvent(NewRunStartedEvent("thread-1", "run-1")))
+		validator.Reset()
+		assert.NoError(t, validator.ValidateEvent(NewRunStartedEvent("thread-1", "run-1")))
+	})
+
+	t.Run("Compact", func(t *testing.T) {
+		validator := NewEventValidator()
+		require.NoError(t, validator.ValidateEvent(NewRunStartedEvent("thread-1", "run-1")))
+		require.NoError(t, validator.ValidateEvent(NewRunFinishedEvent("thread-1", "run-1")))
+		require.NoError(t, validator.ValidateEvent(NewRunStartedEvent("thread-1", "run-2")))
+		assert.Equal(t, 1, validator.Compact())
+		assert.NoError(t, validator.ValidateEvent(NewRunStartedEvent("thread-1", "run-1")), "finished runs are forgotten")
+		assert.Error(t, validator.ValidateEvent(NewRunStartedEvent("thread-1", "run-2")), "active runs are kept")
+	})
+
+	t.Run("MessageEdits", func(t *testing.T) {
+		validator := NewEventValidator()
+		require.NoError(t, validator.ValidateEvent(NewRunStartedEvent("thread-1", "run-1")))
+		require.NoError(t, validator.ValidateEvent(NewTextMessageStartEvent("msg-1", WithRole("assistant"))))
+		assert.Error(t, validator.ValidateEvent(NewMessageUpdatedEvent("msg-1", "Hi")), "streaming messages cannot be edited")
+		assert.Error(t, validator.ValidateEvent(NewMessageDeletedEvent("msg-1")))
+		require.NoError(t, validator.ValidateEvent(NewTextMessageContentEvent("msg-1", "Hello")))
+		require.NoError(t, validator.ValidateEvent(NewTextMessageEndEvent("msg-1")))
+
+		require.NoError(t, validator.ValidateEvent(NewMessageUpdatedEvent("msg-1", "Hello there")))
+		require.NoError(t, validator.ValidateEvent(NewMessageUpdatedEvent("msg-0", "Edited")), "messages of the history can be edited")
+		require.NoError(t, validator.ValidateEvent(NewMessageDeletedEvent("msg-1")))
+		assert.Error(t, validator.ValidateEvent(NewMessageUpdatedEvent("msg-1", "Back")), "deleted messages stay deleted")
+		assert.Error(t, validator.ValidateEvent(NewMessageDeletedEvent("msg-1")))
+		assert.Error(t, validator.ValidateEvent(NewTextMessageStartEvent("msg-1")))
+
+		assert.Equal(t, 1, validator.Compact())
+		assert.NoError(t, validator.ValidateEvent(NewMessageDeletedEvent("msg-1")), "deleted messages are forgotten")
+	})
+
+	t.Run("MaxEventSize", func(t *testing.T) {
+		validator := NewEventValidator(WithMaxEventSize(100))
+		assert.Equal(t, 100, validator.MaxEventSize())
+		require.NoError(t, validator.ValidateEvent(NewStepStartedEvent("plan")))
+		err := validator.ValidateEvent(NewCustomEvent("large", WithValue(strings.Repeat("x", 100))))
+		assert.True(t, errors.Is(err, ErrEventTooLarge), "got %v", err)
+	})
+}
@@ -0,0 +1,238 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+// Protocol versions are MAJOR.MINOR. Clients declare the version they speak
+// in RunAgentInput.ProtocolVersion, servers answer with the version they
+// negotiated (see NegotiateProtocolVersion) in ProtocolVersionHeader and in
+// the RUN_STARTED event, and a ProtocolShim converts the events of the run
+// between that version and the one this package implements.
+const (
+	// ProtocolVersionHeader is the HTTP header carrying the negotiated
+	// protocol version of a run
+	ProtocolVersionHeader = "X-AG-UI-Protocol-Version"
+
+	// ProtocolVersion0_1 streams reasoning as THINKING_* events, sends run
+	// inputs that may omit their collections, cannot edit or delete
+	// messages and has no approval events
+	ProtocolVersion0_1 = "0.1"
+
+	// ProtocolVersion0_2 streams reasoning as REASONING_* events
+	ProtocolVersion0_2 = "0.2"
+
+	// ProtocolVersion is the version implemented by this package. Peers
+	// that do not declare a version are assumed to speak it.
+	ProtocolVersion = ProtocolVersion0_2
+)
+
+// ErrUnsupportedProtocolVersion is returned when no protocol version can be
+// agreed on
+var ErrUnsupportedProtocolVersion = errors.New("unsupported protocol version")
+
+// supportedProtocolVersions lists the versions shims exist for, oldest first
+var supportedProtocolVersions = []string{ProtocolVersion0_1, ProtocolVersion0_2}
+
+// SupportedProtocolVersions returns the protocol versions this package can
+// speak, oldest first
+func SupportedProtocolVersions() []string {
+	return append([]string{}, supportedProtocolVersions...)
+}
+
+// NegotiateProtocolVersion returns the version to use with a peer requesting
+// requested: the newest supported version not newer than requested, so that
+// newer peers fall back to the versions they know. An empty request stands
+// for ProtocolVersion.
+func NegotiateProtocolVersion(requested string) (string, error) {
+	if requested == "" {
+		return ProtocolVersion, nil
+	}
+	major, minor, err := parseProtocolVersion(requested)
+	if err != nil {
+		return "", err
+	}
+	for i := len(supportedProtocolVersions) - 1; i >= 0; i-- {
+		version := supportedProtocolVersions[i]
+		supportedMajor, supportedMinor, _ := parseProtocolVersion(version)
+		if supportedMajor == major && supportedMinor <= minor {
+			return version, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedProtocolVersion, requested)
+}
+
+// parseProtocolVersion splits a MAJOR.MINOR version
+func parseProtocolVersion(version string) (int, int, error) {
+	majorText, minorText, ok := strings.Cut(version, ".")
+	major, majorErr := strconv.Atoi(majorText)
+	minor, minorErr := strconv.Atoi(minorText)
+	if !ok || majorErr != nil || minorErr != nil || major < 0 || minor < 0 {
+		return 0, 0, fmt.Errorf("%w: malformed version %q", ErrUnsupportedProtocolVersion, version)
+	}
+	return major, minor, nil
+}
+
+// ProtocolShim converts the events of a run between ProtocolVersion and the
+// version spoken by a peer. It tracks the reasoning messages of the run, so
+// use one per run; it is not safe for concurrent use.
+type ProtocolShim struct {
+	version string
+
+	// reasoningID is the ID of the reasoning phase in progress and
+	// reasoningOpen whether its message has started
+	reasoningID   string
+	reasoningOpen bool
+}
+
+// NewProtocolShim creates a shim for a peer speaking version, "" standing
+// for ProtocolVersion
+func NewProtocolShim(version string) (*ProtocolShim, error) {
+	if version == "" {
+		version = ProtocolVersion
+	}
+	for _, supported := range supportedProtocolVersions {
+		if supported == version {
+			return &ProtocolShim{version: version}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedProtocolVersion, version)
+}
+
+// Version returns the version spoken by the peer
+func (s *ProtocolShim) Version() string {
+	return s.version
+}
+
+// UpgradeInput fills in the fields of a run input that the peer's version
+// allows to omit
+func (s *ProtocolShim) UpgradeInput(input *types.RunAgentInput) {
+	if s.version != ProtocolVersion0_1 {
+		return
+	}
+	if input.State == nil {
+		input.State = map[string]any{}
+	}
+	if input.Messages == nil {
+		input.Messages = []types.Message{}
+	}
+	if input.Tools == nil {
+		input.Tools = []types.Tool{}
+	}
+	if input.Context == nil {
+		input.Context = []types.Context{}
+	}
+	if input.ForwardedProps == nil {
+		input.ForwardedProps = map[string]any{}
+	}
+}
+
+// Downgrade converts an event to the peer's version. It returns no event
+// for events the version cannot express, and stamps RUN_STARTED events with
+// the version.
+func (s *ProtocolShim) Downgrade(event Event) []Event {
+	if started, ok := event.(*RunStartedEvent); ok && started.BaseEvent != nil && started.ProtocolVersion == "" {
+		started.ProtocolVersion = s.version
+	}
+	if s.version != ProtocolVersion0_1 {
+		return []Event{event}
+	}
+
+	switch e := event.(type) {
+	case *ReasoningStartEvent:
+		s.reasoningID, s.reasoningOpen = e.MessageID, false
+		return []Event{shimmed(NewThinkingStartEvent(), e)}
+	case *ReasoningMessageStartEvent:
+		s.reasoningID, s.reasoningOpen = e.MessageID, true
+		return []Event{shimmed(NewThinkingTextMessageStartEvent(), e)}
+	case *ReasoningMessageContentEvent:
+		return []Event{shimmed(NewThinkingTextMessageContentEvent(e.Delta), e)}
+	case *ReasoningMessageEndEvent:
+		s.reasoningOpen = false
+		return []Event{shimmed(NewThinkingTextMessageEndEvent(), e)}
+	case *ReasoningMessageChunkEvent:
+		// Chunks start and end their messages implicitly
+		var converted []Event
+		if e.MessageID != nil && *e.MessageID != s.reasoningID && s.reasoningOpen {
+			converted = append(converted, shimmed(NewThinkingTextMessageEndEvent(), e))
+			s.reasoningOpen = false
+		}
+		if e.MessageID != nil {
+			s.reasoningID = *e.MessageID
+		}
+		if !s.reasoningOpen {
+			converted = append(converted, shimmed(NewThinkingTextMessageStartEvent(), e))
+			s.reasoningOpen = true
+		}
+		if e.Delta != nil {
+			converted = append(converted, shimmed(NewThinkingTextMessageContentEvent(*e.Delta), e))
+		}
+		return converted
+	case *ReasoningEndEvent:
+		var converted []Event
+		if s.reasoningOpen {
+			converted = append(converted, shimmed(NewThinkingTextMessageEndEvent(), e))
+		}
+		s.reasoningID, s.reasoningOpen = "", false
+		return append(converted, shimmed(NewThinkingEndEvent(), e))
+	case *ReasoningEncryptedValueEvent, *MessageUpdatedEvent, *MessageDeletedEvent,
+		*ApprovalRequestedEvent, *ApprovalGrantedEvent, *ApprovalDeniedEvent:
+		return nil
+	}
+	return []Event{event}
+}
+
+// Upgrade converts an event sent by the peer to ProtocolVersion. A RUN_STARTED
+// event announcing a version switches the shim to it.
+func (s *ProtocolShim) Upgrade(event Event) Event {
+	if started, ok := event.(*RunStartedEvent); ok && started.BaseEvent != nil && started.ProtocolVersion != "" {
+		if version, err := NegotiateProtocolVersion(started.ProtocolVersion); err == nil {
+			s.version = version
+		}
+	}
+	if s.version != ProtocolVersion0_1 {
+		return event
+	}
+
+	switch e := event.(type) {
+	case *ThinkingStartEvent:
+		s.reasoningID, s.reasoningOpen = GenerateMessageID(), false
+		return shimmed(NewReasoningStartEvent(s.reasoningID), e)
+	case *ThinkingTextMessageStartEvent:
+		if s.reasoningID == "" {
+			s.reasoningID = GenerateMessageID()
+		}
+		s.reasoningOpen = true
+		return shimmed(NewReasoningMessageStartEvent(s.reasoningID, "assistant"), e)
+	case *ThinkingTextMessageContentEvent:
+		if s.reasoningID == "" {
+			s.reasoningID = GenerateMessageID()
+		}
+		return shimmed(NewReasoningMessageContentEvent(s.reasoningID, e.Delta), e)
+	case *ThinkingTextMessageEndEvent:
+		s.reasoningOpen = false
+		return shimmed(NewReasoningMessageEndEvent(s.reasoningID), e)
+	case *ThinkingEndEvent:
+		id := s.reasoningID
+		s.reasoningID, s.reasoningOpen = "", false
+		return shimmed(NewReasoningEndEvent(id), e)
+	}
+	return event
+}
+
+// shimmed gives a converted event the timestamp and metadata of the event
+// it replaces
+func shimmed[E Event](converted E, original Event) E {
+	if from, to := original.GetBaseEvent(), converted.GetBaseEvent(); from != nil && to != nil {
+		to.TimestampMs = from.TimestampMs
+		to.RawEvent = from.RawEvent
+		to.ProtocolVersion = from.ProtocolVersion
+		to.Metadata = from.Metadata
+	}
+	return converted
+}
@@ -0,0 +1,133 @@
+package events
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	for requested, want := range map[string]string{
+		"":    ProtocolVersion,
+		"0.1": ProtocolVersion0_1,
+		"0.2": ProtocolVersion0_2,
+		"0.7": ProtocolVersion,
+	} {
+		version, err := NegotiateProtocolVersion(requested)
+		require.NoError(t, err, requested)
+		assert.Equal(t, want, version, requested)
+	}
+
+	for _, requested := range []string{"0.0", "1.0", "latest", "0", "0.x"} {
+		_, err := NegotiateProtocolVersion(requested)
+		assert.True(t, errors.Is(err, ErrUnsupportedProtocolVersion), requested)
+	}
+}
+
+func TestProtocolShim(t *testing.T) {
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := NewProtocolShim("0.3")
+		assert.True(t, errors.Is(err, ErrUnsupportedProtocolVersion))
+	})
+
+	t.Run("current version", func(t *testing.T) {
+		shim, err := NewProtocolShim("")
+		require.NoError(t, err)
+		started := NewRunStartedEvent("thread-1", "run-1")
+		assert.Equal(t, []Event{started}, shim.Downgrade(started))
+		assert.Equal(t, ProtocolVersion, started.ProtocolVersion)
+
+		reasoning := NewReasoningStartEvent("reasoning-1")
+		assert.Equal(t, []Event{reasoning}, shim.Downgrade(reasoning))
+		assert.Same(t, reasoning, shim.Upgrade(reasoning))
+
+		input := &types.RunAgentInput{ThreadID: "thread-1", RunID: "run-1"}
+		shim.UpgradeInput(input)
+		assert.Nil(t, input.Messages)
+	})
+
+	t.Run("downgrade", func(t *testing.T) {
+		shim, err := NewProtocolShim(ProtocolVersion0_1)
+		require.NoError(t, err)
+		start := NewReasoningStartEvent("reasoning-1")
+		start.SetTimestamp(42)
+		start.Metadata = map[string]string{"tenantId": "acme"}
+
+		var downgraded []EventType
+		for _, event := range []Event{
+			start,
+			NewReasoningMessageChunkEvent(nil, nil).WithChunkMessageID("reasoning-1").WithChunkDelta("Let me"),
+			NewReasoningMessageChunkEvent(nil, nil).WithChunkDelta(" think"),
+			NewReasoningEncryptedValueEvent(ReasoningEncryptedValueSubtypeMessage, "reasoning-1", "opaque"),
+			NewMessageUpdatedEvent("msg-1", "Corrected"),
+			NewMessageDeletedEvent("msg-2"),
+			NewApprovalRequestedEvent("approval-1"),
+			NewApprovalGrantedEvent("approval-1"),
+			NewApprovalDeniedEvent("approval-2"),
+			NewReasoningEndEvent("reasoning-1"),
+		} {
+			for _, converted := range shim.Downgrade(event) {
+				require.NoError(t, converted.Validate())
+				downgraded = append(downgraded, converted.Type())
+				if event == start {
+					assert.Equal(t, int64(42), *converted.Timestamp())
+					assert.Equal(t, start.Metadata, converted.GetBaseEvent().Metadata)
+				}
+			}
+		}
+		assert.Equal(t, []EventType{
+			EventTypeThinkingStart,
+			EventTypeThinkingTextMessageStart,
+			EventTypeThinkingTextMessageContent,
+			EventTypeThinkingTextMessageContent,
+			EventTypeThinkingTextMessageEnd,
+			EventTypeThinkingEnd,
+		}, downgraded)
+	})
+
+	t.Run("upgrade", func(t *testing.T) {
+		shim, err := NewProtocolShim("")
+		require.NoError(t, err)
+		started := NewRunStartedEvent("thread-1", "run-1")
+		started.ProtocolVersion = ProtocolVersion0_1
+		shim.Upgrade(started)
+		assert.Equal(t, ProtocolVersion0_1, shim.Version(), "RUN_STARTED announces the version of the server")
+
+		var upgraded []Event
+		for _, event := range []Event{
+			NewThinkingStartEvent(),
+			NewThinkingTextMessageStartEvent(),
+			NewThinkingTextMessageContentEvent("Let me think"),
+			NewThinkingTextMessageEndEvent(),
+			NewThinkingEndEvent(),
+		} {
+			converted := shim.Upgrade(event)
+			require.NoError(t, converted.Validate())
+			upgraded = append(upgraded, converted)
+		}
+		require.NoError(t, ValidateSequence(append([]Event{NewRunStartedEvent("thread-1", "run-1")}, upgraded...)))
+		start, ok := upgraded[0].(*ReasoningStartEvent)
+		require.True(t, ok)
+		content, ok := upgraded[2].(*ReasoningMessageContentEvent)
+		require.True(t, ok)
+		assert.Equal(t, start.MessageID, content.MessageID)
+		assert.Equal(t, "Let me think", content.Delta)
+		assert.IsType(t, &ReasoningEndEvent{}, upgraded[4])
+	})
+
+	t.Run("input defaults", func(t *testing.T) {
+		shim, err := NewProtocolShim(ProtocolVersion0_1)
+		require.NoError(t, err)
+		input := &types.RunAgentInput{ThreadID: "thread-1", RunID: "run-1"}
+		shim.UpgradeInput(input)
+		assert.Equal(t, map[string]any{}, input.State)
+		assert.Equal(t, []types.Message{}, input.Messages)
+		assert.Equal(t, []types.Tool{}, input.Tools)
+		assert.Equal(t, []types.Context{}, input.Context)
+		assert.Equal(t, map[string]any{}, input.ForwardedProps)
+	})
+}
@@ -359,6 +359,8 @@ type RunAgentInput struct {
 	ForwardedProps any `json:"forwardedProps"`
 	// Resume is an optional list of interrupt responses for resuming a paused run.
 	Resume []ResumeEntry `json:"resume,omitempty"`
+	// ProtocolVersion is the AG-UI protocol version spoken by the client, empty for the current one.
+	ProtocolVersion string `json:"protocolVersion,omitempty"`
 }
 
 // UnmarshalJSON implements json.Unmarshaler and supports snake_case compatibility.
@@ -395,6 +397,9 @@ func (r *RunAgentInput) UnmarshalJSON(data []byte) error {
 	if err := unmarshalField(raw, &r.Resume, "resume"); err != nil {
 		return err
 	}
+	if err := unmarshalField(raw, &r.ProtocolVersion, "protocolVersion", "protocol_version"); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -528,6 +528,69 @@ func TestRunAgentInputUnmarshalWithoutResume(t *testing.T) {
 	assert.Nil(t, input.Resume)
 }
 
+// TestRunAgentInputUnmarshalWithProtocolVersion verifies decoding the protocol version of older clients.
+func TestRunAgentInputUnmarshalWithProtocolVersion(t *testing.T) {
+	payload := []byte(`{
+		"threadId": "thread-1",
+		"runId": "run-1",
+		"state": {},
+		"messages": [],
+		"tools": [],
+		"context": [],
+		"forwardedProps": {},
+		"protocolVersion": "0.2"
+	}`)
+
+	var input RunAgentInput
+	err := json.Unmarshal(payload, &input)
+	require.NoError(t, err)
+
+	assert.Equal(t, "0.2", input.ProtocolVersion)
+}
+
+// TestRunAgentInputUnmarshalWithProtocolVersionSnakeCase verifies snake_case compatibility for the protocol version.
+func TestRunAgentInputUnmarshalWithProtocolVersionSnakeCase(t *testing.T) {
+	payload := []byte(`{
+		"thread_id": "thread-1",
+		"run_id": "run-1",
+		"state": {},
+		"messages": [],
+		"tools": [],
+		"context": [],
+		"forwarded_props": {},
+		"protocol_version": "0.1"
+	}`)
+
+	var input RunAgentInput
+	err := json.Unmarshal(payload, &input)
+	require.NoError(t, err)
+
+	assert.Equal(t, "0.1", input.ProtocolVersion)
+}
+
+// TestRunAgentInputUnmarshalWithoutProtocolVersion verifies ProtocolVersion is empty, the current version, when absent from the payload.
+func TestRunAgentInputUnmarshalWithoutProtocolVersion(t *testing.T) {
+	payload := []byte(`{
+		"threadId": "thread-1",
+		"runId": "run-1",
+		"state": {},
+		"messages": [],
+		"tools": [],
+		"context": [],
+		"forwardedProps": {}
+	}`)
+
+	var input RunAgentInput
+	err := json.Unmarshal(payload, &input)
+	require.NoError(t, err)
+
+	assert.Empty(t, input.ProtocolVersion)
+
+	data, err := json.Marshal(input)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "protocolVersion")
+}
+
 // TestMessageContentActivity verifies ContentActivity extracts structured activity content.
 func TestMessageContentActivity(t *testing.T) {
 	payload := []byte(`{
@@ -553,3 +616,50 @@ func TestMessageContentActivity(t *testing.T) {
 	_, ok = msg.ContentActivity()
 	assert.False(t, ok)
 }
+
+func TestRunAgentInputValidate(t *testing.T) {
+	valid := RunAgentInput{
+		Messages: []Message{
+			{ID: "msg-1", Role: RoleUser, Content: []any{map[string]any{"type": "text", "text": "hello"}}},
+			{ID: "msg-2", Role: RoleAssistant, ToolCalls: []ToolCall{
+				{ID: "tc-1", Type: ToolCallTypeFunction, Function: FunctionCall{Name: "tool", Arguments: "{}"}},
+			}},
+			{ID: "msg-3", Role: RoleTool, ToolCallID: "tc-1", Content: "done"},
+			{ID: "act-1", Role: RoleActivity, ActivityType: "progress", Content: map[string]any{"pct": 50}},
+		},
+		Tools:   []Tool{{Name: "tool"}},
+		Context: []Context{{Description: "locale", Value: "en"}},
+		Resume:  []ResumeEntry{{InterruptID: "int-1", Status: ResumeStatusResolved}},
+	}
+	require.NoError(t, valid.Validate())
+	require.NoError(t, (&RunAgentInput{}).Validate())
+
+	tests := []struct {
+		name  string
+		edit  func(*RunAgentInput)
+		error string
+	}{
+		{"message without id", func(r *RunAgentInput) { r.Messages[0].ID = "" }, "messages[0]: id is required"},
+		{"unknown role", func(r *RunAgentInput) { r.Messages[0].Role = "robot" }, `messages[0]: unknown role "robot"`},
+		{"user content", func(r *RunAgentInput) { r.Messages[0].Content = 42 }, "messages[0]: content must be a string or a list of input contents"},
+		{"tool calls of users", func(r *RunAgentInput) { r.Messages[0].ToolCalls = r.Messages[1].ToolCalls }, "messages[0]: toolCalls are only allowed on assistant messages"},
+		{"tool call without name", func(r *RunAgentInput) { r.Messages[1].ToolCalls[0].Function.Name = "" }, "messages[1].toolCalls[0]: function name is required"},
+		{"tool message without call", func(r *RunAgentInput) { r.Messages[2].ToolCallID = "" }, "messages[2]: tool messages require toolCallId"},
+		{"activity content", func(r *RunAgentInput) { r.Messages[3].Content = "50%" }, "messages[3]: content must be an object"},
+		{"duplicate tool", func(r *RunAgentInput) { r.Tools = append(r.Tools, Tool{Name: "tool"}) }, `tools[1]: duplicate tool name "tool"`},
+		{"empty context", func(r *RunAgentInput) { r.Context[0] = Context{} }, "context[0]: description or value is required"},
+		{"resume status", func(r *RunAgentInput) { r.Resume[0].Status = "done" }, `resume[0]: unknown status "done"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(valid)
+			require.NoError(t, err)
+			var input RunAgentInput
+			require.NoError(t, json.Unmarshal(data, &input))
+			tt.edit(&input)
+			err = input.Validate()
+			require.ErrorIs(t, err, ErrInvalidInput)
+			assert.Contains(t, err.Error(), tt.error)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidInput is wrapped by the errors of the Validate methods.
+var ErrInvalidInput = errors.New("invalid run input")
+
+// invalid returns an ErrInvalidInput for the field at path.
+func invalid(path, format string, args ...any) error {
+	return fmt.Errorf("%w: %s: %s", ErrInvalidInput, path, fmt.Sprintf(format, args...))
+}
+
+// Validate checks the structure of the input: its messages, tools, context
+// entries and resume entries. Thread and run identifiers are left to the
+// server, which assigns the missing ones.
+func (r *RunAgentInput) Validate() error {
+	for i, message := range r.Messages {
+		if err := message.validate(fmt.Sprintf("messages[%d]", i)); err != nil {
+			return err
+		}
+	}
+	names := make(map[string]bool, len(r.Tools))
+	for i, tool := range r.Tools {
+		path := fmt.Sprintf("tools[%d]", i)
+		if err := tool.validate(path); err != nil {
+			return err
+		}
+		if names[tool.Name] {
+			return invalid(path, "duplicate tool name %q", tool.Name)
+		}
+		names[tool.Name] = true
+	}
+	for i, context := range r.Context {
+		if err := context.validate(fmt.Sprintf("context[%d]", i)); err != nil {
+			return err
+		}
+	}
+	for i, entry := range r.Resume {
+		path := fmt.Sprintf("resume[%d]", i)
+		if entry.InterruptID == "" {
+			return invalid(path, "interruptId is required")
+		}
+		if entry.Status != ResumeStatusResolved && entry.Status != ResumeStatusCancelled {
+			return invalid(path, "unknown status %q", entry.Status)
+		}
+	}
+	return nil
+}
+
+// Validate checks that the message has an identifier and a known role, that
+// the fields specific to a role are only set on messages of that role, and
+// that user and activity messages carry content of the expected shape.
+func (m Message) Validate() error {
+	return m.validate("message")
+}
+
+func (m Message) validate(path string) error {
+	if m.ID == "" {
+		return invalid(path, "id is required")
+	}
+	switch m.Role {
+	case RoleDeveloper, RoleSystem, RoleAssistant, RoleUser, RoleTool, RoleActivity, RoleReasoning:
+	case "":
+		return invalid(path, "role is required")
+	default:
+		return invalid(path, "unknown role %q", m.Role)
+	}
+	if m.Role == RoleTool && m.ToolCallID == "" {
+		return invalid(path, "tool messages require toolCallId")
+	}
+	if m.Role != RoleTool && m.ToolCallID != "" {
+		return invalid(path, "toolCallId is only allowed on tool messages")
+	}
+	if m.Role == RoleActivity && m.ActivityType == "" {
+		return invalid(path, "activity messages require activityType")
+	}
+	if m.Content != nil {
+		_, text := m.ContentString()
+		switch {
+		case m.Role == RoleUser && !text:
+			if _, ok := m.ContentInputContents(); !ok {
+				return invalid(path, "content must be a string or a list of input contents")
+			}
+		case m.Role == RoleActivity:
+			if _, ok := m.ContentActivity(); !ok {
+				return invalid(path, "content must be an object")
+			}
+		}
+	}
+	if m.Role != RoleAssistant && len(m.ToolCalls) > 0 {
+		return invalid(path, "toolCalls are only allowed on assistant messages")
+	}
+	for i, call := range m.ToolCalls {
+		callPath := fmt.Sprintf("%s.toolCalls[%d]", path, i)
+		if call.ID == "" {
+			return invalid(callPath, "id is required")
+		}
+		if call.Type != "" && call.Type != ToolCallTypeFunction {
+			return invalid(callPath, "unknown type %q", call.Type)
+		}
+		if call.Function.Name == "" {
+			return invalid(callPath, "function name is required")
+		}
+	}
+	return nil
+}
+
+// Validate checks that the tool has a name.
+func (t Tool) Validate() error {
+	return t.validate("tool")
+}
+
+func (t Tool) validate(path string) error {
+	if t.Name == "" {
+		return invalid(path, "name is required")
+	}
+	return nil
+}
+
+// Validate checks that the context entry carries a description or a value.
+func (c Context) Validate() error {
+	return c.validate("context")
+}
+
+func (c Context) validate(path string) error {
+	if c.Description == "" && c.Value == "" {
+		return invalid(path, "description or value is required")
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+// Package deadletter captures the events that fail decoding or validation
+// instead of dropping them silently. Each is recorded as a Letter holding
+// its raw bytes, the error and where it was received, and sent to a
+// pluggable Sink, such as a file or a message broker topic. Once the cause
+// is fixed, Replay decodes the letters of a file again and hands the events
+// recovered to the application.
+//
+// Decoders, validators and transports take an optional *Queue, e.g. the SSE
+// RunManager and the ingest webhook; a nil Queue drops the letters.
+package deadletter
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"maps"
+	"sync/atomic"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/logging"
+)
+
+// Letter is an event rejected by a decoder, a validator or a transport
+type Letter struct {
+	// Data is the raw event, as received
+	Data []byte `json:"data"`
+
+	// Error describes why the event was rejected
+	Error string `json:"error"`
+
+	// Source names the component that rejected the event, e.g. "sse"
+	Source string `json:"source"`
+
+	// ThreadID and RunID identify the run of the event, when known
+	ThreadID string `json:"threadId,omitempty"`
+	RunID    string `json:"runId,omitempty"`
+
+	// Attributes carry further context, e.g. the endpoint of the stream
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	// Time is when the event was rejected
+	Time time.Time `json:"time"`
+}
+
+// NewLetter returns the letter of data rejected by source with err
+func NewLetter(source string, data []byte, err error) Letter {
+	letter := Letter{Data: data, Source: source}
+	if err != nil {
+		letter.Error = err.Error()
+	}
+	return letter
+}
+
+// Sink stores letters. Implementations are safe for concurrent use.
+type Sink interface {
+	Send(ctx context.Context, letter Letter) error
+}
+
+// SinkFunc adapts an ordinary function to the Sink interface
+type SinkFunc func(ctx context.Context, letter Letter) error
+
+// Send calls f(ctx, letter)
+func (f SinkFunc) Send(ctx context.Context, letter Letter) error {
+	return f(ctx, letter)
+}
+
+// Config configures a Queue
+type Config struct {
+	// Clock timestamps the letters (defaults to the real clock)
+	Clock clock.Clock
+
+	// Logger logs the letters the sink fails to store (defaults to
+	// slog.Default())
+	Logger *slog.Logger
+}
+
+// Queue captures rejected events to a sink. Capturing never fails the
+// caller: letters the sink fails to store are logged and counted. It is
+// safe for concurrent use, and a nil Queue drops the letters.
+type Queue struct {
+	sink   Sink
+	config Config
+
+	captured atomic.Uint64
+	failed   atomic.Uint64
+}
+
+// NewQueue creates a queue sending its letters to sink
+func NewQueue(sink Sink, config Config) *Queue {
+	config.Clock = clock.Or(config.Clock)
+	config.Logger = logging.ForComponent(config.Logger, "deadletter")
+	return &Queue{sink: sink, config: config}
+}
+
+// Capture sends letter to the sink, timestamped unless it is already. Its
+// data and attributes are copied, so callers may reuse them.
+func (q *Queue) Capture(ctx context.Context, letter Letter) {
+	if q == nil {
+		return
+	}
+	letter.Data = bytes.Clone(letter.Data)
+	letter.Attributes = maps.Clone(letter.Attributes)
+	if letter.Time.IsZero() {
+		letter.Time = q.config.Clock.Now()
+	}
+	if err := q.sink.Send(ctx, letter); err != nil {
+		q.failed.Add(1)
+		logging.ForRun(q.config.Logger, letter.ThreadID, letter.RunID).Error("failed to store dead letter",
+			"source", letter.Source, "reason", letter.Error, "error", err)
+		return
+	}
+	q.captured.Add(1)
+}
+
+// Captured counts the letters stored
+func (q *Queue) Captured() uint64 {
+	if q == nil {
+		return 0
+	}
+	return q.captured.Load()
+}
+
+// Failed counts the letters the sink failed to store
+func (q *Queue) Failed() uint64 {
+	if q == nil {
+		return 0
+	}
+	return q.failed.Load()
+}
@@ -0,0 +1,146 @@
+package deadletter_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/deadletter"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/logging"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/testhelper"
+)
+
+// memorySink records the letters it receives
+type memorySink struct {
+	mu      sync.Mutex
+	letters []deadletter.Letter
+}
+
+func (s *memorySink) Send(_ context.Context, letter deadletter.Letter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.letters = append(s.letters, letter)
+	return nil
+}
+
+func TestQueueCapture(t *testing.T) {
+	ctx := context.Background()
+	clk := testhelper.NewFakeClock(time.Unix(100, 0))
+	sink := &memorySink{}
+	queue := deadletter.NewQueue(sink, deadletter.Config{Clock: clk, Logger: logging.Discard()})
+
+	data := []byte(`{"type":`)
+	letter := deadletter.NewLetter("sse", data, errors.New("unexpected end of JSON input"))
+	letter.RunID = "run-1"
+	queue.Capture(ctx, letter)
+	data[0] = 'X'
+
+	require.Len(t, sink.letters, 1)
+	assert.Equal(t, deadletter.Letter{
+		Data:   []byte(`{"type":`),
+		Error:  "unexpected end of JSON input",
+		Source: "sse",
+		RunID:  "run-1",
+		Time:   time.Unix(100, 0),
+	}, sink.letters[0], "the data is copied and the letter timestamped")
+	assert.Equal(t, uint64(1), queue.Captured())
+
+	failing := deadletter.NewQueue(deadletter.SinkFunc(func(context.Context, deadletter.Letter) error {
+		return errors.New("disk full")
+	}), deadletter.Config{Logger: logging.Discard()})
+	failing.Capture(ctx, letter)
+	assert.Equal(t, uint64(0), failing.Captured())
+	assert.Equal(t, uint64(1), failing.Failed())
+
+	var none *deadletter.Queue
+	none.Capture(ctx, letter)
+	assert.Zero(t, none.Captured())
+}
+
+func TestFileSinkReplay(t *testing.T) {
+	ctx := context.Background()
+	name := filepath.Join(t.TempDir(), "dead.jsonl")
+	sink, err := deadletter.NewFileSink(name)
+	require.NoError(t, err)
+	queue := deadletter.NewQueue(sink, deadletter.Config{Logger: logging.Discard()})
+
+	valid, err := events.NewTextMessageContentEvent("msg-1", "Hello").ToJSON()
+	require.NoError(t, err)
+	queue.Capture(ctx, deadletter.NewLetter("sse", valid, errors.New("unknown field")))
+	queue.Capture(ctx, deadletter.NewLetter("sse", []byte("garbage"), errors.New("invalid character")))
+	queue.Capture(ctx, deadletter.NewLetter("ingest", []byte(`{"type":"TEXT_MESSAGE_CONTENT","messageId":"msg-1"}`), errors.New("missing delta")))
+	require.NoError(t, sink.Close())
+
+	file, err := os.Open(name)
+	require.NoError(t, err)
+	defer file.Close()
+	retry := &memorySink{}
+	var recovered []string
+	result, err := deadletter.Replay(ctx, file, func(_ context.Context, letter deadletter.Letter, event events.Event) error {
+		recovered = append(recovered, event.(*events.TextMessageContentEvent).Delta)
+		assert.Equal(t, "unknown field", letter.Error)
+		return nil
+	}, deadletter.ReplayConfig{Retry: retry})
+	require.NoError(t, err)
+
+	assert.Equal(t, deadletter.ReplayResult{Recovered: 1, Failed: 2}, result)
+	assert.Equal(t, []string{"Hello"}, recovered)
+	require.Len(t, retry.letters, 2)
+	assert.Equal(t, "garbage", string(retry.letters[0].Data))
+	assert.NotEqual(t, "invalid character", retry.letters[0].Error, "letters failing again carry their new error")
+	assert.Equal(t, "ingest", retry.letters[1].Source)
+}
+
+func TestReplayHandlerFailure(t *testing.T) {
+	valid, err := events.NewTextMessageContentEvent("msg-1", "Hello").ToJSON()
+	require.NoError(t, err)
+	line, err := json.Marshal(deadletter.NewLetter("sse", valid, errors.New("timeout")))
+	require.NoError(t, err)
+
+	retry := &memorySink{}
+	result, err := deadletter.Replay(context.Background(), bytes.NewReader(line), func(context.Context, deadletter.Letter, events.Event) error {
+		return errors.New("consumer down")
+	}, deadletter.ReplayConfig{Retry: retry})
+	require.NoError(t, err)
+	assert.Equal(t, deadletter.ReplayResult{Failed: 1}, result)
+	require.Len(t, retry.letters, 1)
+	assert.Equal(t, "consumer down", retry.letters[0].Error)
+
+	_, err = deadletter.Replay(context.Background(), strings.NewReader("{not a letter"), nil, deadletter.ReplayConfig{})
+	assert.Error(t, err)
+}
+
+// recordingProducer records the messages it publishes
+type recordingProducer struct {
+	topic      string
+	key, value []byte
+}
+
+func (p *recordingProducer) Produce(_ context.Context, topic string, key, value []byte) error {
+	p.topic, p.key, p.value = topic, key, value
+	return nil
+}
+
+func TestTopicSink(t *testing.T) {
+	producer := &recordingProducer{}
+	letter := deadletter.NewLetter("sse", []byte("garbage"), errors.New("invalid character"))
+	letter.RunID = "run-1"
+	require.NoError(t, deadletter.NewTopicSink(producer, "agui.dead").Send(context.Background(), letter))
+
+	assert.Equal(t, "agui.dead", producer.topic)
+	assert.Equal(t, "run-1", string(producer.key))
+	var published deadletter.Letter
+	require.NoError(t, json.Unmarshal(producer.value, &published))
+	assert.Equal(t, letter, published)
+}
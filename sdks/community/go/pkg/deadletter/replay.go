@@ -0,0 +1,108 @@
+package deadletter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// Reader reads letters stored as JSON Lines, e.g. by a FileSink
+type Reader struct {
+	decoder *json.Decoder
+}
+
+// NewReader creates a reader of the letters of r
+func NewReader(r io.Reader) *Reader {
+	return &Reader{decoder: json.NewDecoder(r)}
+}
+
+// Next returns the next letter, or io.EOF once every letter was read
+func (r *Reader) Next() (Letter, error) {
+	var letter Letter
+	if err := r.decoder.Decode(&letter); err != nil {
+		if errors.Is(err, io.EOF) {
+			return Letter{}, io.EOF
+		}
+		return Letter{}, fmt.Errorf("invalid dead letter: %w", err)
+	}
+	return letter, nil
+}
+
+// Handler receives the events recovered from letters
+type Handler func(ctx context.Context, letter Letter, event events.Event) error
+
+// ReplayConfig configures Replay
+type ReplayConfig struct {
+	// Decode decodes the data of letters (defaults to events.EventFromJSON,
+	// validating the events)
+	Decode func(data []byte) (events.Event, error)
+
+	// Retry receives the letters failing again, with their new error (nil =
+	// letters failing again are only counted)
+	Retry Sink
+}
+
+// ReplayResult counts the letters replayed
+type ReplayResult struct {
+	// Recovered counts the letters decoded and handled
+	Recovered int
+
+	// Failed counts the letters failing again, to decode or to be handled
+	Failed int
+}
+
+// Replay decodes the letters of r again, e.g. once a decoder is fixed, and
+// hands the events recovered to handle. Letters failing again, whether to
+// decode or to be handled, go to config.Retry. Replay stops at the first
+// letter it cannot read or retry, or once ctx is done.
+func Replay(ctx context.Context, r io.Reader, handle Handler, config ReplayConfig) (ReplayResult, error) {
+	if config.Decode == nil {
+		config.Decode = decodeValid
+	}
+	var result ReplayResult
+	reader := NewReader(r)
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		letter, err := reader.Next()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return result, err
+		}
+
+		event, err := config.Decode(letter.Data)
+		if err == nil {
+			err = handle(ctx, letter, event)
+		}
+		if err == nil {
+			result.Recovered++
+			continue
+		}
+		result.Failed++
+		if config.Retry != nil {
+			letter.Error = err.Error()
+			if err := config.Retry.Send(ctx, letter); err != nil {
+				return result, fmt.Errorf("failed to retry dead letter: %w", err)
+			}
+		}
+	}
+}
+
+// decodeValid decodes a JSON event and validates it
+func decodeValid(data []byte) (events.Event, error) {
+	event, err := events.EventFromJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := event.Validate(); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
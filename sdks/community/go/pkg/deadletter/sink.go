@@ -0,0 +1,70 @@
+package deadletter
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// FileSink appends letters to a file as JSON Lines, which Replay reads back
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink creates a sink appending to the file name, created if missing
+func NewFileSink(name string) (*FileSink, error) {
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{file: file}, nil
+}
+
+// Send appends letter to the file, as a single write
+func (s *FileSink) Send(_ context.Context, letter Letter) error {
+	line, err := json.Marshal(letter)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(line)
+	return err
+}
+
+// Close closes the file
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// Producer publishes messages to the topics of a message broker, e.g. a
+// Kafka producer. Implementations are safe for concurrent use.
+type Producer interface {
+	Produce(ctx context.Context, topic string, key, value []byte) error
+}
+
+// TopicSink publishes letters to a topic, as JSON keyed by run ID so that
+// the letters of a run share a partition
+type TopicSink struct {
+	producer Producer
+	topic    string
+}
+
+// NewTopicSink creates a sink publishing to topic with producer
+func NewTopicSink(producer Producer, topic string) *TopicSink {
+	return &TopicSink{producer: producer, topic: topic}
+}
+
+// Send publishes letter
+func (s *TopicSink) Send(ctx context.Context, letter Letter) error {
+	value, err := json.Marshal(letter)
+	if err != nil {
+		return err
+	}
+	return s.producer.Produce(ctx, s.topic, []byte(letter.RunID), value)
+}
@@ -46,6 +46,14 @@ func GetOptimalBufferSize(eventType events.EventType) int {
 		return MediumEventBufferSize // Delta operations are usually medium
 	case events.EventTypeMessagesSnapshot:
 		return VeryLargeEventBufferSize // Message snapshots can be very large
+	case events.EventTypeMessageUpdated:
+		return LargeEventBufferSize // Whole message content
+	case events.EventTypeMessageDeleted:
+		return SmallEventBufferSize // Simple metadata
+	case events.EventTypeApprovalRequested:
+		return MediumEventBufferSize // Tool call arguments
+	case events.EventTypeApprovalGranted, events.EventTypeApprovalDenied:
+		return SmallEventBufferSize // Simple metadata
 	case events.EventTypeRaw:
 		return LargeEventBufferSize // Raw events are unpredictable
 	case events.EventTypeCustom:
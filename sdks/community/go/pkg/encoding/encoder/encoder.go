@@ -3,6 +3,9 @@ package encoder
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
 	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding"
@@ -10,22 +13,87 @@ import (
 	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding/negotiation"
 )
 
+// maxNegotiated bounds the Accept headers whose negotiation is cached
+const maxNegotiated = 256
+
 // EventEncoder provides a high-level interface for encoding AG-UI events
 // This adapter bridges the Go SDK encoding package with example server needs
 type EventEncoder struct {
 	negotiator *negotiation.ContentNegotiator
-	jsonCodec  encoding.Codec
+
+	// mu guards the registry of codecs by content type, of their profiles
+	// and constructors, and the cache of negotiations by Accept header,
+	// which generation versions: it is bumped, and the cache cleared,
+	// whenever the registry changes
+	mu           sync.RWMutex
+	codecs       map[string]encoding.Codec
+	profiles     map[profileKey]encoding.Codec
+	constructors map[string]CodecConstructor
+	negotiated   map[string]negotiated
+	generation   atomic.Uint64
 }
 
-// NewEventEncoder creates a new event encoder with content negotiation support
+// negotiated is the cached negotiation of an Accept header
+type negotiated struct {
+	contentType string
+	err         error
+}
+
+// NewEventEncoder creates a new event encoder with content negotiation
+// support. JSON comes with the compact, debug and strict profiles.
 func NewEventEncoder() *EventEncoder {
 	// Create content negotiator with JSON as preferred type
 	negotiator := negotiation.NewContentNegotiator("application/json")
 
-	return &EventEncoder{
+	e := &EventEncoder{
 		negotiator: negotiator,
-		jsonCodec:  json.NewCodec(),
+		codecs:     map[string]encoding.Codec{"application/json": json.NewCodec()},
+		profiles:   make(map[profileKey]encoding.Codec),
+		constructors: map[string]CodecConstructor{
+			"application/json": func(encOptions *encoding.EncodingOptions, decOptions *encoding.DecodingOptions) (encoding.Codec, error) {
+				return json.NewJSONCodec(encOptions, decOptions), nil
+			},
+		},
+		negotiated: make(map[string]negotiated),
+	}
+	for name, options := range jsonProfiles() {
+		e.profiles[profileKey{"application/json", name}] = json.NewJSONCodec(options.EncodingOptions, options.DecodingOptions)
 	}
+	return e
+}
+
+// RegisterCodec registers codec for its content type, replacing the codec
+// registered for it, if any. Content types the negotiator does not know
+// are negotiable with priority priority. Sessions pick up the change on
+// their next event.
+func (e *EventEncoder) RegisterCodec(codec encoding.Codec, priority float64) error {
+	contentType := strings.ToLower(codec.ContentType())
+	if !e.negotiator.CanHandle(contentType) {
+		if err := e.negotiator.AddFormat(contentType, priority); err != nil {
+			return err
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.codecs[contentType] = codec
+	e.invalidate()
+	return nil
+}
+
+// UnregisterCodec removes the codec registered for contentType, leaving its
+// profiles
+func (e *EventEncoder) UnregisterCodec(contentType string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.codecs, strings.ToLower(contentType))
+	e.invalidate()
+}
+
+// invalidate clears the negotiations cached; callers must hold mu
+func (e *EventEncoder) invalidate() {
+	clear(e.negotiated)
+	e.generation.Add(1)
 }
 
 // EncodeEvent encodes a single event using the specified content type
@@ -39,25 +107,82 @@ func (e *EventEncoder) EncodeEvent(ctx context.Context, event events.Event, cont
 		return nil, fmt.Errorf("event validation failed: %w", err)
 	}
 
-	// For now, we only support JSON encoding as specified in the task
-	// Protobuf support can be added later
-	switch contentType {
-	case "application/json", "":
-		return e.jsonCodec.Encode(ctx, event)
-	default:
-		// Try to negotiate to a supported type
-		supportedType, err := e.negotiator.Negotiate(contentType)
-		if err != nil {
-			return nil, fmt.Errorf("unsupported content type %q: %w", contentType, err)
-		}
+	var codec encoding.Codec
+	var err error
+	if contentType == "application/json" || contentType == "" {
+		// JSON needs no negotiation
+		e.mu.RLock()
+		codec, err = e.lookup("application/json", "")
+		e.mu.RUnlock()
+	} else {
+		codec, _, err = e.codecFor(contentType, "")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return codec.Encode(ctx, event)
+}
 
-		// For now, fallback to JSON
-		if supportedType == "application/json" {
-			return e.jsonCodec.Encode(ctx, event)
-		}
+// EncodeEventWithProfile encodes a single event using the specified content
+// type, configured as its profile profile (see RegisterProfile)
+func (e *EventEncoder) EncodeEventWithProfile(ctx context.Context, event events.Event, contentType, profile string) ([]byte, error) {
+	if event == nil {
+		return nil, fmt.Errorf("event cannot be nil")
+	}
+	if err := event.Validate(); err != nil {
+		return nil, fmt.Errorf("event validation failed: %w", err)
+	}
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	codec, _, err := e.codecFor(contentType, profile)
+	if err != nil {
+		return nil, err
+	}
+	return codec.Encode(ctx, event)
+}
 
-		return nil, fmt.Errorf("content type %q not implemented yet", supportedType)
+// codecFor returns the codec of the profile profile of the content type
+// negotiated for an Accept header, and the content type
+func (e *EventEncoder) codecFor(acceptHeader, profile string) (encoding.Codec, string, error) {
+	contentType, err := e.negotiate(acceptHeader)
+	if err != nil {
+		return nil, "", fmt.Errorf("unsupported content type %q: %w", acceptHeader, err)
 	}
+
+	e.mu.RLock()
+	codec, err := e.lookup(contentType, profile)
+	e.mu.RUnlock()
+	if err != nil {
+		return nil, "", err
+	}
+	return codec, contentType, nil
+}
+
+// negotiate negotiates acceptHeader, caching the outcome until the registry
+// changes
+func (e *EventEncoder) negotiate(acceptHeader string) (string, error) {
+	e.mu.RLock()
+	cached, ok := e.negotiated[acceptHeader]
+	e.mu.RUnlock()
+	if ok {
+		return cached.contentType, cached.err
+	}
+
+	generation := e.generation.Load()
+	contentType, err := e.negotiator.Negotiate(acceptHeader)
+	contentType = strings.ToLower(contentType)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	// Drop negotiations that raced with a change of the registry
+	if e.generation.Load() == generation {
+		if len(e.negotiated) >= maxNegotiated {
+			clear(e.negotiated)
+		}
+		e.negotiated[acceptHeader] = negotiated{contentType: contentType, err: err}
+	}
+	return contentType, err
 }
 
 // NegotiateContentType performs content negotiation based on Accept header
@@ -66,7 +191,7 @@ func (e *EventEncoder) NegotiateContentType(acceptHeader string) (string, error)
 		return "application/json", nil // Default to JSON
 	}
 
-	contentType, err := e.negotiator.Negotiate(acceptHeader)
+	contentType, err := e.negotiate(acceptHeader)
 	if err != nil {
 		// If negotiation fails, fallback to JSON with a clear message
 		return "application/json", fmt.Errorf("content negotiation failed, falling back to JSON: %w", err)
@@ -89,3 +214,78 @@ func (e *EventEncoder) GetContentType(acceptHeader string) string {
 	}
 	return contentType
 }
+
+// Session is the codec negotiated for a connection, resolved once rather
+// than for each event. It follows the changes of the registry of its
+// EventEncoder. A Session serves a single connection and is not safe for
+// concurrent use.
+type Session struct {
+	encoder      *EventEncoder
+	acceptHeader string
+	profile      string
+
+	codec       encoding.Codec
+	contentType string
+	generation  uint64
+}
+
+// NewSession negotiates the codec of a connection whose client accepts
+// acceptHeader, JSON when it is empty
+func (e *EventEncoder) NewSession(acceptHeader string) (*Session, error) {
+	return e.NewProfileSession(acceptHeader, "")
+}
+
+// NewProfileSession negotiates the codec of a connection as NewSession
+// does, configured as its profile profile (see RegisterProfile)
+func (e *EventEncoder) NewProfileSession(acceptHeader, profile string) (*Session, error) {
+	if acceptHeader == "" {
+		acceptHeader = "application/json"
+	}
+	s := &Session{encoder: e, acceptHeader: acceptHeader, profile: profile}
+	if err := s.resolve(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// resolve negotiates the codec of the session again
+func (s *Session) resolve() error {
+	generation := s.encoder.generation.Load()
+	codec, contentType, err := s.encoder.codecFor(s.acceptHeader, s.profile)
+	if err != nil {
+		return err
+	}
+	s.codec, s.contentType, s.generation = codec, contentType, generation
+	return nil
+}
+
+// Codec returns the codec of the session, negotiated again when the
+// registry changed since
+func (s *Session) Codec() (encoding.Codec, error) {
+	if s.generation != s.encoder.generation.Load() {
+		if err := s.resolve(); err != nil {
+			return nil, err
+		}
+	}
+	return s.codec, nil
+}
+
+// ContentType returns the content type of the codec of the session
+func (s *Session) ContentType() string {
+	return s.contentType
+}
+
+// EncodeEvent validates and encodes an event with the codec of the session
+func (s *Session) EncodeEvent(ctx context.Context, event events.Event) ([]byte, error) {
+	if event == nil {
+		return nil, fmt.Errorf("event cannot be nil")
+	}
+	if err := event.Validate(); err != nil {
+		return nil, fmt.Errorf("event validation failed: %w", err)
+	}
+	codec, err := s.Codec()
+	if err != nil {
+		return nil, err
+	}
+	return codec.Encode(ctx, event)
+}
@@ -0,0 +1,123 @@
+package encoder
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding/protobuf"
+)
+
+// taggingCodec encodes events as its tag
+type taggingCodec struct {
+	encoding.Codec
+	tag string
+}
+
+func (c taggingCodec) Encode(context.Context, events.Event) ([]byte, error) {
+	return []byte(c.tag), nil
+}
+
+func (c taggingCodec) ContentType() string {
+	return "application/json"
+}
+
+func TestSessionFollowsRegistry(t *testing.T) {
+	ctx := context.Background()
+	event := events.NewTextMessageContentEvent("msg-1", "Hello")
+	encoder := NewEventEncoder()
+
+	session, err := encoder.NewSession("")
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", session.ContentType())
+	data, err := session.EncodeEvent(ctx, event)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"delta":"Hello"`)
+
+	require.NoError(t, encoder.RegisterCodec(taggingCodec{tag: "tagged"}, 0.9))
+	data, err = session.EncodeEvent(ctx, event)
+	require.NoError(t, err)
+	assert.Equal(t, "tagged", string(data))
+
+	encoder.UnregisterCodec("application/json")
+	_, err = session.EncodeEvent(ctx, event)
+	assert.Error(t, err)
+
+	_, err = session.EncodeEvent(ctx, nil)
+	assert.Error(t, err)
+}
+
+func TestRegisterCodec(t *testing.T) {
+	ctx := context.Background()
+	event := events.NewTextMessageContentEvent("msg-1", "Hello")
+	encoder := NewEventEncoder()
+
+	_, err := encoder.EncodeEvent(ctx, event, protobuf.ContentType)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not implemented yet")
+	_, err = encoder.NewSession("application/x-protobuf, application/json;q=0.5")
+	require.Error(t, err)
+
+	require.NoError(t, encoder.RegisterCodec(protobuf.NewCodec(protobuf.StreamConfig{}), 1))
+	data, err := encoder.EncodeEvent(ctx, event, protobuf.ContentType)
+	require.NoError(t, err)
+	decoded, err := protobuf.NewCodec(protobuf.StreamConfig{}).Decode(ctx, data)
+	require.NoError(t, err)
+	assert.Equal(t, event.Delta, decoded.(*events.TextMessageContentEvent).Delta)
+
+	session, err := encoder.NewSession("application/x-protobuf, application/json;q=0.5")
+	require.NoError(t, err)
+	assert.Equal(t, protobuf.ContentType, session.ContentType())
+}
+
+func TestNegotiationCache(t *testing.T) {
+	encoder := NewEventEncoder()
+	contentType, err := encoder.NegotiateContentType("text/json")
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", contentType)
+	assert.Contains(t, encoder.negotiated, "text/json")
+
+	_, err = encoder.NegotiateContentType("image/png")
+	assert.Error(t, err)
+	_, err = encoder.NegotiateContentType("image/png")
+	assert.Error(t, err, "failures are cached as well")
+
+	for i := range 2 * maxNegotiated {
+		_, _ = encoder.NegotiateContentType(fmt.Sprintf("application/json;q=0.%d", i%10+1) + fmt.Sprintf(", text/x-%d", i))
+	}
+	assert.LessOrEqual(t, len(encoder.negotiated), maxNegotiated)
+
+	require.NoError(t, encoder.RegisterCodec(protobuf.NewCodec(protobuf.StreamConfig{}), 1))
+	assert.Empty(t, encoder.negotiated, "registering a codec clears the cache")
+}
+
+func BenchmarkEncodeEvent(b *testing.B) {
+	ctx := context.Background()
+	event := events.NewTextMessageContentEvent("msg-1", "Hello")
+	encoder := NewEventEncoder()
+	const accept = "application/vnd.ag-ui+json;q=0.5, application/json"
+
+	b.Run("negotiated", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := encoder.EncodeEvent(ctx, event, accept); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("session", func(b *testing.B) {
+		session, err := encoder.NewSession(accept)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for b.Loop() {
+			if _, err := session.EncodeEvent(ctx, event); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package encoder
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding/json"
+)
+
+// Names of the profiles registered for JSON by NewEventEncoder
+const (
+	// ProfileCompact skips validation for throughput
+	ProfileCompact = "compact"
+
+	// ProfileDebug pretty-prints events
+	ProfileDebug = "debug"
+
+	// ProfileStrict validates events and rejects unknown fields
+	ProfileStrict = "strict"
+)
+
+// Profile is a named configuration of the codec of a format
+type Profile struct {
+	Encoding *encoding.EncodingOptions
+	Decoding *encoding.DecodingOptions
+}
+
+// CodecConstructor creates the codec of a format configured with options
+type CodecConstructor func(encOptions *encoding.EncodingOptions, decOptions *encoding.DecodingOptions) (encoding.Codec, error)
+
+// profileKey identifies a profile of a format
+type profileKey struct {
+	contentType string
+	name        string
+}
+
+// jsonProfiles are the profiles registered for JSON by NewEventEncoder
+func jsonProfiles() map[string]*json.CodecOptions {
+	return map[string]*json.CodecOptions{
+		ProfileCompact: json.StreamingCodecOptions(),
+		ProfileDebug:   json.PrettyCodecOptions(),
+		ProfileStrict:  json.DefaultCodecOptions(),
+	}
+}
+
+// RegisterConstructor registers how to create the codecs of the profiles
+// of contentType
+func (e *EventEncoder) RegisterConstructor(contentType string, constructor CodecConstructor) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.constructors[strings.ToLower(contentType)] = constructor
+}
+
+// RegisterProfile registers the profile name of contentType, whose
+// constructor must be registered. Its options are validated and its codec
+// created once, here, rather than by each caller selecting it. Profiles
+// replace those registered with the same name, and sessions pick up the
+// change on their next event.
+func (e *EventEncoder) RegisterProfile(contentType, name string, profile Profile) error {
+	contentType = strings.ToLower(contentType)
+	if name == "" {
+		return fmt.Errorf("profile of %s: name cannot be empty", contentType)
+	}
+	if err := profile.Encoding.Validate(); err != nil {
+		return fmt.Errorf("profile %q of %s: %w", name, contentType, err)
+	}
+	if err := profile.Decoding.Validate(); err != nil {
+		return fmt.Errorf("profile %q of %s: %w", name, contentType, err)
+	}
+
+	e.mu.RLock()
+	constructor, ok := e.constructors[contentType]
+	e.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("profile %q of %s: no codec constructor registered", name, contentType)
+	}
+	codec, err := constructor(profile.Encoding, profile.Decoding)
+	if err != nil {
+		return fmt.Errorf("profile %q of %s: %w", name, contentType, err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.profiles[profileKey{contentType, name}] = codec
+	e.invalidate()
+	return nil
+}
+
+// SetDefaultProfile makes the profile name of contentType the codec used
+// for the format when callers select no profile
+func (e *EventEncoder) SetDefaultProfile(contentType, name string) error {
+	contentType = strings.ToLower(contentType)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	codec, ok := e.profiles[profileKey{contentType, name}]
+	if !ok {
+		return fmt.Errorf("%s has no profile %q", contentType, name)
+	}
+	e.codecs[contentType] = codec
+	e.invalidate()
+	return nil
+}
+
+// Profiles returns the names of the profiles of contentType, sorted
+func (e *EventEncoder) Profiles(contentType string) []string {
+	contentType = strings.ToLower(contentType)
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	var names []string
+	for key := range e.profiles {
+		if key.contentType == contentType {
+			names = append(names, key.name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// lookup returns the codec of the profile name of contentType, its default
+// codec for an empty name; callers must hold mu
+func (e *EventEncoder) lookup(contentType, name string) (encoding.Codec, error) {
+	if name == "" {
+		codec, ok := e.codecs[contentType]
+		if !ok {
+			return nil, fmt.Errorf("content type %q not implemented yet", contentType)
+		}
+		return codec, nil
+	}
+	codec, ok := e.profiles[profileKey{contentType, name}]
+	if !ok {
+		return nil, fmt.Errorf("%s has no profile %q", contentType, name)
+	}
+	return codec, nil
+}
@@ -0,0 +1,72 @@
+package encoder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding"
+)
+
+func TestBuiltinProfiles(t *testing.T) {
+	ctx := context.Background()
+	event := events.NewTextMessageContentEvent("msg-1", "Hello")
+	encoder := NewEventEncoder()
+	assert.Equal(t, []string{ProfileCompact, ProfileDebug, ProfileStrict}, encoder.Profiles("application/json"))
+
+	compact, err := encoder.EncodeEventWithProfile(ctx, event, "application/json", ProfileCompact)
+	require.NoError(t, err)
+	assert.NotContains(t, string(compact), "\n")
+	debug, err := encoder.EncodeEventWithProfile(ctx, event, "", ProfileDebug)
+	require.NoError(t, err)
+	assert.Contains(t, string(debug), "\n  ")
+	assert.JSONEq(t, string(compact), string(debug))
+
+	_, err = encoder.EncodeEventWithProfile(ctx, event, "application/json", "missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `no profile "missing"`)
+}
+
+func TestRegisterProfile(t *testing.T) {
+	ctx := context.Background()
+	event := events.NewTextMessageContentEvent("msg-1", "Hello")
+	encoder := NewEventEncoder()
+
+	assert.Error(t, encoder.RegisterProfile("application/json", "", Profile{}))
+	assert.Error(t, encoder.RegisterProfile("application/json", "broken", Profile{Encoding: &encoding.EncodingOptions{BufferSize: -1}}))
+	assert.Error(t, encoder.RegisterProfile("application/x-protobuf", "compact", Profile{}), "no constructor")
+	assert.Error(t, encoder.SetDefaultProfile("application/json", "missing"))
+
+	// Sessions follow profiles replaced under their name
+	session, err := encoder.NewProfileSession("application/json", "export")
+	require.Error(t, err)
+	require.NoError(t, encoder.RegisterProfile("application/json", "export", Profile{Encoding: &encoding.EncodingOptions{Pretty: true}}))
+	session, err = encoder.NewProfileSession("application/json", "export")
+	require.NoError(t, err)
+	data, err := session.EncodeEvent(ctx, event)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "\n")
+	require.NoError(t, encoder.RegisterProfile("application/json", "export", Profile{Encoding: &encoding.EncodingOptions{}}))
+	data, err = session.EncodeEvent(ctx, event)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "\n")
+
+	// The default profile applies to callers selecting none
+	require.NoError(t, encoder.SetDefaultProfile("application/json", ProfileDebug))
+	session, err = encoder.NewSession("text/json")
+	require.NoError(t, err)
+	data, err = session.EncodeEvent(ctx, event)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "\n  ")
+}
+
+func TestDefaultProfileEncodeEvent(t *testing.T) {
+	encoder := NewEventEncoder()
+	require.NoError(t, encoder.SetDefaultProfile("application/json", ProfileDebug))
+	data, err := encoder.EncodeEvent(context.Background(), events.NewTextMessageContentEvent("msg-1", "Hello"), "application/json")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "\n  ")
+}
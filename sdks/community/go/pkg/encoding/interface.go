@@ -20,6 +20,11 @@ type Encoder interface {
 	// EncodeMultiple encodes multiple events efficiently
 	EncodeMultiple(ctx context.Context, events []events.Event) ([]byte, error)
 
+	// EncodeTo encodes a single event, writing it to w rather than
+	// returning it, so that large events go to the network buffers of w
+	// without being copied out of the encoder
+	EncodeTo(ctx context.Context, w io.Writer, event events.Event) error
+
 	// ContentType returns the MIME type for this encoder
 	ContentType() string
 }
@@ -32,6 +37,9 @@ type Decoder interface {
 	// DecodeMultiple decodes multiple events from raw data
 	DecodeMultiple(ctx context.Context, data []byte) ([]events.Event, error)
 
+	// DecodeFrom decodes a single event from all the data of r
+	DecodeFrom(ctx context.Context, r io.Reader) (events.Event, error)
+
 	// ContentType returns the MIME type for this decoder
 	ContentType() string
 }
@@ -2,6 +2,7 @@ package json
 
 import (
 	"context"
+	"io"
 
 	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
 	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding"
@@ -54,6 +55,11 @@ func (c *JSONCodec) EncodeMultiple(ctx context.Context, events []events.Event) (
 	return c.JSONEncoder.EncodeMultiple(ctx, events)
 }
 
+// EncodeTo delegates to the encoder
+func (c *JSONCodec) EncodeTo(ctx context.Context, w io.Writer, event events.Event) error {
+	return c.JSONEncoder.EncodeTo(ctx, w, event)
+}
+
 // Decode delegates to the decoder
 func (c *JSONCodec) Decode(ctx context.Context, data []byte) (events.Event, error) {
 	return c.JSONDecoder.Decode(ctx, data)
@@ -64,6 +70,11 @@ func (c *JSONCodec) DecodeMultiple(ctx context.Context, data []byte) ([]events.E
 	return c.JSONDecoder.DecodeMultiple(ctx, data)
 }
 
+// DecodeFrom delegates to the decoder
+func (c *JSONCodec) DecodeFrom(ctx context.Context, r io.Reader) (events.Event, error) {
+	return c.JSONDecoder.DecodeFrom(ctx, r)
+}
+
 // ContentType returns the MIME type for JSON
 func (c *JSONCodec) ContentType() string {
 	return "application/json"
@@ -0,0 +1,84 @@
+package json
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding"
+)
+
+func TestEncodeToMatchesEncode(t *testing.T) {
+	ctx := context.Background()
+	event := events.NewTextMessageContentEvent("msg-1", "Hello")
+	for name, options := range map[string]*CodecOptions{
+		"default":       DefaultCodecOptions(),
+		"pretty":        PrettyCodecOptions(),
+		"compatibility": CompatibilityCodecOptions(),
+		"streaming":     StreamingCodecOptions(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			codec := NewJSONCodec(options.EncodingOptions, options.DecodingOptions)
+			data, err := codec.Encode(ctx, event)
+			require.NoError(t, err)
+			var buf bytes.Buffer
+			require.NoError(t, codec.EncodeTo(ctx, &buf, event))
+			assert.Equal(t, string(data), buf.String())
+
+			decoded, err := codec.DecodeFrom(ctx, &buf)
+			require.NoError(t, err)
+			assert.Equal(t, event.Delta, decoded.(*events.TextMessageContentEvent).Delta)
+		})
+	}
+}
+
+func TestEncodeToErrors(t *testing.T) {
+	ctx := context.Background()
+	event := events.NewTextMessageContentEvent("msg-1", "Hello")
+
+	var encodingErr *encoding.EncodingError
+	require.ErrorAs(t, NewDefaultJSONCodec().EncodeTo(ctx, &bytes.Buffer{}, nil), &encodingErr)
+
+	var buf bytes.Buffer
+	codec := NewJSONCodec(&encoding.EncodingOptions{CrossSDKCompatibility: true, MaxSize: 8}, nil)
+	require.ErrorAs(t, codec.EncodeTo(ctx, &buf, event), &encodingErr)
+	assert.Contains(t, encodingErr.Message, "exceeds max size")
+	assert.Zero(t, buf.Len(), "events over the size limit are not written")
+
+	errWrite := errors.New("write failed")
+	err := NewDefaultJSONCodec().EncodeTo(ctx, failingWriter{errWrite}, event)
+	assert.ErrorIs(t, err, errWrite)
+}
+
+func TestDecodeFromErrors(t *testing.T) {
+	ctx := context.Background()
+	data := `{"type":"TEXT_MESSAGE_CONTENT","messageId":"msg-1","delta":"Hello"}`
+
+	var decodingErr *encoding.DecodingError
+	codec := NewJSONCodec(nil, &encoding.DecodingOptions{MaxSize: int64(len(data) - 1)})
+	_, err := codec.DecodeFrom(ctx, strings.NewReader(data))
+	require.ErrorAs(t, err, &decodingErr)
+	assert.Contains(t, decodingErr.Message, "exceeds max size")
+
+	_, err = NewDefaultJSONCodec().DecodeFrom(ctx, strings.NewReader(`{"type":"UNKNOWN"}`))
+	require.ErrorAs(t, err, &decodingErr)
+	assert.Equal(t, `{"type":"UNKNOWN"}`, string(decodingErr.Data), "the data outlives the pooled buffer")
+
+	_, err = NewDefaultJSONCodec().DecodeFrom(ctx, strings.NewReader(""))
+	assert.Error(t, err)
+}
+
+// failingWriter fails every write with err
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
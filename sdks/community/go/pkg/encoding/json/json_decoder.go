@@ -1,9 +1,12 @@
 package json
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"sync/atomic"
 
 	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
@@ -53,11 +56,6 @@ func NewJSONDecoderWithConcurrencyLimit(options *encoding.DecodingOptions, maxCo
 	}
 }
 
-// eventTypeWrapper is used to extract the event type from JSON
-type eventTypeWrapper struct {
-	Type string `json:"type"`
-}
-
 // Decode decodes a single event from JSON data
 func (d *JSONDecoder) Decode(ctx context.Context, data []byte) (events.Event, error) {
 	// Check context cancellation
@@ -103,9 +101,10 @@ func (d *JSONDecoder) Decode(ctx context.Context, data []byte) (events.Event, er
 		}
 	}
 
-	// First, decode just the type field without strict checking
-	var typeWrapper eventTypeWrapper
-	if err := json.Unmarshal(data, &typeWrapper); err != nil {
+	// First, peek the type field without unmarshaling, so that the event
+	// is only decoded once
+	eventType, err := events.PeekEventType(data)
+	if err != nil {
 		return nil, &encoding.DecodingError{
 			Format:  "json",
 			Data:    data,
@@ -115,7 +114,7 @@ func (d *JSONDecoder) Decode(ctx context.Context, data []byte) (events.Event, er
 	}
 
 	// Create the appropriate event type based on the type field
-	event, err := d.createEvent(events.EventType(typeWrapper.Type), data)
+	event, err := d.createEvent(eventType, data)
 	if err != nil {
 		return nil, err
 	}
@@ -135,6 +134,45 @@ func (d *JSONDecoder) Decode(ctx context.Context, data []byte) (events.Event, er
 	return event, nil
 }
 
+// DecodeFrom decodes a single event from all the JSON data of r, read
+// into a pooled buffer rather than a slice allocated for the event
+func (d *JSONDecoder) DecodeFrom(ctx context.Context, r io.Reader) (events.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &encoding.DecodingError{
+			Format:  "json",
+			Message: "context cancelled",
+			Cause:   err,
+		}
+	}
+
+	buf := encoding.GetBuffer(bytes.MinRead)
+	defer encoding.PutBuffer(buf)
+
+	// Read one byte past the size limit to tell when it is exceeded
+	reader := r
+	if d.options.MaxSize > 0 {
+		reader = io.LimitReader(r, d.options.MaxSize+1)
+	}
+	if _, err := buf.ReadFrom(reader); err != nil {
+		return nil, &encoding.DecodingError{
+			Format:  "json",
+			Message: "failed to read event",
+			Cause:   err,
+		}
+	}
+
+	event, err := d.Decode(ctx, buf.Bytes())
+	if err != nil {
+		// The data of the error must outlive the buffer
+		var decodingErr *encoding.DecodingError
+		if errors.As(err, &decodingErr) {
+			decodingErr.Data = bytes.Clone(decodingErr.Data)
+		}
+		return nil, err
+	}
+	return event, nil
+}
+
 // DecodeMultiple decodes multiple events from JSON array data
 func (d *JSONDecoder) DecodeMultiple(ctx context.Context, data []byte) ([]events.Event, error) {
 	// Check context cancellation
@@ -210,20 +248,7 @@ func (d *JSONDecoder) DecodeMultiple(ctx context.Context, data []byte) ([]events
 
 // createEvent creates the appropriate event type based on the type string
 func (d *JSONDecoder) createEvent(eventType events.EventType, data []byte) (events.Event, error) {
-	// Use buffer pooling for creating a byte reader
-	buf := encoding.GetBufferSafe(len(data))
-	if buf == nil {
-		return nil, &encoding.DecodingError{
-			Format:  "json",
-			Data:    data,
-			Message: "failed to allocate buffer: resource limits exceeded",
-		}
-	}
-	defer encoding.PutBuffer(buf)
-
-	buf.Write(data)
-
-	decoder := json.NewDecoder(buf)
+	decoder := json.NewDecoder(bytes.NewReader(data))
 	if d.options.Strict && !d.options.AllowUnknownFields {
 		decoder.DisallowUnknownFields()
 	}
@@ -359,6 +384,12 @@ func (d *JSONDecoder) createEvent(eventType events.EventType, data []byte) (even
 		}
 	}
 
+	if err == nil {
+		// The event must be the only value in data
+		if _, tokenErr := decoder.Token(); tokenErr != io.EOF {
+			err = errors.New("unexpected data after event")
+		}
+	}
 	if err != nil {
 		return nil, &encoding.DecodingError{
 			Format:  "json",
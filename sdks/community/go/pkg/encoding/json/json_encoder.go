@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"sync/atomic"
 
 	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
@@ -218,6 +219,144 @@ func (e *JSONEncoder) Encode(ctx context.Context, event events.Event) ([]byte, e
 	return data, nil
 }
 
+// EncodeTo encodes a single event to JSON, writing it to w. Unlike Encode,
+// the event is not copied out of the pooled buffer it is encoded into. It
+// is written with a single call to w, and only when it fits MaxSize.
+func (e *JSONEncoder) EncodeTo(ctx context.Context, w io.Writer, event events.Event) error {
+	// Check context cancellation
+	if err := ctx.Err(); err != nil {
+		return &encoding.EncodingError{
+			Format:  "json",
+			Message: "context cancelled",
+			Cause:   err,
+		}
+	}
+
+	// Check concurrency limits atomically to avoid race condition
+	if e.maxConcurrent > 0 {
+		current := atomic.AddInt32(&e.activeOperations, 1)
+		if current > e.maxConcurrent {
+			atomic.AddInt32(&e.activeOperations, -1)
+			return &encoding.EncodingError{
+				Format:  "json",
+				Message: fmt.Sprintf("encoding concurrency limit exceeded: %d", e.maxConcurrent),
+			}
+		}
+		defer atomic.AddInt32(&e.activeOperations, -1)
+	}
+
+	if event == nil {
+		return &encoding.EncodingError{
+			Format:  "json",
+			Message: "cannot encode nil event",
+		}
+	}
+
+	if e.options.ValidateOutput {
+		if err := event.Validate(); err != nil {
+			return &encoding.EncodingError{
+				Format:  "json",
+				Event:   event,
+				Message: "event validation failed",
+				Cause:   err,
+			}
+		}
+	}
+
+	// The event's ToJSON output is written as is unless pretty printed
+	var data []byte
+	if e.options.CrossSDKCompatibility {
+		var err error
+		if data, err = event.ToJSON(); err != nil {
+			return &encoding.EncodingError{
+				Format:  "json",
+				Event:   event,
+				Message: "failed to encode event",
+				Cause:   err,
+			}
+		}
+		if !e.options.Pretty {
+			if err := e.checkSize(event, len(data)); err != nil {
+				return err
+			}
+			return writeEvent(w, event, data)
+		}
+	}
+
+	size := encoding.GetOptimalBufferSizeForEvent(event)
+	if e.options.Pretty {
+		size *= 2 // Pretty printing needs more space
+	}
+	buf := encoding.GetBufferSafe(size)
+	if buf == nil {
+		return &encoding.EncodingError{
+			Format:  "json",
+			Event:   event,
+			Message: "failed to allocate buffer: resource limits exceeded",
+		}
+	}
+	defer encoding.PutBuffer(buf)
+
+	if data != nil {
+		if err := json.Indent(buf, data, "", "  "); err != nil {
+			return &encoding.EncodingError{
+				Format:  "json",
+				Event:   event,
+				Message: "failed to format JSON",
+				Cause:   err,
+			}
+		}
+	} else {
+		encoder := json.NewEncoder(buf)
+		if e.options.Pretty {
+			encoder.SetIndent("", "  ")
+		}
+		if err := encoder.Encode(event); err != nil {
+			return &encoding.EncodingError{
+				Format:  "json",
+				Event:   event,
+				Message: "failed to marshal event",
+				Cause:   err,
+			}
+		}
+		// Remove trailing newline added by json.Encoder, as Encode does
+		// for compact output
+		if !e.options.Pretty {
+			buf.Truncate(buf.Len() - 1)
+		}
+	}
+
+	if err := e.checkSize(event, buf.Len()); err != nil {
+		return err
+	}
+	return writeEvent(w, event, buf.Bytes())
+}
+
+// checkSize checks the size of an encoded event against MaxSize
+func (e *JSONEncoder) checkSize(event events.Event, size int) error {
+	if e.options.MaxSize > 0 && int64(size) > e.options.MaxSize {
+		return &encoding.EncodingError{
+			Format:  "json",
+			Event:   event,
+			Message: fmt.Sprintf("encoded event exceeds max size of %d bytes", e.options.MaxSize),
+		}
+	}
+	return nil
+}
+
+// writeEvent writes the encoded event data to w
+func writeEvent(w io.Writer, event events.Event, data []byte) error {
+	if _, err := w.Write(data); err != nil {
+		return &encoding.EncodingError{
+			Format:  "json",
+			Event:   event,
+			Message: "failed to write event",
+			Cause:   err,
+		}
+	}
+	return nil
+}
+
 // EncodeMultiple encodes multiple events efficiently
 func (e *JSONEncoder) EncodeMultiple(ctx context.Context, events []events.Event) ([]byte, error) {
 	// Check context cancellation
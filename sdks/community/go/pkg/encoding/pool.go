@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"sync"
 	"sync/atomic"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/bufpool"
 )
 
 // Pool interface for object pooling
@@ -449,136 +451,66 @@ func (e *DecodingError) Reset() {
 
 // Global pools for common objects
 var (
-	// Buffer pools with different size limits and capacity limits (secure by default)
-	smallBufferPool  = NewBufferPoolWithOptions(4096, 500, true)   // 4KB max, 500 buffers, secure
-	mediumBufferPool = NewBufferPoolWithOptions(65536, 200, true)  // 64KB max, 200 buffers, secure
-	largeBufferPool  = NewBufferPoolWithOptions(1048576, 50, true) // 1MB max, 50 buffers, secure
-
-	// Slice pools for different sizes with capacity limits (secure by default)
-	smallSlicePool  = NewSlicePoolWithOptions(1024, 4096, 500, true)    // 1KB initial, 4KB max, 500 slices, secure
-	mediumSlicePool = NewSlicePoolWithOptions(4096, 65536, 200, true)   // 4KB initial, 64KB max, 200 slices, secure
-	largeSlicePool  = NewSlicePoolWithOptions(16384, 1048576, 50, true) // 16KB initial, 1MB max, 50 slices, secure
-
 	// Error pool
 	errorPool = NewErrorPool()
 )
 
-// GetBuffer returns a buffer from the appropriate pool based on expected size
-// Returns nil if resource limits are exceeded
+// maxUnpooledSize bounds the buffers and slices the Safe variants allocate
+// beyond the tiers of the shared pool
+const maxUnpooledSize = 100 * 1024 * 1024 // 100MB limit
+
+// GetBuffer returns a buffer with a capacity of at least expectedSize from
+// the pool shared across the SDK, see bufpool.Default
 func GetBuffer(expectedSize int) *bytes.Buffer {
-	switch {
-	case expectedSize <= 4096:
-		return smallBufferPool.Get()
-	case expectedSize <= 65536:
-		return mediumBufferPool.Get()
-	default:
-		return largeBufferPool.Get()
-	}
+	return bufpool.Default().GetBuffer(expectedSize)
 }
 
-// GetBufferSafe returns a buffer from the appropriate pool or creates a new one if pool is exhausted
+// GetBufferSafe returns a buffer like GetBuffer, or nil when expectedSize
+// exceeds reasonable limits
 func GetBufferSafe(expectedSize int) *bytes.Buffer {
-	buf := GetBuffer(expectedSize)
-	if buf == nil {
-		// Pool exhausted, create a new buffer but don't exceed reasonable limits
-		if expectedSize > 100*1024*1024 { // 100MB limit
-			return nil
-		}
-		return &bytes.Buffer{}
+	if expectedSize > maxUnpooledSize {
+		return nil
 	}
-	return buf
+	return GetBuffer(expectedSize)
 }
 
-// PutBuffer returns a buffer to the appropriate pool
+// PutBuffer returns a buffer to the shared pool, which zeroes its contents
 func PutBuffer(buf *bytes.Buffer) {
-	if buf == nil {
-		return
-	}
-
-	// The individual pool's Put method will handle zeroing
-	switch {
-	case buf.Cap() <= 4096:
-		smallBufferPool.Put(buf)
-	case buf.Cap() <= 65536:
-		mediumBufferPool.Put(buf)
-	default:
-		largeBufferPool.Put(buf)
-	}
+	bufpool.Default().PutBuffer(buf)
 }
 
-// PutBufferSecure returns a buffer to the appropriate pool with secure zeroing
+// PutBufferSecure returns a buffer to the shared pool with secure zeroing
 func PutBufferSecure(buf *bytes.Buffer) {
-	if buf == nil {
-		return
-	}
-
-	switch {
-	case buf.Cap() <= 4096:
-		smallBufferPool.PutSecure(buf)
-	case buf.Cap() <= 65536:
-		mediumBufferPool.PutSecure(buf)
-	default:
-		largeBufferPool.PutSecure(buf)
+	if buf != nil {
+		clear(buf.Bytes())
 	}
+	PutBuffer(buf)
 }
 
-// GetSlice returns a slice from the appropriate pool based on expected size
-// Returns nil if resource limits are exceeded
+// GetSlice returns a slice with a capacity of at least expectedSize from
+// the pool shared across the SDK, see bufpool.Default
 func GetSlice(expectedSize int) []byte {
-	switch {
-	case expectedSize <= 4096:
-		return smallSlicePool.Get()
-	case expectedSize <= 65536:
-		return mediumSlicePool.Get()
-	default:
-		return largeSlicePool.Get()
-	}
+	return bufpool.Default().GetSlice(expectedSize)
 }
 
-// GetSliceSafe returns a slice from the appropriate pool or creates a new one if pool is exhausted
+// GetSliceSafe returns a slice like GetSlice, or nil when expectedSize
+// exceeds reasonable limits
 func GetSliceSafe(expectedSize int) []byte {
-	slice := GetSlice(expectedSize)
-	if slice == nil {
-		// Pool exhausted, create a new slice but don't exceed reasonable limits
-		if expectedSize > 100*1024*1024 { // 100MB limit
-			return nil
-		}
-		return make([]byte, 0, expectedSize)
+	if expectedSize > maxUnpooledSize {
+		return nil
 	}
-	return slice
+	return GetSlice(expectedSize)
 }
 
-// PutSlice returns a slice to the appropriate pool
+// PutSlice returns a slice to the shared pool, which zeroes its contents
 func PutSlice(slice []byte) {
-	if slice == nil {
-		return
-	}
-
-	// The individual pool's Put method will handle zeroing
-	switch {
-	case cap(slice) <= 4096:
-		smallSlicePool.Put(slice)
-	case cap(slice) <= 65536:
-		mediumSlicePool.Put(slice)
-	default:
-		largeSlicePool.Put(slice)
-	}
+	bufpool.Default().PutSlice(slice)
 }
 
-// PutSliceSecure returns a slice to the appropriate pool with secure zeroing
+// PutSliceSecure returns a slice to the shared pool with secure zeroing
 func PutSliceSecure(slice []byte) {
-	if slice == nil {
-		return
-	}
-
-	switch {
-	case cap(slice) <= 4096:
-		smallSlicePool.PutSecure(slice)
-	case cap(slice) <= 65536:
-		mediumSlicePool.PutSecure(slice)
-	default:
-		largeSlicePool.PutSecure(slice)
-	}
+	clear(slice)
+	PutSlice(slice)
 }
 
 // GetEncodingError returns an encoding error from the pool
@@ -653,12 +585,7 @@ func PutRegistryError(err *RegistryError) {
 
 // ResetAllPools resets all global pools
 func ResetAllPools() {
-	smallBufferPool.Reset()
-	mediumBufferPool.Reset()
-	largeBufferPool.Reset()
-	smallSlicePool.Reset()
-	mediumSlicePool.Reset()
-	largeSlicePool.Reset()
+	bufpool.Default().Reset()
 	errorPool.Reset()
 }
 
@@ -0,0 +1,248 @@
+package protobuf
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	// ErrNoCheckpoint is returned by CheckpointStore.LoadCheckpoint for
+	// streams without checkpoints
+	ErrNoCheckpoint = errors.New("no checkpoint")
+
+	// ErrCheckpointMismatch is returned by Checkpoint.Verify for streams
+	// that do not match the checkpoint
+	ErrCheckpointMismatch = errors.New("stream does not match the checkpoint")
+)
+
+// Checkpoint is the position of a stream at the end of a chunk, saved
+// periodically while the stream is encoded or decoded (see
+// StreamConfig.Checkpoints) so that a job interrupted by a crash restarts
+// from it rather than from the start of the stream. It serializes to JSON.
+type Checkpoint struct {
+	// ID identifies the stream in its CheckpointStore
+	ID string `json:"id"`
+
+	// Chunks and Events count the chunks and events of the stream up to
+	// the checkpoint, and Offset their size in bytes
+	Chunks int   `json:"chunks"`
+	Events int   `json:"events"`
+	Offset int64 `json:"offset"`
+
+	// Hash is the CRC-32C of the stream up to the checkpoint, the state
+	// Verify checks a stream against
+	Hash uint32 `json:"hash"`
+}
+
+// Verify checks that r starts with the stream up to the checkpoint, e.g.
+// that an output file holds the chunks checkpointed before it is truncated
+// to Offset to restart the encoding
+func (c Checkpoint) Verify(r io.Reader) error {
+	h := crc32.New(castagnoli)
+	n, err := io.CopyN(h, r, c.Offset)
+	if err == io.EOF {
+		return fmt.Errorf("%w: the stream is %d bytes, the checkpoint is at %d", ErrCheckpointMismatch, n, c.Offset)
+	}
+	if err != nil {
+		return err
+	}
+	if h.Sum32() != c.Hash {
+		return fmt.Errorf("%w: hash %08x, the checkpoint says %08x", ErrCheckpointMismatch, h.Sum32(), c.Hash)
+	}
+	return nil
+}
+
+// CheckpointStore persists the checkpoints of streams. Implementations are
+// safe for concurrent use.
+type CheckpointStore interface {
+	// SaveCheckpoint replaces the checkpoint of the stream checkpoint.ID
+	SaveCheckpoint(ctx context.Context, checkpoint Checkpoint) error
+
+	// LoadCheckpoint returns the last checkpoint of a stream or
+	// ErrNoCheckpoint
+	LoadCheckpoint(ctx context.Context, id string) (Checkpoint, error)
+
+	// DeleteCheckpoint removes the checkpoint of a stream, e.g. once its job
+	// completed; deleting a missing checkpoint is not an error
+	DeleteCheckpoint(ctx context.Context, id string) error
+}
+
+type checkpointKey struct{}
+
+// WithCheckpoint returns a context under which the streams encoded or
+// decoded by codecs configured with a CheckpointStore save their
+// checkpoints, from checkpoint on: the zero checkpoint with an ID for new
+// streams, or the checkpoint loaded from the store to restart a stream,
+// the output being truncated to, or the input read from, its Offset and the
+// events encoded from its Events. Sessions started with Resume do not save
+// checkpoints.
+func WithCheckpoint(ctx context.Context, checkpoint Checkpoint) context.Context {
+	return context.WithValue(ctx, checkpointKey{}, checkpoint)
+}
+
+// checkpointer tracks the position of a stream and saves its checkpoints
+// every interval chunks
+type checkpointer struct {
+	store    CheckpointStore
+	interval int
+	current  Checkpoint
+	unsaved  int
+}
+
+// newCheckpointer returns the checkpointer of a stream started under ctx,
+// nil when it saves no checkpoints
+func newCheckpointer(ctx context.Context, config StreamConfig) *checkpointer {
+	checkpoint, ok := ctx.Value(checkpointKey{}).(Checkpoint)
+	if !ok || checkpoint.ID == "" || config.Checkpoints == nil {
+		return nil
+	}
+	return &checkpointer{store: config.Checkpoints, interval: config.CheckpointInterval, current: checkpoint}
+}
+
+// chain returns the hash of the stream followed by data
+func (c *checkpointer) chain(data ...[]byte) uint32 {
+	hash := c.current.Hash
+	for _, d := range data {
+		hash = crc32.Update(hash, castagnoli, d)
+	}
+	return hash
+}
+
+// advance moves the position past a chunk of length bytes and evts events,
+// the stream then hashing to hash
+func (c *checkpointer) advance(length, evts int, hash uint32) {
+	c.current.Chunks++
+	c.current.Events += evts
+	c.current.Offset += int64(length)
+	c.current.Hash = hash
+	c.unsaved++
+}
+
+// save saves the checkpoint once interval chunks are unsaved, or as soon as
+// one is when force is set
+func (c *checkpointer) save(ctx context.Context, force bool) error {
+	if c.unsaved == 0 || (!force && c.unsaved < c.interval) {
+		return nil
+	}
+	if err := c.store.SaveCheckpoint(ctx, c.current); err != nil {
+		return fmt.Errorf("failed to save checkpoint %s: %w", c.current.ID, err)
+	}
+	c.unsaved = 0
+	return nil
+}
+
+// MemoryCheckpointStore keeps checkpoints in memory, for streams restarted
+// within a process
+type MemoryCheckpointStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]Checkpoint
+}
+
+// NewMemoryCheckpointStore creates an empty in-memory store
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{checkpoints: make(map[string]Checkpoint)}
+}
+
+// SaveCheckpoint replaces the checkpoint of a stream
+func (s *MemoryCheckpointStore) SaveCheckpoint(_ context.Context, checkpoint Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints[checkpoint.ID] = checkpoint
+	return nil
+}
+
+// LoadCheckpoint returns the last checkpoint of a stream
+func (s *MemoryCheckpointStore) LoadCheckpoint(_ context.Context, id string) (Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checkpoint, ok := s.checkpoints[id]
+	if !ok {
+		return Checkpoint{}, fmt.Errorf("%w: %s", ErrNoCheckpoint, id)
+	}
+	return checkpoint, nil
+}
+
+// DeleteCheckpoint removes the checkpoint of a stream
+func (s *MemoryCheckpointStore) DeleteCheckpoint(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.checkpoints, id)
+	return nil
+}
+
+// FileCheckpointStore keeps each checkpoint as a JSON file in a directory,
+// for streams restarted by another process. Checkpoints are written
+// atomically.
+type FileCheckpointStore struct {
+	dir string
+}
+
+// NewFileCheckpointStore creates a store in dir, creating the directory if
+// needed
+func NewFileCheckpointStore(dir string) (*FileCheckpointStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+	return &FileCheckpointStore{dir: dir}, nil
+}
+
+// SaveCheckpoint replaces the checkpoint of a stream through a temporary
+// file
+func (s *FileCheckpointStore) SaveCheckpoint(_ context.Context, checkpoint Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, ".checkpoint-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(checkpoint.ID))
+}
+
+// LoadCheckpoint returns the last checkpoint of a stream
+func (s *FileCheckpointStore) LoadCheckpoint(_ context.Context, id string) (Checkpoint, error) {
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Checkpoint{}, fmt.Errorf("%w: %s", ErrNoCheckpoint, id)
+	}
+	if err != nil {
+		return Checkpoint{}, fmt.Errorf("failed to read checkpoint %s: %w", id, err)
+	}
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return Checkpoint{}, fmt.Errorf("failed to decode checkpoint %s: %w", id, err)
+	}
+	return checkpoint, nil
+}
+
+// DeleteCheckpoint removes the checkpoint of a stream
+func (s *FileCheckpointStore) DeleteCheckpoint(_ context.Context, id string) error {
+	if err := os.Remove(s.path(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete checkpoint %s: %w", id, err)
+	}
+	return nil
+}
+
+// path returns the file of a checkpoint. IDs are encoded so that any ID
+// maps to a single file name inside the store directory.
+func (s *FileCheckpointStore) path(id string) string {
+	return filepath.Join(s.dir, base64.RawURLEncoding.EncodeToString([]byte(id))+".json")
+}
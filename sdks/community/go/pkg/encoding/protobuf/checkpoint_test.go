@@ -0,0 +1,192 @@
+package protobuf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"hash/crc32"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// recordingStore is a memory store recording the checkpoints saved
+type recordingStore struct {
+	*MemoryCheckpointStore
+	saved []Checkpoint
+	err   error
+}
+
+func (s *recordingStore) SaveCheckpoint(ctx context.Context, checkpoint Checkpoint) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.saved = append(s.saved, checkpoint)
+	return s.MemoryCheckpointStore.SaveCheckpoint(ctx, checkpoint)
+}
+
+func TestCheckpointedEncodeStream(t *testing.T) {
+	evts := testEvents(100)
+	store := &recordingStore{MemoryCheckpointStore: NewMemoryCheckpointStore()}
+	config := StreamConfig{ChunkEvents: 10, Workers: 3, Checkpoints: store, CheckpointInterval: 3}
+
+	input := make(chan events.Event, len(evts))
+	for _, event := range evts {
+		input <- event
+	}
+	close(input)
+	var out bytes.Buffer
+	ctx := WithCheckpoint(context.Background(), Checkpoint{ID: "job-1"})
+	require.NoError(t, NewStreamEncoder(config).EncodeStream(ctx, input, &out))
+
+	var chunks []int
+	for _, checkpoint := range store.saved {
+		chunks = append(chunks, checkpoint.Chunks)
+	}
+	assert.Equal(t, []int{3, 6, 9, 10}, chunks, "the end of the stream is always checkpointed")
+
+	last, err := store.LoadCheckpoint(context.Background(), "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, Checkpoint{ID: "job-1", Chunks: 10, Events: 100, Offset: int64(out.Len()), Hash: crc32.Checksum(out.Bytes(), castagnoli)}, last)
+	assert.NoError(t, last.Verify(bytes.NewReader(out.Bytes())))
+
+	// Streams encoded without a checkpoint in their context are not
+	// checkpointed
+	store.saved = nil
+	input = make(chan events.Event, 1)
+	input <- evts[0]
+	close(input)
+	require.NoError(t, NewStreamEncoder(config).EncodeStream(context.Background(), input, &out))
+	assert.Empty(t, store.saved)
+}
+
+func TestCheckpointedEncodingRestart(t *testing.T) {
+	ctx := context.Background()
+	evts := testEvents(100)
+	store := NewMemoryCheckpointStore()
+	config := StreamConfig{ChunkEvents: 10, Checkpoints: store}
+
+	var want bytes.Buffer
+	uninterrupted := NewStreamEncoder(StreamConfig{ChunkEvents: 10})
+	require.NoError(t, uninterrupted.StartStream(ctx, &want))
+	for _, event := range evts {
+		require.NoError(t, uninterrupted.WriteEvent(ctx, event))
+	}
+	require.NoError(t, uninterrupted.EndStream(ctx))
+
+	// The job crashes after 45 events, in the middle of writing a chunk
+	var out bytes.Buffer
+	encoder := NewStreamEncoder(config)
+	require.NoError(t, encoder.StartStream(WithCheckpoint(ctx, Checkpoint{ID: "job-1"}), &out))
+	for _, event := range evts[:45] {
+		require.NoError(t, encoder.WriteEvent(ctx, event))
+	}
+	out.Write([]byte{0, 0, 1})
+
+	checkpoint, err := store.LoadCheckpoint(ctx, "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, 4, checkpoint.Chunks)
+	assert.Equal(t, 40, checkpoint.Events)
+	require.NoError(t, checkpoint.Verify(bytes.NewReader(out.Bytes())))
+
+	// It restarts from the checkpoint
+	out.Truncate(int(checkpoint.Offset))
+	encoder = NewStreamEncoder(config)
+	require.NoError(t, encoder.StartStream(WithCheckpoint(ctx, checkpoint), &out))
+	for _, event := range evts[checkpoint.Events:] {
+		require.NoError(t, encoder.WriteEvent(ctx, event))
+	}
+	require.NoError(t, encoder.EndStream(ctx))
+	assert.Equal(t, want.Bytes(), out.Bytes())
+
+	checkpoint, err = store.LoadCheckpoint(ctx, "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, 100, checkpoint.Events)
+	assert.Equal(t, crc32.Checksum(want.Bytes(), castagnoli), checkpoint.Hash)
+}
+
+func TestCheckpointedDecodingRestart(t *testing.T) {
+	ctx := context.Background()
+	evts := testEvents(100)
+	data, _ := encodeWithManifest(t, StreamConfig{ChunkEvents: 10}, evts)
+	store := NewMemoryCheckpointStore()
+	config := StreamConfig{Checkpoints: store}
+
+	// The job crashes while handling the 36th event: the events of a chunk
+	// are checkpointed once the next one is read
+	decoder := NewStreamDecoder(config)
+	require.NoError(t, decoder.StartStream(WithCheckpoint(ctx, Checkpoint{ID: "job-1"}), bytes.NewReader(data)))
+	received := readEvents(t, decoder, 36)
+	checkpoint, err := store.LoadCheckpoint(ctx, "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, checkpoint.Chunks)
+	assert.Equal(t, 30, checkpoint.Events)
+	require.NoError(t, checkpoint.Verify(bytes.NewReader(data)))
+
+	decoder = NewStreamDecoder(config)
+	require.NoError(t, decoder.StartStream(WithCheckpoint(ctx, checkpoint), bytes.NewReader(data[checkpoint.Offset:])))
+	assert.Equal(t, Progress{Chunks: 3, Bytes: checkpoint.Offset, Events: 30}, decoder.Progress())
+	requireSameEvents(t, evts, append(received[:checkpoint.Events], readEvents(t, decoder, -1)...))
+
+	checkpoint, err = store.LoadCheckpoint(ctx, "job-1")
+	require.NoError(t, err)
+	assert.Equal(t, Checkpoint{ID: "job-1", Chunks: 10, Events: 100, Offset: int64(len(data)), Hash: crc32.Checksum(data, castagnoli)}, checkpoint)
+}
+
+func TestCheckpointErrors(t *testing.T) {
+	ctx := context.Background()
+	data, _ := encodeWithManifest(t, StreamConfig{ChunkEvents: 10}, testEvents(30))
+	checkpoint := Checkpoint{Chunks: 3, Events: 30, Offset: int64(len(data)), Hash: crc32.Checksum(data, castagnoli)}
+
+	assert.ErrorIs(t, checkpoint.Verify(bytes.NewReader(data[:len(data)-1])), ErrCheckpointMismatch)
+	corrupt := bytes.Clone(data)
+	corrupt[chunkHeaderSize] ^= 0xff
+	assert.ErrorIs(t, checkpoint.Verify(bytes.NewReader(corrupt)), ErrCheckpointMismatch)
+
+	store := &recordingStore{MemoryCheckpointStore: NewMemoryCheckpointStore(), err: errors.New("disk full")}
+	encoder := NewStreamEncoder(StreamConfig{ChunkEvents: 10, Checkpoints: store})
+	require.NoError(t, encoder.StartStream(WithCheckpoint(ctx, Checkpoint{ID: "job-1"}), &bytes.Buffer{}))
+	for _, event := range testEvents(9) {
+		require.NoError(t, encoder.WriteEvent(ctx, event))
+	}
+	err := encoder.WriteEvent(ctx, events.NewStepStartedEvent("plan"))
+	assert.ErrorContains(t, err, "failed to save checkpoint job-1: disk full")
+
+	decoder := NewStreamDecoder(StreamConfig{Checkpoints: store})
+	require.NoError(t, decoder.StartStream(WithCheckpoint(ctx, Checkpoint{ID: "job-1"}), bytes.NewReader(data)))
+	readEvents(t, decoder, 10)
+	_, err = decoder.ReadEvent(ctx)
+	assert.ErrorContains(t, err, "disk full")
+}
+
+func TestCheckpointStores(t *testing.T) {
+	fileStore, err := NewFileCheckpointStore(t.TempDir())
+	require.NoError(t, err)
+	stores := map[string]CheckpointStore{
+		"memory": NewMemoryCheckpointStore(),
+		"file":   fileStore,
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			_, err := store.LoadCheckpoint(ctx, "../job-1")
+			assert.ErrorIs(t, err, ErrNoCheckpoint)
+
+			checkpoint := Checkpoint{ID: "../job-1", Chunks: 2, Events: 20, Offset: 512, Hash: 0xdeadbeef}
+			require.NoError(t, store.SaveCheckpoint(ctx, checkpoint))
+			checkpoint.Chunks, checkpoint.Events = 3, 30
+			require.NoError(t, store.SaveCheckpoint(ctx, checkpoint))
+			loaded, err := store.LoadCheckpoint(ctx, "../job-1")
+			require.NoError(t, err)
+			assert.Equal(t, checkpoint, loaded)
+
+			require.NoError(t, store.DeleteCheckpoint(ctx, "../job-1"))
+			require.NoError(t, store.DeleteCheckpoint(ctx, "../job-1"))
+			_, err = store.LoadCheckpoint(ctx, "../job-1")
+			assert.ErrorIs(t, err, ErrNoCheckpoint)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package protobuf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+)
+
+// checksumSize is the size of the CRC-32C following each chunk of the
+// streams encoded with checksums
+const checksumSize = 4
+
+// The preamble of the streams encoded with checksums: a magic number no
+// chunk header nor event starts with, followed by flags describing the
+// stream
+var preambleMagic = []byte("AGPB")
+
+const preambleSize = 5
+
+const (
+	// preambleChecksums flags streams whose chunks are followed by their
+	// checksum
+	preambleChecksums byte = 1 << iota
+
+	// preambleDelimited flags streams with FramingDelimited
+	preambleDelimited
+)
+
+// ChecksumError reports a chunk of a stream, or an event of a delimited
+// one, whose content does not match the checksum following it
+type ChecksumError struct {
+	// Chunk is the index of the chunk in the stream, Offset its position
+	// in bytes
+	Chunk  int
+	Offset int64
+
+	// Checksum is the checksum read, Computed that of the content read
+	Checksum uint32
+	Computed uint32
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("chunk %d at offset %d: checksum %08x does not match its content (%08x)", e.Chunk, e.Offset, e.Checksum, e.Computed)
+}
+
+// appendPreamble appends the preamble of a stream with framing to dst
+func appendPreamble(dst []byte, framing Framing) []byte {
+	flags := preambleChecksums
+	if framing == FramingDelimited {
+		flags |= preambleDelimited
+	}
+	return append(append(dst, preambleMagic...), flags)
+}
+
+// parsePreamble returns the framing of the stream starting with preamble
+func parsePreamble(preamble []byte) (Framing, error) {
+	flags := preamble[len(preambleMagic)]
+	if flags&^(preambleChecksums|preambleDelimited) != 0 || flags&preambleChecksums == 0 {
+		return FramingAuto, fmt.Errorf("unsupported stream flags %#02x", flags)
+	}
+	if flags&preambleDelimited != 0 {
+		return FramingDelimited, nil
+	}
+	return FramingChunked, nil
+}
+
+// startsWithPreamble reports whether data starts with the magic number of
+// the preamble
+func startsWithPreamble(data []byte) bool {
+	return bytes.HasPrefix(data, preambleMagic)
+}
+
+// appendChecksum appends the checksum of data[start:] to data
+func appendChecksum(data []byte, start int) []byte {
+	return binary.BigEndian.AppendUint32(data, crc32.Checksum(data[start:], castagnoli))
+}
@@ -0,0 +1,133 @@
+package protobuf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"hash/crc32"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding"
+)
+
+func TestChecksums(t *testing.T) {
+	ctx := context.Background()
+	evts := testEvents(50)
+
+	for _, framing := range []Framing{FramingChunked, FramingDelimited} {
+		t.Run(framing.String(), func(t *testing.T) {
+			config := StreamConfig{Framing: framing, ChunkEvents: 8, Checksums: true}
+			data := encodeStream(t, config, evts)
+			assert.Equal(t, appendPreamble(nil, framing), data[:preambleSize])
+			plain := encodeStream(t, StreamConfig{Framing: framing, ChunkEvents: 8}, evts)
+			assert.Greater(t, len(data), len(plain))
+
+			session := NewStreamEncoder(config)
+			var out bytes.Buffer
+			require.NoError(t, session.StartStream(ctx, &out))
+			for _, event := range evts {
+				require.NoError(t, session.WriteEvent(ctx, event))
+			}
+			require.NoError(t, session.EndStream(ctx))
+			multiple, err := NewCodec(config).EncodeMultiple(ctx, evts)
+			require.NoError(t, err)
+			assert.Equal(t, out.Bytes(), multiple)
+
+			// The preamble tells decoders of the framing and checksums
+			for _, config := range []StreamConfig{{}, config} {
+				requireSameEvents(t, evts, decodeStream(t, config, data))
+				requireSameEvents(t, evts, decodeStream(t, config, multiple))
+			}
+		})
+	}
+
+	// Empty streams are a preamble
+	var out bytes.Buffer
+	session := NewStreamEncoder(StreamConfig{Checksums: true})
+	require.NoError(t, session.StartStream(ctx, &out))
+	require.NoError(t, session.EndStream(ctx))
+	assert.Equal(t, appendPreamble(nil, FramingChunked), out.Bytes())
+	assert.Empty(t, decodeStream(t, StreamConfig{}, out.Bytes()))
+}
+
+func TestChecksumErrors(t *testing.T) {
+	ctx := context.Background()
+	evts := testEvents(10)
+	config := StreamConfig{Framing: FramingDelimited, Checksums: true}
+	data, manifest := encodeWithManifest(t, config, evts)
+	require.Equal(t, len(evts)+1, manifest.Len(), "the preamble is a chunk of its own")
+
+	// Flip the last byte of the fourth event
+	info := manifest.Chunks[4]
+	corrupted := bytes.Clone(data)
+	corrupted[info.Offset+int64(info.Length)-checksumSize-1] ^= 0xff
+
+	decoder := NewStreamDecoder(StreamConfig{})
+	require.NoError(t, decoder.StartStream(ctx, bytes.NewReader(corrupted)))
+	assert.Len(t, readEvents(t, decoder, 3), 3)
+	_, err := decoder.ReadEvent(ctx)
+	var checksumErr *ChecksumError
+	require.ErrorAs(t, err, &checksumErr)
+	assert.Equal(t, 4, checksumErr.Chunk)
+	assert.Equal(t, info.Offset, checksumErr.Offset)
+	end := info.Offset + int64(info.Length) - checksumSize
+	assert.Equal(t, crc32.Checksum(data[info.Offset:end], castagnoli), checksumErr.Checksum)
+	assert.Equal(t, crc32.Checksum(corrupted[info.Offset:end], castagnoli), checksumErr.Computed)
+
+	decoder = NewStreamDecoder(StreamConfig{SkipCorruptChunks: true})
+	require.NoError(t, decoder.StartStream(ctx, bytes.NewReader(corrupted)))
+	requireSameEvents(t, append(evts[:3:3], evts[4:]...), readEvents(t, decoder, -1))
+	assert.Equal(t, Progress{Chunks: manifest.Len(), Bytes: int64(len(data)), Events: len(evts) - 1, Skipped: 1}, decoder.Progress())
+
+	decode := func(config StreamConfig, data []byte) error {
+		decoder := NewStreamDecoder(config)
+		require.NoError(t, decoder.StartStream(ctx, bytes.NewReader(data)))
+		for {
+			if _, err := decoder.ReadEvent(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	var decodingErr *encoding.DecodingError
+	assert.Equal(t, io.EOF, decode(StreamConfig{}, data))
+	require.True(t, errors.As(decode(StreamConfig{}, data[:len(data)-2]), &decodingErr))
+	assert.Equal(t, "truncated chunk checksum", decodingErr.Message)
+	require.True(t, errors.As(decode(StreamConfig{}, data[:len(preambleMagic)]), &decodingErr))
+	assert.Equal(t, "truncated stream preamble", decodingErr.Message)
+	require.True(t, errors.As(decode(StreamConfig{Framing: FramingChunked}, data), &decodingErr))
+	assert.Contains(t, decodingErr.Message, "does not match the configured chunked")
+	unknown := bytes.Clone(data)
+	unknown[len(preambleMagic)] |= 0x80
+	require.True(t, errors.As(decode(StreamConfig{}, unknown), &decodingErr))
+	assert.Equal(t, "invalid stream preamble", decodingErr.Message)
+}
+
+func TestChecksumResume(t *testing.T) {
+	ctx := context.Background()
+	evts := testEvents(30)
+	config := StreamConfig{ChunkEvents: 4, Checksums: true}
+	data, manifest := encodeWithManifest(t, config, evts)
+
+	// Sessions resumed past the preamble take the checksums from the config
+	from := 3
+	offset, next := manifest.Offset(from)
+	decoder := NewStreamDecoder(config)
+	require.NoError(t, decoder.Resume(ctx, bytes.NewReader(data[offset:]), manifest, from))
+	requireSameEvents(t, evts[next:], readEvents(t, decoder, -1))
+	assert.Equal(t, manifest, decoder.Manifest())
+
+	// and encoders resumed past it do not write it again
+	sender := NewStreamEncoder(config)
+	var rest bytes.Buffer
+	next, err := sender.Resume(ctx, &rest, manifest, from)
+	require.NoError(t, err)
+	for _, event := range evts[next:] {
+		require.NoError(t, sender.WriteEvent(ctx, event))
+	}
+	require.NoError(t, sender.EndStream(ctx))
+	assert.Equal(t, data[offset:], rest.Bytes())
+}
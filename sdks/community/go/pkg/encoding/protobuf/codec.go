@@ -0,0 +1,264 @@
+// Package protobuf encodes AG-UI events in their protobuf representation
+// (see pkg/proto). Each event is encoded as a pb.RunAgentResponse, which
+// carries the event types missing from events.proto as JSON, so that every
+// event round trips.
+//
+// Streams are sequences of chunks, each made of a header holding the length
+// of the chunk and the number of events in it, as big-endian uint32s,
+// followed by the events, each prefixed with its length as a varint. Chunks
+// are encoded in parallel by StreamEncoder.EncodeStream and written in
+// order. With FramingDelimited, streams leave out the chunk headers, making
+// them the canonical length-delimited streams of protobuf tooling; decoders
+// detect the framing of a stream unless configured. With
+// StreamConfig.Checksums, streams start with a preamble announcing that
+// each chunk, or event of delimited streams, is followed by its CRC-32C,
+// checked before its events are decoded.
+//
+// A Manifest records the offsets and checksums of the chunks of a stream,
+// so that interrupted transfers resume from their last complete chunk, and a
+// Checkpoint the position of a stream, saved periodically to a
+// CheckpointStore, so that long-running jobs encoding or decoding streams
+// restart from it after a crash.
+//
+// PooledDecoder decodes single events into pooled messages and events, for
+// ingestion paths decoding many of them.
+package protobuf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/bufpool"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/proto/pb"
+)
+
+// ContentType is the MIME type of protobuf encoded events
+const ContentType = "application/x-protobuf"
+
+// Codec encodes single events as protobuf messages and several events, or
+// streams of them, as chunks
+type Codec struct {
+	encoder *StreamEncoder
+	decoder *StreamDecoder
+}
+
+// Ensure Codec implements the codec interfaces
+var (
+	_ encoding.Codec       = (*Codec)(nil)
+	_ encoding.StreamCodec = (*Codec)(nil)
+)
+
+// NewCodec creates a protobuf codec streaming with config
+func NewCodec(config StreamConfig) *Codec {
+	return &Codec{
+		encoder: NewStreamEncoder(config),
+		decoder: NewStreamDecoder(config),
+	}
+}
+
+// Encode encodes event as a single pb.RunAgentResponse
+func (c *Codec) Encode(ctx context.Context, event events.Event) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &encoding.EncodingError{Format: "protobuf", Message: "context cancelled", Cause: err}
+	}
+	if event == nil {
+		return nil, &encoding.EncodingError{Format: "protobuf", Message: "cannot encode nil event"}
+	}
+	resp, err := pb.NewRunAgentResponse(event)
+	if err != nil {
+		return nil, &encoding.EncodingError{Format: "protobuf", Event: event, Message: "failed to convert event", Cause: err}
+	}
+	data, err := proto.Marshal(resp)
+	if err != nil {
+		return nil, &encoding.EncodingError{Format: "protobuf", Event: event, Message: "failed to encode event", Cause: err}
+	}
+	return data, nil
+}
+
+// EncodeTo encodes event as a single pb.RunAgentResponse, marshaled into
+// a pooled slice and written to w
+func (c *Codec) EncodeTo(ctx context.Context, w io.Writer, event events.Event) error {
+	if err := ctx.Err(); err != nil {
+		return &encoding.EncodingError{Format: "protobuf", Message: "context cancelled", Cause: err}
+	}
+	if event == nil {
+		return &encoding.EncodingError{Format: "protobuf", Message: "cannot encode nil event"}
+	}
+	resp, err := pb.NewRunAgentResponse(event)
+	if err != nil {
+		return &encoding.EncodingError{Format: "protobuf", Event: event, Message: "failed to convert event", Cause: err}
+	}
+	options := proto.MarshalOptions{UseCachedSize: true}
+	data := bufpool.Default().GetSlice(options.Size(resp))
+	defer func() { bufpool.Default().PutSlice(data) }()
+	if data, err = options.MarshalAppend(data, resp); err != nil {
+		return &encoding.EncodingError{Format: "protobuf", Event: event, Message: "failed to encode event", Cause: err}
+	}
+	if _, err := w.Write(data); err != nil {
+		return &encoding.EncodingError{Format: "protobuf", Event: event, Message: "failed to write event", Cause: err}
+	}
+	return nil
+}
+
+// EncodeMultiple encodes evts as chunks
+func (c *Codec) EncodeMultiple(ctx context.Context, evts []events.Event) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &encoding.EncodingError{Format: "protobuf", Message: "context cancelled", Cause: err}
+	}
+	var data []byte
+	if c.encoder.config.Checksums {
+		data = appendPreamble(data, c.encoder.config.Framing)
+	}
+	for start := 0; start < len(evts); start += c.encoder.config.ChunkEvents {
+		var err error
+		end := min(start+c.encoder.config.ChunkEvents, len(evts))
+		if data, err = appendChunk(data, evts[start:end], c.encoder.config.Framing, c.encoder.config.Checksums); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// Decode decodes a single pb.RunAgentResponse
+func (c *Codec) Decode(ctx context.Context, data []byte) (events.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &encoding.DecodingError{Format: "protobuf", Message: "context cancelled", Cause: err}
+	}
+	return decodeEvent(data)
+}
+
+// DecodeFrom decodes a single pb.RunAgentResponse from all the data of r,
+// read into a pooled buffer. Events longer than the MaxChunkBytes of the
+// codec are rejected.
+func (c *Codec) DecodeFrom(ctx context.Context, r io.Reader) (events.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &encoding.DecodingError{Format: "protobuf", Message: "context cancelled", Cause: err}
+	}
+	return decodeFrom(r, c.decoder.config.MaxChunkBytes, decodeEvent)
+}
+
+// DecodeMultiple decodes the events of the chunks in data
+func (c *Codec) DecodeMultiple(ctx context.Context, data []byte) ([]events.Event, error) {
+	decoder := NewStreamDecoder(c.decoder.config)
+	if err := decoder.StartStream(ctx, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	var result []events.Event
+	for {
+		event, err := decoder.ReadEvent(ctx)
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, event)
+	}
+}
+
+// ContentType returns the MIME type of protobuf encoded events
+func (c *Codec) ContentType() string {
+	return ContentType
+}
+
+// SupportsStreaming reports that the codec streams events
+func (c *Codec) SupportsStreaming() bool {
+	return true
+}
+
+// EncodeStream encodes the events of input to output, see
+// StreamEncoder.EncodeStream
+func (c *Codec) EncodeStream(ctx context.Context, input <-chan events.Event, output io.Writer) error {
+	return c.encoder.EncodeStream(ctx, input, output)
+}
+
+// DecodeStream decodes the events of input to output, see
+// StreamDecoder.DecodeStream
+func (c *Codec) DecodeStream(ctx context.Context, input io.Reader, output chan<- events.Event) error {
+	return c.decoder.DecodeStream(ctx, input, output)
+}
+
+// StartEncoding starts an encoding session writing to w
+func (c *Codec) StartEncoding(ctx context.Context, w io.Writer) error {
+	return c.encoder.StartStream(ctx, w)
+}
+
+// WriteEvent adds an event to the encoding session
+func (c *Codec) WriteEvent(ctx context.Context, event events.Event) error {
+	return c.encoder.WriteEvent(ctx, event)
+}
+
+// EndEncoding writes the events left and ends the encoding session
+func (c *Codec) EndEncoding(ctx context.Context) error {
+	return c.encoder.EndStream(ctx)
+}
+
+// StartDecoding starts a decoding session reading from r
+func (c *Codec) StartDecoding(ctx context.Context, r io.Reader) error {
+	return c.decoder.StartStream(ctx, r)
+}
+
+// ReadEvent reads the next event of the decoding session
+func (c *Codec) ReadEvent(ctx context.Context) (events.Event, error) {
+	return c.decoder.ReadEvent(ctx)
+}
+
+// EndDecoding ends the decoding session
+func (c *Codec) EndDecoding(ctx context.Context) error {
+	return c.decoder.EndStream(ctx)
+}
+
+// GetStreamEncoder returns the stream encoder of the codec
+func (c *Codec) GetStreamEncoder() encoding.StreamEncoder {
+	return c.encoder
+}
+
+// GetStreamDecoder returns the stream decoder of the codec
+func (c *Codec) GetStreamDecoder() encoding.StreamDecoder {
+	return c.decoder
+}
+
+// decodeEvent decodes a single pb.RunAgentResponse
+func decodeEvent(data []byte) (events.Event, error) {
+	var resp pb.RunAgentResponse
+	if err := proto.Unmarshal(data, &resp); err != nil {
+		return nil, &encoding.DecodingError{Format: "protobuf", Data: data, Message: "failed to decode event", Cause: err}
+	}
+	event, err := pb.ResponseEvent(&resp)
+	if err != nil {
+		return nil, &encoding.DecodingError{Format: "protobuf", Data: data, Message: "failed to convert event", Cause: err}
+	}
+	return event, nil
+}
+
+// decodeFrom decodes with decode a single event from all the data of r, read
+// into a pooled buffer. Events longer than limit are rejected.
+func decodeFrom(r io.Reader, limit int, decode func([]byte) (events.Event, error)) (events.Event, error) {
+	buf := bufpool.Default().GetBuffer(bytes.MinRead)
+	defer bufpool.Default().PutBuffer(buf)
+
+	// Read one byte past the limit to tell when it is exceeded
+	if _, err := buf.ReadFrom(io.LimitReader(r, int64(limit)+1)); err != nil {
+		return nil, &encoding.DecodingError{Format: "protobuf", Message: "failed to read event", Cause: err}
+	}
+	if buf.Len() > limit {
+		return nil, &encoding.DecodingError{Format: "protobuf", Message: fmt.Sprintf("event exceeds the limit of %d bytes", limit)}
+	}
+	event, err := decode(buf.Bytes())
+	if err != nil {
+		// The data of the error must outlive the buffer
+		var decodingErr *encoding.DecodingError
+		if errors.As(err, &decodingErr) {
+			decodingErr.Data = bytes.Clone(decodingErr.Data)
+		}
+		return nil, err
+	}
+	return event, nil
+}
@@ -0,0 +1,76 @@
+package protobuf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding"
+)
+
+func TestCodecEncodeToDecodeFrom(t *testing.T) {
+	ctx := context.Background()
+	codec := NewCodec(StreamConfig{})
+	for i, event := range testEvents(4) {
+		var buf bytes.Buffer
+		require.NoError(t, codec.EncodeTo(ctx, &buf, event))
+		data, err := codec.Encode(ctx, event)
+		require.NoError(t, err)
+		assert.Equal(t, data, buf.Bytes(), "event %d", i)
+
+		decoded, err := codec.DecodeFrom(ctx, &buf)
+		require.NoError(t, err)
+		requireSameEvents(t, []events.Event{event}, []events.Event{decoded})
+	}
+}
+
+func TestCodecEncodeToErrors(t *testing.T) {
+	ctx := context.Background()
+	codec := NewCodec(StreamConfig{})
+	event := events.NewTextMessageContentEvent("msg-1", "Hello")
+
+	var encodingErr *encoding.EncodingError
+	require.ErrorAs(t, codec.EncodeTo(ctx, &bytes.Buffer{}, nil), &encodingErr)
+
+	err := codec.EncodeTo(ctx, failingWriter{}, event)
+	require.ErrorAs(t, err, &encodingErr)
+	assert.Equal(t, "failed to write event", encodingErr.Message)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, codec.EncodeTo(cancelled, &bytes.Buffer{}, event), context.Canceled)
+}
+
+func TestCodecDecodeFromErrors(t *testing.T) {
+	ctx := context.Background()
+	event := events.NewTextMessageContentEvent("msg-1", "Hello")
+	data, err := NewCodec(StreamConfig{}).Encode(ctx, event)
+	require.NoError(t, err)
+
+	var decodingErr *encoding.DecodingError
+	_, err = NewCodec(StreamConfig{MaxChunkBytes: len(data) - 1}).DecodeFrom(ctx, bytes.NewReader(data))
+	require.ErrorAs(t, err, &decodingErr)
+	assert.Contains(t, decodingErr.Message, "exceeds the limit")
+
+	garbage := []byte{0xff, 0xff, 0xff}
+	_, err = NewCodec(StreamConfig{}).DecodeFrom(ctx, bytes.NewReader(garbage))
+	require.ErrorAs(t, err, &decodingErr)
+	assert.Equal(t, garbage, decodingErr.Data, "the data outlives the pooled buffer")
+
+	_, err = NewCodec(StreamConfig{}).DecodeFrom(ctx, errReader{})
+	assert.ErrorIs(t, err, errRead)
+}
+
+var errRead = errors.New("read failed")
+
+// errReader fails every read
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errRead
+}
@@ -0,0 +1,103 @@
+package protobuf
+
+import (
+	"bufio"
+	"encoding/binary"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Framing is the framing of the events of a stream
+type Framing int
+
+const (
+	// FramingAuto encodes chunked streams and detects the framing of the
+	// streams decoded from their first bytes
+	FramingAuto Framing = iota
+
+	// FramingChunked groups events into chunks, each with a header holding
+	// its length and number of events
+	FramingChunked
+
+	// FramingDelimited prefixes each event with its length as a varint and
+	// nothing else, the canonical length-delimited format of protobuf
+	// tooling (see protodelim), so that other SDKs read and write the
+	// streams. Manifests and checkpoints then count each event as a chunk.
+	FramingDelimited
+)
+
+// String returns the name of the framing
+func (f Framing) String() string {
+	switch f {
+	case FramingAuto:
+		return "auto"
+	case FramingChunked:
+		return "chunked"
+	case FramingDelimited:
+		return "delimited"
+	}
+	return "unknown"
+}
+
+// The keys of the fields of pb.RunAgentResponse, one of which starts every
+// encoded event
+var (
+	eventKey     = byte(protowire.EncodeTag(1, protowire.BytesType))
+	jsonEventKey = byte(protowire.EncodeTag(2, protowire.BytesType))
+)
+
+// startsWithEvent reports whether data starts like a length-delimited
+// pb.RunAgentResponse: a non-zero length followed by the key of a field
+func startsWithEvent(data []byte) bool {
+	size, n := protowire.ConsumeVarint(data)
+	if n <= 0 || size == 0 || n >= len(data) {
+		return false
+	}
+	return data[n] == eventKey || data[n] == jsonEventKey
+}
+
+// detectFraming tells the framing of a stream from its first bytes, peeking
+// only at bytes the stream is bound to have: a chunk header in bounds,
+// followed by the start of an event unless the chunk has none, makes a
+// chunked stream, the start of an event a delimited one. Streams matching
+// neither are read as chunked, to fail as such.
+func detectFraming(r *bufio.Reader, maxChunkBytes int) Framing {
+	head, _ := r.Peek(chunkHeaderSize)
+	if len(head) == chunkHeaderSize {
+		length := binary.BigEndian.Uint32(head)
+		count := binary.BigEndian.Uint32(head[4:])
+		if int64(length) <= int64(maxChunkBytes) {
+			if count == 0 {
+				return FramingChunked
+			}
+			// The start of an event: its length and the key of a field
+			head, _ = r.Peek(chunkHeaderSize + int(min(length, binary.MaxVarintLen64+1)))
+			if startsWithEvent(head[chunkHeaderSize:]) {
+				return FramingChunked
+			}
+		}
+	}
+	if startsWithEvent(head) {
+		return FramingDelimited
+	}
+	return FramingChunked
+}
+
+// frames calls fn with each frame of data, the encoding of n events with
+// framing and checksums: the chunk itself, or each event of delimited
+// streams, or the preamble of the stream when n is 0
+func frames(framing Framing, checksums bool, data []byte, n int, fn func(frame []byte, events int)) {
+	if framing != FramingDelimited || n == 0 {
+		fn(data, n)
+		return
+	}
+	for len(data) > 0 {
+		size, prefix := protowire.ConsumeVarint(data)
+		end := prefix + int(size)
+		if checksums {
+			end += checksumSize
+		}
+		fn(data[:end], 1)
+		data = data[end:]
+	}
+}
@@ -0,0 +1,146 @@
+package protobuf
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protodelim"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/proto/pb"
+)
+
+func TestDelimitedFraming(t *testing.T) {
+	ctx := context.Background()
+	evts := testEvents(100)
+	delimited := StreamConfig{Framing: FramingDelimited, ChunkEvents: 7}
+	data := encodeStream(t, delimited, evts)
+
+	// The streams are those of protodelim
+	r := bufio.NewReader(bytes.NewReader(data))
+	var protodelimited bytes.Buffer
+	for range evts {
+		var resp pb.RunAgentResponse
+		require.NoError(t, protodelim.UnmarshalFrom(r, &resp))
+		_, err := protodelim.MarshalTo(&protodelimited, &resp)
+		require.NoError(t, err)
+	}
+	_, err := r.ReadByte()
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, data, protodelimited.Bytes())
+
+	session := NewStreamEncoder(delimited)
+	var out bytes.Buffer
+	require.NoError(t, session.StartStream(ctx, &out))
+	for _, event := range evts {
+		require.NoError(t, session.WriteEvent(ctx, event))
+	}
+	require.NoError(t, session.EndStream(ctx))
+	assert.Equal(t, data, out.Bytes())
+
+	multiple, err := NewCodec(delimited).EncodeMultiple(ctx, evts)
+	require.NoError(t, err)
+	assert.Equal(t, data, multiple)
+
+	for _, config := range []StreamConfig{delimited, {}} {
+		t.Run("decode "+config.Framing.String(), func(t *testing.T) {
+			requireSameEvents(t, evts, decodeStream(t, config, data))
+		})
+	}
+}
+
+func TestFramingDetection(t *testing.T) {
+	ctx := context.Background()
+	evts := testEvents(20)
+	emptyChunk, err := appendChunk(nil, nil, FramingChunked, false)
+	require.NoError(t, err)
+
+	for name, test := range map[string]struct {
+		data []byte
+		want Framing
+	}{
+		"chunked":     {encodeStream(t, StreamConfig{}, evts), FramingChunked},
+		"delimited":   {encodeStream(t, StreamConfig{Framing: FramingDelimited}, evts), FramingDelimited},
+		"empty chunk": {append(emptyChunk, encodeStream(t, StreamConfig{}, evts)...), FramingChunked},
+		"garbage":     {[]byte("not a stream at all"), FramingChunked},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, detectFraming(bufio.NewReader(bytes.NewReader(test.data)), defaultMaxChunkBytes))
+		})
+	}
+
+	// A single event is detected without waiting for more
+	single := encodeStream(t, StreamConfig{Framing: FramingDelimited}, evts[:1])
+	r, w := io.Pipe()
+	go func() { _, _ = w.Write(single) }()
+	decoder := NewStreamDecoder(StreamConfig{})
+	require.NoError(t, decoder.StartStream(ctx, r))
+	event, err := decoder.ReadEvent(ctx)
+	require.NoError(t, err)
+	requireSameEvents(t, evts[:1], []events.Event{event})
+	_ = w.Close()
+}
+
+func TestDelimitedManifest(t *testing.T) {
+	ctx := context.Background()
+	evts := testEvents(30)
+	config := StreamConfig{Framing: FramingDelimited, ChunkEvents: 10}
+	data, manifest := encodeWithManifest(t, config, evts)
+
+	// Each event is a chunk of its own
+	require.Equal(t, len(evts), manifest.Len())
+	end, total := manifest.Offset(manifest.Len())
+	assert.Equal(t, int64(len(data)), end)
+	assert.Equal(t, len(evts), total)
+
+	receiver := NewStreamDecoder(StreamConfig{RecordManifest: true})
+	require.NoError(t, receiver.StartStream(ctx, bytes.NewReader(data)))
+	received := readEvents(t, receiver, 12)
+	assert.Equal(t, Progress{Chunks: 12, Bytes: manifest.Chunks[12].Offset, Events: 12}, receiver.Progress())
+
+	sender := NewStreamEncoder(config)
+	var rest bytes.Buffer
+	next, err := sender.Resume(ctx, &rest, manifest, receiver.Manifest().Len())
+	require.NoError(t, err)
+	assert.Equal(t, 12, next)
+	for _, event := range evts[next:] {
+		require.NoError(t, sender.WriteEvent(ctx, event))
+	}
+	require.NoError(t, sender.EndStream(ctx))
+	assert.Equal(t, manifest, sender.Manifest())
+
+	require.NoError(t, receiver.Resume(ctx, &rest, manifest, next))
+	requireSameEvents(t, evts, append(received, readEvents(t, receiver, -1)...))
+	assert.Equal(t, manifest, receiver.Manifest())
+}
+
+func TestDelimitedDecodeErrors(t *testing.T) {
+	ctx := context.Background()
+	data := encodeStream(t, StreamConfig{Framing: FramingDelimited}, testEvents(2))
+	decode := func(config StreamConfig, data []byte) error {
+		config.Framing = FramingDelimited
+		decoder := NewStreamDecoder(config)
+		require.NoError(t, decoder.StartStream(ctx, bytes.NewReader(data)))
+		for {
+			if _, err := decoder.ReadEvent(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	var decodingErr *encoding.DecodingError
+
+	assert.Equal(t, io.EOF, decode(StreamConfig{}, data))
+	require.True(t, errors.As(decode(StreamConfig{}, data[:len(data)-1]), &decodingErr))
+	assert.Equal(t, "truncated event", decodingErr.Message)
+	require.True(t, errors.As(decode(StreamConfig{}, append(bytes.Clone(data), 0x80)), &decodingErr))
+	assert.Equal(t, "truncated event length", decodingErr.Message)
+	require.True(t, errors.As(decode(StreamConfig{MaxChunkBytes: 4}, data), &decodingErr))
+	assert.Contains(t, decodingErr.Message, "exceeds the limit of 4")
+}
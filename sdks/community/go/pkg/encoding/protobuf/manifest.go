@@ -0,0 +1,102 @@
+package protobuf
+
+import (
+	"fmt"
+	"hash/crc32"
+	"slices"
+)
+
+// castagnoli computes the checksums of chunks
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// ChunkInfo describes a chunk of a stream
+type ChunkInfo struct {
+	// Offset is the position of the chunk in the stream, in bytes
+	Offset int64 `json:"offset"`
+
+	// Length is the size of the chunk, header included, in bytes
+	Length int `json:"length"`
+
+	// Events is the number of events of the chunk
+	Events int `json:"events"`
+
+	// Checksum is the CRC-32C of the chunk, header included
+	Checksum uint32 `json:"checksum"`
+}
+
+// Manifest describes the chunks of a stream, so that the progress of its
+// transfer can be tracked and an interrupted transfer resumed from its last
+// complete chunk (see StreamEncoder.Resume and StreamDecoder.Resume). It
+// serializes to JSON.
+type Manifest struct {
+	Chunks []ChunkInfo `json:"chunks"`
+}
+
+// Len returns the number of chunks of the stream
+func (m *Manifest) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.Chunks)
+}
+
+// Offset returns the position, in bytes and in events, of chunk in the
+// stream. The end of the stream is at chunk m.Len().
+func (m *Manifest) Offset(chunk int) (bytes int64, events int) {
+	if m == nil || chunk <= 0 {
+		return 0, 0
+	}
+	for _, info := range m.Chunks[:min(chunk, len(m.Chunks))] {
+		events += info.Events
+	}
+	last := m.Chunks[min(chunk, len(m.Chunks))-1]
+	return last.Offset + int64(last.Length), events
+}
+
+// Verify checks that data, the chunk of index chunk, matches the manifest.
+// Chunks beyond the manifest are not checked.
+func (m *Manifest) Verify(chunk int, data []byte) error {
+	return m.check(chunk, len(data), crc32.Checksum(data, castagnoli))
+}
+
+// Clone returns a copy of the first chunks of the manifest
+func (m *Manifest) Clone(chunks int) *Manifest {
+	if m == nil {
+		return &Manifest{}
+	}
+	return &Manifest{Chunks: slices.Clone(m.Chunks[:max(0, min(chunks, len(m.Chunks)))])}
+}
+
+// check checks the length and checksum of the chunk of index chunk
+func (m *Manifest) check(chunk, length int, checksum uint32) error {
+	if chunk >= m.Len() {
+		return nil
+	}
+	info := m.Chunks[chunk]
+	if length != info.Length {
+		return fmt.Errorf("chunk %d is %d bytes, the manifest says %d", chunk, length, info.Length)
+	}
+	if checksum != info.Checksum {
+		return fmt.Errorf("chunk %d checksum %08x does not match the manifest checksum %08x", chunk, checksum, info.Checksum)
+	}
+	return nil
+}
+
+// add records a chunk of length bytes and evts events as the next chunk of
+// the stream
+func (m *Manifest) add(length, evts int, checksum uint32) {
+	var offset int64
+	if n := len(m.Chunks); n > 0 {
+		offset = m.Chunks[n-1].Offset + int64(m.Chunks[n-1].Length)
+	}
+	m.Chunks = append(m.Chunks, ChunkInfo{Offset: offset, Length: length, Events: evts, Checksum: checksum})
+}
+
+// checkResume checks that a stream described by manifest can resume from
+// chunk
+func checkResume(manifest *Manifest, chunk int) error {
+	if chunk < 0 || chunk > manifest.Len() {
+		return fmt.Errorf("cannot resume from chunk %d of a manifest of %d chunks", chunk, manifest.Len())
+	}
+	return nil
+}
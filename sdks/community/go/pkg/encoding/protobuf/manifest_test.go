@@ -0,0 +1,146 @@
+package protobuf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding"
+)
+
+// encodeWithManifest encodes evts with EncodeStreamWithManifest
+func encodeWithManifest(t *testing.T, config StreamConfig, evts []events.Event) ([]byte, *Manifest) {
+	t.Helper()
+	input := make(chan events.Event, len(evts))
+	for _, event := range evts {
+		input <- event
+	}
+	close(input)
+	var out bytes.Buffer
+	manifest := &Manifest{}
+	require.NoError(t, NewStreamEncoder(config).EncodeStreamWithManifest(context.Background(), input, &out, manifest))
+	return out.Bytes(), manifest
+}
+
+// readEvents reads up to n events of the decoding session of decoder, all
+// of them if n < 0
+func readEvents(t *testing.T, decoder *StreamDecoder, n int) []events.Event {
+	t.Helper()
+	var evts []events.Event
+	for n < 0 || len(evts) < n {
+		event, err := decoder.ReadEvent(context.Background())
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		evts = append(evts, event)
+	}
+	return evts
+}
+
+func TestManifest(t *testing.T) {
+	evts := testEvents(100)
+	data, manifest := encodeWithManifest(t, StreamConfig{ChunkEvents: 10, Workers: 3}, evts)
+
+	end, total := manifest.Offset(manifest.Len())
+	assert.Equal(t, int64(len(data)), end)
+	assert.Equal(t, len(evts), total)
+	for i, info := range manifest.Chunks {
+		require.NoError(t, manifest.Verify(i, data[info.Offset:info.Offset+int64(info.Length)]))
+	}
+	assert.Error(t, manifest.Verify(0, data[:manifest.Chunks[0].Length-1]))
+
+	// The session encoder and the decoder record the same manifest
+	ctx := context.Background()
+	encoder := NewStreamEncoder(StreamConfig{ChunkEvents: 10, RecordManifest: true})
+	var out bytes.Buffer
+	require.NoError(t, encoder.StartStream(ctx, &out))
+	for _, event := range evts {
+		require.NoError(t, encoder.WriteEvent(ctx, event))
+	}
+	require.NoError(t, encoder.EndStream(ctx))
+	assert.Equal(t, manifest, encoder.Manifest())
+
+	decoder := NewStreamDecoder(StreamConfig{RecordManifest: true})
+	require.NoError(t, decoder.StartStream(ctx, &out))
+	requireSameEvents(t, evts, readEvents(t, decoder, -1))
+	assert.Equal(t, manifest, decoder.Manifest())
+	assert.Nil(t, NewStreamDecoder(StreamConfig{}).Manifest())
+
+	encoded, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	var decoded Manifest
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, manifest, &decoded)
+}
+
+func TestResume(t *testing.T) {
+	ctx := context.Background()
+	evts := testEvents(100)
+	data, manifest := encodeWithManifest(t, StreamConfig{ChunkEvents: 10}, evts)
+
+	// The transfer is interrupted in the middle of the fourth chunk
+	receiver := NewStreamDecoder(StreamConfig{RecordManifest: true})
+	require.NoError(t, receiver.StartStream(ctx, bytes.NewReader(data)))
+	received := readEvents(t, receiver, 35)
+	require.Len(t, received, 35)
+	progress := receiver.Progress()
+	assert.Equal(t, 3, progress.Chunks)
+	assert.Equal(t, 35, progress.Events)
+	assert.Equal(t, 3, receiver.Manifest().Len())
+
+	// The sender resumes from the last complete chunk
+	fromChunk := receiver.Manifest().Len()
+	sender := NewStreamEncoder(StreamConfig{ChunkEvents: 10})
+	var rest bytes.Buffer
+	next, err := sender.Resume(ctx, &rest, manifest, fromChunk)
+	require.NoError(t, err)
+	assert.Equal(t, 30, next)
+	for _, event := range evts[next:] {
+		require.NoError(t, sender.WriteEvent(ctx, event))
+	}
+	require.NoError(t, sender.EndStream(ctx))
+	assert.Equal(t, manifest, sender.Manifest())
+	offset, _ := manifest.Offset(fromChunk)
+	assert.Equal(t, data[offset:], rest.Bytes())
+
+	require.NoError(t, receiver.Resume(ctx, &rest, manifest, fromChunk))
+	progress = receiver.Progress()
+	assert.Equal(t, Progress{Chunks: 3, Bytes: offset, Events: 30, TotalChunks: 10, TotalBytes: int64(len(data)), TotalEvents: 100}, progress)
+	requireSameEvents(t, evts, append(received[:30], readEvents(t, receiver, -1)...))
+	assert.Equal(t, manifest, receiver.Manifest())
+	assert.Equal(t, 10, receiver.Progress().Chunks)
+	assert.Equal(t, int64(len(data)), receiver.Progress().Bytes)
+}
+
+func TestResumeErrors(t *testing.T) {
+	ctx := context.Background()
+	data, manifest := encodeWithManifest(t, StreamConfig{ChunkEvents: 10}, testEvents(100))
+
+	_, err := NewStreamEncoder(StreamConfig{}).Resume(ctx, io.Discard, manifest, 11)
+	assert.Error(t, err)
+	assert.Error(t, NewStreamDecoder(StreamConfig{}).Resume(ctx, bytes.NewReader(data), manifest, -1))
+
+	// Corrupt the fifth chunk
+	corrupt := bytes.Clone(data)
+	corrupt[manifest.Chunks[4].Offset+chunkHeaderSize+1] ^= 0xff
+	offset, _ := manifest.Offset(3)
+	decoder := NewStreamDecoder(StreamConfig{})
+	require.NoError(t, decoder.Resume(ctx, bytes.NewReader(corrupt[offset:]), manifest, 3))
+	for range 10 {
+		_, err = decoder.ReadEvent(ctx)
+		require.NoError(t, err)
+	}
+	_, err = decoder.ReadEvent(ctx)
+	var decErr *encoding.DecodingError
+	require.True(t, errors.As(err, &decErr))
+	assert.Equal(t, "chunk does not match the manifest", decErr.Message)
+	assert.Contains(t, err.Error(), "chunk 4 checksum")
+}
@@ -0,0 +1,190 @@
+package protobuf
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/proto/pb"
+)
+
+// The numbers of the fields leading to the pooled events: the event of a
+// pb.RunAgentResponse, and its text message or tool call delta
+var (
+	responseEventField      = fieldNumber(&pb.RunAgentResponse{}, "event")
+	textMessageContentField = fieldNumber(&pb.Event{}, "text_message_content")
+	toolCallArgsField       = fieldNumber(&pb.Event{}, "tool_call_args")
+)
+
+func fieldNumber(m proto.Message, name protoreflect.Name) protowire.Number {
+	return m.ProtoReflect().Descriptor().Fields().ByName(name).Number()
+}
+
+// PooledDecoder decodes single events like Codec.Decode, for ingestion paths
+// decoding many of them. The text message and tool call deltas making up
+// most streams are decoded into pooled protobuf messages and, once released
+// with Release, converted to pooled events, so that decoding them allocates
+// little more than their strings. Other events are decoded like
+// Codec.Decode. It is safe for concurrent use.
+type PooledDecoder struct {
+	maxEventBytes int
+
+	// The protobuf messages, and the events they are converted to
+	contentMessages sync.Pool
+	argsMessages    sync.Pool
+	contents        sync.Pool
+	args            sync.Pool
+}
+
+// NewPooledDecoder creates a pooled decoder, rejecting the events longer
+// than the MaxChunkBytes of config
+func NewPooledDecoder(config StreamConfig) *PooledDecoder {
+	return &PooledDecoder{
+		maxEventBytes: config.withDefaults().MaxChunkBytes,
+		contentMessages: sync.Pool{New: func() any {
+			return &pb.TextMessageContentEvent{BaseEvent: new(pb.BaseEvent)}
+		}},
+		argsMessages: sync.Pool{New: func() any {
+			return &pb.ToolCallArgsEvent{BaseEvent: new(pb.BaseEvent)}
+		}},
+		contents: sync.Pool{New: func() any {
+			return &events.TextMessageContentEvent{BaseEvent: new(events.BaseEvent)}
+		}},
+		args: sync.Pool{New: func() any {
+			return &events.ToolCallArgsEvent{BaseEvent: new(events.BaseEvent)}
+		}},
+	}
+}
+
+// Decode decodes a single pb.RunAgentResponse
+func (d *PooledDecoder) Decode(ctx context.Context, data []byte) (events.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &encoding.DecodingError{Format: "protobuf", Message: "context cancelled", Cause: err}
+	}
+	return d.decode(data)
+}
+
+// DecodeFrom decodes a single pb.RunAgentResponse from all the data of r,
+// read into a pooled buffer
+func (d *PooledDecoder) DecodeFrom(ctx context.Context, r io.Reader) (events.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &encoding.DecodingError{Format: "protobuf", Message: "context cancelled", Cause: err}
+	}
+	return decodeFrom(r, d.maxEventBytes, d.decode)
+}
+
+// Release returns an event decoded by the decoder to it once it is no
+// longer referenced. Neither the event nor its BaseEvent may be used after.
+// Events of other types than the pooled ones are left to the garbage
+// collector.
+func (d *PooledDecoder) Release(event events.Event) {
+	switch e := event.(type) {
+	case *events.TextMessageContentEvent:
+		if e.BaseEvent != nil {
+			e.MessageID, e.Delta = "", ""
+			*e.BaseEvent = events.BaseEvent{TimestampMs: e.TimestampMs}
+			d.contents.Put(e)
+		}
+	case *events.ToolCallArgsEvent:
+		if e.BaseEvent != nil {
+			e.ToolCallID, e.Delta = "", ""
+			*e.BaseEvent = events.BaseEvent{TimestampMs: e.TimestampMs}
+			d.args.Put(e)
+		}
+	}
+}
+
+// decode decodes the deltas of data into pooled events, and the other
+// events, those with raw events and the invalid ones like decodeEvent
+func (d *PooledDecoder) decode(data []byte) (events.Event, error) {
+	field, value := eventCase(data)
+	switch field {
+	case textMessageContentField:
+		if event := d.decodeContent(value); event != nil {
+			return event, nil
+		}
+	case toolCallArgsField:
+		if event := d.decodeArgs(value); event != nil {
+			return event, nil
+		}
+	}
+	return decodeEvent(data)
+}
+
+func (d *PooledDecoder) decodeContent(data []byte) events.Event {
+	msg := d.contentMessages.Get().(*pb.TextMessageContentEvent)
+	defer d.contentMessages.Put(msg)
+	msg.MessageId, msg.Delta = "", ""
+	resetBase(msg.BaseEvent)
+	msg.ProtoReflect().SetUnknown(nil)
+	if err := (proto.UnmarshalOptions{Merge: true}).Unmarshal(data, msg); err != nil || msg.BaseEvent.RawEvent != nil {
+		return nil
+	}
+	event := d.contents.Get().(*events.TextMessageContentEvent)
+	event.MessageID, event.Delta = msg.MessageId, msg.Delta
+	setBase(event.BaseEvent, events.EventTypeTextMessageContent, msg.BaseEvent)
+	return event
+}
+
+func (d *PooledDecoder) decodeArgs(data []byte) events.Event {
+	msg := d.argsMessages.Get().(*pb.ToolCallArgsEvent)
+	defer d.argsMessages.Put(msg)
+	msg.ToolCallId, msg.Delta = "", ""
+	resetBase(msg.BaseEvent)
+	msg.ProtoReflect().SetUnknown(nil)
+	if err := (proto.UnmarshalOptions{Merge: true}).Unmarshal(data, msg); err != nil || msg.BaseEvent.RawEvent != nil {
+		return nil
+	}
+	event := d.args.Get().(*events.ToolCallArgsEvent)
+	event.ToolCallID, event.Delta = msg.ToolCallId, msg.Delta
+	setBase(event.BaseEvent, events.EventTypeToolCallArgs, msg.BaseEvent)
+	return event
+}
+
+// eventCase returns the number and the data of the case of the event held
+// by the pb.RunAgentResponse in data, or 0 for responses holding anything
+// else
+func eventCase(data []byte) (protowire.Number, []byte) {
+	for _, field := range []protowire.Number{responseEventField, 0} {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 || typ != protowire.BytesType || field != 0 && num != field {
+			return 0, nil
+		}
+		value, m := protowire.ConsumeBytes(data[n:])
+		if m < 0 || n+m != len(data) {
+			return 0, nil
+		}
+		if field == 0 {
+			return num, value
+		}
+		data = value
+	}
+	return 0, nil
+}
+
+// resetBase clears the fields of a pooled base event for it to be merged
+// into
+func resetBase(base *pb.BaseEvent) {
+	base.Type, base.Timestamp, base.RawEvent = 0, nil, nil
+	base.ProtoReflect().SetUnknown(nil)
+}
+
+// setBase sets the fields shared by pooled events, reusing the timestamp
+// allocated by a previous use of base
+func setBase(base *events.BaseEvent, eventType events.EventType, pbBase *pb.BaseEvent) {
+	timestamp := base.TimestampMs
+	*base = events.BaseEvent{EventType: eventType}
+	if pbBase.Timestamp != nil {
+		if timestamp == nil {
+			timestamp = new(int64)
+		}
+		*timestamp = pbBase.GetTimestamp()
+		base.TimestampMs = timestamp
+	}
+}
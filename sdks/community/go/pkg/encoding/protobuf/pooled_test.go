@@ -0,0 +1,145 @@
+package protobuf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding"
+)
+
+// deltaEvents returns n events alternating between the ones whose fields
+// are set and left out, so that decoding them in turn into reused messages
+// shows stale fields
+func deltaEvents(n int) []events.Event {
+	evts := make([]events.Event, 0, n)
+	for i := range n {
+		switch i % 6 {
+		case 0:
+			evts = append(evts, events.NewTextMessageStartEvent(fmt.Sprintf("msg-%d", i), events.WithRole("assistant")))
+		case 1:
+			evts = append(evts, events.NewTextMessageStartEvent(fmt.Sprintf("msg-%d", i)))
+		case 2:
+			evts = append(evts, events.NewTextMessageContentEvent(fmt.Sprintf("msg-%d", i), "Hello, world"))
+		case 3:
+			event := events.NewTextMessageContentEvent(fmt.Sprintf("msg-%d", i), "")
+			event.TimestampMs = nil
+			evts = append(evts, event)
+		case 4:
+			event := events.NewToolCallArgsEvent("tool-1", `{"city":`)
+			event.RawEvent = map[string]any{"source": "upstream"}
+			evts = append(evts, event)
+		default:
+			evts = append(evts, events.NewToolCallArgsEvent("tool-1", `"Paris"}`))
+		}
+	}
+	return evts
+}
+
+func TestPooledDecoder(t *testing.T) {
+	ctx := context.Background()
+	codec := NewCodec(StreamConfig{})
+	evts := append(deltaEvents(60), testEvents(20)...)
+	var encoded [][]byte
+	for _, event := range evts {
+		data, err := codec.Encode(ctx, event)
+		require.NoError(t, err)
+		encoded = append(encoded, data)
+	}
+
+	decoder := NewPooledDecoder(StreamConfig{})
+	for i, data := range encoded {
+		want, err := codec.Decode(ctx, data)
+		require.NoError(t, err)
+		got, err := decoder.Decode(ctx, data)
+		require.NoError(t, err)
+		requireSameEvents(t, []events.Event{want}, []events.Event{got})
+		assert.Equal(t, want.GetBaseEvent(), got.GetBaseEvent(), "event %d", i)
+		decoder.Release(got)
+	}
+
+	// Events are decoded and released concurrently
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, data := range encoded {
+				got, err := decoder.DecodeFrom(ctx, bytes.NewReader(data))
+				if assert.NoError(t, err) {
+					assert.Equal(t, evts[i].Type(), got.Type())
+					decoder.Release(got)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPooledDecoderErrors(t *testing.T) {
+	ctx := context.Background()
+	decoder := NewPooledDecoder(StreamConfig{MaxChunkBytes: 16})
+	data, err := NewCodec(StreamConfig{}).Encode(ctx, events.NewTextMessageContentEvent("msg-1", "Hello, world"))
+	require.NoError(t, err)
+
+	var decodingErr *encoding.DecodingError
+	_, err = decoder.Decode(ctx, []byte{0xff})
+	require.ErrorAs(t, err, &decodingErr)
+	assert.Equal(t, "failed to decode event", decodingErr.Message)
+	_, err = decoder.DecodeFrom(ctx, bytes.NewReader(data))
+	require.ErrorAs(t, err, &decodingErr)
+	assert.Equal(t, "event exceeds the limit of 16 bytes", decodingErr.Message)
+
+	// Failed decodes leave nothing behind for the next ones
+	event, err := decoder.Decode(ctx, data)
+	require.NoError(t, err)
+	requireSameEvents(t, []events.Event{events.NewTextMessageContentEvent("msg-1", "Hello, world")}, []events.Event{event})
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = decoder.Decode(cancelled, data)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func BenchmarkDecode(b *testing.B) {
+	ctx := context.Background()
+	codec := NewCodec(StreamConfig{})
+	var encoded [][]byte
+	for i := range 1024 {
+		var event events.Event = events.NewTextMessageContentEvent("msg-1", fmt.Sprintf("token %d", i))
+		if i%2 == 1 {
+			event = events.NewToolCallArgsEvent("tool-1", fmt.Sprintf(`"arg %d"`, i))
+		}
+		data, err := codec.Encode(ctx, event)
+		if err != nil {
+			b.Fatal(err)
+		}
+		encoded = append(encoded, data)
+	}
+
+	b.Run("codec", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; b.Loop(); i++ {
+			if _, err := codec.Decode(ctx, encoded[i%len(encoded)]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("pooled", func(b *testing.B) {
+		decoder := NewPooledDecoder(StreamConfig{})
+		b.ReportAllocs()
+		for i := 0; b.Loop(); i++ {
+			event, err := decoder.Decode(ctx, encoded[i%len(encoded)])
+			if err != nil {
+				b.Fatal(err)
+			}
+			decoder.Release(event)
+		}
+	})
+}
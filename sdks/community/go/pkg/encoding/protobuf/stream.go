@@ -0,0 +1,784 @@
+package protobuf
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"runtime"
+	"slices"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/bufpool"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/proto/pb"
+)
+
+const (
+	// chunkHeaderSize is the size of the header of a chunk: its length
+	// and its number of events
+	chunkHeaderSize = 8
+
+	// defaultChunkEvents bounds the events of a chunk unless configured
+	defaultChunkEvents = 256
+
+	// defaultMaxChunkBytes bounds the chunks read unless configured
+	defaultMaxChunkBytes = 64 << 20
+
+	// eventSizeHint sizes the buffers of chunks, per event
+	eventSizeHint = 128
+)
+
+// StreamConfig configures the streams of a Codec
+type StreamConfig struct {
+	// ChunkEvents bounds the number of events of a chunk (defaults to
+	// 256). EncodeStream writes smaller chunks when its input runs dry, so
+	// that live streams are not held back.
+	ChunkEvents int
+
+	// Workers is the number of chunks EncodeStream encodes at once
+	// (defaults to GOMAXPROCS)
+	Workers int
+
+	// MaxChunkBytes bounds the length of the chunks read (defaults to
+	// 64MB)
+	MaxChunkBytes int
+
+	// RecordManifest records the manifest of the streams of encoding and
+	// decoding sessions, see Manifest. Sessions started with Resume always
+	// record theirs.
+	RecordManifest bool
+
+	// Checkpoints saves the checkpoints of the streams encoded and decoded
+	// under a context carrying one (see WithCheckpoint), so that long-running
+	// jobs restart from their last checkpoint after a crash
+	Checkpoints CheckpointStore
+
+	// CheckpointInterval is the number of chunks between checkpoints
+	// (defaults to 1). The end of a stream is always checkpointed.
+	CheckpointInterval int
+
+	// Framing is the framing of the streams encoded, and of those decoded
+	// unless FramingAuto, the default, detects it
+	Framing Framing
+
+	// Checksums follows each chunk of the streams encoded, or each event of
+	// delimited ones, with its CRC-32C, and starts the streams with a
+	// preamble announcing them, so that decoders detect corrupted chunks
+	// before decoding their events. Decoders check the chunks of the
+	// streams whose preamble announces checksums, and those of the sessions
+	// starting past the preamble, e.g. with Resume, when Checksums is set.
+	Checksums bool
+
+	// SkipCorruptChunks skips the chunks failing their checksum, counted by
+	// Progress.Skipped, instead of failing the stream with a
+	// *ChecksumError. A corrupted chunk length still derails the stream.
+	SkipCorruptChunks bool
+}
+
+// withDefaults returns config with its unset fields defaulted
+func (config StreamConfig) withDefaults() StreamConfig {
+	if config.ChunkEvents <= 0 {
+		config.ChunkEvents = defaultChunkEvents
+	}
+	if config.Workers <= 0 {
+		config.Workers = runtime.GOMAXPROCS(0)
+	}
+	if config.MaxChunkBytes <= 0 {
+		config.MaxChunkBytes = defaultMaxChunkBytes
+	}
+	if config.CheckpointInterval <= 0 {
+		config.CheckpointInterval = 1
+	}
+	return config
+}
+
+// StreamEncoder writes events as chunks. EncodeStream may be called
+// concurrently; the methods of encoding sessions may not.
+type StreamEncoder struct {
+	config StreamConfig
+
+	// w, pending, manifest and checkpoints are the writer, the events not
+	// yet written, the manifest, if recorded, and the checkpointer, if
+	// checkpointed, of the encoding session, and preamble whether the
+	// preamble of the stream is still to be written
+	w           io.Writer
+	pending     []events.Event
+	manifest    *Manifest
+	checkpoints *checkpointer
+	preamble    bool
+}
+
+var _ encoding.StreamEncoder = (*StreamEncoder)(nil)
+
+// NewStreamEncoder creates a stream encoder
+func NewStreamEncoder(config StreamConfig) *StreamEncoder {
+	return &StreamEncoder{config: config.withDefaults()}
+}
+
+// chunk is a chunk encoded by a worker of EncodeStream
+type chunk struct {
+	events []events.Event
+	done   chan encodedChunk
+}
+
+// encodedChunk is the encoding of a chunk
+type encodedChunk struct {
+	data []byte
+	err  error
+}
+
+// EncodeStream encodes the events of input to output until input is closed.
+// Chunks are encoded by config.Workers goroutines and written in order; a
+// chunk holds the events available when it is started, up to
+// config.ChunkEvents. It stops at the first encoding or write error.
+//
+// Under a context carrying a checkpoint, the stream is checkpointed once
+// its chunks are written (see StreamConfig.Checkpoints).
+func (e *StreamEncoder) EncodeStream(ctx context.Context, input <-chan events.Event, output io.Writer) error {
+	return e.EncodeStreamWithManifest(ctx, input, output, nil)
+}
+
+// EncodeStreamWithManifest encodes the events of input to output as
+// EncodeStream does, adding the chunks written to manifest. To resume an
+// interrupted transfer from a chunk, pass the manifest cloned up to that
+// chunk and the events from its offset (see Manifest.Offset).
+func (e *StreamEncoder) EncodeStreamWithManifest(ctx context.Context, input <-chan events.Event, output io.Writer, manifest *Manifest) error {
+	checkpoints := newCheckpointer(ctx, e.config)
+	if e.config.Checksums && manifest.Len() == 0 && (checkpoints == nil || checkpoints.current.Offset == 0) {
+		preamble := appendPreamble(nil, e.config.Framing)
+		if _, err := output.Write(preamble); err != nil {
+			return err
+		}
+		if err := e.record(ctx, manifest, checkpoints, preamble, 0); err != nil {
+			return err
+		}
+	}
+
+	streamCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	// Chunks are handed to the workers and, in order, to the writer, which
+	// waits for each to be encoded. ordered bounds the chunks in flight.
+	work := make(chan *chunk)
+	ordered := make(chan *chunk, 2*e.config.Workers)
+	var workers sync.WaitGroup
+	for range e.config.Workers {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for c := range work {
+				data, err := appendChunk(bufpool.Default().GetSlice(len(c.events)*eventSizeHint), c.events, e.config.Framing, e.config.Checksums)
+				c.done <- encodedChunk{data: data, err: err}
+			}
+		}()
+	}
+	written := make(chan error, 1)
+	go func() {
+		var err error
+		for c := range ordered {
+			encoded := <-c.done
+			if err == nil {
+				if err = encoded.err; err == nil {
+					_, err = output.Write(encoded.data)
+				}
+				if err == nil {
+					err = e.record(ctx, manifest, checkpoints, encoded.data, len(c.events))
+				}
+				if err != nil {
+					cancel(err)
+				}
+			}
+			bufpool.Default().PutSlice(encoded.data)
+		}
+		if err == nil && checkpoints != nil {
+			err = checkpoints.save(ctx, true)
+		}
+		written <- err
+	}()
+
+	dispatch := func(batch []events.Event) bool {
+		c := &chunk{events: batch, done: make(chan encodedChunk, 1)}
+		select {
+		case ordered <- c:
+		case <-streamCtx.Done():
+			return false
+		}
+		select {
+		case work <- c:
+			return true
+		case <-streamCtx.Done():
+			c.done <- encodedChunk{err: context.Cause(streamCtx)}
+			return false
+		}
+	}
+	batch := make([]events.Event, 0, e.config.ChunkEvents)
+receive:
+	for {
+		select {
+		case event, ok := <-input:
+			if !ok {
+				break receive
+			}
+			batch = append(batch, event)
+		case <-streamCtx.Done():
+			break receive
+		}
+		// Take the events already waiting, then encode the chunk
+		for len(batch) < e.config.ChunkEvents {
+			event, ok, ready := tryReceive(input)
+			if !ready || !ok {
+				break
+			}
+			batch = append(batch, event)
+		}
+		if !dispatch(batch) {
+			break
+		}
+		batch = make([]events.Event, 0, e.config.ChunkEvents)
+	}
+
+	close(work)
+	workers.Wait()
+	close(ordered)
+	if err := <-written; err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// tryReceive receives an event from input if one is ready
+func tryReceive(input <-chan events.Event) (event events.Event, ok, ready bool) {
+	select {
+	case event, ok = <-input:
+		return event, ok, true
+	default:
+		return nil, false, false
+	}
+}
+
+// StartStream starts an encoding session writing to w, checkpointed under a
+// context carrying a checkpoint (see StreamConfig.Checkpoints)
+func (e *StreamEncoder) StartStream(ctx context.Context, w io.Writer) error {
+	if w == nil {
+		return errors.New("writer cannot be nil")
+	}
+	e.w, e.pending, e.manifest = w, e.pending[:0], nil
+	e.checkpoints = newCheckpointer(ctx, e.config)
+	e.preamble = e.config.Checksums && (e.checkpoints == nil || e.checkpoints.current.Offset == 0)
+	if e.config.RecordManifest {
+		e.manifest = &Manifest{}
+	}
+	return nil
+}
+
+// Resume starts an encoding session writing to w the chunks of a stream
+// described by manifest from chunk fromChunk on, w being positioned at the
+// byte offset of the chunk. It returns the index of the first event of the
+// chunk, from which the events of the stream are to be written again.
+func (e *StreamEncoder) Resume(ctx context.Context, w io.Writer, manifest *Manifest, fromChunk int) (int, error) {
+	if err := checkResume(manifest, fromChunk); err != nil {
+		return 0, err
+	}
+	if err := e.StartStream(ctx, w); err != nil {
+		return 0, err
+	}
+	e.manifest, e.checkpoints = manifest.Clone(fromChunk), nil
+	e.preamble = e.config.Checksums && fromChunk == 0
+	_, event := manifest.Offset(fromChunk)
+	return event, nil
+}
+
+// Manifest returns the manifest of the chunks written by the encoding
+// session, or nil when it is not recorded
+func (e *StreamEncoder) Manifest() *Manifest {
+	if e.manifest == nil {
+		return nil
+	}
+	return e.manifest.Clone(e.manifest.Len())
+}
+
+// WriteEvent adds an event to the encoding session, writing a chunk once
+// config.ChunkEvents events are pending. Call Flush to write the pending
+// events earlier.
+func (e *StreamEncoder) WriteEvent(ctx context.Context, event events.Event) error {
+	if e.w == nil {
+		return errors.New("no encoding session started")
+	}
+	if event == nil {
+		return &encoding.EncodingError{Format: "protobuf", Message: "cannot encode nil event"}
+	}
+	e.pending = append(e.pending, event)
+	if len(e.pending) < e.config.ChunkEvents {
+		return nil
+	}
+	return e.Flush(ctx)
+}
+
+// Flush writes the pending events of the encoding session as a chunk,
+// preceded by the preamble of the stream if it is not written yet
+func (e *StreamEncoder) Flush(ctx context.Context) error {
+	if e.w == nil {
+		return errors.New("no encoding session started")
+	}
+	if e.preamble {
+		preamble := appendPreamble(nil, e.config.Framing)
+		if _, err := e.w.Write(preamble); err != nil {
+			return err
+		}
+		e.preamble = false
+		if err := e.record(ctx, e.manifest, e.checkpoints, preamble, 0); err != nil {
+			return err
+		}
+	}
+	if len(e.pending) == 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	data, err := appendChunk(bufpool.Default().GetSlice(len(e.pending)*eventSizeHint), e.pending, e.config.Framing, e.config.Checksums)
+	if err == nil {
+		_, err = e.w.Write(data)
+	}
+	if err == nil {
+		err = e.record(ctx, e.manifest, e.checkpoints, data, len(e.pending))
+	}
+	bufpool.Default().PutSlice(data)
+	clear(e.pending)
+	e.pending = e.pending[:0]
+	return err
+}
+
+// EndStream writes the pending events, checkpointing them, and ends the
+// encoding session
+func (e *StreamEncoder) EndStream(ctx context.Context) error {
+	err := e.Flush(ctx)
+	if err == nil && e.checkpoints != nil {
+		err = e.checkpoints.save(ctx, true)
+	}
+	e.w, e.checkpoints = nil, nil
+	return err
+}
+
+// ContentType returns the MIME type of protobuf encoded events
+func (e *StreamEncoder) ContentType() string {
+	return ContentType
+}
+
+// record adds data, the encoding of n events written, to manifest and
+// checkpoints when set, frame by frame
+func (e *StreamEncoder) record(ctx context.Context, manifest *Manifest, checkpoints *checkpointer, data []byte, n int) error {
+	if manifest == nil && checkpoints == nil {
+		return nil
+	}
+	frames(e.config.Framing, e.config.Checksums, data, n, func(frame []byte, events int) {
+		if manifest != nil {
+			manifest.add(len(frame), events, crc32.Checksum(frame, castagnoli))
+		}
+		if checkpoints != nil {
+			checkpoints.advance(len(frame), events, checkpoints.chain(frame))
+		}
+	})
+	if checkpoints == nil {
+		return nil
+	}
+	return checkpoints.save(ctx, false)
+}
+
+// appendChunk appends the chunk holding evts to dst, or only its events,
+// length-delimited, with FramingDelimited, each followed by its checksum
+// with checksums
+func appendChunk(dst []byte, evts []events.Event, framing Framing, checksums bool) ([]byte, error) {
+	start := len(dst)
+	if framing != FramingDelimited {
+		dst = append(dst, make([]byte, chunkHeaderSize)...)
+	}
+	options := proto.MarshalOptions{UseCachedSize: true}
+	for _, event := range evts {
+		if event == nil {
+			return dst, &encoding.EncodingError{Format: "protobuf", Message: "cannot encode nil event"}
+		}
+		resp, err := pb.NewRunAgentResponse(event)
+		if err != nil {
+			return dst, &encoding.EncodingError{Format: "protobuf", Event: event, Message: "failed to convert event", Cause: err}
+		}
+		eventStart := len(dst)
+		dst = protowire.AppendVarint(dst, uint64(options.Size(resp)))
+		if dst, err = options.MarshalAppend(dst, resp); err != nil {
+			return dst, &encoding.EncodingError{Format: "protobuf", Event: event, Message: "failed to encode event", Cause: err}
+		}
+		if checksums && framing == FramingDelimited {
+			dst = appendChecksum(dst, eventStart)
+		}
+	}
+	if framing != FramingDelimited {
+		binary.BigEndian.PutUint32(dst[start:], uint32(len(dst)-start-chunkHeaderSize))
+		binary.BigEndian.PutUint32(dst[start+4:], uint32(len(evts)))
+		if checksums {
+			dst = appendChecksum(dst, start)
+		}
+	}
+	return dst, nil
+}
+
+// StreamDecoder reads the events of chunks. It is not safe for concurrent
+// use.
+type StreamDecoder struct {
+	config StreamConfig
+
+	// r is the reader of the decoding session, buffered as br, framing
+	// that of the stream once detected and checksums whether its chunks are
+	// followed by their checksum, chunk the buffer of the chunk being read,
+	// buf its unread events and left their number
+	r         io.Reader
+	br        *bufio.Reader
+	framing   Framing
+	checksums bool
+	header    [chunkHeaderSize]byte
+	checksum  [checksumSize]byte
+	chunk     []byte
+	buf       []byte
+	left      int
+
+	// progress is that of the decoding session, current the chunk being
+	// read, expected the manifest of the stream, if known, and manifest
+	// that of the chunks read, if recorded
+	progress Progress
+	current  ChunkInfo
+	expected *Manifest
+	manifest *Manifest
+
+	// checkpoints is the checkpointer of the session, if checkpointed, and
+	// hash that of the stream up to the end of the current chunk
+	checkpoints *checkpointer
+	hash        uint32
+}
+
+// Progress is the progress of a decoding session
+type Progress struct {
+	// Chunks and Bytes count the chunks whose events were all read,
+	// Events the events read and Skipped the chunks skipped as corrupt,
+	// whose events are not read (see StreamConfig.SkipCorruptChunks)
+	Chunks  int
+	Bytes   int64
+	Events  int
+	Skipped int
+
+	// TotalChunks, TotalBytes and TotalEvents describe the whole stream
+	// when its manifest is known, and are 0 otherwise
+	TotalChunks int
+	TotalBytes  int64
+	TotalEvents int
+}
+
+var _ encoding.StreamDecoder = (*StreamDecoder)(nil)
+
+// NewStreamDecoder creates a stream decoder
+func NewStreamDecoder(config StreamConfig) *StreamDecoder {
+	return &StreamDecoder{config: config.withDefaults()}
+}
+
+// DecodeStream decodes the events of input to output until the end of
+// input. output is not closed.
+func (d *StreamDecoder) DecodeStream(ctx context.Context, input io.Reader, output chan<- events.Event) error {
+	decoder := NewStreamDecoder(d.config)
+	if err := decoder.StartStream(ctx, input); err != nil {
+		return err
+	}
+	for {
+		event, err := decoder.ReadEvent(ctx)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		select {
+		case output <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// StartStream starts a decoding session reading from r. Under a context
+// carrying a checkpoint, r is read from its offset and the session is
+// checkpointed (see StreamConfig.Checkpoints): the events of a chunk are
+// checkpointed once the event following them is read, i.e. once the
+// caller is done with them, or the end of the stream reached.
+func (d *StreamDecoder) StartStream(ctx context.Context, r io.Reader) error {
+	if r == nil {
+		return errors.New("reader cannot be nil")
+	}
+	var ok bool
+	if d.br, ok = r.(*bufio.Reader); !ok {
+		d.br = bufio.NewReader(r)
+	}
+	d.r, d.buf, d.left = d.br, nil, 0
+	d.framing, d.checksums = d.config.Framing, d.config.Checksums
+	d.progress, d.expected, d.manifest = Progress{}, nil, nil
+	if d.config.RecordManifest {
+		d.manifest = &Manifest{}
+	}
+	d.checkpoints = newCheckpointer(ctx, d.config)
+	if d.checkpoints != nil {
+		d.progress.Chunks = d.checkpoints.current.Chunks
+		d.progress.Bytes = d.checkpoints.current.Offset
+		d.progress.Events = d.checkpoints.current.Events
+	}
+	return nil
+}
+
+// Resume starts a decoding session reading from r the chunks of a stream
+// described by manifest from chunk fromChunk on, r being positioned at the
+// byte offset of the chunk. The chunks read are checked against manifest.
+// To resume an interrupted transfer, pass the manifest of the session
+// (see Manifest) and its length.
+func (d *StreamDecoder) Resume(ctx context.Context, r io.Reader, manifest *Manifest, fromChunk int) error {
+	if err := checkResume(manifest, fromChunk); err != nil {
+		return err
+	}
+	if err := d.StartStream(ctx, r); err != nil {
+		return err
+	}
+	d.expected, d.manifest, d.checkpoints = manifest, manifest.Clone(fromChunk), nil
+	d.progress.Chunks = fromChunk
+	d.progress.Bytes, d.progress.Events = manifest.Offset(fromChunk)
+	d.progress.TotalChunks = manifest.Len()
+	d.progress.TotalBytes, d.progress.TotalEvents = manifest.Offset(manifest.Len())
+	return nil
+}
+
+// Manifest returns the manifest of the chunks whose events the decoding
+// session read, or nil when it is not recorded
+func (d *StreamDecoder) Manifest() *Manifest {
+	if d.manifest == nil {
+		return nil
+	}
+	return d.manifest.Clone(d.manifest.Len())
+}
+
+// Progress returns the progress of the decoding session
+func (d *StreamDecoder) Progress() Progress {
+	return d.progress
+}
+
+// ReadEvent reads the next event of the decoding session. It returns io.EOF
+// at the end of the stream, after the last chunk.
+func (d *StreamDecoder) ReadEvent(ctx context.Context) (events.Event, error) {
+	if d.r == nil {
+		return nil, errors.New("no decoding session started")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for d.left == 0 {
+		if d.checkpoints != nil {
+			if err := d.checkpoints.save(ctx, false); err != nil {
+				return nil, err
+			}
+		}
+		if err := d.readChunk(); err != nil {
+			if err == io.EOF && d.checkpoints != nil {
+				if saveErr := d.checkpoints.save(ctx, true); saveErr != nil {
+					return nil, saveErr
+				}
+			}
+			return nil, err
+		}
+	}
+
+	size, n := protowire.ConsumeVarint(d.buf)
+	if n < 0 || uint64(len(d.buf)-n) < size {
+		return nil, &encoding.DecodingError{Format: "protobuf", Message: "truncated event in chunk"}
+	}
+	event, err := decodeEvent(d.buf[n : n+int(size)])
+	if err != nil {
+		return nil, err
+	}
+	d.buf, d.left = d.buf[n+int(size):], d.left-1
+	d.progress.Events++
+	if d.left == 0 {
+		d.endChunk()
+	}
+	return event, nil
+}
+
+// readChunk reads the next chunk into buf, or the next event of delimited
+// streams as a chunk of its own, checking its checksum if it has one. The
+// preamble of a stream is read as a chunk without events, and the framing
+// of streams without one detected first if need be.
+func (d *StreamDecoder) readChunk() error {
+	preamble := false
+	if d.progress.Chunks == 0 && d.progress.Bytes == 0 {
+		head, _ := d.br.Peek(len(preambleMagic))
+		preamble = startsWithPreamble(head)
+	}
+	if !preamble && d.framing == FramingAuto {
+		d.framing = detectFraming(d.br, d.config.MaxChunkBytes)
+	}
+	var header, checksum []byte
+	var err error
+	switch {
+	case preamble:
+		err = d.readPreamble()
+	case d.framing == FramingDelimited:
+		err = d.readDelimited()
+	default:
+		header, err = d.header[:], d.readChunked()
+	}
+	if err != nil {
+		return err
+	}
+
+	corrupt := false
+	if d.checksums && !preamble {
+		checksum = d.checksum[:]
+		if _, err := io.ReadFull(d.r, checksum); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return &encoding.DecodingError{Format: "protobuf", Message: "truncated chunk checksum", Cause: err}
+		}
+		d.current.Length += checksumSize
+		want := binary.BigEndian.Uint32(checksum)
+		if got := crc32.Update(crc32.Checksum(header, castagnoli), castagnoli, d.chunk); got != want {
+			checksumErr := &ChecksumError{Chunk: d.progress.Chunks, Offset: d.progress.Bytes, Checksum: want, Computed: got}
+			if !d.config.SkipCorruptChunks {
+				return &encoding.DecodingError{Format: "protobuf", Message: "corrupt chunk", Cause: checksumErr}
+			}
+			corrupt = true
+		}
+	}
+	if d.expected != nil || d.manifest != nil {
+		d.current.Checksum = crc32.Update(crc32.Update(crc32.Checksum(header, castagnoli), castagnoli, d.chunk), castagnoli, checksum)
+		if err := d.expected.check(d.progress.Chunks, d.current.Length, d.current.Checksum); err != nil {
+			return &encoding.DecodingError{Format: "protobuf", Message: "chunk does not match the manifest", Cause: err}
+		}
+	}
+	if d.checkpoints != nil {
+		d.hash = d.checkpoints.chain(header, d.chunk, checksum)
+	}
+	d.buf, d.left = d.chunk, d.current.Events
+	if corrupt {
+		d.buf, d.left = nil, 0
+		d.progress.Skipped++
+	}
+	if d.left == 0 {
+		d.endChunk()
+	}
+	return nil
+}
+
+// readPreamble reads the preamble of the stream into chunk, taking the
+// framing of the stream from it
+func (d *StreamDecoder) readPreamble() error {
+	preamble, err := d.br.Peek(preambleSize)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return &encoding.DecodingError{Format: "protobuf", Message: "truncated stream preamble", Cause: err}
+	}
+	framing, err := parsePreamble(preamble)
+	if err != nil {
+		return &encoding.DecodingError{Format: "protobuf", Message: "invalid stream preamble", Cause: err}
+	}
+	if d.framing != FramingAuto && d.framing != framing {
+		return &encoding.DecodingError{Format: "protobuf", Message: fmt.Sprintf("stream framing %s does not match the configured %s", framing, d.framing)}
+	}
+	d.framing, d.checksums = framing, true
+	d.chunk = append(d.chunk[:0], preamble...)
+	_, _ = d.br.Discard(preambleSize)
+	d.current = ChunkInfo{Length: preambleSize}
+	return nil
+}
+
+// readChunked reads the header of the next chunk and its events into chunk
+func (d *StreamDecoder) readChunked() error {
+	if _, err := io.ReadFull(d.r, d.header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return &encoding.DecodingError{Format: "protobuf", Message: "truncated chunk header", Cause: err}
+		}
+		return err
+	}
+	length := binary.BigEndian.Uint32(d.header[:])
+	if int64(length) > int64(d.config.MaxChunkBytes) {
+		return &encoding.DecodingError{Format: "protobuf", Message: fmt.Sprintf("chunk of %d bytes exceeds the limit of %d", length, d.config.MaxChunkBytes)}
+	}
+	if int(length) > cap(d.chunk) {
+		d.chunk = make([]byte, length)
+	}
+	d.chunk = d.chunk[:length]
+	if _, err := io.ReadFull(d.r, d.chunk); err != nil {
+		return &encoding.DecodingError{Format: "protobuf", Message: "truncated chunk", Cause: err}
+	}
+	d.current = ChunkInfo{Length: chunkHeaderSize + len(d.chunk), Events: int(binary.BigEndian.Uint32(d.header[4:]))}
+	return nil
+}
+
+// readDelimited reads the next event of a delimited stream into chunk,
+// along with its length
+func (d *StreamDecoder) readDelimited() error {
+	d.chunk = d.chunk[:0]
+	for {
+		b, err := d.br.ReadByte()
+		if err != nil {
+			if err == io.EOF && len(d.chunk) > 0 {
+				return &encoding.DecodingError{Format: "protobuf", Message: "truncated event length", Cause: io.ErrUnexpectedEOF}
+			}
+			return err
+		}
+		d.chunk = append(d.chunk, b)
+		if b < 0x80 {
+			break
+		}
+		if len(d.chunk) == binary.MaxVarintLen64 {
+			return &encoding.DecodingError{Format: "protobuf", Message: "invalid event length"}
+		}
+	}
+	size, prefix := protowire.ConsumeVarint(d.chunk)
+	if prefix < 0 {
+		return &encoding.DecodingError{Format: "protobuf", Message: "invalid event length", Cause: protowire.ParseError(prefix)}
+	}
+	if size > uint64(d.config.MaxChunkBytes) {
+		return &encoding.DecodingError{Format: "protobuf", Message: fmt.Sprintf("event of %d bytes exceeds the limit of %d", size, d.config.MaxChunkBytes)}
+	}
+	d.chunk = slices.Grow(d.chunk, int(size))[:prefix+int(size)]
+	if _, err := io.ReadFull(d.br, d.chunk[prefix:]); err != nil {
+		return &encoding.DecodingError{Format: "protobuf", Message: "truncated event", Cause: err}
+	}
+	d.current = ChunkInfo{Length: len(d.chunk), Events: 1}
+	return nil
+}
+
+// endChunk records the chunk whose events were all read
+func (d *StreamDecoder) endChunk() {
+	if d.manifest != nil {
+		d.manifest.add(d.current.Length, d.current.Events, d.current.Checksum)
+	}
+	if d.checkpoints != nil {
+		d.checkpoints.advance(d.current.Length, d.current.Events, d.hash)
+	}
+	d.progress.Chunks++
+	d.progress.Bytes += int64(d.current.Length)
+}
+
+// EndStream ends the decoding session
+func (d *StreamDecoder) EndStream(context.Context) error {
+	d.r, d.br, d.left, d.checkpoints = nil, nil, 0, nil
+	return nil
+}
+
+// ContentType returns the MIME type of protobuf encoded events
+func (d *StreamDecoder) ContentType() string {
+	return ContentType
+}
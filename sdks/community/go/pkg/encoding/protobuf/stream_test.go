@@ -0,0 +1,283 @@
+package protobuf
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding"
+)
+
+// testEvents returns n events mixing types with a protobuf representation
+// and JSON-only ones
+func testEvents(n int) []events.Event {
+	evts := make([]events.Event, 0, n)
+	for i := range n {
+		switch i % 4 {
+		case 0:
+			evts = append(evts, events.NewTextMessageStartEvent(fmt.Sprintf("msg-%d", i), events.WithRole("assistant")))
+		case 1:
+			evts = append(evts, events.NewTextMessageContentEvent(fmt.Sprintf("msg-%d", i), "Hello, world"))
+		case 2:
+			evts = append(evts, events.NewToolCallResultEvent(fmt.Sprintf("msg-%d", i), "tool-1", "result"))
+		default:
+			evts = append(evts, events.NewStateSnapshotEvent(map[string]any{"count": float64(i)}))
+		}
+	}
+	return evts
+}
+
+func requireSameEvents(t *testing.T, want, got []events.Event) {
+	t.Helper()
+	require.Len(t, got, len(want))
+	for i := range want {
+		wantJSON, err := want[i].ToJSON()
+		require.NoError(t, err)
+		gotJSON, err := got[i].ToJSON()
+		require.NoError(t, err)
+		require.JSONEq(t, string(wantJSON), string(gotJSON), "event %d", i)
+	}
+}
+
+// encodeStream encodes evts with EncodeStream
+func encodeStream(t testing.TB, config StreamConfig, evts []events.Event) []byte {
+	input := make(chan events.Event)
+	go func() {
+		defer close(input)
+		for _, event := range evts {
+			input <- event
+		}
+	}()
+	var out bytes.Buffer
+	require.NoError(t, NewStreamEncoder(config).EncodeStream(context.Background(), input, &out))
+	return out.Bytes()
+}
+
+// decodeStream decodes the events of data with DecodeStream
+func decodeStream(t testing.TB, config StreamConfig, data []byte) []events.Event {
+	output := make(chan events.Event, 64)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- NewStreamDecoder(config).DecodeStream(context.Background(), bytes.NewReader(data), output)
+		close(output)
+	}()
+	var evts []events.Event
+	for event := range output {
+		evts = append(evts, event)
+	}
+	require.NoError(t, <-errc)
+	return evts
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	for _, config := range []StreamConfig{
+		{},
+		{ChunkEvents: 1, Workers: 1},
+		{ChunkEvents: 7, Workers: 4},
+	} {
+		t.Run(fmt.Sprintf("chunk=%d,workers=%d", config.ChunkEvents, config.Workers), func(t *testing.T) {
+			evts := testEvents(1000)
+			requireSameEvents(t, evts, decodeStream(t, config, encodeStream(t, config, evts)))
+		})
+	}
+}
+
+func TestStreamEncodeError(t *testing.T) {
+	evts := testEvents(100)
+	evts[50] = nil
+	input := make(chan events.Event, len(evts))
+	for _, event := range evts {
+		input <- event
+	}
+	close(input)
+
+	var out bytes.Buffer
+	err := NewStreamEncoder(StreamConfig{ChunkEvents: 10}).EncodeStream(context.Background(), input, &out)
+	var encErr *encoding.EncodingError
+	require.True(t, errors.As(err, &encErr))
+
+	// The chunks before the failing one are written
+	got, err := NewCodec(StreamConfig{}).DecodeMultiple(context.Background(), out.Bytes())
+	require.NoError(t, err)
+	requireSameEvents(t, evts[:50], got)
+}
+
+func TestStreamEncodeWriteError(t *testing.T) {
+	input := make(chan events.Event)
+	go func() {
+		// The encoder stops reading after the write error, so give up
+		// once nobody receives
+		for _, event := range testEvents(1000) {
+			select {
+			case input <- event:
+			default:
+			}
+		}
+		close(input)
+	}()
+	err := NewStreamEncoder(StreamConfig{ChunkEvents: 1}).EncodeStream(context.Background(), input, failingWriter{})
+	assert.ErrorIs(t, err, io.ErrClosedPipe)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func TestStreamSession(t *testing.T) {
+	codec := NewCodec(StreamConfig{ChunkEvents: 3})
+	ctx := context.Background()
+	evts := testEvents(10)
+
+	var out bytes.Buffer
+	require.NoError(t, codec.StartEncoding(ctx, &out))
+	for _, event := range evts[:4] {
+		require.NoError(t, codec.WriteEvent(ctx, event))
+	}
+	// A full chunk is written, the fourth event is pending
+	assert.Equal(t, 1, countChunks(t, out.Bytes()))
+	require.NoError(t, codec.encoder.Flush(ctx))
+	assert.Equal(t, 2, countChunks(t, out.Bytes()))
+	for _, event := range evts[4:] {
+		require.NoError(t, codec.WriteEvent(ctx, event))
+	}
+	require.NoError(t, codec.EndEncoding(ctx))
+	assert.Error(t, codec.WriteEvent(ctx, evts[0]))
+
+	require.NoError(t, codec.StartDecoding(ctx, &out))
+	var got []events.Event
+	for {
+		event, err := codec.ReadEvent(ctx)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		got = append(got, event)
+	}
+	require.NoError(t, codec.EndDecoding(ctx))
+	requireSameEvents(t, evts, got)
+}
+
+// countChunks counts the chunks of data
+func countChunks(t *testing.T, data []byte) int {
+	t.Helper()
+	chunks := 0
+	for len(data) > 0 {
+		require.GreaterOrEqual(t, len(data), chunkHeaderSize)
+		data = data[chunkHeaderSize+int(binary.BigEndian.Uint32(data)):]
+		chunks++
+	}
+	return chunks
+}
+
+func TestCodecMultiple(t *testing.T) {
+	codec := NewCodec(StreamConfig{ChunkEvents: 16})
+	ctx := context.Background()
+	evts := testEvents(100)
+
+	data, err := codec.EncodeMultiple(ctx, evts)
+	require.NoError(t, err)
+	assert.Equal(t, 7, countChunks(t, data))
+	got, err := codec.DecodeMultiple(ctx, data)
+	require.NoError(t, err)
+	requireSameEvents(t, evts, got)
+
+	data, err = codec.Encode(ctx, evts[2])
+	require.NoError(t, err)
+	event, err := codec.Decode(ctx, data)
+	require.NoError(t, err)
+	requireSameEvents(t, evts[2:3], []events.Event{event})
+
+	assert.Equal(t, ContentType, codec.ContentType())
+	assert.True(t, codec.SupportsStreaming())
+}
+
+func TestStreamDecodeErrors(t *testing.T) {
+	ctx := context.Background()
+	data, err := NewCodec(StreamConfig{}).EncodeMultiple(ctx, testEvents(10))
+	require.NoError(t, err)
+
+	t.Run("truncated chunk", func(t *testing.T) {
+		_, err := NewCodec(StreamConfig{}).DecodeMultiple(ctx, data[:len(data)-5])
+		var decErr *encoding.DecodingError
+		require.True(t, errors.As(err, &decErr))
+		assert.Equal(t, "truncated chunk", decErr.Message)
+	})
+
+	t.Run("truncated header", func(t *testing.T) {
+		_, err := NewCodec(StreamConfig{}).DecodeMultiple(ctx, append(bytes.Clone(data), 0, 0, 0))
+		var decErr *encoding.DecodingError
+		require.True(t, errors.As(err, &decErr))
+		assert.Equal(t, "truncated chunk header", decErr.Message)
+	})
+
+	t.Run("chunk over the limit", func(t *testing.T) {
+		_, err := NewCodec(StreamConfig{MaxChunkBytes: 64}).DecodeMultiple(ctx, data)
+		var decErr *encoding.DecodingError
+		require.True(t, errors.As(err, &decErr))
+		assert.Contains(t, decErr.Message, "exceeds the limit of 64")
+	})
+
+	t.Run("events missing from chunk", func(t *testing.T) {
+		corrupt := bytes.Clone(data)
+		binary.BigEndian.PutUint32(corrupt[4:], 11)
+		_, err := NewCodec(StreamConfig{}).DecodeMultiple(ctx, corrupt)
+		var decErr *encoding.DecodingError
+		require.True(t, errors.As(err, &decErr))
+		assert.Equal(t, "truncated event in chunk", decErr.Message)
+	})
+}
+
+func BenchmarkEncodeStream(b *testing.B) {
+	evts := testEvents(1 << 20)
+	for name, config := range map[string]StreamConfig{
+		"serial":   {Workers: 1},
+		"parallel": {},
+	} {
+		b.Run(name, func(b *testing.B) {
+			for b.Loop() {
+				input := make(chan events.Event, 4096)
+				go func() {
+					defer close(input)
+					for _, event := range evts {
+						input <- event
+					}
+				}()
+				if err := NewStreamEncoder(config).EncodeStream(context.Background(), input, io.Discard); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(b.N*len(evts))/b.Elapsed().Seconds(), "events/s")
+		})
+	}
+}
+
+func BenchmarkDecodeStream(b *testing.B) {
+	evts := testEvents(1 << 20)
+	data, err := NewCodec(StreamConfig{}).EncodeMultiple(context.Background(), evts)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(data)))
+	for b.Loop() {
+		output := make(chan events.Event, 4096)
+		go func() {
+			for range output {
+			}
+		}()
+		if err := NewStreamDecoder(StreamConfig{}).DecodeStream(context.Background(), bytes.NewReader(data), output); err != nil {
+			b.Fatal(err)
+		}
+		close(output)
+	}
+	b.ReportMetric(float64(b.N*len(evts))/b.Elapsed().Seconds(), "events/s")
+}
@@ -0,0 +1,245 @@
+package encoding
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/i18n"
+)
+
+// SizeBuckets are the upper bounds, in bytes, of the buckets of the payload
+// size histogram served by SizeBudget.MetricsHandler
+var SizeBuckets = []float64{256, 1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20, 4 << 20}
+
+// SizeBudgetConfig configures a SizeBudget
+type SizeBudgetConfig struct {
+	// Default bounds the encoded size of events of types without a limit
+	// in Limits, in bytes (0 = unlimited)
+	Default int
+
+	// Limits bounds the encoded size of the events of each type, in bytes
+	// (0 = unlimited, overriding Default)
+	Limits map[events.EventType]int
+}
+
+// SizeStats describes the payloads encoded for an event type
+type SizeStats struct {
+	// Events counts the events checked, rejected ones included
+	Events uint64
+
+	// Rejected counts the events over the limit of their type
+	Rejected uint64
+
+	// TotalBytes and MaxBytes sum and bound the sizes of the events
+	TotalBytes int64
+	MaxBytes   int
+}
+
+// EventTooLargeError is returned for events whose encoding exceeds the limit
+// of their type. It matches events.ErrEventTooLarge with errors.Is.
+type EventTooLargeError struct {
+	EventType events.EventType
+	Size      int
+	Limit     int
+
+	// Chunks is the least number of events within the limit the payload
+	// would need
+	Chunks int
+
+	// Suggestion tells how to send the payload within the limit, empty for
+	// event types that cannot be split
+	Suggestion string
+}
+
+func (e *EventTooLargeError) Error() string {
+	msg := fmt.Sprintf("%s %s: %d bytes exceeds the limit of %d", e.EventType, events.ErrEventTooLarge, e.Size, e.Limit)
+	if e.Suggestion != "" {
+		msg += "; " + e.Suggestion
+	}
+	return msg
+}
+
+func (e *EventTooLargeError) Unwrap() error {
+	return events.ErrEventTooLarge
+}
+
+// Localize renders the error with l, e.g. to report it to a user in their
+// language
+func (e *EventTooLargeError) Localize(l *i18n.Localizer) string {
+	msg := l.Localize(i18n.NewMessage(MessageEventTooLarge, "{type} event too large: {size} bytes exceeds the limit of {limit}",
+		"type", e.EventType, "size", e.Size, "limit", e.Limit))
+	if suggestion, ok := ChunkingSuggestion(e.EventType, e.Chunks); ok {
+		msg += "; " + l.Localize(suggestion)
+	}
+	return msg
+}
+
+// Message keys of the size limit errors, see EventTooLargeError.Localize.
+// Their parameters are those of the default messages.
+const (
+	MessageEventTooLarge        = "encoding.event_too_large"
+	MessageSplitDelta           = "encoding.suggest.split_delta"
+	MessageSplitChunks          = "encoding.suggest.split_chunks"
+	MessageSplitMessages        = "encoding.suggest.split_messages_snapshot"
+	MessageSmallerStateSnapshot = "encoding.suggest.smaller_state_snapshot"
+	MessageSplitStateDelta      = "encoding.suggest.split_state_delta"
+	MessageAttachToolResult     = "encoding.suggest.attach_tool_result"
+)
+
+// SuggestChunking tells how to send the payload of an event of type
+// eventType across at least chunks events, or returns "" for event types
+// that cannot be split
+func SuggestChunking(eventType events.EventType, chunks int) string {
+	if suggestion, ok := ChunkingSuggestion(eventType, chunks); ok {
+		return suggestion.String()
+	}
+	return ""
+}
+
+// ChunkingSuggestion is SuggestChunking as a localizable message, reporting
+// whether events of type eventType can be split
+func ChunkingSuggestion(eventType events.EventType, chunks int) (i18n.Message, bool) {
+	switch eventType {
+	case events.EventTypeTextMessageContent, events.EventTypeToolCallArgs, events.EventTypeReasoningMessageContent,
+		events.EventTypeThinkingTextMessageContent, events.EventTypeActivityDelta:
+		return i18n.NewMessage(MessageSplitDelta, "split the delta across {chunks} or more {type} events",
+			"chunks", chunks, "type", eventType), true
+	case events.EventTypeTextMessageChunk, events.EventTypeToolCallChunk, events.EventTypeReasoningMessageChunk:
+		return i18n.NewMessage(MessageSplitChunks, "split the delta across {chunks} or more {type} events with the same ID",
+			"chunks", chunks, "type", eventType), true
+	case events.EventTypeMessagesSnapshot:
+		return i18n.NewMessage(MessageSplitMessages, "send the snapshot in chunks with events.SplitMessagesSnapshot"), true
+	case events.EventTypeStateSnapshot:
+		return i18n.NewMessage(MessageSmallerStateSnapshot, "send a smaller snapshot followed by STATE_DELTA events"), true
+	case events.EventTypeStateDelta:
+		return i18n.NewMessage(MessageSplitStateDelta, "split the patch across {chunks} or more STATE_DELTA events",
+			"chunks", chunks), true
+	case events.EventTypeToolCallResult:
+		return i18n.NewMessage(MessageAttachToolResult, "send the content as an attachment, see events.NewAttachmentEvent"), true
+	}
+	return i18n.Message{}, false
+}
+
+// SizeBudget bounds the encoded size of events by type, so that oversized
+// payloads are rejected before they are written to a stream, and records the
+// distribution of the sizes encoded for each type. It is safe for
+// concurrent use.
+type SizeBudget struct {
+	config SizeBudgetConfig
+
+	mu      sync.Mutex
+	stats   map[events.EventType]*SizeStats
+	buckets map[events.EventType][]uint64
+}
+
+// NewSizeBudget creates a size budget
+func NewSizeBudget(config SizeBudgetConfig) *SizeBudget {
+	return &SizeBudget{
+		config:  config,
+		stats:   make(map[events.EventType]*SizeStats),
+		buckets: make(map[events.EventType][]uint64),
+	}
+}
+
+// Limit returns the size limit of events of type eventType (0 = unlimited)
+func (b *SizeBudget) Limit(eventType events.EventType) int {
+	if limit, ok := b.config.Limits[eventType]; ok {
+		return limit
+	}
+	return b.config.Default
+}
+
+// Check records size, the encoded size of an event of type eventType, and
+// returns an *EventTooLargeError when it exceeds the limit of the type. A
+// nil budget accepts every event.
+func (b *SizeBudget) Check(eventType events.EventType, size int) error {
+	if b == nil {
+		return nil
+	}
+	limit := b.Limit(eventType)
+	tooLarge := limit > 0 && size > limit
+
+	b.mu.Lock()
+	stats, ok := b.stats[eventType]
+	if !ok {
+		stats = &SizeStats{}
+		b.stats[eventType] = stats
+		b.buckets[eventType] = make([]uint64, len(SizeBuckets))
+	}
+	stats.Events++
+	stats.TotalBytes += int64(size)
+	stats.MaxBytes = max(stats.MaxBytes, size)
+	if tooLarge {
+		stats.Rejected++
+	}
+	for i, bound := range SizeBuckets {
+		if float64(size) <= bound {
+			b.buckets[eventType][i]++
+		}
+	}
+	b.mu.Unlock()
+
+	if !tooLarge {
+		return nil
+	}
+	chunks := (size + limit - 1) / limit
+	return &EventTooLargeError{
+		EventType:  eventType,
+		Size:       size,
+		Limit:      limit,
+		Chunks:     chunks,
+		Suggestion: SuggestChunking(eventType, chunks),
+	}
+}
+
+// Stats returns the statistics of the event types checked so far
+func (b *SizeBudget) Stats() map[events.EventType]SizeStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	result := make(map[events.EventType]SizeStats, len(b.stats))
+	for eventType, stats := range b.stats {
+		result[eventType] = *stats
+	}
+	return result
+}
+
+// MetricsHandler serves the payload size metrics in the Prometheus text
+// exposition format
+func (b *SizeBudget) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		b.writeMetrics(w)
+	})
+}
+
+func (b *SizeBudget) writeMetrics(w io.Writer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	const size, rejected = "agui_event_payload_bytes", "agui_event_payload_rejected_total"
+
+	eventTypes := make([]events.EventType, 0, len(b.stats))
+	for eventType := range b.stats {
+		eventTypes = append(eventTypes, eventType)
+	}
+	slices.Sort(eventTypes)
+
+	fmt.Fprintf(w, "# HELP %s Encoded size of events by type.\n# TYPE %s histogram\n", size, size)
+	for _, eventType := range eventTypes {
+		stats := b.stats[eventType]
+		for i, bound := range SizeBuckets {
+			fmt.Fprintf(w, "%s_bucket{type=%q,le=%q} %d\n", size, eventType, strconv.FormatFloat(bound, 'f', -1, 64), b.buckets[eventType][i])
+		}
+		fmt.Fprintf(w, "%s_bucket{type=%q,le=\"+Inf\"} %d\n", size, eventType, stats.Events)
+		fmt.Fprintf(w, "%s_sum{type=%q} %d\n", size, eventType, stats.TotalBytes)
+		fmt.Fprintf(w, "%s_count{type=%q} %d\n", size, eventType, stats.Events)
+	}
+	fmt.Fprintf(w, "# HELP %s Events rejected for exceeding the size limit of their type.\n# TYPE %s counter\n", rejected, rejected)
+	for _, eventType := range eventTypes {
+		fmt.Fprintf(w, "%s{type=%q} %d\n", rejected, eventType, b.stats[eventType].Rejected)
+	}
+}
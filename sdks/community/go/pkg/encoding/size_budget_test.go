@@ -0,0 +1,82 @@
+package encoding
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/i18n"
+)
+
+func TestSizeBudgetLimits(t *testing.T) {
+	budget := NewSizeBudget(SizeBudgetConfig{
+		Default: 1000,
+		Limits: map[events.EventType]int{
+			events.EventTypeToolCallArgs:     100,
+			events.EventTypeMessagesSnapshot: 0,
+		},
+	})
+
+	assert.NoError(t, budget.Check(events.EventTypeToolCallArgs, 100))
+	assert.NoError(t, budget.Check(events.EventTypeMessagesSnapshot, 1<<20), "a zero limit overrides the default")
+	assert.NoError(t, budget.Check(events.EventTypeRunStarted, 1000))
+	assert.Error(t, budget.Check(events.EventTypeRunStarted, 1001))
+
+	err := budget.Check(events.EventTypeToolCallArgs, 250)
+	var tooLarge *EventTooLargeError
+	require.True(t, errors.As(err, &tooLarge))
+	assert.ErrorIs(t, err, events.ErrEventTooLarge)
+	assert.Equal(t, 250, tooLarge.Size)
+	assert.Equal(t, 100, tooLarge.Limit)
+	assert.Equal(t, 3, tooLarge.Chunks)
+	assert.Equal(t, "TOOL_CALL_ARGS event too large: 250 bytes exceeds the limit of 100; split the delta across 3 or more TOOL_CALL_ARGS events", err.Error())
+
+	var none *SizeBudget
+	assert.NoError(t, none.Check(events.EventTypeToolCallArgs, 1<<30))
+}
+
+func TestEventTooLargeErrorLocalize(t *testing.T) {
+	catalog := i18n.NewCatalog()
+	catalog.Add("es", map[string]string{
+		MessageEventTooLarge: "evento {type} demasiado grande: {size} bytes superan el límite de {limit}",
+		MessageSplitDelta:    "divide el delta en {chunks} o más eventos {type}",
+	})
+	err := &EventTooLargeError{EventType: events.EventTypeToolCallArgs, Size: 250, Limit: 100, Chunks: 3}
+
+	assert.Equal(t, "evento TOOL_CALL_ARGS demasiado grande: 250 bytes superan el límite de 100; divide el delta en 3 o más eventos TOOL_CALL_ARGS",
+		err.Localize(i18n.NewLocalizer(catalog, "es-MX")))
+	err.Suggestion = SuggestChunking(err.EventType, err.Chunks)
+	assert.Equal(t, err.Error(), err.Localize(nil))
+
+	_, ok := ChunkingSuggestion(events.EventTypeRunStarted, 2)
+	assert.False(t, ok)
+}
+
+func TestSizeBudgetMetrics(t *testing.T) {
+	budget := NewSizeBudget(SizeBudgetConfig{Limits: map[events.EventType]int{events.EventTypeToolCallResult: 2048}})
+	budget.Check(events.EventTypeToolCallResult, 100)
+	budget.Check(events.EventTypeToolCallResult, 3000)
+	budget.Check(events.EventTypeRunStarted, 80)
+
+	stats := budget.Stats()
+	assert.Equal(t, SizeStats{Events: 2, Rejected: 1, TotalBytes: 3100, MaxBytes: 3000}, stats[events.EventTypeToolCallResult])
+	assert.Equal(t, uint64(1), stats[events.EventTypeRunStarted].Events)
+
+	rec := httptest.NewRecorder()
+	budget.MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, line := range []string{
+		`agui_event_payload_bytes_bucket{type="TOOL_CALL_RESULT",le="256"} 1`,
+		`agui_event_payload_bytes_bucket{type="TOOL_CALL_RESULT",le="4096"} 2`,
+		`agui_event_payload_bytes_bucket{type="TOOL_CALL_RESULT",le="+Inf"} 2`,
+		`agui_event_payload_bytes_sum{type="TOOL_CALL_RESULT"} 3100`,
+		`agui_event_payload_rejected_total{type="TOOL_CALL_RESULT"} 1`,
+		`agui_event_payload_rejected_total{type="RUN_STARTED"} 0`,
+	} {
+		assert.Contains(t, body, line)
+	}
+}
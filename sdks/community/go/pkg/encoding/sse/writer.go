@@ -1,6 +1,7 @@
 package sse
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -11,30 +12,45 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/bufpool"
 	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding"
 	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding/encoder"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/logging"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/runctx"
 )
 
 // SSEWriter provides utilities for writing Server-Sent Events with proper framing
 type SSEWriter struct {
 	encoder *encoder.EventEncoder
 	logger  *slog.Logger
+	budget  *encoding.SizeBudget
 }
 
 // NewSSEWriter creates a new SSE writer
 func NewSSEWriter() *SSEWriter {
 	return &SSEWriter{
 		encoder: encoder.NewEventEncoder(),
-		logger:  slog.Default(),
+		logger:  logging.ContextLogger(slog.Default()),
 	}
 }
 
-// WithLogger sets a custom logger for the SSE writer
+// WithLogger sets a custom logger for the SSE writer. Loggers derived with
+// logging.ContextLogger, as the default one is, tag records with the run
+// metadata of the context events are written with.
 func (w *SSEWriter) WithLogger(logger *slog.Logger) *SSEWriter {
 	w.logger = logger
 	return w
 }
 
+// WithSizeBudget bounds the encoded size of the events written by type.
+// Events over their limit are not written, leaving the stream usable, and
+// fail with an *encoding.EventTooLargeError.
+func (w *SSEWriter) WithSizeBudget(budget *encoding.SizeBudget) *SSEWriter {
+	w.budget = budget
+	return w
+}
+
 // WriteEvent writes a single event as SSE format to the writer with proper framing
 // Format: data: <json>\n\n with proper escaping and flushing
 func (w *SSEWriter) WriteEvent(ctx context.Context, writer io.Writer, event events.Event) error {
@@ -44,16 +60,13 @@ func (w *SSEWriter) WriteEvent(ctx context.Context, writer io.Writer, event even
 // WriteBytes writes an event
 func (w *SSEWriter) WriteBytes(ctx context.Context, writer io.Writer, event []byte) error {
 
-	// Create SSE frame
-	sseFrame, err := w.createSSEFrame(event, "", nil)
-	if err != nil {
-		w.logger.ErrorContext(ctx, "Failed to create SSE frame",
-			"error", err)
-		return fmt.Errorf("SSE frame creation failed: %w", err)
-	}
+	// Create the SSE frame in a pooled buffer
+	frame := bufpool.Default().GetBuffer(len(event) + frameOverhead)
+	defer bufpool.Default().PutBuffer(frame)
+	writeSSEFrame(frame, event, "", "", nil)
 
 	// Write the SSE frame
-	_, err = writer.Write([]byte(sseFrame))
+	_, err := writer.Write(frame.Bytes())
 	if err != nil {
 		w.logger.ErrorContext(ctx, "Failed to write SSE frame",
 			"error", err)
@@ -76,6 +89,21 @@ func (w *SSEWriter) WriteBytes(ctx context.Context, writer io.Writer, event []by
 
 // WriteEventWithType writes an event with a specific SSE event type
 func (w *SSEWriter) WriteEventWithType(ctx context.Context, writer io.Writer, event events.Event, eventType string) error {
+	return w.writeEvent(ctx, writer, event, eventType, "")
+}
+
+// WriteEventWithID writes an event with id as its SSE event ID, e.g. the
+// continuity token of the event (see events.ContinuityToken), instead of
+// one derived from its type and timestamp
+func (w *SSEWriter) WriteEventWithID(ctx context.Context, writer io.Writer, event events.Event, id string) error {
+	if strings.ContainsAny(id, "\r\n") {
+		return fmt.Errorf("SSE event ID cannot contain line breaks")
+	}
+	return w.writeEvent(ctx, writer, event, "", id)
+}
+
+// writeEvent writes an event with an SSE event type and ID, if not empty
+func (w *SSEWriter) writeEvent(ctx context.Context, writer io.Writer, event events.Event, eventType, id string) error {
 	if event == nil {
 		return fmt.Errorf("event cannot be nil")
 	}
@@ -92,18 +120,20 @@ func (w *SSEWriter) WriteEventWithType(ctx context.Context, writer io.Writer, ev
 			"event_type", event.Type())
 		return fmt.Errorf("event encoding failed: %w", err)
 	}
-
-	// Create SSE frame
-	sseFrame, err := w.createSSEFrame(jsonData, eventType, event)
-	if err != nil {
-		w.logger.ErrorContext(ctx, "Failed to create SSE frame",
+	if err := w.budget.Check(event.Type(), len(jsonData)); err != nil {
+		w.logger.WarnContext(ctx, "Event over its size limit",
 			"error", err,
 			"event_type", event.Type())
-		return fmt.Errorf("SSE frame creation failed: %w", err)
+		return err
 	}
 
+	// Create the SSE frame in a pooled buffer
+	frame := bufpool.Default().GetBuffer(len(jsonData) + frameOverhead)
+	defer bufpool.Default().PutBuffer(frame)
+	writeSSEFrame(frame, jsonData, eventType, id, event)
+
 	// Write the SSE frame
-	_, err = writer.Write([]byte(sseFrame))
+	_, err = writer.Write(frame.Bytes())
 	if err != nil {
 		w.logger.ErrorContext(ctx, "Failed to write SSE frame",
 			"error", err,
@@ -142,7 +172,9 @@ func (w *SSEWriter) WriteEventWithNegotiation(ctx context.Context, writer io.Wri
 	return w.WriteEvent(ctx, writer, event)
 }
 
-// WriteErrorEvent writes an error as an SSE event
+// WriteErrorEvent writes an error as an SSE event. The run metadata of ctx,
+// or that err was tagged with (see runctx.WrapError), is included so that
+// clients can attribute the error.
 func (w *SSEWriter) WriteErrorEvent(ctx context.Context, writer io.Writer, err error, requestID string) error {
 	// Create a custom error event
 	errorEvent := &CustomEvent{
@@ -150,11 +182,25 @@ func (w *SSEWriter) WriteErrorEvent(ctx context.Context, writer io.Writer, err e
 			EventType: events.EventTypeCustom,
 		},
 	}
-	errorEvent.SetData(map[string]interface{}{
+	data := map[string]interface{}{
 		"error":      true,
 		"message":    err.Error(),
 		"request_id": requestID,
-	})
+	}
+	metadata := runctx.From(ctx)
+	if tagged, ok := runctx.FromError(err); ok {
+		metadata = metadata.Merge(tagged)
+	}
+	for key, value := range map[string]string{
+		logging.KeyThreadID: metadata.ThreadID,
+		logging.KeyRunID:    metadata.RunID,
+		logging.KeyTenant:   metadata.Tenant,
+	} {
+		if value != "" {
+			data[key] = value
+		}
+	}
+	errorEvent.SetData(data)
 
 	// Set timestamp
 	errorEvent.SetTimestamp(getCurrentTimestamp())
@@ -162,31 +208,53 @@ func (w *SSEWriter) WriteErrorEvent(ctx context.Context, writer io.Writer, err e
 	return w.WriteEventWithType(ctx, writer, errorEvent, "error")
 }
 
+// frameOverhead is room for the event and id lines of a frame, beyond its
+// data
+const frameOverhead = 128
+
 // createSSEFrame creates a properly formatted SSE frame
 func (w *SSEWriter) createSSEFrame(jsonData []byte, eventType string, event events.Event) (string, error) {
-	var frame strings.Builder
+	var frame bytes.Buffer
+	writeSSEFrame(&frame, jsonData, eventType, "", event)
+	return frame.String(), nil
+}
 
+// writeSSEFrame writes a properly formatted SSE frame to frame, with id as
+// its event ID or, when empty, one derived from event
+func writeSSEFrame(frame *bytes.Buffer, jsonData []byte, eventType, id string, event events.Event) {
 	// Add event type if specified
 	if eventType != "" {
-		frame.WriteString(fmt.Sprintf("event: %s\n", eventType))
+		frame.WriteString("event: ")
+		frame.WriteString(eventType)
+		frame.WriteByte('\n')
 	}
 
 	// Add event ID if available
-	if event != nil && event.Timestamp() != nil {
-		frame.WriteString(fmt.Sprintf("id: %s_%d\n", event.Type(), *event.Timestamp()))
+	if id != "" {
+		frame.WriteString("id: ")
+		frame.WriteString(id)
+		frame.WriteByte('\n')
+	} else if event != nil && event.Timestamp() != nil {
+		fmt.Fprintf(frame, "id: %s_%d\n", event.Type(), *event.Timestamp())
 	}
 
-	// Escape newlines in JSON data to maintain SSE format integrity
-	escapedData := strings.ReplaceAll(string(jsonData), "\n", "\\n")
-	escapedData = strings.ReplaceAll(escapedData, "\r", "\\r")
-
-	// Write data line
-	frame.WriteString(fmt.Sprintf("data: %s\n", escapedData))
+	// Write data line, escaping newlines in JSON data to maintain SSE
+	// format integrity
+	frame.WriteString("data: ")
+	for _, b := range jsonData {
+		switch b {
+		case '\n':
+			frame.WriteString(`\n`)
+		case '\r':
+			frame.WriteString(`\r`)
+		default:
+			frame.WriteByte(b)
+		}
+	}
+	frame.WriteByte('\n')
 
 	// End with empty line to complete the SSE event
-	frame.WriteString("\n")
-
-	return frame.String(), nil
+	frame.WriteByte('\n')
 }
 
 // flusher interface for writers that support flushing
@@ -13,6 +13,8 @@ import (
 	"time"
 
 	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/runctx"
 )
 
 type mockEvent struct {
@@ -361,6 +363,32 @@ func TestSSEWriter_WriteEventWithType(t *testing.T) {
 	}
 }
 
+func TestSSEWriter_WriteEventWithID(t *testing.T) {
+	event := &mockEvent{
+		BaseEvent: events.BaseEvent{
+			EventType:   events.EventTypeCustom,
+			TimestampMs: ptr(int64(1234567890)),
+		},
+	}
+	token := events.ContinuityToken{RunID: "run-1", Seq: 3}.String()
+
+	var buf bytes.Buffer
+	if err := NewSSEWriter().WriteEventWithID(context.Background(), &buf, event, token); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "id: "+token+"\ndata: ") {
+		t.Errorf("expected the given SSE id line, got: %q", buf.String())
+	}
+	if strings.Count(buf.String(), "id: ") != 1 {
+		t.Errorf("expected a single SSE id line, got: %q", buf.String())
+	}
+
+	err := NewSSEWriter().WriteEventWithID(context.Background(), &bytes.Buffer{}, event, "ct1.\ndata: forged")
+	if err == nil {
+		t.Fatal("expected an error for an ID spanning lines")
+	}
+}
+
 func TestSSEWriter_WriteEventWithNegotiation(t *testing.T) {
 	ctx := context.Background()
 	writer := NewSSEWriter()
@@ -408,6 +436,52 @@ func TestSSEWriter_WriteErrorEvent(t *testing.T) {
 	}
 }
 
+func TestSSEWriter_WriteErrorEventRunMetadata(t *testing.T) {
+	ctx := runctx.With(context.Background(), runctx.Metadata{Tenant: "acme"})
+	writer := NewSSEWriter()
+	var buf bytes.Buffer
+
+	err := runctx.WrapError(runctx.With(ctx, runctx.Metadata{ThreadID: "thread-1", RunID: "run-1"}), errors.New("test error"))
+	if err := writer.WriteErrorEvent(ctx, &buf, err, "req-123"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := buf.String()
+	for _, expected := range []string{`"thread_id":"thread-1"`, `"run_id":"run-1"`, `"tenant":"acme"`} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected %s in output: %s", expected, output)
+		}
+	}
+}
+
+func TestSSEWriter_SizeBudget(t *testing.T) {
+	budget := encoding.NewSizeBudget(encoding.SizeBudgetConfig{
+		Limits: map[events.EventType]int{events.EventTypeToolCallResult: 200},
+	})
+	writer := NewSSEWriter().WithSizeBudget(budget)
+	var buf bytes.Buffer
+
+	large := events.NewToolCallResultEvent("msg-1", "tool-1", strings.Repeat("x", 500))
+	err := writer.WriteEvent(context.Background(), &buf, large)
+	var tooLarge *encoding.EventTooLargeError
+	if !errors.As(err, &tooLarge) || !errors.Is(err, events.ErrEventTooLarge) {
+		t.Fatalf("expected an EventTooLargeError, got %v", err)
+	}
+	if tooLarge.Limit != 200 || tooLarge.Size < 500 || tooLarge.Chunks < 3 || tooLarge.Suggestion == "" {
+		t.Errorf("unexpected error details: %+v", tooLarge)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("oversized event was written: %s", buf.String())
+	}
+
+	if err := writer.WriteEvent(context.Background(), &buf, events.NewTextMessageContentEvent("msg-1", strings.Repeat("x", 500))); err != nil {
+		t.Errorf("types without a limit are not bounded: %v", err)
+	}
+	if stats := budget.Stats()[events.EventTypeToolCallResult]; stats.Events != 1 || stats.Rejected != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
 func TestSSEWriter_Flushing(t *testing.T) {
 	tests := []struct {
 		name        string
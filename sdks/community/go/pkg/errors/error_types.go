@@ -443,9 +443,15 @@ func GetSeverity(err error) Severity {
 		return e.BaseError.Severity
 	case *SecurityError:
 		return e.BaseError.Severity
+	case *PanicError:
+		return e.BaseError.Severity
 	}
 
 	// Check wrapped errors
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		return panicErr.Severity
+	}
 	var base *BaseError
 	if errors.As(err, &base) {
 		return base.Severity
@@ -8,6 +8,8 @@ import (
 	"math/rand"
 	"strings"
 	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
 )
 
 // Wrap wraps an error with additional context
@@ -124,6 +126,9 @@ type RetryConfig struct {
 
 	// OnRetry is called before each retry attempt
 	OnRetry func(attempt int, err error, delay time.Duration)
+
+	// Clock times the delays between attempts (defaults to the real clock)
+	Clock clock.Clock
 }
 
 // DefaultRetryConfig returns a default retry configuration
@@ -178,7 +183,7 @@ func Retry(ctx context.Context, config *RetryConfig, fn func() error) error {
 		select {
 		case <-ctx.Done():
 			return Chain(lastErr, ctx.Err())
-		case <-time.After(actualDelay):
+		case <-clock.Or(config.Clock).After(actualDelay):
 		}
 
 		// Calculate next delay
@@ -0,0 +1,80 @@
+package errors
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// CodePanic is the code of the errors converted from panics
+const CodePanic = "PANIC"
+
+// PanicError is a panic converted into a critical error, e.g. the panic of
+// an event handler, tool executor or agent, so that it ends what panicked
+// with a well-formed error rather than crashing the stream
+type PanicError struct {
+	*BaseError
+
+	// Value is the value passed to panic
+	Value interface{}
+
+	// Stack is the stack trace of the panicking goroutine, nil once redacted
+	Stack []byte
+}
+
+// NewPanicError creates a critical error for a panic with value, whose cause
+// is value when it is an error
+func NewPanicError(value interface{}, stack []byte) *PanicError {
+	e := &PanicError{
+		BaseError: &BaseError{
+			Code:      CodePanic,
+			Message:   fmt.Sprintf("panic: %v", value),
+			Severity:  SeverityCritical,
+			Timestamp: time.Now(),
+			Details:   make(map[string]interface{}),
+		},
+		Value: value,
+		Stack: stack,
+	}
+	if err, ok := value.(error); ok {
+		e.Cause = err
+	}
+	return e
+}
+
+// Error implements the error interface, leaving out the cause, which is
+// the panic value already in the message
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("[%s] %s: %s", e.Severity, e.Code, e.Message)
+}
+
+// Redacted returns a copy of the error without the panic value, its
+// details and the stack trace, which may hold secrets or internals, for
+// reports leaving the process in production
+func (e *PanicError) Redacted() *PanicError {
+	return &PanicError{
+		BaseError: &BaseError{
+			Code:      e.Code,
+			Message:   "panic",
+			Severity:  e.Severity,
+			Timestamp: e.Timestamp,
+			Details:   make(map[string]interface{}),
+		},
+	}
+}
+
+// Recover converts a panic of the calling function into a *PanicError
+// stored in *errp, capturing the stack trace. It must be deferred directly:
+//
+//	defer errors.Recover(&err)
+func Recover(errp *error) {
+	if r := recover(); r != nil {
+		*errp = NewPanicError(r, debug.Stack())
+	}
+}
+
+// Safely calls fn, returning its panic as a *PanicError
+func Safely(fn func() error) (err error) {
+	defer Recover(&err)
+	return fn()
+}
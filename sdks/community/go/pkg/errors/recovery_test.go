@@ -0,0 +1,39 @@
+package errors
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSafely(t *testing.T) {
+	assert.NoError(t, Safely(func() error { return nil }))
+	assert.Equal(t, io.EOF, Safely(func() error { return io.EOF }))
+
+	err := Safely(func() error { panic("boom") })
+	var panicErr *PanicError
+	require.True(t, errors.As(err, &panicErr))
+	assert.Equal(t, "boom", panicErr.Value)
+	assert.NotEmpty(t, panicErr.Stack)
+	assert.Equal(t, CodePanic, panicErr.Code)
+	assert.Equal(t, SeverityCritical, GetSeverity(err))
+	assert.Equal(t, SeverityCritical, GetSeverity(Wrap(err, "tool search")))
+	assert.Equal(t, "[CRITICAL] PANIC: panic: boom", err.Error())
+
+	err = Safely(func() error { panic(io.ErrUnexpectedEOF) })
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF), "error values are the cause")
+}
+
+func TestPanicErrorRedacted(t *testing.T) {
+	err := NewPanicError("secret token abc", []byte("goroutine 1 [running]"))
+	redacted := err.Redacted()
+	assert.Nil(t, redacted.Value)
+	assert.Nil(t, redacted.Stack)
+	assert.NotContains(t, redacted.Error(), "secret")
+	assert.Equal(t, SeverityCritical, redacted.Severity)
+	assert.Equal(t, err.Timestamp, redacted.Timestamp)
+	assert.Equal(t, "secret token abc", err.Value, "the original is left as it is")
+}
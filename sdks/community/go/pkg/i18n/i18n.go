@@ -0,0 +1,300 @@
+// Package i18n localizes the user facing messages of the SDK, e.g. the
+// messages of RUN_ERROR events and the suggestions of size limit errors.
+// Messages are identified by keys and carry their parameters, so that a
+// Catalog of templates per locale can render them in the language of the
+// user, falling back to their English default.
+package i18n
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale is the locale of the default messages, ending every
+// fallback chain
+const DefaultLocale = "en"
+
+// Message is a localizable message. Its templates, and Default, refer to
+// its parameters as {name}, e.g. "run exceeded timeout of {timeout}".
+type Message struct {
+	Key    string         `json:"key"`
+	Params map[string]any `json:"params,omitempty"`
+
+	// Default is the English template, used when no catalog translates Key
+	Default string `json:"-"`
+}
+
+// NewMessage creates a message whose parameters are given as name, value
+// pairs
+func NewMessage(key, defaultTemplate string, params ...any) Message {
+	m := Message{Key: key, Default: defaultTemplate}
+	for i := 0; i+1 < len(params); i += 2 {
+		if m.Params == nil {
+			m.Params = make(map[string]any, len(params)/2)
+		}
+		m.Params[fmt.Sprint(params[i])] = params[i+1]
+	}
+	return m
+}
+
+// String renders the message in English
+func (m Message) String() string {
+	return (*Localizer)(nil).Localize(m)
+}
+
+// Catalog holds the message templates of each locale. It is safe for
+// concurrent use.
+type Catalog struct {
+	mu        sync.RWMutex
+	templates map[string]map[string]string
+}
+
+// NewCatalog creates an empty catalog
+func NewCatalog() *Catalog {
+	return &Catalog{templates: make(map[string]map[string]string)}
+}
+
+// Add adds templates by message key to the locale, replacing those of the
+// same keys
+func (c *Catalog) Add(locale string, templates map[string]string) {
+	locale = CanonicalLocale(locale)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.templates[locale] == nil {
+		c.templates[locale] = make(map[string]string, len(templates))
+	}
+	for key, template := range templates {
+		c.templates[locale][key] = template
+	}
+}
+
+// AddJSON adds the templates of a JSON object mapping message keys to
+// templates to the locale
+func (c *Catalog) AddJSON(locale string, data []byte) error {
+	var templates map[string]string
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return fmt.Errorf("invalid catalog of %s: %w", locale, err)
+	}
+	c.Add(locale, templates)
+	return nil
+}
+
+// LoadFS adds the catalogs of the files of fsys matching pattern, each a
+// JSON object named after its locale, e.g. "locales/de-CH.json"
+func (c *Catalog) LoadFS(fsys fs.FS, pattern string) error {
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		if err := c.AddJSON(strings.TrimSuffix(path.Base(name), path.Ext(name)), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Template returns the template of key in the locale, without fallback
+func (c *Catalog) Template(locale, key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	template, ok := c.templates[CanonicalLocale(locale)][key]
+	return template, ok
+}
+
+// Locales returns the locales of the catalog, sorted
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	locales := make([]string, 0, len(c.templates))
+	for locale := range c.templates {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Localizer renders messages in the first locale of its fallback chain
+// translating them. A nil Localizer renders the default messages.
+type Localizer struct {
+	catalog *Catalog
+	chain   []string
+}
+
+// NewLocalizer creates a localizer rendering messages with the templates of
+// catalog in the first of locales translating them. Each locale falls back
+// to its parents, e.g. "pt-BR" to "pt", before the next one.
+func NewLocalizer(catalog *Catalog, locales ...string) *Localizer {
+	return &Localizer{catalog: catalog, chain: FallbackChain(locales...)}
+}
+
+// WithLocales returns a localizer with the same catalog trying locales
+// before those of l, e.g. the locales of a request
+func (l *Localizer) WithLocales(locales ...string) *Localizer {
+	if len(locales) == 0 {
+		return l
+	}
+	if l == nil {
+		return nil
+	}
+	return &Localizer{catalog: l.catalog, chain: FallbackChain(append(locales, l.chain...)...)}
+}
+
+// Locales returns the fallback chain of the localizer
+func (l *Localizer) Locales() []string {
+	if l == nil {
+		return nil
+	}
+	return append([]string{}, l.chain...)
+}
+
+// Localize renders m in the first locale of the fallback chain translating
+// it, in its default template otherwise. Parameters that are messages are
+// localized too.
+func (l *Localizer) Localize(m Message) string {
+	template := m.Default
+	if l != nil && l.catalog != nil && m.Key != "" {
+		for _, locale := range l.chain {
+			if t, ok := l.catalog.Template(locale, m.Key); ok {
+				template = t
+				break
+			}
+		}
+	}
+	if template == "" {
+		template = m.Key
+	}
+	return expand(template, func(name string) (string, bool) {
+		value, ok := m.Params[name]
+		if !ok {
+			return "", false
+		}
+		if nested, ok := value.(Message); ok {
+			return l.Localize(nested), true
+		}
+		return fmt.Sprint(value), true
+	})
+}
+
+// expand replaces the {name} placeholders of template with their values,
+// leaving unknown ones as they are
+func expand(template string, value func(string) (string, bool)) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+		b.WriteString(template[:start])
+		if v, ok := value(template[start+1 : end]); ok {
+			b.WriteString(v)
+		} else {
+			b.WriteString(template[start : end+1])
+		}
+		template = template[end+1:]
+	}
+	b.WriteString(template)
+	return b.String()
+}
+
+// CanonicalLocale returns locale in its canonical form, e.g. "pt-BR" for
+// "pt_br" and "zh-Hant-TW" for "zh-hant-tw"
+func CanonicalLocale(locale string) string {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	for i, part := range parts {
+		switch {
+		case i > 0 && len(part) == 2:
+			parts[i] = strings.ToUpper(part)
+		case i > 0 && len(part) == 4:
+			parts[i] = strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+		default:
+			parts[i] = strings.ToLower(part)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// FallbackChain returns the locales to try in turn for locales: each one
+// then its parents, without duplicates, then DefaultLocale
+func FallbackChain(locales ...string) []string {
+	var chain []string
+	seen := make(map[string]bool)
+	add := func(locale string) {
+		if locale != "" && !seen[locale] {
+			seen[locale] = true
+			chain = append(chain, locale)
+		}
+	}
+	for _, locale := range locales {
+		locale = CanonicalLocale(locale)
+		for locale != "" {
+			add(locale)
+			i := strings.LastIndexByte(locale, '-')
+			if i < 0 {
+				break
+			}
+			locale = locale[:i]
+		}
+	}
+	add(DefaultLocale)
+	return chain
+}
+
+// ParseAcceptLanguage returns the locales of an Accept-Language header, by
+// descending quality, leaving out the wildcard and the refused ones
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale  string
+		quality float64
+	}
+	var ranges []weighted
+	for _, part := range strings.Split(header, ",") {
+		locale, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if locale = strings.TrimSpace(locale); locale == "" || locale == "*" || quality <= 0 {
+			continue
+		}
+		ranges = append(ranges, weighted{CanonicalLocale(locale), quality})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].quality > ranges[j].quality })
+	locales := make([]string, len(ranges))
+	for i, r := range ranges {
+		locales[i] = r.locale
+	}
+	return locales
+}
+
+type localesKey struct{}
+
+// WithLocales returns a copy of ctx carrying the preferred locales of the
+// user, e.g. from the Accept-Language header of a request
+func WithLocales(ctx context.Context, locales []string) context.Context {
+	return context.WithValue(ctx, localesKey{}, locales)
+}
+
+// LocalesFromContext returns the locales stored in ctx, if any
+func LocalesFromContext(ctx context.Context) []string {
+	locales, _ := ctx.Value(localesKey{}).([]string)
+	return locales
+}
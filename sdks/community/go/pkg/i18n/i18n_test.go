@@ -0,0 +1,83 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalize(t *testing.T) {
+	catalog := NewCatalog()
+	catalog.Add("de", map[string]string{
+		"run.timeout": "Zeitlimit von {timeout} überschritten",
+		"run.failed":  "Lauf fehlgeschlagen: {reason}",
+		"run.reason":  "Kontingent erschöpft",
+	})
+	catalog.Add("de-CH", map[string]string{"run.timeout": "Zeitlimit von {timeout} überschritten (CH)"})
+
+	timeout := NewMessage("run.timeout", "run exceeded timeout of {timeout}", "timeout", 30*time.Second)
+
+	assert.Equal(t, "run exceeded timeout of 30s", timeout.String())
+	assert.Equal(t, "run exceeded timeout of 30s", (*Localizer)(nil).Localize(timeout))
+	assert.Equal(t, "Zeitlimit von 30s überschritten", NewLocalizer(catalog, "de").Localize(timeout))
+	assert.Equal(t, "Zeitlimit von 30s überschritten (CH)", NewLocalizer(catalog, "de_ch").Localize(timeout))
+	assert.Equal(t, "Zeitlimit von 30s überschritten", NewLocalizer(catalog, "de-AT").Localize(timeout), "falls back to the language")
+	assert.Equal(t, "Zeitlimit von 30s überschritten", NewLocalizer(catalog, "fr", "de").Localize(timeout), "falls back to the next locale")
+	assert.Equal(t, "run exceeded timeout of 30s", NewLocalizer(catalog, "fr").Localize(timeout), "falls back to the default")
+
+	t.Run("nested messages", func(t *testing.T) {
+		failed := NewMessage("run.failed", "run failed: {reason}", "reason", NewMessage("run.reason", "out of quota"))
+		assert.Equal(t, "Lauf fehlgeschlagen: Kontingent erschöpft", NewLocalizer(catalog, "de").Localize(failed))
+		assert.Equal(t, "run failed: out of quota", failed.String())
+	})
+
+	t.Run("unknown placeholders", func(t *testing.T) {
+		assert.Equal(t, "{missing} and {unclosed", Message{Default: "{missing} and {unclosed"}.String())
+		assert.Equal(t, "no.default", Message{Key: "no.default"}.String())
+	})
+
+	t.Run("request locales", func(t *testing.T) {
+		localizer := NewLocalizer(catalog, "fr")
+		assert.Same(t, localizer, localizer.WithLocales())
+		assert.Equal(t, []string{"de-CH", "de", "fr", "en"}, localizer.WithLocales("de-CH").Locales())
+		assert.Nil(t, (*Localizer)(nil).WithLocales("de"))
+	})
+}
+
+func TestCatalogLoading(t *testing.T) {
+	catalog := NewCatalog()
+	require.NoError(t, catalog.LoadFS(fstest.MapFS{
+		"locales/pt-BR.json": {Data: []byte(`{"run.cancelled": "execução cancelada"}`)},
+		"locales/es.json":    {Data: []byte(`{"run.cancelled": "ejecución cancelada"}`)},
+		"locales/README.md":  {Data: []byte("not a catalog")},
+	}, "locales/*.json"))
+	assert.Equal(t, []string{"es", "pt-BR"}, catalog.Locales())
+
+	template, ok := catalog.Template("pt_br", "run.cancelled")
+	assert.True(t, ok)
+	assert.Equal(t, "execução cancelada", template)
+	_, ok = catalog.Template("pt", "run.cancelled")
+	assert.False(t, ok, "lookups do not fall back")
+
+	assert.Error(t, catalog.AddJSON("fr", []byte(`["not", "an", "object"]`)))
+}
+
+func TestFallbackChain(t *testing.T) {
+	assert.Equal(t, []string{"en"}, FallbackChain())
+	assert.Equal(t, []string{"zh-Hant-TW", "zh-Hant", "zh", "en"}, FallbackChain("zh-hant-tw"))
+	assert.Equal(t, []string{"pt-BR", "pt", "en-GB", "en"}, FallbackChain("pt-BR", "en-GB", "pt"))
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	assert.Empty(t, ParseAcceptLanguage(""))
+	assert.Equal(t, []string{"fr-CH", "fr", "en", "de"}, ParseAcceptLanguage("fr-CH, fr;q=0.9, en;q=0.8, de;q=0.7, *;q=0.5"))
+	assert.Equal(t, []string{"de", "pt-BR"}, ParseAcceptLanguage("pt-br;q=0.5, de, es;q=0"))
+
+	ctx := WithLocales(context.Background(), []string{"de"})
+	assert.Equal(t, []string{"de"}, LocalesFromContext(ctx))
+	assert.Nil(t, LocalesFromContext(context.Background()))
+}
@@ -0,0 +1,94 @@
+package ingest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/deadletter"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/logging"
+)
+
+// maxBodySize bounds the size of a webhook request
+const maxBodySize = 10 << 20
+
+// Handler ingests envelopes posted by a webhook, as a JSON object or an
+// array of them, in order. It answers 204 once every envelope is
+// acknowledged, duplicates included, and an error status otherwise so that
+// the sender retries: 400 for a malformed request, 503 when an event is too
+// far ahead of its sequence or still held back once the request is ingested,
+// and 500 when delivery fails. Malformed and
+// invalid requests are captured to the dead letters of the ingester, if
+// any, as a whole.
+func (i *Ingester) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+		if err != nil {
+			http.Error(w, "failed to read request", http.StatusBadRequest)
+			return
+		}
+		var envelopes []Envelope
+		if body = bytes.TrimSpace(body); len(body) > 0 && body[0] == '[' {
+			err = json.Unmarshal(body, &envelopes)
+		} else {
+			envelopes = make([]Envelope, 1)
+			err = json.Unmarshal(body, &envelopes[0])
+		}
+		if err != nil {
+			i.config.DeadLetters.Capture(r.Context(), deadletter.NewLetter("ingest", body, err))
+			http.Error(w, "invalid envelope: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var held []int
+		for index, envelope := range envelopes {
+			err := i.Ingest(r.Context(), envelope)
+			if errors.Is(err, ErrHeldBack) {
+				held = append(held, index)
+				continue
+			}
+			if err != nil {
+				i.reject(w, r, body, index, envelope, err)
+				return
+			}
+		}
+
+		// The events held back may have been delivered by the following
+		// envelopes of the request, acknowledging their retry
+		for _, index := range held {
+			if err := i.Ingest(r.Context(), envelopes[index]); err != nil {
+				i.reject(w, r, body, index, envelopes[index], err)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// reject answers a webhook request whose envelope at index failed to be
+// ingested
+func (i *Ingester) reject(w http.ResponseWriter, r *http.Request, body []byte, index int, envelope Envelope, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidEnvelope):
+		letter := deadletter.NewLetter("ingest", body, err)
+		letter.RunID = envelope.RunID
+		letter.Attributes = map[string]string{"index": strconv.Itoa(index), "event_id": envelope.EventID}
+		i.config.DeadLetters.Capture(r.Context(), letter)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrTooFarAhead), errors.Is(err, ErrHeldBack):
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		i.config.Logger.Error("failed to ingest event",
+			logging.KeyRunID, envelope.RunID, "event_id", envelope.EventID, "error", err)
+		http.Error(w, "failed to ingest event", http.StatusInternalServerError)
+	}
+}
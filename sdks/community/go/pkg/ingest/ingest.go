@@ -0,0 +1,362 @@
+// Package ingest delivers events received from at-least-once transports,
+// such as Kafka consumers or webhook retries, exactly once and in order per
+// run. Each event travels in an Envelope identifying it by run and event ID;
+// an Ingester drops the events it already delivered, as recorded in a
+// pluggable Store, and holds back events that arrive ahead of their sequence
+// until the gap is filled, without acknowledging them so that a restart
+// loses none. Replaying a whole run, e.g. to backfill a consumer, is
+// therefore safe.
+//
+// Merge consolidates the envelopes of several sources into one ordered
+// stream without duplicates, and Split routes a stream to a channel per
+// thread, for services aggregating the traffic of many agents.
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/deadletter"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/logging"
+)
+
+const (
+	// defaultMaxPending is the default number of events held back per run
+	defaultMaxPending = 1000
+
+	// defaultHoldTimeout is the default time the events of a run are held
+	// back without the run receiving an envelope
+	defaultHoldTimeout = 10 * time.Minute
+)
+
+var (
+	// ErrInvalidEnvelope is returned for an envelope without a run ID, an
+	// event ID or an event
+	ErrInvalidEnvelope = errors.New("invalid envelope")
+
+	// ErrTooFarAhead is returned for an event that arrives ahead of its
+	// sequence while MaxPending events of its run are already held back. It
+	// is not acknowledged, so the transport should redeliver it later.
+	ErrTooFarAhead = errors.New("event too far ahead of its sequence")
+
+	// ErrHeldBack is returned for an event that arrives ahead of its
+	// sequence and is held back until the gap is filled. It is not
+	// acknowledged: the held events live in memory only, so the transport
+	// must redeliver it, e.g. by not committing the offset of a Kafka
+	// message. Once the event is delivered, its redelivery is dropped as a
+	// duplicate and acknowledged.
+	ErrHeldBack = errors.New("event held back until its sequence is filled")
+)
+
+// Envelope carries an event through a transport
+type Envelope struct {
+	RunID string `json:"runId"`
+
+	// EventID identifies the event within its run; redeliveries of an event
+	// keep its ID
+	EventID string `json:"eventId"`
+
+	// Sequence is the 1-based position of the event in its run. Events with
+	// a sequence are delivered in sequence order; events without one (0) are
+	// delivered in arrival order.
+	Sequence uint64 `json:"sequence,omitempty"`
+
+	Event events.Event `json:"event"`
+}
+
+// MarshalJSON encodes an envelope and its event
+func (e Envelope) MarshalJSON() ([]byte, error) {
+	var event json.RawMessage
+	if e.Event != nil {
+		data, err := e.Event.ToJSON()
+		if err != nil {
+			return nil, err
+		}
+		event = data
+	}
+	return json.Marshal(struct {
+		RunID    string          `json:"runId"`
+		EventID  string          `json:"eventId"`
+		Sequence uint64          `json:"sequence,omitempty"`
+		Event    json.RawMessage `json:"event"`
+	}{e.RunID, e.EventID, e.Sequence, event})
+}
+
+// UnmarshalJSON decodes an envelope and its event
+func (e *Envelope) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		RunID    string          `json:"runId"`
+		EventID  string          `json:"eventId"`
+		Sequence uint64          `json:"sequence"`
+		Event    json.RawMessage `json:"event"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*e = Envelope{RunID: raw.RunID, EventID: raw.EventID, Sequence: raw.Sequence}
+	if len(raw.Event) == 0 || string(raw.Event) == "null" {
+		return nil
+	}
+	event, err := events.EventFromJSON(raw.Event)
+	if err != nil {
+		return err
+	}
+	e.Event = event
+	return nil
+}
+
+func (e Envelope) validate() error {
+	if e.RunID == "" || e.EventID == "" || e.Event == nil {
+		return ErrInvalidEnvelope
+	}
+	return nil
+}
+
+// Consumer receives the events of a run, once each and in order
+type Consumer func(ctx context.Context, runID string, event events.Event) error
+
+// Config configures an Ingester
+type Config struct {
+	// Store records the delivered events (defaults to an in-memory store)
+	Store Store
+
+	// MaxPending bounds the events held back per run while waiting for an
+	// earlier event (defaults to 1000)
+	MaxPending int
+
+	// HoldTimeout drops the events held back for a run that received no
+	// envelope for this long, e.g. because the gap is never filled; they
+	// are redelivered by the transport since they were not acknowledged
+	// (defaults to 10 minutes)
+	HoldTimeout time.Duration
+
+	// Clock times the held events out (defaults to the real clock)
+	Clock clock.Clock
+
+	// Logger logs dropped duplicates at debug level (defaults to
+	// slog.Default())
+	Logger *slog.Logger
+
+	// DeadLetters captures the requests of the webhook rejected as
+	// malformed or invalid (nil = dropped)
+	DeadLetters *deadletter.Queue
+}
+
+// Ingester delivers envelopes to a consumer exactly once and in order per
+// run. It is safe for concurrent use; the events of a run are delivered one
+// at a time.
+//
+// An event is recorded in the store before it is delivered and the record is
+// released if the consumer fails, so that a redelivery retries it. Ordering
+// is kept within a process: route the events of a run to a single Ingester,
+// as Kafka does for a partition.
+type Ingester struct {
+	consumer Consumer
+	config   Config
+
+	mu    sync.Mutex
+	runs  map[string]*run
+	swept time.Time
+}
+
+// run is the delivery state of a run
+type run struct {
+	mu      sync.Mutex
+	users   int
+	loaded  bool
+	cursor  uint64
+	pending map[uint64]Envelope
+
+	// lastUse is when the run last received an envelope
+	lastUse time.Time
+}
+
+func (r *run) hold(envelope Envelope) {
+	if r.pending == nil {
+		r.pending = make(map[uint64]Envelope)
+	}
+	r.pending[envelope.Sequence] = envelope
+}
+
+// NewIngester creates an ingester delivering to consumer
+func NewIngester(consumer Consumer, config Config) *Ingester {
+	if config.Store == nil {
+		config.Store = NewMemoryStore(MemoryConfig{})
+	}
+	if config.MaxPending <= 0 {
+		config.MaxPending = defaultMaxPending
+	}
+	if config.HoldTimeout <= 0 {
+		config.HoldTimeout = defaultHoldTimeout
+	}
+	config.Clock = clock.Or(config.Clock)
+	config.Logger = logging.ForComponent(config.Logger, "ingest")
+	return &Ingester{
+		consumer: consumer,
+		config:   config,
+		runs:     make(map[string]*run),
+		swept:    config.Clock.Now(),
+	}
+}
+
+// Ingest delivers the event of envelope unless it was delivered before. An
+// event ahead of its sequence is held back, and delivered with the events
+// following it once the missing events arrive; ErrHeldBack is returned
+// meanwhile. A nil error acknowledges the envelope; on error, ErrHeldBack
+// included, the transport should redeliver it.
+func (i *Ingester) Ingest(ctx context.Context, envelope Envelope) error {
+	if err := envelope.validate(); err != nil {
+		return err
+	}
+	r := i.acquire(envelope.RunID)
+	defer i.release(envelope.RunID, r)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if envelope.Sequence == 0 {
+		return i.deliver(ctx, envelope)
+	}
+
+	if !r.loaded {
+		cursor, err := i.config.Store.Cursor(ctx, envelope.RunID)
+		if err != nil {
+			return err
+		}
+		r.cursor, r.loaded = cursor, true
+	}
+	switch {
+	case envelope.Sequence <= r.cursor:
+		i.duplicate(envelope)
+	case envelope.Sequence > r.cursor+1:
+		if _, ok := r.pending[envelope.Sequence]; !ok {
+			if len(r.pending) >= i.config.MaxPending {
+				return fmt.Errorf("%w: run %s expects %d, got %d", ErrTooFarAhead, envelope.RunID, r.cursor+1, envelope.Sequence)
+			}
+			r.hold(envelope)
+		}
+		return fmt.Errorf("%w: run %s expects %d, got %d", ErrHeldBack, envelope.RunID, r.cursor+1, envelope.Sequence)
+	default:
+		r.hold(envelope)
+	}
+	return i.drain(ctx, envelope.RunID, r)
+}
+
+// drain delivers the events of a run held back that follow its cursor. An
+// event whose delivery fails stays held back, to be retried by the next
+// envelope of the run.
+func (i *Ingester) drain(ctx context.Context, runID string, r *run) error {
+	for {
+		next, ok := r.pending[r.cursor+1]
+		if !ok {
+			return nil
+		}
+		if err := i.deliver(ctx, next); err != nil {
+			return err
+		}
+		if err := i.config.Store.SetCursor(ctx, runID, next.Sequence); err != nil {
+			return err
+		}
+		r.cursor = next.Sequence
+		delete(r.pending, next.Sequence)
+	}
+}
+
+// Pending returns the number of events of a run held back
+func (i *Ingester) Pending(runID string) int {
+	i.mu.Lock()
+	r, ok := i.runs[runID]
+	i.mu.Unlock()
+	if !ok {
+		return 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.pending)
+}
+
+// deliver claims the event of envelope and hands it to the consumer unless
+// it was claimed before
+func (i *Ingester) deliver(ctx context.Context, envelope Envelope) error {
+	claimed, err := i.config.Store.Claim(ctx, envelope.RunID, envelope.EventID)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		i.duplicate(envelope)
+		return nil
+	}
+	if err := i.consumer(ctx, envelope.RunID, envelope.Event); err != nil {
+		if releaseErr := i.config.Store.Release(ctx, envelope.RunID, envelope.EventID); releaseErr != nil {
+			return errors.Join(err, releaseErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (i *Ingester) duplicate(envelope Envelope) {
+	i.config.Logger.Debug("dropped duplicate event",
+		logging.KeyRunID, envelope.RunID, "event_id", envelope.EventID, "sequence", envelope.Sequence)
+}
+
+// acquire returns the state of a run, creating it if needed
+func (i *Ingester) acquire(runID string) *run {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.sweep()
+	r, ok := i.runs[runID]
+	if !ok {
+		r = &run{}
+		i.runs[runID] = r
+	}
+	r.users++
+	return r
+}
+
+// release drops the state of a run once it is unused and holds no events
+func (i *Ingester) release(runID string, r *run) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	r.users--
+	if r.users > 0 {
+		return
+	}
+	r.mu.Lock()
+	empty := len(r.pending) == 0
+	r.lastUse = i.config.Clock.Now()
+	r.mu.Unlock()
+	if empty {
+		delete(i.runs, runID)
+	}
+}
+
+// sweep drops, at most once per HoldTimeout, the unused runs holding events
+// back that received no envelope for HoldTimeout; callers must hold i.mu
+func (i *Ingester) sweep() {
+	now := i.config.Clock.Now()
+	if now.Sub(i.swept) < i.config.HoldTimeout {
+		return
+	}
+	i.swept = now
+	for runID, r := range i.runs {
+		if r.users > 0 {
+			continue
+		}
+		r.mu.Lock()
+		expired := now.Sub(r.lastUse) >= i.config.HoldTimeout
+		held := len(r.pending)
+		r.mu.Unlock()
+		if expired {
+			i.config.Logger.Warn("dropped events held back past the hold timeout",
+				logging.KeyRunID, runID, "pending", held)
+			delete(i.runs, runID)
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/deadletter"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/testhelper"
+)
+
+var quiet = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// recorder is a consumer recording the content of the events it receives
+type recorder struct {
+	mu       sync.Mutex
+	received map[string][]string
+	fail     func(delta string) error
+}
+
+func newRecorder() *recorder {
+	return &recorder{received: make(map[string][]string)}
+}
+
+func (r *recorder) consume(_ context.Context, runID string, event events.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delta := event.(*events.TextMessageContentEvent).Delta
+	if r.fail != nil {
+		if err := r.fail(delta); err != nil {
+			return err
+		}
+	}
+	r.received[runID] = append(r.received[runID], delta)
+	return nil
+}
+
+func (r *recorder) run(runID string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.received[runID]
+}
+
+// envelope returns the nth event of a run
+func envelope(runID string, n int, ordered bool) Envelope {
+	e := Envelope{
+		RunID:   runID,
+		EventID: fmt.Sprintf("evt-%d", n),
+		Event:   events.NewTextMessageContentEvent("msg-1", fmt.Sprint(n)),
+	}
+	if ordered {
+		e.Sequence = uint64(n)
+	}
+	return e
+}
+
+func TestIngestDeduplicates(t *testing.T) {
+	ctx := context.Background()
+	consumer := newRecorder()
+	ingester := NewIngester(consumer.consume, Config{Logger: quiet})
+
+	for _, n := range []int{1, 2, 1, 3, 2, 3} {
+		require.NoError(t, ingester.Ingest(ctx, envelope("run-1", n, false)))
+	}
+	require.NoError(t, ingester.Ingest(ctx, envelope("run-2", 1, false)))
+
+	assert.Equal(t, []string{"1", "2", "3"}, consumer.run("run-1"))
+	assert.Equal(t, []string{"1"}, consumer.run("run-2"))
+}
+
+func TestIngestOrders(t *testing.T) {
+	ctx := context.Background()
+	consumer := newRecorder()
+	ingester := NewIngester(consumer.consume, Config{Logger: quiet})
+
+	assert.ErrorIs(t, ingester.Ingest(ctx, envelope("run-1", 3, true)), ErrHeldBack)
+	assert.ErrorIs(t, ingester.Ingest(ctx, envelope("run-1", 2, true)), ErrHeldBack)
+	assert.ErrorIs(t, ingester.Ingest(ctx, envelope("run-1", 3, true)), ErrHeldBack)
+	assert.Empty(t, consumer.run("run-1"))
+	assert.Equal(t, 2, ingester.Pending("run-1"))
+
+	require.NoError(t, ingester.Ingest(ctx, envelope("run-1", 1, true)))
+	assert.Equal(t, []string{"1", "2", "3"}, consumer.run("run-1"))
+	assert.Zero(t, ingester.Pending("run-1"))
+
+	require.NoError(t, ingester.Ingest(ctx, envelope("run-1", 2, true)))
+	assert.Len(t, consumer.run("run-1"), 3)
+}
+
+func TestIngestTooFarAhead(t *testing.T) {
+	ctx := context.Background()
+	consumer := newRecorder()
+	ingester := NewIngester(consumer.consume, Config{MaxPending: 1, Logger: quiet})
+
+	assert.ErrorIs(t, ingester.Ingest(ctx, envelope("run-1", 2, true)), ErrHeldBack)
+	err := ingester.Ingest(ctx, envelope("run-1", 3, true))
+	assert.True(t, errors.Is(err, ErrTooFarAhead))
+
+	require.NoError(t, ingester.Ingest(ctx, envelope("run-1", 1, true)))
+	require.NoError(t, ingester.Ingest(ctx, envelope("run-1", 3, true)))
+	assert.Equal(t, []string{"1", "2", "3"}, consumer.run("run-1"))
+}
+
+func TestIngestRetriesFailedDeliveries(t *testing.T) {
+	ctx := context.Background()
+	consumer := newRecorder()
+	failures := 1
+	consumer.fail = func(delta string) error {
+		if delta == "2" && failures > 0 {
+			failures--
+			return errors.New("unavailable")
+		}
+		return nil
+	}
+	ingester := NewIngester(consumer.consume, Config{Logger: quiet})
+
+	assert.ErrorIs(t, ingester.Ingest(ctx, envelope("run-1", 2, true)), ErrHeldBack)
+	require.Error(t, ingester.Ingest(ctx, envelope("run-1", 1, true)), "delivering held back events fails")
+	assert.Equal(t, []string{"1"}, consumer.run("run-1"))
+	assert.Equal(t, 1, ingester.Pending("run-1"))
+
+	require.NoError(t, ingester.Ingest(ctx, envelope("run-1", 1, true)), "a redelivery retries them")
+	assert.Equal(t, []string{"1", "2"}, consumer.run("run-1"))
+
+	failures = 1
+	require.Error(t, ingester.Ingest(ctx, envelope("run-2", 2, false)))
+	require.NoError(t, ingester.Ingest(ctx, envelope("run-2", 2, false)))
+	assert.Equal(t, []string{"2"}, consumer.run("run-2"))
+}
+
+// cursorFailure is a store failing to save cursors
+type cursorFailure struct {
+	Store
+	fail bool
+}
+
+func (s *cursorFailure) SetCursor(ctx context.Context, runID string, sequence uint64) error {
+	if s.fail {
+		return errors.New("unavailable")
+	}
+	return s.Store.SetCursor(ctx, runID, sequence)
+}
+
+func TestIngestBackfill(t *testing.T) {
+	ctx := context.Background()
+	store := &cursorFailure{Store: NewMemoryStore(MemoryConfig{})}
+	consumer := newRecorder()
+
+	first := NewIngester(consumer.consume, Config{Store: store, Logger: quiet})
+	for n := 1; n <= 3; n++ {
+		require.NoError(t, first.Ingest(ctx, envelope("run-1", n, true)))
+	}
+	store.fail = true
+	require.Error(t, first.Ingest(ctx, envelope("run-1", 4, true)), "the event is delivered but its cursor is lost")
+	store.fail = false
+
+	// a restarted consumer replays the whole run
+	second := NewIngester(consumer.consume, Config{Store: store, Logger: quiet})
+	for n := 1; n <= 5; n++ {
+		require.NoError(t, second.Ingest(ctx, envelope("run-1", n, true)))
+	}
+	assert.Equal(t, []string{"1", "2", "3", "4", "5"}, consumer.run("run-1"))
+}
+
+func TestIngestRestartWhileHoldingBack(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(MemoryConfig{})
+	consumer := newRecorder()
+
+	first := NewIngester(consumer.consume, Config{Store: store, Logger: quiet})
+	require.NoError(t, first.Ingest(ctx, envelope("run-1", 1, true)))
+	assert.ErrorIs(t, first.Ingest(ctx, envelope("run-1", 3, true)), ErrHeldBack)
+
+	// The held back event is lost with the process, but was not
+	// acknowledged, so the transport redelivers it after the restart
+	second := NewIngester(consumer.consume, Config{Store: store, Logger: quiet})
+	require.NoError(t, second.Ingest(ctx, envelope("run-1", 2, true)))
+	require.NoError(t, second.Ingest(ctx, envelope("run-1", 3, true)))
+	assert.Equal(t, []string{"1", "2", "3"}, consumer.run("run-1"))
+}
+
+func TestIngestHoldTimeout(t *testing.T) {
+	ctx := context.Background()
+	clk := testhelper.NewFakeClock(time.Now())
+	consumer := newRecorder()
+	ingester := NewIngester(consumer.consume, Config{HoldTimeout: time.Minute, Clock: clk, Logger: quiet})
+
+	assert.ErrorIs(t, ingester.Ingest(ctx, envelope("run-1", 2, true)), ErrHeldBack)
+	clk.Advance(30 * time.Second)
+	assert.ErrorIs(t, ingester.Ingest(ctx, envelope("run-2", 2, true)), ErrHeldBack)
+	assert.Equal(t, 1, ingester.Pending("run-1"))
+
+	// The gap of run-1 is never filled; its events are dropped while those
+	// of run-2, held back for less than the timeout, are kept
+	clk.Advance(45 * time.Second)
+	require.NoError(t, ingester.Ingest(ctx, envelope("run-3", 1, true)))
+	assert.Zero(t, ingester.Pending("run-1"))
+	assert.Equal(t, 1, ingester.Pending("run-2"))
+
+	// A redelivery of the dropped event is held back anew
+	assert.ErrorIs(t, ingester.Ingest(ctx, envelope("run-1", 2, true)), ErrHeldBack)
+	require.NoError(t, ingester.Ingest(ctx, envelope("run-1", 1, true)))
+	assert.Equal(t, []string{"1", "2"}, consumer.run("run-1"))
+}
+
+func TestIngestConcurrentRedeliveries(t *testing.T) {
+	ctx := context.Background()
+	consumer := newRecorder()
+	ingester := NewIngester(consumer.consume, Config{Logger: quiet})
+
+	const runs, size = 4, 50
+	var deliveries []Envelope
+	for r := 0; r < runs; r++ {
+		for n := 1; n <= size; n++ {
+			for copies := rand.Intn(3) + 1; copies > 0; copies-- {
+				deliveries = append(deliveries, envelope(fmt.Sprint("run-", r), n, true))
+			}
+		}
+	}
+	rand.Shuffle(len(deliveries), func(i, j int) { deliveries[i], deliveries[j] = deliveries[j], deliveries[i] })
+
+	// The envelopes held back are not acknowledged, and redelivered once
+	// every envelope has been
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		unacked []Envelope
+	)
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := worker; i < len(deliveries); i += 8 {
+				err := ingester.Ingest(ctx, deliveries[i])
+				if errors.Is(err, ErrHeldBack) {
+					mu.Lock()
+					unacked = append(unacked, deliveries[i])
+					mu.Unlock()
+					continue
+				}
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+	for _, e := range unacked {
+		assert.NoError(t, ingester.Ingest(ctx, e))
+	}
+
+	want := make([]string, size)
+	for n := range want {
+		want[n] = fmt.Sprint(n + 1)
+	}
+	for r := 0; r < runs; r++ {
+		assert.Equal(t, want, consumer.run(fmt.Sprint("run-", r)))
+	}
+}
+
+func TestIngestRejectsInvalidEnvelopes(t *testing.T) {
+	ingester := NewIngester(newRecorder().consume, Config{Logger: quiet})
+	for _, e := range []Envelope{
+		{EventID: "evt-1", Event: events.NewTextMessageContentEvent("msg-1", "x")},
+		{RunID: "run-1", Event: events.NewTextMessageContentEvent("msg-1", "x")},
+		{RunID: "run-1", EventID: "evt-1"},
+	} {
+		assert.True(t, errors.Is(ingester.Ingest(context.Background(), e), ErrInvalidEnvelope))
+	}
+}
+
+func TestHandler(t *testing.T) {
+	consumer := newRecorder()
+	ingester := NewIngester(consumer.consume, Config{MaxPending: 1, Logger: quiet})
+	srv := httptest.NewServer(ingester.Handler())
+	defer srv.Close()
+
+	post := func(body string) int {
+		t.Helper()
+		resp, err := http.Post(srv.URL, "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	event := func(n int) string {
+		return fmt.Sprintf(`{"runId":"run-1","eventId":"evt-%d","sequence":%d,"event":{"type":"TEXT_MESSAGE_CONTENT","messageId":"msg-1","delta":"%d"}}`, n, n, n)
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, post(event(2)), "an event held back is not acknowledged")
+	assert.Equal(t, http.StatusServiceUnavailable, post(event(3)))
+	assert.Equal(t, http.StatusNoContent, post("["+event(1)+","+event(3)+"]"))
+	assert.Equal(t, http.StatusNoContent, post(event(1)), "a retried webhook is acknowledged")
+	assert.Equal(t, http.StatusNoContent, post(event(2)), "so is the retry of an event held back")
+	assert.Equal(t, []string{"1", "2", "3"}, consumer.run("run-1"))
+	assert.Equal(t, http.StatusNoContent, post("["+event(5)+","+event(4)+"]"), "a request may fill its own gaps")
+	assert.Equal(t, []string{"1", "2", "3", "4", "5"}, consumer.run("run-1"))
+
+	assert.Equal(t, http.StatusBadRequest, post(`{"runId":"run-1"`))
+	assert.Equal(t, http.StatusBadRequest, post(`{"runId":"run-1","eventId":"evt-9"}`))
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestHandlerDeadLetters(t *testing.T) {
+	var mu sync.Mutex
+	var letters []deadletter.Letter
+	queue := deadletter.NewQueue(deadletter.SinkFunc(func(_ context.Context, letter deadletter.Letter) error {
+		mu.Lock()
+		defer mu.Unlock()
+		letters = append(letters, letter)
+		return nil
+	}), deadletter.Config{Logger: quiet})
+	ingester := NewIngester(newRecorder().consume, Config{Logger: quiet, DeadLetters: queue})
+	srv := httptest.NewServer(ingester.Handler())
+	defer srv.Close()
+
+	for _, body := range []string{`{"runId":"run-1"`, `{"runId":"run-1","eventId":"evt-9"}`} {
+		resp, err := http.Post(srv.URL, "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, letters, 2)
+	assert.Equal(t, `{"runId":"run-1"`, string(letters[0].Data))
+	assert.Equal(t, "ingest", letters[0].Source)
+	assert.NotEmpty(t, letters[0].Error)
+	assert.Equal(t, "run-1", letters[1].RunID)
+	assert.Equal(t, map[string]string{"index": "0", "event_id": "evt-9"}, letters[1].Attributes)
+}
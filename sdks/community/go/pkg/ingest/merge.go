@@ -0,0 +1,244 @@
+package ingest
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/logging"
+)
+
+const (
+	// defaultDedupWindow is the default number of events Merge remembers to
+	// drop duplicates
+	defaultDedupWindow = 10000
+
+	// defaultThreadBuffer is the default capacity of the channels of Split
+	defaultThreadBuffer = 64
+)
+
+// MergeConfig configures Merge
+type MergeConfig struct {
+	// DedupWindow bounds the events remembered to drop their duplicates,
+	// oldest first (defaults to 10000)
+	DedupWindow int
+
+	// Buffer is the capacity of the merged channel
+	Buffer int
+
+	// Logger logs dropped duplicates at debug level (defaults to
+	// slog.Default())
+	Logger *slog.Logger
+}
+
+// Merge merges the envelopes of inputs, e.g. received from several
+// transports or replicas, into one channel. Each input must be ordered; the
+// merged channel is ordered by event timestamp, then by sequence within a
+// run, and holds each event once, as identified by its run and event ID.
+// Envelopes without an event ID are never deduplicated.
+//
+// An envelope is merged once every open input has one pending, so a stalled
+// input holds back the others. The merged channel is closed once every input
+// is, or ctx is done.
+func Merge(ctx context.Context, config MergeConfig, inputs ...<-chan Envelope) <-chan Envelope {
+	if config.DedupWindow <= 0 {
+		config.DedupWindow = defaultDedupWindow
+	}
+	config.Logger = logging.ForComponent(config.Logger, "ingest")
+	output := make(chan Envelope, config.Buffer)
+	m := &merger{
+		config: config,
+		inputs: inputs,
+		heads:  make([]*Envelope, len(inputs)),
+		open:   make([]bool, len(inputs)),
+		seen:   make(map[eventKey]struct{}, config.DedupWindow),
+	}
+	for i := range m.open {
+		m.open[i] = true
+	}
+	go m.run(ctx, output)
+	return output
+}
+
+// eventKey identifies an event across runs
+type eventKey struct {
+	runID   string
+	eventID string
+}
+
+// merger is the state of a Merge
+type merger struct {
+	config MergeConfig
+	inputs []<-chan Envelope
+
+	// heads are the envelopes pending per input, open whether the inputs
+	// are
+	heads []*Envelope
+	open  []bool
+
+	// seen are the events merged, window the order they were, oldest first
+	// from next once full
+	seen   map[eventKey]struct{}
+	window []eventKey
+	next   int
+}
+
+func (m *merger) run(ctx context.Context, output chan<- Envelope) {
+	defer close(output)
+	for {
+		if !m.fill(ctx) {
+			return
+		}
+		i := m.earliest()
+		if i < 0 {
+			return
+		}
+		envelope := *m.heads[i]
+		m.heads[i] = nil
+		if m.duplicate(envelope) {
+			m.config.Logger.Debug("dropped duplicate event",
+				logging.KeyRunID, envelope.RunID, "event_id", envelope.EventID, "sequence", envelope.Sequence)
+			continue
+		}
+		select {
+		case output <- envelope:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// fill receives the pending envelope of each open input, reporting false
+// when ctx is done
+func (m *merger) fill(ctx context.Context) bool {
+	for i, input := range m.inputs {
+		if !m.open[i] || m.heads[i] != nil {
+			continue
+		}
+		select {
+		case envelope, ok := <-input:
+			if ok {
+				m.heads[i] = &envelope
+			} else {
+				m.open[i] = false
+			}
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// earliest returns the input whose pending envelope comes first, -1 when
+// none is pending
+func (m *merger) earliest() int {
+	first := -1
+	for i, head := range m.heads {
+		if head != nil && (first < 0 || before(*head, *m.heads[first])) {
+			first = i
+		}
+	}
+	return first
+}
+
+// before reports whether a comes before b: by timestamp, then by sequence
+// for envelopes of the same run
+func before(a, b Envelope) bool {
+	if ta, tb := timestamp(a), timestamp(b); ta != tb {
+		return ta < tb
+	}
+	return a.RunID == b.RunID && a.Sequence < b.Sequence
+}
+
+func timestamp(envelope Envelope) int64 {
+	if envelope.Event == nil || envelope.Event.Timestamp() == nil {
+		return 0
+	}
+	return *envelope.Event.Timestamp()
+}
+
+// duplicate reports whether the event of envelope was merged before, and
+// remembers it otherwise
+func (m *merger) duplicate(envelope Envelope) bool {
+	if envelope.EventID == "" {
+		return false
+	}
+	key := eventKey{envelope.RunID, envelope.EventID}
+	if _, ok := m.seen[key]; ok {
+		return true
+	}
+	if len(m.window) < m.config.DedupWindow {
+		m.window = append(m.window, key)
+	} else {
+		delete(m.seen, m.window[m.next])
+		m.window[m.next] = key
+		m.next = (m.next + 1) % len(m.window)
+	}
+	m.seen[key] = struct{}{}
+	return false
+}
+
+// SplitConfig configures Split
+type SplitConfig struct {
+	// Buffer is the capacity of the channel of each thread (defaults to 64)
+	Buffer int
+}
+
+// Split routes the envelopes of input to a channel per thread, created with
+// the first envelope of the thread and passed to newThread, which typically
+// starts consuming it in a goroutine. The thread of a run is learned from
+// its RUN_STARTED event; envelopes of runs whose thread is unknown are routed
+// to the thread "". A full channel holds back every thread.
+//
+// Split returns once input is closed, or with the error of ctx once it is
+// done, closing the channels of the threads.
+func Split(ctx context.Context, input <-chan Envelope, config SplitConfig, newThread func(threadID string, thread <-chan Envelope)) error {
+	if config.Buffer <= 0 {
+		config.Buffer = defaultThreadBuffer
+	}
+	threads := make(map[string]chan Envelope)
+	defer func() {
+		for _, thread := range threads {
+			close(thread)
+		}
+	}()
+	runs := make(map[string]string)
+
+	for {
+		var envelope Envelope
+		select {
+		case e, ok := <-input:
+			if !ok {
+				return nil
+			}
+			envelope = e
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		threadID := runs[envelope.RunID]
+		if envelope.Event != nil {
+			if id := envelope.Event.ThreadID(); id != "" {
+				threadID = id
+			}
+			switch envelope.Event.Type() {
+			case events.EventTypeRunStarted:
+				runs[envelope.RunID] = threadID
+			case events.EventTypeRunFinished, events.EventTypeRunError:
+				delete(runs, envelope.RunID)
+			}
+		}
+
+		thread, ok := threads[threadID]
+		if !ok {
+			thread = make(chan Envelope, config.Buffer)
+			threads[threadID] = thread
+			newThread(threadID, thread)
+		}
+		select {
+		case thread <- envelope:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// stamped returns the nth event of a run, timestamped at ms
+func stamped(runID string, n int, ms int64) Envelope {
+	e := envelope(runID, n, true)
+	e.Event.SetTimestamp(ms)
+	return e
+}
+
+// source returns a closed channel holding envelopes
+func source(envelopes ...Envelope) <-chan Envelope {
+	ch := make(chan Envelope, len(envelopes))
+	for _, e := range envelopes {
+		ch <- e
+	}
+	close(ch)
+	return ch
+}
+
+func collect(ch <-chan Envelope) []string {
+	var merged []string
+	for e := range ch {
+		merged = append(merged, fmt.Sprintf("%s/%s", e.RunID, e.EventID))
+	}
+	return merged
+}
+
+func TestMergeOrdersAndDeduplicates(t *testing.T) {
+	replica1 := source(stamped("run-1", 1, 10), stamped("run-2", 1, 20), stamped("run-1", 2, 30))
+	replica2 := source(stamped("run-1", 1, 10), stamped("run-1", 2, 30), stamped("run-1", 3, 30), stamped("run-2", 2, 40))
+	anonymous := stamped("run-3", 2, 50)
+	anonymous.EventID = ""
+	transport := source(stamped("run-3", 1, 15), anonymous, anonymous)
+
+	merged := collect(Merge(context.Background(), MergeConfig{Logger: quiet}, replica1, replica2, transport))
+	assert.Equal(t, []string{
+		"run-1/evt-1",
+		"run-3/evt-1",
+		"run-2/evt-1",
+		"run-1/evt-2",
+		"run-1/evt-3",
+		"run-2/evt-2",
+		// Envelopes without an event ID are not deduplicated
+		"run-3/",
+		"run-3/",
+	}, merged)
+}
+
+func TestMergeOrdersBySequenceWithinRun(t *testing.T) {
+	merged := collect(Merge(context.Background(), MergeConfig{Logger: quiet},
+		source(stamped("run-1", 2, 10)),
+		source(stamped("run-1", 1, 10), stamped("run-1", 3, 10)),
+	))
+	assert.Equal(t, []string{"run-1/evt-1", "run-1/evt-2", "run-1/evt-3"}, merged)
+}
+
+func TestMergeDedupWindow(t *testing.T) {
+	var evts []Envelope
+	for n := 1; n <= 3; n++ {
+		evts = append(evts, stamped("run-1", n, int64(n)))
+	}
+	evts = append(evts, stamped("run-1", 3, 4), stamped("run-1", 1, 5))
+
+	merged := collect(Merge(context.Background(), MergeConfig{DedupWindow: 2, Logger: quiet}, source(evts...)))
+	assert.Equal(t, []string{"run-1/evt-1", "run-1/evt-2", "run-1/evt-3", "run-1/evt-1"}, merged,
+		"events older than the window are merged again")
+}
+
+func TestMergeStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stalled := make(chan Envelope)
+	merged := Merge(ctx, MergeConfig{Logger: quiet}, source(stamped("run-1", 1, 10)), stalled)
+	cancel()
+	assert.Empty(t, collect(merged), "a stalled input holds back the others")
+}
+
+func TestSplitByThread(t *testing.T) {
+	input := source(
+		Envelope{RunID: "run-1", EventID: "start", Event: events.NewRunStartedEvent("thread-1", "run-1")},
+		Envelope{RunID: "run-2", EventID: "start", Event: events.NewRunStartedEvent("thread-2", "run-2")},
+		envelope("run-1", 1, true),
+		envelope("run-2", 1, true),
+		envelope("run-3", 1, true),
+		Envelope{RunID: "run-1", EventID: "finish", Event: events.NewRunFinishedEvent("thread-1", "run-1")},
+		envelope("run-1", 2, true),
+	)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	received := make(map[string][]string)
+	err := Split(context.Background(), input, SplitConfig{}, func(threadID string, thread <-chan Envelope) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range thread {
+				mu.Lock()
+				received[threadID] = append(received[threadID], e.RunID+"/"+e.EventID)
+				mu.Unlock()
+			}
+		}()
+	})
+	require.NoError(t, err)
+	wg.Wait()
+
+	assert.Equal(t, map[string][]string{
+		"thread-1": {"run-1/start", "run-1/evt-1", "run-1/finish"},
+		"thread-2": {"run-2/start", "run-2/evt-1"},
+		"":         {"run-3/evt-1", "run-1/evt-2"},
+	}, received)
+}
+
+func TestSplitStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := Split(ctx, make(chan Envelope), SplitConfig{}, func(string, <-chan Envelope) {})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,102 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultRedisPrefix namespaces the keys of the records
+const defaultRedisPrefix = "ag-ui:ingest:"
+
+// RedisConfig configures a RedisStore
+type RedisConfig struct {
+	// Prefix is prepended to run IDs to form keys (defaults to
+	// "ag-ui:ingest:")
+	Prefix string `json:"prefix"`
+
+	// TTL drops the records of a run this long after its last event
+	// (defaults to 24h)
+	TTL time.Duration `json:"ttl"`
+}
+
+// RedisStore keeps the records in Redis, one set of event IDs and one cursor
+// per run, so that processes sharing it deliver each event once. The client
+// is owned by the caller.
+type RedisStore struct {
+	client redis.UniversalClient
+	config RedisConfig
+}
+
+// NewRedisStore creates a store using client
+func NewRedisStore(client redis.UniversalClient, config RedisConfig) *RedisStore {
+	if config.Prefix == "" {
+		config.Prefix = defaultRedisPrefix
+	}
+	if config.TTL <= 0 {
+		config.TTL = defaultTTL
+	}
+	return &RedisStore{client: client, config: config}
+}
+
+// Claim records an event as delivered
+func (s *RedisStore) Claim(ctx context.Context, runID, eventID string) (bool, error) {
+	key := s.eventsKey(runID)
+	var added *redis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		added = pipe.SAdd(ctx, key, eventID)
+		pipe.Expire(ctx, key, s.config.TTL)
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to claim event %s of run %s: %w", eventID, runID, err)
+	}
+	return added.Val() == 1, nil
+}
+
+// Release forgets a claimed event
+func (s *RedisStore) Release(ctx context.Context, runID, eventID string) error {
+	if err := s.client.SRem(ctx, s.eventsKey(runID), eventID).Err(); err != nil {
+		return fmt.Errorf("failed to release event %s of run %s: %w", eventID, runID, err)
+	}
+	return nil
+}
+
+// Cursor returns the sequence of the last event delivered in order
+func (s *RedisStore) Cursor(ctx context.Context, runID string) (uint64, error) {
+	cursor, err := s.client.Get(ctx, s.cursorKey(runID)).Uint64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read cursor of run %s: %w", runID, err)
+	}
+	return cursor, nil
+}
+
+// SetCursor records the sequence of the last event delivered in order
+func (s *RedisStore) SetCursor(ctx context.Context, runID string, sequence uint64) error {
+	if err := s.client.Set(ctx, s.cursorKey(runID), sequence, s.config.TTL).Err(); err != nil {
+		return fmt.Errorf("failed to save cursor of run %s: %w", runID, err)
+	}
+	return nil
+}
+
+// Forget drops the records of a run
+func (s *RedisStore) Forget(ctx context.Context, runID string) error {
+	if err := s.client.Del(ctx, s.eventsKey(runID), s.cursorKey(runID)).Err(); err != nil {
+		return fmt.Errorf("failed to forget run %s: %w", runID, err)
+	}
+	return nil
+}
+
+func (s *RedisStore) eventsKey(runID string) string {
+	return s.config.Prefix + runID + ":events"
+}
+
+func (s *RedisStore) cursorKey(runID string) string {
+	return s.config.Prefix + runID + ":cursor"
+}
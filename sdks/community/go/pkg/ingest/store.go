@@ -0,0 +1,137 @@
+package ingest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+)
+
+// defaultTTL is the default time the records of a run are kept after its
+// last event
+const defaultTTL = 24 * time.Hour
+
+// Store records the events delivered per run. Implementations are safe for
+// concurrent use; Claim must be atomic so that an event is claimed once
+// across the processes sharing the store.
+type Store interface {
+	// Claim records an event of a run as delivered, reporting false when it
+	// already was
+	Claim(ctx context.Context, runID, eventID string) (bool, error)
+
+	// Release undoes the Claim of an event whose delivery failed
+	Release(ctx context.Context, runID, eventID string) error
+
+	// Cursor returns the sequence of the last event of a run delivered in
+	// order (0 = none)
+	Cursor(ctx context.Context, runID string) (uint64, error)
+
+	// SetCursor records the sequence of the last event of a run delivered in
+	// order
+	SetCursor(ctx context.Context, runID string, sequence uint64) error
+
+	// Forget drops the records of a run; its events are delivered again if
+	// they are replayed
+	Forget(ctx context.Context, runID string) error
+}
+
+// MemoryConfig configures a MemoryStore
+type MemoryConfig struct {
+	// TTL drops the records of a run this long after its last event, bounding
+	// the window in which replays are detected (defaults to 24h)
+	TTL time.Duration
+
+	// Clock expires the records (defaults to the real clock)
+	Clock clock.Clock
+}
+
+// MemoryStore keeps the records in memory. It only deduplicates the events
+// seen by one process; use a RedisStore to share them.
+type MemoryStore struct {
+	config MemoryConfig
+
+	mu    sync.Mutex
+	runs  map[string]*memoryRun
+	swept time.Time
+}
+
+// memoryRun is the record of a run
+type memoryRun struct {
+	events    map[string]struct{}
+	cursor    uint64
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore(config MemoryConfig) *MemoryStore {
+	if config.TTL <= 0 {
+		config.TTL = defaultTTL
+	}
+	config.Clock = clock.Or(config.Clock)
+	return &MemoryStore{config: config, runs: make(map[string]*memoryRun), swept: config.Clock.Now()}
+}
+
+// Claim records an event as delivered
+func (s *MemoryStore) Claim(_ context.Context, runID, eventID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.run(runID)
+	if _, ok := r.events[eventID]; ok {
+		return false, nil
+	}
+	r.events[eventID] = struct{}{}
+	return true, nil
+}
+
+// Release forgets a claimed event
+func (s *MemoryStore) Release(_ context.Context, runID, eventID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.run(runID).events, eventID)
+	return nil
+}
+
+// Cursor returns the sequence of the last event delivered in order
+func (s *MemoryStore) Cursor(_ context.Context, runID string) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.run(runID).cursor, nil
+}
+
+// SetCursor records the sequence of the last event delivered in order
+func (s *MemoryStore) SetCursor(_ context.Context, runID string, sequence uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.run(runID).cursor = sequence
+	return nil
+}
+
+// Forget drops the records of a run
+func (s *MemoryStore) Forget(_ context.Context, runID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.runs, runID)
+	return nil
+}
+
+// run returns the live record of a run, creating it if needed, and extends
+// its expiry. Expired records are swept at most once per TTL.
+func (s *MemoryStore) run(runID string) *memoryRun {
+	now := s.config.Clock.Now()
+	if now.Sub(s.swept) >= s.config.TTL {
+		for id, r := range s.runs {
+			if !now.Before(r.expiresAt) {
+				delete(s.runs, id)
+			}
+		}
+		s.swept = now
+	}
+	r, ok := s.runs[runID]
+	if !ok || !now.Before(r.expiresAt) {
+		r = &memoryRun{events: make(map[string]struct{})}
+		s.runs[runID] = r
+	}
+	r.expiresAt = now.Add(s.config.TTL)
+	return r
+}
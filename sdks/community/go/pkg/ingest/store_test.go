@@ -0,0 +1,100 @@
+package ingest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/testhelper"
+)
+
+// backends returns constructors of every store, with a function advancing
+// their time
+func backends() map[string]func(t *testing.T) (Store, func(time.Duration)) {
+	return map[string]func(t *testing.T) (Store, func(time.Duration)){
+		"memory": func(t *testing.T) (Store, func(time.Duration)) {
+			clk := testhelper.NewFakeClock(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+			return NewMemoryStore(MemoryConfig{TTL: time.Hour, Clock: clk}), clk.Advance
+		},
+		"redis": func(t *testing.T) (Store, func(time.Duration)) {
+			mr := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			t.Cleanup(func() { _ = client.Close() })
+			return NewRedisStore(client, RedisConfig{TTL: time.Hour}), mr.FastForward
+		},
+	}
+}
+
+func TestStores(t *testing.T) {
+	ctx := context.Background()
+	for name, newStore := range backends() {
+		t.Run(name, func(t *testing.T) {
+			t.Run("Claim", func(t *testing.T) {
+				store, _ := newStore(t)
+				claimed, err := store.Claim(ctx, "run-1", "evt-1")
+				require.NoError(t, err)
+				assert.True(t, claimed)
+				claimed, err = store.Claim(ctx, "run-1", "evt-1")
+				require.NoError(t, err)
+				assert.False(t, claimed)
+				claimed, err = store.Claim(ctx, "run-2", "evt-1")
+				require.NoError(t, err)
+				assert.True(t, claimed, "event IDs are scoped to their run")
+
+				require.NoError(t, store.Release(ctx, "run-1", "evt-1"))
+				claimed, err = store.Claim(ctx, "run-1", "evt-1")
+				require.NoError(t, err)
+				assert.True(t, claimed, "released events are claimed again")
+			})
+
+			t.Run("Cursor", func(t *testing.T) {
+				store, _ := newStore(t)
+				cursor, err := store.Cursor(ctx, "run-1")
+				require.NoError(t, err)
+				assert.Zero(t, cursor)
+				require.NoError(t, store.SetCursor(ctx, "run-1", 7))
+				cursor, err = store.Cursor(ctx, "run-1")
+				require.NoError(t, err)
+				assert.Equal(t, uint64(7), cursor)
+			})
+
+			t.Run("Forget", func(t *testing.T) {
+				store, _ := newStore(t)
+				_, err := store.Claim(ctx, "run-1", "evt-1")
+				require.NoError(t, err)
+				require.NoError(t, store.SetCursor(ctx, "run-1", 1))
+				require.NoError(t, store.Forget(ctx, "run-1"))
+
+				cursor, err := store.Cursor(ctx, "run-1")
+				require.NoError(t, err)
+				assert.Zero(t, cursor)
+				claimed, err := store.Claim(ctx, "run-1", "evt-1")
+				require.NoError(t, err)
+				assert.True(t, claimed)
+			})
+
+			t.Run("Expiry", func(t *testing.T) {
+				store, advance := newStore(t)
+				_, err := store.Claim(ctx, "run-1", "evt-1")
+				require.NoError(t, err)
+				advance(30 * time.Minute)
+				_, err = store.Claim(ctx, "run-1", "evt-2")
+				require.NoError(t, err)
+				advance(45 * time.Minute)
+
+				claimed, err := store.Claim(ctx, "run-1", "evt-1")
+				require.NoError(t, err)
+				assert.False(t, claimed, "records are kept for TTL after the last event")
+				advance(2 * time.Hour)
+				claimed, err = store.Claim(ctx, "run-1", "evt-1")
+				require.NoError(t, err)
+				assert.True(t, claimed, "records expire after TTL")
+			})
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Package jsonpatch applies the RFC 6902 JSON Patch operations of
+// STATE_DELTA events to agent state
+package jsonpatch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// Apply applies JSON Patch operations to a decoded JSON document and returns
+// the updated document. The document may be modified in place. test
+// operations are accepted but not evaluated.
+func Apply(doc any, ops []events.JSONPatchOperation) (any, error) {
+	var err error
+	for _, op := range ops {
+		switch op.Op {
+		case "add", "replace":
+			doc, err = setPointer(doc, pointer(op.Path), op.Value, op.Op == "add")
+		case "remove":
+			doc, err = removePointer(doc, pointer(op.Path))
+		case "move", "copy":
+			var value any
+			value, err = getPointer(doc, pointer(op.From))
+			if err == nil && op.Op == "move" {
+				doc, err = removePointer(doc, pointer(op.From))
+			}
+			if err == nil {
+				doc, err = setPointer(doc, pointer(op.Path), value, true)
+			}
+		case "test":
+		default:
+			err = fmt.Errorf("unsupported operation %q", op.Op)
+		}
+		if err != nil {
+			return doc, err
+		}
+	}
+	return doc, nil
+}
+
+// pointer splits a JSON Pointer into unescaped tokens
+func pointer(path string) []string {
+	if path == "" {
+		return nil
+	}
+	tokens := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens
+}
+
+func getPointer(doc any, tokens []string) (any, error) {
+	for _, token := range tokens {
+		switch node := doc.(type) {
+		case map[string]any:
+			doc = node[token]
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("invalid index %q", token)
+			}
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("path not found at %q", token)
+		}
+	}
+	return doc, nil
+}
+
+func setPointer(doc any, tokens []string, value any, insert bool) (any, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	token, rest := tokens[0], tokens[1:]
+	switch node := doc.(type) {
+	case map[string]any:
+		child, err := setPointer(node[token], rest, value, insert)
+		if err != nil {
+			return doc, err
+		}
+		node[token] = child
+		return node, nil
+	case []any:
+		if token == "-" && len(rest) == 0 {
+			return append(node, value), nil
+		}
+		i, err := strconv.Atoi(token)
+		if err != nil || i < 0 || i > len(node) || (i == len(node) && !(insert && len(rest) == 0)) {
+			return doc, fmt.Errorf("invalid index %q", token)
+		}
+		if insert && len(rest) == 0 {
+			node = append(node, nil)
+			copy(node[i+1:], node[i:])
+			node[i] = value
+			return node, nil
+		}
+		child, err := setPointer(node[i], rest, value, insert)
+		if err != nil {
+			return doc, err
+		}
+		node[i] = child
+		return node, nil
+	case nil:
+		return setPointer(map[string]any{}, tokens, value, insert)
+	default:
+		return doc, fmt.Errorf("path not found at %q", token)
+	}
+}
+
+func removePointer(doc any, tokens []string) (any, error) {
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	token, rest := tokens[0], tokens[1:]
+	switch node := doc.(type) {
+	case map[string]any:
+		if len(rest) == 0 {
+			delete(node, token)
+			return node, nil
+		}
+		child, err := removePointer(node[token], rest)
+		if err != nil {
+			return doc, err
+		}
+		node[token] = child
+		return node, nil
+	case []any:
+		i, err := strconv.Atoi(token)
+		if err != nil || i < 0 || i >= len(node) {
+			return doc, fmt.Errorf("invalid index %q", token)
+		}
+		if len(rest) == 0 {
+			return append(node[:i], node[i+1:]...), nil
+		}
+		child, err := removePointer(node[i], rest)
+		if err != nil {
+			return doc, err
+		}
+		node[i] = child
+		return node, nil
+	default:
+		return doc, fmt.Errorf("path not found at %q", token)
+	}
+}
@@ -0,0 +1,461 @@
+// Package loadtest generates load against an AG-UI server streaming over
+// Server-Sent Events: it keeps up to a number of streams open, starts runs
+// at a target rate and reports the distributions of latency, throughput and
+// errors, for capacity planning. cmd/agui-load is its command line.
+package loadtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+// Error kinds of failed runs
+const (
+	// ErrorConnect is a run whose request failed before a response
+	ErrorConnect = "connect"
+
+	// ErrorStatus is a run rejected with a status other than 200
+	ErrorStatus = "status"
+
+	// ErrorStream is a run whose stream broke
+	ErrorStream = "stream"
+
+	// ErrorRun is a run ended by a RUN_ERROR event
+	ErrorRun = "run_error"
+
+	// ErrorTimeout is a run cut by Config.RunTimeout
+	ErrorTimeout = "timeout"
+
+	// ErrorIncomplete is a run whose stream ended without RUN_FINISHED
+	ErrorIncomplete = "incomplete"
+
+	// ErrorCancelled is a run in flight when the test was cancelled
+	ErrorCancelled = "cancelled"
+)
+
+// maxErrorSamples bounds the distinct error messages kept per kind
+const maxErrorSamples = 3
+
+// Config configures a load test
+type Config struct {
+	// Client configures the SSE client; its logger defaults to warnings only
+	Client sse.Config
+
+	// Streams is the number of streams open at once (defaults to 1)
+	Streams int
+
+	// Rate is the number of runs started per second (0 = start a run as
+	// soon as a stream is free). Runs due while every stream is busy are
+	// skipped, so that a saturated server shows as skipped runs.
+	Rate float64
+
+	// Runs stops the test after this many runs are started (0 = no limit)
+	Runs int
+
+	// Duration stops starting runs after this long (0 = no limit); one of
+	// Runs and Duration is required
+	Duration time.Duration
+
+	// RunTimeout bounds each run (0 = no timeout)
+	RunTimeout time.Duration
+
+	// Input returns the input of the i-th run (defaults to a user message
+	// on a fresh thread)
+	Input func(i int) types.RunAgentInput
+
+	// OnResult receives each run as it ends, e.g. to feed live metrics
+	OnResult func(Result)
+}
+
+// Result is the measurements of one run
+type Result struct {
+	Start time.Time
+
+	// FirstEvent is the time from the request to the first event
+	FirstEvent time.Duration
+
+	// Duration is the time from the request to the end of the stream
+	Duration time.Duration
+
+	Events int
+
+	// ErrorKind is one of the Error constants, empty for a successful run
+	ErrorKind string
+	Err       error
+}
+
+// Distribution summarizes a series of samples
+type Distribution struct {
+	Count int     `json:"count"`
+	Min   float64 `json:"min"`
+	Mean  float64 `json:"mean"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P95   float64 `json:"p95"`
+	P99   float64 `json:"p99"`
+	Max   float64 `json:"max"`
+}
+
+// Report is the result of a load test. Durations are in milliseconds.
+type Report struct {
+	Endpoint string  `json:"endpoint"`
+	Streams  int     `json:"streams"`
+	Rate     float64 `json:"rate"`
+
+	ElapsedMs    float64 `json:"elapsedMs"`
+	Started      int     `json:"started"`
+	Succeeded    int     `json:"succeeded"`
+	Failed       int     `json:"failed"`
+	Skipped      int     `json:"skipped"`
+	RunsPerSec   float64 `json:"runsPerSec"`
+	EventsPerSec float64 `json:"eventsPerSec"`
+	ErrorRate    float64 `json:"errorRate"`
+
+	// Errors counts the failed runs by kind, with samples of their messages
+	Errors       map[string]int      `json:"errors,omitempty"`
+	ErrorSamples map[string][]string `json:"errorSamples,omitempty"`
+
+	FirstEventMs Distribution `json:"firstEventMs"`
+	DurationMs   Distribution `json:"durationMs"`
+	EventsPerRun Distribution `json:"eventsPerRun"`
+}
+
+// Run generates load until Runs runs have been started or Duration has
+// elapsed, waits for the runs in flight and reports. Cancelling ctx stops
+// the test at once, counting the runs in flight as cancelled. Run returns
+// an error only for an invalid config.
+func Run(ctx context.Context, config Config) (*Report, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	if config.Streams == 0 {
+		config.Streams = 1
+	}
+	if config.Input == nil {
+		config.Input = defaultInput
+	}
+	if config.Client.Logger == nil {
+		config.Client.Logger = logrus.New()
+		config.Client.Logger.SetLevel(logrus.WarnLevel)
+	}
+	client := sse.NewClient(config.Client)
+	defer client.Close()
+
+	submit := ctx
+	if config.Duration > 0 {
+		var cancel context.CancelFunc
+		submit, cancel = context.WithTimeout(ctx, config.Duration)
+		defer cancel()
+	}
+
+	var (
+		mu      sync.Mutex
+		results []Result
+		wg      sync.WaitGroup
+	)
+	slots := make(chan struct{}, config.Streams)
+	skipped := 0
+	start := time.Now()
+
+	var tick <-chan time.Time
+	if config.Rate > 0 {
+		ticker := time.NewTicker(max(time.Duration(float64(time.Second)/config.Rate), time.Microsecond))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for i := 0; config.Runs == 0 || i < config.Runs; {
+		if tick != nil {
+			select {
+			case <-tick:
+			case <-submit.Done():
+			}
+			if submit.Err() != nil {
+				break
+			}
+			select {
+			case slots <- struct{}{}:
+			default:
+				skipped++
+				continue
+			}
+		} else {
+			select {
+			case slots <- struct{}{}:
+			case <-submit.Done():
+			}
+			if submit.Err() != nil {
+				break
+			}
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			r := measure(ctx, client, config, config.Input(i))
+			mu.Lock()
+			results = append(results, r)
+			mu.Unlock()
+			if config.OnResult != nil {
+				config.OnResult(r)
+			}
+		}(i)
+		i++
+	}
+	wg.Wait()
+
+	return report(config, results, skipped, time.Since(start)), nil
+}
+
+func (c Config) validate() error {
+	if err := c.Client.Validate(); err != nil {
+		return err
+	}
+	if c.Client.Endpoint == "" {
+		return errors.New("an endpoint is required")
+	}
+	if c.Streams < 0 || c.Runs < 0 || c.Duration < 0 || c.RunTimeout < 0 {
+		return errors.New("streams, runs and durations must not be negative")
+	}
+	if c.Rate < 0 || math.IsInf(c.Rate, 0) || math.IsNaN(c.Rate) {
+		return fmt.Errorf("invalid rate %v", c.Rate)
+	}
+	if c.Runs == 0 && c.Duration == 0 {
+		return errors.New("a number of runs or a duration is required")
+	}
+	return nil
+}
+
+func defaultInput(i int) types.RunAgentInput {
+	return types.RunAgentInput{
+		ThreadID: "load-" + uuid.NewString(),
+		RunID:    "run-" + uuid.NewString(),
+		State:    map[string]any{},
+		Messages: []types.Message{{
+			ID:      "msg-" + uuid.NewString(),
+			Role:    types.RoleUser,
+			Content: fmt.Sprintf("Load test run %d", i+1),
+		}},
+		Tools:          []types.Tool{},
+		Context:        []types.Context{},
+		ForwardedProps: map[string]any{},
+	}
+}
+
+// measure streams one run to its end
+func measure(ctx context.Context, client *sse.Client, config Config, input types.RunAgentInput) Result {
+	if config.RunTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.RunTimeout)
+		defer cancel()
+	}
+
+	r := Result{Start: time.Now()}
+	fail := func(kind string, err error) Result {
+		switch {
+		case errors.Is(ctx.Err(), context.Canceled):
+			kind = ErrorCancelled
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			kind = ErrorTimeout
+		}
+		r.Duration = time.Since(r.Start)
+		r.ErrorKind, r.Err = kind, err
+		return r
+	}
+
+	frames, errs, err := client.Stream(sse.StreamOptions{Context: ctx, Payload: input})
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "unexpected status code") {
+			return fail(ErrorStatus, err)
+		}
+		return fail(ErrorConnect, err)
+	}
+
+	finished := false
+	var runErr error
+	for frames != nil || errs != nil {
+		select {
+		case frame, ok := <-frames:
+			if !ok {
+				frames = nil
+				continue
+			}
+			if r.Events == 0 {
+				r.FirstEvent = time.Since(r.Start)
+			}
+			r.Events++
+			var event struct {
+				Type    events.EventType `json:"type"`
+				Message string           `json:"message"`
+			}
+			if json.Unmarshal(frame.Data, &event) != nil {
+				continue
+			}
+			switch event.Type {
+			case events.EventTypeRunFinished:
+				finished = true
+			case events.EventTypeRunError:
+				runErr = fmt.Errorf("run error: %s", event.Message)
+			}
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			if err != nil {
+				return fail(ErrorStream, err)
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return fail(ErrorTimeout, err)
+	}
+	if runErr != nil {
+		return fail(ErrorRun, runErr)
+	}
+	if !finished {
+		return fail(ErrorIncomplete, errors.New("stream ended without RUN_FINISHED"))
+	}
+	r.Duration = time.Since(r.Start)
+	return r
+}
+
+func report(config Config, results []Result, skipped int, elapsed time.Duration) *Report {
+	rep := &Report{
+		Endpoint:  config.Client.Endpoint,
+		Streams:   config.Streams,
+		Rate:      config.Rate,
+		ElapsedMs: ms(elapsed),
+		Started:   len(results),
+		Skipped:   skipped,
+	}
+
+	var firstEvent, durations, counts []float64
+	total := 0
+	for _, r := range results {
+		total += r.Events
+		if r.ErrorKind != "" {
+			rep.Failed++
+			if rep.Errors == nil {
+				rep.Errors = make(map[string]int)
+				rep.ErrorSamples = make(map[string][]string)
+			}
+			rep.Errors[r.ErrorKind]++
+			samples := rep.ErrorSamples[r.ErrorKind]
+			if msg := r.Err.Error(); len(samples) < maxErrorSamples && !contains(samples, msg) {
+				rep.ErrorSamples[r.ErrorKind] = append(samples, msg)
+			}
+			continue
+		}
+		rep.Succeeded++
+		firstEvent = append(firstEvent, ms(r.FirstEvent))
+		durations = append(durations, ms(r.Duration))
+		counts = append(counts, float64(r.Events))
+	}
+	if elapsed > 0 {
+		rep.RunsPerSec = float64(rep.Succeeded) / elapsed.Seconds()
+		rep.EventsPerSec = float64(total) / elapsed.Seconds()
+	}
+	if len(results) > 0 {
+		rep.ErrorRate = float64(rep.Failed) / float64(len(results))
+	}
+	rep.FirstEventMs = distribution(firstEvent)
+	rep.DurationMs = distribution(durations)
+	rep.EventsPerRun = distribution(counts)
+	return rep
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func distribution(samples []float64) Distribution {
+	if len(samples) == 0 {
+		return Distribution{}
+	}
+	sort.Float64s(samples)
+	var sum float64
+	for _, s := range samples {
+		sum += s
+	}
+	return Distribution{
+		Count: len(samples),
+		Min:   samples[0],
+		Mean:  sum / float64(len(samples)),
+		P50:   percentile(samples, 50),
+		P90:   percentile(samples, 90),
+		P95:   percentile(samples, 95),
+		P99:   percentile(samples, 99),
+		Max:   samples[len(samples)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile p of sorted samples
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank-1, 0)]
+}
+
+// String renders the report as a table
+func (r *Report) String() string {
+	var b strings.Builder
+	rate := "unlimited"
+	if r.Rate > 0 {
+		rate = fmt.Sprintf("%.1f runs/s", r.Rate)
+	}
+	fmt.Fprintf(&b, "Load test of %s: %d streams, rate %s\n", r.Endpoint, r.Streams, rate)
+	fmt.Fprintf(&b, "Elapsed %.0f ms: %d started, %d succeeded, %d failed (%.1f%%), %d skipped\n",
+		r.ElapsedMs, r.Started, r.Succeeded, r.Failed, r.ErrorRate*100, r.Skipped)
+	fmt.Fprintf(&b, "Throughput: %.2f runs/s, %.1f events/s\n", r.RunsPerSec, r.EventsPerSec)
+
+	kinds := make([]string, 0, len(r.Errors))
+	for kind := range r.Errors {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		fmt.Fprintf(&b, "  %s: %d\n", kind, r.Errors[kind])
+		for _, sample := range r.ErrorSamples[kind] {
+			fmt.Fprintf(&b, "    %s\n", sample)
+		}
+	}
+
+	fmt.Fprintf(&b, "\n%-20s %7s %9s %9s %9s %9s %9s %9s %9s\n", "", "count", "min", "mean", "p50", "p90", "p95", "p99", "max")
+	for _, row := range []struct {
+		name string
+		d    Distribution
+	}{
+		{"first event (ms)", r.FirstEventMs},
+		{"duration (ms)", r.DurationMs},
+		{"events per run", r.EventsPerRun},
+	} {
+		d := row.d
+		fmt.Fprintf(&b, "%-20s %7d %9.1f %9.1f %9.1f %9.1f %9.1f %9.1f %9.1f\n",
+			row.name, d.Count, d.Min, d.Mean, d.P50, d.P90, d.P95, d.P99, d.Max)
+	}
+	return b.String()
+}
@@ -0,0 +1,130 @@
+package loadtest
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/testhelper"
+)
+
+func TestRunReportsDistributions(t *testing.T) {
+	server := testhelper.NewMockAGUIServer()
+	defer server.Close()
+
+	var mu sync.Mutex
+	var results []Result
+	report, err := Run(context.Background(), Config{
+		Client:  sse.Config{Endpoint: server.Endpoint()},
+		Streams: 4,
+		Runs:    20,
+		OnResult: func(r Result) {
+			mu.Lock()
+			defer mu.Unlock()
+			results = append(results, r)
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 20, report.Started)
+	assert.Equal(t, 20, report.Succeeded)
+	assert.Zero(t, report.Failed)
+	assert.Empty(t, report.Errors)
+	assert.Len(t, results, 20)
+	assert.Len(t, server.Requests(), 20)
+	assert.Equal(t, 20, report.DurationMs.Count)
+	assert.Equal(t, Distribution{Count: 20, Min: 5, Mean: 5, P50: 5, P90: 5, P95: 5, P99: 5, Max: 5}, report.EventsPerRun)
+	assert.LessOrEqual(t, report.FirstEventMs.Max, report.DurationMs.Max)
+	assert.Greater(t, report.RunsPerSec, 0.0)
+	assert.Contains(t, report.String(), "20 succeeded")
+}
+
+func TestRunClassifiesErrors(t *testing.T) {
+	server := testhelper.NewMockAGUIServer()
+	defer server.Close()
+	server.Script(testhelper.Fail(http.StatusServiceUnavailable))
+	server.Script(
+		testhelper.Emit(events.NewRunStartedEvent("thread-1", "run-1")),
+		testhelper.Emit(events.NewRunErrorEvent("agent crashed")),
+	)
+	server.Script(testhelper.Emit(events.NewRunStartedEvent("thread-1", "run-1")))
+	server.Script(testhelper.Sleep(time.Second))
+	faults := testhelper.NewFaultTransport(nil, 1, testhelper.Scenario{RefuseConnection: true})
+
+	report, err := Run(context.Background(), Config{
+		Client:     sse.Config{Endpoint: server.Endpoint(), WrapTransport: faults.Wrap},
+		Runs:       6,
+		RunTimeout: 200 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 6, report.Started)
+	assert.Equal(t, 1, report.Succeeded)
+	assert.Equal(t, 5, report.Failed)
+	assert.InDelta(t, 5.0/6, report.ErrorRate, 0.001)
+	assert.Equal(t, map[string]int{
+		ErrorConnect:    1,
+		ErrorStatus:     1,
+		ErrorRun:        1,
+		ErrorIncomplete: 1,
+		ErrorTimeout:    1,
+	}, report.Errors)
+	assert.Equal(t, []string{"run error: agent crashed"}, report.ErrorSamples[ErrorRun])
+}
+
+func TestRunSkipsRunsWhenSaturated(t *testing.T) {
+	server := testhelper.NewMockAGUIServer()
+	defer server.Close()
+	for i := 0; i < 50; i++ {
+		server.Script(append([]testhelper.Step{testhelper.Sleep(100 * time.Millisecond)}, testhelper.TextReply("thread-1", "run-1", "hi")...)...)
+	}
+
+	report, err := Run(context.Background(), Config{
+		Client:   sse.Config{Endpoint: server.Endpoint()},
+		Streams:  1,
+		Rate:     50,
+		Duration: 300 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	assert.GreaterOrEqual(t, report.Started, 1)
+	assert.LessOrEqual(t, report.Started, 4)
+	assert.Greater(t, report.Skipped, 0)
+	assert.Equal(t, report.Started, report.Succeeded)
+}
+
+func TestRunCancel(t *testing.T) {
+	server := testhelper.NewMockAGUIServer()
+	defer server.Close()
+	server.Script(testhelper.Sleep(time.Minute))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	report, err := Run(ctx, Config{Client: sse.Config{Endpoint: server.Endpoint()}, Runs: 10})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Started)
+	assert.Equal(t, map[string]int{ErrorCancelled: 1}, report.Errors)
+}
+
+func TestRunValidatesConfig(t *testing.T) {
+	for name, config := range map[string]Config{
+		"no endpoint":      {Runs: 1},
+		"bad endpoint":     {Client: sse.Config{Endpoint: "ftp://agent"}, Runs: 1},
+		"no limit":         {Client: sse.Config{Endpoint: "http://agent"}},
+		"negative rate":    {Client: sse.Config{Endpoint: "http://agent"}, Runs: 1, Rate: -1},
+		"negative streams": {Client: sse.Config{Endpoint: "http://agent"}, Runs: 1, Streams: -1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Run(context.Background(), config)
+			assert.Error(t, err)
+		})
+	}
+}
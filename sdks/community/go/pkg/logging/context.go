@@ -0,0 +1,161 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/runctx"
+)
+
+// KeyTenant identifies the tenant a record is about
+const KeyTenant = "tenant"
+
+// ContextLogger returns a logger tagging the records logged with a context,
+// e.g. through InfoContext, with the run metadata of that context; see
+// NewContextHandler.
+func ContextLogger(logger *slog.Logger) *slog.Logger {
+	logger = Or(logger)
+	if _, ok := logger.Handler().(*contextHandler); ok {
+		return logger
+	}
+	return slog.New(NewContextHandler(logger.Handler()))
+}
+
+// NewContextHandler returns a handler adding the run metadata (see
+// runctx.With) of the context a record is logged with to the record, as the
+// KeyThreadID, KeyRunID and KeyTenant attributes. Errors logged as
+// attributes and tagged by runctx.WrapError fill in what the context lacks.
+// Attributes the logger or the record already carry are not repeated, and
+// the metadata stays at the top level when the logger has groups.
+func NewContextHandler(handler slog.Handler) slog.Handler {
+	return &contextHandler{root: handler, handler: handler, bound: map[string]bool{}}
+}
+
+// contextHandler is the handler NewContextHandler returns. It keeps the
+// handler it wraps (root) along with the WithAttrs and WithGroup calls made
+// since, so that metadata can be added before the groups are opened.
+type contextHandler struct {
+	root    slog.Handler
+	handler slog.Handler
+	derive  []func(slog.Handler) slog.Handler
+	bound   map[string]bool
+	grouped bool
+}
+
+func (h *contextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	metadata := runctx.From(ctx)
+	present := map[string]bool{}
+	record.Attrs(func(attr slog.Attr) bool {
+		if err, ok := attr.Value.Any().(error); ok && attr.Value.Kind() == slog.KindAny {
+			if tagged, ok := runctx.FromError(err); ok {
+				metadata = metadata.Merge(tagged)
+			}
+		}
+		if !h.grouped {
+			present[attr.Key] = true
+		}
+		return true
+	})
+
+	attrs := h.attrs(metadata, present)
+	if len(attrs) == 0 {
+		return h.handler.Handle(ctx, record)
+	}
+	handler := h.root.WithAttrs(attrs)
+	for _, derive := range h.derive {
+		handler = derive(handler)
+	}
+	return handler.Handle(ctx, record)
+}
+
+// attrs returns the attributes metadata adds to a record of h
+func (h *contextHandler) attrs(metadata runctx.Metadata, present map[string]bool) []slog.Attr {
+	var attrs []slog.Attr
+	for _, attr := range []slog.Attr{
+		slog.String(KeyThreadID, metadata.ThreadID),
+		slog.String(KeyRunID, metadata.RunID),
+		slog.String(KeyTenant, metadata.Tenant),
+	} {
+		if attr.Value.String() != "" && !h.bound[attr.Key] && !present[attr.Key] {
+			attrs = append(attrs, attr)
+		}
+	}
+	return attrs
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := h.with(func(handler slog.Handler) slog.Handler {
+		return handler.WithAttrs(attrs)
+	})
+	if !h.grouped {
+		for _, attr := range attrs {
+			next.bound[attr.Key] = true
+		}
+	}
+	return next
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	next := h.with(func(handler slog.Handler) slog.Handler {
+		return handler.WithGroup(name)
+	})
+	next.grouped = true
+	return next
+}
+
+// with returns a copy of h with derive applied
+func (h *contextHandler) with(derive func(slog.Handler) slog.Handler) *contextHandler {
+	bound := make(map[string]bool, len(h.bound))
+	for key := range h.bound {
+		bound[key] = true
+	}
+	return &contextHandler{
+		root:    h.root,
+		handler: derive(h.handler),
+		derive:  append(h.derive[:len(h.derive):len(h.derive)], derive),
+		bound:   bound,
+		grouped: h.grouped,
+	}
+}
+
+// ContextHook is a logrus hook adding the run metadata of the context of an
+// entry (see logrus.Logger.WithContext) to its fields, the logrus
+// counterpart of NewContextHandler for loggers not derived with ToLogrus
+type ContextHook struct{}
+
+// Levels returns all levels
+func (ContextHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire adds the metadata of the context of entry to its fields
+func (ContextHook) Fire(entry *logrus.Entry) error {
+	var metadata runctx.Metadata
+	if entry.Context != nil {
+		metadata = runctx.From(entry.Context)
+	}
+	if err, ok := entry.Data[logrus.ErrorKey].(error); ok {
+		if tagged, ok := runctx.FromError(err); ok {
+			metadata = metadata.Merge(tagged)
+		}
+	}
+	for key, value := range map[string]string{
+		KeyThreadID: metadata.ThreadID,
+		KeyRunID:    metadata.RunID,
+		KeyTenant:   metadata.Tenant,
+	} {
+		if _, ok := entry.Data[key]; !ok && value != "" {
+			entry.Data[key] = value
+		}
+	}
+	return nil
+}
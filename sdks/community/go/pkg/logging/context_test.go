@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/runctx"
+)
+
+func TestContextLogger(t *testing.T) {
+	base, buf := jsonLogger(slog.LevelInfo)
+	logger := ContextLogger(ForComponent(base, "server"))
+	assert.Same(t, logger, ContextLogger(logger), "loggers are wrapped once")
+
+	ctx := runctx.With(context.Background(), runctx.Metadata{ThreadID: "thread-1", RunID: "run-1", Tenant: "acme"})
+	logger.InfoContext(ctx, "Run started")
+	logger.WithGroup("request").InfoContext(ctx, "Request handled", "status", 200)
+	logger.With(KeyRunID, "run-2").InfoContext(ctx, "Run started")
+	logger.InfoContext(ctx, "Run started", KeyTenant, "globex")
+	logger.Info("Shutting down")
+
+	logged := records(t, buf)
+	require.Len(t, logged, 5)
+	assert.Equal(t, "server", logged[0][KeyComponent])
+	assert.Equal(t, "thread-1", logged[0][KeyThreadID])
+	assert.Equal(t, "run-1", logged[0][KeyRunID])
+	assert.Equal(t, "acme", logged[0][KeyTenant])
+
+	assert.Equal(t, "run-1", logged[1][KeyRunID], "metadata stays at the top level")
+	assert.Equal(t, map[string]any{"status": float64(200)}, logged[1]["request"])
+
+	assert.Equal(t, "run-2", logged[2][KeyRunID], "attributes of the logger are not repeated")
+	assert.Equal(t, "globex", logged[3][KeyTenant], "attributes of the record are not repeated")
+	assert.NotContains(t, logged[4], KeyRunID)
+}
+
+func TestContextLoggerTaggedErrors(t *testing.T) {
+	base, buf := jsonLogger(slog.LevelInfo)
+	logger := ContextLogger(base)
+
+	err := runctx.WrapError(runctx.With(context.Background(), runctx.Metadata{ThreadID: "thread-1", RunID: "run-1"}), errors.New("boom"))
+	logger.InfoContext(runctx.With(context.Background(), runctx.Metadata{Tenant: "acme"}), "Run ended with error", "error", err)
+
+	logged := records(t, buf)
+	require.Len(t, logged, 1)
+	assert.Equal(t, "thread-1", logged[0][KeyThreadID])
+	assert.Equal(t, "run-1", logged[0][KeyRunID])
+	assert.Equal(t, "acme", logged[0][KeyTenant])
+}
+
+func TestContextHook(t *testing.T) {
+	var buf bytes.Buffer
+	entries := logrus.New()
+	entries.SetOutput(&buf)
+	entries.SetFormatter(&logrus.JSONFormatter{})
+	entries.AddHook(ContextHook{})
+
+	ctx := runctx.With(context.Background(), runctx.Metadata{ThreadID: "thread-1", RunID: "run-1"})
+	entries.WithContext(ctx).Info("SSE connection established")
+	entries.WithContext(ctx).WithField(KeyRunID, "run-2").Info("Run state changed")
+	entries.WithError(runctx.WrapError(ctx, errors.New("boom"))).Warn("SSE run stalled")
+	entries.Info("SSE connection closed")
+
+	logged := records(t, &buf)
+	require.Len(t, logged, 4)
+	assert.Equal(t, "thread-1", logged[0][KeyThreadID])
+	assert.Equal(t, "run-1", logged[0][KeyRunID])
+	assert.Equal(t, "run-2", logged[1][KeyRunID])
+	assert.Equal(t, "run-1", logged[2][KeyRunID])
+	assert.NotContains(t, logged[3], KeyRunID)
+}
+
+func TestToLogrusContext(t *testing.T) {
+	logger, buf := jsonLogger(slog.LevelInfo)
+	ctx := runctx.With(context.Background(), runctx.Metadata{RunID: "run-1"})
+	ToLogrus(logger).WithContext(ctx).Info("SSE connection established")
+
+	logged := records(t, buf)
+	require.Len(t, logged, 1)
+	assert.Equal(t, "run-1", logged[0][KeyRunID])
+}
@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/config"
+)
+
+// SettingLevel is the config key WatchLevel reads below its prefix
+const SettingLevel = "log.level"
+
+// ParseLevel parses a level name, case-insensitively. Besides the slog
+// names (debug, info, warn, error, optionally with an offset as in
+// "debug-4"), it accepts the logrus names still found in configs: trace
+// maps to debug-4, warning to warn, and fatal and panic to error.
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "trace":
+		return slog.LevelDebug - 4, nil
+	case "warning":
+		return slog.LevelWarn, nil
+	case "fatal", "panic":
+		return slog.LevelError, nil
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+		return 0, fmt.Errorf("invalid log level %q", name)
+	}
+	return level, nil
+}
+
+// WatchLevel sets level from the setting prefix+SettingLevel of cfg now and
+// whenever it changes, e.g. "agent.log.level" for the prefix "agent.".
+// Handlers created with level as their HandlerOptions.Level then follow the
+// setting. Removing the setting restores the level level had when
+// WatchLevel was called; invalid values are logged to logger and ignored.
+// It returns a function to stop watching, and fails when the current value
+// is invalid.
+func WatchLevel(cfg *config.Config, prefix string, level *slog.LevelVar, logger *slog.Logger) (func(), error) {
+	key := prefix + SettingLevel
+	base := level.Level()
+	apply := func() error {
+		next := base
+		if value, ok := cfg.Lookup(key); ok {
+			var err error
+			if next, err = ParseLevel(value); err != nil {
+				return fmt.Errorf("invalid %s: %w", key, err)
+			}
+		}
+		level.Set(next)
+		return nil
+	}
+
+	if err := apply(); err != nil {
+		return nil, err
+	}
+	return cfg.OnChange(key, func([]config.Change) {
+		if err := apply(); err != nil {
+			Or(logger).Warn("Rejected log level change", "error", err)
+		}
+	}), nil
+}
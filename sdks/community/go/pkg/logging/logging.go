@@ -0,0 +1,63 @@
+// Package logging lets applications plug a single log/slog logger into
+// every package of the SDK. Servers, run managers, archivers and ingesters
+// take a *slog.Logger; the SSE client, the event decoder and the other
+// packages predating slog take a *logrus.Logger, which ToLogrus derives
+// from the application's logger, and FromLogrus goes the other way for
+// applications built on logrus.
+//
+// Records about a run carry the same attributes whichever package logs
+// them, see ForRun and ForComponent, so that the logs of a run can be
+// followed from the transport to the agent. Loggers derived with
+// ContextLogger add them from the run metadata of the context a record is
+// logged with (see package runctx), so they need not be passed along. The level can be changed while
+// the process runs from a config.Config, see WatchLevel.
+package logging
+
+import (
+	"io"
+	"log/slog"
+)
+
+// Attribute keys shared by the records of every package
+const (
+	// KeyComponent names the part of the SDK logging the record, e.g.
+	// "run_manager" or "sse_client"
+	KeyComponent = "component"
+
+	// KeyThreadID and KeyRunID identify the run a record is about
+	KeyThreadID = "thread_id"
+	KeyRunID    = "run_id"
+)
+
+// Or returns logger, or slog.Default() when logger is nil, for configs
+// leaving the logger unset
+func Or(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return slog.Default()
+	}
+	return logger
+}
+
+// Discard returns a logger dropping every record
+func Discard() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+}
+
+// ForComponent returns a logger labelling its records with the component
+// logging them
+func ForComponent(logger *slog.Logger, component string) *slog.Logger {
+	return Or(logger).With(KeyComponent, component)
+}
+
+// ForRun returns a logger labelling its records with the run they are
+// about. Empty IDs are left out.
+func ForRun(logger *slog.Logger, threadID, runID string) *slog.Logger {
+	var args []any
+	if threadID != "" {
+		args = append(args, KeyThreadID, threadID)
+	}
+	if runID != "" {
+		args = append(args, KeyRunID, runID)
+	}
+	return Or(logger).With(args...)
+}
@@ -0,0 +1,132 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/config"
+)
+
+// jsonLogger returns a logger writing JSON records to the returned buffer
+func jsonLogger(level slog.Leveler) (*slog.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: level})), &buf
+}
+
+// records decodes the JSON records written to buf
+func records(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var decoded []map[string]any
+	decoder := json.NewDecoder(buf)
+	for decoder.More() {
+		var record map[string]any
+		require.NoError(t, decoder.Decode(&record))
+		decoded = append(decoded, record)
+	}
+	return decoded
+}
+
+func TestRunAttributes(t *testing.T) {
+	logger, buf := jsonLogger(slog.LevelInfo)
+	ForRun(ForComponent(logger, "run_manager"), "thread-1", "run-1").Info("Run started")
+	ForRun(logger, "", "run-2").Info("Run started")
+
+	logged := records(t, buf)
+	require.Len(t, logged, 2)
+	assert.Equal(t, "run_manager", logged[0][KeyComponent])
+	assert.Equal(t, "thread-1", logged[0][KeyThreadID])
+	assert.Equal(t, "run-1", logged[0][KeyRunID])
+	assert.NotContains(t, logged[1], KeyThreadID)
+	assert.Equal(t, "run-2", logged[1][KeyRunID])
+
+	assert.Same(t, slog.Default(), Or(nil))
+	assert.False(t, Discard().Enabled(context.Background(), slog.LevelError))
+}
+
+func TestParseLevel(t *testing.T) {
+	for name, want := range map[string]slog.Level{
+		"debug":   slog.LevelDebug,
+		"INFO":    slog.LevelInfo,
+		"warning": slog.LevelWarn,
+		"error":   slog.LevelError,
+		"debug-4": slog.LevelDebug - 4,
+		"trace":   slog.LevelDebug - 4,
+		"fatal":   slog.LevelError,
+	} {
+		level, err := ParseLevel(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, level, name)
+	}
+	_, err := ParseLevel("loud")
+	assert.Error(t, err)
+}
+
+func TestWatchLevel(t *testing.T) {
+	values := map[string]string{"agent.log.level": "debug"}
+	cfg, err := config.New(context.Background(), config.Options{Logger: Discard()}, config.NewMapSource("test", values))
+	require.NoError(t, err)
+
+	level := new(slog.LevelVar)
+	level.Set(slog.LevelWarn)
+	stop, err := WatchLevel(cfg, "agent.", level, Discard())
+	require.NoError(t, err)
+	defer stop()
+	assert.Equal(t, slog.LevelDebug, level.Level())
+
+	values["agent.log.level"] = "loud"
+	require.NoError(t, cfg.Reload(context.Background()))
+	assert.Equal(t, slog.LevelDebug, level.Level(), "invalid levels are ignored")
+
+	values["agent.log.level"] = "error"
+	require.NoError(t, cfg.Reload(context.Background()))
+	assert.Equal(t, slog.LevelError, level.Level())
+
+	delete(values, "agent.log.level")
+	require.NoError(t, cfg.Reload(context.Background()))
+	assert.Equal(t, slog.LevelWarn, level.Level(), "removing the setting restores the level")
+
+	values["agent.log.level"] = "loud"
+	require.NoError(t, cfg.Reload(context.Background()))
+	_, err = WatchLevel(cfg, "agent.", level, Discard())
+	assert.Error(t, err)
+}
+
+func TestToLogrus(t *testing.T) {
+	logger, buf := jsonLogger(slog.LevelInfo)
+	entries := ToLogrus(logger)
+	entries.WithField(KeyRunID, "run-1").WithError(errors.New("boom")).Warn("SSE run stalled")
+	entries.Debug("SSE stream progress")
+
+	logged := records(t, buf)
+	require.Len(t, logged, 1, "the level of the slog logger applies")
+	assert.Equal(t, "WARN", logged[0]["level"])
+	assert.Equal(t, "SSE run stalled", logged[0]["msg"])
+	assert.Equal(t, "run-1", logged[0][KeyRunID])
+	assert.Equal(t, "boom", logged[0]["error"])
+}
+
+func TestFromLogrus(t *testing.T) {
+	var buf bytes.Buffer
+	entries := logrus.New()
+	entries.SetOutput(&buf)
+	entries.SetFormatter(&logrus.JSONFormatter{})
+	entries.SetLevel(logrus.InfoLevel)
+
+	logger := ForComponent(FromLogrus(entries), "server")
+	logger.WithGroup("request").Info("Run started", KeyRunID, "run-1", slog.Group("client", "ip", "127.0.0.1"))
+	logger.Debug("Run finished")
+
+	logged := records(t, &buf)
+	require.Len(t, logged, 1, "the level of the logrus logger applies")
+	assert.Equal(t, "info", logged[0]["level"])
+	assert.Equal(t, "server", logged[0][KeyComponent])
+	assert.Equal(t, "run-1", logged[0]["request."+KeyRunID])
+	assert.Equal(t, "127.0.0.1", logged[0]["request.client.ip"])
+}
@@ -0,0 +1,161 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ToLogrus returns a logrus logger forwarding its entries to logger, for
+// the packages taking a *logrus.Logger such as the SSE client. Entry fields
+// become attributes and logrus levels map to the closest slog level, see
+// ParseLevel; the level of logger decides what is logged, unless the
+// returned logger is given a level of its own. Entries logged with a
+// context carry its run metadata, see NewContextHandler.
+func ToLogrus(logger *slog.Logger) *logrus.Logger {
+	logger = ContextLogger(logger)
+	out := logrus.New()
+	out.SetOutput(io.Discard)
+	out.SetFormatter(discardFormatter{})
+	out.SetLevel(logrus.TraceLevel)
+	out.AddHook(slogHook{handler: logger.Handler()})
+	return out
+}
+
+// slogHook passes logrus entries to a slog handler
+type slogHook struct {
+	handler slog.Handler
+}
+
+func (h slogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h slogHook) Fire(entry *logrus.Entry) error {
+	ctx := entry.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	level := slogLevel(entry.Level)
+	if !h.handler.Enabled(ctx, level) {
+		return nil
+	}
+	record := slog.NewRecord(entry.Time, level, entry.Message, 0)
+	keys := make([]string, 0, len(entry.Data))
+	for key := range entry.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		record.AddAttrs(slog.Any(key, entry.Data[key]))
+	}
+	return h.handler.Handle(ctx, record)
+}
+
+// discardFormatter skips formatting the entries that slogHook forwards
+type discardFormatter struct{}
+
+func (discardFormatter) Format(*logrus.Entry) ([]byte, error) {
+	return nil, nil
+}
+
+// FromLogrus returns a slog logger writing to logger, for applications
+// built on logrus. Attributes become entry fields, those of groups prefixed
+// with the group names, and the level of logger decides what is logged.
+func FromLogrus(logger *logrus.Logger) *slog.Logger {
+	return slog.New(&logrusHandler{logger: logger})
+}
+
+// logrusHandler is a slog handler writing to a logrus logger
+type logrusHandler struct {
+	logger *logrus.Logger
+	fields logrus.Fields
+	prefix string
+}
+
+func (h *logrusHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.IsLevelEnabled(logrusLevel(level))
+}
+
+func (h *logrusHandler) Handle(ctx context.Context, record slog.Record) error {
+	fields := make(logrus.Fields, len(h.fields)+record.NumAttrs())
+	for key, value := range h.fields {
+		fields[key] = value
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		addField(fields, h.prefix, attr)
+		return true
+	})
+	h.logger.WithContext(ctx).WithTime(record.Time).WithFields(fields).Log(logrusLevel(record.Level), record.Message)
+	return nil
+}
+
+func (h *logrusHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make(logrus.Fields, len(h.fields)+len(attrs))
+	for key, value := range h.fields {
+		fields[key] = value
+	}
+	for _, attr := range attrs {
+		addField(fields, h.prefix, attr)
+	}
+	return &logrusHandler{logger: h.logger, fields: fields, prefix: h.prefix}
+}
+
+func (h *logrusHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &logrusHandler{logger: h.logger, fields: h.fields, prefix: h.prefix + name + "."}
+}
+
+// addField adds an attribute to fields, flattening groups into dotted keys
+func addField(fields logrus.Fields, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, member := range attr.Value.Group() {
+			addField(fields, prefix, member)
+		}
+		return
+	}
+	fields[strings.TrimSuffix(prefix+attr.Key, ".")] = attr.Value.Any()
+}
+
+// slogLevel maps a logrus level to the closest slog level
+func slogLevel(level logrus.Level) slog.Level {
+	switch level {
+	case logrus.TraceLevel:
+		return slog.LevelDebug - 4
+	case logrus.DebugLevel:
+		return slog.LevelDebug
+	case logrus.InfoLevel:
+		return slog.LevelInfo
+	case logrus.WarnLevel:
+		return slog.LevelWarn
+	}
+	return slog.LevelError
+}
+
+// logrusLevel maps a slog level to the closest logrus level
+func logrusLevel(level slog.Level) logrus.Level {
+	switch {
+	case level < slog.LevelDebug:
+		return logrus.TraceLevel
+	case level < slog.LevelInfo:
+		return logrus.DebugLevel
+	case level < slog.LevelWarn:
+		return logrus.InfoLevel
+	case level < slog.LevelError:
+		return logrus.WarnLevel
+	}
+	return logrus.ErrorLevel
+}
@@ -0,0 +1,114 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegexHook(t *testing.T) {
+	hook := NewRegexHook(
+		Rule{Pattern: regexp.MustCompile(`(\d{4})-\d{4}-\d{4}-(\d{4})`), Action: Redact, Replacement: "$1-****-****-$2", Label: "pii", Reason: "card number"},
+		Rule{Pattern: regexp.MustCompile(`rm -rf`), Action: Block, Label: "dangerous", Kinds: []Kind{KindToolArgs}},
+		Keywords(Allow, "finance", "invoice", "refund"),
+	)
+	ctx := context.Background()
+
+	t.Run("Redact", func(t *testing.T) {
+		verdict, err := hook.Moderate(ctx, Content{Kind: KindText, Text: "Card 1234-5678-9012-3456 for the Invoice"})
+		require.NoError(t, err)
+		assert.Equal(t, Verdict{Action: Redact, Text: "Card 1234-****-****-3456 for the Invoice", Labels: []string{"pii", "finance"}, Reason: "card number"}, verdict)
+	})
+
+	t.Run("Kinds", func(t *testing.T) {
+		verdict, err := hook.Moderate(ctx, Content{Kind: KindText, Text: "never run rm -rf /"})
+		require.NoError(t, err)
+		assert.Equal(t, Allow, verdict.Action)
+
+		verdict, err = hook.Moderate(ctx, Content{Kind: KindToolArgs, ToolName: "shell", Text: `{"cmd":"rm -rf /"}`})
+		require.NoError(t, err)
+		assert.Equal(t, Verdict{Action: Block, Labels: []string{"dangerous"}}, verdict)
+	})
+
+	t.Run("WholeWords", func(t *testing.T) {
+		verdict, err := hook.Moderate(ctx, Content{Kind: KindText, Text: "refunded invoices"})
+		require.NoError(t, err)
+		assert.Equal(t, Verdict{Text: "refunded invoices"}, verdict)
+	})
+}
+
+func TestActionText(t *testing.T) {
+	for _, action := range []Action{Allow, Redact, Block} {
+		text, err := action.MarshalText()
+		require.NoError(t, err)
+		var parsed Action
+		require.NoError(t, parsed.UnmarshalText(text))
+		assert.Equal(t, action, parsed)
+	}
+	var action Action
+	assert.Error(t, action.UnmarshalText([]byte("delete")))
+}
+
+func TestHTTPHook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+		var content Content
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&content))
+		assert.Equal(t, Content{Kind: KindToolArgs, ID: "call-1", ToolName: "search", Text: `{"q":"x"}`}, content)
+		_, _ = w.Write([]byte(`{"action":"redact","text":"{}","labels":["spam"]}`))
+	}))
+	defer server.Close()
+
+	hook := NewHTTPHook(HTTPConfig{URL: server.URL, Header: http.Header{"X-Api-Key": {"secret"}}})
+	verdict, err := hook.Moderate(context.Background(), Content{Kind: KindToolArgs, ID: "call-1", ToolName: "search", Text: `{"q":"x"}`})
+	require.NoError(t, err)
+	assert.Equal(t, Verdict{Action: Redact, Text: "{}", Labels: []string{"spam"}}, verdict)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	_, err = NewHTTPHook(HTTPConfig{URL: failing.URL}).Moderate(context.Background(), Content{Text: "hi"})
+	assert.ErrorContains(t, err, "overloaded")
+}
+
+func TestOpenAIHook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+		var request map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, defaultOpenAIModel, request["model"])
+		if request["input"] == "hello" {
+			_, _ = w.Write([]byte(`{"results":[{"flagged":false,"categories":{"violence":false}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"results":[{"flagged":true,"categories":{"violence":true,"harassment":true,"sexual":false}}]}`))
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	hook := NewOpenAIHook(OpenAIConfig{APIKey: "sk-test", Endpoint: server.URL})
+	verdict, err := hook.Moderate(ctx, Content{Text: "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, Verdict{}, verdict)
+
+	verdict, err = hook.Moderate(ctx, Content{Text: "threat"})
+	require.NoError(t, err)
+	assert.Equal(t, Verdict{Action: Block, Labels: []string{"harassment", "violence"}, Reason: "flagged as harassment, violence"}, verdict)
+
+	hook = NewOpenAIHook(OpenAIConfig{APIKey: "sk-test", Endpoint: server.URL, AnnotateOnly: true})
+	verdict, err = hook.Moderate(ctx, Content{Text: "threat"})
+	require.NoError(t, err)
+	assert.Equal(t, Allow, verdict.Action)
+	assert.Equal(t, []string{"harassment", "violence"}, verdict.Labels)
+
+	t.Setenv("OPENAI_API_KEY", "")
+	_, err = NewOpenAIHook(OpenAIConfig{Endpoint: server.URL}).Moderate(ctx, Content{Text: "hello"})
+	assert.Error(t, err)
+}
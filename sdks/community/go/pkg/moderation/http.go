@@ -0,0 +1,156 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	// defaultOpenAIEndpoint is the moderation endpoint of the OpenAI API
+	defaultOpenAIEndpoint = "https://api.openai.com/v1/moderations"
+
+	// defaultOpenAIModel is the moderation model used unless configured
+	defaultOpenAIModel = "omni-moderation-latest"
+)
+
+// HTTPConfig configures a hook delegating to a moderation service
+type HTTPConfig struct {
+	// URL receives each Content as a JSON POST request and answers with a
+	// Verdict as JSON, its action being "allow", "redact" or "block"
+	URL string `json:"url"`
+
+	// Header is added to the requests, e.g. for authorization
+	Header http.Header `json:"-"`
+
+	// Client sends the requests (defaults to http.DefaultClient)
+	Client *http.Client `json:"-"`
+}
+
+// NewHTTPHook returns a hook delegating to the moderation service of config
+func NewHTTPHook(config HTTPConfig) Hook {
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	return HookFunc(func(ctx context.Context, content Content) (Verdict, error) {
+		var verdict Verdict
+		if err := post(ctx, config.Client, config.URL, config.Header, content, &verdict); err != nil {
+			return Verdict{}, err
+		}
+		return verdict, nil
+	})
+}
+
+// OpenAIConfig configures a hook delegating to the OpenAI moderation API
+type OpenAIConfig struct {
+	// APIKey authenticates the requests (defaults to OPENAI_API_KEY)
+	APIKey string `json:"-"`
+
+	// Model is the moderation model (defaults to omni-moderation-latest)
+	Model string `json:"model"`
+
+	// Endpoint of the moderation API (defaults to that of OpenAI)
+	Endpoint string `json:"endpoint"`
+
+	// AnnotateOnly labels flagged content with its categories rather than
+	// blocking it
+	AnnotateOnly bool `json:"annotateOnly"`
+
+	// Client sends the requests (defaults to http.DefaultClient)
+	Client *http.Client `json:"-"`
+}
+
+// openAIResponse is the part of the response of the moderation API read
+type openAIResponse struct {
+	Results []struct {
+		Flagged    bool            `json:"flagged"`
+		Categories map[string]bool `json:"categories"`
+	} `json:"results"`
+}
+
+// NewOpenAIHook returns a hook blocking the content flagged by the OpenAI
+// moderation API, labeled with the categories it was flagged for
+func NewOpenAIHook(config OpenAIConfig) Hook {
+	if config.APIKey == "" {
+		config.APIKey = os.Getenv("OPENAI_API_KEY")
+	}
+	if config.Model == "" {
+		config.Model = defaultOpenAIModel
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = defaultOpenAIEndpoint
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	return HookFunc(func(ctx context.Context, content Content) (Verdict, error) {
+		if config.APIKey == "" {
+			return Verdict{}, errors.New("moderation: no OpenAI API key configured")
+		}
+		header := http.Header{"Authorization": {"Bearer " + config.APIKey}}
+		request := map[string]string{"model": config.Model, "input": content.Text}
+		var response openAIResponse
+		if err := post(ctx, config.Client, config.Endpoint, header, request, &response); err != nil {
+			return Verdict{}, err
+		}
+
+		var verdict Verdict
+		for _, result := range response.Results {
+			if !result.Flagged {
+				continue
+			}
+			for category, flagged := range result.Categories {
+				if flagged {
+					verdict.Labels = append(verdict.Labels, category)
+				}
+			}
+		}
+		if len(verdict.Labels) == 0 {
+			return verdict, nil
+		}
+		sort.Strings(verdict.Labels)
+		verdict.Reason = "flagged as " + strings.Join(verdict.Labels, ", ")
+		if !config.AnnotateOnly {
+			verdict.Action = Block
+		}
+		return verdict, nil
+	})
+}
+
+// post sends body as JSON to url and decodes the JSON response into out
+func post(ctx context.Context, client *http.Client, url string, header http.Header, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("moderation request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("moderation request: %s %s", resp.Status, strings.TrimSpace(string(text)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode moderation response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,550 @@
+// Package moderation moderates the content of runs before it reaches
+// transports or storage: the text of messages and the arguments of tool
+// calls are submitted to hooks, which let them through, redact them or block
+// them, and annotate them for clients (see events.NewModerationEvent). A
+// Moderator applies hooks to the runs of a server as a server.Tap; hooks
+// matching regular expressions or keywords (see NewRegexHook) and delegating
+// to moderation services (see NewHTTPHook and NewOpenAIHook) are built in.
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/logging"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server"
+)
+
+const (
+	// RunErrorCodeBlocked is the code of the RUN_ERROR ending runs whose
+	// content was blocked
+	RunErrorCodeBlocked = "CONTENT_BLOCKED"
+
+	// MessageBlocked is the localization key of the message of the
+	// RUN_ERROR ending runs whose content was blocked
+	MessageBlocked = "moderation.blocked"
+
+	// defaultQueueSize bounds the events queued by asynchronous moderation
+	// unless configured
+	defaultQueueSize = 256
+)
+
+// ErrBlocked is matched by the errors of runs whose content was blocked
+var ErrBlocked = errors.New("content blocked by moderation")
+
+// Kind is the kind of content moderated
+type Kind string
+
+const (
+	// KindText is the text of messages, streamed or updated
+	KindText Kind = "text"
+
+	// KindToolArgs is the arguments of tool calls
+	KindToolArgs Kind = "tool_args"
+)
+
+// Content is the content submitted to hooks
+type Content struct {
+	Kind Kind `json:"kind"`
+
+	// ID is the ID of the message or tool call
+	ID string `json:"id"`
+
+	// ToolName is the name of the tool called, for KindToolArgs
+	ToolName string `json:"toolName,omitempty"`
+
+	// Text is the content: the delta of an event, the deltas buffered (see
+	// Config.BufferBytes), or the content of an updated message
+	Text string `json:"text"`
+}
+
+// Action is what a hook decides for content
+type Action int
+
+const (
+	// Allow lets the content through
+	Allow Action = iota
+	// Redact replaces the content with the text of the verdict
+	Redact
+	// Block drops the content and fails the run
+	Block
+)
+
+var actionNames = map[Action]string{Allow: "allow", Redact: "redact", Block: "block"}
+
+func (a Action) String() string {
+	if name, ok := actionNames[a]; ok {
+		return name
+	}
+	return fmt.Sprintf("Action(%d)", int(a))
+}
+
+// MarshalText encodes the action as its name
+func (a Action) MarshalText() ([]byte, error) {
+	if _, ok := actionNames[a]; !ok {
+		return nil, fmt.Errorf("moderation: unknown action %d", int(a))
+	}
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText decodes an action from its name
+func (a *Action) UnmarshalText(text []byte) error {
+	for action, name := range actionNames {
+		if name == string(text) {
+			*a = action
+			return nil
+		}
+	}
+	return fmt.Errorf("moderation: unknown action %q", text)
+}
+
+// annotation returns the action of moderation events for a
+func (a Action) annotation() string {
+	switch a {
+	case Redact:
+		return events.ModerationRedacted
+	case Block:
+		return events.ModerationBlocked
+	}
+	return events.ModerationAllowed
+}
+
+// Verdict is the decision of a hook on content
+type Verdict struct {
+	Action Action `json:"action"`
+
+	// Text replaces the content when Action is Redact. Content redacted
+	// entirely is dropped.
+	Text string `json:"text,omitempty"`
+
+	// Labels classify the content. Content redacted, blocked or labeled is
+	// annotated with a moderation event.
+	Labels []string `json:"labels,omitempty"`
+
+	// Reason explains the verdict, for display
+	Reason string `json:"reason,omitempty"`
+}
+
+// Hook moderates content. Implementations are safe for concurrent use.
+type Hook interface {
+	Moderate(ctx context.Context, content Content) (Verdict, error)
+}
+
+// HookFunc adapts a function to the Hook interface
+type HookFunc func(ctx context.Context, content Content) (Verdict, error)
+
+// Moderate calls f(ctx, content)
+func (f HookFunc) Moderate(ctx context.Context, content Content) (Verdict, error) {
+	return f(ctx, content)
+}
+
+// BlockedError tells which content was blocked. It matches ErrBlocked with
+// errors.Is and ends the run with a RUN_ERROR of code RunErrorCodeBlocked,
+// the reason of the block being left to the moderation event.
+type BlockedError struct {
+	Kind   Kind
+	ID     string
+	Reason string
+}
+
+func (e *BlockedError) Error() string {
+	what := "message"
+	if e.Kind == KindToolArgs {
+		what = "arguments of tool call"
+	}
+	if e.Reason == "" {
+		return fmt.Sprintf("moderation blocked the %s %s", what, e.ID)
+	}
+	return fmt.Sprintf("moderation blocked the %s %s: %s", what, e.ID, e.Reason)
+}
+
+func (e *BlockedError) Unwrap() []error {
+	return []error{ErrBlocked, &server.RunError{Code: RunErrorCodeBlocked, Key: MessageBlocked, Message: ErrBlocked.Error()}}
+}
+
+// Config configures a Moderator
+type Config struct {
+	// Hooks moderate the content in order, each seeing the content as
+	// redacted by the previous ones, until one blocks it
+	Hooks []Hook
+
+	// BufferBytes buffers the deltas of each message and tool call up to
+	// this many bytes, so that hooks see words and patterns split across
+	// deltas (0 = each delta moderated on its own). Buffered deltas are
+	// moderated, and sent as a single event, once BufferBytes are buffered
+	// or before any event other than a delta.
+	BufferBytes int
+
+	// Async moderates in the background: emitting returns once the event is
+	// queued, up to QueueSize events (defaults to 256), so that agents are
+	// not held back by slow hooks. Events are delivered in order; blocked
+	// content and delivery errors fail the next emit, and the terminal event
+	// of the run waits for the queue to drain. Runs finishing before their
+	// blocked content was moderated end with a RUN_ERROR nonetheless.
+	Async     bool
+	QueueSize int
+
+	// FailOpen lets content through when a hook fails; it is blocked
+	// otherwise. Hook errors are logged either way.
+	FailOpen bool
+
+	// Logger receives the errors of hooks (defaults to slog.Default())
+	Logger *slog.Logger
+}
+
+// Moderator applies hooks to the content of runs
+type Moderator struct {
+	config Config
+	logger *slog.Logger
+}
+
+// NewModerator creates a moderator applying the hooks of config
+func NewModerator(config Config) *Moderator {
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaultQueueSize
+	}
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+	return &Moderator{config: config, logger: logging.ContextLogger(logging.ForComponent(config.Logger, "moderation"))}
+}
+
+// Tap moderates the events of a run passing to emitter. It is meant for
+// server.RunManagerConfig.Tap, first among server.Taps so that the taps
+// storing runs see moderated content.
+func (m *Moderator) Tap(ctx context.Context, _ *types.RunAgentInput, emitter server.Emitter) server.Emitter {
+	r := &run{moderator: m, next: emitter, toolNames: make(map[string]string)}
+	if !m.config.Async {
+		var mu sync.Mutex
+		return server.EmitterFunc(func(ctx context.Context, event events.Event) error {
+			mu.Lock()
+			defer mu.Unlock()
+			return r.process(ctx, event)
+		})
+	}
+	a := &asyncRun{run: r, queue: make(chan queued, m.config.QueueSize)}
+	go a.work(ctx)
+	return a
+}
+
+// Moderate applies the hooks to content, returning their combined verdict
+func (m *Moderator) Moderate(ctx context.Context, content Content) (Verdict, error) {
+	verdict := Verdict{Text: content.Text}
+	var reasons []string
+	for _, hook := range m.config.Hooks {
+		v, err := hook.Moderate(ctx, content)
+		if err != nil {
+			return Verdict{}, err
+		}
+		verdict.Labels = append(verdict.Labels, v.Labels...)
+		if v.Reason != "" {
+			reasons = append(reasons, v.Reason)
+		}
+		verdict.Reason = strings.Join(reasons, "; ")
+		switch v.Action {
+		case Redact:
+			verdict.Action, verdict.Text = Redact, v.Text
+			content.Text = v.Text
+		case Block:
+			verdict.Action, verdict.Text = Block, ""
+			return verdict, nil
+		}
+	}
+	return verdict, nil
+}
+
+// run moderates the events of a run
+type run struct {
+	moderator *Moderator
+	next      server.Emitter
+
+	// toolNames are the names of the tools called, by tool call ID
+	toolNames map[string]string
+
+	// buffers hold the deltas buffered, in the order of their first delta
+	buffers []*buffer
+
+	// blocked fails the events following blocked content
+	blocked error
+}
+
+// buffer holds the buffered deltas of a message or tool call
+type buffer struct {
+	content Content
+	first   events.Event
+}
+
+// process moderates an event and passes it on
+func (r *run) process(ctx context.Context, event events.Event) error {
+	terminal := isTerminal(event)
+	if r.blocked != nil && !terminal {
+		return r.blocked
+	}
+	if content, ok := r.delta(event); ok {
+		if r.moderator.config.BufferBytes <= 0 {
+			return r.moderate(ctx, content, event)
+		}
+		b := r.buffer(content, event)
+		if len(b.content.Text) < r.moderator.config.BufferBytes {
+			return nil
+		}
+		return r.flush(ctx, b)
+	}
+	for len(r.buffers) > 0 {
+		if err := r.flush(ctx, r.buffers[0]); err != nil && !terminal {
+			return err
+		}
+	}
+	if updated, ok := event.(*events.MessageUpdatedEvent); ok {
+		return r.moderate(ctx, Content{Kind: KindText, ID: updated.MessageID, Text: updated.Content}, event)
+	}
+	if start, ok := event.(*events.ToolCallStartEvent); ok {
+		r.toolNames[start.ToolCallID] = start.ToolCallName
+	}
+	if finished, ok := event.(*events.RunFinishedEvent); ok && r.blocked != nil {
+		// The agent finished before learning of the block
+		event = events.NewRunErrorEvent(ErrBlocked.Error(), events.WithErrorCode(RunErrorCodeBlocked), events.WithRunID(finished.RunID()))
+	}
+	return r.next.Emit(ctx, event)
+}
+
+// delta returns the content of the events streaming deltas
+func (r *run) delta(event events.Event) (Content, bool) {
+	switch e := event.(type) {
+	case *events.TextMessageContentEvent:
+		return Content{Kind: KindText, ID: e.MessageID, Text: e.Delta}, true
+	case *events.TextMessageChunkEvent:
+		if e.Delta != nil && *e.Delta != "" {
+			return Content{Kind: KindText, ID: deref(e.MessageID), Text: *e.Delta}, true
+		}
+	case *events.ToolCallArgsEvent:
+		return Content{Kind: KindToolArgs, ID: e.ToolCallID, ToolName: r.toolNames[e.ToolCallID], Text: e.Delta}, true
+	case *events.ToolCallChunkEvent:
+		if e.ToolCallID != nil && e.ToolCallName != nil {
+			r.toolNames[*e.ToolCallID] = *e.ToolCallName
+		}
+		if e.Delta != nil && *e.Delta != "" {
+			id := deref(e.ToolCallID)
+			return Content{Kind: KindToolArgs, ID: id, ToolName: r.toolNames[id], Text: *e.Delta}, true
+		}
+	}
+	return Content{}, false
+}
+
+// buffer adds a delta to the buffer of its message or tool call
+func (r *run) buffer(content Content, event events.Event) *buffer {
+	for _, b := range r.buffers {
+		if b.content.Kind == content.Kind && b.content.ID == content.ID && b.first.Type() == event.Type() {
+			b.content.Text += content.Text
+			return b
+		}
+	}
+	b := &buffer{content: content, first: event}
+	r.buffers = append(r.buffers, b)
+	return b
+}
+
+// flush moderates the deltas of a buffer as the first of its events
+func (r *run) flush(ctx context.Context, b *buffer) error {
+	for i := range r.buffers {
+		if r.buffers[i] == b {
+			r.buffers = append(r.buffers[:i], r.buffers[i+1:]...)
+			break
+		}
+	}
+	return r.moderate(ctx, b.content, withText(b.first, b.content.Text))
+}
+
+// moderate moderates the content of event, passing on the event as
+// moderated and its annotation
+func (r *run) moderate(ctx context.Context, content Content, event events.Event) error {
+	verdict, err := r.moderator.Moderate(ctx, content)
+	if err != nil {
+		r.moderator.logger.ErrorContext(ctx, "Moderation failed", "kind", content.Kind, "id", content.ID, "error", err)
+		if !r.moderator.config.FailOpen {
+			verdict = Verdict{Action: Block, Reason: "moderation failed"}
+		} else {
+			verdict = Verdict{Text: content.Text}
+		}
+		err = nil
+	}
+
+	switch verdict.Action {
+	case Allow:
+		err = r.next.Emit(ctx, event)
+	case Redact:
+		if event = withText(event, verdict.Text); event != nil {
+			err = r.next.Emit(ctx, event)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if verdict.Action != Allow || len(verdict.Labels) > 0 {
+		annotation := events.Moderation{Action: verdict.Action.annotation(), Labels: verdict.Labels, Reason: verdict.Reason}
+		if content.Kind == KindToolArgs {
+			annotation.ToolCallID = content.ID
+		} else {
+			annotation.MessageID = content.ID
+		}
+		if content.ID != "" {
+			if err := r.next.Emit(ctx, events.NewModerationEvent(annotation)); err != nil {
+				return err
+			}
+		}
+	}
+	if verdict.Action == Block {
+		r.blocked = &BlockedError{Kind: content.Kind, ID: content.ID, Reason: verdict.Reason}
+		return r.blocked
+	}
+	return nil
+}
+
+// queued is an event queued for asynchronous moderation; result receives
+// the outcome of terminal events
+type queued struct {
+	ctx    context.Context
+	event  events.Event
+	result chan error
+}
+
+// asyncRun moderates the events of a run in the background
+type asyncRun struct {
+	run   *run
+	queue chan queued
+
+	// done is set once the terminal event was queued
+	done atomic.Bool
+
+	mu  sync.Mutex
+	err error
+}
+
+// Emit queues an event, returning the first error of the events moderated
+// so far. Terminal events wait for the events queued before them.
+func (a *asyncRun) Emit(ctx context.Context, event events.Event) error {
+	terminal := isTerminal(event)
+	if a.done.Load() {
+		return server.ErrRunCompleted
+	}
+	if err := a.failure(); err != nil && !terminal {
+		return err
+	}
+	q := queued{ctx: ctx, event: event}
+	if terminal {
+		q.result = make(chan error, 1)
+		a.done.Store(true)
+	}
+	select {
+	case a.queue <- q:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if !terminal {
+		return nil
+	}
+	select {
+	case err := <-q.result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// work moderates the events queued until the terminal event of the run,
+// or until ctx, the context of the run, is done
+func (a *asyncRun) work(ctx context.Context) {
+	for {
+		select {
+		case q := <-a.queue:
+			err := a.run.process(q.ctx, q.event)
+			if q.result != nil {
+				q.result <- err
+				return
+			}
+			if err != nil {
+				a.mu.Lock()
+				if a.err == nil {
+					a.err = err
+				}
+				a.mu.Unlock()
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// failure returns the first error of the events moderated
+func (a *asyncRun) failure() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.err
+}
+
+// withText returns a copy of a content event carrying text, nil when text
+// is empty and the event carries nothing else
+func withText(event events.Event, text string) events.Event {
+	switch e := event.(type) {
+	case *events.TextMessageContentEvent:
+		if text == "" {
+			return nil
+		}
+		c := *e
+		c.Delta = text
+		return &c
+	case *events.TextMessageChunkEvent:
+		c := *e
+		c.Delta = nonEmpty(text)
+		return &c
+	case *events.ToolCallArgsEvent:
+		if text == "" {
+			return nil
+		}
+		c := *e
+		c.Delta = text
+		return &c
+	case *events.ToolCallChunkEvent:
+		c := *e
+		c.Delta = nonEmpty(text)
+		return &c
+	case *events.MessageUpdatedEvent:
+		if text == "" {
+			return nil
+		}
+		c := *e
+		c.Content = text
+		return &c
+	}
+	return event
+}
+
+// isTerminal reports whether event ends its run
+func isTerminal(event events.Event) bool {
+	switch event.Type() {
+	case events.EventTypeRunFinished, events.EventTypeRunError:
+		return true
+	}
+	return false
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func nonEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,241 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server"
+)
+
+// sink records the events of a run
+type sink struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (s *sink) Emit(_ context.Context, event events.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *sink) types() []events.EventType {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var types []events.EventType
+	for _, event := range s.events {
+		types = append(types, event.Type())
+	}
+	return types
+}
+
+// moderation returns the annotation of the event of index i
+func (s *sink) moderation(t *testing.T, i int) *events.Moderation {
+	t.Helper()
+	moderation, ok, err := events.ModerationOf(s.events[i])
+	require.NoError(t, err)
+	require.True(t, ok, "event %d is %s", i, s.events[i].Type())
+	return moderation
+}
+
+func newInput() *types.RunAgentInput {
+	return &types.RunAgentInput{ThreadID: "thread-1", RunID: "run-1"}
+}
+
+// piiAndViolence redacts email addresses and blocks violent content
+var piiAndViolence = NewRegexHook(
+	Rule{Pattern: regexp.MustCompile(`[\w.]+@[\w.]+`), Action: Redact, Label: "pii"},
+	Keywords(Block, "violence", "bomb", "grenade"),
+)
+
+func TestModerator(t *testing.T) {
+	agent := server.AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, emitter *server.EventEmitter) error {
+		messageID, err := emitter.StartTextMessage(ctx, "assistant")
+		if err != nil {
+			return err
+		}
+		if err := emitter.EmitContent(ctx, messageID, "Mail me at jane@example.com"); err != nil {
+			return err
+		}
+		if err := emitter.EndTextMessage(ctx, messageID); err != nil {
+			return err
+		}
+		callID, err := emitter.StartToolCall(ctx, "search", messageID)
+		if err != nil {
+			return err
+		}
+		if err := emitter.EmitToolCallArgs(ctx, callID, `{"q":"how to build a bomb"}`); err != nil {
+			return err
+		}
+		return emitter.EndToolCall(ctx, callID)
+	})
+	out := &sink{}
+	moderator := NewModerator(Config{Hooks: []Hook{piiAndViolence}})
+
+	err := server.NewRunManager(agent, server.RunManagerConfig{Tap: moderator.Tap}).Run(context.Background(), newInput(), out)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrBlocked)
+	var blocked *BlockedError
+	require.ErrorAs(t, err, &blocked)
+	assert.Equal(t, KindToolArgs, blocked.Kind)
+
+	assert.Equal(t, []events.EventType{
+		events.EventTypeRunStarted,
+		events.EventTypeTextMessageStart,
+		events.EventTypeTextMessageContent,
+		events.EventTypeCustom,
+		events.EventTypeTextMessageEnd,
+		events.EventTypeToolCallStart,
+		events.EventTypeCustom,
+		events.EventTypeRunError,
+	}, out.types())
+	assert.Equal(t, "Mail me at [redacted]", out.events[2].(*events.TextMessageContentEvent).Delta)
+	redacted := out.moderation(t, 3)
+	assert.Equal(t, events.ModerationRedacted, redacted.Action)
+	assert.Equal(t, []string{"pii"}, redacted.Labels)
+	assert.NotEmpty(t, redacted.MessageID)
+	assert.Equal(t, events.Moderation{ToolCallID: blocked.ID, Action: events.ModerationBlocked, Labels: []string{"violence"}}, *out.moderation(t, 6))
+	runError := out.events[7].(*events.RunErrorEvent)
+	assert.Equal(t, RunErrorCodeBlocked, *runError.Code)
+	assert.Equal(t, ErrBlocked.Error(), runError.Message)
+}
+
+func TestModeratorBuffers(t *testing.T) {
+	agent := server.AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, emitter *server.EventEmitter) error {
+		for _, event := range []events.Event{
+			events.NewTextMessageStartEvent("msg-1", events.WithRole("assistant")),
+			events.NewTextMessageContentEvent("msg-1", "Write to jane@exa"),
+			events.NewTextMessageContentEvent("msg-1", "mple.com or "),
+			events.NewTextMessageContentEvent("msg-1", "to me"),
+			events.NewTextMessageEndEvent("msg-1"),
+		} {
+			if err := emitter.Emit(ctx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	out := &sink{}
+	moderator := NewModerator(Config{Hooks: []Hook{piiAndViolence}, BufferBytes: 20})
+
+	require.NoError(t, server.NewRunManager(agent, server.RunManagerConfig{Tap: moderator.Tap}).Run(context.Background(), newInput(), out))
+	assert.Equal(t, []events.EventType{
+		events.EventTypeRunStarted,
+		events.EventTypeTextMessageStart,
+		events.EventTypeTextMessageContent,
+		events.EventTypeCustom,
+		events.EventTypeTextMessageContent,
+		events.EventTypeTextMessageEnd,
+		events.EventTypeRunFinished,
+	}, out.types())
+	assert.Equal(t, "Write to [redacted] or ", out.events[2].(*events.TextMessageContentEvent).Delta, "the deltas are moderated together")
+	assert.Equal(t, "to me", out.events[4].(*events.TextMessageContentEvent).Delta, "buffered deltas are flushed before other events")
+}
+
+func TestModeratorAsync(t *testing.T) {
+	release := make(chan struct{})
+	slow := HookFunc(func(ctx context.Context, content Content) (Verdict, error) {
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+			return Verdict{}, errors.New("the agent was held back")
+		}
+		if content.Text == "boom" {
+			return Verdict{Action: Block, Reason: "explosive"}, nil
+		}
+		return Verdict{}, nil
+	})
+	run := func(deltas ...string) (*sink, error) {
+		agent := server.AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, emitter *server.EventEmitter) error {
+			messageID, err := emitter.StartTextMessage(ctx, "assistant")
+			if err != nil {
+				return err
+			}
+			for _, delta := range deltas {
+				if err := emitter.EmitContent(ctx, messageID, delta); err != nil {
+					return err
+				}
+			}
+			release <- struct{}{}
+			return emitter.EndTextMessage(ctx, messageID)
+		})
+		out := &sink{}
+		moderator := NewModerator(Config{Hooks: []Hook{slow}, Async: true})
+		go func() {
+			for range deltas[1:] {
+				release <- struct{}{}
+			}
+		}()
+		return out, server.NewRunManager(agent, server.RunManagerConfig{Tap: moderator.Tap}).Run(context.Background(), newInput(), out)
+	}
+
+	out, err := run("Hello", " world")
+	require.NoError(t, err)
+	assert.Equal(t, []events.EventType{
+		events.EventTypeRunStarted,
+		events.EventTypeTextMessageStart,
+		events.EventTypeTextMessageContent,
+		events.EventTypeTextMessageContent,
+		events.EventTypeTextMessageEnd,
+		events.EventTypeRunFinished,
+	}, out.types())
+
+	// The agent ends the message before the block is known; the run ends
+	// with an error nonetheless
+	out, _ = run("Ready?", "boom")
+	assert.Equal(t, []events.EventType{
+		events.EventTypeRunStarted,
+		events.EventTypeTextMessageStart,
+		events.EventTypeTextMessageContent,
+		events.EventTypeCustom,
+		events.EventTypeRunError,
+	}, out.types())
+	assert.Equal(t, "explosive", out.moderation(t, 3).Reason)
+}
+
+func TestModeratorHookErrors(t *testing.T) {
+	failing := HookFunc(func(context.Context, Content) (Verdict, error) {
+		return Verdict{}, errors.New("service unavailable")
+	})
+	agent := server.AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, emitter *server.EventEmitter) error {
+		messageID, err := emitter.StartTextMessage(ctx, "assistant")
+		if err != nil {
+			return err
+		}
+		if err := emitter.EmitContent(ctx, messageID, "Hello"); err != nil {
+			return err
+		}
+		return emitter.EndTextMessage(ctx, messageID)
+	})
+
+	out := &sink{}
+	moderator := NewModerator(Config{Hooks: []Hook{failing}, FailOpen: true})
+	require.NoError(t, server.NewRunManager(agent, server.RunManagerConfig{Tap: moderator.Tap}).Run(context.Background(), newInput(), out))
+	assert.Equal(t, events.EventTypeTextMessageContent, out.events[2].Type())
+
+	out = &sink{}
+	moderator = NewModerator(Config{Hooks: []Hook{failing}})
+	err := server.NewRunManager(agent, server.RunManagerConfig{Tap: moderator.Tap}).Run(context.Background(), newInput(), out)
+	assert.ErrorIs(t, err, ErrBlocked)
+	assert.Equal(t, "moderation failed", out.moderation(t, 2).Reason)
+}
+
+func TestModeratorUpdatedMessages(t *testing.T) {
+	moderator := NewModerator(Config{Hooks: []Hook{piiAndViolence}, BufferBytes: 1024})
+	out := &sink{}
+	emitter := moderator.Tap(context.Background(), newInput(), out)
+	require.NoError(t, emitter.Emit(context.Background(), events.NewMessageUpdatedEvent("msg-1", "Reach me at jane@example.com")))
+	require.Len(t, out.events, 2)
+	assert.Equal(t, "Reach me at [redacted]", out.events[0].(*events.MessageUpdatedEvent).Content)
+	assert.Equal(t, "msg-1", out.moderation(t, 1).MessageID)
+}
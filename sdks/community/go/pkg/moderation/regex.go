@@ -0,0 +1,83 @@
+package moderation
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// defaultReplacement replaces the matches of Redact rules unless configured
+const defaultReplacement = "[redacted]"
+
+// Rule matches content with a regular expression
+type Rule struct {
+	// Pattern matches the content the rule applies to
+	Pattern *regexp.Regexp
+
+	// Action is taken on matching content: Redact replaces the matches with
+	// Replacement, Block blocks the content and Allow only labels it
+	Action Action
+
+	// Replacement replaces the matches of Redact rules (defaults to
+	// "[redacted]"). It may refer to submatches, see
+	// regexp.Regexp.ReplaceAllString.
+	Replacement string
+
+	// Label labels matching content, and Reason explains its verdict
+	Label  string
+	Reason string
+
+	// Kinds restricts the rule to content of these kinds (empty = all)
+	Kinds []Kind
+}
+
+// Keywords returns a rule matching words as whole words, case-insensitively
+func Keywords(action Action, label string, words ...string) Rule {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = regexp.QuoteMeta(word)
+	}
+	return Rule{
+		Pattern: regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`),
+		Action:  action,
+		Label:   label,
+	}
+}
+
+// NewRegexHook returns a hook applying rules in order, each to the content
+// as redacted by the previous ones, until one blocks it
+func NewRegexHook(rules ...Rule) Hook {
+	return HookFunc(func(_ context.Context, content Content) (Verdict, error) {
+		verdict := Verdict{Text: content.Text}
+		var reasons []string
+		for _, rule := range rules {
+			if len(rule.Kinds) > 0 && !slices.Contains(rule.Kinds, content.Kind) {
+				continue
+			}
+			if !rule.Pattern.MatchString(verdict.Text) {
+				continue
+			}
+			if rule.Label != "" && !slices.Contains(verdict.Labels, rule.Label) {
+				verdict.Labels = append(verdict.Labels, rule.Label)
+			}
+			if rule.Reason != "" {
+				reasons = append(reasons, rule.Reason)
+			}
+			verdict.Reason = strings.Join(reasons, "; ")
+			switch rule.Action {
+			case Redact:
+				replacement := rule.Replacement
+				if replacement == "" {
+					replacement = defaultReplacement
+				}
+				verdict.Action = Redact
+				verdict.Text = rule.Pattern.ReplaceAllString(verdict.Text, replacement)
+			case Block:
+				verdict.Action, verdict.Text = Block, ""
+				return verdict, nil
+			}
+		}
+		return verdict, nil
+	})
+}
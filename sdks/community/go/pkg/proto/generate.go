@@ -0,0 +1,17 @@
+// Package proto contains the protobuf definitions of the AG-UI protocol.
+//
+// events.proto, types.proto and patch.proto are copies of the canonical
+// definitions in sdks/typescript/packages/proto/src/proto and must be kept in
+// sync with them. agent_service.proto defines the gRPC AgentService. Generated
+// Go code lives in the pb subpackage; regenerate it with go generate, which
+// requires buf, protoc-gen-go and protoc-gen-go-grpc on the PATH.
+//
+// The conversions between the protobuf events and the Go SDK events in
+// pb/convert_gen.go are generated from both by internal/convgen, which fails
+// for event types without a conversion. Regenerate them with
+//
+//	go generate -run convgen ./pkg/proto
+package proto
+
+//go:generate buf generate
+//go:generate go run ./internal/convgen -pb pb -events ../core/events -out pb/convert_gen.go
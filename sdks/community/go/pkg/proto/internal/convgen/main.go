@@ -0,0 +1,544 @@
+// Command convgen generates the conversions between the protobuf events of
+// package pb and the Go SDK event types, writing pb/convert_gen.go:
+//
+//	go run ./internal/convgen -pb pb -events ../core/events -out pb/convert_gen.go
+//
+// Protobuf events are read from the descriptors embedded in the source of
+// package pb, which therefore need not build, and Go events from the source
+// of package events: every event type of
+// validEventTypes maps to the struct named after its constant, and to the
+// member of the Event oneof whose message has the same name. Fields are
+// matched by their JSON names.
+//
+// Generation fails unless every event type is converted, converted by hand
+// (see manualEvents) or listed in jsonOnlyEvents, so that new event types
+// cannot be added without deciding on their protobuf representation. The
+// package tests fail when pb/convert_gen.go is not up to date.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// jsonOnlyEvents are the event types sent as JSON on the RunAgent stream,
+// with the reason they have no protobuf conversion
+var jsonOnlyEvents = map[string]string{
+	"TOOL_CALL_RESULT":              "not in events.proto",
+	"MESSAGES_SNAPSHOT":             "message conversions are not implemented",
+	"ACTIVITY_SNAPSHOT":             "not in events.proto",
+	"ACTIVITY_DELTA":                "not in events.proto",
+	"THINKING_START":                "not in events.proto",
+	"THINKING_END":                  "not in events.proto",
+	"THINKING_TEXT_MESSAGE_START":   "not in events.proto",
+	"THINKING_TEXT_MESSAGE_CONTENT": "not in events.proto",
+	"THINKING_TEXT_MESSAGE_END":     "not in events.proto",
+	"REASONING_START":               "not in events.proto",
+	"REASONING_MESSAGE_START":       "not in events.proto",
+	"REASONING_MESSAGE_CONTENT":     "not in events.proto",
+	"REASONING_MESSAGE_END":         "not in events.proto",
+	"REASONING_MESSAGE_CHUNK":       "not in events.proto",
+	"REASONING_END":                 "not in events.proto",
+	"REASONING_ENCRYPTED_VALUE":     "not in events.proto",
+	"MESSAGE_UPDATED":               "not in events.proto",
+	"MESSAGE_DELETED":               "not in events.proto",
+	"APPROVAL_REQUESTED":            "not in events.proto",
+	"APPROVAL_GRANTED":              "not in events.proto",
+	"APPROVAL_DENIED":               "not in events.proto",
+}
+
+// manualEvents are the event types whose fields do not map one to one,
+// converted by the functions of pb/convert.go named after their message:
+// xToProto(*BaseEvent, *events.X) (*X, error) and xFromProto(*X) *events.X
+var manualEvents = map[string]bool{
+	// The outcome of the Go event is flattened into outcome and interrupts
+	"RUN_FINISHED": true,
+}
+
+func main() {
+	pbDir := flag.String("pb", "pb", "source directory of package pb")
+	eventsDir := flag.String("events", "../core/events", "source directory of package events")
+	out := flag.String("out", "pb/convert_gen.go", "file to write")
+	flag.Parse()
+
+	src, err := generate(*pbDir, *eventsDir)
+	if err == nil {
+		err = os.WriteFile(*out, src, 0o644)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "convgen:", err)
+		os.Exit(1)
+	}
+}
+
+// generate returns the source of the conversions between the packages pb
+// and events in pbDir and eventsDir
+func generate(pbDir, eventsDir string) ([]byte, error) {
+	members, err := loadProtoEvents(pbDir)
+	if err != nil {
+		return nil, err
+	}
+	goEvents, err := loadGoEvents(eventsDir)
+	if err != nil {
+		return nil, err
+	}
+	conversions, err := plan(goEvents, members)
+	if err != nil {
+		return nil, err
+	}
+	return render(conversions)
+}
+
+// goEvent is an event type of package events and its struct
+type goEvent struct {
+	Type   string // value of the event type, e.g. RUN_STARTED
+	Const  string // name of its constant, e.g. EventTypeRunStarted
+	Struct string // name of its struct, e.g. RunStartedEvent
+	Fields []goField
+}
+
+// goField is a field of an event struct
+type goField struct {
+	Name string
+	JSON string // name in the JSON encoding
+	Type string // type expression, e.g. *string
+}
+
+// loadGoEvents parses the event types of validEventTypes and their structs
+// from the source of package events
+func loadGoEvents(dir string) ([]goEvent, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	pkg, ok := pkgs["events"]
+	if !ok {
+		return nil, fmt.Errorf("no events package in %s", dir)
+	}
+
+	constants := map[string]string{}
+	structs := map[string]*ast.StructType{}
+	var valid []string
+	for _, file := range pkg.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.ValueSpec:
+				for i, name := range n.Names {
+					if i < len(n.Values) {
+						if lit, ok := n.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+							constants[name.Name], _ = strconv.Unquote(lit.Value)
+						}
+					}
+					if name.Name == "validEventTypes" && len(n.Values) == 1 {
+						for _, elt := range n.Values[0].(*ast.CompositeLit).Elts {
+							if key, ok := elt.(*ast.KeyValueExpr).Key.(*ast.Ident); ok {
+								valid = append(valid, key.Name)
+							}
+						}
+					}
+				}
+			case *ast.TypeSpec:
+				if st, ok := n.Type.(*ast.StructType); ok {
+					structs[n.Name.Name] = st
+				}
+			}
+			return true
+		})
+	}
+	if len(valid) == 0 {
+		return nil, fmt.Errorf("validEventTypes not found in %s", dir)
+	}
+
+	goEvents := make([]goEvent, 0, len(valid))
+	for _, name := range valid {
+		event := goEvent{
+			Type:   constants[name],
+			Const:  name,
+			Struct: strings.TrimPrefix(name, "EventType") + "Event",
+		}
+		st, ok := structs[event.Struct]
+		if !ok {
+			return nil, fmt.Errorf("%s has no struct %s", event.Type, event.Struct)
+		}
+		for _, f := range st.Fields.List {
+			if len(f.Names) == 0 {
+				continue // *BaseEvent
+			}
+			jsonName := ""
+			if f.Tag != nil {
+				tag, _ := strconv.Unquote(f.Tag.Value)
+				jsonName, _, _ = strings.Cut(reflect.StructTag(tag).Get("json"), ",")
+			}
+			if jsonName == "" || jsonName == "-" {
+				continue
+			}
+			event.Fields = append(event.Fields, goField{Name: f.Names[0].Name, JSON: jsonName, Type: types.ExprString(f.Type)})
+		}
+		goEvents = append(goEvents, event)
+	}
+	return goEvents, nil
+}
+
+// loadProtoEvents returns the members of the Event oneof, built from the
+// raw descriptors of the files generated by protoc-gen-go in dir
+func loadProtoEvents(dir string) (protoreflect.FieldDescriptors, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return strings.HasSuffix(fi.Name(), ".pb.go") && !strings.HasSuffix(fi.Name(), "_grpc.pb.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+		protodesc.ToFileDescriptorProto(structpb.File_google_protobuf_struct_proto),
+	}}
+	for _, pkg := range pkgs {
+		for name, file := range pkg.Files {
+			raw, err := rawDescriptor(file)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			fd := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(raw, fd); err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			set.File = append(set.File, fd)
+		}
+	}
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, err
+	}
+
+	desc, err := files.FindDescriptorByName("ag_ui.Event")
+	if err != nil {
+		return nil, err
+	}
+	event, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok || event.Oneofs().ByName("event") == nil {
+		return nil, fmt.Errorf("ag_ui.Event has no event oneof")
+	}
+	return event.Oneofs().ByName("event").Fields(), nil
+}
+
+// rawDescriptor returns the bytes of the file_*_rawDesc variable of file,
+// the serialized descriptor of its .proto file
+func rawDescriptor(file *ast.File) ([]byte, error) {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			if len(vs.Names) != 1 || !strings.HasSuffix(vs.Names[0].Name, "_rawDesc") || len(vs.Values) != 1 {
+				continue
+			}
+			// string([]byte{0x0a, ...})
+			call, ok := vs.Values[0].(*ast.CallExpr)
+			if !ok || len(call.Args) != 1 {
+				return nil, fmt.Errorf("unexpected form of %s", vs.Names[0].Name)
+			}
+			lit, ok := call.Args[0].(*ast.CompositeLit)
+			if !ok {
+				return nil, fmt.Errorf("unexpected form of %s", vs.Names[0].Name)
+			}
+			raw := make([]byte, 0, len(lit.Elts))
+			for _, elt := range lit.Elts {
+				b, ok := elt.(*ast.BasicLit)
+				if !ok {
+					return nil, fmt.Errorf("unexpected form of %s", vs.Names[0].Name)
+				}
+				v, err := strconv.ParseUint(b.Value, 0, 8)
+				if err != nil {
+					return nil, err
+				}
+				raw = append(raw, byte(v))
+			}
+			return raw, nil
+		}
+	}
+	return nil, errors.New("no raw descriptor")
+}
+
+// conversion is an event type converted by the generated code
+type conversion struct {
+	Event  goEvent
+	Member protoreflect.FieldDescriptor // member of the Event oneof
+	Manual bool
+	Fields []fieldConversion
+	Unset  []goField // Go fields without a protobuf counterpart
+}
+
+// fieldConversion converts a Go field to a protobuf field and back
+type fieldConversion struct {
+	Go    goField
+	Proto protoreflect.FieldDescriptor
+	// ToProto and FromProto are the expressions converting the field, with
+	// %s standing for the Go or protobuf message
+	ToProto, FromProto string
+	// Fallible is set when ToProto also returns an error
+	Fallible bool
+}
+
+// plan matches the Go events with the members of the Event oneof, failing
+// for event types that are neither converted nor listed as JSON-only
+func plan(goEvents []goEvent, members protoreflect.FieldDescriptors) ([]conversion, error) {
+	byStruct := map[string]goEvent{}
+	for _, event := range goEvents {
+		byStruct[event.Struct] = event
+	}
+
+	var conversions []conversion
+	var errs []error
+	converted := map[string]bool{}
+	for i := 0; i < members.Len(); i++ {
+		member := members.Get(i)
+		name := string(member.Message().Name())
+		event, ok := byStruct[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("protobuf event %s has no Go struct", name))
+			continue
+		}
+		c, err := convert(event, member)
+		if _, jsonOnly := jsonOnlyEvents[event.Type]; jsonOnly {
+			if err == nil {
+				errs = append(errs, fmt.Errorf("%s is listed as JSON-only but can be converted", event.Type))
+			}
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		conversions = append(conversions, c)
+		converted[event.Type] = true
+	}
+
+	for _, event := range goEvents {
+		_, jsonOnly := jsonOnlyEvents[event.Type]
+		if !converted[event.Type] && !jsonOnly {
+			errs = append(errs, fmt.Errorf("%s has no protobuf conversion: add it to events.proto or to jsonOnlyEvents", event.Type))
+		}
+	}
+	for eventType := range jsonOnlyEvents {
+		if !slices.ContainsFunc(goEvents, func(e goEvent) bool { return e.Type == eventType }) {
+			errs = append(errs, fmt.Errorf("JSON-only event type %s does not exist", eventType))
+		}
+	}
+	return conversions, errors.Join(errs...)
+}
+
+// convert matches the fields of a Go event with those of its protobuf message
+func convert(event goEvent, member protoreflect.FieldDescriptor) (conversion, error) {
+	c := conversion{Event: event, Member: member, Manual: manualEvents[event.Type]}
+	if c.Manual {
+		return c, nil
+	}
+
+	// Protobuf fields missing from the Go event are left unset
+	fields := member.Message().Fields()
+	for _, f := range event.Fields {
+		fd := findField(fields, f.JSON)
+		if fd == nil {
+			if zeroCheck(f) == "" {
+				return c, fmt.Errorf("%s.%s has no protobuf field and cannot be checked for zero", event.Struct, f.Name)
+			}
+			c.Unset = append(c.Unset, f)
+			continue
+		}
+		fc, err := convertField(f, fd)
+		if err != nil {
+			return c, fmt.Errorf("%s.%s: %w", event.Struct, f.Name, err)
+		}
+		c.Fields = append(c.Fields, fc)
+	}
+	return c, nil
+}
+
+// findField returns the field named jsonName in JSON
+func findField(fields protoreflect.FieldDescriptors, jsonName string) protoreflect.FieldDescriptor {
+	for i := 0; i < fields.Len(); i++ {
+		if fd := fields.Get(i); fd.JSONName() == jsonName && fd.Name() != "base_event" {
+			return fd
+		}
+	}
+	return nil
+}
+
+// convertField returns the conversion of a Go field to fd
+func convertField(f goField, fd protoreflect.FieldDescriptor) (fieldConversion, error) {
+	fc := fieldConversion{Go: f, Proto: fd}
+	goName, getter := "%s."+f.Name, "%s.Get"+goCamelCase(string(fd.Name()))+"()"
+	switch {
+	case fd.Kind() == protoreflect.StringKind && !fd.IsList():
+		switch {
+		case f.Type == "string" && !fd.HasPresence():
+			fc.ToProto, fc.FromProto = goName, getter
+		case f.Type == "*string" && fd.HasPresence():
+			fc.ToProto, fc.FromProto = goName, "%s."+goCamelCase(string(fd.Name()))
+		default:
+			return fc, fmt.Errorf("no conversion between %s and %s", f.Type, describe(fd))
+		}
+	case fd.Kind() == protoreflect.MessageKind && !fd.IsList() && fd.Message().FullName() == "google.protobuf.Value":
+		if f.Type != "any" && f.Type != "interface{}" {
+			return fc, fmt.Errorf("no conversion between %s and %s", f.Type, describe(fd))
+		}
+		fc.ToProto, fc.FromProto, fc.Fallible = "valueToProto("+goName+")", "valueFromProto("+getter+")", true
+		if fd.HasOptionalKeyword() {
+			fc.ToProto = "optionalValueToProto(" + goName + ")"
+		}
+	case fd.Kind() == protoreflect.MessageKind && fd.IsList() && fd.Message().FullName() == "ag_ui.JsonPatchOperation":
+		if f.Type != "[]JSONPatchOperation" {
+			return fc, fmt.Errorf("no conversion between %s and %s", f.Type, describe(fd))
+		}
+		fc.ToProto, fc.FromProto, fc.Fallible = "patchToProto("+goName+")", "patchFromProto("+getter+")", true
+	default:
+		return fc, fmt.Errorf("no conversion between %s and %s", f.Type, describe(fd))
+	}
+	return fc, nil
+}
+
+// describe returns the protobuf type of fd, e.g. repeated ag_ui.Message
+func describe(fd protoreflect.FieldDescriptor) string {
+	name := fd.Kind().String()
+	if fd.Message() != nil {
+		name = string(fd.Message().FullName())
+	}
+	switch {
+	case fd.IsList():
+		return "repeated " + name
+	case fd.HasOptionalKeyword():
+		return "optional " + name
+	}
+	return name
+}
+
+// zeroCheck returns the condition under which the Go field f of e is set,
+// or "" for types it cannot check
+func zeroCheck(f goField) string {
+	switch {
+	case f.Type == "string":
+		return "e." + f.Name + ` != ""`
+	case strings.HasPrefix(f.Type, "*"), f.Type == "any", f.Type == "interface{}":
+		return "e." + f.Name + " != nil"
+	case strings.HasPrefix(f.Type, "[]"), strings.HasPrefix(f.Type, "map["):
+		return "len(e." + f.Name + ") > 0"
+	}
+	return ""
+}
+
+// goCamelCase returns the Go name of a protobuf field, e.g. MessageId for
+// message_id
+func goCamelCase(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part != "" {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
+
+// lowerFirst returns name with its first letter lowered, e.g. runFinished
+func lowerFirst(name string) string {
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// render returns the formatted source of the conversions
+func render(conversions []conversion) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(`// Code generated by convgen; DO NOT EDIT.
+
+package pb
+
+import (
+	"fmt"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// eventToProto converts the events with a protobuf representation, whose
+// shared fields were converted to base
+func eventToProto(base *BaseEvent, event events.Event) (*Event, error) {
+	switch e := event.(type) {
+`)
+	for _, c := range conversions {
+		message := string(c.Member.Message().Name())
+		member := goCamelCase(string(c.Member.Name()))
+		fmt.Fprintf(&b, "case *events.%s:\n", c.Event.Struct)
+		for _, f := range c.Unset {
+			fmt.Fprintf(&b, "if %s {\nreturn nil, fmt.Errorf(\"%%w: %%s with %s\", ErrUnsupportedEvent, e.Type())\n}\n", zeroCheck(f), f.JSON)
+		}
+		if c.Manual {
+			fmt.Fprintf(&b, "%s, err := %sToProto(base, e)\nif err != nil {\nreturn nil, err\n}\n", lowerFirst(member), lowerFirst(member))
+			fmt.Fprintf(&b, "return &Event{Event: &Event_%s{%s: %s}}, nil\n", member, member, lowerFirst(member))
+			continue
+		}
+		for _, f := range c.Fields {
+			if f.Fallible {
+				fmt.Fprintf(&b, "%s, err := %s\nif err != nil {\nreturn nil, err\n}\n", lowerFirst(goCamelCase(string(f.Proto.Name()))), fmt.Sprintf(f.ToProto, "e"))
+			}
+		}
+		fmt.Fprintf(&b, "return &Event{Event: &Event_%s{%s: &%s{\nBaseEvent: base,\n", member, member, message)
+		for _, f := range c.Fields {
+			value := fmt.Sprintf(f.ToProto, "e")
+			if f.Fallible {
+				value = lowerFirst(goCamelCase(string(f.Proto.Name())))
+			}
+			fmt.Fprintf(&b, "%s: %s,\n", goCamelCase(string(f.Proto.Name())), value)
+		}
+		b.WriteString("}}}, nil\n")
+	}
+	b.WriteString(`default:
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedEvent, event.Type())
+	}
+}
+
+// eventFromProto converts a protobuf event to its Go SDK representation
+func eventFromProto(pe *Event) (events.Event, error) {
+	switch e := pe.GetEvent().(type) {
+`)
+	for _, c := range conversions {
+		member := goCamelCase(string(c.Member.Name()))
+		fmt.Fprintf(&b, "case *Event_%s:\n", member)
+		if c.Manual {
+			fmt.Fprintf(&b, "event := %sFromProto(e.%s)\n", lowerFirst(member), member)
+		} else {
+			fmt.Fprintf(&b, "event := &events.%s{\n", c.Event.Struct)
+			for _, f := range c.Fields {
+				fmt.Fprintf(&b, "%s: %s,\n", f.Go.Name, fmt.Sprintf(f.FromProto, "e."+member))
+			}
+			b.WriteString("}\n")
+		}
+		fmt.Fprintf(&b, "event.BaseEvent = baseFromProto(events.%s, e.%s.GetBaseEvent())\nreturn event, nil\n", c.Event.Const, member)
+	}
+	b.WriteString(`default:
+	return nil, fmt.Errorf("unsupported protobuf event %T", pe.GetEvent())
+	}
+}
+`)
+	return format.Source(b.Bytes())
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	pbDir     = "../../pb"
+	eventsDir = "../../../core/events"
+)
+
+func TestGeneratedUpToDate(t *testing.T) {
+	src, err := generate(pbDir, eventsDir)
+	require.NoError(t, err)
+	current, err := os.ReadFile(pbDir + "/convert_gen.go")
+	require.NoError(t, err)
+	assert.Equal(t, string(src), string(current), "pb/convert_gen.go is stale, run go generate -run convgen ./pkg/proto")
+}
+
+func TestPlanRequiresConversions(t *testing.T) {
+	members, err := loadProtoEvents(pbDir)
+	require.NoError(t, err)
+	goEvents, err := loadGoEvents(eventsDir)
+	require.NoError(t, err)
+
+	_, err = plan(append(goEvents, goEvent{Type: "NEW_EVENT", Const: "EventTypeNewEvent", Struct: "NewEventEvent"}), members)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "NEW_EVENT has no protobuf conversion")
+
+	var changed []goEvent
+	for _, event := range goEvents {
+		if event.Type == "TEXT_MESSAGE_CONTENT" {
+			event.Fields = []goField{{Name: "MessageID", JSON: "messageId", Type: "int"}}
+		}
+		changed = append(changed, event)
+	}
+	_, err = plan(changed, members)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "TextMessageContentEvent.MessageID: no conversion between int and string")
+
+	jsonOnlyEvents["REMOVED_EVENT"] = "test"
+	defer delete(jsonOnlyEvents, "REMOVED_EVENT")
+	_, err = plan(goEvents, members)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "JSON-only event type REMOVED_EVENT does not exist")
+}
@@ -0,0 +1,337 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        (unknown)
+// source: agent_service.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RunAgentRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Request:
+	//
+	//	*RunAgentRequest_Input
+	//	*RunAgentRequest_Cancel
+	Request       isRunAgentRequest_Request `protobuf_oneof:"request"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunAgentRequest) Reset() {
+	*x = RunAgentRequest{}
+	mi := &file_agent_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunAgentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunAgentRequest) ProtoMessage() {}
+
+func (x *RunAgentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunAgentRequest.ProtoReflect.Descriptor instead.
+func (*RunAgentRequest) Descriptor() ([]byte, []int) {
+	return file_agent_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RunAgentRequest) GetRequest() isRunAgentRequest_Request {
+	if x != nil {
+		return x.Request
+	}
+	return nil
+}
+
+func (x *RunAgentRequest) GetInput() []byte {
+	if x != nil {
+		if x, ok := x.Request.(*RunAgentRequest_Input); ok {
+			return x.Input
+		}
+	}
+	return nil
+}
+
+func (x *RunAgentRequest) GetCancel() *CancelRun {
+	if x != nil {
+		if x, ok := x.Request.(*RunAgentRequest_Cancel); ok {
+			return x.Cancel
+		}
+	}
+	return nil
+}
+
+type isRunAgentRequest_Request interface {
+	isRunAgentRequest_Request()
+}
+
+type RunAgentRequest_Input struct {
+	// JSON-encoded RunAgentInput, identical to the HTTP request body
+	Input []byte `protobuf:"bytes,1,opt,name=input,proto3,oneof"`
+}
+
+type RunAgentRequest_Cancel struct {
+	Cancel *CancelRun `protobuf:"bytes,2,opt,name=cancel,proto3,oneof"`
+}
+
+func (*RunAgentRequest_Input) isRunAgentRequest_Request() {}
+
+func (*RunAgentRequest_Cancel) isRunAgentRequest_Request() {}
+
+type CancelRun struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        *string                `protobuf:"bytes,1,opt,name=reason,proto3,oneof" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelRun) Reset() {
+	*x = CancelRun{}
+	mi := &file_agent_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelRun) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelRun) ProtoMessage() {}
+
+func (x *CancelRun) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelRun.ProtoReflect.Descriptor instead.
+func (*CancelRun) Descriptor() ([]byte, []int) {
+	return file_agent_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CancelRun) GetReason() string {
+	if x != nil && x.Reason != nil {
+		return *x.Reason
+	}
+	return ""
+}
+
+type RunAgentResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Response:
+	//
+	//	*RunAgentResponse_Event
+	//	*RunAgentResponse_JsonEvent
+	Response      isRunAgentResponse_Response `protobuf_oneof:"response"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunAgentResponse) Reset() {
+	*x = RunAgentResponse{}
+	mi := &file_agent_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunAgentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunAgentResponse) ProtoMessage() {}
+
+func (x *RunAgentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunAgentResponse.ProtoReflect.Descriptor instead.
+func (*RunAgentResponse) Descriptor() ([]byte, []int) {
+	return file_agent_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RunAgentResponse) GetResponse() isRunAgentResponse_Response {
+	if x != nil {
+		return x.Response
+	}
+	return nil
+}
+
+func (x *RunAgentResponse) GetEvent() *Event {
+	if x != nil {
+		if x, ok := x.Response.(*RunAgentResponse_Event); ok {
+			return x.Event
+		}
+	}
+	return nil
+}
+
+func (x *RunAgentResponse) GetJsonEvent() []byte {
+	if x != nil {
+		if x, ok := x.Response.(*RunAgentResponse_JsonEvent); ok {
+			return x.JsonEvent
+		}
+	}
+	return nil
+}
+
+type isRunAgentResponse_Response interface {
+	isRunAgentResponse_Response()
+}
+
+type RunAgentResponse_Event struct {
+	// Event is used for event types with a protobuf representation
+	Event *Event `protobuf:"bytes,1,opt,name=event,proto3,oneof"`
+}
+
+type RunAgentResponse_JsonEvent struct {
+	// JSON-encoded event for event types without a protobuf representation
+	JsonEvent []byte `protobuf:"bytes,2,opt,name=json_event,json=jsonEvent,proto3,oneof"`
+}
+
+func (*RunAgentResponse_Event) isRunAgentResponse_Response() {}
+
+func (*RunAgentResponse_JsonEvent) isRunAgentResponse_Response() {}
+
+var File_agent_service_proto protoreflect.FileDescriptor
+
+var file_agent_service_proto_rawDesc = string([]byte{
+	0x0a, 0x13, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05, 0x61, 0x67, 0x5f, 0x75, 0x69, 0x1a, 0x0c, 0x65, 0x76,
+	0x65, 0x6e, 0x74, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x60, 0x0a, 0x0f, 0x52, 0x75,
+	0x6e, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a,
+	0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x05,
+	0x69, 0x6e, 0x70, 0x75, 0x74, 0x12, 0x2a, 0x0a, 0x06, 0x63, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x61, 0x67, 0x5f, 0x75, 0x69, 0x2e, 0x43, 0x61,
+	0x6e, 0x63, 0x65, 0x6c, 0x52, 0x75, 0x6e, 0x48, 0x00, 0x52, 0x06, 0x63, 0x61, 0x6e, 0x63, 0x65,
+	0x6c, 0x42, 0x09, 0x0a, 0x07, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x33, 0x0a, 0x09,
+	0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x52, 0x75, 0x6e, 0x12, 0x1b, 0x0a, 0x06, 0x72, 0x65, 0x61,
+	0x73, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x06, 0x72, 0x65, 0x61,
+	0x73, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f,
+	0x6e, 0x22, 0x65, 0x0a, 0x10, 0x52, 0x75, 0x6e, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x24, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x61, 0x67, 0x5f, 0x75, 0x69, 0x2e, 0x45, 0x76, 0x65,
+	0x6e, 0x74, 0x48, 0x00, 0x52, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x1f, 0x0a, 0x0a, 0x6a,
+	0x73, 0x6f, 0x6e, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x48,
+	0x00, 0x52, 0x09, 0x6a, 0x73, 0x6f, 0x6e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x42, 0x0a, 0x0a, 0x08,
+	0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0x4f, 0x0a, 0x0c, 0x41, 0x67, 0x65, 0x6e,
+	0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x3f, 0x0a, 0x08, 0x52, 0x75, 0x6e, 0x41,
+	0x67, 0x65, 0x6e, 0x74, 0x12, 0x16, 0x2e, 0x61, 0x67, 0x5f, 0x75, 0x69, 0x2e, 0x52, 0x75, 0x6e,
+	0x41, 0x67, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x61,
+	0x67, 0x5f, 0x75, 0x69, 0x2e, 0x52, 0x75, 0x6e, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x42, 0x8e, 0x01, 0x0a, 0x09, 0x63, 0x6f,
+	0x6d, 0x2e, 0x61, 0x67, 0x5f, 0x75, 0x69, 0x42, 0x11, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x53, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50, 0x01, 0x5a, 0x3e, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x67, 0x2d, 0x75, 0x69, 0x2d, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x2f, 0x61, 0x67, 0x2d, 0x75, 0x69, 0x2f, 0x73, 0x64,
+	0x6b, 0x73, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x75, 0x6e, 0x69, 0x74, 0x79, 0x2f, 0x67, 0x6f, 0x2f,
+	0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x70, 0x62, 0xa2, 0x02, 0x03, 0x41,
+	0x58, 0x58, 0xaa, 0x02, 0x04, 0x41, 0x67, 0x55, 0x69, 0xca, 0x02, 0x04, 0x41, 0x67, 0x55, 0x69,
+	0xe2, 0x02, 0x10, 0x41, 0x67, 0x55, 0x69, 0x5c, 0x47, 0x50, 0x42, 0x4d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0xea, 0x02, 0x04, 0x41, 0x67, 0x55, 0x69, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+})
+
+var (
+	file_agent_service_proto_rawDescOnce sync.Once
+	file_agent_service_proto_rawDescData []byte
+)
+
+func file_agent_service_proto_rawDescGZIP() []byte {
+	file_agent_service_proto_rawDescOnce.Do(func() {
+		file_agent_service_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_agent_service_proto_rawDesc), len(file_agent_service_proto_rawDesc)))
+	})
+	return file_agent_service_proto_rawDescData
+}
+
+var file_agent_service_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_agent_service_proto_goTypes = []any{
+	(*RunAgentRequest)(nil),  // 0: ag_ui.RunAgentRequest
+	(*CancelRun)(nil),        // 1: ag_ui.CancelRun
+	(*RunAgentResponse)(nil), // 2: ag_ui.RunAgentResponse
+	(*Event)(nil),            // 3: ag_ui.Event
+}
+var file_agent_service_proto_depIdxs = []int32{
+	1, // 0: ag_ui.RunAgentRequest.cancel:type_name -> ag_ui.CancelRun
+	3, // 1: ag_ui.RunAgentResponse.event:type_name -> ag_ui.Event
+	0, // 2: ag_ui.AgentService.RunAgent:input_type -> ag_ui.RunAgentRequest
+	2, // 3: ag_ui.AgentService.RunAgent:output_type -> ag_ui.RunAgentResponse
+	3, // [3:4] is the sub-list for method output_type
+	2, // [2:3] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_agent_service_proto_init() }
+func file_agent_service_proto_init() {
+	if File_agent_service_proto != nil {
+		return
+	}
+	file_events_proto_init()
+	file_agent_service_proto_msgTypes[0].OneofWrappers = []any{
+		(*RunAgentRequest_Input)(nil),
+		(*RunAgentRequest_Cancel)(nil),
+	}
+	file_agent_service_proto_msgTypes[1].OneofWrappers = []any{}
+	file_agent_service_proto_msgTypes[2].OneofWrappers = []any{
+		(*RunAgentResponse_Event)(nil),
+		(*RunAgentResponse_JsonEvent)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_agent_service_proto_rawDesc), len(file_agent_service_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_agent_service_proto_goTypes,
+		DependencyIndexes: file_agent_service_proto_depIdxs,
+		MessageInfos:      file_agent_service_proto_msgTypes,
+	}.Build()
+	File_agent_service_proto = out.File
+	file_agent_service_proto_goTypes = nil
+	file_agent_service_proto_depIdxs = nil
+}
@@ -0,0 +1,251 @@
+package pb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+// ErrUnsupportedEvent is returned for event types without a protobuf representation
+var ErrUnsupportedEvent = errors.New("event type has no protobuf representation")
+
+// NewRunAgentResponse wraps an event for the RunAgent stream, using the
+// protobuf representation when one exists and JSON otherwise
+func NewRunAgentResponse(event events.Event) (*RunAgentResponse, error) {
+	pe, err := EventToProto(event)
+	if err == nil {
+		return &RunAgentResponse{Response: &RunAgentResponse_Event{Event: pe}}, nil
+	}
+	if !errors.Is(err, ErrUnsupportedEvent) {
+		return nil, err
+	}
+
+	data, err := event.ToJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", event.Type(), err)
+	}
+	return &RunAgentResponse{Response: &RunAgentResponse_JsonEvent{JsonEvent: data}}, nil
+}
+
+// ResponseEvent extracts the event carried by a RunAgent response
+func ResponseEvent(resp *RunAgentResponse) (events.Event, error) {
+	switch r := resp.GetResponse().(type) {
+	case *RunAgentResponse_Event:
+		return EventFromProto(r.Event)
+	case *RunAgentResponse_JsonEvent:
+		var envelope struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(r.JsonEvent, &envelope); err != nil {
+			return nil, fmt.Errorf("failed to decode event: %w", err)
+		}
+		return events.NewEventDecoder(nil).DecodeEvent(envelope.Type, r.JsonEvent)
+	default:
+		return nil, fmt.Errorf("response carries no event")
+	}
+}
+
+// EventToProto converts an event to its protobuf representation.
+// ErrUnsupportedEvent is returned for event types missing from events.proto.
+// The conversions of each event type are generated by convgen, see package
+// proto.
+func EventToProto(event events.Event) (*Event, error) {
+	if event == nil {
+		return nil, fmt.Errorf("event cannot be nil")
+	}
+	base, err := baseToProto(event)
+	if err != nil {
+		return nil, err
+	}
+	return eventToProto(base, event)
+}
+
+// EventFromProto converts a protobuf event to its Go SDK representation
+func EventFromProto(pe *Event) (events.Event, error) {
+	return eventFromProto(pe)
+}
+
+// runFinishedToProto converts a RUN_FINISHED event, flattening its outcome
+func runFinishedToProto(base *BaseEvent, e *events.RunFinishedEvent) (*RunFinishedEvent, error) {
+	result, err := optionalValueToProto(e.Result)
+	if err != nil {
+		return nil, err
+	}
+	finished := &RunFinishedEvent{BaseEvent: base, ThreadId: e.ThreadIDValue, RunId: e.RunIDValue, Result: result}
+	if e.Outcome != nil {
+		finished.Outcome = string(e.Outcome.Type)
+		if finished.Interrupts, err = interruptsToProto(e.Outcome.Interrupts); err != nil {
+			return nil, err
+		}
+	}
+	return finished, nil
+}
+
+// runFinishedFromProto converts a protobuf RUN_FINISHED event, leaving its
+// base to the caller
+func runFinishedFromProto(f *RunFinishedEvent) *events.RunFinishedEvent {
+	event := &events.RunFinishedEvent{ThreadIDValue: f.GetThreadId(), RunIDValue: f.GetRunId(), Result: valueFromProto(f.GetResult())}
+	if f.GetOutcome() != "" || len(f.GetInterrupts()) > 0 {
+		event.Outcome = &events.RunFinishedOutcome{
+			Type:       events.RunFinishedOutcomeType(f.GetOutcome()),
+			Interrupts: interruptsFromProto(f.GetInterrupts()),
+		}
+	}
+	return event
+}
+
+// baseToProto converts the fields shared by all events
+func baseToProto(event events.Event) (*BaseEvent, error) {
+	eventType, ok := EventType_value[string(event.Type())]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedEvent, event.Type())
+	}
+	base := &BaseEvent{Type: EventType(eventType), Timestamp: event.Timestamp()}
+	if b := event.GetBaseEvent(); b != nil && b.RawEvent != nil {
+		raw, err := valueToProto(b.RawEvent)
+		if err != nil {
+			return nil, err
+		}
+		base.RawEvent = raw
+	}
+	return base, nil
+}
+
+// baseFromProto converts the fields shared by all events
+func baseFromProto(eventType events.EventType, base *BaseEvent) *events.BaseEvent {
+	result := &events.BaseEvent{
+		EventType: eventType,
+		RawEvent:  valueFromProto(base.GetRawEvent()),
+	}
+	if base != nil && base.Timestamp != nil {
+		timestamp := base.GetTimestamp()
+		result.TimestampMs = &timestamp
+	}
+	return result
+}
+
+// patchToProto converts JSON Patch operations
+func patchToProto(ops []events.JSONPatchOperation) ([]*JsonPatchOperation, error) {
+	result := make([]*JsonPatchOperation, 0, len(ops))
+	for _, op := range ops {
+		opType, ok := JsonPatchOperationType_value[strings.ToUpper(op.Op)]
+		if !ok {
+			return nil, fmt.Errorf("invalid JSON Patch operation %q", op.Op)
+		}
+		value, err := optionalValueToProto(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		pop := &JsonPatchOperation{Op: JsonPatchOperationType(opType), Path: op.Path, Value: value}
+		if op.From != "" {
+			from := op.From
+			pop.From = &from
+		}
+		result = append(result, pop)
+	}
+	return result, nil
+}
+
+// patchFromProto converts protobuf JSON Patch operations
+func patchFromProto(ops []*JsonPatchOperation) []events.JSONPatchOperation {
+	result := make([]events.JSONPatchOperation, 0, len(ops))
+	for _, op := range ops {
+		result = append(result, events.JSONPatchOperation{
+			Op:    strings.ToLower(op.GetOp().String()),
+			Path:  op.GetPath(),
+			Value: valueFromProto(op.GetValue()),
+			From:  op.GetFrom(),
+		})
+	}
+	return result
+}
+
+// interruptsToProto converts run interrupts
+func interruptsToProto(interrupts []types.Interrupt) ([]*Interrupt, error) {
+	result := make([]*Interrupt, 0, len(interrupts))
+	for _, in := range interrupts {
+		pi := &Interrupt{Id: in.ID, Reason: in.Reason}
+		if in.Message != "" {
+			pi.Message = &in.Message
+		}
+		if in.ToolCallID != "" {
+			pi.ToolCallId = &in.ToolCallID
+		}
+		if in.ExpiresAt != "" {
+			pi.ExpiresAt = &in.ExpiresAt
+		}
+		var err error
+		if in.ResponseSchema != nil {
+			if pi.ResponseSchema, err = valueToProto(in.ResponseSchema); err != nil {
+				return nil, err
+			}
+		}
+		if in.Metadata != nil {
+			if pi.Metadata, err = valueToProto(in.Metadata); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, pi)
+	}
+	return result, nil
+}
+
+// interruptsFromProto converts protobuf run interrupts
+func interruptsFromProto(interrupts []*Interrupt) []types.Interrupt {
+	if len(interrupts) == 0 {
+		return nil
+	}
+	result := make([]types.Interrupt, 0, len(interrupts))
+	for _, pi := range interrupts {
+		in := types.Interrupt{
+			ID:         pi.GetId(),
+			Reason:     pi.GetReason(),
+			Message:    pi.GetMessage(),
+			ToolCallID: pi.GetToolCallId(),
+			ExpiresAt:  pi.GetExpiresAt(),
+		}
+		in.ResponseSchema, _ = valueFromProto(pi.GetResponseSchema()).(map[string]any)
+		in.Metadata, _ = valueFromProto(pi.GetMetadata()).(map[string]any)
+		result = append(result, in)
+	}
+	return result
+}
+
+// optionalValueToProto converts an optional value, mapping nil to an unset field
+func optionalValueToProto(v any) (*structpb.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return valueToProto(v)
+}
+
+// valueToProto converts an arbitrary JSON-compatible value via its JSON encoding
+func valueToProto(v any) (*structpb.Value, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode value: %w", err)
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, fmt.Errorf("failed to encode value: %w", err)
+	}
+	value, err := structpb.NewValue(generic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode value: %w", err)
+	}
+	return value, nil
+}
+
+// valueFromProto converts a protobuf value to its generic Go representation
+func valueFromProto(v *structpb.Value) any {
+	if v == nil {
+		return nil
+	}
+	return v.AsInterface()
+}
@@ -0,0 +1,86 @@
+package pb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+func TestEventRoundTrip(t *testing.T) {
+	tests := []events.Event{
+		events.NewRunStartedEvent("thread-1", "run-1"),
+		events.NewTextMessageStartEvent("msg-1", events.WithRole("assistant")),
+		events.NewTextMessageContentEvent("msg-1", "Hello"),
+		events.NewTextMessageEndEvent("msg-1"),
+		events.NewToolCallStartEvent("tool-1", "search", events.WithParentMessageID("msg-1")),
+		events.NewToolCallArgsEvent("tool-1", `{"q":"go"}`),
+		events.NewToolCallEndEvent("tool-1"),
+		events.NewStateSnapshotEvent(map[string]any{"count": float64(1), "tags": []any{"a"}}),
+		events.NewStateDeltaEvent([]events.JSONPatchOperation{
+			{Op: "replace", Path: "/count", Value: float64(2)},
+			{Op: "move", Path: "/b", From: "/a"},
+		}),
+		events.NewCustomEvent("progress", events.WithValue(map[string]any{"pct": float64(50)})),
+		events.NewRawEvent(map[string]any{"upstream": true}),
+		events.NewStepStartedEvent("plan"),
+		events.NewStepFinishedEvent("plan"),
+		events.NewRunErrorEvent("boom", events.WithErrorCode("AGENT_ERROR")),
+		events.NewRunFinishedEventWithOptions("thread-1", "run-1", events.WithInterruptOutcome([]types.Interrupt{
+			{ID: "int-1", Reason: "tool_call", ToolCallID: "tool-1", Metadata: map[string]any{"k": "v"}},
+		})),
+	}
+
+	for _, event := range tests {
+		t.Run(string(event.Type()), func(t *testing.T) {
+			pe, err := EventToProto(event)
+			require.NoError(t, err)
+
+			// Round trip through the wire format as well
+			data, err := proto.Marshal(pe)
+			require.NoError(t, err)
+			var decoded Event
+			require.NoError(t, proto.Unmarshal(data, &decoded))
+
+			back, err := EventFromProto(&decoded)
+			require.NoError(t, err)
+
+			want, err := event.ToJSON()
+			require.NoError(t, err)
+			got, err := back.ToJSON()
+			require.NoError(t, err)
+			assert.JSONEq(t, string(want), string(got))
+		})
+	}
+}
+
+func TestRunAgentResponseFallsBackToJSON(t *testing.T) {
+	event := events.NewToolCallResultEvent("msg-2", "tool-1", "done")
+
+	_, err := EventToProto(event)
+	assert.ErrorIs(t, err, ErrUnsupportedEvent)
+	_, err = EventToProto(events.NewRunErrorEvent("boom", events.WithRunID("run-1")))
+	assert.ErrorIs(t, err, ErrUnsupportedEvent, "fields missing from events.proto must be unset")
+
+	resp, err := NewRunAgentResponse(event)
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.GetJsonEvent())
+
+	back, err := ResponseEvent(resp)
+	require.NoError(t, err)
+	result, ok := back.(*events.ToolCallResultEvent)
+	require.True(t, ok)
+	assert.Equal(t, "done", result.Content)
+
+	approval := events.NewApprovalRequestedEvent("approval-1").WithToolCall("tool-1", "delete_file", "{}")
+	resp, err = NewRunAgentResponse(approval)
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.GetJsonEvent(), "approvals are missing from events.proto")
+	back, err = ResponseEvent(resp)
+	require.NoError(t, err)
+	assert.Equal(t, approval, back)
+}
@@ -0,0 +1,6 @@
+// Package pb contains the Go code generated from the AG-UI protobuf
+// definitions, the gRPC AgentService stubs, and conversions between the
+// generated messages and the Go SDK event and message types. Run inputs
+// travel in RunAgent requests as their JSON encoding, validated on both
+// ends, see NewRunAgentRequest and RequestInput.
+package pb
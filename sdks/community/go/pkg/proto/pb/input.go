@@ -0,0 +1,185 @@
+package pb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+// ErrUnsupportedMessage is returned for messages carrying fields missing
+// from types.proto
+var ErrUnsupportedMessage = errors.New("message has no protobuf representation")
+
+// NewRunAgentRequest wraps a run input for the RunAgent stream, after
+// validating it. The input is carried as its JSON encoding, the body of the
+// HTTP requests of the run.
+func NewRunAgentRequest(input *types.RunAgentInput) (*RunAgentRequest, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode run input: %w", err)
+	}
+	return &RunAgentRequest{Request: &RunAgentRequest_Input{Input: data}}, nil
+}
+
+// RequestInput extracts and validates the run input carried by a RunAgent
+// request
+func RequestInput(req *RunAgentRequest) (*types.RunAgentInput, error) {
+	data := req.GetInput()
+	if data == nil {
+		return nil, fmt.Errorf("request carries no run input")
+	}
+	var input types.RunAgentInput
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, fmt.Errorf("failed to decode run input: %w", err)
+	}
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	return &input, nil
+}
+
+// MessageToProto converts a message to its protobuf representation.
+// ErrUnsupportedMessage is returned for activity messages, encrypted
+// contents and binary input contents, missing from types.proto.
+func MessageToProto(m types.Message) (*Message, error) {
+	switch {
+	case m.Role == types.RoleActivity:
+		return nil, fmt.Errorf("%w: %s message %s", ErrUnsupportedMessage, m.Role, m.ID)
+	case m.EncryptedContent != "" || m.EncryptedValue != "":
+		return nil, fmt.Errorf("%w: message %s is encrypted", ErrUnsupportedMessage, m.ID)
+	}
+	pm := &Message{Id: m.ID, Role: string(m.Role)}
+	if text, ok := m.ContentString(); ok {
+		pm.Content = &text
+	} else if parts, ok := m.ContentInputContents(); ok {
+		for _, part := range parts {
+			pp, err := inputContentToProto(part)
+			if err != nil {
+				return nil, fmt.Errorf("message %s: %w", m.ID, err)
+			}
+			pm.ContentParts = append(pm.ContentParts, pp)
+		}
+	} else if m.Content != nil {
+		return nil, fmt.Errorf("%w: content of message %s", ErrUnsupportedMessage, m.ID)
+	}
+	if m.Name != "" {
+		pm.Name = &m.Name
+	}
+	if m.ToolCallID != "" {
+		pm.ToolCallId = &m.ToolCallID
+	}
+	if m.Error != "" {
+		pm.Error = &m.Error
+	}
+	for _, call := range m.ToolCalls {
+		pm.ToolCalls = append(pm.ToolCalls, &ToolCall{
+			Id:       call.ID,
+			Type:     call.Type,
+			Function: &ToolCall_Function{Name: call.Function.Name, Arguments: call.Function.Arguments},
+		})
+	}
+	return pm, nil
+}
+
+// MessageFromProto converts a protobuf message to its Go SDK representation
+func MessageFromProto(pm *Message) types.Message {
+	m := types.Message{
+		ID:         pm.GetId(),
+		Role:       types.Role(pm.GetRole()),
+		Name:       pm.GetName(),
+		ToolCallID: pm.GetToolCallId(),
+		Error:      pm.GetError(),
+	}
+	if pm.Content != nil {
+		m.Content = pm.GetContent()
+	}
+	if len(pm.GetContentParts()) > 0 {
+		parts := make([]types.InputContent, 0, len(pm.GetContentParts()))
+		for _, pp := range pm.GetContentParts() {
+			parts = append(parts, inputContentFromProto(pp))
+		}
+		m.Content = parts
+	}
+	for _, call := range pm.GetToolCalls() {
+		m.ToolCalls = append(m.ToolCalls, types.ToolCall{
+			ID:   call.GetId(),
+			Type: call.GetType(),
+			Function: types.FunctionCall{
+				Name:      call.GetFunction().GetName(),
+				Arguments: call.GetFunction().GetArguments(),
+			},
+		})
+	}
+	return m
+}
+
+// inputContentToProto converts a fragment of multimodal content
+func inputContentToProto(c types.InputContent) (*InputContent, error) {
+	if c.Type == types.InputContentTypeText {
+		return &InputContent{Part: &InputContent_Text{Text: &TextInputPart{Text: c.Text}}}, nil
+	}
+	if c.Source == nil {
+		return nil, fmt.Errorf("%w: %s input content without a source", ErrUnsupportedMessage, c.Type)
+	}
+	source := &InputContentSource{}
+	switch c.Source.Type {
+	case types.InputContentSourceTypeData:
+		source.Source = &InputContentSource_Data{Data: &InputContentDataSource{Value: c.Source.Value, MimeType: c.Source.MimeType}}
+	case types.InputContentSourceTypeURL:
+		url := &InputContentUrlSource{Value: c.Source.Value}
+		if c.Source.MimeType != "" {
+			url.MimeType = &c.Source.MimeType
+		}
+		source.Source = &InputContentSource_Url{Url: url}
+	default:
+		return nil, fmt.Errorf("%w: input content source %q", ErrUnsupportedMessage, c.Source.Type)
+	}
+	metadata, err := optionalValueToProto(c.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	switch c.Type {
+	case types.InputContentTypeImage:
+		return &InputContent{Part: &InputContent_Image{Image: &ImageInputPart{Source: source, Metadata: metadata}}}, nil
+	case types.InputContentTypeAudio:
+		return &InputContent{Part: &InputContent_Audio{Audio: &AudioInputPart{Source: source, Metadata: metadata}}}, nil
+	case types.InputContentTypeVideo:
+		return &InputContent{Part: &InputContent_Video{Video: &VideoInputPart{Source: source, Metadata: metadata}}}, nil
+	case types.InputContentTypeDocument:
+		return &InputContent{Part: &InputContent_Document{Document: &DocumentInputPart{Source: source, Metadata: metadata}}}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s input content", ErrUnsupportedMessage, c.Type)
+	}
+}
+
+// inputContentFromProto converts a protobuf fragment of multimodal content
+func inputContentFromProto(pc *InputContent) types.InputContent {
+	var (
+		c      types.InputContent
+		source *InputContentSource
+	)
+	switch p := pc.GetPart().(type) {
+	case *InputContent_Text:
+		return types.InputContent{Type: types.InputContentTypeText, Text: p.Text.GetText()}
+	case *InputContent_Image:
+		c.Type, source, c.Metadata = types.InputContentTypeImage, p.Image.GetSource(), valueFromProto(p.Image.GetMetadata())
+	case *InputContent_Audio:
+		c.Type, source, c.Metadata = types.InputContentTypeAudio, p.Audio.GetSource(), valueFromProto(p.Audio.GetMetadata())
+	case *InputContent_Video:
+		c.Type, source, c.Metadata = types.InputContentTypeVideo, p.Video.GetSource(), valueFromProto(p.Video.GetMetadata())
+	case *InputContent_Document:
+		c.Type, source, c.Metadata = types.InputContentTypeDocument, p.Document.GetSource(), valueFromProto(p.Document.GetMetadata())
+	}
+	switch s := source.GetSource().(type) {
+	case *InputContentSource_Data:
+		c.Source = &types.InputContentSource{Type: types.InputContentSourceTypeData, Value: s.Data.GetValue(), MimeType: s.Data.GetMimeType()}
+	case *InputContentSource_Url:
+		c.Source = &types.InputContentSource{Type: types.InputContentSourceTypeURL, Value: s.Url.GetValue(), MimeType: s.Url.GetMimeType()}
+	}
+	return c
+}
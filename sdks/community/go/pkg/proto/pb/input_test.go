@@ -0,0 +1,78 @@
+package pb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+func TestRunAgentRequest(t *testing.T) {
+	input := &types.RunAgentInput{
+		ThreadID: "thread-1",
+		RunID:    "run-1",
+		Messages: []types.Message{{ID: "msg-1", Role: types.RoleUser, Content: "hello"}},
+		Tools:    []types.Tool{{Name: "search", Parameters: map[string]any{"type": "object"}}},
+	}
+	req, err := NewRunAgentRequest(input)
+	require.NoError(t, err)
+	got, err := RequestInput(req)
+	require.NoError(t, err)
+	want, err := json.Marshal(input)
+	require.NoError(t, err)
+	data, err := json.Marshal(got)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(want), string(data))
+
+	// Invalid inputs are neither sent nor accepted
+	input.Messages[0].Role = "robot"
+	_, err = NewRunAgentRequest(input)
+	assert.ErrorIs(t, err, types.ErrInvalidInput)
+	data, err = json.Marshal(input)
+	require.NoError(t, err)
+	_, err = RequestInput(&RunAgentRequest{Request: &RunAgentRequest_Input{Input: data}})
+	assert.ErrorIs(t, err, types.ErrInvalidInput)
+	_, err = RequestInput(&RunAgentRequest{Request: &RunAgentRequest_Cancel{Cancel: &CancelRun{}}})
+	assert.ErrorContains(t, err, "request carries no run input")
+}
+
+func TestMessageRoundTrip(t *testing.T) {
+	tests := []types.Message{
+		{ID: "msg-1", Role: types.RoleSystem, Content: "Be brief"},
+		{ID: "msg-2", Role: types.RoleUser, Name: "ada", Content: []types.InputContent{
+			{Type: types.InputContentTypeText, Text: "What is this?"},
+			{Type: types.InputContentTypeImage, Source: &types.InputContentSource{Type: types.InputContentSourceTypeURL, Value: "https://example.com/cat.png"}},
+			{Type: types.InputContentTypeDocument, Source: &types.InputContentSource{Type: types.InputContentSourceTypeData, Value: "aGk=", MimeType: "text/plain"}, Metadata: map[string]any{"pages": float64(1)}},
+		}},
+		{ID: "msg-3", Role: types.RoleAssistant, ToolCalls: []types.ToolCall{
+			{ID: "tc-1", Type: types.ToolCallTypeFunction, Function: types.FunctionCall{Name: "search", Arguments: `{"q":"cats"}`}},
+		}},
+		{ID: "msg-4", Role: types.RoleTool, ToolCallID: "tc-1", Content: "", Error: "timeout"},
+	}
+	for _, m := range tests {
+		t.Run(m.ID, func(t *testing.T) {
+			pm, err := MessageToProto(m)
+			require.NoError(t, err)
+			data, err := proto.Marshal(pm)
+			require.NoError(t, err)
+			var decoded Message
+			require.NoError(t, proto.Unmarshal(data, &decoded))
+			assert.Equal(t, m, MessageFromProto(&decoded))
+		})
+	}
+
+	for _, m := range []types.Message{
+		{ID: "act-1", Role: types.RoleActivity, ActivityType: "progress", Content: map[string]any{"pct": 50}},
+		{ID: "rsn-1", Role: types.RoleReasoning, EncryptedValue: "enc"},
+		{ID: "msg-5", Role: types.RoleUser, Content: []types.InputContent{
+			{Type: types.InputContentTypeBinary, MimeType: "image/png", URL: "https://example.com/cat.png"},
+		}},
+	} {
+		_, err := MessageToProto(m)
+		assert.ErrorIs(t, err, ErrUnsupportedMessage, m.ID)
+	}
+}
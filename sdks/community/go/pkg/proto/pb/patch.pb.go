@@ -0,0 +1,239 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        (unknown)
+// source: patch.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type JsonPatchOperationType int32
+
+const (
+	JsonPatchOperationType_ADD     JsonPatchOperationType = 0
+	JsonPatchOperationType_REMOVE  JsonPatchOperationType = 1
+	JsonPatchOperationType_REPLACE JsonPatchOperationType = 2
+	JsonPatchOperationType_MOVE    JsonPatchOperationType = 3
+	JsonPatchOperationType_COPY    JsonPatchOperationType = 4
+	JsonPatchOperationType_TEST    JsonPatchOperationType = 5
+)
+
+// Enum value maps for JsonPatchOperationType.
+var (
+	JsonPatchOperationType_name = map[int32]string{
+		0: "ADD",
+		1: "REMOVE",
+		2: "REPLACE",
+		3: "MOVE",
+		4: "COPY",
+		5: "TEST",
+	}
+	JsonPatchOperationType_value = map[string]int32{
+		"ADD":     0,
+		"REMOVE":  1,
+		"REPLACE": 2,
+		"MOVE":    3,
+		"COPY":    4,
+		"TEST":    5,
+	}
+)
+
+func (x JsonPatchOperationType) Enum() *JsonPatchOperationType {
+	p := new(JsonPatchOperationType)
+	*p = x
+	return p
+}
+
+func (x JsonPatchOperationType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JsonPatchOperationType) Descriptor() protoreflect.EnumDescriptor {
+	return file_patch_proto_enumTypes[0].Descriptor()
+}
+
+func (JsonPatchOperationType) Type() protoreflect.EnumType {
+	return &file_patch_proto_enumTypes[0]
+}
+
+func (x JsonPatchOperationType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JsonPatchOperationType.Descriptor instead.
+func (JsonPatchOperationType) EnumDescriptor() ([]byte, []int) {
+	return file_patch_proto_rawDescGZIP(), []int{0}
+}
+
+type JsonPatchOperation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Op            JsonPatchOperationType `protobuf:"varint,1,opt,name=op,proto3,enum=ag_ui.JsonPatchOperationType" json:"op,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	From          *string                `protobuf:"bytes,3,opt,name=from,proto3,oneof" json:"from,omitempty"`
+	Value         *structpb.Value        `protobuf:"bytes,4,opt,name=value,proto3,oneof" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JsonPatchOperation) Reset() {
+	*x = JsonPatchOperation{}
+	mi := &file_patch_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JsonPatchOperation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JsonPatchOperation) ProtoMessage() {}
+
+func (x *JsonPatchOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_patch_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JsonPatchOperation.ProtoReflect.Descriptor instead.
+func (*JsonPatchOperation) Descriptor() ([]byte, []int) {
+	return file_patch_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *JsonPatchOperation) GetOp() JsonPatchOperationType {
+	if x != nil {
+		return x.Op
+	}
+	return JsonPatchOperationType_ADD
+}
+
+func (x *JsonPatchOperation) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *JsonPatchOperation) GetFrom() string {
+	if x != nil && x.From != nil {
+		return *x.From
+	}
+	return ""
+}
+
+func (x *JsonPatchOperation) GetValue() *structpb.Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+var File_patch_proto protoreflect.FileDescriptor
+
+var file_patch_proto_rawDesc = string([]byte{
+	0x0a, 0x0b, 0x70, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05, 0x61,
+	0x67, 0x5f, 0x75, 0x69, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x22, 0xb6, 0x01, 0x0a, 0x12, 0x4a, 0x73, 0x6f, 0x6e, 0x50, 0x61, 0x74, 0x63, 0x68,
+	0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2d, 0x0a, 0x02, 0x6f, 0x70, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1d, 0x2e, 0x61, 0x67, 0x5f, 0x75, 0x69, 0x2e, 0x4a, 0x73,
+	0x6f, 0x6e, 0x50, 0x61, 0x74, 0x63, 0x68, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x54, 0x79, 0x70, 0x65, 0x52, 0x02, 0x6f, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x17, 0x0a, 0x04,
+	0x66, 0x72, 0x6f, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x66, 0x72,
+	0x6f, 0x6d, 0x88, 0x01, 0x01, 0x12, 0x31, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x48, 0x01, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x88, 0x01, 0x01, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x66, 0x72, 0x6f,
+	0x6d, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x2a, 0x58, 0x0a, 0x16, 0x4a,
+	0x73, 0x6f, 0x6e, 0x50, 0x61, 0x74, 0x63, 0x68, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x54, 0x79, 0x70, 0x65, 0x12, 0x07, 0x0a, 0x03, 0x41, 0x44, 0x44, 0x10, 0x00, 0x12, 0x0a,
+	0x0a, 0x06, 0x52, 0x45, 0x4d, 0x4f, 0x56, 0x45, 0x10, 0x01, 0x12, 0x0b, 0x0a, 0x07, 0x52, 0x45,
+	0x50, 0x4c, 0x41, 0x43, 0x45, 0x10, 0x02, 0x12, 0x08, 0x0a, 0x04, 0x4d, 0x4f, 0x56, 0x45, 0x10,
+	0x03, 0x12, 0x08, 0x0a, 0x04, 0x43, 0x4f, 0x50, 0x59, 0x10, 0x04, 0x12, 0x08, 0x0a, 0x04, 0x54,
+	0x45, 0x53, 0x54, 0x10, 0x05, 0x42, 0x87, 0x01, 0x0a, 0x09, 0x63, 0x6f, 0x6d, 0x2e, 0x61, 0x67,
+	0x5f, 0x75, 0x69, 0x42, 0x0a, 0x50, 0x61, 0x74, 0x63, 0x68, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50,
+	0x01, 0x5a, 0x3e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x67,
+	0x2d, 0x75, 0x69, 0x2d, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x2f, 0x61, 0x67, 0x2d,
+	0x75, 0x69, 0x2f, 0x73, 0x64, 0x6b, 0x73, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x75, 0x6e, 0x69, 0x74,
+	0x79, 0x2f, 0x67, 0x6f, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x70,
+	0x62, 0xa2, 0x02, 0x03, 0x41, 0x58, 0x58, 0xaa, 0x02, 0x04, 0x41, 0x67, 0x55, 0x69, 0xca, 0x02,
+	0x04, 0x41, 0x67, 0x55, 0x69, 0xe2, 0x02, 0x10, 0x41, 0x67, 0x55, 0x69, 0x5c, 0x47, 0x50, 0x42,
+	0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0xea, 0x02, 0x04, 0x41, 0x67, 0x55, 0x69, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_patch_proto_rawDescOnce sync.Once
+	file_patch_proto_rawDescData []byte
+)
+
+func file_patch_proto_rawDescGZIP() []byte {
+	file_patch_proto_rawDescOnce.Do(func() {
+		file_patch_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_patch_proto_rawDesc), len(file_patch_proto_rawDesc)))
+	})
+	return file_patch_proto_rawDescData
+}
+
+var file_patch_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_patch_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_patch_proto_goTypes = []any{
+	(JsonPatchOperationType)(0), // 0: ag_ui.JsonPatchOperationType
+	(*JsonPatchOperation)(nil),  // 1: ag_ui.JsonPatchOperation
+	(*structpb.Value)(nil),      // 2: google.protobuf.Value
+}
+var file_patch_proto_depIdxs = []int32{
+	0, // 0: ag_ui.JsonPatchOperation.op:type_name -> ag_ui.JsonPatchOperationType
+	2, // 1: ag_ui.JsonPatchOperation.value:type_name -> google.protobuf.Value
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_patch_proto_init() }
+func file_patch_proto_init() {
+	if File_patch_proto != nil {
+		return
+	}
+	file_patch_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_patch_proto_rawDesc), len(file_patch_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_patch_proto_goTypes,
+		DependencyIndexes: file_patch_proto_depIdxs,
+		EnumInfos:         file_patch_proto_enumTypes,
+		MessageInfos:      file_patch_proto_msgTypes,
+	}.Build()
+	File_patch_proto = out.File
+	file_patch_proto_goTypes = nil
+	file_patch_proto_depIdxs = nil
+}
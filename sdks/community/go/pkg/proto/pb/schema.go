@@ -0,0 +1,136 @@
+package pb
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// SchemaHeader is the gRPC metadata key, or HTTP header, carrying the
+// SchemaHash of a peer
+const SchemaHeader = "ag-ui-schema"
+
+// ErrSchemaDrift is wrapped by the errors reporting that two parties were
+// built from divergent .proto files
+var ErrSchemaDrift = errors.New("protobuf schema drift")
+
+// SchemaDriftError reports that the schema the code was generated from
+// differs from the one a peer, a registry or the configuration declares
+type SchemaDriftError struct {
+	// Expected is the schema hash declared, Actual the SchemaHash of the
+	// process
+	Expected string
+	Actual   string
+
+	// Source names who declared Expected, e.g. "upstream server"
+	Source string
+
+	// Cause is the error the drift may explain, e.g. a decoding error
+	Cause error
+}
+
+func (e *SchemaDriftError) Error() string {
+	msg := fmt.Sprintf("%s: this build has schema %s but the %s has schema %s; "+
+		"regenerate both from the same .proto files", ErrSchemaDrift, short(e.Actual), e.Source, short(e.Expected))
+	if e.Cause != nil {
+		msg += ": " + e.Cause.Error()
+	}
+	return msg
+}
+
+// Unwrap returns ErrSchemaDrift and the cause, if any
+func (e *SchemaDriftError) Unwrap() []error {
+	if e.Cause == nil {
+		return []error{ErrSchemaDrift}
+	}
+	return []error{ErrSchemaDrift, e.Cause}
+}
+
+// short abbreviates a schema hash for diagnostics
+func short(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+// schemaFiles are the descriptors of the generated .proto files
+func schemaFiles() []protoreflect.FileDescriptor {
+	return []protoreflect.FileDescriptor{File_agent_service_proto, File_events_proto, File_patch_proto, File_types_proto}
+}
+
+// SchemaHash returns the SHA-256 of the descriptors embedded in the
+// generated code, in hex. Builds generated from the same .proto files share
+// it.
+func SchemaHash() string {
+	return schemaHash()
+}
+
+var schemaHash = sync.OnceValue(func() string {
+	files := schemaFiles()
+	sort.Slice(files, func(i, j int) bool { return files[i].Path() < files[j].Path() })
+	hash := sha256.New()
+	for _, file := range files {
+		data, err := proto.MarshalOptions{Deterministic: true}.Marshal(protodesc.ToFileDescriptorProto(file))
+		if err != nil {
+			panic(fmt.Sprintf("pb: failed to marshal the descriptor of %s: %v", file.Path(), err))
+		}
+		hash.Write([]byte(file.Path()))
+		hash.Write(data)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+})
+
+// CheckSchema returns a *SchemaDriftError when expected, declared by
+// source, is not the SchemaHash of the process. An empty expected hash is
+// not checked.
+func CheckSchema(expected, source string) error {
+	if expected == "" || expected == SchemaHash() {
+		return nil
+	}
+	return &SchemaDriftError{Expected: expected, Actual: SchemaHash(), Source: source}
+}
+
+// SchemaRegistry serves the schema hash expected of the services of a
+// deployment, e.g. from a configuration service
+type SchemaRegistry interface {
+	ExpectedSchema(ctx context.Context) (string, error)
+}
+
+// SchemaRegistryFunc adapts an ordinary function to the SchemaRegistry
+// interface
+type SchemaRegistryFunc func(ctx context.Context) (string, error)
+
+// ExpectedSchema calls f(ctx)
+func (f SchemaRegistryFunc) ExpectedSchema(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// CheckSchemaRegistry checks the SchemaHash of the process against the
+// schema registry expects, typically at startup
+func CheckSchemaRegistry(ctx context.Context, registry SchemaRegistry) error {
+	expected, err := registry.ExpectedSchema(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the expected schema: %w", err)
+	}
+	return CheckSchema(expected, "schema registry")
+}
+
+// DiagnoseDecodeError explains err, a failure to decode a message of a
+// peer, by a schema drift when the peer announced a schema (see
+// SchemaHeader) other than the SchemaHash of the process. Other errors are
+// returned unchanged.
+func DiagnoseDecodeError(err error, peerSchema, peer string) error {
+	if err == nil || peerSchema == "" || peerSchema == SchemaHash() {
+		return err
+	}
+	return &SchemaDriftError{Expected: peerSchema, Actual: SchemaHash(), Source: peer, Cause: err}
+}
@@ -0,0 +1,54 @@
+package pb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaHash(t *testing.T) {
+	hash := SchemaHash()
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, SchemaHash())
+
+	assert.NoError(t, CheckSchema("", "config"))
+	assert.NoError(t, CheckSchema(hash, "config"))
+	err := CheckSchema("0123456789abcdef", "config")
+	require.ErrorIs(t, err, ErrSchemaDrift)
+	assert.Contains(t, err.Error(), "config has schema 0123456789ab")
+	assert.Contains(t, err.Error(), hash[:12])
+}
+
+func TestCheckSchemaRegistry(t *testing.T) {
+	ctx := context.Background()
+	assert.NoError(t, CheckSchemaRegistry(ctx, SchemaRegistryFunc(func(context.Context) (string, error) {
+		return SchemaHash(), nil
+	})))
+	assert.ErrorIs(t, CheckSchemaRegistry(ctx, SchemaRegistryFunc(func(context.Context) (string, error) {
+		return "other", nil
+	})), ErrSchemaDrift)
+
+	unavailable := errors.New("registry unavailable")
+	err := CheckSchemaRegistry(ctx, SchemaRegistryFunc(func(context.Context) (string, error) {
+		return "", unavailable
+	}))
+	assert.ErrorIs(t, err, unavailable)
+	assert.NotErrorIs(t, err, ErrSchemaDrift)
+}
+
+func TestDiagnoseDecodeError(t *testing.T) {
+	decodeErr := errors.New("cannot parse invalid wire-format data")
+	assert.Nil(t, DiagnoseDecodeError(nil, "other", "server"))
+	assert.Equal(t, decodeErr, DiagnoseDecodeError(decodeErr, "", "server"), "peers announcing no schema are not diagnosed")
+	assert.Equal(t, decodeErr, DiagnoseDecodeError(decodeErr, SchemaHash(), "server"))
+
+	err := DiagnoseDecodeError(decodeErr, "other", "server")
+	assert.ErrorIs(t, err, ErrSchemaDrift)
+	assert.ErrorIs(t, err, decodeErr)
+	var drift *SchemaDriftError
+	require.ErrorAs(t, err, &drift)
+	assert.Equal(t, "other", drift.Expected)
+}
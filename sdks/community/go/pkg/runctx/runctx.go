@@ -0,0 +1,97 @@
+// Package runctx carries the identity of a run, its thread, run and tenant
+// IDs, in a context.Context. The server stores it in the context of every
+// run before the agent is invoked, so that the agent, the emitters, the
+// encoders and the loggers handed that context can tag what they produce
+// with the run without the IDs being threaded through by hand; see
+// logging.NewContextHandler for loggers.
+//
+// Errors leaving the context they were produced in, e.g. the error a run
+// ends with, can carry the metadata along, see WrapError.
+package runctx
+
+import (
+	"context"
+	"errors"
+)
+
+// Metadata identifies a run. Empty fields are unknown.
+type Metadata struct {
+	ThreadID string
+	RunID    string
+	Tenant   string
+}
+
+// IsZero reports whether m carries no IDs
+func (m Metadata) IsZero() bool {
+	return m == Metadata{}
+}
+
+// Merge returns m with its empty fields filled in from other
+func (m Metadata) Merge(other Metadata) Metadata {
+	if m.ThreadID == "" {
+		m.ThreadID = other.ThreadID
+	}
+	if m.RunID == "" {
+		m.RunID = other.RunID
+	}
+	if m.Tenant == "" {
+		m.Tenant = other.Tenant
+	}
+	return m
+}
+
+type metadataKey struct{}
+
+// With returns a copy of ctx carrying metadata. The non-empty fields of
+// metadata replace those already in ctx; the others are kept, so that a
+// tenant resolved by middleware survives the run IDs being added later.
+func With(ctx context.Context, metadata Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, metadata.Merge(From(ctx)))
+}
+
+// From returns the metadata stored in ctx, or the zero Metadata
+func From(ctx context.Context) Metadata {
+	metadata, _ := ctx.Value(metadataKey{}).(Metadata)
+	return metadata
+}
+
+// Error is an error tagged with the run it occurred in
+type Error struct {
+	Metadata Metadata
+	Err      error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WrapError tags err with the metadata in ctx, so that error handlers
+// outside ctx can still attribute it, see FromError. It returns err as is
+// when err is nil, ctx carries no metadata or err is already tagged.
+// errors.Is and errors.As see through the tag.
+func WrapError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	metadata := From(ctx)
+	if metadata.IsZero() {
+		return err
+	}
+	if _, ok := FromError(err); ok {
+		return err
+	}
+	return &Error{Metadata: metadata, Err: err}
+}
+
+// FromError returns the metadata err was tagged with by WrapError, if any
+func FromError(err error) (Metadata, bool) {
+	var tagged *Error
+	if !errors.As(err, &tagged) {
+		return Metadata{}, false
+	}
+	return tagged.Metadata, true
+}
@@ -0,0 +1,43 @@
+package runctx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWith(t *testing.T) {
+	ctx := context.Background()
+	assert.True(t, From(ctx).IsZero())
+
+	ctx = With(ctx, Metadata{Tenant: "acme"})
+	ctx = With(ctx, Metadata{ThreadID: "thread-1", RunID: "run-1"})
+	assert.Equal(t, Metadata{ThreadID: "thread-1", RunID: "run-1", Tenant: "acme"}, From(ctx))
+
+	nested := With(ctx, Metadata{RunID: "run-2"})
+	assert.Equal(t, Metadata{ThreadID: "thread-1", RunID: "run-2", Tenant: "acme"}, From(nested))
+	assert.Equal(t, "run-1", From(ctx).RunID, "the parent context is unchanged")
+}
+
+func TestWrapError(t *testing.T) {
+	cause := errors.New("boom")
+	assert.Nil(t, WrapError(With(context.Background(), Metadata{RunID: "run-1"}), nil))
+	assert.Same(t, cause, WrapError(context.Background(), cause), "errors without metadata are left as is")
+
+	err := WrapError(With(context.Background(), Metadata{ThreadID: "thread-1", RunID: "run-1"}), cause)
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, "boom", err.Error())
+
+	wrapped := fmt.Errorf("run failed: %w", err)
+	metadata, ok := FromError(wrapped)
+	assert.True(t, ok)
+	assert.Equal(t, Metadata{ThreadID: "thread-1", RunID: "run-1"}, metadata)
+
+	assert.Same(t, wrapped, WrapError(With(context.Background(), Metadata{RunID: "run-2"}), wrapped), "the innermost tag wins")
+
+	_, ok = FromError(cause)
+	assert.False(t, ok)
+}
@@ -0,0 +1,222 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+)
+
+const (
+	// defaultTargetLatency is the default write latency above which the
+	// rate of a run decreases
+	defaultTargetLatency = 50 * time.Millisecond
+
+	// defaultAdjustInterval is the default interval between adjustments of
+	// the rate of a run
+	defaultAdjustInterval = 100 * time.Millisecond
+
+	// latencyWeight is the weight of a new write latency in its moving
+	// average
+	latencyWeight = 0.2
+)
+
+// QueueDepthReporter is implemented by emitters buffering the events of a
+// run, reporting the number of events buffered and not yet written, which
+// StreamMetrics records as the queue depth of the run
+type QueueDepthReporter interface {
+	QueueDepth() int
+}
+
+// StreamMetrics measures how fast the consumer of a run takes its events:
+// the latency of the writes of its emitter and, for emitters implementing
+// QueueDepthReporter, the number of events they buffer. It is safe for
+// concurrent use.
+type StreamMetrics struct {
+	mu      sync.Mutex
+	writes  uint64
+	latency time.Duration
+	depth   int
+}
+
+// Observe records a write of latency latency, after which queueDepth
+// events were left to write
+func (m *StreamMetrics) Observe(latency time.Duration, queueDepth int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.writes == 0 {
+		m.latency = latency
+	} else {
+		m.latency += time.Duration(latencyWeight * float64(latency-m.latency))
+	}
+	m.writes++
+	m.depth = queueDepth
+}
+
+// Writes counts the writes observed
+func (m *StreamMetrics) Writes() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.writes
+}
+
+// Latency returns the moving average of the write latency
+func (m *StreamMetrics) Latency() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.latency
+}
+
+// QueueDepth returns the queue depth observed last
+func (m *StreamMetrics) QueueDepth() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.depth
+}
+
+// AdaptiveFlowConfig configures the adaptive rate control of runs, see
+// AdaptiveFlowController
+type AdaptiveFlowConfig struct {
+	// MaxRate bounds the rate of content events per second of a run (0 =
+	// adaptive rate control disabled)
+	MaxRate float64
+
+	// MinRate bounds the rate from below (defaults to 1)
+	MinRate float64
+
+	// InitialRate is the rate runs start at (defaults to MaxRate)
+	InitialRate float64
+
+	// TargetLatency is the write latency above which the rate decreases
+	// (defaults to 50ms)
+	TargetLatency time.Duration
+
+	// MaxQueueDepth is the queue depth above which the rate decreases (0 =
+	// queue depth ignored)
+	MaxQueueDepth int
+
+	// Increase is added to the rate after each interval the consumer keeps
+	// up (defaults to a tenth of MaxRate)
+	Increase float64
+
+	// Decrease multiplies the rate after each interval the consumer falls
+	// behind (defaults to 0.5)
+	Decrease float64
+
+	// Interval is the least time between adjustments of the rate (defaults
+	// to 100ms)
+	Interval time.Duration
+
+	// Clock paces the events and times the writes (defaults to the real
+	// clock)
+	Clock clock.Clock
+}
+
+// AdaptiveFlowController paces the streamed content of a run at a rate it
+// adapts to the consumer by additive increase, multiplicative decrease:
+// each interval the rate decreases while the write latency or the queue
+// depth measured by its StreamMetrics exceeds its target, and increases
+// otherwise. Unlike FlowController it needs no cooperation from the client.
+// It is safe for concurrent use.
+type AdaptiveFlowController struct {
+	config  AdaptiveFlowConfig
+	metrics StreamMetrics
+
+	mu       sync.Mutex
+	rate     float64
+	next     time.Time
+	adjusted time.Time
+}
+
+// NewAdaptiveFlowController creates an adaptive flow controller, nil when
+// config.MaxRate is not positive
+func NewAdaptiveFlowController(config AdaptiveFlowConfig) *AdaptiveFlowController {
+	if config.MaxRate <= 0 {
+		return nil
+	}
+	if config.MinRate <= 0 {
+		config.MinRate = 1
+	}
+	config.MinRate = min(config.MinRate, config.MaxRate)
+	if config.InitialRate <= 0 {
+		config.InitialRate = config.MaxRate
+	}
+	if config.TargetLatency <= 0 {
+		config.TargetLatency = defaultTargetLatency
+	}
+	if config.Increase <= 0 {
+		config.Increase = config.MaxRate / 10
+	}
+	if config.Decrease <= 0 || config.Decrease >= 1 {
+		config.Decrease = 0.5
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaultAdjustInterval
+	}
+	config.Clock = clock.Or(config.Clock)
+	now := config.Clock.Now()
+	return &AdaptiveFlowController{
+		config:   config,
+		rate:     min(max(config.InitialRate, config.MinRate), config.MaxRate),
+		next:     now,
+		adjusted: now,
+	}
+}
+
+// Rate returns the current rate of content events per second
+func (a *AdaptiveFlowController) Rate() float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.rate
+}
+
+// Metrics returns the metrics the rate adapts to
+func (a *AdaptiveFlowController) Metrics() *StreamMetrics {
+	return &a.metrics
+}
+
+// acquire waits for the next event of the rate, until ctx is done
+func (a *AdaptiveFlowController) acquire(ctx context.Context) error {
+	a.mu.Lock()
+	now := a.config.Clock.Now()
+	if a.next.Before(now) {
+		a.next = now
+	}
+	wait := a.next.Sub(now)
+	a.next = a.next.Add(time.Duration(float64(time.Second) / a.rate))
+	a.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := a.config.Clock.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// observe records a write of the run and adjusts the rate once an interval
+// has elapsed since the last adjustment
+func (a *AdaptiveFlowController) observe(latency time.Duration, queueDepth int) {
+	a.metrics.Observe(latency, queueDepth)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.config.Clock.Now()
+	if now.Sub(a.adjusted) < a.config.Interval {
+		return
+	}
+	a.adjusted = now
+	behind := a.metrics.Latency() > a.config.TargetLatency ||
+		(a.config.MaxQueueDepth > 0 && a.metrics.QueueDepth() > a.config.MaxQueueDepth)
+	if behind {
+		a.rate = max(a.rate*a.config.Decrease, a.config.MinRate)
+	} else {
+		a.rate = min(a.rate+a.config.Increase, a.config.MaxRate)
+	}
+}
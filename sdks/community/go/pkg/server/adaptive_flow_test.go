@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/testhelper"
+)
+
+func TestAdaptiveFlowAIMD(t *testing.T) {
+	clk := testhelper.NewFakeClock(time.Unix(0, 0))
+	flow := NewAdaptiveFlowController(AdaptiveFlowConfig{
+		MaxRate:  100,
+		MinRate:  10,
+		Increase: 10,
+		Interval: 100 * time.Millisecond,
+		Clock:    clk,
+	})
+	assert.Nil(t, NewAdaptiveFlowController(AdaptiveFlowConfig{}))
+	assert.Equal(t, float64(100), flow.Rate())
+
+	// Writes within an interval do not adjust the rate
+	flow.observe(200*time.Millisecond, 0)
+	assert.Equal(t, float64(100), flow.Rate())
+
+	// A slow consumer halves the rate each interval, down to MinRate
+	for _, want := range []float64{50, 25, 12.5, 10} {
+		clk.Advance(100 * time.Millisecond)
+		flow.observe(200*time.Millisecond, 0)
+		assert.Equal(t, want, flow.Rate())
+	}
+	assert.Equal(t, 200*time.Millisecond, flow.Metrics().Latency())
+
+	// Once the average latency recovers the rate grows by Increase
+	var rates []float64
+	for range 12 {
+		clk.Advance(100 * time.Millisecond)
+		flow.observe(time.Millisecond, 0)
+		rates = append(rates, flow.Rate())
+	}
+	assert.Equal(t, []float64{10, 10, 10, 10, 10, 10, 20, 30, 40, 50, 60, 70}, rates)
+	assert.Equal(t, uint64(17), flow.Metrics().Writes())
+}
+
+func TestAdaptiveFlowQueueDepth(t *testing.T) {
+	clk := testhelper.NewFakeClock(time.Unix(0, 0))
+	flow := NewAdaptiveFlowController(AdaptiveFlowConfig{MaxRate: 100, MaxQueueDepth: 4, Clock: clk})
+	clk.Advance(time.Second)
+	flow.observe(0, 10)
+	assert.Equal(t, float64(50), flow.Rate())
+	assert.Equal(t, 10, flow.Metrics().QueueDepth())
+	clk.Advance(time.Second)
+	flow.observe(0, 4)
+	assert.Equal(t, float64(60), flow.Rate())
+}
+
+func TestAdaptiveFlowPacing(t *testing.T) {
+	clk := testhelper.NewFakeClock(time.Unix(0, 0))
+	flow := NewAdaptiveFlowController(AdaptiveFlowConfig{MaxRate: 10, Clock: clk})
+	require.NoError(t, flow.acquire(context.Background()))
+
+	acquired := make(chan error, 1)
+	go func() { acquired <- flow.acquire(context.Background()) }()
+	clk.BlockUntil(1)
+	select {
+	case <-acquired:
+		t.Fatal("acquired ahead of the rate")
+	default:
+	}
+	clk.Advance(100 * time.Millisecond)
+	require.NoError(t, <-acquired)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, flow.acquire(ctx), context.Canceled)
+}
+
+// queueingEmitter takes latency to write each event, buffering depth
+type queueingEmitter struct {
+	clock   *testhelper.FakeClock
+	latency time.Duration
+	depth   int
+}
+
+func (q *queueingEmitter) Emit(context.Context, events.Event) error {
+	q.clock.Advance(q.latency)
+	return nil
+}
+
+func (q *queueingEmitter) QueueDepth() int {
+	return q.depth
+}
+
+func TestEventEmitterAdaptsToConsumer(t *testing.T) {
+	clk := testhelper.NewFakeClock(time.Unix(0, 0))
+	sink := &queueingEmitter{clock: clk, latency: 200 * time.Millisecond, depth: 3}
+	emitter := newEventEmitter(sink)
+	emitter.adaptive = NewAdaptiveFlowController(AdaptiveFlowConfig{MaxRate: 100, MinRate: 5, Clock: clk})
+
+	ctx := context.Background()
+	messageID, err := emitter.StartTextMessage(ctx, "assistant")
+	require.NoError(t, err)
+	for range 10 {
+		require.NoError(t, emitter.EmitContent(ctx, messageID, "token"))
+	}
+	assert.Equal(t, float64(5), emitter.adaptive.Rate())
+	metrics := emitter.adaptive.Metrics()
+	assert.Equal(t, uint64(11), metrics.Writes())
+	assert.Equal(t, 200*time.Millisecond, metrics.Latency())
+	assert.Equal(t, 3, metrics.QueueDepth())
+}
+
+func TestRunManagerAdaptiveFlow(t *testing.T) {
+	clk := testhelper.NewFakeClock(time.Unix(0, 0))
+	var streamed atomic.Int32
+	agent := AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, emitter *EventEmitter) error {
+		messageID, err := emitter.StartTextMessage(ctx, "assistant")
+		if err != nil {
+			return err
+		}
+		for range 2 {
+			if err := emitter.EmitContent(ctx, messageID, "token"); err != nil {
+				return err
+			}
+			streamed.Add(1)
+		}
+		return emitter.EndTextMessage(ctx, messageID)
+	})
+	manager := NewRunManager(agent, RunManagerConfig{Clock: clk, AdaptiveFlow: AdaptiveFlowConfig{MaxRate: 1}})
+
+	done := make(chan error, 1)
+	go func() { done <- manager.Run(context.Background(), newTestInput(), &recordingEmitter{}) }()
+	clk.BlockUntil(1)
+	assert.Equal(t, int32(1), streamed.Load(), "the second token is paced")
+	clk.Advance(time.Second)
+	require.NoError(t, <-done)
+	assert.Equal(t, int32(2), streamed.Load())
+}
@@ -0,0 +1,42 @@
+// Package server provides building blocks for serving AG-UI agents.
+// It takes care of the run lifecycle so agent implementations only need to
+// produce the events that describe their work.
+package server
+
+import (
+	"context"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+// Emitter delivers events produced during a run to the client
+type Emitter interface {
+	// Emit sends a single event to the client
+	Emit(ctx context.Context, event events.Event) error
+}
+
+// Agent handles a single agent run.
+// RUN_STARTED, RUN_FINISHED and RUN_ERROR are emitted by the RunManager;
+// implementations only emit the events in between and return an error
+// if the run failed.
+type Agent interface {
+	// HandleRun executes the run described by input, emitting events through emitter
+	HandleRun(ctx context.Context, input *types.RunAgentInput, emitter *EventEmitter) error
+}
+
+// AgentFunc adapts an ordinary function to the Agent interface
+type AgentFunc func(ctx context.Context, input *types.RunAgentInput, emitter *EventEmitter) error
+
+// HandleRun calls f(ctx, input, emitter)
+func (f AgentFunc) HandleRun(ctx context.Context, input *types.RunAgentInput, emitter *EventEmitter) error {
+	return f(ctx, input, emitter)
+}
+
+// EmitterFunc adapts an ordinary function to the Emitter interface
+type EmitterFunc func(ctx context.Context, event events.Event) error
+
+// Emit calls f(ctx, event)
+func (f EmitterFunc) Emit(ctx context.Context, event events.Event) error {
+	return f(ctx, event)
+}
@@ -0,0 +1,417 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/logging"
+)
+
+var (
+	// ErrApprovalNotFound is returned when an approval does not exist
+	ErrApprovalNotFound = errors.New("approval not found")
+
+	// ErrApprovalResolved is returned when deciding an approval that has already been decided
+	ErrApprovalResolved = errors.New("approval already resolved")
+)
+
+// ApprovalStatus is the state of an approval
+type ApprovalStatus string
+
+const (
+	// ApprovalStatusPending indicates the approval is awaiting a decision
+	ApprovalStatusPending ApprovalStatus = "pending"
+	// ApprovalStatusApproved indicates the tool call may proceed
+	ApprovalStatusApproved ApprovalStatus = "approved"
+	// ApprovalStatusRejected indicates the tool call must not proceed
+	ApprovalStatusRejected ApprovalStatus = "rejected"
+)
+
+// Approval is a tool call awaiting, or having received, a human decision
+type Approval struct {
+	ID         string         `json:"id"`
+	ThreadID   string         `json:"threadId"`
+	RunID      string         `json:"runId"`
+	ToolCallID string         `json:"toolCallId"`
+	ToolName   string         `json:"toolName"`
+	Arguments  string         `json:"arguments"`
+	Status     ApprovalStatus `json:"status"`
+	Reason     string         `json:"reason,omitempty"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	ResolvedAt *time.Time     `json:"resolvedAt,omitempty"`
+}
+
+// ApprovalDecision is a human decision on an approval
+type ApprovalDecision struct {
+	// Approved reports whether the tool call may proceed
+	Approved bool `json:"approved"`
+
+	// Arguments optionally replaces the tool call arguments
+	Arguments string `json:"arguments,omitempty"`
+
+	// Reason optionally explains the decision
+	Reason string `json:"reason,omitempty"`
+}
+
+// ApprovalStore persists approvals
+type ApprovalStore interface {
+	// Save creates or replaces an approval
+	Save(ctx context.Context, approval *Approval) error
+
+	// Get returns the approval with the given ID or ErrApprovalNotFound
+	Get(ctx context.Context, id string) (*Approval, error)
+
+	// ListPending returns pending approvals, optionally restricted to a thread
+	ListPending(ctx context.Context, threadID string) ([]*Approval, error)
+}
+
+// MemoryApprovalStore is an in-memory ApprovalStore
+type MemoryApprovalStore struct {
+	mu        sync.RWMutex
+	approvals map[string]*Approval
+}
+
+// NewMemoryApprovalStore creates an empty in-memory approval store
+func NewMemoryApprovalStore() *MemoryApprovalStore {
+	return &MemoryApprovalStore{approvals: make(map[string]*Approval)}
+}
+
+// Save creates or replaces an approval
+func (s *MemoryApprovalStore) Save(_ context.Context, approval *Approval) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *approval
+	s.approvals[approval.ID] = &stored
+	return nil
+}
+
+// Get returns the approval with the given ID
+func (s *MemoryApprovalStore) Get(_ context.Context, id string) (*Approval, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	approval, ok := s.approvals[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrApprovalNotFound, id)
+	}
+	result := *approval
+	return &result, nil
+}
+
+// ListPending returns pending approvals ordered by creation time
+func (s *MemoryApprovalStore) ListPending(_ context.Context, threadID string) ([]*Approval, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var result []*Approval
+	for _, approval := range s.approvals {
+		if approval.Status != ApprovalStatusPending || (threadID != "" && approval.ThreadID != threadID) {
+			continue
+		}
+		pending := *approval
+		result = append(result, &pending)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result, nil
+}
+
+// ApprovalConfig configures an ApprovalManager
+type ApprovalConfig struct {
+	// Store persists approvals (defaults to an in-memory store)
+	Store ApprovalStore
+
+	// Timeout bounds how long a run waits for a decision before the tool
+	// call is rejected (0 = wait until the run is cancelled)
+	Timeout time.Duration
+
+	// Logger receives approval diagnostics (defaults to slog.Default())
+	Logger *slog.Logger
+
+	// Clock times approvals out and stamps them (defaults to the real clock)
+	Clock clock.Clock
+}
+
+// ApprovalManager pauses runs at tool-call boundaries until a human approves,
+// rejects or modifies the call. Decisions arrive through Resolve, the HTTP
+// API returned by Handler, or the resume entries of a RunAgentInput.
+type ApprovalManager struct {
+	store   ApprovalStore
+	timeout time.Duration
+	logger  *slog.Logger
+	clock   clock.Clock
+
+	mu      sync.Mutex
+	waiters map[string]chan ApprovalDecision
+}
+
+// NewApprovalManager creates an approval manager
+func NewApprovalManager(config ApprovalConfig) *ApprovalManager {
+	if config.Store == nil {
+		config.Store = NewMemoryApprovalStore()
+	}
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+	return &ApprovalManager{
+		store:   config.Store,
+		timeout: config.Timeout,
+		logger:  logging.ForComponent(config.Logger, "approvals"),
+		clock:   clock.Or(config.Clock),
+		waiters: make(map[string]chan ApprovalDecision),
+	}
+}
+
+// RequireApproval pauses the run until the tool call is decided.
+// The pending approval is persisted and announced to the client with an
+// APPROVAL_REQUESTED event whose approval ID clients use to respond, and the
+// decision with an APPROVAL_GRANTED or APPROVAL_DENIED event. If input
+// already carries a resume entry for the tool call, that decision is used
+// without pausing. Timeouts are treated as rejections.
+func (m *ApprovalManager) RequireApproval(ctx context.Context, emitter *EventEmitter, input *types.RunAgentInput, toolCallID, toolName, arguments string) (*ApprovalDecision, error) {
+	if decision, ok := decisionFromResume(input, approvalID(input.ThreadID, toolCallID)); ok {
+		err := m.Resolve(ctx, approvalID(input.ThreadID, toolCallID), *decision)
+		if err != nil && !errors.Is(err, ErrApprovalNotFound) && !errors.Is(err, ErrApprovalResolved) {
+			return nil, err
+		}
+		return decision, nil
+	}
+
+	approval := &Approval{
+		ID:         approvalID(input.ThreadID, toolCallID),
+		ThreadID:   input.ThreadID,
+		RunID:      input.RunID,
+		ToolCallID: toolCallID,
+		ToolName:   toolName,
+		Arguments:  arguments,
+		Status:     ApprovalStatusPending,
+		CreatedAt:  m.clock.Now(),
+	}
+
+	waiter := make(chan ApprovalDecision, 1)
+	m.mu.Lock()
+	m.waiters[approval.ID] = waiter
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		delete(m.waiters, approval.ID)
+		m.mu.Unlock()
+	}()
+
+	if err := m.store.Save(ctx, approval); err != nil {
+		return nil, fmt.Errorf("failed to persist approval: %w", err)
+	}
+	request := events.NewApprovalRequestedEvent(approval.ID).WithToolCall(toolCallID, toolName, arguments)
+	if err := emitter.Emit(ctx, request); err != nil {
+		return nil, err
+	}
+	m.logger.InfoContext(ctx, "Waiting for approval", "approval_id", approval.ID, "tool", toolName)
+
+	var timeout <-chan time.Time
+	if m.timeout > 0 {
+		timer := m.clock.NewTimer(m.timeout)
+		defer timer.Stop()
+		timeout = timer.C()
+	}
+
+	var decision ApprovalDecision
+	select {
+	case decision = <-waiter:
+	case <-timeout:
+		decision = ApprovalDecision{Reason: "approval timed out"}
+		if err := m.Resolve(ctx, approval.ID, decision); err != nil && !errors.Is(err, ErrApprovalResolved) {
+			return nil, err
+		}
+		// A decision may have raced the timeout; it takes precedence
+		select {
+		case decision = <-waiter:
+		default:
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	resolved, err := m.store.Get(ctx, approval.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := emitter.Emit(ctx, resolved.answer()); err != nil {
+		return nil, err
+	}
+	return &decision, nil
+}
+
+// Resolve records a decision for a pending approval and resumes the run waiting on it
+func (m *ApprovalManager) Resolve(ctx context.Context, id string, decision ApprovalDecision) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	approval, err := m.store.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if approval.Status != ApprovalStatusPending {
+		return fmt.Errorf("%w: %s", ErrApprovalResolved, id)
+	}
+
+	now := m.clock.Now()
+	approval.Status = ApprovalStatusRejected
+	if decision.Approved {
+		approval.Status = ApprovalStatusApproved
+	}
+	if decision.Arguments != "" {
+		approval.Arguments = decision.Arguments
+	}
+	approval.Reason = decision.Reason
+	approval.ResolvedAt = &now
+	if err := m.store.Save(ctx, approval); err != nil {
+		return fmt.Errorf("failed to persist approval: %w", err)
+	}
+
+	if waiter, ok := m.waiters[id]; ok {
+		waiter <- decision
+		delete(m.waiters, id)
+	}
+	m.logger.InfoContext(ctx, "Approval resolved", "approval_id", id, "status", approval.Status)
+	return nil
+}
+
+// ResumeFromInput resolves pending approvals addressed by the resume entries of input.
+// Resolved entries approve the call; cancelled entries reject it. The entry
+// payload may carry an ApprovalDecision to modify arguments or give a reason.
+func (m *ApprovalManager) ResumeFromInput(ctx context.Context, input *types.RunAgentInput) error {
+	for _, entry := range input.Resume {
+		decision, ok := decisionFromResume(input, entry.InterruptID)
+		if !ok {
+			continue
+		}
+		err := m.Resolve(ctx, entry.InterruptID, *decision)
+		if err != nil && !errors.Is(err, ErrApprovalNotFound) && !errors.Is(err, ErrApprovalResolved) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pending returns pending approvals, optionally restricted to a thread
+func (m *ApprovalManager) Pending(ctx context.Context, threadID string) ([]*Approval, error) {
+	return m.store.ListPending(ctx, threadID)
+}
+
+// Handler returns an HTTP API for approvals, to be mounted with http.StripPrefix:
+//
+//	GET  /?threadId=...  lists pending approvals
+//	GET  /{id}           returns an approval
+//	POST /{id}           resolves an approval with an ApprovalDecision body
+func (m *ApprovalManager) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.Trim(r.URL.Path, "/")
+
+		switch {
+		case id == "" && r.Method == http.MethodGet:
+			pending, err := m.Pending(r.Context(), r.URL.Query().Get("threadId"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusOK, pending)
+
+		case id != "" && r.Method == http.MethodGet:
+			approval, err := m.store.Get(r.Context(), id)
+			if err != nil {
+				http.Error(w, err.Error(), approvalErrorStatus(err))
+				return
+			}
+			writeJSON(w, http.StatusOK, approval)
+
+		case id != "" && r.Method == http.MethodPost:
+			var decision ApprovalDecision
+			if err := json.NewDecoder(r.Body).Decode(&decision); err != nil {
+				http.Error(w, "invalid request body", http.StatusBadRequest)
+				return
+			}
+			if err := m.Resolve(r.Context(), id, decision); err != nil {
+				http.Error(w, err.Error(), approvalErrorStatus(err))
+				return
+			}
+			approval, err := m.store.Get(r.Context(), id)
+			if err != nil {
+				http.Error(w, err.Error(), approvalErrorStatus(err))
+				return
+			}
+			writeJSON(w, http.StatusOK, approval)
+
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// answer returns the event answering the request of a resolved approval
+func (a *Approval) answer() events.Event {
+	if a.Status != ApprovalStatusApproved {
+		denied := events.NewApprovalDeniedEvent(a.ID).WithToolCallID(a.ToolCallID)
+		if a.Reason != "" {
+			denied.WithReason(a.Reason)
+		}
+		return denied
+	}
+	granted := events.NewApprovalGrantedEvent(a.ID).WithToolCallID(a.ToolCallID).WithArguments(a.Arguments)
+	if a.Reason != "" {
+		granted.WithReason(a.Reason)
+	}
+	return granted
+}
+
+// approvalID derives a stable approval ID so that resumed runs address the same approval
+func approvalID(threadID, toolCallID string) string {
+	return threadID + ":" + toolCallID
+}
+
+// decisionFromResume returns the decision carried by the resume entry for id, if any
+func decisionFromResume(input *types.RunAgentInput, id string) (*ApprovalDecision, bool) {
+	for _, entry := range input.Resume {
+		if entry.InterruptID != id {
+			continue
+		}
+		decision := ApprovalDecision{Approved: entry.Status == types.ResumeStatusResolved}
+		if entry.Payload != nil {
+			if data, err := json.Marshal(entry.Payload); err == nil {
+				_ = json.Unmarshal(data, &decision)
+			}
+		}
+		if entry.Status == types.ResumeStatusCancelled {
+			decision.Approved = false
+		}
+		return &decision, true
+	}
+	return nil, false
+}
+
+// approvalErrorStatus maps approval errors to HTTP status codes
+func approvalErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrApprovalNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrApprovalResolved):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
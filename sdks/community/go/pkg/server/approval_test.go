@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/testhelper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runWithApproval runs an agent that requests approval for a single tool call
+func runWithApproval(t *testing.T, manager *ApprovalManager, input *types.RunAgentInput) (<-chan *ApprovalDecision, *recordingEmitter) {
+	t.Helper()
+	decisions := make(chan *ApprovalDecision, 1)
+	agent := AgentFunc(func(ctx context.Context, input *types.RunAgentInput, emitter *EventEmitter) error {
+		decision, err := manager.RequireApproval(ctx, emitter, input, "tool-1", "delete_file", `{"path":"/tmp/x"}`)
+		if err != nil {
+			return err
+		}
+		decisions <- decision
+		return nil
+	})
+	emitter := &recordingEmitter{}
+	go func() {
+		assert.NoError(t, NewRunManager(agent, RunManagerConfig{}).Run(context.Background(), input, emitter))
+	}()
+	return decisions, emitter
+}
+
+func waitForPending(t *testing.T, manager *ApprovalManager) *Approval {
+	t.Helper()
+	var pending []*Approval
+	require.Eventually(t, func() bool {
+		pending, _ = manager.Pending(context.Background(), "")
+		return len(pending) == 1
+	}, time.Second, time.Millisecond)
+	return pending[0]
+}
+
+func TestApprovalManagerResolve(t *testing.T) {
+	manager := NewApprovalManager(ApprovalConfig{})
+	decisions, emitter := runWithApproval(t, manager, newTestInput())
+
+	approval := waitForPending(t, manager)
+	assert.Equal(t, "tool-1", approval.ToolCallID)
+	assert.Equal(t, "delete_file", approval.ToolName)
+
+	require.NoError(t, manager.Resolve(context.Background(), approval.ID, ApprovalDecision{Approved: true, Arguments: `{"path":"/tmp/y"}`}))
+	decision := <-decisions
+	assert.True(t, decision.Approved)
+	assert.Equal(t, `{"path":"/tmp/y"}`, decision.Arguments)
+
+	require.Eventually(t, func() bool { return len(emitter.types()) == 4 }, time.Second, time.Millisecond)
+	assert.Equal(t, []events.EventType{
+		events.EventTypeRunStarted,
+		events.EventTypeApprovalRequested,
+		events.EventTypeApprovalGranted,
+		events.EventTypeRunFinished,
+	}, emitter.types())
+	request := emitter.events[1].(*events.ApprovalRequestedEvent)
+	assert.Equal(t, approval.ID, request.ApprovalID)
+	assert.Equal(t, "tool-1", request.ToolCallID)
+	assert.Equal(t, "delete_file", request.ToolName)
+	assert.Equal(t, `{"path":"/tmp/x"}`, request.Arguments)
+	granted := emitter.events[2].(*events.ApprovalGrantedEvent)
+	assert.Equal(t, approval.ID, granted.ApprovalID)
+	assert.Equal(t, "tool-1", granted.ToolCallID)
+	require.NotNil(t, granted.Arguments)
+	assert.Equal(t, `{"path":"/tmp/y"}`, *granted.Arguments)
+
+	err := manager.Resolve(context.Background(), approval.ID, ApprovalDecision{})
+	assert.ErrorIs(t, err, ErrApprovalResolved)
+}
+
+func TestApprovalManagerTimeout(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := testhelper.NewFakeClock(start)
+	manager := NewApprovalManager(ApprovalConfig{Timeout: time.Minute, Clock: clk})
+	decisions, _ := runWithApproval(t, manager, newTestInput())
+
+	approval := waitForPending(t, manager)
+	assert.Equal(t, start, approval.CreatedAt)
+	clk.BlockUntil(1)
+	clk.Advance(time.Minute - time.Second)
+	select {
+	case <-decisions:
+		t.Fatal("the approval timed out early")
+	default:
+	}
+
+	clk.Advance(time.Second)
+	decision := <-decisions
+	assert.False(t, decision.Approved)
+	assert.Equal(t, "approval timed out", decision.Reason)
+}
+
+func TestApprovalManagerResume(t *testing.T) {
+	manager := NewApprovalManager(ApprovalConfig{})
+	input := newTestInput()
+	input.Resume = []types.ResumeEntry{{
+		InterruptID: approvalID("thread-1", "tool-1"),
+		Status:      types.ResumeStatusCancelled,
+		Payload:     map[string]any{"reason": "not today"},
+	}}
+
+	decisions, _ := runWithApproval(t, manager, input)
+	decision := <-decisions
+	assert.False(t, decision.Approved)
+	assert.Equal(t, "not today", decision.Reason)
+}
+
+func TestApprovalHandler(t *testing.T) {
+	manager := NewApprovalManager(ApprovalConfig{})
+	handler := http.StripPrefix("/approvals", manager.Handler())
+	decisions, _ := runWithApproval(t, manager, newTestInput())
+	approval := waitForPending(t, manager)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/approvals/?threadId=thread-1", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var pending []*Approval
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &pending))
+	require.Len(t, pending, 1)
+	assert.Equal(t, approval.ID, pending[0].ID)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/approvals/"+approval.ID, strings.NewReader(`{"approved":false,"reason":"too risky"}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"rejected"`)
+	assert.Equal(t, "too risky", (<-decisions).Reason)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/approvals/"+approval.ID, strings.NewReader(`{"approved":true}`)))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/approvals/missing", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
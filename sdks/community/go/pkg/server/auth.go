@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrUnauthenticated is returned by authenticators when a request carries no valid credentials
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Principal identifies the caller of a request
+type Principal struct {
+	// ID uniquely identifies the caller, e.g. a user or API client
+	ID string
+
+	// Claims carries additional attributes about the caller
+	Claims map[string]any
+}
+
+// Authenticator resolves the principal making a request
+type Authenticator interface {
+	// Authenticate returns the principal for r, or an error wrapping ErrUnauthenticated
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// AuthenticatorFunc adapts an ordinary function to the Authenticator interface
+type AuthenticatorFunc func(r *http.Request) (*Principal, error)
+
+// Authenticate calls f(r)
+func (f AuthenticatorFunc) Authenticate(r *http.Request) (*Principal, error) {
+	return f(r)
+}
+
+// NewBearerTokenAuthenticator authenticates requests using static bearer tokens.
+// tokens maps each accepted token to the ID of the principal it identifies.
+func NewBearerTokenAuthenticator(tokens map[string]string) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) (*Principal, error) {
+		header := r.Header.Get("Authorization")
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || token == "" {
+			return nil, ErrUnauthenticated
+		}
+		for candidate, id := range tokens {
+			if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+				return &Principal{ID: id}, nil
+			}
+		}
+		return nil, ErrUnauthenticated
+	})
+}
+
+// NewAPIKeyAuthenticator authenticates requests using static API keys sent in header.
+// keys maps each accepted key to the ID of the principal it identifies.
+func NewAPIKeyAuthenticator(header string, keys map[string]string) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) (*Principal, error) {
+		key := r.Header.Get(header)
+		if key == "" {
+			return nil, ErrUnauthenticated
+		}
+		for candidate, id := range keys {
+			if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+				return &Principal{ID: id}, nil
+			}
+		}
+		return nil, ErrUnauthenticated
+	})
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal stored in ctx, if any
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
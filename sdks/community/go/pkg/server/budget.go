@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/i18n"
+)
+
+// ErrBudgetExceeded is the cancellation cause of runs exceeding their
+// budget, see RunBudget
+var ErrBudgetExceeded = errors.New("run budget exceeded")
+
+// BudgetLimit names a limit of a RunBudget
+type BudgetLimit string
+
+const (
+	BudgetEvents   BudgetLimit = "events"
+	BudgetBytes    BudgetLimit = "bytes"
+	BudgetDuration BudgetLimit = "duration"
+)
+
+// RunBudget bounds what a single run may emit, and for how long, protecting
+// clients from runaway agents, e.g. stuck in a loop. A run exceeding its
+// budget is cancelled and ends with a RUN_ERROR event of code
+// RunErrorCodeBudgetExceeded; the emit exceeding it fails with a
+// *BudgetExceededError and does not reach the client.
+type RunBudget struct {
+	// MaxEvents bounds the number of events the agent emits (0 = unlimited)
+	MaxEvents int
+
+	// MaxBytes bounds the total size of the JSON encoding of the events the
+	// agent emits (0 = unlimited)
+	MaxBytes int64
+
+	// MaxDuration bounds the duration of the run, measured with
+	// RunManagerConfig.Clock (0 = unlimited). Unlike RunTimeout, it ends the
+	// run with RunErrorCodeBudgetExceeded.
+	MaxDuration time.Duration
+}
+
+// BudgetExceededError tells which limit of its budget a run exceeded. It
+// matches ErrBudgetExceeded with errors.Is.
+type BudgetExceededError struct {
+	Limit BudgetLimit
+
+	// Max is the value of the limit: a number of events or bytes, or a
+	// time.Duration
+	Max int64
+}
+
+func (e *BudgetExceededError) Error() string {
+	return e.message().String()
+}
+
+func (e *BudgetExceededError) Unwrap() error {
+	return ErrBudgetExceeded
+}
+
+// message returns the message of the RUN_ERROR ending the run, keyed
+// MessageRunBudgetExceeded followed by the limit
+func (e *BudgetExceededError) message() i18n.Message {
+	key := MessageRunBudgetExceeded + "." + string(e.Limit)
+	if e.Limit == BudgetDuration {
+		return i18n.NewMessage(key, "run exceeded its budget of {max}", "max", time.Duration(e.Max))
+	}
+	return i18n.NewMessage(key, fmt.Sprintf("run exceeded its budget of {max} %s", e.Limit), "max", e.Max)
+}
+
+type runBudgetKey struct{}
+
+// WithRunBudget returns a copy of ctx setting the budget of the run it
+// starts, overriding RunManagerConfig.Budget
+func WithRunBudget(ctx context.Context, budget RunBudget) context.Context {
+	return context.WithValue(ctx, runBudgetKey{}, budget)
+}
+
+// runBudget returns the budget of the run started with ctx
+func (m *RunManager) runBudget(ctx context.Context) RunBudget {
+	if budget, ok := ctx.Value(runBudgetKey{}).(RunBudget); ok {
+		return budget
+	}
+	return m.config.Budget
+}
+
+// budgetMeter charges the events of a run against its budget
+type budgetMeter struct {
+	budget RunBudget
+	cancel context.CancelCauseFunc
+
+	events int
+	bytes  int64
+}
+
+// charge accounts for event, cancelling the run if it exceeds the budget;
+// callers must hold the mu of the emitter
+func (b *budgetMeter) charge(event events.Event) error {
+	if b == nil {
+		return nil
+	}
+	var exceeded *BudgetExceededError
+	if b.budget.MaxEvents > 0 && b.events+1 > b.budget.MaxEvents {
+		exceeded = &BudgetExceededError{Limit: BudgetEvents, Max: int64(b.budget.MaxEvents)}
+	}
+	var size int64
+	if exceeded == nil && b.budget.MaxBytes > 0 {
+		data, err := event.ToJSON()
+		if err != nil {
+			return fmt.Errorf("%s encoding failed: %w", event.Type(), err)
+		}
+		size = int64(len(data))
+		if b.bytes+size > b.budget.MaxBytes {
+			exceeded = &BudgetExceededError{Limit: BudgetBytes, Max: b.budget.MaxBytes}
+		}
+	}
+	if exceeded != nil {
+		b.cancel(exceeded)
+		return exceeded
+	}
+	b.events++
+	b.bytes += size
+	return nil
+}
+
+// enforceDuration cancels the run of runCtx once it lasted d
+func enforceDuration(runCtx context.Context, cancel context.CancelCauseFunc, clk clock.Clock, d time.Duration) {
+	timer := clk.NewTimer(d)
+	go func() {
+		select {
+		case <-runCtx.Done():
+			timer.Stop()
+		case <-timer.C():
+			cancel(&BudgetExceededError{Limit: BudgetDuration, Max: int64(d)})
+		}
+	}()
+}
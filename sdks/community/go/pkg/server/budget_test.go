@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/i18n"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/testhelper"
+)
+
+// loopingAgent streams content until its emitter fails, like an agent stuck
+// in a loop, and records the error that stopped it
+func loopingAgent(stopped *error) Agent {
+	return AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, emitter *EventEmitter) error {
+		messageID, err := emitter.StartTextMessage(ctx, "assistant")
+		for err == nil {
+			err = emitter.EmitContent(ctx, messageID, "again and again")
+		}
+		*stopped = err
+		return err
+	})
+}
+
+func TestRunBudget(t *testing.T) {
+	t.Run("events", func(t *testing.T) {
+		var stopped error
+		emitter := &recordingEmitter{}
+		err := NewRunManager(loopingAgent(&stopped), RunManagerConfig{Budget: RunBudget{MaxEvents: 5}}).
+			Run(context.Background(), newTestInput(), emitter)
+		require.ErrorIs(t, err, ErrBudgetExceeded)
+
+		var exceeded *BudgetExceededError
+		require.True(t, errors.As(stopped, &exceeded))
+		assert.Equal(t, BudgetEvents, exceeded.Limit)
+		assert.Len(t, emitter.events, 1+5+1, "RUN_STARTED, the budget and the RUN_ERROR")
+
+		runErr, ok := emitter.last().(*events.RunErrorEvent)
+		require.True(t, ok)
+		assert.Equal(t, RunErrorCodeBudgetExceeded, *runErr.Code)
+		assert.Equal(t, "run exceeded its budget of 5 events", runErr.Message)
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		var stopped error
+		emitter := &recordingEmitter{}
+		ctx := WithRunBudget(context.Background(), RunBudget{MaxBytes: 1000})
+		_ = NewRunManager(loopingAgent(&stopped), RunManagerConfig{Budget: RunBudget{MaxEvents: 1}}).Run(ctx, newTestInput(), emitter)
+
+		var exceeded *BudgetExceededError
+		require.True(t, errors.As(stopped, &exceeded), "the run budget overrides the default")
+		assert.Equal(t, BudgetBytes, exceeded.Limit)
+		var total int
+		for _, event := range emitter.events[1 : len(emitter.events)-1] {
+			data, err := event.ToJSON()
+			require.NoError(t, err)
+			total += len(data)
+		}
+		assert.LessOrEqual(t, total, 1000)
+		assert.Equal(t, "run exceeded its budget of 1000 bytes", emitter.last().(*events.RunErrorEvent).Message)
+	})
+
+	t.Run("duration", func(t *testing.T) {
+		clk := testhelper.NewFakeClock(time.Unix(0, 0))
+		stuck := AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, _ *EventEmitter) error {
+			<-ctx.Done()
+			return context.Cause(ctx)
+		})
+		catalog := i18n.NewCatalog()
+		catalog.Add("de", map[string]string{MessageRunBudgetExceeded + ".duration": "Lauf hat sein Budget von {max} überschritten"})
+		manager := NewRunManager(stuck, RunManagerConfig{
+			Budget:    RunBudget{MaxDuration: time.Minute},
+			Clock:     clk,
+			Localizer: i18n.NewLocalizer(catalog, "de"),
+		})
+
+		emitter := &recordingEmitter{}
+		done := make(chan error, 1)
+		go func() { done <- manager.Run(context.Background(), newTestInput(), emitter) }()
+		clk.BlockUntil(1)
+		clk.Advance(time.Minute)
+		require.ErrorIs(t, <-done, ErrBudgetExceeded)
+
+		runErr, ok := emitter.last().(*events.RunErrorEvent)
+		require.True(t, ok)
+		assert.Equal(t, RunErrorCodeBudgetExceeded, *runErr.Code)
+		assert.Equal(t, "Lauf hat sein Budget von 1m0s überschritten", runErr.Message)
+	})
+
+	t.Run("within budget", func(t *testing.T) {
+		agent := AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, emitter *EventEmitter) error {
+			return emitter.StartStep(ctx, "plan")
+		})
+		config := RunManagerConfig{Budget: RunBudget{MaxEvents: 1, MaxBytes: 1 << 10, MaxDuration: time.Hour}}
+		emitter := &recordingEmitter{}
+		require.NoError(t, NewRunManager(agent, config).Run(context.Background(), newTestInput(), emitter))
+		assert.Equal(t, events.EventTypeRunFinished, emitter.last().Type(), "lifecycle events are not charged")
+	})
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"context"
+	"slices"
+)
+
+type clientExtensionsKey struct{}
+
+// WithClientExtensions returns a copy of ctx carrying the protocol
+// extensions supported by the client of a run, see ClientExtensions
+func WithClientExtensions(ctx context.Context, extensions []string) context.Context {
+	return context.WithValue(ctx, clientExtensionsKey{}, extensions)
+}
+
+// ClientExtensions returns the protocol extensions the client of a run
+// listed in events.ExtensionsHeader, reporting false for clients that do
+// not advertise theirs. Agents use it to leave out events the client would
+// not understand, e.g. attachments.
+func ClientExtensions(ctx context.Context) ([]string, bool) {
+	extensions, ok := ctx.Value(clientExtensionsKey{}).([]string)
+	return extensions, ok
+}
+
+// ClientSupports reports whether the client of a run advertised an
+// extension
+func ClientSupports(ctx context.Context, extension string) bool {
+	extensions, _ := ClientExtensions(ctx)
+	return slices.Contains(extensions, extension)
+}
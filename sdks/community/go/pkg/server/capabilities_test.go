@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+func TestServerCapabilities(t *testing.T) {
+	srv := NewServer(AgentFunc(func(context.Context, *types.RunAgentInput, *EventEmitter) error { return nil }), Config{
+		RunManagerConfig: RunManagerConfig{MaxEventSize: 1 << 20},
+		Extensions:       []string{"acme_forms"},
+	})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var capabilities events.Capabilities
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &capabilities))
+	assert.Equal(t, srv.Capabilities(), capabilities)
+	assert.True(t, capabilities.SupportsEvent(events.EventTypeReasoningStart))
+	assert.True(t, capabilities.SupportsExtension(events.ExtensionHeartbeat))
+	assert.True(t, capabilities.SupportsExtension("acme_forms"))
+	assert.Equal(t, 1<<20, capabilities.MaxEventSize)
+	assert.Equal(t, int64(defaultMaxBodyBytes), capabilities.MaxBodyBytes)
+}
+
+func TestServerExchangesExtensions(t *testing.T) {
+	type seen struct {
+		extensions []string
+		advertised bool
+		attachment bool
+	}
+	runs := make(chan seen, 1)
+	srv := NewServer(AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, _ *EventEmitter) error {
+		extensions, ok := ClientExtensions(ctx)
+		runs <- seen{extensions, ok, ClientSupports(ctx, events.ExtensionAttachments)}
+		return nil
+	}), Config{})
+
+	run := func(header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"threadId":"thread-1","runId":"run-1"}`))
+		if header != "" {
+			req.Header.Set(events.ExtensionsHeader, header)
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := run("attachment, heartbeat")
+	assert.Equal(t, seen{[]string{"attachment", "heartbeat"}, true, true}, <-runs)
+	assert.Equal(t, srv.Capabilities().Extensions, events.ParseExtensions(rec.Header().Get(events.ExtensionsHeader)))
+
+	run("")
+	assert.Equal(t, seen{nil, false, false}, <-runs, "older clients advertise nothing")
+}
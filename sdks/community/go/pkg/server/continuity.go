@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding/sse"
+)
+
+// ErrRunNotResumable is returned for a request presenting a continuity token
+// of a run the server no longer has, e.g. once its retention expired,
+// after a restart or once it went past IdempotencyConfig.MaxRunBytes; the
+// client must start the run over
+var ErrRunNotResumable = errors.New("run cannot be resumed")
+
+// ResumableEmitter receives the events of a resumable run (see
+// IdempotencyConfig) along with their continuity tokens, which clients
+// present to resume the run after the event
+type ResumableEmitter interface {
+	EmitResumable(ctx context.Context, token events.ContinuityToken, event events.Event) error
+}
+
+// ResumableEmitterFunc adapts a function to ResumableEmitter
+type ResumableEmitterFunc func(ctx context.Context, token events.ContinuityToken, event events.Event) error
+
+func (f ResumableEmitterFunc) EmitResumable(ctx context.Context, token events.ContinuityToken, event events.Event) error {
+	return f(ctx, token, event)
+}
+
+// NewResumableSSEEmitter creates an emitter that writes events to w as SSE
+// frames like NewSSEEmitter, stamping each frame with the continuity token
+// of its event as its event ID
+func NewResumableSSEEmitter(w io.Writer, writer *sse.SSEWriter) ResumableEmitter {
+	if writer == nil {
+		writer = sse.NewSSEWriter()
+	}
+	return ResumableEmitterFunc(func(ctx context.Context, token events.ContinuityToken, event events.Event) error {
+		return writer.WriteEventWithID(ctx, w, event, token.String())
+	})
+}
+
+type continuityTokenKey struct{}
+
+// WithContinuityToken returns a copy of ctx resuming the idempotent run it
+// re-attaches to after the event of token, instead of replaying the run
+// from the start
+func WithContinuityToken(ctx context.Context, token events.ContinuityToken) context.Context {
+	return context.WithValue(ctx, continuityTokenKey{}, token)
+}
+
+// continuityToken returns the continuity token the run re-attached to with
+// ctx resumes from
+func continuityToken(ctx context.Context) (events.ContinuityToken, bool) {
+	token, ok := ctx.Value(continuityTokenKey{}).(events.ContinuityToken)
+	return token, ok
+}
+
+// requestedContinuityToken parses the continuity token presented with
+// events.LastEventIDHeader
+func requestedContinuityToken(r *http.Request) (events.ContinuityToken, bool, error) {
+	value := r.Header.Get(events.LastEventIDHeader)
+	if value == "" {
+		return events.ContinuityToken{}, false, nil
+	}
+	token, err := events.ParseContinuityToken(value)
+	if err != nil {
+		return events.ContinuityToken{}, false, err
+	}
+	return token, true, nil
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+// echoedHeaders are the request headers a diagnostic run echoes
+var echoedHeaders = []string{"Accept", "Accept-Encoding", "Content-Type", "User-Agent", events.IdempotencyKeyHeader, events.HeartbeatHeader, events.FlowWindowHeader}
+
+// DiagnosticConfig configures a DiagnosticServer
+type DiagnosticConfig struct {
+	Config
+
+	// Chunks is the number of chunks events.DiagnosticProbe is streamed in
+	// (defaults to 8)
+	Chunks int
+
+	// Delay is the time between two chunks (defaults to 100ms), long enough
+	// for clients to tell streamed events from buffered ones
+	Delay time.Duration
+}
+
+// DiagnosticServer is an endpoint for clients testing the path to an agent,
+// e.g. behind the same proxies and authentication as the real one. Each run
+// echoes its request in an events.DiagnosticEchoEventName event then streams
+// events.DiagnosticProbe chunk by chunk; runs submitted with an idempotency
+// key can be re-attached to, for clients testing reconnection.
+type DiagnosticServer struct {
+	*Server
+}
+
+type diagnosticHeadersKey struct{}
+
+// NewDiagnosticServer creates a diagnostic endpoint advertising
+// events.ExtensionDiagnostics
+func NewDiagnosticServer(config DiagnosticConfig) *DiagnosticServer {
+	if config.Chunks <= 0 {
+		config.Chunks = 8
+	}
+	if config.Delay <= 0 {
+		config.Delay = 100 * time.Millisecond
+	}
+	if !slices.Contains(config.Extensions, events.ExtensionDiagnostics) {
+		config.Extensions = append(slices.Clone(config.Extensions), events.ExtensionDiagnostics)
+	}
+	clk := clock.Or(config.Clock)
+
+	agent := AgentFunc(func(ctx context.Context, input *types.RunAgentInput, emitter *EventEmitter) error {
+		chunks := splitProbe(config.Chunks)
+		echo := events.DiagnosticEcho{
+			ProtocolVersion: input.ProtocolVersion,
+			Chunks:          len(chunks),
+			IntervalMs:      config.Delay.Milliseconds(),
+		}
+		echo.Extensions, _ = ClientExtensions(ctx)
+		if principal, ok := PrincipalFromContext(ctx); ok {
+			echo.Principal = principal.ID
+		}
+		echo.Headers, _ = ctx.Value(diagnosticHeadersKey{}).(map[string]string)
+		if err := emitter.Emit(ctx, events.NewDiagnosticEchoEvent(echo)); err != nil {
+			return err
+		}
+
+		messageID, err := emitter.StartTextMessage(ctx, "assistant")
+		if err != nil {
+			return err
+		}
+		for i, chunk := range chunks {
+			if i > 0 {
+				select {
+				case <-ctx.Done():
+					return context.Cause(ctx)
+				case <-clk.After(config.Delay):
+				}
+			}
+			if err := emitter.EmitContent(ctx, messageID, chunk); err != nil {
+				return err
+			}
+		}
+		return emitter.EndTextMessage(ctx, messageID)
+	})
+	return &DiagnosticServer{Server: NewServer(agent, config.Config)}
+}
+
+// ServeHTTP serves the request like Server.ServeHTTP, recording the headers
+// echoed by its run
+func (s *DiagnosticServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	headers := make(map[string]string)
+	for _, name := range echoedHeaders {
+		if value := r.Header.Get(name); value != "" {
+			headers[name] = value
+		}
+	}
+	s.Server.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), diagnosticHeadersKey{}, headers)))
+}
+
+// splitProbe splits events.DiagnosticProbe in n chunks, or fewer when it has
+// fewer characters, without splitting any character
+func splitProbe(n int) []string {
+	runes := []rune(events.DiagnosticProbe)
+	n = min(n, len(runes))
+	chunks := make([]string, 0, n)
+	for i := range n {
+		chunks = append(chunks, string(runes[i*len(runes)/n:(i+1)*len(runes)/n]))
+	}
+	return chunks
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+func TestSplitProbe(t *testing.T) {
+	for _, n := range []int{1, 3, 8, 1000} {
+		chunks := splitProbe(n)
+		assert.Equal(t, events.DiagnosticProbe, strings.Join(chunks, ""))
+		assert.LessOrEqual(t, len(chunks), n)
+		for _, chunk := range chunks {
+			assert.NotEmpty(t, chunk)
+		}
+	}
+}
+
+func TestDiagnosticServer(t *testing.T) {
+	srv := NewDiagnosticServer(DiagnosticConfig{Chunks: 3, Delay: time.Millisecond})
+	assert.True(t, srv.Capabilities().SupportsExtension(events.ExtensionDiagnostics))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"threadId":"thread-1","runId":"run-1"}`))
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set(events.ExtensionsHeader, events.ExtensionDiagnostics)
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var echo *events.DiagnosticEcho
+	var text strings.Builder
+	for _, line := range strings.Split(rec.Body.String(), "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			continue
+		}
+		event, err := events.EventFromJSON([]byte(data))
+		require.NoError(t, err)
+		if e, ok, err := events.DiagnosticEchoOf(event); ok {
+			require.NoError(t, err)
+			echo = e
+		}
+		if content, ok := event.(*events.TextMessageContentEvent); ok {
+			text.WriteString(content.Delta)
+		}
+	}
+	require.NotNil(t, echo)
+	assert.Equal(t, events.DiagnosticEcho{
+		ProtocolVersion: events.ProtocolVersion,
+		Extensions:      []string{events.ExtensionDiagnostics},
+		Headers:         map[string]string{"Accept": "text/event-stream"},
+		Chunks:          3,
+		IntervalMs:      1,
+	}, *echo, "credentials are not echoed")
+	assert.Equal(t, events.DiagnosticProbe, text.String())
+}
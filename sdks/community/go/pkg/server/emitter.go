@@ -0,0 +1,352 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// ErrInvalidSequence is returned when an event would violate the AG-UI protocol rules
+var ErrInvalidSequence = errors.New("invalid event sequence")
+
+// EventEmitter is handed to agents for the duration of a run.
+// Every event is validated against the protocol rules before it reaches the
+// underlying emitter, so an agent cannot start a message twice, send content
+// for a message that was never started, and so on. Emits are serialized and
+// block until the underlying emitter has delivered the event, which means a
+// slow client applies backpressure to the agent producing the events.
+type EventEmitter struct {
+	sink      Emitter
+	validator *events.EventValidator
+	// flow meters streamed content, nil when the run is not flow
+	// controlled
+	flow *FlowController
+	// share meters streamed content against the rate shared by the runs,
+	// nil when there is none
+	share *FlowShare
+	// adaptive paces streamed content at the rate the consumer keeps up
+	// with, nil when the rate is not adapted
+	adaptive *AdaptiveFlowController
+	// steps tracks the steps of the run, nil when steps are not required
+	// to nest
+	steps *events.StepTracker
+	// budget charges the events of the agent against the budget of the
+	// run, nil when unlimited
+	budget *budgetMeter
+
+	mu        sync.Mutex
+	completed bool
+
+	// emitted counts the events delivered, and beat its value when the
+	// run was last checked for silence
+	emitted uint64
+	beat    uint64
+}
+
+// newEventEmitter creates an event emitter delivering to sink
+func newEventEmitter(sink Emitter, options ...events.ValidatorOption) *EventEmitter {
+	return &EventEmitter{
+		sink:      sink,
+		validator: events.NewEventValidator(options...),
+	}
+}
+
+// Emit validates and delivers a single event.
+// Run lifecycle events are rejected with ErrLifecycleEvent and events
+// emitted after the run has completed with ErrRunCompleted. Events carrying
+// streamed content wait for credit in flow controlled runs (see
+// FlowController), and for their share of the rate shared by the runs, if
+// any (see SharedFlowController), and are paced in runs adapting their rate
+// to their consumer (see AdaptiveFlowController). Events exceeding the
+// budget of the run fail with a *BudgetExceededError (see RunBudget). Messages
+// snapshots over the event size limit (RunManagerConfig.MaxEventSize) are
+// sent in chunks, see events.SplitMessagesSnapshot.
+func (e *EventEmitter) Emit(ctx context.Context, event events.Event) error {
+	if event == nil {
+		return fmt.Errorf("event cannot be nil")
+	}
+	switch event.Type() {
+	case events.EventTypeRunStarted, events.EventTypeRunFinished, events.EventTypeRunError:
+		return fmt.Errorf("%w: %s", ErrLifecycleEvent, event.Type())
+	}
+	// Credit is awaited before taking mu so that heartbeats go on while the
+	// client holds the run back
+	if events.ConsumesCredit(event) {
+		if e.flow != nil {
+			if err := e.flow.acquire(ctx); err != nil {
+				return err
+			}
+		}
+		if e.share != nil {
+			if err := e.share.acquire(ctx); err != nil {
+				return err
+			}
+		}
+		if e.adaptive != nil {
+			if err := e.adaptive.acquire(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.completed {
+		return ErrRunCompleted
+	}
+	if err := e.budget.charge(event); err != nil {
+		return err
+	}
+	if snapshot, ok := event.(*events.MessagesSnapshotEvent); ok {
+		parts, err := events.SplitMessagesSnapshot(snapshot, e.validator.MaxEventSize())
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidSequence, err)
+		}
+		if len(parts) > 1 {
+			// The validator only sees the chunks
+			if err := snapshot.Validate(); err != nil {
+				return fmt.Errorf("%w: %s validation failed: %w", ErrInvalidSequence, snapshot.Type(), err)
+			}
+		}
+		for _, part := range parts {
+			if err := e.emit(ctx, part); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return e.emit(ctx, event)
+}
+
+// StartTextMessage starts a new text message and returns its ID
+func (e *EventEmitter) StartTextMessage(ctx context.Context, role string) (string, error) {
+	messageID := events.GenerateMessageID()
+	if err := e.Emit(ctx, events.NewTextMessageStartEvent(messageID, events.WithRole(role))); err != nil {
+		return "", err
+	}
+	return messageID, nil
+}
+
+// EmitContent appends a chunk of content to a started text message
+func (e *EventEmitter) EmitContent(ctx context.Context, messageID, delta string) error {
+	return e.Emit(ctx, events.NewTextMessageContentEvent(messageID, delta))
+}
+
+// EndTextMessage ends a started text message
+func (e *EventEmitter) EndTextMessage(ctx context.Context, messageID string) error {
+	return e.Emit(ctx, events.NewTextMessageEndEvent(messageID))
+}
+
+// StartToolCall starts a new tool call and returns its ID.
+// parentMessageID may be empty.
+func (e *EventEmitter) StartToolCall(ctx context.Context, name, parentMessageID string) (string, error) {
+	toolCallID := events.GenerateToolCallID()
+	var options []events.ToolCallStartOption
+	if parentMessageID != "" {
+		options = append(options, events.WithParentMessageID(parentMessageID))
+	}
+	if err := e.Emit(ctx, events.NewToolCallStartEvent(toolCallID, name, options...)); err != nil {
+		return "", err
+	}
+	return toolCallID, nil
+}
+
+// EmitToolCallArgs appends a chunk of arguments to a started tool call
+func (e *EventEmitter) EmitToolCallArgs(ctx context.Context, toolCallID, delta string) error {
+	return e.Emit(ctx, events.NewToolCallArgsEvent(toolCallID, delta))
+}
+
+// EndToolCall ends a started tool call
+func (e *EventEmitter) EndToolCall(ctx context.Context, toolCallID string) error {
+	return e.Emit(ctx, events.NewToolCallEndEvent(toolCallID))
+}
+
+// EmitToolCallResult emits the result of a tool call and returns the ID of the result message
+func (e *EventEmitter) EmitToolCallResult(ctx context.Context, toolCallID, content string) (string, error) {
+	messageID := events.GenerateMessageID()
+	if err := e.Emit(ctx, events.NewToolCallResultEvent(messageID, toolCallID, content)); err != nil {
+		return "", err
+	}
+	return messageID, nil
+}
+
+// StartStep starts a named step
+func (e *EventEmitter) StartStep(ctx context.Context, name string) error {
+	return e.Emit(ctx, events.NewStepStartedEvent(name))
+}
+
+// FinishStep finishes a started step. When steps are required to nest
+// (RunManagerConfig.NestedSteps), the steps still open in it are finished
+// first, innermost first.
+func (e *EventEmitter) FinishStep(ctx context.Context, name string) error {
+	return e.Emit(ctx, events.NewStepFinishedEvent(name))
+}
+
+// Steps returns the steps of the run in the order they started, nil
+// unless RunManagerConfig.NestedSteps is set
+func (e *EventEmitter) Steps() []events.Step {
+	if e.steps == nil {
+		return nil
+	}
+	return e.steps.Steps()
+}
+
+// EmitStateSnapshot emits a complete state snapshot
+func (e *EventEmitter) EmitStateSnapshot(ctx context.Context, snapshot any) error {
+	return e.Emit(ctx, events.NewStateSnapshotEvent(snapshot))
+}
+
+// EmitStateDelta emits an incremental state change as JSON Patch operations
+func (e *EventEmitter) EmitStateDelta(ctx context.Context, delta []events.JSONPatchOperation) error {
+	return e.Emit(ctx, events.NewStateDeltaEvent(delta))
+}
+
+// EmitMessagesSnapshot emits a complete snapshot of the conversation messages
+func (e *EventEmitter) EmitMessagesSnapshot(ctx context.Context, messages []events.Message) error {
+	return e.Emit(ctx, events.NewMessagesSnapshotEvent(messages))
+}
+
+// UpdateMessage replaces the content of a message that has ended, e.g. to
+// correct earlier output, rather than resending a messages snapshot
+func (e *EventEmitter) UpdateMessage(ctx context.Context, messageID, content string) error {
+	return e.Emit(ctx, events.NewMessageUpdatedEvent(messageID, content))
+}
+
+// DeleteMessage retracts a message that has ended; reason, shown by clients
+// in its place, may be empty
+func (e *EventEmitter) DeleteMessage(ctx context.Context, messageID, reason string) error {
+	event := events.NewMessageDeletedEvent(messageID)
+	if reason != "" {
+		event.WithReason(reason)
+	}
+	return e.Emit(ctx, event)
+}
+
+// EmitCustom emits an application-specific custom event
+func (e *EventEmitter) EmitCustom(ctx context.Context, name string, value any) error {
+	return e.Emit(ctx, events.NewCustomEvent(name, events.WithValue(value)))
+}
+
+// EmitAttachment emits binary content attached to a message or tool call,
+// as created by an attachment.Attacher
+func (e *EventEmitter) EmitAttachment(ctx context.Context, attachment events.Attachment) error {
+	if err := attachment.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSequence, err)
+	}
+	return e.Emit(ctx, events.NewAttachmentEvent(attachment))
+}
+
+// EmitUsage reports the model tokens consumed for a message, when it ends,
+// or for the run as a whole, before it completes
+func (e *EventEmitter) EmitUsage(ctx context.Context, usage events.Usage) error {
+	if err := usage.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSequence, err)
+	}
+	return e.Emit(ctx, events.NewUsageEvent(usage))
+}
+
+// emitLifecycle delivers a run lifecycle event on behalf of the RunManager
+func (e *EventEmitter) emitLifecycle(ctx context.Context, event events.Event) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.emit(ctx, event)
+}
+
+// emit validates and delivers an event, finishing first the steps still
+// open in a step it finishes, if steps are tracked; callers must hold mu
+func (e *EventEmitter) emit(ctx context.Context, event events.Event) error {
+	if e.steps == nil {
+		return e.deliver(ctx, event)
+	}
+	if finished, ok := event.(*events.StepFinishedEvent); ok {
+		// The tracker learns of the nested steps as orphans once their
+		// parent finished
+		for _, name := range e.steps.Nested(finished.StepName) {
+			if err := e.deliver(ctx, events.NewStepFinishedEvent(name)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := e.deliver(ctx, event); err != nil {
+		return err
+	}
+	return e.steps.Observe(event)
+}
+
+// deliver validates and delivers an event; callers must hold mu
+func (e *EventEmitter) deliver(ctx context.Context, event events.Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := e.validator.ValidateEvent(event); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSequence, err)
+	}
+	var start time.Time
+	if e.adaptive != nil {
+		start = e.adaptive.config.Clock.Now()
+	}
+	if err := e.sink.Emit(ctx, event); err != nil {
+		return err
+	}
+	if e.adaptive != nil {
+		var depth int
+		if reporter, ok := e.sink.(QueueDepthReporter); ok {
+			depth = reporter.QueueDepth()
+		}
+		e.adaptive.observe(clock.Since(e.adaptive.config.Clock, start), depth)
+	}
+	e.emitted++
+	return nil
+}
+
+// resetHeartbeat starts a heartbeat period
+func (e *EventEmitter) resetHeartbeat() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.beat = e.emitted
+}
+
+// heartbeat emits a heartbeat if no event was delivered since the last
+// call, then starts a new heartbeat period
+func (e *EventEmitter) heartbeat(ctx context.Context, interval time.Duration) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.completed {
+		return nil
+	}
+	var err error
+	if e.emitted == e.beat {
+		err = e.emit(ctx, events.NewHeartbeatEvent(interval))
+	}
+	e.beat = e.emitted
+	return err
+}
+
+// closeSteps finishes the steps left open, innermost first, and returns
+// how many it finished
+func (e *EventEmitter) closeSteps(ctx context.Context) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.steps == nil || e.completed {
+		return 0, nil
+	}
+	closed := e.steps.Close()
+	for _, event := range closed {
+		if err := e.deliver(ctx, event); err != nil {
+			return 0, err
+		}
+	}
+	return len(closed), nil
+}
+
+// complete rejects further events, waiting for any in-flight Emit to return
+func (e *EventEmitter) complete() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.completed = true
+}
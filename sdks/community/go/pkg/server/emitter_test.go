@@ -0,0 +1,248 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventEmitterTypedMethods(t *testing.T) {
+	agent := AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, emitter *EventEmitter) error {
+		messageID, err := emitter.StartTextMessage(ctx, "assistant")
+		if err != nil {
+			return err
+		}
+		if err := emitter.EmitContent(ctx, messageID, "Looking that up"); err != nil {
+			return err
+		}
+		toolCallID, err := emitter.StartToolCall(ctx, "search", messageID)
+		if err != nil {
+			return err
+		}
+		if err := emitter.EmitToolCallArgs(ctx, toolCallID, `{"q":"go"}`); err != nil {
+			return err
+		}
+		if err := emitter.EndToolCall(ctx, toolCallID); err != nil {
+			return err
+		}
+		if _, err := emitter.EmitToolCallResult(ctx, toolCallID, "found"); err != nil {
+			return err
+		}
+		return emitter.EndTextMessage(ctx, messageID)
+	})
+	emitter := &recordingEmitter{}
+
+	require.NoError(t, NewRunManager(agent, RunManagerConfig{}).Run(context.Background(), newTestInput(), emitter))
+	assert.Equal(t, []events.EventType{
+		events.EventTypeRunStarted,
+		events.EventTypeTextMessageStart,
+		events.EventTypeTextMessageContent,
+		events.EventTypeToolCallStart,
+		events.EventTypeToolCallArgs,
+		events.EventTypeToolCallEnd,
+		events.EventTypeToolCallResult,
+		events.EventTypeTextMessageEnd,
+		events.EventTypeRunFinished,
+	}, emitter.types())
+}
+
+func TestEventEmitterRejectsInvalidSequences(t *testing.T) {
+	tests := []struct {
+		name string
+		emit func(ctx context.Context, emitter *EventEmitter) error
+	}{
+		{
+			name: "content before start",
+			emit: func(ctx context.Context, emitter *EventEmitter) error {
+				return emitter.EmitContent(ctx, "msg-1", "Hello")
+			},
+		},
+		{
+			name: "message ended twice",
+			emit: func(ctx context.Context, emitter *EventEmitter) error {
+				messageID, err := emitter.StartTextMessage(ctx, "assistant")
+				if err != nil {
+					return err
+				}
+				if err := emitter.EndTextMessage(ctx, messageID); err != nil {
+					return err
+				}
+				return emitter.EndTextMessage(ctx, messageID)
+			},
+		},
+		{
+			name: "args for unknown tool call",
+			emit: func(ctx context.Context, emitter *EventEmitter) error {
+				return emitter.EmitToolCallArgs(ctx, "tool-1", "{}")
+			},
+		},
+		{
+			name: "update streaming message",
+			emit: func(ctx context.Context, emitter *EventEmitter) error {
+				messageID, err := emitter.StartTextMessage(ctx, "assistant")
+				if err != nil {
+					return err
+				}
+				return emitter.UpdateMessage(ctx, messageID, "Hello")
+			},
+		},
+		{
+			name: "delete message twice",
+			emit: func(ctx context.Context, emitter *EventEmitter) error {
+				if err := emitter.DeleteMessage(ctx, "msg-1", ""); err != nil {
+					return err
+				}
+				return emitter.DeleteMessage(ctx, "msg-1", "")
+			},
+		},
+		{
+			name: "finish step that was not started",
+			emit: func(ctx context.Context, emitter *EventEmitter) error {
+				return emitter.FinishStep(ctx, "plan")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var emitErr error
+			agent := AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, emitter *EventEmitter) error {
+				emitErr = tt.emit(ctx, emitter)
+				return nil
+			})
+			emitter := &recordingEmitter{}
+
+			require.NoError(t, NewRunManager(agent, RunManagerConfig{}).Run(context.Background(), newTestInput(), emitter))
+			assert.ErrorIs(t, emitErr, ErrInvalidSequence)
+			assert.Equal(t, events.EventTypeRunFinished, emitter.last().Type())
+		})
+	}
+}
+
+func TestEventEmitterEditsMessages(t *testing.T) {
+	agent := AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, emitter *EventEmitter) error {
+		messageID, err := emitter.StartTextMessage(ctx, "assistant")
+		if err != nil {
+			return err
+		}
+		if err := emitter.EmitContent(ctx, messageID, "Lyon"); err != nil {
+			return err
+		}
+		if err := emitter.EndTextMessage(ctx, messageID); err != nil {
+			return err
+		}
+		if err := emitter.UpdateMessage(ctx, messageID, "Paris"); err != nil {
+			return err
+		}
+		return emitter.DeleteMessage(ctx, "msg-earlier", "superseded")
+	})
+	emitter := &recordingEmitter{}
+
+	require.NoError(t, NewRunManager(agent, RunManagerConfig{}).Run(context.Background(), newTestInput(), emitter))
+	require.Len(t, emitter.events, 7)
+	updated, ok := emitter.events[4].(*events.MessageUpdatedEvent)
+	require.True(t, ok)
+	assert.Equal(t, "Paris", updated.Content)
+	deleted, ok := emitter.events[5].(*events.MessageDeletedEvent)
+	require.True(t, ok)
+	assert.Equal(t, "msg-earlier", deleted.MessageID)
+	assert.Equal(t, "superseded", *deleted.Reason)
+}
+
+func TestEventEmitterChunksLargeSnapshots(t *testing.T) {
+	messages := make([]events.Message, 20)
+	for i := range messages {
+		messages[i] = events.Message{ID: fmt.Sprintf("msg-%d", i), Role: "user", Content: strings.Repeat("x", 100)}
+	}
+	agent := AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, emitter *EventEmitter) error {
+		if err := emitter.EmitMessagesSnapshot(ctx, messages); err != nil {
+			return err
+		}
+		return emitter.EmitCustom(ctx, "large", strings.Repeat("x", 1024))
+	})
+	emitter := &recordingEmitter{}
+
+	err := NewRunManager(agent, RunManagerConfig{MaxEventSize: 1024}).Run(context.Background(), newTestInput(), emitter)
+	assert.True(t, errors.Is(err, events.ErrEventTooLarge), "got %v", err)
+
+	assembler := events.NewSnapshotAssembler(0)
+	var rebuilt []events.Event
+	for _, event := range emitter.events {
+		event, err := assembler.Assemble(event)
+		require.NoError(t, err)
+		if event != nil {
+			rebuilt = append(rebuilt, event)
+		}
+	}
+	assert.Greater(t, len(emitter.events), len(rebuilt)+2)
+	require.Len(t, rebuilt, 3)
+	assert.Equal(t, messages, rebuilt[1].(*events.MessagesSnapshotEvent).Messages)
+	assert.Equal(t, events.EventTypeRunError, rebuilt[2].Type())
+}
+
+func TestEventEmitterAttachments(t *testing.T) {
+	content := []byte("result")
+	valid := events.Attachment{ID: "att-1", MediaType: "text/plain", Size: int64(len(content)), SHA256: events.ContentHash(content), Data: content}
+	invalid := valid
+	invalid.Size++
+	agent := AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, emitter *EventEmitter) error {
+		if err := emitter.EmitAttachment(ctx, valid); err != nil {
+			return err
+		}
+		return emitter.EmitAttachment(ctx, invalid)
+	})
+	emitter := &recordingEmitter{}
+
+	err := NewRunManager(agent, RunManagerConfig{}).Run(context.Background(), newTestInput(), emitter)
+	assert.True(t, errors.Is(err, events.ErrInvalidAttachment), "got %v", err)
+	require.Len(t, emitter.events, 3)
+	attachment, ok, err := events.AttachmentOf(emitter.events[1])
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, valid, *attachment)
+}
+
+func TestEventEmitterNestedSteps(t *testing.T) {
+	var steps []events.Step
+	agent := AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, emitter *EventEmitter) error {
+		for _, name := range []string{"plan", "search", "rank"} {
+			if err := emitter.StartStep(ctx, name); err != nil {
+				return err
+			}
+		}
+		if err := emitter.FinishStep(ctx, "search"); err != nil {
+			return err
+		}
+		if err := emitter.StartStep(ctx, "answer"); err != nil {
+			return err
+		}
+		steps = emitter.Steps()
+		return nil
+	})
+	emitter := &recordingEmitter{}
+
+	require.NoError(t, NewRunManager(agent, RunManagerConfig{NestedSteps: true}).Run(context.Background(), newTestInput(), emitter))
+	var sequence []string
+	for _, event := range emitter.events {
+		switch e := event.(type) {
+		case *events.StepStartedEvent:
+			sequence = append(sequence, "+"+e.StepName)
+		case *events.StepFinishedEvent:
+			sequence = append(sequence, "-"+e.StepName)
+		}
+	}
+	assert.Equal(t, []string{"+plan", "+search", "+rank", "-rank", "-search", "+answer", "-answer", "-plan"}, sequence,
+		"nested steps finish with their parent and open steps before the run")
+	assert.Equal(t, events.EventTypeRunFinished, emitter.last().Type())
+
+	require.Len(t, steps, 4)
+	assert.True(t, steps[2].Orphaned)
+	assert.Equal(t, "plan", steps[3].Parent)
+}
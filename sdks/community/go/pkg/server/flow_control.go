@@ -0,0 +1,362 @@
+package server
+
+import (
+	"container/heap"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// FlowController meters the streamed content of a run with the credit its
+// client grants, see events.FlowWindowHeader. The EventEmitter of the run
+// spends one credit for each event carrying content and blocks the agent
+// while the credit is spent, so that the agent produces tokens no faster
+// than the client consumes them. Other events, heartbeats included, are
+// never held back. It is safe for concurrent use.
+type FlowController struct {
+	mu     sync.Mutex
+	window int
+	credit int
+	// granted is closed, then replaced, whenever credit is granted
+	granted chan struct{}
+}
+
+// NewFlowController creates a flow controller with a window of window
+// events of credit
+func NewFlowController(window int) *FlowController {
+	return &FlowController{window: window, credit: window, granted: make(chan struct{})}
+}
+
+// Grant adds credit for that many more events
+func (f *FlowController) Grant(credit int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.credit += credit
+	f.notify()
+}
+
+// Reset opens a new window of window events of credit, discarding the
+// remaining credit, e.g. for a client re-attaching to the run
+func (f *FlowController) Reset(window int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.window, f.credit = window, window
+	f.notify()
+}
+
+// Credit returns the number of events the run may still stream
+func (f *FlowController) Credit() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.credit
+}
+
+// notify wakes up the run waiting for credit; callers must hold mu
+func (f *FlowController) notify() {
+	close(f.granted)
+	f.granted = make(chan struct{})
+}
+
+// acquire spends one credit, waiting for the client to grant some when it
+// has run out, until ctx is done
+func (f *FlowController) acquire(ctx context.Context) error {
+	for {
+		f.mu.Lock()
+		if f.credit > 0 {
+			f.credit--
+			f.mu.Unlock()
+			return nil
+		}
+		granted := f.granted
+		f.mu.Unlock()
+
+		select {
+		case <-granted:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// SharedFlowConfig configures a SharedFlowController
+type SharedFlowConfig struct {
+	// Rate is the number of content events per second shared by the runs
+	// (0 = unlimited)
+	Rate float64
+
+	// Burst is the number of content events streamed at once after the
+	// runs were idle (defaults to 1)
+	Burst int
+
+	// Clock times the refills of the rate (defaults to the real clock)
+	Clock clock.Clock
+}
+
+// SharedFlowController shares a rate of streamed content between the runs
+// of a process by weighted fair queuing: while the rate is exhausted, each
+// run waiting for it streams in proportion to its weight (see
+// WithFlowWeight), so that one giant export does not starve interactive
+// runs, and runs alone stream at the full rate. Like FlowController it only
+// meters events carrying content. It is safe for concurrent use.
+type SharedFlowController struct {
+	config SharedFlowConfig
+
+	mu       sync.Mutex
+	tokens   float64
+	refilled time.Time
+	// virtual is the virtual time of the queue, the start tag of the last
+	// request granted
+	virtual float64
+	queue   flowQueue
+	seq     uint64
+}
+
+// NewSharedFlowController creates a shared flow controller
+func NewSharedFlowController(config SharedFlowConfig) *SharedFlowController {
+	if config.Burst <= 0 {
+		config.Burst = 1
+	}
+	config.Clock = clock.Or(config.Clock)
+	return &SharedFlowController{
+		config:   config,
+		tokens:   float64(config.Burst),
+		refilled: config.Clock.Now(),
+	}
+}
+
+// FlowShare is the share of a run in a SharedFlowController
+type FlowShare struct {
+	shared *SharedFlowController
+	weight float64
+	// finish is the finish tag of the last request of the run
+	finish float64
+}
+
+// Join returns the share of a run of weight weight (1 when not positive),
+// nil for a nil controller
+func (s *SharedFlowController) Join(weight float64) *FlowShare {
+	if s == nil {
+		return nil
+	}
+	if weight <= 0 {
+		weight = 1
+	}
+	return &FlowShare{shared: s, weight: weight}
+}
+
+// shareRequest is a request of a run for an event of the shared rate
+type shareRequest struct {
+	start     float64
+	seq       uint64
+	granted   chan struct{}
+	done      bool
+	cancelled bool
+}
+
+// flowQueue orders requests by start tag, then arrival, as a heap
+type flowQueue []*shareRequest
+
+func (q flowQueue) Len() int { return len(q) }
+func (q flowQueue) Less(i, j int) bool {
+	if q[i].start != q[j].start {
+		return q[i].start < q[j].start
+	}
+	return q[i].seq < q[j].seq
+}
+func (q flowQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *flowQueue) Push(x any)   { *q = append(*q, x.(*shareRequest)) }
+func (q *flowQueue) Pop() any {
+	old := *q
+	r := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return r
+}
+
+// acquire spends one event of the rate, waiting for the requests ahead of
+// it, until ctx is done. Requests are tagged as in start-time fair queuing:
+// a run starts where its previous request finished, or at the virtual time
+// of the queue when it was idle, and each request lasts 1/weight.
+func (f *FlowShare) acquire(ctx context.Context) error {
+	s := f.shared
+	s.mu.Lock()
+	if s.config.Rate <= 0 {
+		s.mu.Unlock()
+		return nil
+	}
+	start := max(s.virtual, f.finish)
+	f.finish = start + 1/f.weight
+	request := &shareRequest{start: start, seq: s.seq, granted: make(chan struct{})}
+	s.seq++
+	heap.Push(&s.queue, request)
+
+	for {
+		wait := s.grant()
+		if request.done {
+			s.mu.Unlock()
+			return nil
+		}
+		s.mu.Unlock()
+
+		timer := s.config.Clock.NewTimer(wait)
+		select {
+		case <-request.granted:
+			timer.Stop()
+			return nil
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			s.mu.Lock()
+			if request.done {
+				// Give back the event granted meanwhile
+				s.tokens++
+			}
+			request.cancelled = true
+			s.mu.Unlock()
+			return ctx.Err()
+		}
+		s.mu.Lock()
+	}
+}
+
+// SetRate changes the rate shared by the runs (0 = unlimited), e.g. to
+// slow down streaming under memory pressure
+func (s *SharedFlowController) SetRate(rate float64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.config.Rate > 0 {
+		s.grant()
+	}
+	s.config.Rate = rate
+	s.refilled = s.config.Clock.Now()
+	if rate <= 0 {
+		for s.queue.Len() > 0 {
+			request := heap.Pop(&s.queue).(*shareRequest)
+			request.done = true
+			close(request.granted)
+		}
+	}
+}
+
+// Rate returns the rate shared by the runs (0 = unlimited)
+func (s *SharedFlowController) Rate() float64 {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.config.Rate
+}
+
+// grant refills the rate and grants the requests it covers, in order,
+// returning the wait until the next event of the rate; callers must hold mu
+func (s *SharedFlowController) grant() time.Duration {
+	now := s.config.Clock.Now()
+	if elapsed := now.Sub(s.refilled); elapsed > 0 {
+		s.tokens = min(float64(s.config.Burst), s.tokens+elapsed.Seconds()*s.config.Rate)
+		s.refilled = now
+	}
+	for s.queue.Len() > 0 && s.tokens >= 1 {
+		request := heap.Pop(&s.queue).(*shareRequest)
+		if request.cancelled {
+			continue
+		}
+		s.tokens--
+		s.virtual = request.start
+		request.done = true
+		close(request.granted)
+	}
+	return time.Duration((1 - s.tokens) / s.config.Rate * float64(time.Second))
+}
+
+type flowControlKey struct{}
+
+type flowWeightKey struct{}
+
+// WithFlowWeight returns a copy of ctx giving the run it starts weight
+// weight in the SharedFlowController of the run manager (see
+// RunManagerConfig.SharedFlow), runs weighing 1 by default. A run of weight
+// 2 streams twice as fast as a run of weight 1 while they share the rate.
+func WithFlowWeight(ctx context.Context, weight float64) context.Context {
+	return context.WithValue(ctx, flowWeightKey{}, weight)
+}
+
+// flowWeight returns the weight of the run started with ctx
+func flowWeight(ctx context.Context) float64 {
+	weight, _ := ctx.Value(flowWeightKey{}).(float64)
+	return weight
+}
+
+// WithFlowControl returns a copy of ctx metering the run it starts with
+// flow (see FlowController)
+func WithFlowControl(ctx context.Context, flow *FlowController) context.Context {
+	return context.WithValue(ctx, flowControlKey{}, flow)
+}
+
+// flowControl returns the flow controller of the run started with ctx, if
+// any
+func flowControl(ctx context.Context) *FlowController {
+	flow, _ := ctx.Value(flowControlKey{}).(*FlowController)
+	return flow
+}
+
+// requestedFlowControl returns the flow controller for the window a run
+// request opens with events.FlowWindowHeader, nil when it opens none
+func requestedFlowControl(r *http.Request) (*FlowController, error) {
+	value := r.Header.Get(events.FlowWindowHeader)
+	if value == "" {
+		return nil, nil
+	}
+	window, err := events.ParseFlowWindow(value)
+	if err != nil {
+		return nil, err
+	}
+	return NewFlowController(window), nil
+}
+
+// flowControlRunID returns the run of a POST request for runs/{id}/flow,
+// reporting false for other paths
+func flowControlRunID(r *http.Request) (string, bool) {
+	dir, name := path.Split(strings.TrimSuffix(r.URL.Path, "/"))
+	if name != "flow" {
+		return "", false
+	}
+	dir, runID := path.Split(strings.TrimSuffix(dir, "/"))
+	return runID, runID != "" && path.Base(dir) == "runs"
+}
+
+// grantCredit handles a POST request for runs/{id}/flow carrying a flow
+// control event
+func (s *Server) grantCredit(w http.ResponseWriter, r *http.Request, runID string) {
+	var event events.CustomEvent
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.config.MaxBodyBytes)).Decode(&event); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	grant, ok, err := events.FlowControlOf(&event)
+	if !ok {
+		err = errors.New("not a flow control event")
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	flow, err := s.runs.FlowControl(r.Context(), runID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	flow.Grant(grant.Credit)
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/testhelper"
+)
+
+func TestFlowController(t *testing.T) {
+	flow := NewFlowController(1)
+	require.NoError(t, flow.acquire(context.Background()))
+	assert.Equal(t, 0, flow.Credit())
+
+	acquired := make(chan error)
+	go func() { acquired <- flow.acquire(context.Background()) }()
+	select {
+	case <-acquired:
+		t.Fatal("acquired without credit")
+	case <-time.After(20 * time.Millisecond):
+	}
+	flow.Grant(2)
+	require.NoError(t, <-acquired)
+	assert.Equal(t, 1, flow.Credit())
+
+	flow.Reset(4)
+	assert.Equal(t, 4, flow.Credit())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	flow.Reset(0)
+	assert.ErrorIs(t, flow.acquire(ctx), context.Canceled)
+}
+
+func flowRequest(runID string, credit int) *http.Request {
+	data, _ := events.NewFlowControlEvent(credit).ToJSON()
+	return httptest.NewRequest(http.MethodPost, "/agent/runs/"+runID+"/flow", strings.NewReader(string(data)))
+}
+
+func TestServerFlowControl(t *testing.T) {
+	var streamed atomic.Int32
+	srv := NewServer(AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, emitter *EventEmitter) error {
+		messageID, err := emitter.StartTextMessage(ctx, "assistant")
+		if err != nil {
+			return err
+		}
+		for range 5 {
+			if err := emitter.EmitContent(ctx, messageID, "token"); err != nil {
+				return err
+			}
+			streamed.Add(1)
+		}
+		return emitter.EndTextMessage(ctx, messageID)
+	}), Config{})
+
+	req := httptest.NewRequest(http.MethodPost, "/agent", strings.NewReader(`{"threadId":"thread-1","runId":"run-1"}`))
+	req.Header.Set(events.FlowWindowHeader, "2")
+	rec := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		srv.ServeHTTP(rec, req)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool { return streamed.Load() == 2 }, 5*time.Second, time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("the run went on without credit")
+	case <-time.After(20 * time.Millisecond):
+	}
+	assert.Equal(t, int32(2), streamed.Load(), "the agent waits for credit")
+
+	grant := httptest.NewRecorder()
+	srv.ServeHTTP(grant, flowRequest("run-1", 3))
+	assert.Equal(t, http.StatusAccepted, grant.Code)
+	<-done
+	assert.Equal(t, 5, strings.Count(rec.Body.String(), `"type":"TEXT_MESSAGE_CONTENT"`))
+	assert.Contains(t, rec.Body.String(), "RUN_FINISHED")
+	assert.Contains(t, rec.Header().Get(events.ExtensionsHeader), events.ExtensionFlowControl)
+
+	t.Run("rejected grants", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, flowRequest("run-1", 1))
+		assert.Equal(t, http.StatusNotFound, rec.Code, "the run has ended")
+
+		rec = httptest.NewRecorder()
+		srv.ServeHTTP(rec, flowRequest("run-1", 0))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		rec = httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/agent/runs/run-1/flow", strings.NewReader(`{"type":"CUSTOM","name":"usage"}`)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("invalid window", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/agent", strings.NewReader(`{"threadId":"thread-1","runId":"run-2"}`))
+		req.Header.Set(events.FlowWindowHeader, "0")
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestSharedFlowBurst(t *testing.T) {
+	clk := testhelper.NewFakeClock(time.Unix(0, 0))
+	share := NewSharedFlowController(SharedFlowConfig{Rate: 1, Burst: 3, Clock: clk}).Join(0)
+	ctx := context.Background()
+	for range 3 {
+		require.NoError(t, share.acquire(ctx))
+	}
+
+	acquired := make(chan error, 1)
+	go func() { acquired <- share.acquire(ctx) }()
+	clk.BlockUntil(1)
+	select {
+	case <-acquired:
+		t.Fatal("acquired beyond the burst")
+	default:
+	}
+	clk.Advance(time.Second)
+	require.NoError(t, <-acquired)
+}
+
+func TestSharedFlowCancellation(t *testing.T) {
+	clk := testhelper.NewFakeClock(time.Unix(0, 0))
+	shared := NewSharedFlowController(SharedFlowConfig{Rate: 1, Clock: clk})
+	require.NoError(t, shared.Join(1).acquire(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	acquired := make(chan error, 1)
+	go func() { acquired <- shared.Join(1).acquire(ctx) }()
+	clk.BlockUntil(1)
+	cancel()
+	assert.ErrorIs(t, <-acquired, context.Canceled)
+
+	// The cancelled request does not take the next event
+	clk.Advance(time.Second)
+	require.NoError(t, shared.Join(1).acquire(context.Background()))
+}
+
+func TestSharedFlowSetRate(t *testing.T) {
+	clk := testhelper.NewFakeClock(time.Unix(0, 0))
+	shared := NewSharedFlowController(SharedFlowConfig{Rate: 1, Clock: clk})
+	require.NoError(t, shared.Join(1).acquire(context.Background()))
+
+	acquired := make(chan error, 1)
+	go func() { acquired <- shared.Join(1).acquire(context.Background()) }()
+	clk.BlockUntil(1)
+	shared.SetRate(0)
+	require.NoError(t, <-acquired, "an unlimited rate grants the waiting requests")
+	assert.Zero(t, shared.Rate())
+
+	shared.SetRate(2)
+	assert.Equal(t, 2.0, shared.Rate())
+	go func() { acquired <- shared.Join(1).acquire(context.Background()) }()
+	clk.BlockUntil(1)
+	clk.Advance(500 * time.Millisecond)
+	require.NoError(t, <-acquired, "the new rate refills twice as fast")
+
+	var none *SharedFlowController
+	none.SetRate(1)
+	assert.Zero(t, none.Rate())
+}
+
+func TestSharedFlowFairness(t *testing.T) {
+	shared := NewSharedFlowController(SharedFlowConfig{Rate: 2000})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A heavy export and an interactive run weighing three times as much
+	// compete for the rate
+	var export, interactive atomic.Int32
+	for _, run := range []struct {
+		weight  float64
+		counter *atomic.Int32
+	}{{1, &export}, {3, &interactive}} {
+		share := shared.Join(run.weight)
+		go func() {
+			for share.acquire(ctx) == nil {
+				run.counter.Add(1)
+			}
+		}()
+	}
+	require.Eventually(t, func() bool { return export.Load()+interactive.Load() >= 400 }, 5*time.Second, time.Millisecond)
+	cancel()
+	ratio := float64(interactive.Load()) / float64(export.Load())
+	assert.InDelta(t, 3, ratio, 0.5, "interactive %d, export %d", interactive.Load(), export.Load())
+}
+
+func TestRunManagerSharedFlow(t *testing.T) {
+	clk := testhelper.NewFakeClock(time.Unix(0, 0))
+	var streamed atomic.Int32
+	agent := AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, emitter *EventEmitter) error {
+		messageID, err := emitter.StartTextMessage(ctx, "assistant")
+		if err != nil {
+			return err
+		}
+		for range 2 {
+			if err := emitter.EmitContent(ctx, messageID, "token"); err != nil {
+				return err
+			}
+			streamed.Add(1)
+		}
+		return emitter.EndTextMessage(ctx, messageID)
+	})
+	manager := NewRunManager(agent, RunManagerConfig{
+		SharedFlow: NewSharedFlowController(SharedFlowConfig{Rate: 1, Clock: clk}),
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- manager.Run(WithFlowWeight(context.Background(), 2), newTestInput(), &recordingEmitter{})
+	}()
+	clk.BlockUntil(1)
+	assert.Equal(t, int32(1), streamed.Load(), "the second token waits for the rate")
+	clk.Advance(time.Second)
+	require.NoError(t, <-done)
+	assert.Equal(t, int32(2), streamed.Load())
+}
@@ -0,0 +1,187 @@
+// Package gateway bridges AG-UI transports. Its agents forward each run to an
+// upstream agent over gRPC or HTTP and Server-Sent Events, so that a
+// server.Server or grpcserver.Service in front of them translates between
+// SSE+JSON and gRPC+protobuf. Forwarded events go through the run manager
+// like those of any agent: they are validated against the protocol rules,
+// and run limits, timeouts and shutdown apply. cmd/agui-gateway serves them.
+//
+// The run manager emits RUN_STARTED and RUN_FINISHED itself, so the result
+// and outcome of an upstream RUN_FINISHED are not passed on. An upstream
+// RUN_ERROR ends the run with the same code and message.
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/proto/pb"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server"
+)
+
+// ErrIncomplete ends a run whose upstream stream closed without a
+// terminal event
+var ErrIncomplete = errors.New("upstream stream ended without RUN_FINISHED")
+
+// Config configures the forwarding of runs
+type Config struct {
+	// Headers returns the HTTP headers or gRPC metadata sent upstream with
+	// a run, e.g. to pass on the caller found by the authenticator
+	// (server.PrincipalFromContext). Nil sends none.
+	Headers func(ctx context.Context, input *types.RunAgentInput) map[string]string
+}
+
+func (c Config) headers(ctx context.Context, input *types.RunAgentInput) map[string]string {
+	if c.Headers == nil {
+		return nil
+	}
+	return c.Headers(ctx, input)
+}
+
+// NewGRPCAgent creates an agent forwarding runs to an AgentService. Upstream
+// events failing to decode are diagnosed as a schema drift when the upstream
+// announces another pb.SchemaHash.
+func NewGRPCAgent(client pb.AgentServiceClient, config Config) server.Agent {
+	return server.AgentFunc(func(ctx context.Context, input *types.RunAgentInput, emitter *server.EventEmitter) error {
+		req, err := pb.NewRunAgentRequest(input)
+		if err != nil {
+			return err
+		}
+		md := metadata.New(config.headers(ctx, input))
+		md.Set(pb.SchemaHeader, pb.SchemaHash())
+		ctx = metadata.NewOutgoingContext(ctx, md)
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		stream, err := client.RunAgent(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to reach upstream: %w", err)
+		}
+		if err := stream.Send(req); err != nil {
+			return fmt.Errorf("failed to send run input upstream: %w", err)
+		}
+		if err := stream.CloseSend(); err != nil {
+			return err
+		}
+
+		var f forwarder
+		for {
+			resp, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return f.end()
+			}
+			if err != nil {
+				return fmt.Errorf("upstream stream failed: %w", err)
+			}
+			event, err := pb.ResponseEvent(resp)
+			if err != nil {
+				return fmt.Errorf("invalid upstream event: %w", diagnose(stream, err))
+			}
+			if done, err := f.forward(ctx, emitter, event); done {
+				return err
+			}
+		}
+	})
+}
+
+// diagnose explains err, a failure to decode an upstream event, by a schema
+// drift when the upstream announced another schema
+func diagnose(stream grpc.ClientStream, err error) error {
+	md, mdErr := stream.Header()
+	if mdErr != nil {
+		return err
+	}
+	var schema string
+	if schemas := md.Get(pb.SchemaHeader); len(schemas) > 0 {
+		schema = schemas[0]
+	}
+	return pb.DiagnoseDecodeError(err, schema, "upstream server")
+}
+
+// NewSSEAgent creates an agent forwarding runs to an HTTP endpoint
+// streaming Server-Sent Events, as configured in client
+func NewSSEAgent(client *sse.Client, config Config) server.Agent {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	decoder := events.NewEventDecoder(logger)
+
+	return server.AgentFunc(func(ctx context.Context, input *types.RunAgentInput, emitter *server.EventEmitter) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		frames, errs, err := client.Stream(sse.StreamOptions{Context: ctx, Payload: *input, Headers: config.headers(ctx, input)})
+		if err != nil {
+			return fmt.Errorf("failed to reach upstream: %w", err)
+		}
+
+		var f forwarder
+		for frames != nil || errs != nil {
+			select {
+			case frame, ok := <-frames:
+				if !ok {
+					frames = nil
+					continue
+				}
+				event, err := decoder.Decode(frame.Data)
+				if err != nil {
+					return fmt.Errorf("invalid upstream event: %w", err)
+				}
+				if done, err := f.forward(ctx, emitter, event); done {
+					return err
+				}
+			case err, ok := <-errs:
+				if !ok {
+					errs = nil
+					continue
+				}
+				if err != nil {
+					return fmt.Errorf("upstream stream failed: %w", err)
+				}
+			}
+		}
+		return f.end()
+	})
+}
+
+// forwarder relays the events of an upstream run, leaving the lifecycle
+// events to the run manager
+type forwarder struct {
+	finished bool
+}
+
+// forward relays event, reporting whether the run is over and how it ended
+func (f *forwarder) forward(ctx context.Context, emitter *server.EventEmitter, event events.Event) (bool, error) {
+	switch e := event.(type) {
+	case *events.RunStartedEvent:
+		return false, nil
+	case *events.RunFinishedEvent:
+		f.finished = true
+		return true, nil
+	case *events.RunErrorEvent:
+		runErr := &server.RunError{Message: e.Message}
+		if e.Code != nil {
+			runErr.Code = *e.Code
+		}
+		return true, runErr
+	}
+	if err := emitter.Emit(ctx, event); err != nil {
+		return true, err
+	}
+	return false, nil
+}
+
+// end returns how a run ends when its upstream stream closes
+func (f *forwarder) end() error {
+	if !f.finished {
+		return ErrIncomplete
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/proto/pb"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server/grpcserver"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/testhelper"
+)
+
+// serveGRPC serves svc over an in-memory connection
+func serveGRPC(t *testing.T, svc *grpcserver.Service) pb.AgentServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pb.RegisterAgentServiceServer(srv, svc)
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return pb.NewAgentServiceClient(conn)
+}
+
+func newSSEClient(endpoint string) *sse.Client {
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	return sse.NewClient(sse.Config{Endpoint: endpoint, Logger: logger})
+}
+
+func newInput() *types.RunAgentInput {
+	return &types.RunAgentInput{
+		ThreadID:       "thread-1",
+		RunID:          "run-1",
+		State:          map[string]any{},
+		Messages:       []types.Message{},
+		Tools:          []types.Tool{},
+		Context:        []types.Context{},
+		ForwardedProps: map[string]any{},
+	}
+}
+
+// forwardingHeaders sends the caller's principal upstream
+var forwardingHeaders = Config{Headers: func(ctx context.Context, _ *types.RunAgentInput) map[string]string {
+	if principal, ok := server.PrincipalFromContext(ctx); ok {
+		return map[string]string{"x-principal": principal.ID}
+	}
+	return nil
+}}
+
+// run runs agent through a run manager, returning the events it emits
+func run(t *testing.T, agent server.Agent) ([]events.Event, error) {
+	t.Helper()
+	var mu sync.Mutex
+	var got []events.Event
+	err := server.NewRunManager(agent, server.RunManagerConfig{}).Run(context.Background(), newInput(),
+		server.EmitterFunc(func(_ context.Context, event events.Event) error {
+			mu.Lock()
+			defer mu.Unlock()
+			got = append(got, event)
+			return nil
+		}))
+	return got, err
+}
+
+func eventTypes(seq []events.Event) []events.EventType {
+	got := make([]events.EventType, len(seq))
+	for i, event := range seq {
+		got[i] = event.Type()
+	}
+	return got
+}
+
+func TestSSEToGRPC(t *testing.T) {
+	var principal []string
+	upstream := serveGRPC(t, grpcserver.NewService(server.AgentFunc(func(ctx context.Context, input *types.RunAgentInput, emitter *server.EventEmitter) error {
+		md, _ := metadata.FromIncomingContext(ctx)
+		principal = md.Get("x-principal")
+		id, err := emitter.StartTextMessage(ctx, "assistant")
+		if err != nil {
+			return err
+		}
+		if err := emitter.EmitContent(ctx, id, "hello from "+input.RunID); err != nil {
+			return err
+		}
+		if err := emitter.EndTextMessage(ctx, id); err != nil {
+			return err
+		}
+		return emitter.EmitCustom(ctx, "progress", map[string]any{"done": true})
+	}), server.RunManagerConfig{}))
+
+	gateway := server.Chain(
+		server.NewServer(NewGRPCAgent(upstream, forwardingHeaders), server.Config{}),
+		server.NewMiddleware(server.MiddlewareConfig{
+			Authenticator: server.NewAPIKeyAuthenticator("X-API-Key", map[string]string{"key-a": "alice"}),
+		})...,
+	)
+	front := httptest.NewServer(gateway)
+	defer front.Close()
+
+	client := newSSEClient(front.URL)
+	defer client.Close()
+	frames, errs, err := client.Stream(sse.StreamOptions{Context: context.Background(), Payload: *newInput(), Headers: map[string]string{"X-API-Key": "key-a"}})
+	require.NoError(t, err)
+	seq, err := testhelper.Capture(t, frames, errs)
+	require.NoError(t, err)
+
+	assert.Equal(t, []events.EventType{
+		events.EventTypeRunStarted,
+		events.EventTypeTextMessageStart,
+		events.EventTypeTextMessageContent,
+		events.EventTypeTextMessageEnd,
+		events.EventTypeCustom,
+		events.EventTypeRunFinished,
+	}, eventTypes(seq))
+	assert.Equal(t, "hello from run-1", seq[2].(*events.TextMessageContentEvent).Delta)
+	assert.Equal(t, "run-1", seq[5].(*events.RunFinishedEvent).RunID())
+	assert.Equal(t, []string{"alice"}, principal)
+
+	_, _, err = client.Stream(sse.StreamOptions{Context: context.Background(), Payload: *newInput()})
+	require.Error(t, err, "the gateway authenticates callers")
+	assert.Contains(t, err.Error(), "401")
+}
+
+func TestGRPCToSSE(t *testing.T) {
+	upstream := testhelper.NewMockAGUIServer()
+	defer upstream.Close()
+	upstream.Script(testhelper.TextReply("thread-1", "run-1", "hi")...)
+	sseClient := newSSEClient(upstream.Endpoint())
+	defer sseClient.Close()
+
+	client := serveGRPC(t, grpcserver.NewService(NewSSEAgent(sseClient, Config{
+		Headers: func(context.Context, *types.RunAgentInput) map[string]string {
+			return map[string]string{"X-Tenant": "acme"}
+		},
+	}), server.RunManagerConfig{}))
+
+	stream, err := client.RunAgent(context.Background())
+	require.NoError(t, err)
+	data, err := json.Marshal(newInput())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pb.RunAgentRequest{Request: &pb.RunAgentRequest_Input{Input: data}}))
+
+	var seq []events.Event
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		event, err := pb.ResponseEvent(resp)
+		require.NoError(t, err)
+		seq = append(seq, event)
+	}
+
+	assert.Equal(t, []events.EventType{
+		events.EventTypeRunStarted,
+		events.EventTypeTextMessageStart,
+		events.EventTypeTextMessageContent,
+		events.EventTypeTextMessageEnd,
+		events.EventTypeRunFinished,
+	}, eventTypes(seq))
+	assert.Equal(t, "hi", seq[2].(*events.TextMessageContentEvent).Delta)
+	requests := upstream.Requests()
+	require.Len(t, requests, 1)
+	assert.Equal(t, "acme", requests[0].Header.Get("X-Tenant"))
+	assert.Equal(t, "run-1", requests[0].Input.RunID)
+}
+
+func TestUpstreamFailures(t *testing.T) {
+	upstream := testhelper.NewMockAGUIServer()
+	defer upstream.Close()
+	client := newSSEClient(upstream.Endpoint())
+	defer client.Close()
+	agent := NewSSEAgent(client, Config{})
+
+	lastError := func(seq []events.Event) *events.RunErrorEvent {
+		t.Helper()
+		require.NotEmpty(t, seq)
+		runErr, ok := seq[len(seq)-1].(*events.RunErrorEvent)
+		require.True(t, ok, "the run ends with RUN_ERROR")
+		return runErr
+	}
+
+	t.Run("run error keeps its code", func(t *testing.T) {
+		upstream.Script(
+			testhelper.Emit(events.NewRunStartedEvent("thread-1", "run-1")),
+			testhelper.Emit(events.NewRunErrorEvent("out of tokens", events.WithErrorCode("QUOTA"))),
+		)
+		seq, err := run(t, agent)
+		require.Error(t, err)
+		runErr := lastError(seq)
+		assert.Equal(t, "QUOTA", *runErr.Code)
+		assert.Equal(t, "out of tokens", runErr.Message)
+	})
+
+	t.Run("invalid sequence", func(t *testing.T) {
+		upstream.Script(
+			testhelper.Emit(events.NewRunStartedEvent("thread-1", "run-1")),
+			testhelper.Emit(events.NewTextMessageContentEvent("never-started", "hi")),
+			testhelper.Emit(events.NewRunFinishedEvent("thread-1", "run-1")),
+		)
+		seq, err := run(t, agent)
+		require.ErrorIs(t, err, server.ErrInvalidSequence)
+		assert.Equal(t, server.RunErrorCodeAgentError, *lastError(seq).Code)
+	})
+
+	t.Run("incomplete stream", func(t *testing.T) {
+		upstream.Script(testhelper.Emit(events.NewRunStartedEvent("thread-1", "run-1")))
+		seq, err := run(t, agent)
+		require.ErrorIs(t, err, ErrIncomplete)
+		assert.Equal(t, ErrIncomplete.Error(), lastError(seq).Message)
+	})
+
+	t.Run("upstream unavailable", func(t *testing.T) {
+		upstream.Script(testhelper.Fail(503))
+		_, err := run(t, agent)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to reach upstream")
+	})
+}
+
+// driftedUpstream announces another schema and streams undecodable events
+type driftedUpstream struct {
+	pb.UnimplementedAgentServiceServer
+}
+
+func (driftedUpstream) RunAgent(stream pb.AgentService_RunAgentServer) error {
+	if _, err := stream.Recv(); err != nil {
+		return err
+	}
+	if err := stream.SetHeader(metadata.Pairs(pb.SchemaHeader, "drifted")); err != nil {
+		return err
+	}
+	return stream.Send(&pb.RunAgentResponse{Response: &pb.RunAgentResponse_JsonEvent{JsonEvent: []byte(`{"type":"NEW_EVENT_TYPE"}`)}})
+}
+
+func TestGRPCUpstreamSchemaDrift(t *testing.T) {
+	listener := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pb.RegisterAgentServiceServer(srv, driftedUpstream{})
+	go func() { _ = srv.Serve(listener) }()
+	defer srv.Stop()
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = run(t, NewGRPCAgent(pb.NewAgentServiceClient(conn), Config{}))
+	require.ErrorIs(t, err, pb.ErrSchemaDrift)
+	assert.Contains(t, err.Error(), "upstream server has schema drifted")
+}
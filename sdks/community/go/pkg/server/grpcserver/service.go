@@ -0,0 +1,122 @@
+// Package grpcserver serves agents over the gRPC AgentService defined in
+// pkg/proto, as an alternative to HTTP and Server-Sent Events. Runs go through
+// the same server.RunManager, so agents work unchanged on either transport.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/logging"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/proto/pb"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server"
+)
+
+// Service implements pb.AgentServiceServer on top of a server.RunManager
+type Service struct {
+	pb.UnimplementedAgentServiceServer
+
+	runs   *server.RunManager
+	logger *slog.Logger
+	budget *encoding.SizeBudget
+}
+
+// NewService creates a gRPC agent service for the given agent.
+// Register it with pb.RegisterAgentServiceServer.
+func NewService(agent server.Agent, config server.RunManagerConfig) *Service {
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+	return &Service{
+		runs:   server.NewRunManager(agent, config),
+		logger: logging.ContextLogger(logging.ForComponent(config.Logger, "grpc_service")),
+		budget: config.SizeBudget,
+	}
+}
+
+// RunManager returns the run manager used by the service
+func (s *Service) RunManager() *server.RunManager {
+	return s.runs
+}
+
+// RunAgent handles a single run. The first request must carry the run input;
+// a later cancel request cancels the run, which then ends with RUN_ERROR.
+// The response headers carry the pb.SchemaHash of the service under
+// pb.SchemaHeader.
+func (s *Service) RunAgent(stream pb.AgentService_RunAgentServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if req.GetInput() == nil {
+		return status.Error(codes.InvalidArgument, "first request must carry the run input")
+	}
+	input, err := pb.RequestInput(req)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid run input: %v", err)
+	}
+
+	// Announce the schema of the service, and diagnose the clients built
+	// from other .proto files
+	if err := stream.SetHeader(metadata.Pairs(pb.SchemaHeader, pb.SchemaHash())); err != nil {
+		return err
+	}
+	if md, ok := metadata.FromIncomingContext(stream.Context()); ok {
+		if schemas := md.Get(pb.SchemaHeader); len(schemas) > 0 {
+			if err := pb.CheckSchema(schemas[0], "client"); err != nil {
+				s.logger.Warn("Client built from another protobuf schema", logging.KeyRunID, input.RunID, "error", err)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancelCause(stream.Context())
+	defer cancel(nil)
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			if req.GetCancel() != nil {
+				s.logger.Debug("Run cancelled by client", logging.KeyRunID, input.RunID, "reason", req.GetCancel().GetReason())
+				cancel(server.ErrRunCancelled)
+				return
+			}
+		}
+	}()
+
+	emitter := server.EmitterFunc(func(_ context.Context, event events.Event) error {
+		resp, err := pb.NewRunAgentResponse(event)
+		if err != nil {
+			return err
+		}
+		if err := s.budget.Check(event.Type(), proto.Size(resp)); err != nil {
+			return err
+		}
+		return stream.Send(resp)
+	})
+
+	err = s.runs.Run(ctx, input, emitter)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, server.ErrTooManyRuns):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, server.ErrRunAlreadyActive):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, server.ErrShedding), errors.Is(err, server.ErrShuttingDown):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		// The run was started and the stream carries its RUN_ERROR event
+		s.logger.DebugContext(ctx, "Run ended with error", "error", err)
+		return nil
+	}
+}
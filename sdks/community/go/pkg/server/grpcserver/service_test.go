@@ -0,0 +1,141 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/proto/pb"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server"
+)
+
+// newTestClient serves svc over an in-memory connection
+func newTestClient(t *testing.T, svc *Service) pb.AgentServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pb.RegisterAgentServiceServer(srv, svc)
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return pb.NewAgentServiceClient(conn)
+}
+
+func startRun(t *testing.T, client pb.AgentServiceClient, input *types.RunAgentInput) pb.AgentService_RunAgentClient {
+	t.Helper()
+	stream, err := client.RunAgent(context.Background())
+	require.NoError(t, err)
+	data, err := json.Marshal(input)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pb.RunAgentRequest{Request: &pb.RunAgentRequest_Input{Input: data}}))
+	return stream
+}
+
+func receiveAll(t *testing.T, stream pb.AgentService_RunAgentClient) ([]events.Event, error) {
+	t.Helper()
+	var received []events.Event
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return received, nil
+		}
+		if err != nil {
+			return received, err
+		}
+		event, err := pb.ResponseEvent(resp)
+		require.NoError(t, err)
+		received = append(received, event)
+	}
+}
+
+func TestRunAgent(t *testing.T) {
+	agent := server.AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, emitter *server.EventEmitter) error {
+		messageID, err := emitter.StartTextMessage(ctx, "assistant")
+		if err != nil {
+			return err
+		}
+		if err := emitter.EmitContent(ctx, messageID, "Hello"); err != nil {
+			return err
+		}
+		return emitter.EndTextMessage(ctx, messageID)
+	})
+	client := newTestClient(t, NewService(agent, server.RunManagerConfig{}))
+
+	stream := startRun(t, client, &types.RunAgentInput{ThreadID: "thread-1", RunID: "run-1"})
+	received, err := receiveAll(t, stream)
+	require.NoError(t, err)
+
+	var eventTypes []events.EventType
+	for _, event := range received {
+		eventTypes = append(eventTypes, event.Type())
+	}
+	assert.Equal(t, []events.EventType{
+		events.EventTypeRunStarted,
+		events.EventTypeTextMessageStart,
+		events.EventTypeTextMessageContent,
+		events.EventTypeTextMessageEnd,
+		events.EventTypeRunFinished,
+	}, eventTypes)
+	assert.Equal(t, "Hello", received[2].(*events.TextMessageContentEvent).Delta)
+}
+
+func TestRunAgentAnnouncesSchema(t *testing.T) {
+	agent := server.AgentFunc(func(context.Context, *types.RunAgentInput, *server.EventEmitter) error { return nil })
+	client := newTestClient(t, NewService(agent, server.RunManagerConfig{}))
+
+	stream := startRun(t, client, &types.RunAgentInput{ThreadID: "thread-1", RunID: "run-1"})
+	header, err := stream.Header()
+	require.NoError(t, err)
+	assert.Equal(t, []string{pb.SchemaHash()}, header.Get(pb.SchemaHeader))
+	_, err = receiveAll(t, stream)
+	require.NoError(t, err)
+}
+
+func TestRunAgentCancel(t *testing.T) {
+	started := make(chan struct{})
+	agent := server.AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, _ *server.EventEmitter) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	client := newTestClient(t, NewService(agent, server.RunManagerConfig{}))
+
+	stream := startRun(t, client, &types.RunAgentInput{ThreadID: "thread-1", RunID: "run-1"})
+	<-started
+	require.NoError(t, stream.Send(&pb.RunAgentRequest{Request: &pb.RunAgentRequest_Cancel{Cancel: &pb.CancelRun{}}}))
+
+	received, err := receiveAll(t, stream)
+	require.NoError(t, err)
+	runErr, ok := received[len(received)-1].(*events.RunErrorEvent)
+	require.True(t, ok)
+	assert.Equal(t, server.RunErrorCodeCancelled, *runErr.Code)
+}
+
+func TestRunAgentRejectsMissingInput(t *testing.T) {
+	client := newTestClient(t, NewService(server.AgentFunc(nil), server.RunManagerConfig{}))
+
+	stream, err := client.RunAgent(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pb.RunAgentRequest{Request: &pb.RunAgentRequest_Cancel{Cancel: &pb.CancelRun{}}}))
+
+	_, err = receiveAll(t, stream)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
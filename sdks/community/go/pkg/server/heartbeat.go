@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// MinHeartbeat is the shortest heartbeat interval a client may request
+const MinHeartbeat = time.Second
+
+type heartbeatKey struct{}
+
+// WithHeartbeat returns a copy of ctx requesting heartbeats at interval for
+// the run it starts, overriding RunManagerConfig.Heartbeat (0 = none)
+func WithHeartbeat(ctx context.Context, interval time.Duration) context.Context {
+	return context.WithValue(ctx, heartbeatKey{}, interval)
+}
+
+// heartbeatInterval returns the heartbeat interval of the run started with
+// ctx
+func (m *RunManager) heartbeatInterval(ctx context.Context) time.Duration {
+	if interval, ok := ctx.Value(heartbeatKey{}).(time.Duration); ok {
+		return interval
+	}
+	return m.config.Heartbeat
+}
+
+// requestedHeartbeat parses the heartbeat interval requested with
+// events.HeartbeatHeader, in seconds, raising it to MinHeartbeat
+func requestedHeartbeat(r *http.Request) (time.Duration, bool, error) {
+	value := r.Header.Get(events.HeartbeatHeader)
+	if value == "" {
+		return 0, false, nil
+	}
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds < 0 || math.IsInf(seconds, 0) || math.IsNaN(seconds) {
+		return 0, false, fmt.Errorf("invalid %s header %q", events.HeartbeatHeader, value)
+	}
+	if seconds == 0 {
+		return 0, true, nil
+	}
+	return max(time.Duration(seconds*float64(time.Second)), MinHeartbeat), true, nil
+}
+
+// startHeartbeat emits a heartbeat every interval during which the run
+// emitted nothing, until the returned function is called
+func (m *RunManager) startHeartbeat(ctx context.Context, emitter *EventEmitter, interval time.Duration) func() {
+	emitter.resetHeartbeat()
+	ticker := m.config.Clock.NewTicker(interval)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C():
+				if err := emitter.heartbeat(ctx, interval); err != nil {
+					m.logger.DebugContext(ctx, "Failed to emit heartbeat", "error", err)
+					return
+				}
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
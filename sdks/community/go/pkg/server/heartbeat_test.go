@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/testhelper"
+)
+
+// channelTap passes the events of runs to a channel
+func channelTap(ch chan<- events.Event) Tap {
+	return func(_ context.Context, _ *types.RunAgentInput, emitter Emitter) Emitter {
+		return EmitterFunc(func(ctx context.Context, event events.Event) error {
+			ch <- event
+			return emitter.Emit(ctx, event)
+		})
+	}
+}
+
+func requireHeartbeat(t *testing.T, event events.Event, interval time.Duration) {
+	t.Helper()
+	got, ok := events.HeartbeatInterval(event)
+	require.True(t, ok, "expected a heartbeat, got %s", event.Type())
+	assert.Equal(t, interval, got)
+}
+
+func TestRunManagerHeartbeat(t *testing.T) {
+	clk := testhelper.NewFakeClock(time.Unix(0, 0))
+	release := make(chan struct{})
+	agent := AgentFunc(func(context.Context, *types.RunAgentInput, *EventEmitter) error {
+		<-release
+		return nil
+	})
+	seen := make(chan events.Event, 10)
+	manager := NewRunManager(agent, RunManagerConfig{Clock: clk, Heartbeat: time.Minute, Tap: channelTap(seen)})
+
+	done := make(chan error, 1)
+	go func() {
+		ctx := WithHeartbeat(context.Background(), 10*time.Second)
+		done <- manager.Run(ctx, newTestInput(), &recordingEmitter{})
+	}()
+	assert.Equal(t, events.EventTypeRunStarted, (<-seen).Type())
+
+	clk.BlockUntil(1)
+	clk.Advance(10 * time.Second)
+	requireHeartbeat(t, <-seen, 10*time.Second)
+	clk.Advance(10 * time.Second)
+	requireHeartbeat(t, <-seen, 10*time.Second)
+
+	close(release)
+	require.NoError(t, <-done)
+	assert.Equal(t, events.EventTypeRunFinished, (<-seen).Type())
+	assert.Zero(t, clk.Pending(), "heartbeats stop with the run")
+}
+
+func TestEventEmitterHeartbeat(t *testing.T) {
+	ctx := context.Background()
+	sink := &recordingEmitter{}
+	emitter := newEventEmitter(sink)
+	require.NoError(t, emitter.emitLifecycle(ctx, events.NewRunStartedEvent("thread-1", "run-1")))
+	emitter.resetHeartbeat()
+
+	require.NoError(t, emitter.heartbeat(ctx, time.Second))
+	require.NoError(t, emitter.EmitCustom(ctx, "work", nil))
+	require.NoError(t, emitter.heartbeat(ctx, time.Second), "the run was not silent")
+	require.NoError(t, emitter.heartbeat(ctx, time.Second))
+	emitter.complete()
+	require.NoError(t, emitter.heartbeat(ctx, time.Second))
+
+	require.Len(t, sink.events, 4)
+	requireHeartbeat(t, sink.events[1], time.Second)
+	assert.Equal(t, "work", sink.events[2].(*events.CustomEvent).Name)
+	requireHeartbeat(t, sink.events[3], time.Second)
+}
+
+func TestServerHeartbeatHeader(t *testing.T) {
+	clk := testhelper.NewFakeClock(time.Unix(0, 0))
+	release := make(chan struct{})
+	agent := AgentFunc(func(context.Context, *types.RunAgentInput, *EventEmitter) error {
+		<-release
+		return nil
+	})
+	seen := make(chan events.Event, 10)
+	srv := NewServer(agent, Config{RunManagerConfig: RunManagerConfig{Clock: clk, Tap: channelTap(seen)}})
+
+	request := func(heartbeat string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"threadId":"thread-1","runId":"run-1"}`))
+		req.Header.Set(events.HeartbeatHeader, heartbeat)
+		return req
+	}
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, request("soon"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		srv.ServeHTTP(httptest.NewRecorder(), request("0.2"))
+	}()
+	<-seen
+	clk.BlockUntil(1)
+	clk.Advance(MinHeartbeat)
+	requireHeartbeat(t, <-seen, MinHeartbeat)
+	close(release)
+	<-done
+}
@@ -0,0 +1,465 @@
+package server
+
+import (
+	"container/heap"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/logging"
+)
+
+var (
+	// ErrIdempotencyKeyReused is returned for a request reusing the
+	// idempotency key of a different request
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused for a different request")
+
+	// ErrRunAbandoned cancels the idempotent runs left without callers for
+	// longer than IdempotencyConfig.Grace
+	ErrRunAbandoned = errors.New("run abandoned by its callers")
+)
+
+const (
+	defaultIdempotencyRetention   = 5 * time.Minute
+	defaultIdempotencyGrace       = 30 * time.Second
+	defaultIdempotencyMaxRunBytes = 8 << 20
+	defaultIdempotencyMaxBytes    = 64 << 20
+)
+
+// IdempotencyConfig configures the runs submitted with an idempotency key
+// (see events.IdempotencyKeyHeader). Such a run does not belong to the
+// request that started it: retrying the request with the same key, e.g.
+// after a dropped connection, re-attaches the caller to the run, which
+// replays its events from the start, or from the event following the
+// continuity token the retry presents (see events.ContinuityToken).
+type IdempotencyConfig struct {
+	// Retention is how long the events of a completed run are kept for
+	// callers retrying late (defaults to 5 minutes)
+	Retention time.Duration
+
+	// Grace is how long a run goes on without any caller attached before
+	// it is cancelled with ErrRunAbandoned (defaults to 30 seconds)
+	Grace time.Duration
+
+	// MaxRunBytes bounds the JSON size of the events kept for the retries
+	// of a run (defaults to 8MB). A run going past it, or past MaxBytes,
+	// stops recording: its events are only kept until the attached callers
+	// have streamed them, and retries fail with ErrRunNotResumable. The run
+	// then waits for its callers when they lag behind by more than
+	// MaxRunBytes, like a run without idempotency key.
+	MaxRunBytes int64
+
+	// MaxBytes bounds the JSON size of the events kept for all the runs
+	// (defaults to 64MB)
+	MaxBytes int64
+}
+
+// recordedEvent is an event of an idempotent run with its JSON size
+type recordedEvent struct {
+	event events.Event
+	size  int64
+}
+
+// idempotentRun records the events of a run submitted with an idempotency
+// key for the callers attached to it
+type idempotentRun struct {
+	fingerprint [sha256.Size]byte
+	cancel      context.CancelCauseFunc
+	registry    *idempotencyRegistry
+
+	mu sync.Mutex
+	// events are the recorded events from the one of index base on
+	events []recordedEvent
+	base   int
+	size   int64
+	// truncated is set once the run stops recording for retries; the
+	// events are then dropped once the callers following the run, whose
+	// positions are in cursors, have streamed them
+	truncated bool
+	cursors   map[*int]struct{}
+	// consumed is closed, then replaced, whenever events are dropped
+	consumed chan struct{}
+	// changed is closed, then replaced, whenever an event is recorded or
+	// the run ends
+	changed   chan struct{}
+	done      bool
+	err       error
+	completed time.Time
+	callers   int
+	// detached counts the times the run was left without callers, so that
+	// a grace period only cancels the run it started for
+	detached uint64
+}
+
+// Emit records an event of the run. It only blocks once the run stopped
+// recording, for the callers attached to the run to catch up; departed
+// callers never hold up the run.
+func (run *idempotentRun) Emit(ctx context.Context, event events.Event) error {
+	var size int64
+	if data, err := event.ToJSON(); err == nil {
+		size = int64(len(data))
+	}
+
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	if !run.truncated && (run.size+size > run.registry.config.MaxRunBytes || !run.registry.reserve(size)) {
+		run.truncate()
+	}
+	run.events = append(run.events, recordedEvent{event: event, size: size})
+	run.size += size
+	if run.truncated {
+		run.trim()
+	}
+	run.notify()
+
+	for run.truncated && run.size > run.registry.config.MaxRunBytes {
+		consumed := run.consumed
+		run.mu.Unlock()
+		select {
+		case <-consumed:
+		case <-ctx.Done():
+			run.mu.Lock()
+			return ctx.Err()
+		}
+		run.mu.Lock()
+	}
+	return nil
+}
+
+// truncate stops recording the run for retries, returning its events to
+// the budget of the registry; callers must hold mu
+func (run *idempotentRun) truncate() {
+	if run.truncated {
+		return
+	}
+	run.truncated = true
+	run.registry.release(run.size)
+	run.trim()
+}
+
+// trim drops the events of a truncated run that the callers following it
+// have streamed; callers must hold mu
+func (run *idempotentRun) trim() {
+	keep := run.base + len(run.events)
+	for cursor := range run.cursors {
+		// Callers behind base have lost events already
+		if *cursor >= run.base {
+			keep = min(keep, *cursor)
+		}
+	}
+	if keep == run.base {
+		return
+	}
+	for _, recorded := range run.events[:keep-run.base] {
+		run.size -= recorded.size
+	}
+	// The events are not cleared, callers may still be streaming them
+	run.events = run.events[keep-run.base:]
+	run.base = keep
+	close(run.consumed)
+	run.consumed = make(chan struct{})
+}
+
+// finish records the end of the run
+func (run *idempotentRun) finish(err error, now time.Time) {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	run.done, run.err, run.completed = true, err, now
+	run.notify()
+}
+
+// notify wakes up the callers following the run; callers must hold mu
+func (run *idempotentRun) notify() {
+	close(run.changed)
+	run.changed = make(chan struct{})
+}
+
+// track registers a caller following the run from the event after from,
+// so that the events it has yet to stream are kept
+func (run *idempotentRun) track(from int) *int {
+	next := from
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	run.cursors[&next] = struct{}{}
+	return &next
+}
+
+// untrack unregisters a caller registered with track
+func (run *idempotentRun) untrack(next *int) {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	delete(run.cursors, next)
+	if run.truncated {
+		run.trim()
+	}
+}
+
+// follow passes the events of the run of ID runID to emit with their
+// continuity tokens, from the one following the position of the caller
+// next, registered with track, until the run ends or ctx is done. It
+// returns the error of a run that ended before emitting anything, e.g.
+// ErrTooManyRuns, and ErrRunNotResumable when the events to pass have
+// been dropped.
+func (run *idempotentRun) follow(ctx context.Context, runID string, next *int, emit ResumableEmitter) error {
+	for {
+		run.mu.Lock()
+		if *next > run.base+len(run.events) {
+			run.mu.Unlock()
+			return fmt.Errorf("%w: event %d was not streamed", events.ErrInvalidContinuityToken, *next)
+		}
+		if *next < run.base {
+			run.mu.Unlock()
+			return ErrRunNotResumable
+		}
+		pending := run.events[*next-run.base:]
+		done, err, changed := run.done, run.err, run.changed
+		run.mu.Unlock()
+
+		if done && *next == 0 && len(pending) == 0 {
+			return err
+		}
+		for i, recorded := range pending {
+			token := events.ContinuityToken{RunID: runID, Seq: *next + i + 1}
+			if err := emit.EmitResumable(ctx, token, recorded.event); err != nil {
+				return err
+			}
+		}
+
+		run.mu.Lock()
+		*next += len(pending)
+		if run.truncated {
+			run.trim()
+		}
+		run.mu.Unlock()
+		if done {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// completedRun is a run waiting for the end of its retention
+type completedRun struct {
+	key       tenantKey
+	run       *idempotentRun
+	completed time.Time
+}
+
+// completedRuns orders completed runs by completion time, as a heap
+type completedRuns []completedRun
+
+func (q completedRuns) Len() int           { return len(q) }
+func (q completedRuns) Less(i, j int) bool { return q[i].completed.Before(q[j].completed) }
+func (q completedRuns) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *completedRuns) Push(x any)        { *q = append(*q, x.(completedRun)) }
+func (q *completedRuns) Pop() any {
+	old := *q
+	c := old[len(old)-1]
+	old[len(old)-1] = completedRun{}
+	*q = old[:len(old)-1]
+	return c
+}
+
+// idempotencyRegistry tracks the runs submitted with an idempotency key
+type idempotencyRegistry struct {
+	config IdempotencyConfig
+	clock  clock.Clock
+
+	// size is the JSON size of the events recorded by all runs
+	size atomic.Int64
+
+	mu        sync.Mutex
+	runs      map[tenantKey]*idempotentRun
+	completed completedRuns
+}
+
+func newIdempotencyRegistry(config IdempotencyConfig, clk clock.Clock) *idempotencyRegistry {
+	if config.Retention <= 0 {
+		config.Retention = defaultIdempotencyRetention
+	}
+	if config.Grace <= 0 {
+		config.Grace = defaultIdempotencyGrace
+	}
+	if config.MaxRunBytes <= 0 {
+		config.MaxRunBytes = defaultIdempotencyMaxRunBytes
+	}
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = defaultIdempotencyMaxBytes
+	}
+	return &idempotencyRegistry{config: config, clock: clk, runs: make(map[tenantKey]*idempotentRun)}
+}
+
+// reserve takes size bytes from the budget of the registry, if there are
+// that many left
+func (r *idempotencyRegistry) reserve(size int64) bool {
+	if r.size.Add(size) > r.config.MaxBytes {
+		r.size.Add(-size)
+		return false
+	}
+	return true
+}
+
+// release returns size bytes to the budget of the registry
+func (r *idempotencyRegistry) release(size int64) {
+	r.size.Add(-size)
+}
+
+// attach attaches a caller to the run of key, creating it when the key is
+// new. A new run must be started by the caller, with cancel cancelling it.
+func (r *idempotencyRegistry) attach(key tenantKey, body []byte, cancel context.CancelCauseFunc) (*idempotentRun, bool, error) {
+	fingerprint := sha256.Sum256(body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expire()
+
+	run, ok := r.runs[key]
+	if ok && run.fingerprint != fingerprint {
+		return nil, false, ErrIdempotencyKeyReused
+	}
+	if !ok {
+		run = &idempotentRun{
+			fingerprint: fingerprint,
+			cancel:      cancel,
+			registry:    r,
+			cursors:     make(map[*int]struct{}),
+			consumed:    make(chan struct{}),
+			changed:     make(chan struct{}),
+		}
+		r.runs[key] = run
+	}
+	run.mu.Lock()
+	run.callers++
+	run.mu.Unlock()
+	return run, !ok, nil
+}
+
+// complete records the end of the run of key, which is kept for the
+// retention period
+func (r *idempotencyRegistry) complete(key tenantKey, run *idempotentRun, err error) {
+	now := r.clock.Now()
+	run.finish(err, now)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	heap.Push(&r.completed, completedRun{key: key, run: run, completed: now})
+	r.expire()
+}
+
+// expire drops the completed runs past their retention; callers must hold
+// mu
+func (r *idempotencyRegistry) expire() {
+	now := r.clock.Now()
+	for r.completed.Len() > 0 && now.Sub(r.completed[0].completed) >= r.config.Retention {
+		c := heap.Pop(&r.completed).(completedRun)
+		r.remove(c.key, c.run)
+	}
+}
+
+// remove drops run, if it is still the run of key, and returns its events
+// to the budget; callers must hold mu
+func (r *idempotencyRegistry) remove(key tenantKey, run *idempotentRun) {
+	if r.runs[key] != run {
+		return
+	}
+	delete(r.runs, key)
+	run.mu.Lock()
+	run.truncate()
+	run.mu.Unlock()
+}
+
+// detach detaches a caller from run, cancelling the run once it has been
+// left without callers for the grace period
+func (r *idempotencyRegistry) detach(run *idempotentRun) {
+	run.mu.Lock()
+	run.callers--
+	if run.callers > 0 || run.done {
+		run.mu.Unlock()
+		return
+	}
+	run.detached++
+	detached := run.detached
+	run.mu.Unlock()
+
+	go func() {
+		<-r.clock.After(r.config.Grace)
+		run.mu.Lock()
+		abandoned := !run.done && run.callers == 0 && run.detached == detached
+		run.mu.Unlock()
+		if abandoned {
+			run.cancel(ErrRunAbandoned)
+		}
+	}()
+}
+
+// forget drops the run of key, e.g. one that could not start, so that a
+// retry starts it again
+func (r *idempotencyRegistry) forget(key tenantKey, run *idempotentRun) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.remove(key, run)
+}
+
+// runIdempotent runs input for the request with idempotency key key and
+// body, or re-attaches to the run a previous request with the same key
+// started, streaming its events to sink from the continuity token of ctx,
+// if any. The run outlives the request and emits to emitter wrapping the
+// recording of its events.
+func (s *Server) runIdempotent(ctx context.Context, key string, body []byte, input *types.RunAgentInput, wrap func(Emitter) Emitter, sink ResumableEmitter) error {
+	token, resume := continuityToken(ctx)
+	if resume && token.RunID != input.RunID {
+		return fmt.Errorf("%w: issued for run %q", events.ErrInvalidContinuityToken, token.RunID)
+	}
+
+	scoped := scopeToTenant(ctx, key)
+	runCtx, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+	run, created, err := s.idempotency.attach(scoped, body, cancel)
+	if err != nil {
+		cancel(nil)
+		return err
+	}
+	defer s.idempotency.detach(run)
+
+	if created && resume {
+		// The run the token was issued for is gone; starting it over would
+		// not resume it
+		s.idempotency.forget(scoped, run)
+		cancel(nil)
+		return ErrRunNotResumable
+	}
+	next := run.track(token.Seq)
+	defer run.untrack(next)
+	if created {
+		go func() {
+			defer cancel(nil)
+			err := s.runs.Run(runCtx, input, wrap(run))
+			s.idempotency.complete(scoped, run, err)
+			if errors.Is(err, ErrTooManyRuns) || errors.Is(err, ErrShedding) || errors.Is(err, ErrRunAlreadyActive) || errors.Is(err, ErrShuttingDown) {
+				s.idempotency.forget(scoped, run)
+			}
+		}()
+	} else {
+		cancel(nil)
+		s.logger.Debug("Re-attaching to idempotent run", logging.KeyRunID, input.RunID)
+		if requested := flowControl(ctx); requested != nil {
+			// The window of the new connection replaces that of the
+			// dropped one
+			if flow, err := s.runs.FlowControl(ctx, input.RunID); err == nil {
+				flow.Reset(requested.window)
+			}
+		}
+	}
+	return run.follow(ctx, input.RunID, next, sink)
+}
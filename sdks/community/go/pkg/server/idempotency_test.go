@@ -0,0 +1,412 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/testhelper"
+)
+
+// newIdempotentServer serves an agent answering "Hello" once release is
+// closed, reporting each run it starts on started
+func newIdempotentServer(clk *testhelper.FakeClock, started chan<- string, release <-chan struct{}, taps ...Tap) *Server {
+	return newIdempotentServerWithConfig(clk, IdempotencyConfig{Retention: time.Minute, Grace: 10 * time.Second}, started, release, taps...)
+}
+
+// newIdempotentServerWithConfig is newIdempotentServer with config
+func newIdempotentServerWithConfig(clk *testhelper.FakeClock, config IdempotencyConfig, started chan<- string, release <-chan struct{}, taps ...Tap) *Server {
+	return NewServer(AgentFunc(func(ctx context.Context, input *types.RunAgentInput, emitter *EventEmitter) error {
+		started <- input.RunID
+		select {
+		case <-release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		messageID, err := emitter.StartTextMessage(ctx, "assistant")
+		if err != nil {
+			return err
+		}
+		if err := emitter.EmitContent(ctx, messageID, "Hello"); err != nil {
+			return err
+		}
+		return emitter.EndTextMessage(ctx, messageID)
+	}), Config{RunManagerConfig: RunManagerConfig{Clock: clk, Tap: Taps(taps...)}, Idempotency: config})
+}
+
+func idempotentRequest(ctx context.Context, key, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)).WithContext(ctx)
+	req.Header.Set(events.IdempotencyKeyHeader, key)
+	return req
+}
+
+func TestServerIdempotentRuns(t *testing.T) {
+	const body = `{"threadId":"thread-1","runId":"run-1"}`
+	complete := []string{"RUN_STARTED", "TEXT_MESSAGE_START", "TEXT_MESSAGE_CONTENT", "TEXT_MESSAGE_END", "RUN_FINISHED"}
+
+	t.Run("retries re-attach to the run", func(t *testing.T) {
+		started, release := make(chan string, 2), make(chan struct{})
+		srv := newIdempotentServer(testhelper.NewFakeClock(time.Unix(0, 0)), started, release)
+
+		recs := make([]*httptest.ResponseRecorder, 2)
+		done := make(chan struct{}, 2)
+		for i := range recs {
+			recs[i] = httptest.NewRecorder()
+			go func(rec *httptest.ResponseRecorder) {
+				srv.ServeHTTP(rec, idempotentRequest(context.Background(), "key-1", body))
+				done <- struct{}{}
+			}(recs[i])
+			if i == 0 {
+				<-started
+			}
+		}
+		close(release)
+		<-done
+		<-done
+
+		for _, rec := range recs {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			for _, want := range complete {
+				assert.Contains(t, rec.Body.String(), want)
+			}
+			assert.Equal(t, len(complete), strings.Count(rec.Body.String(), "data: "))
+		}
+		assert.Empty(t, started, "the retry does not start another run")
+
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, idempotentRequest(context.Background(), "key-1", body))
+		assert.Equal(t, recs[0].Body.String(), rec.Body.String(), "completed runs are replayed")
+		assert.Empty(t, started)
+	})
+
+	t.Run("completed runs are retained", func(t *testing.T) {
+		clk := testhelper.NewFakeClock(time.Unix(0, 0))
+		started, release := make(chan string, 2), make(chan struct{})
+		close(release)
+		srv := newIdempotentServer(clk, started, release)
+
+		srv.ServeHTTP(httptest.NewRecorder(), idempotentRequest(context.Background(), "key-1", body))
+		assert.Len(t, started, 1)
+		clk.Advance(time.Minute)
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, idempotentRequest(context.Background(), "key-1", `{"threadId":"thread-1","runId":"run-2"}`))
+		assert.Equal(t, http.StatusOK, rec.Code, "expired keys can be reused")
+		assert.Len(t, started, 2)
+	})
+
+	t.Run("key reused for another request", func(t *testing.T) {
+		started, release := make(chan string, 1), make(chan struct{})
+		close(release)
+		srv := newIdempotentServer(testhelper.NewFakeClock(time.Unix(0, 0)), started, release)
+
+		srv.ServeHTTP(httptest.NewRecorder(), idempotentRequest(context.Background(), "key-1", body))
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, idempotentRequest(context.Background(), "key-1", `{"threadId":"thread-1","runId":"run-2"}`))
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("keys are scoped to tenants", func(t *testing.T) {
+		started, release := make(chan string, 4), make(chan struct{})
+		close(release)
+		srv := newIdempotentServer(testhelper.NewFakeClock(time.Unix(0, 0)), started, release)
+
+		// Keys containing the tenant separator of a joined key do not
+		// collide either
+		for _, request := range []struct{ tenant, key string }{
+			{"acme", "key-1"},
+			{"globex", "key-1"},
+			{"acme/eu", "key-1"},
+			{"acme", "eu/key-1"},
+		} {
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, idempotentRequest(WithTenant(context.Background(), request.tenant), request.key, body))
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+		assert.Len(t, started, 4)
+	})
+
+	t.Run("runs outlive dropped connections", func(t *testing.T) {
+		clk := testhelper.NewFakeClock(time.Unix(0, 0))
+		started, release := make(chan string, 1), make(chan struct{})
+		srv := newIdempotentServer(clk, started, release)
+
+		ctx, drop := context.WithCancel(context.Background())
+		dropped := make(chan struct{})
+		go func() {
+			srv.ServeHTTP(httptest.NewRecorder(), idempotentRequest(ctx, "key-1", body))
+			close(dropped)
+		}()
+		<-started
+		drop()
+		<-dropped
+		clk.BlockUntil(1)
+		clk.Advance(5 * time.Second)
+
+		close(release)
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, idempotentRequest(context.Background(), "key-1", body))
+		for _, want := range complete {
+			assert.Contains(t, rec.Body.String(), want)
+		}
+	})
+
+	t.Run("abandoned runs are cancelled", func(t *testing.T) {
+		clk := testhelper.NewFakeClock(time.Unix(0, 0))
+		started, release := make(chan string, 1), make(chan struct{})
+		defer close(release)
+		var cancelled atomic.Bool
+		srv := newIdempotentServer(clk, started, release, func(_ context.Context, _ *types.RunAgentInput, emitter Emitter) Emitter {
+			return EmitterFunc(func(ctx context.Context, event events.Event) error {
+				if runError, ok := event.(*events.RunErrorEvent); ok && *runError.Code == RunErrorCodeCancelled {
+					cancelled.Store(true)
+				}
+				return emitter.Emit(ctx, event)
+			})
+		})
+
+		ctx, drop := context.WithCancel(context.Background())
+		dropped := make(chan struct{})
+		go func() {
+			srv.ServeHTTP(httptest.NewRecorder(), idempotentRequest(ctx, "key-1", body))
+			close(dropped)
+		}()
+		<-started
+		drop()
+		<-dropped
+		clk.BlockUntil(1)
+		clk.Advance(10 * time.Second)
+		require.Eventually(t, cancelled.Load, 5*time.Second, 10*time.Millisecond)
+	})
+	t.Run("retries resume from continuity tokens", func(t *testing.T) {
+		started, release := make(chan string, 2), make(chan struct{})
+		close(release)
+		srv := newIdempotentServer(testhelper.NewFakeClock(time.Unix(0, 0)), started, release)
+
+		first := httptest.NewRecorder()
+		srv.ServeHTTP(first, idempotentRequest(context.Background(), "key-1", body))
+		ids := frameIDs(first.Body.String())
+		require.Len(t, ids, len(complete))
+		token, err := events.ParseContinuityToken(ids[1])
+		require.NoError(t, err)
+		assert.Equal(t, events.ContinuityToken{RunID: "run-1", Seq: 2}, token)
+
+		rec := httptest.NewRecorder()
+		req := idempotentRequest(context.Background(), "key-1", body)
+		req.Header.Set(events.LastEventIDHeader, ids[1])
+		srv.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, ids[2:], frameIDs(rec.Body.String()))
+		assert.NotContains(t, rec.Body.String(), "TEXT_MESSAGE_START", "delivered events are not replayed")
+		assert.Contains(t, rec.Body.String(), "RUN_FINISHED")
+
+		rec = httptest.NewRecorder()
+		req = idempotentRequest(context.Background(), "key-1", body)
+		req.Header.Set(events.LastEventIDHeader, ids[len(ids)-1])
+		srv.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Body.String(), "nothing follows the last event")
+		assert.Len(t, started, 1)
+	})
+
+	t.Run("unknown runs are not resumable", func(t *testing.T) {
+		started, release := make(chan string, 1), make(chan struct{})
+		close(release)
+		srv := newIdempotentServer(testhelper.NewFakeClock(time.Unix(0, 0)), started, release)
+
+		req := idempotentRequest(context.Background(), "key-1", body)
+		req.Header.Set(events.LastEventIDHeader, events.ContinuityToken{RunID: "run-1", Seq: 3}.String())
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusGone, rec.Code)
+		assert.Empty(t, started)
+
+		rec = httptest.NewRecorder()
+		srv.ServeHTTP(rec, idempotentRequest(context.Background(), "key-1", body))
+		assert.Equal(t, http.StatusOK, rec.Code, "the run can be started over")
+		assert.Len(t, started, 1)
+	})
+
+	t.Run("invalid continuity tokens", func(t *testing.T) {
+		started, release := make(chan string, 1), make(chan struct{})
+		close(release)
+		srv := newIdempotentServer(testhelper.NewFakeClock(time.Unix(0, 0)), started, release)
+		srv.ServeHTTP(httptest.NewRecorder(), idempotentRequest(context.Background(), "key-1", body))
+
+		for _, token := range []string{
+			"RUN_STARTED_1700000000",
+			events.ContinuityToken{RunID: "run-2", Seq: 1}.String(),
+			events.ContinuityToken{RunID: "run-1", Seq: 99}.String(),
+		} {
+			req := idempotentRequest(context.Background(), "key-1", body)
+			req.Header.Set(events.LastEventIDHeader, token)
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, token)
+		}
+	})
+}
+
+func TestIdempotentRunLimits(t *testing.T) {
+	const body = `{"threadId":"thread-1","runId":"run-1"}`
+	complete := []string{"RUN_STARTED", "TEXT_MESSAGE_START", "TEXT_MESSAGE_CONTENT", "TEXT_MESSAGE_END", "RUN_FINISHED"}
+
+	tests := []struct {
+		name   string
+		config IdempotencyConfig
+	}{
+		{"past MaxRunBytes", IdempotencyConfig{Retention: time.Minute, MaxRunBytes: 200}},
+		{"past MaxBytes", IdempotencyConfig{Retention: time.Minute, MaxBytes: 200}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started, release := make(chan string, 1), make(chan struct{})
+			close(release)
+			srv := newIdempotentServerWithConfig(testhelper.NewFakeClock(time.Unix(0, 0)), tt.config, started, release)
+
+			first := httptest.NewRecorder()
+			srv.ServeHTTP(first, idempotentRequest(context.Background(), "key-1", body))
+			assert.Equal(t, http.StatusOK, first.Code)
+			for _, want := range complete {
+				assert.Contains(t, first.Body.String(), want, "the attached caller gets every event")
+			}
+			ids := frameIDs(first.Body.String())
+			require.Len(t, ids, len(complete))
+
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, idempotentRequest(context.Background(), "key-1", body))
+			assert.Equal(t, http.StatusGone, rec.Code, "the events were not kept")
+
+			rec = httptest.NewRecorder()
+			req := idempotentRequest(context.Background(), "key-1", body)
+			req.Header.Set(events.LastEventIDHeader, ids[1])
+			srv.ServeHTTP(rec, req)
+			assert.Equal(t, http.StatusGone, rec.Code)
+			assert.Len(t, started, 1, "the run is not started again")
+			assert.Zero(t, srv.idempotency.size.Load(), "truncated runs leave the budget")
+		})
+	}
+}
+
+func TestIdempotencyRegistry(t *testing.T) {
+	clk := testhelper.NewFakeClock(time.Unix(0, 0))
+	r := newIdempotencyRegistry(IdempotencyConfig{Retention: time.Minute, MaxRunBytes: 2000, MaxBytes: 1500}, clk)
+	content := func(i int) events.Event {
+		return events.NewTextMessageContentEvent("msg-1", strings.Repeat("x", 50+i%10))
+	}
+	collect := func(got *[]int) ResumableEmitter {
+		return ResumableEmitterFunc(func(_ context.Context, token events.ContinuityToken, _ events.Event) error {
+			*got = append(*got, token.Seq)
+			return nil
+		})
+	}
+
+	t.Run("runs within the budget are kept", func(t *testing.T) {
+		run, created, err := r.attach(tenantKey{id: "key-1"}, []byte("a"), func(error) {})
+		require.NoError(t, err)
+		require.True(t, created)
+		for i := range 5 {
+			require.NoError(t, run.Emit(context.Background(), content(i)))
+		}
+		r.complete(tenantKey{id: "key-1"}, run, nil)
+		assert.False(t, run.truncated)
+		assert.Equal(t, run.size, r.size.Load())
+
+		var got []int
+		require.NoError(t, run.follow(context.Background(), "run-1", run.track(2), collect(&got)))
+		assert.Equal(t, []int{3, 4, 5}, got)
+	})
+
+	t.Run("the budget of the registry is shared", func(t *testing.T) {
+		run, _, err := r.attach(tenantKey{id: "key-2"}, []byte("b"), func(error) {})
+		require.NoError(t, err)
+		next := run.track(0)
+		for i := range 10 {
+			require.NoError(t, run.Emit(context.Background(), content(i)))
+		}
+		assert.True(t, run.truncated, "the registry is out of budget")
+		assert.Equal(t, 10, run.base+len(run.events))
+		assert.Equal(t, 0, run.base, "the events the caller has not streamed are kept")
+
+		var got []int
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_ = run.follow(ctx, "run-2", next, collect(&got))
+		assert.Len(t, got, 10)
+		assert.Equal(t, 10, run.base, "streamed events are dropped")
+		run.untrack(next)
+
+		require.NoError(t, run.Emit(context.Background(), content(0)))
+		assert.Empty(t, run.events, "without callers nothing is kept")
+		assert.ErrorIs(t, run.follow(context.Background(), "run-2", run.track(0), collect(&got)), ErrRunNotResumable)
+	})
+
+	t.Run("truncated runs wait for lagging callers", func(t *testing.T) {
+		run, _, err := r.attach(tenantKey{id: "key-3"}, []byte("c"), func(error) {})
+		require.NoError(t, err)
+		next := run.track(0)
+		emitted := make(chan int, 40)
+		go func() {
+			defer close(emitted)
+			for i := range 40 {
+				if run.Emit(context.Background(), content(i)) != nil {
+					return
+				}
+				emitted <- i
+			}
+		}()
+
+		// The events past MaxRunBytes wait for the caller
+		time.Sleep(50 * time.Millisecond)
+		assert.Less(t, len(emitted), 40)
+		run.mu.Lock()
+		assert.True(t, run.truncated)
+		assert.Equal(t, 0, run.base)
+		run.mu.Unlock()
+
+		var got []int
+		ctx, cancel := context.WithCancel(context.Background())
+		follow := make(chan error, 1)
+		go func() { follow <- run.follow(ctx, "run-3", next, collect(&got)) }()
+		for range emitted {
+		}
+		require.Eventually(t, func() bool {
+			run.mu.Lock()
+			defer run.mu.Unlock()
+			return *next == 40
+		}, 5*time.Second, time.Millisecond)
+		cancel()
+		assert.ErrorIs(t, <-follow, context.Canceled)
+		assert.Len(t, got, 40, "the caller gets every event")
+		run.untrack(next)
+	})
+
+	t.Run("completed runs expire", func(t *testing.T) {
+		require.NotZero(t, r.size.Load())
+		clk.Advance(time.Minute)
+		_, created, err := r.attach(tenantKey{id: "key-1"}, []byte("a"), func(error) {})
+		require.NoError(t, err)
+		assert.True(t, created, "the expired run is gone")
+		assert.Zero(t, r.size.Load(), "expired runs leave the budget")
+		assert.Empty(t, r.completed)
+	})
+}
+
+// frameIDs returns the event IDs of the SSE frames of body
+func frameIDs(body string) []string {
+	var ids []string
+	for _, line := range strings.Split(body, "\n") {
+		if id, ok := strings.CutPrefix(line, "id: "); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,385 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding/sse"
+	agerrors "github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/errors"
+)
+
+// Middleware wraps an http.Handler with additional behaviour
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps handler with middleware. The first middleware is the outermost,
+// so Chain(h, a, b) handles a request as a(b(h)).
+func Chain(handler http.Handler, middleware ...Middleware) http.Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
+
+// MiddlewareConfig configures the standard middleware chain built by NewMiddleware
+type MiddlewareConfig struct {
+	// Logger receives request logs and recovered panics (defaults to slog.Default())
+	Logger *slog.Logger `json:"-"`
+
+	// Authenticator authenticates requests (nil = no authentication)
+	Authenticator Authenticator `json:"-"`
+
+	// RateLimit limits requests per principal (zero value = unlimited)
+	RateLimit RateLimitConfig `json:"rateLimit"`
+
+	// MaxBodyBytes limits the size of request bodies (0 = unlimited)
+	MaxBodyBytes int64 `json:"maxBodyBytes"`
+
+	// Production leaves panic values out of the RUN_ERROR events written by
+	// the recovery middleware
+	Production bool `json:"production"`
+}
+
+// RateLimitConfig configures per-principal rate limiting
+type RateLimitConfig struct {
+	// RequestsPerSecond is the sustained request rate allowed per principal (0 = unlimited)
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+
+	// Burst is the number of requests a principal may make at once (defaults to 1)
+	Burst int `json:"burst"`
+
+	// Clock refills the buckets (defaults to the real clock)
+	Clock clock.Clock `json:"-"`
+}
+
+// NewMiddleware builds the standard middleware chain from config:
+// request logging, panic recovery, request size limits, authentication and
+// rate limiting, in that order. Middleware that is not configured is omitted.
+func NewMiddleware(config MiddlewareConfig) []Middleware {
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+
+	middleware := []Middleware{
+		RequestLogger(config.Logger),
+		NewRecovery(RecoveryConfig{Logger: config.Logger, Production: config.Production}),
+	}
+	if config.MaxBodyBytes > 0 {
+		middleware = append(middleware, MaxBodySize(config.MaxBodyBytes))
+	}
+	if config.Authenticator != nil {
+		middleware = append(middleware, Authenticate(config.Authenticator))
+	}
+	if config.RateLimit.RequestsPerSecond > 0 {
+		middleware = append(middleware, RateLimit(NewRateLimiter(config.RateLimit)))
+	}
+	return middleware
+}
+
+// Authenticate rejects requests that authenticator does not accept with
+// 401 Unauthorized and stores the principal of accepted requests in the
+// request context, see PrincipalFromContext
+func Authenticate(authenticator Authenticator) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, err := authenticator.Authenticate(r)
+			if err != nil || principal == nil {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			if info, ok := r.Context().Value(requestInfoKey{}).(*requestInfo); ok {
+				info.principal = principal.ID
+			}
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// RequestLogger logs every request once it has been handled
+func RequestLogger(logger *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := wrapResponseWriter(w)
+
+			// The principal and tenant are only known once middleware further down the chain has run
+			info := &requestInfo{}
+			next.ServeHTTP(rw, r.WithContext(context.WithValue(r.Context(), requestInfoKey{}, info)))
+
+			logger.InfoContext(r.Context(), "Request handled",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", rw.statusCode(),
+				"bytes", rw.bytes,
+				"duration", time.Since(start),
+				"remote_addr", r.RemoteAddr,
+				"principal", info.principal,
+				TenantLabel, info.tenant)
+		})
+	}
+}
+
+// RecoveryConfig configures the Recovery middleware
+type RecoveryConfig struct {
+	// Logger receives recovered panics with their stack traces (defaults to
+	// slog.Default())
+	Logger *slog.Logger
+
+	// Production leaves the panic values out of the RUN_ERROR events
+	// written to clients
+	Production bool
+}
+
+// Recovery recovers from panics in the wrapped handler. If the response has
+// not started a 500 error is returned; if an SSE stream is already in
+// progress a RUN_ERROR event with code PANIC is written to it instead.
+func Recovery(logger *slog.Logger) Middleware {
+	return NewRecovery(RecoveryConfig{Logger: logger})
+}
+
+// NewRecovery creates a Recovery middleware from config
+func NewRecovery(config RecoveryConfig) Middleware {
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+	logger := config.Logger
+	writer := sse.NewSSEWriter().WithLogger(logger)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := wrapResponseWriter(w)
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+				panicErr := agerrors.NewPanicError(recovered, debug.Stack())
+
+				logger.ErrorContext(r.Context(), "Recovered from panic",
+					"severity", panicErr.Severity,
+					"panic", recovered,
+					"stack", string(panicErr.Stack))
+
+				if !rw.wroteHeader {
+					http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					return
+				}
+				if strings.HasPrefix(rw.Header().Get("Content-Type"), "text/event-stream") {
+					message := fmt.Sprintf("internal server error: %v", recovered)
+					if config.Production {
+						message = "internal server error"
+					}
+					event := events.NewRunErrorEvent(message, events.WithErrorCode(RunErrorCodePanic))
+					_ = writer.WriteEvent(r.Context(), rw, event)
+				}
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// MaxBodySize rejects request bodies larger than limit bytes with 413 Request Entity Too Large
+func MaxBodySize(limit int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RateLimit rejects requests exceeding limiter's rate with 429 Too Many Requests.
+// Requests are keyed by principal, falling back to the client IP address for
+// unauthenticated requests.
+func RateLimit(limiter *RateLimiter) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ok, retryAfter := limiter.Allow(rateLimitKey(r)); !ok {
+				tooManyRequests(w, retryAfter)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// tooManyRequests rejects a rate limited request
+func tooManyRequests(w http.ResponseWriter, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+}
+
+// rateLimitKey returns the key requests are rate limited by
+func rateLimitKey(r *http.Request) string {
+	if principal, ok := PrincipalFromContext(r.Context()); ok {
+		return "principal:" + principal.ID
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// maxBuckets is the number of buckets kept before those of the least
+// recently used keys are evicted
+const maxBuckets = 10000
+
+// RateLimiter is a token bucket rate limiter keyed by an arbitrary string
+type RateLimiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	uses    uint64
+}
+
+type tokenBucket struct {
+	tokens  float64
+	last    time.Time
+	lastUse uint64
+}
+
+// NewRateLimiter creates a rate limiter from config
+func NewRateLimiter(config RateLimitConfig) *RateLimiter {
+	burst := config.Burst
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:    config.RequestsPerSecond,
+		burst:   float64(burst),
+		now:     clock.Or(config.Clock).Now,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// Allow reports whether a request for key may proceed. If not, it also
+// returns how long the caller should wait before retrying.
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	if l.rate <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.uses++
+	bucket, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxBuckets {
+			l.evict(len(l.buckets) - maxBuckets*9/10)
+		}
+		bucket = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = bucket
+	}
+	bucket.lastUse = l.uses
+
+	bucket.tokens = math.Min(l.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		wait := time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+	bucket.tokens--
+	return true, 0
+}
+
+// evict removes the buckets of the n least recently used keys; callers
+// must hold mu
+func (l *RateLimiter) evict(n int) {
+	keys := make([]string, 0, len(l.buckets))
+	for key := range l.buckets {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return l.buckets[keys[i]].lastUse < l.buckets[keys[j]].lastUse
+	})
+	for _, key := range keys[:n] {
+		delete(l.buckets, key)
+	}
+}
+
+type requestInfoKey struct{}
+
+// requestInfo collects attributes resolved while handling a request for RequestLogger
+type requestInfo struct {
+	principal string
+	tenant    string
+}
+
+// responseWriter records the status and size of a response while
+// preserving flushing for streaming handlers
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+// wrapResponseWriter wraps w unless it is already wrapped
+func wrapResponseWriter(w http.ResponseWriter) *responseWriter {
+	if rw, ok := w.(*responseWriter); ok {
+		return rw
+	}
+	return &responseWriter{ResponseWriter: w}
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher
+func (w *responseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *responseWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
@@ -0,0 +1,200 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/testhelper"
+)
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+func TestChainOrder(t *testing.T) {
+	var order []string
+	mark := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	Chain(okHandler, mark("a"), mark("b")).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, []string{"a", "b"}, order)
+}
+
+func TestAuthenticate(t *testing.T) {
+	var principal *Principal
+	handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = PrincipalFromContext(r.Context())
+	}), Authenticate(NewBearerTokenAuthenticator(map[string]string{"secret": "alice"})))
+
+	t.Run("missing token", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("wrong token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("Authorization", "Bearer nope")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("valid token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		require.NotNil(t, principal)
+		assert.Equal(t, "alice", principal.ID)
+	})
+}
+
+func TestRecovery(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+
+	t.Run("before response", func(t *testing.T) {
+		handler := Chain(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic("boom")
+		}), Recovery(logger))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("during stream", func(t *testing.T) {
+		handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte("data: {}\n\n"))
+			panic("boom")
+		}), Recovery(logger))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"type":"RUN_ERROR"`)
+		assert.Contains(t, rec.Body.String(), RunErrorCodePanic)
+		assert.Contains(t, rec.Body.String(), "internal server error: boom")
+	})
+
+	t.Run("production", func(t *testing.T) {
+		var logs bytes.Buffer
+		handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte("data: {}\n\n"))
+			panic("secret token abc")
+		}), NewRecovery(RecoveryConfig{Logger: slog.New(slog.NewTextHandler(&logs, nil)), Production: true}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+		assert.Contains(t, rec.Body.String(), `"message":"internal server error"`)
+		assert.NotContains(t, rec.Body.String(), "secret")
+		assert.Contains(t, logs.String(), "severity=CRITICAL")
+		assert.Contains(t, logs.String(), "secret token abc", "logs keep the panic")
+		assert.Contains(t, logs.String(), "stack=")
+	})
+}
+
+func TestMaxBodySize(t *testing.T) {
+	handler := Chain(NewServer(AgentFunc(nil), Config{}), MaxBodySize(8))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"threadId":"thread-1"}`)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestRateLimiter(t *testing.T) {
+	clk := testhelper.NewFakeClock(time.Unix(0, 0))
+	limiter := NewRateLimiter(RateLimitConfig{RequestsPerSecond: 1, Burst: 2, Clock: clk})
+
+	allowed, _ := limiter.Allow("alice")
+	assert.True(t, allowed)
+	allowed, _ = limiter.Allow("alice")
+	assert.True(t, allowed)
+	allowed, retryAfter := limiter.Allow("alice")
+	assert.False(t, allowed)
+	assert.Equal(t, time.Second, retryAfter)
+
+	allowed, _ = limiter.Allow("bob")
+	assert.True(t, allowed, "limits are per key")
+
+	clk.Advance(time.Second)
+	allowed, _ = limiter.Allow("alice")
+	assert.True(t, allowed)
+}
+
+func TestRateLimiterEviction(t *testing.T) {
+	clk := testhelper.NewFakeClock(time.Unix(0, 0))
+	limiter := NewRateLimiter(RateLimitConfig{RequestsPerSecond: 1, Clock: clk})
+	limiter.Allow("alice")
+	for i := range maxBuckets - 1 {
+		limiter.Allow(fmt.Sprint("key-", i))
+	}
+	allowed, _ := limiter.Allow("alice")
+	assert.False(t, allowed)
+	require.Len(t, limiter.buckets, maxBuckets)
+
+	// The least recently used keys are evicted past the limit, even with
+	// buckets that have not refilled
+	limiter.Allow("bob")
+	assert.Len(t, limiter.buckets, maxBuckets*9/10+1)
+	assert.NotContains(t, limiter.buckets, "key-0")
+	allowed, _ = limiter.Allow("alice")
+	assert.False(t, allowed, "recently used keys keep their bucket")
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	handler := Chain(okHandler,
+		Authenticate(NewAPIKeyAuthenticator("X-API-Key", map[string]string{"key-a": "alice", "key-b": "bob"})),
+		RateLimit(NewRateLimiter(RateLimitConfig{RequestsPerSecond: 0.001})))
+
+	request := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("X-API-Key", key)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, request("key-a").Code)
+	limited := request("key-a")
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.NotEmpty(t, limited.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, request("key-b").Code)
+}
+
+func TestRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}), NewMiddleware(MiddlewareConfig{
+		Logger:        logger,
+		Authenticator: NewBearerTokenAuthenticator(map[string]string{"secret": "alice"}),
+	})...)
+
+	req := httptest.NewRequest(http.MethodPost, "/agent", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Contains(t, buf.String(), "status=202")
+	assert.Contains(t, buf.String(), "path=/agent")
+	assert.Contains(t, buf.String(), "principal=alice")
+}
@@ -0,0 +1,59 @@
+package server
+
+import "github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+
+// Priority is the scheduling class of an event. A Scheduler delivers higher
+// classes first under load.
+type Priority int
+
+// Priority classes, from lowest to highest
+const (
+	// PriorityBulk is streamed content, whose delay only slows the output
+	PriorityBulk Priority = iota + 1
+
+	// PriorityNormal is the structure of the output: messages and tool calls
+	// starting and ending, results, state and custom events
+	PriorityNormal
+
+	// PriorityHigh is the lifecycle of runs and steps, errors and heartbeats,
+	// which clients rely on to follow a run
+	PriorityHigh
+)
+
+// Priorities lists the classes from highest to lowest
+var Priorities = []Priority{PriorityHigh, PriorityNormal, PriorityBulk}
+
+// String returns the name of the class, as used in metric labels
+func (p Priority) String() string {
+	switch p {
+	case PriorityBulk:
+		return "bulk"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	default:
+		return "unset"
+	}
+}
+
+// PriorityOf returns the class of event, given by its type. Heartbeats are
+// PriorityHigh.
+func PriorityOf(event events.Event) Priority {
+	if _, ok := events.HeartbeatInterval(event); ok {
+		return PriorityHigh
+	}
+	switch event.Type() {
+	case events.EventTypeRunStarted, events.EventTypeRunFinished, events.EventTypeRunError,
+		events.EventTypeStepStarted, events.EventTypeStepFinished:
+		return PriorityHigh
+	case events.EventTypeTextMessageContent, events.EventTypeTextMessageChunk,
+		events.EventTypeToolCallArgs, events.EventTypeToolCallChunk,
+		events.EventTypeThinkingTextMessageContent,
+		events.EventTypeReasoningMessageContent, events.EventTypeReasoningMessageChunk,
+		events.EventTypeRaw:
+		return PriorityBulk
+	default:
+		return PriorityNormal
+	}
+}
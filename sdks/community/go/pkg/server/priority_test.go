@@ -0,0 +1,23 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+func TestPriorityOf(t *testing.T) {
+	assert.Equal(t, PriorityHigh, PriorityOf(events.NewRunErrorEvent("failed")))
+	assert.Equal(t, PriorityHigh, PriorityOf(events.NewStepStartedEvent("plan")))
+	assert.Equal(t, PriorityHigh, PriorityOf(events.NewHeartbeatEvent(time.Second)))
+	assert.Equal(t, PriorityNormal, PriorityOf(events.NewToolCallStartEvent("call-1", "search")))
+	assert.Equal(t, PriorityNormal, PriorityOf(events.NewCustomEvent("progress")))
+	assert.Equal(t, PriorityBulk, PriorityOf(events.NewTextMessageContentEvent("msg-1", "Hello")))
+	assert.Equal(t, PriorityBulk, PriorityOf(events.NewToolCallArgsEvent("call-1", "{")))
+
+	assert.Equal(t, "bulk", PriorityBulk.String())
+	assert.Equal(t, "unset", Priority(0).String())
+}
@@ -0,0 +1,568 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding"
+	agerrors "github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/errors"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/i18n"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/logging"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/runctx"
+)
+
+// Error codes used in RUN_ERROR events emitted by the RunManager
+const (
+	// RunErrorCodeAgentError indicates the agent returned an error
+	RunErrorCodeAgentError = "AGENT_ERROR"
+	// RunErrorCodePanic indicates the agent panicked
+	RunErrorCodePanic = "PANIC"
+	// RunErrorCodeTimeout indicates the run exceeded its timeout
+	RunErrorCodeTimeout = "TIMEOUT"
+	// RunErrorCodeCancelled indicates the run was cancelled, e.g. by a client disconnect
+	// or with Cancel
+	RunErrorCodeCancelled = events.RunErrorCodeCancelled
+	// RunErrorCodeShutdown indicates the run was cancelled because the server is shutting down
+	RunErrorCodeShutdown = "SHUTDOWN"
+	// RunErrorCodeBudgetExceeded indicates the run exceeded its budget, see RunBudget
+	RunErrorCodeBudgetExceeded = "BUDGET_EXCEEDED"
+)
+
+// Message keys of the RUN_ERROR events emitted by the RunManager, see
+// RunManagerConfig.Localizer. Their parameters are those of the default
+// messages.
+const (
+	MessageRunPanic             = "run.panic"
+	MessageRunPanicRedacted     = "run.panic_redacted"
+	MessageRunShutdown          = "run.shutdown"
+	MessageRunTimeout           = "run.timeout"
+	MessageRunCancelledByClient = "run.cancelled_by_client"
+	MessageRunCancelled         = "run.cancelled"
+	// MessageRunBudgetExceeded is followed by the exceeded BudgetLimit,
+	// e.g. "run.budget_exceeded.events"
+	MessageRunBudgetExceeded = "run.budget_exceeded"
+)
+
+// shutdownCancelWait bounds how long Shutdown waits for cancelled runs to emit their terminal events
+const shutdownCancelWait = 5 * time.Second
+
+var (
+	// ErrTooManyRuns is returned when the concurrent run limit has been reached
+	ErrTooManyRuns = errors.New("too many concurrent runs")
+
+	// ErrRunAlreadyActive is returned when a run with the same ID is already in progress
+	ErrRunAlreadyActive = errors.New("run already active")
+
+	// ErrLifecycleEvent is returned when an agent tries to emit a run lifecycle event itself
+	ErrLifecycleEvent = errors.New("run lifecycle events are emitted by the run manager")
+
+	// ErrRunCompleted is returned when an agent emits an event after its run has completed
+	ErrRunCompleted = errors.New("run already completed")
+
+	// ErrShuttingDown is returned when a run is started after Shutdown has been called
+	ErrShuttingDown = errors.New("run manager is shutting down")
+
+	// ErrRunNotFound is returned when cancelling a run that is not in progress
+	ErrRunNotFound = errors.New("run not found")
+
+	// ErrShedding is returned when a run is started while the run manager
+	// sheds load, see Shed
+	ErrShedding = errors.New("run manager is shedding load")
+
+	// ErrRunCancelled is the cancellation cause of runs cancelled by their
+	// client, see Cancel
+	ErrRunCancelled = errors.New("run cancelled by client")
+)
+
+// PanicError wraps a value recovered from a panicking agent, as a critical
+// error
+type PanicError struct {
+	*agerrors.PanicError
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("agent panicked: %v", e.Value)
+}
+
+func (e *PanicError) Unwrap() error {
+	return e.PanicError
+}
+
+// RunError is returned by an agent to choose the code and message of the
+// RUN_ERROR ending its run, e.g. to relay the error of an upstream agent
+type RunError struct {
+	// Code defaults to RunErrorCodeAgentError
+	Code    string
+	Message string
+
+	// Key, when set, localizes Message with RunManagerConfig.Localizer.
+	// Message is then the default template of the key, expanding Params
+	// (see i18n.Message).
+	Key    string
+	Params map[string]any
+}
+
+func (e *RunError) Error() string {
+	if e.Code == "" {
+		return e.message().String()
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.message())
+}
+
+func (e *RunError) message() i18n.Message {
+	if e.Key == "" {
+		return i18n.Message{Default: e.Message}
+	}
+	return i18n.Message{Key: e.Key, Params: e.Params, Default: e.Message}
+}
+
+// RunManagerConfig configures a RunManager
+type RunManagerConfig struct {
+	// MaxConcurrentRuns limits the number of runs in progress (0 = unlimited)
+	MaxConcurrentRuns int
+
+	// RunTimeout bounds the duration of a single run (0 = no timeout)
+	RunTimeout time.Duration
+
+	// Logger receives lifecycle diagnostics (defaults to slog.Default())
+	Logger *slog.Logger
+
+	// Clock times heartbeats and the grace given to cancelled runs on
+	// shutdown (defaults to the real clock). RunTimeout is a context deadline and always uses
+	// the real clock.
+	Clock clock.Clock
+
+	// Tap, when set, wraps the emitter of each run, e.g. to archive runs
+	// (see package archive). Combine several with Taps.
+	Tap Tap
+
+	// Heartbeat emits a heartbeat event (see events.NewHeartbeatEvent) each
+	// time a run stays silent for this long, so that clients can tell it
+	// from a stalled run (0 = none). Runs override it with WithHeartbeat.
+	Heartbeat time.Duration
+
+	// MaxEventSize rejects events whose JSON encoding is larger than this
+	// many bytes (0 = unlimited), e.g. to stay within the message size
+	// limit of a transport. Messages snapshots over it are sent in chunks.
+	MaxEventSize int
+
+	// NestedSteps requires the steps of runs to be properly nested (see
+	// events.WithNestedSteps): finishing a step finishes the steps still
+	// open in it first, and the steps an agent leaves open are finished
+	// before the terminal event. The steps of a run are then listed by
+	// EventEmitter.Steps.
+	NestedSteps bool
+
+	// SizeBudget bounds the encoded size of events by type, checked by the
+	// transports as they encode events, and records the sizes encoded
+	// (serve them with its MetricsHandler). Events over their limit fail to
+	// emit with an *encoding.EventTooLargeError and the stream goes on.
+	SizeBudget *encoding.SizeBudget
+
+	// SharedFlow, when set, shares a rate of streamed content between the
+	// runs, fairly by their weight (see WithFlowWeight), on top of the flow
+	// control windows their clients open
+	SharedFlow *SharedFlowController
+
+	// AdaptiveFlow paces the streamed content of each run at a rate adapted
+	// to the latency of its consumer (see AdaptiveFlowController), when its
+	// MaxRate is set. Its clock defaults to Clock.
+	AdaptiveFlow AdaptiveFlowConfig
+
+	// Localizer renders the messages of RUN_ERROR events, in the locales of
+	// the context of the run (see i18n.WithLocales, set by Server from the
+	// Accept-Language header) before its own (nil = English)
+	Localizer *i18n.Localizer
+
+	// Budget bounds what each run may emit, and for how long (zero value =
+	// unlimited). Runs override it with WithRunBudget.
+	Budget RunBudget
+
+	// Production leaves the values agents panic with out of the RUN_ERROR
+	// events reporting them, which may hold secrets or internals; they are
+	// logged with their stack traces either way
+	Production bool
+}
+
+// Tap wraps the emitter of a run. The emitter it returns receives every
+// event of the run once validated, lifecycle events included, and must pass
+// them on to emitter.
+type Tap func(ctx context.Context, input *types.RunAgentInput, emitter Emitter) Emitter
+
+// Taps combines taps; the first sees the events first
+func Taps(taps ...Tap) Tap {
+	return func(ctx context.Context, input *types.RunAgentInput, emitter Emitter) Emitter {
+		for i := len(taps) - 1; i >= 0; i-- {
+			if taps[i] != nil {
+				emitter = taps[i](ctx, input, emitter)
+			}
+		}
+		return emitter
+	}
+}
+
+// RunManager executes agent runs and owns their lifecycle.
+// It emits RUN_STARTED before the agent is invoked and exactly one of
+// RUN_FINISHED or RUN_ERROR afterwards, including when the agent panics,
+// times out or the client disconnects.
+type RunManager struct {
+	agent  Agent
+	config RunManagerConfig
+	logger *slog.Logger
+	slots  chan struct{}
+
+	// shedding rejects new runs with ErrShedding
+	shedding atomic.Bool
+
+	mu      sync.Mutex
+	runs    map[tenantKey]activeRun
+	closed  bool
+	drained chan struct{}
+}
+
+// activeRun is a run in progress
+type activeRun struct {
+	cancel context.CancelCauseFunc
+	// flow meters the run, nil when its client opened no flow control
+	// window
+	flow *FlowController
+}
+
+// NewRunManager creates a run manager for the given agent
+func NewRunManager(agent Agent, config RunManagerConfig) *RunManager {
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+	config.Clock = clock.Or(config.Clock)
+	if config.AdaptiveFlow.Clock == nil {
+		config.AdaptiveFlow.Clock = config.Clock
+	}
+
+	m := &RunManager{
+		agent:  agent,
+		config: config,
+		logger: logging.ContextLogger(logging.ForComponent(config.Logger, "run_manager")),
+		runs:   make(map[tenantKey]activeRun),
+	}
+	if config.MaxConcurrentRuns > 0 {
+		m.slots = make(chan struct{}, config.MaxConcurrentRuns)
+	}
+	return m
+}
+
+// Run executes a single run, blocking until it completes.
+// Missing thread and run IDs are generated. ErrTooManyRuns, ErrShedding,
+// ErrRunAlreadyActive and ErrShuttingDown are returned before any event is
+// emitted so callers can reject the request; any other error means the run
+// was started and its terminal event has already been attempted, and is
+// tagged with the run (see runctx.WrapError). The agent is invoked with a
+// context carrying the run metadata, see runctx.From.
+func (m *RunManager) Run(ctx context.Context, input *types.RunAgentInput, emitter Emitter) error {
+	if input == nil {
+		return fmt.Errorf("run input cannot be nil")
+	}
+	if emitter == nil {
+		return fmt.Errorf("emitter cannot be nil")
+	}
+	if input.ThreadID == "" {
+		input.ThreadID = events.GenerateThreadID()
+	}
+	if input.RunID == "" {
+		input.RunID = events.GenerateRunID()
+	}
+	ctx = runctx.With(ctx, runctx.Metadata{ThreadID: input.ThreadID, RunID: input.RunID})
+
+	if m.shedding.Load() {
+		return ErrShedding
+	}
+	if !m.acquire() {
+		return ErrTooManyRuns
+	}
+	defer m.release()
+
+	runCtx, cancel := m.runContext(ctx)
+	defer cancel(nil)
+
+	key := scopeToTenant(ctx, input.RunID)
+	flow := flowControl(ctx)
+	if err := m.register(key, activeRun{cancel: cancel, flow: flow}); err != nil {
+		return err
+	}
+	defer m.unregister(key)
+
+	if m.config.Tap != nil {
+		emitter = m.config.Tap(ctx, input, emitter)
+	}
+	options := []events.ValidatorOption{events.WithMaxEventSize(m.config.MaxEventSize)}
+	if m.config.NestedSteps {
+		options = append(options, events.WithNestedSteps())
+	}
+	agentEmitter := newEventEmitter(emitter, options...)
+	if m.config.NestedSteps {
+		agentEmitter.steps = events.NewStepTracker(events.WithStepClock(m.config.Clock))
+	}
+	agentEmitter.flow = flow
+	agentEmitter.share = m.config.SharedFlow.Join(flowWeight(ctx))
+	agentEmitter.adaptive = NewAdaptiveFlowController(m.config.AdaptiveFlow)
+	if err := agentEmitter.emitLifecycle(runCtx, events.NewRunStartedEvent(input.ThreadID, input.RunID)); err != nil {
+		return fmt.Errorf("failed to emit RUN_STARTED: %w", err)
+	}
+
+	budget := m.runBudget(ctx)
+	if budget.MaxEvents > 0 || budget.MaxBytes > 0 {
+		agentEmitter.budget = &budgetMeter{budget: budget, cancel: cancel}
+	}
+	if budget.MaxDuration > 0 {
+		enforceDuration(runCtx, cancel, m.config.Clock, budget.MaxDuration)
+	}
+
+	stopHeartbeat := func() {}
+	if interval := m.heartbeatInterval(ctx); interval > 0 {
+		stopHeartbeat = m.startHeartbeat(runCtx, agentEmitter, interval)
+	}
+	runErr := m.invoke(runCtx, input, agentEmitter)
+	stopHeartbeat()
+	var panicErr *PanicError
+	if errors.As(runErr, &panicErr) {
+		m.logger.ErrorContext(ctx, "Agent panicked",
+			"severity", panicErr.Severity, "panic", panicErr.Value, "stack", string(panicErr.Stack))
+	}
+
+	// Terminal events are still attempted after cancellation so that
+	// emitters which can deliver them (e.g. buffered or logging emitters) do.
+	terminalCtx := context.WithoutCancel(ctx)
+
+	if closed, err := agentEmitter.closeSteps(terminalCtx); err != nil {
+		m.logger.WarnContext(ctx, "Failed to finish open steps", "error", err)
+	} else if closed > 0 {
+		m.logger.DebugContext(ctx, "Finished open steps", "count", closed)
+	}
+	agentEmitter.complete()
+
+	if terminal := m.terminalEvent(ctx, runCtx, input, runErr); terminal != nil {
+		m.logger.WarnContext(ctx, "Run failed", "code", *terminal.Code, "error", terminal.Message)
+		if err := agentEmitter.emitLifecycle(terminalCtx, terminal); err != nil {
+			return fmt.Errorf("failed to emit RUN_ERROR: %w", err)
+		}
+		if runErr == nil {
+			runErr = runCtx.Err()
+		}
+		return runctx.WrapError(ctx, runErr)
+	}
+
+	if err := agentEmitter.emitLifecycle(terminalCtx, events.NewRunFinishedEvent(input.ThreadID, input.RunID)); err != nil {
+		return fmt.Errorf("failed to emit RUN_FINISHED: %w", err)
+	}
+	m.logger.DebugContext(ctx, "Run finished")
+	return nil
+}
+
+// Shutdown stops accepting new runs and waits for in-flight runs to
+// complete. When ctx expires first, the remaining runs are cancelled and end
+// with a RUN_ERROR event carrying the SHUTDOWN code; Shutdown then waits
+// briefly for those events to be emitted and returns ctx.Err().
+func (m *RunManager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	m.closed = true
+	if len(m.runs) == 0 {
+		m.mu.Unlock()
+		return nil
+	}
+	if m.drained == nil {
+		m.drained = make(chan struct{})
+	}
+	drained := m.drained
+	m.mu.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+	}
+
+	m.mu.Lock()
+	for _, run := range m.runs {
+		run.cancel(ErrShuttingDown)
+	}
+	m.mu.Unlock()
+
+	m.logger.Warn("Shutdown deadline reached, cancelling in-flight runs")
+	select {
+	case <-drained:
+	case <-m.config.Clock.After(shutdownCancelWait):
+	}
+	return ctx.Err()
+}
+
+// Cancel cancels the run runID of the tenant in ctx, if any. The context of
+// the agent is cancelled with ErrRunCancelled as its cause, so that the
+// agent and the tools it executes stop, and the run ends with a RUN_ERROR
+// event with code RunErrorCodeCancelled. Cancel does not wait for the run
+// to end.
+func (m *RunManager) Cancel(ctx context.Context, runID string) error {
+	key := scopeToTenant(ctx, runID)
+	m.mu.Lock()
+	run, ok := m.runs[key]
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrRunNotFound, runID)
+	}
+	run.cancel(ErrRunCancelled)
+	return nil
+}
+
+// FlowControl returns the flow controller metering the run runID of the
+// tenant in ctx, see WithFlowControl. Runs not in progress, or whose client
+// opened no flow control window, fail with ErrRunNotFound.
+func (m *RunManager) FlowControl(ctx context.Context, runID string) (*FlowController, error) {
+	key := scopeToTenant(ctx, runID)
+	m.mu.Lock()
+	run, ok := m.runs[key]
+	m.mu.Unlock()
+	if !ok || run.flow == nil {
+		return nil, fmt.Errorf("%w: %s", ErrRunNotFound, runID)
+	}
+	return run.flow, nil
+}
+
+// Shed makes the run manager reject new runs with ErrShedding while
+// shedding, e.g. under memory pressure, leaving the runs in progress alone
+func (m *RunManager) Shed(shedding bool) {
+	m.shedding.Store(shedding)
+}
+
+// ActiveRuns returns the number of runs currently in progress
+func (m *RunManager) ActiveRuns() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.runs)
+}
+
+// invoke calls the agent, converting panics into a PanicError
+func (m *RunManager) invoke(ctx context.Context, input *types.RunAgentInput, emitter *EventEmitter) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{PanicError: agerrors.NewPanicError(r, debug.Stack())}
+		}
+	}()
+	return m.agent.HandleRun(ctx, input, emitter)
+}
+
+// terminalEvent returns the RUN_ERROR event for a failed run, or nil if the run succeeded
+func (m *RunManager) terminalEvent(parent, runCtx context.Context, input *types.RunAgentInput, runErr error) *events.RunErrorEvent {
+	var panicErr *PanicError
+	var agentErr *RunError
+	var budgetErr *BudgetExceededError
+
+	var code string
+	var msg i18n.Message
+	switch {
+	case errors.As(runErr, &panicErr) && m.config.Production:
+		code, msg = RunErrorCodePanic, i18n.NewMessage(MessageRunPanicRedacted, "agent panicked")
+	case errors.As(runErr, &panicErr):
+		code, msg = RunErrorCodePanic, i18n.NewMessage(MessageRunPanic, "agent panicked: {value}", "value", panicErr.Value)
+	case errors.As(context.Cause(runCtx), &budgetErr):
+		code, msg = RunErrorCodeBudgetExceeded, budgetErr.message()
+	case errors.Is(context.Cause(runCtx), ErrShuttingDown):
+		code, msg = RunErrorCodeShutdown, i18n.NewMessage(MessageRunShutdown, "server shutting down")
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded) && parent.Err() == nil:
+		code, msg = RunErrorCodeTimeout, i18n.NewMessage(MessageRunTimeout, "run exceeded timeout of {timeout}", "timeout", m.config.RunTimeout)
+	case errors.Is(context.Cause(runCtx), ErrRunCancelled):
+		code, msg = RunErrorCodeCancelled, i18n.NewMessage(MessageRunCancelledByClient, ErrRunCancelled.Error())
+	case runCtx.Err() != nil:
+		code, msg = RunErrorCodeCancelled, i18n.NewMessage(MessageRunCancelled, "run cancelled")
+	case errors.As(runErr, &agentErr):
+		code, msg = agentErr.Code, agentErr.message()
+		if code == "" {
+			code = RunErrorCodeAgentError
+		}
+	case runErr != nil:
+		code, msg = RunErrorCodeAgentError, i18n.Message{Default: runErr.Error()}
+	default:
+		return nil
+	}
+	localizer := m.config.Localizer.WithLocales(i18n.LocalesFromContext(parent)...)
+	return events.NewRunErrorEvent(localizer.Localize(msg),
+		events.WithErrorCode(code), events.WithRunID(input.RunID))
+}
+
+// runContext derives the context for a run, applying the configured timeout
+func (m *RunManager) runContext(ctx context.Context) (context.Context, context.CancelCauseFunc) {
+	runCtx, cancel := context.WithCancelCause(ctx)
+	if m.config.RunTimeout > 0 {
+		timeoutCtx, cancelTimeout := context.WithTimeout(runCtx, m.config.RunTimeout)
+		return timeoutCtx, func(cause error) {
+			cancel(cause)
+			cancelTimeout()
+		}
+	}
+	return runCtx, cancel
+}
+
+// acquire reserves a run slot without blocking
+func (m *RunManager) acquire() bool {
+	if m.slots == nil {
+		return true
+	}
+	select {
+	case m.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// release frees a run slot
+func (m *RunManager) release() {
+	if m.slots != nil {
+		<-m.slots
+	}
+}
+
+// tenantKey scopes an ID, e.g. a run ID or an idempotency key, to a tenant.
+// Tenant and ID are kept apart so that no pair of them can collide with
+// another, whatever characters they contain.
+type tenantKey struct {
+	tenant string
+	id     string
+}
+
+// scopeToTenant scopes id to the tenant in ctx, if any
+func scopeToTenant(ctx context.Context, id string) tenantKey {
+	tenant, _ := TenantFromContext(ctx)
+	return tenantKey{tenant: tenant, id: id}
+}
+
+// register records an active run
+func (m *RunManager) register(key tenantKey, run activeRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return ErrShuttingDown
+	}
+	if _, exists := m.runs[key]; exists {
+		return fmt.Errorf("%w: %s", ErrRunAlreadyActive, key.id)
+	}
+	m.runs[key] = run
+	return nil
+}
+
+// unregister removes an active run
+func (m *RunManager) unregister(key tenantKey) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.runs, key)
+	if m.drained != nil && len(m.runs) == 0 {
+		close(m.drained)
+		m.drained = nil
+	}
+}
@@ -0,0 +1,461 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	agerrors "github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/errors"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/i18n"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/logging"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/runctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingEmitter collects emitted events for assertions
+type recordingEmitter struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (r *recordingEmitter) Emit(_ context.Context, event events.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *recordingEmitter) types() []events.EventType {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([]events.EventType, len(r.events))
+	for i, e := range r.events {
+		result[i] = e.Type()
+	}
+	return result
+}
+
+func (r *recordingEmitter) last() events.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.events[len(r.events)-1]
+}
+
+func newTestInput() *types.RunAgentInput {
+	return &types.RunAgentInput{ThreadID: "thread-1", RunID: "run-1"}
+}
+
+func TestRunManagerLifecycle(t *testing.T) {
+	t.Run("successful run", func(t *testing.T) {
+		agent := AgentFunc(func(ctx context.Context, input *types.RunAgentInput, emitter *EventEmitter) error {
+			return emitter.Emit(ctx, events.NewStepStartedEvent("work"))
+		})
+		emitter := &recordingEmitter{}
+
+		err := NewRunManager(agent, RunManagerConfig{}).Run(context.Background(), newTestInput(), emitter)
+		require.NoError(t, err)
+		assert.Equal(t, []events.EventType{
+			events.EventTypeRunStarted,
+			events.EventTypeStepStarted,
+			events.EventTypeRunFinished,
+		}, emitter.types())
+	})
+
+	t.Run("generates missing IDs", func(t *testing.T) {
+		emitter := &recordingEmitter{}
+		input := &types.RunAgentInput{}
+
+		err := NewRunManager(AgentFunc(func(context.Context, *types.RunAgentInput, *EventEmitter) error { return nil }), RunManagerConfig{}).
+			Run(context.Background(), input, emitter)
+		require.NoError(t, err)
+		assert.NotEmpty(t, input.ThreadID)
+		assert.NotEmpty(t, input.RunID)
+		assert.Equal(t, input.RunID, emitter.last().RunID())
+	})
+
+	t.Run("agent error", func(t *testing.T) {
+		agent := AgentFunc(func(context.Context, *types.RunAgentInput, *EventEmitter) error {
+			return errors.New("model unavailable")
+		})
+		emitter := &recordingEmitter{}
+
+		err := NewRunManager(agent, RunManagerConfig{}).Run(context.Background(), newTestInput(), emitter)
+		require.EqualError(t, err, "model unavailable")
+
+		runErr, ok := emitter.last().(*events.RunErrorEvent)
+		require.True(t, ok)
+		assert.Equal(t, RunErrorCodeAgentError, *runErr.Code)
+		assert.Equal(t, "model unavailable", runErr.Message)
+		assert.Equal(t, "run-1", runErr.RunID())
+	})
+
+	t.Run("agent run error", func(t *testing.T) {
+		agent := AgentFunc(func(context.Context, *types.RunAgentInput, *EventEmitter) error {
+			return fmt.Errorf("upstream: %w", &RunError{Code: "QUOTA", Message: "out of tokens"})
+		})
+		emitter := &recordingEmitter{}
+
+		err := NewRunManager(agent, RunManagerConfig{}).Run(context.Background(), newTestInput(), emitter)
+		require.EqualError(t, err, "upstream: QUOTA: out of tokens")
+
+		runErr, ok := emitter.last().(*events.RunErrorEvent)
+		require.True(t, ok)
+		assert.Equal(t, "QUOTA", *runErr.Code)
+		assert.Equal(t, "out of tokens", runErr.Message)
+	})
+
+	t.Run("agent panic", func(t *testing.T) {
+		agent := AgentFunc(func(context.Context, *types.RunAgentInput, *EventEmitter) error {
+			panic("boom")
+		})
+		emitter := &recordingEmitter{}
+
+		err := NewRunManager(agent, RunManagerConfig{}).Run(context.Background(), newTestInput(), emitter)
+		var panicErr *PanicError
+		require.ErrorAs(t, err, &panicErr)
+		assert.Equal(t, "boom", panicErr.Value)
+		assert.NotEmpty(t, panicErr.Stack)
+
+		assert.Equal(t, agerrors.SeverityCritical, agerrors.GetSeverity(err))
+
+		runErr, ok := emitter.last().(*events.RunErrorEvent)
+		require.True(t, ok)
+		assert.Equal(t, RunErrorCodePanic, *runErr.Code)
+		assert.Equal(t, "agent panicked: boom", runErr.Message)
+	})
+
+	t.Run("agent panic in production", func(t *testing.T) {
+		agent := AgentFunc(func(context.Context, *types.RunAgentInput, *EventEmitter) error {
+			panic("secret token abc")
+		})
+		emitter := &recordingEmitter{}
+
+		var logs bytes.Buffer
+		config := RunManagerConfig{Production: true, Logger: slog.New(slog.NewTextHandler(&logs, nil))}
+		err := NewRunManager(agent, config).Run(context.Background(), newTestInput(), emitter)
+		var panicErr *PanicError
+		require.ErrorAs(t, err, &panicErr)
+		assert.NotEmpty(t, panicErr.Stack)
+
+		runErr, ok := emitter.last().(*events.RunErrorEvent)
+		require.True(t, ok)
+		assert.Equal(t, RunErrorCodePanic, *runErr.Code)
+		assert.Equal(t, "agent panicked", runErr.Message)
+		assert.Contains(t, logs.String(), "secret token abc")
+		assert.Contains(t, logs.String(), "stack=")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		agent := AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, _ *EventEmitter) error {
+			<-ctx.Done()
+			return nil
+		})
+		emitter := &recordingEmitter{}
+
+		err := NewRunManager(agent, RunManagerConfig{RunTimeout: 10 * time.Millisecond}).
+			Run(context.Background(), newTestInput(), emitter)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		runErr, ok := emitter.last().(*events.RunErrorEvent)
+		require.True(t, ok)
+		assert.Equal(t, RunErrorCodeTimeout, *runErr.Code)
+	})
+
+	t.Run("client disconnect", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		agent := AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, _ *EventEmitter) error {
+			cancel()
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		emitter := &recordingEmitter{}
+
+		err := NewRunManager(agent, RunManagerConfig{}).Run(ctx, newTestInput(), emitter)
+		require.ErrorIs(t, err, context.Canceled)
+
+		runErr, ok := emitter.last().(*events.RunErrorEvent)
+		require.True(t, ok)
+		assert.Equal(t, RunErrorCodeCancelled, *runErr.Code)
+	})
+}
+
+func TestRunManagerLocalizesRunErrors(t *testing.T) {
+	catalog := i18n.NewCatalog()
+	catalog.Add("de", map[string]string{
+		MessageRunPanic: "Agent abgestürzt: {value}",
+		"quota":         "Kontingent von {limit} Tokens erschöpft",
+	})
+	run := func(ctx context.Context, localizer *i18n.Localizer, runErr error) *events.RunErrorEvent {
+		agent := AgentFunc(func(context.Context, *types.RunAgentInput, *EventEmitter) error {
+			if runErr == nil {
+				panic("boom")
+			}
+			return runErr
+		})
+		emitter := &recordingEmitter{}
+		_ = NewRunManager(agent, RunManagerConfig{Localizer: localizer}).Run(ctx, newTestInput(), emitter)
+		event, ok := emitter.last().(*events.RunErrorEvent)
+		require.True(t, ok)
+		return event
+	}
+	quota := &RunError{Code: "QUOTA", Key: "quota", Message: "out of {limit} tokens", Params: map[string]any{"limit": 1000}}
+	german := i18n.WithLocales(context.Background(), []string{"de-AT"})
+
+	assert.Equal(t, "agent panicked: boom", run(context.Background(), nil, nil).Message)
+	assert.Equal(t, "Agent abgestürzt: boom", run(context.Background(), i18n.NewLocalizer(catalog, "de"), nil).Message)
+	assert.Equal(t, "Agent abgestürzt: boom", run(german, i18n.NewLocalizer(catalog), nil).Message, "the locales of the run come first")
+	assert.Equal(t, "out of 1000 tokens", run(context.Background(), nil, quota).Message)
+	assert.Equal(t, "Kontingent von 1000 Tokens erschöpft", run(german, i18n.NewLocalizer(catalog), quota).Message)
+	assert.Equal(t, "QUOTA: out of 1000 tokens", quota.Error())
+	assert.Equal(t, "plain {error}", run(german, i18n.NewLocalizer(catalog), errors.New("plain {error}")).Message)
+}
+
+func TestRunManagerGuardsLifecycleEvents(t *testing.T) {
+	var emitErr error
+	agent := AgentFunc(func(ctx context.Context, input *types.RunAgentInput, emitter *EventEmitter) error {
+		emitErr = emitter.Emit(ctx, events.NewRunFinishedEvent(input.ThreadID, input.RunID))
+		return nil
+	})
+	emitter := &recordingEmitter{}
+
+	require.NoError(t, NewRunManager(agent, RunManagerConfig{}).Run(context.Background(), newTestInput(), emitter))
+	assert.ErrorIs(t, emitErr, ErrLifecycleEvent)
+	assert.Equal(t, []events.EventType{events.EventTypeRunStarted, events.EventTypeRunFinished}, emitter.types())
+}
+
+func TestRunManagerRejectsEventsAfterCompletion(t *testing.T) {
+	var captured *EventEmitter
+	agent := AgentFunc(func(_ context.Context, _ *types.RunAgentInput, emitter *EventEmitter) error {
+		captured = emitter
+		return nil
+	})
+
+	require.NoError(t, NewRunManager(agent, RunManagerConfig{}).Run(context.Background(), newTestInput(), &recordingEmitter{}))
+	assert.ErrorIs(t, captured.Emit(context.Background(), events.NewStepStartedEvent("late")), ErrRunCompleted)
+}
+
+func TestRunManagerTap(t *testing.T) {
+	agent := AgentFunc(func(ctx context.Context, input *types.RunAgentInput, emitter *EventEmitter) error {
+		return emitter.Emit(ctx, events.NewStepStartedEvent("work"))
+	})
+	tapped := &recordingEmitter{}
+	var tappedRun string
+	config := RunManagerConfig{Tap: func(_ context.Context, input *types.RunAgentInput, emitter Emitter) Emitter {
+		tappedRun = input.RunID
+		return EmitterFunc(func(ctx context.Context, event events.Event) error {
+			_ = tapped.Emit(ctx, event)
+			return emitter.Emit(ctx, event)
+		})
+	}}
+	emitter := &recordingEmitter{}
+
+	require.NoError(t, NewRunManager(agent, config).Run(context.Background(), &types.RunAgentInput{}, emitter))
+	assert.NotEmpty(t, tappedRun, "the tap sees the generated run ID")
+	assert.Equal(t, []events.EventType{
+		events.EventTypeRunStarted,
+		events.EventTypeStepStarted,
+		events.EventTypeRunFinished,
+	}, tapped.types())
+	assert.Equal(t, tapped.types(), emitter.types())
+}
+
+func TestTaps(t *testing.T) {
+	var order []string
+	tap := func(name string) Tap {
+		return func(_ context.Context, _ *types.RunAgentInput, emitter Emitter) Emitter {
+			return EmitterFunc(func(ctx context.Context, event events.Event) error {
+				if event.Type() == events.EventTypeRunStarted {
+					order = append(order, name)
+				}
+				return emitter.Emit(ctx, event)
+			})
+		}
+	}
+	agent := AgentFunc(func(context.Context, *types.RunAgentInput, *EventEmitter) error { return nil })
+	emitter := &recordingEmitter{}
+
+	config := RunManagerConfig{Tap: Taps(tap("first"), nil, tap("second"))}
+	require.NoError(t, NewRunManager(agent, config).Run(context.Background(), newTestInput(), emitter))
+	assert.Equal(t, []string{"first", "second"}, order)
+	assert.Equal(t, []events.EventType{events.EventTypeRunStarted, events.EventTypeRunFinished}, emitter.types())
+}
+
+func TestRunManagerConcurrency(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	agent := AgentFunc(func(context.Context, *types.RunAgentInput, *EventEmitter) error {
+		close(started)
+		<-release
+		return nil
+	})
+	manager := NewRunManager(agent, RunManagerConfig{MaxConcurrentRuns: 1})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- manager.Run(context.Background(), newTestInput(), &recordingEmitter{})
+	}()
+	<-started
+
+	assert.Equal(t, 1, manager.ActiveRuns())
+	emitter := &recordingEmitter{}
+	err := manager.Run(context.Background(), &types.RunAgentInput{ThreadID: "thread-2", RunID: "run-2"}, emitter)
+	assert.ErrorIs(t, err, ErrTooManyRuns)
+	assert.Empty(t, emitter.types())
+
+	close(release)
+	require.NoError(t, <-done)
+	assert.Equal(t, 0, manager.ActiveRuns())
+}
+
+func TestRunManagerDuplicateRun(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	agent := AgentFunc(func(context.Context, *types.RunAgentInput, *EventEmitter) error {
+		close(started)
+		<-release
+		return nil
+	})
+	manager := NewRunManager(agent, RunManagerConfig{})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- manager.Run(context.Background(), newTestInput(), &recordingEmitter{})
+	}()
+	<-started
+
+	err := manager.Run(context.Background(), newTestInput(), &recordingEmitter{})
+	assert.ErrorIs(t, err, ErrRunAlreadyActive)
+
+	close(release)
+	require.NoError(t, <-done)
+}
+
+func TestRunManagerCancel(t *testing.T) {
+	started := make(chan struct{})
+	agent := AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, _ *EventEmitter) error {
+		close(started)
+		<-ctx.Done()
+		assert.ErrorIs(t, context.Cause(ctx), ErrRunCancelled, "agents can tell a cancellation from a disconnect")
+		return ctx.Err()
+	})
+	manager := NewRunManager(agent, RunManagerConfig{})
+
+	emitter := &recordingEmitter{}
+	done := make(chan error, 1)
+	ctx := WithTenant(context.Background(), "acme")
+	go func() {
+		done <- manager.Run(ctx, newTestInput(), emitter)
+	}()
+	<-started
+
+	assert.ErrorIs(t, manager.Cancel(context.Background(), "run-1"), ErrRunNotFound, "runs are scoped to their tenant")
+	assert.ErrorIs(t, manager.Cancel(context.Background(), "acme/run-1"), ErrRunNotFound, "including run IDs naming the tenant")
+	assert.ErrorIs(t, manager.Cancel(ctx, "run-2"), ErrRunNotFound)
+	require.NoError(t, manager.Cancel(ctx, "run-1"))
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	runErr, ok := emitter.last().(*events.RunErrorEvent)
+	require.True(t, ok)
+	assert.True(t, runErr.Cancelled())
+	assert.Equal(t, ErrRunCancelled.Error(), runErr.Message)
+	assert.ErrorIs(t, manager.Cancel(ctx, "run-1"), ErrRunNotFound, "completed runs cannot be cancelled")
+}
+
+func TestRunManagerRunMetadata(t *testing.T) {
+	failure := errors.New("model unavailable")
+	agent := AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, _ *EventEmitter) error {
+		assert.Equal(t, runctx.Metadata{ThreadID: "thread-1", RunID: "run-1", Tenant: "acme"}, runctx.From(ctx))
+		return failure
+	})
+	var logs bytes.Buffer
+	manager := NewRunManager(agent, RunManagerConfig{Logger: slog.New(slog.NewJSONHandler(&logs, nil))})
+
+	err := manager.Run(WithTenant(context.Background(), "acme"), newTestInput(), &recordingEmitter{})
+	assert.ErrorIs(t, err, failure)
+	metadata, ok := runctx.FromError(err)
+	require.True(t, ok, "the error is tagged with the run")
+	assert.Equal(t, "run-1", metadata.RunID)
+
+	var logged map[string]any
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &logged))
+	assert.Equal(t, "Run failed", logged["msg"])
+	assert.Equal(t, "thread-1", logged[logging.KeyThreadID])
+	assert.Equal(t, "run-1", logged[logging.KeyRunID])
+	assert.Equal(t, "acme", logged[TenantLabel])
+}
+
+func TestRunManagerShutdown(t *testing.T) {
+	t.Run("drains in-flight runs", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{})
+		agent := AgentFunc(func(context.Context, *types.RunAgentInput, *EventEmitter) error {
+			close(started)
+			<-release
+			return nil
+		})
+		manager := NewRunManager(agent, RunManagerConfig{})
+		emitter := &recordingEmitter{}
+
+		done := make(chan error, 1)
+		go func() {
+			done <- manager.Run(context.Background(), newTestInput(), emitter)
+		}()
+		<-started
+
+		shutdown := make(chan error, 1)
+		go func() {
+			shutdown <- manager.Shutdown(context.Background())
+		}()
+		require.Eventually(t, func() bool {
+			err := manager.Run(context.Background(), &types.RunAgentInput{RunID: "run-2"}, &recordingEmitter{})
+			return errors.Is(err, ErrShuttingDown)
+		}, time.Second, time.Millisecond)
+
+		close(release)
+		require.NoError(t, <-done)
+		require.NoError(t, <-shutdown)
+		assert.Equal(t, events.EventTypeRunFinished, emitter.last().Type())
+	})
+
+	t.Run("cancels runs after deadline", func(t *testing.T) {
+		started := make(chan struct{})
+		agent := AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, _ *EventEmitter) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		manager := NewRunManager(agent, RunManagerConfig{})
+		emitter := &recordingEmitter{}
+
+		done := make(chan error, 1)
+		go func() {
+			done <- manager.Run(context.Background(), newTestInput(), emitter)
+		}()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, manager.Shutdown(ctx), context.DeadlineExceeded)
+		assert.ErrorIs(t, <-done, context.Canceled)
+
+		runErr, ok := emitter.last().(*events.RunErrorEvent)
+		require.True(t, ok)
+		assert.Equal(t, RunErrorCodeShutdown, *runErr.Code)
+		assert.Equal(t, 0, manager.ActiveRuns())
+	})
+
+	t.Run("idle", func(t *testing.T) {
+		manager := NewRunManager(AgentFunc(func(context.Context, *types.RunAgentInput, *EventEmitter) error { return nil }), RunManagerConfig{})
+		require.NoError(t, manager.Shutdown(context.Background()))
+		assert.ErrorIs(t, manager.Run(context.Background(), newTestInput(), &recordingEmitter{}), ErrShuttingDown)
+	})
+}
@@ -0,0 +1,329 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+// defaultMaxWait is the default wait after which an event is delivered
+// ahead of higher classes
+const defaultMaxWait = 500 * time.Millisecond
+
+// ErrSchedulerClosed is returned for events emitted through a closed
+// Scheduler
+var ErrSchedulerClosed = errors.New("scheduler closed")
+
+// WaitBuckets are the upper bounds, in seconds, of the buckets of the queue
+// wait histogram served by Scheduler.MetricsHandler
+var WaitBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+
+// SchedulerConfig configures a Scheduler
+type SchedulerConfig struct {
+	// Workers bounds the number of events delivered at once (defaults to
+	// GOMAXPROCS). Events queue when all workers are busy.
+	Workers int
+
+	// MaxWait protects lower classes from starvation: an event queued for
+	// this long is delivered before events of higher classes (defaults to
+	// 500ms)
+	MaxWait time.Duration
+
+	// Priority returns the class of an event (defaults to PriorityOf), e.g.
+	// to raise the class of some custom events. Classes out of the range of
+	// Priorities are clamped to it.
+	Priority func(events.Event) Priority
+
+	// Clock times the waits (defaults to the real clock)
+	Clock clock.Clock
+}
+
+// Scheduler delivers the events of many runs through a bounded number of
+// workers, servicing higher priority classes first (see PriorityOf),
+// so that under load lifecycle events and errors are not stuck behind bulk
+// content. Events of a run stay in order as long as they are emitted one at
+// a time, as an EventEmitter does, since each Emit returns once its event is
+// delivered.
+type Scheduler struct {
+	config SchedulerConfig
+
+	mu      sync.Mutex
+	queues  map[Priority][]*scheduled
+	stats   map[Priority]*QueueStats
+	buckets map[Priority][]uint64
+	closed  bool
+	wake    chan struct{}
+	done    chan struct{}
+	workers sync.WaitGroup
+}
+
+// scheduled is a queued event
+type scheduled struct {
+	ctx      context.Context
+	event    events.Event
+	sink     Emitter
+	class    Priority
+	enqueued time.Time
+	taken    bool
+	result   chan error
+}
+
+// QueueStats describes the queue of a priority class
+type QueueStats struct {
+	// Queued is the number of events waiting
+	Queued int `json:"queued"`
+
+	// Delivered counts the events dequeued, and Promoted those among them
+	// delivered ahead of higher classes after waiting MaxWait
+	Delivered uint64 `json:"delivered"`
+	Promoted  uint64 `json:"promoted"`
+
+	// TotalWait and MaxWait measure the time delivered events spent queued
+	TotalWait time.Duration `json:"totalWait"`
+	MaxWait   time.Duration `json:"maxWait"`
+}
+
+// MeanWait returns the mean time delivered events spent queued
+func (s QueueStats) MeanWait() time.Duration {
+	if s.Delivered == 0 {
+		return 0
+	}
+	return s.TotalWait / time.Duration(s.Delivered)
+}
+
+// NewScheduler creates a scheduler and starts its workers
+func NewScheduler(config SchedulerConfig) *Scheduler {
+	if config.Workers <= 0 {
+		config.Workers = runtime.GOMAXPROCS(0)
+	}
+	if config.MaxWait <= 0 {
+		config.MaxWait = defaultMaxWait
+	}
+	if config.Priority == nil {
+		config.Priority = PriorityOf
+	}
+	config.Clock = clock.Or(config.Clock)
+
+	s := &Scheduler{
+		config:  config,
+		queues:  make(map[Priority][]*scheduled),
+		stats:   make(map[Priority]*QueueStats),
+		buckets: make(map[Priority][]uint64),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	for _, class := range Priorities {
+		s.stats[class] = &QueueStats{}
+		s.buckets[class] = make([]uint64, len(WaitBuckets))
+	}
+	s.workers.Add(config.Workers)
+	for range config.Workers {
+		go s.work()
+	}
+	return s
+}
+
+// Wrap returns an emitter delivering to sink through the scheduler
+func (s *Scheduler) Wrap(sink Emitter) Emitter {
+	return EmitterFunc(func(ctx context.Context, event events.Event) error {
+		return s.emit(ctx, sink, event)
+	})
+}
+
+// Tap schedules the events of each run. It is meant for
+// RunManagerConfig.Tap, after the other taps so that they are not delayed.
+func (s *Scheduler) Tap(_ context.Context, _ *types.RunAgentInput, emitter Emitter) Emitter {
+	return s.Wrap(emitter)
+}
+
+// Close stops the workers once the events being delivered are done. Queued
+// events fail with ErrSchedulerClosed.
+func (s *Scheduler) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	close(s.done)
+	for class, queue := range s.queues {
+		for _, item := range queue {
+			item.result <- ErrSchedulerClosed
+		}
+		s.queues[class] = nil
+	}
+	s.mu.Unlock()
+	s.workers.Wait()
+}
+
+// Stats returns the state of the queue of each class
+func (s *Scheduler) Stats() map[Priority]QueueStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := make(map[Priority]QueueStats, len(s.stats))
+	for class, st := range s.stats {
+		st := *st
+		st.Queued = len(s.queues[class])
+		stats[class] = st
+	}
+	return stats
+}
+
+// emit queues event and waits for its delivery
+func (s *Scheduler) emit(ctx context.Context, sink Emitter, event events.Event) error {
+	class := min(max(s.config.Priority(event), PriorityBulk), PriorityHigh)
+	item := &scheduled{
+		ctx:      ctx,
+		event:    event,
+		sink:     sink,
+		class:    class,
+		enqueued: s.config.Clock.Now(),
+		result:   make(chan error, 1),
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrSchedulerClosed
+	}
+	s.queues[class] = append(s.queues[class], item)
+	s.mu.Unlock()
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	select {
+	case err := <-item.result:
+		return err
+	case <-ctx.Done():
+	}
+	s.mu.Lock()
+	if !item.taken {
+		s.remove(item)
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+	s.mu.Unlock()
+	return <-item.result
+}
+
+// remove drops a queued item; s.mu must be held
+func (s *Scheduler) remove(item *scheduled) {
+	queue := s.queues[item.class]
+	for i, queued := range queue {
+		if queued == item {
+			s.queues[item.class] = append(queue[:i:i], queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// work delivers queued events until the scheduler is closed
+func (s *Scheduler) work() {
+	defer s.workers.Done()
+	for {
+		item := s.next()
+		if item == nil {
+			select {
+			case <-s.wake:
+				continue
+			case <-s.done:
+				return
+			}
+		}
+		// Pass the wake-up on to an idle worker for the events left queued
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+		item.result <- item.sink.Emit(item.ctx, item.event)
+	}
+}
+
+// next dequeues the event to deliver: the oldest event that waited
+// MaxWait, or else the first event of the highest class
+func (s *Scheduler) next() *scheduled {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	now := s.config.Clock.Now()
+
+	var pick *scheduled
+	promoted := false
+	for _, class := range Priorities {
+		if queue := s.queues[class]; len(queue) > 0 {
+			if pick == nil {
+				pick = queue[0]
+			} else if now.Sub(queue[0].enqueued) >= s.config.MaxWait && queue[0].enqueued.Before(pick.enqueued) {
+				pick, promoted = queue[0], true
+			}
+		}
+	}
+	if pick == nil {
+		return nil
+	}
+	s.queues[pick.class] = s.queues[pick.class][1:]
+	pick.taken = true
+
+	wait := now.Sub(pick.enqueued)
+	stats := s.stats[pick.class]
+	stats.Delivered++
+	if promoted {
+		stats.Promoted++
+	}
+	stats.TotalWait += wait
+	stats.MaxWait = max(stats.MaxWait, wait)
+	for i, bound := range WaitBuckets {
+		if wait.Seconds() <= bound {
+			s.buckets[pick.class][i]++
+		}
+	}
+	return pick
+}
+
+// MetricsHandler serves the queue metrics in the Prometheus text exposition
+// format
+func (s *Scheduler) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		s.writeMetrics(w)
+	})
+}
+
+func (s *Scheduler) writeMetrics(w io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	const queued, promoted, wait = "agui_scheduler_queued_events", "agui_scheduler_promoted_events_total", "agui_scheduler_wait_seconds"
+
+	fmt.Fprintf(w, "# HELP %s Events waiting for delivery by class.\n# TYPE %s gauge\n", queued, queued)
+	for _, class := range Priorities {
+		fmt.Fprintf(w, "%s{class=%q} %d\n", queued, class, len(s.queues[class]))
+	}
+	fmt.Fprintf(w, "# HELP %s Events delivered ahead of higher classes to avoid starvation.\n# TYPE %s counter\n", promoted, promoted)
+	for _, class := range Priorities {
+		fmt.Fprintf(w, "%s{class=%q} %d\n", promoted, class, s.stats[class].Promoted)
+	}
+	fmt.Fprintf(w, "# HELP %s Time events spent queued by class.\n# TYPE %s histogram\n", wait, wait)
+	for _, class := range Priorities {
+		stats := s.stats[class]
+		for i, bound := range WaitBuckets {
+			fmt.Fprintf(w, "%s_bucket{class=%q,le=%q} %d\n", wait, class, strconv.FormatFloat(bound, 'g', -1, 64), s.buckets[class][i])
+		}
+		fmt.Fprintf(w, "%s_bucket{class=%q,le=\"+Inf\"} %d\n", wait, class, stats.Delivered)
+		fmt.Fprintf(w, "%s_sum{class=%q} %s\n", wait, class, strconv.FormatFloat(stats.TotalWait.Seconds(), 'g', -1, 64))
+		fmt.Fprintf(w, "%s_count{class=%q} %d\n", wait, class, stats.Delivered)
+	}
+}
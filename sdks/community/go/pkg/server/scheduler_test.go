@@ -0,0 +1,191 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/testhelper"
+)
+
+// blockedScheduler returns a single worker scheduler whose worker is busy
+// delivering a first event until the returned function is called, and the
+// sink recording the events delivered
+func blockedScheduler(t *testing.T, config SchedulerConfig) (*Scheduler, *recordingEmitter, func()) {
+	config.Workers = 1
+	scheduler := NewScheduler(config)
+	t.Cleanup(scheduler.Close)
+
+	sink := &recordingEmitter{}
+	release := make(chan struct{})
+	blocking := EmitterFunc(func(ctx context.Context, event events.Event) error {
+		if custom, ok := event.(*events.CustomEvent); ok && custom.Name == "first" {
+			<-release
+		}
+		return sink.Emit(ctx, event)
+	})
+	go func() { _ = scheduler.Wrap(blocking).Emit(context.Background(), events.NewCustomEvent("first")) }()
+	require.Eventually(t, func() bool { return scheduler.Stats()[PriorityNormal].Delivered == 1 }, time.Second, time.Millisecond)
+	return scheduler, sink, func() { close(release) }
+}
+
+// emitAsync emits event through emitter in the background once the queue
+// grows, returning the channel receiving the result
+func emitAsync(t *testing.T, scheduler *Scheduler, emitter Emitter, event events.Event) <-chan error {
+	queued := 0
+	for _, stats := range scheduler.Stats() {
+		queued += stats.Queued
+	}
+	result := make(chan error, 1)
+	go func() { result <- emitter.Emit(context.Background(), event) }()
+	require.Eventually(t, func() bool {
+		total := 0
+		for _, stats := range scheduler.Stats() {
+			total += stats.Queued
+		}
+		return total == queued+1
+	}, time.Second, time.Millisecond)
+	return result
+}
+
+func TestSchedulerPriorities(t *testing.T) {
+	scheduler, sink, release := blockedScheduler(t, SchedulerConfig{
+		Priority: func(event events.Event) Priority {
+			if custom, ok := event.(*events.CustomEvent); ok && custom.Name == "urgent" {
+				return PriorityHigh
+			}
+			return PriorityOf(event)
+		},
+	})
+	emitter := scheduler.Wrap(sink)
+
+	results := []<-chan error{
+		emitAsync(t, scheduler, emitter, events.NewTextMessageContentEvent("msg-1", "bulk")),
+		emitAsync(t, scheduler, emitter, events.NewTextMessageStartEvent("msg-2")),
+		emitAsync(t, scheduler, emitter, events.NewRunErrorEvent("failed")),
+		emitAsync(t, scheduler, emitter, events.NewCustomEvent("urgent")),
+	}
+	assert.Equal(t, 2, scheduler.Stats()[PriorityHigh].Queued)
+	release()
+	for _, result := range results {
+		require.NoError(t, <-result)
+	}
+
+	assert.Equal(t, []events.EventType{
+		events.EventTypeCustom,
+		events.EventTypeRunError,
+		events.EventTypeCustom,
+		events.EventTypeTextMessageStart,
+		events.EventTypeTextMessageContent,
+	}, sink.types())
+}
+
+func TestSchedulerStarvation(t *testing.T) {
+	clk := testhelper.NewFakeClock(time.Unix(0, 0))
+	scheduler, sink, release := blockedScheduler(t, SchedulerConfig{MaxWait: time.Second, Clock: clk})
+	emitter := scheduler.Wrap(sink)
+
+	bulk := emitAsync(t, scheduler, emitter, events.NewTextMessageContentEvent("msg-1", "waiting"))
+	clk.Advance(2 * time.Second)
+	high := emitAsync(t, scheduler, emitter, events.NewStepStartedEvent("plan"))
+	release()
+	require.NoError(t, <-bulk)
+	require.NoError(t, <-high)
+
+	assert.Equal(t, []events.EventType{
+		events.EventTypeCustom,
+		events.EventTypeTextMessageContent,
+		events.EventTypeStepStarted,
+	}, sink.types(), "the starved event goes first")
+	stats := scheduler.Stats()[PriorityBulk]
+	assert.Equal(t, uint64(1), stats.Promoted)
+	assert.Equal(t, 2*time.Second, stats.MaxWait)
+	assert.Equal(t, 2*time.Second, stats.MeanWait())
+}
+
+func TestSchedulerCancellation(t *testing.T) {
+	scheduler, sink, release := blockedScheduler(t, SchedulerConfig{})
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() { result <- scheduler.Wrap(sink).Emit(ctx, events.NewStepStartedEvent("plan")) }()
+	require.Eventually(t, func() bool { return scheduler.Stats()[PriorityHigh].Queued == 1 }, time.Second, time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-result, context.Canceled)
+	assert.Zero(t, scheduler.Stats()[PriorityHigh].Queued)
+}
+
+func TestSchedulerClose(t *testing.T) {
+	scheduler, sink, release := blockedScheduler(t, SchedulerConfig{})
+	emitter := scheduler.Wrap(sink)
+	queued := emitAsync(t, scheduler, emitter, events.NewStepStartedEvent("plan"))
+
+	closed := make(chan struct{})
+	go func() {
+		scheduler.Close()
+		close(closed)
+	}()
+	assert.True(t, errors.Is(<-queued, ErrSchedulerClosed))
+	release()
+	<-closed
+	assert.True(t, errors.Is(emitter.Emit(context.Background(), events.NewStepStartedEvent("plan")), ErrSchedulerClosed))
+	assert.Len(t, sink.types(), 1, "the event being delivered completes")
+}
+
+func TestSchedulerTap(t *testing.T) {
+	scheduler := NewScheduler(SchedulerConfig{Workers: 2})
+	defer scheduler.Close()
+	agent := AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, emitter *EventEmitter) error {
+		messageID, err := emitter.StartTextMessage(ctx, "assistant")
+		if err != nil {
+			return err
+		}
+		for range 20 {
+			if err := emitter.EmitContent(ctx, messageID, "."); err != nil {
+				return err
+			}
+		}
+		return emitter.EndTextMessage(ctx, messageID)
+	})
+	manager := NewRunManager(agent, RunManagerConfig{Tap: scheduler.Tap})
+
+	emitters := make([]*recordingEmitter, 4)
+	done := make(chan error, len(emitters))
+	for i := range emitters {
+		emitters[i] = &recordingEmitter{}
+		go func() {
+			done <- manager.Run(context.Background(), &types.RunAgentInput{RunID: string(rune('a' + i))}, emitters[i])
+		}()
+	}
+	for range emitters {
+		require.NoError(t, <-done)
+	}
+	for _, emitter := range emitters {
+		require.Len(t, emitter.events, 24)
+		require.NoError(t, events.ValidateSequence(emitter.events), "the events of each run stay in order")
+	}
+	stats := scheduler.Stats()
+	assert.Equal(t, uint64(80), stats[PriorityBulk].Delivered)
+	assert.Equal(t, uint64(8), stats[PriorityNormal].Delivered)
+	assert.Equal(t, uint64(8), stats[PriorityHigh].Delivered)
+
+	rec := httptest.NewRecorder()
+	scheduler.MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, line := range []string{
+		`agui_scheduler_queued_events{class="bulk"} 0`,
+		`agui_scheduler_wait_seconds_count{class="bulk"} 80`,
+		`agui_scheduler_wait_seconds_bucket{class="high",le="+Inf"} 8`,
+		`agui_scheduler_promoted_events_total{class="normal"} 0`,
+	} {
+		assert.Contains(t, body, line+"\n")
+	}
+}
@@ -0,0 +1,257 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding/sse"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/i18n"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/logging"
+)
+
+// defaultMaxBodyBytes bounds the size of a run request body
+const defaultMaxBodyBytes = 10 << 20
+
+// Config configures a Server
+type Config struct {
+	RunManagerConfig
+
+	// MaxBodyBytes limits the size of the request body (0 = 10MB)
+	MaxBodyBytes int64
+
+	// Extensions lists the application defined protocol extensions
+	// advertised besides those of package events
+	Extensions []string
+
+	// Idempotency configures the runs submitted with an idempotency key
+	Idempotency IdempotencyConfig
+}
+
+// Server is an http.Handler that accepts RunAgentInput requests and streams
+// the events produced by an agent back to the client as Server-Sent Events
+type Server struct {
+	runs         *RunManager
+	config       Config
+	logger       *slog.Logger
+	writer       *sse.SSEWriter
+	capabilities events.Capabilities
+	idempotency  *idempotencyRegistry
+}
+
+// NewServer creates a server for the given agent
+func NewServer(agent Agent, config Config) *Server {
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = defaultMaxBodyBytes
+	}
+
+	capabilities := events.DefaultCapabilities()
+	capabilities.Extensions = append(capabilities.Extensions, config.Extensions...)
+	capabilities.MaxEventSize = config.MaxEventSize
+	capabilities.MaxBodyBytes = config.MaxBodyBytes
+
+	return &Server{
+		runs:         NewRunManager(agent, config.RunManagerConfig),
+		config:       config,
+		logger:       logging.ContextLogger(logging.ForComponent(config.Logger, "server")),
+		writer:       sse.NewSSEWriter().WithLogger(logging.ContextLogger(config.Logger)).WithSizeBudget(config.SizeBudget),
+		capabilities: capabilities,
+		idempotency:  newIdempotencyRegistry(config.Idempotency, clock.Or(config.Clock)),
+	}
+}
+
+// RunManager returns the run manager used by the server
+func (s *Server) RunManager() *RunManager {
+	return s.runs
+}
+
+// Shutdown gracefully stops the server: new runs are rejected with 503
+// Service Unavailable while in-flight runs complete, and runs still going
+// when ctx expires end with a SHUTDOWN RUN_ERROR before their streams close.
+// It does not close listeners; call it before http.Server.Shutdown so that
+// streams are drained before connections are torn down.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.runs.Shutdown(ctx)
+}
+
+// Capabilities returns the capabilities advertised by the server
+func (s *Server) Capabilities() events.Capabilities {
+	capabilities := s.capabilities
+	capabilities.EventTypes = slices.Clone(capabilities.EventTypes)
+	capabilities.Formats = slices.Clone(capabilities.Formats)
+	capabilities.Extensions = slices.Clone(capabilities.Extensions)
+	return capabilities
+}
+
+// ServeHTTP handles a single run request, a GET request for the
+// capabilities of the server, or a DELETE request for runs/{id} below the
+// path the server is mounted at, cancelling the run (see RunManager.Cancel).
+// Run requests carrying an idempotency key (see IdempotencyConfig)
+// re-attach to the run of a previous request with the same key instead of
+// starting another, resuming after the event of the continuity token in
+// events.LastEventIDHeader, if any. Run requests opening a flow control window (see
+// events.FlowWindowHeader) are metered by a FlowController, which POST
+// requests for runs/{id}/flow grant credit to.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		if runID, ok := flowControlRunID(r); ok {
+			s.grantCredit(w, r, runID)
+			return
+		}
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(s.capabilities)
+		return
+	case http.MethodDelete:
+		s.cancelRun(w, r)
+		return
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var input types.RunAgentInput
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.config.MaxBodyBytes))
+	if err == nil {
+		err = json.Unmarshal(body, &input)
+	}
+	if err != nil {
+		s.logger.Debug("Failed to parse request body", "error", err)
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := input.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := events.NegotiateProtocolVersion(input.ProtocolVersion)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	shim, _ := events.NewProtocolShim(version)
+	shim.UpgradeInput(&input)
+	input.ProtocolVersion = version
+
+	ctx := r.Context()
+	if interval, ok, err := requestedHeartbeat(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if ok {
+		ctx = WithHeartbeat(ctx, interval)
+	}
+
+	if flow, err := requestedFlowControl(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if flow != nil {
+		ctx = WithFlowControl(ctx, flow)
+	}
+
+	if token, ok, err := requestedContinuityToken(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if ok {
+		ctx = WithContinuityToken(ctx, token)
+	}
+
+	if header, ok := r.Header[http.CanonicalHeaderKey(events.ExtensionsHeader)]; ok {
+		ctx = WithClientExtensions(ctx, events.ParseExtensions(strings.Join(header, ",")))
+	}
+	if locales := i18n.ParseAcceptLanguage(r.Header.Get("Accept-Language")); len(locales) > 0 {
+		ctx = i18n.WithLocales(ctx, locales)
+	}
+
+	w.Header().Set(events.ProtocolVersionHeader, version)
+	w.Header().Set(events.ExtensionsHeader, events.FormatExtensions(s.capabilities.Extensions))
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	wrap := func(emitter Emitter) Emitter {
+		return StampTenant(NewProtocolEmitter(emitter, shim))
+	}
+	if key := r.Header.Get(events.IdempotencyKeyHeader); key != "" {
+		err = s.runIdempotent(ctx, key, body, &input, wrap, NewResumableSSEEmitter(w, s.writer))
+	} else {
+		err = s.runs.Run(ctx, &input, wrap(NewSSEEmitter(w, s.writer)))
+	}
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrIdempotencyKeyReused):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, events.ErrInvalidContinuityToken):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrRunNotResumable):
+		http.Error(w, err.Error(), http.StatusGone)
+	case errors.Is(err, ErrTooManyRuns):
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+	case errors.Is(err, ErrRunAlreadyActive):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, ErrShedding):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	case errors.Is(err, ErrShuttingDown):
+		w.Header().Set("Connection", "close")
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		// The stream has already started and carries the RUN_ERROR event
+		s.logger.DebugContext(ctx, "Run ended with error", "error", err)
+	}
+}
+
+// cancelRun handles a DELETE request for runs/{id}
+func (s *Server) cancelRun(w http.ResponseWriter, r *http.Request) {
+	dir, runID := path.Split(strings.TrimSuffix(r.URL.Path, "/"))
+	if path.Base(dir) != "runs" || runID == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if err := s.runs.Cancel(r.Context(), runID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	s.logger.Debug("Run cancelled by client", logging.KeyRunID, runID)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// NewSSEEmitter creates an emitter that writes events to w as SSE frames.
+// Each event is flushed as soon as it is written; writes block until the
+// client has accepted the data, so a slow client slows down the run.
+func NewSSEEmitter(w io.Writer, writer *sse.SSEWriter) Emitter {
+	if writer == nil {
+		writer = sse.NewSSEWriter()
+	}
+	return EmitterFunc(func(ctx context.Context, event events.Event) error {
+		return writer.WriteEvent(ctx, w, event)
+	})
+}
+
+// NewProtocolEmitter creates an emitter converting events to the protocol
+// version of shim before passing them to emitter, which only receives the
+// events that version can express. Taps wrapping it see the events of the
+// current version.
+func NewProtocolEmitter(emitter Emitter, shim *events.ProtocolShim) Emitter {
+	return EmitterFunc(func(ctx context.Context, event events.Event) error {
+		for _, converted := range shim.Downgrade(event) {
+			if err := emitter.Emit(ctx, converted); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,231 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerStreamsRun(t *testing.T) {
+	agent := AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, emitter *EventEmitter) error {
+		messageID, err := emitter.StartTextMessage(ctx, "assistant")
+		if err != nil {
+			return err
+		}
+		if err := emitter.EmitContent(ctx, messageID, "Hello"); err != nil {
+			return err
+		}
+		return emitter.EndTextMessage(ctx, messageID)
+	})
+	srv := NewServer(agent, Config{})
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"threadId":"thread-1","runId":"run-1"}`))
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	assert.True(t, rec.Flushed)
+
+	body := rec.Body.String()
+	for _, want := range []string{"RUN_STARTED", "TEXT_MESSAGE_START", "TEXT_MESSAGE_CONTENT", "TEXT_MESSAGE_END", "RUN_FINISHED"} {
+		assert.Contains(t, body, want)
+	}
+	assert.Equal(t, 5, strings.Count(body, "data: "))
+}
+
+func TestServerLocalizesRunErrors(t *testing.T) {
+	catalog := i18n.NewCatalog()
+	catalog.Add("fr", map[string]string{MessageRunPanic: "l'agent a planté : {value}"})
+	srv := NewServer(AgentFunc(func(context.Context, *types.RunAgentInput, *EventEmitter) error { panic("boom") }),
+		Config{RunManagerConfig: RunManagerConfig{Localizer: i18n.NewLocalizer(catalog)}})
+
+	for header, want := range map[string]string{
+		"":                      "agent panicked: boom",
+		"fr-CA, en;q=0.5":       "l'agent a planté : boom",
+		"de, fr;q=0.8, *;q=0.1": "l'agent a planté : boom",
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"threadId":"thread-1","runId":"run-1"}`))
+		if header != "" {
+			req.Header.Set("Accept-Language", header)
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		assert.Contains(t, rec.Body.String(), `"message":"`+want+`"`, header)
+	}
+}
+
+func TestServerSheddingLoad(t *testing.T) {
+	srv := NewServer(AgentFunc(func(context.Context, *types.RunAgentInput, *EventEmitter) error { return nil }), Config{})
+	srv.RunManager().Shed(true)
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"threadId":"thread-1","runId":"run-1"}`)))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	srv.RunManager().Shed(false)
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"threadId":"thread-1","runId":"run-1"}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestServerRejectsBadRequests(t *testing.T) {
+	srv := NewServer(AgentFunc(func(context.Context, *types.RunAgentInput, *EventEmitter) error { return nil }), Config{})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.Equal(t, "GET, POST, DELETE", rec.Header().Get("Allow"))
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{")))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"threadId":"thread-1","runId":"run-1","messages":[{"id":"msg-1","role":"tool","content":"42"}]}`)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "messages[0]: tool messages require toolCallId")
+	})
+
+	t.Run("unsupported protocol version", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"threadId":"thread-1","runId":"run-1","protocolVersion":"2.0"}`)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestServerCancelsRuns(t *testing.T) {
+	started := make(chan struct{})
+	srv := NewServer(AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, _ *EventEmitter) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}), Config{})
+
+	run := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		srv.ServeHTTP(run, httptest.NewRequest(http.MethodPost, "/agent", strings.NewReader(`{"threadId":"thread-1","runId":"run-1"}`)))
+		close(done)
+	}()
+	<-started
+
+	for target, want := range map[string]int{
+		"/agent/runs/run-2": http.StatusNotFound,
+		"/agent/run-1":      http.StatusNotFound,
+		"/agent/runs/":      http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, target, nil))
+		assert.Equal(t, want, rec.Code, target)
+	}
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/agent/runs/run-1", nil))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	<-done
+	assert.Contains(t, run.Body.String(), `"code":"CANCELLED"`)
+}
+
+func TestServerProtocolVersion(t *testing.T) {
+	inputs := make(chan *types.RunAgentInput, 1)
+	srv := NewServer(AgentFunc(func(ctx context.Context, input *types.RunAgentInput, emitter *EventEmitter) error {
+		inputs <- input
+		for _, event := range []events.Event{
+			events.NewReasoningStartEvent("reasoning-1"),
+			events.NewReasoningMessageStartEvent("reasoning-1", "assistant"),
+			events.NewReasoningMessageContentEvent("reasoning-1", "Thinking"),
+			events.NewReasoningMessageEndEvent("reasoning-1"),
+			events.NewReasoningEndEvent("reasoning-1"),
+		} {
+			if err := emitter.Emit(ctx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	}), Config{})
+
+	run := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		require.Equal(t, http.StatusOK, rec.Code)
+		return rec
+	}
+
+	t.Run("current", func(t *testing.T) {
+		rec := run(`{"threadId":"thread-1","runId":"run-1"}`)
+		assert.Equal(t, events.ProtocolVersion, rec.Header().Get(events.ProtocolVersionHeader))
+		assert.Equal(t, events.ProtocolVersion, (<-inputs).ProtocolVersion)
+		assert.Contains(t, rec.Body.String(), `"protocolVersion":"`+events.ProtocolVersion+`"`)
+		assert.Contains(t, rec.Body.String(), "REASONING_MESSAGE_CONTENT")
+	})
+
+	t.Run("older client", func(t *testing.T) {
+		rec := run(`{"thread_id":"thread-1","run_id":"run-2","protocol_version":"0.1"}`)
+		assert.Equal(t, events.ProtocolVersion0_1, rec.Header().Get(events.ProtocolVersionHeader))
+		input := <-inputs
+		assert.Equal(t, events.ProtocolVersion0_1, input.ProtocolVersion)
+		assert.NotNil(t, input.Messages, "collections omitted by 0.1 clients are defaulted")
+
+		body := rec.Body.String()
+		assert.NotContains(t, body, "REASONING_")
+		for _, want := range []string{"THINKING_START", "THINKING_TEXT_MESSAGE_START", "THINKING_TEXT_MESSAGE_CONTENT", "THINKING_TEXT_MESSAGE_END", "THINKING_END"} {
+			assert.Contains(t, body, want)
+		}
+	})
+
+	t.Run("newer client", func(t *testing.T) {
+		rec := run(`{"threadId":"thread-1","runId":"run-3","protocolVersion":"0.9"}`)
+		assert.Equal(t, events.ProtocolVersion, rec.Header().Get(events.ProtocolVersionHeader))
+		<-inputs
+	})
+}
+
+func TestServerConcurrencyLimit(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	agent := AgentFunc(func(context.Context, *types.RunAgentInput, *EventEmitter) error {
+		close(started)
+		<-release
+		return nil
+	})
+	srv := NewServer(agent, Config{RunManagerConfig: RunManagerConfig{MaxConcurrentRuns: 1}})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"runId":"run-1"}`)))
+	}()
+	<-started
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"runId":"run-2"}`)))
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "RUN_STARTED")
+
+	close(release)
+	<-done
+}
+
+func TestServerShutdown(t *testing.T) {
+	srv := NewServer(AgentFunc(func(context.Context, *types.RunAgentInput, *EventEmitter) error { return nil }), Config{})
+	require.NoError(t, srv.Shutdown(context.Background()))
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "RUN_STARTED")
+}
@@ -0,0 +1,274 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/logging"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/runctx"
+)
+
+// ErrNoTenant is returned when a request cannot be attributed to a tenant
+var ErrNoTenant = errors.New("no tenant")
+
+// TenantLabel is the label and log attribute name carrying the tenant ID
+const TenantLabel = logging.KeyTenant
+
+// TenantResolver determines the tenant a request belongs to
+type TenantResolver interface {
+	// ResolveTenant returns the tenant ID for r, or an error wrapping ErrNoTenant
+	ResolveTenant(r *http.Request) (string, error)
+}
+
+// TenantResolverFunc adapts an ordinary function to the TenantResolver interface
+type TenantResolverFunc func(r *http.Request) (string, error)
+
+// ResolveTenant calls f(r)
+func (f TenantResolverFunc) ResolveTenant(r *http.Request) (string, error) {
+	return f(r)
+}
+
+// NewClaimTenantResolver resolves the tenant from a string claim of the
+// authenticated principal. It must run after Authenticate.
+func NewClaimTenantResolver(claim string) TenantResolver {
+	return TenantResolverFunc(func(r *http.Request) (string, error) {
+		principal, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			return "", ErrNoTenant
+		}
+		tenant, _ := principal.Claims[claim].(string)
+		if tenant == "" {
+			return "", ErrNoTenant
+		}
+		return tenant, nil
+	})
+}
+
+// NewHeaderTenantResolver resolves the tenant from a request header. Clients
+// choose the header, so it is only meant for requests from trusted upstreams,
+// e.g. a gateway authenticating the clients and setting it; otherwise
+// resolve the tenant from the claims of the authenticated principal.
+func NewHeaderTenantResolver(header string) TenantResolver {
+	return TenantResolverFunc(func(r *http.Request) (string, error) {
+		tenant := r.Header.Get(header)
+		if tenant == "" {
+			return "", ErrNoTenant
+		}
+		return tenant, nil
+	})
+}
+
+// FirstTenantResolver tries resolvers in order and returns the first tenant found
+func FirstTenantResolver(resolvers ...TenantResolver) TenantResolver {
+	return TenantResolverFunc(func(r *http.Request) (string, error) {
+		for _, resolver := range resolvers {
+			tenant, err := resolver.ResolveTenant(r)
+			if err == nil {
+				return tenant, nil
+			}
+			if !errors.Is(err, ErrNoTenant) {
+				return "", err
+			}
+		}
+		return "", ErrNoTenant
+	})
+}
+
+// WithTenant returns a copy of ctx carrying the tenant ID as part of its
+// run metadata, see runctx.With
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return runctx.With(ctx, runctx.Metadata{Tenant: tenantID})
+}
+
+// TenantFromContext returns the tenant ID stored in ctx, if any
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenant := runctx.From(ctx).Tenant
+	return tenant, tenant != ""
+}
+
+// MetricLabels returns the labels that metrics recorded for ctx should carry
+func MetricLabels(ctx context.Context) map[string]string {
+	labels := make(map[string]string)
+	if tenant, ok := TenantFromContext(ctx); ok {
+		labels[TenantLabel] = tenant
+	}
+	return labels
+}
+
+// ResolveTenant rejects requests that cannot be attributed to a tenant with
+// 403 Forbidden and stores the tenant of accepted requests in the request
+// context, see TenantFromContext. The context of runs, and of the emitters
+// of their events, carries it in turn.
+func ResolveTenant(resolver TenantResolver) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenant, err := resolver.ResolveTenant(r)
+			if err != nil {
+				http.Error(w, "tenant could not be determined", http.StatusForbidden)
+				return
+			}
+			if info, ok := r.Context().Value(requestInfoKey{}).(*requestInfo); ok {
+				info.tenant = tenant
+			}
+			next.ServeHTTP(w, r.WithContext(WithTenant(r.Context(), tenant)))
+		})
+	}
+}
+
+// TenantMetadataKey is the key of the tenant in the metadata of the events
+// stamped by StampTenant
+const TenantMetadataKey = "tenantId"
+
+// StampTenant wraps emitter so that events carry the tenant in ctx in their
+// metadata, under TenantMetadataKey. The rest of the events, including
+// their rawEvent, is left as produced.
+func StampTenant(emitter Emitter) Emitter {
+	return EmitterFunc(func(ctx context.Context, event events.Event) error {
+		if tenant, ok := TenantFromContext(ctx); ok {
+			if base := event.GetBaseEvent(); base != nil {
+				// The map may be shared with other events
+				metadata := maps.Clone(base.Metadata)
+				if metadata == nil {
+					metadata = make(map[string]string, 1)
+				}
+				metadata[TenantMetadataKey] = tenant
+				base.Metadata = metadata
+			}
+		}
+		return emitter.Emit(ctx, event)
+	})
+}
+
+// TenantRateLimitConfig configures per-tenant rate limiting
+type TenantRateLimitConfig struct {
+	// Default applies to the tenants without an explicit limit, which share
+	// a single bucket (zero value = unlimited)
+	Default RateLimitConfig `json:"default"`
+
+	// Tenants overrides the limit for individual tenants
+	Tenants map[string]RateLimitConfig `json:"tenants,omitempty"`
+}
+
+// defaultTenants is the key of the bucket shared by the tenants without an
+// explicit limit
+const defaultTenants = "default"
+
+// TenantRateLimit limits the aggregate request rate of each tenant listed in
+// config, and of all the others together, rejecting excess requests with 429
+// Too Many Requests. It must run after ResolveTenant.
+func TenantRateLimit(config TenantRateLimitConfig) Middleware {
+	limiters := make(map[string]*RateLimiter, len(config.Tenants))
+	for tenant, limit := range config.Tenants {
+		limiters[tenant] = NewRateLimiter(limit)
+	}
+	shared := NewRateLimiter(config.Default)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenant, ok := TenantFromContext(r.Context())
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			limiter, key := limiters[tenant], tenant
+			if limiter == nil {
+				limiter, key = shared, defaultTenants
+			}
+			if ok, retryAfter := limiter.Allow(key); !ok {
+				tooManyRequests(w, retryAfter)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// maxTenantStores is the number of stores kept before those of the least
+// recently used tenants are evicted
+const maxTenantStores = 10000
+
+// TenantStores keeps an isolated instance of a store per tenant, created on
+// first use. It is used to give each tenant its own state, session or
+// approval storage within a single deployment.
+//
+// Beyond 10000 tenants, the stores of the least recently used tenants are
+// evicted, and created again on their next use. Factories of stores whose
+// contents must survive eviction open persistent storage.
+type TenantStores[T any] struct {
+	factory func(tenantID string) T
+
+	mu     sync.Mutex
+	stores map[string]*tenantStore[T]
+	uses   uint64
+}
+
+type tenantStore[T any] struct {
+	store   T
+	lastUse uint64
+}
+
+// NewTenantStores creates a per-tenant store registry using factory to create stores
+func NewTenantStores[T any](factory func(tenantID string) T) *TenantStores[T] {
+	return &TenantStores[T]{
+		factory: factory,
+		stores:  make(map[string]*tenantStore[T]),
+	}
+}
+
+// Get returns the store of the tenant in ctx, or an error wrapping ErrNoTenant
+func (s *TenantStores[T]) Get(ctx context.Context) (T, error) {
+	tenant, ok := TenantFromContext(ctx)
+	if !ok {
+		var zero T
+		return zero, ErrNoTenant
+	}
+	return s.ForTenant(tenant), nil
+}
+
+// ForTenant returns the store of the given tenant
+func (s *TenantStores[T]) ForTenant(tenantID string) T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uses++
+	entry, ok := s.stores[tenantID]
+	if !ok {
+		if len(s.stores) >= maxTenantStores {
+			s.evict(len(s.stores) - maxTenantStores*9/10)
+		}
+		entry = &tenantStore[T]{store: s.factory(tenantID)}
+		s.stores[tenantID] = entry
+	}
+	entry.lastUse = s.uses
+	return entry.store
+}
+
+// evict removes the stores of the n least recently used tenants; callers
+// must hold mu
+func (s *TenantStores[T]) evict(n int) {
+	tenants := make([]string, 0, len(s.stores))
+	for tenant := range s.stores {
+		tenants = append(tenants, tenant)
+	}
+	sort.Slice(tenants, func(i, j int) bool {
+		return s.stores[tenants[i]].lastUse < s.stores[tenants[j]].lastUse
+	})
+	for _, tenant := range tenants[:n] {
+		delete(s.stores, tenant)
+	}
+}
+
+// Tenants returns the IDs of tenants with a store
+func (s *TenantStores[T]) Tenants() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tenants := make([]string, 0, len(s.stores))
+	for tenant := range s.stores {
+		tenants = append(tenants, tenant)
+	}
+	return tenants
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantResolvers(t *testing.T) {
+	claims := AuthenticatorFunc(func(r *http.Request) (*Principal, error) {
+		return &Principal{ID: "alice", Claims: map[string]any{"org": "acme"}}, nil
+	})
+	resolver := FirstTenantResolver(NewHeaderTenantResolver("X-Tenant-ID"), NewClaimTenantResolver("org"))
+
+	var tenant string
+	handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant, _ = TenantFromContext(r.Context())
+	}), Authenticate(claims), ResolveTenant(resolver))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", nil))
+	assert.Equal(t, "acme", tenant)
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("X-Tenant-ID", "globex")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "globex", tenant)
+}
+
+func TestResolveTenantRejectsUnknown(t *testing.T) {
+	handler := Chain(okHandler, ResolveTenant(NewHeaderTenantResolver("X-Tenant-ID")))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestTenantRateLimit(t *testing.T) {
+	handler := Chain(okHandler,
+		ResolveTenant(NewHeaderTenantResolver("X-Tenant-ID")),
+		TenantRateLimit(TenantRateLimitConfig{
+			Default: RateLimitConfig{RequestsPerSecond: 0.001},
+			Tenants: map[string]RateLimitConfig{"premium": {RequestsPerSecond: 0.001, Burst: 3}},
+		}))
+
+	request := func(tenant string) int {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("X-Tenant-ID", tenant)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request("basic"))
+	assert.Equal(t, http.StatusTooManyRequests, request("basic"))
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, request("premium"))
+	}
+	assert.Equal(t, http.StatusTooManyRequests, request("premium"))
+
+	// Tenants without a limit share a bucket, so changing the header does
+	// not get around it
+	assert.Equal(t, http.StatusTooManyRequests, request("basic-2"))
+}
+
+func TestTenantStoresIsolation(t *testing.T) {
+	stores := NewTenantStores(func(string) *MemoryApprovalStore { return NewMemoryApprovalStore() })
+
+	_, err := stores.Get(context.Background())
+	assert.ErrorIs(t, err, ErrNoTenant)
+
+	acme, err := stores.Get(WithTenant(context.Background(), "acme"))
+	require.NoError(t, err)
+	require.NoError(t, acme.Save(context.Background(), &Approval{ID: "a1", Status: ApprovalStatusPending}))
+
+	globex := stores.ForTenant("globex")
+	_, err = globex.Get(context.Background(), "a1")
+	assert.ErrorIs(t, err, ErrApprovalNotFound)
+	assert.Same(t, acme, stores.ForTenant("acme"))
+	assert.ElementsMatch(t, []string{"acme", "globex"}, stores.Tenants())
+}
+
+func TestTenantStoresEviction(t *testing.T) {
+	stores := NewTenantStores(func(tenant string) *string { return &tenant })
+	acme := stores.ForTenant("acme")
+	for i := range maxTenantStores - 1 {
+		stores.ForTenant(fmt.Sprint("tenant-", i))
+	}
+	stores.ForTenant("acme")
+	require.Len(t, stores.Tenants(), maxTenantStores)
+
+	// The least recently used tenants are evicted past the limit
+	stores.ForTenant("globex")
+	assert.Len(t, stores.Tenants(), maxTenantStores*9/10+1)
+	assert.Same(t, acme, stores.ForTenant("acme"))
+	assert.NotContains(t, stores.Tenants(), "tenant-0")
+}
+
+func TestServerTenantContext(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	var tenants []string
+	srv := NewServer(AgentFunc(func(ctx context.Context, _ *types.RunAgentInput, emitter *EventEmitter) error {
+		tenant, _ := TenantFromContext(ctx)
+		tenants = append(tenants, tenant)
+		return emitter.Emit(ctx, events.NewRawEvent(map[string]any{"upstream": true}))
+	}), Config{RunManagerConfig: RunManagerConfig{Logger: logger}})
+	handler := Chain(srv, RequestLogger(logger), ResolveTenant(NewHeaderTenantResolver("X-Tenant-ID")))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"threadId":"thread-1","runId":"run-1"}`))
+	req.Header.Set("X-Tenant-ID", "acme")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	// The tenant travels with the context of the run and is stamped into
+	// the metadata of its events, leaving their payload as produced
+	assert.Equal(t, []string{"acme"}, tenants)
+	assert.Contains(t, rec.Body.String(), `"metadata":{"tenantId":"acme"},"event":{"upstream":true}`)
+	assert.Equal(t, strings.Count(rec.Body.String(), "data: "), strings.Count(rec.Body.String(), `"metadata":{"tenantId":"acme"}`))
+	assert.Contains(t, logs.String(), "tenant=acme")
+	assert.Equal(t, map[string]string{TenantLabel: "acme"}, MetricLabels(WithTenant(context.Background(), "acme")))
+}
+
+func TestStampTenant(t *testing.T) {
+	var emitted []events.Event
+	emitter := StampTenant(EmitterFunc(func(_ context.Context, event events.Event) error {
+		emitted = append(emitted, event)
+		return nil
+	}))
+	ctx := WithTenant(context.Background(), "acme")
+
+	shared := map[string]string{"region": "eu"}
+	step := events.NewStepStartedEvent("work")
+	step.Metadata = shared
+	raw := events.NewRawEvent(map[string]any{"upstream": true})
+	raw.RawEvent = "original"
+	require.NoError(t, emitter.Emit(ctx, step))
+	require.NoError(t, emitter.Emit(ctx, raw))
+	require.NoError(t, emitter.Emit(context.Background(), events.NewStepFinishedEvent("work")))
+
+	require.Len(t, emitted, 3)
+	assert.Equal(t, map[string]string{"region": "eu", TenantMetadataKey: "acme"}, emitted[0].GetBaseEvent().Metadata)
+	assert.Equal(t, map[string]string{"region": "eu"}, shared, "maps set by the agent are not modified")
+	assert.Equal(t, map[string]string{TenantMetadataKey: "acme"}, emitted[1].GetBaseEvent().Metadata)
+	assert.Equal(t, "original", emitted[1].GetBaseEvent().RawEvent, "rawEvent is left alone")
+	assert.Nil(t, emitted[2].GetBaseEvent().Metadata, "events without a tenant are not stamped")
+}
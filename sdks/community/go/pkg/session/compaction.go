@@ -0,0 +1,169 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+// SummaryMessageName is the name of summary messages created by compaction
+const SummaryMessageName = "conversation_summary"
+
+// Summarizer condenses messages into a summary that replaces them in the history
+type Summarizer interface {
+	// Summarize returns a summary of messages. The first message may be the
+	// summary from a previous compaction.
+	Summarize(ctx context.Context, messages []types.Message) (string, error)
+}
+
+// SummarizerFunc adapts an ordinary function to the Summarizer interface
+type SummarizerFunc func(ctx context.Context, messages []types.Message) (string, error)
+
+// Summarize calls f(ctx, messages)
+func (f SummarizerFunc) Summarize(ctx context.Context, messages []types.Message) (string, error) {
+	return f(ctx, messages)
+}
+
+// TokenCounter returns the number of model tokens a message occupies
+type TokenCounter func(message types.Message) int
+
+// EstimateTokens approximates the tokens of a message as a quarter of the
+// size of its JSON encoding, which is close enough for English text on most
+// tokenizers. Use a model specific TokenCounter for exact limits.
+func EstimateTokens(message types.Message) int {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return 0
+	}
+	return (len(data) + 3) / 4
+}
+
+// CompactionPolicy bounds the history of a session. When a limit is exceeded
+// the oldest messages are replaced by a summary message, or dropped when no
+// Summarizer is configured. Leading system and developer messages are always
+// kept, and tool results are never separated from the preceding messages.
+// Stores apply the policy whenever a session is saved.
+type CompactionPolicy struct {
+	// MaxMessages is the maximum number of messages kept (0 = unlimited)
+	MaxMessages int `json:"maxMessages"`
+
+	// MaxTokens is the maximum number of tokens kept (0 = unlimited)
+	MaxTokens int `json:"maxTokens"`
+
+	// Summarizer summarizes compacted messages (nil = drop them)
+	Summarizer Summarizer `json:"-"`
+
+	// CountTokens counts message tokens (defaults to EstimateTokens)
+	CountTokens TokenCounter `json:"-"`
+}
+
+// enabled reports whether the policy limits the history
+func (p CompactionPolicy) enabled() bool {
+	return p.MaxMessages > 0 || p.MaxTokens > 0
+}
+
+// Compact applies the policy to session and reports whether its history changed
+func (p CompactionPolicy) Compact(ctx context.Context, session *Session) (bool, error) {
+	if !p.enabled() {
+		return false, nil
+	}
+	if p.CountTokens == nil {
+		p.CountTokens = EstimateTokens
+	}
+	if p.fits(session.Messages) {
+		return false, nil
+	}
+
+	pinned := 0
+	for pinned < len(session.Messages) && isInstruction(session.Messages[pinned]) {
+		pinned++
+	}
+	head, history := session.Messages[:pinned], session.Messages[pinned:]
+
+	// Reserve a slot for the summary, whose size is only known once written
+	reserved := len(head)
+	if p.Summarizer != nil {
+		reserved++
+	}
+
+	var summary []types.Message
+	cut := p.boundary(history, 0, reserved, p.tokens(head))
+	for {
+		if p.Summarizer != nil && cut > 0 {
+			content, err := p.Summarizer.Summarize(ctx, history[:cut])
+			if err != nil {
+				return false, fmt.Errorf("failed to summarize session %s: %w", session.ID, err)
+			}
+			summary = []types.Message{{
+				ID:      uuid.New().String(),
+				Role:    types.RoleSystem,
+				Name:    SummaryMessageName,
+				Content: content,
+			}}
+		}
+
+		// A long summary may push the history over the token limit again, in
+		// which case more messages are folded into the next summary
+		next := p.boundary(history, cut, len(head)+len(summary), p.tokens(head)+p.tokens(summary))
+		if next == cut || p.Summarizer == nil {
+			break
+		}
+		cut = next
+	}
+
+	messages := make([]types.Message, 0, len(head)+len(summary)+len(history)-cut)
+	messages = append(messages, head...)
+	messages = append(messages, summary...)
+	messages = append(messages, history[cut:]...)
+	session.Messages = messages
+	return true, nil
+}
+
+// boundary returns the smallest index from start such that history[index:]
+// fits within the limits alongside reserved messages and tokens. The index
+// never lands on a tool result, which must follow the message that called it.
+func (p CompactionPolicy) boundary(history []types.Message, start, reservedMessages, reservedTokens int) int {
+	tokens := reservedTokens + p.tokens(history[start:])
+	cut := start
+	for cut < len(history) {
+		count := reservedMessages + len(history) - cut
+		if (p.MaxMessages <= 0 || count <= p.MaxMessages) &&
+			(p.MaxTokens <= 0 || tokens <= p.MaxTokens) &&
+			history[cut].Role != types.RoleTool {
+			break
+		}
+		tokens -= p.CountTokens(history[cut])
+		cut++
+	}
+	return cut
+}
+
+// fits reports whether messages are within the policy limits
+func (p CompactionPolicy) fits(messages []types.Message) bool {
+	if p.MaxMessages > 0 && len(messages) > p.MaxMessages {
+		return false
+	}
+	return p.MaxTokens <= 0 || p.tokens(messages) <= p.MaxTokens
+}
+
+func (p CompactionPolicy) tokens(messages []types.Message) int {
+	if p.MaxTokens <= 0 {
+		return 0
+	}
+	total := 0
+	for _, message := range messages {
+		total += p.CountTokens(message)
+	}
+	return total
+}
+
+// isInstruction reports whether message is a system or developer instruction
+// rather than a previous summary
+func isInstruction(message types.Message) bool {
+	return (message.Role == types.RoleSystem || message.Role == types.RoleDeveloper) &&
+		message.Name != SummaryMessageName
+}
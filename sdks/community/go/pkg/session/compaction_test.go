@@ -0,0 +1,142 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+func conversation(n int) []types.Message {
+	messages := []types.Message{{ID: "sys", Role: types.RoleSystem, Content: "Be helpful"}}
+	for i := range n {
+		role := types.RoleUser
+		if i%2 == 1 {
+			role = types.RoleAssistant
+		}
+		messages = append(messages, types.Message{ID: fmt.Sprintf("msg-%d", i), Role: role, Content: "text"})
+	}
+	return messages
+}
+
+func messageIDs(messages []types.Message) []string {
+	ids := make([]string, len(messages))
+	for i, message := range messages {
+		ids[i] = message.ID
+	}
+	return ids
+}
+
+func TestCompactDropsOldestMessages(t *testing.T) {
+	session := New("thread-1")
+	session.Messages = conversation(6)
+
+	changed, err := CompactionPolicy{MaxMessages: 4}.Compact(context.Background(), session)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []string{"sys", "msg-3", "msg-4", "msg-5"}, messageIDs(session.Messages))
+}
+
+func TestCompactSummarizes(t *testing.T) {
+	var summarized [][]string
+	policy := CompactionPolicy{
+		MaxMessages: 4,
+		Summarizer: SummarizerFunc(func(_ context.Context, messages []types.Message) (string, error) {
+			summarized = append(summarized, messageIDs(messages))
+			return fmt.Sprintf("summary of %d messages", len(messages)), nil
+		}),
+	}
+
+	session := New("thread-1")
+	session.Messages = conversation(6)
+	changed, err := policy.Compact(context.Background(), session)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	require.Len(t, session.Messages, 4)
+	assert.Equal(t, "sys", session.Messages[0].ID)
+	summary := session.Messages[1]
+	assert.Equal(t, types.RoleSystem, summary.Role)
+	assert.Equal(t, SummaryMessageName, summary.Name)
+	assert.Equal(t, "summary of 4 messages", summary.Content)
+	assert.Equal(t, []string{"msg-4", "msg-5"}, messageIDs(session.Messages[2:]))
+
+	// A later compaction folds the previous summary into the new one
+	session.AppendMessages(conversation(7)[7:]...)
+	session.AppendMessages(types.Message{ID: "msg-new", Role: types.RoleUser, Content: "text"})
+	_, err = policy.Compact(context.Background(), session)
+	require.NoError(t, err)
+	assert.Equal(t, []string{summary.ID, "msg-4", "msg-5"}, summarized[1])
+	assert.Equal(t, []string{"msg-6", "msg-new"}, messageIDs(session.Messages[2:]))
+}
+
+func TestCompactKeepsToolResultsWithCalls(t *testing.T) {
+	session := New("thread-1")
+	session.Messages = []types.Message{
+		{ID: "user", Role: types.RoleUser, Content: "weather?"},
+		{ID: "call", Role: types.RoleAssistant, ToolCalls: []types.ToolCall{{ID: "tc-1", Type: "function"}}},
+		{ID: "result", Role: types.RoleTool, ToolCallID: "tc-1", Content: "sunny"},
+		{ID: "answer", Role: types.RoleAssistant, Content: "It is sunny"},
+	}
+
+	_, err := CompactionPolicy{MaxMessages: 2}.Compact(context.Background(), session)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"answer"}, messageIDs(session.Messages))
+}
+
+func TestCompactMaxTokens(t *testing.T) {
+	session := New("thread-1")
+	session.Messages = conversation(6)
+	count := func(types.Message) int { return 10 }
+
+	changed, err := CompactionPolicy{MaxTokens: 35, CountTokens: count}.Compact(context.Background(), session)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []string{"sys", "msg-4", "msg-5"}, messageIDs(session.Messages))
+
+	changed, err = CompactionPolicy{MaxTokens: 35, CountTokens: count}.Compact(context.Background(), session)
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestCompactSummarizerError(t *testing.T) {
+	failure := errors.New("model unavailable")
+	policy := CompactionPolicy{
+		MaxMessages: 2,
+		Summarizer: SummarizerFunc(func(context.Context, []types.Message) (string, error) {
+			return "", failure
+		}),
+	}
+	session := New("thread-1")
+	session.Messages = conversation(4)
+
+	_, err := policy.Compact(context.Background(), session)
+	assert.ErrorIs(t, err, failure)
+	assert.Len(t, session.Messages, 5)
+}
+
+func TestStoreAppliesCompaction(t *testing.T) {
+	for name, newStore := range backends() {
+		t.Run(name, func(t *testing.T) {
+			store, _ := newStore(t, Config{Compaction: CompactionPolicy{MaxMessages: 3}})
+			ctx := context.Background()
+
+			for i := range 5 {
+				_, err := store.Update(ctx, "thread-1", func(s *Session) error {
+					s.AppendMessages(types.Message{ID: fmt.Sprintf("msg-%d", i), Role: types.RoleUser})
+					return nil
+				})
+				require.NoError(t, err)
+			}
+
+			got, err := store.Get(ctx, "thread-1")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"msg-2", "msg-3", "msg-4"}, messageIDs(got.Messages))
+		})
+	}
+}
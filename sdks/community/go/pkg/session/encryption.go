@@ -0,0 +1,213 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// envelopeVersion prefixes encrypted sessions; it can never start a JSON document
+const envelopeVersion byte = 1
+
+var (
+	// ErrUnknownKey is returned when a session was encrypted with a key that is not available
+	ErrUnknownKey = errors.New("unknown encryption key")
+
+	// ErrInvalidCiphertext is returned when encrypted session data is malformed or has been tampered with
+	ErrInvalidCiphertext = errors.New("invalid session ciphertext")
+)
+
+// Cipher encrypts and decrypts stored sessions. The additional data is the
+// session ID, binding each ciphertext to the session it belongs to.
+type Cipher interface {
+	Encrypt(plaintext, additionalData []byte) ([]byte, error)
+	Decrypt(ciphertext, additionalData []byte) ([]byte, error)
+}
+
+// Key is a named AES key of 16, 24 or 32 bytes
+type Key struct {
+	ID       string
+	Material []byte
+}
+
+// KeyProvider supplies encryption keys. Implementations can load keys from
+// an OS keyring or unwrap data keys with a KMS.
+type KeyProvider interface {
+	// CurrentKey returns the key new data is encrypted with
+	CurrentKey() (Key, error)
+
+	// Key returns the key with the given ID or an error wrapping ErrUnknownKey
+	Key(id string) (Key, error)
+}
+
+// Keyring is a static KeyProvider. The first key is current; the others are
+// kept to decrypt sessions written before the key was rotated.
+type Keyring struct {
+	keys []Key
+}
+
+// NewKeyring creates a keyring whose current key is current
+func NewKeyring(current Key, previous ...Key) (*Keyring, error) {
+	keys := append([]Key{current}, previous...)
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if key.ID == "" || len(key.ID) > 255 {
+			return nil, fmt.Errorf("invalid key ID %q", key.ID)
+		}
+		if seen[key.ID] {
+			return nil, fmt.Errorf("duplicate key ID %q", key.ID)
+		}
+		seen[key.ID] = true
+		if _, err := aes.NewCipher(key.Material); err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", key.ID, err)
+		}
+	}
+	return &Keyring{keys: keys}, nil
+}
+
+// KeyringFromEnv creates a keyring from an environment variable holding
+// comma separated id:base64-key pairs, current key first, for example
+// AG_UI_SESSION_KEYS="2024-06:q5M...,2024-01:Z3h..."
+func KeyringFromEnv(name string) (*Keyring, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil, fmt.Errorf("environment variable %s is not set", name)
+	}
+	var keys []Key
+	for _, pair := range strings.Split(value, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid key in %s: expected id:base64-key", name)
+		}
+		material, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q in %s: %w", id, name, err)
+		}
+		keys = append(keys, Key{ID: id, Material: material})
+	}
+	return NewKeyring(keys[0], keys[1:]...)
+}
+
+// CurrentKey returns the key new data is encrypted with
+func (k *Keyring) CurrentKey() (Key, error) {
+	return k.keys[0], nil
+}
+
+// Key returns the key with the given ID
+func (k *Keyring) Key(id string) (Key, error) {
+	for _, key := range k.keys {
+		if key.ID == id {
+			return key, nil
+		}
+	}
+	return Key{}, fmt.Errorf("%w: %s", ErrUnknownKey, id)
+}
+
+// AESGCMCipher encrypts with AES-GCM using keys from a KeyProvider. Each
+// ciphertext records the ID of its key, so sessions remain readable after the
+// current key is rotated as long as the provider still returns the old key.
+type AESGCMCipher struct {
+	keys KeyProvider
+}
+
+// NewAESGCMCipher creates a cipher using keys from provider
+func NewAESGCMCipher(provider KeyProvider) *AESGCMCipher {
+	return &AESGCMCipher{keys: provider}
+}
+
+// Encrypt seals plaintext with the current key. The result is laid out as
+// version | key ID length | key ID | nonce | sealed data.
+func (c *AESGCMCipher) Encrypt(plaintext, additionalData []byte) ([]byte, error) {
+	key, err := c.keys.CurrentKey()
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, 2+len(key.ID)+aead.NonceSize())
+	header = append(header, envelopeVersion, byte(len(key.ID)))
+	header = append(header, key.ID...)
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	header = append(header, nonce...)
+	return aead.Seal(header, nonce, plaintext, additionalData), nil
+}
+
+// Decrypt opens ciphertext with the key it was encrypted with
+func (c *AESGCMCipher) Decrypt(ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < 2 || ciphertext[0] != envelopeVersion {
+		return nil, ErrInvalidCiphertext
+	}
+	idEnd := 2 + int(ciphertext[1])
+	if len(ciphertext) < idEnd {
+		return nil, ErrInvalidCiphertext
+	}
+	key, err := c.keys.Key(string(ciphertext[2:idEnd]))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonceEnd := idEnd + aead.NonceSize()
+	if len(ciphertext) < nonceEnd {
+		return nil, ErrInvalidCiphertext
+	}
+	plaintext, err := aead.Open(nil, ciphertext[idEnd:nonceEnd], ciphertext[nonceEnd:], additionalData)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return plaintext, nil
+}
+
+func newGCM(key Key) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key.Material)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key %q: %w", key.ID, err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// isPlaintext reports whether stored data is an unencrypted JSON session
+func isPlaintext(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("{"))
+}
+
+// Reencrypt rewrites every session in store, encrypting it with the
+// store's current key. Run it after rotating keys, or after enabling
+// encryption on a store holding plain sessions, before old keys are retired.
+// Rewriting a session refreshes its UpdatedAt and expiry. It returns the
+// number of sessions rewritten.
+func Reencrypt(ctx context.Context, store Store) (int, error) {
+	ids, err := store.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+		if _, err := store.Get(ctx, id); errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if _, err := store.Update(ctx, id, func(*Session) error { return nil }); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
@@ -0,0 +1,131 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+func testKey(id string, fill byte) Key {
+	return Key{ID: id, Material: bytes.Repeat([]byte{fill}, 32)}
+}
+
+func newTestCipher(t *testing.T, keys ...Key) *AESGCMCipher {
+	t.Helper()
+	keyring, err := NewKeyring(keys[0], keys[1:]...)
+	require.NoError(t, err)
+	return NewAESGCMCipher(keyring)
+}
+
+func TestAESGCMCipher(t *testing.T) {
+	c := newTestCipher(t, testKey("k1", 1))
+	plaintext := []byte(`{"id":"thread-1"}`)
+
+	ciphertext, err := c.Encrypt(plaintext, []byte("thread-1"))
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(ciphertext, []byte("thread-1")))
+	assert.False(t, isPlaintext(ciphertext))
+
+	got, err := c.Decrypt(ciphertext, []byte("thread-1"))
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, got)
+
+	// Ciphertexts are bound to their session
+	_, err = c.Decrypt(ciphertext, []byte("thread-2"))
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	tampered := bytes.Clone(ciphertext)
+	tampered[len(tampered)-1] ^= 1
+	_, err = c.Decrypt(tampered, []byte("thread-1"))
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	_, err = newTestCipher(t, testKey("k2", 2)).Decrypt(ciphertext, []byte("thread-1"))
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestNewKeyringValidatesKeys(t *testing.T) {
+	_, err := NewKeyring(Key{ID: "short", Material: []byte("too short")})
+	assert.Error(t, err)
+	_, err = NewKeyring(testKey("k1", 1), testKey("k1", 2))
+	assert.Error(t, err)
+	_, err = NewKeyring(testKey("", 1))
+	assert.Error(t, err)
+}
+
+func TestKeyringFromEnv(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	t.Setenv("TEST_SESSION_KEYS", "new:"+encoded+", old:"+encoded)
+
+	keyring, err := KeyringFromEnv("TEST_SESSION_KEYS")
+	require.NoError(t, err)
+	current, err := keyring.CurrentKey()
+	require.NoError(t, err)
+	assert.Equal(t, "new", current.ID)
+	_, err = keyring.Key("old")
+	assert.NoError(t, err)
+
+	t.Setenv("TEST_SESSION_KEYS", "missing-separator")
+	_, err = KeyringFromEnv("TEST_SESSION_KEYS")
+	assert.Error(t, err)
+}
+
+func TestEncryptedStores(t *testing.T) {
+	for name, newStore := range backends() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store, _ := newStore(t, Config{Cipher: newTestCipher(t, testKey("k1", 1))})
+
+			session := New("thread-1")
+			session.AppendMessages(types.Message{ID: "msg-1", Role: types.RoleUser, Content: "my password is hunter2"})
+			require.NoError(t, store.Save(ctx, session))
+
+			got, err := store.Get(ctx, "thread-1")
+			require.NoError(t, err)
+			assert.Equal(t, "my password is hunter2", got.Messages[0].Content)
+		})
+	}
+}
+
+func TestEncryptionMigrationAndRotation(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	open := func(config Config) *FileStore {
+		store, err := NewFileStore(dir, config)
+		require.NoError(t, err)
+		return store
+	}
+	readRaw := func() []byte {
+		data, err := os.ReadFile(open(Config{}).path("thread-1"))
+		require.NoError(t, err)
+		return data
+	}
+
+	// A plain session stays readable once encryption is enabled
+	require.NoError(t, open(Config{}).Save(ctx, New("thread-1")))
+	v1 := Config{Cipher: newTestCipher(t, testKey("k1", 1))}
+	_, err := open(v1).Get(ctx, "thread-1")
+	require.NoError(t, err)
+
+	count, err := Reencrypt(ctx, open(v1))
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.False(t, isPlaintext(readRaw()))
+
+	// After rotation, sessions under the old key remain readable until re-encrypted
+	v2 := Config{Cipher: newTestCipher(t, testKey("k2", 2), testKey("k1", 1))}
+	_, err = open(v2).Get(ctx, "thread-1")
+	require.NoError(t, err)
+	_, err = Reencrypt(ctx, open(v2))
+	require.NoError(t, err)
+
+	retired := Config{Cipher: newTestCipher(t, testKey("k2", 2))}
+	_, err = open(retired).Get(ctx, "thread-1")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,321 @@
+package session
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// fileExt is the extension of session files, backupExt that added to
+	// their backups
+	fileExt   = ".json"
+	backupExt = ".bak"
+
+	// lockFile is the file of the directory lock
+	lockFile = ".lock"
+
+	// maxLockPoll bounds the delay between attempts to lock the directory
+	maxLockPoll = 50 * time.Millisecond
+)
+
+// FileStore keeps each session as a JSON file in a directory, shared safely
+// by processes, e.g. several instances of a CLI: every operation holds an
+// advisory lock on the directory (flock on Unix, LockFileEx on Windows),
+// shared for reads and exclusive for writes, and sessions are written to a
+// temporary file, synced, then renamed over the previous one.
+//
+// Saving a session keeps its previous version as a backup, from which
+// sessions whose file is corrupted, e.g. truncated by a crash, or missing
+// after an interrupted save, are recovered.
+type FileStore struct {
+	dir    string
+	config Config
+	now    func() time.Time
+
+	mu sync.Mutex
+}
+
+// NewFileStore creates a store in dir, creating the directory if needed
+func NewFileStore(dir string, config Config) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create session directory: %w", err)
+	}
+	return &FileStore{dir: dir, config: config, now: time.Now}, nil
+}
+
+// Get returns the session with the given ID
+func (s *FileStore) Get(ctx context.Context, id string) (*Session, error) {
+	unlock, err := s.lock(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return s.get(id)
+}
+
+// Save creates or replaces a session
+func (s *FileStore) Save(ctx context.Context, session *Session) error {
+	unlock, err := s.lock(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return s.save(ctx, session)
+}
+
+// Update atomically modifies a session, also across processes
+func (s *FileStore) Update(ctx context.Context, id string, fn func(*Session) error) (*Session, error) {
+	unlock, err := s.lock(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	current, err := s.get(id)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	updated, err := apply(id, current, fn)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.save(ctx, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Delete removes a session and its backup
+func (s *FileStore) Delete(ctx context.Context, id string) error {
+	unlock, err := s.lock(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := s.remove(id); err != nil {
+		return fmt.Errorf("failed to delete session %s: %w", id, err)
+	}
+	return nil
+}
+
+// List returns the IDs of live sessions in ID order
+func (s *FileStore) List(ctx context.Context) ([]string, error) {
+	unlock, err := s.lock(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	ids, err := s.ids()
+	if err != nil {
+		return nil, err
+	}
+	live := ids[:0]
+	for _, id := range ids {
+		if _, err := s.get(id); err == nil {
+			live = append(live, id)
+		}
+	}
+	return live, nil
+}
+
+// Prune removes expired sessions and returns how many were removed
+func (s *FileStore) Prune(ctx context.Context) (int, error) {
+	unlock, err := s.lock(ctx, true)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+	ids, err := s.ids()
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, id := range ids {
+		if _, err := s.get(id); !errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err := s.remove(id); err != nil {
+			return removed, fmt.Errorf("failed to prune session %s: %w", id, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// Close is a no-op; FileStore holds no open files between calls
+func (s *FileStore) Close() error {
+	return nil
+}
+
+// lock locks the directory of the store, shared or exclusive, until the
+// returned function is called, waiting for other processes holding a
+// conflicting lock until ctx is done
+func (s *FileStore) lock(ctx context.Context, exclusive bool) (func(), error) {
+	s.mu.Lock()
+	f, err := os.OpenFile(filepath.Join(s.dir, lockFile), os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("failed to lock session directory: %w", err)
+	}
+	release := func() {
+		_ = f.Close()
+		s.mu.Unlock()
+	}
+	for delay := time.Millisecond; ; delay = min(2*delay, maxLockPoll) {
+		locked, err := tryLock(f, exclusive)
+		if err != nil {
+			release()
+			return nil, fmt.Errorf("failed to lock session directory: %w", err)
+		}
+		if locked {
+			return func() {
+				_ = unlock(f)
+				release()
+			}, nil
+		}
+		select {
+		case <-ctx.Done():
+			release()
+			return nil, fmt.Errorf("failed to lock session directory: %w", ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+// get reads a session, from its backup if its file is missing or
+// corrupted, reporting expired sessions as missing; callers must hold the
+// lock. Expired sessions are left to Prune and Delete, which hold the
+// exclusive lock.
+func (s *FileStore) get(id string) (*Session, error) {
+	if id == "" {
+		return nil, ErrInvalidID
+	}
+	session, err := s.read(id, s.path(id))
+	if err != nil {
+		backup, backupErr := s.read(id, s.path(id)+backupExt)
+		switch {
+		case backupErr == nil:
+			session = backup
+		case errors.Is(err, fs.ErrNotExist):
+			return nil, notFound(id)
+		default:
+			return nil, err
+		}
+	}
+	if session.Expired(s.now()) {
+		return nil, notFound(id)
+	}
+	return session, nil
+}
+
+// read reads and decodes the session id from the file at path
+func (s *FileStore) read(id, path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session %s: %w", id, err)
+	}
+	return s.config.decode(id, data)
+}
+
+// save writes a session, keeping its previous version, when readable, as
+// its backup; callers must hold the lock
+func (s *FileStore) save(ctx context.Context, session *Session) error {
+	if session == nil || session.ID == "" {
+		return ErrInvalidID
+	}
+	if err := s.config.prepare(ctx, session, s.now()); err != nil {
+		return err
+	}
+	data, err := s.config.encode(session)
+	if err != nil {
+		return err
+	}
+
+	// The backup is encoded anew so that it is encrypted like the session
+	path := s.path(session.ID)
+	if previous, err := s.read(session.ID, path); err == nil {
+		backup, err := s.config.encode(previous)
+		if err == nil {
+			err = s.write(path+backupExt, backup)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to back up session %s: %w", session.ID, err)
+		}
+	}
+	if err := s.write(path, data); err != nil {
+		return fmt.Errorf("failed to save session %s: %w", session.ID, err)
+	}
+	return nil
+}
+
+// write writes data to path through a temporary file, synced before it is
+// renamed over path
+func (s *FileStore) write(path string, data []byte) error {
+	tmp, err := os.CreateTemp(s.dir, ".session-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// remove removes the file of a session and its backup, the backup first so
+// that an interrupted removal does not bring the session back
+func (s *FileStore) remove(id string) error {
+	for _, path := range []string{s.path(id) + backupExt, s.path(id)} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// ids returns the IDs of all session files, including expired ones and
+// those only left with a backup
+func (s *FileStore) ids() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	var ids []string
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), backupExt)
+		if entry.IsDir() || !strings.HasSuffix(name, fileExt) {
+			continue
+		}
+		id, err := base64.RawURLEncoding.DecodeString(strings.TrimSuffix(name, fileExt))
+		if err != nil {
+			continue
+		}
+		ids = append(ids, string(id))
+	}
+	sort.Strings(ids)
+	return slices.Compact(ids), nil
+}
+
+// path returns the file of a session. IDs are encoded so that any ID maps to
+// a single file name inside the store directory.
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, base64.RawURLEncoding.EncodeToString([]byte(id))+fileExt)
+}
@@ -0,0 +1,154 @@
+package session
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStoreSharedDirectory(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// Stores sharing a directory, as separate processes do, are only
+	// serialized by the directory lock
+	var stores []*FileStore
+	for range 4 {
+		store, err := NewFileStore(dir, Config{})
+		require.NoError(t, err)
+		stores = append(stores, store)
+	}
+	var wg sync.WaitGroup
+	for i, store := range stores {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 25 {
+				_, err := store.Update(ctx, "thread-1", func(s *Session) error {
+					count, _ := s.Metadata["count"].(float64)
+					s.Metadata = map[string]any{"count": count + 1}
+					return nil
+				})
+				assert.NoError(t, err, "store %d update %d", i, j)
+			}
+		}()
+	}
+	wg.Wait()
+	got, err := stores[0].Get(ctx, "thread-1")
+	require.NoError(t, err)
+	assert.Equal(t, float64(100), got.Metadata["count"])
+
+	// Operations wait for the lock until their context is done
+	unlock, err := stores[0].lock(ctx, true)
+	require.NoError(t, err)
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = stores[1].Get(timeout, "thread-1")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	unlock()
+	_, err = stores[1].Get(ctx, "thread-1")
+	assert.NoError(t, err)
+}
+
+func TestFileStoreRecovery(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(t.TempDir(), Config{})
+	require.NoError(t, err)
+	save := func(version int) {
+		session := New("thread-1")
+		session.Metadata = map[string]any{"version": float64(version)}
+		require.NoError(t, store.Save(ctx, session))
+	}
+	version := func() any {
+		t.Helper()
+		got, err := store.Get(ctx, "thread-1")
+		require.NoError(t, err)
+		return got.Metadata["version"]
+	}
+	path := store.path("thread-1")
+
+	save(1)
+	save(2)
+	assert.Equal(t, float64(2), version())
+
+	// A corrupted session is read from its backup, the previous version
+	require.NoError(t, os.WriteFile(path, []byte(`{"id":"thread-1","mess`), 0o600))
+	assert.Equal(t, float64(1), version())
+
+	// Saving over a corrupted session keeps the last good backup
+	save(3)
+	require.NoError(t, os.Truncate(path, 0))
+	assert.Equal(t, float64(1), version())
+
+	// A session whose save was interrupted between the backup and the
+	// rename is read from its backup
+	save(4)
+	save(5)
+	require.NoError(t, os.Remove(path))
+	assert.Equal(t, float64(4), version())
+	ids, err := store.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"thread-1"}, ids)
+
+	require.NoError(t, store.Delete(ctx, "thread-1"))
+	_, err = store.Get(ctx, "thread-1")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = os.Stat(path + backupExt)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	// Sessions without a readable version fail with the error of their file
+	require.NoError(t, os.WriteFile(path, []byte("garbage"), 0o600))
+	_, err = store.Get(ctx, "thread-1")
+	assert.ErrorContains(t, err, "failed to decode session thread-1")
+}
+
+func TestFileStoreEncryptedBackups(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	plain, err := NewFileStore(dir, Config{})
+	require.NoError(t, err)
+	require.NoError(t, plain.Save(ctx, New("thread-1")))
+
+	// Backups are encrypted like the sessions, even those saved in plain
+	encrypted, err := NewFileStore(dir, Config{Cipher: newTestCipher(t, testKey("k1", 1))})
+	require.NoError(t, err)
+	_, err = Reencrypt(ctx, encrypted)
+	require.NoError(t, err)
+	for _, path := range []string{plain.path("thread-1"), plain.path("thread-1") + backupExt} {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.False(t, isPlaintext(data), path)
+	}
+}
+
+func TestFileStoreExpiry(t *testing.T) {
+	ctx := context.Background()
+	c := newClock()
+	store, err := NewFileStore(t.TempDir(), Config{TTL: time.Minute})
+	require.NoError(t, err)
+	store.now = c.Now
+	require.NoError(t, store.Save(ctx, New("thread-1")))
+	require.NoError(t, store.Save(ctx, New("thread-1")))
+	c.Advance(time.Hour)
+
+	// Reads, holding the shared lock, report expired sessions as missing
+	// without removing them
+	_, err = store.Get(ctx, "thread-1")
+	assert.ErrorIs(t, err, ErrNotFound)
+	ids, err := store.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+	assert.FileExists(t, store.path("thread-1"))
+	assert.FileExists(t, store.path("thread-1")+backupExt)
+
+	removed, err := store.Prune(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.NoFileExists(t, store.path("thread-1"))
+	assert.NoFileExists(t, store.path("thread-1")+backupExt)
+}
@@ -0,0 +1,16 @@
+//go:build !unix && !windows
+
+package session
+
+import "os"
+
+// tryLock always succeeds: processes sharing a directory are not locked out
+// of each other on this platform
+func tryLock(*os.File, bool) (bool, error) {
+	return true, nil
+}
+
+// unlock is a no-op on this platform
+func unlock(*os.File) error {
+	return nil
+}
@@ -0,0 +1,176 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryConfig configures a MemoryStore
+type MemoryConfig struct {
+	Config
+
+	// MaxSessions evicts the least recently saved sessions once exceeded (0 = unlimited)
+	MaxSessions int `json:"maxSessions"`
+}
+
+// MemoryStore keeps sessions in memory. Sessions are stored serialized so that
+// callers never share a session with the store.
+type MemoryStore struct {
+	config MemoryConfig
+	now    func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	data      []byte
+	updatedAt time.Time
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates an empty in-memory session store
+func NewMemoryStore(config MemoryConfig) *MemoryStore {
+	return &MemoryStore{
+		config:   config,
+		now:      time.Now,
+		sessions: make(map[string]memoryEntry),
+	}
+}
+
+// Get returns the session with the given ID
+func (s *MemoryStore) Get(_ context.Context, id string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.get(id)
+}
+
+// Save creates or replaces a session
+func (s *MemoryStore) Save(ctx context.Context, session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.save(ctx, session)
+}
+
+// Update atomically modifies a session
+func (s *MemoryStore) Update(ctx context.Context, id string, fn func(*Session) error) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.get(id)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	updated, err := apply(id, current, fn)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.save(ctx, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Delete removes a session
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+	return nil
+}
+
+// List returns the IDs of live sessions in ID order
+func (s *MemoryStore) List(_ context.Context) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(s.now())
+	ids := make([]string, 0, len(s.sessions))
+	for id := range s.sessions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Prune removes expired sessions and returns how many were removed
+func (s *MemoryStore) Prune(_ context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.prune(s.now()), nil
+}
+
+// Close releases the stored sessions
+func (s *MemoryStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = make(map[string]memoryEntry)
+	return nil
+}
+
+// get returns the session with the given ID; callers must hold mu. A missing
+// session is reported as a nil session with ErrNotFound.
+func (s *MemoryStore) get(id string) (*Session, error) {
+	entry, ok := s.sessions[id]
+	if ok && !entry.expiresAt.IsZero() && !s.now().Before(entry.expiresAt) {
+		delete(s.sessions, id)
+		ok = false
+	}
+	if !ok {
+		return nil, notFound(id)
+	}
+	return s.config.decode(id, entry.data)
+}
+
+// save stores a session and applies eviction; callers must hold mu
+func (s *MemoryStore) save(ctx context.Context, session *Session) error {
+	if session == nil || session.ID == "" {
+		return ErrInvalidID
+	}
+	now := s.now()
+	if err := s.config.prepare(ctx, session, now); err != nil {
+		return err
+	}
+	data, err := s.config.encode(session)
+	if err != nil {
+		return err
+	}
+	s.sessions[session.ID] = memoryEntry{data: data, updatedAt: now, expiresAt: session.ExpiresAt}
+
+	if s.config.MaxSessions > 0 && len(s.sessions) > s.config.MaxSessions {
+		s.prune(now)
+		s.evict(len(s.sessions) - s.config.MaxSessions)
+	}
+	return nil
+}
+
+// prune removes expired sessions; callers must hold mu
+func (s *MemoryStore) prune(now time.Time) int {
+	removed := 0
+	for id, entry := range s.sessions {
+		if !entry.expiresAt.IsZero() && !now.Before(entry.expiresAt) {
+			delete(s.sessions, id)
+			removed++
+		}
+	}
+	return removed
+}
+
+// evict removes the n least recently saved sessions; callers must hold mu
+func (s *MemoryStore) evict(n int) {
+	if n <= 0 {
+		return
+	}
+	ids := make([]string, 0, len(s.sessions))
+	for id := range s.sessions {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return s.sessions[ids[i]].updatedAt.Before(s.sessions[ids[j]].updatedAt)
+	})
+	for _, id := range ids[:n] {
+		delete(s.sessions, id)
+	}
+}
@@ -0,0 +1,159 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultRedisPrefix namespaces session keys
+const defaultRedisPrefix = "ag-ui:session:"
+
+// maxUpdateRetries bounds optimistic retries of a contended Update
+const maxUpdateRetries = 10
+
+// ErrConflict is returned when an Update keeps losing to concurrent writers
+var ErrConflict = errors.New("session update conflict")
+
+// RedisConfig configures a RedisStore
+type RedisConfig struct {
+	Config
+
+	// Prefix is prepended to session IDs to form keys (defaults to "ag-ui:session:")
+	Prefix string `json:"prefix"`
+}
+
+// RedisStore keeps sessions in Redis. Expiry uses Redis key TTLs, and Update
+// uses optimistic transactions, so it is safe across processes. The client is
+// owned by the caller; Close does not close it.
+type RedisStore struct {
+	client redis.UniversalClient
+	config RedisConfig
+	now    func() time.Time
+}
+
+// NewRedisStore creates a store using client
+func NewRedisStore(client redis.UniversalClient, config RedisConfig) *RedisStore {
+	if config.Prefix == "" {
+		config.Prefix = defaultRedisPrefix
+	}
+	return &RedisStore{client: client, config: config, now: time.Now}
+}
+
+// Get returns the session with the given ID
+func (s *RedisStore) Get(ctx context.Context, id string) (*Session, error) {
+	return s.get(ctx, s.client, id)
+}
+
+// Save creates or replaces a session
+func (s *RedisStore) Save(ctx context.Context, session *Session) error {
+	data, err := s.prepare(ctx, session)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Set(ctx, s.key(session.ID), data, s.config.TTL).Err(); err != nil {
+		return fmt.Errorf("failed to save session %s: %w", session.ID, err)
+	}
+	return nil
+}
+
+// Update atomically modifies a session, retrying when another writer
+// modifies it concurrently
+func (s *RedisStore) Update(ctx context.Context, id string, fn func(*Session) error) (*Session, error) {
+	if id == "" {
+		return nil, ErrInvalidID
+	}
+	key := s.key(id)
+
+	var updated *Session
+	for range maxUpdateRetries {
+		err := s.client.Watch(ctx, func(tx *redis.Tx) error {
+			current, err := s.get(ctx, tx, id)
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				return err
+			}
+			updated, err = apply(id, current, fn)
+			if err != nil {
+				return err
+			}
+			data, err := s.prepare(ctx, updated)
+			if err != nil {
+				return err
+			}
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(ctx, key, data, s.config.TTL)
+				return nil
+			})
+			return err
+		}, key)
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return updated, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrConflict, id)
+}
+
+// Delete removes a session
+func (s *RedisStore) Delete(ctx context.Context, id string) error {
+	if err := s.client.Del(ctx, s.key(id)).Err(); err != nil {
+		return fmt.Errorf("failed to delete session %s: %w", id, err)
+	}
+	return nil
+}
+
+// List returns the IDs of live sessions in ID order
+func (s *RedisStore) List(ctx context.Context) ([]string, error) {
+	var ids []string
+	iter := s.client.Scan(ctx, 0, s.config.Prefix+"*", 0).Iterator()
+	for iter.Next(ctx) {
+		ids = append(ids, strings.TrimPrefix(iter.Val(), s.config.Prefix))
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Close is a no-op; the client is owned by the caller
+func (s *RedisStore) Close() error {
+	return nil
+}
+
+func (s *RedisStore) get(ctx context.Context, client redis.Cmdable, id string) (*Session, error) {
+	if id == "" {
+		return nil, ErrInvalidID
+	}
+	data, err := client.Get(ctx, s.key(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, notFound(id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session %s: %w", id, err)
+	}
+	return s.config.decode(id, data)
+}
+
+// prepare compacts, stamps and encodes a session for saving
+func (s *RedisStore) prepare(ctx context.Context, session *Session) ([]byte, error) {
+	if session == nil || session.ID == "" {
+		return nil, ErrInvalidID
+	}
+	if err := s.config.prepare(ctx, session, s.now()); err != nil {
+		return nil, err
+	}
+	return s.config.encode(session)
+}
+
+func (s *RedisStore) key(id string) string {
+	return s.config.Prefix + id
+}
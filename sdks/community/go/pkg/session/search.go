@@ -0,0 +1,300 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+// Query selects sessions. Every criterion that is set must match.
+type Query struct {
+	// Text matches sessions whose messages contain every word of the text
+	Text string `json:"text,omitempty"`
+
+	// Labels matches sessions carrying all of the labels
+	Labels []string `json:"labels,omitempty"`
+
+	// Tools matches sessions that called any of the tools
+	Tools []string `json:"tools,omitempty"`
+
+	// Since matches sessions updated at or after this time
+	Since time.Time `json:"since,omitzero"`
+
+	// Until matches sessions updated before this time
+	Until time.Time `json:"until,omitzero"`
+
+	// Limit bounds the number of results (0 = unlimited)
+	Limit int `json:"limit,omitempty"`
+}
+
+// Index is an in-memory search index over sessions. It is updated
+// incrementally as sessions change and is safe for concurrent use.
+type Index struct {
+	mu    sync.RWMutex
+	docs  map[string]*indexEntry
+	terms map[string]map[string]struct{}
+}
+
+type indexEntry struct {
+	labels    map[string]struct{}
+	tools     map[string]struct{}
+	terms     []string
+	updatedAt time.Time
+}
+
+// NewIndex creates an empty index
+func NewIndex() *Index {
+	return &Index{
+		docs:  make(map[string]*indexEntry),
+		terms: make(map[string]map[string]struct{}),
+	}
+}
+
+// Add indexes a session, replacing any previous entry for it
+func (x *Index) Add(session *Session) {
+	entry := &indexEntry{
+		labels:    make(map[string]struct{}, len(session.Labels)),
+		tools:     make(map[string]struct{}),
+		updatedAt: session.UpdatedAt,
+	}
+	for _, label := range session.Labels {
+		entry.labels[label] = struct{}{}
+	}
+	terms := make(map[string]struct{})
+	for _, message := range session.Messages {
+		for _, call := range message.ToolCalls {
+			entry.tools[call.Function.Name] = struct{}{}
+		}
+		for _, term := range tokenize(messageText(message)) {
+			terms[term] = struct{}{}
+		}
+	}
+	for term := range terms {
+		entry.terms = append(entry.terms, term)
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.remove(session.ID)
+	x.docs[session.ID] = entry
+	for _, term := range entry.terms {
+		ids, ok := x.terms[term]
+		if !ok {
+			ids = make(map[string]struct{})
+			x.terms[term] = ids
+		}
+		ids[session.ID] = struct{}{}
+	}
+}
+
+// Remove drops a session from the index
+func (x *Index) Remove(id string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.remove(id)
+}
+
+// Search returns the IDs of matching sessions, most recently updated first
+func (x *Index) Search(query Query) []string {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	// Start from the rarest term so text queries scan as few sessions as possible
+	var candidates map[string]struct{}
+	words := tokenize(query.Text)
+	for _, word := range words {
+		ids := x.terms[word]
+		if candidates == nil || len(ids) < len(candidates) {
+			candidates = ids
+		}
+	}
+	if len(words) == 0 {
+		candidates = make(map[string]struct{}, len(x.docs))
+		for id := range x.docs {
+			candidates[id] = struct{}{}
+		}
+	}
+
+	var ids []string
+	for id := range candidates {
+		if x.matches(x.docs[id], id, query, words) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := x.docs[ids[i]].updatedAt, x.docs[ids[j]].updatedAt
+		if a.Equal(b) {
+			return ids[i] < ids[j]
+		}
+		return a.After(b)
+	})
+	if query.Limit > 0 && len(ids) > query.Limit {
+		ids = ids[:query.Limit]
+	}
+	return ids
+}
+
+// matches reports whether an indexed session satisfies query; callers must hold mu
+func (x *Index) matches(entry *indexEntry, id string, query Query, words []string) bool {
+	for _, word := range words {
+		if _, ok := x.terms[word][id]; !ok {
+			return false
+		}
+	}
+	for _, label := range query.Labels {
+		if _, ok := entry.labels[label]; !ok {
+			return false
+		}
+	}
+	if len(query.Tools) > 0 {
+		found := false
+		for _, tool := range query.Tools {
+			if _, ok := entry.tools[tool]; ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if !query.Since.IsZero() && entry.updatedAt.Before(query.Since) {
+		return false
+	}
+	if !query.Until.IsZero() && !entry.updatedAt.Before(query.Until) {
+		return false
+	}
+	return true
+}
+
+// remove drops a session from the index; callers must hold mu
+func (x *Index) remove(id string) {
+	entry, ok := x.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range entry.terms {
+		delete(x.terms[term], id)
+		if len(x.terms[term]) == 0 {
+			delete(x.terms, term)
+		}
+	}
+	delete(x.docs, id)
+}
+
+// IndexedStore wraps a Store and keeps an Index of its sessions up to date
+// with the changes made through it
+type IndexedStore struct {
+	Store
+	index *Index
+}
+
+// NewIndexedStore indexes the sessions in store and returns a store that
+// maintains the index incrementally. Changes made to store other than through
+// the returned store are not reflected in the index.
+func NewIndexedStore(ctx context.Context, store Store) (*IndexedStore, error) {
+	s := &IndexedStore{Store: store, index: NewIndex()}
+	ids, err := store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		session, err := store.Get(ctx, id)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.index.Add(session)
+	}
+	return s, nil
+}
+
+// Index returns the index maintained by the store
+func (s *IndexedStore) Index() *Index {
+	return s.index
+}
+
+// Save creates or replaces a session and indexes it
+func (s *IndexedStore) Save(ctx context.Context, session *Session) error {
+	if err := s.Store.Save(ctx, session); err != nil {
+		return err
+	}
+	s.index.Add(session)
+	return nil
+}
+
+// Update atomically modifies a session and indexes the result
+func (s *IndexedStore) Update(ctx context.Context, id string, fn func(*Session) error) (*Session, error) {
+	session, err := s.Store.Update(ctx, id, fn)
+	if err != nil {
+		return nil, err
+	}
+	s.index.Add(session)
+	return session, nil
+}
+
+// Delete removes a session and its index entry
+func (s *IndexedStore) Delete(ctx context.Context, id string) error {
+	if err := s.Store.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.index.Remove(id)
+	return nil
+}
+
+// Search returns the sessions matching query, most recently updated first.
+// Sessions that have expired since they were indexed are dropped from the index.
+func (s *IndexedStore) Search(ctx context.Context, query Query) ([]*Session, error) {
+	limit := query.Limit
+	query.Limit = 0
+
+	var sessions []*Session
+	for _, id := range s.index.Search(query) {
+		session, err := s.Store.Get(ctx, id)
+		if errors.Is(err, ErrNotFound) {
+			s.index.Remove(id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+		if limit > 0 && len(sessions) == limit {
+			break
+		}
+	}
+	return sessions, nil
+}
+
+// messageText returns the searchable text of a message
+func messageText(message types.Message) string {
+	if text, ok := message.ContentString(); ok {
+		return text
+	}
+	parts, ok := message.ContentInputContents()
+	if !ok {
+		return ""
+	}
+	var text []string
+	for _, part := range parts {
+		if part.Text != "" {
+			text = append(text, part.Text)
+		}
+	}
+	return strings.Join(text, " ")
+}
+
+// tokenize splits text into lower case words
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
@@ -0,0 +1,110 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+func newSearchStore(t *testing.T) (*IndexedStore, *clock) {
+	t.Helper()
+	c := newClock()
+	memory := NewMemoryStore(MemoryConfig{})
+	memory.now = c.Now
+	ctx := context.Background()
+
+	weather := New("weather")
+	weather.Labels = []string{"support", "vip"}
+	weather.AppendMessages(
+		types.Message{ID: "1", Role: types.RoleUser, Content: "What's the Weather in Paris?"},
+		types.Message{ID: "2", Role: types.RoleAssistant, ToolCalls: []types.ToolCall{
+			{ID: "tc-1", Type: "function", Function: types.FunctionCall{Name: "get_weather"}},
+		}},
+	)
+	require.NoError(t, memory.Save(ctx, weather))
+	c.Advance(time.Hour)
+
+	recipes := New("recipes")
+	recipes.Labels = []string{"support"}
+	recipes.AppendMessages(types.Message{ID: "1", Role: types.RoleUser, Content: []any{
+		map[string]any{"type": "text", "text": "A recipe for Paris-Brest"},
+	}})
+	require.NoError(t, memory.Save(ctx, recipes))
+
+	// Sessions saved before the index was created are indexed on construction
+	store, err := NewIndexedStore(ctx, memory)
+	require.NoError(t, err)
+	return store, c
+}
+
+func searchIDs(t *testing.T, store *IndexedStore, query Query) []string {
+	t.Helper()
+	sessions, err := store.Search(context.Background(), query)
+	require.NoError(t, err)
+	ids := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		ids = append(ids, session.ID)
+	}
+	return ids
+}
+
+func TestIndexedStoreSearch(t *testing.T) {
+	store, c := newSearchStore(t)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{"all, most recent first", Query{}, []string{"recipes", "weather"}},
+		{"text", Query{Text: "paris"}, []string{"recipes", "weather"}},
+		{"text requires every word", Query{Text: "weather paris"}, []string{"weather"}},
+		{"text without match", Query{Text: "london"}, []string{}},
+		{"labels", Query{Labels: []string{"support", "vip"}}, []string{"weather"}},
+		{"tools", Query{Tools: []string{"search", "get_weather"}}, []string{"weather"}},
+		{"since", Query{Since: start.Add(time.Minute)}, []string{"recipes"}},
+		{"until", Query{Until: start.Add(time.Minute)}, []string{"weather"}},
+		{"limit", Query{Limit: 1}, []string{"recipes"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, searchIDs(t, store, tt.query))
+		})
+	}
+
+	t.Run("incremental updates", func(t *testing.T) {
+		ctx := context.Background()
+		c.Advance(time.Hour)
+		_, err := store.Update(ctx, "weather", func(s *Session) error {
+			s.AppendMessages(types.Message{ID: "3", Role: types.RoleUser, Content: "And in London?"})
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"weather"}, searchIDs(t, store, Query{Text: "london"}))
+		assert.Equal(t, []string{"weather", "recipes"}, searchIDs(t, store, Query{}))
+
+		require.NoError(t, store.Delete(ctx, "weather"))
+		assert.Equal(t, []string{}, searchIDs(t, store, Query{Text: "paris weather"}))
+		assert.Equal(t, []string{"recipes"}, searchIDs(t, store, Query{Text: "paris"}))
+	})
+}
+
+func TestIndexedStoreSearchDropsExpiredSessions(t *testing.T) {
+	c := newClock()
+	memory := NewMemoryStore(MemoryConfig{Config: Config{TTL: time.Minute}})
+	memory.now = c.Now
+	store, err := NewIndexedStore(context.Background(), memory)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Save(context.Background(), New("old")))
+	c.Advance(2 * time.Minute)
+
+	assert.Equal(t, []string{}, searchIDs(t, store, Query{}))
+	assert.Empty(t, store.Index().Search(Query{}))
+}
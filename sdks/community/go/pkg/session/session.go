@@ -0,0 +1,239 @@
+// Package session persists conversation sessions: the message history, agent
+// state and metadata of a thread. A Store is safe for concurrent use and can
+// be shared by servers, which keep the history of the threads they serve, and
+// clients, which resume conversations across restarts.
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+var (
+	// ErrNotFound is returned when a session does not exist or has expired
+	ErrNotFound = errors.New("session not found")
+
+	// ErrInvalidID is returned for an empty session ID
+	ErrInvalidID = errors.New("invalid session ID")
+)
+
+// Session is the persisted state of a conversation thread
+type Session struct {
+	// ID identifies the session; it is used as the thread ID of runs
+	ID string `json:"id"`
+
+	// Messages is the message history of the thread
+	Messages []types.Message `json:"messages"`
+
+	// State is the latest agent state
+	State any `json:"state,omitempty"`
+
+	// Labels tag the session for search
+	Labels []string `json:"labels,omitempty"`
+
+	// Metadata holds application defined attributes
+	Metadata map[string]any `json:"metadata,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// ExpiresAt is when the session is evicted (zero = never)
+	ExpiresAt time.Time `json:"expiresAt,omitzero"`
+}
+
+// New creates an empty session
+func New(id string) *Session {
+	return &Session{ID: id, Messages: []types.Message{}}
+}
+
+// AppendMessages adds messages to the history, replacing existing messages
+// with the same ID so that snapshots can be applied repeatedly
+func (s *Session) AppendMessages(messages ...types.Message) {
+	for _, message := range messages {
+		replaced := false
+		for i := range s.Messages {
+			if message.ID != "" && s.Messages[i].ID == message.ID {
+				s.Messages[i] = message
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			s.Messages = append(s.Messages, message)
+		}
+	}
+}
+
+// UpdateMessage replaces the content of the message with the given ID and
+// reports whether the history has it
+func (s *Session) UpdateMessage(id, content string) bool {
+	for i := range s.Messages {
+		if s.Messages[i].ID == id {
+			s.Messages[i].Content = content
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteMessage removes the message with the given ID from the history and
+// reports whether the history had it
+func (s *Session) DeleteMessage(id string) bool {
+	for i := range s.Messages {
+		if s.Messages[i].ID == id {
+			s.Messages = append(s.Messages[:i], s.Messages[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyEvent updates the history with the events of a run changing it:
+// MESSAGES_SNAPSHOT, MESSAGE_UPDATED and MESSAGE_DELETED. It reports whether
+// the history changed, other events being ignored.
+func (s *Session) ApplyEvent(event events.Event) bool {
+	switch e := event.(type) {
+	case *events.MessagesSnapshotEvent:
+		s.AppendMessages(e.Messages...)
+		return len(e.Messages) > 0
+	case *events.MessageUpdatedEvent:
+		return s.UpdateMessage(e.MessageID, e.Content)
+	case *events.MessageDeletedEvent:
+		return s.DeleteMessage(e.MessageID)
+	}
+	return false
+}
+
+// RunInput creates the input for a new run continuing the session
+func (s *Session) RunInput(runID string) *types.RunAgentInput {
+	messages := make([]types.Message, len(s.Messages))
+	copy(messages, s.Messages)
+	return &types.RunAgentInput{
+		ThreadID: s.ID,
+		RunID:    runID,
+		State:    s.State,
+		Messages: messages,
+	}
+}
+
+// Expired reports whether the session has expired at now
+func (s *Session) Expired(now time.Time) bool {
+	return !s.ExpiresAt.IsZero() && !now.Before(s.ExpiresAt)
+}
+
+// Store persists sessions. Implementations are safe for concurrent use.
+type Store interface {
+	// Get returns the session with the given ID or ErrNotFound
+	Get(ctx context.Context, id string) (*Session, error)
+
+	// Save creates or replaces a session, updating its timestamps and expiry
+	Save(ctx context.Context, session *Session) error
+
+	// Update atomically applies fn to the session with the given ID, creating
+	// it if it does not exist, and saves the result. If fn returns an error
+	// the session is left unchanged.
+	Update(ctx context.Context, id string, fn func(*Session) error) (*Session, error)
+
+	// Delete removes a session; deleting a missing session is not an error
+	Delete(ctx context.Context, id string) error
+
+	// List returns the IDs of sessions that have not expired
+	List(ctx context.Context) ([]string, error)
+
+	// Close releases resources held by the store
+	Close() error
+}
+
+// Config configures a Store
+type Config struct {
+	// TTL evicts sessions that have not been saved for this long (0 = never)
+	TTL time.Duration `json:"ttl"`
+
+	// Compaction bounds the message history kept per session (zero value = unbounded)
+	Compaction CompactionPolicy `json:"compaction"`
+
+	// Cipher encrypts sessions at rest (nil = stored as plain JSON)
+	Cipher Cipher `json:"-"`
+}
+
+// prepare compacts a session being saved and stamps its timestamps and expiry
+func (c Config) prepare(ctx context.Context, session *Session, now time.Time) error {
+	if _, err := c.Compaction.Compact(ctx, session); err != nil {
+		return err
+	}
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = now
+	}
+	session.UpdatedAt = now
+	if c.TTL > 0 {
+		session.ExpiresAt = now.Add(c.TTL)
+	} else {
+		session.ExpiresAt = time.Time{}
+	}
+	return nil
+}
+
+// encode serializes, and encrypts if configured, a session for storage
+func (c Config) encode(session *Session) ([]byte, error) {
+	if session == nil || session.ID == "" {
+		return nil, ErrInvalidID
+	}
+	data, err := json.Marshal(session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode session %s: %w", session.ID, err)
+	}
+	if c.Cipher == nil {
+		return data, nil
+	}
+	data, err = c.Cipher.Encrypt(data, []byte(session.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt session %s: %w", session.ID, err)
+	}
+	return data, nil
+}
+
+// decode decrypts, if needed, and deserializes a stored session. Sessions
+// stored before encryption was enabled are read as plain JSON.
+func (c Config) decode(id string, data []byte) (*Session, error) {
+	if c.Cipher != nil && !isPlaintext(data) {
+		var err error
+		if data, err = c.Cipher.Decrypt(data, []byte(id)); err != nil {
+			return nil, fmt.Errorf("failed to decrypt session %s: %w", id, err)
+		}
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to decode session %s: %w", id, err)
+	}
+	if session.Messages == nil {
+		session.Messages = []types.Message{}
+	}
+	return &session, nil
+}
+
+// notFound returns ErrNotFound annotated with the session ID
+func notFound(id string) error {
+	return fmt.Errorf("%w: %s", ErrNotFound, id)
+}
+
+// apply runs fn against current, or a new session when current is nil, and
+// returns the session to save. Stores call it while holding their lock.
+func apply(id string, current *Session, fn func(*Session) error) (*Session, error) {
+	if id == "" {
+		return nil, ErrInvalidID
+	}
+	if current == nil {
+		current = New(id)
+	}
+	if err := fn(current); err != nil {
+		return nil, err
+	}
+	current.ID = id
+	return current, nil
+}
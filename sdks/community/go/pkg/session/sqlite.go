@@ -0,0 +1,177 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// sqliteSchema creates the sessions table. expires_at is a Unix timestamp in
+// milliseconds, 0 for sessions that never expire.
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS ag_ui_sessions (
+	id TEXT PRIMARY KEY,
+	data BLOB NOT NULL,
+	expires_at INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS ag_ui_sessions_expires_at ON ag_ui_sessions (expires_at);`
+
+// SQLiteStore keeps sessions in a SQLite database. The caller opens the
+// database with the driver of their choice (for example modernc.org/sqlite or
+// github.com/mattn/go-sqlite3) and owns it; Close does not close it.
+type SQLiteStore struct {
+	db     *sql.DB
+	config Config
+	now    func() time.Time
+
+	// mu serializes Update within the process; SQLite only supports one
+	// writer at a time and would otherwise fail concurrent transactions
+	mu sync.Mutex
+}
+
+// NewSQLiteStore creates a store in db, creating the sessions table if needed
+func NewSQLiteStore(ctx context.Context, db *sql.DB, config Config) (*SQLiteStore, error) {
+	if _, err := db.ExecContext(ctx, sqliteSchema); err != nil {
+		return nil, fmt.Errorf("failed to create sessions table: %w", err)
+	}
+	return &SQLiteStore{db: db, config: config, now: time.Now}, nil
+}
+
+// Get returns the session with the given ID
+func (s *SQLiteStore) Get(ctx context.Context, id string) (*Session, error) {
+	return s.get(ctx, s.db, id)
+}
+
+// Save creates or replaces a session
+func (s *SQLiteStore) Save(ctx context.Context, session *Session) error {
+	return s.save(ctx, s.db, session)
+}
+
+// Update atomically modifies a session
+func (s *SQLiteStore) Update(ctx context.Context, id string, fn func(*Session) error) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update session %s: %w", id, err)
+	}
+	defer tx.Rollback()
+
+	current, err := s.get(ctx, tx, id)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	updated, err := apply(id, current, fn)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.save(ctx, tx, updated); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to update session %s: %w", id, err)
+	}
+	return updated, nil
+}
+
+// Delete removes a session
+func (s *SQLiteStore) Delete(ctx context.Context, id string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM ag_ui_sessions WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete session %s: %w", id, err)
+	}
+	return nil
+}
+
+// List returns the IDs of live sessions in ID order
+func (s *SQLiteStore) List(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id FROM ag_ui_sessions WHERE expires_at = 0 OR expires_at > ? ORDER BY id`,
+		s.now().UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to list sessions: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return ids, nil
+}
+
+// Prune removes expired sessions and returns how many were removed
+func (s *SQLiteStore) Prune(ctx context.Context) (int, error) {
+	result, err := s.db.ExecContext(ctx,
+		`DELETE FROM ag_ui_sessions WHERE expires_at != 0 AND expires_at <= ?`, s.now().UnixMilli())
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune sessions: %w", err)
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune sessions: %w", err)
+	}
+	return int(removed), nil
+}
+
+// Close is a no-op; the database is owned by the caller
+func (s *SQLiteStore) Close() error {
+	return nil
+}
+
+// queryer is implemented by *sql.DB and *sql.Tx
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (s *SQLiteStore) get(ctx context.Context, q queryer, id string) (*Session, error) {
+	if id == "" {
+		return nil, ErrInvalidID
+	}
+	var data []byte
+	err := q.QueryRowContext(ctx,
+		`SELECT data FROM ag_ui_sessions WHERE id = ? AND (expires_at = 0 OR expires_at > ?)`,
+		id, s.now().UnixMilli()).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFound(id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session %s: %w", id, err)
+	}
+	return s.config.decode(id, data)
+}
+
+func (s *SQLiteStore) save(ctx context.Context, q queryer, session *Session) error {
+	if session == nil || session.ID == "" {
+		return ErrInvalidID
+	}
+	if err := s.config.prepare(ctx, session, s.now()); err != nil {
+		return err
+	}
+	data, err := s.config.encode(session)
+	if err != nil {
+		return err
+	}
+	var expiresAt int64
+	if !session.ExpiresAt.IsZero() {
+		expiresAt = session.ExpiresAt.UnixMilli()
+	}
+	_, err = q.ExecContext(ctx,
+		`INSERT INTO ag_ui_sessions (id, data, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET data = excluded.data, expires_at = excluded.expires_at`,
+		session.ID, data, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to save session %s: %w", session.ID, err)
+	}
+	return nil
+}
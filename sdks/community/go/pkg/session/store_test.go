@@ -0,0 +1,276 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+// clock is a manually advanced time source
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newClock() *clock {
+	return &clock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// backend creates a store using config and a clock that advances it
+type backend func(t *testing.T, config Config) (Store, func(time.Duration))
+
+func backends() map[string]backend {
+	return map[string]backend{
+		"memory": func(t *testing.T, config Config) (Store, func(time.Duration)) {
+			c := newClock()
+			store := NewMemoryStore(MemoryConfig{Config: config})
+			store.now = c.Now
+			return store, c.Advance
+		},
+		"file": func(t *testing.T, config Config) (Store, func(time.Duration)) {
+			c := newClock()
+			store, err := NewFileStore(t.TempDir(), config)
+			require.NoError(t, err)
+			store.now = c.Now
+			return store, c.Advance
+		},
+		"sqlite": func(t *testing.T, config Config) (Store, func(time.Duration)) {
+			c := newClock()
+			db, err := sql.Open("sqlite", fmt.Sprintf("file:%s/sessions.db?_pragma=busy_timeout(5000)", t.TempDir()))
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = db.Close() })
+			store, err := NewSQLiteStore(context.Background(), db, config)
+			require.NoError(t, err)
+			store.now = c.Now
+			return store, c.Advance
+		},
+		"redis": func(t *testing.T, config Config) (Store, func(time.Duration)) {
+			c := newClock()
+			mr := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			t.Cleanup(func() { _ = client.Close() })
+			store := NewRedisStore(client, RedisConfig{Config: config})
+			store.now = c.Now
+			return store, func(d time.Duration) {
+				c.Advance(d)
+				mr.FastForward(d)
+			}
+		},
+	}
+}
+
+func TestStores(t *testing.T) {
+	for name, newStore := range backends() {
+		t.Run(name, func(t *testing.T) {
+			t.Run("SaveAndGet", func(t *testing.T) {
+				store, _ := newStore(t, Config{})
+				ctx := context.Background()
+
+				session := New("thread-1")
+				session.AppendMessages(types.Message{ID: "msg-1", Role: types.RoleUser, Content: "Hello"})
+				session.State = map[string]any{"count": float64(1)}
+				session.Metadata = map[string]any{"title": "Greeting"}
+				require.NoError(t, store.Save(ctx, session))
+				assert.False(t, session.CreatedAt.IsZero())
+
+				got, err := store.Get(ctx, "thread-1")
+				require.NoError(t, err)
+				assert.Equal(t, "thread-1", got.ID)
+				require.Len(t, got.Messages, 1)
+				assert.Equal(t, "Hello", got.Messages[0].Content)
+				assert.Equal(t, map[string]any{"count": float64(1)}, got.State)
+				assert.Equal(t, "Greeting", got.Metadata["title"])
+				assert.True(t, got.CreatedAt.Equal(session.CreatedAt))
+				assert.True(t, got.ExpiresAt.IsZero())
+			})
+
+			t.Run("NotFound", func(t *testing.T) {
+				store, _ := newStore(t, Config{})
+				_, err := store.Get(context.Background(), "missing")
+				assert.ErrorIs(t, err, ErrNotFound)
+				assert.NoError(t, store.Delete(context.Background(), "missing"))
+				assert.ErrorIs(t, store.Save(context.Background(), New("")), ErrInvalidID)
+			})
+
+			t.Run("DeleteAndList", func(t *testing.T) {
+				store, _ := newStore(t, Config{})
+				ctx := context.Background()
+				for _, id := range []string{"b", "a/../x", "c"} {
+					require.NoError(t, store.Save(ctx, New(id)))
+				}
+				require.NoError(t, store.Delete(ctx, "c"))
+
+				ids, err := store.List(ctx)
+				require.NoError(t, err)
+				assert.Equal(t, []string{"a/../x", "b"}, ids)
+			})
+
+			t.Run("TTL", func(t *testing.T) {
+				store, advance := newStore(t, Config{TTL: time.Minute})
+				ctx := context.Background()
+				require.NoError(t, store.Save(ctx, New("short")))
+				advance(30 * time.Second)
+				require.NoError(t, store.Save(ctx, New("long")))
+				advance(45 * time.Second)
+
+				_, err := store.Get(ctx, "short")
+				assert.ErrorIs(t, err, ErrNotFound)
+				got, err := store.Get(ctx, "long")
+				require.NoError(t, err)
+				assert.False(t, got.ExpiresAt.IsZero())
+
+				ids, err := store.List(ctx)
+				require.NoError(t, err)
+				assert.Equal(t, []string{"long"}, ids)
+			})
+
+			t.Run("Update", func(t *testing.T) {
+				store, _ := newStore(t, Config{})
+				ctx := context.Background()
+
+				updated, err := store.Update(ctx, "thread-1", func(s *Session) error {
+					s.State = "created"
+					return nil
+				})
+				require.NoError(t, err)
+				assert.Equal(t, "created", updated.State)
+
+				failure := errors.New("rejected")
+				_, err = store.Update(ctx, "thread-1", func(s *Session) error {
+					s.State = "discarded"
+					return failure
+				})
+				assert.ErrorIs(t, err, failure)
+
+				got, err := store.Get(ctx, "thread-1")
+				require.NoError(t, err)
+				assert.Equal(t, "created", got.State)
+			})
+
+			t.Run("ConcurrentUpdates", func(t *testing.T) {
+				store, _ := newStore(t, Config{})
+				ctx := context.Background()
+
+				const writers = 10
+				var wg sync.WaitGroup
+				for i := range writers {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						_, err := store.Update(ctx, "thread-1", func(s *Session) error {
+							s.AppendMessages(types.Message{ID: fmt.Sprintf("msg-%d", i), Role: types.RoleUser})
+							return nil
+						})
+						assert.NoError(t, err)
+					}()
+				}
+				wg.Wait()
+
+				got, err := store.Get(ctx, "thread-1")
+				require.NoError(t, err)
+				assert.Len(t, got.Messages, writers)
+			})
+		})
+	}
+}
+
+func TestMemoryStoreMaxSessions(t *testing.T) {
+	c := newClock()
+	store := NewMemoryStore(MemoryConfig{MaxSessions: 2})
+	store.now = c.Now
+	ctx := context.Background()
+
+	for _, id := range []string{"a", "b", "c"} {
+		require.NoError(t, store.Save(ctx, New(id)))
+		c.Advance(time.Second)
+	}
+
+	ids, err := store.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "c"}, ids)
+}
+
+func TestPrune(t *testing.T) {
+	ctx := context.Background()
+	for name, newStore := range backends() {
+		t.Run(name, func(t *testing.T) {
+			store, advance := newStore(t, Config{TTL: time.Minute})
+			pruner, ok := store.(interface {
+				Prune(context.Context) (int, error)
+			})
+			if !ok {
+				t.Skip("expiry is handled by the backend")
+			}
+			require.NoError(t, store.Save(ctx, New("a")))
+			require.NoError(t, store.Save(ctx, New("b")))
+			advance(2 * time.Minute)
+
+			removed, err := pruner.Prune(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, 2, removed)
+		})
+	}
+}
+
+func TestSessionAppendMessagesAndRunInput(t *testing.T) {
+	session := New("thread-1")
+	session.AppendMessages(
+		types.Message{ID: "msg-1", Role: types.RoleUser, Content: "Hi"},
+		types.Message{ID: "msg-2", Role: types.RoleAssistant, Content: "Hel"},
+	)
+	session.AppendMessages(types.Message{ID: "msg-2", Role: types.RoleAssistant, Content: "Hello"})
+	session.State = map[string]any{"step": "greet"}
+
+	input := session.RunInput("run-1")
+	assert.Equal(t, "thread-1", input.ThreadID)
+	assert.Equal(t, "run-1", input.RunID)
+	require.Len(t, input.Messages, 2)
+	assert.Equal(t, "Hello", input.Messages[1].Content)
+	assert.Equal(t, session.State, input.State)
+
+	input.Messages[0].Content = "changed"
+	assert.Equal(t, "Hi", session.Messages[0].Content)
+}
+
+func TestSessionApplyEvent(t *testing.T) {
+	session := New("thread-1")
+	assert.True(t, session.ApplyEvent(events.NewMessagesSnapshotEvent([]events.Message{
+		{ID: "msg-1", Role: types.RoleUser, Content: "What is the capital of France?"},
+		{ID: "msg-2", Role: types.RoleAssistant, Content: "Lyon"},
+		{ID: "msg-3", Role: types.RoleAssistant, Content: "Anything else?"},
+	})))
+
+	assert.True(t, session.ApplyEvent(events.NewMessageUpdatedEvent("msg-2", "Paris")))
+	assert.True(t, session.ApplyEvent(events.NewMessageDeletedEvent("msg-3").WithReason("off topic")))
+	assert.False(t, session.ApplyEvent(events.NewMessageDeletedEvent("msg-3")), "the message is already deleted")
+	assert.False(t, session.ApplyEvent(events.NewMessageUpdatedEvent("msg-9", "unknown")))
+	assert.False(t, session.ApplyEvent(events.NewStepStartedEvent("plan")))
+
+	require.Len(t, session.Messages, 2)
+	assert.Equal(t, "Paris", session.Messages[1].Content)
+}
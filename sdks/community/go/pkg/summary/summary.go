@@ -0,0 +1,437 @@
+// Package summary builds the report of an agent run from its events: the
+// final messages, the tool calls with their results, the final state, the
+// errors and the timings of the run and of its steps. A Builder consumes the
+// events of a run, e.g. as a client receives them or from an archive, and
+// produces a RunSummary that serializes to JSON, so that consumers do not
+// reconstruct the outcome of runs themselves.
+package summary
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/jsonpatch"
+)
+
+// Outcomes of runs
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+
+	// OutcomeInterrupted is a run that paused on interrupts, e.g. for an
+	// approval, see RunSummary.Interrupts
+	OutcomeInterrupted = "interrupted"
+
+	// OutcomeIncomplete is a run whose stream ended without a terminal event
+	OutcomeIncomplete = "incomplete"
+)
+
+// RunSummary is the report of a run
+type RunSummary struct {
+	ThreadID string `json:"threadId,omitempty"`
+	RunID    string `json:"runId,omitempty"`
+
+	// Outcome is OutcomeSuccess, OutcomeError, OutcomeInterrupted or
+	// OutcomeIncomplete
+	Outcome string `json:"outcome"`
+
+	// Result is the result of the RUN_FINISHED event and Interrupts the
+	// interrupts the run paused on
+	Result     any               `json:"result,omitempty"`
+	Interrupts []types.Interrupt `json:"interrupts,omitempty"`
+
+	// StartedAt and FinishedAt are the times of the first and terminal
+	// events; FinishedAt is zero for incomplete runs
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	DurationMs float64   `json:"durationMs"`
+
+	// FirstContentMs is the time to the first text streamed (0 = none)
+	FirstContentMs float64 `json:"firstContentMs,omitempty"`
+
+	// Messages are the messages of the run: those of its last
+	// MESSAGES_SNAPSHOT, if any, followed by those streamed since
+	Messages []types.Message `json:"messages"`
+
+	ToolCalls []ToolCall `json:"toolCalls,omitempty"`
+
+	// State is the state of the run once its snapshots and deltas applied,
+	// and StateVersion counts them
+	State        any `json:"state,omitempty"`
+	StateVersion int `json:"stateVersion"`
+
+	Steps  []Step  `json:"steps,omitempty"`
+	Errors []Error `json:"errors,omitempty"`
+
+	// Events counts the events of the run
+	Events int `json:"events"`
+}
+
+// ToolCall is a tool call of a run
+type ToolCall struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Arguments       string `json:"arguments"`
+	ParentMessageID string `json:"parentMessageId,omitempty"`
+
+	// Result is the result of the call, when Returned
+	Result   string `json:"result,omitempty"`
+	Returned bool   `json:"returned"`
+
+	// DurationMs is the time from the start of the call to its result, or
+	// to its end when it returned no result
+	DurationMs float64 `json:"durationMs"`
+}
+
+// Step is a step of a run
+type Step struct {
+	Name       string  `json:"name"`
+	Parent     string  `json:"parent,omitempty"`
+	DurationMs float64 `json:"durationMs"`
+
+	// Open reports a step the run did not finish, and Orphaned a step
+	// finished with its parent or the run
+	Open     bool `json:"open,omitempty"`
+	Orphaned bool `json:"orphaned,omitempty"`
+}
+
+// Error is an error of a run: its RUN_ERROR, or an event that could not be
+// applied
+type Error struct {
+	Message string `json:"message"`
+	Code    string `json:"code,omitempty"`
+
+	// EventType is the type of the event reporting or causing the error
+	EventType events.EventType `json:"eventType"`
+}
+
+// Config configures a Builder
+type Config struct {
+	// Clock times the events without a timestamp (defaults to the real
+	// clock)
+	Clock clock.Clock
+}
+
+// Builder accumulates the events of a run into its summary. It is safe for
+// concurrent use.
+type Builder struct {
+	clock      clock.Clock
+	dispatcher *events.Dispatcher
+
+	mu      sync.Mutex
+	summary RunSummary
+	// now is the time of the event being applied
+	now      time.Time
+	messages []*message
+	tools    []*toolCall
+	steps    *events.StepTracker
+	done     bool
+}
+
+// message is a message being assembled
+type message struct {
+	types.Message
+	content strings.Builder
+	// streamed is set for the messages assembled from streamed events, as
+	// opposed to those of a snapshot
+	streamed bool
+}
+
+// toolCall is a tool call being assembled
+type toolCall struct {
+	ToolCall
+	arguments strings.Builder
+	startedAt time.Time
+}
+
+// NewBuilder creates a builder without events
+func NewBuilder(config Config) *Builder {
+	b := &Builder{clock: clock.Or(config.Clock), dispatcher: events.NewDispatcher()}
+	b.steps = events.NewStepTracker(events.WithStepClock(eventClock{Clock: clock.Real, b: b}))
+	b.register()
+	return b
+}
+
+// Summarize returns the summary of the events of a run
+func Summarize(evts []events.Event) RunSummary {
+	b := NewBuilder(Config{})
+	for _, event := range evts {
+		b.Observe(event)
+	}
+	return b.Summary()
+}
+
+// eventClock is the clock of the steps: the time of the event being applied
+type eventClock struct {
+	clock.Clock
+	b *Builder
+}
+
+// Now returns the time of the event being applied; callers hold b.mu
+func (c eventClock) Now() time.Time {
+	return c.b.now
+}
+
+// Observe adds event to the summary. Events after the terminal event of
+// the run are ignored.
+func (b *Builder) Observe(event events.Event) {
+	if event == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.done {
+		return
+	}
+	b.now = b.clock.Now()
+	if ts := event.Timestamp(); ts != nil {
+		b.now = time.UnixMilli(*ts)
+	}
+	if b.summary.Events == 0 {
+		b.summary.StartedAt = b.now
+	}
+	b.summary.Events++
+	if err := b.dispatcher.Dispatch(event); err != nil {
+		b.fail(event.Type(), err.Error())
+	}
+}
+
+// Summary returns the summary of the events observed so far. Until the
+// terminal event is observed the outcome is OutcomeIncomplete and the
+// steps still open are listed as such.
+func (b *Builder) Summary() RunSummary {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	summary := b.summary
+	if !b.done {
+		summary.Outcome = OutcomeIncomplete
+		summary.DurationMs = milliseconds(b.now.Sub(summary.StartedAt))
+	}
+	summary.Interrupts = append([]types.Interrupt(nil), summary.Interrupts...)
+	summary.Errors = append([]Error(nil), summary.Errors...)
+
+	summary.Messages = make([]types.Message, len(b.messages))
+	for i, m := range b.messages {
+		summary.Messages[i] = m.Message
+		if m.streamed {
+			summary.Messages[i].Content = m.content.String()
+		}
+		summary.Messages[i].ToolCalls = append([]types.ToolCall(nil), m.ToolCalls...)
+		for j, call := range summary.Messages[i].ToolCalls {
+			if tool := b.tool(call.ID); tool != nil {
+				summary.Messages[i].ToolCalls[j].Function.Arguments = tool.arguments.String()
+			}
+		}
+	}
+	for _, tool := range b.tools {
+		call := tool.ToolCall
+		call.Arguments = tool.arguments.String()
+		summary.ToolCalls = append(summary.ToolCalls, call)
+	}
+	for _, step := range b.steps.Steps() {
+		s := Step{Name: step.Name, Parent: step.Parent, Orphaned: step.Orphaned}
+		if step.FinishedAt.IsZero() {
+			s.Open = true
+			s.DurationMs = milliseconds(b.now.Sub(step.StartedAt))
+		} else {
+			s.DurationMs = milliseconds(step.Duration())
+		}
+		summary.Steps = append(summary.Steps, s)
+	}
+	return summary
+}
+
+// register registers the handlers of the events of the summary
+func (b *Builder) register() {
+	d := b.dispatcher
+	d.OnRunStarted(func(e *events.RunStartedEvent) {
+		b.summary.ThreadID, b.summary.RunID = e.ThreadIDValue, e.RunIDValue
+	})
+	d.OnRunFinished(func(e *events.RunFinishedEvent) {
+		if b.summary.RunID == "" {
+			b.summary.ThreadID, b.summary.RunID = e.ThreadIDValue, e.RunIDValue
+		}
+		b.summary.Result = e.Result
+		outcome := OutcomeSuccess
+		if e.Outcome != nil && e.Outcome.Type == events.RunFinishedOutcomeTypeInterrupt {
+			outcome = OutcomeInterrupted
+			b.summary.Interrupts = e.Outcome.Interrupts
+		}
+		b.finish(outcome)
+	})
+	d.OnRunError(func(e *events.RunErrorEvent) {
+		runError := Error{Message: e.Message, EventType: e.Type()}
+		if e.Code != nil {
+			runError.Code = *e.Code
+		}
+		b.summary.Errors = append(b.summary.Errors, runError)
+		b.finish(OutcomeError)
+	})
+
+	d.OnTextMessageStart(func(e *events.TextMessageStartEvent) {
+		role := types.RoleAssistant
+		if e.Role != nil {
+			role = types.Role(*e.Role)
+		}
+		b.message(e.MessageID, role)
+	})
+	d.OnTextMessageContent(func(e *events.TextMessageContentEvent) {
+		b.content(b.message(e.MessageID, types.RoleAssistant), e.Delta)
+	})
+	d.OnTextMessageChunk(func(e *events.TextMessageChunkEvent) {
+		if e.MessageID == nil {
+			return
+		}
+		role := types.RoleAssistant
+		if e.Role != nil {
+			role = types.Role(*e.Role)
+		}
+		m := b.message(*e.MessageID, role)
+		if e.Delta != nil {
+			b.content(m, *e.Delta)
+		}
+	})
+
+	d.OnToolCallStart(func(e *events.ToolCallStartEvent) {
+		b.startTool(e.ToolCallID, e.ToolCallName, e.ParentMessageID)
+	})
+	d.OnToolCallArgs(func(e *events.ToolCallArgsEvent) {
+		if tool := b.tool(e.ToolCallID); tool != nil {
+			tool.arguments.WriteString(e.Delta)
+		}
+	})
+	d.OnToolCallChunk(func(e *events.ToolCallChunkEvent) {
+		if e.ToolCallID == nil {
+			return
+		}
+		tool := b.tool(*e.ToolCallID)
+		if tool == nil && e.ToolCallName != nil {
+			tool = b.startTool(*e.ToolCallID, *e.ToolCallName, e.ParentMessageID)
+		}
+		if tool != nil && e.Delta != nil {
+			tool.arguments.WriteString(*e.Delta)
+		}
+	})
+	d.OnToolCallEnd(func(e *events.ToolCallEndEvent) {
+		if tool := b.tool(e.ToolCallID); tool != nil && !tool.Returned {
+			tool.DurationMs = milliseconds(b.now.Sub(tool.startedAt))
+		}
+	})
+	d.OnToolCallResult(func(e *events.ToolCallResultEvent) {
+		if tool := b.tool(e.ToolCallID); tool != nil {
+			tool.Result, tool.Returned = e.Content, true
+			tool.DurationMs = milliseconds(b.now.Sub(tool.startedAt))
+		}
+		b.messages = append(b.messages, &message{Message: types.Message{
+			ID:         e.MessageID,
+			Role:       types.RoleTool,
+			Content:    e.Content,
+			ToolCallID: e.ToolCallID,
+		}})
+	})
+
+	d.OnMessagesSnapshot(func(e *events.MessagesSnapshotEvent) {
+		b.messages = b.messages[:0]
+		for _, m := range e.Messages {
+			b.messages = append(b.messages, &message{Message: m})
+		}
+	})
+	d.OnStateSnapshot(func(e *events.StateSnapshotEvent) {
+		b.summary.State = e.Snapshot
+		b.summary.StateVersion++
+	})
+	d.OnStateDelta(func(e *events.StateDeltaEvent) {
+		state, err := jsonpatch.Apply(b.summary.State, e.Delta)
+		if err != nil {
+			b.fail(e.Type(), fmt.Sprintf("failed to apply state delta: %v", err))
+			return
+		}
+		b.summary.State = state
+		b.summary.StateVersion++
+	})
+
+	d.OnStepStarted(func(e *events.StepStartedEvent) {
+		if err := b.steps.Observe(e); err != nil {
+			b.fail(e.Type(), err.Error())
+		}
+	})
+	d.OnStepFinished(func(e *events.StepFinishedEvent) {
+		if err := b.steps.Observe(e); err != nil {
+			b.fail(e.Type(), err.Error())
+		}
+	})
+}
+
+// message returns the streamed message id, starting it if needed
+func (b *Builder) message(id string, role types.Role) *message {
+	for i := len(b.messages) - 1; i >= 0; i-- {
+		if b.messages[i].ID == id {
+			return b.messages[i]
+		}
+	}
+	m := &message{Message: types.Message{ID: id, Role: role}, streamed: true}
+	b.messages = append(b.messages, m)
+	return m
+}
+
+func (b *Builder) content(m *message, delta string) {
+	if b.summary.FirstContentMs == 0 && delta != "" {
+		b.summary.FirstContentMs = milliseconds(b.now.Sub(b.summary.StartedAt))
+	}
+	if !m.streamed {
+		// Content appended to a message of a snapshot
+		if content, ok := m.Content.(string); ok {
+			m.content.WriteString(content)
+		}
+		m.streamed = true
+	}
+	m.content.WriteString(delta)
+}
+
+func (b *Builder) startTool(id, name string, parentMessageID *string) *toolCall {
+	tool := &toolCall{ToolCall: ToolCall{ID: id, Name: name}, startedAt: b.now}
+	b.tools = append(b.tools, tool)
+	if parentMessageID == nil {
+		return tool
+	}
+	tool.ParentMessageID = *parentMessageID
+	parent := b.message(*parentMessageID, types.RoleAssistant)
+	parent.ToolCalls = append(parent.ToolCalls, types.ToolCall{
+		ID:       id,
+		Type:     "function",
+		Function: types.FunctionCall{Name: name},
+	})
+	return tool
+}
+
+func (b *Builder) tool(id string) *toolCall {
+	for i := len(b.tools) - 1; i >= 0; i-- {
+		if b.tools[i].ID == id {
+			return b.tools[i]
+		}
+	}
+	return nil
+}
+
+// fail records an event that could not be applied
+func (b *Builder) fail(eventType events.EventType, message string) {
+	b.summary.Errors = append(b.summary.Errors, Error{Message: message, EventType: eventType})
+}
+
+// finish completes the summary with the terminal event of the run
+func (b *Builder) finish(outcome string) {
+	b.done = true
+	b.summary.Outcome = outcome
+	b.summary.FinishedAt = b.now
+	b.summary.DurationMs = milliseconds(b.now.Sub(b.summary.StartedAt))
+	b.steps.Close()
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
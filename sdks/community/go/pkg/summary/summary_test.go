@@ -0,0 +1,127 @@
+package summary
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+// at stamps event with the time ms milliseconds after the epoch
+func at[T events.Event](ms int64, event T) T {
+	event.SetTimestamp(ms)
+	return event
+}
+
+func TestSummarize(t *testing.T) {
+	summary := Summarize([]events.Event{
+		at(0, events.NewRunStartedEvent("thread-1", "run-1")),
+		at(0, events.NewStateSnapshotEvent(map[string]any{"count": float64(0)})),
+		at(10, events.NewStepStartedEvent("answer")),
+		at(20, events.NewTextMessageStartEvent("msg-1", events.WithRole("assistant"))),
+		at(50, events.NewTextMessageContentEvent("msg-1", "Looking ")),
+		at(60, events.NewTextMessageContentEvent("msg-1", "that up")),
+		at(70, events.NewToolCallStartEvent("tool-1", "search", events.WithParentMessageID("msg-1"))),
+		at(80, events.NewToolCallArgsEvent("tool-1", `{"q":`)),
+		at(90, events.NewToolCallArgsEvent("tool-1", `"go"}`)),
+		at(100, events.NewToolCallEndEvent("tool-1")),
+		at(170, events.NewToolCallResultEvent("msg-2", "tool-1", "found")),
+		at(180, events.NewTextMessageEndEvent("msg-1")),
+		at(190, events.NewStateDeltaEvent([]events.JSONPatchOperation{{Op: "replace", Path: "/count", Value: float64(1)}})),
+		at(200, events.NewStateDeltaEvent([]events.JSONPatchOperation{{Op: "add", Path: "/count/nested", Value: true}})),
+		at(210, events.NewStepStartedEvent("cleanup")),
+		at(250, events.NewRunFinishedEvent("thread-1", "run-1")),
+		at(260, events.NewTextMessageContentEvent("msg-1", "ignored")),
+	})
+
+	assert.Equal(t, "thread-1", summary.ThreadID)
+	assert.Equal(t, "run-1", summary.RunID)
+	assert.Equal(t, OutcomeSuccess, summary.Outcome)
+	assert.Equal(t, time.UnixMilli(0), summary.StartedAt)
+	assert.Equal(t, time.UnixMilli(250), summary.FinishedAt)
+	assert.Equal(t, 250.0, summary.DurationMs)
+	assert.Equal(t, 50.0, summary.FirstContentMs)
+	assert.Equal(t, 16, summary.Events, "events after the terminal event are ignored")
+
+	assert.Equal(t, []types.Message{
+		{ID: "msg-1", Role: types.RoleAssistant, Content: "Looking that up", ToolCalls: []types.ToolCall{
+			{ID: "tool-1", Type: "function", Function: types.FunctionCall{Name: "search", Arguments: `{"q":"go"}`}},
+		}},
+		{ID: "msg-2", Role: types.RoleTool, Content: "found", ToolCallID: "tool-1"},
+	}, summary.Messages)
+	assert.Equal(t, []ToolCall{
+		{ID: "tool-1", Name: "search", Arguments: `{"q":"go"}`, ParentMessageID: "msg-1", Result: "found", Returned: true, DurationMs: 100},
+	}, summary.ToolCalls)
+
+	assert.Equal(t, map[string]any{"count": float64(1)}, summary.State)
+	assert.Equal(t, 2, summary.StateVersion)
+	require.Len(t, summary.Errors, 1)
+	assert.Equal(t, events.EventTypeStateDelta, summary.Errors[0].EventType)
+
+	assert.Equal(t, []Step{
+		{Name: "answer", DurationMs: 240, Orphaned: true},
+		{Name: "cleanup", Parent: "answer", DurationMs: 40, Orphaned: true},
+	}, summary.Steps, "steps left open finish with the run")
+
+	data, err := json.Marshal(summary)
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "success", decoded["outcome"])
+	assert.Len(t, decoded["messages"], 2)
+}
+
+func TestBuilderOutcomes(t *testing.T) {
+	t.Run("Error", func(t *testing.T) {
+		summary := Summarize([]events.Event{
+			at(0, events.NewRunStartedEvent("thread-1", "run-1")),
+			at(30, events.NewRunErrorEvent("model overloaded", events.WithErrorCode("UNAVAILABLE"))),
+		})
+		assert.Equal(t, OutcomeError, summary.Outcome)
+		assert.Equal(t, []Error{{Message: "model overloaded", Code: "UNAVAILABLE", EventType: events.EventTypeRunError}}, summary.Errors)
+		assert.Equal(t, 30.0, summary.DurationMs)
+	})
+
+	t.Run("Interrupted", func(t *testing.T) {
+		interrupts := []types.Interrupt{{ID: "approval-1", Reason: "tool_call"}}
+		summary := Summarize([]events.Event{
+			events.NewRunStartedEvent("thread-1", "run-1"),
+			events.NewRunFinishedEventWithOptions("thread-1", "run-1", events.WithOutcome(events.RunFinishedOutcome{
+				Type:       events.RunFinishedOutcomeTypeInterrupt,
+				Interrupts: interrupts,
+			})),
+		})
+		assert.Equal(t, OutcomeInterrupted, summary.Outcome)
+		assert.Equal(t, interrupts, summary.Interrupts)
+	})
+
+	t.Run("Incomplete", func(t *testing.T) {
+		builder := NewBuilder(Config{})
+		builder.Observe(at(0, events.NewRunStartedEvent("thread-1", "run-1")))
+		builder.Observe(at(5, events.NewMessagesSnapshotEvent([]events.Message{{ID: "msg-0", Role: types.RoleUser, Content: "Hi"}})))
+		builder.Observe(at(10, events.NewStepStartedEvent("think")))
+		builder.Observe(at(40, events.NewTextMessageChunkEvent(ptr("msg-1"), ptr("assistant"), ptr("Hel"))))
+
+		summary := builder.Summary()
+		assert.Equal(t, OutcomeIncomplete, summary.Outcome)
+		assert.Equal(t, 40.0, summary.DurationMs)
+		assert.Equal(t, []Step{{Name: "think", DurationMs: 30, Open: true}}, summary.Steps)
+		assert.Equal(t, []types.Message{
+			{ID: "msg-0", Role: types.RoleUser, Content: "Hi"},
+			{ID: "msg-1", Role: types.RoleAssistant, Content: "Hel"},
+		}, summary.Messages)
+
+		builder.Observe(at(50, events.NewTextMessageChunkEvent(ptr("msg-1"), nil, ptr("lo"))))
+		assert.Equal(t, "Hel", summary.Messages[1].Content, "summaries are not updated by later events")
+		assert.Equal(t, "Hello", builder.Summary().Messages[1].Content)
+	})
+}
+
+func ptr(s string) *string {
+	return &s
+}
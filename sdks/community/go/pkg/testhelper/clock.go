@@ -0,0 +1,181 @@
+package testhelper
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+)
+
+// FakeClock is a clock.Clock whose time only moves when a test advances it,
+// so that retries, rate limits and timeouts run without real sleeps.
+// Like the time package, it drops ticks that a slow receiver misses.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+	changed chan struct{}
+}
+
+var _ clock.Clock = (*FakeClock)(nil)
+
+// fakeWaiter is a pending timer, or a ticker when period is set
+type fakeWaiter struct {
+	clock  *FakeClock
+	c      chan time.Time
+	when   time.Time
+	period time.Duration
+}
+
+// NewFakeClock creates a fake clock reading start
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start, changed: make(chan struct{})}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) clock.Timer {
+	w := &fakeWaiter{clock: c, c: make(chan time.Time, 1)}
+	c.schedule(w, d, 0)
+	return (*fakeTimer)(w)
+}
+
+func (c *FakeClock) NewTicker(d time.Duration) clock.Ticker {
+	if d <= 0 {
+		panic("testhelper: non-positive interval for NewTicker")
+	}
+	w := &fakeWaiter{clock: c, c: make(chan time.Time, 1)}
+	c.schedule(w, d, d)
+	return (*fakeTicker)(w)
+}
+
+// Advance moves the clock forward by d, firing the timers and ticks due on
+// the way in order
+func (c *FakeClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the clock to t, firing the timers and ticks due by then.
+// Setting the clock back fires nothing.
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for {
+		sort.SliceStable(c.waiters, func(i, j int) bool { return c.waiters[i].when.Before(c.waiters[j].when) })
+		if len(c.waiters) == 0 || c.waiters[0].when.After(t) {
+			break
+		}
+		w := c.waiters[0]
+		c.now = w.when
+		select {
+		case w.c <- w.when:
+		default:
+		}
+		if w.period > 0 {
+			w.when = w.when.Add(w.period)
+		} else {
+			c.waiters = c.waiters[1:]
+		}
+	}
+	c.now = t
+	c.notify()
+}
+
+// Pending returns the number of timers and tickers waiting on the clock
+func (c *FakeClock) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil waits until n timers and tickers are waiting on the clock,
+// e.g. for the code under test to start a timeout before advancing past it
+func (c *FakeClock) BlockUntil(n int) {
+	for {
+		c.mu.Lock()
+		pending, changed := len(c.waiters), c.changed
+		c.mu.Unlock()
+		if pending >= n {
+			return
+		}
+		<-changed
+	}
+}
+
+// schedule adds w to fire after d, then every period if set. A timer due
+// now fires at once.
+func (c *FakeClock) schedule(w *fakeWaiter, d, period time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w.when = c.now.Add(d)
+	w.period = period
+	if d <= 0 && period == 0 {
+		select {
+		case w.c <- c.now:
+		default:
+		}
+		return
+	}
+	c.waiters = append(c.waiters, w)
+	c.notify()
+}
+
+// unschedule removes w, reporting whether it was pending
+func (c *FakeClock) unschedule(w *fakeWaiter) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, pending := range c.waiters {
+		if pending == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			c.notify()
+			return true
+		}
+	}
+	return false
+}
+
+// notify wakes up BlockUntil; c.mu must be held
+func (c *FakeClock) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+type fakeTimer fakeWaiter
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	return t.clock.unschedule((*fakeWaiter)(t))
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	active := t.Stop()
+	t.clock.schedule((*fakeWaiter)(t), d, 0)
+	return active
+}
+
+type fakeTicker fakeWaiter
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTicker) Stop() {
+	t.clock.unschedule((*fakeWaiter)(t))
+}
+
+func (t *fakeTicker) Reset(d time.Duration) {
+	t.clock.unschedule((*fakeWaiter)(t))
+	t.clock.schedule((*fakeWaiter)(t), d, d)
+}
@@ -0,0 +1,115 @@
+package testhelper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	agerrors "github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/errors"
+)
+
+var epoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// fired returns the time received on c, or false if nothing is ready
+func fired(c <-chan time.Time) (time.Time, bool) {
+	select {
+	case t := <-c:
+		return t, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestFakeClockTimers(t *testing.T) {
+	clk := NewFakeClock(epoch)
+	late := clk.NewTimer(2 * time.Second)
+	early := clk.After(time.Second)
+	stopped := clk.NewTimer(time.Second)
+	assert.Equal(t, 3, clk.Pending())
+	assert.True(t, stopped.Stop())
+	assert.False(t, stopped.Stop())
+
+	clk.Advance(999 * time.Millisecond)
+	_, ok := fired(early)
+	assert.False(t, ok)
+
+	clk.Advance(time.Millisecond)
+	at, ok := fired(early)
+	require.True(t, ok)
+	assert.Equal(t, epoch.Add(time.Second), at)
+	_, ok = fired(stopped.C())
+	assert.False(t, ok)
+
+	assert.True(t, late.Reset(time.Second))
+	clk.Advance(time.Second)
+	at, ok = fired(late.C())
+	require.True(t, ok)
+	assert.Equal(t, epoch.Add(2*time.Second), at)
+	assert.Equal(t, 0, clk.Pending())
+
+	_, ok = fired(clk.After(0))
+	assert.True(t, ok, "a timer due now fires at once")
+}
+
+func TestFakeClockTicker(t *testing.T) {
+	clk := NewFakeClock(epoch)
+	ticker := clk.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	var ticks []time.Time
+	for i := 0; i < 3; i++ {
+		clk.Advance(time.Second)
+		at, ok := fired(ticker.C())
+		require.True(t, ok)
+		ticks = append(ticks, at)
+	}
+	assert.Equal(t, []time.Time{epoch.Add(time.Second), epoch.Add(2 * time.Second), epoch.Add(3 * time.Second)}, ticks)
+
+	// Like time.Ticker, ticks missed by a slow receiver are dropped
+	clk.Advance(5 * time.Second)
+	at, ok := fired(ticker.C())
+	require.True(t, ok)
+	assert.Equal(t, epoch.Add(4*time.Second), at)
+	_, ok = fired(ticker.C())
+	assert.False(t, ok)
+	assert.Equal(t, epoch.Add(8*time.Second), clk.Now())
+
+	ticker.Reset(time.Minute)
+	clk.Advance(time.Second)
+	_, ok = fired(ticker.C())
+	assert.False(t, ok)
+	clk.Set(epoch.Add(8*time.Second + time.Minute))
+	_, ok = fired(ticker.C())
+	assert.True(t, ok)
+}
+
+func TestFakeClockDrivesRetry(t *testing.T) {
+	clk := NewFakeClock(epoch)
+	var attempts []time.Time
+	done := make(chan error, 1)
+	go func() {
+		done <- agerrors.Retry(context.Background(), &agerrors.RetryConfig{
+			MaxAttempts:  4,
+			InitialDelay: time.Second,
+			MaxDelay:     time.Minute,
+			Multiplier:   2,
+			Clock:        clk,
+		}, func() error {
+			attempts = append(attempts, clk.Now())
+			return errors.New("unavailable")
+		})
+	}()
+
+	for _, delay := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		clk.BlockUntil(1)
+		clk.Advance(delay)
+	}
+	err := <-done
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unavailable")
+	assert.Equal(t, []time.Time{epoch, epoch.Add(time.Second), epoch.Add(3 * time.Second), epoch.Add(7 * time.Second)}, attempts)
+}
@@ -0,0 +1,271 @@
+package testhelper
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FaultKind is a kind of fault injected in a stream
+type FaultKind string
+
+const (
+	// FaultDelay holds back the frame for Fault.Delay
+	FaultDelay FaultKind = "delay"
+
+	// FaultDrop loses the frame
+	FaultDrop FaultKind = "drop"
+
+	// FaultDuplicate delivers the frame twice
+	FaultDuplicate FaultKind = "duplicate"
+
+	// FaultReorder delivers the frame after the next one
+	FaultReorder FaultKind = "reorder"
+
+	// FaultDisconnect drops the connection before the frame
+	FaultDisconnect FaultKind = "disconnect"
+)
+
+// ErrInjectedDisconnect ends a stream cut by FaultDisconnect
+var ErrInjectedDisconnect = errors.New("testhelper: injected disconnect")
+
+// Fault is a fault injected at one frame of a stream
+type Fault struct {
+	// Frame is the index of the frame sent by the server, from 0
+	Frame int
+
+	Kind FaultKind
+
+	// Delay is how long FaultDelay holds the frame back
+	Delay time.Duration
+}
+
+// Scenario is the faults injected in one response. Scripted faults apply
+// at their frame; the rates apply at random to every other frame, drawn
+// from the seed of the transport so that runs repeat.
+type Scenario struct {
+	Faults []Fault
+
+	// Latency delays every frame, plus up to Jitter at random
+	Latency time.Duration
+	Jitter  time.Duration
+
+	// DropRate, DuplicateRate and ReorderRate are the probabilities of
+	// each fault per frame
+	DropRate      float64
+	DuplicateRate float64
+	ReorderRate   float64
+
+	// RefuseConnection fails the request before it reaches the server
+	RefuseConnection bool
+}
+
+// FaultTransport is an http.RoundTripper that injects faults in the
+// Server-Sent Events streams it receives. Each request plays the next
+// scenario; requests past the last scenario pass through unchanged.
+type FaultTransport struct {
+	base http.RoundTripper
+
+	mu        sync.Mutex
+	rand      *rand.Rand
+	scenarios []Scenario
+	requests  int
+	injected  []string
+}
+
+// NewFaultTransport wraps base, e.g. as the WrapTransport of an SSE client
+func NewFaultTransport(base http.RoundTripper, seed int64, scenarios ...Scenario) *FaultTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &FaultTransport{base: base, rand: rand.New(rand.NewSource(seed)), scenarios: scenarios}
+}
+
+// Wrap returns a decorator of transports injecting the faults of t
+func (t *FaultTransport) Wrap(base http.RoundTripper) http.RoundTripper {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.base = base
+	return t
+}
+
+// Injected returns the faults injected so far, e.g. "request 1 frame 3 drop"
+func (t *FaultTransport) Injected() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.injected...)
+}
+
+func (t *FaultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	var scenario *Scenario
+	request := t.requests
+	t.requests++
+	if len(t.scenarios) > 0 {
+		scenario = &t.scenarios[0]
+		t.scenarios = t.scenarios[1:]
+	}
+	base := t.base
+	t.mu.Unlock()
+
+	if scenario == nil {
+		return base.RoundTrip(req)
+	}
+	if scenario.RefuseConnection {
+		t.record("request %d refused", request)
+		return nil, fmt.Errorf("testhelper: injected connection failure to %s", req.URL.Host)
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return resp, err
+	}
+	resp.Body = &faultBody{
+		transport: t,
+		scenario:  scenario,
+		ctx:       req.Context(),
+		request:   request,
+		body:      resp.Body,
+		reader:    bufio.NewReader(resp.Body),
+	}
+	return resp, nil
+}
+
+func (t *FaultTransport) record(format string, args ...any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.injected = append(t.injected, fmt.Sprintf(format, args...))
+}
+
+// float returns a random number from the seeded source
+func (t *FaultTransport) float() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.rand.Float64()
+}
+
+// faultBody reads the stream frame by frame, applying the faults of its
+// scenario to each before handing it out
+type faultBody struct {
+	transport *FaultTransport
+	scenario  *Scenario
+	ctx       context.Context
+	request   int
+	body      io.ReadCloser
+	reader    *bufio.Reader
+
+	frame int
+	out   bytes.Buffer
+	held  []byte
+	err   error
+}
+
+func (b *faultBody) Read(p []byte) (int, error) {
+	for b.out.Len() == 0 && b.err == nil {
+		b.next()
+	}
+	if b.out.Len() > 0 {
+		return b.out.Read(p)
+	}
+	return 0, b.err
+}
+
+func (b *faultBody) Close() error {
+	return b.body.Close()
+}
+
+// next reads one frame from the server and queues what the client gets
+func (b *faultBody) next() {
+	frame, err := b.readFrame()
+	if err != nil {
+		b.out.Write(b.held)
+		b.held = nil
+		b.err = err
+		return
+	}
+	i := b.frame
+	b.frame++
+
+	kind, delay := b.fault(i)
+	if kind != "" {
+		b.transport.record("request %d frame %d %s", b.request, i, kind)
+	}
+	delay += b.scenario.Latency
+	if b.scenario.Jitter > 0 {
+		delay += time.Duration(b.transport.float() * float64(b.scenario.Jitter))
+	}
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-b.ctx.Done():
+			b.err = b.ctx.Err()
+			return
+		}
+	}
+
+	switch kind {
+	case FaultDisconnect:
+		_ = b.body.Close()
+		b.err = ErrInjectedDisconnect
+		return
+	case FaultDrop:
+	case FaultDuplicate:
+		b.out.Write(frame)
+		b.out.Write(frame)
+	case FaultReorder:
+		b.out.Write(b.held)
+		b.held = frame
+		return
+	default:
+		b.out.Write(frame)
+	}
+	b.out.Write(b.held)
+	b.held = nil
+}
+
+// fault returns the fault injected at frame i and its delay
+func (b *faultBody) fault(i int) (FaultKind, time.Duration) {
+	for _, f := range b.scenario.Faults {
+		if f.Frame == i {
+			return f.Kind, f.Delay
+		}
+	}
+	for _, r := range []struct {
+		kind FaultKind
+		rate float64
+	}{
+		{FaultDrop, b.scenario.DropRate},
+		{FaultDuplicate, b.scenario.DuplicateRate},
+		{FaultReorder, b.scenario.ReorderRate},
+	} {
+		if r.rate > 0 && b.transport.float() < r.rate {
+			return r.kind, 0
+		}
+	}
+	return "", 0
+}
+
+// readFrame reads up to and including the blank line ending a frame
+func (b *faultBody) readFrame() ([]byte, error) {
+	var frame []byte
+	for {
+		line, err := b.reader.ReadBytes('\n')
+		frame = append(frame, line...)
+		if err != nil {
+			if len(bytes.TrimSpace(frame)) > 0 && errors.Is(err, io.EOF) {
+				return frame, nil
+			}
+			return nil, err
+		}
+		if len(bytes.TrimSpace(line)) == 0 && len(bytes.TrimSpace(frame)) > 0 {
+			return frame, nil
+		}
+	}
+}
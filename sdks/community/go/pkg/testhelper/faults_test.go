@@ -0,0 +1,132 @@
+package testhelper
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// countingScript streams content events whose deltas are their index
+func countingScript(n int) []Step {
+	steps := []Step{Emit(events.NewTextMessageStartEvent("msg-1"))}
+	for i := 1; i < n; i++ {
+		steps = append(steps, Emit(events.NewTextMessageContentEvent("msg-1", strconv.Itoa(i))))
+	}
+	return steps
+}
+
+// deltas streams a run through faults, returning the index of each frame
+// received, -1 for the start, and the error that ended the stream
+func deltas(t *testing.T, server *MockAGUIServer, faults *FaultTransport) ([]int, error) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	client := sse.NewClient(sse.Config{Endpoint: server.Endpoint(), Logger: logger, WrapTransport: faults.Wrap})
+	defer client.Close()
+
+	frames, errs, err := client.Stream(sse.StreamOptions{Context: context.Background(), Payload: runInput()})
+	if err != nil {
+		return nil, err
+	}
+	var got []int
+	var streamErr error
+	for frames != nil || errs != nil {
+		select {
+		case frame, ok := <-frames:
+			if !ok {
+				frames = nil
+				continue
+			}
+			var event struct {
+				Delta string `json:"delta"`
+			}
+			require.NoError(t, json.Unmarshal(frame.Data, &event))
+			i, err := strconv.Atoi(event.Delta)
+			if err != nil {
+				i = -1
+			}
+			got = append(got, i)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			streamErr = err
+		case <-time.After(5 * time.Second):
+			t.Fatal("the stream did not end")
+		}
+	}
+	return got, streamErr
+}
+
+func TestFaultTransportScriptedFaults(t *testing.T) {
+	server := NewMockAGUIServer()
+	defer server.Close()
+	server.Script(countingScript(8)...)
+	faults := NewFaultTransport(nil, 1, Scenario{Faults: []Fault{
+		{Frame: 1, Kind: FaultDrop},
+		{Frame: 2, Kind: FaultDuplicate},
+		{Frame: 3, Kind: FaultReorder},
+		{Frame: 5, Kind: FaultDelay, Delay: 50 * time.Millisecond},
+		{Frame: 6, Kind: FaultDisconnect},
+	}})
+
+	start := time.Now()
+	got, err := deltas(t, server, faults)
+	assert.Equal(t, []int{-1, 2, 2, 4, 3, 5}, got)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), ErrInjectedDisconnect.Error())
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Equal(t, []string{
+		"request 0 frame 1 drop",
+		"request 0 frame 2 duplicate",
+		"request 0 frame 3 reorder",
+		"request 0 frame 5 delay",
+		"request 0 frame 6 disconnect",
+	}, faults.Injected())
+}
+
+func TestFaultTransportRefusesAndPassesThrough(t *testing.T) {
+	server := NewMockAGUIServer()
+	defer server.Close()
+	server.Script(countingScript(3)...)
+	faults := NewFaultTransport(nil, 1, Scenario{RefuseConnection: true})
+
+	_, err := deltas(t, server, faults)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "injected connection failure")
+	assert.Empty(t, server.Requests())
+
+	// Past its scenarios the transport changes nothing
+	got, err := deltas(t, server, faults)
+	require.NoError(t, err)
+	assert.Equal(t, []int{-1, 1, 2}, got)
+}
+
+func TestFaultTransportRatesRepeatWithSeed(t *testing.T) {
+	server := NewMockAGUIServer()
+	defer server.Close()
+	scenario := Scenario{DropRate: 0.2, DuplicateRate: 0.2, ReorderRate: 0.2, Latency: time.Millisecond, Jitter: time.Millisecond}
+
+	var runs [2][]int
+	var injected [2][]string
+	for i := range runs {
+		server.Script(countingScript(30)...)
+		faults := NewFaultTransport(nil, 99, scenario)
+		var err error
+		runs[i], err = deltas(t, server, faults)
+		require.NoError(t, err)
+		injected[i] = faults.Injected()
+	}
+	assert.Equal(t, runs[0], runs[1])
+	assert.Equal(t, injected[0], injected[1])
+	assert.NotEmpty(t, injected[0])
+}
@@ -0,0 +1,449 @@
+package testhelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// SeedEnv replays the sequences of ForAll with a given seed
+const SeedEnv = "AG_UI_TEST_SEED"
+
+// Shape configures the sequences of a Generator. Zero fields take their
+// default, negative counts disable a kind of event.
+type Shape struct {
+	// Runs is the number of runs in a sequence, one after the other (default 1)
+	Runs int
+
+	// MaxMessages is the most text messages per run or step (default 3)
+	MaxMessages int
+
+	// MaxToolCalls is the most tool calls per run or step, each followed by
+	// its result (default 2)
+	MaxToolCalls int
+
+	// MaxChunks is the most content or argument events per message or tool
+	// call (default 3)
+	MaxChunks int
+
+	// MaxDepth is how deep steps nest (default 2)
+	MaxDepth int
+
+	// Interleave overlaps the messages, tool calls and steps of a level
+	// instead of emitting them one after the other
+	Interleave bool
+
+	// Reasoning adds reasoning messages
+	Reasoning bool
+
+	// State adds a state snapshot to each run and deltas applying to it
+	State bool
+
+	// ErrorRate is the probability of a run ending with RUN_ERROR
+	ErrorRate float64
+}
+
+func (s Shape) withDefaults() Shape {
+	def := func(n *int, value int) {
+		switch {
+		case *n == 0:
+			*n = value
+		case *n < 0:
+			*n = 0
+		}
+	}
+	def(&s.Runs, 1)
+	def(&s.MaxMessages, 3)
+	def(&s.MaxToolCalls, 2)
+	def(&s.MaxChunks, 3)
+	def(&s.MaxDepth, 2)
+	s.MaxChunks = max(s.MaxChunks, 1)
+	return s
+}
+
+// Generator produces random event sequences that follow the AG-UI protocol,
+// for property-based tests of validators, assemblers and state engines. The
+// same seed and shape produce the same sequences.
+type Generator struct {
+	rand  *rand.Rand
+	shape Shape
+
+	// ids numbers the IDs of each kind of entity
+	ids   map[string]int
+	state map[string]any
+	clock int64
+}
+
+// NewGenerator creates a generator of sequences of the given shape
+func NewGenerator(seed int64, shape Shape) *Generator {
+	return &Generator{
+		rand:  rand.New(rand.NewSource(seed)),
+		shape: shape.withDefaults(),
+		ids:   make(map[string]int),
+		clock: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli(),
+	}
+}
+
+// Sequence returns a new valid sequence
+func (g *Generator) Sequence() []events.Event {
+	threadID := g.id("thread")
+	var seq []events.Event
+	for i := 0; i < g.shape.Runs; i++ {
+		seq = append(seq, g.run(threadID)...)
+	}
+	// Timestamps follow the order of the events, a millisecond apart
+	for _, event := range seq {
+		g.clock++
+		event.SetTimestamp(g.clock)
+	}
+	return seq
+}
+
+func (g *Generator) id(kind string) string {
+	g.ids[kind]++
+	return kind + "-" + strconv.Itoa(g.ids[kind])
+}
+
+func (g *Generator) run(threadID string) []events.Event {
+	runID := g.id("run")
+	seq := []events.Event{events.NewRunStartedEvent(threadID, runID)}
+	if g.shape.State {
+		g.state = map[string]any{"counter": 0, "status": "started"}
+		seq = append(seq, events.NewStateSnapshotEvent(copyState(g.state)))
+	}
+	seq = append(seq, g.level(0)...)
+	if g.shape.State {
+		g.fillDeltas(seq)
+	}
+	if g.rand.Float64() < g.shape.ErrorRate {
+		return append(seq, events.NewRunErrorEvent("generated failure", events.WithRunID(runID)))
+	}
+	return append(seq, events.NewRunFinishedEvent(threadID, runID))
+}
+
+// level returns the events of a run or step body: messages, tool calls,
+// nested steps and state deltas, in order or interleaved
+func (g *Generator) level(depth int) []events.Event {
+	var parts [][]events.Event
+	for i := g.rand.Intn(g.shape.MaxMessages + 1); i > 0; i-- {
+		parts = append(parts, g.message())
+	}
+	for i := g.rand.Intn(g.shape.MaxToolCalls + 1); i > 0; i-- {
+		parts = append(parts, g.toolCall())
+	}
+	if g.shape.Reasoning && g.rand.Intn(2) == 0 {
+		parts = append(parts, g.reasoning())
+	}
+	if depth < g.shape.MaxDepth && g.rand.Intn(2) == 0 {
+		name := g.id("step")
+		step := append([]events.Event{events.NewStepStartedEvent(name)}, g.level(depth+1)...)
+		parts = append(parts, append(step, events.NewStepFinishedEvent(name)))
+	}
+	g.rand.Shuffle(len(parts), func(i, j int) { parts[i], parts[j] = parts[j], parts[i] })
+
+	var seq []events.Event
+	if g.shape.Interleave {
+		seq = g.interleave(parts)
+	} else {
+		for _, part := range parts {
+			seq = append(seq, part...)
+		}
+	}
+	// Deltas go between the parts; fillDeltas sets their operations once
+	// their order is final
+	if g.shape.State {
+		for i := g.rand.Intn(3); i > 0; i-- {
+			at := g.rand.Intn(len(seq) + 1)
+			seq = append(seq[:at], append([]events.Event{&events.StateDeltaEvent{}}, seq[at:]...)...)
+		}
+	}
+	return seq
+}
+
+// interleave merges parts at random, keeping the order within each part
+func (g *Generator) interleave(parts [][]events.Event) []events.Event {
+	var seq []events.Event
+	for len(parts) > 0 {
+		i := g.rand.Intn(len(parts))
+		seq = append(seq, parts[i][0])
+		if parts[i] = parts[i][1:]; len(parts[i]) == 0 {
+			parts = append(parts[:i], parts[i+1:]...)
+		}
+	}
+	return seq
+}
+
+func (g *Generator) message() []events.Event {
+	id := g.id("msg")
+	seq := []events.Event{events.NewTextMessageStartEvent(id, events.WithRole("assistant"))}
+	for _, chunk := range g.chunks(g.text()) {
+		seq = append(seq, events.NewTextMessageContentEvent(id, chunk))
+	}
+	return append(seq, events.NewTextMessageEndEvent(id))
+}
+
+func (g *Generator) toolCall() []events.Event {
+	id := g.id("tool")
+	args, _ := json.Marshal(map[string]any{"query": g.text(), "limit": g.rand.Intn(10) + 1})
+	seq := []events.Event{events.NewToolCallStartEvent(id, "search")}
+	for _, chunk := range g.chunks(string(args)) {
+		seq = append(seq, events.NewToolCallArgsEvent(id, chunk))
+	}
+	return append(seq,
+		events.NewToolCallEndEvent(id),
+		events.NewToolCallResultEvent(g.id("result"), id, g.text()),
+	)
+}
+
+func (g *Generator) reasoning() []events.Event {
+	id := g.id("reasoning")
+	seq := []events.Event{
+		events.NewReasoningStartEvent(id),
+		events.NewReasoningMessageStartEvent(id, "assistant"),
+	}
+	for _, chunk := range g.chunks(g.text()) {
+		seq = append(seq, events.NewReasoningMessageContentEvent(id, chunk))
+	}
+	return append(seq, events.NewReasoningMessageEndEvent(id), events.NewReasoningEndEvent(id))
+}
+
+// fillDeltas sets the operations of the state deltas of a run in order, so
+// that each applies to the state left by the ones before
+func (g *Generator) fillDeltas(seq []events.Event) {
+	for i, event := range seq {
+		if delta, ok := event.(*events.StateDeltaEvent); ok && len(delta.Delta) == 0 {
+			seq[i] = events.NewStateDeltaEvent(g.delta())
+		}
+	}
+}
+
+// delta changes the state, returning the patch of the change
+func (g *Generator) delta() []events.JSONPatchOperation {
+	counter, _ := g.state["counter"].(int)
+	g.state["counter"] = counter + 1
+	ops := []events.JSONPatchOperation{{Op: "replace", Path: "/counter", Value: counter + 1}}
+	if g.rand.Intn(2) == 0 {
+		key := g.id("key")
+		g.state[key] = g.text()
+		ops = append(ops, events.JSONPatchOperation{Op: "add", Path: "/" + key, Value: g.state[key]})
+	}
+	return ops
+}
+
+// State returns the state left by the deltas of the last run, with
+// Shape.State
+func (g *Generator) State() map[string]any {
+	return copyState(g.state)
+}
+
+var words = strings.Fields("the agent reads a tool result and streams an answer with unicode ✓ and \"quotes\"")
+
+func (g *Generator) text() string {
+	n := g.rand.Intn(6) + 1
+	picked := make([]string, n)
+	for i := range picked {
+		picked[i] = words[g.rand.Intn(len(words))]
+	}
+	return strings.Join(picked, " ")
+}
+
+// chunks splits s into up to MaxChunks non-empty parts, on rune boundaries
+func (g *Generator) chunks(s string) []string {
+	runes := []rune(s)
+	n := min(g.rand.Intn(g.shape.MaxChunks)+1, len(runes))
+	cuts := g.rand.Perm(len(runes) - 1)[:n-1]
+	cuts = append(cuts, len(runes)-1)
+	var out []string
+	start := 0
+	for end := 0; end < len(runes); end++ {
+		for _, cut := range cuts {
+			if cut == end {
+				out = append(out, string(runes[start:end+1]))
+				start = end + 1
+				break
+			}
+		}
+	}
+	return out
+}
+
+// Mutation is a change that makes a valid sequence break the protocol
+type Mutation string
+
+const (
+	// MutationDropStart removes the start of a run, step, message or tool call
+	MutationDropStart Mutation = "drop-start"
+
+	// MutationDuplicateStart repeats the start of a run, step, message or
+	// tool call
+	MutationDuplicateStart Mutation = "duplicate-start"
+
+	// MutationEndFirst moves the end of a run, step, message or tool call
+	// before its start
+	MutationEndFirst Mutation = "end-first"
+
+	// MutationMissingID clears the ID of a message or tool call event
+	MutationMissingID Mutation = "missing-id"
+
+	// MutationRestartRun starts a finished run again
+	MutationRestartRun Mutation = "restart-run"
+)
+
+// Mutations lists every mutation
+var Mutations = []Mutation{MutationDropStart, MutationDuplicateStart, MutationEndFirst, MutationMissingID, MutationRestartRun}
+
+// Invalid returns a new sequence broken by a random mutation
+func (g *Generator) Invalid() ([]events.Event, Mutation) {
+	seq := g.Sequence()
+	for {
+		mutation := Mutations[g.rand.Intn(len(Mutations))]
+		if out, ok := g.Mutate(seq, mutation); ok {
+			return out, mutation
+		}
+	}
+}
+
+// Mutate returns a copy of a generated sequence broken by mutation, or false
+// when the sequence has no event the mutation applies to
+func (g *Generator) Mutate(seq []events.Event, mutation Mutation) ([]events.Event, bool) {
+	out := slices.Clone(seq)
+	var starts, ids []int
+	for i, event := range out {
+		if _, _, start := entity(event); start {
+			starts = append(starts, i)
+		}
+		if withoutID(event) != nil {
+			ids = append(ids, i)
+		}
+	}
+	if len(starts) == 0 {
+		return nil, false
+	}
+
+	switch mutation {
+	case MutationDropStart:
+		i := starts[g.rand.Intn(len(starts))]
+		return slices.Delete(out, i, i+1), true
+	case MutationDuplicateStart:
+		i := starts[g.rand.Intn(len(starts))]
+		return slices.Insert(out, i, out[i]), true
+	case MutationEndFirst:
+		i := starts[g.rand.Intn(len(starts))]
+		kind, id, _ := entity(out[i])
+		for j := len(out) - 1; j > i; j-- {
+			if k, end, _ := entity(out[j]); k == kind+"-end" && end == id {
+				event := out[j]
+				return slices.Insert(slices.Delete(out, j, j+1), i, event), true
+			}
+		}
+	case MutationMissingID:
+		if len(ids) > 0 {
+			i := ids[g.rand.Intn(len(ids))]
+			out[i] = withoutID(out[i])
+			return out, true
+		}
+	case MutationRestartRun:
+		for _, event := range out {
+			if start, ok := event.(*events.RunStartedEvent); ok {
+				return append(out, start), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// entity returns the kind and ID of the run, step, message or tool call an
+// event starts or ends, the kind of ends suffixed with "-end"
+func entity(event events.Event) (kind, id string, start bool) {
+	switch e := event.(type) {
+	case *events.RunStartedEvent:
+		return "run", e.RunID(), true
+	case *events.RunFinishedEvent:
+		return "run-end", e.RunID(), false
+	case *events.RunErrorEvent:
+		return "run-end", e.RunID(), false
+	case *events.StepStartedEvent:
+		return "step", e.StepName, true
+	case *events.StepFinishedEvent:
+		return "step-end", e.StepName, false
+	case *events.TextMessageStartEvent:
+		return "msg", e.MessageID, true
+	case *events.TextMessageEndEvent:
+		return "msg-end", e.MessageID, false
+	case *events.ToolCallStartEvent:
+		return "tool", e.ToolCallID, true
+	case *events.ToolCallEndEvent:
+		return "tool-end", e.ToolCallID, false
+	case *events.ReasoningMessageStartEvent:
+		return "reasoning", e.MessageID, true
+	case *events.ReasoningMessageEndEvent:
+		return "reasoning-end", e.MessageID, false
+	}
+	return "", "", false
+}
+
+// withoutID returns a copy of a message or tool call event with an empty
+// ID, or nil for other events
+func withoutID(event events.Event) events.Event {
+	switch e := event.(type) {
+	case *events.TextMessageContentEvent:
+		return events.NewTextMessageContentEvent("", e.Delta)
+	case *events.TextMessageEndEvent:
+		return events.NewTextMessageEndEvent("")
+	case *events.ToolCallArgsEvent:
+		return events.NewToolCallArgsEvent("", e.Delta)
+	case *events.ToolCallEndEvent:
+		return events.NewToolCallEndEvent("")
+	}
+	return nil
+}
+
+func copyState(state map[string]any) map[string]any {
+	out := make(map[string]any, len(state))
+	for k, v := range state {
+		out[k] = v
+	}
+	return out
+}
+
+// ForAll checks property against n generated sequences, failing t with the
+// seed and the sequence that broke it. The seed is random unless SeedEnv is
+// set, to replay a failure.
+func ForAll(t testing.TB, n int, shape Shape, property func([]events.Event) error) {
+	t.Helper()
+	seed := time.Now().UnixNano()
+	if s := os.Getenv(SeedEnv); s != "" {
+		var err error
+		if seed, err = strconv.ParseInt(s, 10, 64); err != nil {
+			t.Fatalf("invalid %s: %v", SeedEnv, err)
+		}
+	}
+	g := NewGenerator(seed, shape)
+	for i := 0; i < n; i++ {
+		seq := g.Sequence()
+		if err := property(seq); err != nil {
+			t.Fatalf("sequence %d failed with %s=%d: %v\n%s", i, SeedEnv, seed, err, Describe(seq))
+		}
+	}
+}
+
+// Describe lists the events of a sequence, one per line
+func Describe(seq []events.Event) string {
+	var b strings.Builder
+	for i, event := range seq {
+		data, err := event.ToJSON()
+		if err != nil {
+			data = []byte(event.Type())
+		}
+		fmt.Fprintf(&b, "%3d %s\n", i, data)
+	}
+	return b.String()
+}
@@ -0,0 +1,99 @@
+package testhelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+var shapes = map[string]Shape{
+	"default":     {},
+	"interleaved": {Interleave: true, Runs: 2, MaxDepth: 3},
+	"everything":  {Interleave: true, Reasoning: true, State: true, ErrorRate: 0.3, MaxChunks: 6},
+	"no tools":    {MaxToolCalls: -1, MaxDepth: -1},
+}
+
+func TestGeneratedSequencesAreValid(t *testing.T) {
+	for name, shape := range shapes {
+		t.Run(name, func(t *testing.T) {
+			ForAll(t, 200, shape, events.ValidateSequence)
+		})
+	}
+}
+
+func TestMutatedSequencesAreInvalid(t *testing.T) {
+	g := NewGenerator(1, Shape{Interleave: true, Reasoning: true, State: true})
+	for _, mutation := range Mutations {
+		t.Run(string(mutation), func(t *testing.T) {
+			applied := 0
+			for i := 0; i < 100; i++ {
+				seq, ok := g.Mutate(g.Sequence(), mutation)
+				if !ok {
+					continue
+				}
+				applied++
+				assert.Error(t, events.ValidateSequence(seq), Describe(seq))
+			}
+			assert.Greater(t, applied, 50)
+		})
+	}
+
+	seq, mutation := g.Invalid()
+	assert.Contains(t, Mutations, mutation)
+	assert.Error(t, events.ValidateSequence(seq))
+}
+
+func TestGeneratorIsDeterministic(t *testing.T) {
+	shape := shapes["everything"]
+	a, b := NewGenerator(42, shape), NewGenerator(42, shape)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, Describe(a.Sequence()), Describe(b.Sequence()))
+	}
+}
+
+func TestGeneratedContentAssembles(t *testing.T) {
+	ForAll(t, 200, Shape{Interleave: true, MaxChunks: 8}, func(seq []events.Event) error {
+		args := map[string]*strings.Builder{}
+		for _, event := range seq {
+			switch e := event.(type) {
+			case *events.ToolCallStartEvent:
+				args[e.ToolCallID] = &strings.Builder{}
+			case *events.ToolCallArgsEvent:
+				args[e.ToolCallID].WriteString(e.Delta)
+			}
+		}
+		for id, b := range args {
+			if !json.Valid([]byte(b.String())) {
+				return fmt.Errorf("tool call %s has invalid arguments %q", id, b.String())
+			}
+		}
+		return nil
+	})
+}
+
+func TestGeneratedStateDeltasApply(t *testing.T) {
+	g := NewGenerator(7, Shape{State: true, Interleave: true})
+	for i := 0; i < 100; i++ {
+		var state map[string]any
+		for _, event := range g.Sequence() {
+			switch e := event.(type) {
+			case *events.StateSnapshotEvent:
+				state = e.Snapshot.(map[string]any)
+			case *events.StateDeltaEvent:
+				for _, op := range e.Delta {
+					key := strings.TrimPrefix(op.Path, "/")
+					_, exists := state[key]
+					require.True(t, op.Op == "add" || exists, "%s of missing %s", op.Op, op.Path)
+					state[key] = op.Value
+				}
+			}
+		}
+		assert.Equal(t, g.State(), state)
+	}
+}
@@ -0,0 +1,270 @@
+package testhelper
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+)
+
+// UpdateEnv set to a non-empty value rewrites golden files like -update,
+// for packages run together with others that lack the flag
+const UpdateEnv = "AG_UI_UPDATE_GOLDEN"
+
+var update = flag.Bool("update", false, "rewrite golden files with the output of the tests")
+
+// GoldenPath returns the golden file of name, under the testdata directory
+// of the package being tested
+func GoldenPath(name string) string {
+	return filepath.Join("testdata", filepath.FromSlash(name)+".golden")
+}
+
+// AssertGolden compares got with the golden file of name, showing a diff
+// when they differ. With -update it rewrites the file instead.
+func AssertGolden(t testing.TB, name string, got []byte) bool {
+	t.Helper()
+	path := GoldenPath(name)
+	if updating() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+		return true
+	}
+
+	want, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("golden file %s is missing; run the test with -update to create it", path)
+	}
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	return assert.Equal(t, string(want), string(got), "output differs from %s; run the test with -update to accept it", path)
+}
+
+func updating() bool {
+	return *update || os.Getenv(UpdateEnv) != ""
+}
+
+// AssertGoldenEvents compares seq, normalized, with the golden file of name
+func AssertGoldenEvents(t testing.TB, name string, seq []events.Event) bool {
+	t.Helper()
+	got, err := NewNormalizer().Events(seq)
+	if err != nil {
+		t.Fatalf("failed to normalize events: %v", err)
+	}
+	return AssertGolden(t, name, got)
+}
+
+// AssertGoldenSSE compares a Server-Sent Events stream, e.g. the body
+// written by an encoder or a server, normalized, with the golden file of name
+func AssertGoldenSSE(t testing.TB, name string, stream []byte) bool {
+	t.Helper()
+	return AssertGolden(t, name, NewNormalizer().SSE(stream))
+}
+
+// Capture reads the frames of a client stream to its end and decodes them
+// into events. It returns the error that ended the stream, if any.
+func Capture(t testing.TB, frames <-chan sse.Frame, errs <-chan error) ([]events.Event, error) {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	decoder := events.NewEventDecoder(logger)
+
+	var seq []events.Event
+	var streamErr error
+	timeout := time.After(10 * time.Second)
+	for frames != nil || errs != nil {
+		select {
+		case frame, ok := <-frames:
+			if !ok {
+				frames = nil
+				continue
+			}
+			event, err := decoder.Decode(frame.Data)
+			if err != nil {
+				t.Fatalf("undecodable frame %q: %v", frame.Data, err)
+			}
+			seq = append(seq, event)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			streamErr = err
+		case <-timeout:
+			t.Fatal("the stream did not end")
+		}
+	}
+	return seq, streamErr
+}
+
+// DefaultIDFields are the fields whose values Normalizer replaces with
+// stable placeholders
+var DefaultIDFields = []string{"id", "threadId", "runId", "parentRunId", "messageId", "parentMessageId", "toolCallId"}
+
+// DefaultTimeFields are the fields Normalizer blanks out
+var DefaultTimeFields = []string{"timestamp", "createdAt", "resolvedAt"}
+
+// frameID matches the id line of frames written by the SSE encoder, made of
+// the event type and timestamp
+var frameID = regexp.MustCompile(`^id: ([A-Z_]+)_\d+$`)
+
+// Normalizer makes event streams comparable across runs. Keys are sorted
+// and each distinct ID becomes a placeholder such as <id-1>, numbered in
+// order of appearance in the output, so that the references between events
+// survive; timestamps become <timestamp>. A Normalizer remembers the IDs it
+// has seen until it is discarded.
+type Normalizer struct {
+	IDFields   []string
+	TimeFields []string
+
+	ids   map[string]string
+	order []string
+}
+
+// NewNormalizer creates a normalizer of the default fields
+func NewNormalizer() *Normalizer {
+	return &Normalizer{IDFields: DefaultIDFields, TimeFields: DefaultTimeFields}
+}
+
+// Events renders seq as one normalized JSON object per line
+func (n *Normalizer) Events(seq []events.Event) ([]byte, error) {
+	var out bytes.Buffer
+	for i, event := range seq {
+		data, err := event.ToJSON()
+		if err != nil {
+			return nil, fmt.Errorf("event %d: %w", i, err)
+		}
+		line, err := n.JSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("event %d: %w", i, err)
+		}
+		out.Write(line)
+		out.WriteByte('\n')
+	}
+	return out.Bytes(), nil
+}
+
+// JSON normalizes a JSON document, at any depth
+func (n *Normalizer) JSON(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(n.value("", value)); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(out.Bytes(), []byte("\n")), nil
+}
+
+// SSE normalizes the data of each frame of a Server-Sent Events stream,
+// and the ids the SSE encoder derives from timestamps. Data that is not
+// JSON is kept as is.
+func (n *Normalizer) SSE(stream []byte) []byte {
+	lines := strings.SplitAfter(string(stream), "\n")
+	var out strings.Builder
+	for _, line := range lines {
+		text := strings.TrimRight(line, "\r\n")
+		eol := line[len(text):]
+		switch {
+		case strings.HasPrefix(text, "data:"):
+			data := strings.TrimPrefix(strings.TrimPrefix(text, "data:"), " ")
+			if normalized, err := n.JSON([]byte(data)); err == nil {
+				text = "data: " + string(normalized)
+			}
+		case frameID.MatchString(text):
+			text = frameID.ReplaceAllString(text, "id: ${1}_<timestamp>")
+		}
+		out.WriteString(text)
+		out.WriteString(eol)
+	}
+	return []byte(out.String())
+}
+
+// Text replaces the IDs seen so far in plain text, e.g. the output of a
+// renderer fed with the events already normalized
+func (n *Normalizer) Text(text []byte) []byte {
+	// Longest first, so that no ID is replaced inside another
+	ids := append([]string(nil), n.order...)
+	sort.SliceStable(ids, func(i, j int) bool { return len(ids[i]) > len(ids[j]) })
+	pairs := make([]string, 0, 2*len(ids))
+	for _, id := range ids {
+		pairs = append(pairs, id, n.ids[id])
+	}
+	return []byte(strings.NewReplacer(pairs...).Replace(string(text)))
+}
+
+func (n *Normalizer) value(key string, value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		// In the order of the output, for the numbering to be stable
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v[k] = n.value(k, v[k])
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = n.value(key, item)
+		}
+		return v
+	}
+	if value == nil {
+		return nil
+	}
+	if contains(n.TimeFields, key) {
+		return "<timestamp>"
+	}
+	if id, ok := value.(string); ok && id != "" && contains(n.IDFields, key) {
+		return n.id(id)
+	}
+	return value
+}
+
+func (n *Normalizer) id(id string) string {
+	if n.ids == nil {
+		n.ids = make(map[string]string)
+	}
+	placeholder, ok := n.ids[id]
+	if !ok {
+		placeholder = fmt.Sprintf("<id-%d>", len(n.ids)+1)
+		n.ids[id] = placeholder
+		n.order = append(n.order, id)
+	}
+	return placeholder
+}
+
+func contains(fields []string, key string) bool {
+	for _, field := range fields {
+		if field == key {
+			return true
+		}
+	}
+	return false
+}
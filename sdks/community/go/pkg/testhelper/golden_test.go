@@ -0,0 +1,118 @@
+package testhelper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	encoding "github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding/sse"
+)
+
+// toolReply is a run calling a tool, with fresh IDs and timestamps each time
+func toolReply() []events.Event {
+	run := events.NewRunStartedEventWithOptions("", "", events.WithAutoThreadID(), events.WithAutoRunID())
+	message := events.NewTextMessageStartEvent("", events.WithRole("assistant"), events.WithAutoMessageID())
+	tool := events.NewToolCallStartEvent("", "search", events.WithAutoToolCallID(), events.WithParentMessageID(message.MessageID))
+	return []events.Event{
+		run,
+		message,
+		events.NewTextMessageContentEvent(message.MessageID, "Searching"),
+		events.NewTextMessageEndEvent(message.MessageID),
+		tool,
+		events.NewToolCallArgsEvent(tool.ToolCallID, `{"q":"<go>"}`),
+		events.NewToolCallEndEvent(tool.ToolCallID),
+		events.NewRunFinishedEvent(run.ThreadID(), run.RunID()),
+	}
+}
+
+// recordingTB records the failures of an assertion under test
+type recordingTB struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertGoldenEvents(t *testing.T) {
+	AssertGoldenEvents(t, "tool_reply", toolReply())
+}
+
+func TestAssertGoldenSSE(t *testing.T) {
+	var stream bytes.Buffer
+	writer := encoding.NewSSEWriter()
+	for _, event := range toolReply() {
+		require.NoError(t, writer.WriteEvent(context.Background(), &stream, event))
+	}
+	AssertGoldenSSE(t, "tool_reply_sse", stream.Bytes())
+}
+
+func TestCaptureMatchesGolden(t *testing.T) {
+	server := NewMockAGUIServer()
+	defer server.Close()
+	var steps []Step
+	for _, event := range toolReply() {
+		steps = append(steps, Emit(event))
+	}
+	server.Script(steps...)
+
+	client := newClient(server)
+	defer client.Close()
+	frames, errs, err := client.Stream(sse.StreamOptions{Context: context.Background(), Payload: runInput()})
+	require.NoError(t, err)
+	seq, err := Capture(t, frames, errs)
+	require.NoError(t, err)
+	AssertGoldenEvents(t, "tool_reply", seq)
+}
+
+func TestNormalizer(t *testing.T) {
+	n := NewNormalizer()
+	got, err := n.JSON([]byte(`{"runId":"r-9","threadId":"t-9","timestamp":1700000000000,"result":{"id":"t-9","count":12345678901234567890}}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"result":{"count":12345678901234567890,"id":"<id-1>"},"runId":"<id-2>","threadId":"<id-1>","timestamp":"<timestamp>"}`, string(got))
+
+	assert.Equal(t, "run <id-2> of thread <id-1>", string(n.Text([]byte("run r-9 of thread t-9"))))
+
+	stream := n.SSE([]byte("id: RUN_STARTED_1700000000000\ndata: {\"runId\":\"r-10\"}\n\ndata: not json\n\n"))
+	assert.Equal(t, "id: RUN_STARTED_<timestamp>\ndata: {\"runId\":\"<id-3>\"}\n\ndata: not json\n\n", string(stream))
+}
+
+func TestAssertGoldenShowsDiff(t *testing.T) {
+	if updating() {
+		t.Skip("golden files are being updated")
+	}
+	rec := &recordingTB{TB: t}
+	assert.False(t, AssertGolden(rec, "tool_reply", []byte("something else\n")))
+	require.Len(t, rec.failures, 1)
+	assert.Contains(t, rec.failures[0], "+something else")
+	assert.Contains(t, rec.failures[0], "run the test with -update")
+}
+
+func TestAssertGoldenUpdate(t *testing.T) {
+	if *update {
+		t.Skip("golden files are being updated")
+	}
+	t.Chdir(t.TempDir())
+	t.Setenv(UpdateEnv, "1")
+	assert.True(t, AssertGolden(t, "nested/output", []byte("rendered\n")))
+
+	written, err := os.ReadFile(GoldenPath("nested/output"))
+	require.NoError(t, err)
+	assert.Equal(t, "rendered\n", string(written))
+
+	require.NoError(t, os.Unsetenv(UpdateEnv))
+	rec := &recordingTB{TB: t}
+	assert.True(t, AssertGolden(rec, "nested/output", []byte("rendered\n")))
+	assert.False(t, AssertGolden(rec, "nested/output", []byte(strings.ToUpper("rendered\n"))))
+}
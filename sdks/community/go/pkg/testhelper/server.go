@@ -0,0 +1,218 @@
+// Package testhelper provides an in-process AG-UI agent for tests of clients
+// and transports, so they do not need a running example server.
+package testhelper
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/encoding/sse"
+)
+
+// AgenticPath is the run endpoint of the example servers
+const AgenticPath = "/agentic"
+
+// Step is one action of a scripted run
+type Step struct {
+	// Event is sent as an SSE frame
+	Event events.Event
+
+	// Raw is written to the stream as is, e.g. a malformed frame
+	Raw string
+
+	// Delay is waited before the step
+	Delay time.Duration
+
+	// Disconnect drops the connection without ending the stream
+	Disconnect bool
+
+	// Status answers the request with an HTTP error instead of a stream;
+	// it only applies to the first step of a script
+	Status int
+}
+
+// Emit sends an event
+func Emit(event events.Event) Step {
+	return Step{Event: event}
+}
+
+// Raw writes data to the stream unchanged
+func Raw(data string) Step {
+	return Step{Raw: data}
+}
+
+// Sleep waits before the next step
+func Sleep(d time.Duration) Step {
+	return Step{Delay: d}
+}
+
+// Disconnect drops the connection in the middle of the stream
+func Disconnect() Step {
+	return Step{Disconnect: true}
+}
+
+// Fail answers the request with an HTTP error
+func Fail(status int) Step {
+	return Step{Status: status}
+}
+
+// TextReply is the script of a run answering with one text message, which
+// the server plays when no script is queued
+func TextReply(threadID, runID, text string) []Step {
+	return []Step{
+		Emit(events.NewRunStartedEvent(threadID, runID)),
+		Emit(events.NewTextMessageStartEvent("msg-1", events.WithRole("assistant"))),
+		Emit(events.NewTextMessageContentEvent("msg-1", text)),
+		Emit(events.NewTextMessageEndEvent("msg-1")),
+		Emit(events.NewRunFinishedEvent(threadID, runID)),
+	}
+}
+
+// Request is a request received by the server
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   []byte
+
+	// Input is the decoded body of a run request, nil when it is not a
+	// RunAgentInput
+	Input *types.RunAgentInput
+}
+
+// MockAGUIServer is an AG-UI agent served over HTTP. GET / describes the
+// server and a POST to any path runs the next queued script, streaming it
+// as Server-Sent Events.
+type MockAGUIServer struct {
+	*httptest.Server
+
+	writer *sse.SSEWriter
+
+	mu       sync.Mutex
+	scripts  [][]Step
+	requests []Request
+}
+
+// NewMockAGUIServer starts a server; Close it at the end of the test
+func NewMockAGUIServer() *MockAGUIServer {
+	s := &MockAGUIServer{writer: sse.NewSSEWriter()}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Endpoint returns the URL of the run endpoint
+func (s *MockAGUIServer) Endpoint() string {
+	return s.URL + AgenticPath
+}
+
+// Script queues the steps of a run; runs play the queued scripts in order
+func (s *MockAGUIServer) Script(steps ...Step) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scripts = append(s.scripts, steps)
+}
+
+// Requests returns the requests received so far
+func (s *MockAGUIServer) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *MockAGUIServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	req := Request{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone(), Body: body}
+	var input types.RunAgentInput
+	if json.Unmarshal(body, &input) == nil {
+		req.Input = &input
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	s.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/":
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"message": "AG-UI mock server", "endpoint": AgenticPath})
+		return
+	case r.Method != http.MethodPost:
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	case req.Input == nil:
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	steps := TextReply(input.ThreadID, input.RunID, "Hello from the mock agent")
+	if len(s.scripts) > 0 {
+		steps, s.scripts = s.scripts[0], s.scripts[1:]
+	}
+	s.mu.Unlock()
+	if len(steps) > 0 && steps[0].Status != 0 {
+		http.Error(w, http.StatusText(steps[0].Status), steps[0].Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flush(w)
+	s.play(w, r, steps)
+}
+
+// play runs the steps of a script until they are done or the client leaves
+func (s *MockAGUIServer) play(w http.ResponseWriter, r *http.Request, steps []Step) {
+	ctx := r.Context()
+	for _, step := range steps {
+		if step.Delay > 0 {
+			select {
+			case <-time.After(step.Delay):
+			case <-ctx.Done():
+				return
+			}
+		}
+		switch {
+		case step.Disconnect:
+			disconnect(w)
+			return
+		case step.Event != nil:
+			if err := s.writer.WriteEvent(ctx, w, step.Event); err != nil {
+				return
+			}
+		case step.Raw != "":
+			if _, err := io.WriteString(w, step.Raw); err != nil {
+				return
+			}
+			flush(w)
+		}
+	}
+}
+
+func flush(w http.ResponseWriter) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// disconnect closes the connection under a response without ending it, as
+// a crashed server or a dropped network would
+func disconnect(w http.ResponseWriter) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		panic("testhelper: the response cannot be hijacked to disconnect")
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	_ = conn.Close()
+}
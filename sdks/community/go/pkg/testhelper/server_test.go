@@ -0,0 +1,155 @@
+package testhelper
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/client/sse"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+)
+
+func newClient(server *MockAGUIServer) *sse.Client {
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	return sse.NewClient(sse.Config{Endpoint: server.Endpoint(), APIKey: "test-key", Logger: logger})
+}
+
+func runInput() types.RunAgentInput {
+	return types.RunAgentInput{
+		ThreadID:       "thread-1",
+		RunID:          "run-1",
+		State:          map[string]any{},
+		Messages:       []types.Message{},
+		Tools:          []types.Tool{},
+		Context:        []types.Context{},
+		ForwardedProps: map[string]any{},
+	}
+}
+
+// collect reads a stream to its end, returning the event types received
+// and the error that ended it
+func collect(t *testing.T, client *sse.Client) ([]events.EventType, error) {
+	frames, errs, err := client.Stream(sse.StreamOptions{Context: context.Background(), Payload: runInput()})
+	require.NoError(t, err)
+
+	var got []events.EventType
+	var streamErr error
+	timeout := time.After(5 * time.Second)
+	for frames != nil || errs != nil {
+		select {
+		case frame, ok := <-frames:
+			if !ok {
+				frames = nil
+				continue
+			}
+			var base struct {
+				Type events.EventType `json:"type"`
+			}
+			if json.Unmarshal(frame.Data, &base) != nil {
+				base.Type = "MALFORMED"
+			}
+			got = append(got, base.Type)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			streamErr = err
+		case <-timeout:
+			t.Fatal("the stream did not end")
+		}
+	}
+	return got, streamErr
+}
+
+func TestMockServerDefaultReply(t *testing.T) {
+	server := NewMockAGUIServer()
+	defer server.Close()
+	client := newClient(server)
+	defer client.Close()
+
+	got, err := collect(t, client)
+	require.NoError(t, err)
+	assert.Equal(t, []events.EventType{
+		events.EventTypeRunStarted,
+		events.EventTypeTextMessageStart,
+		events.EventTypeTextMessageContent,
+		events.EventTypeTextMessageEnd,
+		events.EventTypeRunFinished,
+	}, got)
+
+	requests := server.Requests()
+	require.Len(t, requests, 1)
+	assert.Equal(t, http.MethodPost, requests[0].Method)
+	assert.Equal(t, AgenticPath, requests[0].Path)
+	assert.Equal(t, "Bearer test-key", requests[0].Header.Get("Authorization"))
+	require.NotNil(t, requests[0].Input)
+	assert.Equal(t, "run-1", requests[0].Input.RunID)
+}
+
+func TestMockServerScripts(t *testing.T) {
+	server := NewMockAGUIServer()
+	defer server.Close()
+	client := newClient(server)
+	defer client.Close()
+
+	server.Script(
+		Emit(events.NewRunStartedEvent("thread-1", "run-1")),
+		Raw("data: {not json\n\n"),
+		Sleep(50*time.Millisecond),
+		Emit(events.NewRunFinishedEvent("thread-1", "run-1")),
+	)
+	server.Script(
+		Emit(events.NewRunStartedEvent("thread-1", "run-2")),
+		Disconnect(),
+	)
+	server.Script(Fail(http.StatusServiceUnavailable))
+
+	t.Run("malformed frames and delays", func(t *testing.T) {
+		start := time.Now()
+		got, err := collect(t, client)
+		require.NoError(t, err)
+		assert.Equal(t, []events.EventType{events.EventTypeRunStarted, "MALFORMED", events.EventTypeRunFinished}, got)
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	})
+
+	t.Run("disconnect", func(t *testing.T) {
+		got, err := collect(t, client)
+		assert.Equal(t, []events.EventType{events.EventTypeRunStarted}, got)
+		assert.Error(t, err)
+	})
+
+	t.Run("http error", func(t *testing.T) {
+		_, _, err := client.Stream(sse.StreamOptions{Context: context.Background(), Payload: runInput()})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "503")
+	})
+
+	assert.Len(t, server.Requests(), 3)
+}
+
+func TestMockServerRejectsInvalidRequests(t *testing.T) {
+	server := NewMockAGUIServer()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Post(server.Endpoint(), "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	require.Len(t, server.Requests(), 2)
+	assert.Nil(t, server.Requests()[1].Input)
+}
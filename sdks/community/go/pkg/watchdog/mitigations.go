@@ -0,0 +1,87 @@
+package watchdog
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/bufpool"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server"
+)
+
+// CompactValidators forgets the finished runs and answered approvals kept
+// by long-lived validators (see events.EventValidator.Compact)
+func CompactValidators(validators ...*events.EventValidator) Mitigation {
+	return Mitigation{
+		Name: "compact validators",
+		Apply: func(context.Context) error {
+			for _, v := range validators {
+				v.Compact()
+			}
+			return nil
+		},
+	}
+}
+
+// ShrinkPool drops the buffers retained by pool
+func ShrinkPool(pool *bufpool.Pool) Mitigation {
+	return Mitigation{
+		Name: "shrink buffer pool",
+		Apply: func(context.Context) error {
+			pool.Reset()
+			return nil
+		},
+	}
+}
+
+// FreeOSMemory forces a garbage collection and returns as much memory to
+// the operating system as possible, at the cost of a pause; it applies at
+// LevelCritical
+func FreeOSMemory() Mitigation {
+	return Mitigation{
+		Name:  "free OS memory",
+		Level: LevelCritical,
+		Apply: func(context.Context) error {
+			debug.FreeOSMemory()
+			return nil
+		},
+	}
+}
+
+// Throttle applies backpressure to the streams of the runs sharing flow,
+// lowering its rate to rate content events per second, and restores its
+// rate once released
+func Throttle(flow *server.SharedFlowController, rate float64) Mitigation {
+	var previous float64
+	return Mitigation{
+		Name: "throttle streams",
+		Apply: func(context.Context) error {
+			previous = flow.Rate()
+			if previous <= 0 || rate < previous {
+				flow.SetRate(rate)
+			}
+			return nil
+		},
+		Release: func(context.Context) error {
+			flow.SetRate(previous)
+			return nil
+		},
+	}
+}
+
+// ShedRuns rejects new runs of runs (see server.RunManager.Shed) until
+// released; it applies at LevelCritical
+func ShedRuns(runs *server.RunManager) Mitigation {
+	return Mitigation{
+		Name:  "shed new runs",
+		Level: LevelCritical,
+		Apply: func(context.Context) error {
+			runs.Shed(true)
+			return nil
+		},
+		Release: func(context.Context) error {
+			runs.Shed(false)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,298 @@
+// Package watchdog watches the memory of the process and mitigates its
+// pressure before it runs out: when the memory in use crosses a threshold,
+// the Watchdog applies the mitigations configured for it, e.g. compacting
+// validators, shrinking caches, slowing down streams or shedding new runs,
+// and releases them once the pressure is gone. Each action is logged and
+// reported as an Action.
+package watchdog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/metrics"
+	"sync"
+	"time"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/clock"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/logging"
+)
+
+// Level is a level of memory pressure
+type Level int
+
+const (
+	LevelNormal Level = iota
+	// LevelWarning is reached past Config.Warning
+	LevelWarning
+	// LevelCritical is reached past Config.Critical
+	LevelCritical
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelNormal:
+		return "normal"
+	case LevelWarning:
+		return "warning"
+	case LevelCritical:
+		return "critical"
+	default:
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+}
+
+// ResourceMonitor reports the memory in use by the process, in bytes
+type ResourceMonitor interface {
+	MemoryUsage() (uint64, error)
+}
+
+// ResourceMonitorFunc adapts a function to ResourceMonitor
+type ResourceMonitorFunc func() (uint64, error)
+
+func (f ResourceMonitorFunc) MemoryUsage() (uint64, error) {
+	return f()
+}
+
+// heapMetric is the runtime metric of the memory of the live and not yet
+// swept heap objects
+const heapMetric = "/memory/classes/heap/objects:bytes"
+
+// RuntimeMonitor reports the heap memory of the live objects, as sampled by
+// the runtime without stopping the world
+func RuntimeMonitor() ResourceMonitor {
+	return ResourceMonitorFunc(func() (uint64, error) {
+		sample := []metrics.Sample{{Name: heapMetric}}
+		metrics.Read(sample)
+		if sample[0].Value.Kind() != metrics.KindUint64 {
+			return 0, fmt.Errorf("runtime metric %s is not supported", heapMetric)
+		}
+		return sample[0].Value.Uint64(), nil
+	})
+}
+
+// Mitigation relieves memory pressure
+type Mitigation struct {
+	// Name describes the mitigation in actions and logs
+	Name string
+
+	// Level is the level of pressure the mitigation applies from (defaults
+	// to LevelWarning)
+	Level Level
+
+	// Apply applies the mitigation when the pressure reaches Level
+	Apply func(ctx context.Context) error
+
+	// Release, when set, undoes the mitigation once the pressure falls
+	// below Level, e.g. to accept runs again
+	Release func(ctx context.Context) error
+}
+
+// Action describes a mitigation applied or released
+type Action struct {
+	Mitigation string
+	Level      Level
+
+	// Released tells a mitigation released from one applied
+	Released bool
+
+	// Usage is the memory in use that triggered the action, in bytes
+	Usage uint64
+	At    time.Time
+
+	// Err is the error of the mitigation, if it failed
+	Err error
+}
+
+func (a Action) String() string {
+	verb := "applied"
+	if a.Released {
+		verb = "released"
+	}
+	s := fmt.Sprintf("%s %s at %s pressure (%d bytes in use)", verb, a.Mitigation, a.Level, a.Usage)
+	if a.Err != nil {
+		s += ": " + a.Err.Error()
+	}
+	return s
+}
+
+// Config configures a Watchdog
+type Config struct {
+	// Monitor reports the memory in use (defaults to RuntimeMonitor)
+	Monitor ResourceMonitor
+
+	// Warning and Critical are the memory in use, in bytes, from which the
+	// pressure is at LevelWarning and LevelCritical (0 = never)
+	Warning  uint64
+	Critical uint64
+
+	// Hysteresis is the fraction of a threshold the memory in use must
+	// fall below for its level to end (defaults to 0.9), so that
+	// mitigations do not flap around a threshold
+	Hysteresis float64
+
+	// Interval is the time between two samples (defaults to 5s)
+	Interval time.Duration
+
+	// Mitigations are applied in order as their level is reached, and
+	// released in reverse order
+	Mitigations []Mitigation
+
+	// Alert, when set, receives each action taken, e.g. to page operators
+	Alert func(Action)
+
+	// Clock times the samples (defaults to the real clock)
+	Clock clock.Clock
+
+	// Logger receives the actions taken (defaults to slog.Default())
+	Logger *slog.Logger
+}
+
+// Watchdog samples the memory in use and applies the mitigations of the
+// level of pressure it reaches. It is safe for concurrent use.
+type Watchdog struct {
+	config Config
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	level   Level
+	usage   uint64
+	applied []bool
+}
+
+// New creates a watchdog
+func New(config Config) *Watchdog {
+	if config.Monitor == nil {
+		config.Monitor = RuntimeMonitor()
+	}
+	if config.Hysteresis <= 0 || config.Hysteresis > 1 {
+		config.Hysteresis = 0.9
+	}
+	if config.Interval <= 0 {
+		config.Interval = 5 * time.Second
+	}
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+	config.Clock = clock.Or(config.Clock)
+	for i := range config.Mitigations {
+		if config.Mitigations[i].Level == LevelNormal {
+			config.Mitigations[i].Level = LevelWarning
+		}
+	}
+	return &Watchdog{
+		config:  config,
+		logger:  logging.ForComponent(config.Logger, "watchdog"),
+		applied: make([]bool, len(config.Mitigations)),
+	}
+}
+
+// Run checks the memory every Config.Interval until ctx is done
+func (w *Watchdog) Run(ctx context.Context) {
+	ticker := w.config.Clock.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := w.Check(ctx); err != nil {
+			w.logger.WarnContext(ctx, "Failed to sample memory", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Check samples the memory in use once, applying or releasing mitigations
+// as its level of pressure changes, and returns the actions taken
+func (w *Watchdog) Check(ctx context.Context) ([]Action, error) {
+	usage, err := w.config.Monitor.MemoryUsage()
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.usage = usage
+	w.level = w.levelOf(usage)
+
+	var actions []Action
+	for i := len(w.config.Mitigations) - 1; i >= 0; i-- {
+		if m := w.config.Mitigations[i]; w.applied[i] && w.level < m.Level {
+			w.applied[i] = false
+			if m.Release != nil {
+				actions = append(actions, w.act(ctx, m, true, m.Release))
+			}
+		}
+	}
+	for i, m := range w.config.Mitigations {
+		if !w.applied[i] && w.level >= m.Level {
+			w.applied[i] = true
+			if m.Apply != nil {
+				actions = append(actions, w.act(ctx, m, false, m.Apply))
+			}
+		}
+	}
+	return actions, nil
+}
+
+// levelOf returns the level of pressure of usage, keeping the current level
+// until usage falls below its threshold by the hysteresis; callers must
+// hold mu
+func (w *Watchdog) levelOf(usage uint64) Level {
+	level := LevelNormal
+	for _, l := range []Level{LevelWarning, LevelCritical} {
+		threshold := w.threshold(l)
+		if threshold == 0 {
+			continue
+		}
+		if usage >= threshold || (w.level >= l && float64(usage) >= float64(threshold)*w.config.Hysteresis) {
+			level = l
+		}
+	}
+	return level
+}
+
+func (w *Watchdog) threshold(level Level) uint64 {
+	switch level {
+	case LevelWarning:
+		return w.config.Warning
+	case LevelCritical:
+		return w.config.Critical
+	}
+	return 0
+}
+
+// act runs a mitigation and reports its action; callers must hold mu
+func (w *Watchdog) act(ctx context.Context, m Mitigation, released bool, run func(context.Context) error) Action {
+	action := Action{Mitigation: m.Name, Level: w.level, Released: released, Usage: w.usage, At: w.config.Clock.Now()}
+	if released {
+		action.Level = m.Level
+	}
+	action.Err = run(ctx)
+	if action.Err != nil {
+		w.logger.ErrorContext(ctx, "Memory mitigation failed", "mitigation", m.Name, "released", released, "bytes", w.usage, "error", action.Err)
+	} else if released {
+		w.logger.InfoContext(ctx, "Memory mitigation released", "mitigation", m.Name, "level", action.Level.String(), "bytes", w.usage)
+	} else {
+		w.logger.WarnContext(ctx, "Memory mitigation applied", "mitigation", m.Name, "level", action.Level.String(), "bytes", w.usage)
+	}
+	if w.config.Alert != nil {
+		w.config.Alert(action)
+	}
+	return action
+}
+
+// Level returns the level of pressure of the last sample
+func (w *Watchdog) Level() Level {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.level
+}
+
+// Usage returns the memory in use at the last sample, in bytes
+func (w *Watchdog) Usage() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.usage
+}
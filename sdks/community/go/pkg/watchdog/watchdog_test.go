@@ -0,0 +1,154 @@
+package watchdog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/events"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/core/types"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/server"
+	"github.com/ag-ui-protocol/ag-ui/sdks/community/go/pkg/testhelper"
+)
+
+// recorder records the mitigations applied and released
+type recorder []string
+
+func (r *recorder) mitigation(name string, level Level) Mitigation {
+	return Mitigation{
+		Name:    name,
+		Level:   level,
+		Apply:   func(context.Context) error { *r = append(*r, "+"+name); return nil },
+		Release: func(context.Context) error { *r = append(*r, "-"+name); return nil },
+	}
+}
+
+func TestWatchdog(t *testing.T) {
+	var usage uint64
+	var calls recorder
+	var alerts []Action
+	w := New(Config{
+		Monitor:  ResourceMonitorFunc(func() (uint64, error) { return usage, nil }),
+		Warning:  100,
+		Critical: 200,
+		Mitigations: []Mitigation{
+			calls.mitigation("compact", 0),
+			calls.mitigation("shed", LevelCritical),
+			{Name: "gc", Level: LevelCritical, Apply: func(context.Context) error { return errors.New("no luck") }},
+		},
+		Alert:  func(a Action) { alerts = append(alerts, a) },
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	check := func(bytes uint64) []Action {
+		usage = bytes
+		actions, err := w.Check(context.Background())
+		require.NoError(t, err)
+		return actions
+	}
+
+	assert.Empty(t, check(50))
+	assert.Equal(t, LevelNormal, w.Level())
+
+	actions := check(120)
+	require.Len(t, actions, 1)
+	assert.Equal(t, "applied compact at warning pressure (120 bytes in use)", actions[0].String())
+	assert.Equal(t, LevelWarning, w.Level())
+
+	actions = check(250)
+	assert.Equal(t, []string{"+compact", "+shed"}, []string(calls))
+	require.Len(t, actions, 2)
+	assert.EqualError(t, actions[1].Err, "no luck")
+	assert.Equal(t, uint64(250), w.Usage())
+
+	assert.Empty(t, check(190), "within the hysteresis of the critical threshold")
+	assert.Equal(t, LevelCritical, w.Level())
+
+	actions = check(150)
+	assert.Equal(t, LevelWarning, w.Level())
+	require.Len(t, actions, 1, "gc has nothing to release")
+	assert.True(t, actions[0].Released)
+	assert.Equal(t, LevelCritical, actions[0].Level)
+
+	check(10)
+	assert.Equal(t, []string{"+compact", "+shed", "-shed", "-compact"}, []string(calls))
+	assert.Len(t, alerts, 5)
+
+	check(300)
+	assert.Equal(t, []string{"+compact", "+shed", "-shed", "-compact", "+compact", "+shed"}, []string(calls), "mitigations apply again")
+}
+
+func TestWatchdogRun(t *testing.T) {
+	clk := testhelper.NewFakeClock(time.Unix(0, 0))
+	samples := make(chan struct{}, 10)
+	w := New(Config{
+		Monitor: ResourceMonitorFunc(func() (uint64, error) {
+			samples <- struct{}{}
+			return 0, nil
+		}),
+		Interval: time.Second,
+		Clock:    clk,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+
+	<-samples
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+	<-samples
+	cancel()
+	<-done
+}
+
+func TestRuntimeMonitor(t *testing.T) {
+	usage, err := RuntimeMonitor().MemoryUsage()
+	require.NoError(t, err)
+	assert.Positive(t, usage)
+}
+
+func TestMitigations(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("compact validators", func(t *testing.T) {
+		v := events.NewEventValidator()
+		require.NoError(t, v.ValidateEvent(events.NewRunStartedEvent("thread-1", "run-1")))
+		require.NoError(t, v.ValidateEvent(events.NewRunFinishedEvent("thread-1", "run-1")))
+		require.NoError(t, CompactValidators(v).Apply(ctx))
+		assert.Zero(t, v.Compact())
+	})
+
+	t.Run("throttle", func(t *testing.T) {
+		flow := server.NewSharedFlowController(server.SharedFlowConfig{Rate: 100})
+		throttle := Throttle(flow, 10)
+		require.NoError(t, throttle.Apply(ctx))
+		assert.Equal(t, 10.0, flow.Rate())
+		require.NoError(t, throttle.Release(ctx))
+		assert.Equal(t, 100.0, flow.Rate())
+
+		slow := server.NewSharedFlowController(server.SharedFlowConfig{Rate: 1})
+		require.NoError(t, Throttle(slow, 10).Apply(ctx))
+		assert.Equal(t, 1.0, slow.Rate(), "slower rates are kept")
+	})
+
+	t.Run("shed runs", func(t *testing.T) {
+		runs := server.NewRunManager(server.AgentFunc(func(context.Context, *types.RunAgentInput, *server.EventEmitter) error { return nil }),
+			server.RunManagerConfig{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+		shed := ShedRuns(runs)
+		assert.Equal(t, LevelCritical, shed.Level)
+		emitter := server.EmitterFunc(func(context.Context, events.Event) error { return nil })
+
+		require.NoError(t, shed.Apply(ctx))
+		assert.ErrorIs(t, runs.Run(ctx, &types.RunAgentInput{}, emitter), server.ErrShedding)
+		require.NoError(t, shed.Release(ctx))
+		assert.NoError(t, runs.Run(ctx, &types.RunAgentInput{}, emitter))
+	})
+}